@@ -0,0 +1,99 @@
+// Package braintest lets tests run against recorded LLM traffic instead of
+// live providers or hand-written mock servers.
+//
+// Record the fixtures once with real credentials:
+//
+//	OVERHUMAN_RECORD=1 ANTHROPIC_API_KEY=sk-ant-... go test ./internal/skills -run TestDigest
+//
+// commit the fixture directory, and the same test then replays offline:
+//
+//	llm := braintest.Provider(t, "testdata/llm", braintest.EnvProvider)
+//
+// API keys and common token formats are redacted before anything is
+// written (see brain.Redact).
+package braintest
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// RecordEnv switches Provider from replaying to recording. The daemon reads
+// it too, as the directory to record into.
+const RecordEnv = "OVERHUMAN_RECORD"
+
+// keyEnvs hold credentials redacted from recordings.
+var keyEnvs = []string{"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "LLM_API_KEY"}
+
+// Provider returns the LLM for a test. By default it replays the fixtures
+// in dir and fails the test if a request has none. With OVERHUMAN_RECORD
+// set it records live traffic from live into dir instead, skipping the
+// test when live cannot be built (no API key).
+func Provider(t testing.TB, dir string, live func() (brain.LLMProvider, error)) brain.LLMProvider {
+	t.Helper()
+	if os.Getenv(RecordEnv) == "" {
+		return Replay(t, dir)
+	}
+	p, err := live()
+	if err != nil {
+		t.Skipf("braintest: recording needs a live provider: %v", err)
+	}
+	rec, err := brain.NewRecorder(p, dir, secrets()...)
+	if err != nil {
+		t.Fatalf("braintest: %v", err)
+	}
+	return rec
+}
+
+// Replay returns a provider serving the fixtures in dir. Requests without
+// a fixture fail the test at cleanup with a hint to re-record.
+func Replay(t testing.TB, dir string) *brain.ReplayProvider {
+	t.Helper()
+	p, err := brain.LoadReplay(dir, secrets()...)
+	if err != nil {
+		t.Fatalf("braintest: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, req := range p.Misses() {
+			t.Errorf("braintest: no fixture in %s for a request with %d message(s), model %q; re-record with %s=1",
+				dir, len(req.Messages), req.Model, RecordEnv)
+		}
+	})
+	return p
+}
+
+// Record wraps live so its traffic is written to dir, for tests that build
+// fixtures programmatically (e.g. from a FakeProvider).
+func Record(t testing.TB, live brain.LLMProvider, dir string) *brain.Recorder {
+	t.Helper()
+	rec, err := brain.NewRecorder(live, dir, secrets()...)
+	if err != nil {
+		t.Fatalf("braintest: %v", err)
+	}
+	return rec
+}
+
+// EnvProvider builds a live provider from ANTHROPIC_API_KEY or
+// OPENAI_API_KEY, for use as Provider's live argument.
+func EnvProvider() (brain.LLMProvider, error) {
+	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+		return brain.NewUniversalProvider(brain.AnthropicConfig(key)), nil
+	}
+	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+		return brain.NewUniversalProvider(brain.OpenAIConfig(key)), nil
+	}
+	return nil, errors.New("set ANTHROPIC_API_KEY or OPENAI_API_KEY")
+}
+
+func secrets() []string {
+	var out []string
+	for _, k := range keyEnvs {
+		if v := os.Getenv(k); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
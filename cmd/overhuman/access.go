@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// newAccessControl returns the team access control, or nil when no team
+// is configured and the daemon stays single-user.
+func newAccessControl(cfg Config, audit *security.AuditLogger) *security.AccessControl {
+	if len(cfg.Team) == 0 {
+		return nil
+	}
+	ac, err := security.NewAccessControl(cfg.AdminToken, cfg.Team, audit)
+	if err != nil {
+		log.Fatalf("[config] team: %v", err)
+	}
+	return ac
+}
+
+// teamRouteRole is the least role a request to the API or kiosk server
+// needs in team mode. Routes are listed: the kiosk shell and health check
+// are public, task submission and the task-scoped reads need a member —
+// their handlers show a member only their own tasks, UIs, history and
+// sessions — and status reads a viewer. Everything else, including goals,
+// costs and any route added later, needs an admin.
+func teamRouteRole(r *http.Request) security.Role {
+	path := r.URL.Path
+	switch path {
+	case "/", "/kiosk", "/theme.css", "/logo", "/health", "/ws/schema":
+		return ""
+	case "/input", "/input/sync", "/voice", "/v1/chat/completions", "/api/ui/generate":
+		return security.RoleMember
+	case "/ws":
+		// Viewers may watch; the WS server checks what each client sends.
+		return security.RoleViewer
+	}
+	switch {
+	case strings.HasPrefix(path, "/kiosk/"):
+		return ""
+	case strings.HasPrefix(path, "/artifacts/"):
+		// Download links are signed; recipients of a reply have no token.
+		return ""
+	case strings.HasPrefix(path, "/webhook/") && r.Method == http.MethodPost:
+		// Inbound webhooks are signed with their own secret.
+		return ""
+	case path == "/api/sessions" || strings.HasPrefix(path, "/api/sessions/"):
+		// Members list and end their own conversations.
+		return security.RoleMember
+	case strings.HasPrefix(path, "/tasks/") && r.Method == http.MethodDelete:
+		// Members cancel their own tasks; the handler checks whose.
+		return security.RoleMember
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return security.RoleAdmin
+	}
+	switch path {
+	case "/tasks", "/api/chat/export", "/api/ui/last":
+		return security.RoleMember
+	case "/api/ui/ws/status", "/api/mode", "/mode", "/api/whoami", "/capabilities", "/api/capabilities",
+		"/v1/models", "/metrics", "/api/commands", "/api/soul", "/api/skills", "/api/changes",
+		"/api/approvals", "/api/automations", "/api/schedules", "/api/routing/overrides":
+		return security.RoleViewer
+	}
+	for _, prefix := range []string{"/api/skills/", "/api/changes/", "/api/approvals/", "/api/schedules/"} {
+		if strings.HasPrefix(path, prefix) {
+			return security.RoleViewer
+		}
+	}
+	if strings.HasPrefix(path, "/api/ui/") {
+		// A task's UI; the handler checks whose task it was.
+		return security.RoleMember
+	}
+	return security.RoleAdmin
+}
+
+// whoamiHandler serves GET /api/whoami: the caller's name and role, or an
+// empty object when the daemon is single-user (everything is allowed). The
+// kiosk uses it to ask for a token and to go read-only for viewers.
+func whoamiHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if p, ok := security.PrincipalFrom(r.Context()); ok {
+		json.NewEncoder(w).Encode(p)
+		return
+	}
+	w.Write([]byte("{}\n"))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestTeamRouteRole(t *testing.T) {
+	for _, tc := range []struct {
+		method, path string
+		want         security.Role
+	}{
+		{"GET", "/", ""},
+		{"GET", "/kiosk/app.js", ""},
+		{"GET", "/health", ""},
+		{"GET", "/ws", security.RoleViewer},
+		{"GET", "/api/mode", security.RoleViewer},
+		{"GET", "/api/whoami", security.RoleViewer},
+		{"POST", "/input", security.RoleMember},
+		{"POST", "/input/sync", security.RoleMember},
+		{"POST", "/voice", security.RoleMember},
+		{"POST", "/v1/chat/completions", security.RoleMember},
+		{"GET", "/v1/models", security.RoleViewer},
+		{"GET", "/api/chat/export", security.RoleMember},
+		{"GET", "/api/sessions", security.RoleMember},
+		{"DELETE", "/api/sessions/API/bob", security.RoleMember},
+		{"GET", "/artifacts/t1/report.csv", ""},
+		{"POST", "/webhook/github", ""},
+		{"DELETE", "/tasks/task_1", security.RoleMember},
+		{"GET", "/tasks", security.RoleMember},
+		{"GET", "/api/ui/task_1", security.RoleMember},
+		{"GET", "/api/ui/last", security.RoleMember},
+		{"GET", "/api/ui/ws/status", security.RoleViewer},
+		{"POST", "/api/ui/generate", security.RoleMember},
+		{"GET", "/metrics", security.RoleViewer},
+		{"GET", "/api/skills/sk_1", security.RoleViewer},
+		{"GET", "/api/approvals", security.RoleViewer},
+		{"GET", "/goals", security.RoleAdmin},
+		{"GET", "/goals/g1", security.RoleAdmin},
+		{"GET", "/api/costs", security.RoleAdmin},
+		{"GET", "/api/moderation/held", security.RoleAdmin},
+		{"GET", "/api/something-new", security.RoleAdmin},
+		{"POST", "/mode", security.RoleAdmin},
+		{"GET", "/logs/stream", security.RoleAdmin},
+		{"POST", "/api/commands/run", security.RoleAdmin},
+		{"POST", "/api/changes/c1/rollback", security.RoleAdmin},
+	} {
+		if got := teamRouteRole(httptest.NewRequest(tc.method, tc.path, nil)); got != tc.want {
+			t.Errorf("%s %s = %q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestNewAccessControl_SingleUser(t *testing.T) {
+	if ac := newAccessControl(Config{AdminToken: "x"}, nil); ac != nil {
+		t.Error("no team should keep the daemon single-user")
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/approvals"
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/observability"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/versioning"
+)
+
+// actionLogLines is how many log lines view_logs returns.
+const actionLogLines = 50
+
+// kioskActions routes the actions of generated UIs (WSMsgAction):
+//
+//	view_logs     recent log lines, of data.task_id if set
+//	rollback:{id} roll back a change (or data.change_id)
+//	skill:{id}    run a skill as a new task
+//	approve:{id}  approve a pending approval (data.note is kept with it)
+//	deny:{id}     deny a pending approval
+//
+// Any other callback runs as a follow-up task in the sender's
+// conversation, so the agent knows which button of its answer was pressed.
+// Tasks are started with submit; their UI arrives as usual and the
+// action_result only says they started. Without access control, logs are
+// shown only when no admin token guards them.
+func kioskActions(logs *observability.LogBuffer, adminToken string, changes *versioning.Controller, skills *instruments.SkillRegistry, pending *approvals.Store, submit func(*senses.UnifiedInput) bool) *genui.ActionRouter {
+	router := genui.NewActionRouter()
+
+	router.Handle("view_logs", func(_ context.Context, req genui.ActionRequest) (string, error) {
+		if req.Principal == nil && adminToken != "" || !actionByAdmin(req) {
+			return "", fmt.Errorf("viewing logs needs the admin role")
+		}
+		filter := observability.LogFilter{TaskID: req.Field("task_id")}
+		var b strings.Builder
+		for _, e := range logs.Entries(filter, actionLogLines) {
+			fmt.Fprintf(&b, "%s %s %s\n", e.Time.Format("15:04:05"), e.Level, e.Message)
+		}
+		if b.Len() == 0 {
+			return "No log lines.", nil
+		}
+		return b.String(), nil
+	})
+
+	router.Handle("rollback", func(_ context.Context, req genui.ActionRequest) (string, error) {
+		if !actionByAdmin(req) {
+			return "", fmt.Errorf("rollbacks need the admin role")
+		}
+		id := req.Arg
+		if id == "" {
+			id = req.Field("change_id")
+		}
+		ch := changes.Get(id)
+		switch {
+		case id == "" || ch == nil:
+			return "", fmt.Errorf("change %q not found", id)
+		case !changes.CanRollback(ch.Type):
+			return "", fmt.Errorf("changes of type %s cannot be rolled back", ch.Type)
+		}
+		if err := changes.ForceRollback(id); err != nil {
+			return "", err
+		}
+		return "Rolled back " + id, nil
+	})
+
+	router.Handle("skill", func(_ context.Context, req genui.ActionRequest) (string, error) {
+		sk := skills.Get(req.Arg)
+		if sk == nil || sk.Meta.Status == instruments.SkillStatusDeprecated {
+			return "", fmt.Errorf("skill %q not found", req.Arg)
+		}
+		// Same input as the skill's palette entry (skillCommands).
+		text := sk.Meta.Description
+		if text == "" {
+			text = sk.Meta.Name
+		}
+		if in := req.Field("input"); in != "" {
+			text += "\n\n" + in
+		}
+		if !submit(actionInput(req, text)) {
+			return "", fmt.Errorf("pipeline busy")
+		}
+		return "Running " + sk.Meta.Name + "…", nil
+	})
+
+	decide := func(approve bool) genui.ActionHandler {
+		return func(_ context.Context, req genui.ActionRequest) (string, error) {
+			if !actionByAdmin(req) {
+				return "", fmt.Errorf("approvals need the admin role")
+			}
+			by := "admin"
+			if req.Principal != nil {
+				by = req.Principal.Name
+			}
+			a, err := pending.Decide(req.Arg, approve, by, req.Field("note"))
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s %s: %s", a.ID, a.Status, a.Action), nil
+		}
+	}
+	router.Handle("approve", decide(true))
+	router.Handle("deny", decide(false))
+
+	router.HandleDefault(func(_ context.Context, req genui.ActionRequest) (string, error) {
+		text := fmt.Sprintf("I chose %q in your last answer.", req.ActionID)
+		if len(req.Data) > 0 && string(req.Data) != "null" && string(req.Data) != "{}" {
+			text += "\nAction data: " + string(req.Data)
+		}
+		if !submit(actionInput(req, text)) {
+			return "", fmt.Errorf("pipeline busy")
+		}
+		return "Working on it…", nil
+	})
+	return router
+}
+
+// actionByAdmin reports whether req comes from an admin or from the
+// single-user daemon.
+func actionByAdmin(req genui.ActionRequest) bool {
+	return req.Principal == nil || req.Principal.Role.Allows(security.RoleAdmin)
+}
+
+// actionInput is the task an action starts: it comes from and answers the
+// action's connection, as text typed into the kiosk would.
+func actionInput(req genui.ActionRequest, text string) *senses.UnifiedInput {
+	input := senses.NewUnifiedInput(senses.SourceAPI, text)
+	if req.Principal != nil {
+		input.SourceMeta.Sender = req.Principal.Name
+	}
+	input.ResponseChannel = "ws"
+	input.CorrelationID = req.ConnID
+	input.SessionID = "ws_" + req.ConnID
+	input.SourceMeta.Extra = map[string]string{"action": req.ActionID}
+	return input
+}
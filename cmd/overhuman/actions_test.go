@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/approvals"
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/observability"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/versioning"
+)
+
+func TestKioskActions(t *testing.T) {
+	logs := observability.NewLogBuffer(0)
+	logs.Write([]byte("2026/10/15 10:00:00 [daemon] started\n"))
+	skills := instruments.NewSkillRegistry()
+	skills.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "sk_inv", Name: "Invoice parser", Description: "Parse the invoice", Status: instruments.SkillStatusActive}})
+	vc := versioning.New()
+	restored := ""
+	vc.SetRollback(versioning.ChangeSkill, func(ch versioning.Change) error {
+		restored = ch.EntityID
+		return nil
+	})
+	ch := vc.Record(versioning.Change{Type: versioning.ChangeSkill, EntityID: "sk_inv", RollbackData: "active"})
+
+	pending := approvals.New(0)
+	requested := make(chan approvals.Approval, 1)
+	pending.OnRequest(func(a approvals.Approval) { requested <- a })
+
+	var submitted []*senses.UnifiedInput
+	actions := kioskActions(logs, "", vc, skills, pending, func(in *senses.UnifiedInput) bool {
+		submitted = append(submitted, in)
+		return true
+	})
+	member := &security.Principal{Name: "alice", Role: security.RoleMember}
+	dispatch := func(id, data string, who *security.Principal) genui.WSActionResultPayload {
+		return actions.Dispatch(context.Background(), genui.ActionRequest{ConnID: "c1", ActionID: id, Data: json.RawMessage(data), Principal: who})
+	}
+
+	if res := dispatch("view_logs", "", nil); !res.Success || !strings.Contains(res.Result, "started") {
+		t.Errorf("view_logs = %+v", res)
+	}
+	if res := dispatch("view_logs", "", member); res.Success {
+		t.Error("view_logs by a member succeeded")
+	}
+
+	if res := dispatch("rollback", `{"change_id":"`+ch.ID+`"}`, member); res.Success {
+		t.Error("rollback by a member succeeded")
+	}
+	if res := dispatch("rollback:"+ch.ID, "", nil); !res.Success || restored != "sk_inv" {
+		t.Errorf("rollback = %+v, restored %q", res, restored)
+	}
+	if res := dispatch("rollback:nope", "", nil); res.Success {
+		t.Error("rollback of an unknown change succeeded")
+	}
+
+	// A parked task is told once an admin approves it from the kiosk.
+	waited := make(chan approvals.Approval, 1)
+	pending.OnDecide(func(a approvals.Approval) { waited <- a })
+	pending.Request(approvals.Approval{Action: "run skill Deploy (sk_dep)"})
+	apr := <-requested
+	if res := dispatch("approve:"+apr.ID, "", member); res.Success {
+		t.Error("approval by a member succeeded")
+	}
+	if res := dispatch("approve:"+apr.ID, `{"note":"go ahead"}`, nil); !res.Success {
+		t.Errorf("approve = %+v", res)
+	}
+	if a := <-waited; a.Status != approvals.StatusApproved || a.Note != "go ahead" {
+		t.Errorf("approval = %+v", a)
+	}
+	if res := dispatch("deny:"+apr.ID, "", nil); res.Success {
+		t.Error("deciding an approval twice succeeded")
+	}
+
+	if res := dispatch("skill:sk_inv", `{"input":"INV-7"}`, member); !res.Success || res.Result != "Running Invoice parser…" {
+		t.Errorf("skill = %+v", res)
+	}
+	if res := dispatch("skill:nope", "", member); res.Success {
+		t.Error("unknown skill succeeded")
+	}
+	if res := dispatch("compare_prices", `{"item":"kettle"}`, member); !res.Success {
+		t.Errorf("custom action = %+v", res)
+	}
+
+	if len(submitted) != 2 {
+		t.Fatalf("submitted %d tasks, want 2", len(submitted))
+	}
+	in := submitted[0]
+	if in.Payload != "Parse the invoice\n\nINV-7" || in.CorrelationID != "c1" || in.ResponseChannel != "ws" || in.SourceMeta.Sender != "alice" {
+		t.Errorf("skill input = %+v", in)
+	}
+	if got := submitted[1].Payload; !strings.Contains(got, `"compare_prices"`) || !strings.Contains(got, "kettle") {
+		t.Errorf("custom action payload = %q", got)
+	}
+
+	// An admin token guards the logs from clients without a principal.
+	guarded := kioskActions(logs, "secret", vc, skills, pending, func(*senses.UnifiedInput) bool { return true })
+	if res := guarded.Dispatch(context.Background(), genui.ActionRequest{ActionID: "view_logs"}); res.Success {
+		t.Error("view_logs without a principal succeeded despite the admin token")
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/approvals"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/netaddr"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/webhook"
+)
+
+// approvalSettings is the "approvals" block of config.json.
+type approvalSettings struct {
+	// Permissions lists the skill permissions whose every use waits for
+	// an admin's approval, e.g. ["process", "external_write"].
+	Permissions []string `json:"permissions,omitempty"`
+	// Timeout is how long a task waits for a decision before it gives up,
+	// e.g. "30m" (default 1h).
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// defaultApprovalTimeout is how long a task waits for an approval.
+const defaultApprovalTimeout = time.Hour
+
+// approvalPermissions are the permissions approvals may be required for.
+var approvalPermissions = []string{
+	instruments.PermNetwork, instruments.PermFilesystem, instruments.PermProcess,
+	instruments.PermEnv, instruments.PermStorage, instruments.PermSecrets,
+	instruments.PermExternalWrite,
+}
+
+// validate checks the permissions and the timeout.
+func (s approvalSettings) validate() error {
+	for _, perm := range s.Permissions {
+		if !slices.Contains(approvalPermissions, perm) {
+			return fmt.Errorf("unknown permission %q (one of %s)", perm, strings.Join(approvalPermissions, ", "))
+		}
+	}
+	if s.Timeout != "" {
+		if d, err := time.ParseDuration(s.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("timeout %q is not a positive duration like 30m", s.Timeout)
+		}
+	}
+	return nil
+}
+
+// timeout returns how long a task waits for a decision.
+func (s approvalSettings) timeout() time.Duration {
+	if d, err := time.ParseDuration(s.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultApprovalTimeout
+}
+
+// approvalNotice tells the admins what waits for them.
+func approvalNotice(a approvals.Approval) string {
+	msg := fmt.Sprintf("Approval needed (%s): %s", a.ID, a.Action)
+	if a.Detail != "" {
+		msg += " — " + a.Detail
+	}
+	return msg + ". Approve or deny it under Approvals, or with /approve " + a.ID + " or /deny " + a.ID + "."
+}
+
+// formatApprovals lists approvals one per line for the CLI.
+func formatApprovals(list []approvals.Approval) string {
+	if len(list) == 0 {
+		return "No approvals waiting."
+	}
+	var b strings.Builder
+	for _, a := range list {
+		fmt.Fprintf(&b, "%s  %-8s %s  %s", a.ID, a.Status, a.CreatedAt.Local().Format("2006-01-02 15:04"), a.Action)
+		if a.Requester != "" {
+			fmt.Fprintf(&b, " (for %s)", a.Requester)
+		}
+		if a.Detail != "" {
+			fmt.Fprintf(&b, "\n    %s", a.Detail)
+		}
+		if a.DecidedBy != "" {
+			fmt.Fprintf(&b, "\n    %s by %s", a.Status, a.DecidedBy)
+		}
+		if a.Note != "" {
+			fmt.Fprintf(&b, "\n    note: %s", a.Note)
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// approvalsCommand runs an approvals command against the daemon's kiosk
+// server at addr and returns what to print:
+//
+//	list [all]          the pending (or recent) approvals
+//	approve ID [NOTE]   let the waiting task go on
+//	deny ID [NOTE]      end the waiting task
+func approvalsCommand(client *http.Client, addr, adminToken string, args []string) (string, error) {
+	sub := "list"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	var req *http.Request
+	switch {
+	case sub == "list":
+		path := "/api/approvals"
+		if len(args) > 1 && args[1] == "all" {
+			path += "?status=all"
+		}
+		req, _ = http.NewRequest(http.MethodGet, netaddr.URL(addr, path), nil)
+	case (sub == "approve" || sub == "deny") && len(args) > 1:
+		body, _ := json.Marshal(map[string]string{"note": strings.Join(args[2:], " ")})
+		req, _ = http.NewRequest(http.MethodPost, netaddr.URL(addr, "/api/approvals/"+args[1]+"/"+sub), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	default:
+		return "", fmt.Errorf("usage: approvals [list [all]|approve ID [NOTE]|deny ID [NOTE]]")
+	}
+	if adminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("daemon is not answering at %s: %w", addr, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			return "", fmt.Errorf("%s", e.Error)
+		}
+		return "", fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if sub == "list" {
+		var res struct {
+			Approvals []approvals.Approval `json:"approvals"`
+		}
+		if err := json.Unmarshal(data, &res); err != nil {
+			return "", fmt.Errorf("bad response: %w", err)
+		}
+		return formatApprovals(res.Approvals), nil
+	}
+	var a approvals.Approval
+	if err := json.Unmarshal(data, &a); err != nil {
+		return "", fmt.Errorf("bad response: %w", err)
+	}
+	return fmt.Sprintf("%s %s: %s", a.ID, a.Status, a.Action), nil
+}
+
+// cliApprovalCommand runs the /approvals, /approve and /deny lines of a
+// remote CLI session, and reports whether line was one.
+func cliApprovalCommand(client *http.Client, addr, adminToken, line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false
+	}
+	var args []string
+	switch fields[0] {
+	case "/approvals":
+		args = append([]string{"list"}, fields[1:]...)
+	case "/approve", "/deny":
+		args = append([]string{fields[0][1:]}, fields[1:]...)
+	default:
+		return "", false
+	}
+	out, err := approvalsCommand(client, addr, adminToken, args)
+	if err != nil {
+		return "Error: " + err.Error(), true
+	}
+	return out, true
+}
+
+// runApprovals handles `overhuman approvals [list [all]|approve ID
+// [NOTE]|deny ID [NOTE]]` against the running daemon.
+func runApprovals(args []string) {
+	cfg, addr, args := daemonAddr(args)
+	kiosk := deriveKioskAddr(addr)
+	out, err := approvalsCommand(netaddr.HTTPClient(kiosk, 10*time.Second), kiosk, cfg.AdminToken, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
+
+// cliApprovalPrompter asks for approvals inline in `overhuman cli`, where
+// the pipeline runs on the CLI loop's goroutine: like the permission
+// prompt, the next line the user types is the answer.
+func cliApprovalPrompter(store *approvals.Store, w io.Writer, answers <-chan *senses.UnifiedInput) func(approvals.Approval) {
+	return func(a approvals.Approval) {
+		fmt.Fprintf(w, "\n%s", a.Action)
+		if a.Detail != "" {
+			fmt.Fprintf(w, " (%s)", a.Detail)
+		}
+		fmt.Fprint(w, " needs your approval. Approve? [yes/no] ")
+		for i := 0; i < cliPermissionAttempts; i++ {
+			in, ok := <-answers
+			if !ok {
+				return
+			}
+			switch strings.ToLower(strings.TrimSpace(in.Payload)) {
+			case "y", "yes", "approve":
+				store.Decide(a.ID, true, "cli", "")
+				return
+			case "n", "no", "deny":
+				store.Decide(a.ID, false, "cli", "")
+				return
+			}
+			fmt.Fprint(w, "Please answer yes or no: ")
+		}
+		fmt.Fprintln(w, "No valid answer — denied.")
+		store.Decide(a.ID, false, "cli", "no valid answer")
+	}
+}
+
+// announceApprovals tells the admins about new approvals: in the log, on
+// the kiosk and to the webhooks.
+func announceApprovals(store *approvals.Store, notify func(msg string), hooks *webhook.Dispatcher) {
+	store.OnRequest(func(a approvals.Approval) {
+		log.Printf("[approvals] %s waits for approval: %s", a.ID, a.Action)
+		notify(approvalNotice(a))
+		hooks.Emit(webhook.EventApprovalRequested, map[string]any{
+			"kind":      "action",
+			"id":        a.ID,
+			"task_id":   a.TaskID,
+			"action":    a.Action,
+			"detail":    a.Detail,
+			"requester": a.Requester,
+		})
+	})
+}
+
+// parkedTasks holds the inputs of tasks parked until an approval is
+// decided, by approval ID, and queues each again once its approval is
+// approved, denied or expired; the re-run goes on or ends accordingly.
+// Parked tasks hold no dispatcher worker.
+type parkedTasks struct {
+	store   *approvals.Store
+	requeue func(*senses.UnifiedInput)
+
+	mu     sync.Mutex
+	inputs map[string]*senses.UnifiedInput
+}
+
+func newParkedTasks(store *approvals.Store, requeue func(*senses.UnifiedInput)) *parkedTasks {
+	pt := &parkedTasks{store: store, requeue: requeue, inputs: make(map[string]*senses.UnifiedInput)}
+	store.OnDecide(func(a approvals.Approval) { pt.resume(a.ID) })
+	return pt
+}
+
+// park keeps in until the approval id is decided. An approval decided
+// while the task was still ending resumes it at once.
+func (pt *parkedTasks) park(id string, in *senses.UnifiedInput) {
+	pt.mu.Lock()
+	pt.inputs[id] = in
+	pt.mu.Unlock()
+	if a, ok := pt.store.Get(id); !ok || a.Status != approvals.StatusPending {
+		pt.resume(id)
+	}
+}
+
+// resume queues the input parked on id again, if there is one.
+func (pt *parkedTasks) resume(id string) {
+	pt.mu.Lock()
+	in, ok := pt.inputs[id]
+	delete(pt.inputs, id)
+	pt.mu.Unlock()
+	if !ok {
+		return
+	}
+	pipeline.ResumeWithApproval(in, id)
+	log.Printf("[approvals] %s decided: running %s input %s again", id, in.SourceType, in.InputID)
+	pt.requeue(in)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/approvals"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+func TestParkedTasks(t *testing.T) {
+	store := approvals.New(0)
+	requeued := make(chan *senses.UnifiedInput, 2)
+	parked := newParkedTasks(store, func(in *senses.UnifiedInput) { requeued <- in })
+
+	a := store.Request(approvals.Approval{Action: "run skill Deploy (sk_dep)"})
+	in := senses.NewFromText("deploy the site")
+	parked.park(a.ID, in)
+	if len(requeued) != 0 {
+		t.Fatal("requeued before the decision")
+	}
+	store.Decide(a.ID, true, "bob", "")
+	if got := <-requeued; got != in || got.SourceMeta.Extra[pipeline.ExtraApprovals] != a.ID {
+		t.Errorf("requeued = %+v", got)
+	}
+
+	// Decided before the task finished parking: queued again at once.
+	b := store.Request(approvals.Approval{Action: "run skill Deploy (sk_dep)"})
+	store.Decide(b.ID, false, "bob", "")
+	parked.park(b.ID, in)
+	if got := <-requeued; got.SourceMeta.Extra[pipeline.ExtraApprovals] != a.ID+","+b.ID {
+		t.Errorf("approvals = %q", got.SourceMeta.Extra[pipeline.ExtraApprovals])
+	}
+	store.Decide(b.ID, true, "bob", "")
+	if len(requeued) != 0 {
+		t.Error("requeued twice")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// artifactStore serves the files kept from runs (<data dir>/artifacts/<task>/)
+// at GET /artifacts/{task}/{file...}. Links carry an HMAC signature instead
+// of a token, so the recipient of an e-mail or chat reply can open them.
+type artifactStore struct {
+	dir  string
+	base string // public kiosk URL, without a trailing slash
+	key  []byte
+}
+
+// newArtifactStore serves dir, signing links with the key in keyFile
+// (created on first use).
+func newArtifactStore(dir, keyFile, base string) (*artifactStore, error) {
+	key, err := os.ReadFile(keyFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(keyFile, key, 0o600); err != nil {
+			return nil, fmt.Errorf("artifact key: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("artifact key: %w", err)
+	}
+	return &artifactStore{dir: dir, base: strings.TrimRight(base, "/"), key: key}, nil
+}
+
+func (s *artifactStore) sign(task, name string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(task + "/" + name))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedPath returns the signed path of file name of task's artifacts.
+func (s *artifactStore) signedPath(task, name string) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return "/artifacts/" + url.PathEscape(task) + "/" + strings.Join(parts, "/") + "?sig=" + s.sign(task, name)
+}
+
+// Link returns the public download URL of att, a file of task.
+func (s *artifactStore) Link(task string) func(senses.Attachment) string {
+	return func(att senses.Attachment) string { return s.base + s.signedPath(task, att.Name) }
+}
+
+// Attachments returns the files kept from result's run. A nil store has
+// none.
+func (s *artifactStore) Attachments(result *pipeline.RunResult) []senses.Attachment {
+	if s == nil || result == nil || result.ArtifactsDir == "" {
+		return nil
+	}
+	var atts []senses.Attachment
+	for _, name := range result.Artifacts {
+		p := filepath.Join(result.ArtifactsDir, filepath.FromSlash(name))
+		info, err := os.Stat(p)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		atts = append(atts, senses.Attachment{
+			Name: name,
+			Type: mime.TypeByExtension(path.Ext(name)),
+			Size: info.Size(),
+			Path: p,
+		})
+	}
+	return atts
+}
+
+// Downloads lists the files kept from result's run for the kiosk.
+func (s *artifactStore) Downloads(result *pipeline.RunResult) []genui.Download {
+	var out []genui.Download
+	for _, att := range s.Attachments(result) {
+		out = append(out, genui.Download{Name: att.Name, URL: s.signedPath(result.TaskID, att.Name), Type: att.Type, Size: att.Size})
+	}
+	return out
+}
+
+// ServeHTTP serves GET /artifacts/{task}/{file...} as a download.
+func (s *artifactStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	task, name := r.PathValue("task"), r.PathValue("file")
+	sig, _ := hex.DecodeString(r.URL.Query().Get("sig"))
+	want, _ := hex.DecodeString(s.sign(task, name))
+	if !hmac.Equal(sig, want) {
+		http.Error(w, "invalid or missing signature", http.StatusForbidden)
+		return
+	}
+	clean := path.Clean("/" + name)[1:]
+	if task == "" || task == "." || task == ".." || strings.ContainsAny(task, `/\`) || clean == "" || clean != name {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(filepath.Join(s.dir, task, filepath.FromSlash(clean)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(clean)}))
+	http.ServeContent(w, r, clean, info.ModTime(), f)
+}
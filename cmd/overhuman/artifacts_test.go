@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+func TestArtifactStore(t *testing.T) {
+	dir := t.TempDir()
+	taskDir := filepath.Join(dir, "artifacts", "task_1")
+	os.MkdirAll(filepath.Join(taskDir, "out"), 0o700)
+	os.WriteFile(filepath.Join(taskDir, "out", "report.csv"), []byte("a,b\n1,2\n"), 0o600)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600)
+
+	store, err := newArtifactStore(filepath.Join(dir, "artifacts"), filepath.Join(dir, "artifacts.key"), "https://agent.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &pipeline.RunResult{TaskID: "task_1", ArtifactsDir: taskDir, Artifacts: []string{"out/report.csv"}}
+	atts := store.Attachments(result)
+	if len(atts) != 1 || atts[0].Name != "out/report.csv" || atts[0].Size != 8 || !strings.HasPrefix(atts[0].Type, "text/csv") {
+		t.Fatalf("Attachments = %+v", atts)
+	}
+	link := store.Link("task_1")(atts[0])
+	if !strings.HasPrefix(link, "https://agent.example.com/artifacts/task_1/out/report.csv?sig=") {
+		t.Fatalf("link = %q", link)
+	}
+	if d := store.Downloads(result); len(d) != 1 || d[0].URL != strings.TrimPrefix(link, "https://agent.example.com") {
+		t.Errorf("Downloads = %+v", d)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /artifacts/{task}/{file...}", store)
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+		return rec
+	}
+	rec := get(strings.TrimPrefix(link, "https://agent.example.com"))
+	if body, _ := io.ReadAll(rec.Body); rec.Code != 200 || string(body) != "a,b\n1,2\n" {
+		t.Fatalf("signed download: %d %q", rec.Code, body)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename=report.csv` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if rec := get("/artifacts/task_1/out/report.csv"); rec.Code != http.StatusForbidden {
+		t.Errorf("unsigned download: %d", rec.Code)
+	}
+	if rec := get("/artifacts/task_1/out/report.csv?sig=00" + strings.Repeat("ab", 31)); rec.Code != http.StatusForbidden {
+		t.Errorf("forged signature: %d", rec.Code)
+	}
+	// A valid signature for a path outside the artifacts is still refused.
+	if rec := get("/artifacts/task_1/..%2F..%2Fsecret.txt?sig=" + store.sign("task_1", "../../secret.txt")); rec.Code != http.StatusNotFound {
+		t.Errorf("escaping path: %d", rec.Code)
+	}
+
+	// The key persists, so links survive a restart.
+	again, _ := newArtifactStore(filepath.Join(dir, "artifacts"), filepath.Join(dir, "artifacts.key"), "https://agent.example.com")
+	if again.Link("task_1")(atts[0]) != link {
+		t.Error("signing key changed across restarts")
+	}
+	var none *artifactStore
+	if none.Attachments(result) != nil || none.Downloads(result) != nil {
+		t.Error("a nil store should have no attachments")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/automation"
+	"github.com/overhuman/overhuman/internal/mcp"
+)
+
+// runAutomations handles `overhuman automations`: it lists the rules the
+// daemon would load, failing with the parse error when automations.yaml is
+// invalid.
+func runAutomations() {
+	cfg := loadConfig()
+	e, err := automation.New(cfg.DataDir, automation.Handlers{Templates: cfg.Templates})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	rules := e.Rules()
+	if len(rules) == 0 {
+		fmt.Printf("No automation rules. Add them to %s or POST /api/automations.\n", filepath.Join(cfg.DataDir, automation.FileName))
+		return
+	}
+	for _, r := range rules {
+		state := ""
+		if r.Disabled {
+			state = " (disabled)"
+		}
+		fmt.Printf("%s [%s]%s\n  when %s\n", r.Name, r.Source, state, r.When)
+		for _, a := range r.Then {
+			fmt.Printf("  then %s\n", describeAction(a))
+		}
+		if r.Consume {
+			fmt.Println("  (matched inputs skip the pipeline)")
+		}
+	}
+}
+
+func describeAction(a automation.Action) string {
+	switch {
+	case a.Template != "":
+		return "run template " + a.Template
+	case a.Run != "":
+		return fmt.Sprintf("run %q", a.Run)
+	case a.Skill != "":
+		return "call skill " + a.Skill + describeParams(a.Params)
+	case a.Tool != "":
+		return "call tool " + a.Tool + describeParams(a.Params)
+	}
+	return fmt.Sprintf("notify %q", a.Notify)
+}
+
+func describeParams(p automation.Params) string {
+	if len(p) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return " (" + strings.Join(keys, ", ") + ")"
+}
+
+// connectMCP registers the configured MCP servers and connects those
+// marked auto_connect. It returns nil when none are configured.
+func connectMCP(ctx context.Context, servers []mcp.ServerConfig) *mcp.Registry {
+	if len(servers) == 0 {
+		return nil
+	}
+	reg := mcp.NewRegistry()
+	for _, sc := range servers {
+		reg.Add(sc)
+	}
+	for _, err := range reg.ConnectAll(ctx) {
+		log.Printf("[mcp] %v", err)
+	}
+	log.Printf("[mcp] %d/%d server(s) connected", reg.ConnectedCount(), reg.Count())
+	return reg
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/automation"
+)
+
+func TestDescribeAction(t *testing.T) {
+	for _, tc := range []struct {
+		a    automation.Action
+		want string
+	}{
+		{automation.Action{Template: "digest"}, "run template digest"},
+		{automation.Action{Skill: "skill_issues", Params: automation.Params{"title": "x", "action": "create"}}, "call skill skill_issues (action, title)"},
+		{automation.Action{Tool: "fs/read_file"}, "call tool fs/read_file"},
+		{automation.Action{Notify: "done"}, `notify "done"`},
+	} {
+		if got := describeAction(tc.a); got != tc.want {
+			t.Errorf("describeAction(%+v) = %q, want %q", tc.a, got, tc.want)
+		}
+	}
+}
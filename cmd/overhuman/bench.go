@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// benchOptions configures `overhuman bench`.
+type benchOptions struct {
+	RPS      float64       // target inputs per second
+	Duration time.Duration // how long to generate traffic
+	Provider string        // LLM provider for the run (default "fake")
+	Workers  int           // concurrent pipeline runs
+	Keep     bool          // keep the temporary data directory
+	Verbose  bool          // keep daemon logging enabled
+}
+
+// parseBenchArgs parses the flags of `bench`.
+func parseBenchArgs(args []string) (benchOptions, error) {
+	opts := benchOptions{RPS: 5, Duration: time.Minute, Provider: "fake", Workers: 8}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--keep":
+			opts.Keep = true
+			continue
+		case "--verbose", "-v":
+			opts.Verbose = true
+			continue
+		case "--rps", "--duration", "--provider", "--workers":
+		default:
+			return opts, fmt.Errorf("unknown flag %q", args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--rps":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil || f <= 0 {
+				return opts, fmt.Errorf("--rps: want a positive number, got %q", value)
+			}
+			opts.RPS = f
+		case "--duration":
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return opts, fmt.Errorf("--duration: want a positive duration like 30s or 10m, got %q", value)
+			}
+			opts.Duration = d
+		case "--provider":
+			opts.Provider = value
+		case "--workers":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return opts, fmt.Errorf("--workers: want a positive number, got %q", value)
+			}
+			opts.Workers = n
+		}
+	}
+	return opts, nil
+}
+
+// benchSources are the senses synthetic traffic claims to come from.
+var benchSources = []struct {
+	Type    senses.SourceType
+	Channel string
+}{
+	{senses.SourceText, "text"},
+	{senses.SourceAPI, "api"},
+	{senses.SourceTelegram, "telegram"},
+	{senses.SourceSlack, "slack"},
+	{senses.SourceEmail, "email"},
+	{senses.SourceWebhook, "webhook"},
+	{senses.SourceTimer, "timer"},
+}
+
+// benchPriorities is weighted towards normal traffic.
+var benchPriorities = []senses.Priority{
+	senses.PriorityNormal, senses.PriorityNormal, senses.PriorityNormal,
+	senses.PriorityLow, senses.PriorityHigh, senses.PriorityCritical,
+}
+
+// benchTasks are the payload templates. A few repeat verbatim so pattern
+// tracking and skill generation get exercised, the rest vary per input.
+var benchTasks = []string{
+	"Summarize the following note: %s",
+	"Translate to French: %s",
+	"Extract action items from: %s",
+	"What is the capital of France?",
+	"Draft a short reply to this message: %s",
+	"Convert 100 USD to EUR",
+}
+
+var benchWords = strings.Fields("meeting budget release deploy invoice customer roadmap outage review hiring quarterly migration latency backlog")
+
+// syntheticInput builds the n-th synthetic input.
+func syntheticInput(n int, rng *rand.Rand) senses.UnifiedInput {
+	src := benchSources[rng.Intn(len(benchSources))]
+	task := benchTasks[rng.Intn(len(benchTasks))]
+	if strings.Contains(task, "%s") {
+		words := make([]string, 6+rng.Intn(20))
+		for i := range words {
+			words[i] = benchWords[rng.Intn(len(benchWords))]
+		}
+		task = fmt.Sprintf(task, strings.Join(words, " "))
+	}
+	in := senses.NewFromText(task)
+	in.SourceType = src.Type
+	in.SourceMeta.Channel = src.Channel
+	in.SourceMeta.Sender = fmt.Sprintf("bench-user-%d", rng.Intn(10))
+	in.Priority = benchPriorities[rng.Intn(len(benchPriorities))]
+	in.SessionID = fmt.Sprintf("bench_%d", n%20)
+	return *in
+}
+
+// benchStats collects results from concurrent runs.
+type benchStats struct {
+	mu        sync.Mutex
+	completed int
+	failed    int
+	dropped   int // inputs not accepted because every worker was busy
+	latencies []float64
+	stages    map[string][]float64
+	order     map[string]int // stage name → stage number, for reporting
+	errors    map[string]int
+}
+
+func newBenchStats() *benchStats {
+	return &benchStats{
+		stages: make(map[string][]float64),
+		order:  make(map[string]int),
+		errors: make(map[string]int),
+	}
+}
+
+func (s *benchStats) record(res *pipeline.RunResult, dur time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failed++
+		msg := err.Error()
+		if len(msg) > 80 {
+			msg = msg[:80]
+		}
+		s.errors[msg]++
+		return
+	}
+	s.completed++
+	s.latencies = append(s.latencies, float64(dur.Microseconds())/1000)
+	for _, sl := range res.StageLogs {
+		s.stages[sl.Name] = append(s.stages[sl.Name], float64(sl.DurMs))
+		s.order[sl.Name] = sl.Number
+	}
+}
+
+func (s *benchStats) drop() {
+	s.mu.Lock()
+	s.dropped++
+	s.mu.Unlock()
+}
+
+// percentile returns the p-th percentile (0-100) of values using the
+// nearest-rank method. values is sorted in place.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(values) {
+		rank = len(values)
+	}
+	return values[rank-1]
+}
+
+// benchStage is the latency summary of one pipeline stage.
+type benchStage struct {
+	Number int
+	Name   string
+	Count  int
+	P50Ms  float64
+	P95Ms  float64
+}
+
+// benchReport is the outcome of a bench run.
+type benchReport struct {
+	Provider   string
+	TargetRPS  float64
+	Elapsed    time.Duration
+	Sent       int
+	Completed  int
+	Failed     int
+	Dropped    int
+	Throughput float64 // completed runs per second
+	P50Ms      float64
+	P95Ms      float64
+	P99Ms      float64
+	Stages     []benchStage
+	Errors     map[string]int
+
+	HeapStart      uint64
+	HeapEnd        uint64
+	HeapPeak       uint64
+	GoroutineStart int
+	GoroutineEnd   int
+	DBBytes        int64
+}
+
+func (s *benchStats) report() benchReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := benchReport{
+		Completed: s.completed,
+		Failed:    s.failed,
+		Dropped:   s.dropped,
+		P50Ms:     percentile(s.latencies, 50),
+		P95Ms:     percentile(s.latencies, 95),
+		P99Ms:     percentile(s.latencies, 99),
+		Errors:    make(map[string]int, len(s.errors)),
+	}
+	for name, durs := range s.stages {
+		r.Stages = append(r.Stages, benchStage{
+			Number: s.order[name],
+			Name:   name,
+			Count:  len(durs),
+			P50Ms:  percentile(durs, 50),
+			P95Ms:  percentile(durs, 95),
+		})
+	}
+	sort.Slice(r.Stages, func(i, j int) bool { return r.Stages[i].Number < r.Stages[j].Number })
+	for k, v := range s.errors {
+		r.Errors[k] = v
+	}
+	return r
+}
+
+// heapInUse forces a GC and returns the live heap, so start and end
+// measurements compare retained memory rather than garbage.
+func heapInUse() uint64 {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
+
+// dbSize returns the size of the SQLite database including its WAL.
+func dbSize(dataDir string) int64 {
+	var total int64
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if fi, err := os.Stat(filepath.Join(dataDir, "overhuman.db"+suffix)); err == nil {
+			total += fi.Size()
+		}
+	}
+	return total
+}
+
+// runBenchLoad generates synthetic traffic against p at opts.RPS for
+// opts.Duration and reports what happened. Inputs arriving while every
+// worker is busy are dropped and counted rather than queued, so the target
+// rate stays honest. progress, if set, is called about every 10 seconds.
+func runBenchLoad(ctx context.Context, p *pipeline.Pipeline, dataDir string, opts benchOptions, progress func(benchReport)) benchReport {
+	stats := newBenchStats()
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	heapStart := heapInUse()
+	goroutineStart := runtime.NumGoroutine()
+	var heapPeak uint64
+
+	jobs := make(chan senses.UnifiedInput)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for in := range jobs {
+				start := time.Now()
+				res, err := p.Run(ctx, in)
+				stats.record(res, time.Since(start), err)
+			}
+		}()
+	}
+
+	interval := time.Duration(float64(time.Second) / opts.RPS)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	sample := time.NewTicker(time.Second)
+	defer sample.Stop()
+
+	start := time.Now()
+	deadline := time.NewTimer(opts.Duration)
+	defer deadline.Stop()
+	sent, lastProgress := 0, start
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-deadline.C:
+			break loop
+		case now := <-sample.C:
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			if ms.HeapAlloc > heapPeak {
+				heapPeak = ms.HeapAlloc
+			}
+			if progress != nil && now.Sub(lastProgress) >= 10*time.Second {
+				lastProgress = now
+				r := stats.report()
+				r.Sent, r.Elapsed = sent, now.Sub(start)
+				progress(r)
+			}
+		case <-tick.C:
+			select {
+			case jobs <- syntheticInput(sent, rng):
+			default:
+				stats.drop()
+			}
+			sent++
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	r := stats.report()
+	r.Provider = opts.Provider
+	r.TargetRPS = opts.RPS
+	r.Elapsed = elapsed
+	r.Sent = sent
+	if secs := elapsed.Seconds(); secs > 0 {
+		r.Throughput = float64(r.Completed) / secs
+	}
+	r.HeapStart = heapStart
+	r.HeapEnd = heapInUse()
+	r.HeapPeak = max(heapPeak, r.HeapEnd)
+	r.GoroutineStart = goroutineStart
+	r.GoroutineEnd = runtime.NumGoroutine()
+	r.DBBytes = dbSize(dataDir)
+	return r
+}
+
+// formatBytes renders a byte count with a binary unit.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatBenchReport renders the final bench summary.
+func formatBenchReport(r benchReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bench: provider %s, target %.1f rps, %s\n", r.Provider, r.TargetRPS, r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "  Inputs:      %d sent, %d completed, %d failed, %d dropped (workers busy)\n", r.Sent, r.Completed, r.Failed, r.Dropped)
+	fmt.Fprintf(&b, "  Throughput:  %.2f runs/s\n", r.Throughput)
+	fmt.Fprintf(&b, "  Latency:     p50 %.1fms  p95 %.1fms  p99 %.1fms\n", r.P50Ms, r.P95Ms, r.P99Ms)
+	if len(r.Stages) > 0 {
+		fmt.Fprintln(&b, "  Stages:")
+		for _, s := range r.Stages {
+			fmt.Fprintf(&b, "    %2d %-17s p50 %6.0fms  p95 %6.0fms  (%d runs)\n", s.Number, s.Name, s.P50Ms, s.P95Ms, s.Count)
+		}
+	}
+	growth := int64(r.HeapEnd) - int64(r.HeapStart)
+	sign := "+"
+	if growth < 0 {
+		sign, growth = "-", -growth
+	}
+	fmt.Fprintf(&b, "  Heap:        %s → %s (%s%s, peak %s)\n", formatBytes(r.HeapStart), formatBytes(r.HeapEnd), sign, formatBytes(uint64(growth)), formatBytes(r.HeapPeak))
+	fmt.Fprintf(&b, "  Goroutines:  %d → %d\n", r.GoroutineStart, r.GoroutineEnd)
+	fmt.Fprintf(&b, "  Database:    %s\n", formatBytes(uint64(r.DBBytes)))
+	if len(r.Errors) > 0 {
+		msgs := make([]string, 0, len(r.Errors))
+		for m := range r.Errors {
+			msgs = append(msgs, m)
+		}
+		sort.Slice(msgs, func(i, j int) bool { return r.Errors[msgs[i]] > r.Errors[msgs[j]] })
+		fmt.Fprintln(&b, "  Errors:")
+		for _, m := range msgs {
+			fmt.Fprintf(&b, "    %5d× %s\n", r.Errors[m], m)
+		}
+	}
+	return b.String()
+}
+
+// runBench handles `overhuman bench [--rps N] [--duration D] [--provider P]
+// [--workers N] [--keep] [--verbose]`: an in-process daemon on a throwaway
+// data directory, fed synthetic inputs across senses and priorities.
+func runBench(args []string) {
+	opts, err := parseBenchArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		fmt.Fprintf(os.Stderr, "usage: %s bench [--rps N] [--duration 10m] [--provider fake] [--workers N] [--keep] [--verbose]\n", appName)
+		os.Exit(1)
+	}
+
+	dataDir, err := os.MkdirTemp("", "overhuman-bench-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		os.Exit(1)
+	}
+	if !opts.Keep {
+		defer os.RemoveAll(dataDir)
+	}
+
+	cfg := loadConfig()
+	cfg.DataDir = dataDir
+	cfg.LLMProvider = opts.Provider
+	if !opts.Verbose {
+		log.SetOutput(io.Discard)
+	}
+	deps, _, _, err := bootstrap(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bench: bootstrap: %v\n", err)
+		os.Exit(1)
+	}
+	defer deps.LongTerm.Close()
+	p := pipeline.New(deps)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Benchmarking %s at %.1f rps for %s with %d workers (data: %s)\n", opts.Provider, opts.RPS, opts.Duration, opts.Workers, dataDir)
+	r := runBenchLoad(ctx, p, dataDir, opts, func(r benchReport) {
+		fmt.Printf("  %s: %d sent, %d completed, %d failed, %d dropped, p95 %.1fms\n",
+			r.Elapsed.Round(time.Second), r.Sent, r.Completed, r.Failed, r.Dropped, r.P95Ms)
+	})
+	fmt.Print(formatBenchReport(r))
+	if opts.Keep {
+		fmt.Printf("Data kept in %s\n", dataDir)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+func TestParseBenchArgs(t *testing.T) {
+	opts, err := parseBenchArgs(nil)
+	if err != nil || opts.RPS != 5 || opts.Duration != time.Minute || opts.Provider != "fake" || opts.Workers != 8 {
+		t.Fatalf("defaults = %+v, %v", opts, err)
+	}
+	opts, err = parseBenchArgs([]string{"--rps", "20", "--duration=10m", "--provider", "ollama", "--workers=2", "--keep"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.RPS != 20 || opts.Duration != 10*time.Minute || opts.Provider != "ollama" || opts.Workers != 2 || !opts.Keep {
+		t.Errorf("opts = %+v", opts)
+	}
+	for _, bad := range [][]string{{"--rps", "0"}, {"--duration", "soon"}, {"--workers"}, {"--fast"}} {
+		if _, err := parseBenchArgs(bad); err == nil {
+			t.Errorf("parseBenchArgs(%v) should fail", bad)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	vals := []float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}
+	if got := percentile(vals, 50); got != 5 {
+		t.Errorf("p50 = %v, want 5", got)
+	}
+	if got := percentile(vals, 95); got != 10 {
+		t.Errorf("p95 = %v, want 10", got)
+	}
+	if got := percentile(nil, 95); got != 0 {
+		t.Errorf("p95 of nothing = %v", got)
+	}
+}
+
+func TestRunBenchLoad(t *testing.T) {
+	t.Setenv("FAKE_LLM_SCRIPT", "")
+	dir := t.TempDir()
+	deps, _, _, err := bootstrap(Config{DataDir: dir, DefaultSpec: "general", LLMProvider: "fake"})
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	defer deps.LongTerm.Close()
+
+	opts := benchOptions{RPS: 50, Duration: 300 * time.Millisecond, Provider: "fake", Workers: 4}
+	r := runBenchLoad(context.Background(), pipeline.New(deps), dir, opts, nil)
+	if r.Sent == 0 || r.Completed == 0 {
+		t.Fatalf("report = %+v", r)
+	}
+	if r.Sent != r.Completed+r.Failed+r.Dropped {
+		t.Errorf("sent %d != completed %d + failed %d + dropped %d", r.Sent, r.Completed, r.Failed, r.Dropped)
+	}
+	if len(r.Stages) == 0 || r.Stages[0].Number != 1 {
+		t.Errorf("stages = %+v", r.Stages)
+	}
+	if r.DBBytes == 0 {
+		t.Error("database size not measured")
+	}
+
+	out := formatBenchReport(r)
+	for _, want := range []string{"Throughput:", "p95", "Heap:", "Database:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
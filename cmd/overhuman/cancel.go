@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// mayCancel reports whether who may see and cancel task t: admins and the
+// single-user daemon (no principal) any task, team members their own.
+func mayCancel(who *security.Principal, t pipeline.ActiveTask) bool {
+	return who == nil || who.Role.Allows(security.RoleAdmin) || t.Sender == who.Name
+}
+
+// cancelTasks cancels the running tasks matched by match that who may
+// cancel, and returns their IDs.
+func cancelTasks(p *pipeline.Pipeline, who *security.Principal, match func(pipeline.ActiveTask) bool) []string {
+	var ids []string
+	for _, t := range p.ActiveTasks() {
+		if match(t) && mayCancel(who, t) && p.Cancel(t.TaskID) {
+			ids = append(ids, t.TaskID)
+		}
+	}
+	return ids
+}
+
+// taskSender is who sent the running task id, "" if it is not running.
+func taskSender(p *pipeline.Pipeline, id string) string {
+	for _, t := range p.ActiveTasks() {
+		if t.TaskID == id {
+			return t.Sender
+		}
+	}
+	return ""
+}
+
+// apiActiveTasks serves GET /tasks for the API sense: the running tasks
+// the caller may cancel.
+func apiActiveTasks(p *pipeline.Pipeline) func(r *http.Request) any {
+	return func(r *http.Request) any {
+		who := requestPrincipal(r)
+		tasks := []pipeline.ActiveTask{}
+		for _, t := range p.ActiveTasks() {
+			if mayCancel(who, t) {
+				tasks = append(tasks, t)
+			}
+		}
+		return tasks
+	}
+}
+
+// apiTaskCanceller serves DELETE /tasks/{id} for the API sense.
+func apiTaskCanceller(p *pipeline.Pipeline) func(r *http.Request, id string) bool {
+	return func(r *http.Request, id string) bool {
+		return len(cancelTasks(p, requestPrincipal(r), func(t pipeline.ActiveTask) bool { return t.TaskID == id })) > 0
+	}
+}
+
+// requestPrincipal is the team member who made r, or nil in single-user
+// mode.
+func requestPrincipal(r *http.Request) *security.Principal {
+	if pr, ok := security.PrincipalFrom(r.Context()); ok {
+		return &pr
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestMayCancel(t *testing.T) {
+	task := pipeline.ActiveTask{TaskID: "task_1", Sender: "alice"}
+	for _, tc := range []struct {
+		who  *security.Principal
+		want bool
+	}{
+		{nil, true},
+		{&security.Principal{Name: "root", Role: security.RoleAdmin}, true},
+		{&security.Principal{Name: "alice", Role: security.RoleMember}, true},
+		{&security.Principal{Name: "bob", Role: security.RoleMember}, false},
+	} {
+		if got := mayCancel(tc.who, task); got != tc.want {
+			t.Errorf("mayCancel(%+v) = %v, want %v", tc.who, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// adminEndpoints are the API endpoints that require admin authorization.
+var adminEndpoints = []string{"POST /mode", "POST /shutdown"}
+
+// daemonCapabilities builds the GET /capabilities response from the running
+// daemon: registered senses, active skills and enabled instruments.
+func daemonCapabilities(cfg Config, deps pipeline.Dependencies, registry *senses.SenseRegistry) senses.Capabilities {
+	inputTypes := append(registry.SourceTypes(), senses.SourceTimer)
+	c := senses.Capabilities{
+		Version:     version,
+		Agent:       cfg.AgentName,
+		Senses:      registry.Names(),
+		InputTypes:  inputTypes,
+		Attachments: registry.AttachmentCaps(),
+		Tools:       daemonTools(deps),
+		Skills:      []senses.CapabilitySkill{},
+		UIFormats: []string{
+			string(genui.FormatHTML), string(genui.FormatReact),
+			string(genui.FormatANSI), string(genui.FormatMarkdown),
+		},
+		Endpoints: map[string]string{
+			"api":   cfg.APIAddr,
+			"ws":    deriveWSAddr(cfg.APIAddr),
+			"kiosk": deriveKioskAddr(cfg.APIAddr),
+		},
+		Auth: senses.CapabilityAuth{
+			Input:          "none",
+			Admin:          "loopback",
+			AdminEndpoints: adminEndpoints,
+		},
+		Mode: deps.Mode.Status().Mode,
+	}
+	if cfg.AdminToken != "" {
+		c.Auth.Admin = "bearer"
+	}
+	if deps.Skills != nil {
+		for _, m := range deps.Skills.Catalog() {
+			if m.Status != instruments.SkillStatusActive {
+				continue
+			}
+			c.Skills = append(c.Skills, senses.CapabilitySkill{
+				ID:          m.ID,
+				Name:        m.Name,
+				Type:        string(m.Type),
+				Description: m.Description,
+			})
+		}
+	}
+	return c
+}
+
+// daemonTools lists the optional instruments wired into the pipeline.
+func daemonTools(deps pipeline.Dependencies) []string {
+	tools := []string{}
+	if deps.Generator != nil {
+		tools = append(tools, "skill_generator")
+	}
+	if deps.Sandbox != nil {
+		tools = append(tools, "code_sandbox")
+	}
+	if deps.SubagentMgr != nil {
+		tools = append(tools, "subagents")
+	}
+	if deps.STT != nil {
+		tools = append(tools, "speech_to_text")
+	}
+	if deps.TTS != nil {
+		tools = append(tools, "text_to_speech")
+	}
+	return tools
+}
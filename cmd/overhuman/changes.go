@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/soul"
+	"github.com/overhuman/overhuman/internal/versioning"
+)
+
+// trackChanges records every soul update in the change history and teaches
+// the controller how to undo soul and skill changes. Soul changes carry the
+// previous version number as rollback data, skill changes the previous
+// status.
+func trackChanges(vc *versioning.Controller, s *soul.Soul, skills *instruments.SkillRegistry) {
+	s.OnUpdate(func(ev soul.UpdateEvent) {
+		vc.Record(versioning.Change{
+			Type:         versioning.ChangeSoul,
+			EntityID:     versioning.SoulEntity,
+			Description:  fmt.Sprintf("Soul v%d → v%d", ev.PrevVersion, ev.Version),
+			Trigger:      ev.Reason,
+			RollbackData: strconv.Itoa(ev.PrevVersion),
+			Before:       ev.Before,
+			After:        ev.After,
+		})
+	})
+	vc.SetRollback(versioning.ChangeSoul, func(ch versioning.Change) error {
+		version, err := strconv.Atoi(ch.RollbackData)
+		if err != nil {
+			return fmt.Errorf("soul change %s: bad rollback version %q", ch.ID, ch.RollbackData)
+		}
+		_, err = s.Rollback(version)
+		return err
+	})
+	vc.SetRollback(versioning.ChangeSkill, func(ch versioning.Change) error {
+		return skills.UpdateStatus(ch.EntityID, instruments.SkillStatus(ch.RollbackData))
+	})
+}
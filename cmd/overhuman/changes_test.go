@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/soul"
+	"github.com/overhuman/overhuman/internal/versioning"
+)
+
+func TestTrackChanges_SoulRollback(t *testing.T) {
+	s := soul.New(t.TempDir(), "Agent", "general")
+	if err := s.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	vc := versioning.New()
+	trackChanges(vc, s, instruments.NewSkillRegistry())
+
+	orig, _ := s.Read()
+	if _, err := s.Update(strings.Replace(orig, "Run count: 0", "Run count: 5", 1), "reflection"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	list := vc.List()
+	if len(list) != 1 || list[0].Trigger != "reflection" || list[0].RollbackData != "1" {
+		t.Fatalf("changes = %+v", list)
+	}
+	if err := vc.ForceRollback(list[0].ID); err != nil {
+		t.Fatalf("ForceRollback: %v", err)
+	}
+	if got, _ := s.Read(); got != orig {
+		t.Error("soul not restored to the previous version")
+	}
+	if len(vc.List()) != 1 {
+		t.Error("rollback recorded as a new change")
+	}
+}
+
+func TestTrackChanges_SkillRollback(t *testing.T) {
+	reg := instruments.NewSkillRegistry()
+	reg.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "s1", Status: instruments.SkillStatusActive}})
+	vc := versioning.New()
+	trackChanges(vc, soul.New(t.TempDir(), "Agent", "general"), reg)
+
+	reg.UpdateStatus("s1", instruments.SkillStatusDeprecated)
+	ch := vc.Record(versioning.Change{Type: versioning.ChangeSkill, EntityID: "s1", RollbackData: string(instruments.SkillStatusActive)})
+	if err := vc.ForceRollback(ch.ID); err != nil {
+		t.Fatalf("ForceRollback: %v", err)
+	}
+	if got := reg.Get("s1").Meta.Status; got != instruments.SkillStatusActive {
+		t.Errorf("status = %s, want active", got)
+	}
+}
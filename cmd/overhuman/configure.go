@@ -9,10 +9,22 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.org/x/term"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/locale"
+	"github.com/overhuman/overhuman/internal/mcp"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/skills"
+	"github.com/overhuman/overhuman/internal/webhook"
 )
 
 // persistedConfig is the JSON structure stored in ~/.overhuman/config.json.
@@ -23,6 +35,301 @@ type persistedConfig struct {
 	BaseURL  string `json:"base_url,omitempty"` // Custom base URL
 	Name     string `json:"name,omitempty"`     // Agent name
 	APIAddr  string `json:"api_addr,omitempty"` // API listen address
+
+	// Timezone (IANA, e.g. "Europe/Berlin") and locale (e.g. "de-DE") of
+	// the agent; Users holds per-user overrides keyed by sender ID.
+	Timezone string                     `json:"timezone,omitempty"`
+	Locale   string                     `json:"locale,omitempty"`
+	Users    map[string]locale.Settings `json:"users,omitempty"`
+
+	// ActiveHours is the daily "HH:MM-HH:MM" window outside which the
+	// daemon goes into standby.
+	ActiveHours string `json:"active_hours,omitempty"`
+
+	// HeartbeatMin and HeartbeatMax bound the adaptive heartbeat interval
+	// as Go durations, e.g. "5m" and "3h" (defaults 10m and 2h).
+	HeartbeatMin string `json:"heartbeat_min,omitempty"`
+	HeartbeatMax string `json:"heartbeat_max,omitempty"`
+
+	// Templates are saved prompts (name → input text) for the kiosk
+	// command palette.
+	Templates map[string]string `json:"templates,omitempty"`
+
+	// AdminToken authenticates admin endpoints such as POST /mode.
+	AdminToken string `json:"admin_token,omitempty"`
+
+	// AutoMigrateModels lets the daemon replace a configured model that the
+	// provider no longer offers with the suggested successor. The previous
+	// config is kept under config-history/ (overhuman models rollback).
+	AutoMigrateModels bool `json:"auto_migrate_models,omitempty"`
+
+	// ModelUpgradeTrials lets the daemon start evaluating a newer default
+	// model on its own (eval suite plus shadowed tasks) instead of only
+	// announcing it. Switching always waits for `overhuman models upgrade
+	// accept`.
+	ModelUpgradeTrials bool `json:"model_upgrade_trials,omitempty"`
+
+	// EmbeddingModel and EmbeddingBaseURL select the embedding model and an
+	// optional separate OpenAI-compatible endpoint for it.
+	EmbeddingModel   string `json:"embedding_model,omitempty"`
+	EmbeddingBaseURL string `json:"embedding_base_url,omitempty"`
+
+	// UIProvider, UIModel and UIBaseURL pin a dedicated provider and model
+	// for UI generation (defaults: the main provider's cheapest model).
+	UIProvider string `json:"ui_provider,omitempty"`
+	UIModel    string `json:"ui_model,omitempty"`
+	UIBaseURL  string `json:"ui_base_url,omitempty"`
+
+	// FallbackProvider, FallbackModel and FallbackBaseURL name a local
+	// provider ("ollama", "lmstudio") that answers while the main one is
+	// unreachable, e.g. offline or during an outage.
+	FallbackProvider string `json:"fallback_provider,omitempty"`
+	FallbackModel    string `json:"fallback_model,omitempty"`
+	FallbackBaseURL  string `json:"fallback_base_url,omitempty"`
+
+	// Fallbacks are providers tried in order while the main one is
+	// failing, before the local fallback above, e.g. [{"provider":
+	// "openai", "model": "gpt-4o-mini"}] behind groq.
+	Fallbacks []fallbackSettings `json:"fallbacks,omitempty"`
+
+	// LLMRetry retries requests failing with a rate limit, a server error
+	// or a timeout, with jittered exponential backoff, e.g.
+	// {"max_retries": 5, "base_delay_ms": 1000}. Default: 3 retries from
+	// 500ms; {"max_retries": -1} turns retries off.
+	LLMRetry brain.RetryPolicy `json:"llm_retry,omitempty"`
+
+	// LLMCache answers identical clarification and review requests (same
+	// messages, model and temperature) from the database for a while, e.g.
+	// {"ttl": "6h"}. Default: 24h; {"disabled": true} turns it off.
+	LLMCache llmCacheSettings `json:"llm_cache,omitempty"`
+
+	// SkillGeneration turns a task repeated often enough into a generated
+	// program run in the sandbox, e.g. {"language": "javascript"}.
+	// Default: python; {"disabled": true} turns it off.
+	SkillGeneration skillGenSettings `json:"skill_generation,omitempty"`
+
+	// Sandbox runs code skills in Docker containers or, with {"runtime":
+	// "wasm"}, in WASI modules, e.g. {"enabled": true, "memory_mb": 512,
+	// "timeout_s": 60}. Off by default; code skills are then denied.
+	Sandbox sandboxSettings `json:"sandbox,omitempty"`
+
+	// KeyExpiry records when API keys and tokens expire, keyed by a name of
+	// the user's choosing ("openai", "telegram", ...) with YYYY-MM-DD dates.
+	KeyExpiry map[string]string `json:"key_expiry,omitempty"`
+
+	// STT and TTS select the speech-to-text and text-to-speech backends,
+	// e.g. {"provider": "whisper.cpp", "model": "/models/ggml-base.bin"}.
+	// API keys are better passed via the environment.
+	STT brain.SpeechConfig `json:"stt,omitempty"`
+	TTS brain.SpeechConfig `json:"tts,omitempty"`
+
+	// SpeechOutput reads results out with the TTS backend, playing them
+	// and/or writing audio files to an outbox.
+	SpeechOutput speechOutputSettings `json:"speech_output,omitempty"`
+
+	// SoulTokenBudget caps the soul size enforced by the soul linter on save
+	// (0 = 4000 tokens).
+	SoulTokenBudget int `json:"soul_token_budget,omitempty"`
+
+	// DailyBudgetUSD and MonthlyBudgetUSD cap LLM spending (0 = no cap).
+	// Tasks the remaining budget cannot cover are not run; the sender is
+	// told the budget is exhausted.
+	DailyBudgetUSD   float64 `json:"daily_budget_usd,omitempty"`
+	MonthlyBudgetUSD float64 `json:"monthly_budget_usd,omitempty"`
+
+	// PriorityPolicy overrides rows of the priority policy table, keyed by
+	// "low", "normal", "high" or "critical", e.g.
+	// {"low": {"max_tier": "cheap", "defer_below": 0.3}}.
+	PriorityPolicy budget.Policy `json:"priority_policy,omitempty"`
+
+	// SpeculationMultiplier lets tasks the clarifier finds ambiguous run
+	// both readings in parallel on cheap models when that costs at most
+	// this multiple of a normal run, e.g. 1.5 (0 = off).
+	SpeculationMultiplier float64 `json:"speculation_multiplier,omitempty"`
+
+	// WarmStartRuns is how often a task must have run, with good reviews,
+	// before its clarification and plan are reused instead of asking the
+	// LLM again (0 = 5, negative = never reuse).
+	WarmStartRuns int `json:"warm_start_runs,omitempty"`
+
+	// Sessions sets when a conversation ends on its own (idle_minutes,
+	// default 120, negative = never) and whether ended conversations are
+	// summarized into long-term memory.
+	Sessions pipeline.SessionPolicy `json:"sessions,omitempty"`
+
+	// Scratch caps each run's scratch directory ("quota_mb", default 100)
+	// and with "keep": true keeps every run's files under artifacts/.
+	Scratch pipeline.ScratchPolicy `json:"scratch,omitempty"`
+
+	// PublicURL is the kiosk URL used in download links for run artifacts
+	// sent with replies, e.g. "https://agent.example.com".
+	PublicURL string `json:"public_url,omitempty"`
+
+	// Limits caps payload, attachment and inbox file sizes at the sense
+	// layer (zero values use the defaults).
+	Limits senses.Limits `json:"limits,omitempty"`
+
+	// Dispatch schedules inputs fairly across senders: "workers" run at
+	// once, at most "per_sender" of them from one sender.
+	Dispatch senses.DispatchConfig `json:"dispatch,omitempty"`
+
+	// AuditRetentionDays is how long audit events are kept (0 = 90 days,
+	// negative = forever).
+	AuditRetentionDays int `json:"audit_retention_days,omitempty"`
+
+	// Senses holds per-channel settings keyed by source type
+	// ("email", "file", "telegram", ...).
+	Senses map[string]senseSettings `json:"senses,omitempty"`
+
+	// Notion connects the docs skill to Notion, e.g. {"token": "enc:v1:...",
+	// "allow_writes": true}. Encrypt the token with `overhuman encrypt`.
+	Notion skills.NotionConfig `json:"notion,omitempty"`
+
+	// HTTP restricts the API integration skill, e.g.
+	// {"allowed_domains": ["api.github.com", "*.atlassian.net"],
+	// "endpoints": {"team_webhook": {"method": "POST", "url": "https://..."}}}.
+	HTTP skills.HTTPRequestConfig `json:"http,omitempty"`
+
+	// Issues connects the issues skill to GitHub and Jira, e.g.
+	// {"github": {"token": "enc:v1:...", "default_repo": "acme/api"},
+	// "allow_writes": true}.
+	Issues skills.IssuesConfig `json:"issues,omitempty"`
+
+	// MCPServers lists MCP servers whose tools automation rules can call,
+	// e.g. [{"name": "fs", "command": "npx", "args": [...], "auto_connect": true}].
+	MCPServers []mcp.ServerConfig `json:"mcp_servers,omitempty"`
+
+	// Webhooks receive signed JSON events, e.g. [{"url":
+	// "https://n8n.example.com/webhook/agent", "secret": "enc:v1:...",
+	// "events": ["task.*", "budget.threshold"]}]. Events: task.completed,
+	// task.failed, automation.fired, budget.threshold, approval.requested
+	// (empty = all).
+	Webhooks []webhook.Config `json:"webhooks,omitempty"`
+
+	// InboundWebhooks start tasks from signed POST /webhook/{name}
+	// requests, e.g. [{"name": "github", "secret": "enc:v1:...",
+	// "template": "Review PR {{.pull_request.title}}: {{.pull_request.body}}"}].
+	InboundWebhooks []senses.InboundWebhook `json:"inbound_webhooks,omitempty"`
+
+	// Moderation checks results before they go to email, Slack, Telegram,
+	// Discord or Signal, e.g. {"enabled": true, "provider": "openai",
+	// "categories": {"harassment": "approve", "pii": "block", "*":
+	// "annotate"}, "threshold": 0.5}. Actions: annotate, approve (held
+	// until released via /api/moderation/held), block.
+	Moderation security.OutputModeration `json:"moderation,omitempty"`
+
+	// Approvals makes skills using risky permissions wait for an admin,
+	// e.g. {"permissions": ["process", "external_write"], "timeout": "30m"}.
+	// Pending approvals are listed at /api/approvals, in the kiosk and by
+	// `overhuman approvals`.
+	Approvals approvalSettings `json:"approvals,omitempty"`
+
+	// Documents summarizes documents too long for the context window
+	// before they are run: chunked, each chunk summarized in parallel and
+	// the summaries combined, e.g. {"threshold": 40000, "sources":
+	// ["file", "email"]}. On by default for the inbox.
+	Documents documentSettings `json:"documents,omitempty"`
+
+	// Email connects the email sense, e.g. {"imap_server":
+	// "imap.example.com:993", "smtp_server": "smtp.example.com:587",
+	// "smtp_user": "agent@example.com", "smtp_pass": "enc:v1:...",
+	// "from_addr": "agent@example.com", "dkim": {"domain": "example.com",
+	// "selector": "agent", "key_file": "dkim.pem"}}.
+	Email emailSettings `json:"email,omitempty"`
+
+	// Kiosk themes the kiosk web UI, e.g. {"color_scheme": "light",
+	// "accent": "#e4572e", "logo": "logo.svg"}.
+	Kiosk kioskSettings `json:"kiosk,omitempty"`
+
+	// UIHistory bounds the stored history of generated UIs that the kiosk
+	// and GET /api/ui/{task_id} show again, e.g. {"max_entries": 500,
+	// "max_age_days": 30} (the defaults).
+	UIHistory uiHistorySettings `json:"ui_history,omitempty"`
+
+	// Team shares the daemon with a small team. Each member authenticates
+	// with a bearer token (or ?token= in kiosk links) and gets a role:
+	// "admin" manages config, skills and policies, "member" submits tasks
+	// and sees their own history, "viewer" watches the kiosk read-only,
+	// e.g. [{"name": "anna", "role": "member", "token": "enc:v1:..."}].
+	// Generate tokens with e.g. `openssl rand -hex 24`.
+	Team []security.TeamMember `json:"team,omitempty"`
+}
+
+// senseSettings is the per-channel block inside "senses" in config.json.
+type senseSettings struct {
+	// PrePrompt wraps every input from this channel, e.g.
+	// "Reply in a formal tone.\n\n{payload}". See senses.PrePrompts.
+	PrePrompt string `json:"pre_prompt,omitempty"`
+	// MemoryVisibility sets who may later be shown what the agent learns
+	// from this channel: "private" (the sender only), "channel" (anyone on
+	// the channel) or "shared". Default: private when the sender is known.
+	MemoryVisibility string `json:"memory_visibility,omitempty"`
+	// Preprocess cleans inputs from this channel before anything else sees
+	// them, e.g. {"strip_quoted": true, "strip_signature": true,
+	// "collapse_whitespace": true, "translate": true, "replace":
+	// [{"pattern": "(?i)confidentiality notice:.*", "with": ""}]}.
+	Preprocess *senses.PreprocessConfig `json:"preprocess,omitempty"`
+}
+
+// emailSettings is the "email" block in config.json; the EMAIL_*
+// environment variables override it. Passwords may be encrypted with
+// `overhuman encrypt`; a relative DKIM key file is resolved against the
+// data directory.
+type emailSettings struct {
+	IMAPServer string `json:"imap_server,omitempty"`
+	IMAPUser   string `json:"imap_user,omitempty"`
+	IMAPPass   string `json:"imap_pass,omitempty"`
+	SMTPServer string `json:"smtp_server,omitempty"`
+	SMTPUser   string `json:"smtp_user,omitempty"`
+	SMTPPass   string `json:"smtp_pass,omitempty"`
+	FromAddr   string `json:"from_addr,omitempty"`
+	// SMTPSecurity is "starttls" (default), "tls" (default on port 465)
+	// or "none" for a plaintext local relay.
+	SMTPSecurity string `json:"smtp_security,omitempty"`
+	// SMTPAuth is "plain" or "login" (default: what the server offers).
+	SMTPAuth string            `json:"smtp_auth,omitempty"`
+	DKIM     senses.DKIMConfig `json:"dkim,omitempty"`
+}
+
+// kioskSettings is the "kiosk" block in config.json. Relative logo and
+// CSS paths are resolved against the data directory.
+type kioskSettings struct {
+	Theme       string `json:"theme,omitempty"`        // scifi, cyberpunk or clean
+	ColorScheme string `json:"color_scheme,omitempty"` // dark, light or system
+	Accent      string `json:"accent,omitempty"`       // CSS color, e.g. "#e4572e"
+	Logo        string `json:"logo,omitempty"`         // image URL or file
+	CustomCSS   string `json:"custom_css,omitempty"`   // CSS file (default: kiosk.css)
+}
+
+// uiHistorySettings is the "ui_history" block in config.json.
+type uiHistorySettings struct {
+	MaxEntries int `json:"max_entries,omitempty"`
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+}
+
+// retention returns the settings as a genui.UIRetention.
+func (s uiHistorySettings) retention() genui.UIRetention {
+	return genui.UIRetention{MaxEntries: s.MaxEntries, MaxAge: time.Duration(s.MaxAgeDays) * 24 * time.Hour}
+}
+
+// apply copies the settings onto kc.
+func (s kioskSettings) apply(kc *genui.KioskConfig, dataDir string) {
+	if s.Theme != "" {
+		kc.Theme = s.Theme
+	}
+	kc.ColorScheme = s.ColorScheme
+	kc.AccentColor = s.Accent
+	kc.Logo = s.Logo
+	if kc.Logo != "" && !strings.Contains(kc.Logo, ":") && !filepath.IsAbs(kc.Logo) {
+		kc.Logo = filepath.Join(dataDir, kc.Logo)
+	}
+	kc.CustomCSSPath = s.CustomCSS
+	if kc.CustomCSSPath == "" {
+		kc.CustomCSSPath = "kiosk.css"
+	}
+	if !filepath.IsAbs(kc.CustomCSSPath) {
+		kc.CustomCSSPath = filepath.Join(dataDir, kc.CustomCSSPath)
+	}
 }
 
 // configFilePath returns the path to config.json.
@@ -87,8 +394,17 @@ func savePersistedConfig(cfg *persistedConfig) error {
 	return nil
 }
 
-// runConfigure runs the interactive configuration wizard.
-func runConfigure() {
+// runConfigure runs the interactive configuration wizard, or with
+// arguments `config validate` or the scripted setup (see setup.go).
+func runConfigure(args []string) {
+	switch {
+	case len(args) > 0 && args[0] == "validate":
+		runConfigValidate(args[1:])
+		return
+	case len(args) > 0:
+		runSetup(args)
+		return
+	}
 	fmt.Printf("\n🔧 %s v%s — Configuration Wizard\n\n", appName, version)
 
 	reader := bufio.NewReader(os.Stdin)
@@ -248,6 +564,16 @@ func runConfigure() {
 	cfg.Name = name
 	fmt.Printf("  ✓ Agent name: %s\n\n", name)
 
+	// Step 6: Spending caps.
+	fmt.Println("  Spending caps stop new tasks once reached (0 = no cap).")
+	cfg.DailyBudgetUSD = promptBudget(reader, "Daily budget in USD", existing.DailyBudgetUSD)
+	cfg.MonthlyBudgetUSD = promptBudget(reader, "Monthly budget in USD", existing.MonthlyBudgetUSD)
+	if cfg.DailyBudgetUSD == 0 && cfg.MonthlyBudgetUSD == 0 {
+		fmt.Print("  ✓ Budget: no caps\n\n")
+	} else {
+		fmt.Printf("  ✓ Budget: $%.2f a day, $%.2f a month (0 = no cap)\n\n", cfg.DailyBudgetUSD, cfg.MonthlyBudgetUSD)
+	}
+
 	// Save.
 	if err := savePersistedConfig(cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
@@ -441,7 +767,15 @@ func runDoctor() {
 		}
 	}
 
-	// Check 6: Soul file.
+	// Check 6: Model availability.
+	checks++
+	if cfg != nil && cfg.Provider != "" {
+		if doctorCheckModels() {
+			issues++
+		}
+	}
+
+	// Check 7: Soul file.
 	checks++
 	soulPath := filepath.Join(dataDir, "soul.md")
 	if _, err := os.Stat(soulPath); err == nil {
@@ -450,13 +784,16 @@ func runDoctor() {
 		fmt.Printf("  … Soul: not initialized (will be created on first run)\n")
 	}
 
-	// Check 7: Database.
+	// Check 8: Key expiry.
 	checks++
-	dbPath := filepath.Join(dataDir, "overhuman.db")
-	if info, err := os.Stat(dbPath); err == nil {
-		fmt.Printf("  ✓ Database: %s (%d KB)\n", dbPath, info.Size()/1024)
-	} else {
-		fmt.Printf("  … Database: not created yet (will be created on first run)\n")
+	if doctorCheckKeys(loadConfig().KeyExpiry) {
+		issues++
+	}
+
+	// Check 9: Database.
+	checks++
+	if doctorCheckDatabase(dataDir) {
+		issues++
 	}
 
 	fmt.Println()
@@ -1044,6 +1381,26 @@ func promptString(reader *bufio.Reader, prompt, defaultVal string) string {
 	return line
 }
 
+// promptBudget asks for a spending cap in USD until the answer parses.
+func promptBudget(reader *bufio.Reader, prompt string, current float64) float64 {
+	for {
+		v, err := parseBudgetUSD(promptString(reader, prompt, strconv.FormatFloat(current, 'f', -1, 64)))
+		if err == nil {
+			return v
+		}
+		fmt.Printf("  ⚠ %v\n", err)
+	}
+}
+
+// parseBudgetUSD parses a spending cap such as "5", "2.50" or "$10".
+func parseBudgetUSD(s string) (float64, error) {
+	usd, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(s), "$"), 64)
+	if err != nil || usd < 0 {
+		return 0, fmt.Errorf("budget %q: want an amount in USD, e.g. 5 or 2.50", s)
+	}
+	return usd, nil
+}
+
 // readSecretLine reads a line without echoing (for API keys).
 func readSecretLine(reader *bufio.Reader) string {
 	// Try terminal raw mode (no echo).
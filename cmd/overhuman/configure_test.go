@@ -6,6 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/locale"
+	"github.com/overhuman/overhuman/internal/senses"
 )
 
 func TestPersistedConfig_SaveAndLoad(t *testing.T) {
@@ -131,6 +135,97 @@ func TestLoadConfig_FromConfigJSON(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_SensePrePrompts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OVERHUMAN_DATA", dir)
+
+	cfg := persistedConfig{
+		Provider: "openai",
+		Senses: map[string]senseSettings{
+			"email": {PrePrompt: "Reply in a formal tone.\n\n{payload}"},
+			"slack": {},
+		},
+	}
+	data, _ := json.MarshalIndent(cfg, "", "  ")
+	os.WriteFile(filepath.Join(dir, "config.json"), data, 0o600)
+
+	loaded := loadConfig()
+	if len(loaded.PrePrompts) != 1 {
+		t.Fatalf("pre_prompts = %v, want only email", loaded.PrePrompts)
+	}
+	if !strings.HasPrefix(loaded.PrePrompts["email"], "Reply in a formal tone.") {
+		t.Errorf("email pre_prompt = %q", loaded.PrePrompts["email"])
+	}
+
+	pp := buildPrePrompts(loaded)
+	if pp.Len() != 1 {
+		t.Errorf("buildPrePrompts Len = %d, want 1", pp.Len())
+	}
+}
+
+func TestLoadConfig_SensePreprocess(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OVERHUMAN_DATA", dir)
+
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{
+		"provider": "openai",
+		"senses": {
+			"email": {"preprocess": {"strip_quoted": true, "replace": [{"pattern": "(?i)confidential.*", "with": ""}]}},
+			"slack": {"pre_prompt": "Be brief."}
+		}
+	}`), 0o600)
+
+	loaded := loadConfig()
+	if len(loaded.Preprocess) != 1 || !loaded.Preprocess["email"].StripQuoted {
+		t.Fatalf("preprocess = %+v, want only email", loaded.Preprocess)
+	}
+	pp := buildPreprocessor(loaded, "de-DE", nil)
+	if pp.Len() != 1 {
+		t.Errorf("buildPreprocessor Len = %d, want 1", pp.Len())
+	}
+
+	loaded.Preprocess["email"] = senses.PreprocessConfig{Replace: []senses.ReplaceRule{{Pattern: "("}}}
+	if pp := buildPreprocessor(loaded, "en-US", nil); pp != nil {
+		t.Error("an invalid replace rule should disable preprocessing")
+	}
+}
+
+func TestLoadConfig_TimezoneAndUsers(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OVERHUMAN_DATA", dir)
+	t.Setenv("OVERHUMAN_TZ", "")
+	t.Setenv("OVERHUMAN_LOCALE", "fr-FR")
+
+	cfg := persistedConfig{
+		Timezone: "Europe/Paris",
+		Locale:   "en-GB",
+		Users: map[string]locale.Settings{
+			"bob@example.com": {Timezone: "America/Chicago"},
+		},
+	}
+	data, _ := json.MarshalIndent(cfg, "", "  ")
+	os.WriteFile(filepath.Join(dir, "config.json"), data, 0o600)
+
+	loaded := loadConfig()
+	if loaded.Timezone != "Europe/Paris" {
+		t.Errorf("timezone = %q", loaded.Timezone)
+	}
+	if loaded.Locale != "fr-FR" {
+		t.Errorf("locale = %q, want env override fr-FR", loaded.Locale)
+	}
+
+	r, err := buildLocaleResolver(loaded)
+	if err != nil {
+		t.Fatalf("buildLocaleResolver: %v", err)
+	}
+	if got := r.For("bob@example.com").Location.String(); got != "America/Chicago" {
+		t.Errorf("bob tz = %s", got)
+	}
+	if got := r.For("someone").Location.String(); got != "Europe/Paris" {
+		t.Errorf("default tz = %s", got)
+	}
+}
+
 func TestLoadConfig_EnvOverridesConfigJSON(t *testing.T) {
 	dir := t.TempDir()
 	t.Setenv("OVERHUMAN_DATA", dir)
@@ -246,6 +341,26 @@ func TestConfigFilePath(t *testing.T) {
 	}
 }
 
+func TestKioskSettings_Apply(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+	kc := genui.DefaultKioskConfig()
+	kioskSettings{ColorScheme: "light", Accent: "#e4572e", Logo: "brand/logo.svg"}.apply(&kc, dataDir)
+	if kc.Theme != "scifi" || kc.ColorScheme != "light" || kc.AccentColor != "#e4572e" {
+		t.Errorf("config = %+v", kc)
+	}
+	if kc.Logo != filepath.Join(dataDir, "brand", "logo.svg") {
+		t.Errorf("Logo = %q, want it under the data dir", kc.Logo)
+	}
+	if kc.CustomCSSPath != filepath.Join(dataDir, "kiosk.css") {
+		t.Errorf("CustomCSSPath = %q, want the default kiosk.css", kc.CustomCSSPath)
+	}
+
+	kioskSettings{Theme: "clean", Logo: "https://example.com/logo.png"}.apply(&kc, dataDir)
+	if kc.Theme != "clean" || kc.Logo != "https://example.com/logo.png" {
+		t.Errorf("config = %+v", kc)
+	}
+}
+
 func TestTestProviderConnection_InvalidURL(t *testing.T) {
 	cfg := &persistedConfig{
 		Provider: "custom",
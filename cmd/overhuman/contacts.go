@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+const contactsUsage = "contacts [list|show NAME|add NAME [--email E] [--relationship R] [--prefers P] [--org]|forget NAME] [--owner SENDER]"
+
+// contactsOptions are the parsed `overhuman contacts` arguments.
+type contactsOptions struct {
+	sub    string        // list, show, add or forget
+	entity memory.Entity // name, owner and, for add, the fields to set
+}
+
+// parseContactsArgs parses the subcommand, the name (the remaining words)
+// and flags. --owner selects whose contact book to use ("" = the shared
+// one, used by the CLI and API).
+func parseContactsArgs(args []string) (contactsOptions, error) {
+	opts := contactsOptions{sub: "list"}
+	var words []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--org":
+			opts.entity.Kind = memory.EntityOrganization
+			continue
+		case "--owner", "--email", "--relationship", "--prefers":
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return contactsOptions{}, fmt.Errorf("unknown flag %q", args[i])
+			}
+			words = append(words, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return contactsOptions{}, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--owner":
+			opts.entity.Owner = value
+		case "--email":
+			opts.entity.Email = value
+		case "--relationship":
+			opts.entity.Relationship = value
+		case "--prefers":
+			opts.entity.Preferences = value
+		}
+	}
+	if len(words) > 0 {
+		opts.sub, words = words[0], words[1:]
+	}
+	opts.entity.Name = strings.Join(words, " ")
+
+	switch opts.sub {
+	case "list":
+		if opts.entity.Name != "" {
+			return contactsOptions{}, fmt.Errorf("list takes no name")
+		}
+	case "show", "add", "forget":
+		if opts.entity.Name == "" {
+			return contactsOptions{}, fmt.Errorf("%s needs a NAME", opts.sub)
+		}
+	default:
+		return contactsOptions{}, fmt.Errorf("unknown subcommand %q", opts.sub)
+	}
+	e := opts.entity
+	if opts.sub != "add" && (e.Email != "" || e.Relationship != "" || e.Preferences != "" || e.Kind != "") {
+		return contactsOptions{}, fmt.Errorf("--email, --relationship, --prefers and --org only apply to add")
+	}
+	return opts, nil
+}
+
+// runContacts handles `overhuman contacts`: the people and organizations
+// the agent has learned from requests, which it adds to the context of
+// any task that mentions them.
+func runContacts(args []string) {
+	opts, err := parseContactsArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contacts: %v\nusage: %s %s\n", err, appName, contactsUsage)
+		os.Exit(1)
+	}
+	cfg := loadConfig()
+	ltm, err := memory.NewLongTermMemory(filepath.Join(cfg.DataDir, "overhuman.db"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open memory: %v\n", err)
+		os.Exit(1)
+	}
+	defer ltm.Close()
+	book, err := memory.NewEntityStore(ltm.DB())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	owner := opts.entity.Owner
+	switch opts.sub {
+	case "list":
+		entities, err := book.List(owner)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "contacts: %v\n", err)
+			os.Exit(1)
+		}
+		if len(entities) == 0 {
+			fmt.Println("No contacts yet. They are learned from requests that mention people or organizations.")
+			return
+		}
+		for _, e := range entities {
+			fmt.Println(e.Describe())
+		}
+	case "show":
+		e, err := book.Get(owner, opts.entity.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "contacts: %v\n", err)
+			os.Exit(1)
+		}
+		if e == nil {
+			fmt.Fprintf(os.Stderr, "No contact named %q.\n", opts.entity.Name)
+			os.Exit(1)
+		}
+		fmt.Println(e.Describe())
+		mentions, err := book.AllMentions(e.ID, 10)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "contacts: %v\n", err)
+			os.Exit(1)
+		}
+		for _, m := range mentions {
+			fmt.Printf("  %s  %s\n", m.CreatedAt.Local().Format("2006-01-02"), m.Summary)
+		}
+	case "add":
+		e, err := book.Upsert(opts.entity)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "contacts: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved: %s\n", e.Describe())
+	case "forget":
+		ok, err := book.Delete(owner, opts.entity.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "contacts: %v\n", err)
+			os.Exit(1)
+		}
+		if !ok {
+			fmt.Fprintf(os.Stderr, "No contact named %q.\n", opts.entity.Name)
+			os.Exit(1)
+		}
+		fmt.Printf("Forgot %s.\n", opts.entity.Name)
+	}
+}
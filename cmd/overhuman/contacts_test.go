@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+func TestParseContactsArgs(t *testing.T) {
+	opts, err := parseContactsArgs(nil)
+	if err != nil || opts.sub != "list" {
+		t.Errorf("default: %+v, %v", opts, err)
+	}
+	opts, err = parseContactsArgs([]string{"add", "Anna", "Petrova", "--email=anna@example.com", "--prefers", "informal", "--owner", "42"})
+	e := opts.entity
+	if err != nil || opts.sub != "add" || e.Name != "Anna Petrova" || e.Email != "anna@example.com" || e.Preferences != "informal" || e.Owner != "42" {
+		t.Errorf("add: %+v, %v", opts, err)
+	}
+	opts, err = parseContactsArgs([]string{"add", "Acme", "--org"})
+	if err != nil || opts.entity.Kind != memory.EntityOrganization {
+		t.Errorf("add --org: %+v, %v", opts, err)
+	}
+	for _, bad := range [][]string{{"show"}, {"list", "Anna"}, {"forget", "Anna", "--email", "x"}, {"call", "Anna"}, {"add", "Anna", "--owner"}, {"--all"}} {
+		if _, err := parseContactsArgs(bad); err == nil {
+			t.Errorf("%v: expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+// savingsWindow is the period of the weekly self-report.
+const savingsWindow = 7 * 24 * time.Hour
+
+// costsReport is the body of GET /api/costs.
+type costsReport struct {
+	Budget  string               `json:"budget,omitempty"`
+	Week    memory.SavingsReport `json:"week"`
+	AllTime memory.SavingsReport `json:"all_time"`
+	Skills  map[string]string    `json:"skill_names,omitempty"` // skill ID → name
+}
+
+// buildCostsReport collects budget spend and automation savings.
+func buildCostsReport(deps pipeline.Dependencies, now time.Time) (costsReport, error) {
+	var rep costsReport
+	if deps.Budget != nil {
+		rep.Budget = deps.Budget.BudgetStatus()
+	}
+	if deps.Savings == nil {
+		return rep, nil
+	}
+	var err error
+	if rep.Week, err = deps.Savings.Report(now.Add(-savingsWindow)); err != nil {
+		return rep, err
+	}
+	if rep.AllTime, err = deps.Savings.Report(time.Time{}); err != nil {
+		return rep, err
+	}
+	rep.Skills = make(map[string]string)
+	for _, sk := range rep.AllTime.Skills {
+		rep.Skills[sk.SkillID] = skillName(deps, sk.SkillID)
+	}
+	return rep, nil
+}
+
+// costsHandler serves GET /api/costs.
+func costsHandler(deps pipeline.Dependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		rep, err := buildCostsReport(deps, time.Now())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(rep)
+	}
+}
+
+// skillName returns the display name of a skill, or its ID if unknown.
+func skillName(deps pipeline.Dependencies, id string) string {
+	if deps.Skills != nil {
+		if s := deps.Skills.Get(id); s != nil && s.Meta.Name != "" {
+			return s.Meta.Name
+		}
+	}
+	return id
+}
+
+// formatSavings renders an automation savings report for humans.
+func formatSavings(title string, rep memory.SavingsReport, name func(string) string) string {
+	if rep.Runs == 0 {
+		return title + ": no skill runs with an LLM baseline yet."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: saved $%.4f and %s across %d skill run(s) versus the LLM.",
+		title, rep.SavedCostUSD, formatSavedTime(rep.SavedMs), rep.Runs)
+	for _, sk := range rep.Skills {
+		fmt.Fprintf(&b, "\n  - %s: $%.4f, %s over %d run(s)", name(sk.SkillID), sk.SavedCostUSD, formatSavedTime(sk.SavedMs), sk.Runs)
+	}
+	return b.String()
+}
+
+// formatSavedTime renders saved milliseconds; negative means time lost.
+func formatSavedTime(ms float64) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < 0 {
+		return (-d).Round(time.Second).String() + " slower"
+	}
+	return d.Round(time.Second).String()
+}
+
+// weeklyReport is the text of the weekly self-report.
+func weeklyReport(deps pipeline.Dependencies, now time.Time) (string, error) {
+	rep, err := buildCostsReport(deps, now)
+	if err != nil {
+		return "", err
+	}
+	name := func(id string) string { return skillName(deps, id) }
+	text := "Weekly self-report.\n" + formatSavings("Automation savings (last 7 days)", rep.Week, name)
+	if rep.Budget != "" {
+		text += "\nBudget: " + rep.Budget
+	}
+	return text, nil
+}
+
+// runWeeklyReport sends the weekly self-report through notify once a week.
+func runWeeklyReport(ctx context.Context, deps pipeline.Dependencies, notify func(string)) {
+	ticker := time.NewTicker(savingsWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			text, err := weeklyReport(deps, now)
+			if err != nil {
+				log.Printf("[report] weekly report: %v", err)
+				continue
+			}
+			notify(text)
+		}
+	}
+}
+
+// registerCostsCommand adds the "costs" palette action.
+func registerCostsCommand(cmds *genui.CommandRegistry, deps pipeline.Dependencies) {
+	cmds.AddAction("costs", "Costs and automation savings", "Budget spend and what skills saved versus the LLM", nil,
+		func(_ context.Context, _ map[string]string) (string, error) {
+			rep, err := buildCostsReport(deps, time.Now())
+			if err != nil {
+				return "", err
+			}
+			name := func(id string) string { return skillName(deps, id) }
+			text := formatSavings("Last 7 days", rep.Week, name) + "\n" + formatSavings("All time", rep.AllTime, name)
+			if rep.Budget != "" {
+				text = "Budget: " + rep.Budget + "\n" + text
+			}
+			return text, nil
+		})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+func TestFormatSavings(t *testing.T) {
+	name := func(id string) string { return strings.ToUpper(id) }
+	if got := formatSavings("Last 7 days", memory.SavingsReport{}, name); !strings.Contains(got, "no skill runs") {
+		t.Errorf("empty report = %q", got)
+	}
+	got := formatSavings("Last 7 days", memory.SavingsReport{
+		Runs: 3, SavedCostUSD: 0.06, SavedMs: 12_000,
+		Skills: []memory.SkillSavings{{SkillID: "sk", Runs: 3, SavedCostUSD: 0.06, SavedMs: 12_000}},
+	}, name)
+	for _, want := range []string{"saved $0.0600 and 12s across 3 skill run(s)", "SK: $0.0600, 12s over 3 run(s)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+	if got := formatSavedTime(-2500); got != "3s slower" {
+		t.Errorf("formatSavedTime(-2500) = %q", got)
+	}
+}
+
+func TestWeeklyReport(t *testing.T) {
+	ltm, err := memory.NewLongTermMemory(filepath.Join(t.TempDir(), "overhuman.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ltm.Close()
+	savings, err := memory.NewSavingsTracker(ltm.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	savings.RecordLLMRun("fp", 0.05, 4000)
+	savings.RecordSkillRun("sk", "fp", 0, 1000)
+
+	reg := instruments.NewSkillRegistry()
+	reg.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "sk", Name: "Invoice parser"}})
+	deps := pipeline.Dependencies{Savings: savings, Skills: reg}
+
+	text, err := weeklyReport(deps, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "Invoice parser: $0.0500, 3s over 1 run(s)") {
+		t.Errorf("weekly report = %q", text)
+	}
+	rep, err := buildCostsReport(pipeline.Dependencies{}, time.Now())
+	if err != nil || rep.Week.Runs != 0 {
+		t.Errorf("report without trackers = %+v, %v", rep, err)
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/netaddr"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// debugConsole answers the commands of `overhuman debug repl` with the live
+// state of the daemon's subsystems. Nil fields are reported as unavailable.
+type debugConsole struct {
+	router     *brain.ModelRouter
+	overrides  *brain.RoutingOverrides
+	patterns   *memory.PatternTracker
+	longTerm   *memory.LongTermMemory
+	dispatcher *senses.Dispatcher
+	supervisor *supervisor
+	// heartbeat queues a synthetic heartbeat and reports whether it was
+	// accepted.
+	heartbeat func() bool
+	// adminToken, if set, must accompany every command.
+	adminToken string
+}
+
+const debugHelp = `router                      models, selection per complexity and routing overrides
+patterns [N]                most frequent task patterns (default 10)
+pattern FINGERPRINT         one pattern (a fingerprint prefix will do)
+pattern record CHANNEL QUALITY GOAL...
+                            record an observation, as a run of GOAL would
+queue                       the dispatcher's queue, input by input
+memory QUERY                search long-term memory
+heartbeat                   fire a synthetic heartbeat
+components                  supervised components and their restarts
+help                        this list`
+
+// Exec runs one command line and returns its output.
+func (c *debugConsole) Exec(line string) (string, error) {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return "", nil
+	}
+	var b strings.Builder
+	var err error
+	switch args[0] {
+	case "help", "?":
+		b.WriteString(debugHelp)
+	case "router":
+		err = c.dumpRouter(&b)
+	case "patterns":
+		limit := 10
+		if len(args) > 1 {
+			if limit, err = strconv.Atoi(args[1]); err != nil {
+				return "", fmt.Errorf("patterns: want a number, got %q", args[1])
+			}
+		}
+		err = c.dumpPatterns(&b, limit)
+	case "pattern":
+		err = c.pattern(&b, args[1:])
+	case "queue":
+		err = c.dumpQueue(&b)
+	case "memory":
+		err = c.searchMemory(&b, strings.Join(args[1:], " "))
+	case "heartbeat":
+		if c.heartbeat == nil {
+			return "", fmt.Errorf("heartbeat: unavailable")
+		}
+		if !c.heartbeat() {
+			return "", fmt.Errorf("heartbeat: the input queue is full")
+		}
+		b.WriteString("heartbeat queued")
+	case "components":
+		if c.supervisor == nil {
+			return "", fmt.Errorf("components: unavailable")
+		}
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "COMPONENT\tUP\tRESTARTS\tLAST INCIDENT")
+		for _, st := range c.supervisor.Status() {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", st.Name, time.Since(st.Started).Round(time.Second), st.Restarts, st.LastIncident)
+		}
+		tw.Flush()
+	default:
+		return "", fmt.Errorf("unknown command %q (try help)", args[0])
+	}
+	return strings.TrimRight(b.String(), "\n"), err
+}
+
+func (c *debugConsole) dumpRouter(w io.Writer) error {
+	if c.router == nil {
+		return fmt.Errorf("router: unavailable")
+	}
+	provider := c.router.Provider()
+	if provider == "" {
+		provider = "(any)"
+	}
+	fmt.Fprintf(w, "provider: %s\n", provider)
+	if o := c.router.Override(); o != "" {
+		fmt.Fprintf(w, "override: %s\n", o)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tTIER\t$/1K")
+	for _, m := range c.router.Models() {
+		fmt.Fprintf(tw, "%s\t%s\t%.5f\n", m, c.router.TierOf(m), c.router.CostPer1K(m))
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "selection (ample budget / under $1 / under $0.10):")
+	for _, cx := range []string{"simple", "moderate", "complex"} {
+		fmt.Fprintf(w, "  %-8s %s / %s / %s\n", cx, c.router.Select(cx, 100), c.router.Select(cx, 0.5), c.router.Select(cx, 0))
+	}
+	if c.overrides != nil {
+		list := c.overrides.List()
+		fmt.Fprintf(w, "routing overrides: %d\n", len(list))
+		for _, o := range list {
+			fmt.Fprintf(w, "  %s  %s  %s\n", shortFingerprint(o.Fingerprint), o.Tier, o.Description)
+		}
+	}
+	return nil
+}
+
+func (c *debugConsole) dumpPatterns(w io.Writer, limit int) error {
+	if c.patterns == nil {
+		return fmt.Errorf("patterns: unavailable")
+	}
+	top, err := c.patterns.Top(limit)
+	if err != nil {
+		return err
+	}
+	if len(top) == 0 {
+		fmt.Fprintln(w, "no patterns yet")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FINGERPRINT\tCOUNT\tQUALITY\tLAST SEEN\tSKILL\tDESCRIPTION")
+	for _, p := range top {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t%s\t%s\t%s\n", shortFingerprint(p.Fingerprint), p.Count, p.AvgQuality,
+			p.LastSeen.Local().Format("2006-01-02 15:04"), p.SkillID, truncateText(p.Description, 60))
+	}
+	return tw.Flush()
+}
+
+// pattern shows one pattern, or records an observation of one.
+func (c *debugConsole) pattern(w io.Writer, args []string) error {
+	if c.patterns == nil {
+		return fmt.Errorf("pattern: unavailable")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: pattern FINGERPRINT | pattern record CHANNEL QUALITY GOAL...")
+	}
+	var entry *memory.PatternEntry
+	if args[0] == "record" {
+		if len(args) < 4 {
+			return fmt.Errorf("usage: pattern record CHANNEL QUALITY GOAL...")
+		}
+		quality, err := strconv.ParseFloat(args[2], 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return fmt.Errorf("pattern record: quality must be between 0 and 1, got %q", args[2])
+		}
+		goal := strings.Join(args[3:], " ")
+		if entry, err = c.patterns.Record(c.patterns.ComputeFingerprint(goal, args[1]), goal, quality); err != nil {
+			return err
+		}
+	} else {
+		all, err := c.patterns.Top(0)
+		if err != nil {
+			return err
+		}
+		for i := range all {
+			if strings.HasPrefix(all[i].Fingerprint, args[0]) {
+				if entry != nil {
+					return fmt.Errorf("pattern: %q matches more than one fingerprint", args[0])
+				}
+				entry = &all[i]
+			}
+		}
+		if entry == nil {
+			return fmt.Errorf("pattern: no fingerprint starts with %q", args[0])
+		}
+	}
+	fmt.Fprintf(w, "fingerprint: %s\ndescription: %s\ncount:       %d\nquality:     %.2f\nlast seen:   %s\n",
+		entry.Fingerprint, entry.Description, entry.Count, entry.AvgQuality, entry.LastSeen.Local().Format(time.RFC3339))
+	if entry.SkillID != "" {
+		fmt.Fprintf(w, "skill:       %s\n", entry.SkillID)
+	}
+	return nil
+}
+
+func (c *debugConsole) dumpQueue(w io.Writer) error {
+	if c.dispatcher == nil {
+		return fmt.Errorf("queue: unavailable")
+	}
+	st := c.dispatcher.Stats()
+	fmt.Fprintf(w, "queued %d, in flight %d, served %d, starved %d, dropped %d\n", st.Queued, st.InFlight, st.Served, st.Starved, st.Dropped)
+	waiting := c.dispatcher.Waiting()
+	if len(waiting) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LANE\tINPUT\tPRIORITY\tWAITING\tPAYLOAD")
+	for _, q := range waiting {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", q.Lane, q.Input.InputID, q.Input.Priority, q.Waiting.Round(time.Second),
+			truncateText(strings.Join(strings.Fields(q.Input.Payload), " "), 60))
+	}
+	return tw.Flush()
+}
+
+func (c *debugConsole) searchMemory(w io.Writer, query string) error {
+	if c.longTerm == nil {
+		return fmt.Errorf("memory: unavailable")
+	}
+	if query == "" {
+		return fmt.Errorf("usage: memory QUERY")
+	}
+	entries, err := c.longTerm.Search(query, 10)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "no matching memories")
+	}
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s  %s  %s", e.ID, e.CreatedAt.Local().Format("2006-01-02"), e.Summary)
+		if e.Visibility != memory.VisibilityShared {
+			fmt.Fprintf(w, "  (%s)", describeVisibility(e))
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// shortFingerprint is the first 12 characters of a fingerprint.
+func shortFingerprint(fp string) string {
+	if len(fp) > 12 {
+		return fp[:12]
+	}
+	return fp
+}
+
+// truncateText cuts s to n runes, marking the cut.
+func truncateText(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// ServeHTTP serves POST /debug/exec: {"command": "..."} in, {"output":
+// "..."} or {"error": "..."} out. The console can change state, so only a
+// local admin may use it.
+func (c *debugConsole) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !netaddr.IsLocal(r) || !security.AuthorizeAdmin(r, c.adminToken) {
+		writeExportError(w, http.StatusForbidden, fmt.Errorf("the debug console needs a local admin"))
+		return
+	}
+	var req struct {
+		Command string `json:"command"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&req); err != nil {
+		writeExportError(w, http.StatusBadRequest, fmt.Errorf("bad request: %v", err))
+		return
+	}
+	out, err := c.Exec(req.Command)
+	if err != nil {
+		writeExportError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"output": out})
+}
+
+// runDebug handles `overhuman debug repl`: a prompt sending each line to
+// the running daemon's debug console (on the kiosk server) and printing
+// the answer.
+func runDebug(args []string) {
+	if len(args) == 0 || args[0] != "repl" {
+		fmt.Fprintf(os.Stderr, "usage: %s debug repl\n", appName)
+		os.Exit(1)
+	}
+	cfg := loadConfig()
+	addr := deriveKioskAddr(cfg.APIAddr)
+	client := netaddr.HTTPClient(addr, 30*time.Second)
+	url := netaddr.URL(addr, "/debug/exec")
+	fmt.Printf("%s v%s — debug console at %s (help lists commands, quit exits)\n", appName, version, addr)
+	debugREPL(os.Stdin, os.Stdout, func(line string) (string, error) {
+		return debugExec(client, url, cfg.AdminToken, line)
+	})
+}
+
+// debugREPL reads commands from in until EOF or "quit" and prints what
+// exec returns.
+func debugREPL(in io.Reader, out io.Writer, exec func(string) (string, error)) {
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "debug> ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		line := strings.TrimSpace(sc.Text())
+		switch line {
+		case "":
+			continue
+		case "quit", "exit":
+			return
+		}
+		res, err := exec(line)
+		if err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+			continue
+		}
+		if res != "" {
+			fmt.Fprintln(out, res)
+		}
+	}
+}
+
+// debugExec sends one command to the daemon's debug console.
+func debugExec(client *http.Client, url, adminToken, line string) (string, error) {
+	body, _ := json.Marshal(map[string]string{"command": line})
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if adminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("daemon is not answering: %v", err)
+	}
+	defer resp.Body.Close()
+	var res struct {
+		Output string `json:"output"`
+		Error  string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("bad response (%d): %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s", res.Error)
+	}
+	return res.Output, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+func TestDebugConsole_Exec(t *testing.T) {
+	ltm, err := memory.NewLongTermMemory(filepath.Join(t.TempDir(), "overhuman.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ltm.Close()
+	pt, err := memory.NewPatternTracker(ltm.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := brain.NewModelRouter()
+	router.SetProvider("openai")
+	d := senses.NewDispatcher(senses.DispatchConfig{})
+	in := senses.NewUnifiedInput(senses.SourceTelegram, "summarize\nthe inbox")
+	in.SourceMeta.Sender = "alice"
+	d.Push(in)
+	beats := 0
+	c := &debugConsole{router: router, patterns: pt, longTerm: ltm, dispatcher: d, heartbeat: func() bool { beats++; return true }}
+
+	exec := func(line string) string {
+		t.Helper()
+		out, err := c.Exec(line)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		return out
+	}
+	if out := exec("router"); !strings.Contains(out, "provider: openai") || !strings.Contains(out, "simple   gpt-4o-mini") {
+		t.Errorf("router:\n%s", out)
+	}
+	if out := exec("queue"); !strings.Contains(out, "queued 1") || !strings.Contains(out, "TELEGRAM/alice") || !strings.Contains(out, "summarize the inbox") {
+		t.Errorf("queue:\n%s", out)
+	}
+	if out := exec("patterns"); out != "no patterns yet" {
+		t.Errorf("patterns = %q", out)
+	}
+	exec("pattern record email 0.5 send the weekly report")
+	out := exec("pattern record email 1 send the weekly report")
+	if !strings.Contains(out, "count:       2") || !strings.Contains(out, "quality:     0.75") {
+		t.Errorf("pattern record:\n%s", out)
+	}
+	fp := pt.ComputeFingerprint("send the weekly report", "email")
+	if out := exec("pattern " + fp[:8]); !strings.Contains(out, fp) {
+		t.Errorf("pattern by prefix:\n%s", out)
+	}
+	if out := exec("heartbeat"); out != "heartbeat queued" || beats != 1 {
+		t.Errorf("heartbeat = %q, beats = %d", out, beats)
+	}
+	if _, err := c.Exec("components"); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("components without a supervisor: %v", err)
+	}
+	if _, err := c.Exec("frobnicate"); err == nil {
+		t.Error("unknown command accepted")
+	}
+}
+
+func TestDebugConsole_ServeHTTP(t *testing.T) {
+	c := &debugConsole{router: brain.NewModelRouter(), adminToken: "admin-secret"}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	client := srv.Client()
+
+	if _, err := debugExec(client, srv.URL, "", "router"); err == nil || !strings.Contains(err.Error(), "local admin") {
+		t.Errorf("without the admin token: %v", err)
+	}
+	out, err := debugExec(client, srv.URL, "admin-secret", "router")
+	if err != nil || !strings.Contains(out, "claude-haiku") {
+		t.Errorf("router = %q, %v", out, err)
+	}
+	if _, err := debugExec(client, srv.URL, "admin-secret", "queue"); err == nil || err.Error() != "queue: unavailable" {
+		t.Errorf("queue without a dispatcher: %v", err)
+	}
+
+	// Clients that are not local are refused even with the token.
+	req := httptest.NewRequest(http.MethodPost, "/debug/exec", strings.NewReader(`{"command":"router"}`))
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, req.WithContext(context.Background()))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("remote client: %d", rec.Code)
+	}
+}
+
+func TestDebugREPL(t *testing.T) {
+	var got []string
+	var out strings.Builder
+	debugREPL(strings.NewReader("help\n\nbad\nquit\nrouter\n"), &out, func(line string) (string, error) {
+		got = append(got, line)
+		if line == "bad" {
+			return "", errors.New("unknown command")
+		}
+		return "ok " + line, nil
+	})
+	if strings.Join(got, ",") != "help,bad" {
+		t.Errorf("executed %v, want to stop at quit", got)
+	}
+	if s := out.String(); !strings.Contains(s, "debug> ok help\n") || !strings.Contains(s, "error: unknown command\n") {
+		t.Errorf("output:\n%s", s)
+	}
+}
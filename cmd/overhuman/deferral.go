@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// budgetRetryDelay is how long a budget-deferred input waits before the
+// pipeline is asked again.
+const budgetRetryDelay = 15 * time.Minute
+
+// quotaRetryDelay is the wait when the provider does not say when its rate
+// limit refills.
+const quotaRetryDelay = time.Minute
+
+// deferredInput is an input queued for a later run.
+type deferredInput struct {
+	input   *senses.UnifiedInput
+	retryAt time.Time
+	reason  string // for the log
+	notice  string // sent to the user once
+}
+
+// deferral decides whether a failed run should be retried later: budget
+// deferrals wait budgetRetryDelay; rate-limit throttling and 429s wait
+// until the provider's limit refills (quotaResetAt, when known).
+func deferral(err error, in *senses.UnifiedInput, quotaResetAt, now time.Time) (deferredInput, bool) {
+	switch {
+	case errors.Is(err, pipeline.ErrDeferred):
+		return deferredInput{
+			input:   in,
+			retryAt: now.Add(budgetRetryDelay),
+			reason:  "budget is tight",
+			notice:  "Budget is tight, so this low-priority request is queued and will run once budget frees up.",
+		}, true
+	case errors.Is(err, pipeline.ErrThrottled), brain.IsRateLimitError(err):
+		retryAt := quotaResetAt
+		if !retryAt.After(now) {
+			retryAt = now.Add(quotaRetryDelay)
+		}
+		return deferredInput{
+			input:   in,
+			retryAt: retryAt,
+			reason:  "provider rate limit nearly exhausted",
+			notice:  "The model provider's rate limit is nearly used up, so this request is queued and will run once it resets.",
+		}, true
+	}
+	return deferredInput{}, false
+}
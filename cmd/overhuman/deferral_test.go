@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+func TestDeferral(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	in := senses.NewFromText("x")
+	reset := now.Add(20 * time.Second)
+
+	for _, tc := range []struct {
+		name      string
+		err       error
+		resetAt   time.Time
+		want      bool
+		wantRetry time.Time
+	}{
+		{"budget", pipeline.ErrDeferred, reset, true, now.Add(budgetRetryDelay)},
+		{"throttled", pipeline.ErrThrottled, reset, true, reset},
+		{"throttled, reset unknown", pipeline.ErrThrottled, time.Time{}, true, now.Add(quotaRetryDelay)},
+		{"429", fmt.Errorf("claude: API error 429: rate_limit_error: slow down"), reset, true, reset},
+		{"other error", errors.New("boom"), reset, false, time.Time{}},
+		{"success", nil, reset, false, time.Time{}},
+	} {
+		d, ok := deferral(tc.err, in, tc.resetAt, now)
+		if ok != tc.want || !d.retryAt.Equal(tc.wantRetry) {
+			t.Errorf("%s: deferral = (%v, %v), want (%v, %v)", tc.name, d.retryAt, ok, tc.wantRetry, tc.want)
+		}
+		if ok && (d.input != in || d.notice == "") {
+			t.Errorf("%s: deferred input = %+v", tc.name, d)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/documents"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// documentSettings is the "documents" block of config.json: large
+// documents are summarized chunk by chunk before they reach the pipeline,
+// e.g. {"threshold": 40000, "sources": ["file", "email"]}.
+type documentSettings struct {
+	// Disabled passes large documents on as they are.
+	Disabled bool `json:"disabled,omitempty"`
+	// Threshold is the length, in characters, from which a document is
+	// summarized. Default: 24000.
+	Threshold int `json:"threshold,omitempty"`
+	// ChunkChars is the size of a chunk. Default: 12000.
+	ChunkChars int `json:"chunk_chars,omitempty"`
+	// Parallel is how many chunks are summarized at once. Default: 4.
+	Parallel int `json:"parallel,omitempty"`
+	// MaxChunks caps the chunks summarized per document. Default: 100.
+	MaxChunks int `json:"max_chunks,omitempty"`
+	// Sources lists the channels whose inputs are summarized. Default:
+	// ["file"], the inbox.
+	Sources []string `json:"sources,omitempty"`
+}
+
+// validate checks the sizes and sources.
+func (s documentSettings) validate() error {
+	if s.Threshold < 0 || s.ChunkChars < 0 || s.Parallel < 0 || s.MaxChunks < 0 {
+		return fmt.Errorf("threshold, chunk_chars, parallel and max_chunks must not be negative")
+	}
+	for _, src := range s.Sources {
+		if senses.ParseSourceType(src) == "" {
+			return fmt.Errorf("unknown source %q", src)
+		}
+	}
+	return nil
+}
+
+// condenses reports whether inputs from input's channel are summarized.
+func (s documentSettings) condenses(input *senses.UnifiedInput) bool {
+	if len(s.Sources) == 0 {
+		return input.SourceType == senses.SourceFile
+	}
+	for _, src := range s.Sources {
+		if senses.ParseSourceType(src) == input.SourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// createSummarizer returns the document summarizer, with its cache in db,
+// or nil if it is disabled.
+func createSummarizer(cfg Config, db *sql.DB, deps pipeline.Dependencies) (*documents.Summarizer, error) {
+	s := cfg.Documents
+	if s.Disabled {
+		return nil, nil
+	}
+	return documents.Open(db, documents.Config{
+		LLM:             deps.LLM,
+		Router:          deps.Router,
+		BudgetRemaining: deps.Budget.EffectiveBudget,
+		Threshold:       s.Threshold,
+		ChunkChars:      s.ChunkChars,
+		Parallel:        s.Parallel,
+		MaxChunks:       s.MaxChunks,
+	})
+}
+
+// condenseDocument replaces a large document's payload with its summary,
+// charging the summarization to the budget. On failure the payload is
+// left as it is.
+func condenseDocument(ctx context.Context, docs *documents.Summarizer, spend *budget.Tracker, input *senses.UnifiedInput) {
+	if !docs.Needs(input.Payload) || !spend.CanSpend(0) {
+		return
+	}
+	name := input.SourceMeta.Extra["filename"]
+	if name == "" && input.SourceMeta.Path != "" {
+		name = filepath.Base(input.SourceMeta.Path)
+	}
+	if name == "" {
+		name = "the " + string(input.SourceType) + " input"
+	}
+	sum, err := docs.Summarize(ctx, name, input.Payload)
+	if sum != nil && sum.CostUSD > 0 {
+		spend.Record(input.InputID, sum.CostUSD)
+	}
+	if err != nil {
+		log.Printf("[documents] %s input %s: %v", input.SourceType, input.InputID, err)
+		return
+	}
+	log.Printf("[documents] summarized %s: %d chars in %d chunks (%d cached) cost=$%.4f", name, len(input.Payload), sum.Chunks, sum.Cached, sum.CostUSD)
+
+	where := ""
+	if input.SourceMeta.Path != "" {
+		where = " The full file is at " + input.SourceMeta.Path + "."
+	}
+	input.Payload = fmt.Sprintf("%s is too long to read in full (%d characters); this is a summary of it.%s\n\n%s",
+		name, len(input.Payload), where, sum.Text)
+	if input.SourceMeta.Extra == nil {
+		input.SourceMeta.Extra = make(map[string]string)
+	}
+	input.SourceMeta.Extra["summarized"] = "true"
+	input.SourceMeta.Extra["document_hash"] = sum.Hash
+	input.SourceMeta.Extra["chunks"] = strconv.Itoa(sum.Chunks)
+}
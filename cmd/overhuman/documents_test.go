@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/documents"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+func TestCondenseDocument(t *testing.T) {
+	llm := brain.NewFakeProvider(brain.FakeConfig{Default: "Quarterly numbers went up."})
+	docs := documents.New(documents.Config{LLM: llm, Threshold: 1000, ChunkChars: 800})
+	spend := budget.New(0, 0)
+
+	input := senses.NewUnifiedInput(senses.SourceFile, strings.Repeat("Revenue grew again this quarter. ", 100))
+	input.SourceMeta.Path = "/data/inbox/q3.pdf"
+	input.SourceMeta.Extra = map[string]string{"filename": "q3.pdf"}
+	condenseDocument(context.Background(), docs, spend, input)
+
+	if !strings.HasPrefix(input.Payload, "q3.pdf is too long to read in full") || !strings.HasSuffix(input.Payload, "Quarterly numbers went up.") {
+		t.Errorf("payload = %q", input.Payload)
+	}
+	if input.SourceMeta.Extra["summarized"] != "true" || input.SourceMeta.Extra["document_hash"] == "" {
+		t.Errorf("extra = %v", input.SourceMeta.Extra)
+	}
+
+	short := senses.NewUnifiedInput(senses.SourceFile, "a note")
+	condenseDocument(context.Background(), docs, spend, short)
+	if short.Payload != "a note" {
+		t.Errorf("short payload changed to %q", short.Payload)
+	}
+
+	settings := documentSettings{}
+	if !settings.condenses(input) || settings.condenses(senses.NewUnifiedInput(senses.SourceAPI, "")) {
+		t.Error("default sources should be the inbox only")
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/events"
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+// wireEvents publishes the events of subsystems that report through
+// callbacks (budget alerts, the offline fallback) on deps.Events and counts
+// every event in the metrics as "events.<topic>".
+func wireEvents(deps *pipeline.Dependencies) {
+	bus := deps.Events
+	if bus == nil {
+		return
+	}
+	if deps.Budget != nil {
+		deps.Budget.OnThreshold(func(ev budget.ThresholdEvent) {
+			bus.Publish(events.BudgetThreshold, ev)
+		})
+	}
+	if fb, ok := deps.LLM.(*brain.FallbackProvider); ok {
+		mode := deps.Mode
+		fb.OnChange(func(st brain.FallbackStatus) {
+			msg := fallbackNotice(st, time.Now())
+			if st.Degraded {
+				log.Printf("[brain] %s: %s", msg, st.Reason)
+				mode.SetDegraded(msg)
+				bus.Publish(events.ProviderDegraded, st)
+				return
+			}
+			log.Printf("[brain] %s", msg)
+			mode.SetDegraded("")
+			bus.Publish(events.ProviderRestored, st)
+		})
+	}
+	if m := deps.Metrics; m != nil {
+		bus.Subscribe("*", func(ev events.Event) {
+			m.Increment("events." + ev.Topic)
+		})
+	}
+}
+
+// fallbackNotice describes a switch to or back from the offline fallback
+// for the banner and the log.
+func fallbackNotice(st brain.FallbackStatus, now time.Time) string {
+	if st.Degraded {
+		return fmt.Sprintf("Degraded mode: %s is failing, answering with %s", st.Primary, st.Fallback)
+	}
+	return fmt.Sprintf("%s is reachable again after %s; degraded mode is over", st.Primary, now.Sub(st.Since).Round(time.Second))
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// transcriptsPath is where completed exchanges are recorded.
+func transcriptsPath(cfg Config) string {
+	return filepath.Join(cfg.DataDir, "transcripts.jsonl")
+}
+
+// exportOptions are the flags of `overhuman export-chat`.
+type exportOptions struct {
+	Filter memory.TranscriptFilter
+	Format string // "md" or "json"
+	Out    string // file path; "" = stdout
+}
+
+// parseExportArgs parses --sender, --session, --since, --until, --format
+// and --out.
+func parseExportArgs(args []string, now time.Time) (exportOptions, error) {
+	opts := exportOptions{Format: "md"}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--sender", "--session", "--since", "--until", "--format", "--out", "-o":
+		default:
+			return opts, fmt.Errorf("unknown flag %q", args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--sender":
+			opts.Filter.Sender = value
+		case "--session":
+			opts.Filter.SessionID = value
+		case "--since", "--until":
+			t, err := parseExportTime(value, now)
+			if err != nil {
+				return opts, fmt.Errorf("%s: %w", name, err)
+			}
+			if name == "--since" {
+				opts.Filter.Since = t
+			} else {
+				opts.Filter.Until = t
+			}
+		case "--format":
+			if value != "md" && value != "json" {
+				return opts, fmt.Errorf("--format: want md or json, got %q", value)
+			}
+			opts.Format = value
+		case "--out", "-o":
+			opts.Out = value
+		}
+	}
+	return opts, nil
+}
+
+// parseExportTime accepts a date (2026-03-01, local midnight), an RFC 3339
+// timestamp, or an age like 7d or 12h counted back from now.
+func parseExportTime(v string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", v, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("want a date (2006-01-02), RFC 3339 time or age like 7d, got %q", v)
+}
+
+// runExportChat handles `overhuman export-chat`.
+func runExportChat(args []string) {
+	opts, err := parseExportArgs(args, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export-chat: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Usage: overhuman export-chat [--sender X] [--session ID] [--since DATE] [--until DATE] [--format md|json] [--out FILE]")
+		os.Exit(2)
+	}
+	cfg := loadConfig()
+	transcripts, err := memory.NewTranscriptLog(transcriptsPath(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export-chat: %v\n", err)
+		os.Exit(1)
+	}
+	entries, err := transcripts.Query(opts.Filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export-chat: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := io.Writer(os.Stdout)
+	if opts.Out != "" {
+		f, err := os.OpenFile(opts.Out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export-chat: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeTranscript(w, entries, opts.Format, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "export-chat: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.Out != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d exchange(s) to %s\n", len(entries), opts.Out)
+	}
+}
+
+// transcriptExport is the JSON export format.
+type transcriptExport struct {
+	ExportedAt   time.Time                `json:"exported_at"`
+	Exchanges    int                      `json:"exchanges"`
+	TotalCostUSD float64                  `json:"total_cost_usd"`
+	Entries      []memory.TranscriptEntry `json:"entries"`
+}
+
+// writeTranscript renders entries as Markdown ("md") or JSON.
+func writeTranscript(w io.Writer, entries []memory.TranscriptEntry, format string, now time.Time) error {
+	var total float64
+	for _, e := range entries {
+		total += e.CostUSD
+	}
+	if format == "json" {
+		if entries == nil {
+			entries = []memory.TranscriptEntry{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(transcriptExport{ExportedAt: now.UTC(), Exchanges: len(entries), TotalCostUSD: total, Entries: entries})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Conversation export\n\nExported %s · %d exchange(s) · total cost $%.4f\n",
+		now.Format("2006-01-02 15:04 MST"), len(entries), total)
+	for _, e := range entries {
+		who := e.Channel
+		if e.Sender != "" {
+			who += " · " + e.Sender
+		}
+		fmt.Fprintf(&b, "\n## %s · %s\n\n", e.Time.In(now.Location()).Format("2006-01-02 15:04"), who)
+		fmt.Fprintf(&b, "**User:**\n\n%s\n\n**Agent:**\n\n%s\n\n", strings.TrimSpace(e.Input), strings.TrimSpace(e.Result))
+		fmt.Fprintf(&b, "_Task %s · cost $%.4f · quality %.0f%% · %s_\n",
+			e.TaskID, e.CostUSD, e.Quality*100, (time.Duration(e.ElapsedMs) * time.Millisecond).Round(100*time.Millisecond))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// exportChatHandler serves GET /api/chat/export as a download. It takes
+// the export-chat flags as query parameters: sender, session, since,
+// until and format. In team mode non-admins get their own messages only.
+func exportChatHandler(transcripts *memory.TranscriptLog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var args []string
+		for _, k := range []string{"sender", "session", "since", "until", "format"} {
+			if v := q.Get(k); v != "" {
+				args = append(args, "--"+k+"="+v)
+			}
+		}
+		now := time.Now()
+		opts, err := parseExportArgs(args, now)
+		if err != nil {
+			writeExportError(w, http.StatusBadRequest, err)
+			return
+		}
+		if p, ok := security.PrincipalFrom(r.Context()); ok && !p.Role.Allows(security.RoleAdmin) {
+			// Team members only see their own history.
+			opts.Filter.Sender = p.Name
+		}
+		entries, err := transcripts.Query(opts.Filter)
+		if err != nil {
+			writeExportError(w, http.StatusInternalServerError, err)
+			return
+		}
+		ext, ctype := "md", "text/markdown; charset=utf-8"
+		if opts.Format == "json" {
+			ext, ctype = "json", "application/json"
+		}
+		w.Header().Set("Content-Type", ctype)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-chat-%s.%s"`, appName, now.Format("20060102"), ext))
+		writeTranscript(w, entries, opts.Format, now)
+	}
+}
+
+func writeExportError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestParseExportArgs(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	opts, err := parseExportArgs([]string{"--sender", "alice", "--since=2026-03-01", "--until", "2d", "--format", "json", "-o", "out.json"}, now)
+	if err != nil {
+		t.Fatalf("parseExportArgs: %v", err)
+	}
+	if opts.Filter.Sender != "alice" || opts.Format != "json" || opts.Out != "out.json" {
+		t.Errorf("opts = %+v", opts)
+	}
+	if !opts.Filter.Since.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !opts.Filter.Until.Equal(now.AddDate(0, 0, -2)) {
+		t.Errorf("window = %v .. %v", opts.Filter.Since, opts.Filter.Until)
+	}
+	if opts, _ := parseExportArgs(nil, now); opts.Format != "md" {
+		t.Errorf("default format = %q", opts.Format)
+	}
+	for _, args := range [][]string{{"--format", "pdf"}, {"--since", "yesterday"}, {"--sender"}, {"--verbose"}} {
+		if _, err := parseExportArgs(args, now); err == nil {
+			t.Errorf("%v: no error", args)
+		}
+	}
+}
+
+func TestWriteTranscript(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	entries := []memory.TranscriptEntry{{
+		Time: now.Add(-time.Hour), TaskID: "t1", Sender: "alice", Channel: "telegram",
+		Input: "What's the weather?", Result: "Sunny.", CostUSD: 0.0012, Quality: 0.9, ElapsedMs: 1500,
+	}}
+
+	var md bytes.Buffer
+	if err := writeTranscript(&md, entries, "md", now); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1 exchange(s) · total cost $0.0012", "## 2026-03-10 14:00 · telegram · alice", "**User:**\n\nWhat's the weather?", "**Agent:**\n\nSunny.", "quality 90% · 1.5s"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := writeTranscript(&js, nil, "json", now); err != nil {
+		t.Fatal(err)
+	}
+	var exp transcriptExport
+	if err := json.Unmarshal(js.Bytes(), &exp); err != nil || exp.Entries == nil || exp.Exchanges != 0 {
+		t.Errorf("empty json export = %s (%v)", js.String(), err)
+	}
+}
+
+func TestExportChatHandler(t *testing.T) {
+	l, err := memory.NewTranscriptLog(filepath.Join(t.TempDir(), "transcripts.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Append(memory.TranscriptEntry{Time: time.Now(), TaskID: "t1", Sender: "bob", Channel: "api", Input: "hi", Result: "hello"})
+	h := exportChatHandler(l)
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/api/chat/export?format=json&sender=bob", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), ".json") {
+		t.Fatalf("status %d, headers %v", w.Code, w.Header())
+	}
+	var exp transcriptExport
+	if err := json.Unmarshal(w.Body.Bytes(), &exp); err != nil || exp.Exchanges != 1 {
+		t.Errorf("body = %s", w.Body)
+	}
+
+	// In team mode a member only gets their own messages.
+	req := httptest.NewRequest("GET", "/api/chat/export?format=json&sender=bob", nil)
+	req = req.WithContext(security.WithPrincipal(req.Context(), security.Principal{Name: "ben", Role: security.RoleMember}))
+	w = httptest.NewRecorder()
+	h(w, req)
+	exp = transcriptExport{}
+	if err := json.Unmarshal(w.Body.Bytes(), &exp); err != nil || exp.Exchanges != 0 {
+		t.Errorf("member export of another sender = %s", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/api/chat/export?since=soon", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad since: status %d", w.Code)
+	}
+}
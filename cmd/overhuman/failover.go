@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// fallbackSettings is an entry of the "fallbacks" list of config.json: a
+// provider that answers while the ones before it are failing, e.g.
+// {"provider": "openai", "model": "gpt-4o-mini"}.
+type fallbackSettings struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	BaseURL  string `json:"base_url,omitempty"`
+	// APIKey defaults to the provider's usual key (OPENAI_API_KEY, ...);
+	// it may be encrypted (enc:v1:...).
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// validate checks the provider and the key.
+func (fb fallbackSettings) validate() error {
+	if fb.Provider == "" {
+		return fmt.Errorf("provider is required")
+	}
+	if _, err := decryptConfigSecret(fb.APIKey); err != nil {
+		return fmt.Errorf("api_key: %v", err)
+	}
+	return nil
+}
+
+// validateRetry checks an "llm_retry" block.
+func validateRetry(p brain.RetryPolicy) error {
+	if p.MaxRetries < -1 {
+		return fmt.Errorf("max_retries must be -1 (off) or more")
+	}
+	if p.BaseDelayMs < 0 || p.MaxDelayMs < 0 {
+		return fmt.Errorf("delays must not be negative")
+	}
+	return nil
+}
+
+// retryPolicy is the configured retry policy of the LLM providers:
+// brain.DefaultRetryPolicy unless set, none with max_retries -1.
+func retryPolicy(cfg Config) brain.RetryPolicy {
+	p := cfg.LLMRetry
+	switch {
+	case p.MaxRetries < 0:
+		return brain.RetryPolicy{}
+	case p.MaxRetries == 0:
+		p.MaxRetries = brain.DefaultRetryPolicy.MaxRetries
+	}
+	if p.BaseDelayMs == 0 {
+		p.BaseDelayMs = brain.DefaultRetryPolicy.BaseDelayMs
+	}
+	if p.MaxDelayMs == 0 {
+		p.MaxDelayMs = brain.DefaultRetryPolicy.MaxDelayMs
+	}
+	return p
+}
+
+// createFallbackChain creates the providers of the "fallbacks" list, then
+// the offline fallback, in the order they are tried. Providers that can't
+// be created are left out.
+func createFallbackChain(cfg Config) []brain.LLMProvider {
+	var chain []brain.LLMProvider
+	for i, fb := range cfg.Fallbacks {
+		key, err := decryptConfigSecret(fb.APIKey)
+		if err == nil {
+			sub := cfg
+			sub.LLMProvider = fb.Provider
+			sub.LLMModel = fb.Model
+			sub.LLMBaseURL = fb.BaseURL
+			sub.LLMAPIKey = key
+			var p brain.LLMProvider
+			if p, _, err = createNamedProvider(sub); err == nil {
+				chain = append(chain, p)
+				continue
+			}
+		}
+		log.Printf("[bootstrap] fallback %d (%s) disabled: %v", i+1, fb.Provider, err)
+	}
+	if fb, err := createFallbackProvider(cfg); err != nil {
+		log.Printf("[bootstrap] offline fallback disabled: %v", err)
+	} else if fb != nil {
+		chain = append(chain, fb)
+	}
+	return chain
+}
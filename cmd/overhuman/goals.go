@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/goals"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// goalsUsage lists the subcommands of goalsCommand.
+const goalsUsage = "usage: goals [list [STATUS]|add [--priority P] DESCRIPTION|priority ID P|complete ID|delete ID]"
+
+// goalsCommand runs a goals command against the daemon's API server at
+// addr and returns what to print:
+//
+//	list [STATUS]               the goals, next to be worked on first
+//	add [--priority P] TEXT     a goal of yours (priority low, normal, high
+//	                            or critical; default normal)
+//	priority ID P               change a goal's priority
+//	complete ID                 mark a goal done
+//	delete ID                   forget a goal
+func goalsCommand(client *http.Client, addr, adminToken string, args []string) (string, error) {
+	sub := "list"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	call := func(method, path string, body, out any) error {
+		return daemonCall(client, addr, adminToken, method, path, body, out)
+	}
+	goalPath := func(id string) string { return "/goals/" + url.PathEscape(id) }
+
+	switch {
+	case sub == "list":
+		path := "/goals"
+		if len(args) > 1 {
+			path += "?status=" + url.QueryEscape(args[1])
+		}
+		var res struct {
+			Goals []goals.Goal `json:"goals"`
+		}
+		if err := call(http.MethodGet, path, nil, &res); err != nil {
+			return "", err
+		}
+		return formatGoals(res.Goals), nil
+	case sub == "add" && len(args) > 1:
+		req := map[string]string{}
+		words := args[1:]
+		if len(words) > 2 && (words[0] == "--priority" || words[0] == "-p") {
+			req["priority"], words = words[1], words[2:]
+		}
+		req["description"] = strings.Join(words, " ")
+		var g goals.Goal
+		if err := call(http.MethodPost, "/goals", req, &g); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s added (%s)", g.ID, g.Priority), nil
+	case sub == "priority" && len(args) > 2:
+		var g goals.Goal
+		if err := call(http.MethodPost, goalPath(args[1])+"/priority", map[string]string{"priority": args[2]}, &g); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s is now %s priority", g.ID, g.Priority), nil
+	case sub == "complete" && len(args) > 1:
+		var g goals.Goal
+		if err := call(http.MethodPost, goalPath(args[1])+"/complete", nil, &g); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s is now %s", g.ID, g.Status), nil
+	case sub == "delete" && len(args) > 1:
+		var res map[string]string
+		if err := call(http.MethodDelete, goalPath(args[1]), nil, &res); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s deleted", args[1]), nil
+	}
+	return "", fmt.Errorf("%s", goalsUsage)
+}
+
+// formatGoals lists goals one per line for the CLI.
+func formatGoals(list []goals.Goal) string {
+	if len(list) == 0 {
+		return "No goals."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-10s %-8s %-11s %-10s %8s  %s\n", "ID", "PRIORITY", "STATUS", "SOURCE", "ATTEMPTS", "DESCRIPTION")
+	for _, g := range list {
+		fmt.Fprintf(&b, "%-10s %-8s %-11s %-10s %8d  %s\n", g.ID, g.Priority, g.Status, g.Source, g.Attempts, truncateText(g.Description, 80))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Goals are worked on by the heartbeat: instead of a plain heartbeat it
+// sends the brief of the top pending goal, on the goal channel. Code-skill
+// goals are left to the skill generator.
+const (
+	goalChannel = "goal"
+	extraGoalID = "goal_id"
+)
+
+// nextGoalInput returns the input for the top pending goal, or nil if
+// there is none.
+func nextGoalInput(engine *goals.Engine) *senses.UnifiedInput {
+	if engine == nil {
+		return nil
+	}
+	g := engine.NextPendingExcept(goals.GoalSourcePattern)
+	if g == nil {
+		return nil
+	}
+	in := senses.NewUnifiedInput(senses.SourceTimer, g.Brief())
+	in.SourceMeta.Channel = goalChannel
+	in.SourceMeta.Sender = g.ID
+	in.SourceMeta.Extra = map[string]string{extraGoalID: g.ID}
+	return in
+}
+
+// settleGoal records the outcome of a goal's run: completed when it
+// succeeded, failed otherwise, which puts it back in the queue until it
+// runs out of attempts. Goals completed or deleted meanwhile, runs parked
+// on an approval, and other inputs are left alone.
+func settleGoal(engine *goals.Engine, input *senses.UnifiedInput, result *pipeline.RunResult, err error) {
+	id := input.SourceMeta.Extra[extraGoalID]
+	if engine == nil || id == "" || input.SourceType != senses.SourceTimer || errors.Is(err, pipeline.ErrAwaitingApproval) {
+		return
+	}
+	if g := engine.Get(id); g == nil || g.Status != goals.GoalStatusInProgress {
+		return
+	}
+	if err == nil && result != nil && result.Success && !result.Declined {
+		engine.MarkCompleted(id)
+		log.Printf("[goals] %s completed (task %s)", id, result.TaskID)
+		return
+	}
+	engine.MarkFailed(id)
+	log.Printf("[goals] %s attempt failed", id)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/goals"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+func TestGoalsCommand(t *testing.T) {
+	engine := goals.New()
+	mux := http.NewServeMux()
+	engine.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	run := func(args ...string) (string, error) {
+		return goalsCommand(srv.Client(), srv.Listener.Addr().String(), "", args)
+	}
+
+	if out, err := run(); err != nil || out != "No goals." {
+		t.Errorf("empty list = %q, %v", out, err)
+	}
+	if out, err := run("add", "--priority", "high", "Summarize", "the", "week"); err != nil || out != "goal_1 added (high)" {
+		t.Errorf("add = %q, %v", out, err)
+	}
+	if g := engine.Get("goal_1"); g == nil || g.Description != "Summarize the week" || g.Source != goals.GoalSourceUser {
+		t.Errorf("added goal = %+v", g)
+	}
+	out, err := run("list")
+	if err != nil || !strings.Contains(out, "goal_1") || !strings.Contains(out, "Summarize the week") {
+		t.Errorf("list = %q, %v", out, err)
+	}
+	if out, err := run("priority", "goal_1", "low"); err != nil || out != "goal_1 is now low priority" {
+		t.Errorf("priority = %q, %v", out, err)
+	}
+	if _, err := run("priority", "goal_1", "asap"); err == nil || !strings.Contains(err.Error(), "not low, normal, high or critical") {
+		t.Errorf("bad priority: %v", err)
+	}
+	if out, err := run("complete", "goal_1"); err != nil || out != "goal_1 is now COMPLETED" {
+		t.Errorf("complete = %q, %v", out, err)
+	}
+	if out, err := run("list", "pending"); err != nil || out != "No goals." {
+		t.Errorf("pending = %q, %v", out, err)
+	}
+	if _, err := run("delete", "goal_1"); err != nil || engine.Get("goal_1") != nil {
+		t.Errorf("delete: %v", err)
+	}
+	if _, err := run("delete", "goal_1"); err == nil || err.Error() != "goal not found" {
+		t.Errorf("delete again: %v", err)
+	}
+	if _, err := run("add"); err == nil || err.Error() != goalsUsage {
+		t.Errorf("add without text: %v", err)
+	}
+
+	srv.Close()
+	if _, err := run(); !errors.Is(err, errDaemonDown) {
+		t.Errorf("daemon down: %v", err)
+	}
+}
+
+func TestHeartbeatGoals(t *testing.T) {
+	engine := goals.New()
+	if nextGoalInput(engine) != nil {
+		t.Fatal("input without goals")
+	}
+	engine.Add("Generate code-skill for pattern abc", goals.GoalSourcePattern, goals.GoalPriorityCritical)
+	g := engine.Add("Check the backups", goals.GoalSourceUser, goals.GoalPriorityNormal)
+
+	in := nextGoalInput(engine)
+	if in == nil || in.Payload != "Check the backups" || in.SourceType != senses.SourceTimer || in.SourceMeta.Extra[extraGoalID] != g.ID {
+		t.Fatalf("input = %+v, want the user goal (code-skill goals are the generator's)", in)
+	}
+
+	engine.MarkInProgress(g.ID, "")
+	settleGoal(engine, in, nil, errors.New("provider down"))
+	if g.Status != goals.GoalStatusPending || g.Attempts != 1 {
+		t.Errorf("after a failed run: %s, %d attempts", g.Status, g.Attempts)
+	}
+	engine.MarkInProgress(g.ID, "")
+	settleGoal(engine, in, &pipeline.RunResult{TaskID: "task_1", AwaitingApproval: "apr_1"}, pipeline.ErrAwaitingApproval)
+	if g.Status != goals.GoalStatusInProgress {
+		t.Errorf("after a parked run: %s", g.Status)
+	}
+	settleGoal(engine, in, &pipeline.RunResult{TaskID: "task_1", Success: true}, nil)
+	if g.Status != goals.GoalStatusCompleted {
+		t.Errorf("after a successful run: %s", g.Status)
+	}
+
+	// A goal cancelled while it ran stays cancelled.
+	g2 := engine.Add("Tidy the inbox", goals.GoalSourceUser, goals.GoalPriorityLow)
+	in = nextGoalInput(engine)
+	engine.MarkInProgress(g2.ID, "")
+	engine.Cancel(g2.ID)
+	settleGoal(engine, in, nil, errors.New("cancelled"))
+	if g2.Status != goals.GoalStatusCancelled {
+		t.Errorf("cancelled goal is now %s", g2.Status)
+	}
+}
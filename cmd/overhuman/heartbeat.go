@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// parseHeartbeatBounds parses the heartbeat_min/max settings. Empty values
+// come back as zero, which the schedule treats as its defaults.
+func parseHeartbeatBounds(minSpec, maxSpec string) (lo, hi time.Duration, err error) {
+	parse := func(name, spec string) (time.Duration, error) {
+		if spec == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(spec)
+		if err != nil || d < time.Minute {
+			return 0, fmt.Errorf("%s: want a duration of at least 1m, got %q", name, spec)
+		}
+		return d, nil
+	}
+	if lo, err = parse("heartbeat_min", minSpec); err != nil {
+		return 0, 0, err
+	}
+	if hi, err = parse("heartbeat_max", maxSpec); err != nil {
+		return 0, 0, err
+	}
+	if lo > 0 && hi > 0 && hi < lo {
+		return 0, 0, fmt.Errorf("heartbeat_max %s is below heartbeat_min %s", hi, lo)
+	}
+	return lo, hi, nil
+}
+
+// runHeartbeat calls beat whenever sched says a heartbeat is due, and
+// reschedules early when activity shortens the interval.
+func runHeartbeat(ctx context.Context, sched *senses.HeartbeatSchedule, beat func()) {
+	for {
+		timer := time.NewTimer(time.Until(sched.Plan(time.Now()).NextAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-sched.Changed():
+			timer.Stop()
+		case now := <-timer.C:
+			sched.Beat(now)
+			beat()
+		}
+	}
+}
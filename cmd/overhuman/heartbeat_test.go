@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHeartbeatBounds(t *testing.T) {
+	lo, hi, err := parseHeartbeatBounds("5m", "3h")
+	if err != nil || lo != 5*time.Minute || hi != 3*time.Hour {
+		t.Errorf("got %v, %v, %v", lo, hi, err)
+	}
+	if lo, hi, err := parseHeartbeatBounds("", ""); err != nil || lo != 0 || hi != 0 {
+		t.Errorf("empty = %v, %v, %v; want defaults (zero)", lo, hi, err)
+	}
+	for _, bad := range [][2]string{{"soon", ""}, {"10s", ""}, {"", "-1h"}, {"2h", "1h"}} {
+		if _, _, err := parseHeartbeatBounds(bad[0], bad[1]); err == nil {
+			t.Errorf("parseHeartbeatBounds(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/deploy"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+// Housekeeping limits.
+const (
+	logRotateBytes            = 10 << 20 // rotate overhuman.log at 10 MiB
+	logKeep                   = 5        // rotated logs kept
+	backupsKeep               = 3        // binary backups kept by `update`
+	defaultAuditRetentionDays = 90
+)
+
+// housekeepingTask is a built-in maintenance job. It runs on an idle
+// heartbeat once every has passed since its last run; run returns a short
+// summary of what it did.
+type housekeepingTask struct {
+	name  string
+	every time.Duration
+	run   func(ctx context.Context) (string, error)
+}
+
+// housekeepingStatus is a task's state, reported by GET /health.
+type housekeepingStatus struct {
+	Task    string    `json:"task"`
+	Every   string    `json:"every"`
+	LastRun time.Time `json:"last_run"` // zero = never
+	NextDue time.Time `json:"next_due"`
+	Result  string    `json:"result,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// housekeeper runs the housekeeping tasks opportunistically, so the daemon
+// needs no cron job or manual command to stay tidy. Last runs are kept in
+// the database's maintenance table and survive restarts.
+type housekeeper struct {
+	tasks []housekeepingTask
+	store *memory.LongTermMemory // may be nil
+
+	mu      sync.Mutex
+	status  map[string]*housekeepingStatus
+	running bool
+}
+
+func newHousekeeper(store *memory.LongTermMemory, tasks []housekeepingTask) *housekeeper {
+	h := &housekeeper{tasks: tasks, store: store, status: make(map[string]*housekeepingStatus)}
+	var runs map[string]time.Time
+	if store != nil {
+		var err error
+		if runs, err = store.MaintenanceRuns(); err != nil {
+			log.Printf("[housekeeping] %v", err)
+		}
+	}
+	for _, t := range tasks {
+		last := runs[housekeepingKey(t.name)]
+		h.status[t.name] = &housekeepingStatus{Task: t.name, Every: t.every.String(), LastRun: last, NextDue: last.Add(t.every)}
+	}
+	return h
+}
+
+// housekeepingKey is the maintenance table entry of a task.
+func housekeepingKey(task string) string { return "housekeeping:" + task }
+
+// RunDue starts the tasks that are due at now in the background, unless
+// a previous round is still running.
+func (h *housekeeper) RunDue(ctx context.Context, now time.Time) {
+	h.mu.Lock()
+	if h.running {
+		h.mu.Unlock()
+		return
+	}
+	h.running = true
+	h.mu.Unlock()
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			h.running = false
+			h.mu.Unlock()
+		}()
+		h.runDue(ctx, now)
+	}()
+}
+
+// runDue runs the tasks due at now, one after another.
+func (h *housekeeper) runDue(ctx context.Context, now time.Time) {
+	for _, t := range h.tasks {
+		h.mu.Lock()
+		due := !now.Before(h.status[t.name].NextDue)
+		h.mu.Unlock()
+		if !due || ctx.Err() != nil {
+			continue
+		}
+		result, err := t.run(ctx)
+		ran := time.Now()
+		h.mu.Lock()
+		st := h.status[t.name]
+		st.LastRun, st.NextDue, st.Result, st.Error = ran, ran.Add(t.every), result, ""
+		if err != nil {
+			st.Error = err.Error()
+		}
+		h.mu.Unlock()
+		if err != nil {
+			log.Printf("[housekeeping] %s: %v", t.name, err)
+		} else if result != "" {
+			log.Printf("[housekeeping] %s: %s", t.name, result)
+		}
+		if h.store != nil {
+			if err := h.store.RecordMaintenance(housekeepingKey(t.name), ran); err != nil {
+				log.Printf("[housekeeping] record %s: %v", t.name, err)
+			}
+		}
+	}
+}
+
+// Status returns every task's state, in run order.
+func (h *housekeeper) Status() []housekeepingStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]housekeepingStatus, 0, len(h.tasks))
+	for _, t := range h.tasks {
+		out = append(out, *h.status[t.name])
+	}
+	return out
+}
+
+// housekeepingTasks returns the built-in tasks: database optimize/vacuum,
+// log rotation, memory consolidation, backup rotation, scratch cleanup,
+// audit retention and skill example re-runs. logFile may be nil (logging to stdout only).
+func housekeepingTasks(cfg Config, deps pipeline.Dependencies, logFile *deploy.LogFile) []housekeepingTask {
+	day := 24 * time.Hour
+	tasks := []housekeepingTask{{
+		name:  "db_maintenance",
+		every: dbMaintenanceInterval,
+		run: func(ctx context.Context) (string, error) {
+			res, err := deps.LongTerm.Maintain(ctx, false)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("optimized, vacuumed=%v, reclaimed %d KB", res.Vacuumed, res.ReclaimedBytes/1024), nil
+		},
+	}}
+	if logFile != nil {
+		tasks = append(tasks, housekeepingTask{
+			name:  "log_rotation",
+			every: time.Hour,
+			run: func(context.Context) (string, error) {
+				rotated, err := logFile.Rotate(logRotateBytes, logKeep)
+				if err != nil || !rotated {
+					return "", err
+				}
+				return "rotated " + logFile.Path(), nil
+			},
+		})
+	}
+	tasks = append(tasks,
+		housekeepingTask{
+			name:  "memory_consolidation",
+			every: day,
+			run: func(ctx context.Context) (string, error) {
+				n, err := deps.LongTerm.Consolidate(ctx)
+				if err != nil || n == 0 {
+					return "", err
+				}
+				return fmt.Sprintf("merged %d duplicate memories", n), nil
+			},
+		},
+		housekeepingTask{
+			name:  "backup_rotation",
+			every: day,
+			run: func(context.Context) (string, error) {
+				removed, err := deploy.PruneBackups(cfg.DataDir, backupsKeep)
+				if len(removed) == 0 {
+					return "", err
+				}
+				return "removed " + strings.Join(removed, ", "), err
+			},
+		},
+	)
+	if deps.Scratch.Dir != "" {
+		tasks = append(tasks, housekeepingTask{
+			name:  "scratch_cleanup",
+			every: time.Hour,
+			run: func(context.Context) (string, error) {
+				n, err := instruments.PruneScratch(deps.Scratch.Dir, time.Now().Add(-day))
+				if err != nil || n == 0 {
+					return "", err
+				}
+				return fmt.Sprintf("removed %d abandoned scratch directories", n), nil
+			},
+		})
+	}
+	if days := auditRetentionDays(cfg); days > 0 && deps.AuditLog != nil {
+		tasks = append(tasks, housekeepingTask{
+			name:  "audit_retention",
+			every: day,
+			run: func(context.Context) (string, error) {
+				n, err := deps.AuditLog.Prune(time.Now().AddDate(0, 0, -days))
+				if err != nil || n == 0 {
+					return "", err
+				}
+				return fmt.Sprintf("pruned %d events older than %d days", n, days), nil
+			},
+		})
+	}
+	if deps.Skills != nil {
+		tasks = append(tasks, housekeepingTask{
+			name:  "skill_tests",
+			every: day,
+			run: func(ctx context.Context) (string, error) {
+				return runSkillExamples(ctx, deps.Skills)
+			},
+		})
+	}
+	return tasks
+}
+
+// auditRetentionDays is how long audit events are kept (0 = forever).
+func auditRetentionDays(cfg Config) int {
+	switch {
+	case cfg.AuditRetentionDays < 0:
+		return 0
+	case cfg.AuditRetentionDays == 0:
+		return defaultAuditRetentionDays
+	}
+	return cfg.AuditRetentionDays
+}
+
+// runSkillExamples re-runs the documented examples of the code skills
+// that need no permissions and reports the ones whose output no longer
+// contains the documented result.
+func runSkillExamples(ctx context.Context, reg *instruments.SkillRegistry) (string, error) {
+	tested := 0
+	var failing []string
+	for _, sk := range reg.List() {
+		doc := sk.Meta.Doc
+		if sk.Meta.Type != instruments.SkillTypeCode || sk.Meta.Status == instruments.SkillStatusDeprecated ||
+			doc == nil || len(doc.Permissions) > 0 {
+			continue
+		}
+		for i, ex := range doc.Examples {
+			if ex.Output == "" {
+				continue
+			}
+			tested++
+			out, err := sk.Executor.Execute(ctx, instruments.SkillInput{Goal: ex.Input})
+			if err != nil || out == nil || !out.Success || !strings.Contains(out.Result, ex.Output) {
+				failing = append(failing, fmt.Sprintf("%s (example %d)", sk.Meta.ID, i+1))
+			}
+		}
+	}
+	if tested == 0 {
+		return "", nil
+	}
+	if len(failing) > 0 {
+		return "", fmt.Errorf("%d of %d skill examples failing: %s", len(failing), tested, strings.Join(failing, ", "))
+	}
+	return fmt.Sprintf("%d skill examples passed", tested), nil
+}
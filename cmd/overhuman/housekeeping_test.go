@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+func TestHousekeeper_RunDue(t *testing.T) {
+	ltm, err := memory.NewLongTermMemory(filepath.Join(t.TempDir(), "overhuman.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ltm.Close()
+
+	runs := map[string]int{}
+	tasks := []housekeepingTask{
+		{name: "hourly", every: time.Hour, run: func(context.Context) (string, error) { runs["hourly"]++; return "done", nil }},
+		{name: "daily", every: 24 * time.Hour, run: func(context.Context) (string, error) { runs["daily"]++; return "", errors.New("disk full") }},
+	}
+	h := newHousekeeper(ltm, tasks)
+	now := time.Now()
+
+	h.runDue(context.Background(), now)
+	if runs["hourly"] != 1 || runs["daily"] != 1 {
+		t.Fatalf("first round runs = %v, want every task once", runs)
+	}
+	st := h.Status()
+	if len(st) != 2 || st[0].Result != "done" || st[1].Error != "disk full" || st[0].LastRun.IsZero() || st[0].Every != "1h0m0s" {
+		t.Errorf("Status = %+v", st)
+	}
+
+	h.runDue(context.Background(), now.Add(30*time.Minute))
+	h.runDue(context.Background(), now.Add(90*time.Minute))
+	if runs["hourly"] != 2 || runs["daily"] != 1 {
+		t.Errorf("runs = %v, want hourly twice and daily once", runs)
+	}
+
+	// Last runs survive a restart.
+	h2 := newHousekeeper(ltm, tasks)
+	h2.runDue(context.Background(), now.Add(2*time.Hour))
+	if runs["daily"] != 1 {
+		t.Error("daily task re-ran after a restart")
+	}
+	if st := h2.Status(); st[1].LastRun.IsZero() || !st[1].NextDue.After(now.Add(23*time.Hour)) {
+		t.Errorf("restored status = %+v", st[1])
+	}
+}
+
+func TestRunSkillExamples(t *testing.T) {
+	reg := instruments.NewSkillRegistry()
+	upper := instruments.NewCodeSkill(func(_ context.Context, in instruments.SkillInput) (*instruments.SkillOutput, error) {
+		return &instruments.SkillOutput{Result: strings.ToUpper(in.Goal), Success: true}, nil
+	}, "go", "")
+	doc := func(perms []string, ex ...instruments.SkillExample) *instruments.SkillDoc {
+		return &instruments.SkillDoc{Examples: ex, Permissions: perms}
+	}
+	reg.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "upper", Type: instruments.SkillTypeCode, Status: instruments.SkillStatusActive,
+		Doc: doc(nil, instruments.SkillExample{Input: "abc", Output: "ABC"}, instruments.SkillExample{Input: "no output"})}, Executor: upper})
+	if got, err := runSkillExamples(context.Background(), reg); err != nil || got != "1 skill examples passed" {
+		t.Errorf("runSkillExamples = %q, %v", got, err)
+	}
+
+	reg.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "broken", Type: instruments.SkillTypeCode, Status: instruments.SkillStatusActive,
+		Doc: doc(nil, instruments.SkillExample{Input: "x", Output: "y"})}, Executor: upper})
+	reg.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "net", Type: instruments.SkillTypeCode, Status: instruments.SkillStatusActive,
+		Doc: doc([]string{instruments.PermNetwork}, instruments.SkillExample{Input: "x", Output: "y"})}, Executor: upper})
+	_, err := runSkillExamples(context.Background(), reg)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 skill examples failing: broken (example 1)") {
+		t.Errorf("err = %v, want only the broken skill reported", err)
+	}
+}
+
+func TestAuditRetentionDays(t *testing.T) {
+	for in, want := range map[int]int{0: defaultAuditRetentionDays, 30: 30, -1: 0} {
+		if got := auditRetentionDays(Config{AuditRetentionDays: in}); got != want {
+			t.Errorf("auditRetentionDays(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
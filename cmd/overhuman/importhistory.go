@@ -0,0 +1,499 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+// Import limits.
+const (
+	importMaxExportBytes  = 512 << 20 // conversations.json read from an export
+	importTranscriptChars = 12000     // transcript sent for summarizing
+	importFallbackChars   = 500       // summary without the LLM
+)
+
+// importedMessage is one message of an imported conversation.
+type importedMessage struct {
+	Role string // "user" or "assistant"
+	Text string
+	Time time.Time
+}
+
+// importedConversation is a conversation from another assistant's export.
+type importedConversation struct {
+	Source   string // "chatgpt" or "claude"
+	ID       string
+	Title    string
+	Created  time.Time
+	Messages []importedMessage
+}
+
+// memoryID is the long-term memory entry the conversation is stored as;
+// importing the same export twice replaces rather than duplicates it.
+func (c importedConversation) memoryID() string {
+	return "import_" + c.Source + "_" + c.ID
+}
+
+// transcript renders the conversation for the summarizer, keeping its
+// beginning and end when it is longer than limit.
+func (c importedConversation) transcript(limit int) string {
+	var b strings.Builder
+	for _, m := range c.Messages {
+		fmt.Fprintf(&b, "%s: %s\n\n", m.Role, strings.TrimSpace(m.Text))
+	}
+	s := b.String()
+	r := []rune(s)
+	if len(r) <= limit {
+		return s
+	}
+	half := limit / 2
+	return string(r[:half]) + "\n[…]\n" + string(r[len(r)-half:])
+}
+
+// readHistoryExport returns the conversations.json of an export: the zip
+// file as downloaded from ChatGPT or Claude, or the JSON file itself.
+func readHistoryExport(file string) ([]byte, error) {
+	if !strings.EqualFold(filepath.Ext(file), ".zip") {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(io.LimitReader(f, importMaxExportBytes))
+	}
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if path.Base(f.Name) != "conversations.json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, importMaxExportBytes))
+	}
+	return nil, fmt.Errorf("%s has no conversations.json; is it a ChatGPT or Claude data export?", file)
+}
+
+// parseHistoryExport parses conversations.json, telling the ChatGPT and
+// Claude formats apart by their fields. Conversations without text are
+// skipped.
+func parseHistoryExport(data []byte) ([]importedConversation, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("conversations.json: %w", err)
+	}
+	var out []importedConversation
+	for i, r := range raw {
+		var probe struct {
+			Mapping      json.RawMessage `json:"mapping"`
+			ChatMessages json.RawMessage `json:"chat_messages"`
+		}
+		if err := json.Unmarshal(r, &probe); err != nil {
+			return nil, fmt.Errorf("conversation %d: %w", i+1, err)
+		}
+		var c importedConversation
+		var err error
+		switch {
+		case probe.Mapping != nil:
+			c, err = parseChatGPTConversation(r)
+		case probe.ChatMessages != nil:
+			c, err = parseClaudeConversation(r)
+		default:
+			return nil, fmt.Errorf("conversation %d: neither a ChatGPT nor a Claude conversation", i+1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("conversation %d: %w", i+1, err)
+		}
+		if len(c.Messages) > 0 {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out, nil
+}
+
+// parseChatGPTConversation reads a ChatGPT conversation: a tree of
+// messages (edits and regenerations branch), of which the branch ending at
+// current_node is the one the user saw last.
+func parseChatGPTConversation(data []byte) (importedConversation, error) {
+	var conv struct {
+		ID             string  `json:"id"`
+		ConversationID string  `json:"conversation_id"`
+		Title          string  `json:"title"`
+		CreateTime     float64 `json:"create_time"`
+		CurrentNode    string  `json:"current_node"`
+		Mapping        map[string]struct {
+			Parent  string `json:"parent"`
+			Message *struct {
+				Author struct {
+					Role string `json:"role"`
+				} `json:"author"`
+				CreateTime float64 `json:"create_time"`
+				Content    struct {
+					ContentType string            `json:"content_type"`
+					Parts       []json.RawMessage `json:"parts"`
+				} `json:"content"`
+			} `json:"message"`
+		} `json:"mapping"`
+	}
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return importedConversation{}, err
+	}
+	c := importedConversation{Source: "chatgpt", ID: conv.ConversationID, Title: conv.Title, Created: unixSeconds(conv.CreateTime)}
+	if c.ID == "" {
+		c.ID = conv.ID
+	}
+	var msgs []importedMessage
+	seen := make(map[string]bool)
+	for id := conv.CurrentNode; id != "" && !seen[id]; {
+		seen[id] = true
+		node, ok := conv.Mapping[id]
+		if !ok {
+			break
+		}
+		if m := node.Message; m != nil && (m.Author.Role == "user" || m.Author.Role == "assistant") && m.Content.ContentType == "text" {
+			var parts []string
+			for _, p := range m.Content.Parts {
+				var s string
+				if json.Unmarshal(p, &s) == nil && strings.TrimSpace(s) != "" {
+					parts = append(parts, s) // skips images and other attachments
+				}
+			}
+			if len(parts) > 0 {
+				msgs = append(msgs, importedMessage{Role: m.Author.Role, Text: strings.Join(parts, "\n"), Time: unixSeconds(m.CreateTime)})
+			}
+		}
+		id = node.Parent
+	}
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+	c.Messages = msgs
+	if c.ID == "" {
+		return c, fmt.Errorf("no conversation id")
+	}
+	return c, nil
+}
+
+// parseClaudeConversation reads a Claude conversation: a flat list of
+// messages from "human" and "assistant".
+func parseClaudeConversation(data []byte) (importedConversation, error) {
+	var conv struct {
+		UUID         string    `json:"uuid"`
+		Name         string    `json:"name"`
+		CreatedAt    time.Time `json:"created_at"`
+		ChatMessages []struct {
+			Sender    string    `json:"sender"`
+			Text      string    `json:"text"`
+			CreatedAt time.Time `json:"created_at"`
+			Content   []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"chat_messages"`
+	}
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return importedConversation{}, err
+	}
+	if conv.UUID == "" {
+		return importedConversation{}, fmt.Errorf("no conversation uuid")
+	}
+	c := importedConversation{Source: "claude", ID: conv.UUID, Title: conv.Name, Created: conv.CreatedAt}
+	for _, m := range conv.ChatMessages {
+		text := m.Text
+		if strings.TrimSpace(text) == "" {
+			var parts []string
+			for _, p := range m.Content {
+				if p.Type == "text" && strings.TrimSpace(p.Text) != "" {
+					parts = append(parts, p.Text)
+				}
+			}
+			text = strings.Join(parts, "\n")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		role := "assistant"
+		if m.Sender == "human" {
+			role = "user"
+		}
+		c.Messages = append(c.Messages, importedMessage{Role: role, Text: text, Time: m.CreatedAt})
+	}
+	return c, nil
+}
+
+// unixSeconds converts an export's fractional Unix time (0 = unknown).
+func unixSeconds(s float64) time.Time {
+	if s <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(s*float64(time.Second)))
+}
+
+// importSummarizer turns a conversation into a memory summary and tags.
+type importSummarizer struct {
+	llm   brain.LLMProvider // nil = no LLM, the title and first question are kept
+	model string
+	cost  float64
+}
+
+// summarize returns the memory entry text and extra tags for c. Without an
+// LLM, or when the LLM call fails, it falls back to the title and opening
+// question.
+func (s *importSummarizer) summarize(ctx context.Context, c importedConversation) (string, []string, error) {
+	if s.llm == nil {
+		return importFallbackSummary(c), nil, nil
+	}
+	resp, err := s.llm.Complete(ctx, brain.LLMRequest{
+		Model: s.model,
+		Messages: []brain.Message{
+			{Role: "system", Content: "You turn a past conversation between a user and an AI assistant into a memory for a new assistant. " +
+				"Write at most five sentences about the user: what they asked for, what was decided, and lasting facts such as " +
+				"their projects, preferences, tools and people they mentioned. Skip generic assistant explanations. " +
+				"End with a line \"Tags: \" followed by up to five short lowercase tags, comma-separated."},
+			{Role: "user", Content: "Title: " + c.Title + "\n\n" + c.transcript(importTranscriptChars)},
+		},
+		MaxTokens: 400,
+	})
+	if err != nil {
+		return importFallbackSummary(c), nil, err
+	}
+	s.cost += resp.CostUSD
+	summary, tags := splitSummaryTags(resp.Content)
+	if summary == "" {
+		return importFallbackSummary(c), tags, nil
+	}
+	return summary, tags, nil
+}
+
+// splitSummaryTags separates the trailing "Tags: a, b" line of a summary.
+func splitSummaryTags(text string) (string, []string) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	var tags []string
+	if n := len(lines); n > 0 {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(lines[n-1]), "Tags:"); ok {
+			lines = lines[:n-1]
+			for _, t := range strings.Split(rest, ",") {
+				if t = strings.ToLower(strings.Trim(strings.TrimSpace(t), "#.")); t != "" {
+					tags = append(tags, t)
+				}
+			}
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), tags
+}
+
+// importFallbackSummary is the title and the user's opening question.
+func importFallbackSummary(c importedConversation) string {
+	var first string
+	for _, m := range c.Messages {
+		if m.Role == "user" {
+			first = strings.Join(strings.Fields(m.Text), " ")
+			break
+		}
+	}
+	summary := "Asked " + c.Source + ": " + truncateText(first, importFallbackChars)
+	if c.Title != "" {
+		summary = c.Title + ". " + summary
+	}
+	return summary
+}
+
+// importOptions are the flags of `overhuman import-history`.
+type importOptions struct {
+	File      string
+	Owner     string // sender the memories belong to ("" = shared)
+	Since     time.Time
+	NoSummary bool
+	Embed     bool
+	DryRun    bool
+}
+
+// parseImportArgs parses FILE [--owner SENDER] [--since DATE]
+// [--no-summary] [--embed] [--dry-run].
+func parseImportArgs(args []string, now time.Time) (importOptions, error) {
+	var opts importOptions
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--no-summary":
+			opts.NoSummary = true
+			continue
+		case "--embed":
+			opts.Embed = true
+			continue
+		case "--dry-run":
+			opts.DryRun = true
+			continue
+		case "--owner", "--since":
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return opts, fmt.Errorf("unknown flag %q", args[i])
+			}
+			if opts.File != "" {
+				return opts, fmt.Errorf("one export file at a time")
+			}
+			opts.File = args[i]
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == "--owner" {
+			opts.Owner = value
+		} else {
+			t, err := parseExportTime(value, now)
+			if err != nil {
+				return opts, fmt.Errorf("--since: %w", err)
+			}
+			opts.Since = t
+		}
+	}
+	if opts.File == "" {
+		return opts, fmt.Errorf("no export file given")
+	}
+	return opts, nil
+}
+
+// importConversations stores convs as long-term memories and returns how
+// many were stored. Failed summaries fall back to the title and are
+// reported through warn.
+func importConversations(ctx context.Context, ltm *memory.LongTermMemory, sum *importSummarizer, convs []importedConversation, owner string, progress func(done int), warn func(error)) (int, error) {
+	stored := 0
+	for i, c := range convs {
+		if ctx.Err() != nil {
+			return stored, ctx.Err()
+		}
+		summary, tags, err := sum.summarize(ctx, c)
+		if err != nil && ctx.Err() == nil {
+			warn(fmt.Errorf("summarize %q: %w", c.Title, err))
+		}
+		entry := memory.LongTermEntry{
+			ID:          c.memoryID(),
+			Summary:     summary,
+			Tags:        append([]string{"imported", c.Source}, tags...),
+			SourceRunID: "import:" + c.Source + ":" + c.ID,
+			CreatedAt:   c.Created,
+			Channel:     c.Source,
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
+		if owner != "" {
+			entry.Owner, entry.Visibility = owner, memory.VisibilityPrivate
+		}
+		if err := ltm.Store(entry); err != nil {
+			return stored, fmt.Errorf("store %q: %w", c.Title, err)
+		}
+		stored++
+		if progress != nil {
+			progress(i + 1)
+		}
+	}
+	return stored, nil
+}
+
+// runImportHistory handles `overhuman import-history FILE`: conversations
+// from a ChatGPT or Claude data export become long-term memories, so a new
+// agent starts out knowing what its user has been working on.
+func runImportHistory(args []string) {
+	opts, err := parseImportArgs(args, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-history: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Usage: %s import-history EXPORT.zip [--owner SENDER] [--since DATE] [--no-summary] [--embed] [--dry-run]\n", appName)
+		os.Exit(2)
+	}
+	data, err := readHistoryExport(opts.File)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-history: %v\n", err)
+		os.Exit(1)
+	}
+	convs, err := parseHistoryExport(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-history: %v\n", err)
+		os.Exit(1)
+	}
+	kept := convs[:0]
+	for _, c := range convs {
+		if opts.Since.IsZero() || !c.Created.Before(opts.Since) {
+			kept = append(kept, c)
+		}
+	}
+	convs = kept
+	if len(convs) == 0 {
+		fmt.Println("No conversations to import.")
+		return
+	}
+	if opts.DryRun {
+		for _, c := range convs {
+			fmt.Printf("%s  %-7s  %3d messages  %s\n", c.Created.Local().Format("2006-01-02"), c.Source, len(c.Messages), c.Title)
+		}
+		fmt.Printf("%d conversation(s) would be imported.\n", len(convs))
+		return
+	}
+
+	cfg := loadConfig()
+	sum := &importSummarizer{}
+	if !opts.NoSummary {
+		llm, providerName, err := createLLMProvider(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import-history: %v\n(use --no-summary to import titles and opening questions without the LLM)\n", err)
+			os.Exit(1)
+		}
+		sum.llm, sum.model = llm, newModelRouter(llm, providerName).Select("simple", 100)
+	}
+	ltm, err := memory.NewLongTermMemory(filepath.Join(cfg.DataDir, "overhuman.db"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open memory: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("Importing %d conversation(s) from %s\n", len(convs), filepath.Base(opts.File))
+	var warnings bytes.Buffer
+	stored, err := importConversations(ctx, ltm, sum, convs, opts.Owner,
+		func(done int) { fmt.Printf("\r  %d/%d", done, len(convs)) },
+		func(err error) { fmt.Fprintf(&warnings, "  ⚠ %v\n", err) })
+	ltm.Close()
+	fmt.Println()
+	os.Stdout.Write(warnings.Bytes())
+	if sum.llm != nil {
+		fmt.Printf("Summarized with %s for $%.4f.\n", sum.model, sum.cost)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-history: %v\nStored %d; run the import again to continue (entries are replaced, not duplicated).\n", err, stored)
+		os.Exit(1)
+	}
+	fmt.Printf("Stored %d memories.\n", stored)
+	if opts.Embed {
+		runMemory([]string{"reindex"})
+	} else {
+		fmt.Printf("Run '%s memory reindex' to embed them for semantic recall.\n", appName)
+	}
+}
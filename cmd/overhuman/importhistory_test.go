@@ -0,0 +1,132 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+// chatGPTExport has one conversation whose first answer was regenerated:
+// only the branch ending at current_node is imported.
+const chatGPTExport = `[{
+  "title": "Garden planning", "create_time": 1717236000.5, "conversation_id": "c-1", "current_node": "n4",
+  "mapping": {
+    "root": {"parent": null, "message": null},
+    "n1": {"parent": "root", "message": {"author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]}}},
+    "n2": {"parent": "n1", "message": {"author": {"role": "user"}, "create_time": 1717236001, "content": {"content_type": "text", "parts": ["Which tomatoes grow on a north balcony?"]}}},
+    "n3": {"parent": "n2", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["Discarded answer"]}}},
+    "n4": {"parent": "n2", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["Try cherry tomatoes.", {"asset_pointer": "file-1"}]}}}
+  }
+}]`
+
+const claudeExport = `[
+  {"uuid": "u-2", "name": "Empty", "created_at": "2024-05-01T09:00:00Z", "chat_messages": []},
+  {"uuid": "u-1", "name": "Go generics", "created_at": "2024-04-01T09:00:00.123456Z", "chat_messages": [
+    {"sender": "human", "text": "", "created_at": "2024-04-01T09:00:00Z", "content": [{"type": "text", "text": "How do I constrain a type parameter to numbers?"}]},
+    {"sender": "assistant", "text": "Use a constraint interface.", "created_at": "2024-04-01T09:00:05Z"}
+  ]}
+]`
+
+func TestParseHistoryExport(t *testing.T) {
+	convs, err := parseHistoryExport([]byte(chatGPTExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(convs) != 1 {
+		t.Fatalf("got %d conversations", len(convs))
+	}
+	c := convs[0]
+	if c.Source != "chatgpt" || c.ID != "c-1" || c.Title != "Garden planning" || c.Created.Unix() != 1717236000 {
+		t.Errorf("conversation = %+v", c)
+	}
+	if len(c.Messages) != 2 || c.Messages[0].Role != "user" || c.Messages[1].Text != "Try cherry tomatoes." {
+		t.Errorf("messages = %+v", c.Messages)
+	}
+
+	convs, err = parseHistoryExport([]byte(claudeExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(convs) != 1 || convs[0].Source != "claude" || convs[0].memoryID() != "import_claude_u-1" {
+		t.Fatalf("conversations = %+v", convs)
+	}
+	if m := convs[0].Messages; len(m) != 2 || m[0].Role != "user" || !strings.Contains(m[0].Text, "type parameter") {
+		t.Errorf("messages = %+v", m)
+	}
+
+	if _, err := parseHistoryExport([]byte(`[{"title": "x"}]`)); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestReadHistoryExport_Zip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("export-2024/conversations.json")
+	w.Write([]byte(claudeExport))
+	zw.Close()
+	f.Close()
+
+	data, err := readHistoryExport(file)
+	if err != nil || string(data) != claudeExport {
+		t.Fatalf("readHistoryExport = %q, %v", data, err)
+	}
+}
+
+func TestImportConversations(t *testing.T) {
+	ltm, err := memory.NewLongTermMemory(filepath.Join(t.TempDir(), "overhuman.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ltm.Close()
+	convs, _ := parseHistoryExport([]byte(claudeExport))
+	fake := brain.NewFakeProvider(brain.FakeConfig{Default: "The user writes Go and asked about numeric generics.\nTags: Go, #generics"})
+	sum := &importSummarizer{llm: fake, model: "fake-cheap"}
+
+	for i := 0; i < 2; i++ { // a second import replaces the entries
+		n, err := importConversations(context.Background(), ltm, sum, convs, "alice", nil, func(err error) { t.Error(err) })
+		if err != nil || n != 1 {
+			t.Fatalf("import = %d, %v", n, err)
+		}
+	}
+	all, _ := ltm.GetAll(10)
+	if len(all) != 1 {
+		t.Fatalf("stored %d entries, want 1", len(all))
+	}
+	e := all[0]
+	if e.Summary != "The user writes Go and asked about numeric generics." || strings.Join(e.Tags, ",") != "imported,claude,go,generics" {
+		t.Errorf("entry = %+v", e)
+	}
+	if !e.CreatedAt.Equal(time.Date(2024, 4, 1, 9, 0, 0, 123456000, time.UTC)) || e.Owner != "alice" || e.Visibility != memory.VisibilityPrivate {
+		t.Errorf("entry = %+v", e)
+	}
+
+	// Without the LLM the title and opening question are kept.
+	if s, _, _ := (&importSummarizer{}).summarize(context.Background(), convs[0]); s != "Go generics. Asked claude: How do I constrain a type parameter to numbers?" {
+		t.Errorf("fallback summary = %q", s)
+	}
+}
+
+func TestParseImportArgs(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	opts, err := parseImportArgs([]string{"export.zip", "--owner=alice", "--since", "30d", "--embed"}, now)
+	if err != nil || opts.File != "export.zip" || opts.Owner != "alice" || !opts.Embed || !opts.Since.Equal(now.AddDate(0, 0, -30)) {
+		t.Errorf("opts = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{}, {"a.zip", "b.zip"}, {"a.zip", "--bogus"}} {
+		if _, err := parseImportArgs(args, now); err == nil {
+			t.Errorf("%v accepted", args)
+		}
+	}
+}
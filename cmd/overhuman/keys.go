@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+const (
+	// keyExpiryWarnDays is how long before a key's expiry date the daemon
+	// starts reminding and `doctor` flags it.
+	keyExpiryWarnDays = 14
+
+	// keyCheckInterval is how often the daemon checks key expiry dates.
+	keyCheckInterval = 24 * time.Hour
+
+	// authFailureThreshold rejected calls within authFailureWindow trigger a
+	// "key rotated?" warning.
+	authFailureThreshold = 3
+	authFailureWindow    = time.Hour
+)
+
+// keyExpiryLayout is the date format of key_expiry in config.json.
+const keyExpiryLayout = "2006-01-02"
+
+// keyStatus is a recorded key expiry as seen at a point in time.
+type keyStatus struct {
+	Name     string
+	Expires  time.Time
+	DaysLeft int // negative once expired
+}
+
+// parseKeyExpiry parses the key_expiry block of config.json, skipping (and
+// logging) invalid dates.
+func parseKeyExpiry(raw map[string]string) map[string]time.Time {
+	if len(raw) == 0 {
+		return nil
+	}
+	out := make(map[string]time.Time, len(raw))
+	for name, v := range raw {
+		t, err := time.ParseInLocation(keyExpiryLayout, v, time.Local)
+		if err != nil {
+			log.Printf("[config] invalid key_expiry %s=%q (want YYYY-MM-DD)", name, v)
+			continue
+		}
+		out[name] = t
+	}
+	return out
+}
+
+// keyStatuses returns the recorded key expiries, soonest first.
+func keyStatuses(expiry map[string]time.Time, now time.Time) []keyStatus {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	out := make([]keyStatus, 0, len(expiry))
+	for name, t := range expiry {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+		out = append(out, keyStatus{
+			Name:     name,
+			Expires:  t,
+			DaysLeft: int(math.Round(day.Sub(today).Hours() / 24)),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].DaysLeft != out[j].DaysLeft {
+			return out[i].DaysLeft < out[j].DaysLeft
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// String describes the key's remaining lifetime.
+func (k keyStatus) String() string {
+	date := k.Expires.Format(keyExpiryLayout)
+	switch {
+	case k.DaysLeft < 0:
+		return fmt.Sprintf("%s expired on %s", k.Name, date)
+	case k.DaysLeft == 0:
+		return fmt.Sprintf("%s expires today (%s)", k.Name, date)
+	case k.DaysLeft == 1:
+		return fmt.Sprintf("%s expires tomorrow (%s)", k.Name, date)
+	}
+	return fmt.Sprintf("%s expires in %d days (%s)", k.Name, k.DaysLeft, date)
+}
+
+// dueKeyReminders returns a reminder for each key expiring within
+// keyExpiryWarnDays or already expired.
+func dueKeyReminders(expiry map[string]time.Time, now time.Time) []string {
+	var out []string
+	for _, k := range keyStatuses(expiry, now) {
+		if k.DaysLeft > keyExpiryWarnDays {
+			continue
+		}
+		out = append(out, fmt.Sprintf("API key %s. Rotate it, then record the new date with: %s keys set %s YYYY-MM-DD", k, appName, k.Name))
+	}
+	return out
+}
+
+// watchKeyExpiry reminds through notify about expiring keys at startup and
+// once a day.
+func watchKeyExpiry(ctx context.Context, expiry map[string]time.Time, notify func(string)) {
+	if len(expiry) == 0 {
+		return
+	}
+	check := func(now time.Time) {
+		for _, msg := range dueKeyReminders(expiry, now) {
+			notify(msg)
+		}
+	}
+	check(time.Now())
+	ticker := time.NewTicker(keyCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			check(now)
+		}
+	}
+}
+
+// authFailureTracker notices the provider rejecting its credentials
+// repeatedly — the silent failure mode of a rotated or revoked key.
+type authFailureTracker struct {
+	mu       sync.Mutex
+	failures []time.Time
+	warnedAt time.Time
+	notify   func(string)
+}
+
+func newAuthFailureTracker(notify func(string)) *authFailureTracker {
+	return &authFailureTracker{notify: notify}
+}
+
+// Record inspects a run error. Once authFailureThreshold auth errors fall
+// within authFailureWindow it warns, at most once per window.
+func (t *authFailureTracker) Record(err error, now time.Time) {
+	if !brain.IsAuthError(err) {
+		return
+	}
+	t.mu.Lock()
+	cutoff := now.Add(-authFailureWindow)
+	kept := t.failures[:0]
+	for _, f := range t.failures {
+		if f.After(cutoff) {
+			kept = append(kept, f)
+		}
+	}
+	t.failures = append(kept, now)
+	n := len(t.failures)
+	warn := n >= authFailureThreshold && now.Sub(t.warnedAt) >= authFailureWindow
+	if warn {
+		t.warnedAt = now
+	}
+	t.mu.Unlock()
+	if warn {
+		t.notify(fmt.Sprintf("The LLM provider rejected the API key %d times in the last hour (%v). The key may have expired or been rotated — update it with: %s configure", n, err, appName))
+	}
+}
+
+// runKeys handles `overhuman keys [list|set NAME YYYY-MM-DD|remove NAME]`.
+func runKeys(args []string) {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, "usage: %s keys [list | set NAME YYYY-MM-DD | remove NAME]\n", appName)
+		os.Exit(1)
+	}
+	sub := "list"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	switch sub {
+	case "list":
+		cfg := loadConfig()
+		statuses := keyStatuses(cfg.KeyExpiry, time.Now())
+		if len(statuses) == 0 {
+			fmt.Printf("No key expiry dates recorded. Add one with: %s keys set NAME YYYY-MM-DD\n", appName)
+			return
+		}
+		for _, k := range statuses {
+			mark := "✓"
+			if k.DaysLeft <= keyExpiryWarnDays {
+				mark = "⚠"
+			}
+			fmt.Printf("%s %s\n", mark, k)
+		}
+	case "set", "remove":
+		if (sub == "set" && len(args) != 3) || (sub == "remove" && len(args) != 2) {
+			usage()
+		}
+		if err := updateKeyExpiry(sub, args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "keys %s: %v\n", sub, err)
+			os.Exit(1)
+		}
+		fmt.Println("Saved. Restart the daemon to apply.")
+	default:
+		usage()
+	}
+}
+
+// updateKeyExpiry sets (name, date) or removes (name) a key_expiry entry in
+// config.json.
+func updateKeyExpiry(op string, args []string) error {
+	pc, err := loadPersistedConfig()
+	if err != nil {
+		return err
+	}
+	if pc == nil {
+		pc = &persistedConfig{}
+	}
+	name := strings.TrimSpace(args[0])
+	if op == "set" {
+		if _, err := time.Parse(keyExpiryLayout, args[1]); err != nil {
+			return fmt.Errorf("invalid date %q (want YYYY-MM-DD)", args[1])
+		}
+		if pc.KeyExpiry == nil {
+			pc.KeyExpiry = make(map[string]string)
+		}
+		pc.KeyExpiry[name] = args[1]
+	} else {
+		if _, ok := pc.KeyExpiry[name]; !ok {
+			return fmt.Errorf("no expiry recorded for %q", name)
+		}
+		delete(pc.KeyExpiry, name)
+	}
+	return savePersistedConfig(pc)
+}
+
+// doctorCheckKeys prints the key expiry lines of `overhuman doctor` and
+// reports whether any key is expired or about to expire.
+func doctorCheckKeys(expiry map[string]time.Time) bool {
+	statuses := keyStatuses(expiry, time.Now())
+	if len(statuses) == 0 {
+		fmt.Printf("  … Key expiry: none recorded (%s keys set NAME YYYY-MM-DD)\n", appName)
+		return false
+	}
+	due := false
+	for _, k := range statuses {
+		switch {
+		case k.DaysLeft < 0:
+			fmt.Printf("  ✗ Key expiry: %s\n", k)
+			due = true
+		case k.DaysLeft <= keyExpiryWarnDays:
+			fmt.Printf("  ⚠ Key expiry: %s\n", k)
+			due = true
+		default:
+			fmt.Printf("  ✓ Key expiry: %s\n", k)
+		}
+	}
+	return due
+}
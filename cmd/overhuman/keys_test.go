@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyStatuses(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	expiry := parseKeyExpiry(map[string]string{
+		"openai":   "2026-03-20",
+		"telegram": "2026-03-09",
+		"github":   "2026-06-01",
+		"bad":      "next week",
+	})
+	if _, ok := expiry["bad"]; ok {
+		t.Error("invalid date should be skipped")
+	}
+
+	got := keyStatuses(expiry, now)
+	if len(got) != 3 || got[0].Name != "telegram" || got[0].DaysLeft != -1 || got[1].DaysLeft != 10 {
+		t.Fatalf("statuses = %+v", got)
+	}
+	if s := got[0].String(); s != "telegram expired on 2026-03-09" {
+		t.Errorf("String() = %q", s)
+	}
+
+	reminders := dueKeyReminders(expiry, now)
+	if len(reminders) != 2 {
+		t.Fatalf("reminders = %v, want telegram and openai", reminders)
+	}
+	if !strings.Contains(reminders[1], "openai expires in 10 days (2026-03-20)") || !strings.Contains(reminders[1], "keys set openai") {
+		t.Errorf("reminder = %q", reminders[1])
+	}
+}
+
+func TestUpdateKeyExpiry(t *testing.T) {
+	t.Setenv("OVERHUMAN_DATA", t.TempDir())
+
+	if err := updateKeyExpiry("set", []string{"openai", "2026-13-01"}); err == nil {
+		t.Error("invalid date should fail")
+	}
+	if err := updateKeyExpiry("set", []string{"openai", "2026-12-31"}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	pc, err := loadPersistedConfig()
+	if err != nil || pc == nil || pc.KeyExpiry["openai"] != "2026-12-31" {
+		t.Fatalf("config = %+v, %v", pc, err)
+	}
+	if err := updateKeyExpiry("remove", []string{"openai"}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := updateKeyExpiry("remove", []string{"openai"}); err == nil {
+		t.Error("removing an unknown key should fail")
+	}
+}
+
+func TestAuthFailureTracker(t *testing.T) {
+	var warnings []string
+	tr := newAuthFailureTracker(func(msg string) { warnings = append(warnings, msg) })
+	now := time.Now()
+	auth := errors.New("execute: openai: API error 401: invalid_api_key: Incorrect API key")
+
+	tr.Record(errors.New("openai: API error 500: overloaded"), now)
+	tr.Record(auth, now)
+	tr.Record(auth, now.Add(time.Minute))
+	if len(warnings) != 0 {
+		t.Fatalf("warned too early: %v", warnings)
+	}
+	tr.Record(auth, now.Add(2*time.Minute))
+	tr.Record(auth, now.Add(3*time.Minute))
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "3 times") {
+		t.Fatalf("warnings = %v, want one after 3 failures", warnings)
+	}
+
+	// Old failures age out of the window.
+	tr.Record(auth, now.Add(3*time.Hour))
+	if len(warnings) != 1 {
+		t.Errorf("a single failure after the window should not warn: %v", warnings)
+	}
+}
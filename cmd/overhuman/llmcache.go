@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// llmCacheSettings is the "llm_cache" block of config.json: identical
+// clarification and review requests are answered from the database, e.g.
+// {"ttl": "6h"}.
+type llmCacheSettings struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// TTL is how long a response is reused, e.g. "6h". Default: 24h.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// validate checks the TTL.
+func (s llmCacheSettings) validate() error {
+	if s.TTL != "" {
+		if d, err := time.ParseDuration(s.TTL); err != nil || d <= 0 {
+			return fmt.Errorf("ttl %q is not a positive duration like 6h", s.TTL)
+		}
+	}
+	return nil
+}
+
+// ttl returns how long responses are cached, 0 when caching is off.
+func (s llmCacheSettings) ttl() time.Duration {
+	if s.Disabled {
+		return 0
+	}
+	if d, err := time.ParseDuration(s.TTL); err == nil && d > 0 {
+		return d
+	}
+	return brain.DefaultCacheTTL
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/observability"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// logStreamBacklog is how many recent lines a new stream starts with.
+const logStreamBacklog = 200
+
+// logStreamKeepalive is how often an idle stream sends a comment, so
+// proxies do not time it out.
+const logStreamKeepalive = 15 * time.Second
+
+// logStreamHandler serves GET /logs/stream: the daemon log as server-sent
+// events, starting with the recent lines. Query parameters: level (minimum,
+// e.g. "warn"), task (a task ID) and tail (backlog lines, default 200).
+// Reconnecting clients resume after Last-Event-ID. Logs can hold anything
+// the agent saw, so only admins may read them.
+func logStreamHandler(buf *observability.LogBuffer, adminToken string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !security.AuthorizeAdmin(r, adminToken) {
+			writeExportError(w, http.StatusForbidden, fmt.Errorf("the log stream needs the admin role"))
+			return
+		}
+		q := r.URL.Query()
+		level, ok := observability.ParseLogLevel(q.Get("level"))
+		if !ok {
+			writeExportError(w, http.StatusBadRequest, fmt.Errorf("unknown level %q (debug, info, warn or error)", q.Get("level")))
+			return
+		}
+		filter := observability.LogFilter{MinLevel: level, TaskID: q.Get("task")}
+		tail := logStreamBacklog
+		if v := q.Get("tail"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+				tail = n
+			}
+		}
+		if id, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64); err == nil {
+			filter.After, tail = id, 0
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeExportError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+			return
+		}
+
+		// Subscribe before reading the backlog so no line falls in between.
+		live, cancel := buf.Subscribe()
+		defer cancel()
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		var last uint64
+		send := func(e observability.LogEntry) bool {
+			if e.Seq <= last || !filter.Match(e) {
+				return true
+			}
+			last = e.Seq
+			data, _ := json.Marshal(e)
+			_, err := fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", e.Seq, data)
+			return err == nil
+		}
+		if tail > 0 || filter.After > 0 {
+			for _, e := range buf.Entries(filter, tail) {
+				send(e)
+			}
+		}
+		flusher.Flush()
+
+		keepalive := time.NewTicker(logStreamKeepalive)
+		defer keepalive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e := <-live:
+				if !send(e) {
+					return
+				}
+				flusher.Flush()
+			case <-keepalive.C:
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/observability"
+)
+
+func TestLogStreamHandler(t *testing.T) {
+	buf := observability.NewLogBuffer(0)
+	fmt.Fprintln(buf, "[daemon] started")
+	fmt.Fprintln(buf, "[daemon] run error: boom task=t1")
+	srv := httptest.NewServer(logStreamHandler(buf, "admin-secret"))
+	defer srv.Close()
+
+	for _, target := range []string{"/", "/?token=wrong"} {
+		if resp, err := http.Get(srv.URL + target); err != nil || resp.StatusCode != http.StatusForbidden {
+			t.Errorf("GET %s without the admin token: %v %v", target, resp.Status, err)
+		}
+	}
+	if resp, _ := http.Get(srv.URL + "/?token=admin-secret&level=loud"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown level: %s", resp.Status)
+	}
+
+	resp, err := http.Get(srv.URL + "/?token=admin-secret&level=warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			if strings.HasPrefix(sc.Text(), "data: ") || strings.HasPrefix(sc.Text(), "id: ") {
+				lines <- sc.Text()
+			}
+		}
+		close(lines)
+	}()
+	next := func() string {
+		select {
+		case l := <-lines:
+			return l
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event")
+			return ""
+		}
+	}
+	// The backlog holds only the warning; the info line is filtered out.
+	if id, data := next(), next(); id != "id: 2" || !strings.Contains(data, `"task_id":"t1"`) {
+		t.Fatalf("backlog event = %q %q", id, data)
+	}
+	fmt.Fprintln(buf, "[daemon] quiet")
+	fmt.Fprintln(buf, "[pipeline] WARN: live")
+	if id, data := next(), next(); id != "id: 4" || !strings.Contains(data, `"message":"WARN: live"`) {
+		t.Fatalf("live event = %q %q", id, data)
+	}
+
+	// Reconnecting resumes after Last-Event-ID instead of replaying.
+	req, _ := http.NewRequest("GET", srv.URL+"/?level=warn", nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	req.Header.Set("Last-Event-ID", "2")
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp2.Body.Close()
+	sc := bufio.NewScanner(resp2.Body)
+	for sc.Scan() && !strings.HasPrefix(sc.Text(), "id: ") {
+	}
+	if sc.Text() != "id: 4" {
+		t.Errorf("resumed at %q", sc.Text())
+	}
+}
@@ -10,33 +10,59 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"net"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/overhuman/overhuman/internal/approvals"
+	"github.com/overhuman/overhuman/internal/automation"
 	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/budget"
 	"github.com/overhuman/overhuman/internal/deploy"
+	"github.com/overhuman/overhuman/internal/events"
+	"github.com/overhuman/overhuman/internal/evolution"
 	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/goals"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/locale"
+	"github.com/overhuman/overhuman/internal/mcp"
 	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/netaddr"
+	"github.com/overhuman/overhuman/internal/observability"
 	"github.com/overhuman/overhuman/internal/pipeline"
 	"github.com/overhuman/overhuman/internal/reflection"
+	"github.com/overhuman/overhuman/internal/scheduler"
+	"github.com/overhuman/overhuman/internal/security"
 	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/skills"
 	"github.com/overhuman/overhuman/internal/soul"
+	"github.com/overhuman/overhuman/internal/versioning"
+	"github.com/overhuman/overhuman/internal/webhook"
 )
 
 const (
 	version = "0.2.0"
 	appName = "overhuman"
+
+	// defaultEscalationThreshold is the review score below which a task is
+	// retried on the next model tier.
+	defaultEscalationThreshold = 0.6
 )
 
 // Config holds the daemon configuration.
@@ -49,10 +75,197 @@ type Config struct {
 	DefaultSpec string
 
 	// Universal provider settings.
-	LLMProvider  string // "openai", "claude", "ollama", "lmstudio", "groq", "together", "openrouter", "custom"
-	LLMBaseURL   string // Custom base URL (for "custom" or override)
-	LLMModel     string // Default model override
-	LLMAPIKey    string // API key (for custom provider)
+	LLMProvider string // "openai", "claude", "ollama", "lmstudio", "groq", "together", "openrouter", "custom"
+	LLMBaseURL  string // Custom base URL (for "custom" or override)
+	LLMModel    string // Default model override
+	LLMAPIKey   string // API key (for custom provider)
+
+	// Timezone/locale — agent default plus per-user overrides keyed by
+	// sender ID (e-mail address, Telegram ID, ...).
+	Timezone    string
+	Locale      string
+	UserLocales map[string]locale.Settings
+
+	// ActiveHours is the daily "HH:MM-HH:MM" window in which the daemon is
+	// fully awake. Empty means always active.
+	ActiveHours string
+
+	// HeartbeatMin and HeartbeatMax bound the adaptive heartbeat interval
+	// (Go durations; empty = senses.DefaultHeartbeatMin/Max).
+	HeartbeatMin string
+	HeartbeatMax string
+
+	// Templates are saved prompts (name → input text) offered in the kiosk
+	// command palette.
+	Templates map[string]string
+
+	// Email connects the email sense (IMAP in, SMTP out).
+	Email emailSettings
+
+	// Kiosk themes the kiosk: preset, color scheme, accent, logo and a
+	// custom CSS file.
+	Kiosk kioskSettings
+
+	// UIHistory bounds the stored history of generated UIs.
+	UIHistory uiHistorySettings
+
+	// AdminToken authenticates mode switches (POST /mode). Empty allows
+	// them from localhost only.
+	AdminToken string
+
+	// Team switches the daemon to team mode: API, WS and kiosk requests
+	// need one of these members' tokens, and their role decides what they
+	// may do (see teamRouteRole).
+	Team []security.TeamMember
+
+	// Mode is the daemon mode at startup: "normal", "read_only" or
+	// "maintenance".
+	Mode string
+
+	// Limits are the input quotas enforced by the senses.
+	Limits senses.Limits
+
+	// Dispatch sets how many inputs run at once, overall and per sender.
+	Dispatch senses.DispatchConfig
+
+	// AuditRetentionDays is how long audit events are kept (0 = 90 days,
+	// negative = forever).
+	AuditRetentionDays int
+
+	// Embeddings — model, and an optional separate OpenAI-compatible
+	// endpoint (defaults to the LLM provider's).
+	EmbeddingModel   string
+	EmbeddingBaseURL string
+	EmbeddingAPIKey  string
+
+	// UI generation — an optional dedicated provider (a name as in
+	// LLM_PROVIDER) and model for the cosmetic UI pass, so it never
+	// competes with tasks for expensive tokens or rate limits.
+	UIProvider string
+	UIModel    string
+	UIBaseURL  string
+	UIAPIKey   string
+
+	// Offline fallback — a local provider (a name as in LLM_PROVIDER) and
+	// model that answer while the main provider is unreachable.
+	FallbackProvider string
+	FallbackModel    string
+	FallbackBaseURL  string
+
+	// Fallbacks are providers tried in order, before the offline fallback,
+	// while the main one is failing.
+	Fallbacks []fallbackSettings
+
+	// LLMRetry is the retry policy of the LLM providers (zero = the
+	// default, max_retries -1 = off); see retryPolicy.
+	LLMRetry brain.RetryPolicy
+
+	// LLMCache says how long identical clarification and review requests
+	// are answered from the database.
+	LLMCache llmCacheSettings
+
+	// SkillGeneration says how repeated tasks are turned into code skills.
+	SkillGeneration skillGenSettings
+
+	// Sandbox is the Docker sandbox of code skills.
+	Sandbox sandboxSettings
+
+	// KeyExpiry maps a key name to its expiry date, for reminders.
+	KeyExpiry map[string]time.Time
+
+	// STT and TTS configure the speech backends used for voice input and
+	// spoken replies (empty Provider = disabled).
+	STT brain.SpeechConfig
+	TTS brain.SpeechConfig
+
+	// SpeechOutput says which results are read out, and where.
+	SpeechOutput speechOutputSettings
+
+	// SoulTokenBudget caps the soul size checked by the soul linter
+	// (0 = soul.DefaultTokenBudget).
+	SoulTokenBudget int
+
+	// DailyBudgetUSD and MonthlyBudgetUSD cap LLM spending (0 = no cap).
+	DailyBudgetUSD   float64
+	MonthlyBudgetUSD float64
+
+	// PriorityPolicy overrides rows of the built-in priority policy table
+	// (model tier bounds, budget overdraft and deferral per priority).
+	PriorityPolicy budget.Policy
+
+	// AutoMigrateModels replaces retired models with their suggested
+	// successor instead of only warning.
+	AutoMigrateModels bool
+
+	// ModelUpgradeTrials starts evaluating newer default models without
+	// being asked.
+	ModelUpgradeTrials bool
+
+	// SpeculationMultiplier bounds speculative execution of ambiguous
+	// tasks as a multiple of a normal run's cost (0 = off).
+	SpeculationMultiplier float64
+
+	// WarmStartRuns is the pattern count after which stages 2-3 are
+	// reused from cache (0 = default, negative = off).
+	WarmStartRuns int
+
+	// Sessions is the conversation lifecycle: idle rollover and
+	// summarizing ended conversations.
+	Sessions pipeline.SessionPolicy
+
+	// Scratch is the per-run scratch space policy; its directories are
+	// set from DataDir.
+	Scratch pipeline.ScratchPolicy
+
+	// PublicURL is the kiosk address as the agent's contacts reach it, used
+	// in the artifact download links sent with replies (default: the kiosk
+	// listen address).
+	PublicURL string
+
+	// PrePrompts maps a source type key ("email", "file", ...) to a
+	// pre-prompt template applied to inputs from that channel.
+	PrePrompts map[string]string
+
+	// Preprocess maps a source type key to the filter chain (signature
+	// and quote stripping, replace rules, translation) run on its inputs
+	// before pre-prompts and sanitization.
+	Preprocess map[string]senses.PreprocessConfig
+
+	// MemoryVisibility maps a source type key to the visibility of
+	// long-term memories learned from that channel.
+	MemoryVisibility map[string]string
+
+	// Notion connects the docs skill to a Notion workspace (empty Token =
+	// the skill stays a stub).
+	Notion skills.NotionConfig
+
+	// HTTP is the domain allowlist and endpoint templates for the API
+	// integration skill (no domains = every request is refused).
+	HTTP skills.HTTPRequestConfig
+
+	// Issues connects the issues skill to GitHub and/or Jira (no
+	// credentials = the skill is not registered).
+	Issues skills.IssuesConfig
+
+	// MCPServers are the MCP servers whose tools automation rules call.
+	MCPServers []mcp.ServerConfig
+
+	// Webhooks receive signed JSON notifications of task, automation,
+	// budget and approval events.
+	Webhooks []webhook.Config
+
+	// InboundWebhooks are the named webhooks the API accepts tasks from.
+	InboundWebhooks []senses.InboundWebhook
+
+	// Moderation is the safety pass over results sent to external
+	// channels (off unless enabled).
+	Moderation security.OutputModeration
+
+	// Approvals lists the skill permissions that need an admin's approval.
+	Approvals approvalSettings
+
+	// Documents says how large documents are summarized for the pipeline.
+	Documents documentSettings
 }
 
 func main() {
@@ -64,13 +277,21 @@ func main() {
 	cmd := os.Args[1]
 	switch cmd {
 	case "cli":
+		if remote, _ := parseRemote(os.Args[2:]); remote != "" {
+			runRemoteCLI(remote)
+			break
+		}
 		ensureConfigured()
+		if addr, running := runningDaemonAddr(); running {
+			runRemoteCLI(addr)
+			break
+		}
 		runCLI()
 	case "start":
 		ensureConfigured()
 		runDaemon()
 	case "configure", "config", "setup":
-		runConfigure()
+		runConfigure(os.Args[2:])
 	case "doctor":
 		runDoctor()
 	case "version":
@@ -80,13 +301,45 @@ func main() {
 	case "uninstall":
 		runUninstall()
 	case "stop":
-		runStop()
+		runStop(os.Args[2:])
 	case "update":
 		runUpdate()
 	case "logs":
 		runLogs()
 	case "status":
-		runStatus()
+		runStatus(os.Args[2:])
+	case "mode":
+		runMode(os.Args[2:])
+	case "skill", "skills":
+		runSkill(os.Args[2:])
+	case "goals":
+		runGoals(os.Args[2:])
+	case "models":
+		runModels(os.Args[2:])
+	case "memory":
+		runMemory(os.Args[2:])
+	case "keys":
+		runKeys(os.Args[2:])
+	case "permissions":
+		runPermissions(os.Args[2:])
+	case "approvals":
+		runApprovals(os.Args[2:])
+	case "bench":
+		runBench(os.Args[2:])
+	case "encrypt":
+		runEncrypt()
+	case "automations":
+		runAutomations()
+	case "contacts":
+		runContacts(os.Args[2:])
+	case "runs":
+		runRuns(os.Args[2:])
+	case "export-chat":
+		runExportChat(os.Args[2:])
+	case "import-history":
+		runImportHistory(os.Args[2:])
+	case "debug":
+		runDebug(os.Args[2:])
 	case "help", "--help", "-h":
 		printUsage()
 	default:
@@ -104,10 +357,34 @@ Usage:
 
 Commands:
   configure  Interactive setup wizard (API keys, provider, model)
-  cli        Interactive CLI mode (stdin/stdout)
+             Headless: configure --provider NAME [--api-key-env VAR] [--model M] [--base-url URL] [--name N] [--daily-budget USD] [--monthly-budget USD] --yes
+  config validate  Check config.json (or PATH) and provider connectivity without writing: config validate [PATH] [--offline]
+  cli        Interactive CLI mode (stdin/stdout); --remote ADDR talks to a running daemon
   start      Start daemon (HTTP API + heartbeat timer)
-  stop       Stop the running daemon (sends SIGTERM)
-  status     Check daemon health (requires running daemon)
+  stop       Stop the running daemon (sends SIGTERM; --remote ADDR uses POST /shutdown)
+  status     Check daemon health and show which process holds the daemon lock
+  mode       Show or switch the daemon mode: mode [normal|read_only|maintenance] [message]
+  skills     Inspect and manage the daemon's skills: skills [list|show ID|enable ID|disable ID|delete ID|templates]
+  goals      The daemon's goals, worked on at heartbeats: goals [list [STATUS]|add [--priority P] TEXT|priority ID P|complete ID|delete ID]
+  models     Check configured models against the provider: models [check|migrate|rollback|history|upgrade]
+  memory     Recompute memory embeddings: memory reindex [--force] [--concurrency N] [--batch N]
+             Search long-term memory (read-only, safe while the daemon runs): memory search QUERY [--limit N]
+             Optimize the database (vacuum when fragmented): memory maintain [--vacuum]
+             Delete wrong memories cited in a "based on" section: memory forget ID...
+  keys       Track API key expiry for reminders: keys [list|set NAME YYYY-MM-DD|remove NAME]
+  permissions  Skill permission decisions: permissions [list|reset SKILL [PERMISSION]]
+  approvals  Actions waiting for an admin: approvals [list [all]|approve ID [NOTE]|deny ID [NOTE]]
+  encrypt    Encrypt a secret from stdin for config.json (needs OVERHUMAN_MASTER_KEY)
+  automations  List "when X then Y" rules from automations.yaml and the API, validating the file
+  contacts   The contact book: contacts [list|show NAME|add NAME [--email E] [--relationship R] [--prefers P] [--org]|forget NAME] [--owner SENDER]
+  runs       Recent exchanges, newest first (read-only): runs [--limit N] [--sender X] [--since DATE]
+  export-chat  Export conversation transcripts: export-chat [--sender X] [--since DATE] [--format md|json] [--out FILE]
+  import-history  Bootstrap memory from a ChatGPT or Claude data export: import-history EXPORT.zip [--owner SENDER] [--since DATE] [--no-summary] [--embed] [--dry-run]
+  debug repl  Developer console on the running daemon (local admin only): router state, patterns, queue, memory, heartbeat
+  bench      Load/soak test with synthetic traffic: bench [--rps N] [--duration 10m] [--provider fake] [--workers N]
+
+  status, stop, mode and cli accept --remote ADDR: host:port, [ipv6]:port
+  or unix:/path/to/socket (default: the configured API address).
   install    Install as an OS service (launchd/systemd)
   uninstall  Remove the OS service
   update     Check for and apply updates
@@ -118,13 +395,65 @@ Commands:
 Environment variables (override config.json):
   ANTHROPIC_API_KEY   Claude API key (auto-detected)
   OPENAI_API_KEY      OpenAI API key (auto-detected)
+  ELEVENLABS_API_KEY  ElevenLabs API key (for OVERHUMAN_STT/TTS_PROVIDER=elevenlabs)
   OVERHUMAN_DATA      Data directory (default: ~/.overhuman)
-  OVERHUMAN_API_ADDR  API listen address (default: 127.0.0.1:9090)
+  OVERHUMAN_API_ADDR  API listen address: host:port, [::1]:port or unix:/path.sock (default: 127.0.0.1:9090)
   OVERHUMAN_NAME      Agent name (default: Overhuman)
-  LLM_PROVIDER        Provider: openai, claude, ollama, lmstudio, groq, together, openrouter, custom
+  OVERHUMAN_TZ        Agent timezone, IANA name (default: system timezone)
+  OVERHUMAN_LOCALE    Agent locale, e.g. de-DE (default: en-US)
+  OVERHUMAN_ACTIVE_HOURS  Daily active window, e.g. 08:00-22:00 (default: always active)
+  OVERHUMAN_HEARTBEAT_MIN Shortest heartbeat interval, used while busy (default: 10m)
+  OVERHUMAN_HEARTBEAT_MAX Longest heartbeat interval, used overnight (default: 2h)
+  OVERHUMAN_ADMIN_TOKEN   Bearer token for admin endpoints such as POST /mode (default: localhost only)
+  OVERHUMAN_PUBLIC_URL    Kiosk URL used in file download links sent with replies, e.g. https://agent.example.com (default: kiosk address)
+  OVERHUMAN_MODE          Startup mode: normal, read_only or maintenance (default: normal)
+  OVERHUMAN_AUTO_MIGRATE_MODELS  Replace retired models with the suggested successor (default: false, warn only)
+  OVERHUMAN_SPECULATION_MULTIPLIER  Run both readings of ambiguous tasks on cheap models when that costs at most this multiple of a normal run, e.g. 1.5 (default: 0, off)
+  OVERHUMAN_DAILY_BUDGET_USD   Daily LLM spending cap in USD; tasks beyond it are refused (default: 0, no cap)
+  OVERHUMAN_MONTHLY_BUDGET_USD Monthly LLM spending cap in USD (default: 0, no cap)
+  OVERHUMAN_WARM_START_RUNS    Reuse the clarification and plan of a task after it ran this often with good reviews (default: 5; negative: off)
+  OVERHUMAN_EMBEDDING_MODEL    Embedding model (default: per provider, e.g. text-embedding-3-small)
+  OVERHUMAN_EMBEDDING_URL      OpenAI-compatible embeddings endpoint (default: the LLM provider's)
+  OVERHUMAN_EMBEDDING_API_KEY  API key for OVERHUMAN_EMBEDDING_URL
+  OVERHUMAN_UI_PROVIDER        Provider for UI generation, as LLM_PROVIDER, e.g. ollama (default: the main provider)
+  OVERHUMAN_UI_MODEL           Model for UI generation (default: the main provider's cheapest, or the UI provider's default)
+  OVERHUMAN_UI_URL             Base URL for OVERHUMAN_UI_PROVIDER (ollama, lmstudio, custom)
+  OVERHUMAN_UI_API_KEY         API key for OVERHUMAN_UI_PROVIDER (default: OPENAI_API_KEY / ANTHROPIC_API_KEY)
+  OVERHUMAN_FALLBACK_PROVIDER  Local provider that answers while the main one is unreachable: ollama, lmstudio (default: none)
+  OVERHUMAN_FALLBACK_MODEL     Model for OVERHUMAN_FALLBACK_PROVIDER (default: its default model)
+  OVERHUMAN_FALLBACK_URL       Base URL for OVERHUMAN_FALLBACK_PROVIDER
+  OVERHUMAN_STT_PROVIDER       Speech-to-text: openai, elevenlabs, whisper.cpp, fake (default: disabled)
+  OVERHUMAN_STT_MODEL          STT model ID, or ggml model path for whisper.cpp
+  OVERHUMAN_STT_URL            STT API base URL override (e.g. a local OpenAI-compatible server)
+  OVERHUMAN_STT_API_KEY        STT API key (default: OPENAI_API_KEY / ELEVENLABS_API_KEY)
+  OVERHUMAN_TTS_PROVIDER       Text-to-speech: openai, elevenlabs, piper, fake (default: disabled)
+  OVERHUMAN_TTS_MODEL          TTS model ID, or .onnx voice path for piper
+  OVERHUMAN_TTS_VOICE          TTS voice (OpenAI voice name or ElevenLabs voice ID)
+  OVERHUMAN_TTS_URL            TTS API base URL override
+  OVERHUMAN_TTS_API_KEY        TTS API key (default: OPENAI_API_KEY / ELEVENLABS_API_KEY)
+  OVERHUMAN_MASTER_KEY         Passphrase decrypting "enc:v1:" secrets in config.json (see: encrypt)
+  OVERHUMAN_KIOSK_THEME        Kiosk theme: scifi, cyberpunk or clean (default: scifi)
+  OVERHUMAN_KIOSK_SCHEME       Kiosk color scheme: dark, light or system (default: dark)
+  OVERHUMAN_KIOSK_ACCENT       Kiosk accent color, e.g. #e4572e
+  OVERHUMAN_KIOSK_LOGO         Kiosk logo: image URL or file (relative to the data dir)
+  OVERHUMAN_KIOSK_CSS          Extra kiosk CSS file served in /theme.css (default: <data dir>/kiosk.css)
+  OVERHUMAN_WEBHOOK_URL        Extra outbound webhook receiving every event (or "webhooks" in config.json)
+  OVERHUMAN_WEBHOOK_SECRET     HMAC-SHA256 key signing deliveries to OVERHUMAN_WEBHOOK_URL
+  NOTION_TOKEN                 Notion integration/OAuth token for the docs skill (or notion.token in config.json)
+  GITHUB_TOKEN                 GitHub token for the issues skill (or issues.github.token in config.json)
+  JIRA_URL, JIRA_EMAIL, JIRA_API_TOKEN  Jira site, account and API token for the issues skill
+  OVERHUMAN_MAX_PAYLOAD_BYTES     Max request/message body size (default: 1 MiB, -1 = unlimited)
+  OVERHUMAN_MAX_ATTACHMENT_BYTES  Max size per attachment (default: 10 MiB)
+  OVERHUMAN_MAX_ATTACHMENTS       Max attachments per message (default: 10)
+  OVERHUMAN_MAX_INBOX_FILE_BYTES  Max inbox file size read by the file watcher (default: 5 MiB)
+  LLM_PROVIDER        Provider: openai, claude, ollama, lmstudio, groq, together, openrouter, custom, fake
   LLM_BASE_URL        Custom API base URL (e.g., http://localhost:11434 for Ollama)
   LLM_MODEL           Default model override (e.g., llama3.3, gpt-4o, claude-sonnet-4-20250514)
   LLM_API_KEY         API key for custom/groq/together/openrouter providers
+  FAKE_LLM_SCRIPT     Scripted responses for LLM_PROVIDER=fake (default: ~/.overhuman/fake_llm.json)
+  FAKE_LLM_LATENCY_MS Simulated latency per fake LLM call
+  FAKE_LLM_ERROR_RATE Fraction of fake LLM calls that fail (0.0-1.0)
+  OVERHUMAN_RECORD    Record provider traffic (redacted) into this directory as test fixtures
 
 `, appName, version, appName)
 }
@@ -174,6 +503,91 @@ func loadConfig() Config {
 		if persisted.APIAddr != "" {
 			cfg.APIAddr = persisted.APIAddr
 		}
+		if persisted.Timezone != "" {
+			cfg.Timezone = persisted.Timezone
+		}
+		if persisted.Locale != "" {
+			cfg.Locale = persisted.Locale
+		}
+		if len(persisted.Users) > 0 {
+			cfg.UserLocales = persisted.Users
+		}
+		if len(persisted.Templates) > 0 {
+			cfg.Templates = persisted.Templates
+		}
+		if persisted.ActiveHours != "" {
+			cfg.ActiveHours = persisted.ActiveHours
+		}
+		cfg.HeartbeatMin = persisted.HeartbeatMin
+		cfg.HeartbeatMax = persisted.HeartbeatMax
+		if persisted.AdminToken != "" {
+			cfg.AdminToken = persisted.AdminToken
+		}
+		cfg.Limits = persisted.Limits
+		cfg.Dispatch = persisted.Dispatch
+		cfg.AuditRetentionDays = persisted.AuditRetentionDays
+		cfg.AutoMigrateModels = persisted.AutoMigrateModels
+		cfg.ModelUpgradeTrials = persisted.ModelUpgradeTrials
+		cfg.DailyBudgetUSD = persisted.DailyBudgetUSD
+		cfg.MonthlyBudgetUSD = persisted.MonthlyBudgetUSD
+		cfg.PriorityPolicy = persisted.PriorityPolicy
+		cfg.SoulTokenBudget = persisted.SoulTokenBudget
+		cfg.SpeculationMultiplier = persisted.SpeculationMultiplier
+		cfg.WarmStartRuns = persisted.WarmStartRuns
+		cfg.Sessions = persisted.Sessions
+		cfg.Scratch = persisted.Scratch
+		cfg.PublicURL = persisted.PublicURL
+		cfg.EmbeddingModel = persisted.EmbeddingModel
+		cfg.EmbeddingBaseURL = persisted.EmbeddingBaseURL
+		cfg.UIProvider = persisted.UIProvider
+		cfg.UIModel = persisted.UIModel
+		cfg.UIBaseURL = persisted.UIBaseURL
+		cfg.FallbackProvider = persisted.FallbackProvider
+		cfg.FallbackModel = persisted.FallbackModel
+		cfg.FallbackBaseURL = persisted.FallbackBaseURL
+		cfg.Fallbacks = persisted.Fallbacks
+		cfg.LLMRetry = persisted.LLMRetry
+		cfg.LLMCache = persisted.LLMCache
+		cfg.SkillGeneration = persisted.SkillGeneration
+		cfg.Sandbox = persisted.Sandbox
+		cfg.STT = persisted.STT
+		cfg.KeyExpiry = parseKeyExpiry(persisted.KeyExpiry)
+		cfg.TTS = persisted.TTS
+		cfg.SpeechOutput = persisted.SpeechOutput
+		cfg.Notion = persisted.Notion
+		cfg.HTTP = persisted.HTTP
+		cfg.Issues = persisted.Issues
+		cfg.MCPServers = persisted.MCPServers
+		cfg.Webhooks = persisted.Webhooks
+		cfg.InboundWebhooks = persisted.InboundWebhooks
+		cfg.Moderation = persisted.Moderation
+		cfg.Approvals = persisted.Approvals
+		cfg.Documents = persisted.Documents
+		cfg.Kiosk = persisted.Kiosk
+		cfg.UIHistory = persisted.UIHistory
+		cfg.Email = persisted.Email
+		cfg.Team = persisted.Team
+		for name, sc := range persisted.Senses {
+			if sc.MemoryVisibility != "" {
+				if cfg.MemoryVisibility == nil {
+					cfg.MemoryVisibility = make(map[string]string)
+				}
+				cfg.MemoryVisibility[name] = sc.MemoryVisibility
+			}
+			if sc.Preprocess != nil {
+				if cfg.Preprocess == nil {
+					cfg.Preprocess = make(map[string]senses.PreprocessConfig)
+				}
+				cfg.Preprocess[name] = *sc.Preprocess
+			}
+			if sc.PrePrompt == "" {
+				continue
+			}
+			if cfg.PrePrompts == nil {
+				cfg.PrePrompts = make(map[string]string)
+			}
+			cfg.PrePrompts[name] = sc.PrePrompt
+		}
 	}
 
 	// Layer 2: Environment variables override config.json.
@@ -183,6 +597,197 @@ func loadConfig() Config {
 	if v := os.Getenv("OVERHUMAN_NAME"); v != "" {
 		cfg.AgentName = v
 	}
+	if v := os.Getenv("OVERHUMAN_TZ"); v != "" {
+		cfg.Timezone = v
+	}
+	if v := os.Getenv("OVERHUMAN_LOCALE"); v != "" {
+		cfg.Locale = v
+	}
+	if v := os.Getenv("OVERHUMAN_ACTIVE_HOURS"); v != "" {
+		cfg.ActiveHours = v
+	}
+	if v := os.Getenv("OVERHUMAN_HEARTBEAT_MIN"); v != "" {
+		cfg.HeartbeatMin = v
+	}
+	if v := os.Getenv("OVERHUMAN_HEARTBEAT_MAX"); v != "" {
+		cfg.HeartbeatMax = v
+	}
+	if v := os.Getenv("OVERHUMAN_ADMIN_TOKEN"); v != "" {
+		cfg.AdminToken = v
+	}
+	if v := os.Getenv("OVERHUMAN_PUBLIC_URL"); v != "" {
+		cfg.PublicURL = v
+	}
+	if v := os.Getenv("OVERHUMAN_MODE"); v != "" {
+		cfg.Mode = v
+	}
+	if v := os.Getenv("OVERHUMAN_AUTO_MIGRATE_MODELS"); v != "" {
+		cfg.AutoMigrateModels = v == "1" || strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("OVERHUMAN_SPECULATION_MULTIPLIER"); v != "" {
+		if m, err := strconv.ParseFloat(v, 64); err == nil && m >= 0 {
+			cfg.SpeculationMultiplier = m
+		} else {
+			log.Printf("[config] ignoring OVERHUMAN_SPECULATION_MULTIPLIER=%q: want a number >= 0", v)
+		}
+	}
+	for env, dst := range map[string]*float64{
+		"OVERHUMAN_DAILY_BUDGET_USD":   &cfg.DailyBudgetUSD,
+		"OVERHUMAN_MONTHLY_BUDGET_USD": &cfg.MonthlyBudgetUSD,
+	} {
+		if v := os.Getenv(env); v != "" {
+			if b, err := strconv.ParseFloat(v, 64); err == nil && b >= 0 {
+				*dst = b
+			} else {
+				log.Printf("[config] ignoring %s=%q: want a number >= 0", env, v)
+			}
+		}
+	}
+	if v := os.Getenv("OVERHUMAN_WARM_START_RUNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.WarmStartRuns = n
+		} else {
+			log.Printf("[config] ignoring OVERHUMAN_WARM_START_RUNS=%q: want a whole number", v)
+		}
+	}
+	if v := os.Getenv("OVERHUMAN_EMBEDDING_MODEL"); v != "" {
+		cfg.EmbeddingModel = v
+	}
+	if v := os.Getenv("OVERHUMAN_EMBEDDING_URL"); v != "" {
+		cfg.EmbeddingBaseURL = v
+	}
+	if v := os.Getenv("OVERHUMAN_EMBEDDING_API_KEY"); v != "" {
+		cfg.EmbeddingAPIKey = v
+	}
+	if v := os.Getenv("OVERHUMAN_UI_PROVIDER"); v != "" {
+		cfg.UIProvider = v
+	}
+	if v := os.Getenv("OVERHUMAN_UI_MODEL"); v != "" {
+		cfg.UIModel = v
+	}
+	if v := os.Getenv("OVERHUMAN_UI_URL"); v != "" {
+		cfg.UIBaseURL = v
+	}
+	if v := os.Getenv("OVERHUMAN_UI_API_KEY"); v != "" {
+		cfg.UIAPIKey = v
+	}
+	if v := os.Getenv("OVERHUMAN_FALLBACK_PROVIDER"); v != "" {
+		cfg.FallbackProvider = v
+	}
+	if v := os.Getenv("OVERHUMAN_FALLBACK_MODEL"); v != "" {
+		cfg.FallbackModel = v
+	}
+	if v := os.Getenv("OVERHUMAN_FALLBACK_URL"); v != "" {
+		cfg.FallbackBaseURL = v
+	}
+	envSpeech("OVERHUMAN_STT", &cfg.STT)
+	envSpeech("OVERHUMAN_TTS", &cfg.TTS)
+	if v := os.Getenv("NOTION_TOKEN"); v != "" {
+		cfg.Notion.Token = v
+	}
+	if v := os.Getenv("GITHUB_TOKEN"); v != "" {
+		cfg.Issues.GitHub.Token = v
+	}
+	if v := os.Getenv("JIRA_URL"); v != "" {
+		cfg.Issues.Jira.BaseURL = v
+	}
+	if v := os.Getenv("JIRA_EMAIL"); v != "" {
+		cfg.Issues.Jira.Email = v
+	}
+	if v := os.Getenv("JIRA_API_TOKEN"); v != "" {
+		cfg.Issues.Jira.Token = v
+	}
+	for env, v := range map[string]*string{
+		"EMAIL_IMAP_HOST":     &cfg.Email.IMAPServer,
+		"EMAIL_IMAP_USER":     &cfg.Email.IMAPUser,
+		"EMAIL_IMAP_PASS":     &cfg.Email.IMAPPass,
+		"EMAIL_SMTP_HOST":     &cfg.Email.SMTPServer,
+		"EMAIL_SMTP_USER":     &cfg.Email.SMTPUser,
+		"EMAIL_SMTP_PASS":     &cfg.Email.SMTPPass,
+		"EMAIL_SMTP_SECURITY": &cfg.Email.SMTPSecurity,
+		"EMAIL_FROM":          &cfg.Email.FromAddr,
+	} {
+		if s := os.Getenv(env); s != "" {
+			*v = s
+		}
+	}
+	if f := cfg.Email.DKIM.KeyFile; f != "" && !filepath.IsAbs(f) {
+		cfg.Email.DKIM.KeyFile = filepath.Join(cfg.DataDir, f)
+	}
+	for name, token := range map[string]*string{
+		"notion token":    &cfg.Notion.Token,
+		"github token":    &cfg.Issues.GitHub.Token,
+		"jira token":      &cfg.Issues.Jira.Token,
+		"email imap_pass": &cfg.Email.IMAPPass,
+		"email smtp_pass": &cfg.Email.SMTPPass,
+	} {
+		v, err := decryptConfigSecret(*token)
+		if err != nil {
+			log.Printf("[config] %s: %v (integration disabled)", name, err)
+		}
+		*token = v
+	}
+	if v := os.Getenv("OVERHUMAN_KIOSK_THEME"); v != "" {
+		cfg.Kiosk.Theme = v
+	}
+	if v := os.Getenv("OVERHUMAN_KIOSK_SCHEME"); v != "" {
+		cfg.Kiosk.ColorScheme = v
+	}
+	if v := os.Getenv("OVERHUMAN_KIOSK_ACCENT"); v != "" {
+		cfg.Kiosk.Accent = v
+	}
+	if v := os.Getenv("OVERHUMAN_KIOSK_LOGO"); v != "" {
+		cfg.Kiosk.Logo = v
+	}
+	if v := os.Getenv("OVERHUMAN_KIOSK_CSS"); v != "" {
+		cfg.Kiosk.CustomCSS = v
+	}
+	if v := os.Getenv("OVERHUMAN_WEBHOOK_URL"); v != "" {
+		cfg.Webhooks = append(cfg.Webhooks, webhook.Config{URL: v, Secret: os.Getenv("OVERHUMAN_WEBHOOK_SECRET")})
+	}
+	hooks := cfg.Webhooks[:0:0]
+	for _, h := range cfg.Webhooks {
+		v, err := decryptConfigSecret(h.Secret)
+		if err != nil {
+			log.Printf("[config] webhook %s: secret: %v (webhook disabled)", h.URL, err)
+			continue
+		}
+		h.Secret = v
+		hooks = append(hooks, h)
+	}
+	cfg.Webhooks = hooks
+	inbound := cfg.InboundWebhooks[:0:0]
+	for _, h := range cfg.InboundWebhooks {
+		v, err := decryptConfigSecret(h.Secret)
+		if err != nil {
+			log.Printf("[config] inbound webhook %s: secret: %v (webhook disabled)", h.Name, err)
+			continue
+		}
+		h.Secret = v
+		inbound = append(inbound, h)
+	}
+	cfg.InboundWebhooks = inbound
+	team := cfg.Team[:0:0]
+	for _, m := range cfg.Team {
+		v, err := decryptConfigSecret(m.Token)
+		if err != nil {
+			log.Printf("[config] team member %s: token: %v (member disabled)", m.Name, err)
+			continue
+		}
+		m.Token = v
+		team = append(team, m)
+	}
+	cfg.Team = team
+	envInt64("OVERHUMAN_MAX_PAYLOAD_BYTES", &cfg.Limits.MaxPayloadBytes)
+	envInt64("OVERHUMAN_MAX_ATTACHMENT_BYTES", &cfg.Limits.MaxAttachmentBytes)
+	envInt64("OVERHUMAN_MAX_INBOX_FILE_BYTES", &cfg.Limits.MaxInboxFileBytes)
+	if v := os.Getenv("OVERHUMAN_MAX_ATTACHMENTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Limits.MaxAttachments = n
+		} else {
+			log.Printf("[config] invalid OVERHUMAN_MAX_ATTACHMENTS=%q: %v", v, err)
+		}
+	}
 	if v := os.Getenv("ANTHROPIC_API_KEY"); v != "" {
 		cfg.ClaudeKey = v
 	}
@@ -205,6 +810,37 @@ func loadConfig() Config {
 	return cfg
 }
 
+// envInt64 parses an integer environment variable into dst, keeping dst
+// when the variable is unset or invalid.
+func envInt64(name string, dst *int64) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		log.Printf("[config] invalid %s=%q: %v", name, v, err)
+		return
+	}
+	*dst = n
+}
+
+// envSpeech applies <prefix>_PROVIDER, _MODEL, _VOICE, _URL and _API_KEY to
+// a speech backend config.
+func envSpeech(prefix string, dst *brain.SpeechConfig) {
+	for suffix, field := range map[string]*string{
+		"_PROVIDER": &dst.Provider,
+		"_MODEL":    &dst.Model,
+		"_VOICE":    &dst.Voice,
+		"_URL":      &dst.BaseURL,
+		"_API_KEY":  &dst.APIKey,
+	} {
+		if v := os.Getenv(prefix + suffix); v != "" {
+			*field = v
+		}
+	}
+}
+
 // ensureConfigured checks if the system is configured and guides the user if not.
 func ensureConfigured() {
 	cfg := loadConfig()
@@ -239,7 +875,7 @@ func ensureConfigured() {
 		line = strings.TrimSpace(strings.ToLower(line))
 		if line == "" || line == "y" || line == "yes" {
 			fmt.Println()
-			runConfigure()
+			runConfigure(nil)
 			return
 		}
 	}
@@ -257,6 +893,96 @@ func isTerminal() bool {
 	return fi.Mode()&os.ModeCharDevice != 0
 }
 
+// buildPrePrompts converts the configured per-channel templates into a
+// senses.PrePrompts transformer. Unknown channel keys are logged and skipped.
+func buildPrePrompts(cfg Config) *senses.PrePrompts {
+	templates := make(map[senses.SourceType]string, len(cfg.PrePrompts))
+	for name, tmpl := range cfg.PrePrompts {
+		st := senses.ParseSourceType(name)
+		if st == "" {
+			log.Printf("[config] unknown sense %q in senses config, pre-prompt ignored", name)
+			continue
+		}
+		templates[st] = tmpl
+	}
+	return senses.NewPrePrompts(templates)
+}
+
+// buildPreprocessor builds the per-channel input filter chains. Inputs are
+// translated into the language of the agent's locale. Unknown channel keys
+// are logged and skipped; an invalid chain disables preprocessing.
+func buildPreprocessor(cfg Config, agentLocale string, tr senses.Translator) *senses.Preprocessor {
+	configs := make(map[senses.SourceType]senses.PreprocessConfig, len(cfg.Preprocess))
+	for name, pc := range cfg.Preprocess {
+		st := senses.ParseSourceType(name)
+		if st == "" {
+			log.Printf("[config] unknown sense %q in senses config, preprocess ignored", name)
+			continue
+		}
+		configs[st] = pc
+	}
+	lang, _, _ := strings.Cut(agentLocale, "-")
+	pp, err := senses.NewPreprocessor(configs, strings.ToLower(lang), tr)
+	if err != nil {
+		log.Printf("[config] input preprocessing disabled: %v", err)
+		return nil
+	}
+	return pp
+}
+
+// buildScratch places the per-run scratch and artifact directories in
+// the data directory.
+func buildScratch(cfg Config) pipeline.ScratchPolicy {
+	sp := cfg.Scratch
+	sp.Dir = filepath.Join(cfg.DataDir, "scratch")
+	sp.ArtifactsDir = filepath.Join(cfg.DataDir, "artifacts")
+	return sp
+}
+
+// buildWarmStart returns the warm-start cache, or nil when disabled.
+func buildWarmStart(cfg Config) *pipeline.WarmStart {
+	if cfg.WarmStartRuns < 0 {
+		return nil
+	}
+	return pipeline.NewWarmStart(cfg.WarmStartRuns)
+}
+
+// buildMemoryVisibility converts the configured per-channel memory
+// visibility into the pipeline's map. Invalid entries are logged and
+// skipped, leaving the channel on the default.
+func buildMemoryVisibility(cfg Config) map[string]memory.Visibility {
+	out := make(map[string]memory.Visibility, len(cfg.MemoryVisibility))
+	for name, spec := range cfg.MemoryVisibility {
+		st := senses.ParseSourceType(name)
+		if st == "" {
+			log.Printf("[config] unknown sense %q in senses config, memory visibility ignored", name)
+			continue
+		}
+		v, err := memory.ParseVisibility(spec)
+		if err != nil {
+			log.Printf("[config] senses.%s: %v — using the default", name, err)
+			continue
+		}
+		out[string(st)] = v
+	}
+	return out
+}
+
+// buildLocaleResolver creates the timezone/locale resolver from config.
+// Invalid per-user entries are logged and skipped.
+func buildLocaleResolver(cfg Config) (*locale.Resolver, error) {
+	r, err := locale.NewResolver(locale.Settings{Timezone: cfg.Timezone, Locale: cfg.Locale})
+	if err != nil {
+		return nil, err
+	}
+	for user, us := range cfg.UserLocales {
+		if err := r.SetUser(user, us); err != nil {
+			log.Printf("[config] %v — using agent default", err)
+		}
+	}
+	return r, nil
+}
+
 // bootstrap initializes all subsystems and returns the pipeline dependencies.
 func bootstrap(cfg Config) (pipeline.Dependencies, *reflection.Engine, *genui.UIGenerator, error) {
 	// Ensure data directory exists.
@@ -274,6 +1000,25 @@ func bootstrap(cfg Config) (pipeline.Dependencies, *reflection.Engine, *genui.UI
 	}
 	log.Printf("[bootstrap] soul initialized: %s", cfg.AgentName)
 
+	// Timezone/locale.
+	loc, err := buildLocaleResolver(cfg)
+	if err != nil {
+		return pipeline.Dependencies{}, nil, nil, fmt.Errorf("locale: %w", err)
+	}
+	agentZone := loc.Agent()
+	log.Printf("[bootstrap] timezone: %s, locale: %s", agentZone.Location, agentZone.Locale)
+
+	// Daemon mode (normal / read_only / maintenance).
+	modeSwitch := senses.NewModeSwitch()
+	if cfg.Mode != "" {
+		mode, err := senses.ParseDaemonMode(cfg.Mode)
+		if err != nil {
+			return pipeline.Dependencies{}, nil, nil, fmt.Errorf("mode: %w", err)
+		}
+		modeSwitch.Set(mode, "")
+		log.Printf("[bootstrap] mode: %s", mode)
+	}
+
 	// LLM provider — universal, supports any OpenAI-compatible endpoint.
 	llm, providerName, err := createLLMProvider(cfg)
 	if err != nil {
@@ -296,19 +1041,168 @@ func bootstrap(cfg Config) (pipeline.Dependencies, *reflection.Engine, *genui.UI
 	}
 	log.Printf("[bootstrap] pattern tracker ready")
 
+	savings, err := memory.NewSavingsTracker(ltm.DB())
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, fmt.Errorf("savings tracker: %w", err)
+	}
+
+	// Budget — spending is kept in the database so the caps hold across
+	// restarts; without caps it is only tracked.
+	spend, err := budget.Open(ltm.DB(), cfg.DailyBudgetUSD, cfg.MonthlyBudgetUSD)
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, err
+	}
+	log.Printf("[bootstrap] budget: %s", spend.BudgetStatus())
+
+	// Approvals — skills using the configured permissions wait for an
+	// admin; the history is kept in the database.
+	approvalStore, err := approvals.Open(ltm.DB(), cfg.Approvals.timeout())
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, err
+	}
+
+	// Contact book — people and organizations from requests.
+	entities, err := memory.NewEntityStore(ltm.DB())
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, fmt.Errorf("contact book: %w", err)
+	}
+
+	// Run buffer — findings of long runs survive failures and crashes.
+	runs, err := memory.NewRunBuffer(ltm)
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, fmt.Errorf("run buffer: %w", err)
+	}
+
 	stm := memory.NewShortTermMemory(100)
 
 	// Brain — model router uses models from the active provider.
-	var router *brain.ModelRouter
-	if up, ok := llm.(*brain.UniversalProvider); ok {
-		router = brain.NewModelRouterWithModels(up.ModelEntries())
-	} else {
-		router = brain.NewModelRouter()
-		router.SetProvider(providerName)
-	}
+	router := newModelRouter(llm, providerName)
 	log.Printf("[bootstrap] model router: provider=%s", providerName)
+
+	// Token usage per provider and model, for GET /metrics. Metering sits
+	// below the cache so cached answers are not counted.
+	metrics := newDaemonMetrics()
+	llm = meterLLM(llm, metrics)
+
+	// Response cache — the clarification and review of a task run again
+	// are answered from the database instead of being paid for again.
+	if ttl := cfg.LLMCache.ttl(); ttl > 0 {
+		cached, err := brain.OpenCachingProvider(llm, ltm.DB(), ttl)
+		if err != nil {
+			ltm.Close()
+			return pipeline.Dependencies{}, nil, nil, err
+		}
+		llm = cached
+		log.Printf("[bootstrap] LLM response cache: %s", ttl)
+	}
+
+	// Fixture recording — provider traffic is saved (redacted) for replay
+	// in tests; see braintest.
+	if dir := os.Getenv("OVERHUMAN_RECORD"); dir != "" {
+		rec, err := brain.NewRecorder(llm, dir, cfg.ClaudeKey, cfg.OpenAIKey, cfg.LLMAPIKey)
+		if err != nil {
+			ltm.Close()
+			return pipeline.Dependencies{}, nil, nil, err
+		}
+		llm = rec
+		log.Printf("[bootstrap] recording LLM traffic to %s", dir)
+	}
+
+	// Failover — the configured fallbacks, then a local model, answer in
+	// turn while the provider is failing (after its own retries).
+	if chain := createFallbackChain(cfg); len(chain) > 0 {
+		names := make([]string, len(chain))
+		for i, fb := range chain {
+			names[i] = fb.Name()
+			chain[i] = meterLLM(fb, metrics)
+		}
+		llm = brain.NewFallbackChain(llm, chain...)
+		log.Printf("[bootstrap] failover: %s", strings.Join(names, " → "))
+	}
+
+	// Soul linting — size budget, dangerous directives and contradictions.
+	soulLinter := soul.NewLinter(llm, router.Select("simple", 1000))
+	if cfg.SoulTokenBudget > 0 {
+		soulLinter.MaxTokens = cfg.SoulTokenBudget
+	}
+	s.SetLinter(soulLinter)
+	if content, err := s.Read(); err == nil {
+		for _, is := range soulLinter.Static(content).Issues {
+			log.Printf("[bootstrap] soul lint (%s): %s", is.Severity, is.Message)
+		}
+	}
+
+	// Per-fingerprint routing overrides learned from escalations.
+	overrides, err := brain.NewRoutingOverrides(filepath.Join(cfg.DataDir, "routing_overrides.json"))
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, err
+	}
+	if n := len(overrides.List()); n > 0 {
+		log.Printf("[bootstrap] routing overrides: %d learned", n)
+	}
 	ca := brain.NewContextAssembler()
 
+	// Audit trail (quota violations, skill executions, ...).
+	auditStore, err := security.NewFileAuditStore(filepath.Join(cfg.DataDir, "audit.jsonl"))
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, err
+	}
+	auditLog := security.NewAuditLogger(auditStore)
+
+	// Per-skill permission decisions (asked for inline by the CLI).
+	perms, err := security.NewPermissionStore(permissionsPath(cfg))
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, err
+	}
+
+	// Untrusted context layers are scanned for prompt injection; hits are
+	// flagged in the prompt and audited.
+	ca.SetInjectionDetector(security.NewSanitizer(security.SanitizerConfig{}), func(source string, matches []string) {
+		auditLog.Log(security.AuditInjectionWarn, security.SeverityWarn, "context", "system", "retrieve", source, true,
+			map[string]string{"matches": strings.Join(matches, "; ")})
+	})
+
+	// Skill registry — starter skills plus anything generated later. The
+	// catalog file lets `overhuman skills` read docs without the daemon.
+	skillReg := instruments.NewSkillRegistry()
+	skills.RegisterAll(skillReg, skills.Config{DataDir: cfg.DataDir, Notion: cfg.Notion, Issues: cfg.Issues, HTTP: cfg.HTTP})
+	if n, errs := skills.RegisterTemplates(skillReg, skillTemplatesDir(cfg)); n > 0 || len(errs) > 0 {
+		for _, err := range errs {
+			log.Printf("[skills] template %v (skipped)", err)
+		}
+		log.Printf("[skills] %d template skill(s) from %s", n, skillTemplatesDir(cfg))
+	}
+	// The last catalog keeps the status and fitness of generated skills,
+	// which are loaded once the generator exists.
+	prevCatalog, _ := instruments.LoadCatalog(skillCatalogPath(cfg))
+	if err := skillReg.SetCatalogPath(skillCatalogPath(cfg)); err != nil {
+		log.Printf("[bootstrap] skill catalog: %v", err)
+	}
+
+	// Self-change history — soul edits and skill promotions are observed
+	// and can be rolled back from the kiosk.
+	changes, err := versioning.Open(filepath.Join(cfg.DataDir, "changes.json"))
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, err
+	}
+	trackChanges(changes, s, skillReg)
+
+	// Conversation transcripts for `export-chat` and the kiosk download.
+	transcripts, err := memory.NewTranscriptLog(transcriptsPath(cfg))
+	if err != nil {
+		ltm.Close()
+		return pipeline.Dependencies{}, nil, nil, err
+	}
+
 	// Reflection engine.
 	reflEngine := reflection.NewEngine(llm, router, ca, ltm)
 
@@ -322,15 +1216,120 @@ func bootstrap(cfg Config) (pipeline.Dependencies, *reflection.Engine, *genui.UI
 		Patterns:      pt,
 		AutoThreshold: 3,
 		Reflection:    reflEngine,
+		Locale:        loc,
+		Mode:          modeSwitch,
+		Skills:        skillReg,
+		Savings:       savings,
+		Budget:        spend,
+		Entities:      entities,
+		Semantic:      createSemanticMemory(cfg, providerName, ltm),
+		Runs:          runs,
+		Events:        events.New(),
+		AuditLog:      auditLog,
+		Permissions:   perms,
+		Metrics:       metrics,
+		Goals:         goals.New(),
+
+		PolicyEnforcer:      security.NewPolicyEnforcer(),
+		Approvals:           approvalStore,
+		ApprovalPermissions: cfg.Approvals.Permissions,
+		Experiments:         evolution.NewExperimentManager(),
+
+		VersionControl:      changes,
+		Transcripts:         transcripts,
+		RoutingOverrides:    overrides,
+		EscalationThreshold: defaultEscalationThreshold,
+		PriorityPolicy:      budget.DefaultPolicy().Merge(cfg.PriorityPolicy),
+
+		SpeculationMultiplier: cfg.SpeculationMultiplier,
+		MemoryVisibility:      buildMemoryVisibility(cfg),
+		WarmStart:             buildWarmStart(cfg),
+		Sessions:              cfg.Sessions,
+		Scratch:               buildScratch(cfg),
+	}
+
+	// Speech — optional; a misconfigured backend disables voice only.
+	if deps.STT, err = createSpeechToText(cfg); err != nil {
+		log.Printf("[bootstrap] speech-to-text disabled: %v", err)
+	} else if deps.STT != nil {
+		log.Printf("[bootstrap] speech-to-text: %s", deps.STT.Name())
+	}
+	if deps.TTS, err = createTextToSpeech(cfg); err != nil {
+		log.Printf("[bootstrap] text-to-speech disabled: %v", err)
+	} else if deps.TTS != nil {
+		log.Printf("[bootstrap] text-to-speech: %s", deps.TTS.Name())
+	}
+
+	// Code sandbox — generated skills run in Docker or WASM; without it
+	// they are denied and their tasks go to the LLM.
+	if deps.Sandbox, err = createSandbox(cfg); err != nil {
+		log.Printf("[bootstrap] sandbox unavailable, code skills are denied: %v", err)
+	} else if deps.Sandbox != nil {
+		sc := cfg.Sandbox.config()
+		log.Printf("[bootstrap] sandbox: %s, %dMB, %s", deps.Sandbox.Name(), sc.MemoryMB, sc.Timeout)
+	}
+
+	// Skill generation — repeated tasks get a code skill run in the sandbox.
+	forge := createSkillForge(cfg, &deps)
+	n, errs := forge.Load(prevCatalog)
+	for _, err := range errs {
+		log.Printf("[skills] generated skill %v (skipped)", err)
+	}
+	if deps.Generator != nil {
+		forge.Subscribe(deps.Events)
+		log.Printf("[bootstrap] skill generation: %s, %d generated skill(s)", forge.language, n)
+	} else if n > 0 {
+		log.Printf("[bootstrap] skill generation disabled, %d generated skill(s) loaded", n)
 	}
 
 	// UI generator — separate LLM call for visual representation.
 	uiGen := genui.NewUIGenerator(llm, router)
+	if cfg.UIProvider != "" || cfg.UIModel != "" {
+		uiLLM, err := createUIProvider(cfg)
+		if err != nil {
+			log.Printf("[bootstrap] UI provider disabled, using the main provider: %v", err)
+		} else {
+			uiGen.Pin(uiLLM, cfg.UIModel)
+			log.Printf("[bootstrap] UI generation pinned to %s", uiPinLabel(cfg, providerName))
+		}
+	}
+
+	// Event bus — subsystems publish, webhooks, metrics and the kiosk
+	// subscribe.
+	wireEvents(&deps)
 
 	log.Printf("[bootstrap] all subsystems ready")
 	return deps, reflEngine, uiGen, nil
 }
 
+// duplicateAck is the short reply sent for an input whose content was
+// already processed.
+func duplicateAck(input *senses.UnifiedInput, loc *time.Location) string {
+	what := "this message"
+	if input.SourceType == senses.SourceFile {
+		what = "this file"
+	}
+	when := ""
+	if t, err := time.Parse(time.RFC3339, input.SourceMeta.Extra[senses.ExtraDuplicateSeenAt]); err == nil {
+		when = " on " + t.In(loc).Format("Jan 2 15:04")
+	}
+	return fmt.Sprintf("Already received %s%s, so I'm not processing it again (reference %s).", what, when, input.SourceMeta.Extra[senses.ExtraDuplicateOf])
+}
+
+// newModelRouter builds the router from the provider's own model list, or
+// the built-in defaults filtered to providerName.
+func newModelRouter(llm brain.LLMProvider, providerName string) *brain.ModelRouter {
+	if up, ok := llm.(*brain.UniversalProvider); ok {
+		return brain.NewModelRouterWithModels(up.ModelEntries())
+	}
+	if fp, ok := llm.(*brain.FakeProvider); ok {
+		return brain.NewModelRouterWithModels(fp.ModelEntries())
+	}
+	router := brain.NewModelRouter()
+	router.SetProvider(providerName)
+	return router
+}
+
 // createLLMProvider creates the appropriate LLM provider based on config.
 // Priority: LLM_PROVIDER env > ANTHROPIC_API_KEY > OPENAI_API_KEY.
 func createLLMProvider(cfg Config) (brain.LLMProvider, string, error) {
@@ -386,7 +1385,7 @@ func createNamedProvider(cfg Config) (brain.LLMProvider, string, error) {
 		}
 		// Note: Claude native API uses different message format.
 		// Use the dedicated ClaudeProvider for full compatibility.
-		p := brain.NewClaudeProvider(apiKey)
+		p := brain.NewClaudeProvider(apiKey, brain.WithClaudeRetry(retryPolicy(cfg)))
 		return p, "claude", nil
 
 	case "ollama":
@@ -428,18 +1427,104 @@ func createNamedProvider(cfg Config) (brain.LLMProvider, string, error) {
 		}
 		pcfg = brain.CustomConfig("custom", cfg.LLMBaseURL, apiKey, model)
 
+	case "fake":
+		fcfg, err := loadFakeConfig(cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		return brain.NewFakeProvider(fcfg), "fake", nil
+
 	default:
-		return nil, "", fmt.Errorf("unknown LLM_PROVIDER: %q (use: openai, claude, ollama, lmstudio, groq, together, openrouter, custom)", cfg.LLMProvider)
+		return nil, "", fmt.Errorf("unknown LLM_PROVIDER: %q (use: openai, claude, ollama, lmstudio, groq, together, openrouter, custom, fake)", cfg.LLMProvider)
 	}
 
 	if model != "" && pcfg.DefaultModel != model {
 		pcfg.DefaultModel = model
 	}
+	pcfg.Retry = retryPolicy(cfg)
 
 	p := brain.NewUniversalProvider(pcfg)
 	return p, pcfg.Name, nil
 }
 
+// createUIProvider creates the dedicated UI generation provider, or returns
+// nil when UI generation only pins a model of the main provider.
+func createUIProvider(cfg Config) (brain.LLMProvider, error) {
+	if cfg.UIProvider == "" {
+		return nil, nil
+	}
+	sub := cfg
+	sub.LLMProvider = cfg.UIProvider
+	sub.LLMModel = cfg.UIModel
+	sub.LLMBaseURL = cfg.UIBaseURL
+	sub.LLMAPIKey = cfg.UIAPIKey
+	p, _, err := createNamedProvider(sub)
+	return p, err
+}
+
+// createFallbackProvider creates the offline fallback provider, or returns
+// nil when none is configured.
+func createFallbackProvider(cfg Config) (brain.LLMProvider, error) {
+	if cfg.FallbackProvider == "" {
+		return nil, nil
+	}
+	sub := cfg
+	sub.LLMProvider = cfg.FallbackProvider
+	sub.LLMModel = cfg.FallbackModel
+	sub.LLMBaseURL = cfg.FallbackBaseURL
+	sub.LLMAPIKey = ""
+	p, _, err := createNamedProvider(sub)
+	return p, err
+}
+
+// uiPinLabel describes the pinned UI provider and model for the log.
+func uiPinLabel(cfg Config, mainProvider string) string {
+	provider, model := cfg.UIProvider, cfg.UIModel
+	if provider == "" {
+		provider = mainProvider
+	}
+	if model == "" {
+		model = "default model"
+	}
+	return provider + "/" + model
+}
+
+// loadFakeConfig builds the fake provider config. The script is read from
+// FAKE_LLM_SCRIPT or <data>/fake_llm.json if present; FAKE_LLM_LATENCY_MS
+// and FAKE_LLM_ERROR_RATE override the script values.
+func loadFakeConfig(cfg Config) (brain.FakeConfig, error) {
+	var fcfg brain.FakeConfig
+	path := os.Getenv("FAKE_LLM_SCRIPT")
+	if path == "" {
+		def := filepath.Join(cfg.DataDir, "fake_llm.json")
+		if _, err := os.Stat(def); err == nil {
+			path = def
+		}
+	}
+	if path != "" {
+		loaded, err := brain.LoadFakeConfig(path)
+		if err != nil {
+			return fcfg, err
+		}
+		fcfg = loaded
+	}
+	if v := os.Getenv("FAKE_LLM_LATENCY_MS"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil {
+			return fcfg, fmt.Errorf("fake: invalid FAKE_LLM_LATENCY_MS %q", v)
+		}
+		fcfg.LatencyMs = ms
+	}
+	if v := os.Getenv("FAKE_LLM_ERROR_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return fcfg, fmt.Errorf("fake: invalid FAKE_LLM_ERROR_RATE %q (want 0.0-1.0)", v)
+		}
+		fcfg.ErrorRate = rate
+	}
+	return fcfg, nil
+}
+
 // runCLI starts the agent in interactive CLI mode.
 func runCLI() {
 	cfg := loadConfig()
@@ -448,8 +1533,16 @@ func runCLI() {
 		log.Fatalf("[cli] bootstrap: %v", err)
 	}
 
-	p := pipeline.New(deps)
+	prePrompts := buildPrePrompts(cfg)
 	cli := senses.NewCLISense(os.Stdin, os.Stdout)
+	out := make(chan *senses.UnifiedInput, 10)
+
+	// New skill permissions are asked for inline; the answer is the next
+	// line the user types.
+	deps.PermissionPrompter = &cliPermissionPrompter{w: os.Stdout, answers: out}
+	deps.Approvals.OnRequest(cliApprovalPrompter(deps.Approvals, os.Stdout, out))
+	p := pipeline.New(deps)
+	preprocessor := buildPreprocessor(cfg, deps.Locale.Agent().Locale, p)
 	uiRenderer := genui.NewCLIRenderer(os.Stdout, os.Stdin)
 	uiReflection := genui.NewReflectionStore()
 	caps := genui.CLICapabilities()
@@ -466,7 +1559,27 @@ func runCLI() {
 		cancel()
 	}()
 
-	out := make(chan *senses.UnifiedInput, 10)
+	// Print the answer as it is generated; the rendered UI follows.
+	streaming := false
+	p.OnPartial(func(evt pipeline.PartialEvent) {
+		if evt.Done {
+			if streaming {
+				cli.SendPartial("\n")
+			}
+			streaming = false
+			return
+		}
+		if !streaming {
+			cli.SendPartial("\n")
+			streaming = true
+		}
+		cli.SendPartial(evt.Chunk)
+	})
+
+	// Degraded mode — the provider is unreachable and a local model answers.
+	deps.Events.Subscribe("provider.*", func(ev events.Event) {
+		cli.Send(ctx, "", "⚠ "+fallbackNotice(ev.Data.(brain.FallbackStatus), ev.Time))
+	})
 
 	// CLI session ID — one per process lifetime for conversation continuity.
 	cliSessionID := fmt.Sprintf("cli_%d", time.Now().UnixNano())
@@ -490,6 +1603,10 @@ func runCLI() {
 				return
 			}
 			input.SessionID = cliSessionID
+			if err := preprocessor.Apply(ctx, input); err != nil {
+				log.Printf("[cli] preprocess: %v", err)
+			}
+			prePrompts.Apply(input)
 
 			result, err := p.Run(ctx, *input)
 			if err != nil {
@@ -541,19 +1658,93 @@ func runCLI() {
 	}
 }
 
-// runDaemon starts the full daemon with HTTP API, WebSocket UI server, and heartbeat timer.
+// runRemoteCLI is the interactive CLI against a running daemon: each line
+// is sent to POST /input/sync and the result printed.
+func runRemoteCLI(addr string) {
+	client := netaddr.HTTPClient(addr, 90*time.Second)
+	url := netaddr.URL(addr, "/input/sync")
+	cli := senses.NewCLISense(os.Stdin, os.Stdout)
+	sessionID := fmt.Sprintf("cli_%d", time.Now().UnixNano())
+	kiosk := deriveKioskAddr(addr)
+	kioskClient := netaddr.HTTPClient(kiosk, 10*time.Second)
+	adminToken := loadConfig().AdminToken
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	out := make(chan *senses.UnifiedInput, 10)
+	go func() {
+		if err := cli.Start(ctx, out); err != nil && ctx.Err() == nil {
+			log.Printf("[cli] sense error: %v", err)
+		}
+		cancel()
+	}()
+
+	fmt.Printf("%s v%s — remote session with %s (type /quit to exit)\n\n", appName, version, addr)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case input := <-out:
+			if res, ok := cliApprovalCommand(kioskClient, kiosk, adminToken, input.Payload); ok {
+				cli.Send(ctx, "", res)
+				continue
+			}
+			body, _ := json.Marshal(map[string]string{
+				"payload":    input.Payload,
+				"sender":     "cli",
+				"session_id": sessionID,
+			})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := client.Do(req)
+			if err != nil {
+				cli.Send(ctx, "", fmt.Sprintf("Error: %v", err))
+				continue
+			}
+			var res struct {
+				Result  string `json:"result"`
+				Error   string `json:"error"`
+				Message string `json:"message"`
+			}
+			json.NewDecoder(resp.Body).Decode(&res)
+			resp.Body.Close()
+			switch {
+			case resp.StatusCode == http.StatusOK:
+				cli.Send(ctx, "", res.Result)
+			case res.Message != "":
+				cli.Send(ctx, "", fmt.Sprintf("Error (%d): %s", resp.StatusCode, res.Message))
+			default:
+				cli.Send(ctx, "", fmt.Sprintf("Error (%d): %s", resp.StatusCode, res.Error))
+			}
+		}
+	}
+}
+
+// runDaemon starts the full daemon with HTTP API, WebSocket UI server, and heartbeat timer.
 func runDaemon() {
 	cfg := loadConfig()
 
-	// PID file — ensures single instance and enables `stop` command.
+	// PID file lock — ensures single instance and enables `stop` command.
+	// The kernel releases it if the daemon dies, so a crash leaves no
+	// lock behind.
 	pf := deploy.NewPIDFile(cfg.DataDir)
-	if err := pf.Guard(); err != nil {
+	if err := pf.Lock(deploy.LockInfo{Version: version}); err != nil {
 		log.Fatalf("[daemon] %v", err)
 	}
-	defer pf.Remove()
+	defer pf.Unlock()
 
-	// Set up log tee: write to stdout AND to log file.
-	logFile := setupLogTee(cfg.DataDir)
+	// Set up log tee: write to stdout AND to log file, and keep recent
+	// lines for GET /logs/stream.
+	logBuffer := observability.NewLogBuffer(0)
+	logFile := setupLogTee(cfg.DataDir, logBuffer)
 	if logFile != nil {
 		defer logFile.Close()
 	}
@@ -563,6 +1754,21 @@ func runDaemon() {
 		log.Fatalf("[daemon] bootstrap: %v", err)
 	}
 
+	// Outbound webhooks — signed notifications for n8n, Zapier and the like.
+	hooks := webhook.New(cfg.Webhooks, cfg.AgentName)
+	defer hooks.Close()
+	wireWebhooks(hooks, &deps)
+	if hooks != nil {
+		log.Printf("[daemon] %d webhook(s) configured", len(cfg.Webhooks))
+	}
+	gate, err := newOutputGate(cfg, deps.AuditLog, hooks)
+	if err != nil {
+		log.Fatalf("[daemon] %v", err)
+	}
+	if gate != nil {
+		log.Printf("[daemon] moderating replies to external channels (%s)", cmp.Or(cfg.Moderation.Provider, "local"))
+	}
+
 	p := pipeline.New(deps)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -575,18 +1781,167 @@ func runDaemon() {
 	// Shared input channel.
 	out := make(chan *senses.UnifiedInput, 50)
 
+	// Dispatcher — fair scheduling across senders with per-sender
+	// in-flight caps; queue waits and starved inputs go to the metrics.
+	dispatchCfg := cfg.Dispatch
+	dispatchCfg.OnDequeue = func(lane string, wait time.Duration, starved bool) {
+		deps.Metrics.Record(observability.MetricQueueWait, float64(wait.Milliseconds()), observability.Labels{"lane": lane})
+		if starved {
+			deps.Metrics.Increment("dispatch.starved")
+			log.Printf("[daemon] %s input waited %s in the queue", lane, wait.Round(time.Second))
+		}
+	}
+	// Task queue — accepted inputs are persisted until their run is done,
+	// so the ones in flight or waiting when the daemon dies are replayed
+	// at the next start. Heartbeats are not kept.
+	taskQueue, err := pipeline.NewTaskQueue(deps.LongTerm.DB(), 0)
+	if err != nil {
+		log.Printf("[daemon] %v — inputs will not survive a restart", err)
+	}
+	dispatchCfg.OnAccept = func(input *senses.UnifiedInput) {
+		if input.SourceType == senses.SourceTimer {
+			return
+		}
+		if err := taskQueue.Enqueue(input); err != nil {
+			log.Printf("[daemon] %v", err)
+		}
+		deps.Events.Publish(events.SenseInput, input)
+	}
+	dispatcher := senses.NewDispatcher(dispatchCfg)
+
+	// Standby — outside active hours polling senses and the heartbeat pause,
+	// and only critical inputs are processed; the rest wait for the morning.
+	agentZone := deps.Locale.Agent()
+	activeHours, err := senses.ParseActiveHours(cfg.ActiveHours, agentZone.Location)
+	if err != nil {
+		log.Printf("[daemon] %v — standby disabled", err)
+	}
+	standby := senses.NewStandby(activeHours)
+
+	// Adaptive heartbeat — frequent while inputs arrive or work is queued,
+	// hourly when idle, slowest overnight. Reported by GET /health.
+	hbMin, hbMax, err := parseHeartbeatBounds(cfg.HeartbeatMin, cfg.HeartbeatMax)
+	if err != nil {
+		log.Printf("[daemon] %v — using default heartbeat bounds", err)
+	}
+	hbSchedule := senses.NewHeartbeatSchedule(hbMin, hbMax, activeHours, agentZone.Location)
+
 	// Sense registry — manages all input/output channel adapters.
 	registry := senses.NewSenseRegistry()
 
+	// Input quotas — violations are logged and audited.
+	limits := cfg.Limits
+	limits.OnViolation = func(v senses.LimitViolation) {
+		log.Printf("[senses] %s: %v (from %s)", v.Sense, v, v.Source)
+		deps.AuditLog.Log(security.AuditInputBlocked, security.SeverityWarn, "senses", v.Source, "quota:"+v.Limit, v.Sense, false, map[string]string{
+			"size":  strconv.FormatInt(v.Size, 10),
+			"limit": strconv.FormatInt(v.Max, 10),
+		})
+	}
+
+	// Content-hash dedup for inbox files and e-mail.
+	dedup, err := senses.NewDedup(filepath.Join(cfg.DataDir, "dedup.json"), 0)
+	if err != nil {
+		log.Printf("[daemon] dedup disabled: %v", err)
+	}
+
+	// Housekeeping — database, logs, memory, backups, audit log and skill
+	// checks, run on idle heartbeats.
+	housekeeping := newHousekeeper(deps.LongTerm, housekeepingTasks(cfg, deps, logFile))
+
+	// Supervisor — restarts senses, the WS server and the main loop when
+	// they panic, fail or stall.
+	sup := newSupervisor(deps.AuditLog, deps.Metrics)
+
+	// Team mode: every API, WS and kiosk request needs a member token
+	// with a role that allows it.
+	var guard func(http.Handler) http.Handler
+	if access := newAccessControl(cfg, deps.AuditLog); access != nil {
+		guard = func(h http.Handler) http.Handler { return access.Guard(h, teamRouteRole) }
+		log.Printf("[daemon] team mode: %d member(s), role-based access enforced", len(cfg.Team))
+	}
+
+	// Voice sense — recordings posted to /voice or dropped into
+	// ~/.overhuman/voice/ are transcribed with the speech-to-text backend.
+	var voice *senses.VoiceSense
+	if deps.STT != nil {
+		voiceDir := filepath.Join(cfg.DataDir, "voice")
+		if err := os.MkdirAll(voiceDir, 0o755); err != nil {
+			log.Printf("[daemon] create voice inbox: %v", err)
+			voiceDir = ""
+		}
+		voice = senses.NewVoiceSense(senses.VoiceConfig{
+			STT:          deps.STT,
+			InboxDir:     voiceDir,
+			PollInterval: 5 * time.Second,
+			Paused:       standby.Paused,
+			Limits:       limits,
+		})
+		registry.Register(voice)
+		sup.Go(ctx, "voice", func(ctx context.Context) error {
+			log.Printf("[daemon] voice input: POST /voice, inbox %s (%s)", voiceDir, deps.STT.Name())
+			return voice.Start(ctx, out)
+		}, nil)
+	}
+
+	// Speech output — results read out with the TTS backend.
+	speechOut, err := createSpeechOutput(cfg, deps.TTS)
+	if err != nil {
+		log.Printf("[daemon] speech output disabled: %v", err)
+	} else if speechOut != nil {
+		log.Printf("[daemon] speech output: results read out with %s", deps.TTS.Name())
+	}
+
+	// Documents — large inputs are summarized chunk by chunk, the summaries
+	// cached in the database.
+	docs, err := createSummarizer(cfg, deps.LongTerm.DB(), deps)
+	if err != nil {
+		log.Printf("[daemon] document summaries disabled: %v", err)
+	}
+
 	// Start HTTP API sense.
 	api := senses.NewAPISense(cfg.APIAddr)
+	api.SetMode(deps.Mode, cfg.AdminToken)
+	if guard != nil {
+		api.SetMiddleware(guard)
+	}
+	api.SetLimits(limits)
+	api.SetShutdown(func() {
+		log.Printf("[daemon] shutdown requested via API")
+		cancel()
+	})
+	api.SetCapabilities(func() senses.Capabilities {
+		return daemonCapabilities(cfg, deps, registry)
+	})
+	api.SetTasks(apiActiveTasks(p), apiTaskCanceller(p))
+	api.SetHeartbeat(func() any { return hbSchedule.Plan(time.Now()) })
+	api.SetQueue(func() any { return dispatcher.Stats() })
+	api.SetHousekeeping(func() any { return housekeeping.Status() })
+	api.SetComponents(func() any { return sup.Status() })
+	api.SetRoutes("/goals", deps.Goals.RegisterRoutes)
+	api.SetRoutes("/metrics", metricsRoutes(deps.Metrics))
+	api.SetModels(deps.Router.Models)
+	if voice != nil {
+		api.SetVoice(voice)
+	}
+	if err := api.SetWebhooks(cfg.InboundWebhooks); err != nil {
+		log.Printf("[daemon] inbound webhooks disabled: %v", err)
+	} else if len(cfg.InboundWebhooks) > 0 {
+		log.Printf("[daemon] %d inbound webhook(s) at /webhook/{name}", len(cfg.InboundWebhooks))
+	}
+	api.SetRateLimits(func() any {
+		if qr, ok := deps.LLM.(brain.QuotaReporter); ok {
+			if q, ok := qr.Quota(); ok {
+				return q
+			}
+		}
+		return nil
+	})
 	registry.Register(api)
-	go func() {
+	sup.Go(ctx, "api", func(ctx context.Context) error {
 		log.Printf("[daemon] API listening on %s", cfg.APIAddr)
-		if err := api.Start(ctx, out); err != nil && ctx.Err() == nil {
-			log.Printf("[daemon] API error: %v", err)
-		}
-	}()
+		return api.Start(ctx, out)
+	}, nil)
 
 	// Channel adapters — start if configured via environment variables.
 	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
@@ -601,12 +1956,10 @@ func runDaemon() {
 		}
 		tg := senses.NewTelegramSense(tgCfg)
 		registry.Register(tg)
-		go func() {
+		sup.Go(ctx, "telegram", func(ctx context.Context) error {
 			log.Printf("[daemon] Telegram bot started")
-			if err := tg.Start(ctx, out); err != nil && ctx.Err() == nil {
-				log.Printf("[daemon] Telegram error: %v", err)
-			}
-		}()
+			return tg.Start(ctx, out)
+		}, nil)
 
 		// Set Telegram as primary notification channel if TELEGRAM_CHAT_ID is set.
 		if chatID := os.Getenv("TELEGRAM_CHAT_ID"); chatID != "" {
@@ -620,17 +1973,25 @@ func runDaemon() {
 		if slAddr == "" {
 			slAddr = ":3001"
 		}
+		// With an app token (xapp-...) the bot connects over Socket Mode and
+		// needs no public URL; otherwise Slack posts events to slAddr.
+		appToken := os.Getenv("SLACK_APP_TOKEN")
 		sl := senses.NewSlackSense(senses.SlackConfig{
-			BotToken:   token,
-			ListenAddr: slAddr,
+			BotToken:      token,
+			AppToken:      appToken,
+			SigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+			ListenAddr:    slAddr,
+			Limits:        limits,
 		})
 		registry.Register(sl)
-		go func() {
-			log.Printf("[daemon] Slack bot started on %s", slAddr)
-			if err := sl.Start(ctx, out); err != nil && ctx.Err() == nil {
-				log.Printf("[daemon] Slack error: %v", err)
+		sup.Go(ctx, "slack", func(ctx context.Context) error {
+			if appToken != "" {
+				log.Printf("[daemon] Slack bot connecting via Socket Mode")
+			} else {
+				log.Printf("[daemon] Slack bot started on %s", slAddr)
 			}
-		}()
+			return sl.Start(ctx, out)
+		}, nil)
 	}
 
 	if token := os.Getenv("DISCORD_BOT_TOKEN"); token != "" {
@@ -641,41 +2002,88 @@ func runDaemon() {
 		dc := senses.NewDiscordSense(senses.DiscordConfig{
 			BotToken:   token,
 			ListenAddr: dcAddr,
+			Limits:     limits,
 		})
 		registry.Register(dc)
-		go func() {
+		sup.Go(ctx, "discord", func(ctx context.Context) error {
 			log.Printf("[daemon] Discord bot started on %s", dcAddr)
-			if err := dc.Start(ctx, out); err != nil && ctx.Err() == nil {
-				log.Printf("[daemon] Discord error: %v", err)
+			return dc.Start(ctx, out)
+		}, nil)
+	}
+
+	if account := os.Getenv("SIGNAL_ACCOUNT"); account != "" {
+		sgCfg := senses.SignalConfig{
+			Addr:          os.Getenv("SIGNAL_CLI_ADDR"), // signal-cli daemon --tcp / --socket
+			Account:       account,
+			AttachmentDir: os.Getenv("SIGNAL_ATTACHMENT_DIR"),
+			Limits:        limits,
+		}
+		if numbers := os.Getenv("SIGNAL_ALLOWED_NUMBERS"); numbers != "" {
+			for _, n := range strings.Split(numbers, ",") {
+				if n = strings.TrimSpace(n); n != "" {
+					sgCfg.AllowedNumbers = append(sgCfg.AllowedNumbers, n)
+				}
 			}
-		}()
+		}
+		sg := senses.NewSignalSense(sgCfg)
+		registry.Register(sg)
+		sup.Go(ctx, "signal", func(ctx context.Context) error {
+			log.Printf("[daemon] Signal sense started for %s", account)
+			return sg.Start(ctx, out)
+		}, nil)
 	}
 
-	if imapHost := os.Getenv("EMAIL_IMAP_HOST"); imapHost != "" {
+	if imapHost := cfg.Email.IMAPServer; imapHost != "" {
 		emailSense := senses.NewEmailSense(senses.EmailConfig{
-			IMAPServer: imapHost, // e.g., "imap.gmail.com:993"
-			IMAPUser:   os.Getenv("EMAIL_IMAP_USER"),
-			IMAPPass:   os.Getenv("EMAIL_IMAP_PASS"),
-			SMTPServer: os.Getenv("EMAIL_SMTP_HOST"), // e.g., "smtp.gmail.com:587"
-			SMTPUser:   os.Getenv("EMAIL_SMTP_USER"),
-			SMTPPass:   os.Getenv("EMAIL_SMTP_PASS"),
-			FromAddr:   os.Getenv("EMAIL_FROM"),
+			IMAPServer:   imapHost, // e.g., "imap.gmail.com:993"
+			IMAPUser:     cfg.Email.IMAPUser,
+			IMAPPass:     cfg.Email.IMAPPass,
+			SMTPServer:   cfg.Email.SMTPServer, // e.g., "smtp.gmail.com:587"
+			SMTPUser:     cfg.Email.SMTPUser,
+			SMTPPass:     cfg.Email.SMTPPass,
+			FromAddr:     cfg.Email.FromAddr,
+			SMTPSecurity: cfg.Email.SMTPSecurity,
+			SMTPAuth:     cfg.Email.SMTPAuth,
+			DKIM:         cfg.Email.DKIM,
+			Location:     agentZone.Location,
+			Paused:       standby.Paused,
+			Limits:       limits,
+			Dedup:        dedup,
+			OnDeliveryFailure: func(f senses.DeliveryFailure) {
+				action := "send"
+				if f.Bounce {
+					action = "bounce"
+				}
+				deps.AuditLog.LogError(security.AuditDeliveryFail, "senses", "email", action, f.To, f.Reason,
+					map[string]string{"permanent": strconv.FormatBool(f.Permanent)})
+			},
 		})
 		registry.Register(emailSense)
-		go func() {
+		sup.Go(ctx, "email", func(ctx context.Context) error {
 			log.Printf("[daemon] Email sense started (IMAP: %s)", imapHost)
-			if err := emailSense.Start(ctx, out); err != nil && ctx.Err() == nil {
-				log.Printf("[daemon] Email error: %v", err)
-			}
-		}()
+			return emailSense.Start(ctx, out)
+		}, nil)
 	}
 
 	// WebSocket UI server on derived port (API port + 1).
 	wsAddr := deriveWSAddr(cfg.APIAddr)
 	wsSrv := genui.NewWSServer(wsAddr)
+	deps.Metrics.SetGauge("ws_clients", func() float64 { return float64(wsSrv.ClientCount()) })
+	if guard != nil {
+		wsSrv.SetMiddleware(guard)
+	}
 	uiAPIHandler := genui.NewUIAPIHandler(uiGen, wsSrv)
+	if deps.LongTerm != nil {
+		if store, err := genui.NewSQLUIStore(deps.LongTerm.DB(), cfg.UIHistory.retention()); err != nil {
+			log.Printf("[daemon] UI history kept in memory only: %v", err)
+		} else {
+			uiAPIHandler.SetStore(store)
+		}
+	}
 	uiReflection := genui.NewReflectionStore()
 	webCaps := genui.WebCapabilities(1280, 800)
+	webCaps.Timezone = agentZone.Location.String()
+	webCaps.Locale = agentZone.Locale
 
 	// Wire real-time pipeline stage events → WebSocket broadcast.
 	p.OnStageProgress(func(evt pipeline.StageEvent) {
@@ -686,7 +2094,71 @@ func runDaemon() {
 		if err != nil {
 			return
 		}
-		wsSrv.Broadcast(msg)
+		wsSrv.Publish(msg, genui.WSTopic{TaskID: evt.TaskID, Owner: taskSender(p, evt.TaskID)})
+	})
+
+	// Readable progress lines ("Executing subtask 2/3 on …") → WebSocket
+	// status, so the kiosk shows what a long run is doing.
+	p.OnStatus(func(evt pipeline.StatusEvent) {
+		if wsSrv.ClientCount() == 0 {
+			return
+		}
+		msg, err := genui.NewStatusMessage(evt.TaskID, evt.Stage, evt.Text)
+		if err != nil {
+			return
+		}
+		wsSrv.Publish(msg, genui.WSTopic{TaskID: evt.TaskID, Owner: taskSender(p, evt.TaskID)})
+	})
+
+	// Skill changes and newly automatable patterns → kiosk notices.
+	notify := func(msg string) {
+		if m, err := genui.NewNoticeMessage("info", msg); err == nil && wsSrv.ClientCount() > 0 {
+			wsSrv.Broadcast(m)
+		}
+	}
+	deps.Events.Subscribe("skill.*", func(ev events.Event) {
+		se := ev.Data.(pipeline.SkillEvent)
+		notify(fmt.Sprintf("Skill %s is now %s: %s", se.SkillID, se.To, se.Reason))
+	})
+	deps.Events.Subscribe(events.PatternTriggered, func(ev events.Event) {
+		notify(fmt.Sprintf("Repeated task worth automating: %s", ev.Data.(pipeline.PatternEvent).Goal))
+	})
+	// The kiosk banner follows the offline fallback (GET /api/mode).
+	deps.Events.Subscribe("provider.*", func(ev events.Event) {
+		level := "info"
+		if ev.Topic == events.ProviderDegraded {
+			level = "warn"
+		}
+		if m, err := genui.NewNoticeMessage(level, fallbackNotice(ev.Data.(brain.FallbackStatus), ev.Time)); err == nil {
+			wsSrv.Broadcast(m)
+		}
+	})
+
+	// Stream execution output → WebSocket ui_stream, shown as a preview
+	// until the generated UI arrives, and to the chat completion request
+	// the task answers, if it streams.
+	p.OnPartial(func(evt pipeline.PartialEvent) {
+		if evt.CorrelationID != "" {
+			api.SendPartial(evt.CorrelationID, evt.Chunk, evt.Done)
+		}
+		if wsSrv.ClientCount() == 0 {
+			return
+		}
+		msg, err := genui.NewTextStreamMessage(evt.TaskID, evt.Chunk, evt.Done)
+		if err != nil {
+			return
+		}
+		wsSrv.Publish(msg, genui.WSTopic{TaskID: evt.TaskID, Owner: taskSender(p, evt.TaskID)})
+	})
+
+	// Actions of generated UIs → handlers, answered with action_result.
+	actions := kioskActions(logBuffer, cfg.AdminToken, deps.VersionControl, deps.Skills, deps.Approvals, func(input *senses.UnifiedInput) bool {
+		select {
+		case out <- input:
+			return true
+		default:
+			return false
+		}
 	})
 
 	// Wire WS incoming messages → pipeline input or reflection store.
@@ -699,6 +2171,9 @@ func runDaemon() {
 				return
 			}
 			input := senses.NewUnifiedInput(senses.SourceAPI, payload.Text)
+			if p, ok := wsSrv.ClientPrincipal(connID); ok {
+				input.SourceMeta.Sender = p.Name
+			}
 			input.ResponseChannel = "ws"
 			input.CorrelationID = connID
 			input.SessionID = "ws_" + connID
@@ -723,21 +2198,206 @@ func runDaemon() {
 			})
 
 		case genui.WSMsgCancel:
-			log.Printf("[ws] cancel request from %s", connID)
+			payload, err := genui.ParseCancelPayload(msg)
+			if err != nil {
+				log.Printf("[ws] bad cancel payload from %s: %v", connID, err)
+				return
+			}
+			var who *security.Principal
+			if pr, ok := wsSrv.ClientPrincipal(connID); ok {
+				who = &pr
+			}
+			// The Stop button names the running task; without one, stop
+			// what this connection started.
+			ids := cancelTasks(p, who, func(t pipeline.ActiveTask) bool {
+				if payload.TaskID != "" {
+					return t.TaskID == payload.TaskID
+				}
+				return t.CorrelationID == connID
+			})
+			log.Printf("[ws] cancel request from %s: cancelled %v", connID, ids)
 
 		case genui.WSMsgAction:
-			log.Printf("[ws] action from %s (not yet routed)", connID)
+			payload, err := genui.ParseActionPayload(msg)
+			if err != nil {
+				log.Printf("[ws] bad action payload from %s: %v", connID, err)
+				return
+			}
+			req := genui.ActionRequest{ConnID: connID, ActionID: payload.ActionID, Data: payload.Data}
+			if pr, ok := wsSrv.ClientPrincipal(connID); ok {
+				req.Principal = &pr
+			}
+			go func() {
+				res := actions.Dispatch(ctx, req)
+				if !res.Success {
+					log.Printf("[ws] action %s from %s failed: %s", req.ActionID, connID, res.Error)
+				}
+				if m, err := genui.NewActionResultMessage(res); err == nil {
+					wsSrv.SendTo(connID, m)
+				}
+			}()
 		}
 	})
 
 	// Also start standalone WS server on derived port for non-browser clients.
-	go func() {
+	sup.Go(ctx, "ws", func(ctx context.Context) error {
 		log.Printf("[daemon] WebSocket also on %s (standalone)", wsAddr)
-		if err := wsSrv.Start(ctx); err != nil && ctx.Err() == nil {
-			log.Printf("[daemon] WS error: %v", err)
+		return wsSrv.Start(ctx)
+	}, nil)
+
+	// Standby release — once active hours begin (or the operator resumes),
+	// replay held inputs.
+	releaseHeld := func() {
+		held := standby.Release()
+		if len(held) == 0 {
+			return
+		}
+		log.Printf("[daemon] waking up: releasing %d held input(s)", len(held))
+		for _, in := range held {
+			select {
+			case out <- in:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+	if activeHours != nil {
+		log.Printf("[daemon] active hours: %s", activeHours)
+	}
+
+	// Deferral — inputs the priority policy deferred are retried after 15
+	// minutes (budget) or once the provider's rate limit refills; the
+	// pipeline defers them again while still constrained.
+	var deferredMu sync.Mutex
+	var deferred []deferredInput
+	hbSchedule.SetPending(func() int {
+		deferredMu.Lock()
+		defer deferredMu.Unlock()
+		return len(deferred) + standby.Pending() + dispatcher.Queued()
+	})
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				deferredMu.Lock()
+				var retry []*senses.UnifiedInput
+				waiting := deferred[:0]
+				for _, d := range deferred {
+					if now.Before(d.retryAt) {
+						waiting = append(waiting, d)
+					} else {
+						retry = append(retry, d.input)
+					}
+				}
+				deferred = waiting
+				deferredMu.Unlock()
+				for _, in := range retry {
+					select {
+					case out <- in:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				releaseHeld()
+			}
 		}
 	}()
 
+	// Automation rules — "when X then Y" glue from automations.yaml and the
+	// API, evaluated without the LLM. Tool actions use the MCP servers.
+	mcpTools := connectMCP(ctx, cfg.MCPServers)
+	if mcpTools != nil {
+		defer mcpTools.DisconnectAll()
+	}
+	automations, err := automation.New(cfg.DataDir, automation.Handlers{
+		Submit: func(in *senses.UnifiedInput) error {
+			select {
+			case out <- in:
+				return nil
+			default:
+				return errors.New("pipeline busy")
+			}
+		},
+		Notify: func(msg string) {
+			log.Printf("[automation] %s", msg)
+			if m, err := genui.NewNoticeMessage("info", msg); err == nil {
+				wsSrv.Broadcast(m)
+			}
+		},
+		Templates:   cfg.Templates,
+		Skills:      deps.Skills,
+		Permissions: deps.Permissions,
+		Tools:       mcpTools,
+		Mode:        deps.Mode,
+		Location:    agentZone.Location,
+		Fired: func(ev automation.Event) {
+			deps.Events.Publish(events.AutomationFired, ev)
+		},
+	})
+	if err != nil {
+		log.Printf("[daemon] automations disabled: %v", err)
+	} else {
+		if n := len(automations.Rules()); n > 0 {
+			log.Printf("[daemon] %d automation rule(s) loaded", n)
+		}
+		go automations.Run(ctx)
+	}
+
+	// Scheduled tasks — recurring requests with a cron expression, kept in
+	// the long-term memory database and managed over /api/schedules. Due
+	// tasks arrive as timer inputs and run through the pipeline.
+	schedules, err := scheduler.New(deps.LongTerm.DB(), agentZone.Location)
+	if err != nil {
+		log.Printf("[daemon] scheduled tasks disabled: %v", err)
+	} else {
+		if list, _ := schedules.List(); len(list) > 0 {
+			log.Printf("[daemon] %d scheduled task(s) loaded", len(list))
+		}
+		go schedules.Run(ctx, out)
+	}
+
+	// Command palette — recent tasks, templates, skills and admin actions.
+	recentTasks := genui.NewRecentTasks(20)
+	commands := genui.NewCommandRegistry()
+	commands.AddSource(recentTasks.Commands)
+	if len(cfg.Templates) > 0 {
+		commands.AddSource(genui.TemplateCommands(cfg.Templates))
+	}
+	if deps.Skills != nil {
+		commands.AddSource(skillCommands(deps.Skills))
+	}
+	registerAdminCommands(commands, standby, deps.Router, releaseHeld)
+	registerCostsCommand(commands, deps)
+
+	// Default model upgrades — evaluated on shadowed tasks, switched on accept.
+	upgrades, err := newUpgradeTrials(cfg.DataDir, deps.LLM, deps.Router, deps.Experiments, deps.Budget)
+	if err != nil {
+		log.Printf("[models] upgrade trials disabled: %v", err)
+	} else {
+		upgrades.notify = func(msg string) {
+			log.Printf("[models] %s", msg)
+			if m, err := genui.NewNoticeMessage("info", msg); err == nil {
+				wsSrv.Broadcast(m)
+			}
+		}
+		registerUpgradeCommands(commands, upgrades)
+	}
+
 	// Kiosk web server on derived port (API port + 2).
 	// WebSocket /ws is registered on the SAME mux as kiosk to avoid
 	// cross-port issues with browsers (same-origin policy for WS).
@@ -749,19 +2409,87 @@ func runDaemon() {
 		ShowSidebar:   true,
 		EmergencyStop: true,
 	}
+	branded := kioskCfg
+	cfg.Kiosk.apply(&branded, cfg.DataDir)
+	if err := branded.Validate(); err != nil {
+		log.Printf("[config] ignoring kiosk theming: %v", err)
+	} else {
+		kioskCfg = branded
+	}
 	kioskHandler := genui.NewKioskHandler(kioskCfg)
 	kioskMux := http.NewServeMux()
 	kioskHandler.RegisterRoutes(kioskMux)
 	uiAPIHandler.RegisterRoutes(kioskMux)
+	commands.RegisterRoutes(kioskMux)
+	kioskMux.Handle("GET /api/mode", deps.Mode)
+	deps.RoutingOverrides.RegisterRoutes(kioskMux)
+	deps.Skills.RegisterRoutes(kioskMux)
+	deps.Soul.RegisterRoutes(kioskMux)
+	deps.VersionControl.RegisterRoutes(kioskMux)
+	deps.Approvals.RegisterRoutes(kioskMux, cfg.AdminToken)
+	kioskMux.HandleFunc("GET /api/chat/export", exportChatHandler(deps.Transcripts))
+	registerSessionRoutes(kioskMux, p)
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		publicURL = kioskURL(kioskAddr)
+	}
+	artifacts, artErr := newArtifactStore(deps.Scratch.ArtifactsDir, filepath.Join(cfg.DataDir, "artifacts.key"), publicURL)
+	if artErr != nil {
+		log.Printf("[daemon] artifact downloads disabled: %v", artErr)
+	} else {
+		kioskMux.Handle("GET /artifacts/{task}/{file...}", artifacts)
+	}
+	kioskMux.HandleFunc("GET /api/costs", costsHandler(deps))
+	if automations != nil {
+		automations.RegisterRoutes(kioskMux)
+	}
+	if schedules != nil {
+		schedules.RegisterRoutes(kioskMux)
+	}
+	kioskMux.Handle("GET /api/capabilities", senses.CapabilitiesHandler(func() senses.Capabilities {
+		return daemonCapabilities(cfg, deps, registry)
+	}))
 	wsSrv.RegisterRoutes(kioskMux, ctx) // WS on kiosk port
 
+	kioskMux.HandleFunc("GET /api/whoami", whoamiHandler)
+	kioskMux.HandleFunc("GET /logs/stream", logStreamHandler(logBuffer, cfg.AdminToken))
+	if gate != nil {
+		gate.RegisterRoutes(kioskMux, cfg.AdminToken)
+	}
+	kioskMux.Handle("POST /debug/exec", &debugConsole{
+		router:     deps.Router,
+		overrides:  deps.RoutingOverrides,
+		patterns:   deps.Patterns,
+		longTerm:   deps.LongTerm,
+		dispatcher: dispatcher,
+		supervisor: sup,
+		heartbeat: func() bool {
+			select {
+			case out <- senses.NewHeartbeat():
+				log.Printf("[daemon] synthetic heartbeat from the debug console")
+				return true
+			default:
+				return false
+			}
+		},
+		adminToken: cfg.AdminToken,
+	})
+	var kioskHTTP http.Handler = kioskMux
+	if guard != nil {
+		kioskHTTP = guard(kioskMux)
+	}
 	kioskServer := &http.Server{
 		Addr:    kioskAddr,
-		Handler: kioskMux,
+		Handler: kioskHTTP,
 	}
 	go func() {
-		log.Printf("[daemon] Kiosk UI on http://%s", kioskAddr)
-		if err := kioskServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		ln, err := netaddr.Listen(kioskAddr)
+		if err != nil {
+			log.Printf("[daemon] kiosk error: %v", err)
+			return
+		}
+		log.Printf("[daemon] Kiosk UI on %s", kioskURL(kioskAddr))
+		if err := kioskServer.Serve(ln); err != nil && err != http.ErrServerClosed {
 			log.Printf("[daemon] kiosk error: %v", err)
 		}
 	}()
@@ -775,110 +2503,387 @@ func runDaemon() {
 			WatchDir:     inboxDir,
 			PollInterval: 5 * time.Second,
 			Recursive:    true,
+			Paused:       standby.Paused,
+			Limits:       limits,
+			Dedup:        dedup,
+			Extract:      true,
 		})
-		go func() {
+		registry.Register(fw)
+		sup.Go(ctx, "filewatcher", func(ctx context.Context) error {
 			log.Printf("[daemon] file watcher: %s", inboxDir)
-			if err := fw.Start(ctx, out); err != nil && ctx.Err() == nil {
-				log.Printf("[daemon] file watcher error: %v", err)
-			}
-		}()
+			return fw.Start(ctx, out)
+		}, nil)
 	}
 
-	// Heartbeat timer (every 30 minutes).
-	heartbeatTicker := time.NewTicker(30 * time.Minute)
-	defer heartbeatTicker.Stop()
+	// Model deprecation check — at startup and daily.
+	go watchModelDeprecations(ctx, deps.LLM, cfg, deps.Router, func(msg string) {
+		log.Printf("[models] %s", msg)
+		if m, err := genui.NewNoticeMessage("warn", msg); err == nil {
+			wsSrv.Broadcast(m)
+		}
+	})
 
-	go func() {
-		for {
+	// Newer default models — announced, or trialled with model_upgrade_trials.
+	if upgrades != nil {
+		go watchModelUpgrades(ctx, upgrades, cfg.ModelUpgradeTrials)
+	}
+
+	// Key expiry reminders — at startup and daily; repeated auth failures
+	// (a key rotated without updating the config) warn the same way.
+	keyNotify := func(msg string) {
+		log.Printf("[keys] %s", msg)
+		if m, err := genui.NewNoticeMessage("warn", msg); err == nil {
+			wsSrv.Broadcast(m)
+		}
+	}
+	go watchKeyExpiry(ctx, cfg.KeyExpiry, keyNotify)
+
+	// Approvals — announced to the kiosk and the webhooks as they come in.
+	announceApprovals(deps.Approvals, func(msg string) {
+		if m, err := genui.NewNoticeMessage("warn", msg); err == nil {
+			wsSrv.Broadcast(m)
+		}
+	}, hooks)
+	// Tasks parked on an approval are queued again once it is decided.
+	parked := newParkedTasks(deps.Approvals, func(in *senses.UnifiedInput) {
+		go func() {
 			select {
+			case out <- in:
 			case <-ctx.Done():
-				return
-			case <-heartbeatTicker.C:
-				hb := senses.NewHeartbeat()
-				select {
-				case out <- hb:
-					log.Printf("[daemon] heartbeat sent")
-				default:
-					log.Printf("[daemon] heartbeat skipped (pipeline busy)")
-				}
 			}
-		}
-	}()
+		}()
+	})
+	authFailures := newAuthFailureTracker(keyNotify)
 
-	log.Printf("[daemon] %s v%s started (API=%s, WS=%s, Kiosk=http://%s, Inbox=%s)", cfg.AgentName, version, cfg.APIAddr, wsAddr, kioskAddr, inboxDir)
+	// Weekly self-report — automation savings and budget.
+	go runWeeklyReport(ctx, deps, func(msg string) {
+		log.Printf("[report] %s", msg)
+		if m, err := genui.NewNoticeMessage("info", msg); err == nil {
+			wsSrv.Broadcast(m)
+		}
+	})
 
-	// Main processing loop.
-	go func() {
-		for {
+	// Heartbeat — sent at hbSchedule's adaptive interval.
+	go runHeartbeat(ctx, hbSchedule, func() {
+		if st := dispatcher.Stats(); st.Queued == 0 && st.InFlight == 0 {
+			housekeeping.RunDue(ctx, time.Now())
+		}
+		if standby.Paused() || deps.Mode.Maintenance() {
+			return
+		}
+		plan := hbSchedule.Plan(time.Now())
+		// The top pending goal, if any, is worked on instead.
+		if goal := nextGoalInput(deps.Goals); goal != nil {
+			id := goal.SourceMeta.Extra[extraGoalID]
+			deps.Goals.MarkInProgress(id, "")
 			select {
-			case <-ctx.Done():
+			case out <- goal:
+				log.Printf("[daemon] heartbeat: working on goal %s (next in %s: %s)", id, plan.Every, plan.Reason)
+			default:
+				deps.Goals.Requeue(id)
+				log.Printf("[daemon] heartbeat skipped (pipeline busy)")
+			}
+			return
+		}
+		select {
+		case out <- senses.NewHeartbeat():
+			log.Printf("[daemon] heartbeat sent (next in %s: %s)", plan.Every, plan.Reason)
+		default:
+			log.Printf("[daemon] heartbeat skipped (pipeline busy)")
+		}
+	})
+
+	log.Printf("[daemon] %s v%s started (API=%s, WS=%s, Kiosk=%s, Inbox=%s)", cfg.AgentName, version, cfg.APIAddr, wsAddr, kioskURL(kioskAddr), inboxDir)
+
+	// Per-channel pre-prompts from the "senses" config block.
+	prePrompts := buildPrePrompts(cfg)
+	if n := prePrompts.Len(); n > 0 {
+		log.Printf("[daemon] pre-prompts configured for %d channel(s)", n)
+	}
+	preprocessor := buildPreprocessor(cfg, agentZone.Locale, p)
+	if n := preprocessor.Len(); n > 0 {
+		log.Printf("[daemon] input preprocessing configured for %d channel(s)", n)
+	}
+
+	// replyWith routes text back to the channel an input came from, with
+	// the files of the run as attachments where the sense supports them
+	// and as download links otherwise.
+	replyWith := func(input *senses.UnifiedInput, text string, result *pipeline.RunResult) {
+		if input.ResponseChannel == "" {
+			return
+		}
+		atts := artifacts.Attachments(result)
+		var link func(senses.Attachment) string
+		if len(atts) > 0 {
+			link = artifacts.Link(result.TaskID)
+		}
+		if input.SourceType == senses.SourceAPI && input.CorrelationID != "" {
+			// API sync request — use correlation-based routing.
+			res := senses.APIResult{Text: text}
+			if result != nil {
+				res.TaskID, res.Model, res.CostUSD, res.Declined = result.TaskID, result.Model, result.CostUSD, result.Declined
+			}
+			api.SendResult(ctx, input.CorrelationID, res, atts, link)
+		} else if sense := registry.GetBySourceType(input.SourceType); sense != nil {
+			// Telegram, Slack, Discord, Email, Signal — send reply. Results
+			// pass the output moderation first; notices are our own text.
+			send := func(text string) error {
+				return senses.SendWithAttachments(ctx, sense, input.ResponseChannel, text, atts, link)
+			}
+			var err error
+			if result != nil {
+				err = gate.Send(ctx, string(input.SourceType), input.ResponseChannel, result.TaskID, text, send)
+			} else {
+				err = send(text)
+			}
+			if err != nil {
+				log.Printf("[daemon] reply via %s: %v", input.SourceType, err)
+			}
+		}
+	}
+	reply := func(input *senses.UnifiedInput, text string) { replyWith(input, text, nil) }
+
+	// Main processing loop — the dispatcher takes inputs round-robin across
+	// senders and channels, cfg.Dispatch.Workers at a time. A panicking
+	// input is recorded and skipped; when the queue stops moving the loop
+	// is restarted, cancelling the runs in flight. handleInput reports
+	// whether the input was kept to run later (standby, deferral), which
+	// does not count as an attempt; the others leave the task queue once
+	// handled.
+	handleInput := func(ctx context.Context, input *senses.UnifiedInput) (kept bool) {
+		if input.SourceType != senses.SourceTimer {
+			hbSchedule.Touch(time.Now())
+		}
+		if deps.Mode.Maintenance() {
+			if input.SourceType == senses.SourceTimer {
 				return
-			case input, ok := <-out:
-				if !ok {
-					return
+			}
+			log.Printf("[daemon] maintenance: rejected %s input %s", input.SourceType, input.InputID)
+			msg := deps.Mode.MaintenanceMessage()
+			if input.ResponseChannel == "ws" {
+				if m, err := genui.NewErrorMessage(http.StatusServiceUnavailable, msg); err == nil {
+					wsSrv.Broadcast(m)
 				}
-				result, err := p.Run(ctx, *input)
-				if err != nil {
-					log.Printf("[daemon] run error: %v", err)
-					continue
+			} else {
+				reply(input, msg)
+			}
+			return
+		}
+		if orig := input.SourceMeta.Extra[senses.ExtraDuplicateOf]; orig != "" {
+			log.Printf("[daemon] duplicate %s input %s (duplicate_of=%s), not re-running", input.SourceType, input.InputID, orig)
+			reply(input, duplicateAck(input, agentZone.Location))
+			return
+		}
+		if !standby.Admit(input) {
+			log.Printf("[daemon] standby: held %s input %s until %s", input.SourceType, input.InputID, activeHours)
+			return true
+		}
+		if automations != nil && automations.OnInput(ctx, input) {
+			log.Printf("[daemon] %s input %s handled by an automation rule", input.SourceType, input.InputID)
+			return
+		}
+		// The input as received, to run again if the task is parked.
+		received := *input
+		received.SourceMeta.Extra = maps.Clone(input.SourceMeta.Extra)
+		if docs != nil && cfg.Documents.condenses(input) {
+			condenseDocument(ctx, docs, deps.Budget, input)
+		}
+		if err := preprocessor.Apply(ctx, input); err != nil {
+			log.Printf("[daemon] preprocess %s input %s: %v", input.SourceType, input.InputID, err)
+		}
+		prePrompts.Apply(input)
+		result, err := p.Run(ctx, *input)
+		settleGoal(deps.Goals, input, result, err)
+		if d, ok := deferral(err, input, p.QuotaResetAt(), time.Now()); ok {
+			if input.SourceType == senses.SourceTimer {
+				log.Printf("[daemon] %s deferred: %s", input.SourceMeta.Channel, d.reason)
+				return
+			}
+			log.Printf("[daemon] deferred %s input %s (priority %s) until %s: %s", input.SourceType, input.InputID, input.Priority, d.retryAt.Format(time.Kitchen), d.reason)
+			if input.SourceMeta.Extra["deferred"] == "" {
+				if input.SourceMeta.Extra == nil {
+					input.SourceMeta.Extra = make(map[string]string)
 				}
+				input.SourceMeta.Extra["deferred"] = "true"
+				reply(input, d.notice)
+			}
+			deferredMu.Lock()
+			deferred = append(deferred, d)
+			deferredMu.Unlock()
+			return true
+		}
+		if errors.Is(err, pipeline.ErrAwaitingApproval) && result != nil {
+			log.Printf("[daemon] parked %s input %s until approval %s is decided", input.SourceType, input.InputID, result.AwaitingApproval)
+			parked.park(result.AwaitingApproval, &received)
+			if input.SourceType != senses.SourceTimer {
+				reply(input, result.Result)
+			}
+			return true
+		}
+		if errors.Is(err, pipeline.ErrBudgetExhausted) {
+			log.Printf("[daemon] not running %s input %s: %s", input.SourceType, input.InputID, deps.Budget.BudgetStatus())
+			if input.SourceType == senses.SourceTimer {
+				return
+			}
+			if input.ResponseChannel == "ws" {
+				if m, err := genui.NewNoticeMessage("warn", result.Result); err == nil {
+					wsSrv.Broadcast(m)
+				}
+			} else {
+				reply(input, result.Result)
+			}
+			return
+		}
+		if err != nil {
+			log.Printf("[daemon] run error: %v", err)
+			authFailures.Record(err, time.Now())
+			return
+		}
+		if input.SourceType != senses.SourceTimer || input.SourceMeta.Channel == scheduler.Channel {
+			raw := input.Payload
+			if v, ok := input.SourceMeta.Extra["raw_payload"]; ok {
+				raw = v
+			}
+			recentTasks.Add(result.TaskID, raw, input.SourceMeta.Sender)
+		}
 
-				log.Printf("[daemon] completed task=%s quality=%.0f%% cost=$%.4f time=%dms automation=%v",
-					result.TaskID,
-					result.QualityScore*100,
-					result.CostUSD,
-					result.ElapsedMs,
-					result.AutomationTriggered,
-				)
+		if result.Cancelled {
+			log.Printf("[daemon] cancelled task=%s cost=$%.4f time=%dms", result.TaskID, result.CostUSD, result.ElapsedMs)
+			if input.ResponseChannel == "ws" {
+				if m, err := genui.NewNoticeMessage("info", "Task cancelled."); err == nil {
+					wsSrv.Broadcast(m)
+				}
+			} else {
+				reply(input, result.Result)
+			}
+			return
+		}
+		if result.Declined {
+			log.Printf("[daemon] provider declined task=%s model=%s cost=$%.4f", result.TaskID, result.Model, result.CostUSD)
+		} else {
+			log.Printf("[daemon] completed task=%s quality=%.0f%% cost=$%.4f time=%dms automation=%v",
+				result.TaskID,
+				result.QualityScore*100,
+				result.CostUSD,
+				result.ElapsedMs,
+				result.AutomationTriggered,
+			)
+		}
 
-				// Route response back to the originating channel.
-				if input.ResponseChannel != "" {
-					if input.SourceType == senses.SourceAPI && input.CorrelationID != "" {
-						// API sync request — use correlation-based routing.
-						api.Send(ctx, input.CorrelationID, result.Result)
-					} else if sense := registry.GetBySourceType(input.SourceType); sense != nil {
-						// Telegram, Slack, Discord, Email — send reply.
-						if err := sense.Send(ctx, input.ResponseChannel, result.Result); err != nil {
-							log.Printf("[daemon] reply via %s: %v", input.SourceType, err)
-						}
-					}
+		// Route response back to the originating channel.
+		replyWith(input, result.Result, result)
+		if speechOut != nil && !result.Declined && cfg.SpeechOutput.speaks(input) {
+			go func() {
+				if path, err := speechOut.Speak(ctx, *result); err != nil {
+					log.Printf("[speech] task %s: %v", result.TaskID, err)
+				} else if path != "" {
+					log.Printf("[speech] task %s read out to %s", result.TaskID, path)
 				}
+			}()
+		}
+		if input.SourceType != senses.SourceTimer && upgrades != nil && result.Success {
+			upgrades.Shadow(ctx, input.Payload)
+		}
+		if automations != nil && !result.Declined {
+			automations.OnResult(ctx, input, result.Fingerprint, result.Result)
+		}
 
-				// Generate UI and broadcast to connected WebSocket clients.
-				if wsSrv.ClientCount() > 0 {
-					var thought *genui.ThoughtLog
-					if len(result.StageLogs) > 0 {
-						stages := make([]genui.ThoughtStage, len(result.StageLogs))
-						for i, sl := range result.StageLogs {
-							stages[i] = genui.ThoughtStage{
-								Number:  sl.Number,
-								Name:    sl.Name,
-								Summary: sl.Summary,
-								DurMs:   sl.DurMs,
-							}
-						}
-						thought = genui.BuildThoughtLog(stages)
-						thought.TotalCost = result.CostUSD
+		// Generate UI and broadcast to connected WebSocket clients.
+		if wsSrv.ClientCount() > 0 {
+			var thought *genui.ThoughtLog
+			if len(result.StageLogs) > 0 {
+				stages := make([]genui.ThoughtStage, len(result.StageLogs))
+				for i, sl := range result.StageLogs {
+					stages[i] = genui.ThoughtStage{
+						Number:  sl.Number,
+						Name:    sl.Name,
+						Summary: sl.Summary,
+						DurMs:   sl.DurMs,
 					}
+				}
+				thought = genui.BuildThoughtLog(stages)
+				thought.TotalCost = result.CostUSD
+			}
 
-					hints := uiReflection.BuildHints(result.Fingerprint)
-					ui, uiErr := uiGen.GenerateWithThought(ctx, *result, webCaps, thought, hints)
-					if uiErr != nil {
-						log.Printf("[daemon] UI generation failed: %v", uiErr)
-					} else {
-						ui.Sandbox = true
-						uiAPIHandler.CacheUI(ui)
-						if bErr := wsSrv.BroadcastUI(ui); bErr != nil {
-							log.Printf("[daemon] UI broadcast error: %v", bErr)
-						}
-					}
+			// Generate for the device that asked, if it reported its caps.
+			caps := webCaps
+			if input.ResponseChannel == "ws" {
+				if c, ok := wsSrv.ClientCapabilities(input.CorrelationID); ok {
+					caps = c
+				}
+			}
+
+			hints := uiReflection.BuildHints(result.Fingerprint)
+			ui, uiErr := uiGen.GenerateWithThought(ctx, *result, caps, thought, hints)
+			if uiErr != nil {
+				log.Printf("[daemon] UI generation failed: %v", uiErr)
+			} else {
+				ui.Sandbox = true
+				ui.Meta.Downloads = artifacts.Downloads(result)
+				uiAPIHandler.RecordUI(ui, caps, string(input.SourceType), input.SourceMeta.Sender)
+				if bErr := wsSrv.PublishUI(ui, string(input.SourceType), input.SourceMeta.Sender); bErr != nil {
+					log.Printf("[daemon] UI broadcast error: %v", bErr)
 				}
 			}
 		}
-	}()
+		return false
+	}
+	sup.Go(ctx, "dispatcher", func(ctx context.Context) error {
+		dispatcher.Run(ctx, out, func(input *senses.UnifiedInput) {
+			defer sup.Recover("dispatcher")
+			if err := taskQueue.Start(input.InputID); err != nil {
+				log.Printf("[daemon] %v", err)
+			}
+			kept := handleInput(ctx, input)
+			switch {
+			case kept:
+				if err := taskQueue.Hold(input.InputID); err != nil {
+					log.Printf("[daemon] %v", err)
+				}
+			case ctx.Err() == nil:
+				if err := taskQueue.Complete(input.InputID); err != nil {
+					log.Printf("[daemon] %v", err)
+				}
+			}
+		})
+		return nil
+	}, func() (string, bool) {
+		n, stalled := dispatcher.Stalled()
+		return fmt.Sprintf("%d input(s) waiting, none taken or finished lately", n), stalled
+	})
 
-	// Wait for shutdown signal.
-	<-sigCh
+	// Crash recovery — replay the inputs the last run of the daemon
+	// accepted but did not finish.
+	replay, dropped, err := taskQueue.Pending()
+	if err != nil {
+		log.Printf("[daemon] %v", err)
+	}
+	for _, t := range dropped {
+		log.Printf("[daemon] dropped %s input %s: started %d times without finishing", t.Input.SourceType, t.Input.InputID, t.Attempts)
+		deps.AuditLog.Log(security.AuditInputBlocked, security.SeverityWarn, "daemon", t.Input.SourceMeta.Sender, "replay", string(t.Input.SourceType), false, map[string]string{
+			"input_id": t.Input.InputID,
+			"attempts": strconv.Itoa(t.Attempts),
+		})
+	}
+	if len(replay) > 0 {
+		log.Printf("[daemon] replaying %d unfinished input(s) from the last run", len(replay))
+		go func() {
+			for _, t := range replay {
+				select {
+				case out <- &t.Input:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// Wait for a shutdown signal, or POST /shutdown cancelling ctx.
+	select {
+	case <-sigCh:
+	case <-ctx.Done():
+	}
+	signal.Stop(sigCh)
 	log.Printf("[daemon] shutting down...")
 	cancel()
 
@@ -894,49 +2899,316 @@ func runDaemon() {
 	log.Printf("[daemon] shutdown complete")
 }
 
+// skillCommands lists registered skills as command palette entries.
+func skillCommands(reg *instruments.SkillRegistry) genui.CommandSource {
+	return func() []genui.Command {
+		var cmds []genui.Command
+		for _, sk := range reg.List() {
+			if sk.Meta.Status == instruments.SkillStatusDeprecated {
+				continue
+			}
+			input := sk.Meta.Description
+			if input == "" {
+				input = sk.Meta.Name
+			}
+			cmds = append(cmds, genui.Command{
+				ID:    "skill:" + sk.Meta.ID,
+				Group: genui.CommandGroupSkill,
+				Label: sk.Meta.Name,
+				Hint:  fmt.Sprintf("%s skill, %d runs", sk.Meta.Type, sk.Meta.TotalRuns),
+				Input: input,
+			})
+		}
+		return cmds
+	}
+}
+
+// registerAdminCommands adds the daemon's admin actions to the palette.
+func registerAdminCommands(cmds *genui.CommandRegistry, standby *senses.Standby, router *brain.ModelRouter, releaseHeld func()) {
+	cmds.AddAction("pause", "Pause daemon", "Enter standby: only critical inputs are processed", nil,
+		func(_ context.Context, _ map[string]string) (string, error) {
+			standby.SetPaused(true)
+			log.Printf("[daemon] paused by operator")
+			return "Daemon paused", nil
+		})
+	cmds.AddAction("resume", "Resume daemon", "Leave standby and process held inputs", nil,
+		func(_ context.Context, _ map[string]string) (string, error) {
+			standby.SetPaused(false)
+			n := standby.Pending()
+			go releaseHeld()
+			log.Printf("[daemon] resumed by operator (%d held)", n)
+			return fmt.Sprintf("Daemon resumed, %d held input(s) released", n), nil
+		})
+	cmds.AddAction("model.switch", "Switch model", "Use one model for all tasks: "+strings.Join(router.Models(), ", "), []string{"model"},
+		func(_ context.Context, args map[string]string) (string, error) {
+			model := strings.TrimSpace(args["model"])
+			known := router.Models()
+			if !slices.Contains(known, model) {
+				return "", fmt.Errorf("unknown model %q (available: %s)", model, strings.Join(known, ", "))
+			}
+			router.SetOverride(model)
+			log.Printf("[daemon] model override: %s", model)
+			return "Model switched to " + model, nil
+		})
+	cmds.AddAction("model.auto", "Automatic model selection", "Clear the model override and route by task complexity", nil,
+		func(_ context.Context, _ map[string]string) (string, error) {
+			router.SetOverride("")
+			log.Printf("[daemon] model override cleared")
+			return "Automatic model selection restored", nil
+		})
+}
+
 // deriveWSAddr increments the port from the API address by 1 for the WebSocket server.
+// For a unix socket API address it returns a sibling "-ws" socket.
 func deriveWSAddr(apiAddr string) string {
-	host, portStr, err := net.SplitHostPort(apiAddr)
-	if err != nil {
-		return "127.0.0.1:9091"
-	}
-	var port int
-	fmt.Sscanf(portStr, "%d", &port)
-	return net.JoinHostPort(host, fmt.Sprintf("%d", port+1))
+	return netaddr.Offset(apiAddr, 1, "ws", "127.0.0.1:9091")
 }
 
 // deriveKioskAddr increments the port from the API address by 2 for the kiosk HTTP server.
+// For a unix socket API address it returns a sibling "-kiosk" socket.
 func deriveKioskAddr(apiAddr string) string {
-	host, portStr, err := net.SplitHostPort(apiAddr)
-	if err != nil {
-		return "127.0.0.1:9092"
+	return netaddr.Offset(apiAddr, 2, "kiosk", "127.0.0.1:9092")
+}
+
+// kioskURL is the kiosk address for log output.
+func kioskURL(addr string) string {
+	if netaddr.IsUnix(addr) {
+		return addr
 	}
-	var port int
-	fmt.Sscanf(portStr, "%d", &port)
-	return net.JoinHostPort(host, fmt.Sprintf("%d", port+2))
+	return netaddr.URL(addr, "/")
 }
 
-// runStatus checks if the daemon is running by hitting the health endpoint.
-func runStatus() {
+// parseRemote extracts "--remote ADDR" (or "--remote=ADDR") from args and
+// returns the address and the remaining arguments.
+func parseRemote(args []string) (string, []string) {
+	var remote string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--remote" && i+1 < len(args):
+			remote = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--remote="):
+			remote = strings.TrimPrefix(args[i], "--remote=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return remote, rest
+}
+
+// daemonAddr returns the API address a client command should talk to:
+// --remote if given, otherwise the configured API address.
+func daemonAddr(args []string) (Config, string, []string) {
 	cfg := loadConfig()
-	addr := cfg.APIAddr
+	remote, rest := parseRemote(args)
+	if remote == "" {
+		remote = cfg.APIAddr
+	}
+	return cfg, remote, rest
+}
+
+// runStatus checks if the daemon is running by hitting the health endpoint.
+func runStatus(args []string) {
+	remote, _ := parseRemote(args)
+	cfg, addr, _ := daemonAddr(args)
 
-	client := &http.Client{Timeout: 3 * time.Second}
-	resp, err := client.Get(fmt.Sprintf("http://%s/health", addr))
+	// The lock only says something about a daemon on this machine.
+	var owner deploy.LockInfo
+	var locked bool
+	if remote == "" {
+		owner, locked = localDaemon(cfg)
+	}
+
+	client := netaddr.HTTPClient(addr, 3*time.Second)
+	resp, err := client.Get(netaddr.URL(addr, "/health"))
 	if err != nil {
-		fmt.Printf("daemon is NOT running at %s: %v\n", addr, err)
+		if locked {
+			fmt.Printf("daemon holds the lock (%s) but is NOT answering at %s: %v\n", formatLockOwner(owner), addr, err)
+		} else {
+			fmt.Printf("daemon is NOT running at %s: %v\n", addr, err)
+		}
 		os.Exit(1)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 200 {
+		var health struct {
+			Mode        string `json:"mode"`
+			ModeMessage string `json:"mode_message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&health)
 		fmt.Printf("daemon is running at %s\n", addr)
+		if locked {
+			fmt.Printf("owner: %s\n", formatLockOwner(owner))
+		}
+		if health.Mode != "" && health.Mode != string(senses.ModeNormal) {
+			fmt.Printf("mode: %s %s\n", health.Mode, health.ModeMessage)
+		}
 	} else {
 		fmt.Printf("daemon returned status %d\n", resp.StatusCode)
 		os.Exit(1)
 	}
 }
 
+// localDaemon returns the owner of the data directory's daemon lock, if a
+// live daemon holds it.
+func localDaemon(cfg Config) (deploy.LockInfo, bool) {
+	pf := deploy.NewPIDFile(cfg.DataDir)
+	if _, running := pf.IsRunning(); !running {
+		return deploy.LockInfo{}, false
+	}
+	owner, err := pf.Owner()
+	return owner, err == nil
+}
+
+// runningDaemonAddr returns the API address of a daemon running on this
+// data directory. A local CLI session would open the database the daemon
+// is writing, so `cli` talks to the daemon instead.
+func runningDaemonAddr() (string, bool) {
+	cfg := loadConfig()
+	owner, running := localDaemon(cfg)
+	if !running {
+		return "", false
+	}
+	fmt.Fprintf(os.Stderr, "daemon is running (%s); connecting to it at %s\n", formatLockOwner(owner), cfg.APIAddr)
+	return cfg.APIAddr, true
+}
+
+// formatLockOwner describes a lock owner as "pid 123, v0.2.0, up 3h0m0s".
+func formatLockOwner(o deploy.LockInfo) string {
+	parts := []string{fmt.Sprintf("pid %d", o.PID)}
+	if o.Version != "" {
+		parts = append(parts, "v"+o.Version)
+	}
+	if !o.StartedAt.IsZero() {
+		parts = append(parts, "up "+time.Since(o.StartedAt).Round(time.Second).String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runMode shows the daemon mode, or switches it:
+//
+//	overhuman mode
+//	overhuman mode maintenance "Backing up, back in 10 minutes"
+func runMode(args []string) {
+	cfg, addr, args := daemonAddr(args)
+	client := netaddr.HTTPClient(addr, 5*time.Second)
+	url := netaddr.URL(addr, "/mode")
+
+	var resp *http.Response
+	var err error
+	if len(args) == 0 {
+		resp, err = client.Get(url)
+	} else {
+		mode, perr := senses.ParseDaemonMode(args[0])
+		if perr != nil {
+			fmt.Fprintln(os.Stderr, perr)
+			os.Exit(1)
+		}
+		body, _ := json.Marshal(map[string]string{
+			"mode":    string(mode),
+			"message": strings.Join(args[1:], " "),
+		})
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if cfg.AdminToken != "" {
+			req.Header.Set("Authorization", "Bearer "+cfg.AdminToken)
+		}
+		resp, err = client.Do(req)
+	}
+	if err != nil {
+		fmt.Printf("daemon is NOT running at %s: %v\n", addr, err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+
+	var st senses.ModeStatus
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		fmt.Printf("mode switch failed (%d): %s\n", resp.StatusCode, strings.TrimSpace(string(data)))
+		os.Exit(1)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		fmt.Printf("bad response: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("mode: %s (since %s)\n", st.Mode, st.Since.Format(time.RFC3339))
+	if st.Message != "" {
+		fmt.Printf("message: %s\n", st.Message)
+	}
+}
+
+// skillCatalogPath is where the skill registry mirrors its metadata.
+func skillCatalogPath(cfg Config) string {
+	return filepath.Join(cfg.DataDir, "skills", "catalog.json")
+}
+
+// skillTemplatesDir holds the user's hand-written template skills.
+func skillTemplatesDir(cfg Config) string {
+	return filepath.Join(cfg.DataDir, "skills", "templates")
+}
+
+// checkSkillTemplates lists the template skills in dir the way the daemon
+// would load them and returns 1 if any file is broken.
+func checkSkillTemplates(w io.Writer, dir string) int {
+	templates, errs := skills.LoadTemplates(dir)
+	for _, t := range templates {
+		m := t.Skill().Meta
+		fmt.Fprintf(w, "%-24s %s", m.ID, m.Name)
+		if len(m.Triggers) > 0 {
+			fmt.Fprintf(w, "  (triggers: %s)", strings.Join(m.Triggers, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, err := range errs {
+		fmt.Fprintf(w, "error: %v\n", err)
+	}
+	if len(templates) == 0 && len(errs) == 0 {
+		fmt.Fprintf(w, "no templates in %s\n", dir)
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runSkill inspects and manages the running daemon's skills, or checks
+// the template skills:
+//
+//	overhuman skills [list|show ID|enable ID|disable ID|delete ID]
+//	overhuman skills templates
+//
+// With the daemon down, list and show read the catalog it last wrote.
+func runSkill(args []string) {
+	cfg, addr, args := daemonAddr(args)
+	if len(args) > 0 && args[0] == "templates" {
+		os.Exit(checkSkillTemplates(os.Stdout, skillTemplatesDir(cfg)))
+	}
+	kiosk := deriveKioskAddr(addr)
+	out, err := skillsCommand(netaddr.HTTPClient(kiosk, 10*time.Second), kiosk, cfg.AdminToken, args)
+	if errors.Is(err, errDaemonDown) && (len(args) == 0 || args[0] == "list" || ((args[0] == "show" || args[0] == "info") && len(args) > 1)) {
+		fmt.Fprintln(os.Stderr, "(daemon not running; from the last catalog)")
+		out, err = skillCatalogCommand(skillCatalogPath(cfg), args)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
+
+// runGoals lists and edits the running daemon's goals.
+func runGoals(args []string) {
+	cfg, addr, args := daemonAddr(args)
+	out, err := goalsCommand(netaddr.HTTPClient(addr, 10*time.Second), addr, cfg.AdminToken, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
+
 // runInstall installs overhuman as an OS service.
 func runInstall() {
 	cfg := loadConfig()
@@ -971,9 +3243,29 @@ func runUninstall() {
 	fmt.Println(result.Instructions)
 }
 
-// runStop sends SIGTERM to the running daemon.
-func runStop() {
+// runStop sends SIGTERM to the running daemon, or asks a remote daemon to
+// shut down via POST /shutdown when --remote is given.
+func runStop(args []string) {
 	cfg := loadConfig()
+	if remote, _ := parseRemote(args); remote != "" {
+		req, _ := http.NewRequest(http.MethodPost, netaddr.URL(remote, "/shutdown"), nil)
+		if cfg.AdminToken != "" {
+			req.Header.Set("Authorization", "Bearer "+cfg.AdminToken)
+		}
+		resp, err := netaddr.HTTPClient(remote, 5*time.Second).Do(req)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "stop: daemon is NOT running at %s: %v\n", remote, err)
+			os.Exit(1)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			data, _ := io.ReadAll(resp.Body)
+			fmt.Fprintf(os.Stderr, "stop: daemon returned %d: %s\n", resp.StatusCode, strings.TrimSpace(string(data)))
+			os.Exit(1)
+		}
+		fmt.Println("daemon stopping")
+		return
+	}
 	if err := deploy.StopDaemon(cfg.DataDir); err != nil {
 		fmt.Fprintf(os.Stderr, "stop: %v\n", err)
 		os.Exit(1)
@@ -1033,24 +3325,27 @@ func runUpdate() {
 	}
 }
 
-// setupLogTee configures log output to write to both stdout and a log file.
-// Returns the log file handle (caller should defer Close) or nil on error.
-func setupLogTee(dataDir string) *os.File {
+// setupLogTee configures log output to write to stdout, a log file and
+// buf. Returns the log file (caller should defer Close; housekeeping
+// rotates it) or nil on error.
+func setupLogTee(dataDir string, buf io.Writer) *deploy.LogFile {
 	logDir := filepath.Join(dataDir, "logs")
 	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		log.SetOutput(io.MultiWriter(os.Stdout, buf))
 		log.Printf("[daemon] cannot create log dir: %v (logging to stdout only)", err)
 		return nil
 	}
 
 	logPath := filepath.Join(logDir, "overhuman.log")
-	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	f, err := deploy.OpenLogFile(logPath)
 	if err != nil {
+		log.SetOutput(io.MultiWriter(os.Stdout, buf))
 		log.Printf("[daemon] cannot open log file: %v (logging to stdout only)", err)
 		return nil
 	}
 
-	// Tee: all log output goes to stdout AND the file.
-	mw := io.MultiWriter(os.Stdout, f)
+	// Tee: all log output goes to stdout, the file and buf.
+	mw := io.MultiWriter(os.Stdout, f, buf)
 	log.SetOutput(mw)
 	log.Printf("[daemon] logging to %s", logPath)
 	return f
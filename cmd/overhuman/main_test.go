@@ -1,10 +1,22 @@
 package main
 
 import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/overhuman/overhuman/internal/deploy"
 	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/netaddr"
 	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/senses"
 )
 
 func TestLoadConfig_Defaults(t *testing.T) {
@@ -68,6 +80,69 @@ func TestBootstrap_NoAPIKey(t *testing.T) {
 	}
 }
 
+func TestBootstrap_FakeProvider(t *testing.T) {
+	t.Setenv("FAKE_LLM_SCRIPT", "")
+	t.Setenv("FAKE_LLM_LATENCY_MS", "")
+	t.Setenv("FAKE_LLM_ERROR_RATE", "")
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "fake_llm.json"), []byte(`{"rules":[{"match":"demo","response":"scripted demo"}]}`), 0o644)
+	cfg := Config{
+		DataDir:     dir,
+		AgentName:   "TestAgent",
+		DefaultSpec: "general",
+		LLMProvider: "fake",
+	}
+
+	deps, _, _, err := bootstrap(cfg)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	defer deps.LongTerm.Close()
+
+	if deps.LLM.Name() != "fake" {
+		t.Errorf("LLM = %q, want fake", deps.LLM.Name())
+	}
+	if m := deps.Router.Select("moderate", 10); m != "fake-mid" {
+		t.Errorf("router selected %q, want fake-mid", m)
+	}
+
+	result, err := pipeline.New(deps).Run(context.Background(), *senses.NewFromText("run the demo"))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Result != "scripted demo" {
+		t.Errorf("Result = %q, want scripted response", result.Result)
+	}
+}
+
+func TestBootstrap_FakeProviderBadErrorRate(t *testing.T) {
+	t.Setenv("FAKE_LLM_ERROR_RATE", "2")
+	cfg := Config{DataDir: t.TempDir(), AgentName: "TestAgent", DefaultSpec: "general", LLMProvider: "fake"}
+	if _, _, _, err := bootstrap(cfg); err == nil {
+		t.Error("expected error for out-of-range FAKE_LLM_ERROR_RATE")
+	}
+}
+
+func TestBootstrap_StartupMode(t *testing.T) {
+	t.Setenv("FAKE_LLM_SCRIPT", "")
+	t.Setenv("FAKE_LLM_ERROR_RATE", "")
+	cfg := Config{DataDir: t.TempDir(), AgentName: "TestAgent", DefaultSpec: "general", LLMProvider: "fake", Mode: "read-only"}
+	deps, _, _, err := bootstrap(cfg)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	defer deps.LongTerm.Close()
+	if deps.Mode.Mode() != senses.ModeReadOnly {
+		t.Errorf("mode = %s, want read_only", deps.Mode.Mode())
+	}
+
+	cfg.DataDir, cfg.Mode = t.TempDir(), "sleeping"
+	if _, _, _, err := bootstrap(cfg); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
 func TestBootstrap_WithClaudeKey(t *testing.T) {
 	dir := t.TempDir()
 	cfg := Config{
@@ -189,6 +264,31 @@ func TestBootstrap_CreatesUIGenerator(t *testing.T) {
 	}
 }
 
+func TestCreateUIProvider(t *testing.T) {
+	cfg := Config{LLMProvider: "custom", LLMBaseURL: "http://main.example", LLMAPIKey: "main-key", UIModel: "qwen2.5:3b"}
+	if p, err := createUIProvider(cfg); p != nil || err != nil {
+		t.Errorf("model-only pin: %v, %v", p, err)
+	}
+	if got := uiPinLabel(cfg, "custom"); got != "custom/qwen2.5:3b" {
+		t.Errorf("label = %q", got)
+	}
+
+	cfg.UIProvider = "ollama"
+	p, err := createUIProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != "ollama" || strings.Join(p.Models(), ",") != "qwen2.5:3b" {
+		t.Errorf("UI provider = %s %v", p.Name(), p.Models())
+	}
+
+	// The main provider's key is not handed to another provider.
+	cfg.UIProvider = "groq"
+	if _, err := createUIProvider(cfg); err == nil {
+		t.Error("groq without a key accepted")
+	}
+}
+
 // TestUIGenerator_CLICapabilities verifies CLICapabilities returns correct defaults.
 func TestUIGenerator_CLICapabilities(t *testing.T) {
 	caps := genui.CLICapabilities()
@@ -262,3 +362,177 @@ func TestUIReflectionStore_BasicFlow(t *testing.T) {
 		t.Error("hints should contain non-empty strings")
 	}
 }
+
+func TestParseRemote(t *testing.T) {
+	remote, rest := parseRemote([]string{"maintenance", "--remote", "unix:/run/oh.sock", "back", "soon"})
+	if remote != "unix:/run/oh.sock" {
+		t.Errorf("remote = %q", remote)
+	}
+	if strings.Join(rest, " ") != "maintenance back soon" {
+		t.Errorf("rest = %v", rest)
+	}
+	if remote, _ := parseRemote([]string{"--remote=[::1]:9090"}); remote != "[::1]:9090" {
+		t.Errorf("remote = %q", remote)
+	}
+	if remote, _ := parseRemote(nil); remote != "" {
+		t.Errorf("remote = %q, want empty", remote)
+	}
+}
+
+func TestDeriveAddrs(t *testing.T) {
+	tests := []struct {
+		api, ws, kiosk string
+	}{
+		{"127.0.0.1:9090", "127.0.0.1:9091", "127.0.0.1:9092"},
+		{"[::1]:9090", "[::1]:9091", "[::1]:9092"},
+		{"unix:/run/overhuman/api.sock", "unix:/run/overhuman/api-ws.sock", "unix:/run/overhuman/api-kiosk.sock"},
+		{"not-an-address", "127.0.0.1:9091", "127.0.0.1:9092"},
+	}
+	for _, tt := range tests {
+		if got := deriveWSAddr(tt.api); got != tt.ws {
+			t.Errorf("deriveWSAddr(%q) = %q, want %q", tt.api, got, tt.ws)
+		}
+		if got := deriveKioskAddr(tt.api); got != tt.kiosk {
+			t.Errorf("deriveKioskAddr(%q) = %q, want %q", tt.api, got, tt.kiosk)
+		}
+	}
+}
+
+func TestDuplicateAck(t *testing.T) {
+	in := senses.NewUnifiedInput(senses.SourceFile, "x")
+	in.SourceMeta.Extra = map[string]string{
+		senses.ExtraDuplicateOf:     "in-1",
+		senses.ExtraDuplicateSeenAt: "2026-03-01T10:00:00Z",
+	}
+	got := duplicateAck(in, time.UTC)
+	for _, want := range []string{"this file", "Mar 1 10:00", "in-1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ack %q missing %q", got, want)
+		}
+	}
+}
+
+func TestDaemonCapabilities(t *testing.T) {
+	t.Setenv("FAKE_LLM_SCRIPT", "")
+	cfg := Config{
+		DataDir:     t.TempDir(),
+		AgentName:   "TestAgent",
+		DefaultSpec: "general",
+		LLMProvider: "fake",
+		APIAddr:     "127.0.0.1:9090",
+	}
+	deps, _, _, err := bootstrap(cfg)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	defer deps.LongTerm.Close()
+
+	registry := senses.NewSenseRegistry()
+	registry.Register(senses.NewAPISense(cfg.APIAddr))
+	c := daemonCapabilities(cfg, deps, registry)
+
+	if c.Version != version || c.Agent != "TestAgent" {
+		t.Errorf("version/agent = %q, %q", c.Version, c.Agent)
+	}
+	if len(c.Senses) != 1 || c.Senses[0] != "API" {
+		t.Errorf("senses = %v", c.Senses)
+	}
+	if len(c.Skills) == 0 {
+		t.Error("starter skills should be listed")
+	}
+	if c.Endpoints["ws"] != "127.0.0.1:9091" || c.Endpoints["kiosk"] != "127.0.0.1:9092" {
+		t.Errorf("endpoints = %v", c.Endpoints)
+	}
+	if c.Auth.Admin != "loopback" {
+		t.Errorf("admin auth = %q, want loopback", c.Auth.Admin)
+	}
+	cfg.AdminToken = "secret"
+	if got := daemonCapabilities(cfg, deps, registry).Auth.Admin; got != "bearer" {
+		t.Errorf("admin auth with token = %q, want bearer", got)
+	}
+}
+
+func TestBootstrap_Speech(t *testing.T) {
+	t.Setenv("FAKE_LLM_SCRIPT", "")
+	t.Setenv("OVERHUMAN_STT_PROVIDER", "fake")
+	t.Setenv("OVERHUMAN_TTS_PROVIDER", "elevenlabs")
+	t.Setenv("OVERHUMAN_TTS_VOICE", "v1")
+	t.Setenv("ELEVENLABS_API_KEY", "")
+	cfg := Config{DataDir: t.TempDir(), DefaultSpec: "general", LLMProvider: "fake"}
+	envSpeech("OVERHUMAN_STT", &cfg.STT)
+	envSpeech("OVERHUMAN_TTS", &cfg.TTS)
+	if cfg.TTS.Provider != "elevenlabs" || cfg.TTS.Voice != "v1" {
+		t.Fatalf("TTS config = %+v", cfg.TTS)
+	}
+
+	// ElevenLabs without a key is disabled; the daemon still starts.
+	deps, _, _, err := bootstrap(cfg)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	defer deps.LongTerm.Close()
+	if deps.STT == nil || deps.STT.Name() != "fake" {
+		t.Errorf("STT = %v, want fake", deps.STT)
+	}
+	if deps.TTS != nil {
+		t.Errorf("TTS = %v, want disabled without API key", deps.TTS)
+	}
+	if tools := daemonTools(deps); !slices.Contains(tools, "speech_to_text") || slices.Contains(tools, "text_to_speech") {
+		t.Errorf("tools = %v", tools)
+	}
+
+	t.Setenv("ELEVENLABS_API_KEY", "el-key")
+	if tts, err := createTextToSpeech(cfg); err != nil || tts.Name() != "elevenlabs" {
+		t.Errorf("createTextToSpeech = %v, %v", tts, err)
+	}
+}
+
+func TestRunDaemon_RemoteStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix socket")
+	}
+	dir := t.TempDir()
+	t.Setenv("OVERHUMAN_DATA", dir)
+	addr := netaddr.UnixPrefix + filepath.Join(dir, "api.sock")
+	t.Setenv("OVERHUMAN_API_ADDR", addr)
+	t.Setenv("OVERHUMAN_ADMIN_TOKEN", "")
+	t.Setenv("LLM_PROVIDER", "fake")
+	t.Setenv("FAKE_LLM_SCRIPT", "")
+	t.Setenv("FAKE_LLM_ERROR_RATE", "")
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runDaemon()
+	}()
+
+	client := netaddr.HTTPClient(addr, time.Second)
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := client.Post(netaddr.URL(addr, "/shutdown"), "application/json", nil)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusAccepted {
+				t.Fatalf("POST /shutdown = %s", resp.Status)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon did not start: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("runDaemon did not return after a remote stop")
+	}
+	// The PID lock is released, so another daemon may start.
+	pf := deploy.NewPIDFile(dir)
+	if err := pf.Lock(deploy.LockInfo{Version: version}); err != nil {
+		t.Errorf("lock after stop: %v", err)
+	}
+	pf.Unlock()
+}
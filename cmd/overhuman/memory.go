@@ -0,0 +1,490 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// createEmbedder returns the embedding client for cfg: the dedicated
+// embeddings endpoint if configured, otherwise the LLM provider's own
+// embeddings API.
+func createEmbedder(cfg Config) (brain.Embedder, error) {
+	if cfg.EmbeddingBaseURL != "" {
+		if cfg.EmbeddingModel == "" {
+			return nil, fmt.Errorf("embeddings: set OVERHUMAN_EMBEDDING_MODEL for %s", cfg.EmbeddingBaseURL)
+		}
+		p := brain.NewUniversalProvider(brain.CustomConfig("embeddings", cfg.EmbeddingBaseURL, cfg.EmbeddingAPIKey, cfg.EmbeddingModel))
+		return p.Embedder(cfg.EmbeddingModel), nil
+	}
+	llm, providerName, err := createLLMProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	model := cfg.EmbeddingModel
+	switch p := llm.(type) {
+	case *brain.FakeProvider:
+		return p, nil
+	case *brain.UniversalProvider:
+		if m := brain.DefaultEmbeddingModel(providerName); model != "" || m != "" {
+			if model == "" {
+				model = m
+			}
+			return p.Embedder(model), nil
+		}
+	}
+	// Claude and others have no embeddings API; use OpenAI when a key is
+	// available.
+	if cfg.OpenAIKey != "" {
+		if model == "" {
+			model = brain.DefaultEmbeddingModel("openai")
+		}
+		return brain.NewUniversalProvider(brain.OpenAIConfig(cfg.OpenAIKey)).Embedder(model), nil
+	}
+	return nil, fmt.Errorf("embeddings: %s has no embeddings API; set OVERHUMAN_EMBEDDING_URL (and OVERHUMAN_EMBEDDING_MODEL)", providerName)
+}
+
+// createSemanticMemory returns recall by meaning over ltm, or nil when
+// there is no embedding model for cfg. Providers without an embeddings API
+// (Claude) get it only with an embedding model or endpoint configured, so
+// memories are not sent to a second provider unasked.
+func createSemanticMemory(cfg Config, providerName string, ltm *memory.LongTermMemory) *memory.SemanticMemory {
+	if cfg.EmbeddingModel == "" && cfg.EmbeddingBaseURL == "" && providerName != "fake" && brain.DefaultEmbeddingModel(providerName) == "" {
+		return nil
+	}
+	emb, err := createEmbedder(cfg)
+	if err != nil {
+		log.Printf("[bootstrap] semantic memory disabled: %v", err)
+		return nil
+	}
+	vs, err := memory.NewVectorStore(ltm.DB())
+	if err != nil {
+		log.Printf("[bootstrap] semantic memory disabled: %v", err)
+		return nil
+	}
+	log.Printf("[bootstrap] semantic memory: %s", emb.EmbeddingModel())
+	return memory.NewSemanticMemory(emb, vs, ltm)
+}
+
+// runMemory handles `overhuman memory reindex [--force] [--concurrency N]
+// [--batch N]`, `overhuman memory maintain [--vacuum]`, `overhuman
+// memory search QUERY [--limit N]`, `overhuman memory topics` and
+// `overhuman memory forget ID`.
+func runMemory(args []string) {
+	if len(args) > 0 && args[0] == "maintain" {
+		runMemoryMaintain(args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "search" {
+		runMemorySearch(args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "topics" {
+		runMemoryTopics()
+		return
+	}
+	if len(args) > 0 && args[0] == "forget" {
+		runMemoryForget(args[1:])
+		return
+	}
+	if len(args) == 0 || args[0] != "reindex" {
+		fmt.Fprintf(os.Stderr, "usage: %s memory reindex [--force] [--concurrency N] [--batch N] | memory maintain [--vacuum] | memory search QUERY [--limit N] [--topic NAME] | memory topics | memory forget ID\n", appName)
+		os.Exit(1)
+	}
+	opts, err := parseReindexArgs(args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memory reindex: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := loadConfig()
+	emb, err := createEmbedder(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	ltm, err := memory.NewLongTermMemory(filepath.Join(cfg.DataDir, "overhuman.db"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open memory: %v\n", err)
+		os.Exit(1)
+	}
+	defer ltm.Close()
+	skb, err := memory.NewSharedKnowledgeBase(ltm.DB())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open shared knowledge: %v\n", err)
+		os.Exit(1)
+	}
+	vs, err := memory.NewVectorStore(ltm.DB())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Reindexing memory with %s (concurrency %d, batch %d)\n", emb.EmbeddingModel(), opts.Concurrency, opts.BatchSize)
+	opts.Progress = func(p memory.ReindexProgress) {
+		pct := 100
+		if p.Total > 0 {
+			pct = p.Done * 100 / p.Total
+		}
+		fmt.Printf("\r  %-4s %d/%d (%d%%)", p.Kind, p.Done, p.Total, pct)
+		if p.Done == p.Total {
+			fmt.Println()
+		}
+	}
+	res, err := memory.Reindex(ctx, emb, vs, ltm, skb, opts)
+	if ctx.Err() != nil {
+		fmt.Printf("\nInterrupted. Run '%s memory reindex' again to resume.\n", appName)
+		os.Exit(1)
+	}
+	fmt.Print(formatReindexResult(res))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\nRun '%s memory reindex' again to retry the failed rows.\n", err, appName)
+		os.Exit(1)
+	}
+	if !res.Verified() {
+		fmt.Fprintln(os.Stderr, "Verification failed: vector count does not match row count.")
+		os.Exit(1)
+	}
+}
+
+// parseReindexArgs parses the flags of `memory reindex`.
+func parseReindexArgs(args []string) (memory.ReindexOptions, error) {
+	opts := memory.ReindexOptions{
+		Concurrency: memory.DefaultReindexConcurrency,
+		BatchSize:   memory.DefaultReindexBatchSize,
+	}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--force":
+			opts.Force = true
+			continue
+		case "--concurrency", "--batch":
+		default:
+			return opts, fmt.Errorf("unknown flag %q", args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("%s: want a positive number, got %q", name, value)
+		}
+		if name == "--concurrency" {
+			opts.Concurrency = n
+		} else {
+			opts.BatchSize = n
+		}
+	}
+	return opts, nil
+}
+
+// formatReindexResult renders the per-table summary of a reindex.
+func formatReindexResult(res memory.ReindexResult) string {
+	var b strings.Builder
+	for _, k := range res.Kinds {
+		status := "✓"
+		if !k.Verified {
+			status = "✗"
+		}
+		fmt.Fprintf(&b, "  %s %-4s rows=%d embedded=%d skipped=%d failed=%d pruned=%d vectors=%d\n",
+			status, k.Kind, k.Rows, k.Embedded, k.Skipped, k.Failed, k.Pruned, k.Vectors)
+	}
+	return b.String()
+}
+
+// dbMaintenanceInterval is how often the daemon optimizes the database.
+const dbMaintenanceInterval = 24 * time.Hour
+
+// runMemoryMaintain handles `overhuman memory maintain [--vacuum]`.
+func runMemoryMaintain(args []string) {
+	force := false
+	for _, a := range args {
+		if a != "--vacuum" {
+			fmt.Fprintf(os.Stderr, "usage: %s memory maintain [--vacuum]\n", appName)
+			os.Exit(1)
+		}
+		force = true
+	}
+	cfg := loadConfig()
+	if owner, running := localDaemon(cfg); running {
+		fmt.Fprintf(os.Stderr, "memory maintain: the daemon is running (%s) and optimizes the database every %s; stop it first to maintain now\n",
+			formatLockOwner(owner), dbMaintenanceInterval)
+		os.Exit(1)
+	}
+	ltm, err := memory.NewLongTermMemory(filepath.Join(cfg.DataDir, "overhuman.db"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open memory: %v\n", err)
+		os.Exit(1)
+	}
+	defer ltm.Close()
+	res, err := ltm.Maintain(context.Background(), force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Optimized. Vacuumed: %v. Reclaimed: %d KB.\n", res.Vacuumed, res.ReclaimedBytes/1024)
+}
+
+// runMemorySearch handles `overhuman memory search QUERY [--limit N]
+// [--as SENDER] [--channel NAME] [--topic NAME]`. It opens the database
+// read-only, so it works while the daemon runs. --as and --channel show
+// only what that sender on that channel would be allowed to see; --topic
+// searches one topic, listing its newest entries when QUERY is empty.
+func runMemorySearch(args []string) {
+	opts, err := parseMemorySearchArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memory search: %v\nusage: %s memory search QUERY [--limit N] [--as SENDER] [--channel NAME] [--topic NAME]\n", err, appName)
+		os.Exit(1)
+	}
+	cfg := loadConfig()
+	ltm, err := memory.OpenLongTermMemoryReadOnly(filepath.Join(cfg.DataDir, "overhuman.db"))
+	if os.IsNotExist(err) {
+		fmt.Println("No memories yet.")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open memory: %v\n", err)
+		os.Exit(1)
+	}
+	defer ltm.Close()
+	var entries []memory.LongTermEntry
+	switch {
+	case opts.topic != "" && opts.scoped():
+		entries, err = ltm.Recall(opts.viewer, opts.topic, opts.query, opts.limit)
+	case opts.topic != "":
+		entries, err = ltm.SearchTopic(opts.topic, opts.query, opts.limit)
+	case opts.scoped():
+		entries, err = ltm.SearchAs(opts.viewer, opts.query, opts.limit)
+	default:
+		entries, err = ltm.Search(opts.query, opts.limit)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memory search: %v\n", err)
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No matching memories.")
+		return
+	}
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %s", e.CreatedAt.Local().Format("2006-01-02"), e.Summary)
+		if e.Topic != "" && opts.topic == "" {
+			line += "  {" + e.Topic + "}"
+		}
+		if len(e.Tags) > 0 && e.Tags[0] != "" {
+			line += "  [" + strings.Join(e.Tags, ", ") + "]"
+		}
+		if e.Visibility != memory.VisibilityShared {
+			line += "  (" + describeVisibility(e) + ")"
+		}
+		fmt.Println(line)
+	}
+}
+
+// runMemoryTopics handles `overhuman memory topics`, listing the memory
+// topics and how many entries each holds.
+func runMemoryTopics() {
+	cfg := loadConfig()
+	ltm, err := memory.OpenLongTermMemoryReadOnly(filepath.Join(cfg.DataDir, "overhuman.db"))
+	if os.IsNotExist(err) {
+		fmt.Println("No memories yet.")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open memory: %v\n", err)
+		os.Exit(1)
+	}
+	defer ltm.Close()
+	topics, err := ltm.Topics()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memory topics: %v\n", err)
+		os.Exit(1)
+	}
+	if len(topics) == 0 {
+		fmt.Println(`No topics yet. Start one by telling the agent "switch to topic NAME".`)
+		return
+	}
+	for _, t := range topics {
+		fmt.Printf("%-40s %d\n", t.Topic, t.Entries)
+	}
+}
+
+// runMemoryForget handles `overhuman memory forget ID...`: it deletes
+// memories a run cited as sources (the "based on" section) when they are
+// wrong, so they are no longer recalled.
+func runMemoryForget(ids []string) {
+	if len(ids) == 0 {
+		fmt.Fprintf(os.Stderr, "usage: %s memory forget ID...\n", appName)
+		os.Exit(1)
+	}
+	cfg := loadConfig()
+	ltm, err := memory.NewLongTermMemory(filepath.Join(cfg.DataDir, "overhuman.db"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open memory: %v\n", err)
+		os.Exit(1)
+	}
+	defer ltm.Close()
+	failed := false
+	for _, id := range ids {
+		ok, err := ltm.Forget(id)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", id, err)
+			failed = true
+		case !ok:
+			fmt.Fprintf(os.Stderr, "%s: no such memory\n", id)
+			failed = true
+		default:
+			fmt.Printf("Forgot %s\n", id)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// describeVisibility says who may see a non-shared entry.
+func describeVisibility(e memory.LongTermEntry) string {
+	switch {
+	case e.Visibility == memory.VisibilityPrivate && e.Owner != "":
+		return fmt.Sprintf("private to %s on %s", e.Owner, strings.ToLower(e.Channel))
+	case e.Channel != "":
+		return fmt.Sprintf("%s only", strings.ToLower(e.Channel))
+	}
+	return e.Visibility.String()
+}
+
+// memorySearchOpts are the parsed `memory search` arguments.
+type memorySearchOpts struct {
+	query  string
+	limit  int
+	viewer memory.Viewer // --as / --channel
+	topic  string        // --topic, normalized
+}
+
+// scoped reports whether results are filtered by visibility.
+func (o memorySearchOpts) scoped() bool {
+	return o.viewer != memory.Viewer{}
+}
+
+// parseMemorySearchArgs joins the non-flag arguments into the FTS query.
+// The query may be empty when --topic is given.
+func parseMemorySearchArgs(args []string) (memorySearchOpts, error) {
+	opts := memorySearchOpts{limit: 10}
+	var words []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--limit", "--as", "--channel", "--topic":
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return memorySearchOpts{}, fmt.Errorf("unknown flag %q", args[i])
+			}
+			words = append(words, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return memorySearchOpts{}, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return memorySearchOpts{}, fmt.Errorf("--limit: want a positive number, got %q", value)
+			}
+			opts.limit = n
+		case "--as":
+			opts.viewer.Sender = value
+		case "--channel":
+			st := senses.ParseSourceType(value)
+			if st == "" {
+				return memorySearchOpts{}, fmt.Errorf("--channel: unknown channel %q", value)
+			}
+			opts.viewer.Channel = string(st)
+		case "--topic":
+			opts.topic = memory.NormalizeTopic(value)
+			if opts.topic == "" {
+				return memorySearchOpts{}, fmt.Errorf("--topic: empty topic name %q", value)
+			}
+		}
+	}
+	if len(words) == 0 && opts.topic == "" {
+		return memorySearchOpts{}, fmt.Errorf("missing query")
+	}
+	opts.query = strings.Join(words, " ")
+	return opts, nil
+}
+
+// doctorCheckDatabase prints the database line of `overhuman doctor` and
+// reports whether it needs attention.
+func doctorCheckDatabase(dataDir string) bool {
+	dbPath := filepath.Join(dataDir, "overhuman.db")
+	if _, err := os.Stat(dbPath); err != nil {
+		fmt.Printf("  … Database: not created yet (will be created on first run)\n")
+		return false
+	}
+	ltm, err := memory.NewLongTermMemory(dbPath)
+	if err != nil {
+		fmt.Printf("  ✗ Database: %s (%v)\n", dbPath, err)
+		return true
+	}
+	defer ltm.Close()
+	h, err := ltm.Health()
+	if err != nil {
+		fmt.Printf("  ✗ Database: %s (%v)\n", dbPath, err)
+		return true
+	}
+	fmt.Print(formatDBHealth(h))
+	return h.JournalMode != "wal" || h.FreeRatio() >= memory.VacuumFreeRatio
+}
+
+// formatDBHealth renders the doctor lines for h.
+func formatDBHealth(h memory.DBHealth) string {
+	mark := "✓"
+	var hints []string
+	if h.JournalMode != "wal" {
+		mark = "⚠"
+		hints = append(hints, fmt.Sprintf("journal mode %s, expected wal", h.JournalMode))
+	}
+	if h.FreeRatio() >= memory.VacuumFreeRatio {
+		mark = "⚠"
+		hints = append(hints, fmt.Sprintf("%.0f%% free pages — run: %s memory maintain --vacuum", h.FreeRatio()*100, appName))
+	}
+	never := func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return t.Local().Format("2006-01-02 15:04")
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %s Database: %s (%d KB, WAL %d KB)\n", mark, h.Path, h.SizeBytes/1024, h.WALBytes/1024)
+	fmt.Fprintf(&b, "      pages: %d × %d B, %d free (%.0f%%); last optimize: %s; last vacuum: %s\n",
+		h.PageCount, h.PageSize, h.FreePages, h.FreeRatio()*100, never(h.LastOptimize), never(h.LastVacuum))
+	for _, hint := range hints {
+		fmt.Fprintf(&b, "      %s\n", hint)
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+func TestParseReindexArgs(t *testing.T) {
+	opts, err := parseReindexArgs([]string{"--force", "--concurrency", "8", "--batch=16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.Force || opts.Concurrency != 8 || opts.BatchSize != 16 {
+		t.Errorf("opts = %+v", opts)
+	}
+	opts, _ = parseReindexArgs(nil)
+	if opts.Force || opts.Concurrency != memory.DefaultReindexConcurrency || opts.BatchSize != memory.DefaultReindexBatchSize {
+		t.Errorf("defaults = %+v", opts)
+	}
+	for _, bad := range [][]string{{"--batch"}, {"--concurrency", "0"}, {"--fast"}} {
+		if _, err := parseReindexArgs(bad); err == nil {
+			t.Errorf("%v: expected error", bad)
+		}
+	}
+}
+
+func TestParseMemorySearchArgs(t *testing.T) {
+	opts, err := parseMemorySearchArgs([]string{"tax", "deadline", "--limit=3"})
+	if err != nil || opts.query != "tax deadline" || opts.limit != 3 || opts.scoped() {
+		t.Errorf("got %+v, %v", opts, err)
+	}
+	if opts, _ := parseMemorySearchArgs([]string{"tax"}); opts.limit != 10 {
+		t.Errorf("default limit = %d", opts.limit)
+	}
+	opts, err = parseMemorySearchArgs([]string{"--as", "alice", "tax", "--channel=Telegram"})
+	if err != nil || !opts.scoped() || opts.viewer != (memory.Viewer{Sender: "alice", Channel: "TELEGRAM"}) {
+		t.Errorf("scoped: got %+v, %v", opts, err)
+	}
+	opts, err = parseMemorySearchArgs([]string{"--topic", "Home Renovation"})
+	if err != nil || opts.topic != "home-renovation" || opts.query != "" {
+		t.Errorf("topic without query: got %+v, %v", opts, err)
+	}
+	for _, bad := range [][]string{nil, {"--limit", "5"}, {"tax", "--limit", "0"}, {"tax", "--all"}, {"tax", "--channel", "fax"}, {"tax", "--as"}, {"tax", "--topic", "!!"}} {
+		if _, err := parseMemorySearchArgs(bad); err == nil {
+			t.Errorf("%v: expected error", bad)
+		}
+	}
+}
+
+func TestCreateEmbedder(t *testing.T) {
+	emb, err := createEmbedder(Config{LLMProvider: "ollama"})
+	if err != nil || emb.EmbeddingModel() != "nomic-embed-text" {
+		t.Errorf("ollama embedder = %v, %v", emb, err)
+	}
+	emb, err = createEmbedder(Config{LLMProvider: "openai", OpenAIKey: "k", EmbeddingModel: "text-embedding-3-large"})
+	if err != nil || emb.EmbeddingModel() != "text-embedding-3-large" {
+		t.Errorf("configured model = %v, %v", emb, err)
+	}
+	if _, err := createEmbedder(Config{ClaudeKey: "k"}); err == nil || !strings.Contains(err.Error(), "OVERHUMAN_EMBEDDING_URL") {
+		t.Errorf("claude without OpenAI key: err = %v", err)
+	}
+	if _, err := createEmbedder(Config{EmbeddingBaseURL: "http://localhost:8000"}); err == nil {
+		t.Error("custom endpoint without model should fail")
+	}
+}
+
+func TestFormatDBHealth(t *testing.T) {
+	ok := formatDBHealth(memory.DBHealth{Path: "/d/overhuman.db", SizeBytes: 4096, JournalMode: "wal", PageSize: 4096, PageCount: 100, FreePages: 5})
+	if !strings.HasPrefix(ok, "  ✓ Database") || !strings.Contains(ok, "5 free (5%)") || !strings.Contains(ok, "last vacuum: never") {
+		t.Errorf("healthy:\n%s", ok)
+	}
+	bad := formatDBHealth(memory.DBHealth{JournalMode: "delete", PageCount: 100, FreePages: 40})
+	if !strings.HasPrefix(bad, "  ⚠ Database") || !strings.Contains(bad, "expected wal") || !strings.Contains(bad, "memory maintain --vacuum") {
+		t.Errorf("unhealthy:\n%s", bad)
+	}
+}
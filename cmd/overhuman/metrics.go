@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/observability"
+)
+
+// newDaemonMetrics returns the collector behind GET /metrics, with the
+// help text of the metrics the daemon exports.
+func newDaemonMetrics() *observability.MetricsCollector {
+	m := observability.NewMetricsCollector(0)
+	for name, help := range map[string]string{
+		"pipeline_stage_duration_seconds": "Time spent in each pipeline stage, by stage and outcome.",
+		"llm_requests_total":              "LLM completions answered, by provider and model.",
+		"llm_input_tokens_total":          "Prompt tokens sent, by provider and model.",
+		"llm_output_tokens_total":         "Completion tokens received, by provider and model.",
+		"llm_cost_usd_total":              "Estimated LLM spend in USD, by provider and model.",
+		"ws_clients":                      "WebSocket UI clients connected.",
+	} {
+		m.Describe(name, help)
+	}
+	return m
+}
+
+// meterLLM wraps a provider so its token usage lands in m.
+func meterLLM(llm brain.LLMProvider, m *observability.MetricsCollector) brain.LLMProvider {
+	return brain.NewMeteredProvider(llm, func(provider string, resp *brain.LLMResponse) {
+		labels := observability.Labels{"provider": provider, "model": resp.Model}
+		m.AddCounter("llm_requests_total", labels, 1)
+		m.AddCounter("llm_input_tokens_total", labels, float64(resp.InputTokens))
+		m.AddCounter("llm_output_tokens_total", labels, float64(resp.OutputTokens))
+		m.AddCounter("llm_cost_usd_total", labels, resp.CostUSD)
+	})
+}
+
+// metricsRoutes serves GET /metrics in the Prometheus text format.
+func metricsRoutes(m *observability.MetricsCollector) func(mux *http.ServeMux) {
+	return func(mux *http.ServeMux) {
+		mux.Handle("GET /metrics", m.Handler())
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+func TestMetricsRoutes_TokenUsage(t *testing.T) {
+	m := newDaemonMetrics()
+	fake := brain.NewFakeProvider(brain.FakeConfig{Default: "Done."})
+	llm := meterLLM(fake, m)
+	if _, err := llm.Complete(context.Background(), brain.LLMRequest{Messages: []brain.Message{{Role: "user", Content: "hello there"}}, Model: "fake-cheap"}); err != nil {
+		t.Fatal(err)
+	}
+	m.SetGauge("ws_clients", func() float64 { return 2 })
+
+	mux := http.NewServeMux()
+	metricsRoutes(m)(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	labels := `{model="fake-cheap",provider="` + fake.Name() + `"}`
+	for _, want := range []string{
+		"# HELP overhuman_llm_input_tokens_total Prompt tokens sent, by provider and model.",
+		"overhuman_llm_requests_total" + labels + " 1",
+		"overhuman_llm_input_tokens_total" + labels + " ",
+		"overhuman_llm_output_tokens_total" + labels + " ",
+		"# HELP overhuman_ws_clients WebSocket UI clients connected.",
+		"overhuman_ws_clients 2",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
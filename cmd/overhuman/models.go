@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// modelCheckInterval is how often the daemon re-checks the provider's
+// model list for retired models.
+const modelCheckInterval = 24 * time.Hour
+
+// modelRefs lists the models the agent may send requests to: the configured
+// default, an operator pin and every router tier.
+func modelRefs(cfg Config, router *brain.ModelRouter) []brain.ModelRef {
+	var refs []brain.ModelRef
+	if cfg.LLMModel != "" {
+		refs = append(refs, brain.ModelRef{Model: cfg.LLMModel, Source: "config"})
+	}
+	if m := router.Override(); m != "" {
+		refs = append(refs, brain.ModelRef{Model: m, Source: "pinned"})
+	}
+	for _, m := range router.Models() {
+		refs = append(refs, brain.ModelRef{Model: m, Source: "router"})
+	}
+	// "default" lets a custom server pick its own model; it is never listed.
+	kept := refs[:0]
+	for _, ref := range refs {
+		if ref.Model != "default" {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
+
+// checkModelDeprecations reports configured models the provider no longer
+// lists. Providers that cannot list their models report nothing.
+func checkModelDeprecations(ctx context.Context, llm brain.LLMProvider, cfg Config, router *brain.ModelRouter) ([]brain.ModelDeprecation, error) {
+	lister, ok := llm.(brain.ModelLister)
+	if !ok {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	available, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return brain.CheckModels(modelRefs(cfg, router), available, router), nil
+}
+
+// migrateModel switches routing from d.Model to its replacement and, when
+// the model comes from config.json, rewrites the config after saving the
+// previous version to config-history/. It returns a description of what
+// changed.
+func migrateModel(d brain.ModelDeprecation, router *brain.ModelRouter) (string, error) {
+	if d.Replacement == "" {
+		return "", fmt.Errorf("no replacement for %s", d.Model)
+	}
+	router.ReplaceModel(d.Model, d.Replacement)
+	if d.Source != "config" {
+		return fmt.Sprintf("routing now uses %s instead of %s", d.Replacement, d.Model), nil
+	}
+	persisted, err := loadPersistedConfig()
+	if err != nil {
+		return "", err
+	}
+	if persisted == nil || persisted.Model != d.Model {
+		return fmt.Sprintf("routing now uses %s; %s is set via LLM_MODEL, update it there", d.Replacement, d.Model), nil
+	}
+	backup, err := backupPersistedConfig(fmt.Sprintf("model %s retired, migrated to %s", d.Model, d.Replacement))
+	if err != nil {
+		return "", err
+	}
+	persisted.Model = d.Replacement
+	if err := savePersistedConfig(persisted); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("config model changed from %s to %s (previous config: %s)", d.Model, d.Replacement, backup), nil
+}
+
+// watchModelDeprecations checks the configured models at startup and then
+// every modelCheckInterval. Each retired model is passed to notify; with
+// cfg.AutoMigrateModels it is also migrated to its replacement.
+func watchModelDeprecations(ctx context.Context, llm brain.LLMProvider, cfg Config, router *brain.ModelRouter, notify func(string)) {
+	warned := make(map[string]bool)
+	check := func() {
+		deps, err := checkModelDeprecations(ctx, llm, cfg, router)
+		if err != nil {
+			log.Printf("[models] deprecation check failed: %v", err)
+			return
+		}
+		for _, d := range deps {
+			if cfg.AutoMigrateModels && d.Replacement != "" {
+				what, err := migrateModel(d, router)
+				if err != nil {
+					log.Printf("[models] migrate %s: %v", d.Model, err)
+				} else {
+					notify(d.String() + " — " + what + ". Undo with: overhuman models rollback")
+					continue
+				}
+			}
+			if !warned[d.Model] {
+				warned[d.Model] = true
+				notify(d.String() + ". Run: overhuman models migrate")
+			}
+		}
+	}
+	check()
+	ticker := time.NewTicker(modelCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// --- Config history ---
+
+// configVersion is one entry of config-history/index.jsonl.
+type configVersion struct {
+	File      string    `json:"file"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// configHistoryDir holds previous versions of config.json.
+func configHistoryDir() string {
+	return filepath.Join(filepath.Dir(configFilePath()), "config-history")
+}
+
+// backupPersistedConfig copies config.json into config-history/ and
+// records why. It returns the backup path.
+func backupPersistedConfig(reason string) (string, error) {
+	data, err := os.ReadFile(configFilePath())
+	if err != nil {
+		return "", fmt.Errorf("backup config: %w", err)
+	}
+	dir := configHistoryDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("backup config: %w", err)
+	}
+	now := time.Now().UTC()
+	name := "config-" + now.Format("20060102T150405.000000000Z") + ".json"
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("backup config: %w", err)
+	}
+	line, _ := json.Marshal(configVersion{File: name, Reason: reason, CreatedAt: now})
+	f, err := os.OpenFile(filepath.Join(dir, "index.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return path, fmt.Errorf("backup config: index: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return path, err
+}
+
+// configHistory returns the recorded config versions, oldest first. Backups
+// whose file has been removed (rolled back) are skipped.
+func configHistory() ([]configVersion, error) {
+	dir := configHistoryDir()
+	f, err := os.Open(filepath.Join(dir, "index.jsonl"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var versions []configVersion
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var v configVersion
+		if json.Unmarshal(sc.Bytes(), &v) != nil || v.File == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, v.File)); err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].File < versions[j].File })
+	return versions, sc.Err()
+}
+
+// rollbackPersistedConfig restores the newest backup over config.json and
+// removes it from the history, so repeated rollbacks step further back.
+func rollbackPersistedConfig() (configVersion, error) {
+	versions, err := configHistory()
+	if err != nil {
+		return configVersion{}, err
+	}
+	if len(versions) == 0 {
+		return configVersion{}, fmt.Errorf("no previous config version")
+	}
+	last := versions[len(versions)-1]
+	path := filepath.Join(configHistoryDir(), last.File)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return configVersion{}, err
+	}
+	if err := os.WriteFile(configFilePath(), data, 0o600); err != nil {
+		return configVersion{}, fmt.Errorf("restore config: %w", err)
+	}
+	return last, os.Remove(path)
+}
+
+// runModels handles `overhuman models [check|migrate|rollback|history|upgrade]`.
+func runModels(args []string) {
+	sub := "check"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	switch sub {
+	case "rollback":
+		v, err := rollbackPersistedConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rollback: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Restored config from %s (%s: %s).\nRestart the daemon to apply.\n", v.File, v.CreatedAt.Format("2006-01-02 15:04"), v.Reason)
+		return
+	case "history":
+		versions, err := configHistory()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "history: %v\n", err)
+			os.Exit(1)
+		}
+		if len(versions) == 0 {
+			fmt.Println("No previous config versions.")
+		}
+		for _, v := range versions {
+			fmt.Printf("%s  %s  %s\n", v.CreatedAt.Local().Format("2006-01-02 15:04"), v.File, v.Reason)
+		}
+		return
+	case "upgrade":
+		runModelUpgrade(args[1:])
+		return
+	case "check", "migrate":
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s models [check|migrate|rollback|history|upgrade]\n", appName)
+		os.Exit(1)
+	}
+
+	cfg := loadConfig()
+	llm, providerName, err := createLLMProvider(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if _, ok := llm.(brain.ModelLister); !ok {
+		fmt.Printf("Provider %s cannot list its models; nothing to check.\n", providerName)
+		return
+	}
+	router := newModelRouter(llm, providerName)
+	deps, err := checkModelDeprecations(context.Background(), llm, cfg, router)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "model check: %v\n", err)
+		os.Exit(1)
+	}
+	if len(deps) == 0 {
+		fmt.Printf("All configured models are offered by %s.\n", providerName)
+		return
+	}
+	for _, d := range deps {
+		fmt.Println("⚠ " + d.String())
+		if sub != "migrate" {
+			continue
+		}
+		if d.Source != "config" {
+			fmt.Println("  … built-in tier model: a running daemon with auto_migrate_models swaps it in memory")
+			continue
+		}
+		if what, err := migrateModel(d, router); err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+		} else {
+			fmt.Println("  ✓ " + what)
+		}
+	}
+	if sub == "check" {
+		fmt.Printf("\nRun '%s models migrate' to switch to the suggested replacements.\n", appName)
+	} else {
+		fmt.Printf("\nUndo with '%s models rollback'.\n", appName)
+	}
+}
+
+// doctorCheckModels prints the model availability line of `overhuman
+// doctor` and reports whether any configured model has been retired.
+func doctorCheckModels() bool {
+	cfg := loadConfig()
+	llm, providerName, err := createLLMProvider(cfg)
+	if err != nil {
+		return false
+	}
+	if _, ok := llm.(brain.ModelLister); !ok {
+		fmt.Printf("  … Models: %s cannot list models, skipped\n", providerName)
+		return false
+	}
+	deps, err := checkModelDeprecations(context.Background(), llm, cfg, newModelRouter(llm, providerName))
+	if err != nil {
+		fmt.Printf("  … Models: could not fetch model list (%v)\n", err)
+		return false
+	}
+	if len(deps) == 0 {
+		fmt.Printf("  ✓ Models: all configured models available\n")
+		return false
+	}
+	for _, d := range deps {
+		fmt.Printf("  ⚠ Models: %s\n", d)
+	}
+	fmt.Printf("    Fix with: %s models migrate (undo: %s models rollback)\n", appName, appName)
+	return true
+}
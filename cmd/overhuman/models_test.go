@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+func TestMigrateModel_BacksUpAndRollsBack(t *testing.T) {
+	t.Setenv("OVERHUMAN_DATA", t.TempDir())
+	if err := savePersistedConfig(&persistedConfig{Provider: "openai", Model: "gpt-4o"}); err != nil {
+		t.Fatal(err)
+	}
+	router := brain.NewModelRouter()
+	d := brain.ModelDeprecation{Model: "gpt-4o", Source: "config", Replacement: "gpt-4.5"}
+	if _, err := migrateModel(d, router); err != nil {
+		t.Fatalf("migrateModel: %v", err)
+	}
+	cfg, _ := loadPersistedConfig()
+	if cfg.Model != "gpt-4.5" {
+		t.Errorf("model after migrate = %q", cfg.Model)
+	}
+	if router.TierOf("gpt-4.5") != brain.TierMid {
+		t.Error("router still routes to the retired model")
+	}
+	versions, err := configHistory()
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("history = %+v, err %v", versions, err)
+	}
+
+	if _, err := rollbackPersistedConfig(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	cfg, _ = loadPersistedConfig()
+	if cfg.Model != "gpt-4o" {
+		t.Errorf("model after rollback = %q", cfg.Model)
+	}
+	if _, err := rollbackPersistedConfig(); err == nil {
+		t.Error("second rollback should fail: history is empty")
+	}
+}
+
+func TestModelRefs_SkipsDefault(t *testing.T) {
+	router := brain.NewModelRouterWithModels([]brain.ModelEntry{{ID: "default", Tier: brain.TierMid}})
+	refs := modelRefs(Config{LLMModel: "default"}, router)
+	if len(refs) != 0 {
+		t.Errorf("refs = %+v, want none", refs)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/webhook"
+)
+
+// heldReply is a reply to an external channel waiting for an admin to
+// release or discard it.
+type heldReply struct {
+	ID      string    `json:"id"`
+	Channel string    `json:"channel"`
+	To      string    `json:"to"`
+	TaskID  string    `json:"task_id,omitempty"`
+	Text    string    `json:"text"`
+	Flagged []string  `json:"flagged"`
+	Held    time.Time `json:"held"`
+
+	send func(text string) error
+}
+
+// outputGate runs cfg.Moderation over the results the daemon sends to
+// external channels: flagged replies are annotated, held for approval or
+// blocked, and every flagged reply is audited. Held replies are kept in
+// memory only; a restart drops them.
+type outputGate struct {
+	policy security.OutputModeration
+	mod    security.Moderator
+	audit  *security.AuditLogger // may be nil
+	hooks  *webhook.Dispatcher   // may be nil
+
+	mu   sync.Mutex
+	seq  int
+	held map[string]*heldReply
+}
+
+// newOutputGate returns the gate for cfg, or nil when moderation is off.
+func newOutputGate(cfg Config, audit *security.AuditLogger, hooks *webhook.Dispatcher) (*outputGate, error) {
+	if !cfg.Moderation.Enabled {
+		return nil, nil
+	}
+	mod, err := createModerator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &outputGate{policy: cfg.Moderation, mod: mod, audit: audit, hooks: hooks, held: make(map[string]*heldReply)}, nil
+}
+
+// createModerator builds the classifier of cfg.Moderation. The local
+// patterns always run and know the daemon's own keys.
+func createModerator(cfg Config) (security.Moderator, error) {
+	secrets := security.NewSecretRegistry()
+	for _, s := range []string{cfg.ClaudeKey, cfg.OpenAIKey, cfg.LLMAPIKey, cfg.UIAPIKey, cfg.EmbeddingAPIKey, cfg.AdminToken} {
+		secrets.Register(s)
+	}
+	local := security.LocalModerator{Secrets: secrets}
+	switch cfg.Moderation.Provider {
+	case "", "local":
+		return local, nil
+	case "openai":
+		key := cfg.OpenAIKey
+		if key == "" && cfg.LLMProvider == "openai" {
+			key = cfg.LLMAPIKey
+		}
+		if key == "" {
+			return nil, fmt.Errorf("moderation: the openai provider needs OPENAI_API_KEY")
+		}
+		remote := brain.NewUniversalProvider(brain.OpenAIConfig(key)).Moderator(cfg.Moderation.Model)
+		return security.CombineModerators(local, remote), nil
+	}
+	return nil, fmt.Errorf("moderation: unknown provider %q (local or openai)", cfg.Moderation.Provider)
+}
+
+// Send moderates text on its way to channel and passes what may go out to
+// send: the text as is, or annotated. Held and blocked replies are not
+// sent.
+func (g *outputGate) Send(ctx context.Context, channel, to, taskID, text string, send func(text string) error) error {
+	if g == nil || !g.policy.Moderates(channel) || strings.TrimSpace(text) == "" {
+		return send(text)
+	}
+	var v security.ModerationVerdict
+	scores, err := g.mod.Moderate(ctx, text)
+	if err != nil {
+		log.Printf("[moderation] %s reply for task %s: %v", channel, taskID, err)
+		v = security.ModerationVerdict{Action: g.policy.ErrorAction(), Flagged: []string{"error"}}
+	} else {
+		v = g.policy.Decide(scores)
+	}
+	if v.Action == security.ModerationAllow {
+		return send(text)
+	}
+
+	flagged := strings.Join(v.Flagged, ",")
+	log.Printf("[moderation] %s reply for task %s flagged (%s): %s", channel, taskID, flagged, v.Action)
+	details := map[string]string{"channel": channel, "task_id": taskID, "flagged": flagged}
+	switch v.Action {
+	case security.ModerationBlock:
+		g.log(security.SeverityCritical, "block", to, false, details)
+		return nil
+	case security.ModerationApprove:
+		h := g.hold(channel, to, taskID, text, v.Flagged, send)
+		details["held_id"] = h.ID
+		g.log(security.SeverityWarn, "hold", to, false, details)
+		if g.hooks != nil {
+			g.hooks.Emit(webhook.EventApprovalRequested, map[string]any{
+				"kind":    "reply",
+				"id":      h.ID,
+				"channel": channel,
+				"to":      to,
+				"task_id": taskID,
+				"flagged": v.Flagged,
+			})
+		}
+		return nil
+	default:
+		g.log(security.SeverityWarn, "annotate", to, true, details)
+		return send(moderationNote(v.Flagged) + text)
+	}
+}
+
+// moderationNote is the warning put before an annotated reply.
+func moderationNote(flagged []string) string {
+	return fmt.Sprintf("[Automated safety check flagged this message: %s. Review it before acting on it.]\n\n", strings.Join(flagged, ", "))
+}
+
+func (g *outputGate) log(severity security.AuditSeverity, action, to string, sent bool, details map[string]string) {
+	if g.audit != nil {
+		g.audit.Log(security.AuditOutputModerated, severity, "daemon", "moderation", action, to, sent, details)
+	}
+}
+
+func (g *outputGate) hold(channel, to, taskID, text string, flagged []string, send func(string) error) *heldReply {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.seq++
+	h := &heldReply{
+		ID:      fmt.Sprintf("held-%d", g.seq),
+		Channel: channel,
+		To:      to,
+		TaskID:  taskID,
+		Text:    text,
+		Flagged: flagged,
+		Held:    time.Now(),
+		send:    send,
+	}
+	g.held[h.ID] = h
+	return h
+}
+
+// Held returns the replies waiting for approval, oldest first.
+func (g *outputGate) Held() []heldReply {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out := make([]heldReply, 0, len(g.held))
+	for _, h := range g.held {
+		out = append(out, *h)
+	}
+	slices.SortFunc(out, func(a, b heldReply) int { return a.Held.Compare(b.Held) })
+	return out
+}
+
+var errNotHeld = errors.New("no held reply")
+
+// Resolve sends (release) or drops the held reply id on behalf of actor.
+func (g *outputGate) Resolve(id, actor string, release bool) error {
+	g.mu.Lock()
+	h, ok := g.held[id]
+	delete(g.held, id)
+	g.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w %q", errNotHeld, id)
+	}
+	action := "discard"
+	var err error
+	if release {
+		action = "release"
+		err = h.send(h.Text)
+	}
+	if g.audit != nil {
+		details := map[string]string{"channel": h.Channel, "task_id": h.TaskID, "held_id": h.ID, "flagged": strings.Join(h.Flagged, ",")}
+		if err != nil {
+			g.audit.LogError(security.AuditOutputModerated, "daemon", actor, action, h.To, err.Error(), details)
+		} else {
+			g.audit.Log(security.AuditOutputModerated, security.SeverityInfo, "daemon", actor, action, h.To, release, details)
+		}
+	}
+	return err
+}
+
+// RegisterRoutes serves the held replies to admins: GET
+// /api/moderation/held, POST /api/moderation/held/{id}/release and
+// /discard.
+func (g *outputGate) RegisterRoutes(mux *http.ServeMux, adminToken string) {
+	mux.HandleFunc("GET /api/moderation/held", func(w http.ResponseWriter, r *http.Request) {
+		if !security.AuthorizeAdmin(r, adminToken) {
+			writeExportError(w, http.StatusForbidden, fmt.Errorf("held replies need the admin role"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(g.Held())
+	})
+	mux.HandleFunc("POST /api/moderation/held/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		if !security.AuthorizeAdmin(r, adminToken) {
+			writeExportError(w, http.StatusForbidden, fmt.Errorf("held replies need the admin role"))
+			return
+		}
+		action := r.PathValue("action")
+		if action != "release" && action != "discard" {
+			writeExportError(w, http.StatusNotFound, fmt.Errorf("unknown action %q (release or discard)", action))
+			return
+		}
+		actor := "admin"
+		if p, ok := security.PrincipalFrom(r.Context()); ok {
+			actor = p.Name
+		}
+		if err := g.Resolve(r.PathValue("id"), actor, action == "release"); err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, errNotHeld) {
+				status = http.StatusNotFound
+			}
+			writeExportError(w, status, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// permissionsPath is where skill permission decisions are kept.
+func permissionsPath(cfg Config) string {
+	return filepath.Join(cfg.DataDir, "permissions.json")
+}
+
+// cliPermissionPrompter asks for skill permissions inline in `overhuman
+// cli`. The pipeline runs on the CLI loop's goroutine, so while it waits
+// the next line the user types arrives on answers and is read as the reply.
+type cliPermissionPrompter struct {
+	w       io.Writer
+	answers <-chan *senses.UnifiedInput
+}
+
+// cliPermissionAttempts is how many answers are read before an unrecognised
+// reply denies the permission for this use (without persisting it).
+const cliPermissionAttempts = 3
+
+// PromptPermission implements security.PermissionPrompter.
+func (c *cliPermissionPrompter) PromptPermission(ctx context.Context, req security.PermissionRequest) (security.PermissionDecision, error) {
+	name := req.SkillID
+	if req.SkillName != "" {
+		name = fmt.Sprintf("%s (%s)", req.SkillName, req.SkillID)
+	}
+	what := req.Permission
+	if req.Detail != "" {
+		what = req.Detail
+	}
+	fmt.Fprintf(c.w, "\nAllow skill %s to %s? [always/once/never] ", name, what)
+	for i := 0; i < cliPermissionAttempts; i++ {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case in, ok := <-c.answers:
+			if !ok {
+				return "", fmt.Errorf("permission prompt: input closed")
+			}
+			if d, ok := security.ParsePermissionDecision(in.Payload); ok {
+				return d, nil
+			}
+			fmt.Fprint(c.w, "Please answer always, once or never: ")
+		}
+	}
+	fmt.Fprintln(c.w, "No valid answer — not allowed this time.")
+	return "", fmt.Errorf("permission prompt: no valid answer")
+}
+
+// runPermissions handles `overhuman permissions [list|reset SKILL [PERMISSION]]`.
+func runPermissions(args []string) {
+	cfg := loadConfig()
+	store, err := security.NewPermissionStore(permissionsPath(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	sub := "list"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	switch {
+	case sub == "list":
+		grants := store.List()
+		if len(grants) == 0 {
+			fmt.Println("No permission decisions recorded; skills ask on first use in the CLI.")
+			return
+		}
+		for _, g := range grants {
+			fmt.Printf("%-7s %-20s %-12s %s\n", g.Decision, g.SkillID, g.Permission, g.DecidedAt.Local().Format("2006-01-02 15:04"))
+		}
+	case sub == "reset" && (len(args) == 2 || len(args) == 3):
+		n := 0
+		for _, g := range store.List() {
+			if g.SkillID != args[1] || (len(args) == 3 && g.Permission != args[2]) {
+				continue
+			}
+			if _, err := store.Forget(g.SkillID, g.Permission); err != nil {
+				fmt.Fprintf(os.Stderr, "reset: %v\n", err)
+				os.Exit(1)
+			}
+			n++
+		}
+		fmt.Printf("Reset %d decision(s); the skill will ask again on next use.\n", n)
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s permissions [list|reset SKILL [PERMISSION]]\n", appName)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+func TestCLIPermissionPrompter(t *testing.T) {
+	answers := make(chan *senses.UnifiedInput, 4)
+	var out bytes.Buffer
+	pr := &cliPermissionPrompter{w: &out, answers: answers}
+	req := security.PermissionRequest{SkillID: "skill_fileops", SkillName: "File Operations", Permission: "filesystem", Detail: "read and write files"}
+
+	answers <- senses.NewFromText("sure")
+	answers <- senses.NewFromText("always")
+	d, err := pr.PromptPermission(context.Background(), req)
+	if err != nil || d != security.PermissionAlways {
+		t.Fatalf("PromptPermission = %q, %v", d, err)
+	}
+	if !strings.Contains(out.String(), "Allow skill File Operations (skill_fileops) to read and write files? [always/once/never]") ||
+		!strings.Contains(out.String(), "Please answer always, once or never") {
+		t.Errorf("prompt output = %q", out.String())
+	}
+
+	for i := 0; i < cliPermissionAttempts; i++ {
+		answers <- senses.NewFromText("hmm")
+	}
+	if _, err := pr.PromptPermission(context.Background(), req); err == nil {
+		t.Error("no valid answer should deny with an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pr.PromptPermission(ctx, req); err == nil {
+		t.Error("cancelled context should fail")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+// runsOptions are the flags of `overhuman runs`.
+type runsOptions struct {
+	Filter memory.TranscriptFilter
+	Limit  int
+}
+
+// parseRunsArgs parses --limit, --sender and --since.
+func parseRunsArgs(args []string, now time.Time) (runsOptions, error) {
+	opts := runsOptions{Limit: 20}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--limit", "--sender", "--since":
+		default:
+			return opts, fmt.Errorf("unknown flag %q", args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return opts, fmt.Errorf("--limit: want a positive number, got %q", value)
+			}
+			opts.Limit = n
+		case "--sender":
+			opts.Filter.Sender = value
+		case "--since":
+			t, err := parseExportTime(value, now)
+			if err != nil {
+				return opts, fmt.Errorf("--since: %w", err)
+			}
+			opts.Filter.Since = t
+		}
+	}
+	return opts, nil
+}
+
+// runRuns handles `overhuman runs`: the most recent exchanges from the
+// transcript log, newest first. It only reads files, so it works while the
+// daemon runs.
+func runRuns(args []string) {
+	opts, err := parseRunsArgs(args, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "runs: %v\nUsage: %s runs [--limit N] [--sender X] [--since DATE]\n", err, appName)
+		os.Exit(2)
+	}
+	transcripts, err := memory.NewTranscriptLog(transcriptsPath(loadConfig()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "runs: %v\n", err)
+		os.Exit(1)
+	}
+	entries, err := transcripts.Query(opts.Filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "runs: %v\n", err)
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No runs recorded yet.")
+		return
+	}
+	if len(entries) > opts.Limit {
+		entries = entries[len(entries)-opts.Limit:]
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		fmt.Println(formatRun(entries[i]))
+	}
+}
+
+// formatRun renders one exchange as a single line.
+func formatRun(e memory.TranscriptEntry) string {
+	input := strings.Join(strings.Fields(e.Input), " ")
+	if r := []rune(input); len(r) > 60 {
+		input = string(r[:59]) + "…"
+	}
+	who := e.Channel
+	if e.Sender != "" {
+		who += "/" + e.Sender
+	}
+	return fmt.Sprintf("%s  %-20s  $%.4f  q=%3.0f%%  %s  (%s)",
+		e.Time.Local().Format("2006-01-02 15:04"), who, e.CostUSD, e.Quality*100, input, e.TaskID)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/deploy"
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+func TestParseRunsArgs(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	opts, err := parseRunsArgs([]string{"--limit", "5", "--sender=alice", "--since", "1d"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Limit != 5 || opts.Filter.Sender != "alice" || !opts.Filter.Since.Equal(now.AddDate(0, 0, -1)) {
+		t.Errorf("opts = %+v", opts)
+	}
+	if opts, _ := parseRunsArgs(nil, now); opts.Limit != 20 {
+		t.Errorf("default limit = %d", opts.Limit)
+	}
+	for _, bad := range [][]string{{"--limit", "-1"}, {"--format", "md"}, {"--since"}} {
+		if _, err := parseRunsArgs(bad, now); err == nil {
+			t.Errorf("%v: expected error", bad)
+		}
+	}
+}
+
+func TestFormatRun(t *testing.T) {
+	line := formatRun(memory.TranscriptEntry{
+		Time: time.Now(), TaskID: "task_42", Channel: "TELEGRAM", Sender: "alice",
+		Input: "Plan my week\n" + strings.Repeat("and more ", 20), CostUSD: 0.01, Quality: 0.8,
+	})
+	for _, want := range []string{"TELEGRAM/alice", "$0.0100", "q= 80%", "Plan my week and more", "…", "(task_42)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("line %q missing %q", line, want)
+		}
+	}
+}
+
+func TestLocalDaemon(t *testing.T) {
+	cfg := Config{DataDir: t.TempDir()}
+	if _, running := localDaemon(cfg); running {
+		t.Fatal("no daemon expected")
+	}
+	pf := deploy.NewPIDFile(cfg.DataDir)
+	if err := pf.Lock(deploy.LockInfo{Version: "9.9.9"}); err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Unlock()
+	owner, running := localDaemon(cfg)
+	if !running || !strings.Contains(formatLockOwner(owner), "v9.9.9") {
+		t.Errorf("localDaemon = %+v, %v", owner, running)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// sandboxSettings is the "sandbox" block of config.json: generated code
+// skills run in Docker containers or WASI modules with these limits, e.g.
+// {"enabled": true, "memory_mb": 512}. Without it code skills are not run.
+type sandboxSettings struct {
+	// Enabled turns on the Docker sandbox, unless Runtime says otherwise.
+	Enabled bool `json:"enabled,omitempty"`
+	// Runtime is "docker", "wasm" or "none". Default: docker when
+	// enabled, none otherwise.
+	Runtime string `json:"runtime,omitempty"`
+	// Image is the Docker image skills run in. Default:
+	// overhuman-skill-base.
+	Image string `json:"image,omitempty"`
+	// WasmInterpreters are the interpreter modules of the wasm runtime,
+	// by language, e.g. {"python": {"module": "/opt/wasm/python.wasm",
+	// "dirs": {"/opt/wasm/lib": "/usr/local/lib"}}}.
+	WasmInterpreters map[string]instruments.WasmInterpreter `json:"wasm_interpreters,omitempty"`
+	// MemoryMB is a container's memory limit. Default: 256.
+	MemoryMB int `json:"memory_mb,omitempty"`
+	// CPUs is a container's CPU limit. Default: 0.5.
+	CPUs float64 `json:"cpus,omitempty"`
+	// TimeoutS is how long a run may take, in seconds. Default: 30.
+	TimeoutS int `json:"timeout_s,omitempty"`
+}
+
+// validate checks the runtime and the limits.
+func (s sandboxSettings) validate() error {
+	switch s.Runtime {
+	case "", "docker", "none":
+	case "wasm":
+		if len(s.WasmInterpreters) == 0 {
+			return fmt.Errorf("the wasm runtime needs wasm_interpreters")
+		}
+		for lang, in := range s.WasmInterpreters {
+			if in.Module == "" {
+				return fmt.Errorf("wasm_interpreters.%s: module is required", lang)
+			}
+		}
+	default:
+		return fmt.Errorf("runtime %q is not docker, wasm or none", s.Runtime)
+	}
+	if s.MemoryMB < 0 || s.CPUs < 0 || s.TimeoutS < 0 {
+		return fmt.Errorf("memory_mb, cpus and timeout_s must not be negative")
+	}
+	return nil
+}
+
+// runtime is the configured runtime: docker, wasm or none.
+func (s sandboxSettings) runtime() string {
+	switch {
+	case s.Runtime != "":
+		return s.Runtime
+	case s.Enabled:
+		return "docker"
+	}
+	return "none"
+}
+
+// config is the sandbox configuration: the defaults with the settings
+// applied. Containers never get a network.
+func (s sandboxSettings) config() instruments.SandboxConfig {
+	c := instruments.DefaultSandboxConfig()
+	if s.Image != "" {
+		c.Image = s.Image
+	}
+	if s.MemoryMB > 0 {
+		c.MemoryMB = s.MemoryMB
+	}
+	if s.CPUs > 0 {
+		c.CPUs = s.CPUs
+	}
+	if s.TimeoutS > 0 {
+		c.Timeout = time.Duration(s.TimeoutS) * time.Second
+	}
+	return c
+}
+
+// createSandbox returns the sandbox of the configured runtime, or nil if
+// there is none. It fails if the runtime can't be used.
+func createSandbox(cfg Config) (instruments.Sandbox, error) {
+	var sandbox instruments.Sandbox
+	switch s := cfg.Sandbox; s.runtime() {
+	case "docker":
+		sandbox = instruments.NewDockerSandbox(s.config())
+	case "wasm":
+		c := s.config()
+		sandbox = instruments.NewWasmSandbox(instruments.WasmConfig{
+			Interpreters: s.WasmInterpreters,
+			MemoryMB:     c.MemoryMB,
+			Timeout:      c.Timeout,
+		})
+	default:
+		return nil, nil
+	}
+	if !sandbox.IsAvailable() {
+		return nil, fmt.Errorf("the %s runtime is not available", sandbox.Name())
+	}
+	return sandbox, nil
+}
+
+// errSandboxUnavailable is the error of a code skill run without a
+// sandbox.
+var errSandboxUnavailable = errors.New("code skills are denied: the sandbox is not available")
+
+// sandboxedSkill runs a code skill in the sandbox, within the limits of
+// its manifest, and records each run in the audit log. Without a sandbox
+// every run is denied, so the task falls back to the LLM.
+type sandboxedSkill struct {
+	run     instruments.SkillExecutor // nil = denied
+	runtime string
+	id      string
+	audit   *security.AuditLogger // nil = not audited
+}
+
+// sandboxExecutor returns the executor of the code skills described by a
+// manifest: in sandbox, or denied when it is nil.
+func sandboxExecutor(sandbox instruments.Sandbox, audit *security.AuditLogger) func(m security.SkillManifest, language, code string) instruments.SkillExecutor {
+	return func(m security.SkillManifest, language, code string) instruments.SkillExecutor {
+		s := &sandboxedSkill{id: m.SkillID, audit: audit}
+		if sandbox != nil {
+			s.runtime = sandbox.Name()
+			s.run = sandbox.CreateLimitedSkillExecutor(language, code, instruments.SandboxConfig{
+				MemoryMB: m.MaxMemoryMB,
+				CPUs:     m.MaxCPU,
+				Timeout:  time.Duration(m.MaxTimeoutS) * time.Second,
+			})
+		}
+		return s
+	}
+}
+
+// Execute implements instruments.SkillExecutor.
+func (s *sandboxedSkill) Execute(ctx context.Context, input instruments.SkillInput) (*instruments.SkillOutput, error) {
+	if s.run == nil {
+		if s.audit != nil {
+			s.audit.Log(security.AuditExecDenied, security.SeverityWarn, "sandbox", "system", "execute", s.id, false,
+				map[string]string{"reason": errSandboxUnavailable.Error()})
+		}
+		return &instruments.SkillOutput{Error: errSandboxUnavailable.Error()}, errSandboxUnavailable
+	}
+	out, err := s.run.Execute(ctx, input)
+	if s.audit != nil {
+		ok := err == nil && out != nil && out.Success
+		details := map[string]string{"runtime": s.runtime}
+		if out != nil {
+			details["elapsed_ms"] = strconv.FormatInt(out.ElapsedMs, 10)
+			if out.Error != "" {
+				details["error"] = truncateText(out.Error, 500)
+			}
+		}
+		if err != nil {
+			details["error"] = err.Error()
+		}
+		severity := security.SeverityInfo
+		if !ok {
+			severity = security.SeverityWarn
+		}
+		s.audit.Log(security.AuditSkillExec, severity, "sandbox", "system", "execute", s.id, ok, details)
+	}
+	return out, err
+}
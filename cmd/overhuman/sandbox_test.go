@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestSandboxExecutor_Denied(t *testing.T) {
+	audit := security.NewAuditLogger(security.NewMemoryAuditStore())
+	run := sandboxExecutor(nil, audit)(security.SkillManifest{SkillID: "skill_gen_1"}, "python", "print(1)")
+	if out, err := run.Execute(context.Background(), instruments.SkillInput{Goal: "x"}); err != errSandboxUnavailable || out.Success {
+		t.Errorf("Execute = %+v, %v", out, err)
+	}
+	events, _ := audit.Query(security.AuditFilter{Type: security.AuditExecDenied})
+	if len(events) != 1 || events[0].Resource != "skill_gen_1" {
+		t.Errorf("audit = %+v", events)
+	}
+
+	s := sandboxSettings{MemoryMB: 512, TimeoutS: 60}.config()
+	if s.MemoryMB != 512 || s.Timeout != time.Minute || s.Image != "overhuman-skill-base" || s.NetworkMode != "none" {
+		t.Errorf("config = %+v", s)
+	}
+}
+
+func TestSandboxSettings_Runtime(t *testing.T) {
+	for _, c := range []struct {
+		s    sandboxSettings
+		want string
+	}{
+		{sandboxSettings{}, "none"},
+		{sandboxSettings{Enabled: true}, "docker"},
+		{sandboxSettings{Enabled: true, Runtime: "none"}, "none"},
+		{sandboxSettings{Runtime: "wasm"}, "wasm"},
+	} {
+		if got := c.s.runtime(); got != c.want {
+			t.Errorf("%+v: runtime = %q, want %q", c.s, got, c.want)
+		}
+	}
+	if err := (sandboxSettings{Runtime: "wasm"}).validate(); err == nil {
+		t.Error("wasm without interpreters should not validate")
+	}
+	if err := (sandboxSettings{Runtime: "firecracker"}).validate(); err == nil {
+		t.Error("unknown runtime should not validate")
+	}
+	wasm := sandboxSettings{Runtime: "wasm", WasmInterpreters: map[string]instruments.WasmInterpreter{"python": {Module: "python.wasm"}}}
+	if err := wasm.validate(); err != nil {
+		t.Error(err)
+	}
+	if sb, err := createSandbox(Config{Sandbox: wasm}); sb != nil || err == nil {
+		t.Errorf("createSandbox without the module = %v, %v", sb, err)
+	}
+}
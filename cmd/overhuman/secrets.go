@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// masterKeyEnv holds the passphrase that encrypts secrets in config.json.
+const masterKeyEnv = "OVERHUMAN_MASTER_KEY"
+
+// decryptConfigSecret returns v decrypted with OVERHUMAN_MASTER_KEY when it
+// is an "enc:v1:" value, and v unchanged otherwise.
+func decryptConfigSecret(v string) (string, error) {
+	if !strings.HasPrefix(v, "enc:") {
+		return v, nil
+	}
+	pass := os.Getenv(masterKeyEnv)
+	if pass == "" {
+		return "", fmt.Errorf("value is encrypted but %s is not set", masterKeyEnv)
+	}
+	enc, err := security.NewEncryptor(pass)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", masterKeyEnv, err)
+	}
+	return enc.Decrypt(v)
+}
+
+// runEncrypt handles `overhuman encrypt`: it reads a secret from stdin and
+// prints the "enc:v1:" value to paste into config.json.
+func runEncrypt() {
+	pass := os.Getenv(masterKeyEnv)
+	if pass == "" {
+		fmt.Fprintf(os.Stderr, "Set %s (at least 8 characters) first; the daemon needs the same value to decrypt.\n", masterKeyEnv)
+		os.Exit(1)
+	}
+	enc, err := security.NewEncryptor(pass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", masterKeyEnv, err)
+		os.Exit(1)
+	}
+	fmt.Fprint(os.Stderr, "Secret: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	secret := strings.TrimSpace(line)
+	if secret == "" {
+		fmt.Fprintf(os.Stderr, "no secret read: %v\n", err)
+		os.Exit(1)
+	}
+	out, err := enc.Encrypt(secret)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "encrypt: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestDecryptConfigSecret(t *testing.T) {
+	if v, err := decryptConfigSecret("plain-token"); err != nil || v != "plain-token" {
+		t.Errorf("plain value = %q, %v", v, err)
+	}
+
+	enc, _ := security.NewEncryptor("master-passphrase")
+	sealed, _ := enc.Encrypt("secret_notion")
+
+	t.Setenv(masterKeyEnv, "")
+	if _, err := decryptConfigSecret(sealed); err == nil {
+		t.Error("encrypted value without master key should fail")
+	}
+	t.Setenv(masterKeyEnv, "master-passphrase")
+	if v, err := decryptConfigSecret(sealed); err != nil || v != "secret_notion" {
+		t.Errorf("decrypted = %q, %v", v, err)
+	}
+	t.Setenv(masterKeyEnv, "another-passphrase")
+	if _, err := decryptConfigSecret(sealed); err == nil {
+		t.Error("wrong master key should fail")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// registerSessionRoutes serves the conversation sessions:
+//
+//	GET    /api/sessions       sessions with short-term history
+//	DELETE /api/sessions/{id}  end a session, as "/new" would
+//
+// In team mode a member sees and ends only their own sessions.
+func registerSessionRoutes(mux *http.ServeMux, p *pipeline.Pipeline) {
+	mux.HandleFunc("GET /api/sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions := []pipeline.SessionInfo{}
+		for _, s := range p.Sessions() {
+			if ownsSession(r, s) {
+				sessions = append(sessions, s)
+			}
+		}
+		writeSessionsJSON(w, http.StatusOK, map[string]any{"sessions": sessions})
+	})
+	mux.HandleFunc("DELETE /api/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		found := false
+		for _, s := range p.Sessions() {
+			if s.ID == id {
+				found = ownsSession(r, s)
+				break
+			}
+		}
+		if !found {
+			writeSessionsJSON(w, http.StatusNotFound, map[string]string{"error": pipeline.ErrSessionNotFound.Error()})
+			return
+		}
+		summary, err := p.EndSession(r.Context(), id)
+		switch {
+		case errors.Is(err, pipeline.ErrSessionNotFound):
+			writeSessionsJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		case err != nil:
+			writeSessionsJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		default:
+			writeSessionsJSON(w, http.StatusOK, map[string]string{"ended": id, "summary": summary})
+		}
+	})
+}
+
+// ownsSession reports whether the caller may see session s: admins and
+// the single-user daemon see every session, team members their own.
+func ownsSession(r *http.Request, s pipeline.SessionInfo) bool {
+	p, ok := security.PrincipalFrom(r.Context())
+	return !ok || p.Role.Allows(security.RoleAdmin) || s.Sender == p.Name
+}
+
+func writeSessionsJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestSessionRoutes(t *testing.T) {
+	stm := memory.NewShortTermMemory(20)
+	stm.AddWithSession("user", "hi", map[string]string{"channel": "API", "sender": "alice"}, "API/alice")
+	stm.AddWithSession("user", "hello", map[string]string{"channel": "API", "sender": "bob"}, "API/bob")
+	mux := http.NewServeMux()
+	registerSessionRoutes(mux, pipeline.New(pipeline.Dependencies{ShortTerm: stm}))
+
+	list := func(p *security.Principal) []pipeline.SessionInfo {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/sessions", nil)
+		if p != nil {
+			req = req.WithContext(security.WithPrincipal(req.Context(), *p))
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var body struct{ Sessions []pipeline.SessionInfo }
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("GET /api/sessions = %d, %v", rec.Code, err)
+		}
+		return body.Sessions
+	}
+	if got := list(nil); len(got) != 2 || got[0].ExpiresAt.IsZero() {
+		t.Errorf("single-user list = %+v", got)
+	}
+	bob := &security.Principal{Name: "bob", Role: security.RoleMember}
+	if got := list(bob); len(got) != 1 || got[0].ID != "API/bob" {
+		t.Errorf("member list = %+v, want only their own session", got)
+	}
+
+	end := func(id string, p *security.Principal) int {
+		req := httptest.NewRequest("DELETE", "/api/sessions/"+id, nil)
+		if p != nil {
+			req = req.WithContext(security.WithPrincipal(req.Context(), *p))
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := end("API%2Falice", bob); code != http.StatusNotFound {
+		t.Errorf("ending another member's session = %d, want 404", code)
+	}
+	if code := end("API%2Fbob", bob); code != http.StatusOK {
+		t.Errorf("ending own session = %d, want 200", code)
+	}
+	if got := stm.GetRecentBySession(10, "API/bob"); len(got) != 0 {
+		t.Errorf("ended session kept history: %+v", got)
+	}
+	if got := list(nil); len(got) != 1 || got[0].ID != "API/alice" {
+		t.Errorf("after end = %+v", got)
+	}
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/locale"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/netaddr"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// configProviders are the provider keys config.json accepts.
+var configProviders = []string{"openai", "claude", "anthropic", "ollama", "lmstudio", "groq", "together", "openrouter", "custom", "fake"}
+
+// providerKeyEnv is the provider-specific API key variable; LLM_API_KEY
+// works for every provider.
+var providerKeyEnv = map[string]string{
+	"openai":    "OPENAI_API_KEY",
+	"claude":    "ANTHROPIC_API_KEY",
+	"anthropic": "ANTHROPIC_API_KEY",
+}
+
+// providerNeedsKey reports whether provider refuses to start without a key.
+func providerNeedsKey(provider string) bool {
+	switch provider {
+	case "ollama", "lmstudio", "custom", "fake":
+		return false
+	}
+	return true
+}
+
+// setupOptions are the flags of a scripted `overhuman configure`.
+type setupOptions struct {
+	Provider  string
+	APIKeyEnv string // variable to read the API key from
+	Model     string
+	BaseURL   string
+	Name      string
+	APIAddr   string
+	// DailyBudget and MonthlyBudget are spending caps in USD ("0" = none).
+	DailyBudget   string
+	MonthlyBudget string
+	Yes           bool // write without asking
+	SkipTest      bool // no connection test after writing
+}
+
+func parseSetupArgs(args []string) (setupOptions, error) {
+	var opts setupOptions
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		var dst *string
+		switch name {
+		case "--yes", "-y", "--non-interactive":
+			opts.Yes = true
+			continue
+		case "--skip-test":
+			opts.SkipTest = true
+			continue
+		case "--provider":
+			dst = &opts.Provider
+		case "--api-key-env":
+			dst = &opts.APIKeyEnv
+		case "--model":
+			dst = &opts.Model
+		case "--base-url":
+			dst = &opts.BaseURL
+		case "--name":
+			dst = &opts.Name
+		case "--api-addr":
+			dst = &opts.APIAddr
+		case "--daily-budget":
+			dst = &opts.DailyBudget
+		case "--monthly-budget":
+			dst = &opts.MonthlyBudget
+		default:
+			return opts, fmt.Errorf("unknown flag %q", args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		*dst = strings.TrimSpace(value)
+	}
+	if opts.Provider == "" {
+		return opts, fmt.Errorf("--provider is required (%s)", strings.Join(configProviders, ", "))
+	}
+	if !isConfigProvider(opts.Provider) {
+		return opts, fmt.Errorf("unknown provider %q (%s)", opts.Provider, strings.Join(configProviders, ", "))
+	}
+	return opts, nil
+}
+
+func isConfigProvider(p string) bool {
+	for _, known := range configProviders {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// applySetup merges opts into cfg. Settings the flags do not mention are
+// kept, except that switching provider drops the old key, model and URL.
+func applySetup(cfg *persistedConfig, opts setupOptions, getenv func(string) string) error {
+	if cfg.Provider != opts.Provider {
+		cfg.APIKey, cfg.Model, cfg.BaseURL = "", "", ""
+	}
+	cfg.Provider = opts.Provider
+	if opts.APIKeyEnv != "" {
+		key := strings.TrimSpace(getenv(opts.APIKeyEnv))
+		if key == "" {
+			return fmt.Errorf("--api-key-env: %s is not set", opts.APIKeyEnv)
+		}
+		cfg.APIKey = key
+	}
+	for dst, v := range map[*string]string{
+		&cfg.Model:   opts.Model,
+		&cfg.BaseURL: opts.BaseURL,
+		&cfg.Name:    opts.Name,
+		&cfg.APIAddr: opts.APIAddr,
+	} {
+		if v != "" {
+			*dst = v
+		}
+	}
+	for dst, flag := range map[*float64]string{
+		&cfg.DailyBudgetUSD:   opts.DailyBudget,
+		&cfg.MonthlyBudgetUSD: opts.MonthlyBudget,
+	} {
+		if flag == "" {
+			continue
+		}
+		usd, err := parseBudgetUSD(flag)
+		if err != nil {
+			return err
+		}
+		*dst = usd
+	}
+	return nil
+}
+
+// runSetup handles `overhuman configure --provider ...`: the wizard's
+// settings as flags, for Docker images and provisioning scripts.
+func runSetup(args []string) {
+	opts, err := parseSetupArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configure: %v\n", err)
+		os.Exit(2)
+	}
+	cfg, err := loadPersistedConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configure: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg == nil {
+		cfg = &persistedConfig{}
+	}
+	if err := applySetup(cfg, opts, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "configure: %v\n", err)
+		os.Exit(2)
+	}
+	if printConfigIssues(checkPersistedConfig(cfg, os.Getenv)) > 0 {
+		fmt.Fprintln(os.Stderr, "configure: nothing written")
+		os.Exit(1)
+	}
+
+	path := configFilePath()
+	if !opts.Yes {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprintln(os.Stderr, "configure: pass --yes to write the config without confirmation")
+			os.Exit(2)
+		}
+		fmt.Printf("Write provider=%s model=%s to %s? [y/N] ", cfg.Provider, orDefault(cfg.Model, "(provider default)"), path)
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(line)); a != "y" && a != "yes" {
+			fmt.Println("Cancelled.")
+			return
+		}
+	}
+	if err := savePersistedConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "configure: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Configuration saved to %s\n", path)
+
+	if opts.SkipTest || cfg.Provider == "fake" {
+		return
+	}
+	if err := testProviderConnection(withEnvAPIKey(cfg, os.Getenv)); err != nil {
+		fmt.Printf("⚠ Connection test: %v\n", err)
+	} else {
+		fmt.Println("✓ Connected")
+	}
+}
+
+// runConfigValidate handles `overhuman config validate [PATH] [--offline]`.
+// It checks the file against the config schema and the provider without
+// writing anything, and exits non-zero on errors.
+func runConfigValidate(args []string) {
+	path, offline := "", false
+	for _, a := range args {
+		switch {
+		case a == "--offline":
+			offline = true
+		case strings.HasPrefix(a, "-"):
+			fmt.Fprintf(os.Stderr, "config validate: unknown flag %q\n", a)
+			os.Exit(2)
+		case path == "":
+			path = a
+		default:
+			fmt.Fprintln(os.Stderr, "usage: overhuman config validate [PATH] [--offline]")
+			os.Exit(2)
+		}
+	}
+	if path == "" {
+		path = configFilePath()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := decodeConfigStrict(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if printConfigIssues(checkPersistedConfig(cfg, os.Getenv)) > 0 {
+		os.Exit(1)
+	}
+	if !offline && cfg.Provider != "" && cfg.Provider != "fake" {
+		if err := testProviderConnection(withEnvAPIKey(cfg, os.Getenv)); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ provider %s: %v\n", cfg.Provider, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ provider %s reachable\n", cfg.Provider)
+	}
+	fmt.Printf("✓ %s is valid\n", path)
+}
+
+// decodeConfigStrict parses config.json, rejecting unknown fields so typos
+// do not silently fall back to defaults.
+func decodeConfigStrict(data []byte) (*persistedConfig, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg persistedConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON object")
+	}
+	return &cfg, nil
+}
+
+// configIssue is one finding of checkPersistedConfig.
+type configIssue struct {
+	Field string
+	Msg   string
+	Warn  bool // reported, but does not fail validation
+}
+
+// checkPersistedConfig checks cfg's values without using the network.
+func checkPersistedConfig(cfg *persistedConfig, getenv func(string) string) []configIssue {
+	var issues []configIssue
+	fail := func(field, format string, args ...any) {
+		issues = append(issues, configIssue{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+	warn := func(field, format string, args ...any) {
+		issues = append(issues, configIssue{Field: field, Msg: fmt.Sprintf(format, args...), Warn: true})
+	}
+
+	switch {
+	case cfg.Provider == "" && getenv("LLM_PROVIDER") == "":
+		fail("provider", "not set (and LLM_PROVIDER is empty)")
+	case cfg.Provider != "" && !isConfigProvider(cfg.Provider):
+		fail("provider", "unknown provider %q (%s)", cfg.Provider, strings.Join(configProviders, ", "))
+	case providerNeedsKey(cfg.Provider) && withEnvAPIKey(cfg, getenv).APIKey == "":
+		env := "LLM_API_KEY"
+		if e := providerKeyEnv[cfg.Provider]; e != "" {
+			env = e + " or " + env
+		}
+		fail("api_key", "%s needs an API key: set api_key or %s", cfg.Provider, env)
+	case cfg.Provider == "custom" && cfg.BaseURL == "" && getenv("LLM_BASE_URL") == "":
+		fail("base_url", "the custom provider needs a base URL")
+	}
+	if strings.HasPrefix(cfg.APIKey, "enc:") {
+		fail("api_key", "encrypted values are not supported here; pass the key via the environment instead")
+	}
+	if cfg.BaseURL != "" {
+		if u, err := url.Parse(cfg.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("base_url", "%q is not an http(s) URL", cfg.BaseURL)
+		}
+	}
+	if cfg.APIAddr != "" {
+		if network, addr := netaddr.Parse(cfg.APIAddr); network == "unix" {
+			if addr == "" {
+				fail("api_addr", "empty unix socket path")
+			}
+		} else if _, _, err := net.SplitHostPort(addr); err != nil {
+			fail("api_addr", "%q: want host:port, [ipv6]:port or unix:/path", cfg.APIAddr)
+		}
+	}
+
+	if r, err := locale.NewResolver(locale.Settings{Timezone: cfg.Timezone, Locale: cfg.Locale}); err != nil {
+		fail("timezone/locale", "%v", err)
+	} else {
+		for user, us := range cfg.Users {
+			if err := r.SetUser(user, us); err != nil {
+				fail("users."+user, "%v", err)
+			}
+		}
+	}
+	if cfg.ActiveHours != "" {
+		if _, err := senses.ParseActiveHours(cfg.ActiveHours, time.UTC); err != nil {
+			fail("active_hours", "%v", err)
+		}
+	}
+	if _, _, err := parseHeartbeatBounds(cfg.HeartbeatMin, cfg.HeartbeatMax); err != nil {
+		fail("heartbeat_min/max", "%v", err)
+	}
+	for name, v := range cfg.KeyExpiry {
+		if _, err := time.Parse(keyExpiryLayout, v); err != nil {
+			fail("key_expiry."+name, "%q: want YYYY-MM-DD", v)
+		}
+	}
+	for name, text := range cfg.Templates {
+		if strings.TrimSpace(text) == "" {
+			warn("templates."+name, "empty template")
+		}
+	}
+	kc := genui.DefaultKioskConfig()
+	cfg.Kiosk.apply(&kc, "")
+	if err := kc.Validate(); err != nil {
+		fail("kiosk", "%v", err)
+	}
+	for name, sc := range cfg.Senses {
+		if senses.ParseSourceType(name) == "" {
+			fail("senses."+name, "unknown channel")
+		}
+		if _, err := memory.ParseVisibility(sc.MemoryVisibility); err != nil {
+			fail("senses."+name+".memory_visibility", "%v", err)
+		}
+		if sc.Preprocess != nil {
+			if err := sc.Preprocess.Validate(); err != nil {
+				fail("senses."+name+".preprocess", "%v", err)
+			}
+		}
+	}
+	if cfg.SoulTokenBudget < 0 {
+		fail("soul_token_budget", "must not be negative")
+	}
+	if cfg.SpeculationMultiplier < 0 {
+		fail("speculation_multiplier", "must not be negative")
+	}
+	if cfg.DailyBudgetUSD < 0 {
+		fail("daily_budget_usd", "must not be negative")
+	}
+	if cfg.MonthlyBudgetUSD < 0 {
+		fail("monthly_budget_usd", "must not be negative")
+	}
+	if cfg.DailyBudgetUSD > 0 && cfg.MonthlyBudgetUSD > 0 && cfg.DailyBudgetUSD > cfg.MonthlyBudgetUSD {
+		fail("daily_budget_usd", "exceeds monthly_budget_usd")
+	}
+	seen := make(map[string]bool)
+	for i, s := range cfg.MCPServers {
+		field := fmt.Sprintf("mcp_servers[%d]", i)
+		switch {
+		case s.Name == "" || s.Command == "":
+			fail(field, "name and command are required")
+		case seen[s.Name]:
+			fail(field, "duplicate server name %q", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	for i, h := range cfg.Webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		if err := h.Validate(); err != nil {
+			fail(field, "%v", err)
+		}
+		if _, err := decryptConfigSecret(h.Secret); err != nil {
+			fail(field+".secret", "%v", err)
+		}
+	}
+	if err := cfg.SpeechOutput.validate(cfg.TTS); err != nil {
+		fail("speech_output", "%v", err)
+	}
+	if err := cfg.Approvals.validate(); err != nil {
+		fail("approvals", "%v", err)
+	}
+	if err := cfg.Documents.validate(); err != nil {
+		fail("documents", "%v", err)
+	}
+	if err := validateRetry(cfg.LLMRetry); err != nil {
+		fail("llm_retry", "%v", err)
+	}
+	if err := cfg.LLMCache.validate(); err != nil {
+		fail("llm_cache", "%v", err)
+	}
+	if err := cfg.SkillGeneration.validate(); err != nil {
+		fail("skill_generation", "%v", err)
+	}
+	if err := cfg.Sandbox.validate(); err != nil {
+		fail("sandbox", "%v", err)
+	} else if lang := cfg.SkillGeneration.Language; cfg.Sandbox.runtime() == "wasm" && !cfg.SkillGeneration.Disabled {
+		if lang == "" {
+			lang = "python"
+		}
+		if _, ok := cfg.Sandbox.WasmInterpreters[lang]; !ok {
+			fail("sandbox", "wasm_interpreters has no %s interpreter for the generated skills", lang)
+		}
+	}
+	for i, fb := range cfg.Fallbacks {
+		if err := fb.validate(); err != nil {
+			fail(fmt.Sprintf("fallbacks[%d]", i), "%v", err)
+		}
+	}
+	seenHooks := map[string]bool{}
+	for i, h := range cfg.InboundWebhooks {
+		field := fmt.Sprintf("inbound_webhooks[%d]", i)
+		if err := h.Validate(); err != nil {
+			fail(field, "%v", err)
+		}
+		if seenHooks[h.Name] {
+			fail(field, "duplicate webhook name %q", h.Name)
+		}
+		seenHooks[h.Name] = true
+		if _, err := decryptConfigSecret(h.Secret); err != nil {
+			fail(field+".secret", "%v", err)
+		}
+	}
+	team := make([]security.TeamMember, 0, len(cfg.Team))
+	for i, m := range cfg.Team {
+		v, err := decryptConfigSecret(m.Token)
+		if err != nil {
+			fail(fmt.Sprintf("team[%d].token", i), "%v", err)
+			continue
+		}
+		m.Token = v
+		team = append(team, m)
+	}
+	if err := security.ValidateTeam(team); err != nil {
+		fail("team", "%v", err)
+	}
+	if len(cfg.Team) > 0 && cfg.AdminToken == "" && getenv("OVERHUMAN_ADMIN_TOKEN") == "" {
+		warn("team", "no admin_token: local clients (and anything proxied from localhost) are admins")
+	}
+	if err := cfg.HTTP.Validate(); err != nil {
+		fail("http", "%v", err)
+	}
+	for field, secret := range map[string]string{
+		"notion.token":        cfg.Notion.Token,
+		"issues.github.token": cfg.Issues.GitHub.Token,
+		"issues.jira.token":   cfg.Issues.Jira.Token,
+	} {
+		if _, err := decryptConfigSecret(secret); err != nil {
+			fail(field, "%v", err)
+		}
+	}
+	return issues
+}
+
+// printConfigIssues prints issues sorted by field and returns the number
+// of errors (warnings excluded).
+func printConfigIssues(issues []configIssue) int {
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Field < issues[j].Field })
+	errs := 0
+	for _, is := range issues {
+		mark := "⚠"
+		if !is.Warn {
+			mark = "✗"
+			errs++
+		}
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", mark, is.Field, is.Msg)
+	}
+	return errs
+}
+
+// withEnvAPIKey returns cfg with the API key the daemon would use: the
+// configured one, else LLM_API_KEY or the provider's own variable.
+func withEnvAPIKey(cfg *persistedConfig, getenv func(string) string) *persistedConfig {
+	if cfg.APIKey != "" {
+		return cfg
+	}
+	c := *cfg
+	c.APIKey = getenv("LLM_API_KEY")
+	if c.APIKey == "" && providerKeyEnv[cfg.Provider] != "" {
+		c.APIKey = getenv(providerKeyEnv[cfg.Provider])
+	}
+	return &c
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/skills"
+	"github.com/overhuman/overhuman/internal/webhook"
+)
+
+func TestParseSetupArgs(t *testing.T) {
+	opts, err := parseSetupArgs([]string{"--provider", "openai", "--api-key-env=OPENAI_API_KEY", "--model", "gpt-4o", "--yes"})
+	if err != nil {
+		t.Fatalf("parseSetupArgs: %v", err)
+	}
+	if opts.Provider != "openai" || opts.APIKeyEnv != "OPENAI_API_KEY" || opts.Model != "gpt-4o" || !opts.Yes {
+		t.Errorf("opts = %+v", opts)
+	}
+	for _, args := range [][]string{
+		{"--model", "gpt-4o"},          // no provider
+		{"--provider", "skynet"},       // unknown provider
+		{"--provider", "openai", "-x"}, // unknown flag
+		{"--provider"},                 // missing value
+	} {
+		if _, err := parseSetupArgs(args); err == nil {
+			t.Errorf("parseSetupArgs(%q) succeeded", args)
+		}
+	}
+}
+
+func TestApplySetup(t *testing.T) {
+	env := map[string]string{"OPENAI_API_KEY": "sk-test"}
+	cfg := &persistedConfig{Provider: "claude", APIKey: "old", Model: "claude-x", Name: "Jarvis", ActiveHours: "08:00-22:00"}
+	err := applySetup(cfg, setupOptions{Provider: "openai", APIKeyEnv: "OPENAI_API_KEY", Model: "gpt-4o"}, func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatalf("applySetup: %v", err)
+	}
+	if cfg.Provider != "openai" || cfg.APIKey != "sk-test" || cfg.Model != "gpt-4o" {
+		t.Errorf("provider settings = %+v", cfg)
+	}
+	if cfg.Name != "Jarvis" || cfg.ActiveHours != "08:00-22:00" {
+		t.Errorf("unrelated settings were not kept: %+v", cfg)
+	}
+
+	// Switching provider without a key flag must not keep the old key.
+	if err := applySetup(cfg, setupOptions{Provider: "ollama"}, func(string) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "" || cfg.Model != "" {
+		t.Errorf("old provider settings kept: %+v", cfg)
+	}
+	if err := applySetup(cfg, setupOptions{Provider: "groq", APIKeyEnv: "GROQ_KEY"}, func(string) string { return "" }); err == nil {
+		t.Error("unset --api-key-env variable accepted")
+	}
+
+	if err := applySetup(cfg, setupOptions{Provider: "ollama", DailyBudget: "2.50", MonthlyBudget: "$40"}, func(string) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DailyBudgetUSD != 2.5 || cfg.MonthlyBudgetUSD != 40 {
+		t.Errorf("budget = %v / %v", cfg.DailyBudgetUSD, cfg.MonthlyBudgetUSD)
+	}
+	if err := applySetup(cfg, setupOptions{Provider: "ollama", DailyBudget: "-1"}, func(string) string { return "" }); err == nil {
+		t.Error("negative budget accepted")
+	}
+}
+
+func TestDecodeConfigStrict(t *testing.T) {
+	if _, err := decodeConfigStrict([]byte(`{"provider": "openai", "modle": "gpt-4o"}`)); err == nil || !strings.Contains(err.Error(), "modle") {
+		t.Errorf("typo not reported: %v", err)
+	}
+	cfg, err := decodeConfigStrict([]byte(`{"provider": "ollama", "model": "llama3.3"}`))
+	if err != nil || cfg.Model != "llama3.3" {
+		t.Errorf("decodeConfigStrict = %+v, %v", cfg, err)
+	}
+}
+
+func TestCheckPersistedConfig(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	if issues := checkPersistedConfig(&persistedConfig{Provider: "ollama", Timezone: "Europe/Berlin"}, noEnv); len(issues) != 0 {
+		t.Errorf("valid config: %+v", issues)
+	}
+
+	cfg := &persistedConfig{
+		Provider:        "openai",
+		BaseURL:         "localhost:11434",
+		APIAddr:         "9090",
+		Timezone:        "Mars/Olympus",
+		ActiveHours:     "late",
+		KeyExpiry:       map[string]string{"openai": "next year"},
+		Senses:          map[string]senseSettings{"fax": {}, "email": {MemoryVisibility: "everyone"}, "slack": {Preprocess: &senses.PreprocessConfig{Replace: []senses.ReplaceRule{{Pattern: "[a-"}}}}},
+		Templates:       map[string]string{"empty": " "},
+		Webhooks:        []webhook.Config{{URL: "https://hooks.example.com", Events: []string{"task.started"}}},
+		InboundWebhooks: []senses.InboundWebhook{{Name: "gh", Secret: "s"}, {Name: "gh", Secret: "s"}},
+		Approvals:       approvalSettings{Permissions: []string{"sudo"}},
+		SpeechOutput:    speechOutputSettings{Enabled: true},
+		Documents:       documentSettings{Sources: []string{"fax"}},
+		Fallbacks:       []fallbackSettings{{Model: "gpt-4o-mini"}},
+		LLMRetry:        brain.RetryPolicy{MaxRetries: -2},
+		LLMCache:        llmCacheSettings{TTL: "forever"},
+		SkillGeneration: skillGenSettings{Language: "cobol"},
+		Sandbox:         sandboxSettings{TimeoutS: -1},
+		Kiosk:           kioskSettings{Accent: "red; }"},
+		HTTP:            skills.HTTPRequestConfig{Endpoints: map[string]skills.HTTPEndpoint{"hook": {Method: "DELETE", URL: "https://hooks.example.com"}}},
+		AdminToken:      "admin-secret",
+		Team:            []security.TeamMember{{Name: "ben", Role: security.RoleMember, Token: "short"}},
+
+		DailyBudgetUSD:   50,
+		MonthlyBudgetUSD: 20,
+	}
+	issues := checkPersistedConfig(cfg, noEnv)
+	fields := map[string]bool{}
+	errs := 0
+	for _, is := range issues {
+		fields[is.Field] = true
+		if !is.Warn {
+			errs++
+		}
+	}
+	for _, f := range []string{"api_key", "base_url", "api_addr", "timezone/locale", "active_hours", "key_expiry.openai", "senses.fax", "senses.email.memory_visibility", "templates.empty", "webhooks[0]", "kiosk", "senses.slack.preprocess", "team", "daily_budget_usd", "inbound_webhooks[1]", "approvals", "speech_output", "documents", "fallbacks[0]", "llm_retry", "llm_cache", "skill_generation", "sandbox", "http"} {
+		if !fields[f] {
+			t.Errorf("no issue for %s in %+v", f, issues)
+		}
+	}
+	if errs != len(issues)-1 {
+		t.Errorf("errors = %d of %d, want all but the empty-template warning", errs, len(issues))
+	}
+
+	// The key may come from the environment instead.
+	env := func(k string) string {
+		if k == "OPENAI_API_KEY" {
+			return "sk-env"
+		}
+		return ""
+	}
+	for _, is := range checkPersistedConfig(&persistedConfig{Provider: "openai"}, env) {
+		t.Errorf("key from env: unexpected issue %+v", is)
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/events"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// skillGenSettings is the "skill_generation" block of config.json: with
+// the sandbox enabled, tasks repeated AutoThreshold times get a generated
+// code skill, e.g. {"language": "javascript"}.
+type skillGenSettings struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Language of the generated programs: python (default), javascript or
+	// bash.
+	Language string `json:"language,omitempty"`
+}
+
+// skillLanguages maps the languages skills are generated in to the
+// extension of their source file.
+var skillLanguages = map[string]string{"python": "py", "javascript": "js", "bash": "sh"}
+
+// validate checks the language.
+func (s skillGenSettings) validate() error {
+	if _, ok := skillLanguages[s.Language]; s.Language != "" && !ok {
+		return fmt.Errorf("language %q is not python, javascript or bash", s.Language)
+	}
+	return nil
+}
+
+const (
+	// skillGenAuthor signs the manifests of generated skills.
+	skillGenAuthor = "overhuman-generator"
+	// skillGenMinBudget is what generating a skill may cost at most.
+	skillGenMinBudget = 0.05
+	// skillGenRetryAfter is how long a pattern whose skill could not be
+	// generated waits before it is tried again.
+	skillGenRetryAfter = 24 * time.Hour
+)
+
+// generatedSkill is the skill.json of a generated skill; its program is
+// next to it in main.<ext>.
+type generatedSkill struct {
+	Meta     instruments.SkillMeta  `json:"meta"`
+	Manifest security.SkillManifest `json:"manifest"`
+	Language string                 `json:"language"`
+}
+
+// skillForge turns repeated tasks into code skills. When a pattern becomes
+// automatable (events.PatternTriggered), the generator writes a program
+// for it; the program is checked by the skill validator, saved under
+// skills/generated in the data directory and registered as a TRIAL skill,
+// so the next matching task runs it in the sandbox. A skill that fails
+// falls back to the LLM like any other.
+type skillForge struct {
+	gen       *instruments.Generator
+	validator *security.SkillValidator
+	skills    *instruments.SkillRegistry
+	patterns  *memory.PatternTracker
+	spend     *budget.Tracker // nil = unlimited
+	bus       *events.Bus
+	audit     *security.AuditLogger // nil = not audited
+	// executor runs the program of a manifest; sandboxExecutor in the
+	// daemon.
+	executor func(m security.SkillManifest, language, code string) instruments.SkillExecutor
+	limits   instruments.SandboxConfig
+	language string
+	dir      string
+
+	mu     sync.Mutex
+	busy   map[string]bool      // fingerprints being generated
+	failed map[string]time.Time // fingerprints whose generation failed, and when
+}
+
+// createSkillForge returns the forge of generated skills, running them in
+// deps.Sandbox. Skills are only generated (deps.Generator is set) with a
+// sandbox to run them; without one, those generated before are still
+// loaded, but denied.
+func createSkillForge(cfg Config, deps *pipeline.Dependencies) *skillForge {
+	if deps.Sandbox != nil && !cfg.SkillGeneration.Disabled {
+		deps.Generator = instruments.NewGenerator(deps.LLM, deps.Router, deps.Context)
+	}
+	f := newSkillForge(deps.Generator, deps.Skills, deps.Patterns, filepath.Join(cfg.DataDir, "skills", "generated"), cfg.SkillGeneration.Language, cfg.Sandbox.config())
+	f.executor = sandboxExecutor(deps.Sandbox, deps.AuditLog)
+	f.spend, f.bus, f.audit = deps.Budget, deps.Events, deps.AuditLog
+	deps.Skills.OnRemove(f.forget)
+	return f
+}
+
+// newSkillForge creates a forge saving its skills in dir. Programs are
+// written in language (default python) by gen (nil = none are generated)
+// and run by executor, which must be set, within limits.
+func newSkillForge(gen *instruments.Generator, skills *instruments.SkillRegistry, patterns *memory.PatternTracker, dir, language string, limits instruments.SandboxConfig) *skillForge {
+	if language == "" {
+		language = "python"
+	}
+	return &skillForge{
+		gen:       gen,
+		validator: security.NewSkillValidator(security.ValidatorConfig{TrustedAuthors: []string{skillGenAuthor}}),
+		skills:    skills,
+		patterns:  patterns,
+		limits:    limits,
+		language:  language,
+		dir:       dir,
+		busy:      make(map[string]bool),
+		failed:    make(map[string]time.Time),
+	}
+}
+
+// Subscribe generates skills for the patterns bus reports automatable,
+// in the background.
+func (f *skillForge) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.PatternTriggered, func(ev events.Event) {
+		pe := ev.Data.(pipeline.PatternEvent)
+		go func() {
+			if _, err := f.Forge(context.Background(), pe); err != nil {
+				log.Printf("[skillgen] %s: %v", pe.Fingerprint, err)
+			}
+		}()
+	})
+}
+
+// Forge generates, validates, saves and registers a code skill for the
+// pattern of pe. It returns nil without error when the forge has no
+// generator, or the pattern already has a skill, is being generated, or
+// failed recently.
+func (f *skillForge) Forge(ctx context.Context, pe pipeline.PatternEvent) (*instruments.Skill, error) {
+	fp := pe.Fingerprint
+	if f.gen == nil {
+		return nil, nil
+	}
+	if entry, err := f.patterns.Get(fp); err != nil || entry.SkillID != "" {
+		return nil, err
+	}
+	f.mu.Lock()
+	if f.busy[fp] || time.Since(f.failed[fp]) < skillGenRetryAfter {
+		f.mu.Unlock()
+		return nil, nil
+	}
+	f.busy[fp] = true
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		delete(f.busy, fp)
+		f.mu.Unlock()
+	}()
+
+	skill, err := f.forge(ctx, pe)
+	if err != nil {
+		f.mu.Lock()
+		f.failed[fp] = time.Now()
+		f.mu.Unlock()
+		if f.audit != nil {
+			f.audit.LogError(security.AuditSkillCreate, "skillgen", "system", "generate", fp, err.Error(), map[string]string{"goal": pe.Goal})
+		}
+	}
+	return skill, err
+}
+
+func (f *skillForge) forge(ctx context.Context, pe pipeline.PatternEvent) (*instruments.Skill, error) {
+	if f.spend != nil && !f.spend.CanSpend(skillGenMinBudget) {
+		return nil, fmt.Errorf("not enough budget left to generate a skill")
+	}
+	spec := instruments.CodeSpec{
+		Goal:        pe.Goal,
+		InputDesc:   "a request like the goal, in the goal field of the input",
+		OutputDesc:  "the answer to the request, as plain text",
+		Examples:    []string{pe.Goal},
+		Language:    f.language,
+		Fingerprint: pe.Fingerprint,
+	}
+	code, cost, err := f.gen.Generate(ctx, spec)
+	if f.spend != nil && cost > 0 {
+		f.spend.Record("skillgen_"+pe.Fingerprint, cost)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	id := fmt.Sprintf("skill_gen_%d", now.UnixNano())
+	manifest := f.manifest(id, code.Language, code.Code, now)
+	if err := f.validate(manifest); err != nil {
+		return nil, err
+	}
+	gs := generatedSkill{
+		Meta: instruments.SkillMeta{
+			ID:          id,
+			Name:        "Auto: " + truncateText(pe.Goal, 50),
+			Description: pe.Goal,
+			Type:        instruments.SkillTypeCode,
+			Status:      instruments.SkillStatusTrial,
+			Fingerprint: pe.Fingerprint,
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Doc:         instruments.DocumentGenerated(spec, code),
+		},
+		Manifest: manifest,
+		Language: code.Language,
+	}
+	if err := f.save(gs, code); err != nil {
+		return nil, err
+	}
+	skill := f.register(gs, code.Code)
+	if err := f.patterns.LinkSkill(pe.Fingerprint, id); err != nil {
+		log.Printf("[skillgen] link %s to %s: %v", pe.Fingerprint, id, err)
+	}
+	if f.audit != nil {
+		f.audit.Log(security.AuditSkillCreate, security.SeverityInfo, "skillgen", "system", "generate", id, true,
+			map[string]string{"fingerprint": pe.Fingerprint, "language": code.Language, "signature": manifest.Signature})
+	}
+	f.bus.Publish(events.SkillCreated, pipeline.SkillEvent{
+		SkillID: id,
+		To:      instruments.SkillStatusTrial,
+		Trigger: "pattern " + pe.Fingerprint,
+		Reason:  fmt.Sprintf("generated for the repeated task %q", truncateText(pe.Goal, 80)),
+	})
+	log.Printf("[skillgen] %s: %s skill %s ($%.4f)", pe.Fingerprint, code.Language, id, cost)
+	return skill, nil
+}
+
+// manifest describes a generated program for the validator: signed, run
+// within the sandbox limits, writing only to its working directory.
+func (f *skillForge) manifest(id, language, code string, created time.Time) security.SkillManifest {
+	return security.SkillManifest{
+		SkillID:   id,
+		Name:      id,
+		Version:   1,
+		Author:    skillGenAuthor,
+		Signature: security.ComputeSignature(code),
+		Permissions: security.SkillPermissions{
+			FileWrite:    true,
+			EnvVars:      []string{instruments.SkillInputEnv},
+			AllowedPaths: []string{f.limits.WorkDir},
+		},
+		Dependencies: codeImports(language, code),
+		MaxMemoryMB:  f.limits.MemoryMB,
+		MaxCPU:       f.limits.CPUs,
+		MaxTimeoutS:  int(f.limits.Timeout.Seconds()),
+		NetworkAllow: f.limits.NetworkMode != "none",
+		CreatedAt:    created,
+	}
+}
+
+// validate rejects manifests the validator refuses. Generated code is
+// unreviewed, so suspicious imports (subprocess, sockets, ...) reject it
+// too.
+func (f *skillForge) validate(m security.SkillManifest) error {
+	res := f.validator.Validate(m)
+	for _, w := range res.Warnings {
+		if strings.HasPrefix(w, "suspicious dependency") {
+			res.Errors = append(res.Errors, w)
+		}
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("skill %s rejected: %s", m.SkillID, strings.Join(res.Errors, "; "))
+	}
+	return nil
+}
+
+// save writes the skill to dir/<id>: skill.json, the program and its tests.
+func (f *skillForge) save(gs generatedSkill, code *instruments.GeneratedCode) error {
+	dir := filepath.Join(f.dir, gs.Meta.ID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("save skill: %w", err)
+	}
+	data, err := json.MarshalIndent(gs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save skill: %w", err)
+	}
+	ext := skillLanguages[gs.Language]
+	files := map[string]string{"skill.json": string(data), "main." + ext: code.Code}
+	if code.Tests != "" {
+		files["test_main."+ext] = code.Tests
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			return fmt.Errorf("save skill: %w", err)
+		}
+	}
+	return nil
+}
+
+// register adds a generated skill to the registry.
+func (f *skillForge) register(gs generatedSkill, code string) *instruments.Skill {
+	skill := &instruments.Skill{
+		Meta:     gs.Meta,
+		Executor: instruments.NewCodeSkill(f.executor(gs.Manifest, gs.Language, code).Execute, gs.Language, code),
+	}
+	f.skills.Register(skill)
+	return skill
+}
+
+// forget deletes the saved files of a generated skill removed from the
+// registry, so it doesn't come back on restart. Its pattern stays linked
+// to it, so the task isn't automated again.
+func (f *skillForge) forget(m instruments.SkillMeta) {
+	dir := filepath.Join(f.dir, m.ID)
+	if m.ID == "" || filepath.Base(dir) != m.ID {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skill.json")); err != nil {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("[skillgen] delete %s: %v", m.ID, err)
+		return
+	}
+	log.Printf("[skillgen] deleted %s", m.ID)
+}
+
+// Load registers the skills saved in dir. Their status and fitness come
+// from catalog, the registry's last catalog, when it lists them. Skills
+// whose program no longer matches its signature, or that the validator
+// refuses, are skipped.
+func (f *skillForge) Load(catalog []instruments.SkillMeta) (int, []error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, []error{err}
+	}
+	known := make(map[string]instruments.SkillMeta, len(catalog))
+	for _, m := range catalog {
+		known[m.ID] = m
+	}
+	n := 0
+	var errs []error
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		gs, code, err := f.read(filepath.Join(f.dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+		if m, ok := known[gs.Meta.ID]; ok {
+			gs.Meta = m
+		}
+		f.register(gs, code)
+		n++
+	}
+	return n, errs
+}
+
+// read loads and checks the skill saved in dir.
+func (f *skillForge) read(dir string) (generatedSkill, string, error) {
+	var gs generatedSkill
+	data, err := os.ReadFile(filepath.Join(dir, "skill.json"))
+	if err != nil {
+		return gs, "", err
+	}
+	if err := json.Unmarshal(data, &gs); err != nil {
+		return gs, "", fmt.Errorf("skill.json: %w", err)
+	}
+	ext, ok := skillLanguages[gs.Language]
+	if !ok {
+		return gs, "", fmt.Errorf("unknown language %q", gs.Language)
+	}
+	code, err := os.ReadFile(filepath.Join(dir, "main."+ext))
+	if err != nil {
+		return gs, "", err
+	}
+	if !f.validator.VerifySignature(gs.Manifest, string(code)) {
+		return gs, "", fmt.Errorf("main.%s was changed after it was generated", ext)
+	}
+	if err := f.validate(gs.Manifest); err != nil {
+		return gs, "", err
+	}
+	return gs, string(code), nil
+}
+
+var (
+	pythonImportRe = regexp.MustCompile(`(?m)^\s*(?:from\s+([\w.]+)\s+import|import\s+([\w., ]+))`)
+	jsImportRe     = regexp.MustCompile(`require\(\s*['"]([^'"]+)['"]\s*\)|from\s+['"]([^'"]+)['"]`)
+)
+
+// codeImports lists the modules a program imports, for the validator's
+// dependency check.
+func codeImports(language, code string) []string {
+	var re *regexp.Regexp
+	switch language {
+	case "python":
+		re = pythonImportRe
+	case "javascript":
+		re = jsImportRe
+	default:
+		return nil
+	}
+	var deps []string
+	for _, m := range re.FindAllStringSubmatch(code, -1) {
+		for _, group := range m[1:] {
+			for _, dep := range strings.Split(group, ",") {
+				if f := strings.Fields(dep); len(f) > 0 { // "json as j" → json
+					deps = append(deps, f[0])
+				}
+			}
+		}
+	}
+	return deps
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestSkillForge(t *testing.T) {
+	ltm, err := memory.NewLongTermMemory(filepath.Join(t.TempDir(), "overhuman.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ltm.Close()
+	pt, err := memory.NewPatternTracker(ltm.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := brain.NewModelRouter()
+	router.SetProvider("openai")
+	llm := brain.NewFakeProvider(brain.FakeConfig{Rules: []brain.FakeRule{
+		{Match: "word count", Response: "CODE_START\nimport json, os\nprint(len(json.loads(os.environ['SKILL_INPUT'])['goal'].split()))\nCODE_END\nTESTS_START\nassert True\nTESTS_END"},
+		{Match: "shell", Response: "CODE_START\nimport subprocess\nsubprocess.run(['ls'])\nCODE_END"},
+	}})
+	gen := instruments.NewGenerator(llm, router, brain.NewContextAssembler())
+	dir := filepath.Join(t.TempDir(), "generated")
+	ran := ""
+	newForge := func(reg *instruments.SkillRegistry) *skillForge {
+		f := newSkillForge(gen, reg, pt, dir, "", instruments.DefaultSandboxConfig())
+		f.executor = func(m security.SkillManifest, language, code string) instruments.SkillExecutor {
+			return instruments.NewCodeSkill(func(ctx context.Context, in instruments.SkillInput) (*instruments.SkillOutput, error) {
+				ran = language
+				return &instruments.SkillOutput{Result: "4", Success: true}, nil
+			}, language, code)
+		}
+		return f
+	}
+
+	reg := instruments.NewSkillRegistry()
+	f := newForge(reg)
+	pt.Record("fp-count", "word count of a sentence", 0.9)
+	skill, err := f.Forge(context.Background(), pipeline.PatternEvent{Fingerprint: "fp-count", Goal: "word count of a sentence"})
+	if err != nil || skill == nil {
+		t.Fatalf("Forge = %v, %v", skill, err)
+	}
+	if skill.Meta.Status != instruments.SkillStatusTrial || reg.FindActive("fp-count") != skill {
+		t.Errorf("skill not routed: %+v", skill.Meta)
+	}
+	if e, _ := pt.Get("fp-count"); e.SkillID != skill.Meta.ID {
+		t.Errorf("pattern linked to %q", e.SkillID)
+	}
+	if out, err := skill.Executor.Execute(context.Background(), instruments.SkillInput{Goal: "a b c d"}); err != nil || out.Result != "4" || ran != "python" {
+		t.Errorf("Execute = %+v, %v (ran %q)", out, err, ran)
+	}
+	for _, name := range []string{"skill.json", "main.py", "test_main.py"} {
+		if _, err := os.Stat(filepath.Join(dir, skill.Meta.ID, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	if again, err := f.Forge(context.Background(), pipeline.PatternEvent{Fingerprint: "fp-count", Goal: "word count"}); again != nil || err != nil {
+		t.Errorf("linked pattern generated again: %v, %v", again, err)
+	}
+
+	// Generated code importing subprocess is rejected and not retried soon.
+	pt.Record("fp-shell", "run a shell command", 0.9)
+	if _, err := f.Forge(context.Background(), pipeline.PatternEvent{Fingerprint: "fp-shell", Goal: "run a shell command"}); err == nil || !strings.Contains(err.Error(), "subprocess") {
+		t.Errorf("suspicious skill: err = %v", err)
+	}
+	if s, err := f.Forge(context.Background(), pipeline.PatternEvent{Fingerprint: "fp-shell", Goal: "run a shell command"}); s != nil || err != nil {
+		t.Errorf("failed pattern retried at once: %v, %v", s, err)
+	}
+
+	// A restart loads the skill with its last status; a tampered one is skipped.
+	meta := skill.Meta
+	meta.Status = instruments.SkillStatusActive
+	reloaded := instruments.NewSkillRegistry()
+	if n, errs := newForge(reloaded).Load([]instruments.SkillMeta{meta}); n != 1 || len(errs) != 0 {
+		t.Fatalf("Load = %d, %v", n, errs)
+	}
+	if s := reloaded.Get(skill.Meta.ID); s == nil || s.Meta.Status != instruments.SkillStatusActive {
+		t.Errorf("reloaded skill = %+v", s)
+	}
+	os.WriteFile(filepath.Join(dir, skill.Meta.ID, "main.py"), []byte("print('hi')\n"), 0o600)
+	if n, errs := newForge(instruments.NewSkillRegistry()).Load(nil); n != 0 || len(errs) != 1 {
+		t.Errorf("tampered Load = %d, %v", n, errs)
+	}
+
+	// A deleted skill's files go too.
+	f.forget(skill.Meta)
+	if _, err := os.Stat(filepath.Join(dir, skill.Meta.ID)); !os.IsNotExist(err) {
+		t.Errorf("deleted skill still saved: %v", err)
+	}
+}
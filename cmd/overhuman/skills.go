@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/netaddr"
+)
+
+// skillsUsage lists the subcommands of skillsCommand.
+const skillsUsage = "usage: skills [list|show ID|enable ID|disable ID|delete ID|templates]"
+
+// errDaemonDown is returned by skillsCommand and goalsCommand when the
+// daemon can't be reached.
+var errDaemonDown = errors.New("daemon is not answering")
+
+// skillsCommand runs a skills command against the daemon's kiosk server
+// at addr and returns what to print:
+//
+//	list          the skills with their status, fitness and savings
+//	show ID       a skill's documentation, fitness and source
+//	enable ID     make a skill ACTIVE
+//	disable ID    deprecate a skill, so tasks no longer use it
+//	delete ID     remove a skill (a generated one for good)
+func skillsCommand(client *http.Client, addr, adminToken string, args []string) (string, error) {
+	sub := "list"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	if sub == "info" {
+		sub = "show"
+	}
+	call := func(method, path string, out any) error {
+		return daemonCall(client, addr, adminToken, method, path, nil, out)
+	}
+	// savings returns the all-time savings of each skill; the skills are
+	// shown without them if the report fails.
+	savings := func() map[string]memory.SkillSavings {
+		var rep costsReport
+		if call(http.MethodGet, "/api/costs", &rep) != nil {
+			return nil
+		}
+		bySkill := make(map[string]memory.SkillSavings, len(rep.AllTime.Skills))
+		for _, s := range rep.AllTime.Skills {
+			bySkill[s.SkillID] = s
+		}
+		return bySkill
+	}
+
+	switch {
+	case sub == "list":
+		var res struct {
+			Skills []instruments.SkillMeta `json:"skills"`
+		}
+		if err := call(http.MethodGet, "/api/skills", &res); err != nil {
+			return "", err
+		}
+		return formatSkills(res.Skills, savings()), nil
+	case sub == "show" && len(args) > 1:
+		var detail struct {
+			instruments.SkillMeta
+			Source string `json:"source"`
+		}
+		if err := call(http.MethodGet, "/api/skills/"+url.PathEscape(args[1]), &detail); err != nil {
+			return "", err
+		}
+		out := instruments.FormatSkillDoc(detail.SkillMeta)
+		if s, ok := savings()[detail.ID]; ok {
+			out += fmt.Sprintf("\nSaved: $%.4f and %.1fs over %d runs, against the LLM\n", s.SavedCostUSD, s.SavedMs/1000, s.Runs)
+		}
+		if detail.Source != "" {
+			out += "\nSource:\n" + detail.Source
+		}
+		return strings.TrimRight(out, "\n"), nil
+	case (sub == "enable" || sub == "disable") && len(args) > 1:
+		var meta instruments.SkillMeta
+		if err := call(http.MethodPost, "/api/skills/"+url.PathEscape(args[1])+"/"+sub, &meta); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s is now %s", meta.ID, meta.Status), nil
+	case sub == "delete" && len(args) > 1:
+		var res map[string]string
+		if err := call(http.MethodDelete, "/api/skills/"+url.PathEscape(args[1]), &res); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s deleted (built-in and template skills come back on restart; disable them instead)", args[1]), nil
+	}
+	return "", fmt.Errorf("%s", skillsUsage)
+}
+
+// daemonCall sends method path to the daemon at addr, with body as JSON
+// unless it is nil, and decodes the JSON reply into out. The daemon's
+// {"error": ...} replies come back as errors.
+func daemonCall(client *http.Client, addr, adminToken, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, _ := http.NewRequest(method, netaddr.URL(addr, path), reqBody)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if adminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w at %s: %v", errDaemonDown, addr, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s", e.Error)
+		}
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("bad response: %w", err)
+	}
+	return nil
+}
+
+// formatSkills lists skills one per line for the CLI, with their fitness
+// and what they saved.
+func formatSkills(list []instruments.SkillMeta, savings map[string]memory.SkillSavings) string {
+	if len(list) == 0 {
+		return "No skills registered."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-24s %-6s %-10s %6s %8s %9s  %s\n", "ID", "TYPE", "STATUS", "RUNS", "SUCCESS", "SAVED", "NAME")
+	for _, m := range list {
+		success, saved := "-", "-"
+		if m.TotalRuns > 0 {
+			success = fmt.Sprintf("%.0f%%", m.SuccessRate*100)
+		}
+		if s, ok := savings[m.ID]; ok {
+			saved = fmt.Sprintf("$%.4f", s.SavedCostUSD)
+		}
+		fmt.Fprintf(&b, "%-24s %-6s %-10s %6d %8s %9s  %s\n", m.ID, m.Type, m.Status, m.TotalRuns, success, saved, m.Name)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// skillCatalogCommand answers list and show from the catalog the daemon
+// last wrote to path, for when it isn't running.
+func skillCatalogCommand(path string, args []string) (string, error) {
+	metas, err := instruments.LoadCatalog(path)
+	if err != nil {
+		return "", fmt.Errorf("%v\n(the catalog is written when overhuman starts)", err)
+	}
+	if len(args) == 0 || args[0] == "list" {
+		return formatSkills(metas, nil), nil
+	}
+	for _, m := range metas {
+		if m.ID == args[1] {
+			return strings.TrimRight(instruments.FormatSkillDoc(m), "\n"), nil
+		}
+	}
+	return "", fmt.Errorf("skill %q not found", args[1])
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+func TestSkillsCommand(t *testing.T) {
+	reg := instruments.NewSkillRegistry()
+	reg.Register(&instruments.Skill{
+		Meta: instruments.SkillMeta{ID: "skill_gen_1", Name: "Auto: word count", Type: instruments.SkillTypeCode, Status: instruments.SkillStatusTrial, TotalRuns: 4, SuccessRate: 0.75},
+		Executor: instruments.NewCodeSkill(func(ctx context.Context, in instruments.SkillInput) (*instruments.SkillOutput, error) {
+			return nil, nil
+		}, "python", "print(len(input().split()))"),
+	})
+	mux := http.NewServeMux()
+	reg.RegisterRoutes(mux)
+	mux.HandleFunc("GET /api/costs", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(costsReport{AllTime: memory.SavingsReport{Skills: []memory.SkillSavings{{SkillID: "skill_gen_1", Runs: 3, SavedCostUSD: 0.0123, SavedMs: 4500}}}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	run := func(args ...string) (string, error) {
+		return skillsCommand(srv.Client(), srv.Listener.Addr().String(), "", args)
+	}
+
+	out, err := run()
+	if err != nil || !strings.Contains(out, "skill_gen_1") || !strings.Contains(out, "75%") || !strings.Contains(out, "$0.0123") {
+		t.Errorf("list = %q, %v", out, err)
+	}
+	out, err = run("show", "skill_gen_1")
+	if err != nil || !strings.Contains(out, "Saved: $0.0123 and 4.5s over 3 runs") || !strings.HasSuffix(out, "print(len(input().split()))") {
+		t.Errorf("show = %q, %v", out, err)
+	}
+	if out, err = run("disable", "skill_gen_1"); err != nil || out != "skill_gen_1 is now DEPRECATED" {
+		t.Errorf("disable = %q, %v", out, err)
+	}
+	if out, err = run("enable", "skill_gen_1"); err != nil || out != "skill_gen_1 is now ACTIVE" {
+		t.Errorf("enable = %q, %v", out, err)
+	}
+	if _, err = run("delete", "skill_gen_1"); err != nil || reg.Get("skill_gen_1") != nil {
+		t.Errorf("delete: %v", err)
+	}
+	if _, err = run("show", "skill_gen_1"); err == nil || err.Error() != "skill not found" {
+		t.Errorf("show deleted skill: %v", err)
+	}
+	if _, err = run("enable"); err == nil || err.Error() != skillsUsage {
+		t.Errorf("enable without ID: %v", err)
+	}
+
+	srv.Close()
+	if _, err = run(); !errors.Is(err, errDaemonDown) {
+		t.Errorf("daemon down: %v", err)
+	}
+}
+
+func TestSkillCatalogCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	reg := instruments.NewSkillRegistry()
+	reg.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "s1", Name: "Weather", Type: instruments.SkillTypeLLM, Status: instruments.SkillStatusActive}})
+	if err := reg.SetCatalogPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := skillCatalogCommand(path, nil); err != nil || !strings.Contains(out, "Weather") {
+		t.Errorf("list = %q, %v", out, err)
+	}
+	if out, err := skillCatalogCommand(path, []string{"show", "s1"}); err != nil || !strings.HasPrefix(out, "Weather (s1)") {
+		t.Errorf("show = %q, %v", out, err)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/genui"
+	"github.com/overhuman/overhuman/internal/scheduler"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// speechOutputSettings is the "speech_output" block of config.json: results
+// read out with the "tts" backend, e.g. {"enabled": true, "player": "aplay",
+// "sources": ["voice"]}.
+type speechOutputSettings struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Outbox is the directory receiving an audio file per result. Default:
+	// ~/.overhuman/outbox, unless a player is set.
+	Outbox string `json:"outbox,omitempty"`
+	// Player is the command playing each result, e.g. "aplay" or
+	// "mpv --really-quiet"; the file's path is appended.
+	Player string `json:"player,omitempty"`
+	// Sources limits speech to results for these channels, e.g. ["voice",
+	// "file"]. Default: all but heartbeats.
+	Sources []string `json:"sources,omitempty"`
+	// MaxChars caps how much of a result is read out. Default: 1500.
+	MaxChars int `json:"max_chars,omitempty"`
+}
+
+// validate checks the settings against the configured TTS backend.
+func (s speechOutputSettings) validate(tts brain.SpeechConfig) error {
+	if !s.Enabled {
+		return nil
+	}
+	if tts.Provider == "" {
+		return fmt.Errorf("needs a text-to-speech backend (\"tts\")")
+	}
+	for _, src := range s.Sources {
+		if senses.ParseSourceType(src) == "" {
+			return fmt.Errorf("unknown source %q", src)
+		}
+	}
+	if s.MaxChars < 0 {
+		return fmt.Errorf("max_chars must not be negative")
+	}
+	return nil
+}
+
+// speaks reports whether the result for input is read out.
+func (s speechOutputSettings) speaks(input *senses.UnifiedInput) bool {
+	if len(s.Sources) == 0 {
+		return input.SourceType != senses.SourceTimer || input.SourceMeta.Channel == scheduler.Channel
+	}
+	for _, src := range s.Sources {
+		if senses.ParseSourceType(src) == input.SourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// createSpeechOutput returns the speech output for results, or nil if it
+// is disabled.
+func createSpeechOutput(cfg Config, tts brain.TextToSpeech) (*genui.SpeechOutput, error) {
+	s := cfg.SpeechOutput
+	if !s.Enabled || tts == nil {
+		return nil, nil
+	}
+	outbox := s.Outbox
+	if outbox == "" && s.Player == "" {
+		outbox = filepath.Join(cfg.DataDir, "outbox")
+	}
+	return genui.NewSpeechOutput(genui.SpeechOutputConfig{
+		TTS:       tts,
+		OutboxDir: outbox,
+		Player:    s.Player,
+		MaxChars:  s.MaxChars,
+	})
+}
+
+// speechConfig fills in the API key of a hosted speech backend from the
+// vendor's usual environment variable when none is configured.
+func speechConfig(cfg Config, sc brain.SpeechConfig) brain.SpeechConfig {
+	if sc.APIKey != "" {
+		return sc
+	}
+	switch sc.Provider {
+	case "openai":
+		sc.APIKey = cfg.OpenAIKey
+	case "elevenlabs":
+		sc.APIKey = os.Getenv("ELEVENLABS_API_KEY")
+	}
+	return sc
+}
+
+// createSpeechToText returns the configured transcription backend, or nil
+// if speech-to-text is disabled.
+func createSpeechToText(cfg Config) (brain.SpeechToText, error) {
+	if cfg.STT.Provider == "" {
+		return nil, nil
+	}
+	return brain.NewSpeechToText(speechConfig(cfg, cfg.STT))
+}
+
+// createTextToSpeech returns the configured synthesis backend, or nil if
+// text-to-speech is disabled.
+func createTextToSpeech(cfg Config) (brain.TextToSpeech, error) {
+	if cfg.TTS.Provider == "" {
+		return nil, nil
+	}
+	return brain.NewTextToSpeech(speechConfig(cfg, cfg.TTS))
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/observability"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// Supervisor timing.
+const (
+	restartBackoffMin = time.Second
+	restartBackoffMax = 5 * time.Minute
+	stallCheckEvery   = 30 * time.Second
+	stallGrace        = 30 * time.Second // how long a stalled component gets to stop
+)
+
+// stallCheck reports whether a component has stopped making progress, and
+// why.
+type stallCheck func() (string, bool)
+
+// componentStatus is a supervised component's state, reported by GET /health.
+type componentStatus struct {
+	Name         string    `json:"name"`
+	Started      time.Time `json:"started"`
+	Restarts     int       `json:"restarts"`
+	LastIncident string    `json:"last_incident,omitempty"`
+	LastFailed   time.Time `json:"last_failed,omitempty"`
+}
+
+// supervisor runs the daemon's long-lived components — senses, the WS
+// server, the main processing loop — and restarts one that panics, fails
+// or stalls, so a single broken sense cannot silently take half the daemon
+// down. Incidents go to the audit log and count as "supervisor.restarts"
+// metrics.
+//
+// Panics are caught in the component's own goroutine; goroutines it starts
+// itself must defer Recover.
+type supervisor struct {
+	audit   *security.AuditLogger           // may be nil
+	metrics *observability.MetricsCollector // may be nil
+
+	backoffMin, backoffMax time.Duration
+	checkEvery, grace      time.Duration
+
+	mu     sync.Mutex
+	status map[string]*componentStatus
+}
+
+func newSupervisor(audit *security.AuditLogger, metrics *observability.MetricsCollector) *supervisor {
+	return &supervisor{
+		audit:      audit,
+		metrics:    metrics,
+		backoffMin: restartBackoffMin,
+		backoffMax: restartBackoffMax,
+		checkEvery: stallCheckEvery,
+		grace:      stallGrace,
+		status:     make(map[string]*componentStatus),
+	}
+}
+
+// Go runs the component name in the background until ctx is cancelled.
+// run should block until its context is done; it is restarted, after a
+// growing backoff, when it panics, returns an error or — with a stall
+// check — stops making progress. Returning nil ends it for good.
+func (s *supervisor) Go(ctx context.Context, name string, run func(ctx context.Context) error, stalled stallCheck) {
+	s.mu.Lock()
+	s.status[name] = &componentStatus{Name: name, Started: time.Now()}
+	s.mu.Unlock()
+	go s.supervise(ctx, name, run, stalled)
+}
+
+func (s *supervisor) supervise(ctx context.Context, name string, run func(ctx context.Context) error, stalled stallCheck) {
+	backoff := s.backoffMin
+	for {
+		started := time.Now()
+		incident, ok := s.runOnce(ctx, name, run, stalled)
+		if !ok || ctx.Err() != nil {
+			return
+		}
+		s.fail(name, incident)
+		if time.Since(started) > s.backoffMax {
+			backoff = s.backoffMin // it ran fine for a while
+		}
+		log.Printf("[supervisor] restarting %s in %s", name, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.backoffMax)
+		s.mu.Lock()
+		s.status[name].Started = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// runOnce runs the component until it ends and returns what went wrong;
+// false means it ended cleanly.
+func (s *supervisor) runOnce(ctx context.Context, name string, run func(ctx context.Context) error, stalled stallCheck) (string, bool) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				log.Printf("[supervisor] %s panicked: %v\n%s", name, v, debug.Stack())
+				done <- fmt.Errorf("panic: %v", v)
+			}
+		}()
+		done <- run(runCtx)
+	}()
+
+	var tick <-chan time.Time
+	if stalled != nil {
+		t := time.NewTicker(s.checkEvery)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case err := <-done:
+			if err == nil || ctx.Err() != nil {
+				return "", false
+			}
+			return err.Error(), true
+		case <-tick:
+			reason, ok := stalled()
+			if !ok {
+				continue
+			}
+			log.Printf("[supervisor] %s stalled: %s", name, reason)
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(s.grace):
+				log.Printf("[supervisor] %s did not stop within %s; starting a new one beside it", name, s.grace)
+			}
+			return "stalled: " + reason, true
+		}
+	}
+}
+
+// Recover records a panic in a goroutine of component name and lets the
+// goroutine carry on. Use it as "defer sup.Recover(name)".
+func (s *supervisor) Recover(name string) {
+	if v := recover(); v != nil {
+		log.Printf("[supervisor] %s panicked: %v\n%s", name, v, debug.Stack())
+		s.fail(name, fmt.Sprintf("panic: %v", v))
+	}
+}
+
+// fail records an incident of component name.
+func (s *supervisor) fail(name, incident string) {
+	s.mu.Lock()
+	st, ok := s.status[name]
+	if !ok {
+		st = &componentStatus{Name: name, Started: time.Now()}
+		s.status[name] = st
+	}
+	st.Restarts++
+	st.LastIncident, st.LastFailed = incident, time.Now()
+	restarts := st.Restarts
+	s.mu.Unlock()
+
+	log.Printf("[supervisor] %s failed: %s", name, incident)
+	if s.metrics != nil {
+		s.metrics.Increment("supervisor.restarts")
+		s.metrics.Increment("supervisor.restarts." + name)
+	}
+	if s.audit != nil {
+		s.audit.LogError(security.AuditComponentFail, "daemon", "supervisor", "restart", name, incident,
+			map[string]string{"restarts": strconv.Itoa(restarts)})
+	}
+}
+
+// Status returns the supervised components by name.
+func (s *supervisor) Status() []componentStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]componentStatus, 0, len(s.status))
+	for _, st := range s.status {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
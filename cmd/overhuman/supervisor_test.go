@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/observability"
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func testSupervisor() (*supervisor, *security.AuditLogger, *observability.MetricsCollector) {
+	audit := security.NewAuditLogger(security.NewMemoryAuditStore())
+	metrics := observability.NewMetricsCollector(100)
+	s := newSupervisor(audit, metrics)
+	s.backoffMin, s.backoffMax = time.Millisecond, 10*time.Millisecond
+	s.checkEvery, s.grace = 5*time.Millisecond, 50*time.Millisecond
+	return s, audit, metrics
+}
+
+// waitFor polls cond for up to a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestSupervisor_RestartsOnPanicAndError(t *testing.T) {
+	s, audit, metrics := testSupervisor()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var starts atomic.Int32
+	s.Go(ctx, "telegram", func(ctx context.Context) error {
+		switch starts.Add(1) {
+		case 1:
+			panic("nil map")
+		case 2:
+			return errors.New("connection reset")
+		}
+		<-ctx.Done()
+		return nil
+	}, nil)
+	waitFor(t, "third start", func() bool { return starts.Load() == 3 })
+
+	st := s.Status()
+	if len(st) != 1 || st[0].Name != "telegram" || st[0].Restarts != 2 || st[0].LastIncident != "connection reset" {
+		t.Errorf("Status = %+v", st)
+	}
+	if n := metrics.Counter("supervisor.restarts.telegram"); n != 2 {
+		t.Errorf("restart counter = %d, want 2", n)
+	}
+	events, _ := audit.Query(security.AuditFilter{Type: security.AuditComponentFail})
+	if len(events) != 2 || events[0].Resource != "telegram" {
+		t.Fatalf("audit events = %+v", events)
+	}
+	var errs []string
+	for _, e := range events {
+		errs = append(errs, e.Error)
+	}
+	if got := strings.Join(errs, "|"); !strings.Contains(got, "panic: nil map") || !strings.Contains(got, "connection reset") {
+		t.Errorf("audit errors = %q", got)
+	}
+}
+
+func TestSupervisor_CleanExitIsNotRestarted(t *testing.T) {
+	s, _, _ := testSupervisor()
+	var starts atomic.Int32
+	s.Go(context.Background(), "once", func(context.Context) error {
+		starts.Add(1)
+		return nil
+	}, nil)
+	time.Sleep(30 * time.Millisecond)
+	if n := starts.Load(); n != 1 {
+		t.Errorf("starts = %d, want 1", n)
+	}
+	if st := s.Status(); st[0].Restarts != 0 {
+		t.Errorf("Status = %+v", st)
+	}
+}
+
+func TestSupervisor_RestartsStalled(t *testing.T) {
+	s, _, metrics := testSupervisor()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var starts atomic.Int32
+	var stalled atomic.Bool
+	stalled.Store(true)
+	s.Go(ctx, "dispatcher", func(ctx context.Context) error {
+		if starts.Add(1) > 1 {
+			stalled.Store(false)
+		}
+		<-ctx.Done()
+		return nil
+	}, func() (string, bool) { return "3 input(s) waiting", stalled.Load() })
+	waitFor(t, "restart", func() bool { return starts.Load() == 2 })
+
+	if st := s.Status(); st[0].Restarts != 1 || st[0].LastIncident != "stalled: 3 input(s) waiting" {
+		t.Errorf("Status = %+v", st)
+	}
+	if n := metrics.Counter("supervisor.restarts"); n != 1 {
+		t.Errorf("restarts = %d, want 1", n)
+	}
+}
+
+func TestSupervisor_Recover(t *testing.T) {
+	s, _, _ := testSupervisor()
+	func() {
+		defer s.Recover("dispatcher")
+		panic("bad input")
+	}()
+	if st := s.Status(); len(st) != 1 || st[0].Restarts != 1 || st[0].LastIncident != "panic: bad input" {
+		t.Errorf("Status = %+v", st)
+	}
+}
@@ -0,0 +1,697 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/evolution"
+	"github.com/overhuman/overhuman/internal/genui"
+)
+
+// upgradeShadowSamples is how many real tasks are answered by both models
+// before an upgrade report is presented.
+const upgradeShadowSamples = 20
+
+// Upgrade trial states.
+const (
+	upgradeEvaluating = "evaluating" // eval suite done, shadow comparison running
+	upgradeReady      = "ready"      // report ready, waiting for the user
+	upgradeAccepted   = "accepted"
+	upgradeDeclined   = "declined"
+)
+
+// upgradeMetrics are compared between the current and the candidate model
+// on shadowed traffic, one experiment each.
+var upgradeMetrics = []string{"quality", "cost_usd", "latency_ms"}
+
+// evalCase is one prompt of the eval suite. An answer passes if it
+// contains Want, ignoring case.
+type evalCase struct {
+	Prompt string
+	Want   string
+}
+
+// evalSuite is a small set of prompts with unambiguous answers: arithmetic,
+// facts, translation, extraction and instruction following.
+var evalSuite = []evalCase{
+	{"What is 17 * 23? Reply with the number only.", "391"},
+	{"What is the capital of Australia? Reply with the city name only.", "Canberra"},
+	{"Translate 'thank you' into Spanish. Reply with the translation only.", "gracias"},
+	{"Reverse the letters of the word 'stressed'. Reply with the result only.", "desserts"},
+	{`Extract the email address from: "Reach Dana at dana.k@example.org before Friday." Reply with the address only.`, "dana.k@example.org"},
+	{"Convert 3 hours into minutes. Reply with the number only.", "180"},
+	{`Return a JSON object with keys "name" and "age" for: Ada is 36 years old. Reply with the JSON only.`, `"age": 36`},
+	{"A meeting starts at 14:45 and lasts 50 minutes. When does it end? Reply in HH:MM.", "15:35"},
+}
+
+// evalScore is one model's result on the eval suite.
+type evalScore struct {
+	Model     string   `json:"model"`
+	Passed    int      `json:"passed"`
+	Total     int      `json:"total"`
+	CostUSD   float64  `json:"cost_usd"`
+	LatencyMs int64    `json:"latency_ms"` // mean per prompt
+	Failed    []string `json:"failed,omitempty"`
+}
+
+// runEvalSuite answers every case with model.
+func runEvalSuite(ctx context.Context, llm brain.LLMProvider, model string, cases []evalCase) evalScore {
+	sc := evalScore{Model: model, Total: len(cases)}
+	var latency int64
+	for _, c := range cases {
+		resp, err := llm.Complete(ctx, brain.LLMRequest{
+			Model:     model,
+			Messages:  []brain.Message{{Role: "user", Content: c.Prompt}},
+			MaxTokens: 200,
+		})
+		if err != nil {
+			sc.Failed = append(sc.Failed, c.Prompt)
+			continue
+		}
+		sc.CostUSD += resp.CostUSD
+		latency += resp.LatencyMs
+		if strings.Contains(strings.ToLower(resp.Content), strings.ToLower(c.Want)) {
+			sc.Passed++
+		} else {
+			sc.Failed = append(sc.Failed, c.Prompt)
+		}
+	}
+	if len(cases) > 0 {
+		sc.LatencyMs = latency / int64(len(cases))
+	}
+	return sc
+}
+
+// modelUpgrade is an offered upgrade of the default model and its trial,
+// persisted in model_upgrade.json.
+type modelUpgrade struct {
+	Current   string     `json:"current"`
+	Candidate string     `json:"candidate"`
+	Tier      brain.Tier `json:"tier,omitempty"`
+	Status    string     `json:"status"`
+	OfferedAt time.Time  `json:"offered_at"`
+	DecidedAt time.Time  `json:"decided_at,omitempty"`
+
+	// Eval holds the eval suite results of the current and the candidate
+	// model, in that order.
+	Eval []evalScore `json:"eval,omitempty"`
+	// Shadow holds the shadow comparison samples per metric: [0] for the
+	// current model, [1] for the candidate.
+	Shadow map[string][2][]float64 `json:"shadow,omitempty"`
+}
+
+// shadowed returns the number of shadow comparisons so far.
+func (u *modelUpgrade) shadowed() int {
+	return len(u.Shadow["latency_ms"][1])
+}
+
+// upgradeTrials offers upgrades of the default model when the provider
+// releases a newer one in the same family and tier, evaluates them with
+// the eval suite and a shadow comparison on real traffic (recorded in the
+// experiment manager), and switches only when the user accepts. A declined
+// candidate is not offered again.
+type upgradeTrials struct {
+	path   string
+	llm    brain.LLMProvider
+	router *brain.ModelRouter
+	exps   *evolution.ExperimentManager
+	budget *budget.Tracker // may be nil
+
+	// notify, if set, announces offers and finished reports.
+	notify func(string)
+
+	mu        sync.Mutex
+	up        *modelUpgrade     // nil = nothing offered yet
+	expIDs    map[string]string // metric → experiment ID
+	modTime   time.Time         // of path when last read or written
+	shadowing bool
+}
+
+// newUpgradeTrials loads the trial state from dataDir. An accepted upgrade
+// is applied to the router.
+func newUpgradeTrials(dataDir string, llm brain.LLMProvider, router *brain.ModelRouter, exps *evolution.ExperimentManager, tracker *budget.Tracker) (*upgradeTrials, error) {
+	if exps == nil {
+		exps = evolution.NewExperimentManager()
+	}
+	t := &upgradeTrials{
+		path:   filepath.Join(dataDir, "model_upgrade.json"),
+		llm:    llm,
+		router: router,
+		exps:   exps,
+		budget: tracker,
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// reload re-reads the state when the file changed on disk, e.g. after
+// `overhuman models upgrade accept`. t.mu must be held.
+func (t *upgradeTrials) reload() error {
+	fi, err := os.Stat(t.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("model upgrade: %w", err)
+	}
+	if fi.ModTime().Equal(t.modTime) {
+		return nil
+	}
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return fmt.Errorf("model upgrade: %w", err)
+	}
+	var up modelUpgrade
+	if err := json.Unmarshal(data, &up); err != nil {
+		return fmt.Errorf("model upgrade: parse %s: %w", t.path, err)
+	}
+	t.modTime = fi.ModTime()
+	t.up = &up
+	t.startExperiments()
+	if up.Status == upgradeAccepted {
+		t.router.ReplaceModel(up.Current, up.Candidate)
+	}
+	return nil
+}
+
+// save writes the state. t.mu must be held.
+func (t *upgradeTrials) save() error {
+	data, err := json.MarshalIndent(t.up, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(t.path, data, 0o600); err != nil {
+		return fmt.Errorf("model upgrade: %w", err)
+	}
+	if fi, err := os.Stat(t.path); err == nil {
+		t.modTime = fi.ModTime()
+	}
+	return nil
+}
+
+// startExperiments starts one experiment per metric for the current
+// upgrade and replays its recorded samples. t.mu must be held.
+func (t *upgradeTrials) startExperiments() {
+	for _, id := range t.expIDs {
+		t.exps.Abort(id)
+	}
+	t.expIDs = make(map[string]string)
+	if t.up == nil || (t.up.Status != upgradeEvaluating && t.up.Status != upgradeReady) {
+		return
+	}
+	for _, metric := range upgradeMetrics {
+		exp := t.exps.StartExperiment(fmt.Sprintf("%s is at least as good as %s on %s", t.up.Candidate, t.up.Current, metric),
+			t.up.Current, t.up.Candidate, metric)
+		t.expIDs[metric] = exp.ID
+		for i, variant := range []string{"A", "B"} {
+			for _, v := range t.up.Shadow[metric][i] {
+				t.exps.RecordSample(exp.ID, variant, v)
+			}
+		}
+	}
+}
+
+// Upgrade returns a copy of the current offer, or nil.
+func (t *upgradeTrials) Upgrade() *modelUpgrade {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.reload(); err != nil {
+		log.Printf("[models] %v", err)
+	}
+	if t.up == nil {
+		return nil
+	}
+	up := *t.up
+	return &up
+}
+
+// defaultModel is the model tasks of moderate complexity run on.
+func defaultModel(router *brain.ModelRouter) string {
+	return router.Select(brain.TierComplexity(brain.TierMid), math.MaxFloat64)
+}
+
+// Candidate returns the newer default model in available that could be
+// trialled, or "" when there is none, a trial is in progress or the
+// candidate was already decided on.
+func (t *upgradeTrials) Candidate(available []string) (current, candidate string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.reload(); err != nil {
+		log.Printf("[models] %v", err)
+	}
+	current = defaultModel(t.router)
+	candidate = brain.FindUpgrade(current, available)
+	if t.up != nil && (t.up.Status == upgradeEvaluating || t.up.Status == upgradeReady || t.up.Candidate == candidate) {
+		return current, ""
+	}
+	return current, candidate
+}
+
+// Check starts a trial when Candidate finds one, running the eval suite on
+// both models. It reports whether a new trial started.
+func (t *upgradeTrials) Check(ctx context.Context, available []string) (bool, error) {
+	current, candidate := t.Candidate(available)
+	if candidate == "" {
+		return false, nil
+	}
+
+	up := &modelUpgrade{
+		Current:   current,
+		Candidate: candidate,
+		Tier:      t.router.TierOf(current),
+		Status:    upgradeEvaluating,
+		OfferedAt: time.Now().UTC(),
+	}
+	for _, m := range []string{current, candidate} {
+		sc := runEvalSuite(ctx, t.llm, m, evalSuite)
+		t.spend(sc.CostUSD)
+		up.Eval = append(up.Eval, sc)
+	}
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	t.mu.Lock()
+	t.up = up
+	t.startExperiments()
+	err := t.save()
+	t.mu.Unlock()
+	if t.notify != nil {
+		t.notify(fmt.Sprintf("New model %s is available (default: %s). Eval suite: %d/%d vs %d/%d passed; comparing both on the next %d tasks before suggesting a switch",
+			candidate, current, up.Eval[1].Passed, up.Eval[1].Total, up.Eval[0].Passed, up.Eval[0].Total, upgradeShadowSamples))
+	}
+	return true, err
+}
+
+// spend records trial costs against the budget.
+func (t *upgradeTrials) spend(cost float64) {
+	if t.budget != nil && cost > 0 {
+		t.budget.Record("model_upgrade", cost)
+	}
+}
+
+// Shadow answers goal with both models in the background and records the
+// comparison, while a trial is collecting samples and the budget allows
+// it. Only one comparison runs at a time; the user's answer is unaffected.
+func (t *upgradeTrials) Shadow(ctx context.Context, goal string) {
+	t.mu.Lock()
+	if err := t.reload(); err != nil {
+		log.Printf("[models] %v", err)
+	}
+	if t.up == nil || t.up.Status != upgradeEvaluating || t.shadowing || (t.budget != nil && t.budget.ShouldDowngrade()) {
+		t.mu.Unlock()
+		return
+	}
+	t.shadowing = true
+	current, candidate := t.up.Current, t.up.Candidate
+	t.mu.Unlock()
+
+	go func() {
+		samples, err := t.compare(ctx, goal, current, candidate)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.shadowing = false
+		if err != nil {
+			log.Printf("[models] shadow comparison: %v", err)
+			return
+		}
+		t.reload()
+		if t.up == nil || t.up.Candidate != candidate || t.up.Status != upgradeEvaluating {
+			return
+		}
+		if t.up.Shadow == nil {
+			t.up.Shadow = make(map[string][2][]float64)
+		}
+		for metric, ab := range samples {
+			s := t.up.Shadow[metric]
+			s[0], s[1] = append(s[0], ab[0]), append(s[1], ab[1])
+			t.up.Shadow[metric] = s
+			t.exps.RecordSample(t.expIDs[metric], "A", ab[0])
+			t.exps.RecordSample(t.expIDs[metric], "B", ab[1])
+		}
+		if t.up.shadowed() >= upgradeShadowSamples {
+			t.up.Status = upgradeReady
+		}
+		if err := t.save(); err != nil {
+			log.Printf("[models] %v", err)
+		}
+		if t.up.Status == upgradeReady && t.notify != nil {
+			t.notify(t.summary() + ". Switch with: " + appName + " models upgrade accept")
+		}
+	}()
+}
+
+var judgeScore = regexp.MustCompile(`(?mi)^\s*([AB])\s*:\s*([0-9]*\.?[0-9]+)`)
+
+// compare answers goal with both models and has the current model judge
+// the answers. It returns [current, candidate] values per metric; quality
+// is left out when the verdict cannot be parsed.
+func (t *upgradeTrials) compare(ctx context.Context, goal, current, candidate string) (map[string][2]float64, error) {
+	if len(goal) > 4000 {
+		goal = goal[:4000]
+	}
+	var answers [2]*brain.LLMResponse
+	for i, m := range []string{current, candidate} {
+		resp, err := t.llm.Complete(ctx, brain.LLMRequest{Model: m, Messages: []brain.Message{{Role: "user", Content: goal}}})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m, err)
+		}
+		t.spend(resp.CostUSD)
+		answers[i] = resp
+	}
+	out := map[string][2]float64{
+		"cost_usd":   {answers[0].CostUSD, answers[1].CostUSD},
+		"latency_ms": {float64(answers[0].LatencyMs), float64(answers[1].LatencyMs)},
+	}
+	verdict, err := t.llm.Complete(ctx, brain.LLMRequest{
+		Model: current,
+		Messages: []brain.Message{{Role: "user", Content: fmt.Sprintf(
+			"Rate each answer to the task from 0.0 to 1.0.\n\nTask: %s\n\nAnswer A:\n%s\n\nAnswer B:\n%s\n\nRespond in this format:\nA: <score>\nB: <score>",
+			goal, answers[0].Content, answers[1].Content)}},
+		MaxTokens: 20,
+	})
+	if err != nil {
+		return out, nil
+	}
+	t.spend(verdict.CostUSD)
+	var scores [2]float64
+	found := 0
+	for _, m := range judgeScore.FindAllStringSubmatch(verdict.Content, -1) {
+		v, err := strconv.ParseFloat(m[2], 64)
+		if err != nil || v > 1 {
+			continue
+		}
+		scores[strings.Index("AB", strings.ToUpper(m[1]))] = v
+		found++
+	}
+	if found == 2 {
+		out["quality"] = scores
+	}
+	return out, nil
+}
+
+// upgradeDelta compares one metric between the two models.
+type upgradeDelta struct {
+	Metric      string
+	Current     float64 // mean
+	Candidate   float64 // mean
+	Samples     int
+	Significant bool // per the experiment, once the trial is ready
+}
+
+// deltas returns the shadow comparison per metric. t.mu must be held.
+func (t *upgradeTrials) deltas() []upgradeDelta {
+	var out []upgradeDelta
+	for _, metric := range upgradeMetrics {
+		s := t.up.Shadow[metric]
+		if len(s[1]) == 0 {
+			continue
+		}
+		d := upgradeDelta{Metric: metric, Current: meanOf(s[0]), Candidate: meanOf(s[1]), Samples: len(s[1])}
+		if id := t.expIDs[metric]; id != "" && t.up.Status == upgradeReady {
+			t.exps.Evaluate(id)
+			if exp := t.exps.Get(id); exp != nil && exp.Status == evolution.ExperimentConcluded {
+				d.Significant = exp.Winner != "inconclusive"
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func meanOf(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
+
+// summary is a one-line report. t.mu must be held.
+func (t *upgradeTrials) summary() string {
+	parts := []string{fmt.Sprintf("Model upgrade %s → %s", t.up.Current, t.up.Candidate)}
+	if len(t.up.Eval) == 2 {
+		parts = append(parts, fmt.Sprintf("eval %d/%d → %d/%d", t.up.Eval[0].Passed, t.up.Eval[0].Total, t.up.Eval[1].Passed, t.up.Eval[1].Total))
+	}
+	for _, d := range t.deltas() {
+		parts = append(parts, d.Metric+" "+percentChange(d.Current, d.Candidate))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Report describes the trial for `overhuman models upgrade`.
+func (t *upgradeTrials) Report() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.up == nil {
+		return "No model upgrade offered."
+	}
+	up := t.up
+	var b strings.Builder
+	fmt.Fprintf(&b, "Model upgrade: %s → %s", up.Current, up.Candidate)
+	if up.Tier != "" {
+		fmt.Fprintf(&b, " (%s tier)", up.Tier)
+	}
+	fmt.Fprintf(&b, "\nStatus: %s (offered %s)\n", up.Status, up.OfferedAt.Local().Format("2006-01-02 15:04"))
+	if len(up.Eval) == 2 {
+		cur, cand := up.Eval[0], up.Eval[1]
+		fmt.Fprintf(&b, "\nEval suite        %12s  %12s\n", "current", "candidate")
+		fmt.Fprintf(&b, "  passed          %12s  %12s\n", fmt.Sprintf("%d/%d", cur.Passed, cur.Total), fmt.Sprintf("%d/%d", cand.Passed, cand.Total))
+		fmt.Fprintf(&b, "  cost            %12s  %12s\n", fmt.Sprintf("$%.4f", cur.CostUSD), fmt.Sprintf("$%.4f", cand.CostUSD))
+		fmt.Fprintf(&b, "  latency         %12s  %12s\n", fmt.Sprintf("%dms", cur.LatencyMs), fmt.Sprintf("%dms", cand.LatencyMs))
+		for _, p := range cand.Failed {
+			fmt.Fprintf(&b, "  ✗ candidate failed: %s\n", p)
+		}
+	}
+	fmt.Fprintf(&b, "\nShadow comparison (%d/%d tasks)\n", up.shadowed(), upgradeShadowSamples)
+	for _, d := range t.deltas() {
+		note := ""
+		if up.Status == upgradeReady && !d.Significant {
+			note = "  not significant"
+		}
+		fmt.Fprintf(&b, "  %-15s %12s  %12s  %s%s\n", d.Metric, formatMetric(d.Metric, d.Current), formatMetric(d.Metric, d.Candidate),
+			percentChange(d.Current, d.Candidate), note)
+	}
+	switch up.Status {
+	case upgradeEvaluating, upgradeReady:
+		fmt.Fprintf(&b, "\nSwitch with '%s models upgrade accept', or keep %s with '%s models upgrade decline'.", appName, up.Current, appName)
+	}
+	return b.String()
+}
+
+func formatMetric(metric string, v float64) string {
+	switch metric {
+	case "cost_usd":
+		return fmt.Sprintf("$%.4f", v)
+	case "latency_ms":
+		return fmt.Sprintf("%.0fms", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func percentChange(from, to float64) string {
+	if from == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", (to-from)/from*100)
+}
+
+// Accept switches the default model to the candidate: in the router and,
+// when config.json names the current model, in the config (backed up to
+// config-history/ first). It returns a description of what changed.
+func (t *upgradeTrials) Accept() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.reload(); err != nil {
+		return "", err
+	}
+	if t.up == nil || (t.up.Status != upgradeEvaluating && t.up.Status != upgradeReady) {
+		return "", fmt.Errorf("no model upgrade to accept")
+	}
+	up := t.up
+	t.router.ReplaceModel(up.Current, up.Candidate)
+	what := fmt.Sprintf("default model switched from %s to %s", up.Current, up.Candidate)
+	persisted, err := loadPersistedConfig()
+	if err != nil {
+		return "", err
+	}
+	if persisted != nil && persisted.Model == up.Current {
+		backup, err := backupPersistedConfig(fmt.Sprintf("model upgrade from %s to %s", up.Current, up.Candidate))
+		if err != nil {
+			return "", err
+		}
+		persisted.Model = up.Candidate
+		if err := savePersistedConfig(persisted); err != nil {
+			return "", err
+		}
+		what += fmt.Sprintf(" (previous config: %s)", backup)
+	}
+	up.Status, up.DecidedAt = upgradeAccepted, time.Now().UTC()
+	return what, t.save()
+}
+
+// Decline keeps the current model; the candidate is not offered again.
+func (t *upgradeTrials) Decline() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.reload(); err != nil {
+		return err
+	}
+	if t.up == nil || (t.up.Status != upgradeEvaluating && t.up.Status != upgradeReady) {
+		return fmt.Errorf("no model upgrade to decline")
+	}
+	t.up.Status, t.up.DecidedAt = upgradeDeclined, time.Now().UTC()
+	return t.save()
+}
+
+// registerUpgradeCommands adds the palette actions that answer an upgrade
+// report.
+func registerUpgradeCommands(cmds *genui.CommandRegistry, trials *upgradeTrials) {
+	cmds.AddAction("model.upgrade.accept", "Accept model upgrade", "Switch the default model to the evaluated candidate", nil,
+		func(_ context.Context, _ map[string]string) (string, error) {
+			what, err := trials.Accept()
+			if err != nil {
+				return "", err
+			}
+			log.Printf("[models] %s", what)
+			return strings.ToUpper(what[:1]) + what[1:], nil
+		})
+	cmds.AddAction("model.upgrade.decline", "Decline model upgrade", "Keep the current default model", nil,
+		func(_ context.Context, _ map[string]string) (string, error) {
+			if err := trials.Decline(); err != nil {
+				return "", err
+			}
+			return "Keeping the current model", nil
+		})
+}
+
+// watchModelUpgrades checks for a newer default model at startup and then
+// every modelCheckInterval. With auto it starts the trial; otherwise each
+// candidate is announced once and the user starts the trial with
+// `overhuman models upgrade`, which the daemon then picks up. Providers
+// that cannot list models are skipped.
+func watchModelUpgrades(ctx context.Context, trials *upgradeTrials, auto bool) {
+	lister, ok := trials.llm.(brain.ModelLister)
+	if !ok {
+		return
+	}
+	announced := make(map[string]bool)
+	check := func() {
+		lctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		available, err := lister.ListModels(lctx)
+		cancel()
+		if err != nil {
+			log.Printf("[models] upgrade check failed: %v", err)
+			return
+		}
+		if auto {
+			if _, err := trials.Check(ctx, available); err != nil {
+				log.Printf("[models] upgrade trial: %v", err)
+			}
+			return
+		}
+		current, candidate := trials.Candidate(available)
+		if candidate != "" && !announced[candidate] && trials.notify != nil {
+			announced[candidate] = true
+			trials.notify(fmt.Sprintf("New model %s is available (default: %s). Evaluate it with: %s models upgrade", candidate, current, appName))
+		}
+	}
+	check()
+	ticker := time.NewTicker(modelCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// runModelUpgrade handles `overhuman models upgrade [accept|decline]`.
+// Without an offer it checks the provider and runs the eval suite right
+// away; the shadow comparison needs the daemon's traffic.
+func runModelUpgrade(args []string) {
+	cfg := loadConfig()
+	llm, providerName, err := createLLMProvider(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	trials, err := newUpgradeTrials(cfg.DataDir, llm, newModelRouter(llm, providerName), nil, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	sub := ""
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	switch sub {
+	case "accept":
+		what, err := trials.Accept()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "upgrade: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ %s.\nRestart the daemon to apply; undo the config change with '%s models rollback'.\n", what, appName)
+		return
+	case "decline":
+		if err := trials.Decline(); err != nil {
+			fmt.Fprintf(os.Stderr, "upgrade: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Keeping the current model.")
+		return
+	case "":
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s models upgrade [accept|decline]\n", appName)
+		os.Exit(1)
+	}
+
+	if up := trials.Upgrade(); up == nil || up.Status == upgradeAccepted || up.Status == upgradeDeclined {
+		lister, ok := llm.(brain.ModelLister)
+		if !ok {
+			fmt.Printf("Provider %s cannot list its models; no upgrades to check.\n", providerName)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		available, err := lister.ListModels(ctx)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "model check: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Checking for a newer default model…")
+		started, err := trials.Check(context.Background(), available)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "upgrade: %v\n", err)
+			os.Exit(1)
+		}
+		if !started {
+			fmt.Printf("%s is the newest default model offered by %s.\n", defaultModel(trials.router), providerName)
+			return
+		}
+	}
+	fmt.Println(trials.Report())
+}
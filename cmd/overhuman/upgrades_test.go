@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// upgradeLLM answers the eval suite correctly only on fake-mid-2, which is
+// also cheaper, faster and judged better.
+type upgradeLLM struct{}
+
+func (upgradeLLM) Name() string     { return "stub" }
+func (upgradeLLM) Models() []string { return []string{"fake-mid", "fake-mid-2"} }
+
+func (upgradeLLM) Complete(_ context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	resp := &brain.LLMResponse{Model: req.Model, Content: "not sure", CostUSD: 0.002, LatencyMs: 100}
+	if strings.HasPrefix(prompt, "Rate each answer") {
+		resp.Content = "A: 0.6\nB: 0.9"
+		return resp, nil
+	}
+	if req.Model == "fake-mid-2" {
+		resp.CostUSD, resp.LatencyMs = 0.001, 80
+		for _, c := range evalSuite {
+			if c.Prompt == prompt {
+				resp.Content = c.Want
+			}
+		}
+	}
+	return resp, nil
+}
+
+func TestUpgradeTrials_EvaluateAndAccept(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OVERHUMAN_DATA", dir)
+	if err := savePersistedConfig(&persistedConfig{Provider: "fake", Model: "fake-mid"}); err != nil {
+		t.Fatal(err)
+	}
+	router := brain.NewModelRouterWithModels([]brain.ModelEntry{{ID: "fake-mid", Tier: brain.TierMid}})
+	trials, err := newUpgradeTrials(dir, upgradeLLM{}, router, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var notices []string
+	trials.notify = func(msg string) {
+		mu.Lock()
+		defer mu.Unlock()
+		notices = append(notices, msg)
+	}
+
+	available := []string{"fake-cheap", "fake-mid", "fake-mid-2"}
+	if cur, cand := trials.Candidate(available); cur != "fake-mid" || cand != "fake-mid-2" {
+		t.Fatalf("Candidate = %q, %q", cur, cand)
+	}
+	if started, err := trials.Check(context.Background(), available); !started || err != nil {
+		t.Fatalf("Check = %v, %v", started, err)
+	}
+	up := trials.Upgrade()
+	if up.Status != upgradeEvaluating || len(up.Eval) != 2 || up.Eval[0].Passed != 0 || up.Eval[1].Passed != len(evalSuite) {
+		t.Fatalf("after Check: %+v", up)
+	}
+
+	for i := 0; i < upgradeShadowSamples; i++ {
+		trials.Shadow(context.Background(), "summarize my week")
+		waitShadow(t, trials)
+	}
+	if up := trials.Upgrade(); up.Status != upgradeReady || up.shadowed() != upgradeShadowSamples {
+		t.Fatalf("after shadowing: status %s, %d samples", up.Status, up.shadowed())
+	}
+	report := trials.Report()
+	for _, want := range []string{"fake-mid → fake-mid-2", "0/8", "8/8", "quality", "+50.0%", "cost_usd", "-50.0%", "models upgrade accept"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	mu.Lock()
+	if len(notices) != 2 || !strings.Contains(notices[1], "models upgrade accept") {
+		t.Errorf("notices = %q", notices)
+	}
+	mu.Unlock()
+
+	// A second process (the CLI) accepts; the daemon picks it up.
+	cli, err := newUpgradeTrials(dir, upgradeLLM{}, brain.NewModelRouterWithModels([]brain.ModelEntry{{ID: "fake-mid", Tier: brain.TierMid}}), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond) // distinct mtime for the reload
+	if _, err := cli.Accept(); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if cfg, _ := loadPersistedConfig(); cfg.Model != "fake-mid-2" {
+		t.Errorf("config model = %q", cfg.Model)
+	}
+	if up := trials.Upgrade(); up.Status != upgradeAccepted || router.TierOf("fake-mid-2") != brain.TierMid {
+		t.Errorf("daemon did not pick up the accepted upgrade: %+v", up)
+	}
+	if started, _ := trials.Check(context.Background(), available); started {
+		t.Error("the accepted candidate was offered again")
+	}
+}
+
+func TestUpgradeTrials_Decline(t *testing.T) {
+	dir := t.TempDir()
+	router := brain.NewModelRouterWithModels([]brain.ModelEntry{{ID: "fake-mid", Tier: brain.TierMid}})
+	trials, err := newUpgradeTrials(dir, upgradeLLM{}, router, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trials.Decline(); err == nil {
+		t.Error("Decline without an offer should fail")
+	}
+	available := []string{"fake-mid", "fake-mid-2"}
+	trials.Check(context.Background(), available)
+	if err := trials.Decline(); err != nil {
+		t.Fatal(err)
+	}
+	if _, cand := trials.Candidate(available); cand != "" {
+		t.Errorf("declined candidate %q offered again", cand)
+	}
+	if router.TierOf("fake-mid-2") != "" {
+		t.Error("declining switched the model")
+	}
+}
+
+// waitShadow waits for a background shadow comparison to finish.
+func waitShadow(t *testing.T, trials *upgradeTrials) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		trials.mu.Lock()
+		busy := trials.shadowing
+		trials.mu.Unlock()
+		if !busy {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("shadow comparison did not finish")
+}
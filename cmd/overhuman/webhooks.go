@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+
+	"github.com/overhuman/overhuman/internal/automation"
+	"github.com/overhuman/overhuman/internal/events"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/webhook"
+)
+
+// wireWebhooks forwards task outcomes, budget alerts and automation runs
+// from deps.Events to the webhooks, and reports undecided skill permissions
+// to them. Heartbeat runs are not reported.
+func wireWebhooks(hooks *webhook.Dispatcher, deps *pipeline.Dependencies) {
+	if hooks == nil {
+		return
+	}
+	bus := deps.Events
+	bus.Subscribe(events.TaskCompleted, func(ev events.Event) {
+		if te := ev.Data.(pipeline.TaskEvent); te.Input.SourceType != senses.SourceTimer {
+			hooks.Emit(webhook.EventTaskCompleted, taskCompletedEvent(&te.Input, te.Result))
+		}
+	})
+	bus.Subscribe(events.TaskFailed, func(ev events.Event) {
+		if te := ev.Data.(pipeline.TaskEvent); te.Input.SourceType != senses.SourceTimer {
+			hooks.Emit(webhook.EventTaskFailed, taskFailedEvent(&te.Input, te.Result, te.Err))
+		}
+	})
+	bus.Subscribe(events.BudgetThreshold, func(ev events.Event) {
+		hooks.Emit(webhook.EventBudgetThreshold, ev.Data)
+	})
+	bus.Subscribe(events.AutomationFired, func(ev events.Event) {
+		hooks.Emit(webhook.EventAutomationFired, automationFiredEvent(ev.Data.(automation.Event)))
+	})
+	if deps.PermissionPrompter == nil {
+		deps.PermissionPrompter = approvalNotifier{hooks: hooks}
+	}
+}
+
+// approvalNotifier is the daemon's permission prompter. Nobody is there to
+// answer, so it announces the request and allows this one use without
+// storing a decision — what the daemon does without a prompter — leaving
+// the user to decide with `overhuman permissions`.
+type approvalNotifier struct {
+	hooks *webhook.Dispatcher
+}
+
+// PromptPermission implements security.PermissionPrompter.
+func (n approvalNotifier) PromptPermission(_ context.Context, req security.PermissionRequest) (security.PermissionDecision, error) {
+	n.hooks.Emit(webhook.EventApprovalRequested, map[string]any{
+		"skill_id":   req.SkillID,
+		"skill_name": req.SkillName,
+		"permission": req.Permission,
+		"detail":     req.Detail,
+		"decision":   security.PermissionOnce,
+	})
+	return security.PermissionOnce, nil
+}
+
+// inputFields describes where a task came from in webhook payloads.
+func inputFields(in *senses.UnifiedInput) map[string]any {
+	raw := in.Payload
+	if v, ok := in.SourceMeta.Extra["raw_payload"]; ok {
+		raw = v
+	}
+	return map[string]any{
+		"input_id": in.InputID,
+		"source":   string(in.SourceType),
+		"sender":   in.SourceMeta.Sender,
+		"channel":  in.SourceMeta.Channel,
+		"input":    raw,
+	}
+}
+
+// taskCompletedEvent is the task.completed payload data.
+func taskCompletedEvent(in *senses.UnifiedInput, r *pipeline.RunResult) map[string]any {
+	data := inputFields(in)
+	data["task_id"] = r.TaskID
+	data["result"] = r.Result
+	data["quality"] = r.QualityScore
+	data["cost_usd"] = r.CostUSD
+	data["elapsed_ms"] = r.ElapsedMs
+	data["fingerprint"] = r.Fingerprint
+	if r.Declined {
+		data["declined"] = true
+	}
+	return data
+}
+
+// taskFailedEvent is the task.failed payload data; r may be nil.
+func taskFailedEvent(in *senses.UnifiedInput, r *pipeline.RunResult, err error) map[string]any {
+	data := inputFields(in)
+	data["error"] = err.Error()
+	if r != nil {
+		data["task_id"] = r.TaskID
+		data["cost_usd"] = r.CostUSD
+		data["elapsed_ms"] = r.ElapsedMs
+	}
+	return data
+}
+
+// automationFiredEvent is the automation.fired payload data.
+func automationFiredEvent(ev automation.Event) map[string]any {
+	return map[string]any{
+		"rule":        ev.Rule,
+		"source":      ev.Source,
+		"sender":      ev.Sender,
+		"channel":     ev.Channel,
+		"payload":     ev.Payload,
+		"fingerprint": ev.Fingerprint,
+		"time":        ev.Time,
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/events"
+	"github.com/overhuman/overhuman/internal/pipeline"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/webhook"
+)
+
+func TestWireWebhooks(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got = map[string]map[string]any{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p struct {
+			Event string         `json:"event"`
+			Data  map[string]any `json:"data"`
+		}
+		json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		got[p.Event] = p.Data
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	hooks := webhook.New([]webhook.Config{{URL: srv.URL}}, "test")
+	deps := pipeline.Dependencies{Budget: budget.New(1, 0), Events: events.New()}
+	wireEvents(&deps)
+	wireWebhooks(hooks, &deps)
+	if deps.PermissionPrompter == nil {
+		t.Fatal("no approval prompter wired")
+	}
+
+	deps.Budget.Record("t1", 0.9)
+	in := senses.NewFromText("plan my week")
+	deps.Events.Publish(events.TaskCompleted, pipeline.TaskEvent{TaskID: "t1", Input: *in, Result: &pipeline.RunResult{TaskID: "t1", Result: "done"}})
+	deps.Events.Publish(events.TaskFailed, pipeline.TaskEvent{Input: *senses.NewHeartbeat(), Err: errors.New("offline")})
+	d, err := deps.PermissionPrompter.PromptPermission(context.Background(), security.PermissionRequest{SkillID: "docs", Permission: "network"})
+	if err != nil || d != security.PermissionOnce {
+		t.Errorf("decision = %q, %v; want once (allowed, not stored)", d, err)
+	}
+	hooks.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if ev := got[webhook.EventTaskCompleted]; ev["task_id"] != "t1" || ev["input"] != "plan my week" {
+		t.Errorf("task event = %v", ev)
+	}
+	if _, ok := got[webhook.EventTaskFailed]; ok {
+		t.Error("heartbeat runs should not be reported")
+	}
+	if ev := got[webhook.EventBudgetThreshold]; ev["period"] != "daily" || ev["threshold"] != 0.8 {
+		t.Errorf("budget event = %v", ev)
+	}
+	if ev := got[webhook.EventApprovalRequested]; ev["skill_id"] != "docs" || ev["permission"] != "network" {
+		t.Errorf("approval event = %v", ev)
+	}
+}
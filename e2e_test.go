@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/overhuman/overhuman/braintest"
 	"github.com/overhuman/overhuman/internal/brain"
 	"github.com/overhuman/overhuman/internal/memory"
 	"github.com/overhuman/overhuman/internal/pipeline"
@@ -200,6 +201,69 @@ func TestE2E_FullPipelineRun(t *testing.T) {
 		result.TaskID, result.QualityScore*100, result.CostUSD, result.ElapsedMs, callCount.Load())
 }
 
+// ---------------------------------------------------------------------------
+// Test: Built-in Fake Provider (no HTTP mock needed)
+// ---------------------------------------------------------------------------
+
+func TestE2E_FakeProvider(t *testing.T) {
+	deps := setupE2EDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{
+		Rules: []brain.FakeRule{{Match: "weather", Response: "The weather in Moscow is -5°C."}},
+	})
+	deps.LLM = fake
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+
+	result, err := pipeline.New(deps).Run(context.Background(), *senses.NewFromText("What is the weather in Moscow?"))
+	if err != nil {
+		t.Fatalf("Pipeline.Run failed: %v", err)
+	}
+	if result.Result != "The weather in Moscow is -5°C." {
+		t.Errorf("Result = %q, want scripted response", result.Result)
+	}
+	if result.CostUSD != 0 {
+		t.Errorf("CostUSD = %f, want 0 for fake provider", result.CostUSD)
+	}
+	if fake.Calls() < 2 {
+		t.Errorf("expected at least 2 LLM calls, got %d", fake.Calls())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Test: Recorded Provider Traffic Replays Deterministically
+// ---------------------------------------------------------------------------
+
+func TestE2E_RecordReplay(t *testing.T) {
+	fixtures := t.TempDir()
+	fake := brain.NewFakeProvider(brain.FakeConfig{
+		Rules: []brain.FakeRule{{Match: "weather", Response: "The weather in Moscow is -5°C."}},
+	})
+	input := "What is the weather in Moscow?"
+
+	deps := setupE2EDeps(t, "http://127.0.0.1:0")
+	deps.LLM = braintest.Record(t, fake, fixtures)
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	recorded, err := pipeline.New(deps).Run(context.Background(), *senses.NewFromText(input))
+	if err != nil {
+		t.Fatalf("recording run: %v", err)
+	}
+
+	// A fresh agent with no network: every call is served from fixtures.
+	deps = setupE2EDeps(t, "http://127.0.0.1:0")
+	replay := braintest.Replay(t, fixtures)
+	deps.LLM = replay
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	replayed, err := pipeline.New(deps).Run(context.Background(), *senses.NewFromText(input))
+	if err != nil {
+		t.Fatalf("replay run: %v", err)
+	}
+	if replayed.Result != recorded.Result || replayed.QualityScore != recorded.QualityScore {
+		t.Errorf("replay = %q (%.2f), recording = %q (%.2f)", replayed.Result, replayed.QualityScore, recorded.Result, recorded.QualityScore)
+	}
+	if replay.Len() == 0 {
+		t.Error("no fixtures recorded")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Test: Memory Persistence Across Runs
 // ---------------------------------------------------------------------------
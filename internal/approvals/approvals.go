@@ -0,0 +1,230 @@
+// Package approvals holds actions a require_approval policy stopped until a
+// human decides. The task asking files a Request and is parked; an admin
+// approves or denies the request over the HTTP API, the kiosk or the CLI,
+// and OnDecide callbacks run the task again or end it.
+package approvals
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is where an approval stands.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusDenied   Status = "denied"
+	StatusExpired  Status = "expired" // nobody decided in time, or the task is gone
+)
+
+// Approval is a request for a human's go-ahead.
+type Approval struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id,omitempty"`
+	Requester string    `json:"requester,omitempty"` // who started the task
+	Rule      string    `json:"rule"`                // policy rule, e.g. "require_approval"
+	Action    string    `json:"action"`              // what waits, e.g. "run skill Deploy (sk_12)"
+	Detail    string    `json:"detail,omitempty"`    // why it needs approval
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	DecidedAt time.Time `json:"decided_at,omitzero"`
+	DecidedBy string    `json:"decided_by,omitempty"`
+	Note      string    `json:"note,omitempty"`
+}
+
+var (
+	// ErrNotFound is returned for an unknown approval ID.
+	ErrNotFound = errors.New("approval not found")
+	// ErrDecided is returned when deciding an approval that is not pending.
+	ErrDecided = errors.New("approval already decided")
+)
+
+// historySize is how many decided approvals are kept in memory (and
+// loaded on Open) for listing.
+const historySize = 100
+
+// Store keeps the approvals and tells the parked tasks when theirs are
+// decided. It is safe for concurrent use.
+type Store struct {
+	db      *sql.DB // nil = in memory only
+	timeout time.Duration
+
+	mu       sync.Mutex
+	items    map[string]*Approval
+	timers   map[string]*time.Timer // expiry of the pending approvals
+	onCreate []func(Approval)
+	onDecide []func(Approval)
+}
+
+// New creates an in-memory store. Requests nobody decides within timeout
+// expire (0 = they wait until decided).
+func New(timeout time.Duration) *Store {
+	return &Store{
+		timeout: timeout,
+		items:   make(map[string]*Approval),
+		timers:  make(map[string]*time.Timer),
+	}
+}
+
+// OnRequest registers fn to be called with every new pending approval,
+// e.g. to notify the admins. fn runs before Request returns and may decide
+// the approval itself.
+func (s *Store) OnRequest(fn func(Approval)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onCreate = append(s.onCreate, fn)
+}
+
+// OnDecide registers fn to be called with every approval that stops
+// pending: approved, denied or expired. This is where a parked task is
+// queued again.
+func (s *Store) OnDecide(fn func(Approval)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDecide = append(s.onDecide, fn)
+}
+
+// Request files a as a pending approval and returns it without waiting:
+// the task asking is parked until an OnDecide callback resumes it. The
+// OnRequest callbacks may have decided it already (an interactive prompt),
+// so check the returned Status; only StatusApproved allows the action.
+func (s *Store) Request(a Approval) Approval {
+	a.ID = "apr_" + uuid.NewString()[:8]
+	a.Status = StatusPending
+	a.CreatedAt = time.Now().UTC()
+	a.DecidedAt, a.DecidedBy = time.Time{}, ""
+
+	s.mu.Lock()
+	s.items[a.ID] = &a
+	if s.timeout > 0 {
+		id := a.ID
+		s.timers[id] = time.AfterFunc(s.timeout, func() {
+			s.expire(id, fmt.Sprintf("nobody decided within %s", s.timeout))
+		})
+	}
+	notify := append([]func(Approval){}, s.onCreate...)
+	s.mu.Unlock()
+	s.persist(a)
+	for _, fn := range notify {
+		fn(a)
+	}
+	got, _ := s.Get(a.ID)
+	return got
+}
+
+// Decide approves or denies the pending approval id on behalf of by, and
+// tells the OnDecide callbacks.
+func (s *Store) Decide(id string, approve bool, by, note string) (Approval, error) {
+	status := StatusDenied
+	if approve {
+		status = StatusApproved
+	}
+	return s.finish(id, status, by, note)
+}
+
+// expire ends id as expired unless it was decided meanwhile, and returns
+// the approval as it ended.
+func (s *Store) expire(id, note string) Approval {
+	a, err := s.finish(id, StatusExpired, "", note)
+	if err != nil {
+		got, _ := s.Get(id)
+		return got
+	}
+	return a
+}
+
+func (s *Store) finish(id string, status Status, by, note string) (Approval, error) {
+	s.mu.Lock()
+	a, ok := s.items[id]
+	if !ok {
+		s.mu.Unlock()
+		return Approval{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	if a.Status != StatusPending {
+		s.mu.Unlock()
+		return *a, fmt.Errorf("%w: %s is %s", ErrDecided, id, a.Status)
+	}
+	a.Status, a.DecidedAt, a.DecidedBy, a.Note = status, time.Now().UTC(), by, note
+	out := *a
+	if t := s.timers[id]; t != nil {
+		t.Stop()
+		delete(s.timers, id)
+	}
+	notify := append([]func(Approval){}, s.onDecide...)
+	s.pruneLocked()
+	s.mu.Unlock()
+
+	s.persist(out)
+	for _, fn := range notify {
+		fn(out)
+	}
+	return out, nil
+}
+
+// pruneLocked drops the oldest decided approvals beyond historySize.
+// Caller holds s.mu.
+func (s *Store) pruneLocked() {
+	var decided []*Approval
+	for _, a := range s.items {
+		if a.Status != StatusPending {
+			decided = append(decided, a)
+		}
+	}
+	if len(decided) <= historySize {
+		return
+	}
+	sort.Slice(decided, func(i, j int) bool { return decided[i].DecidedAt.Before(decided[j].DecidedAt) })
+	for _, a := range decided[:len(decided)-historySize] {
+		delete(s.items, a.ID)
+	}
+}
+
+// Get returns the approval id.
+func (s *Store) Get(id string) (Approval, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.items[id]
+	if !ok {
+		return Approval{}, false
+	}
+	return *a, true
+}
+
+// List returns the approvals with the given status ("" = all), newest
+// first.
+func (s *Store) List(status Status) []Approval {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Approval, 0, len(s.items))
+	for _, a := range s.items {
+		if status == "" || a.Status == status {
+			out = append(out, *a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// Pending returns the approvals waiting for a decision, newest first.
+func (s *Store) Pending() []Approval {
+	return s.List(StatusPending)
+}
+
+// persist saves a when the store is backed by a database.
+func (s *Store) persist(a Approval) {
+	if s.db == nil {
+		return
+	}
+	if err := save(s.db, a); err != nil {
+		log.Printf("[approvals] save %s: %v", a.ID, err)
+	}
+}
@@ -0,0 +1,173 @@
+package approvals
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/security"
+
+	_ "modernc.org/sqlite"
+)
+
+// decisions records what the OnDecide callbacks of s are told.
+func decisions(s *Store) <-chan Approval {
+	ch := make(chan Approval, 10)
+	s.OnDecide(func(a Approval) { ch <- a })
+	return ch
+}
+
+func TestStore_Decide(t *testing.T) {
+	s := New(0)
+	decided := decisions(s)
+	pending := s.Request(Approval{Action: "run skill Deploy (sk_1)"})
+	if !strings.HasPrefix(pending.ID, "apr_") || pending.Status != StatusPending {
+		t.Fatalf("pending = %+v", pending)
+	}
+	if got := s.Pending(); len(got) != 1 || got[0].ID != pending.ID {
+		t.Fatalf("Pending() = %+v", got)
+	}
+
+	a, err := s.Decide(pending.ID, false, "bob", "not on a Friday")
+	if err != nil || a.Status != StatusDenied {
+		t.Fatalf("Decide = %+v, %v", a, err)
+	}
+	if got := <-decided; got.ID != pending.ID || got.Status != StatusDenied || got.DecidedBy != "bob" || got.Note != "not on a Friday" {
+		t.Errorf("OnDecide = %+v", got)
+	}
+	if _, err := s.Decide(pending.ID, true, "alice", ""); !errors.Is(err, ErrDecided) {
+		t.Errorf("second decision: err = %v, want ErrDecided", err)
+	}
+	if _, err := s.Decide("apr_nope", true, "alice", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown id: err = %v, want ErrNotFound", err)
+	}
+	if len(s.Pending()) != 0 || len(s.List("")) != 1 || len(decided) != 0 {
+		t.Errorf("pending %d, all %d, extra decisions %d", len(s.Pending()), len(s.List("")), len(decided))
+	}
+}
+
+func TestStore_RequestDecidedAtOnce(t *testing.T) {
+	s := New(0)
+	s.OnRequest(func(a Approval) { s.Decide(a.ID, true, "cli", "") })
+	if a := s.Request(Approval{Action: "x"}); a.Status != StatusApproved || a.DecidedBy != "cli" {
+		t.Errorf("Request = %+v", a)
+	}
+}
+
+func TestStore_Expire(t *testing.T) {
+	s := New(20 * time.Millisecond)
+	decided := decisions(s)
+	pending := s.Request(Approval{Action: "x"})
+	select {
+	case a := <-decided:
+		if a.ID != pending.ID || a.Status != StatusExpired || !strings.Contains(a.Note, "nobody decided") {
+			t.Errorf("timed out: %+v", a)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("approval never expired")
+	}
+	if _, err := s.Decide(pending.ID, true, "bob", ""); !errors.Is(err, ErrDecided) {
+		t.Errorf("decision after expiry: err = %v", err)
+	}
+}
+
+func TestOpen_Persists(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "approvals.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := Open(db, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s.Request(Approval{Action: "deploy"})
+	s.Decide(first.ID, true, "bob", "")
+	// Left pending when the daemon stops.
+	s.Request(Approval{Action: "wipe"})
+
+	s, err = Open(db, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := s.List("")
+	if len(all) != 2 || len(s.Pending()) != 0 {
+		t.Fatalf("reopened = %+v", all)
+	}
+	if a, _ := s.Get(first.ID); a.Status != StatusApproved || a.DecidedBy != "bob" || a.DecidedAt.IsZero() {
+		t.Errorf("decided = %+v", a)
+	}
+	if all[0].Status != StatusExpired || all[0].Note != "the daemon restarted" {
+		t.Errorf("left pending = %+v", all[0])
+	}
+}
+
+func TestStore_Routes(t *testing.T) {
+	s := New(0)
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux, "")
+	decided := decisions(s)
+	pending := s.Request(Approval{Action: "run skill Deploy (sk_1)"})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(security.WithPrincipal(req.Context(), security.Principal{Name: "carol", Role: security.RoleAdmin}))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("GET", "/api/approvals", "")
+	var list struct {
+		Approvals []Approval `json:"approvals"`
+	}
+	if json.Unmarshal(w.Body.Bytes(), &list); len(list.Approvals) != 1 || list.Approvals[0].ID != pending.ID {
+		t.Fatalf("list = %s", w.Body)
+	}
+	if w := do("GET", "/api/approvals/apr_nope", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown: %d", w.Code)
+	}
+	if w := do("POST", "/api/approvals/"+pending.ID+"/maybe", ""); w.Code != http.StatusNotFound {
+		t.Errorf("bad decision: %d", w.Code)
+	}
+
+	// Without a principal or token, only local clients may decide.
+	req := httptest.NewRequest("POST", "/api/approvals/"+pending.ID+"/approve", nil)
+	req.RemoteAddr = "203.0.113.5:1234"
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("remote unauthenticated: %d %s", w.Code, w.Body)
+	}
+	if a, _ := s.Get(pending.ID); a.Status != StatusPending {
+		t.Fatalf("decided without auth: %+v", a)
+	}
+	req = httptest.NewRequest("POST", "/api/approvals/"+pending.ID+"/approve", nil)
+	req = req.WithContext(security.WithPrincipal(req.Context(), security.Principal{Name: "dave", Role: security.RoleMember}))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("member: %d %s", w.Code, w.Body)
+	}
+
+	if w := do("POST", "/api/approvals/"+pending.ID+"/approve", `{"note":"ship it"}`); w.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", w.Code, w.Body)
+	}
+	if got := <-decided; got.Status != StatusApproved || got.DecidedBy != "carol" || got.Note != "ship it" {
+		t.Errorf("decided = %+v", got)
+	}
+	if w := do("POST", "/api/approvals/"+pending.ID+"/deny", ""); w.Code != http.StatusConflict {
+		t.Errorf("decided twice: %d", w.Code)
+	}
+	w = do("GET", "/api/approvals?status=all", "")
+	if json.Unmarshal(w.Body.Bytes(), &list); len(list.Approvals) != 1 || list.Approvals[0].Status != StatusApproved {
+		t.Errorf("all = %s", w.Body)
+	}
+}
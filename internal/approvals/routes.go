@@ -0,0 +1,72 @@
+package approvals
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// RegisterRoutes serves the approvals for oversight.
+// Routes: GET /api/approvals, GET /api/approvals/{id},
+// POST /api/approvals/{id}/approve, POST /api/approvals/{id}/deny
+//
+// The list holds the pending approvals; ?status=all (or approved, denied,
+// expired) lists the recent ones. Decisions need an admin (see
+// security.AuthorizeAdmin), take an optional {"note": "..."} body and are
+// made in the caller's name.
+func (s *Store) RegisterRoutes(mux *http.ServeMux, adminToken string) {
+	mux.HandleFunc("GET /api/approvals", func(w http.ResponseWriter, r *http.Request) {
+		status := Status(r.URL.Query().Get("status"))
+		switch status {
+		case "":
+			status = StatusPending
+		case "all":
+			status = ""
+		}
+		writeApprovalsJSON(w, http.StatusOK, map[string]any{"approvals": s.List(status)})
+	})
+	mux.HandleFunc("GET /api/approvals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		a, ok := s.Get(r.PathValue("id"))
+		if !ok {
+			writeApprovalsJSON(w, http.StatusNotFound, map[string]string{"error": "approval not found"})
+			return
+		}
+		writeApprovalsJSON(w, http.StatusOK, a)
+	})
+	mux.HandleFunc("POST /api/approvals/{id}/{decision}", func(w http.ResponseWriter, r *http.Request) {
+		if !security.AuthorizeAdmin(r, adminToken) {
+			writeApprovalsJSON(w, http.StatusForbidden, map[string]string{"error": "decisions need the admin role"})
+			return
+		}
+		decision := r.PathValue("decision")
+		if decision != "approve" && decision != "deny" {
+			writeApprovalsJSON(w, http.StatusNotFound, map[string]string{"error": "unknown decision (approve or deny)"})
+			return
+		}
+		var body struct {
+			Note string `json:"note"`
+		}
+		json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body)
+		by := "admin"
+		if p, ok := security.PrincipalFrom(r.Context()); ok {
+			by = p.Name
+		}
+		a, err := s.Decide(r.PathValue("id"), decision == "approve", by, body.Note)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			writeApprovalsJSON(w, http.StatusNotFound, map[string]string{"error": "approval not found"})
+		case errors.Is(err, ErrDecided):
+			writeApprovalsJSON(w, http.StatusConflict, map[string]string{"error": "approval is already " + string(a.Status)})
+		default:
+			writeApprovalsJSON(w, http.StatusOK, a)
+		}
+	})
+}
+
+func writeApprovalsJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
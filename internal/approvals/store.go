@@ -0,0 +1,79 @@
+package approvals
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Open creates a store like New whose approvals are kept in db, in the
+// approvals table, so their history survives a restart. Approvals still
+// pending from an earlier run expire: the tasks parked on them are gone.
+func Open(db *sql.DB, timeout time.Duration) (*Store, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS approvals (
+		id         TEXT PRIMARY KEY,
+		task_id    TEXT NOT NULL DEFAULT '',
+		requester  TEXT NOT NULL DEFAULT '',
+		rule       TEXT NOT NULL DEFAULT '',
+		action     TEXT NOT NULL DEFAULT '',
+		detail     TEXT NOT NULL DEFAULT '',
+		status     TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		decided_at TEXT NOT NULL DEFAULT '',
+		decided_by TEXT NOT NULL DEFAULT '',
+		note       TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_approvals_created ON approvals(created_at);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("approvals: create table: %w", err)
+	}
+	_, err := db.Exec(`UPDATE approvals SET status = ?, decided_at = ?, note = ? WHERE status = ?`,
+		StatusExpired, time.Now().UTC().Format(time.RFC3339Nano), "the daemon restarted", StatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("approvals: expire pending: %w", err)
+	}
+
+	rows, err := db.Query(`
+	SELECT id, task_id, requester, rule, action, detail, status, created_at, decided_at, decided_by, note
+	FROM approvals ORDER BY created_at DESC LIMIT ?`, historySize)
+	if err != nil {
+		return nil, fmt.Errorf("approvals: load: %w", err)
+	}
+	defer rows.Close()
+
+	s := New(timeout)
+	for rows.Next() {
+		var a Approval
+		var created, decided string
+		if err := rows.Scan(&a.ID, &a.TaskID, &a.Requester, &a.Rule, &a.Action, &a.Detail,
+			&a.Status, &created, &decided, &a.DecidedBy, &a.Note); err != nil {
+			return nil, fmt.Errorf("approvals: load: %w", err)
+		}
+		a.CreatedAt, _ = time.Parse(time.RFC3339Nano, created)
+		a.DecidedAt, _ = time.Parse(time.RFC3339Nano, decided)
+		s.items[a.ID] = &a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("approvals: load: %w", err)
+	}
+	s.db = db
+	return s, nil
+}
+
+// save inserts or updates a.
+func save(db *sql.DB, a Approval) error {
+	decided := ""
+	if !a.DecidedAt.IsZero() {
+		decided = a.DecidedAt.Format(time.RFC3339Nano)
+	}
+	_, err := db.Exec(`
+	INSERT INTO approvals (id, task_id, requester, rule, action, detail, status, created_at, decided_at, decided_by, note)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		status = excluded.status, decided_at = excluded.decided_at,
+		decided_by = excluded.decided_by, note = excluded.note`,
+		a.ID, a.TaskID, a.Requester, a.Rule, a.Action, a.Detail, a.Status,
+		a.CreatedAt.Format(time.RFC3339Nano), decided, a.DecidedBy, a.Note)
+	return err
+}
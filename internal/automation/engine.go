@@ -0,0 +1,747 @@
+// Package automation evaluates declarative "when X then Y" rules in the
+// daemon. Rules cover the simple glue cases — forward matching emails to a
+// skill, run a saved prompt every morning, ping the UI when a task family
+// completes — without the LLM generating a code-skill for them.
+//
+// Rules come from two places:
+//   - automations.yaml in the data directory, written by the user and
+//     reloaded when it changes (read-only over the API)
+//   - the HTTP API, persisted to automations.json
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/mcp"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/internal/yamlite"
+)
+
+// File names in the data directory.
+const (
+	FileName    = "automations.yaml"
+	APIFileName = "automations.json"
+)
+
+// ExtraRule marks inputs an automation submitted to the pipeline with the
+// rule's name. Sense rules ignore such inputs so rules cannot loop.
+const ExtraRule = "automation_rule"
+
+// Where a rule was defined.
+const (
+	SourceFile = "file"
+	SourceAPI  = "api"
+)
+
+// Trigger says when a rule fires. Exactly one kind is set: a sense, a
+// schedule (Every or At) or a pattern fingerprint.
+type Trigger struct {
+	// Sense fires on inputs from a channel ("email", "telegram", ... or
+	// "*" for any), optionally filtered by Contains, Match, Sender and
+	// Channel.
+	Sense string `json:"sense,omitempty"`
+
+	// Every fires at a fixed interval (Go duration, at least a minute);
+	// At fires daily at "HH:MM" in the agent's timezone.
+	Every string `json:"every,omitempty"`
+	At    string `json:"at,omitempty"`
+
+	// Fingerprint fires when a task of that pattern completes; Contains
+	// and Match then filter its input.
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	Contains string `json:"contains,omitempty"` // case-insensitive substring of the payload
+	Match    string `json:"match,omitempty"`    // regular expression on the payload
+	Sender   string `json:"sender,omitempty"`   // exact sender
+	Channel  string `json:"channel,omitempty"`  // exact channel (chat, mailbox, ...)
+}
+
+// Action is one step of a rule. Exactly one of Template, Run, Skill, Tool
+// or Notify is set. Text fields and Params accept the placeholders
+// {{rule}}, {{source}}, {{sender}}, {{channel}}, {{payload}},
+// {{fingerprint}}, {{time}} and {{result}} — the output of the previous
+// skill or tool action.
+type Action struct {
+	Template string `json:"template,omitempty"` // saved prompt, queued for the pipeline
+	Run      string `json:"run,omitempty"`      // input text, queued for the pipeline
+	Skill    string `json:"skill,omitempty"`    // skill ID, called directly
+	Tool     string `json:"tool,omitempty"`     // MCP tool, "name" or "server/name"
+	Notify   string `json:"notify,omitempty"`   // message for the log and connected UIs
+
+	Goal   string `json:"goal,omitempty"`   // skill goal (default: the payload)
+	Params Params `json:"params,omitempty"` // skill parameters or tool arguments
+}
+
+// Params are action parameters. Numbers and bools are accepted and kept
+// as text, since YAML users write "limit: 5".
+type Params map[string]string
+
+// UnmarshalJSON accepts scalar values of any JSON type.
+func (p *Params) UnmarshalJSON(data []byte) error {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = make(Params, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case string:
+			(*p)[k] = v
+		case nil:
+			(*p)[k] = ""
+		case bool, float64:
+			(*p)[k] = fmt.Sprint(v)
+		default:
+			return fmt.Errorf("param %q: expected a scalar", k)
+		}
+	}
+	return nil
+}
+
+// Rule is a named trigger and the actions it runs.
+type Rule struct {
+	Name     string   `json:"name"`
+	When     Trigger  `json:"when"`
+	Then     []Action `json:"then"`
+	Consume  bool     `json:"consume,omitempty"` // sense rules: matched inputs skip the pipeline
+	Disabled bool     `json:"disabled,omitempty"`
+	Source   string   `json:"source,omitempty"` // SourceFile or SourceAPI, set on load
+
+	re    *regexp.Regexp
+	every time.Duration
+	at    int // minutes after midnight, -1 = unset
+}
+
+// Event is what fired a rule; it fills action placeholders.
+type Event struct {
+	Rule        string
+	Source      string
+	Sender      string
+	Channel     string
+	Payload     string
+	Fingerprint string
+	Result      string
+	Time        time.Time
+}
+
+// Handlers connect actions to the rest of the daemon. Nil handlers make
+// the matching actions fail with an error.
+type Handlers struct {
+	Submit      func(*senses.UnifiedInput) error // queue an input for the pipeline
+	Notify      func(msg string)
+	Templates   map[string]string
+	Skills      *instruments.SkillRegistry
+	Permissions *security.PermissionStore // "never" decisions block skill actions
+	Tools       *mcp.Registry
+	Mode        *senses.ModeSwitch // skill and tool actions are refused while read-only
+	Location    *time.Location     // for At; nil = local time
+	Fired       func(Event)        // observes every firing, before its actions run
+}
+
+// Engine holds the rules and runs them. Safe for concurrent use.
+type Engine struct {
+	mu       sync.RWMutex
+	dir      string
+	fileMod  time.Time
+	file     []Rule
+	api      []Rule
+	lastRun  map[string]time.Time // schedule bookkeeping, by rule name
+	started  time.Time
+	h        Handlers
+	inflight sync.WaitGroup
+}
+
+// New loads the rules in dir. Missing files mean no rules.
+func New(dir string, h Handlers) (*Engine, error) {
+	if h.Location == nil {
+		h.Location = time.Local
+	}
+	e := &Engine{dir: dir, h: h, lastRun: make(map[string]time.Time), started: time.Now()}
+	if err := e.reloadFile(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, APIFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("automations: read: %w", err)
+	}
+	if err == nil {
+		var rules []Rule
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("automations: parse %s: %w", APIFileName, err)
+		}
+		for i := range rules {
+			rules[i].Source = SourceAPI
+			if err := e.compile(&rules[i]); err != nil {
+				return nil, fmt.Errorf("automations: %s: %w", APIFileName, err)
+			}
+		}
+		e.api = rules
+	}
+	return e, nil
+}
+
+// reloadFile re-reads automations.yaml when its modification time changed.
+// A broken file keeps the previous rules.
+func (e *Engine) reloadFile() error {
+	path := filepath.Join(e.dir, FileName)
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		e.mu.Lock()
+		e.file, e.fileMod = nil, time.Time{}
+		e.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("automations: %w", err)
+	}
+	e.mu.RLock()
+	same := fi.ModTime().Equal(e.fileMod)
+	e.mu.RUnlock()
+	if same {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("automations: read: %w", err)
+	}
+	rules, err := e.parseFile(data)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fileMod = fi.ModTime() // a broken version is reported once, not every tick
+	if err != nil {
+		return fmt.Errorf("automations: %s: %w", FileName, err)
+	}
+	e.file = rules
+	return nil
+}
+
+// parseFile decodes and validates the rules of automations.yaml.
+func (e *Engine) parseFile(data []byte) ([]Rule, error) {
+	var doc struct {
+		Rules []Rule `json:"rules"`
+	}
+	if err := yamlite.Decode(data, &doc); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(doc.Rules))
+	for i := range doc.Rules {
+		doc.Rules[i].Source = SourceFile
+		if err := e.compile(&doc.Rules[i]); err != nil {
+			return nil, err
+		}
+		if seen[doc.Rules[i].Name] {
+			return nil, fmt.Errorf("duplicate rule %q", doc.Rules[i].Name)
+		}
+		seen[doc.Rules[i].Name] = true
+	}
+	return doc.Rules, nil
+}
+
+// compile validates r and fills its parsed fields.
+func (e *Engine) compile(r *Rule) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || strings.ContainsAny(r.Name, "/?#") {
+		return fmt.Errorf("rule %q: name must be non-empty and free of / ? #", r.Name)
+	}
+	w := r.When
+	kinds := 0
+	for _, set := range []bool{w.Sense != "", w.Every != "" || w.At != "", w.Fingerprint != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("rule %q: when needs exactly one of sense, every/at or fingerprint", r.Name)
+	}
+	if w.Sense != "" && w.Sense != "*" && senses.ParseSourceType(w.Sense) == "" {
+		return fmt.Errorf("rule %q: unknown sense %q", r.Name, w.Sense)
+	}
+	r.every, r.at = 0, -1
+	if w.Every != "" && w.At != "" {
+		return fmt.Errorf("rule %q: use either every or at", r.Name)
+	}
+	if w.Every != "" {
+		d, err := time.ParseDuration(w.Every)
+		if err != nil || d < time.Minute {
+			return fmt.Errorf("rule %q: every must be a duration of at least 1m", r.Name)
+		}
+		r.every = d
+	}
+	if w.At != "" {
+		t, err := time.Parse("15:04", w.At)
+		if err != nil {
+			return fmt.Errorf("rule %q: at must be HH:MM", r.Name)
+		}
+		r.at = t.Hour()*60 + t.Minute()
+	}
+	r.re = nil
+	if w.Match != "" {
+		re, err := regexp.Compile(w.Match)
+		if err != nil {
+			return fmt.Errorf("rule %q: match: %w", r.Name, err)
+		}
+		r.re = re
+	}
+	if len(r.Then) == 0 {
+		return fmt.Errorf("rule %q: then needs at least one action", r.Name)
+	}
+	for i, a := range r.Then {
+		n := 0
+		for _, s := range []string{a.Template, a.Run, a.Skill, a.Tool, a.Notify} {
+			if s != "" {
+				n++
+			}
+		}
+		if n != 1 {
+			return fmt.Errorf("rule %q: action %d needs exactly one of template, run, skill, tool or notify", r.Name, i+1)
+		}
+		if a.Template != "" && e.h.Templates != nil {
+			if _, ok := e.h.Templates[a.Template]; !ok {
+				return fmt.Errorf("rule %q: unknown template %q", r.Name, a.Template)
+			}
+		}
+	}
+	return nil
+}
+
+// Rules returns all rules, file rules first, each group sorted by name.
+func (e *Engine) Rules() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rulesLocked()
+}
+
+func (e *Engine) rulesLocked() []Rule {
+	out := make([]Rule, 0, len(e.file)+len(e.api))
+	for _, group := range [][]Rule{e.file, e.api} {
+		start := len(out)
+		out = append(out, group...)
+		sort.Slice(out[start:], func(i, j int) bool { return out[start+i].Name < out[start+j].Name })
+	}
+	return out
+}
+
+// Put adds or replaces an API rule. Rules from automations.yaml cannot be
+// replaced this way.
+func (e *Engine) Put(r Rule) error {
+	r.Source = SourceAPI
+	if err := e.compile(&r); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, f := range e.file {
+		if f.Name == r.Name {
+			return fmt.Errorf("rule %q is defined in %s", r.Name, FileName)
+		}
+	}
+	replaced := false
+	for i := range e.api {
+		if e.api[i].Name == r.Name {
+			e.api[i], replaced = r, true
+		}
+	}
+	if !replaced {
+		e.api = append(e.api, r)
+	}
+	delete(e.lastRun, r.Name)
+	return e.saveLocked()
+}
+
+// Delete removes an API rule and reports whether it existed.
+func (e *Engine) Delete(name string) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range e.api {
+		if e.api[i].Name == name {
+			e.api = append(e.api[:i], e.api[i+1:]...)
+			delete(e.lastRun, name)
+			return true, e.saveLocked()
+		}
+	}
+	return false, nil
+}
+
+// saveLocked writes the API rules atomically. Caller holds e.mu.
+func (e *Engine) saveLocked() error {
+	data, err := json.MarshalIndent(e.api, "", "  ")
+	if err != nil {
+		return fmt.Errorf("automations: encode: %w", err)
+	}
+	if err := os.MkdirAll(e.dir, 0o755); err != nil {
+		return fmt.Errorf("automations: mkdir: %w", err)
+	}
+	path := filepath.Join(e.dir, APIFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("automations: write: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// OnInput fires the sense rules matching in and reports whether one of
+// them consumed it, in which case the caller should not run the pipeline.
+// Actions run in the background.
+func (e *Engine) OnInput(ctx context.Context, in *senses.UnifiedInput) (consumed bool) {
+	if in.SourceMeta.Extra[ExtraRule] != "" {
+		return false
+	}
+	for _, r := range e.Rules() {
+		if r.Disabled || r.When.Sense == "" || !matchesInput(r, in) {
+			continue
+		}
+		consumed = consumed || r.Consume
+		e.fire(ctx, r, eventFor(r.Name, in))
+	}
+	return consumed
+}
+
+// OnResult fires the fingerprint rules for a completed task.
+func (e *Engine) OnResult(ctx context.Context, in *senses.UnifiedInput, fingerprint, result string) {
+	if fingerprint == "" || in.SourceMeta.Extra[ExtraRule] != "" {
+		return
+	}
+	for _, r := range e.Rules() {
+		if r.Disabled || r.When.Fingerprint != fingerprint || !matchesInput(r, in) {
+			continue
+		}
+		ev := eventFor(r.Name, in)
+		ev.Fingerprint = fingerprint
+		ev.Result = result
+		e.fire(ctx, r, ev)
+	}
+}
+
+func matchesInput(r Rule, in *senses.UnifiedInput) bool {
+	w := r.When
+	if w.Sense != "" && w.Sense != "*" && senses.ParseSourceType(w.Sense) != in.SourceType {
+		return false
+	}
+	if w.Sender != "" && w.Sender != in.SourceMeta.Sender {
+		return false
+	}
+	if w.Channel != "" && w.Channel != in.SourceMeta.Channel {
+		return false
+	}
+	if w.Contains != "" && !strings.Contains(strings.ToLower(in.Payload), strings.ToLower(w.Contains)) {
+		return false
+	}
+	return r.re == nil || r.re.MatchString(in.Payload)
+}
+
+func eventFor(rule string, in *senses.UnifiedInput) Event {
+	return Event{
+		Rule:    rule,
+		Source:  strings.ToLower(string(in.SourceType)),
+		Sender:  in.SourceMeta.Sender,
+		Channel: in.SourceMeta.Channel,
+		Payload: in.Payload,
+		Time:    time.Now(),
+	}
+}
+
+// Run fires schedule rules and picks up edits to automations.yaml until
+// ctx is done.
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.inflight.Wait()
+			return
+		case now := <-ticker.C:
+			if err := e.reloadFile(); err != nil {
+				log.Printf("[automation] %v (keeping previous rules)", err)
+			}
+			e.tick(ctx, now)
+		}
+	}
+}
+
+// tick fires the schedule rules due at now.
+func (e *Engine) tick(ctx context.Context, now time.Time) {
+	e.mu.Lock()
+	var due []Rule
+	for _, r := range e.rulesLocked() {
+		if r.Disabled || (r.every == 0 && r.at < 0) {
+			continue
+		}
+		last, ok := e.lastRun[r.Name]
+		if !ok {
+			last = e.started
+		}
+		if scheduleDue(r, last, now, e.h.Location) {
+			e.lastRun[r.Name] = now
+			due = append(due, r)
+		}
+	}
+	e.mu.Unlock()
+	for _, r := range due {
+		e.fire(ctx, r, Event{Rule: r.Name, Source: "schedule", Time: now})
+	}
+}
+
+// scheduleDue reports whether r should fire at now, given it last fired
+// (or the engine started) at last. A daily time that passed while the
+// daemon was down is not caught up.
+func scheduleDue(r Rule, last, now time.Time, loc *time.Location) bool {
+	if r.every > 0 {
+		return now.Sub(last) >= r.every
+	}
+	local := now.In(loc)
+	y, m, d := local.Date()
+	at := time.Date(y, m, d, r.at/60, r.at%60, 0, 0, loc)
+	return !now.Before(at) && last.Before(at)
+}
+
+// Fire runs the actions of the named rule now, as the API's "run" does.
+func (e *Engine) Fire(ctx context.Context, name string) error {
+	for _, r := range e.Rules() {
+		if r.Name == name {
+			e.fire(ctx, r, Event{Rule: name, Source: "manual", Time: time.Now()})
+			return nil
+		}
+	}
+	return fmt.Errorf("rule %q not found", name)
+}
+
+// fire runs r's actions in order in the background. A failing action stops
+// the rest and is reported through Notify.
+func (e *Engine) fire(ctx context.Context, r Rule, ev Event) {
+	e.inflight.Add(1)
+	go func() {
+		defer e.inflight.Done()
+		log.Printf("[automation] %s fired (%s)", r.Name, ev.Source)
+		if e.h.Fired != nil {
+			e.h.Fired(ev)
+		}
+		for i, a := range r.Then {
+			if err := e.do(ctx, a, &ev); err != nil {
+				msg := fmt.Sprintf("Automation %q: action %d failed: %v", r.Name, i+1, err)
+				log.Printf("[automation] %s", msg)
+				if e.h.Notify != nil {
+					e.h.Notify(msg)
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Wait blocks until actions in flight have finished.
+func (e *Engine) Wait() { e.inflight.Wait() }
+
+func (e *Engine) do(ctx context.Context, a Action, ev *Event) error {
+	switch {
+	case a.Notify != "":
+		if e.h.Notify == nil {
+			return errors.New("notifications are not available")
+		}
+		e.h.Notify(expand(a.Notify, *ev))
+		return nil
+
+	case a.Template != "", a.Run != "":
+		text := a.Run
+		if a.Template != "" {
+			t, ok := e.h.Templates[a.Template]
+			if !ok {
+				return fmt.Errorf("unknown template %q", a.Template)
+			}
+			text = t
+		}
+		if e.h.Submit == nil {
+			return errors.New("the pipeline is not available")
+		}
+		in := senses.NewUnifiedInput(senses.SourceAPI, expand(text, *ev))
+		in.SourceMeta.Extra = map[string]string{ExtraRule: ev.Rule}
+		return e.h.Submit(in)
+
+	case a.Skill != "":
+		if e.h.Mode.ReadOnly() {
+			log.Printf("[automation] %s: read-only mode: not running skill %s", ev.Rule, a.Skill)
+			return fmt.Errorf("skill %s: the daemon is in %s mode", a.Skill, e.h.Mode.Mode())
+		}
+		if e.h.Skills == nil {
+			return errors.New("skills are not available")
+		}
+		skill := e.h.Skills.Get(a.Skill)
+		if skill == nil {
+			return fmt.Errorf("unknown skill %q", a.Skill)
+		}
+		if skill.Meta.Doc != nil {
+			for _, perm := range skill.Meta.Doc.Permissions {
+				if d, ok := e.h.Permissions.Decision(skill.Meta.ID, perm); ok && d == security.PermissionNever {
+					return fmt.Errorf("skill %s: permission %q is denied", a.Skill, perm)
+				}
+			}
+		}
+		goal := ev.Payload
+		if a.Goal != "" {
+			goal = expand(a.Goal, *ev)
+		}
+		out, err := skill.Executor.Execute(ctx, instruments.SkillInput{
+			Goal:       goal,
+			Context:    "automation " + ev.Rule,
+			Parameters: expandParams(a.Params, *ev),
+		})
+		if err != nil {
+			return err
+		}
+		skill.RecordRun(out)
+		if !out.Success {
+			return fmt.Errorf("skill %s: %s", a.Skill, out.Error)
+		}
+		ev.Result = out.Result
+		return nil
+
+	case a.Tool != "":
+		if e.h.Mode.ReadOnly() {
+			log.Printf("[automation] %s: read-only mode: not calling tool %s", ev.Rule, a.Tool)
+			return fmt.Errorf("tool %s: the daemon is in %s mode", a.Tool, e.h.Mode.Mode())
+		}
+		if e.h.Tools == nil {
+			return errors.New("no MCP servers are configured")
+		}
+		server, tool, ok := strings.Cut(a.Tool, "/")
+		if !ok {
+			tool = a.Tool
+			if server, _, ok = e.h.Tools.FindTool(tool); !ok {
+				return fmt.Errorf("tool %q not found on any connected MCP server", tool)
+			}
+		}
+		args := make(map[string]any, len(a.Params))
+		for k, v := range expandParams(a.Params, *ev) {
+			args[k] = v
+		}
+		res, err := e.h.Tools.CallTool(ctx, server, tool, args)
+		if err != nil {
+			return err
+		}
+		var text []string
+		for _, c := range res.Content {
+			if c.Text != "" {
+				text = append(text, c.Text)
+			}
+		}
+		if res.IsError {
+			return fmt.Errorf("tool %s: %s", a.Tool, strings.Join(text, "\n"))
+		}
+		ev.Result = strings.Join(text, "\n")
+		return nil
+	}
+	return errors.New("empty action")
+}
+
+func expand(s string, ev Event) string {
+	return strings.NewReplacer(
+		"{{rule}}", ev.Rule,
+		"{{source}}", ev.Source,
+		"{{sender}}", ev.Sender,
+		"{{channel}}", ev.Channel,
+		"{{payload}}", ev.Payload,
+		"{{fingerprint}}", ev.Fingerprint,
+		"{{result}}", ev.Result,
+		"{{time}}", ev.Time.Format(time.RFC3339),
+	).Replace(s)
+}
+
+func expandParams(p Params, ev Event) map[string]string {
+	if len(p) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(p))
+	for k, v := range p {
+		out[k] = expand(v, ev)
+	}
+	return out
+}
+
+// RegisterRoutes exposes the rules.
+// Routes: GET /api/automations, POST /api/automations (add or replace),
+// DELETE /api/automations/{name}, POST /api/automations/{name}/run
+func (e *Engine) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/automations", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"rules": e.Rules()})
+	})
+	mux.HandleFunc("POST /api/automations", func(w http.ResponseWriter, r *http.Request) {
+		var rule Rule
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&rule); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+			return
+		}
+		if err := e.Put(rule); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"saved": rule.Name})
+	})
+	mux.HandleFunc("DELETE /api/automations/{name}", func(w http.ResponseWriter, r *http.Request) {
+		ok, err := e.Delete(r.PathValue("name"))
+		switch {
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		case !ok:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "rule not found (rules in " + FileName + " are edited there)"})
+		default:
+			writeJSON(w, http.StatusOK, map[string]int{"deleted": 1})
+		}
+	})
+	mux.HandleFunc("POST /api/automations/{name}/run", func(w http.ResponseWriter, r *http.Request) {
+		// The request context ends with the response; actions outlive it.
+		if err := e.Fire(context.WithoutCancel(r.Context()), r.PathValue("name")); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"fired": r.PathValue("name")})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// String summarizes the trigger for listings.
+func (t Trigger) String() string {
+	var s string
+	switch {
+	case t.Sense != "":
+		s = "sense " + t.Sense
+	case t.Every != "":
+		s = "every " + t.Every
+	case t.At != "":
+		s = "daily at " + t.At
+	case t.Fingerprint != "":
+		s = "pattern " + t.Fingerprint
+	}
+	if t.Sender != "" {
+		s += " from " + strconv.Quote(t.Sender)
+	}
+	if t.Channel != "" {
+		s += " in " + strconv.Quote(t.Channel)
+	}
+	if t.Contains != "" {
+		s += " containing " + strconv.Quote(t.Contains)
+	}
+	if t.Match != "" {
+		s += " matching /" + t.Match + "/"
+	}
+	return s
+}
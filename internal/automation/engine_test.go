@@ -0,0 +1,352 @@
+package automation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+type echoSkill struct {
+	mu    sync.Mutex
+	calls []instruments.SkillInput
+}
+
+func (s *echoSkill) Execute(_ context.Context, in instruments.SkillInput) (*instruments.SkillOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, in)
+	return &instruments.SkillOutput{Result: "ticket " + in.Parameters["title"], Success: true}, nil
+}
+
+// recorder collects notifications and submitted inputs.
+type recorder struct {
+	mu        sync.Mutex
+	notes     []string
+	submitted []*senses.UnifiedInput
+}
+
+func (r *recorder) handlers() Handlers {
+	return Handlers{
+		Notify: func(msg string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.notes = append(r.notes, msg)
+		},
+		Submit: func(in *senses.UnifiedInput) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.submitted = append(r.submitted, in)
+			return nil
+		},
+		Templates: map[string]string{"digest": "Summarize today's inbox"},
+	}
+}
+
+const testRules = `rules:
+  - name: invoices
+    when:
+      sense: email
+      contains: invoice
+      match: '#\d+'
+    then:
+      - skill: tracker
+        params:
+          title: "{{sender}}: {{payload}}"
+          limit: 5
+      - notify: "filed {{result}}"
+    consume: true
+  - name: after-report
+    when:
+      fingerprint: fp-report
+    then:
+      - template: digest
+`
+
+func writeRules(t *testing.T, dir, src string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_SenseRule(t *testing.T) {
+	dir := t.TempDir()
+	writeRules(t, dir, testRules)
+	skill := &echoSkill{}
+	rec := &recorder{}
+	h := rec.handlers()
+	h.Skills = instruments.NewSkillRegistry()
+	h.Skills.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "tracker", Name: "Tracker"}, Executor: skill})
+	var fired []Event
+	h.Fired = func(ev Event) { fired = append(fired, ev) }
+	e, err := New(dir, h)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	other := senses.NewUnifiedInput(senses.SourceEmail, "lunch on friday?")
+	if e.OnInput(context.Background(), other) {
+		t.Error("non-matching input consumed")
+	}
+	in := senses.NewUnifiedInput(senses.SourceEmail, "Invoice #42 attached")
+	in.SourceMeta.Sender = "billing@acme.test"
+	if !e.OnInput(context.Background(), in) {
+		t.Error("matching input not consumed")
+	}
+	e.Wait()
+
+	if len(skill.calls) != 1 {
+		t.Fatalf("skill calls = %d, want 1", len(skill.calls))
+	}
+	call := skill.calls[0]
+	if call.Goal != in.Payload || call.Parameters["title"] != "billing@acme.test: Invoice #42 attached" || call.Parameters["limit"] != "5" {
+		t.Errorf("skill input = %+v", call)
+	}
+	if len(rec.notes) != 1 || rec.notes[0] != "filed ticket billing@acme.test: Invoice #42 attached" {
+		t.Errorf("notes = %q", rec.notes)
+	}
+	if len(fired) != 1 || fired[0].Sender != "billing@acme.test" || fired[0].Rule == "" {
+		t.Errorf("fired = %+v", fired)
+	}
+}
+
+func TestEngine_FingerprintRuleAndLoopGuard(t *testing.T) {
+	dir := t.TempDir()
+	writeRules(t, dir, testRules)
+	rec := &recorder{}
+	e, err := New(dir, rec.handlers())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	in := senses.NewUnifiedInput(senses.SourceTelegram, "weekly report")
+	e.OnResult(context.Background(), in, "fp-other", "done")
+	e.OnResult(context.Background(), in, "fp-report", "done")
+	e.Wait()
+	if len(rec.submitted) != 1 {
+		t.Fatalf("submitted = %d, want 1", len(rec.submitted))
+	}
+	sub := rec.submitted[0]
+	if sub.Payload != "Summarize today's inbox" || sub.SourceMeta.Extra[ExtraRule] != "after-report" {
+		t.Errorf("submitted input = %+v", sub)
+	}
+
+	// The submitted input completing the same pattern must not fire again.
+	e.OnResult(context.Background(), sub, "fp-report", "done")
+	e.Wait()
+	if len(rec.submitted) != 1 {
+		t.Errorf("automation input re-fired its rule")
+	}
+}
+
+func TestEngine_SkillPermissionDenied(t *testing.T) {
+	dir := t.TempDir()
+	writeRules(t, dir, testRules)
+	rec := &recorder{}
+	h := rec.handlers()
+	skill := &echoSkill{}
+	h.Skills = instruments.NewSkillRegistry()
+	h.Skills.Register(&instruments.Skill{
+		Meta:     instruments.SkillMeta{ID: "tracker", Doc: &instruments.SkillDoc{Permissions: []string{instruments.PermNetwork}}},
+		Executor: skill,
+	})
+	store, err := security.NewPermissionStore(filepath.Join(dir, "permissions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("tracker", instruments.PermNetwork, security.PermissionNever); err != nil {
+		t.Fatal(err)
+	}
+	h.Permissions = store
+	e, err := New(dir, h)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	e.OnInput(context.Background(), senses.NewUnifiedInput(senses.SourceEmail, "invoice #7"))
+	e.Wait()
+	if len(skill.calls) != 0 {
+		t.Error("denied skill was called")
+	}
+	if len(rec.notes) != 1 || !strings.Contains(rec.notes[0], "denied") {
+		t.Errorf("notes = %q, want a failure notice", rec.notes)
+	}
+}
+
+func TestEngine_ReadOnlyRefusesSkills(t *testing.T) {
+	dir := t.TempDir()
+	writeRules(t, dir, testRules)
+	rec := &recorder{}
+	h := rec.handlers()
+	skill := &echoSkill{}
+	h.Skills = instruments.NewSkillRegistry()
+	h.Skills.Register(&instruments.Skill{Meta: instruments.SkillMeta{ID: "tracker"}, Executor: skill})
+	h.Mode = senses.NewModeSwitch()
+	e, err := New(dir, h)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, mode := range []senses.DaemonMode{senses.ModeReadOnly, senses.ModeMaintenance} {
+		h.Mode.Set(mode, "")
+		rec.mu.Lock()
+		rec.notes = nil
+		rec.mu.Unlock()
+		e.OnInput(context.Background(), senses.NewUnifiedInput(senses.SourceEmail, "invoice #7"))
+		e.Wait()
+		if len(skill.calls) != 0 {
+			t.Fatalf("%s: skill was called", mode)
+		}
+		if len(rec.notes) != 1 || !strings.Contains(rec.notes[0], string(mode)+" mode") {
+			t.Errorf("%s: notes = %q, want a refusal", mode, rec.notes)
+		}
+	}
+
+	h.Mode.Set(senses.ModeNormal, "")
+	e.OnInput(context.Background(), senses.NewUnifiedInput(senses.SourceEmail, "invoice #7"))
+	e.Wait()
+	if len(skill.calls) != 1 {
+		t.Errorf("normal mode: %d skill calls, want 1", len(skill.calls))
+	}
+}
+
+func TestEngine_Schedule(t *testing.T) {
+	loc := time.UTC
+	daily := Rule{Name: "d", When: Trigger{At: "08:30"}, Then: []Action{{Notify: "x"}}}
+	every := Rule{Name: "e", When: Trigger{Every: "15m"}, Then: []Action{{Notify: "x"}}}
+	e := &Engine{h: Handlers{Location: loc}}
+	for _, r := range []*Rule{&daily, &every} {
+		if err := e.compile(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	for _, tc := range []struct {
+		r         Rule
+		last, now string
+		want      bool
+	}{
+		{daily, "2026-03-02 08:00", "2026-03-02 08:29", false},
+		{daily, "2026-03-02 08:00", "2026-03-02 08:31", true},
+		{daily, "2026-03-02 08:31", "2026-03-02 12:00", false}, // already fired today
+		{daily, "2026-03-02 08:31", "2026-03-03 08:30", true},
+		{every, "2026-03-02 08:00", "2026-03-02 08:14", false},
+		{every, "2026-03-02 08:00", "2026-03-02 08:15", true},
+	} {
+		if got := scheduleDue(tc.r, at(tc.last), at(tc.now), loc); got != tc.want {
+			t.Errorf("%s last=%s now=%s: due = %v, want %v", tc.r.When, tc.last, tc.now, got, tc.want)
+		}
+	}
+}
+
+func TestEngine_Validation(t *testing.T) {
+	e := &Engine{h: Handlers{Templates: map[string]string{"t": "x"}}}
+	notify := []Action{{Notify: "x"}}
+	for name, r := range map[string]Rule{
+		"no name":          {When: Trigger{Sense: "email"}, Then: notify},
+		"no trigger":       {Name: "a", Then: notify},
+		"two triggers":     {Name: "a", When: Trigger{Sense: "email", Every: "1h"}, Then: notify},
+		"unknown sense":    {Name: "a", When: Trigger{Sense: "fax"}, Then: notify},
+		"short interval":   {Name: "a", When: Trigger{Every: "10s"}, Then: notify},
+		"bad time":         {Name: "a", When: Trigger{At: "25:00"}, Then: notify},
+		"bad regexp":       {Name: "a", When: Trigger{Sense: "*", Match: "("}, Then: notify},
+		"no actions":       {Name: "a", When: Trigger{Sense: "*"}},
+		"two-kind action":  {Name: "a", When: Trigger{Sense: "*"}, Then: []Action{{Notify: "x", Run: "y"}}},
+		"unknown template": {Name: "a", When: Trigger{Sense: "*"}, Then: []Action{{Template: "nope"}}},
+	} {
+		if err := e.compile(&r); err == nil {
+			t.Errorf("%s: compile succeeded", name)
+		}
+	}
+}
+
+func TestEngine_APIRules(t *testing.T) {
+	dir := t.TempDir()
+	writeRules(t, dir, testRules)
+	rec := &recorder{}
+	e, err := New(dir, rec.handlers())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	mux := http.NewServeMux()
+	e.RegisterRoutes(mux)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := do("POST", "/api/automations", `{"name":"invoices","when":{"sense":"*"},"then":[{"notify":"x"}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("replacing a file rule: status %d", w.Code)
+	}
+	if w := do("POST", "/api/automations", `{"name":"ping","when":{"sense":"slack"},"then":[{"notify":"hi {{sender}}"}]}`); w.Code != http.StatusOK {
+		t.Fatalf("POST: status %d: %s", w.Code, w.Body)
+	}
+	if w := do("POST", "/api/automations/ping/run", ""); w.Code != http.StatusAccepted {
+		t.Errorf("run: status %d", w.Code)
+	}
+	e.Wait()
+	if len(rec.notes) != 1 {
+		t.Errorf("notes after run = %q", rec.notes)
+	}
+
+	// API rules survive a restart; file rules stay read-only.
+	e2, err := New(dir, rec.handlers())
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	var names []string
+	for _, r := range e2.Rules() {
+		names = append(names, r.Name+":"+r.Source)
+	}
+	if got := strings.Join(names, ","); got != "after-report:file,invoices:file,ping:api" {
+		t.Errorf("rules = %s", got)
+	}
+	mux = http.NewServeMux()
+	e2.RegisterRoutes(mux)
+	if w := do("DELETE", "/api/automations/invoices", ""); w.Code != http.StatusNotFound {
+		t.Errorf("deleting a file rule: status %d", w.Code)
+	}
+	if w := do("DELETE", "/api/automations/ping", ""); w.Code != http.StatusOK {
+		t.Errorf("DELETE: status %d", w.Code)
+	}
+	if len(e2.Rules()) != 2 {
+		t.Errorf("rules after delete = %d", len(e2.Rules()))
+	}
+}
+
+func TestEngine_ReloadKeepsRulesOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeRules(t, dir, testRules)
+	e, err := New(dir, (&recorder{}).handlers())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	writeRules(t, dir, "rules:\n  - name: broken\n")
+	// Make sure the modification time moves even on coarse filesystems.
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(dir, FileName), future, future)
+	if err := e.reloadFile(); err == nil {
+		t.Error("broken file reloaded without error")
+	}
+	if len(e.Rules()) != 2 {
+		t.Errorf("rules after failed reload = %d, want the previous 2", len(e.Rules()))
+	}
+	if err := e.reloadFile(); err != nil {
+		t.Errorf("unchanged broken file reported again: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package brain
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a CachingProvider keeps a response.
+const DefaultCacheTTL = 24 * time.Hour
+
+// maxMemoryCacheEntries bounds a CachingProvider kept in memory.
+const maxMemoryCacheEntries = 1000
+
+// CachingProvider answers repeated identical requests from a cache instead
+// of the provider, for the calls that opt in with WithCache: the review and
+// clarification of a task run again. Other calls go to the provider. A
+// request is identified by its messages, model, temperature, token limit,
+// JSON mode and schema. Requests with tools are not cached (their calls act
+// on the world), nor are errors, declined responses and, for structured
+// calls, replies that don't decode. A cached response costs nothing and has
+// Cached set.
+type CachingProvider struct {
+	inner LLMProvider
+	ttl   time.Duration
+	db    *sql.DB // nil = in memory
+
+	mu  sync.Mutex
+	mem map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	resp    LLMResponse
+	expires time.Time
+}
+
+// NewCachingProvider wraps inner with a cache kept in memory for ttl
+// (0 = DefaultCacheTTL).
+func NewCachingProvider(inner LLMProvider, ttl time.Duration) *CachingProvider {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &CachingProvider{inner: inner, ttl: ttl, mem: make(map[string]cacheEntry)}
+}
+
+// OpenCachingProvider is NewCachingProvider with the cache kept in db, in
+// the llm_cache table, so it survives a restart. Expired responses are
+// dropped.
+func OpenCachingProvider(inner LLMProvider, db *sql.DB, ttl time.Duration) (*CachingProvider, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS llm_cache (
+		key        TEXT PRIMARY KEY,
+		response   TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("llm cache: create table: %w", err)
+	}
+	if _, err := db.Exec(`DELETE FROM llm_cache WHERE expires_at < ?`, time.Now().Unix()); err != nil {
+		return nil, fmt.Errorf("llm cache: prune: %w", err)
+	}
+	c := NewCachingProvider(inner, ttl)
+	c.db = db
+	return c, nil
+}
+
+// cacheOptKey is the context key of a call's cacheOpt.
+type cacheOptKey struct{}
+
+// cacheOpt marks a call that may be answered from the cache.
+type cacheOpt struct {
+	// valid, if set, checks a response before it is stored or reused.
+	valid func(*LLMResponse) error
+}
+
+// WithCache lets a CachingProvider answer the completions made with ctx
+// from its cache. Only calls whose reply depends on the request alone
+// should opt in.
+func WithCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheOptKey{}, cacheOpt{})
+}
+
+// withCacheCheck makes a call that opted in with WithCache store and reuse
+// only responses that pass valid. Other calls are left alone.
+func withCacheCheck(ctx context.Context, valid func(*LLMResponse) error) context.Context {
+	if _, ok := ctx.Value(cacheOptKey{}).(cacheOpt); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheOptKey{}, cacheOpt{valid: valid})
+}
+
+// Complete implements LLMProvider.
+func (c *CachingProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return c.CompleteStream(ctx, req, nil)
+}
+
+// CompleteStream implements Streamer. A cached response is passed to
+// onChunk in one piece.
+func (c *CachingProvider) CompleteStream(ctx context.Context, req LLMRequest, onChunk func(chunk string)) (*LLMResponse, error) {
+	opt, ok := ctx.Value(cacheOptKey{}).(cacheOpt)
+	if !ok || len(req.Tools) > 0 {
+		return c.call(ctx, req, onChunk)
+	}
+	key := cacheKey(req)
+	if resp, ok := c.get(key); ok && opt.check(resp) {
+		if onChunk != nil && resp.Content != "" {
+			onChunk(resp.Content)
+		}
+		return resp, nil
+	}
+	resp, err := c.call(ctx, req, onChunk)
+	if err == nil && resp != nil && !resp.Declined() && len(resp.ToolCalls) == 0 && resp.Content != "" && opt.check(resp) {
+		c.put(key, *resp)
+	}
+	return resp, err
+}
+
+// check reports whether resp may be stored or reused.
+func (o cacheOpt) check(resp *LLMResponse) bool {
+	return o.valid == nil || o.valid(resp) == nil
+}
+
+func (c *CachingProvider) call(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
+	if onChunk == nil {
+		return c.inner.Complete(ctx, req)
+	}
+	return CompleteStream(ctx, c.inner, req, onChunk)
+}
+
+// cacheKey is the hex SHA-256 of what makes two requests identical.
+func cacheKey(req LLMRequest) string {
+	data, _ := json.Marshal(struct {
+		Messages    []Message `json:"m"`
+		Model       string    `json:"model"`
+		Temperature float64   `json:"t"`
+		MaxTokens   int       `json:"max"`
+		JSON        bool      `json:"json"`
+		Schema      *Schema   `json:"schema,omitempty"`
+	}{req.Messages, req.Model, req.Temperature, req.MaxTokens, req.JSON, req.Schema})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the cached response for key, marked as cached and free.
+func (c *CachingProvider) get(key string) (*LLMResponse, bool) {
+	var resp LLMResponse
+	if c.db == nil {
+		c.mu.Lock()
+		e, ok := c.mem[key]
+		c.mu.Unlock()
+		if !ok || time.Now().After(e.expires) {
+			return nil, false
+		}
+		resp = e.resp
+	} else {
+		var data string
+		err := c.db.QueryRow(`SELECT response FROM llm_cache WHERE key = ? AND expires_at >= ?`, key, time.Now().Unix()).Scan(&data)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("[brain] llm cache: read: %v", err)
+			}
+			return nil, false
+		}
+		if json.Unmarshal([]byte(data), &resp) != nil {
+			return nil, false
+		}
+	}
+	resp.CostUSD, resp.LatencyMs, resp.Cached = 0, 0, true
+	return &resp, true
+}
+
+// put caches resp under key for the TTL.
+func (c *CachingProvider) put(key string, resp LLMResponse) {
+	expires := time.Now().Add(c.ttl)
+	if c.db == nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if len(c.mem) >= maxMemoryCacheEntries {
+			now := time.Now()
+			for k, e := range c.mem {
+				if now.After(e.expires) {
+					delete(c.mem, k)
+				}
+			}
+			for k := range c.mem {
+				if len(c.mem) < maxMemoryCacheEntries {
+					break
+				}
+				delete(c.mem, k) // arbitrary, like an LRU without the bookkeeping
+			}
+		}
+		c.mem[key] = cacheEntry{resp: resp, expires: expires}
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err == nil {
+		_, err = c.db.Exec(`
+		INSERT INTO llm_cache (key, response, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET response = excluded.response, expires_at = excluded.expires_at`,
+			key, string(data), expires.Unix())
+	}
+	if err != nil {
+		log.Printf("[brain] llm cache: write: %v", err)
+	}
+}
+
+// Name implements LLMProvider.
+func (c *CachingProvider) Name() string { return c.inner.Name() }
+
+// Models implements LLMProvider.
+func (c *CachingProvider) Models() []string { return c.inner.Models() }
+
+// Quota implements QuotaReporter when the wrapped provider does.
+func (c *CachingProvider) Quota() (Quota, bool) {
+	if qr, ok := c.inner.(QuotaReporter); ok {
+		return qr.Quota()
+	}
+	return Quota{}, false
+}
+
+// ListModels implements ModelLister for the wrapped provider.
+func (c *CachingProvider) ListModels(ctx context.Context) ([]string, error) {
+	if l, ok := c.inner.(ModelLister); ok {
+		return l.ListModels(ctx)
+	}
+	return nil, fmt.Errorf("%s: listing models is not supported", c.inner.Name())
+}
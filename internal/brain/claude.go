@@ -35,6 +35,14 @@ func WithClaudeHTTPClient(c *http.Client) ClaudeOption {
 	}
 }
 
+// WithClaudeRetry retries requests failing with a rate limit, a server
+// error or a timeout. Default: no retries.
+func WithClaudeRetry(policy RetryPolicy) ClaudeOption {
+	return func(p *ClaudeProvider) {
+		p.retry = policy
+	}
+}
+
 // WithClaudeDefaultModel sets the default model when none is specified in the request.
 func WithClaudeDefaultModel(model string) ClaudeOption {
 	return func(p *ClaudeProvider) {
@@ -48,6 +56,8 @@ type ClaudeProvider struct {
 	baseURL      string
 	client       *http.Client
 	defaultModel string
+	retry        RetryPolicy
+	quota        quotaState
 }
 
 // NewClaudeProvider creates a new Claude provider.
@@ -78,12 +88,21 @@ func (p *ClaudeProvider) Models() []string {
 
 // claudeRequest is the Anthropic API request body.
 type claudeRequest struct {
-	Model       string         `json:"model"`
-	MaxTokens   int            `json:"max_tokens"`
-	Messages    []claudeMsg    `json:"messages"`
-	System      string         `json:"system,omitempty"`
-	Temperature *float64       `json:"temperature,omitempty"`
-	Tools       []claudeTool   `json:"tools,omitempty"`
+	Model       string        `json:"model"`
+	MaxTokens   int           `json:"max_tokens"`
+	Messages    []claudeMsg   `json:"messages"`
+	System      string        `json:"system,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Tools       []claudeTool  `json:"tools,omitempty"`
+	ToolChoice  *claudeChoice `json:"tool_choice,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+}
+
+// claudeChoice forces a tool call; a structured request (LLMRequest.Schema)
+// is sent as a tool the model must call, whose input is the reply.
+type claudeChoice struct {
+	Type string `json:"type"` // "tool"
+	Name string `json:"name"`
 }
 
 type claudeMsg struct {
@@ -127,6 +146,20 @@ type claudeErrorResponse struct {
 
 // Complete sends a completion request to the Claude API.
 func (p *ClaudeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return p.CompleteStream(ctx, req, nil)
+}
+
+// CompleteStream sends a streaming request to the Claude API, passing the
+// text to onChunk as it arrives. A request is retried per the retry policy
+// until its first chunk has arrived.
+func (p *ClaudeProvider) CompleteStream(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
+	return retryStream(ctx, p.retry, &p.quota, onChunk, func(onChunk func(string)) (*LLMResponse, error) {
+		return p.complete(ctx, req, onChunk)
+	})
+}
+
+// complete streams the response when onChunk is set.
+func (p *ClaudeProvider) complete(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
 	model := req.Model
 	if model == "" {
 		model = p.defaultModel
@@ -153,6 +186,7 @@ func (p *ClaudeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResp
 		MaxTokens: maxTokens,
 		Messages:  msgs,
 		System:    systemPrompt,
+		Stream:    onChunk != nil,
 	}
 
 	if req.Temperature > 0 {
@@ -167,6 +201,10 @@ func (p *ClaudeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResp
 			InputSchema: tool.InputSchema,
 		})
 	}
+	if req.Schema != nil && len(req.Tools) == 0 {
+		cr.Tools = []claudeTool{{Name: req.Schema.Name, Description: req.Schema.Description, InputSchema: req.Schema.JSON}}
+		cr.ToolChoice = &claudeChoice{Type: "tool", Name: req.Schema.Name}
+	}
 
 	body, err := json.Marshal(cr)
 	if err != nil {
@@ -189,6 +227,17 @@ func (p *ClaudeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResp
 		return nil, fmt.Errorf("claude: http request: %w", err)
 	}
 	defer resp.Body.Close()
+	p.quota.observe(p.Name(), resp)
+
+	if onChunk != nil && resp.StatusCode == http.StatusOK && isEventStream(resp.Header.Get("Content-Type")) {
+		result, err := decodeStream(resp.Body, LLMRequest{Model: model, Messages: req.Messages}, onChunk)
+		if err != nil {
+			return nil, fmt.Errorf("claude: %w", err)
+		}
+		result.LatencyMs = time.Since(start).Milliseconds()
+		result.CostUSD = claudeCalculateCost(result.Model, result.InputTokens, result.OutputTokens)
+		return result, nil
+	}
 
 	latency := time.Since(start).Milliseconds()
 
@@ -225,6 +274,10 @@ func (p *ClaudeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResp
 		case "text":
 			textParts = append(textParts, block.Text)
 		case "tool_use":
+			if cr.ToolChoice != nil && block.Name == cr.ToolChoice.Name {
+				textParts = append(textParts, string(block.Input))
+				continue
+			}
 			result.ToolCalls = append(result.ToolCalls, ToolCall{
 				ID:    block.ID,
 				Name:  block.Name,
@@ -237,9 +290,15 @@ func (p *ClaudeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResp
 	// Calculate cost.
 	result.CostUSD = claudeCalculateCost(cr2.Model, cr2.Usage.InputTokens, cr2.Usage.OutputTokens)
 
+	if onChunk != nil && result.Content != "" {
+		onChunk(result.Content) // the server ignored "stream"
+	}
 	return result, nil
 }
 
+// Quota returns the rate limits reported by the last response.
+func (p *ClaudeProvider) Quota() (Quota, bool) { return p.quota.get() }
+
 // claudeCalculateCost computes USD cost based on model and token counts.
 func claudeCalculateCost(model string, inputTokens, outputTokens int) float64 {
 	var pricing [2]float64
@@ -0,0 +1,253 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ModelLister is implemented by providers that can report which models the
+// backend currently serves.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// ModelRef is a model the agent is configured to use and where that choice
+// comes from ("config", "pinned", "router").
+type ModelRef struct {
+	Model  string `json:"model"`
+	Source string `json:"source"`
+}
+
+// ModelDeprecation reports a configured model missing from the provider's
+// model list, with the closest available replacement ("" if none).
+type ModelDeprecation struct {
+	Model       string `json:"model"`
+	Source      string `json:"source"`
+	Tier        Tier   `json:"tier,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// String describes the deprecation for logs and notifications.
+func (d ModelDeprecation) String() string {
+	if d.Replacement == "" {
+		return fmt.Sprintf("model %s (%s) is no longer offered by the provider", d.Model, d.Source)
+	}
+	return fmt.Sprintf("model %s (%s) is no longer offered by the provider; suggested replacement: %s", d.Model, d.Source, d.Replacement)
+}
+
+// CheckModels returns the refs whose model is not in available. An empty
+// available list means the provider could not be queried and nothing is
+// reported. Duplicate models are reported once, for their first source.
+func CheckModels(refs []ModelRef, available []string, router *ModelRouter) []ModelDeprecation {
+	if len(available) == 0 {
+		return nil
+	}
+	have := make(map[string]bool, len(available))
+	for _, id := range available {
+		have[id] = true
+	}
+	seen := make(map[string]bool)
+	var out []ModelDeprecation
+	for _, ref := range refs {
+		if ref.Model == "" || have[ref.Model] || seen[ref.Model] {
+			continue
+		}
+		seen[ref.Model] = true
+		tier := router.TierOf(ref.Model)
+		if tier == "" {
+			tier = guessTier(ref.Model)
+		}
+		out = append(out, ModelDeprecation{
+			Model:       ref.Model,
+			Source:      ref.Source,
+			Tier:        tier,
+			Replacement: SuggestReplacement(ref.Model, tier, available),
+		})
+	}
+	return out
+}
+
+// SuggestReplacement picks the available model closest to model: the one
+// sharing the longest name prefix within the same tier, falling back to any
+// tier. Ties go to the lexically greatest ID, which for dated or numbered
+// IDs is usually the newest. The first dash-separated token (the family,
+// e.g. "claude" or "gpt") must match.
+func SuggestReplacement(model string, tier Tier, available []string) string {
+	family, _, _ := strings.Cut(model, "-")
+	pick := func(sameTier bool) string {
+		best, bestScore := "", 0
+		for _, id := range available {
+			if id == model {
+				continue
+			}
+			if f, _, _ := strings.Cut(id, "-"); f != family {
+				continue
+			}
+			if sameTier && tier != "" && guessTier(id) != tier {
+				continue
+			}
+			score := commonPrefixLen(model, id)
+			if score > bestScore || (score == bestScore && id > best) {
+				best, bestScore = id, score
+			}
+		}
+		return best
+	}
+	if r := pick(true); r != "" {
+		return r
+	}
+	return pick(false)
+}
+
+// specialModelMarkers mark model IDs that are not general chat models
+// (audio, search, previews…) and are never offered as upgrades.
+var specialModelMarkers = []string{"preview", "audio", "realtime", "search", "transcribe", "tts", "embed", "image", "instruct", "latest"}
+
+// FindUpgrade returns the newest available general-purpose model of the
+// same family and tier as model that sorts after it (dated and numbered
+// IDs sort by release), or "" if there is none.
+func FindUpgrade(model string, available []string) string {
+	family, _, _ := strings.Cut(model, "-")
+	tier := guessTier(model)
+	best := ""
+	for _, id := range available {
+		if id <= model || id <= best || guessTier(id) != tier {
+			continue
+		}
+		if f, _, _ := strings.Cut(id, "-"); f != family {
+			continue
+		}
+		special := false
+		for _, m := range specialModelMarkers {
+			if strings.Contains(strings.ToLower(id), m) {
+				special = true
+				break
+			}
+		}
+		if !special {
+			best = id
+		}
+	}
+	return best
+}
+
+// guessTier infers a tier from well-known model name markers.
+func guessTier(model string) Tier {
+	id := strings.ToLower(model)
+	switch {
+	case strings.Contains(id, "haiku"), strings.Contains(id, "mini"), strings.Contains(id, "nano"),
+		strings.Contains(id, "flash"), strings.Contains(id, "small"), strings.Contains(id, "8b"):
+		return TierCheap
+	case strings.Contains(id, "opus"), strings.Contains(id, "gpt-4.1"), strings.Contains(id, "gpt-5"),
+		strings.HasPrefix(id, "o1"), strings.HasPrefix(id, "o3"), strings.Contains(id, "large"), strings.Contains(id, "70b"):
+		return TierPowerful
+	}
+	return TierMid
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// ReplaceModel swaps model ID old for replacement in the router (keeping
+// its tier and cost) and in the override, so routing stops selecting a
+// retired model. It reports whether anything changed.
+func (r *ModelRouter) ReplaceModel(old, replacement string) bool {
+	changed := false
+	r.modelsMu.Lock()
+	models := make([]ModelEntry, len(r.models))
+	copy(models, r.models)
+	for i := range models {
+		if models[i].ID == old {
+			models[i].ID = replacement
+			changed = true
+		}
+	}
+	r.models = models
+	r.modelsMu.Unlock()
+
+	r.overrideMu.Lock()
+	if r.override == old {
+		r.override = replacement
+		changed = true
+	}
+	r.overrideMu.Unlock()
+	return changed
+}
+
+// ListModels queries the provider's OpenAI-compatible /v1/models endpoint.
+func (p *UniversalProvider) ListModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.config.BaseURL, "/")+"/v1/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: list models: %w", p.config.Name, err)
+	}
+	if p.config.APIKey != "" {
+		req.Header.Set(p.config.AuthHeader, p.config.AuthPrefix+p.config.APIKey)
+	}
+	for k, v := range p.config.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+	ids, err := fetchModelIDs(p.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: list models: %w", p.config.Name, err)
+	}
+	return ids, nil
+}
+
+// ListModels queries the Anthropic models endpoint.
+func (p *ClaudeProvider) ListModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/v1/models?limit=1000", nil)
+	if err != nil {
+		return nil, fmt.Errorf("claude: list models: %w", err)
+	}
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	ids, err := fetchModelIDs(p.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("claude: list models: %w", err)
+	}
+	return ids, nil
+}
+
+// fetchModelIDs performs req and decodes a {"data":[{"id":...}]} body, the
+// format shared by OpenAI-compatible servers and Anthropic. The result is
+// sorted.
+func fetchModelIDs(client *http.Client, req *http.Request) ([]string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	ids := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		if m.ID != "" {
+			ids = append(ids, m.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
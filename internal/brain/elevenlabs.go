@@ -0,0 +1,131 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ElevenLabsSpeech implements SpeechToText (Scribe) and TextToSpeech for the
+// ElevenLabs API.
+type ElevenLabsSpeech struct {
+	config SpeechConfig
+	client *http.Client
+}
+
+// NewElevenLabsSpeech creates an ElevenLabs client. Model defaults to
+// "scribe_v1" for transcription and "eleven_multilingual_v2" for synthesis;
+// Voice must be set for synthesis.
+func NewElevenLabsSpeech(cfg SpeechConfig) (*ElevenLabsSpeech, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("elevenlabs: API key required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.elevenlabs.io"
+	}
+	return &ElevenLabsSpeech{config: cfg, client: &http.Client{Timeout: cfg.timeout()}}, nil
+}
+
+// Name returns the provider name.
+func (s *ElevenLabsSpeech) Name() string { return "elevenlabs" }
+
+// Transcribe posts the audio to /v1/speech-to-text.
+func (s *ElevenLabsSpeech) Transcribe(ctx context.Context, req TranscribeRequest) (*Transcript, error) {
+	start := time.Now()
+	model := s.config.Model
+	if model == "" {
+		model = "scribe_v1"
+	}
+	fields := map[string]string{"model_id": model}
+	if req.Language != "" {
+		fields["language_code"] = req.Language
+	}
+	body, contentType, err := multipartAudio(req, fields)
+	if err != nil {
+		return nil, fmt.Errorf("elevenlabs: transcribe: %w", err)
+	}
+	data, err := s.do(ctx, "/v1/speech-to-text", contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("elevenlabs: transcribe: %w", err)
+	}
+	var out struct {
+		Text         string `json:"text"`
+		LanguageCode string `json:"language_code"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("elevenlabs: transcribe: parse: %w", err)
+	}
+	return &Transcript{
+		Text:      strings.TrimSpace(out.Text),
+		Language:  out.LanguageCode,
+		Provider:  s.Name(),
+		LatencyMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// Synthesize posts the text to /v1/text-to-speech/{voice} and returns MP3
+// audio.
+func (s *ElevenLabsSpeech) Synthesize(ctx context.Context, req SpeechRequest) (*SpeechAudio, error) {
+	start := time.Now()
+	voice := req.Voice
+	if voice == "" {
+		voice = s.config.Voice
+	}
+	if voice == "" {
+		return nil, fmt.Errorf("elevenlabs: synthesize: no voice ID configured")
+	}
+	model := s.config.Model
+	if model == "" {
+		model = "eleven_multilingual_v2"
+	}
+	body, err := json.Marshal(map[string]string{"text": req.Text, "model_id": model})
+	if err != nil {
+		return nil, fmt.Errorf("elevenlabs: synthesize: %w", err)
+	}
+	data, err := s.do(ctx, "/v1/text-to-speech/"+url.PathEscape(voice), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("elevenlabs: synthesize: %w", err)
+	}
+	return &SpeechAudio{
+		Data:      data,
+		MIMEType:  "audio/mpeg",
+		Provider:  s.Name(),
+		LatencyMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+func (s *ElevenLabsSpeech) do(ctx context.Context, path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.config.BaseURL, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("xi-api-key", s.config.APIKey)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Detail struct {
+				Message string `json:"message"`
+			} `json:"detail"`
+		}
+		if json.Unmarshal(data, &errResp) == nil && errResp.Detail.Message != "" {
+			return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, errResp.Detail.Message)
+		}
+		return nil, fmt.Errorf("API error %d", resp.StatusCode)
+	}
+	return data, nil
+}
@@ -0,0 +1,153 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Embedder turns texts into vectors for semantic search. Implementations
+// return one vector per input text, in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbeddingModel identifies the model; vectors from different models
+	// are not comparable.
+	EmbeddingModel() string
+}
+
+// DefaultEmbeddingModel returns the embedding model used for a provider when
+// none is configured, or "" if the provider has no known embedding model.
+func DefaultEmbeddingModel(provider string) string {
+	switch provider {
+	case "openai":
+		return "text-embedding-3-small"
+	case "ollama":
+		return "nomic-embed-text"
+	case "lmstudio":
+		return "text-embedding-nomic-embed-text-v1.5"
+	case "together":
+		return "togethercomputer/m2-bert-80M-8k-retrieval"
+	}
+	return ""
+}
+
+// EmbeddingClient calls an OpenAI-compatible /v1/embeddings endpoint
+// (OpenAI, Ollama, LM Studio, vLLM, ...).
+type EmbeddingClient struct {
+	config ProviderConfig
+	model  string
+	client *http.Client
+}
+
+// Embedder returns an embedding client for model that uses the provider's
+// base URL and credentials.
+func (p *UniversalProvider) Embedder(model string) *EmbeddingClient {
+	return &EmbeddingClient{config: p.config, model: model, client: p.client}
+}
+
+// EmbeddingModel returns the model ID.
+func (c *EmbeddingClient) EmbeddingModel() string { return c.model }
+
+// Embed requests embeddings for texts in one call.
+func (c *EmbeddingClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(map[string]any{"model": c.model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("%s: embed: %w", c.config.Name, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.config.BaseURL, "/")+"/v1/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%s: embed: %w", c.config.Name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set(c.config.AuthHeader, c.config.AuthPrefix+c.config.APIKey)
+	}
+	for k, v := range c.config.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: embed: %w", c.config.Name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%s: embed: %w", c.config.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp openaiErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Message != "" {
+			return nil, fmt.Errorf("%s: embed: API error %d: %s", c.config.Name, resp.StatusCode, errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("%s: embed: API error %d", c.config.Name, resp.StatusCode)
+	}
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("%s: embed: parse: %w", c.config.Name, err)
+	}
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("%s: embed: got %d vectors for %d texts", c.config.Name, len(out.Data), len(texts))
+	}
+	vecs := make([][]float32, len(texts))
+	for i, d := range out.Data {
+		idx := d.Index
+		if idx < 0 || idx >= len(vecs) {
+			idx = i
+		}
+		vecs[idx] = d.Embedding
+	}
+	return vecs, nil
+}
+
+// fakeEmbeddingDim is the size of FakeProvider vectors.
+const fakeEmbeddingDim = 64
+
+// EmbeddingModel returns the fake embedding model ID.
+func (p *FakeProvider) EmbeddingModel() string { return "fake-embed" }
+
+// Embed returns deterministic bag-of-words vectors: each lowercased word is
+// hashed into one of 64 buckets and the result is normalised, so texts
+// sharing words are close. Useful for tests and offline demos.
+func (p *FakeProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	vecs := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, fakeEmbeddingDim)
+		for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			h := fnv.New32a()
+			h.Write([]byte(w))
+			v[h.Sum32()%fakeEmbeddingDim]++
+		}
+		var norm float64
+		for _, x := range v {
+			norm += float64(x) * float64(x)
+		}
+		if norm > 0 {
+			n := float32(math.Sqrt(norm))
+			for j := range v {
+				v[j] /= n
+			}
+		}
+		vecs[i] = v
+	}
+	return vecs, nil
+}
@@ -0,0 +1,184 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeRule maps a substring of the request to a scripted response.
+// Match is case-insensitive and checked against the user/assistant
+// messages of the request; the first matching rule wins.
+type FakeRule struct {
+	Match    string `json:"match"`
+	Response string `json:"response"`
+}
+
+// FakeConfig configures a FakeProvider.
+type FakeConfig struct {
+	Rules     []FakeRule `json:"rules,omitempty"`
+	Default   string     `json:"default,omitempty"`    // response when no rule matches
+	LatencyMs int        `json:"latency_ms,omitempty"` // simulated latency per call
+	ErrorRate float64    `json:"error_rate,omitempty"` // 0.0–1.0, fraction of calls that fail
+	Seed      int64      `json:"seed,omitempty"`       // RNG seed for error injection (0 = 1)
+}
+
+// DefaultFakeRules returns rules that satisfy the pipeline's own prompts
+// (review SCORE, reflection, heartbeat), so a run completes end-to-end.
+func DefaultFakeRules() []FakeRule {
+	return []FakeRule{
+		{Match: "Reflect on this completed task", Response: "WENT_WELL: answered directly\nIMPROVEMENTS: none\nSOUL_SUGGESTION: NONE\nSKILL_SUGGESTION: NONE"},
+		{Match: "Review this task result", Response: "SCORE: 0.90\nNOTES: Fake review — looks good."},
+		{Match: "Clarify this task", Response: "GOAL: answer the request\nCONSTRAINTS: none\nEXPECTED_OUTPUT: short answer\nVERIFICATION: user is satisfied"},
+		{Match: "Decompose this task", Response: "1. Answer the request"},
+		{Match: "heartbeat", Response: "Heartbeat check: all systems nominal."},
+	}
+}
+
+// LoadFakeConfig reads a FakeConfig from a JSON file. Rules from the file
+// are checked before DefaultFakeRules.
+func LoadFakeConfig(path string) (FakeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FakeConfig{}, fmt.Errorf("fake: read script: %w", err)
+	}
+	var cfg FakeConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return FakeConfig{}, fmt.Errorf("fake: parse script %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// FakeProvider is a deterministic, offline LLMProvider. It returns scripted
+// responses with optional latency and error injection, so the kiosk,
+// senses and policies can be exercised end-to-end without spending tokens.
+type FakeProvider struct {
+	cfg FakeConfig
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	calls int
+}
+
+// NewFakeProvider creates a fake provider. DefaultFakeRules are always
+// appended after cfg.Rules.
+func NewFakeProvider(cfg FakeConfig) *FakeProvider {
+	cfg.Rules = append(append([]FakeRule{}, cfg.Rules...), DefaultFakeRules()...)
+	if cfg.Default == "" {
+		cfg.Default = "This is a response from the fake provider."
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	return &FakeProvider{
+		cfg: cfg,
+		rng: rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+func (p *FakeProvider) Models() []string {
+	return []string{"fake-cheap", "fake-mid", "fake-powerful"}
+}
+
+// ModelEntries returns router entries for the fake models (all free).
+func (p *FakeProvider) ModelEntries() []ModelEntry {
+	return []ModelEntry{
+		{ID: "fake-cheap", Provider: "fake", Tier: TierCheap},
+		{ID: "fake-mid", Provider: "fake", Tier: TierMid},
+		{ID: "fake-powerful", Provider: "fake", Tier: TierPowerful},
+	}
+}
+
+// Calls returns how many completions were requested.
+func (p *FakeProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// Complete returns the scripted response for req.
+func (p *FakeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	start := time.Now()
+
+	p.mu.Lock()
+	p.calls++
+	n := p.calls
+	fail := p.cfg.ErrorRate > 0 && p.rng.Float64() < p.cfg.ErrorRate
+	p.mu.Unlock()
+
+	if p.cfg.LatencyMs > 0 {
+		select {
+		case <-time.After(time.Duration(p.cfg.LatencyMs) * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if fail {
+		return nil, fmt.Errorf("fake: injected error on call %d", n)
+	}
+
+	var inputChars int
+	for _, m := range req.Messages {
+		inputChars += len(m.Content)
+	}
+	content := p.match(req.Messages)
+
+	model := req.Model
+	if model == "" {
+		model = "fake-mid"
+	}
+	return &LLMResponse{
+		Content:      content,
+		Model:        model,
+		InputTokens:  inputChars / 4,
+		OutputTokens: len(content) / 4,
+		LatencyMs:    time.Since(start).Milliseconds(),
+		StopReason:   "end_turn",
+	}, nil
+}
+
+// CompleteStream is Complete with the text delivered word by word.
+func (p *FakeProvider) CompleteStream(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rest := resp.Content
+	for rest != "" {
+		i := strings.IndexByte(rest[1:], ' ') + 1
+		if i == 0 {
+			i = len(rest)
+		}
+		onChunk(rest[:i])
+		rest = rest[i:]
+	}
+	return resp, nil
+}
+
+// match finds the first rule whose Match occurs in a non-system message.
+// System messages (soul content) are skipped so they never shadow rules.
+func (p *FakeProvider) match(msgs []Message) string {
+	for _, r := range p.cfg.Rules {
+		needle := strings.ToLower(r.Match)
+		if needle == "" {
+			continue
+		}
+		for _, m := range msgs {
+			if m.Role == "system" {
+				continue
+			}
+			if strings.Contains(strings.ToLower(m.Content), needle) {
+				return r.Response
+			}
+		}
+	}
+	return p.cfg.Default
+}
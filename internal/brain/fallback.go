@@ -0,0 +1,196 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFallbackProbe is how often a degraded FallbackProvider tries the
+// primary provider again.
+const DefaultFallbackProbe = time.Minute
+
+// IsUnreachableError reports whether err means the provider could not be
+// reached or is down — a network failure (no route, DNS, connection
+// refused, timeout) or HTTP 502, 503, 504 or 529 — rather than rejecting
+// the request. A cancelled request is not.
+func IsUnreachableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := err.Error()
+	for _, code := range []string{"API error 502", "API error 503", "API error 504", "API error 529"} {
+		if strings.Contains(msg, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// FallbackStatus is the state of a FallbackProvider.
+type FallbackStatus struct {
+	Degraded bool      `json:"degraded"`
+	Primary  string    `json:"primary"`
+	Fallback string    `json:"fallback"`
+	Since    time.Time `json:"since,omitempty"`  // when degraded mode (last) began
+	Reason   string    `json:"reason,omitempty"` // the error that caused it
+}
+
+// FallbackProvider sends requests to a primary (cloud) provider and, while
+// it is unreachable or failing (IsRetryableError: rate limits and server
+// errors too), to a fallback — a local model such as Ollama or LM Studio,
+// or another cloud provider — so the agent keeps working offline or
+// through a provider outage. Degraded, it tries the primary again at most
+// once per probe interval and switches back as soon as it answers.
+// Requests to the fallback use its default model; the router's models
+// belong to the primary.
+type FallbackProvider struct {
+	primary  LLMProvider
+	fallback LLMProvider
+
+	mu        sync.Mutex
+	probe     time.Duration
+	status    FallbackStatus
+	lastProbe time.Time
+	onChange  []func(FallbackStatus)
+}
+
+// NewFallbackProvider wraps primary with fallback.
+func NewFallbackProvider(primary, fallback LLMProvider) *FallbackProvider {
+	return &FallbackProvider{
+		primary:  primary,
+		fallback: fallback,
+		probe:    DefaultFallbackProbe,
+		status:   FallbackStatus{Primary: primary.Name(), Fallback: fallback.Name()},
+	}
+}
+
+// NewFallbackChain wraps primary with fallbacks tried in order, e.g. groq,
+// then openai, then a local model: each provider falls back to the rest of
+// the chain. Without fallbacks it returns primary.
+func NewFallbackChain(primary LLMProvider, fallbacks ...LLMProvider) LLMProvider {
+	if len(fallbacks) == 0 {
+		return primary
+	}
+	return NewFallbackProvider(primary, NewFallbackChain(fallbacks[0], fallbacks[1:]...))
+}
+
+// SetProbeInterval sets how often the primary is retried while degraded;
+// 0 retries it on every request.
+func (f *FallbackProvider) SetProbeInterval(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.probe = d
+}
+
+// OnChange registers fn to be called, outside the lock, whenever the
+// provider enters or leaves degraded mode.
+func (f *FallbackProvider) OnChange(fn func(FallbackStatus)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onChange = append(f.onChange, fn)
+}
+
+// Status returns whether the provider is degraded, since when and why.
+func (f *FallbackProvider) Status() FallbackStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.status
+}
+
+// Complete implements LLMProvider.
+func (f *FallbackProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return f.do(ctx, req, func(p LLMProvider, req LLMRequest) (*LLMResponse, error) {
+		return p.Complete(ctx, req)
+	})
+}
+
+// CompleteStream implements Streamer; the fallback streams when it can.
+func (f *FallbackProvider) CompleteStream(ctx context.Context, req LLMRequest, onChunk func(chunk string)) (*LLMResponse, error) {
+	return f.do(ctx, req, func(p LLMProvider, req LLMRequest) (*LLMResponse, error) {
+		return CompleteStream(ctx, p, req, onChunk)
+	})
+}
+
+func (f *FallbackProvider) do(ctx context.Context, req LLMRequest, call func(LLMProvider, LLMRequest) (*LLMResponse, error)) (*LLMResponse, error) {
+	if f.tryPrimary() {
+		resp, err := call(f.primary, req)
+		if ctx.Err() != nil {
+			return resp, err
+		}
+		if !IsRetryableError(err) {
+			f.set(false, nil) // it answered, even if with an error
+			return resp, err
+		}
+		f.set(true, err)
+	}
+	req.Model = ""
+	resp, err := call(f.fallback, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s failing, fallback %s: %w", f.status.Primary, f.status.Fallback, err)
+	}
+	return resp, nil
+}
+
+// tryPrimary reports whether a request should go to the primary: always
+// when it is up, once per probe interval while degraded.
+func (f *FallbackProvider) tryPrimary() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.status.Degraded || time.Since(f.lastProbe) >= f.probe {
+		f.lastProbe = time.Now()
+		return true
+	}
+	return false
+}
+
+// set records the primary being down (degraded, with the error) or up,
+// and notifies the OnChange callbacks when that changes the mode.
+func (f *FallbackProvider) set(degraded bool, reason error) {
+	f.mu.Lock()
+	if f.status.Degraded == degraded {
+		f.mu.Unlock()
+		return
+	}
+	f.status.Degraded = degraded
+	f.status.Reason = ""
+	if degraded {
+		f.status.Since = time.Now()
+		f.status.Reason = reason.Error()
+	}
+	st, callbacks := f.status, f.onChange
+	f.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(st)
+	}
+}
+
+// Name implements LLMProvider.
+func (f *FallbackProvider) Name() string { return f.primary.Name() }
+
+// Models implements LLMProvider.
+func (f *FallbackProvider) Models() []string { return f.primary.Models() }
+
+// Quota implements QuotaReporter when the primary does.
+func (f *FallbackProvider) Quota() (Quota, bool) {
+	if qr, ok := f.primary.(QuotaReporter); ok {
+		return qr.Quota()
+	}
+	return Quota{}, false
+}
+
+// ListModels implements ModelLister for the primary.
+func (f *FallbackProvider) ListModels(ctx context.Context) ([]string, error) {
+	if l, ok := f.primary.(ModelLister); ok {
+		return l.ListModels(ctx)
+	}
+	return nil, fmt.Errorf("%s: listing models is not supported", f.primary.Name())
+}
@@ -0,0 +1,158 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WhisperCPP implements SpeechToText with the whisper.cpp command-line tool.
+// Audio must be in a format the binary accepts (16 kHz WAV for stock
+// builds).
+type WhisperCPP struct {
+	binary  string
+	model   string
+	timeout time.Duration
+}
+
+// NewWhisperCPP creates a whisper.cpp backend. Model is the path of a ggml
+// model file; Binary defaults to "whisper-cli".
+func NewWhisperCPP(cfg SpeechConfig) (*WhisperCPP, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("whisper.cpp: model path required")
+	}
+	bin := cfg.Binary
+	if bin == "" {
+		bin = "whisper-cli"
+	}
+	return &WhisperCPP{binary: bin, model: cfg.Model, timeout: cfg.timeout()}, nil
+}
+
+// Name returns the provider name.
+func (w *WhisperCPP) Name() string { return "whisper.cpp" }
+
+// Transcribe writes the audio to a temp file and runs
+// `whisper-cli -m MODEL -f FILE -nt -np [-l LANG]`, reading the text from
+// stdout.
+func (w *WhisperCPP) Transcribe(ctx context.Context, req TranscribeRequest) (*Transcript, error) {
+	start := time.Now()
+	if len(req.Audio) == 0 {
+		return nil, fmt.Errorf("whisper.cpp: empty audio")
+	}
+	ext := filepath.Ext(req.Filename)
+	if ext == "" {
+		ext = ".wav"
+	}
+	f, err := os.CreateTemp("", "overhuman-stt-*"+ext)
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp: %w", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(req.Audio)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp: %w", err)
+	}
+
+	args := []string{"-m", w.model, "-f", f.Name(), "-nt", "-np"}
+	if req.Language != "" {
+		args = append(args, "-l", req.Language)
+	}
+	out, err := runSpeechCommand(ctx, w.timeout, w.binary, args, nil)
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp: %w", err)
+	}
+	return &Transcript{
+		Text:      strings.Join(strings.Fields(string(out)), " "),
+		Language:  req.Language,
+		Provider:  w.Name(),
+		LatencyMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// Piper implements TextToSpeech with the Piper command-line tool.
+type Piper struct {
+	binary  string
+	model   string
+	timeout time.Duration
+}
+
+// NewPiper creates a Piper backend. Model is the path of an .onnx voice;
+// Binary defaults to "piper".
+func NewPiper(cfg SpeechConfig) (*Piper, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("piper: voice model path required")
+	}
+	bin := cfg.Binary
+	if bin == "" {
+		bin = "piper"
+	}
+	return &Piper{binary: bin, model: cfg.Model, timeout: cfg.timeout()}, nil
+}
+
+// Name returns the provider name.
+func (p *Piper) Name() string { return "piper" }
+
+// Synthesize runs `piper --model MODEL --output_file FILE` with the text on
+// stdin and returns the WAV file. req.Voice, if set, replaces the model path.
+func (p *Piper) Synthesize(ctx context.Context, req SpeechRequest) (*SpeechAudio, error) {
+	start := time.Now()
+	model := p.model
+	if req.Voice != "" {
+		model = req.Voice
+	}
+	dir, err := os.MkdirTemp("", "overhuman-tts-*")
+	if err != nil {
+		return nil, fmt.Errorf("piper: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	outPath := filepath.Join(dir, "speech.wav")
+
+	args := []string{"--model", model, "--output_file", outPath}
+	if _, err := runSpeechCommand(ctx, p.timeout, p.binary, args, strings.NewReader(req.Text)); err != nil {
+		return nil, fmt.Errorf("piper: %w", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("piper: read output: %w", err)
+	}
+	return &SpeechAudio{
+		Data:      data,
+		MIMEType:  "audio/wav",
+		Provider:  p.Name(),
+		LatencyMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// runSpeechCommand runs a local speech engine and returns its stdout. The
+// tail of stderr is included in the error when the command fails.
+func runSpeechCommand(ctx context.Context, timeout time.Duration, bin string, args []string, stdin io.Reader) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, bin, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 500 {
+			msg = msg[len(msg)-500:]
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", filepath.Base(bin), err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", filepath.Base(bin), err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,60 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+)
+
+// MeteredProvider reports every response of the wrapped provider, with
+// the provider's name, to a usage callback: token and cost metrics per
+// provider and model. Wrap each real provider, below any cache, so cached
+// answers are not counted.
+type MeteredProvider struct {
+	inner   LLMProvider
+	onUsage func(provider string, resp *LLMResponse)
+}
+
+// NewMeteredProvider wraps inner; onUsage is called after each successful
+// completion, from the calling goroutine.
+func NewMeteredProvider(inner LLMProvider, onUsage func(provider string, resp *LLMResponse)) *MeteredProvider {
+	return &MeteredProvider{inner: inner, onUsage: onUsage}
+}
+
+// Complete implements LLMProvider.
+func (m *MeteredProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return m.observe(m.inner.Complete(ctx, req))
+}
+
+// CompleteStream implements Streamer.
+func (m *MeteredProvider) CompleteStream(ctx context.Context, req LLMRequest, onChunk func(chunk string)) (*LLMResponse, error) {
+	return m.observe(CompleteStream(ctx, m.inner, req, onChunk))
+}
+
+func (m *MeteredProvider) observe(resp *LLMResponse, err error) (*LLMResponse, error) {
+	if err == nil && resp != nil && !resp.Cached {
+		m.onUsage(m.inner.Name(), resp)
+	}
+	return resp, err
+}
+
+// Name implements LLMProvider.
+func (m *MeteredProvider) Name() string { return m.inner.Name() }
+
+// Models implements LLMProvider.
+func (m *MeteredProvider) Models() []string { return m.inner.Models() }
+
+// Quota implements QuotaReporter when the wrapped provider does.
+func (m *MeteredProvider) Quota() (Quota, bool) {
+	if qr, ok := m.inner.(QuotaReporter); ok {
+		return qr.Quota()
+	}
+	return Quota{}, false
+}
+
+// ListModels implements ModelLister for the wrapped provider.
+func (m *MeteredProvider) ListModels(ctx context.Context) ([]string, error) {
+	if l, ok := m.inner.(ModelLister); ok {
+		return l.ListModels(ctx)
+	}
+	return nil, fmt.Errorf("%s: listing models is not supported", m.inner.Name())
+}
@@ -0,0 +1,79 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultModerationModel is the OpenAI moderation model used when none is
+// configured.
+const DefaultModerationModel = "omni-moderation-latest"
+
+// ModerationClient calls an OpenAI-compatible /v1/moderations endpoint.
+type ModerationClient struct {
+	config ProviderConfig
+	model  string
+	client *http.Client
+}
+
+// Moderator returns a moderation client for model ("" = the default) that
+// uses the provider's base URL and credentials.
+func (p *UniversalProvider) Moderator(model string) *ModerationClient {
+	if model == "" {
+		model = DefaultModerationModel
+	}
+	return &ModerationClient{config: p.config, model: model, client: p.client}
+}
+
+// Moderate returns the score of text in each moderation category
+// ("harassment", "hate/threatening", ...).
+func (c *ModerationClient) Moderate(ctx context.Context, text string) (map[string]float64, error) {
+	body, err := json.Marshal(map[string]any{"model": c.model, "input": text})
+	if err != nil {
+		return nil, fmt.Errorf("%s: moderate: %w", c.config.Name, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.config.BaseURL, "/")+"/v1/moderations", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%s: moderate: %w", c.config.Name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set(c.config.AuthHeader, c.config.AuthPrefix+c.config.APIKey)
+	}
+	for k, v := range c.config.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: moderate: %w", c.config.Name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%s: moderate: %w", c.config.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp openaiErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Message != "" {
+			return nil, fmt.Errorf("%s: moderate: API error %d: %s", c.config.Name, resp.StatusCode, errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("%s: moderate: API error %d", c.config.Name, resp.StatusCode)
+	}
+	var out struct {
+		Results []struct {
+			CategoryScores map[string]float64 `json:"category_scores"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("%s: moderate: parse: %w", c.config.Name, err)
+	}
+	if len(out.Results) == 0 {
+		return nil, fmt.Errorf("%s: moderate: no results", c.config.Name)
+	}
+	return out.Results[0].CategoryScores, nil
+}
@@ -76,12 +76,33 @@ func (p *OpenAIProvider) Models() []string {
 
 // openaiRequest is the OpenAI chat completions request body.
 type openaiRequest struct {
-	Model               string           `json:"model"`
-	Messages            []openaiMsg      `json:"messages"`
-	Temperature         *float64         `json:"temperature,omitempty"`
-	MaxTokens           *int             `json:"max_tokens,omitempty"`
-	MaxCompletionTokens *int             `json:"max_completion_tokens,omitempty"`
-	Tools               []openaiToolDef  `json:"tools,omitempty"`
+	Model               string               `json:"model"`
+	Messages            []openaiMsg          `json:"messages"`
+	Temperature         *float64             `json:"temperature,omitempty"`
+	MaxTokens           *int                 `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int                 `json:"max_completion_tokens,omitempty"`
+	Tools               []openaiToolDef      `json:"tools,omitempty"`
+	Stream              bool                 `json:"stream,omitempty"`
+	StreamOptions       *openaiStreamOptions `json:"stream_options,omitempty"`
+	ResponseFormat      *openaiFormat        `json:"response_format,omitempty"`
+}
+
+type openaiStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openaiFormat is the response_format of JSON mode.
+type openaiFormat struct {
+	Type       string            `json:"type"` // "json_object" or "json_schema"
+	JSONSchema *openaiJSONSchema `json:"json_schema,omitempty"`
+}
+
+// openaiJSONSchema is the schema of a structured request
+// (LLMRequest.Schema).
+type openaiJSONSchema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
 }
 
 type openaiMsg struct {
@@ -111,6 +132,7 @@ type openaiResponse struct {
 		Message      struct {
 			Role      string `json:"role"`
 			Content   string `json:"content"`
+			Refusal   string `json:"refusal,omitempty"`
 			ToolCalls []struct {
 				ID       string `json:"id"`
 				Type     string `json:"type"`
@@ -152,6 +174,15 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResp
 		Model:    model,
 		Messages: msgs,
 	}
+	if req.Schema != nil {
+		or.ResponseFormat = &openaiFormat{Type: "json_schema", JSONSchema: &openaiJSONSchema{
+			Name:        req.Schema.Name,
+			Description: req.Schema.Description,
+			Schema:      req.Schema.JSON,
+		}}
+	} else if req.JSON {
+		or.ResponseFormat = &openaiFormat{Type: "json_object"}
+	}
 
 	if req.Temperature > 0 {
 		t := req.Temperature
@@ -230,6 +261,7 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResp
 		choice := or2.Choices[0]
 		result.Content = choice.Message.Content
 		result.StopReason = choice.FinishReason
+		result.Refusal = choice.Message.Refusal
 
 		for _, tc := range choice.Message.ToolCalls {
 			result.ToolCalls = append(result.ToolCalls, ToolCall{
@@ -0,0 +1,206 @@
+package brain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reasons a routing override was learned.
+const (
+	OverrideReasonManual     = "manual"          // user asked for a better model
+	OverrideReasonEscalation = "auto_escalation" // a higher tier fixed a low review score
+)
+
+// RoutingOverride pins a task family (pattern fingerprint) to a model tier.
+type RoutingOverride struct {
+	Fingerprint string    `json:"fingerprint"`
+	Tier        Tier      `json:"tier"`
+	Reason      string    `json:"reason"`
+	Count       int       `json:"count"`                 // times the override was (re)confirmed
+	Description string    `json:"description,omitempty"` // example goal, for humans
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RoutingOverrides stores per-fingerprint tiers learned from escalation
+// history, persisted as JSON. A nil *RoutingOverrides has no overrides.
+type RoutingOverrides struct {
+	mu    sync.RWMutex
+	path  string // "" = in-memory only
+	items map[string]RoutingOverride
+}
+
+// NewRoutingOverrides loads overrides from path (created on first write).
+// An empty path keeps overrides in memory only.
+func NewRoutingOverrides(path string) (*RoutingOverrides, error) {
+	o := &RoutingOverrides{path: path, items: make(map[string]RoutingOverride)}
+	if path == "" {
+		return o, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("routing overrides: read: %w", err)
+	}
+	var list []RoutingOverride
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("routing overrides: parse %s: %w", path, err)
+	}
+	for _, ov := range list {
+		o.items[ov.Fingerprint] = ov
+	}
+	return o, nil
+}
+
+// Tier returns the learned tier for fingerprint.
+func (o *RoutingOverrides) Tier(fingerprint string) (Tier, bool) {
+	ov, ok := o.Get(fingerprint)
+	return ov.Tier, ok
+}
+
+// Get returns the override for fingerprint.
+func (o *RoutingOverrides) Get(fingerprint string) (RoutingOverride, bool) {
+	if o == nil || fingerprint == "" {
+		return RoutingOverride{}, false
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	ov, ok := o.items[fingerprint]
+	return ov, ok
+}
+
+// Record learns that fingerprint needs at least tier. Overrides only ever
+// move up; recording the same or a lower tier bumps Count.
+func (o *RoutingOverrides) Record(fingerprint string, tier Tier, reason, description string) error {
+	if o == nil || fingerprint == "" {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ov, ok := o.items[fingerprint]
+	if !ok || tierRank(tier) > tierRank(ov.Tier) {
+		ov.Tier = tier
+		ov.Reason = reason
+	}
+	ov.Fingerprint = fingerprint
+	ov.Count++
+	if description != "" {
+		ov.Description = description
+	}
+	ov.UpdatedAt = time.Now().UTC()
+	o.items[fingerprint] = ov
+	return o.saveLocked()
+}
+
+// List returns all overrides, most recently updated first.
+func (o *RoutingOverrides) List() []RoutingOverride {
+	if o == nil {
+		return nil
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	list := make([]RoutingOverride, 0, len(o.items))
+	for _, ov := range o.items {
+		list = append(list, ov)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UpdatedAt.After(list[j].UpdatedAt) })
+	return list
+}
+
+// Clear removes the override for fingerprint and reports whether it existed.
+func (o *RoutingOverrides) Clear(fingerprint string) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.items[fingerprint]; !ok {
+		return false, nil
+	}
+	delete(o.items, fingerprint)
+	return true, o.saveLocked()
+}
+
+// ClearAll removes every override and returns how many were removed.
+func (o *RoutingOverrides) ClearAll() (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := len(o.items)
+	o.items = make(map[string]RoutingOverride)
+	return n, o.saveLocked()
+}
+
+// saveLocked writes overrides to disk atomically. Caller holds o.mu.
+func (o *RoutingOverrides) saveLocked() error {
+	if o.path == "" {
+		return nil
+	}
+	list := make([]RoutingOverride, 0, len(o.items))
+	for _, ov := range o.items {
+		list = append(list, ov)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Fingerprint < list[j].Fingerprint })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("routing overrides: encode: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o755); err != nil {
+		return fmt.Errorf("routing overrides: mkdir: %w", err)
+	}
+	tmp := o.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("routing overrides: write: %w", err)
+	}
+	return os.Rename(tmp, o.path)
+}
+
+// RegisterRoutes exposes the learned overrides.
+// Routes: GET /api/routing/overrides, DELETE /api/routing/overrides,
+// DELETE /api/routing/overrides/{fingerprint}
+func (o *RoutingOverrides) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/routing/overrides", func(w http.ResponseWriter, r *http.Request) {
+		writeOverridesJSON(w, http.StatusOK, map[string]any{"overrides": o.List()})
+	})
+	mux.HandleFunc("DELETE /api/routing/overrides", func(w http.ResponseWriter, r *http.Request) {
+		n, err := o.ClearAll()
+		if err != nil {
+			writeOverridesJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeOverridesJSON(w, http.StatusOK, map[string]int{"cleared": n})
+	})
+	mux.HandleFunc("DELETE /api/routing/overrides/{fingerprint}", func(w http.ResponseWriter, r *http.Request) {
+		ok, err := o.Clear(r.PathValue("fingerprint"))
+		switch {
+		case err != nil:
+			writeOverridesJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		case !ok:
+			writeOverridesJSON(w, http.StatusNotFound, map[string]string{"error": "override not found"})
+		default:
+			writeOverridesJSON(w, http.StatusOK, map[string]int{"cleared": 1})
+		}
+	})
+}
+
+func writeOverridesJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// tierRank orders tiers from cheapest to most capable.
+func tierRank(t Tier) int {
+	switch t {
+	case TierCheap:
+		return 1
+	case TierMid:
+		return 2
+	case TierPowerful:
+		return 3
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package brain
 import (
 	"context"
 	"encoding/json"
+	"strings"
 )
 
 // Message represents a chat message.
@@ -18,6 +19,13 @@ type LLMRequest struct {
 	Temperature float64   `json:"temperature,omitempty"`
 	MaxTokens   int       `json:"max_tokens,omitempty"`
 	Tools       []Tool    `json:"tools,omitempty"`
+	// JSON asks for a reply that is one JSON object, on providers with a
+	// JSON mode; the prompt must still describe the object.
+	JSON bool `json:"json,omitempty"`
+	// Schema, if set, describes that object; providers that can enforce a
+	// schema (Claude's forced tool, OpenAI's json_schema) do. See
+	// StructuredComplete.
+	Schema *Schema `json:"schema,omitempty"`
 }
 
 // Tool represents a callable tool (MCP compatible).
@@ -44,6 +52,24 @@ type LLMResponse struct {
 	LatencyMs    int64      `json:"latency_ms"`
 	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
 	StopReason   string     `json:"stop_reason"`
+	// Refusal is the provider's explanation when it declined the request
+	// (OpenAI's message.refusal).
+	Refusal string `json:"refusal,omitempty"`
+	// Cached is set on a response answered from a CachingProvider.
+	Cached bool `json:"cached,omitempty"`
+}
+
+// Stop reasons of a provider declining a request instead of answering it.
+const (
+	StopRefusal       = "refusal"        // Claude
+	StopContentFilter = "content_filter" // OpenAI and compatible APIs
+)
+
+// Declined reports whether the provider refused the request or its content
+// filter stopped the reply. Content then holds the refusal, or whatever was
+// generated before the filter cut in, not an answer.
+func (r *LLMResponse) Declined() bool {
+	return r.Refusal != "" || r.StopReason == StopRefusal || r.StopReason == StopContentFilter
 }
 
 // LLMProvider is the abstract interface for LLM backends.
@@ -52,3 +78,20 @@ type LLMProvider interface {
 	Name() string
 	Models() []string
 }
+
+// IsAuthError reports whether err is a provider rejecting its credentials
+// (HTTP 401/403), as opposed to a transient failure. A run of these usually
+// means the API key was rotated or revoked.
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "API error 401") || strings.Contains(msg, "API error 403")
+}
+
+// IsRateLimitError reports whether err is a provider answering HTTP 429
+// (rate limit or quota exhausted).
+func IsRateLimitError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "API error 429")
+}
@@ -0,0 +1,192 @@
+package brain
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quota is a provider's last reported rate-limit state, parsed from the
+// response headers. Zero limits mean the provider did not report them.
+type Quota struct {
+	Provider          string    `json:"provider"`
+	RequestsLimit     int       `json:"requests_limit,omitempty"`
+	RequestsRemaining int       `json:"requests_remaining,omitempty"`
+	RequestsReset     time.Time `json:"requests_reset,omitempty"`
+	TokensLimit       int       `json:"tokens_limit,omitempty"`
+	TokensRemaining   int       `json:"tokens_remaining,omitempty"`
+	TokensReset       time.Time `json:"tokens_reset,omitempty"`
+	// RetryAfter is set after a 429 until the provider lets us back in.
+	RetryAfter time.Time `json:"retry_after,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// QuotaReporter is implemented by providers that track rate-limit headers.
+type QuotaReporter interface {
+	// Quota returns the last observed quota; ok is false until a response
+	// carrying rate-limit headers has been seen.
+	Quota() (q Quota, ok bool)
+}
+
+// Headroom returns the smallest remaining fraction (0..1) across the
+// reported limits. Limits whose reset time has passed count as refilled;
+// an active RetryAfter means no headroom at all.
+func (q Quota) Headroom(now time.Time) float64 {
+	if now.Before(q.RetryAfter) {
+		return 0
+	}
+	h := 1.0
+	if f, ok := fraction(q.RequestsRemaining, q.RequestsLimit, q.RequestsReset, now); ok && f < h {
+		h = f
+	}
+	if f, ok := fraction(q.TokensRemaining, q.TokensLimit, q.TokensReset, now); ok && f < h {
+		h = f
+	}
+	return h
+}
+
+// ResetAt returns when the tightest limit refills: RetryAfter if active,
+// otherwise the later of the reset times of limits below half. Zero when
+// unknown.
+func (q Quota) ResetAt(now time.Time) time.Time {
+	if now.Before(q.RetryAfter) {
+		return q.RetryAfter
+	}
+	var at time.Time
+	if f, ok := fraction(q.RequestsRemaining, q.RequestsLimit, q.RequestsReset, now); ok && f < 0.5 && q.RequestsReset.After(at) {
+		at = q.RequestsReset
+	}
+	if f, ok := fraction(q.TokensRemaining, q.TokensLimit, q.TokensReset, now); ok && f < 0.5 && q.TokensReset.After(at) {
+		at = q.TokensReset
+	}
+	return at
+}
+
+func fraction(remaining, limit int, reset, now time.Time) (float64, bool) {
+	if limit <= 0 || (!reset.IsZero() && !now.Before(reset)) {
+		return 0, false
+	}
+	return float64(remaining) / float64(limit), true
+}
+
+// ParseRateLimits reads rate-limit headers in the Anthropic
+// (anthropic-ratelimit-*) and OpenAI-compatible (x-ratelimit-*) formats,
+// plus Retry-After. ok is false when none are present.
+func ParseRateLimits(h http.Header, now time.Time) (q Quota, ok bool) {
+	set := func(dst *int, key string) {
+		if v, err := strconv.Atoi(strings.TrimSpace(h.Get(key))); err == nil {
+			*dst = v
+			ok = true
+		}
+	}
+	reset := func(dst *time.Time, key string) {
+		if t, good := parseReset(h.Get(key), now); good {
+			*dst = t
+			ok = true
+		}
+	}
+
+	// Anthropic: limits per minute, resets as RFC 3339 timestamps.
+	set(&q.RequestsLimit, "anthropic-ratelimit-requests-limit")
+	set(&q.RequestsRemaining, "anthropic-ratelimit-requests-remaining")
+	reset(&q.RequestsReset, "anthropic-ratelimit-requests-reset")
+	set(&q.TokensLimit, "anthropic-ratelimit-tokens-limit")
+	set(&q.TokensRemaining, "anthropic-ratelimit-tokens-remaining")
+	reset(&q.TokensReset, "anthropic-ratelimit-tokens-reset")
+
+	// OpenAI, Groq, Together: resets as durations ("6m0s", "20ms").
+	set(&q.RequestsLimit, "x-ratelimit-limit-requests")
+	set(&q.RequestsRemaining, "x-ratelimit-remaining-requests")
+	reset(&q.RequestsReset, "x-ratelimit-reset-requests")
+	set(&q.TokensLimit, "x-ratelimit-limit-tokens")
+	set(&q.TokensRemaining, "x-ratelimit-remaining-tokens")
+	reset(&q.TokensReset, "x-ratelimit-reset-tokens")
+
+	// OpenRouter and others: a single request limit, reset in epoch ms.
+	if q.RequestsLimit == 0 {
+		set(&q.RequestsLimit, "x-ratelimit-limit")
+		set(&q.RequestsRemaining, "x-ratelimit-remaining")
+		reset(&q.RequestsReset, "x-ratelimit-reset")
+	}
+
+	if v := strings.TrimSpace(h.Get("retry-after")); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			q.RetryAfter = now.Add(time.Duration(secs * float64(time.Second)))
+			ok = true
+		} else if t, err := http.ParseTime(v); err == nil {
+			q.RetryAfter = t
+			ok = true
+		}
+	}
+	if ok {
+		q.UpdatedAt = now
+	}
+	return q, ok
+}
+
+// parseReset accepts an RFC 3339 timestamp, a Go duration, plain seconds
+// or epoch seconds/milliseconds.
+func parseReset(v string, now time.Time) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(d), true
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch {
+	case n > 1e12: // epoch milliseconds
+		return time.UnixMilli(int64(n)), true
+	case n > 1e9: // epoch seconds
+		return time.Unix(int64(n), 0), true
+	}
+	return now.Add(time.Duration(n * float64(time.Second))), true
+}
+
+// quotaState is the rate-limit tracking shared by the HTTP providers.
+type quotaState struct {
+	mu   sync.Mutex
+	q    Quota
+	seen bool
+}
+
+// observe records the rate-limit headers of a response. A 429 without
+// usable headers still backs off for a short while.
+func (s *quotaState) observe(provider string, resp *http.Response) {
+	now := time.Now()
+	q, ok := ParseRateLimits(resp.Header, now)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if q.RetryAfter.IsZero() {
+			q.RetryAfter = now.Add(defaultRateLimitBackoff)
+		}
+		q.UpdatedAt = now
+		ok = true
+	}
+	if !ok {
+		return
+	}
+	q.Provider = provider
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.q = q
+	s.seen = true
+}
+
+// defaultRateLimitBackoff is how long a 429 without Retry-After blocks
+// further low-priority work.
+const defaultRateLimitBackoff = 30 * time.Second
+
+func (s *quotaState) get() (Quota, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.q, s.seen
+}
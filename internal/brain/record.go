@@ -0,0 +1,289 @@
+package brain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fixture is one recorded request and the responses it got, in order. A
+// request sent several times during a recording keeps every answer.
+type Fixture struct {
+	Key       string            `json:"key"`
+	Request   LLMRequest        `json:"request"`
+	Responses []FixtureResponse `json:"responses"`
+}
+
+// FixtureResponse is a recorded answer: a response or a provider error.
+type FixtureResponse struct {
+	Response *LLMResponse `json:"response,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// secretPatterns match credentials that must not end up in fixtures.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`),            // OpenAI, Anthropic
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]{16,}`), // auth headers pasted into text
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`),        // GitHub
+	regexp.MustCompile(`xox[abprs]-[A-Za-z0-9\-]{10,}`),     // Slack
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),                  // AWS access key
+	regexp.MustCompile(`\b\d{8,10}:[A-Za-z0-9_\-]{30,}\b`),  // Telegram bot token
+	regexp.MustCompile(`(?i)(api[_-]?key|token|secret|password)(["':=\s]+)[^\s"',]{8,}`),
+}
+
+// redactedMark replaces every secret in recorded fixtures.
+const redactedMark = "[REDACTED]"
+
+// Redact removes credentials from s: known token formats plus each of the
+// given secrets verbatim.
+func Redact(s string, secrets []string) string {
+	for _, sec := range secrets {
+		if len(sec) >= 4 {
+			s = strings.ReplaceAll(s, sec, redactedMark)
+		}
+	}
+	for i, re := range secretPatterns {
+		if i == len(secretPatterns)-1 {
+			s = re.ReplaceAllString(s, "${1}${2}"+redactedMark)
+			continue
+		}
+		s = re.ReplaceAllString(s, redactedMark)
+	}
+	return s
+}
+
+func redactRequest(req LLMRequest, secrets []string) LLMRequest {
+	msgs := make([]Message, len(req.Messages))
+	for i, m := range req.Messages {
+		msgs[i] = Message{Role: m.Role, Content: Redact(m.Content, secrets)}
+	}
+	req.Messages = msgs
+	return req
+}
+
+// volatilePatterns match values that differ between otherwise identical
+// runs (IDs, clock readings) and are ignored when matching fixtures.
+var volatilePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`),
+	regexp.MustCompile(`\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?)?`),
+	regexp.MustCompile(`\b\d{1,2}:\d{2}(:\d{2})?\b`),
+}
+
+// FixtureKey identifies a request for replay: a hash of the model,
+// sampling parameters, tool names and messages, with secrets redacted and
+// IDs and timestamps masked so a replayed run matches its recording.
+func FixtureKey(req LLMRequest, secrets []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "model=%s\ntemperature=%g\nmax_tokens=%d\n", req.Model, req.Temperature, req.MaxTokens)
+	for _, t := range req.Tools {
+		fmt.Fprintf(h, "tool=%s\n", t.Name)
+	}
+	for _, m := range req.Messages {
+		content := Redact(m.Content, secrets)
+		for _, re := range volatilePatterns {
+			content = re.ReplaceAllString(content, "#")
+		}
+		fmt.Fprintf(h, "%s:%d:%s\n", m.Role, len(content), content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Recorder wraps a live provider and writes every request/response pair
+// to a fixtures directory (one JSON file per distinct request), with
+// credentials redacted, for ReplayProvider to serve later.
+type Recorder struct {
+	inner   LLMProvider
+	dir     string
+	secrets []string
+
+	mu   sync.Mutex
+	seen map[string]*Fixture // requests recorded this session
+}
+
+// NewRecorder records inner's traffic into dir. secrets (API keys and the
+// like) are redacted on top of the built-in token formats.
+func NewRecorder(inner LLMProvider, dir string, secrets ...string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("record: mkdir: %w", err)
+	}
+	return &Recorder{inner: inner, dir: dir, secrets: secrets, seen: make(map[string]*Fixture)}, nil
+}
+
+// Complete implements LLMProvider.
+func (r *Recorder) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	resp, err := r.inner.Complete(ctx, req)
+	if ctx.Err() != nil {
+		return resp, err // cancelled, not an answer worth replaying
+	}
+
+	rec := FixtureResponse{}
+	if err != nil {
+		rec.Error = Redact(err.Error(), r.secrets)
+	} else if resp != nil {
+		clean := *resp
+		clean.Content = Redact(resp.Content, r.secrets)
+		rec.Response = &clean
+	}
+
+	key := FixtureKey(req, r.secrets)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fx, ok := r.seen[key]
+	if !ok {
+		fx = &Fixture{Key: key, Request: redactRequest(req, r.secrets)}
+		r.seen[key] = fx
+	}
+	fx.Responses = append(fx.Responses, rec)
+	if werr := writeFixture(filepath.Join(r.dir, key+".json"), fx); werr != nil {
+		return resp, errors.Join(err, werr)
+	}
+	return resp, err
+}
+
+// Name implements LLMProvider.
+func (r *Recorder) Name() string { return r.inner.Name() }
+
+// Models implements LLMProvider.
+func (r *Recorder) Models() []string { return r.inner.Models() }
+
+// Quota implements QuotaReporter when the wrapped provider does.
+func (r *Recorder) Quota() (Quota, bool) {
+	if qr, ok := r.inner.(QuotaReporter); ok {
+		return qr.Quota()
+	}
+	return Quota{}, false
+}
+
+func writeFixture(path string, fx *Fixture) error {
+	data, err := json.MarshalIndent(fx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("record: encode: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("record: write: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("record: rename: %w", err)
+	}
+	return nil
+}
+
+// ErrNoFixture is returned by ReplayProvider for requests that were never
+// recorded.
+var ErrNoFixture = errors.New("no recorded fixture for request")
+
+// ReplayProvider answers requests from fixtures written by a Recorder.
+// Repeated requests get the recorded answers in order, then the last one
+// again. It makes no network calls.
+type ReplayProvider struct {
+	name     string
+	fixtures map[string]*Fixture
+	models   []string
+	secrets  []string
+
+	mu     sync.Mutex
+	served map[string]int
+	misses []LLMRequest
+}
+
+// LoadReplay reads every fixture in dir. Pass the secrets given to the
+// Recorder if requests still contain them.
+func LoadReplay(dir string, secrets ...string) (*ReplayProvider, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	p := &ReplayProvider{name: "replay", fixtures: make(map[string]*Fixture), secrets: secrets, served: make(map[string]int)}
+	models := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("replay: read: %w", err)
+		}
+		var fx Fixture
+		if err := json.Unmarshal(data, &fx); err != nil {
+			return nil, fmt.Errorf("replay: parse %s: %w", filepath.Base(path), err)
+		}
+		if len(fx.Responses) == 0 {
+			continue
+		}
+		// Re-key from the stored request so fixtures survive edits to the
+		// file name.
+		key := FixtureKey(fx.Request, nil)
+		p.fixtures[key] = &fx
+		if fx.Request.Model != "" {
+			models[fx.Request.Model] = true
+		}
+	}
+	for m := range models {
+		p.models = append(p.models, m)
+	}
+	sort.Strings(p.models)
+	return p, nil
+}
+
+// Complete implements LLMProvider.
+func (p *ReplayProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := FixtureKey(req, p.secrets)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fx, ok := p.fixtures[key]
+	if !ok {
+		p.misses = append(p.misses, req)
+		return nil, fmt.Errorf("replay: %w (key %s, %q)", ErrNoFixture, key, lastUserMessage(req))
+	}
+	i := min(p.served[key], len(fx.Responses)-1)
+	p.served[key]++
+	rec := fx.Responses[i]
+	switch {
+	case rec.Error != "":
+		return nil, errors.New(rec.Error)
+	case rec.Response == nil:
+		return nil, fmt.Errorf("replay: fixture %s has an empty response", key)
+	}
+	resp := *rec.Response
+	return &resp, nil
+}
+
+// Name implements LLMProvider.
+func (p *ReplayProvider) Name() string { return p.name }
+
+// Models implements LLMProvider: the models seen in the recording.
+func (p *ReplayProvider) Models() []string { return p.models }
+
+// Len returns the number of distinct recorded requests.
+func (p *ReplayProvider) Len() int { return len(p.fixtures) }
+
+// Misses returns the requests that had no fixture, so a test can report
+// what needs re-recording.
+func (p *ReplayProvider) Misses() []LLMRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]LLMRequest(nil), p.misses...)
+}
+
+func lastUserMessage(req LLMRequest) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			s := req.Messages[i].Content
+			if len(s) > 80 {
+				s = s[:80] + "…"
+			}
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package brain
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// RetryPolicy says how a provider retries requests that failed for a
+// transient reason: a rate limit (429), a server error (5xx) or a network
+// failure or timeout. The zero policy doesn't retry.
+type RetryPolicy struct {
+	// MaxRetries is how many times a request is retried after the first
+	// try. 0 = no retries.
+	MaxRetries int `json:"max_retries,omitempty"`
+
+	// BaseDelayMs is the wait before the first retry; it doubles with
+	// each retry, with jitter. Default: 500.
+	BaseDelayMs int `json:"base_delay_ms,omitempty"`
+
+	// MaxDelayMs caps a single wait. A rate limit asking for a longer
+	// wait (Retry-After) is not retried. Default: 30000.
+	MaxDelayMs int `json:"max_delay_ms,omitempty"`
+}
+
+// DefaultRetryPolicy retries three times, waiting about 0.5s, 1s and 2s.
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, BaseDelayMs: 500, MaxDelayMs: 30_000}
+
+// IsRetryableError reports whether a request that failed with err may
+// succeed if sent again: the provider was unreachable or overloaded
+// (IsUnreachableError), rate-limited, or failed with a server error.
+func IsRetryableError(err error) bool {
+	if IsUnreachableError(err) || IsRateLimitError(err) {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "API error 500")
+}
+
+// backoff returns the wait before retry n (0-based): BaseDelay·2ⁿ capped
+// at MaxDelay, less up to half of it at random so that clients failing
+// together don't retry together.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	base := time.Duration(p.BaseDelayMs) * time.Millisecond
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	d := base << min(n, 20)
+	if limit := p.maxDelay(); d > limit || d <= 0 {
+		d = limit
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func (p RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelayMs <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(p.MaxDelayMs) * time.Millisecond
+}
+
+// do calls try until it succeeds, fails for good, or the retries are used
+// up. retryAfter returns when the provider's rate limit lets us back in
+// (see Quota.RetryAfter); a rate-limited request waits at least that long,
+// and is given up on if that is longer than MaxDelay. try reports whether
+// its request may be sent again.
+func (p RetryPolicy) do(ctx context.Context, retryAfter func() time.Time, try func() (resp *LLMResponse, again bool, err error)) (*LLMResponse, error) {
+	for n := 0; ; n++ {
+		resp, again, err := try()
+		if err == nil || !again || n >= p.MaxRetries || !IsRetryableError(err) || ctx.Err() != nil {
+			return resp, err
+		}
+		wait := p.backoff(n)
+		if IsRateLimitError(err) {
+			if until := time.Until(retryAfter()); until > p.maxDelay() {
+				return resp, err
+			} else if until > wait {
+				wait = until
+			}
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return resp, err
+		case <-t.C:
+		}
+	}
+}
+
+// retryStream runs complete under policy, with the rate limit of quota.
+// With onChunk set, a request is only retried while nothing has been
+// streamed: text already shown can't be taken back.
+func retryStream(ctx context.Context, policy RetryPolicy, quota *quotaState, onChunk func(string), complete func(onChunk func(string)) (*LLMResponse, error)) (*LLMResponse, error) {
+	if policy.MaxRetries <= 0 {
+		return complete(onChunk)
+	}
+	streamed := false
+	if onChunk != nil {
+		next := onChunk
+		onChunk = func(chunk string) {
+			streamed = true
+			next(chunk)
+		}
+	}
+	retryAfter := func() time.Time {
+		q, _ := quota.get()
+		return q.RetryAfter
+	}
+	return policy.do(ctx, retryAfter, func() (*LLMResponse, bool, error) {
+		resp, err := complete(onChunk)
+		return resp, !streamed, err
+	})
+}
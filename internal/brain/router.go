@@ -1,5 +1,7 @@
 package brain
 
+import "sync"
+
 // Tier represents a model cost/capability tier.
 type Tier string
 
@@ -19,8 +21,12 @@ type ModelEntry struct {
 
 // ModelRouter selects the best model based on task complexity and remaining budget.
 type ModelRouter struct {
-	models   []ModelEntry
-	provider string // Active provider filter ("claude", "openai", or "" for any)
+	modelsMu sync.RWMutex
+	models   []ModelEntry // replaced, never mutated in place (see ReplaceModel)
+	provider string       // Active provider filter ("claude", "openai", or "" for any)
+
+	overrideMu sync.RWMutex
+	override   string // Operator-selected model that bypasses tier selection
 }
 
 // NewModelRouter creates a router with default model entries.
@@ -48,6 +54,61 @@ func (r *ModelRouter) SetProvider(provider string) {
 	r.provider = provider
 }
 
+// SetOverride forces Select to return model regardless of complexity and
+// budget (e.g. an operator switching models from the kiosk). An empty
+// string restores normal tier-based selection.
+func (r *ModelRouter) SetOverride(model string) {
+	r.overrideMu.Lock()
+	defer r.overrideMu.Unlock()
+	r.override = model
+}
+
+// Override returns the current model override, or "" if none.
+func (r *ModelRouter) Override() string {
+	r.overrideMu.RLock()
+	defer r.overrideMu.RUnlock()
+	return r.override
+}
+
+// entries returns the current model entries.
+func (r *ModelRouter) entries() []ModelEntry {
+	r.modelsMu.RLock()
+	defer r.modelsMu.RUnlock()
+	return r.models
+}
+
+// Models returns the IDs of models available to the active provider.
+func (r *ModelRouter) Models() []string {
+	var ids []string
+	for _, m := range r.entries() {
+		if r.matchesProvider(m) {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// TierOf returns the tier of a known model ID, or "" if unknown.
+func (r *ModelRouter) TierOf(model string) Tier {
+	for _, m := range r.entries() {
+		if m.ID == model {
+			return m.Tier
+		}
+	}
+	return ""
+}
+
+// CostPer1K returns the blended cost per 1K tokens of a known model ID, or
+// 0 if unknown.
+func (r *ModelRouter) CostPer1K(model string) float64 {
+	for _, m := range r.entries() {
+		if m.ID == model {
+			return m.CostPer1K
+		}
+	}
+	return 0
+}
+
 // Provider returns the current provider filter.
 func (r *ModelRouter) Provider() string {
 	return r.provider
@@ -58,6 +119,19 @@ func (r *ModelRouter) Provider() string {
 // budgetRemaining is in USD.
 // If a provider filter is set, only models from that provider are considered.
 func (r *ModelRouter) Select(complexity string, budgetRemaining float64) string {
+	return r.SelectBounded(complexity, budgetRemaining, "", "")
+}
+
+// SelectBounded is Select with the target tier clamped to [minTier,
+// maxTier] after the budget downgrade ("" = unbounded), so a priority
+// policy can keep background work cheap or let critical work stay on the
+// powerful tier when the budget is low. An operator override still wins.
+func (r *ModelRouter) SelectBounded(complexity string, budgetRemaining float64, minTier, maxTier Tier) string {
+	if m := r.Override(); m != "" {
+		return m
+	}
+
+	models := r.entries()
 	targetTier := complexityToTier(complexity)
 
 	// If budget is low (less than $0.10), force downgrade to cheap tier.
@@ -67,9 +141,10 @@ func (r *ModelRouter) Select(complexity string, budgetRemaining float64) string
 		// If budget is moderate but not generous, downgrade powerful to mid.
 		targetTier = TierMid
 	}
+	targetTier = ClampTier(targetTier, minTier, maxTier)
 
 	// Find the first model matching the target tier and provider.
-	for _, m := range r.models {
+	for _, m := range models {
 		if r.matchesProvider(m) && m.Tier == targetTier {
 			return m.ID
 		}
@@ -78,7 +153,7 @@ func (r *ModelRouter) Select(complexity string, budgetRemaining float64) string
 	// Fallback: if target tier not found, try progressively cheaper tiers.
 	fallbackOrder := tierFallback(targetTier)
 	for _, tier := range fallbackOrder {
-		for _, m := range r.models {
+		for _, m := range models {
 			if r.matchesProvider(m) && m.Tier == tier {
 				return m.ID
 			}
@@ -86,15 +161,15 @@ func (r *ModelRouter) Select(complexity string, budgetRemaining float64) string
 	}
 
 	// Absolute fallback: return first available model matching provider.
-	for _, m := range r.models {
+	for _, m := range models {
 		if r.matchesProvider(m) {
 			return m.ID
 		}
 	}
 
 	// Last resort: return first model regardless of provider.
-	if len(r.models) > 0 {
-		return r.models[0].ID
+	if len(models) > 0 {
+		return models[0].ID
 	}
 	return ""
 }
@@ -118,6 +193,41 @@ func complexityToTier(complexity string) Tier {
 	}
 }
 
+// TierComplexity is the inverse of complexityToTier: the complexity
+// argument for Select that targets tier t.
+func TierComplexity(t Tier) string {
+	switch t {
+	case TierCheap:
+		return "simple"
+	case TierPowerful:
+		return "complex"
+	default:
+		return "moderate"
+	}
+}
+
+// NextTier returns the tier above t, or false if t is already the top.
+func NextTier(t Tier) (Tier, bool) {
+	switch t {
+	case TierCheap:
+		return TierMid, true
+	case TierMid:
+		return TierPowerful, true
+	}
+	return "", false
+}
+
+// ClampTier bounds t to [minTier, maxTier]; empty bounds are ignored.
+func ClampTier(t, minTier, maxTier Tier) Tier {
+	if maxTier != "" && tierRank(t) > tierRank(maxTier) {
+		t = maxTier
+	}
+	if minTier != "" && tierRank(t) < tierRank(minTier) {
+		t = minTier
+	}
+	return t
+}
+
 // tierFallback returns the fallback order for a given tier.
 func tierFallback(tier Tier) []Tier {
 	switch tier {
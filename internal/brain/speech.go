@@ -0,0 +1,348 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Speech — pluggable speech-to-text and text-to-speech backends.
+//
+// Configured like LLM providers (SpeechConfig ~ ProviderConfig):
+//   - openai       OpenAI /v1/audio/* (or any compatible server, via BaseURL)
+//   - elevenlabs   ElevenLabs Scribe / TTS
+//   - whisper.cpp  local whisper.cpp CLI (STT only)
+//   - piper        local Piper CLI (TTS only)
+//   - fake         deterministic, offline (tests and demos)
+// ---------------------------------------------------------------------------
+
+// TranscribeRequest holds audio to transcribe.
+type TranscribeRequest struct {
+	Audio    []byte `json:"-"`
+	Filename string `json:"filename,omitempty"` // extension hints the format; default "audio.wav"
+	Language string `json:"language,omitempty"` // ISO-639-1 hint, empty = auto-detect
+}
+
+// Transcript is the result of a transcription.
+type Transcript struct {
+	Text      string `json:"text"`
+	Language  string `json:"language,omitempty"`
+	Provider  string `json:"provider"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// SpeechRequest holds text to synthesize.
+type SpeechRequest struct {
+	Text  string `json:"text"`
+	Voice string `json:"voice,omitempty"` // empty = the provider's configured voice
+}
+
+// SpeechAudio is synthesized audio.
+type SpeechAudio struct {
+	Data      []byte `json:"-"`
+	MIMEType  string `json:"mime_type"`
+	Provider  string `json:"provider"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// SpeechToText is the abstract interface for transcription backends.
+type SpeechToText interface {
+	Transcribe(ctx context.Context, req TranscribeRequest) (*Transcript, error)
+	Name() string
+}
+
+// TextToSpeech is the abstract interface for speech synthesis backends.
+type TextToSpeech interface {
+	Synthesize(ctx context.Context, req SpeechRequest) (*SpeechAudio, error)
+	Name() string
+}
+
+// SpeechConfig describes how to connect to a speech provider.
+type SpeechConfig struct {
+	// Provider is "openai", "elevenlabs", "whisper.cpp", "piper" or "fake".
+	Provider string `json:"provider"`
+
+	// BaseURL overrides the API base URL of hosted providers.
+	BaseURL string `json:"base_url,omitempty"`
+
+	// APIKey authenticates hosted providers.
+	APIKey string `json:"api_key,omitempty"`
+
+	// Model is the model ID for hosted providers, or the model file path
+	// (ggml .bin, Piper .onnx) for local ones.
+	Model string `json:"model,omitempty"`
+
+	// Voice is the default TTS voice (OpenAI voice name, ElevenLabs voice ID).
+	Voice string `json:"voice,omitempty"`
+
+	// Binary is the executable of a local engine (default "whisper-cli" or
+	// "piper", looked up in PATH).
+	Binary string `json:"binary,omitempty"`
+
+	// TimeoutSeconds bounds one call. Default: 120.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+func (c SpeechConfig) timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return 120 * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// NewSpeechToText creates the transcription backend for cfg.
+func NewSpeechToText(cfg SpeechConfig) (SpeechToText, error) {
+	switch cfg.Provider {
+	case "openai":
+		return NewOpenAISpeech(cfg), nil
+	case "elevenlabs":
+		s, err := NewElevenLabsSpeech(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "whisper.cpp", "whisper":
+		s, err := NewWhisperCPP(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "fake":
+		return FakeSpeech{}, nil
+	case "piper":
+		return nil, fmt.Errorf("speech: piper does not support speech-to-text")
+	}
+	return nil, fmt.Errorf("speech: unknown STT provider %q (use: openai, elevenlabs, whisper.cpp, fake)", cfg.Provider)
+}
+
+// NewTextToSpeech creates the synthesis backend for cfg.
+func NewTextToSpeech(cfg SpeechConfig) (TextToSpeech, error) {
+	switch cfg.Provider {
+	case "openai":
+		return NewOpenAISpeech(cfg), nil
+	case "elevenlabs":
+		s, err := NewElevenLabsSpeech(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "piper":
+		s, err := NewPiper(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "fake":
+		return FakeSpeech{}, nil
+	case "whisper.cpp", "whisper":
+		return nil, fmt.Errorf("speech: whisper.cpp does not support text-to-speech")
+	}
+	return nil, fmt.Errorf("speech: unknown TTS provider %q (use: openai, elevenlabs, piper, fake)", cfg.Provider)
+}
+
+// ---------------------------------------------------------------------------
+// OpenAI
+// ---------------------------------------------------------------------------
+
+// OpenAISpeech implements SpeechToText and TextToSpeech for the OpenAI audio
+// API and compatible servers (e.g. faster-whisper-server, LocalAI).
+type OpenAISpeech struct {
+	config SpeechConfig
+	client *http.Client
+}
+
+// NewOpenAISpeech creates an OpenAI audio client. Model defaults to
+// "whisper-1" for transcription and "gpt-4o-mini-tts" for synthesis.
+func NewOpenAISpeech(cfg SpeechConfig) *OpenAISpeech {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.openai.com"
+	}
+	if cfg.Voice == "" {
+		cfg.Voice = "alloy"
+	}
+	return &OpenAISpeech{config: cfg, client: &http.Client{Timeout: cfg.timeout()}}
+}
+
+// Name returns the provider name.
+func (s *OpenAISpeech) Name() string { return "openai" }
+
+// Transcribe posts the audio to /v1/audio/transcriptions. Whisper models
+// are asked for verbose_json, which reports the detected language.
+func (s *OpenAISpeech) Transcribe(ctx context.Context, req TranscribeRequest) (*Transcript, error) {
+	start := time.Now()
+	model := s.config.Model
+	if model == "" {
+		model = "whisper-1"
+	}
+	format := "json"
+	if strings.Contains(strings.ToLower(model), "whisper") {
+		format = "verbose_json"
+	}
+	fields := map[string]string{"model": model, "response_format": format}
+	if req.Language != "" {
+		fields["language"] = req.Language
+	}
+	body, contentType, err := multipartAudio(req, fields)
+	if err != nil {
+		return nil, fmt.Errorf("openai: transcribe: %w", err)
+	}
+	data, err := s.do(ctx, "/v1/audio/transcriptions", contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("openai: transcribe: %w", err)
+	}
+	var out struct {
+		Text     string `json:"text"`
+		Language string `json:"language"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("openai: transcribe: parse: %w", err)
+	}
+	return &Transcript{
+		Text:      strings.TrimSpace(out.Text),
+		Language:  whisperLanguageCode(out.Language),
+		Provider:  s.Name(),
+		LatencyMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// Synthesize posts the text to /v1/audio/speech and returns MP3 audio.
+func (s *OpenAISpeech) Synthesize(ctx context.Context, req SpeechRequest) (*SpeechAudio, error) {
+	start := time.Now()
+	model := s.config.Model
+	if model == "" {
+		model = "gpt-4o-mini-tts"
+	}
+	voice := req.Voice
+	if voice == "" {
+		voice = s.config.Voice
+	}
+	body, err := json.Marshal(map[string]string{
+		"model": model, "voice": voice, "input": req.Text, "response_format": "mp3",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("openai: synthesize: %w", err)
+	}
+	data, err := s.do(ctx, "/v1/audio/speech", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("openai: synthesize: %w", err)
+	}
+	return &SpeechAudio{
+		Data:      data,
+		MIMEType:  "audio/mpeg",
+		Provider:  s.Name(),
+		LatencyMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// whisperLanguages maps the language names Whisper reports to ISO 639-1.
+var whisperLanguages = map[string]string{
+	"arabic": "ar", "chinese": "zh", "czech": "cs", "danish": "da", "dutch": "nl",
+	"english": "en", "finnish": "fi", "french": "fr", "german": "de", "greek": "el",
+	"hebrew": "he", "hindi": "hi", "hungarian": "hu", "italian": "it", "japanese": "ja",
+	"korean": "ko", "norwegian": "no", "polish": "pl", "portuguese": "pt", "romanian": "ro",
+	"russian": "ru", "spanish": "es", "swedish": "sv", "thai": "th", "turkish": "tr",
+	"ukrainian": "uk", "vietnamese": "vi",
+}
+
+// whisperLanguageCode returns the ISO 639-1 code of a language Whisper
+// named, or lang itself when it is already a code or unknown.
+func whisperLanguageCode(lang string) string {
+	if code, ok := whisperLanguages[strings.ToLower(lang)]; ok {
+		return code
+	}
+	return lang
+}
+
+func (s *OpenAISpeech) do(ctx context.Context, path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.config.BaseURL, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp openaiErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Message != "" {
+			return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("API error %d", resp.StatusCode)
+	}
+	return data, nil
+}
+
+// multipartAudio builds a multipart/form-data body with the audio as "file"
+// plus the given fields.
+func multipartAudio(req TranscribeRequest, fields map[string]string) (io.Reader, string, error) {
+	if len(req.Audio) == 0 {
+		return nil, "", fmt.Errorf("empty audio")
+	}
+	name := req.Filename
+	if name == "" {
+		name = "audio.wav"
+	}
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			return nil, "", err
+		}
+	}
+	fw, err := w.CreateFormFile("file", filepath.Base(name))
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := fw.Write(req.Audio); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, w.FormDataContentType(), nil
+}
+
+// ---------------------------------------------------------------------------
+// Fake
+// ---------------------------------------------------------------------------
+
+// FakeSpeech is a deterministic, offline speech backend: the "audio" it
+// synthesizes is the UTF-8 text itself, and transcription returns the audio
+// bytes as text, so a round trip is lossless.
+type FakeSpeech struct{}
+
+// Name returns the provider name.
+func (FakeSpeech) Name() string { return "fake" }
+
+// Transcribe returns the audio bytes as text.
+func (FakeSpeech) Transcribe(ctx context.Context, req TranscribeRequest) (*Transcript, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Transcript{Text: strings.TrimSpace(string(req.Audio)), Language: req.Language, Provider: "fake"}, nil
+}
+
+// Synthesize returns the text as "audio".
+func (FakeSpeech) Synthesize(ctx context.Context, req SpeechRequest) (*SpeechAudio, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &SpeechAudio{Data: []byte(req.Text), MIMEType: "text/plain", Provider: "fake"}, nil
+}
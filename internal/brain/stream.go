@@ -0,0 +1,159 @@
+package brain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Streamer is implemented by providers that can deliver a completion as it
+// is generated. onChunk receives each piece of text in order; the returned
+// response is the same as Complete's, with Content holding the whole text.
+// Tool calls are not streamed: use Complete for requests with tools.
+type Streamer interface {
+	CompleteStream(ctx context.Context, req LLMRequest, onChunk func(chunk string)) (*LLMResponse, error)
+}
+
+// CompleteStream completes req, streaming the text to onChunk when p is a
+// Streamer. Other providers, and requests with tools, are completed in one
+// go and the text is delivered as a single chunk.
+func CompleteStream(ctx context.Context, p LLMProvider, req LLMRequest, onChunk func(chunk string)) (*LLMResponse, error) {
+	if s, ok := p.(Streamer); ok && len(req.Tools) == 0 {
+		return s.CompleteStream(ctx, req, onChunk)
+	}
+	resp, err := p.Complete(ctx, req)
+	if err == nil && resp.Content != "" {
+		onChunk(resp.Content)
+	}
+	return resp, err
+}
+
+// streamEvent is one server-sent event of either streaming dialect: OpenAI
+// chat completion chunks (choices[].delta) or Anthropic message events
+// (type + delta).
+type streamEvent struct {
+	// Anthropic.
+	Type    string `json:"type"`
+	Message struct {
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+
+	// OpenAI.
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		OutputTokens     int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// decodeStream reads a text/event-stream body into a response, passing
+// each text delta to onChunk. Token counts are estimated when the server
+// does not report usage.
+func decodeStream(body io.Reader, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
+	resp := &LLMResponse{Model: req.Model}
+	var content strings.Builder
+
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue // event names, comments, keep-alives
+		}
+		data = strings.TrimSpace(data)
+		if data == "" {
+			continue
+		}
+		if data == "[DONE]" {
+			break
+		}
+		var ev streamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return nil, fmt.Errorf("stream: decode event: %w", err)
+		}
+		if ev.Error != nil {
+			return nil, fmt.Errorf("stream: %s: %s", ev.Error.Type, ev.Error.Message)
+		}
+
+		var text string
+		switch ev.Type {
+		case "message_start":
+			if ev.Message.Model != "" {
+				resp.Model = ev.Message.Model
+			}
+			resp.InputTokens = ev.Message.Usage.InputTokens
+		case "content_block_delta":
+			if ev.Delta.Type == "text_delta" {
+				text = ev.Delta.Text
+			}
+		case "message_delta":
+			if ev.Delta.StopReason != "" {
+				resp.StopReason = ev.Delta.StopReason
+			}
+		case "message_stop":
+		default:
+			if ev.Model != "" {
+				resp.Model = ev.Model
+			}
+			if len(ev.Choices) > 0 {
+				text = ev.Choices[0].Delta.Content
+				if r := ev.Choices[0].FinishReason; r != "" {
+					resp.StopReason = r
+				}
+			}
+		}
+		if ev.Usage != nil {
+			if ev.Usage.PromptTokens > 0 {
+				resp.InputTokens = ev.Usage.PromptTokens
+			}
+			resp.OutputTokens = max(ev.Usage.CompletionTokens, ev.Usage.OutputTokens)
+		}
+		if text != "" {
+			content.WriteString(text)
+			onChunk(text)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("stream: read: %w", err)
+	}
+
+	resp.Content = content.String()
+	if resp.InputTokens == 0 {
+		for _, m := range req.Messages {
+			resp.InputTokens += estimateTokens(m.Content)
+		}
+	}
+	if resp.OutputTokens == 0 {
+		resp.OutputTokens = estimateTokens(resp.Content)
+	}
+	return resp, nil
+}
+
+// isEventStream reports whether a response carries server-sent events.
+// Servers that ignore "stream" answer with plain JSON instead.
+func isEventStream(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/event-stream")
+}
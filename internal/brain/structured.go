@@ -0,0 +1,153 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Schema describes the JSON object a structured request asks for.
+type Schema struct {
+	// Name identifies the object, e.g. "review"; letters, digits, _ and -.
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// JSON is the JSON Schema of the object.
+	JSON json.RawMessage `json:"schema"`
+}
+
+// ErrUnstructured is returned by StructuredComplete when the reply could
+// not be read even after a retry.
+var ErrUnstructured = errors.New("reply is not the requested JSON object")
+
+// TextReply is implemented by structured replies that can also be read
+// from a stage's older line format ("SCORE: 0.8"), for models that ignore
+// the JSON instructions. ParseText reports whether text was in it.
+type TextReply interface {
+	ParseText(text string) bool
+}
+
+// ValidReply is implemented by structured replies that check their
+// decoded values; a reply that fails the check is asked for again.
+type ValidReply interface {
+	Validate() error
+}
+
+// structuredRetries is how many times an unreadable reply is asked for
+// again.
+const structuredRetries = 1
+
+// StructuredComplete sends req asking for a reply that is one JSON object
+// described by schema, and decodes it into out, a pointer to a struct
+// whose current value holds the defaults. The provider's JSON mode or
+// schema enforcement is used where it has one; the prompt must still
+// describe the object for those that don't. A reply that can't be read
+// (see DecodeStructured) is asked for again once, quoting the schema.
+//
+// The returned response is the last one, with the cost, tokens and
+// latency of all attempts. When no reply could be read the error wraps
+// ErrUnstructured and out is left as it was. A call that opted in with
+// WithCache caches only replies that can be read.
+func StructuredComplete(ctx context.Context, llm LLMProvider, req LLMRequest, schema Schema, out any) (*LLMResponse, error) {
+	ctx = withCacheCheck(ctx, func(resp *LLMResponse) error {
+		return decodeCopy(resp.Content, out)
+	})
+	req.JSON = true
+	req.Schema = &schema
+	req.Messages = append([]Message(nil), req.Messages...)
+
+	var total LLMResponse
+	for attempt := 0; ; attempt++ {
+		resp, err := llm.Complete(ctx, req)
+		if err != nil {
+			if attempt > 0 {
+				return addUsage(&total, nil), err
+			}
+			return nil, err
+		}
+		resp = addUsage(&total, resp)
+		if resp.Declined() {
+			return resp, fmt.Errorf("%w: the provider declined the request", ErrUnstructured)
+		}
+		derr := DecodeStructured(resp.Content, out)
+		if derr == nil {
+			return resp, nil
+		}
+		if attempt >= structuredRetries {
+			return resp, fmt.Errorf("%w: %v", ErrUnstructured, derr)
+		}
+		req.Messages = append(req.Messages,
+			Message{Role: "assistant", Content: resp.Content},
+			Message{Role: "user", Content: fmt.Sprintf(
+				"That reply could not be read (%v). Respond with only a JSON object matching this schema:\n%s", derr, schema.JSON)})
+	}
+}
+
+// addUsage adds resp's cost, tokens and latency to total and returns resp
+// carrying the totals; with resp nil, a copy of total.
+func addUsage(total *LLMResponse, resp *LLMResponse) *LLMResponse {
+	if resp == nil {
+		r := *total
+		return &r
+	}
+	total.CostUSD += resp.CostUSD
+	total.InputTokens += resp.InputTokens
+	total.OutputTokens += resp.OutputTokens
+	total.LatencyMs += resp.LatencyMs
+	r := *resp
+	r.CostUSD, r.InputTokens, r.OutputTokens, r.LatencyMs = total.CostUSD, total.InputTokens, total.OutputTokens, total.LatencyMs
+	return &r
+}
+
+// decodeCopy is DecodeStructured into a copy of *out, leaving out alone.
+func decodeCopy(text string, out any) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return DecodeStructured(text, out)
+	}
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	return DecodeStructured(text, cp.Interface())
+}
+
+// DecodeStructured reads a structured reply into out, a pointer to a
+// struct whose current value holds the defaults: the JSON object in text
+// (code fences and surrounding prose are ignored), or else the older line
+// format if out is a TextReply. The result is checked if out is a
+// ValidReply. On error out is left as it was.
+func DecodeStructured(text string, out any) error {
+	dst := reflect.ValueOf(out)
+	if dst.Kind() != reflect.Pointer || dst.IsNil() {
+		return fmt.Errorf("structured reply: need a non-nil pointer, got %T", out)
+	}
+	v := reflect.New(dst.Elem().Type())
+	v.Elem().Set(dst.Elem())
+	reply := v.Interface()
+
+	if obj, ok := jsonObject(text); ok {
+		if err := json.Unmarshal([]byte(obj), reply); err != nil {
+			return fmt.Errorf("invalid JSON: %v", err)
+		}
+	} else if tr, ok := reply.(TextReply); !ok || !tr.ParseText(text) {
+		return fmt.Errorf("no JSON object")
+	}
+	if vr, ok := reply.(ValidReply); ok {
+		if err := vr.Validate(); err != nil {
+			return err
+		}
+	}
+	dst.Elem().Set(v.Elem())
+	return nil
+}
+
+// jsonObject returns the outermost {...} of text, if it is valid JSON.
+func jsonObject(text string) (string, bool) {
+	i, j := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if i < 0 || j < i {
+		return "", false
+	}
+	obj := text[i : j+1]
+	return obj, json.Valid([]byte(obj))
+}
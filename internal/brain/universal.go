@@ -65,6 +65,14 @@ type ProviderConfig struct {
 	// MaxTokensDefault is the default max_tokens if not specified in request.
 	// Default: 4096.
 	MaxTokensDefault int `json:"max_tokens_default,omitempty"`
+
+	// JSONMode sends response_format json_object for requests that ask for
+	// JSON. Leave it off for backends that reject the parameter.
+	JSONMode bool `json:"json_mode,omitempty"`
+
+	// Retry retries requests failing with a rate limit, a server error or
+	// a timeout. Default: no retries.
+	Retry RetryPolicy `json:"retry,omitempty"`
 }
 
 // ModelConfig describes a single model available from a provider.
@@ -80,6 +88,7 @@ type ModelConfig struct {
 type UniversalProvider struct {
 	config ProviderConfig
 	client *http.Client
+	quota  quotaState
 }
 
 // NewUniversalProvider creates a provider from config.
@@ -148,6 +157,20 @@ func (p *UniversalProvider) ModelEntries() []ModelEntry {
 
 // Complete sends a chat completion request.
 func (p *UniversalProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return p.CompleteStream(ctx, req, nil)
+}
+
+// CompleteStream sends a streaming chat completion request, passing the
+// text to onChunk as it arrives. A request is retried per the Retry policy
+// until its first chunk has arrived.
+func (p *UniversalProvider) CompleteStream(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
+	return retryStream(ctx, p.config.Retry, &p.quota, onChunk, func(onChunk func(string)) (*LLMResponse, error) {
+		return p.complete(ctx, req, onChunk)
+	})
+}
+
+// complete streams the response when onChunk is set.
+func (p *UniversalProvider) complete(ctx context.Context, req LLMRequest, onChunk func(string)) (*LLMResponse, error) {
 	model := req.Model
 	if model == "" {
 		model = p.config.DefaultModel
@@ -168,6 +191,13 @@ func (p *UniversalProvider) Complete(ctx context.Context, req LLMRequest) (*LLMR
 		Model:    model,
 		Messages: msgs,
 	}
+	if onChunk != nil {
+		or.Stream = true
+		or.StreamOptions = &openaiStreamOptions{IncludeUsage: true}
+	}
+	if req.JSON && p.config.JSONMode {
+		or.ResponseFormat = &openaiFormat{Type: "json_object"}
+	}
 
 	if req.Temperature > 0 {
 		t := req.Temperature
@@ -221,6 +251,17 @@ func (p *UniversalProvider) Complete(ctx context.Context, req LLMRequest) (*LLMR
 		return nil, fmt.Errorf("%s: http request: %w", p.config.Name, err)
 	}
 	defer resp.Body.Close()
+	p.quota.observe(p.config.Name, resp)
+
+	if onChunk != nil && resp.StatusCode == http.StatusOK && isEventStream(resp.Header.Get("Content-Type")) {
+		result, err := decodeStream(resp.Body, LLMRequest{Model: model, Messages: req.Messages}, onChunk)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.config.Name, err)
+		}
+		result.LatencyMs = time.Since(start).Milliseconds()
+		result.CostUSD = p.calculateCost(model, result.InputTokens, result.OutputTokens)
+		return result, nil
+	}
 
 	latency := time.Since(start).Milliseconds()
 
@@ -254,6 +295,7 @@ func (p *UniversalProvider) Complete(ctx context.Context, req LLMRequest) (*LLMR
 		choice := or2.Choices[0]
 		result.Content = choice.Message.Content
 		result.StopReason = choice.FinishReason
+		result.Refusal = choice.Message.Refusal
 
 		for _, tc := range choice.Message.ToolCalls {
 			result.ToolCalls = append(result.ToolCalls, ToolCall{
@@ -267,9 +309,15 @@ func (p *UniversalProvider) Complete(ctx context.Context, req LLMRequest) (*LLMR
 	// Calculate cost.
 	result.CostUSD = p.calculateCost(model, result.InputTokens, result.OutputTokens)
 
+	if onChunk != nil && result.Content != "" {
+		onChunk(result.Content) // the server ignored "stream"
+	}
 	return result, nil
 }
 
+// Quota returns the rate limits reported by the last response.
+func (p *UniversalProvider) Quota() (Quota, bool) { return p.quota.get() }
+
 // calculateCost computes cost based on model config.
 func (p *UniversalProvider) calculateCost(model string, inputTokens, outputTokens int) float64 {
 	for _, m := range p.config.Models {
@@ -298,6 +346,7 @@ func OpenAIConfig(apiKey string) ProviderConfig {
 		BaseURL:      "https://api.openai.com",
 		APIKey:       apiKey,
 		DefaultModel: "o4-mini",
+		JSONMode:     true,
 		Models: []ModelConfig{
 			{ID: "o4-mini", Tier: "cheap", InputCostPerM: 1.10, OutputCostPerM: 4.40},
 			{ID: "o3", Tier: "mid", InputCostPerM: 2.00, OutputCostPerM: 8.00},
@@ -339,6 +388,7 @@ func OllamaConfig(model string) ProviderConfig {
 		Name:         "ollama",
 		BaseURL:      "http://localhost:11434",
 		DefaultModel: model,
+		JSONMode:     true,
 		Models: []ModelConfig{
 			{ID: model, Tier: "mid", CostPer1K: 0}, // Free, local
 		},
@@ -385,6 +435,7 @@ func GroqConfig(apiKey string) ProviderConfig {
 		BaseURL:      "https://api.groq.com/openai",
 		APIKey:       apiKey,
 		DefaultModel: "llama-3.3-70b-versatile",
+		JSONMode:     true,
 		Models: []ModelConfig{
 			{ID: "llama-3.3-70b-versatile", Tier: "mid", InputCostPerM: 0.59, OutputCostPerM: 0.79},
 			{ID: "llama-3.1-8b-instant", Tier: "cheap", InputCostPerM: 0.05, OutputCostPerM: 0.08},
@@ -401,6 +452,7 @@ func TogetherConfig(apiKey string) ProviderConfig {
 		BaseURL:      "https://api.together.xyz",
 		APIKey:       apiKey,
 		DefaultModel: "meta-llama/Meta-Llama-3.1-70B-Instruct-Turbo",
+		JSONMode:     true,
 		Models: []ModelConfig{
 			{ID: "meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo", Tier: "cheap", InputCostPerM: 0.18, OutputCostPerM: 0.18},
 			{ID: "meta-llama/Meta-Llama-3.1-70B-Instruct-Turbo", Tier: "mid", InputCostPerM: 0.88, OutputCostPerM: 0.88},
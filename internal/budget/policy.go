@@ -0,0 +1,77 @@
+package budget
+
+import "strings"
+
+// Tier names used by policy rules. They match brain.Tier values.
+const (
+	TierCheap    = "cheap"
+	TierMid      = "mid"
+	TierPowerful = "powerful"
+)
+
+// PriorityRule is one row of the priority policy table: how much model and
+// budget a task of that priority may use.
+type PriorityRule struct {
+	// MinTier and MaxTier bound the execution tier ("cheap", "mid",
+	// "powerful"; "" = unbounded).
+	MinTier string `json:"min_tier,omitempty"`
+	MaxTier string `json:"max_tier,omitempty"`
+
+	// Overdraft lets the task spend past the daily/monthly limits, which
+	// become soft caps, by this fraction of the limit (0.25 = 25% over).
+	Overdraft float64 `json:"overdraft,omitempty"`
+
+	// DeferBelow defers the task while less than this fraction of the
+	// budget remains (0.2 = defer in the last 20%). 0 never defers.
+	DeferBelow float64 `json:"defer_below,omitempty"`
+
+	// DeferQuotaBelow defers the task while the provider's rate-limit
+	// headroom (remaining requests/tokens in the current window) is below
+	// this fraction, so background work does not push the provider into
+	// 429s. 0 never defers.
+	DeferQuotaBelow float64 `json:"defer_quota_below,omitempty"`
+}
+
+// Policy maps priority labels ("low", "normal", "high", "critical") to
+// rules. Missing priorities use the zero rule: no bounds, hard caps.
+type Policy map[string]PriorityRule
+
+// DefaultPolicy is the built-in table: critical inputs may use the
+// powerful tier and overdraw by 25%; low-priority background work stays on
+// the cheap tier and waits while the last 20% of the budget or of the
+// provider's rate limit remains.
+func DefaultPolicy() Policy {
+	return Policy{
+		"low":      {MaxTier: TierCheap, DeferBelow: 0.2, DeferQuotaBelow: 0.2},
+		"normal":   {},
+		"high":     {},
+		"critical": {MinTier: TierPowerful, Overdraft: 0.25},
+	}
+}
+
+// Merge returns a copy of p with the rows of override replacing p's.
+// Keys are case-insensitive.
+func (p Policy) Merge(override Policy) Policy {
+	out := make(Policy, len(p)+len(override))
+	for k, r := range p {
+		out[strings.ToLower(k)] = r
+	}
+	for k, r := range override {
+		out[strings.ToLower(k)] = r
+	}
+	return out
+}
+
+// Rule returns the rule for priority (case-insensitive).
+func (p Policy) Rule(priority string) PriorityRule {
+	return p[strings.ToLower(priority)]
+}
+
+// ShouldDefer reports whether a task under rule should wait. A nil tracker
+// never defers.
+func (r PriorityRule) ShouldDefer(t *Tracker) bool {
+	if t == nil || r.DeferBelow <= 0 {
+		return false
+	}
+	return t.RemainingFraction() < r.DeferBelow
+}
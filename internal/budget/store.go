@@ -0,0 +1,45 @@
+package budget
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Open creates a tracker like New whose spending is kept in db, one row
+// per day in the budget_spend table, so the daily and monthly totals
+// survive a restart.
+func Open(db *sql.DB, dailyLimit, monthlyLimit float64) (*Tracker, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS budget_spend (
+		day      TEXT PRIMARY KEY,
+		cost_usd REAL NOT NULL DEFAULT 0
+	);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("budget: create table: %w", err)
+	}
+
+	t := New(dailyLimit, monthlyLimit)
+	err := db.QueryRow(`
+	SELECT
+		COALESCE(SUM(CASE WHEN day = ? THEN cost_usd END), 0),
+		COALESCE(SUM(CASE WHEN substr(day, 1, 7) = ? THEN cost_usd END), 0),
+		COALESCE(SUM(cost_usd), 0)
+	FROM budget_spend`, t.dayKey, t.monthKey).Scan(&t.dailySpend, &t.monthlySpend, &t.totalSpend)
+	if err != nil {
+		return nil, fmt.Errorf("budget: load spending: %w", err)
+	}
+	t.db = db
+	return t, nil
+}
+
+// persist adds costUSD to the spending of day.
+func (t *Tracker) persist(day string, costUSD float64) {
+	_, err := t.db.Exec(`
+	INSERT INTO budget_spend (day, cost_usd) VALUES (?, ?)
+	ON CONFLICT(day) DO UPDATE SET cost_usd = budget_spend.cost_usd + excluded.cost_usd`,
+		day, costUSD)
+	if err != nil {
+		log.Printf("[budget] record spending: %v", err)
+	}
+}
@@ -6,6 +6,7 @@
 package budget
 
 import (
+	"database/sql"
 	"fmt"
 	"sync"
 	"time"
@@ -28,6 +29,22 @@ type Tracker struct {
 	taskSpend map[string]float64
 	dayKey    string // "2006-01-02" — reset daily when date changes
 	monthKey  string // "2006-01" — reset monthly when month changes
+
+	onThreshold func(ThresholdEvent)
+
+	db *sql.DB // see Open; nil keeps spending in memory only
+}
+
+// Thresholds are the fractions of a limit whose crossing is reported to
+// OnThreshold.
+var Thresholds = []float64{0.8, 1.0}
+
+// ThresholdEvent reports spending crossing a fraction of a limit.
+type ThresholdEvent struct {
+	Period    string  `json:"period"`    // "daily" or "monthly"
+	Threshold float64 `json:"threshold"` // one of Thresholds
+	SpendUSD  float64 `json:"spend_usd"`
+	LimitUSD  float64 `json:"limit_usd"`
 }
 
 // New creates a budget tracker with the given limits.
@@ -43,17 +60,54 @@ func New(dailyLimit, monthlyLimit float64) *Tracker {
 	}
 }
 
-// Record records a cost against a task ID.
-func (t *Tracker) Record(taskID string, costUSD float64) {
+// OnThreshold registers fn to be called when a Record pushes daily or
+// monthly spending past one of Thresholds. Each crossing fires once per
+// period. fn runs without the tracker lock held.
+func (t *Tracker) OnThreshold(fn func(ThresholdEvent)) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	t.onThreshold = fn
+}
 
+// Record records a cost against a task ID.
+func (t *Tracker) Record(taskID string, costUSD float64) {
+	t.mu.Lock()
 	t.maybeReset()
 
+	var crossed []ThresholdEvent
+	crossed = appendCrossed(crossed, "daily", t.dailySpend, t.dailySpend+costUSD, t.dailyLimit)
+	crossed = appendCrossed(crossed, "monthly", t.monthlySpend, t.monthlySpend+costUSD, t.monthlyLimit)
+
 	t.dailySpend += costUSD
 	t.monthlySpend += costUSD
 	t.totalSpend += costUSD
 	t.taskSpend[taskID] += costUSD
+	fn, day := t.onThreshold, t.dayKey
+	t.mu.Unlock()
+
+	if t.db != nil && costUSD != 0 {
+		t.persist(day, costUSD)
+	}
+
+	if fn != nil {
+		for _, ev := range crossed {
+			fn(ev)
+		}
+	}
+}
+
+// appendCrossed adds an event for each threshold that spending moving from
+// before to after crosses.
+func appendCrossed(events []ThresholdEvent, period string, before, after, limit float64) []ThresholdEvent {
+	if limit <= 0 {
+		return events
+	}
+	for _, th := range Thresholds {
+		if before < limit*th && after >= limit*th {
+			events = append(events, ThresholdEvent{Period: period, Threshold: th, SpendUSD: after, LimitUSD: limit})
+		}
+	}
+	return events
 }
 
 // CanSpend returns true if spending the given amount would stay within limits.
@@ -70,6 +124,51 @@ func (t *Tracker) CanSpend(amount float64) bool {
 	return true
 }
 
+// CanSpendWithin is CanSpend with the limits raised by overdraft, a
+// fraction of each limit (see PriorityRule.Overdraft).
+func (t *Tracker) CanSpendWithin(amount, overdraft float64) bool {
+	return t.ExceededPeriod(amount, overdraft) == ""
+}
+
+// ExceededPeriod returns the limit spending amount would exceed, raised by
+// overdraft as in CanSpendWithin: "daily", "monthly", or "" if it fits.
+func (t *Tracker) ExceededPeriod(amount, overdraft float64) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	factor := 1 + max(overdraft, 0)
+	if t.dailyLimit > 0 && t.dailySpend+amount > t.dailyLimit*factor {
+		return "daily"
+	}
+	if t.monthlyLimit > 0 && t.monthlySpend+amount > t.monthlyLimit*factor {
+		return "monthly"
+	}
+	return ""
+}
+
+// Limits returns the daily and monthly limits (0 = no limit).
+func (t *Tracker) Limits() (daily, monthly float64) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.dailyLimit, t.monthlyLimit
+}
+
+// RemainingFraction returns the smaller of the daily and monthly remaining
+// budget as a fraction of the limit (0-1), or 1 if unlimited.
+func (t *Tracker) RemainingFraction() float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	frac := 1.0
+	if t.dailyLimit > 0 {
+		frac = min(frac, max(t.dailyLimit-t.dailySpend, 0)/t.dailyLimit)
+	}
+	if t.monthlyLimit > 0 {
+		frac = min(frac, max(t.monthlyLimit-t.monthlySpend, 0)/t.monthlyLimit)
+	}
+	return frac
+}
+
 // RemainingDaily returns the remaining daily budget. Returns -1 if no limit.
 func (t *Tracker) RemainingDaily() float64 {
 	t.mu.RLock()
@@ -180,6 +279,32 @@ func (t *Tracker) EffectiveBudget() float64 {
 	return monthly
 }
 
+// EffectiveBudgetWithin is EffectiveBudget with the limits raised by
+// overdraft, so a task allowed to exceed the soft caps is not downgraded
+// as soon as they are reached.
+func (t *Tracker) EffectiveBudgetWithin(overdraft float64) float64 {
+	if overdraft <= 0 {
+		return t.EffectiveBudget()
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	remaining := -1.0
+	for _, lim := range [][2]float64{{t.dailyLimit, t.dailySpend}, {t.monthlyLimit, t.monthlySpend}} {
+		if lim[0] <= 0 {
+			continue
+		}
+		r := max(lim[0]*(1+overdraft)-lim[1], 0)
+		if remaining < 0 || r < remaining {
+			remaining = r
+		}
+	}
+	if remaining < 0 {
+		return 1000.0 // Unlimited.
+	}
+	return remaining
+}
+
 // maybeReset resets daily/monthly counters when the period changes.
 // Must be called with mu held.
 func (t *Tracker) maybeReset() {
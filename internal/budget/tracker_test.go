@@ -1,8 +1,13 @@
 package budget
 
 import (
+	"database/sql"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
 )
 
 func TestTracker_Record(t *testing.T) {
@@ -29,6 +34,29 @@ func TestTracker_Record(t *testing.T) {
 	}
 }
 
+func TestTracker_OnThreshold(t *testing.T) {
+	tr := New(1.0, 100.0)
+	var events []ThresholdEvent
+	tr.OnThreshold(func(ev ThresholdEvent) { events = append(events, ev) })
+
+	tr.Record("t1", 0.5)
+	if len(events) != 0 {
+		t.Fatalf("events at 50%% = %+v", events)
+	}
+	tr.Record("t2", 0.35) // 85% daily
+	tr.Record("t3", 0.01) // still above 80%, no repeat
+	tr.Record("t4", 0.2)  // 106% daily
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 80%% then 100%%", events)
+	}
+	if ev := events[0]; ev.Period != "daily" || ev.Threshold != 0.8 || ev.LimitUSD != 1.0 {
+		t.Errorf("first event = %+v", ev)
+	}
+	if ev := events[1]; ev.Threshold != 1.0 || ev.SpendUSD < 1.0 {
+		t.Errorf("second event = %+v", ev)
+	}
+}
+
 func TestTracker_CanSpend(t *testing.T) {
 	tr := New(1.0, 10.0)
 
@@ -188,3 +216,107 @@ func TestTracker_TaskSpend_Unknown(t *testing.T) {
 		t.Error("unknown task should return 0")
 	}
 }
+
+func TestTracker_CanSpendWithin(t *testing.T) {
+	tr := New(1.0, 0)
+	tr.Record("t", 1.1)
+
+	if tr.CanSpend(0.01) {
+		t.Error("hard cap should be exceeded")
+	}
+	if !tr.CanSpendWithin(0.01, 0.25) {
+		t.Error("25% overdraft should allow spending up to 1.25")
+	}
+	if tr.CanSpendWithin(0.2, 0.25) {
+		t.Error("spending past the overdraft should be refused")
+	}
+	if got := tr.EffectiveBudgetWithin(0.25); got < 0.149 || got > 0.151 {
+		t.Errorf("EffectiveBudgetWithin = %f, want 0.15", got)
+	}
+	if got := tr.EffectiveBudgetWithin(0); got != 0 {
+		t.Errorf("EffectiveBudgetWithin(0) = %f, want 0", got)
+	}
+}
+
+func TestTracker_RemainingFraction(t *testing.T) {
+	if got := New(0, 0).RemainingFraction(); got != 1 {
+		t.Errorf("unlimited RemainingFraction = %f, want 1", got)
+	}
+	tr := New(10.0, 20.0)
+	tr.Record("t", 9.0)
+	if got := tr.RemainingFraction(); got < 0.099 || got > 0.101 {
+		t.Errorf("RemainingFraction = %f, want 0.1 (daily is tighter)", got)
+	}
+}
+
+func TestPolicy_MergeAndDefer(t *testing.T) {
+	p := DefaultPolicy().Merge(Policy{"LOW": {MaxTier: TierCheap, DeferBelow: 0.5}})
+
+	if r := p.Rule("Critical"); r.MinTier != TierPowerful || r.Overdraft <= 0 {
+		t.Errorf("critical rule = %+v", r)
+	}
+	low := p.Rule("low")
+	if low.DeferBelow != 0.5 {
+		t.Errorf("override not applied: %+v", low)
+	}
+	if p.Rule("unknown") != (PriorityRule{}) {
+		t.Error("unknown priority should use the zero rule")
+	}
+
+	tr := New(10.0, 0)
+	if low.ShouldDefer(tr) {
+		t.Error("full budget should not defer")
+	}
+	tr.Record("t", 6.0)
+	if !low.ShouldDefer(tr) {
+		t.Error("40% remaining should defer below 50%")
+	}
+	if low.ShouldDefer(nil) || p.Rule("normal").ShouldDefer(tr) {
+		t.Error("nil tracker and rules without DeferBelow never defer")
+	}
+}
+
+func TestOpen_PersistsSpending(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "budget.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tr, err := Open(db, 1.0, 20.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Record("t1", 0.4)
+	tr.Record("t2", 0.5)
+	// Spending earlier this month and last month.
+	now := time.Now()
+	wantMonthly := 0.9
+	if now.Day() > 1 {
+		db.Exec(`INSERT INTO budget_spend (day, cost_usd) VALUES (?, 2)`, now.AddDate(0, 0, -1).Format("2006-01-02"))
+		wantMonthly += 2
+	}
+	db.Exec(`INSERT INTO budget_spend (day, cost_usd) VALUES (?, 5)`, now.AddDate(0, 0, -now.Day()).Format("2006-01-02"))
+
+	// A restarted daemon picks the totals up again.
+	tr, err = Open(db, 1.0, 20.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	near := func(got, want float64) bool { return got > want-1e-9 && got < want+1e-9 }
+	if d := tr.DailySpend(); !near(d, 0.9) {
+		t.Errorf("DailySpend = %f, want 0.9", d)
+	}
+	if m := tr.MonthlySpend(); !near(m, wantMonthly) {
+		t.Errorf("MonthlySpend = %f, want %f", m, wantMonthly)
+	}
+	if tot := tr.TotalSpend(); !near(tot, wantMonthly+5) {
+		t.Errorf("TotalSpend = %f, want %f", tot, wantMonthly+5)
+	}
+	if got := tr.ExceededPeriod(0.2, 0); got != "daily" {
+		t.Errorf("ExceededPeriod = %q, want daily", got)
+	}
+	if tr.ExceededPeriod(0.05, 0) != "" || !tr.CanSpendWithin(0.2, 0.5) {
+		t.Error("spending within the (overdrawn) limits reported as exceeded")
+	}
+}
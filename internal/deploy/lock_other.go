@@ -0,0 +1,24 @@
+//go:build !unix
+
+package deploy
+
+import (
+	"errors"
+	"os"
+)
+
+var errLockHeld = errors.New("lock held by another process")
+
+const flockSupported = false
+
+// lockFile is a no-op where flock is unavailable; the PID check in Lock
+// and IsRunning is all the protection there is.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// lockHeld falls back to checking the recorded PID.
+func lockHeld(path string) bool {
+	info, _, err := (&PIDFile{path: path}).owner()
+	return err == nil && info.PID != 0 && processExists(info.PID)
+}
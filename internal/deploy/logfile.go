@@ -0,0 +1,75 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// LogFile is an append-only log file that can be rotated while in use:
+// writes made during a rotation go to the new file.
+type LogFile struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// OpenLogFile opens (or creates) the log at path for appending.
+func OpenLogFile(path string) (*LogFile, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &LogFile{path: path, f: f}, nil
+}
+
+// Path returns the log's path.
+func (l *LogFile) Path() string { return l.path }
+
+// Write implements io.Writer.
+func (l *LogFile) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Write(p)
+}
+
+// Rotate renames the log to path.1 (shifting older generations up to
+// path.<keep> and dropping the oldest) and starts a new one, once it has
+// reached maxBytes. It reports whether it rotated.
+func (l *LogFile) Rotate(maxBytes int64, keep int) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fi, err := l.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if fi.Size() < maxBytes {
+		return false, nil
+	}
+	if keep < 1 {
+		keep = 1
+	}
+	os.Remove(fmt.Sprintf("%s.%d", l.path, keep))
+	for i := keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	// Windows cannot rename an open file.
+	l.f.Close()
+	renameErr := os.Rename(l.path, l.path+".1")
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return false, err
+	}
+	l.f = f
+	if renameErr != nil {
+		return false, renameErr
+	}
+	return true, nil
+}
+
+// Close closes the file.
+func (l *LogFile) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
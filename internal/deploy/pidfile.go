@@ -1,19 +1,52 @@
 package deploy
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 const pidFileName = "overhuman.pid"
 
-// PIDFile manages the daemon's PID file.
+// LockInfo is the ownership metadata the daemon writes into its lock file.
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Version   string    `json:"version,omitempty"`
+	Command   string    `json:"command,omitempty"`
+}
+
+// LockedError is returned by Lock when another process owns the lock.
+type LockedError struct {
+	Owner LockInfo
+}
+
+func (e *LockedError) Error() string {
+	msg := fmt.Sprintf("daemon already running (pid=%d", e.Owner.PID)
+	if e.Owner.Host != "" {
+		msg += ", host=" + e.Owner.Host
+	}
+	if !e.Owner.StartedAt.IsZero() {
+		msg += ", since " + e.Owner.StartedAt.Local().Format(time.DateTime)
+	}
+	return msg + ")"
+}
+
+// PIDFile manages the daemon's PID file. The daemon holds it as an
+// advisory file lock (flock) for as long as it runs, so a crashed daemon's
+// file is recognised as stale even if its PID has been reused. Files
+// holding a bare PID, as written by Write and older releases, fall back to
+// checking whether that process is alive.
 type PIDFile struct {
 	path string
+	f    *os.File // open while this process holds the lock
 }
 
 // NewPIDFile creates a PID file manager for the given data directory.
@@ -26,6 +59,104 @@ func (p *PIDFile) Path() string {
 	return p.path
 }
 
+// Lock takes the daemon lock and records info (PID, host, start time and
+// command are filled in when empty). It returns a *LockedError naming the
+// owner if another live process holds the lock.
+func (p *PIDFile) Lock(info LockInfo) error {
+	if p.f != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		return fmt.Errorf("create pid dir: %w", err)
+	}
+	fillLockInfo(&info)
+
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(p.path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return fmt.Errorf("open pid file: %w", err)
+		}
+		if err := lockFile(f); err != nil {
+			f.Close()
+			if errors.Is(err, errLockHeld) {
+				owner, _, _ := p.owner()
+				return &LockedError{Owner: owner}
+			}
+			return fmt.Errorf("lock pid file: %w", err)
+		}
+		// A previous owner may have removed the file between our open and
+		// lock; then we hold a lock nobody else can see. Start over.
+		if !sameFile(f, p.path) {
+			f.Close()
+			if attempt < 3 {
+				continue
+			}
+			return fmt.Errorf("lock pid file: %s keeps changing", p.path)
+		}
+		// The lock is ours, but a bare PID file may belong to a daemon that
+		// never took the lock.
+		if owner, legacy, err := p.owner(); err == nil && (legacy || !flockSupported) && owner.PID != 0 && processExists(owner.PID) {
+			f.Close()
+			return &LockedError{Owner: owner}
+		}
+		data, _ := json.Marshal(info)
+		if err := f.Truncate(0); err == nil {
+			_, err = f.WriteAt(append(data, '\n'), 0)
+		}
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("write pid file: %w", err)
+		}
+		p.f = f
+		return nil
+	}
+}
+
+// Unlock removes the PID file and releases the lock taken by Lock.
+func (p *PIDFile) Unlock() error {
+	if p.f == nil {
+		return nil
+	}
+	err := p.Remove()
+	p.f.Close()
+	p.f = nil
+	return err
+}
+
+// Owner returns the metadata of the current lock file. Files holding a
+// bare PID yield only the PID.
+func (p *PIDFile) Owner() (LockInfo, error) {
+	info, _, err := p.owner()
+	return info, err
+}
+
+// owner reads the lock file; legacy reports a bare-PID file. A missing
+// file yields a zero LockInfo.
+func (p *PIDFile) owner() (info LockInfo, legacy bool, err error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return LockInfo{}, false, nil
+		}
+		return LockInfo{}, false, fmt.Errorf("read pid file: %w", err)
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return LockInfo{}, false, nil
+	}
+	if strings.HasPrefix(text, "{") {
+		if err := json.Unmarshal([]byte(text), &info); err != nil {
+			return LockInfo{}, false, fmt.Errorf("invalid pid file: %w", err)
+		}
+		return info, false, nil
+	}
+	pid, err := strconv.Atoi(text)
+	if err != nil {
+		return LockInfo{}, false, fmt.Errorf("invalid pid: %w", err)
+	}
+	return LockInfo{PID: pid}, true, nil
+}
+
 // Write creates/overwrites the PID file with the current process ID.
 func (p *PIDFile) Write() error {
 	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
@@ -40,18 +171,8 @@ func (p *PIDFile) Write() error {
 
 // Read returns the PID stored in the PID file, or 0 if not found/invalid.
 func (p *PIDFile) Read() (int, error) {
-	data, err := os.ReadFile(p.path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return 0, nil
-		}
-		return 0, fmt.Errorf("read pid file: %w", err)
-	}
-	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
-	if err != nil {
-		return 0, fmt.Errorf("invalid pid: %w", err)
-	}
-	return pid, nil
+	info, err := p.Owner()
+	return info.PID, err
 }
 
 // Remove deletes the PID file.
@@ -65,25 +186,69 @@ func (p *PIDFile) Remove() error {
 // IsRunning checks if a daemon process is currently running.
 // Returns the PID if running, 0 if not.
 func (p *PIDFile) IsRunning() (int, bool) {
-	pid, err := p.Read()
-	if err != nil || pid == 0 {
+	info, legacy, err := p.owner()
+	if err != nil || info.PID == 0 {
 		return 0, false
 	}
-	if !processExists(pid) {
+	if p.f != nil {
+		return info.PID, true
+	}
+	var running bool
+	switch {
+	case legacy:
+		running = processExists(info.PID)
+	case info.Host != "" && info.Host != hostname():
+		// Locks are not reliable across machines sharing a data dir;
+		// trust the owner.
+		running = true
+	default:
+		running = lockHeld(p.path)
+	}
+	if !running {
 		// Stale PID file — process died without cleanup.
 		p.Remove()
 		return 0, false
 	}
-	return pid, true
+	return info.PID, true
 }
 
-// Guard ensures no other instance is running. Returns an error if one is.
+// Guard ensures no other instance is running and takes the lock. Returns
+// an error if another instance holds it.
 func (p *PIDFile) Guard() error {
-	pid, running := p.IsRunning()
-	if running {
-		return fmt.Errorf("daemon already running (pid=%d)", pid)
+	return p.Lock(LockInfo{})
+}
+
+func fillLockInfo(info *LockInfo) {
+	if info.PID == 0 {
+		info.PID = os.Getpid()
+	}
+	if info.Host == "" {
+		info.Host = hostname()
+	}
+	if info.StartedAt.IsZero() {
+		info.StartedAt = time.Now().UTC()
+	}
+	if info.Command == "" {
+		info.Command = strings.Join(os.Args, " ")
+	}
+}
+
+func hostname() string {
+	h, _ := os.Hostname()
+	return h
+}
+
+// sameFile reports whether f is still the file at path.
+func sameFile(f *os.File, path string) bool {
+	a, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	b, err := os.Stat(path)
+	if err != nil {
+		return false
 	}
-	return p.Write()
+	return os.SameFile(a, b)
 }
 
 // processExists checks if a process with the given PID is alive.
@@ -1,6 +1,8 @@
 package deploy
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -176,6 +178,75 @@ func TestPIDFile_Guard_AlreadyRunning(t *testing.T) {
 	}
 }
 
+func TestPIDFile_Lock_Metadata(t *testing.T) {
+	dir := t.TempDir()
+	pf := NewPIDFile(dir)
+
+	if err := pf.Lock(LockInfo{Version: "1.2.3"}); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	owner, err := pf.Owner()
+	if err != nil {
+		t.Fatalf("Owner: %v", err)
+	}
+	if owner.PID != os.Getpid() || owner.Version != "1.2.3" || owner.Host == "" || owner.StartedAt.IsZero() {
+		t.Fatalf("owner = %+v", owner)
+	}
+	if pid, running := pf.IsRunning(); !running || pid != os.Getpid() {
+		t.Fatalf("IsRunning = %d, %v", pid, running)
+	}
+
+	// A second handle (another instance) is refused and told who owns it.
+	other := NewPIDFile(dir)
+	err = other.Lock(LockInfo{})
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.Owner.Version != "1.2.3" {
+		t.Fatalf("second Lock = %v", err)
+	}
+	if _, running := other.IsRunning(); !running {
+		t.Fatal("IsRunning from another handle: expected true while locked")
+	}
+
+	if err := pf.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if _, err := os.Stat(pf.Path()); !os.IsNotExist(err) {
+		t.Fatal("lock file still exists after Unlock")
+	}
+	if err := other.Lock(LockInfo{}); err != nil {
+		t.Fatalf("Lock after Unlock: %v", err)
+	}
+	other.Unlock()
+}
+
+func TestPIDFile_Lock_StaleReusedPID(t *testing.T) {
+	if !flockSupported {
+		t.Skip("no flock on this platform")
+	}
+	dir := t.TempDir()
+	pf := NewPIDFile(dir)
+
+	// A crashed daemon's lock file naming a PID that is alive again (here:
+	// ours) but with nobody holding the lock is stale.
+	stale := fmt.Sprintf(`{"pid":%d,"host":%q,"started_at":"2020-01-01T00:00:00Z"}`, os.Getpid(), hostname())
+	if err := os.WriteFile(pf.Path(), []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, running := pf.IsRunning(); running {
+		t.Fatal("IsRunning: expected false for an unheld lock file")
+	}
+	if err := os.WriteFile(pf.Path(), []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := pf.Lock(LockInfo{}); err != nil {
+		t.Fatalf("Lock over stale file: %v", err)
+	}
+	defer pf.Unlock()
+	if owner, _ := pf.Owner(); owner.StartedAt.Year() == 2020 {
+		t.Error("stale metadata was not replaced")
+	}
+}
+
 func TestProcessExists_CurrentPID(t *testing.T) {
 	if !processExists(os.Getpid()) {
 		t.Fatal("processExists: expected true for current PID")
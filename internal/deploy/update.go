@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"time"
 )
 
@@ -227,6 +228,41 @@ func ListBackups(dataDir string) ([]string, error) {
 	return backups, nil
 }
 
+// PruneBackups deletes all but the keep most recent backups and returns
+// the names it deleted.
+func PruneBackups(dataDir string, keep int) ([]string, error) {
+	backupDir := filepath.Join(dataDir, "backups")
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	type backup struct {
+		name string
+		mod  time.Time
+	}
+	var backups []backup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if fi, err := e.Info(); err == nil {
+			backups = append(backups, backup{e.Name(), fi.ModTime()})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].mod.After(backups[j].mod) })
+	var removed []string
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(backupDir, backups[i].name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, backups[i].name)
+	}
+	return removed, nil
+}
+
 // --- helpers ---
 
 func downloadFile(dst, url string) error {
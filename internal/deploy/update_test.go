@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 )
 
 // githubRelease is a minimal GitHub release response for test mocking.
@@ -394,3 +395,63 @@ func TestSplitFields(t *testing.T) {
 		}
 	}
 }
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+	os.MkdirAll(backupDir, 0o755)
+	now := time.Now()
+	for i, v := range []string{"0.1.0", "0.2.0", "0.3.0", "0.4.0"} {
+		path := filepath.Join(backupDir, "overhuman-"+v)
+		os.WriteFile(path, []byte(v), 0o755)
+		mod := now.Add(time.Duration(i-4) * time.Hour)
+		os.Chtimes(path, mod, mod)
+	}
+
+	removed, err := PruneBackups(dir, 2)
+	if err != nil {
+		t.Fatalf("PruneBackups: %v", err)
+	}
+	if len(removed) != 2 || removed[0] != "overhuman-0.2.0" || removed[1] != "overhuman-0.1.0" {
+		t.Errorf("removed = %v, want the two oldest", removed)
+	}
+	if left, _ := ListBackups(dir); len(left) != 2 {
+		t.Errorf("left = %v", left)
+	}
+	if removed, err := PruneBackups(t.TempDir(), 2); err != nil || removed != nil {
+		t.Errorf("no backups dir: %v, %v", removed, err)
+	}
+}
+
+func TestLogFile_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overhuman.log")
+	lf, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatalf("OpenLogFile: %v", err)
+	}
+	defer lf.Close()
+
+	rotate := func(gen string) {
+		t.Helper()
+		fmt.Fprint(lf, gen)
+		if ok, err := lf.Rotate(1, 2); !ok || err != nil {
+			t.Fatalf("Rotate = %v, %v", ok, err)
+		}
+	}
+	if ok, _ := lf.Rotate(1, 2); ok {
+		t.Error("an empty log should not rotate")
+	}
+	rotate("first")
+	rotate("second")
+	rotate("third")
+	fmt.Fprint(lf, "current")
+
+	for name, want := range map[string]string{"": "current", ".1": "third", ".2": "second"} {
+		if b, _ := os.ReadFile(path + name); string(b) != want {
+			t.Errorf("%s%s = %q, want %q", filepath.Base(path), name, b, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("only 2 rotated logs should be kept")
+	}
+}
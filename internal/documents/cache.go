@@ -0,0 +1,79 @@
+package documents
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long a summary stays in the persistent cache.
+const cacheTTL = 90 * 24 * time.Hour
+
+// cache maps a content hash to its summary: of a chunk, or of a whole
+// document. It is kept either in memory or in the database.
+type cache struct {
+	mu  sync.Mutex
+	mem map[string]string
+	db  *sql.DB
+}
+
+func newCache() *cache {
+	return &cache{mem: make(map[string]string)}
+}
+
+func (c *cache) get(hash string) (string, bool) {
+	if c.db == nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		s, ok := c.mem[hash]
+		return s, ok
+	}
+	var s string
+	if err := c.db.QueryRow(`SELECT summary FROM document_summaries WHERE hash = ?`, hash).Scan(&s); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("[documents] read cache: %v", err)
+		}
+		return "", false
+	}
+	return s, true
+}
+
+func (c *cache) put(hash, summary string) {
+	if c.db == nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.mem[hash] = summary
+		return
+	}
+	_, err := c.db.Exec(`
+	INSERT INTO document_summaries (hash, summary, created_at) VALUES (?, ?, ?)
+	ON CONFLICT(hash) DO UPDATE SET summary = excluded.summary, created_at = excluded.created_at`,
+		hash, summary, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		log.Printf("[documents] write cache: %v", err)
+	}
+}
+
+// Open creates a summarizer like New whose summaries are kept in db, in the
+// document_summaries table, so they survive a restart. Summaries older
+// than 90 days are dropped.
+func Open(db *sql.DB, cfg Config) (*Summarizer, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS document_summaries (
+		hash       TEXT PRIMARY KEY,
+		summary    TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("documents: create table: %w", err)
+	}
+	cutoff := time.Now().Add(-cacheTTL).UTC().Format(time.RFC3339)
+	if _, err := db.Exec(`DELETE FROM document_summaries WHERE created_at < ?`, cutoff); err != nil {
+		return nil, fmt.Errorf("documents: prune cache: %w", err)
+	}
+	s := New(cfg)
+	s.cache.db = db
+	return s, nil
+}
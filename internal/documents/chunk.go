@@ -0,0 +1,59 @@
+package documents
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk splits text into pieces of at most size bytes, sharing overlap
+// bytes with the piece before. Pieces end at a paragraph, line or sentence
+// break, or a space, where one falls in the second half of the piece, and
+// never inside a rune.
+func Chunk(text string, size, overlap int) []string {
+	text = strings.TrimSpace(text)
+	if size <= 0 || len(text) <= size {
+		if text == "" {
+			return nil
+		}
+		return []string{text}
+	}
+	overlap = max(0, min(overlap, size/2))
+
+	var chunks []string
+	for start := 0; start < len(text); {
+		end := start + size
+		if end >= len(text) {
+			chunks = append(chunks, strings.TrimSpace(text[start:]))
+			break
+		}
+		end = breakBefore(text, start, end)
+		chunks = append(chunks, strings.TrimSpace(text[start:end]))
+
+		next := end - overlap
+		for next > start && next < end && !utf8.RuneStart(text[next]) {
+			next--
+		}
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+	return chunks
+}
+
+// breakBefore returns where to cut text[start:end]: after the last
+// paragraph, line or sentence break, or space in its second half, or at
+// end adjusted to a rune boundary.
+func breakBefore(text string, start, end int) int {
+	window := text[start:end]
+	half := len(window) / 2
+	for _, sep := range []string{"\n\n", "\n", ". ", "? ", "! ", " "} {
+		if i := strings.LastIndex(window, sep); i >= half {
+			return start + i + len(sep)
+		}
+	}
+	for end > start+1 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return end
+}
@@ -0,0 +1,230 @@
+// Package documents condenses documents too large for the context window:
+// the text is split into chunks, the chunks are summarized in parallel
+// (map), and their summaries are combined into one (reduce). Summaries are
+// cached by content hash, so a document dropped in again costs nothing and
+// an edited one only pays for the chunks that changed.
+package documents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// Config configures a Summarizer.
+type Config struct {
+	// LLM summarizes the chunks; Router picks a cheap model for it.
+	LLM    brain.LLMProvider
+	Router *brain.ModelRouter
+
+	// BudgetRemaining, if set, reports the USD left to spend, for the
+	// router's downgrade rules. Default: unconstrained.
+	BudgetRemaining func() float64
+
+	// Threshold is the text length, in characters, from which a document
+	// is condensed. Default: 24000 (about 6k tokens).
+	Threshold int
+
+	// ChunkChars is the size of a chunk. Default: 12000.
+	ChunkChars int
+
+	// Overlap is how much text, in characters, consecutive chunks share,
+	// so a passage cut at a chunk border is seen whole. Default: 400.
+	Overlap int
+
+	// Parallel is how many chunks are summarized at once. Default: 4.
+	Parallel int
+
+	// MaxChunks caps the chunks summarized; text beyond them is left out
+	// and the summary says so. Default: 100.
+	MaxChunks int
+}
+
+func (c Config) withDefaults() Config {
+	if c.Threshold <= 0 {
+		c.Threshold = 24000
+	}
+	if c.ChunkChars <= 0 {
+		c.ChunkChars = 12000
+	}
+	if c.Overlap < 0 || c.Overlap >= c.ChunkChars/2 {
+		c.Overlap = 0
+	} else if c.Overlap == 0 {
+		c.Overlap = min(400, c.ChunkChars/10)
+	}
+	if c.Parallel <= 0 {
+		c.Parallel = 4
+	}
+	if c.MaxChunks <= 0 {
+		c.MaxChunks = 100
+	}
+	return c
+}
+
+// Summary is a condensed document.
+type Summary struct {
+	Text      string  `json:"text"`
+	Hash      string  `json:"hash"`      // hex SHA-256 of the document text
+	Chunks    int     `json:"chunks"`    // chunks the document was split into
+	Cached    int     `json:"cached"`    // summaries taken from the cache, the final one included
+	Truncated bool    `json:"truncated"` // text beyond MaxChunks was left out
+	CostUSD   float64 `json:"cost_usd"`  // spent on this call
+}
+
+// Summarizer condenses large documents with map-reduce summarization.
+type Summarizer struct {
+	cfg   Config
+	cache *cache
+}
+
+// New creates a summarizer whose cache lives in memory.
+func New(cfg Config) *Summarizer {
+	return &Summarizer{cfg: cfg.withDefaults(), cache: newCache()}
+}
+
+// Needs reports whether text is large enough to be condensed.
+func (s *Summarizer) Needs(text string) bool {
+	return len(text) >= s.cfg.Threshold
+}
+
+// Summarize condenses the document text, named name in the prompts. It is
+// safe for concurrent use.
+func (s *Summarizer) Summarize(ctx context.Context, name, text string) (*Summary, error) {
+	sum := &Summary{Hash: hashText(text)}
+	if cached, ok := s.cache.get(sum.Hash); ok {
+		sum.Text, sum.Cached = cached, 1
+		return sum, nil
+	}
+
+	chunks := Chunk(text, s.cfg.ChunkChars, s.cfg.Overlap)
+	if len(chunks) > s.cfg.MaxChunks {
+		chunks, sum.Truncated = chunks[:s.cfg.MaxChunks], true
+	}
+	sum.Chunks = len(chunks)
+
+	parts, err := s.mapChunks(ctx, name, chunks, sum)
+	if err != nil {
+		return sum, err
+	}
+	// Reduce, in rounds while the summaries don't fit one request.
+	for len(parts) > 1 {
+		groups := Chunk(strings.Join(parts, "\n\n"), s.cfg.ChunkChars, 0)
+		if len(groups) == 1 {
+			break
+		}
+		if len(groups) >= len(parts) {
+			break // summaries as long as a chunk each; combine what we have
+		}
+		if parts, err = s.mapChunks(ctx, name, groups, sum); err != nil {
+			return sum, err
+		}
+	}
+	if len(parts) == 1 && !sum.Truncated {
+		sum.Text = parts[0]
+	} else if sum.Text, err = s.complete(ctx, reducePrompt(name, parts, sum.Truncated), sum); err != nil {
+		return sum, fmt.Errorf("documents: combine summaries of %s: %w", name, err)
+	}
+	s.cache.put(sum.Hash, sum.Text)
+	return sum, nil
+}
+
+// mapChunks summarizes chunks, Parallel at a time, in order. Cached
+// summaries are reused.
+func (s *Summarizer) mapChunks(ctx context.Context, name string, chunks []string, sum *Summary) ([]string, error) {
+	out := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	var mu sync.Mutex // guards sum
+	sem := make(chan struct{}, s.cfg.Parallel)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		key := hashText(chunk)
+		if cached, ok := s.cache.get(key); ok {
+			out[i] = cached
+			sum.Cached++
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			var part Summary
+			out[i], errs[i] = s.complete(ctx, mapPrompt(name, i+1, len(chunks), chunk), &part)
+			mu.Lock()
+			sum.CostUSD += part.CostUSD
+			mu.Unlock()
+			if errs[i] == nil {
+				s.cache.put(key, out[i])
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("documents: summarize part %d of %s: %w", i+1, name, err)
+		}
+	}
+	return out, nil
+}
+
+// complete runs one summarization request and adds its cost to sum.
+func (s *Summarizer) complete(ctx context.Context, prompt string, sum *Summary) (string, error) {
+	remaining := 100.0
+	if s.cfg.BudgetRemaining != nil {
+		remaining = s.cfg.BudgetRemaining()
+	}
+	model := ""
+	if s.cfg.Router != nil {
+		model = s.cfg.Router.Select("simple", remaining)
+	}
+	resp, err := s.cfg.LLM.Complete(ctx, brain.LLMRequest{
+		Messages: []brain.Message{
+			{Role: "system", Content: "You summarize documents faithfully. Keep facts, figures, names, dates, decisions and open questions; leave out filler. Never add information that is not in the text."},
+			{Role: "user", Content: prompt},
+		},
+		Model:     model,
+		MaxTokens: 1024,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum.CostUSD += resp.CostUSD
+	text := strings.TrimSpace(resp.Content)
+	if text == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return text, nil
+}
+
+func mapPrompt(name string, part, parts int, chunk string) string {
+	return fmt.Sprintf("Summarize part %d of %d of the document %q in at most 200 words.\n\n---\n%s\n---", part, parts, name, chunk)
+}
+
+func reducePrompt(name string, parts []string, truncated bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "These are summaries of consecutive parts of the document %q. Combine them into one structured summary of the whole document, at most 500 words", name)
+	if truncated {
+		b.WriteString(", and say that the end of the document was not read")
+	}
+	b.WriteString(".\n")
+	for i, p := range parts {
+		fmt.Fprintf(&b, "\n--- Part %d ---\n%s\n", i+1, p)
+	}
+	return b.String()
+}
+
+// hashText returns the hex SHA-256 of text.
+func hashText(text string) string {
+	h := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,163 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/overhuman/overhuman/internal/brain"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestChunk(t *testing.T) {
+	if got := Chunk("  short  ", 100, 10); len(got) != 1 || got[0] != "short" {
+		t.Errorf("short text = %q", got)
+	}
+	if got := Chunk(" ", 100, 10); got != nil {
+		t.Errorf("blank text = %q", got)
+	}
+
+	var b strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&b, "Sentence number %d is here. ", i)
+		if i%10 == 9 {
+			b.WriteString("\n\n")
+		}
+	}
+	text := b.String()
+	chunks := Chunk(text, 1000, 100)
+	if len(chunks) < 6 {
+		t.Fatalf("%d chunks for %d bytes", len(chunks), len(text))
+	}
+	for i, c := range chunks {
+		if len(c) > 1000 {
+			t.Errorf("chunk %d is %d bytes", i, len(c))
+		}
+		if i < len(chunks)-1 && !strings.HasSuffix(c, ".") {
+			t.Errorf("chunk %d ends mid-sentence: %q", i, c[len(c)-20:])
+		}
+	}
+	if !strings.Contains(chunks[0], "Sentence number 0 ") || !strings.Contains(chunks[len(chunks)-1], "Sentence number 199 ") {
+		t.Error("chunks lose the start or end of the text")
+	}
+	// Consecutive chunks overlap.
+	if tail := chunks[0][len(chunks[0])-40:]; !strings.Contains(chunks[1], tail) {
+		t.Errorf("chunk 1 does not repeat the end of chunk 0 (%q)", tail)
+	}
+
+	for _, c := range Chunk(strings.Repeat("ü", 1000), 99, 10) {
+		if !utf8.ValidString(c) {
+			t.Fatalf("chunk splits a rune: %q", c)
+		}
+	}
+}
+
+// document returns a text of about n bytes in paragraphs.
+func document(n int, word string) string {
+	var b strings.Builder
+	for i := 0; b.Len() < n; i++ {
+		fmt.Fprintf(&b, "Paragraph %d talks about %s at some length. ", i, word)
+		if i%5 == 4 {
+			b.WriteString("\n\n")
+		}
+	}
+	return b.String()
+}
+
+func fakeLLM() *brain.FakeProvider {
+	return brain.NewFakeProvider(brain.FakeConfig{
+		Rules:   []brain.FakeRule{{Match: "Combine them", Response: "The whole document."}},
+		Default: "One part.",
+	})
+}
+
+func TestSummarizer_MapReduce(t *testing.T) {
+	llm := fakeLLM()
+	s := New(Config{LLM: llm, ChunkChars: 2000, Threshold: 5000, Parallel: 2})
+	text := document(9000, "apples")
+	if !s.Needs(text) || s.Needs("short") {
+		t.Error("Needs is wrong")
+	}
+
+	sum, err := s.Summarize(context.Background(), "report.pdf", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Text != "The whole document." || sum.Chunks < 5 || sum.Cached != 0 || sum.Truncated {
+		t.Errorf("summary = %+v", sum)
+	}
+	if calls := llm.Calls(); calls != sum.Chunks+1 {
+		t.Errorf("calls = %d, want %d chunks + 1 reduce", calls, sum.Chunks)
+	}
+
+	// The same document again comes from the cache.
+	before := llm.Calls()
+	again, err := s.Summarize(context.Background(), "copy.pdf", text)
+	if err != nil || again.Text != sum.Text || again.Cached != 1 || llm.Calls() != before {
+		t.Errorf("repeat = %+v, %v (%d new calls)", again, err, llm.Calls()-before)
+	}
+
+	// An edited document only pays for the chunks that changed.
+	before = llm.Calls()
+	edited, err := s.Summarize(context.Background(), "report.pdf", text+"\n\nOne more paragraph about pears.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Cached < edited.Chunks-2 || llm.Calls()-before > 3 {
+		t.Errorf("edited = %+v, %d new calls", edited, llm.Calls()-before)
+	}
+}
+
+func TestSummarizer_Truncates(t *testing.T) {
+	llm := fakeLLM()
+	s := New(Config{LLM: llm, ChunkChars: 1000, MaxChunks: 3})
+	sum, err := s.Summarize(context.Background(), "big.txt", document(10000, "rivers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sum.Truncated || sum.Chunks != 3 || llm.Calls() != 4 {
+		t.Errorf("summary = %+v after %d calls", sum, llm.Calls())
+	}
+}
+
+func TestSummarizer_Error(t *testing.T) {
+	llm := brain.NewFakeProvider(brain.FakeConfig{ErrorRate: 1})
+	s := New(Config{LLM: llm, ChunkChars: 1000})
+	if _, err := s.Summarize(context.Background(), "doc.txt", document(5000, "clouds")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestOpen_PersistsCache(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "docs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	text := document(6000, "mountains")
+
+	llm := fakeLLM()
+	s, err := Open(db, Config{LLM: llm, ChunkChars: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Summarize(context.Background(), "doc.txt", text); err != nil {
+		t.Fatal(err)
+	}
+
+	// After a restart the summary is still there.
+	llm = fakeLLM()
+	s, err = Open(db, Config{LLM: llm, ChunkChars: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := s.Summarize(context.Background(), "doc.txt", text)
+	if err != nil || sum.Cached != 1 || llm.Calls() != 0 {
+		t.Errorf("reopened: %+v, %v, %d calls", sum, err, llm.Calls())
+	}
+}
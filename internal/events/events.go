@@ -0,0 +1,124 @@
+// Package events is the agent's in-process publish/subscribe bus. The
+// pipeline, the budget tracker, the dispatcher and the automation engine
+// publish what happens — tasks starting and finishing, patterns becoming
+// automatable, budget thresholds, skill promotions, inputs arriving — and
+// webhooks, metrics, the kiosk and anything added later subscribe, without
+// the publishers knowing about them.
+//
+// Delivery is synchronous, on the publisher's goroutine, in subscription
+// order: handlers must be quick and hand slow work (HTTP, disk) off to their
+// own queue. A panicking handler is logged and skipped.
+package events
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Topics published by the agent. Data is the payload type noted for each.
+const (
+	TaskStarted      = "task.started"      // pipeline.TaskEvent
+	TaskCompleted    = "task.completed"    // pipeline.TaskEvent
+	TaskFailed       = "task.failed"       // pipeline.TaskEvent
+	PatternTriggered = "pattern.triggered" // pipeline.PatternEvent
+	SkillCreated     = "skill.created"     // pipeline.SkillEvent
+	SkillPromoted    = "skill.promoted"    // pipeline.SkillEvent
+	SkillDeprecated  = "skill.deprecated"  // pipeline.SkillEvent
+	BudgetThreshold  = "budget.threshold"  // budget.ThresholdEvent
+	SenseInput       = "sense.input"       // *senses.UnifiedInput, when queued
+	AutomationFired  = "automation.fired"  // automation.Event
+	ProviderDegraded = "provider.degraded" // brain.FallbackStatus
+	ProviderRestored = "provider.restored" // brain.FallbackStatus
+)
+
+// Event is one published occurrence.
+type Event struct {
+	Topic string
+	Time  time.Time
+	Data  any
+}
+
+// Handler receives the events of a subscription.
+type Handler func(Event)
+
+type subscription struct {
+	id      int
+	pattern string
+	handle  Handler
+}
+
+// Bus delivers published events to the matching subscribers. A nil *Bus
+// drops everything, so publishers need not check for one.
+type Bus struct {
+	mu   sync.RWMutex
+	seq  int
+	subs []subscription
+}
+
+// New creates an empty bus.
+func New() *Bus {
+	return &Bus{}
+}
+
+// Subscribe calls h for every event whose topic matches pattern: a topic
+// ("task.completed"), a prefix ending in ".*" ("task.*") or "*" for all.
+// The returned func cancels the subscription.
+func (b *Bus) Subscribe(pattern string, h Handler) (cancel func()) {
+	if b == nil {
+		return func() {}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	id := b.seq
+	b.subs = append(b.subs, subscription{id: id, pattern: pattern, handle: h})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers data under topic to the subscribers and returns once
+// they have all run.
+func (b *Bus) Publish(topic string, data any) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+	ev := Event{Topic: topic, Time: time.Now(), Data: data}
+	for _, s := range subs {
+		if Match(s.pattern, topic) {
+			deliver(s.handle, ev)
+		}
+	}
+}
+
+func deliver(h Handler, ev Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[events] %s handler panicked: %v", ev.Topic, r)
+		}
+	}()
+	h(ev)
+}
+
+// Match reports whether topic matches a subscription pattern.
+func Match(pattern, topic string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, ".*"):
+		return strings.HasPrefix(topic, pattern[:len(pattern)-1])
+	}
+	return pattern == topic
+}
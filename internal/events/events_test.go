@@ -0,0 +1,50 @@
+package events
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	bus := New()
+	var got []string
+	record := func(name string) Handler {
+		return func(ev Event) { got = append(got, name+":"+ev.Topic) }
+	}
+	bus.Subscribe("*", record("all"))
+	cancel := bus.Subscribe("task.*", record("tasks"))
+	bus.Subscribe(TaskFailed, func(Event) { panic("boom") })
+	bus.Subscribe(TaskFailed, record("failed"))
+
+	bus.Publish(TaskStarted, nil)
+	bus.Publish(TaskFailed, nil)
+	bus.Publish(BudgetThreshold, nil)
+	cancel()
+	bus.Publish(TaskCompleted, nil)
+
+	want := "all:task.started tasks:task.started all:task.failed tasks:task.failed failed:task.failed all:budget.threshold all:task.completed"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("deliveries:\n got %s\nwant %s", s, want)
+	}
+
+	var off *Bus
+	off.Subscribe("*", record("nil"))()
+	off.Publish(TaskStarted, nil)
+}
+
+func TestMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"*", "sense.input", true},
+		{"task.*", "task.completed", true},
+		{"task.*", "tasks.x", false},
+		{"task.completed", "task.completed", true},
+		{"task.completed", "task.failed", false},
+	} {
+		if got := Match(tc.pattern, tc.topic); got != tc.want {
+			t.Errorf("Match(%q, %q) = %v", tc.pattern, tc.topic, got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package genui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// ActionRequest is an action a client triggered in a generated UI.
+type ActionRequest struct {
+	ConnID    string              // WebSocket connection the action came from
+	ActionID  string              // callback ID, e.g. "view_logs" or "skill:sk_123"
+	Name      string              // ActionID up to the first ':'
+	Arg       string              // ActionID after the first ':', if any
+	Data      json.RawMessage     // the action's data-payload, if any
+	Principal *security.Principal // who sent it; nil without access control
+}
+
+// Field returns the string field key of the action's data, or "".
+func (r ActionRequest) Field(key string) string {
+	var fields map[string]any
+	if json.Unmarshal(r.Data, &fields) != nil {
+		return ""
+	}
+	switch v := fields[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ActionHandler runs an action and returns a short result for the UI that
+// triggered it.
+type ActionHandler func(ctx context.Context, req ActionRequest) (string, error)
+
+// ActionRouter maps the callback IDs of generated UIs to handlers. A
+// callback "name:arg" is routed to the handler of name with Arg set, so
+// "skill:sk_123" and "rollback:ch_9" need one handler each.
+type ActionRouter struct {
+	mu       sync.RWMutex
+	handlers map[string]ActionHandler
+	fallback ActionHandler
+}
+
+// NewActionRouter creates a router without handlers.
+func NewActionRouter() *ActionRouter {
+	return &ActionRouter{handlers: make(map[string]ActionHandler)}
+}
+
+// Handle registers fn for the actions named name.
+func (r *ActionRouter) Handle(name string, fn ActionHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[name] = fn
+}
+
+// HandleDefault registers fn for actions no handler is registered for,
+// e.g. the custom callbacks a generated UI makes up.
+func (r *ActionRouter) HandleDefault(fn ActionHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = fn
+}
+
+// Dispatch runs the handler for req.ActionID and returns the payload of
+// the action_result to send back. Name and Arg are filled in from
+// ActionID.
+func (r *ActionRouter) Dispatch(ctx context.Context, req ActionRequest) WSActionResultPayload {
+	res := WSActionResultPayload{ActionID: req.ActionID}
+	req.Name, req.Arg, _ = strings.Cut(req.ActionID, ":")
+	if req.Name == "" {
+		res.Error = "action id required"
+		return res
+	}
+
+	r.mu.RLock()
+	fn, ok := r.handlers[req.Name]
+	if !ok {
+		fn = r.fallback
+	}
+	r.mu.RUnlock()
+	if fn == nil {
+		res.Error = fmt.Sprintf("unknown action %q", req.ActionID)
+		return res
+	}
+
+	out, err := fn(ctx, req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Success, res.Result = true, out
+	return res
+}
@@ -5,15 +5,15 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"sync"
+
+	"github.com/overhuman/overhuman/internal/security"
 )
 
 // UIAPIHandler provides REST API endpoints for UI generation.
 type UIAPIHandler struct {
 	generator *UIGenerator
 	wsServer  *WSServer
-	mu        sync.RWMutex
-	cache     map[string]*GeneratedUI // keyed by task_id
+	store     UIStore // history of generated UIs
 }
 
 // NewUIAPIHandler creates a new UI API handler.
@@ -21,15 +21,23 @@ func NewUIAPIHandler(gen *UIGenerator, ws *WSServer) *UIAPIHandler {
 	return &UIAPIHandler{
 		generator: gen,
 		wsServer:  ws,
-		cache:     make(map[string]*GeneratedUI),
+		store:     NewMemoryUIStore(UIRetention{}),
 	}
 }
 
+// SetStore replaces the in-process UI history with s, e.g. a SQLUIStore.
+func (h *UIAPIHandler) SetStore(s UIStore) {
+	h.store = s
+}
+
 // RegisterRoutes registers UI API routes on the given ServeMux.
-// Routes: POST /api/ui/generate, GET /api/ui/{task_id}, GET /api/ui/ws/status
+// Routes: POST /api/ui/generate, GET /api/ui/last, GET /api/ui/{task_id},
+// GET /api/ui/ws/status. In team mode a member reads only the UIs of
+// their own tasks.
 func (h *UIAPIHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/ui/generate", h.handleGenerate)
 	mux.HandleFunc("GET /api/ui/last", h.handleGetLast)
+	mux.HandleFunc("GET /api/ui/{task_id}", h.handleGetUI)
 	mux.HandleFunc("GET /api/ui/ws/status", h.handleWSStatus)
 }
 
@@ -81,10 +89,11 @@ func (h *UIAPIHandler) handleGenerate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Cache the generated UI.
-	h.mu.Lock()
-	h.cache[ui.TaskID] = ui
-	h.mu.Unlock()
+	sender := ""
+	if p, ok := security.PrincipalFrom(r.Context()); ok {
+		sender = p.Name
+	}
+	h.RecordUI(ui, caps, "api", sender)
 
 	// Also broadcast via WebSocket if connected clients exist.
 	if h.wsServer != nil && h.wsServer.ClientCount() > 0 {
@@ -97,22 +106,52 @@ func (h *UIAPIHandler) handleGenerate(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(apiGenerateResponse{UI: ui})
 }
 
-// handleGetLast handles GET /api/ui/last — returns last generated UI.
+// handleGetLast handles GET /api/ui/last — returns the last UI generated
+// for one of the caller's tasks.
 func (h *UIAPIHandler) handleGetLast(w http.ResponseWriter, r *http.Request) {
-	h.mu.RLock()
-	var last *GeneratedUI
-	for _, ui := range h.cache {
-		last = ui // Return any cached UI (last written wins due to map iteration)
+	limit := 1
+	if _, ok := security.PrincipalFrom(r.Context()); ok {
+		limit = DefaultUIHistoryEntries
+	}
+	recent, err := h.store.Recent(limit)
+	if err != nil {
+		log.Printf("[ui-api] %v", err)
+		writeJSONError(w, "ui history unavailable", http.StatusInternalServerError)
+		return
+	}
+	for _, rec := range recent {
+		if ownsUI(r, rec) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(apiGenerateResponse{UI: &rec.UI})
+			return
+		}
 	}
-	h.mu.RUnlock()
+	writeJSONError(w, "no UI available", http.StatusNotFound)
+}
 
-	if last == nil {
-		writeJSONError(w, "no UI available", http.StatusNotFound)
+// handleGetUI handles GET /api/ui/{task_id}: the last UI generated for a
+// task, exactly as it was shown, with the device and channel it went to.
+func (h *UIAPIHandler) handleGetUI(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.store.Get(r.PathValue("task_id"))
+	if err != nil {
+		log.Printf("[ui-api] %v", err)
+		writeJSONError(w, "ui history unavailable", http.StatusInternalServerError)
+		return
+	}
+	if rec == nil || !ownsUI(r, *rec) {
+		writeJSONError(w, "no UI for this task", http.StatusNotFound)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(apiGenerateResponse{UI: last})
+	json.NewEncoder(w).Encode(rec)
+}
+
+// ownsUI reports whether the caller may read rec: admins and the
+// single-user daemon see every UI, team members those of their own tasks.
+func ownsUI(r *http.Request, rec UIRecord) bool {
+	p, ok := security.PrincipalFrom(r.Context())
+	return !ok || p.Role.Allows(security.RoleAdmin) || rec.Sender == p.Name
 }
 
 // handleWSStatus handles GET /api/ui/ws/status.
@@ -125,7 +164,7 @@ func (h *UIAPIHandler) handleWSStatus(w http.ResponseWriter, r *http.Request) {
 		status.Clients = h.wsServer.ClientCount()
 		status.Addr = h.wsServer.Addr()
 		h.wsServer.mu.RLock()
-		status.HasCachedUI = h.wsServer.lastUI != nil
+		status.HasCachedUI = h.wsServer.lastUI[""] != nil
 		h.wsServer.mu.RUnlock()
 	}
 
@@ -133,14 +172,20 @@ func (h *UIAPIHandler) handleWSStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
-// CacheUI stores a GeneratedUI in the API cache.
+// CacheUI stores a GeneratedUI in the UI history.
 func (h *UIAPIHandler) CacheUI(ui *GeneratedUI) {
+	h.RecordUI(ui, DeviceCapabilities{}, "", "")
+}
+
+// RecordUI stores a GeneratedUI in the UI history with the device it was
+// generated for, the channel it was sent to and the sender of the task.
+func (h *UIAPIHandler) RecordUI(ui *GeneratedUI, caps DeviceCapabilities, channel, sender string) {
 	if ui == nil {
 		return
 	}
-	h.mu.Lock()
-	h.cache[ui.TaskID] = ui
-	h.mu.Unlock()
+	if err := h.store.Save(UIRecord{UI: *ui, Caps: caps, Channel: channel, Sender: sender}); err != nil {
+		log.Printf("[ui-api] %v", err)
+	}
 }
 
 // writeJSONError writes a JSON error response.
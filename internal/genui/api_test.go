@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/security"
 )
 
 func TestUIAPIHandler_Generate(t *testing.T) {
@@ -231,3 +232,62 @@ func TestUIAPIHandler_Generate_WithCustomCaps(t *testing.T) {
 		t.Errorf("Format = %q, want ansi (from custom caps)", resp.UI.Format)
 	}
 }
+
+func TestUIAPIHandler_GetByTask(t *testing.T) {
+	handler := NewUIAPIHandler(NewUIGenerator(nil, brain.NewModelRouter()), nil)
+	handler.RecordUI(&GeneratedUI{TaskID: "t1", Format: FormatHTML, Code: "<p>first</p>"}, WebCapabilities(390, 844), "ws", "ann")
+	handler.RecordUI(&GeneratedUI{TaskID: "t2", Format: FormatHTML, Code: "<p>other</p>"}, WebCapabilities(1280, 800), "ws", "bob")
+	handler.RecordUI(&GeneratedUI{TaskID: "t1", Format: FormatHTML, Code: "<p>again</p>"}, WebCapabilities(390, 844), "ws", "ann")
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/ui/t1", nil))
+	if rr.Code != 200 {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+	var rec UIRecord
+	json.NewDecoder(rr.Body).Decode(&rec)
+	if rec.UI.Code != "<p>again</p>" || rec.Caps.Width != 390 || rec.Channel != "ws" || rec.Created.IsZero() {
+		t.Errorf("record = %+v", rec)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/ui/missing", nil))
+	if rr.Code != 404 {
+		t.Errorf("unknown task: status = %d, want 404", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/ui/last", nil))
+	var last apiGenerateResponse
+	json.NewDecoder(rr.Body).Decode(&last)
+	if last.UI == nil || last.UI.Code != "<p>again</p>" {
+		t.Errorf("last = %+v", last.UI)
+	}
+
+	// In team mode members read the UIs of their own tasks only.
+	as := func(name string, role security.Role, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req = req.WithContext(security.WithPrincipal(req.Context(), security.Principal{Name: name, Role: role}))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+	if rr := as("bob", security.RoleMember, "/api/ui/t1"); rr.Code != 404 {
+		t.Errorf("bob reading ann's UI: status = %d, want 404", rr.Code)
+	}
+	if rr := as("ann", security.RoleMember, "/api/ui/t1"); rr.Code != 200 {
+		t.Errorf("ann reading her UI: status = %d", rr.Code)
+	}
+	if rr := as("root", security.RoleAdmin, "/api/ui/t2"); rr.Code != 200 {
+		t.Errorf("admin reading bob's UI: status = %d", rr.Code)
+	}
+	json.NewDecoder(as("bob", security.RoleMember, "/api/ui/last").Body).Decode(&last)
+	if last.UI == nil || last.UI.Code != "<p>other</p>" {
+		t.Errorf("bob's last = %+v", last.UI)
+	}
+	if rr := as("cy", security.RoleMember, "/api/ui/last"); rr.Code != 404 {
+		t.Errorf("last UI for a member without tasks: status = %d, want 404", rr.Code)
+	}
+}
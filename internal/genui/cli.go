@@ -41,6 +41,13 @@ func (r *CLIRenderer) renderANSI(ui *GeneratedUI) error {
 		return err
 	}
 
+	if footer := ui.Meta.Footer(); footer != "" {
+		fmt.Fprintf(r.out, "\n\033[90m%s\033[0m", footer)
+	}
+	if sources := ui.Meta.BasedOn(); len(sources) > 0 {
+		fmt.Fprintf(r.out, "\n\033[90mBased on:\n  %s\033[0m", SanitizeANSI(strings.Join(sources, "\n  ")))
+	}
+
 	// Progressive disclosure: if summary exists, add expand hint
 	if ui.Meta.Summary != "" {
 		fmt.Fprintf(r.out, "\n\033[90m[d] Details  [t] Thought log\033[0m\n")
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/pipeline"
 )
 
 func TestCLI_RenderANSI(t *testing.T) {
@@ -177,6 +180,32 @@ func TestCLI_RenderWithSummary(t *testing.T) {
 	}
 }
 
+func TestCLI_RenderBasedOn(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewCLIRenderer(&buf, nil)
+
+	ui := &GeneratedUI{
+		TaskID: "test-sources",
+		Format: FormatANSI,
+		Code:   "Meet Anna at 9.",
+		Meta: UIMeta{Sources: []pipeline.MemorySource{{
+			ID: "ltm_3", Date: time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC),
+			Channel: "CLI", Summary: "Anna \033[2Jprefers mornings",
+		}}},
+	}
+	if err := r.Render(ui); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Based on:") || !strings.Contains(output, "2026-02-10 · cli · Anna") || !strings.Contains(output, "(ltm_3)") {
+		t.Errorf("expected based-on section, got %q", output)
+	}
+	if strings.Contains(output, "\033[2J") {
+		t.Errorf("cursor escapes in memory summaries should be stripped, got %q", output)
+	}
+}
+
 func TestCLI_RenderNilUI(t *testing.T) {
 	var buf bytes.Buffer
 	r := NewCLIRenderer(&buf, nil)
@@ -0,0 +1,266 @@
+package genui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// Command groups shown in the kiosk command palette.
+const (
+	CommandGroupRecent   = "recent"
+	CommandGroupTemplate = "template"
+	CommandGroupSkill    = "skill"
+	CommandGroupAdmin    = "admin"
+)
+
+// Command is a single entry in the kiosk command palette.
+// Exactly one of Input (text submitted as a new task) or Action (an admin
+// action run via POST /api/commands/run) is set.
+type Command struct {
+	ID     string   `json:"id"`
+	Group  string   `json:"group"`
+	Label  string   `json:"label"`
+	Hint   string   `json:"hint,omitempty"`
+	Input  string   `json:"input,omitempty"`
+	Action string   `json:"action,omitempty"`
+	Args   []string `json:"args,omitempty"` // argument names the action prompts for
+
+	// owner is the sender of a recent task; behind the team access control
+	// only they and admins are shown it. Empty for shared entries.
+	owner string
+}
+
+// CommandSource lists commands for one group (recent tasks, skills, ...).
+type CommandSource func() []Command
+
+// CommandAction executes an admin action and returns a short result message.
+type CommandAction func(ctx context.Context, args map[string]string) (string, error)
+
+type registeredAction struct {
+	cmd Command
+	fn  CommandAction
+}
+
+// CommandRegistry aggregates palette entries from several registries and
+// exposes them at GET /api/commands and POST /api/commands/run.
+type CommandRegistry struct {
+	mu      sync.RWMutex
+	sources []CommandSource
+	actions map[string]registeredAction
+	order   []string // action registration order
+}
+
+// NewCommandRegistry creates an empty command registry.
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{actions: make(map[string]registeredAction)}
+}
+
+// AddSource registers a dynamic command source.
+func (r *CommandRegistry) AddSource(src CommandSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, src)
+}
+
+// AddAction registers an admin action. args names the parameters the
+// palette should prompt for (e.g. "model").
+func (r *CommandRegistry) AddAction(id, label, hint string, args []string, fn CommandAction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.actions[id]; !exists {
+		r.order = append(r.order, id)
+	}
+	r.actions[id] = registeredAction{
+		cmd: Command{ID: "admin:" + id, Group: CommandGroupAdmin, Label: label, Hint: hint, Action: id, Args: args},
+		fn:  fn,
+	}
+}
+
+// List returns all commands whose label or hint contains query
+// (case-insensitive). An empty query returns everything.
+func (r *CommandRegistry) List(query string) []Command {
+	r.mu.RLock()
+	sources := append([]CommandSource(nil), r.sources...)
+	var admin []Command
+	for _, id := range r.order {
+		admin = append(admin, r.actions[id].cmd)
+	}
+	r.mu.RUnlock()
+
+	// Sources are called outside the lock; admin actions are listed last.
+	var all []Command
+	for _, src := range sources {
+		all = append(all, src()...)
+	}
+	all = append(all, admin...)
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return all
+	}
+	var out []Command
+	for _, c := range all {
+		if strings.Contains(strings.ToLower(c.Label), q) || strings.Contains(strings.ToLower(c.Hint), q) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Run executes the admin action with the given id.
+func (r *CommandRegistry) Run(ctx context.Context, id string, args map[string]string) (string, error) {
+	r.mu.RLock()
+	a, ok := r.actions[id]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown action %q", id)
+	}
+	for _, name := range a.cmd.Args {
+		if strings.TrimSpace(args[name]) == "" {
+			return "", fmt.Errorf("action %q: missing argument %q", id, name)
+		}
+	}
+	return a.fn(ctx, args)
+}
+
+// RegisterRoutes registers the command palette API.
+// Routes: GET /api/commands?q=, POST /api/commands/run
+func (r *CommandRegistry) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/commands", r.handleList)
+	mux.HandleFunc("POST /api/commands/run", r.handleRun)
+}
+
+// apiCommandRunRequest is the JSON body for POST /api/commands/run.
+type apiCommandRunRequest struct {
+	Action string            `json:"action"`
+	Args   map[string]string `json:"args,omitempty"`
+}
+
+// apiCommandRunResponse is the JSON body returned from POST /api/commands/run.
+type apiCommandRunResponse struct {
+	Success bool   `json:"success"`
+	Result  string `json:"result,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (r *CommandRegistry) handleList(w http.ResponseWriter, req *http.Request) {
+	p, team := security.PrincipalFrom(req.Context())
+	cmds := []Command{}
+	for _, c := range r.List(req.URL.Query().Get("q")) {
+		if c.owner == "" || !team || p.Role.Allows(security.RoleAdmin) || c.owner == p.Name {
+			cmds = append(cmds, c)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"commands": cmds})
+}
+
+func (r *CommandRegistry) handleRun(w http.ResponseWriter, req *http.Request) {
+	var body apiCommandRunRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeJSONError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Action == "" {
+		writeJSONError(w, "action is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := r.Run(req.Context(), body.Action, body.Args)
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(apiCommandRunResponse{Error: err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(apiCommandRunResponse{Success: true, Result: result})
+}
+
+// ---------------------------------------------------------------------------
+// RecentTasks — bounded list of recent task inputs for the palette.
+// ---------------------------------------------------------------------------
+
+// RecentTasks keeps the most recent task inputs so they can be re-run from
+// the command palette.
+type RecentTasks struct {
+	mu    sync.Mutex
+	max   int
+	items []Command
+}
+
+// NewRecentTasks creates a list holding at most max entries.
+func NewRecentTasks(max int) *RecentTasks {
+	if max <= 0 {
+		max = 10
+	}
+	return &RecentTasks{max: max}
+}
+
+// Add records a completed task of sender. Duplicate inputs of the same
+// sender move to the front.
+func (rt *RecentTasks) Add(taskID, input, sender string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	for i, c := range rt.items {
+		if c.Input == input && c.owner == sender {
+			rt.items = append(rt.items[:i], rt.items[i+1:]...)
+			break
+		}
+	}
+	label := input
+	if len([]rune(label)) > 60 {
+		label = string([]rune(label)[:57]) + "..."
+	}
+	rt.items = append([]Command{{
+		ID:    "recent:" + taskID,
+		Group: CommandGroupRecent,
+		Label: label,
+		Hint:  "Re-run task " + taskID,
+		Input: input,
+		owner: sender,
+	}}, rt.items...)
+	if len(rt.items) > rt.max {
+		rt.items = rt.items[:rt.max]
+	}
+}
+
+// Commands returns the recent tasks, newest first. Usable as a CommandSource.
+func (rt *RecentTasks) Commands() []Command {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return append([]Command(nil), rt.items...)
+}
+
+// TemplateCommands converts name → prompt templates into palette entries,
+// sorted by name.
+func TemplateCommands(templates map[string]string) CommandSource {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return func() []Command {
+		cmds := make([]Command, 0, len(names))
+		for _, name := range names {
+			cmds = append(cmds, Command{
+				ID:    "template:" + name,
+				Group: CommandGroupTemplate,
+				Label: name,
+				Hint:  templates[name],
+				Input: templates[name],
+			})
+		}
+		return cmds
+	}
+}
@@ -0,0 +1,182 @@
+package genui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func newTestCommandRegistry() *CommandRegistry {
+	r := NewCommandRegistry()
+	r.AddSource(TemplateCommands(map[string]string{
+		"Weekly report": "Write my weekly status report",
+		"Inbox triage":  "Triage my inbox",
+	}))
+	r.AddAction("pause", "Pause daemon", "Enter standby", nil,
+		func(context.Context, map[string]string) (string, error) { return "paused", nil })
+	r.AddAction("model.switch", "Switch model", "Pin one model", []string{"model"},
+		func(_ context.Context, args map[string]string) (string, error) {
+			if args["model"] == "bogus" {
+				return "", fmt.Errorf("unknown model %q", args["model"])
+			}
+			return "switched to " + args["model"], nil
+		})
+	return r
+}
+
+func TestCommandRegistry_List(t *testing.T) {
+	r := newTestCommandRegistry()
+
+	all := r.List("")
+	if len(all) != 4 {
+		t.Fatalf("len = %d, want 4", len(all))
+	}
+	// Templates sorted by name, then admin actions in registration order.
+	wantIDs := []string{"template:Inbox triage", "template:Weekly report", "admin:pause", "admin:model.switch"}
+	for i, id := range wantIDs {
+		if all[i].ID != id {
+			t.Errorf("all[%d].ID = %q, want %q", i, all[i].ID, id)
+		}
+	}
+	if all[2].Action != "pause" || all[2].Group != CommandGroupAdmin {
+		t.Errorf("admin command = %+v", all[2])
+	}
+
+	got := r.List("STATUS")
+	if len(got) != 1 || got[0].Label != "Weekly report" {
+		t.Errorf("List(STATUS) = %+v, want the weekly report template (hint match)", got)
+	}
+	if got := r.List("nothing matches"); len(got) != 0 {
+		t.Errorf("List = %+v, want none", got)
+	}
+}
+
+func TestCommandRegistry_Run(t *testing.T) {
+	r := newTestCommandRegistry()
+	ctx := context.Background()
+
+	res, err := r.Run(ctx, "model.switch", map[string]string{"model": "gpt-4.1"})
+	if err != nil || res != "switched to gpt-4.1" {
+		t.Errorf("Run = %q, %v", res, err)
+	}
+	if _, err := r.Run(ctx, "model.switch", nil); err == nil || !strings.Contains(err.Error(), "missing argument") {
+		t.Errorf("missing arg error = %v", err)
+	}
+	if _, err := r.Run(ctx, "nope", nil); err == nil {
+		t.Error("unknown action should fail")
+	}
+}
+
+func TestCommandRegistry_HTTP(t *testing.T) {
+	mux := http.NewServeMux()
+	newTestCommandRegistry().RegisterRoutes(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/commands?q=model", nil))
+	if rr.Code != 200 {
+		t.Fatalf("list status = %d", rr.Code)
+	}
+	var list struct {
+		Commands []Command `json:"commands"`
+	}
+	json.NewDecoder(rr.Body).Decode(&list)
+	if len(list.Commands) != 1 || list.Commands[0].Args[0] != "model" {
+		t.Errorf("commands = %+v", list.Commands)
+	}
+
+	run := func(body string) (*httptest.ResponseRecorder, apiCommandRunResponse) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("POST", "/api/commands/run", bytes.NewReader([]byte(body))))
+		var resp apiCommandRunResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return rr, resp
+	}
+
+	rr, resp := run(`{"action":"pause"}`)
+	if rr.Code != 200 || !resp.Success || resp.Result != "paused" {
+		t.Errorf("run pause: %d %+v", rr.Code, resp)
+	}
+	rr, resp = run(`{"action":"model.switch","args":{"model":"bogus"}}`)
+	if rr.Code != http.StatusUnprocessableEntity || resp.Success || !strings.Contains(resp.Error, "bogus") {
+		t.Errorf("run failing action: %d %+v", rr.Code, resp)
+	}
+	if rr, _ := run(`{}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("missing action status = %d, want 400", rr.Code)
+	}
+	if rr, _ := run(`not json`); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid JSON status = %d, want 400", rr.Code)
+	}
+}
+
+func TestRecentTasks(t *testing.T) {
+	rt := NewRecentTasks(2)
+	rt.Add("t1", "first", "")
+	rt.Add("t2", "second", "")
+	rt.Add("t3", "first", "") // duplicate moves to front
+	rt.Add("t4", "  ", "")    // ignored
+
+	cmds := rt.Commands()
+	if len(cmds) != 2 {
+		t.Fatalf("len = %d, want 2", len(cmds))
+	}
+	if cmds[0].Input != "first" || cmds[0].ID != "recent:t3" || cmds[1].Input != "second" {
+		t.Errorf("order = %+v", cmds)
+	}
+
+	rt.Add("t5", "third", "")
+	cmds = rt.Commands()
+	if len(cmds) != 2 || cmds[0].Input != "third" || cmds[1].Input != "first" {
+		t.Errorf("cap not applied: %+v", cmds)
+	}
+
+	rt.Add("t6", strings.Repeat("x", 100), "")
+	if label := rt.Commands()[0].Label; len([]rune(label)) != 60 || !strings.HasSuffix(label, "...") {
+		t.Errorf("long label = %q", label)
+	}
+}
+
+func TestCommandRegistry_RecentTasksOwners(t *testing.T) {
+	rt := NewRecentTasks(10)
+	rt.Add("t1", "alice's task", "alice")
+	rt.Add("t2", "bob's task", "bob")
+	rt.Add("t3", "bob's task", "alice") // same input, other sender
+	r := NewCommandRegistry()
+	r.AddSource(rt.Commands)
+	mux := http.NewServeMux()
+	r.RegisterRoutes(mux)
+
+	list := func(who *security.Principal) []string {
+		req := httptest.NewRequest("GET", "/api/commands", nil)
+		if who != nil {
+			req = req.WithContext(security.WithPrincipal(req.Context(), *who))
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		var body struct {
+			Commands []Command `json:"commands"`
+		}
+		json.NewDecoder(rr.Body).Decode(&body)
+		var ids []string
+		for _, c := range body.Commands {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	if got := list(&security.Principal{Name: "alice", Role: security.RoleViewer}); strings.Join(got, ",") != "recent:t3,recent:t1" {
+		t.Errorf("alice sees %v", got)
+	}
+	if got := list(&security.Principal{Name: "root", Role: security.RoleAdmin}); len(got) != 3 {
+		t.Errorf("admin sees %v", got)
+	}
+	if got := list(nil); len(got) != 3 {
+		t.Errorf("single-user daemon sees %v", got)
+	}
+}
@@ -3,9 +3,11 @@ package genui
 import (
 	"context"
 	"errors"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/overhuman/overhuman/internal/brain"
 	"github.com/overhuman/overhuman/internal/pipeline"
@@ -839,3 +841,107 @@ func TestGenerate_ModelRouterSelection(t *testing.T) {
 		t.Errorf("expected cheap model for 'simple' complexity, got %q", capturedModel)
 	}
 }
+
+func TestGenerate_MetaFromRun(t *testing.T) {
+	gen := NewUIGenerator(nil, nil) // fast path only
+	result := genSimpleResult("# Done\n\n- one\n- two", 0.9)
+	result.CostUSD, result.ElapsedMs, result.Model = 0.02, 1500, "gpt-4o-mini"
+	result.Sources = []pipeline.MemorySource{{
+		ID: "ltm_7", Date: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Channel: "TELEGRAM", Topic: "meetings", Summary: "Anna prefers mornings",
+	}}
+
+	ui, err := gen.GenerateWithThought(context.Background(), result, CLICapabilities(), &ThoughtLog{TotalMs: 1400}, nil)
+	if err != nil {
+		t.Fatalf("GenerateWithThought: %v", err)
+	}
+	want := UIMeta{Summary: "Completed in 1400ms", CostUSD: 0.02, LatencyMs: 1500, Model: "gpt-4o-mini", Quality: 0.9, Sources: result.Sources}
+	if !reflect.DeepEqual(ui.Meta, want) {
+		t.Errorf("Meta = %+v, want %+v", ui.Meta, want)
+	}
+	lines := ui.Meta.BasedOn()
+	if len(lines) != 1 || lines[0] != "2026-03-01 · telegram · {meetings} · Anna prefers mornings (ltm_7)" {
+		t.Errorf("BasedOn = %q", lines)
+	}
+}
+
+// quotaLLM is a mockLLM that reports rate-limit headroom.
+type quotaLLM struct {
+	*mockLLM
+	q brain.Quota
+}
+
+func (m *quotaLLM) Quota() (brain.Quota, bool) { return m.q, true }
+
+func TestGenerate_PinnedModel(t *testing.T) {
+	mainLLM := newMockLLM(func(ctx context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+		t.Error("main provider used for UI")
+		return nil, errors.New("unexpected")
+	})
+	uiLLM := newMockLLM(func(ctx context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+		return &brain.LLMResponse{Content: genAnsiSimpleText}, nil
+	})
+	gen := newLLMTestGenerator(mainLLM, brain.NewModelRouter())
+	gen.Pin(uiLLM, "qwen2.5:3b")
+
+	if _, err := gen.Generate(context.Background(), genSimpleResult("Hello.", 0.9), CLICapabilities()); err != nil {
+		t.Fatal(err)
+	}
+	if got := uiLLM.lastRequest().Model; got != "qwen2.5:3b" {
+		t.Errorf("model = %q, want the pinned one", got)
+	}
+
+	// Pinning only a provider leaves the model to the provider's default.
+	gen.Pin(nil, "")
+	gen.Generate(context.Background(), genSimpleResult("Hello.", 0.9), CLICapabilities())
+	if got := uiLLM.lastRequest().Model; got != "" {
+		t.Errorf("model = %q, want the provider default", got)
+	}
+}
+
+func TestGenerate_SkipsLLMWhenQuotaLow(t *testing.T) {
+	llm := &quotaLLM{
+		mockLLM: newMockLLM(func(ctx context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+			return &brain.LLMResponse{Content: genHtmlFullPage}, nil
+		}),
+		q: brain.Quota{RequestsLimit: 100, RequestsRemaining: 5, RequestsReset: time.Now().Add(time.Minute)},
+	}
+	gen := newLLMTestGenerator(llm, brain.NewModelRouter())
+
+	ui, err := gen.GenerateWithThought(context.Background(), genSimpleResult("Fish & chips.", 0.9), WebCapabilities(1280, 800), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if llm.requestCount() != 0 {
+		t.Errorf("LLM called %d times with 5%% quota left", llm.requestCount())
+	}
+	if ui.Source != "plain" || ui.Format != FormatHTML || !strings.Contains(ui.Code, "Fish &amp; chips.") {
+		t.Errorf("ui = %+v", ui)
+	}
+
+	llm.q.RequestsRemaining = 50
+	if ui, err = gen.Generate(context.Background(), genSimpleResult("Fish & chips.", 0.9), WebCapabilities(1280, 800)); err != nil || ui.Source != "llm" {
+		t.Errorf("with headroom: %+v, %v", ui, err)
+	}
+	gen.SetQuotaFloor(0)
+	llm.q.RequestsRemaining = 0
+	if ui, _ = gen.Generate(context.Background(), genSimpleResult("x", 0.9), WebCapabilities(1280, 800)); ui == nil || ui.Source != "llm" {
+		t.Errorf("floor 0: %+v", ui)
+	}
+}
+
+func TestGenerate_DeclinedResultIsPlain(t *testing.T) {
+	llm := newMockLLM(func(ctx context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+		return &brain.LLMResponse{Content: genHtmlFullPage}, nil
+	})
+	gen := newLLMTestGenerator(llm, brain.NewModelRouter())
+	result := pipeline.RunResult{TaskID: "task_declined", Declined: true, Result: "The provider declined this request, so there is no answer."}
+
+	ui, err := gen.Generate(context.Background(), result, WebCapabilities(1280, 800))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if llm.requestCount() != 0 || ui.Source != "plain" || !strings.Contains(ui.Code, "declined this request") {
+		t.Errorf("ui = %+v after %d LLM calls", ui, llm.requestCount())
+	}
+}
@@ -0,0 +1,192 @@
+package genui
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default UI history retention.
+const (
+	DefaultUIHistoryEntries = 500
+	DefaultUIHistoryAge     = 30 * 24 * time.Hour
+)
+
+// UIRecord is a generated UI as it was shown: the code, the device it was
+// generated for, the channel it went to and who asked for it.
+type UIRecord struct {
+	UI      GeneratedUI        `json:"ui"`
+	Caps    DeviceCapabilities `json:"caps"`
+	Channel string             `json:"channel,omitempty"`
+	Sender  string             `json:"sender,omitempty"`
+	Created time.Time          `json:"created"`
+}
+
+// UIRetention bounds the UI history. Zero fields use the defaults.
+type UIRetention struct {
+	MaxEntries int
+	MaxAge     time.Duration
+}
+
+func (r UIRetention) withDefaults() UIRetention {
+	if r.MaxEntries <= 0 {
+		r.MaxEntries = DefaultUIHistoryEntries
+	}
+	if r.MaxAge <= 0 {
+		r.MaxAge = DefaultUIHistoryAge
+	}
+	return r
+}
+
+// UIStore keeps the history of generated UIs. Save applies the store's
+// retention. A task may have several UIs (a regenerated answer); Get
+// returns the last. Embedders plug in their own store with
+// UIAPIHandler.SetStore.
+type UIStore interface {
+	Save(rec UIRecord) error
+	Get(taskID string) (*UIRecord, error) // nil when there is none
+	Recent(limit int) ([]UIRecord, error) // newest first
+}
+
+// MemoryUIStore is an in-process UIStore, the default: the history lasts
+// as long as the process.
+type MemoryUIStore struct {
+	retention UIRetention
+
+	mu      sync.Mutex
+	records []UIRecord // oldest first
+}
+
+// NewMemoryUIStore creates an empty in-process store.
+func NewMemoryUIStore(retention UIRetention) *MemoryUIStore {
+	return &MemoryUIStore{retention: retention.withDefaults()}
+}
+
+// Save appends rec and drops what is beyond the retention.
+func (s *MemoryUIStore) Save(rec UIRecord) error {
+	if rec.Created.IsZero() {
+		rec.Created = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	if n := len(s.records) - s.retention.MaxEntries; n > 0 {
+		s.records = s.records[n:]
+	}
+	cutoff := time.Now().Add(-s.retention.MaxAge)
+	for len(s.records) > 0 && s.records[0].Created.Before(cutoff) {
+		s.records = s.records[1:]
+	}
+	return nil
+}
+
+// Get returns the last UI of a task.
+func (s *MemoryUIStore) Get(taskID string) (*UIRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if s.records[i].UI.TaskID == taskID {
+			rec := s.records[i]
+			return &rec, nil
+		}
+	}
+	return nil, nil
+}
+
+// Recent returns up to limit UIs, newest first.
+func (s *MemoryUIStore) Recent(limit int) ([]UIRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []UIRecord
+	for i := len(s.records) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, s.records[i])
+	}
+	return out, nil
+}
+
+// SQLUIStore is a UIStore in a SQL database (the long-term memory's), so
+// the history survives restarts.
+type SQLUIStore struct {
+	db        *sql.DB
+	retention UIRetention
+}
+
+// NewSQLUIStore creates the ui_history table if needed.
+func NewSQLUIStore(db *sql.DB, retention UIRetention) (*SQLUIStore, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS ui_history (
+		task_id    TEXT NOT NULL,
+		record     TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_ui_history_task ON ui_history(task_id);
+	CREATE INDEX IF NOT EXISTS idx_ui_history_created ON ui_history(created_at);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("ui history: create table: %w", err)
+	}
+	return &SQLUIStore{db: db, retention: retention.withDefaults()}, nil
+}
+
+// Save stores rec and deletes what is beyond the retention.
+func (s *SQLUIStore) Save(rec UIRecord) error {
+	if rec.Created.IsZero() {
+		rec.Created = time.Now()
+	}
+	rec.Created = rec.Created.UTC()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("ui history: save %s: %w", rec.UI.TaskID, err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO ui_history (task_id, record, created_at) VALUES (?, ?, ?)`,
+		rec.UI.TaskID, string(data), rec.Created); err != nil {
+		return fmt.Errorf("ui history: save %s: %w", rec.UI.TaskID, err)
+	}
+	_, err = s.db.Exec(`DELETE FROM ui_history WHERE created_at < ? OR rowid NOT IN
+		(SELECT rowid FROM ui_history ORDER BY created_at DESC, rowid DESC LIMIT ?)`,
+		time.Now().UTC().Add(-s.retention.MaxAge), s.retention.MaxEntries)
+	if err != nil {
+		return fmt.Errorf("ui history: prune: %w", err)
+	}
+	return nil
+}
+
+// Get returns the last UI of a task.
+func (s *SQLUIStore) Get(taskID string) (*UIRecord, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT record FROM ui_history WHERE task_id = ? ORDER BY created_at DESC, rowid DESC LIMIT 1`, taskID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ui history: get %s: %w", taskID, err)
+	}
+	var rec UIRecord
+	if err := json.Unmarshal([]byte(data), &rec); err != nil {
+		return nil, fmt.Errorf("ui history: get %s: %w", taskID, err)
+	}
+	return &rec, nil
+}
+
+// Recent returns up to limit UIs, newest first.
+func (s *SQLUIStore) Recent(limit int) ([]UIRecord, error) {
+	rows, err := s.db.Query(`SELECT record FROM ui_history ORDER BY created_at DESC, rowid DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ui history: recent: %w", err)
+	}
+	defer rows.Close()
+	var out []UIRecord
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("ui history: recent: %w", err)
+		}
+		var rec UIRecord
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			continue // written by a newer version
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
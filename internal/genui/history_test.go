@@ -0,0 +1,53 @@
+package genui
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestUIStores(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "ui.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	retention := UIRetention{MaxEntries: 3, MaxAge: time.Hour}
+	sqlStore, err := NewSQLUIStore(db, retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, store := range []UIStore{NewMemoryUIStore(retention), sqlStore} {
+		t.Run(fmt.Sprintf("%T", store), func(t *testing.T) {
+			old := UIRecord{UI: GeneratedUI{TaskID: "old", Code: "stale"}, Created: time.Now().Add(-2 * time.Hour)}
+			if err := store.Save(old); err != nil {
+				t.Fatal(err)
+			}
+			if rec, _ := store.Get("old"); rec != nil {
+				t.Errorf("record past MaxAge kept: %+v", rec)
+			}
+			for i := 1; i <= 4; i++ {
+				rec := UIRecord{UI: GeneratedUI{TaskID: fmt.Sprintf("t%d", i), Format: FormatANSI, Code: fmt.Sprint("code ", i)}, Caps: CLICapabilities(), Channel: "cli"}
+				if err := store.Save(rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			recent, err := store.Recent(10)
+			if err != nil || len(recent) != 3 || recent[0].UI.TaskID != "t4" || recent[2].UI.TaskID != "t2" {
+				t.Fatalf("recent = %+v, %v", recent, err)
+			}
+			rec, err := store.Get("t3")
+			if err != nil || rec == nil || rec.UI.Code != "code 3" || rec.Caps.Width != 80 || rec.Channel != "cli" {
+				t.Errorf("get t3 = %+v, %v", rec, err)
+			}
+			if rec, _ := store.Get("t1"); rec != nil {
+				t.Errorf("record beyond MaxEntries kept: %+v", rec)
+			}
+		})
+	}
+}
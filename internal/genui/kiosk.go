@@ -2,13 +2,18 @@ package genui
 
 import (
 	"fmt"
+	stdhtml "html"
 	"net/http"
 	"strings"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
 )
 
 // KioskConfig configures the kiosk web application.
 type KioskConfig struct {
 	// WSAddr is the WebSocket server address to connect to (e.g., "127.0.0.1:9091").
+	// Empty, wildcard ("[::]:9092") or unix socket addresses make the page
+	// connect back to the host that served it.
 	WSAddr string
 
 	// Title is displayed in the browser tab and kiosk header.
@@ -31,6 +36,21 @@ type KioskConfig struct {
 
 	// SoundEnabled enables synthesized audio feedback (default: false).
 	SoundEnabled bool
+
+	// ColorScheme is "dark", "light" or "system" (follow the device);
+	// empty falls back to DarkMode.
+	ColorScheme string
+
+	// AccentColor replaces the theme's accent, e.g. "#e4572e".
+	AccentColor string
+
+	// Logo is shown next to the title: an http(s) or data: image URL, or
+	// the path of a local image file, served at /logo.
+	Logo string
+
+	// CustomCSSPath is a CSS file appended to /theme.css, read on every
+	// request (a missing file is ignored).
+	CustomCSSPath string
 }
 
 // DefaultKioskConfig returns the default kiosk configuration.
@@ -60,10 +80,18 @@ func NewKioskHandler(cfg KioskConfig) *KioskHandler {
 	return h
 }
 
-// ServeHTTP serves the kiosk single-page application.
+// ServeHTTP serves the kiosk single-page application and its theme.
 func (h *KioskHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Only serve the root path and /kiosk.
+	// Only serve the root path, /kiosk and the theme assets.
 	path := r.URL.Path
+	switch path {
+	case "/theme.css":
+		h.serveThemeCSS(w, r)
+		return
+	case "/logo":
+		h.serveLogo(w, r)
+		return
+	}
 	if path != "/" && path != "/kiosk" && path != "/kiosk/" {
 		http.NotFound(w, r)
 		return
@@ -82,6 +110,8 @@ func (h *KioskHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("/", h)
 	mux.Handle("/kiosk", h)
 	mux.Handle("/kiosk/", h)
+	mux.HandleFunc("/theme.css", h.serveThemeCSS)
+	mux.HandleFunc("/logo", h.serveLogo)
 }
 
 // renderHTML generates the full kiosk HTML page with injected configuration.
@@ -91,6 +121,9 @@ func (h *KioskHandler) renderHTML() string {
 	// Inject configuration.
 	wsProtocol := "ws"
 	wsURL := fmt.Sprintf("%s://%s/ws", wsProtocol, h.config.WSAddr)
+	if h.config.WSAddr == "" || netaddr.IsUnix(h.config.WSAddr) || netaddr.IsWildcard(h.config.WSAddr) {
+		wsURL = "" // the page derives it from location.host
+	}
 
 	html = strings.ReplaceAll(html, "{{WS_URL}}", wsURL)
 	html = strings.ReplaceAll(html, "{{TITLE}}", h.config.Title)
@@ -106,6 +139,13 @@ func (h *KioskHandler) renderHTML() string {
 	}
 	html = strings.ReplaceAll(html, "{{THEME}}", theme)
 	html = strings.ReplaceAll(html, "{{SOUND_ENABLED}}", boolStr(h.config.SoundEnabled))
+	html = strings.ReplaceAll(html, "{{COLOR_SCHEME}}", h.config.colorScheme())
+
+	logo := ""
+	if src := h.config.logoSrc(); src != "" {
+		logo = fmt.Sprintf(`<img class="brand-logo" src="%s" alt="">`, stdhtml.EscapeString(src))
+	}
+	html = strings.ReplaceAll(html, "{{LOGO}}", logo)
 
 	return html
 }
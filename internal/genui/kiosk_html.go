@@ -3,7 +3,7 @@ package genui
 // KioskHTML is the complete kiosk single-page application.
 // Template variables ({{WS_URL}}, {{TITLE}}, etc.) are replaced at runtime by KioskHandler.
 const KioskHTML = `<!DOCTYPE html>
-<html lang="en">
+<html lang="en" data-color-scheme="{{COLOR_SCHEME}}">
 <head>
 <meta charset="utf-8">
 <meta name="viewport" content="width=device-width, initial-scale=1, maximum-scale=1, user-scalable=no">
@@ -310,7 +310,7 @@ html, body {
   text-overflow: ellipsis;
   white-space: nowrap;
   font-family: 'SF Mono', 'Fira Code', monospace;
-  cursor: default;
+  cursor: pointer;
   transition: all 0.15s;
 }
 .task-list li:hover {
@@ -372,6 +372,53 @@ html, body {
   border: none;
   background: transparent;
 }
+.ui-footer {
+  order: 2;
+  padding: 6px 16px;
+  border-top: 1px solid var(--border-dim);
+  color: var(--text-dim);
+  font-family: 'SF Mono', 'Fira Code', monospace;
+  font-size: 11px;
+  white-space: nowrap;
+  overflow: hidden;
+  text-overflow: ellipsis;
+}
+.ui-sources {
+  order: 3;
+  padding: 4px 16px 8px;
+  color: var(--text-dim);
+  font-size: 12px;
+}
+.ui-sources summary {
+  cursor: pointer;
+  color: var(--text-secondary);
+}
+.ui-sources ul {
+  margin: 6px 0 0;
+  padding-left: 18px;
+}
+.ui-sources li { margin: 3px 0; }
+.log-panel { order: 4; padding: 4px 16px 8px; font-size: 12px; color: var(--text-dim); }
+.log-panel summary { cursor: pointer; color: var(--text-secondary); }
+.log-panel select { margin-left: 8px; background: var(--stage-pending); color: var(--text-primary); border: none; border-radius: 4px; font-size: 10px; }
+.log-panel pre {
+  max-height: 240px;
+  overflow-y: auto;
+  margin: 6px 0 0;
+  font-family: 'SF Mono', 'Fira Code', monospace;
+  font-size: 11px;
+  white-space: pre-wrap;
+}
+.log-panel .log-WARN { color: #d29922; }
+.log-panel .log-ERROR { color: var(--danger); }
+.ui-downloads { order: 3; padding: 4px 16px 8px; font-size: 12px; }
+.ui-downloads a { color: var(--accent); margin-right: 12px; }
+.ui-sources .source-meta,
+.ui-sources .source-id {
+  font-family: 'SF Mono', 'Fira Code', monospace;
+  font-size: 11px;
+  opacity: 0.75;
+}
 
 /* === Pipeline HUD === */
 .pipeline-hud {
@@ -447,6 +494,17 @@ html, body {
   background: var(--stage-done);
   box-shadow: 0 0 4px rgba(63, 185, 80, 0.4);
 }
+.pipeline-status {
+  padding: 4px 16px;
+  font-size: 11px;
+  color: var(--text-secondary);
+  text-align: center;
+  flex-shrink: 0;
+  white-space: nowrap;
+  overflow: hidden;
+  text-overflow: ellipsis;
+}
+.pipeline-status:empty { display: none; }
 
 /* === Empty State === */
 .empty-state {
@@ -649,11 +707,100 @@ html, body {
   .sidebar-open-btn { min-height: 44px; min-width: 44px; }
 }
 
+/* === Mode Banner (read-only / maintenance) === */
+.mode-banner {
+  display: none;
+  position: fixed;
+  top: 0; left: 50%;
+  transform: translateX(-50%);
+  z-index: 350;
+  padding: 6px 16px;
+  border-radius: 0 0 8px 8px;
+  font-size: 12px;
+  color: var(--text-primary);
+  background: var(--bg-glass-hover);
+  border: 1px solid var(--border-glow);
+  border-top: none;
+}
+.mode-banner.visible { display: block; }
+.mode-banner.maintenance { border-color: var(--danger); color: var(--danger); }
+.mode-banner.degraded { border-color: #d29922; color: #d29922; }
+.notice-banner { top: 36px; cursor: pointer; }
+.notice-banner.warn { border-color: var(--danger); }
+
+/* === Command Palette (Cmd/Ctrl+K) === */
+.palette {
+  display: none;
+  position: fixed;
+  inset: 0;
+  z-index: 400;
+  background: rgba(0, 0, 0, 0.55);
+  backdrop-filter: blur(4px);
+}
+.palette.visible { display: block; }
+.palette-panel {
+  width: min(560px, calc(100% - 32px));
+  margin: 12vh auto 0;
+  background: var(--bg-glass-hover);
+  border: 1px solid var(--border-glow);
+  border-radius: 10px;
+  box-shadow: 0 0 24px var(--accent-glow);
+  overflow: hidden;
+}
+.palette-input {
+  width: 100%;
+  padding: 14px 16px;
+  background: transparent;
+  border: none;
+  border-bottom: 1px solid var(--border-dim);
+  color: var(--text-primary);
+  font: inherit;
+  font-size: 15px;
+  outline: none;
+}
+.palette-list { list-style: none; max-height: 50vh; overflow-y: auto; margin: 0; padding: 4px 0; }
+.palette-list li { padding: 8px 16px; cursor: pointer; display: flex; gap: 10px; align-items: baseline; }
+.palette-list li.active { background: var(--accent-glow); }
+.palette-group { font-size: 10px; text-transform: uppercase; color: var(--accent); min-width: 64px; }
+.palette-label { color: var(--text-primary); }
+.palette-hint { color: var(--text-dim); font-size: 12px; margin-left: auto; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; max-width: 45%; }
+.palette-status { padding: 8px 16px; font-size: 12px; color: var(--text-secondary); border-top: 1px solid var(--border-dim); }
+.palette-status:empty { display: none; }
+
+/* === Skills catalog === */
+.skills-btn { width: 100%; margin-top: 8px; padding: 6px; background: var(--stage-pending); color: var(--text-primary); border: 1px solid var(--border-dim); border-radius: 4px; font-size: 11px; cursor: pointer; }
+.skills-btn:hover { border-color: var(--border-glow); }
+a.skills-btn { display: block; box-sizing: border-box; text-align: center; text-decoration: none; }
+.skills-panel { width: min(760px, calc(100% - 32px)); }
+.skills-detail { padding: 12px 16px; max-height: 50vh; overflow-y: auto; font-size: 13px; color: var(--text-secondary); border-top: 1px solid var(--border-dim); }
+.skills-detail:empty { display: none; }
+.skills-detail h3 { margin: 0 0 6px; font-size: 14px; color: var(--text-primary); }
+.skills-detail pre { white-space: pre-wrap; font-size: 11px; background: var(--stage-pending); padding: 8px; border-radius: 4px; max-height: 30vh; overflow: auto; }
+.change-status { display: inline-block; margin-left: 6px; padding: 1px 6px; font-size: 10px; border-radius: 8px; border: 1px solid var(--border-glow); color: var(--text-secondary); }
+.change-status.rolled { border-color: var(--danger); color: var(--danger); }
+.change-diff { font-family: monospace; }
+.change-diff .add { color: #3fb950; }
+.change-diff .del { color: var(--danger); }
+.change-rollback { margin-top: 8px; padding: 6px 12px; background: transparent; color: var(--danger); border: 1px solid var(--danger); border-radius: 4px; font-size: 12px; cursor: pointer; }
+.skill-perm { display: inline-block; margin-right: 4px; padding: 1px 6px; font-size: 10px; border-radius: 8px; border: 1px solid var(--danger); color: var(--danger); }
+
 /* === Scrollbar === */
 ::-webkit-scrollbar { width: 4px; }
 ::-webkit-scrollbar-track { background: transparent; }
 ::-webkit-scrollbar-thumb { background: var(--border-glow); border-radius: 4px; }
+
+/* === Branding === */
+.brand-logo {
+  height: 1.6em;
+  width: auto;
+  max-width: 120px;
+  vertical-align: middle;
+  margin-right: 8px;
+  object-fit: contain;
+}
 </style>
+<!-- Accent, color scheme and custom CSS from the kiosk config -->
+<link rel="stylesheet" href="/theme.css">
 </head>
 <body>
 
@@ -664,7 +811,7 @@ html, body {
   <!-- Sidebar -->
   <aside class="sidebar" id="sidebar">
     <div class="sidebar-header">
-      <h1>{{TITLE2}}</h1>
+      <h1>{{LOGO}}{{TITLE2}}</h1>
       <button class="sidebar-collapse-btn" id="sidebarCollapseBtn" title="Collapse sidebar">&#x2715;</button>
     </div>
     <div class="sidebar-body">
@@ -708,6 +855,11 @@ html, body {
             <option value="clean">Clean</option>
           </select>
         </div>
+        <button class="skills-btn" id="btnSkills">Skills catalog</button>
+        <button class="skills-btn" id="btnChanges">Changes</button>
+        <button class="skills-btn" id="btnApprovals">Approvals</button>
+        <button class="skills-btn" id="btnNewChat" title="Forget this conversation's history">New conversation</button>
+        <a class="skills-btn" id="btnExportChat" href="/api/chat/export?format=md" download>Export chat</a>
       </div>
     </div>
   </aside>
@@ -716,12 +868,36 @@ html, body {
   <main class="main-area" id="mainArea">
     <!-- Pipeline HUD -->
     <div class="pipeline-hud" id="pipelineHUD"></div>
+    <div class="pipeline-status" id="pipelineStatus" aria-live="polite"></div>
 
     <!-- Content area -->
     <div class="empty-state" id="emptyState">
       <div class="empty-state-icon">&#x25C9;</div>
       <div class="empty-state-text">Awaiting signal...</div>
     </div>
+
+    <!-- Run footer: model, cost, latency, quality -->
+    <div class="ui-footer" id="uiFooter" style="display:none"></div>
+
+    <!-- Memories the answer was based on -->
+    <details class="ui-sources" id="uiSources" style="display:none">
+      <summary id="uiSourcesSummary">Based on</summary>
+      <ul id="uiSourcesList"></ul>
+    </details>
+
+    <!-- Files the run produced -->
+    <div class="ui-downloads" id="uiDownloads" style="display:none"></div>
+
+    <!-- Live daemon log (admins) -->
+    <details class="log-panel" id="logPanel">
+      <summary>Logs<select id="logLevel" title="Minimum level">
+        <option value="info">info</option>
+        <option value="warn">warn</option>
+        <option value="error">error</option>
+        <option value="debug">debug</option>
+      </select></summary>
+      <pre id="logLines"></pre>
+    </details>
   </main>
 
   <!-- Bottom Bar -->
@@ -740,7 +916,7 @@ html, body {
   <div class="overlay-bg" id="overlayBg"></div>
   <div class="overlay-panel">
     <div class="sidebar-header">
-      <h1>{{TITLE2}}</h1>
+      <h1>{{LOGO}}{{TITLE2}}</h1>
       <button class="sidebar-collapse-btn" id="overlayCloseBtn">&#x2715;</button>
     </div>
     <div class="sidebar-body">
@@ -754,6 +930,47 @@ html, body {
   </div>
 </div>
 
+<!-- Daemon mode banner -->
+<div class="mode-banner" id="modeBanner"></div>
+<!-- Operator notices (click to dismiss) -->
+<div class="mode-banner notice-banner" id="noticeBanner"></div>
+
+<!-- Command palette (Cmd/Ctrl+K) -->
+<div class="palette" id="palette">
+  <div class="palette-panel">
+    <input class="palette-input" id="paletteInput" type="text" placeholder="Run a command or recent task..." autocomplete="off">
+    <ul class="palette-list" id="paletteList"></ul>
+    <div class="palette-status" id="paletteStatus"></div>
+  </div>
+</div>
+
+<!-- Skills catalog -->
+<div class="palette" id="skillsCatalog">
+  <div class="palette-panel skills-panel">
+    <input class="palette-input" id="skillsFilter" type="text" placeholder="Filter skills..." autocomplete="off">
+    <ul class="palette-list" id="skillsList"></ul>
+    <div class="skills-detail" id="skillsDetail"></div>
+    <div class="palette-status" id="skillsStatus"></div>
+  </div>
+</div>
+
+<!-- Self-change timeline -->
+<div class="palette" id="changesView">
+  <div class="palette-panel skills-panel">
+    <ul class="palette-list" id="changesList"></ul>
+    <div class="skills-detail" id="changesDetail"></div>
+    <div class="palette-status" id="changesStatus"></div>
+  </div>
+</div>
+
+<!-- Actions waiting for approval -->
+<div class="palette" id="approvalsView">
+  <div class="palette-panel skills-panel">
+    <ul class="palette-list" id="approvalsList"></ul>
+    <div class="palette-status" id="approvalsStatus"></div>
+  </div>
+</div>
+
 <script>
 (function() {
   "use strict";
@@ -774,14 +991,19 @@ html, body {
 
   // ==== CONFIGURATION ====
   var CONFIG = {
-    wsURL: "{{WS_URL}}",
+    // Empty when the kiosk sits behind a unix socket / reverse proxy:
+    // connect back to whatever host served the page.
+    wsURL: "{{WS_URL}}" || ((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/ws"),
     darkMode: {{DARK_MODE}},
     showSidebar: {{SHOW_SIDEBAR}},
     touchMode: {{TOUCH_MODE}},
     emergencyStop: {{EMERGENCY_STOP}},
     theme: "{{THEME}}",
+    colorScheme: "{{COLOR_SCHEME}}",
     soundEnabled: {{SOUND_ENABLED}},
+    protocolVersion: 1, // genui.WSProtocolVersion
     pingInterval: 25000,
+    modePollInterval: 15000,
     reconnectBase: 1000,
     reconnectMax: 30000,
     feedbackTimeout: 60000,
@@ -790,6 +1012,44 @@ html, body {
     cacheKeyTasks: "overhuman_task_history"
   };
 
+  // ==== TEAM AUTH ====
+  // In team mode the kiosk authenticates with a member token: opened once
+  // as ?token=..., then kept in localStorage and sent with every request.
+  var AUTH_KEY = "ovh_token";
+  (function() {
+    var params = new URLSearchParams(location.search);
+    var t = params.get("token");
+    if (!t) return;
+    try { localStorage.setItem(AUTH_KEY, t); } catch(e) {}
+    params.delete("token");
+    var qs = params.toString();
+    history.replaceState(null, "", location.pathname + (qs ? "?" + qs : "") + location.hash);
+  })();
+
+  function authToken() {
+    try { return localStorage.getItem(AUTH_KEY) || ""; } catch(e) { return ""; }
+  }
+
+  // withToken adds the token to URLs the browser opens itself (WebSocket,
+  // downloads), which cannot carry an Authorization header.
+  function withToken(url) {
+    var t = authToken();
+    if (!t) return url;
+    return url + (url.indexOf("?") < 0 ? "?" : "&") + "token=" + encodeURIComponent(t);
+  }
+
+  var nativeFetch = window.fetch.bind(window);
+  window.fetch = function(input, init) {
+    var t = authToken();
+    if (t && typeof input === "string" && input.charAt(0) === "/") {
+      init = init || {};
+      var headers = new Headers(init.headers || {});
+      if (!headers.has("Authorization")) headers.set("Authorization", "Bearer " + t);
+      init.headers = headers;
+    }
+    return nativeFetch(input, init);
+  };
+
   // ==== STATE ====
   var state = {
     ws: null,
@@ -806,9 +1066,16 @@ html, body {
     feedbackTimer: null,
     feedbackSent: false,
     streamBuffer: "",
+    textPreview: { taskID: "", text: "", done: false, timer: null },
     isCached: false,
     pipelineActive: false,
-    stageStates: {} // stage number → "started"|"completed"|"error"
+    runningTaskID: "",
+    stageStates: {}, // stage number → "started"|"completed"|"error"
+    paletteItems: [],
+    paletteIndex: 0,
+    paletteSeq: 0,
+    skills: [],
+    readOnly: false // team viewer: watch only
   };
 
   // ==== DOM REFS ====
@@ -829,12 +1096,19 @@ html, body {
     taskListOverlay: document.getElementById("taskListOverlay"),
     mainArea: document.getElementById("mainArea"),
     emptyState: document.getElementById("emptyState"),
+    uiFooter: document.getElementById("uiFooter"),
+    uiSources: document.getElementById("uiSources"),
+    uiSourcesSummary: document.getElementById("uiSourcesSummary"),
+    uiSourcesList: document.getElementById("uiSourcesList"),
+    uiDownloads: document.getElementById("uiDownloads"),
     chatInput: document.getElementById("chatInput"),
     btnSend: document.getElementById("btnSend"),
+    btnExportChat: document.getElementById("btnExportChat"),
     btnStop: document.getElementById("btnStop"),
     bottomBar: document.getElementById("bottomBar"),
     connStatus: document.getElementById("connStatus"),
     pipelineHUD: document.getElementById("pipelineHUD"),
+    pipelineStatus: document.getElementById("pipelineStatus"),
     agentRing: document.getElementById("agentRing"),
     agentStateLabel: document.getElementById("agentStateLabel"),
     metricQuality: document.getElementById("metricQuality"),
@@ -843,7 +1117,32 @@ html, body {
     metricDuration: document.getElementById("metricDuration"),
     toggleSound: document.getElementById("toggleSound"),
     toggleCRT: document.getElementById("toggleCRT"),
-    themeSelect: document.getElementById("themeSelect")
+    themeSelect: document.getElementById("themeSelect"),
+    modeBanner: document.getElementById("modeBanner"),
+    noticeBanner: document.getElementById("noticeBanner"),
+    palette: document.getElementById("palette"),
+    paletteInput: document.getElementById("paletteInput"),
+    paletteList: document.getElementById("paletteList"),
+    paletteStatus: document.getElementById("paletteStatus"),
+    btnSkills: document.getElementById("btnSkills"),
+    skillsCatalog: document.getElementById("skillsCatalog"),
+    skillsFilter: document.getElementById("skillsFilter"),
+    skillsList: document.getElementById("skillsList"),
+    skillsDetail: document.getElementById("skillsDetail"),
+    skillsStatus: document.getElementById("skillsStatus"),
+    btnChanges: document.getElementById("btnChanges"),
+    btnApprovals: document.getElementById("btnApprovals"),
+    btnNewChat: document.getElementById("btnNewChat"),
+    logPanel: document.getElementById("logPanel"),
+    logLevel: document.getElementById("logLevel"),
+    logLines: document.getElementById("logLines"),
+    changesView: document.getElementById("changesView"),
+    changesList: document.getElementById("changesList"),
+    changesDetail: document.getElementById("changesDetail"),
+    changesStatus: document.getElementById("changesStatus"),
+    approvalsView: document.getElementById("approvalsView"),
+    approvalsList: document.getElementById("approvalsList"),
+    approvalsStatus: document.getElementById("approvalsStatus")
   };
 
   // ==== NEURAL BACKGROUND ====
@@ -985,6 +1284,7 @@ html, body {
 
   function resetPipeline() {
     state.stageStates = {};
+    dom.pipelineStatus.textContent = "";
     state.pipelineActive = false;
     for (var i = 0; i < STAGES.length; i++) {
       var el = document.getElementById("pstage" + STAGES[i].n);
@@ -1085,7 +1385,35 @@ html, body {
     loadCachedUI();
     bindEvents();
     initNeural();
+    dom.btnExportChat.href = withToken(dom.btnExportChat.getAttribute("href"));
+    checkAccess();
     connectWS();
+    setInterval(refreshMode, CONFIG.modePollInterval);
+  }
+
+  // checkAccess asks for a token when the daemon is in team mode and goes
+  // read-only for viewers.
+  function checkAccess() {
+    fetch("/api/whoami")
+      .then(function(r) {
+        if (r.status === 401) {
+          var t = window.prompt("Access token:");
+          if (t) {
+            try { localStorage.setItem(AUTH_KEY, t.trim()); } catch(e) {}
+            location.reload();
+          }
+          return null;
+        }
+        return r.ok ? r.json() : null;
+      })
+      .then(function(me) {
+        if (!me || me.role !== "viewer") return;
+        state.readOnly = true;
+        dom.chatInput.disabled = true;
+        dom.btnSend.disabled = true;
+        dom.chatInput.placeholder = "View only";
+      })
+      .catch(function() {});
   }
 
   function isTouchDevice() {
@@ -1107,6 +1435,40 @@ html, body {
     dom.btnSend.addEventListener("click", sendChatMessage);
     dom.btnStop.addEventListener("click", sendEmergencyStop);
     window.addEventListener("message", handleIframeMessage);
+    document.addEventListener("keydown", function(e) {
+      if ((e.metaKey || e.ctrlKey) && (e.key === "k" || e.key === "K")) {
+        e.preventDefault();
+        if (dom.palette.classList.contains("visible")) closePalette(); else openPalette();
+      } else if (e.key === "Escape" && dom.palette.classList.contains("visible")) {
+        closePalette();
+      } else if (e.key === "Escape" && dom.skillsCatalog.classList.contains("visible")) {
+        closeSkills();
+      } else if (e.key === "Escape" && dom.changesView.classList.contains("visible")) {
+        closeChanges();
+      } else if (e.key === "Escape" && dom.approvalsView.classList.contains("visible")) {
+        closeApprovals();
+      }
+    });
+    dom.btnSkills.addEventListener("click", openSkills);
+    dom.btnChanges.addEventListener("click", openChanges);
+    dom.btnApprovals.addEventListener("click", openApprovals);
+    dom.btnNewChat.addEventListener("click", newConversation);
+    dom.logPanel.addEventListener("toggle", function() { dom.logPanel.open ? openLogStream() : closeLogStream(); });
+    dom.logLevel.addEventListener("change", function() { if (dom.logPanel.open) openLogStream(); });
+    dom.logLevel.addEventListener("click", function(e) { e.stopPropagation(); });
+    dom.changesView.addEventListener("click", function(e) { if (e.target === dom.changesView) closeChanges(); });
+    dom.approvalsView.addEventListener("click", function(e) { if (e.target === dom.approvalsView) closeApprovals(); });
+    dom.noticeBanner.addEventListener("click", function() { dom.noticeBanner.className = "mode-banner notice-banner"; });
+    dom.skillsCatalog.addEventListener("click", function(e) { if (e.target === dom.skillsCatalog) closeSkills(); });
+    dom.skillsFilter.addEventListener("input", renderSkills);
+    dom.palette.addEventListener("click", function(e) { if (e.target === dom.palette) closePalette(); });
+    dom.paletteInput.addEventListener("input", function() { loadPaletteCommands(dom.paletteInput.value); });
+    dom.paletteInput.addEventListener("keydown", function(e) {
+      var n = state.paletteItems.length;
+      if (e.key === "ArrowDown" && n) { e.preventDefault(); setPaletteIndex((state.paletteIndex + 1) % n); }
+      else if (e.key === "ArrowUp" && n) { e.preventDefault(); setPaletteIndex((state.paletteIndex - 1 + n) % n); }
+      else if (e.key === "Enter") { e.preventDefault(); runPaletteCommand(state.paletteItems[state.paletteIndex]); }
+    });
   }
 
   function closeMobileOverlay() { dom.sidebarOverlay.classList.remove("visible"); }
@@ -1115,15 +1477,20 @@ html, body {
   function connectWS() {
     if (state.ws) { try { state.ws.close(); } catch(e) {} }
     setConnectionStatus("reconnecting");
-    try { state.ws = new WebSocket(CONFIG.wsURL); } catch(e) { scheduleReconnect(); return; }
+    try { state.ws = new WebSocket(withToken(CONFIG.wsURL)); } catch(e) { scheduleReconnect(); return; }
 
     state.ws.onopen = function() {
       state.connected = true;
       state.reconnectDelay = CONFIG.reconnectBase;
       setConnectionStatus("connected");
-      dom.chatInput.disabled = false;
-      dom.btnSend.disabled = false;
+      dom.chatInput.disabled = state.readOnly;
+      dom.btnSend.disabled = state.readOnly;
       startPing();
+      var hello = { client: "kiosk", caps: clientCaps() };
+      var sub = subscription();
+      if (sub) hello.subscribe = sub;
+      wsSend({ type: "hello", payload: hello });
+      refreshMode();
       soundPlay("connect");
     };
     state.ws.onclose = function() {
@@ -1155,7 +1522,46 @@ html, body {
   }
   function stopPing() { if (state.pingTimer) { clearInterval(state.pingTimer); state.pingTimer = null; } }
 
+  function clientCaps() {
+    var rm = window.matchMedia && window.matchMedia("(prefers-reduced-motion: reduce)").matches;
+    return {
+      width: window.innerWidth || 0,
+      height: window.innerHeight || 0,
+      color_depth: (window.screen && screen.colorDepth) || 0,
+      touch: (navigator.maxTouchPoints || 0) > 0 || ("ontouchstart" in window),
+      reduced_motion: !!rm,
+      timezone: (window.Intl && Intl.DateTimeFormat().resolvedOptions().timeZone) || "",
+      locale: navigator.language || ""
+    };
+  }
+
+  // ?events=notice&channels=TIMER&tasks=... limits the broadcasts shown,
+  // e.g. for a wall display that should only show briefings.
+  function subscription() {
+    var params = new URLSearchParams(location.search), sub = null;
+    ["events", "tasks", "channels"].forEach(function(k) {
+      var v = (params.get(k) || "").split(",").map(function(s) { return s.trim(); }).filter(Boolean);
+      if (v.length) { sub = sub || {}; sub[k] = v; }
+    });
+    return sub;
+  }
+
+  // ==== DAEMON MODE ====
+  function refreshMode() {
+    fetch("/api/mode")
+      .then(function(r) { return r.json(); })
+      .then(function(st) {
+        var mode = (st && st.mode) || "normal";
+        dom.modeBanner.className = "mode-banner" + (mode === "normal" ? (st.degraded ? " visible degraded" : "") : " visible " + mode);
+        if (mode === "read_only") dom.modeBanner.textContent = "Read-only mode: answers only, no actions";
+        else if (mode === "maintenance") dom.modeBanner.textContent = "Maintenance: " + (st.message || "new tasks are paused");
+        else dom.modeBanner.textContent = st.degraded || "";
+      })
+      .catch(function() {});
+  }
+
   function wsSend(msg) {
+    msg.v = CONFIG.protocolVersion;
     if (state.ws && state.ws.readyState === WebSocket.OPEN) { state.ws.send(JSON.stringify(msg)); return true; }
     return false;
   }
@@ -1183,6 +1589,8 @@ html, body {
       case "action_result": handleActionResult(msg.payload); break;
       case "error": handleError(msg.payload); break;
       case "pipeline_stage": handlePipelineStage(msg.payload); break;
+      case "status": handleStatus(msg.payload); break;
+      case "notice": handleNotice(msg.payload); break;
       case "pong": break;
     }
   }
@@ -1191,13 +1599,22 @@ html, body {
     if (!payload) return;
     if (payload.status === "started" && payload.stage === 1) {
       resetPipeline();
+      state.runningTaskID = payload.task_id || "";
     }
+    if (payload.status === "error" || (payload.stage === 10 && payload.status !== "started")) state.runningTaskID = "";
     updatePipelineStage(payload.stage, payload.status);
   }
 
+  // Progress line of the running task; cleared when its UI arrives.
+  function handleStatus(payload) {
+    if (!payload) return;
+    dom.pipelineStatus.textContent = payload.text || "";
+  }
+
   function handleUIFull(payload) {
     if (!payload) return;
     maybeSendFeedback();
+    dom.pipelineStatus.textContent = "";
     var taskID = payload.task_id || "";
     state.currentTaskID = taskID;
     state.uiDeliveredAt = Date.now();
@@ -1212,22 +1629,43 @@ html, body {
     renderSandboxedUI(payload.html || "");
     cacheUI(payload);
     startFeedbackTimer();
-    updateMetrics(payload.thought);
+    updateMetrics(payload.thought, payload.meta);
+    renderFooter(payload.meta);
     removeCachedBadge();
   }
 
   function handleUIStream(payload) {
     if (!payload) return;
+    if (payload.text) { handleTextStream(payload); return; }
     state.streamBuffer += (payload.chunk || "");
     if (payload.done) { renderSandboxedUI(state.streamBuffer); state.streamBuffer = ""; }
   }
 
+  // Execution output streamed as plain text: shown escaped, at most every
+  // 200ms, until the task's ui_full replaces it.
+  function handleTextStream(payload) {
+    var pv = state.textPreview;
+    if (pv.taskID !== (payload.task_id || "") || pv.done) {
+      pv.taskID = payload.task_id || "";
+      pv.text = "";
+    }
+    pv.text += (payload.chunk || "");
+    pv.done = !!payload.done;
+    if (pv.timer || !pv.text) return;
+    pv.timer = setTimeout(function() {
+      pv.timer = null;
+      if (pv.taskID === state.currentTaskID) return; // final UI already shown
+      renderSandboxedUI('<pre style="white-space:pre-wrap;font-family:inherit;opacity:.85;">' + escapeHTML(pv.text) + '</pre>');
+    }, 200);
+  }
+
   function handleActionResult(payload) {
     if (!payload) return;
     var iframe = dom.mainArea.querySelector("iframe");
     if (iframe && iframe.contentWindow) {
       iframe.contentWindow.postMessage({ type: "action_result", payload: payload }, "*");
     }
+    if (!payload.success && payload.error) handleNotice({ level: "warn", message: payload.error });
   }
 
   function handleError(payload) {
@@ -1241,8 +1679,23 @@ html, body {
     renderSandboxedUI(errorHTML);
   }
 
+  function handleNotice(payload) {
+    if (!payload || !payload.message) return;
+    dom.noticeBanner.className = "mode-banner notice-banner visible " + (payload.level || "info");
+    dom.noticeBanner.textContent = payload.message;
+    refreshMode(); // notices may come with a mode or degraded-mode change
+  }
+
   // ==== METRICS ====
-  function updateMetrics(thought) {
+  function updateMetrics(thought, meta) {
+    if (meta && (meta.cost_usd || meta.latency_ms || meta.quality)) {
+      dom.metricDuration.textContent = (meta.latency_ms / 1000 || 0).toFixed(1) + "s";
+      dom.metricCost.textContent = "$" + (meta.cost_usd || 0).toFixed(4);
+      var mq = meta.quality || 0;
+      dom.metricQuality.textContent = (mq * 100).toFixed(0) + "%";
+      dom.metricQualityBar.style.width = (mq * 100) + "%";
+      return;
+    }
     if (!thought) return;
     if (thought.total_ms) dom.metricDuration.textContent = (thought.total_ms / 1000).toFixed(1) + "s";
     if (typeof thought.total_cost === "number") dom.metricCost.textContent = "$" + thought.total_cost.toFixed(4);
@@ -1263,6 +1716,73 @@ html, body {
     }
   }
 
+  // ==== RUN FOOTER ====
+  // metaFooter formats meta like UIMeta.Footer on the server:
+  // "model · $0.0123 · 1.2s · quality 85%", leaving out unset fields.
+  function metaFooter(meta) {
+    if (!meta) return "";
+    var parts = [];
+    if (meta.model) parts.push(meta.model);
+    if (meta.cost_usd > 0) parts.push("$" + meta.cost_usd.toFixed(4));
+    if (meta.latency_ms > 0) parts.push((meta.latency_ms / 1000).toFixed(1) + "s");
+    if (meta.quality > 0) parts.push("quality " + (meta.quality * 100).toFixed(0) + "%");
+    return parts.join(" \u00b7 ");
+  }
+  function renderFooter(meta) {
+    var text = metaFooter(meta);
+    dom.uiFooter.textContent = text;
+    dom.uiFooter.style.display = text ? "" : "none";
+    renderSources(meta && meta.sources);
+    renderDownloads(meta && meta.downloads);
+  }
+
+  // renderDownloads links the files the run produced.
+  function renderDownloads(files) {
+    dom.uiDownloads.innerHTML = "";
+    if (!files || !files.length) {
+      dom.uiDownloads.style.display = "none";
+      return;
+    }
+    files.forEach(function(f) {
+      var a = document.createElement("a");
+      a.href = withToken(f.url);
+      a.download = f.name;
+      a.textContent = "\u2B07 " + f.name;
+      dom.uiDownloads.appendChild(a);
+    });
+    dom.uiDownloads.style.display = "";
+  }
+
+  // renderSources lists the memories the answer was based on, collapsed,
+  // with the IDs "overhuman memory forget" takes to correct them.
+  function renderSources(sources) {
+    dom.uiSourcesList.innerHTML = "";
+    dom.uiSources.open = false;
+    if (!sources || !sources.length) {
+      dom.uiSources.style.display = "none";
+      return;
+    }
+    dom.uiSourcesSummary.textContent = "Based on " + sources.length + (sources.length === 1 ? " memory" : " memories");
+    sources.forEach(function(src) {
+      var li = document.createElement("li");
+      var meta = document.createElement("span");
+      meta.className = "source-meta";
+      var parts = [String(src.date || "").slice(0, 10)];
+      if (src.channel) parts.push(String(src.channel).toLowerCase());
+      if (src.topic) parts.push("{" + src.topic + "}");
+      meta.textContent = parts.join(" \u00b7 ") + " ";
+      var id = document.createElement("span");
+      id.className = "source-id";
+      id.textContent = " " + src.id;
+      id.title = "overhuman memory forget " + src.id;
+      li.appendChild(meta);
+      li.appendChild(document.createTextNode(src.summary || ""));
+      li.appendChild(id);
+      dom.uiSourcesList.appendChild(li);
+    });
+    dom.uiSources.style.display = "";
+  }
+
   // ==== SANDBOXED UI RENDERING ====
   function renderSandboxedUI(html) {
     if (dom.emptyState) dom.emptyState.style.display = "none";
@@ -1323,14 +1843,307 @@ html, body {
   function sendChatMessage() {
     var text = dom.chatInput.value.trim();
     if (!text || !state.connected) return;
-    wsSend({ type: "input", payload: { text: text } });
+    wsSend({ type: "input", payload: { text: text, caps: clientCaps() } });
     dom.chatInput.value = "";
     dom.chatInput.focus();
     soundPlay("send");
   }
 
+  // newConversation ends the conversation: the agent forgets its history.
+  function newConversation() {
+    if (!state.connected || state.readOnly) return;
+    wsSend({ type: "input", payload: { text: "/new", caps: clientCaps() } });
+  }
+
+  // ==== LOG PANEL ====
+  // The panel streams GET /logs/stream while open; it keeps the last
+  // LOG_LINES lines.
+  var LOG_LINES = 500;
+  var logStream = null;
+  function openLogStream() {
+    closeLogStream();
+    dom.logLines.textContent = "";
+    logStream = new EventSource(withToken("/logs/stream?level=" + encodeURIComponent(dom.logLevel.value)));
+    logStream.addEventListener("log", function(ev) {
+      var e;
+      try { e = JSON.parse(ev.data); } catch(err) { return; }
+      var line = document.createElement("div");
+      line.className = "log-" + e.level;
+      line.textContent = String(e.time || "").slice(11, 19) + " " + e.level + " " + (e.component ? "[" + e.component + "] " : "") + e.message;
+      var atBottom = dom.logLines.scrollTop + dom.logLines.clientHeight >= dom.logLines.scrollHeight - 4;
+      dom.logLines.appendChild(line);
+      while (dom.logLines.childNodes.length > LOG_LINES) dom.logLines.removeChild(dom.logLines.firstChild);
+      if (atBottom) dom.logLines.scrollTop = dom.logLines.scrollHeight;
+    });
+    logStream.onerror = function() {
+      if (logStream && logStream.readyState === EventSource.CLOSED) {
+        dom.logLines.textContent = "Log stream unavailable (it needs the admin role).";
+      }
+    };
+  }
+  function closeLogStream() {
+    if (logStream) { logStream.close(); logStream = null; }
+  }
+
+  // ==== COMMAND PALETTE ====
+  function openPalette() {
+    dom.palette.classList.add("visible");
+    dom.paletteInput.value = "";
+    dom.paletteStatus.textContent = "";
+    dom.paletteInput.focus();
+    loadPaletteCommands("");
+  }
+  function closePalette() {
+    dom.palette.classList.remove("visible");
+    dom.chatInput.focus();
+  }
+  function loadPaletteCommands(query) {
+    var seq = ++state.paletteSeq;
+    fetch("/api/commands?q=" + encodeURIComponent(query))
+      .then(function(r) { return r.json(); })
+      .then(function(data) {
+        if (seq !== state.paletteSeq) return; // a newer query is in flight
+        state.paletteItems = (data && data.commands) || [];
+        renderPalette();
+      })
+      .catch(function() { dom.paletteStatus.textContent = "Commands unavailable"; });
+  }
+  function renderPalette() {
+    dom.paletteList.innerHTML = "";
+    state.paletteItems.forEach(function(cmd, i) {
+      var li = document.createElement("li");
+      li.innerHTML = '<span class="palette-group">' + escapeHTML(cmd.group || "") + '</span>' +
+        '<span class="palette-label">' + escapeHTML(cmd.label || "") + '</span>' +
+        '<span class="palette-hint">' + escapeHTML(cmd.hint || "") + '</span>';
+      li.addEventListener("mouseenter", function() { setPaletteIndex(i); });
+      li.addEventListener("click", function() { runPaletteCommand(cmd); });
+      dom.paletteList.appendChild(li);
+    });
+    if (!state.paletteItems.length) dom.paletteStatus.textContent = "No matching commands";
+    else if (dom.paletteStatus.textContent === "No matching commands") dom.paletteStatus.textContent = "";
+    setPaletteIndex(0);
+  }
+  function setPaletteIndex(i) {
+    state.paletteIndex = i;
+    var items = dom.paletteList.children;
+    for (var j = 0; j < items.length; j++) items[j].classList.toggle("active", j === i);
+    if (items[i]) items[i].scrollIntoView({ block: "nearest" });
+  }
+  function runPaletteCommand(cmd) {
+    if (!cmd) return;
+    if (cmd.input) {
+      if (!state.connected) { dom.paletteStatus.textContent = "Not connected"; return; }
+      wsSend({ type: "input", payload: { text: cmd.input, caps: clientCaps() } });
+      soundPlay("send");
+      closePalette();
+      return;
+    }
+    if (!cmd.action) return;
+    var args = {};
+    var names = cmd.args || [];
+    for (var i = 0; i < names.length; i++) {
+      var v = window.prompt(cmd.label + " — " + names[i] + ":", "");
+      if (v === null) return;
+      args[names[i]] = v;
+    }
+    dom.paletteStatus.textContent = "Running " + cmd.label + "...";
+    fetch("/api/commands/run", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ action: cmd.action, args: args })
+    })
+      .then(function(r) { return r.json(); })
+      .then(function(res) {
+        dom.paletteStatus.textContent = res.success ? (res.result || "Done") : ("Error: " + (res.error || "failed"));
+      })
+      .catch(function(e) { dom.paletteStatus.textContent = "Error: " + e; });
+  }
+
+  // ==== SKILLS CATALOG ====
+  function openSkills() {
+    dom.skillsCatalog.classList.add("visible");
+    dom.skillsFilter.value = "";
+    dom.skillsDetail.innerHTML = "";
+    dom.skillsStatus.textContent = "Loading...";
+    dom.skillsFilter.focus();
+    fetch("/api/skills")
+      .then(function(r) { return r.json(); })
+      .then(function(data) {
+        state.skills = (data && data.skills) || [];
+        dom.skillsStatus.textContent = "";
+        renderSkills();
+      })
+      .catch(function() { dom.skillsStatus.textContent = "Skills unavailable"; });
+  }
+  function closeSkills() {
+    dom.skillsCatalog.classList.remove("visible");
+    dom.chatInput.focus();
+  }
+  function skillPerms(doc) {
+    var perms = (doc && doc.permissions) || [];
+    return perms.map(function(p) { return '<span class="skill-perm">' + escapeHTML(p) + '</span>'; }).join("");
+  }
+  function renderSkills() {
+    var q = dom.skillsFilter.value.toLowerCase();
+    dom.skillsList.innerHTML = "";
+    var shown = state.skills.filter(function(s) {
+      var summary = (s.doc && s.doc.summary) || s.description || "";
+      return !q || (s.name + " " + summary).toLowerCase().indexOf(q) >= 0;
+    });
+    shown.forEach(function(s) {
+      var li = document.createElement("li");
+      li.innerHTML = '<span class="palette-group">' + escapeHTML(s.type || "") + '</span>' +
+        '<span class="palette-label">' + escapeHTML(s.name || s.id) + '</span>' + skillPerms(s.doc) +
+        '<span class="palette-hint">' + escapeHTML(((s.doc && s.doc.summary) || s.description || "") + " · " + (s.status || "")) + '</span>';
+      li.addEventListener("click", function() { showSkill(s.id); });
+      dom.skillsList.appendChild(li);
+    });
+    if (!shown.length) dom.skillsStatus.textContent = state.skills.length ? "No matching skills" : "No skills yet";
+    else dom.skillsStatus.textContent = shown.length + " skill" + (shown.length === 1 ? "" : "s");
+  }
+  function showSkill(id) {
+    fetch("/api/skills/" + encodeURIComponent(id))
+      .then(function(r) { return r.json(); })
+      .then(function(s) {
+        if (s.error) { dom.skillsStatus.textContent = s.error; return; }
+        var doc = s.doc || { summary: s.description || "" };
+        var html = '<h3>' + escapeHTML(s.name) + '</h3><p>' + escapeHTML(doc.summary || "") + '</p>';
+        html += '<p>Type: ' + escapeHTML(s.type) + ' · Status: ' + escapeHTML(s.status) + ' · Runs: ' + (s.total_runs || 0) +
+          (doc.language ? ' · Language: ' + escapeHTML(doc.language) : '') + '</p>';
+        if (doc.inputs && doc.inputs.length) {
+          html += '<p>Inputs:</p><ul>' + doc.inputs.map(function(p) {
+            return '<li>' + escapeHTML(p.name) + (p.required ? ' (required)' : '') + ': ' + escapeHTML(p.description || "") + '</li>';
+          }).join("") + '</ul>';
+        }
+        if (doc.outputs) html += '<p>Outputs: ' + escapeHTML(doc.outputs) + '</p>';
+        html += '<p>Permissions: ' + (skillPerms(doc) || 'none') + '</p>';
+        if (doc.examples && doc.examples.length) {
+          html += '<p>Examples:</p><ul>' + doc.examples.map(function(ex) {
+            return '<li>' + escapeHTML(ex.input) + (ex.output ? ' &rarr; ' + escapeHTML(ex.output) : '') + '</li>';
+          }).join("") + '</ul>';
+        }
+        if (s.source) html += '<p>Source:</p><pre>' + escapeHTML(s.source) + '</pre>';
+        dom.skillsDetail.innerHTML = html;
+      })
+      .catch(function(e) { dom.skillsStatus.textContent = "Error: " + e; });
+  }
+
+  // ==== CHANGES (soul edits, skill promotions, rollbacks) ====
+  function openChanges() {
+    dom.changesView.classList.add("visible");
+    dom.changesDetail.innerHTML = "";
+    loadChanges();
+  }
+  function closeChanges() {
+    dom.changesView.classList.remove("visible");
+    dom.chatInput.focus();
+  }
+  function changeStatus(c) {
+    var label = c.status === "ROLLED_BACK" ? "rolled back" + (c.rolled_back_by ? " (" + c.rolled_back_by + ")" : "") : c.status.toLowerCase();
+    return '<span class="change-status' + (c.status === "ROLLED_BACK" ? ' rolled' : '') + '">' + escapeHTML(label) + '</span>';
+  }
+  function loadChanges() {
+    dom.changesStatus.textContent = "Loading...";
+    fetch("/api/changes")
+      .then(function(r) { return r.json(); })
+      .then(function(data) {
+        var changes = (data && data.changes) || [];
+        dom.changesList.innerHTML = "";
+        changes.forEach(function(c) {
+          var li = document.createElement("li");
+          li.innerHTML = '<span class="palette-group">' + escapeHTML(c.type) + '</span>' +
+            '<span class="palette-label">' + escapeHTML(c.description || c.entity_id) + '</span>' + changeStatus(c) +
+            '<span class="palette-hint">' + escapeHTML(new Date(c.created_at).toLocaleString() + (c.trigger ? " · " + c.trigger : "")) + '</span>';
+          li.addEventListener("click", function() { showChange(c.id); });
+          dom.changesList.appendChild(li);
+        });
+        dom.changesStatus.textContent = changes.length ? changes.length + " change" + (changes.length === 1 ? "" : "s") : "No changes yet";
+      })
+      .catch(function() { dom.changesStatus.textContent = "Changes unavailable"; });
+  }
+  function showChange(id) {
+    fetch("/api/changes/" + encodeURIComponent(id))
+      .then(function(r) { return r.json(); })
+      .then(function(c) {
+        if (c.error) { dom.changesStatus.textContent = c.error; return; }
+        var html = '<h3>' + escapeHTML(c.description || c.entity_id) + changeStatus(c) + '</h3>';
+        html += '<p>' + escapeHTML(c.type) + ' · ' + escapeHTML(c.entity_id) + (c.trigger ? ' · Triggered by: ' + escapeHTML(c.trigger) : '') + '</p>';
+        html += '<p>Quality: ' + (c.baseline_quality || 0).toFixed(2) + ' &rarr; ' + (c.current_quality || 0).toFixed(2) +
+          ' · Runs observed: ' + c.runs_observed + '/' + c.window_size + '</p>';
+        if (c.rollback_error) html += '<p>Rollback failed: ' + escapeHTML(c.rollback_error) + '</p>';
+        if (c.diff && c.diff.length) {
+          html += '<pre class="change-diff">' + c.diff.map(function(l) {
+            var cls = l.op === "+" ? "add" : (l.op === "-" ? "del" : "");
+            return '<span class="' + cls + '">' + escapeHTML(l.op + " " + l.text) + '</span>';
+          }).join("\n") + '</pre>';
+        }
+        if (c.can_rollback) html += '<button class="change-rollback" id="changeRollback">Roll back</button>';
+        dom.changesDetail.innerHTML = html;
+        var btn = document.getElementById("changeRollback");
+        if (btn) btn.addEventListener("click", function() { rollbackChange(c.id); });
+      })
+      .catch(function(e) { dom.changesStatus.textContent = "Error: " + e; });
+  }
+  function rollbackChange(id) {
+    if (!confirm("Roll back this change?")) return;
+    fetch("/api/changes/" + encodeURIComponent(id) + "/rollback", { method: "POST" })
+      .then(function(r) { return r.json(); })
+      .then(function(res) {
+        if (res.error) { dom.changesStatus.textContent = "Error: " + res.error; return; }
+        loadChanges();
+        showChange(id);
+      })
+      .catch(function(e) { dom.changesStatus.textContent = "Error: " + e; });
+  }
+
+  // ==== APPROVALS (high-risk actions waiting for an admin) ====
+  function openApprovals() {
+    dom.approvalsView.classList.add("visible");
+    loadApprovals();
+  }
+  function closeApprovals() {
+    dom.approvalsView.classList.remove("visible");
+    dom.chatInput.focus();
+  }
+  function loadApprovals() {
+    dom.approvalsStatus.textContent = "Loading...";
+    fetch("/api/approvals")
+      .then(function(r) { return r.json(); })
+      .then(function(data) {
+        var list = (data && data.approvals) || [];
+        dom.approvalsList.innerHTML = "";
+        list.forEach(function(a) {
+          var li = document.createElement("li");
+          li.innerHTML = '<span class="palette-label">' + escapeHTML(a.action) + '</span>' +
+            '<span class="palette-hint">' + escapeHTML(new Date(a.created_at).toLocaleString() + (a.requester ? " · for " + a.requester : "") + (a.detail ? " · " + a.detail : "")) + '</span>' +
+            '<button class="change-rollback" data-decision="approve">Approve</button> <button class="change-rollback" data-decision="deny">Deny</button>';
+          li.querySelectorAll("button").forEach(function(btn) {
+            btn.addEventListener("click", function() { decideApproval(a.id, btn.dataset.decision); });
+          });
+          dom.approvalsList.appendChild(li);
+        });
+        dom.approvalsStatus.textContent = list.length ? list.length + " waiting" : "Nothing waiting for approval";
+      })
+      .catch(function() { dom.approvalsStatus.textContent = "Approvals unavailable"; });
+  }
+  function decideApproval(id, decision) {
+    var note = prompt(decision === "approve" ? "Approve — note (optional):" : "Deny — reason (optional):", "");
+    if (note === null) return;
+    fetch("/api/approvals/" + encodeURIComponent(id) + "/" + decision, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ note: note })
+    })
+      .then(function(r) { return r.json(); })
+      .then(function(res) {
+        if (res.error) { dom.approvalsStatus.textContent = "Error: " + res.error; return; }
+        loadApprovals();
+      })
+      .catch(function(e) { dom.approvalsStatus.textContent = "Error: " + e; });
+  }
+
   function sendEmergencyStop() {
-    wsSend({ type: "cancel", payload: { reason: "user" } });
+    wsSend({ type: "cancel", payload: { task_id: state.runningTaskID || "", reason: "user" } });
     dom.btnStop.classList.add("pulse");
     setTimeout(function() { dom.btnStop.classList.remove("pulse"); }, 400);
   }
@@ -1370,12 +2183,32 @@ html, body {
     renderTaskHistory();
   }
   function renderTaskHistory() {
-    var html = "";
-    for (var i = 0; i < state.taskHistory.length; i++) {
-      html += '<li title="' + escapeAttr(state.taskHistory[i]) + '">' + escapeHTML(state.taskHistory[i]) + '</li>';
-    }
-    dom.taskList.innerHTML = html;
-    dom.taskListOverlay.innerHTML = html;
+    [dom.taskList, dom.taskListOverlay].forEach(function(list) {
+      list.innerHTML = "";
+      state.taskHistory.forEach(function(taskID) {
+        var li = document.createElement("li");
+        li.title = taskID;
+        li.textContent = taskID;
+        li.addEventListener("click", function() { showTaskUI(taskID); });
+        list.appendChild(li);
+      });
+    });
+  }
+  // showTaskUI shows a past task's UI again, exactly as it was generated,
+  // from the daemon's UI history.
+  function showTaskUI(taskID) {
+    fetch("/api/ui/" + encodeURIComponent(taskID))
+      .then(function(r) { return r.json(); })
+      .then(function(rec) {
+        if (!rec.ui) { handleNotice({ level: "info", message: rec.error || "No UI stored for " + taskID }); return; }
+        maybeSendFeedback();
+        state.currentTaskID = taskID;
+        state.streamBuffer = "";
+        renderSandboxedUI(rec.ui.code || "");
+        renderFooter(rec.ui.meta);
+        closeMobileOverlay();
+      })
+      .catch(function(e) { handleNotice({ level: "error", message: "Error: " + e }); });
   }
 
   // ==== CACHE ====
@@ -1389,6 +2222,7 @@ html, body {
         state.isCached = true;
         state.currentTaskID = payload.task_id || "";
         renderSandboxedUI(payload.html);
+        renderFooter(payload.meta);
         var badge = document.createElement("span");
         badge.className = "cached-badge";
         badge.textContent = "Cached";
@@ -1401,7 +2235,6 @@ html, body {
 
   // ==== UTILITIES ====
   function escapeHTML(str) { var d = document.createElement("div"); d.appendChild(document.createTextNode(str)); return d.innerHTML; }
-  function escapeAttr(str) { return str.replace(/&/g,"&amp;").replace(/"/g,"&quot;").replace(/</g,"&lt;").replace(/>/g,"&gt;"); }
   function safeParseJSON(str) { try { return JSON.parse(str); } catch(e) { return {}; } }
 
   // ==== START ====
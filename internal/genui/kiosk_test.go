@@ -3,6 +3,8 @@ package genui
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -109,6 +111,19 @@ func TestKioskHandler_ConfigInjected(t *testing.T) {
 	}
 }
 
+func TestKioskHandler_WSURLFromPageHost(t *testing.T) {
+	for _, addr := range []string{"unix:/run/overhuman/kiosk.sock", "[::]:9092", "0.0.0.0:9092"} {
+		h := NewKioskHandler(KioskConfig{WSAddr: addr})
+		if !strings.Contains(h.html, `wsURL: "" ||`) {
+			t.Errorf("WSAddr %q: page should derive the WS URL from location.host", addr)
+		}
+	}
+	h := NewKioskHandler(KioskConfig{WSAddr: "[::1]:9092"})
+	if !strings.Contains(h.html, "ws://[::1]:9092/ws") {
+		t.Error("IPv6 WS URL not injected")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // HTTP serving
 // ---------------------------------------------------------------------------
@@ -294,6 +309,13 @@ func TestKioskHTML_HasOfflineCache(t *testing.T) {
 	}
 }
 
+func TestKioskHTML_HasStatusLine(t *testing.T) {
+	h := NewKioskHandler(DefaultKioskConfig())
+	if !strings.Contains(h.html, `id="pipelineStatus"`) || !strings.Contains(h.html, `case "status":`) {
+		t.Error("rendered HTML does not show status messages")
+	}
+}
+
 func TestKioskHTML_HasConnectionStatus(t *testing.T) {
 	h := NewKioskHandler(DefaultKioskConfig())
 	if !strings.Contains(h.html, "conn-dot") {
@@ -499,6 +521,126 @@ func TestKioskHTML_HasSoundTemplateVar(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Branding: color scheme, accent, logo and custom CSS
+// ---------------------------------------------------------------------------
+
+func TestKioskConfig_Validate(t *testing.T) {
+	for _, c := range []struct {
+		cfg     KioskConfig
+		wantErr bool
+	}{
+		{DefaultKioskConfig(), false},
+		{KioskConfig{Theme: "clean", ColorScheme: "system", AccentColor: "#e4572e", Logo: "https://example.com/logo.png"}, false},
+		{KioskConfig{AccentColor: "rgb(228, 87, 46)", Logo: "/srv/logo.svg"}, false},
+		{KioskConfig{AccentColor: "teal"}, false},
+		{KioskConfig{Theme: "neon"}, true},
+		{KioskConfig{ColorScheme: "auto"}, true},
+		{KioskConfig{AccentColor: "red; } body { display: none"}, true},
+		{KioskConfig{Logo: "ftp://example.com/logo.png"}, true},
+	} {
+		if err := c.cfg.Validate(); (err != nil) != c.wantErr {
+			t.Errorf("Validate(%+v) = %v, wantErr %v", c.cfg, err, c.wantErr)
+		}
+	}
+}
+
+func TestKioskHandler_ColorScheme(t *testing.T) {
+	cfg := DefaultKioskConfig()
+	if h := NewKioskHandler(cfg); !strings.Contains(h.html, `colorScheme: "dark"`) {
+		t.Error("default color scheme should be dark")
+	}
+	cfg.DarkMode = false
+	if h := NewKioskHandler(cfg); !strings.Contains(h.html, `data-color-scheme="light"`) {
+		t.Error("DarkMode=false should render the light scheme")
+	}
+	cfg.ColorScheme = ColorSchemeSystem
+	if h := NewKioskHandler(cfg); !strings.Contains(h.html, `colorScheme: "system"`) {
+		t.Error("ColorScheme should override DarkMode")
+	}
+}
+
+func TestKioskHandler_Logo(t *testing.T) {
+	cfg := DefaultKioskConfig()
+	if h := NewKioskHandler(cfg); strings.Contains(h.html, `class="brand-logo"`) {
+		t.Error("logo rendered without one configured")
+	}
+
+	cfg.Logo = `https://example.com/logo.png?a=1&b="x"`
+	h := NewKioskHandler(cfg)
+	if !strings.Contains(h.html, `<img class="brand-logo" src="https://example.com/logo.png?a=1&amp;b=&#34;x&#34;" alt="">`) {
+		t.Error("remote logo not rendered (escaped)")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logo.svg")
+	os.WriteFile(path, []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0o644)
+	cfg.Logo = path
+	h = NewKioskHandler(cfg)
+	if !strings.Contains(h.html, `src="/logo"`) {
+		t.Error("local logo should be served through /logo")
+	}
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/logo", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<svg") {
+		t.Errorf("GET /logo = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestKioskHandler_ThemeCSS(t *testing.T) {
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "kiosk.css")
+
+	cfg := DefaultKioskConfig()
+	cfg.ColorScheme = ColorSchemeSystem
+	cfg.AccentColor = "#e4572e"
+	cfg.CustomCSSPath = custom
+	h := NewKioskHandler(cfg)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	get := func() string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/theme.css", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /theme.css = %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		return rec.Body.String()
+	}
+
+	css := get()
+	for _, want := range []string{"@media (prefers-color-scheme: light)", "--bg-void: #f6f8fa", "--accent: #e4572e;", "--stage-active: #e4572e;"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("theme.css missing %q:\n%s", want, css)
+		}
+	}
+	if strings.Contains(css, "custom") {
+		t.Error("missing custom CSS file should be ignored")
+	}
+
+	// Edits to the custom file show without a restart.
+	os.WriteFile(custom, []byte(".sidebar { display: none; }"), 0o644)
+	if css := get(); !strings.HasSuffix(css, ".sidebar { display: none; }") {
+		t.Errorf("custom CSS not appended:\n%s", css)
+	}
+
+	if !strings.Contains(h.html, `<link rel="stylesheet" href="/theme.css">`) {
+		t.Error("kiosk HTML does not link /theme.css")
+	}
+}
+
+func TestKioskHandler_ThemeCSSDarkDefault(t *testing.T) {
+	if css := DefaultKioskConfig().themeCSS(); css != "" {
+		t.Errorf("default theme.css = %q, want empty (built-in dark palette)", css)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Kiosk HTML new feature tests
 // ---------------------------------------------------------------------------
@@ -636,3 +778,37 @@ func TestKioskHTML_HasToggleControls(t *testing.T) {
 		t.Error("rendered HTML does not contain toggleCRT button")
 	}
 }
+
+func TestKioskHTML_HasCommandPalette(t *testing.T) {
+	h := NewKioskHandler(DefaultKioskConfig())
+	for _, want := range []string{`id="palette"`, "/api/commands", "/api/commands/run", "e.metaKey || e.ctrlKey"} {
+		if !strings.Contains(h.html, want) {
+			t.Errorf("rendered HTML missing %q", want)
+		}
+	}
+}
+
+func TestKioskHTML_HasModeBanner(t *testing.T) {
+	h := NewKioskHandler(DefaultKioskConfig())
+	for _, want := range []string{`id="modeBanner"`, `fetch("/api/mode")`} {
+		if !strings.Contains(h.html, want) {
+			t.Errorf("rendered HTML missing %q", want)
+		}
+	}
+}
+
+func TestKioskHTML_HasChangesAndExport(t *testing.T) {
+	for _, want := range []string{`id="changesView"`, `fetch("/api/changes")`, `"/rollback"`, `id="approvalsView"`, `fetch("/api/approvals")`, `href="/api/chat/export?format=md"`} {
+		if !strings.Contains(KioskHTML, want) {
+			t.Errorf("kiosk HTML missing %q", want)
+		}
+	}
+}
+
+func TestKioskHTML_HasSkillsCatalog(t *testing.T) {
+	for _, want := range []string{`id="skillsCatalog"`, `fetch("/api/skills")`, "Skills catalog"} {
+		if !strings.Contains(KioskHTML, want) {
+			t.Errorf("kiosk HTML missing %q", want)
+		}
+	}
+}
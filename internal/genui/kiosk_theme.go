@@ -0,0 +1,140 @@
+package genui
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Color schemes accepted by KioskConfig.ColorScheme.
+const (
+	ColorSchemeDark   = "dark"
+	ColorSchemeLight  = "light"
+	ColorSchemeSystem = "system" // follows prefers-color-scheme
+)
+
+// kioskThemes are the built-in theme presets.
+var kioskThemes = []string{"scifi", "cyberpunk", "clean"}
+
+// cssColorRe accepts hex colors, named colors and rgb()/hsl() functions,
+// nothing that could close the declaration it is pasted into.
+var cssColorRe = regexp.MustCompile(`^(#[0-9a-fA-F]{3,4}|#[0-9a-fA-F]{6}|#[0-9a-fA-F]{8}|[a-zA-Z]+|(rgb|rgba|hsl|hsla)\([0-9a-z.,%/ ]+\))$`)
+
+// Validate reports the first invalid theming option.
+func (c KioskConfig) Validate() error {
+	if c.Theme != "" && !slices.Contains(kioskThemes, c.Theme) {
+		return fmt.Errorf("unknown theme %q (want %s)", c.Theme, strings.Join(kioskThemes, ", "))
+	}
+	switch c.ColorScheme {
+	case "", ColorSchemeDark, ColorSchemeLight, ColorSchemeSystem:
+	default:
+		return fmt.Errorf("unknown color scheme %q (want dark, light or system)", c.ColorScheme)
+	}
+	if c.AccentColor != "" && !cssColorRe.MatchString(c.AccentColor) {
+		return fmt.Errorf("accent color %q: want #rrggbb, a color name, rgb() or hsl()", c.AccentColor)
+	}
+	if strings.Contains(c.Logo, "://") && !isRemoteLogo(c.Logo) {
+		return fmt.Errorf("logo %q: want an http(s) URL or an image file", c.Logo)
+	}
+	return nil
+}
+
+// colorScheme is the effective color scheme; without one DarkMode decides.
+func (c KioskConfig) colorScheme() string {
+	if c.ColorScheme != "" {
+		return c.ColorScheme
+	}
+	if c.DarkMode {
+		return ColorSchemeDark
+	}
+	return ColorSchemeLight
+}
+
+// logoSrc is the <img> source for the logo: remote URLs as given, local
+// files through the /logo route.
+func (c KioskConfig) logoSrc() string {
+	switch {
+	case c.Logo == "":
+		return ""
+	case isRemoteLogo(c.Logo):
+		return c.Logo
+	}
+	return "/logo"
+}
+
+func isRemoteLogo(logo string) bool {
+	return strings.HasPrefix(logo, "https://") || strings.HasPrefix(logo, "http://") || strings.HasPrefix(logo, "data:image/")
+}
+
+// lightPalette overrides the dark color variables for the light scheme.
+const lightPalette = `  --bg-void: #f6f8fa;
+  --bg-glass: rgba(255, 255, 255, 0.82);
+  --bg-glass-hover: rgba(255, 255, 255, 0.94);
+  --bg-solid: #ffffff;
+  --text-primary: #1f2328;
+  --text-dim: #6e7781;
+  --text-secondary: #57606a;
+  --border-dim: rgba(208, 215, 222, 0.8);
+  --stage-pending: #d0d7de;
+  color-scheme: light;
+`
+
+// themeSelector outranks the :root defaults and the .theme-* presets,
+// which are set on <body>.
+const themeSelector = ":root, body, body[class]"
+
+// themeCSS renders the generated part of /theme.css: the light palette
+// and the accent color derived into the variables that use it.
+func (c KioskConfig) themeCSS() string {
+	var b strings.Builder
+	switch c.colorScheme() {
+	case ColorSchemeLight:
+		fmt.Fprintf(&b, "%s {\n%s}\n", themeSelector, lightPalette)
+	case ColorSchemeSystem:
+		fmt.Fprintf(&b, "@media (prefers-color-scheme: light) {\n%s {\n%s}\n}\n", themeSelector, lightPalette)
+	}
+	if a := c.AccentColor; a != "" && cssColorRe.MatchString(a) {
+		fmt.Fprintf(&b, "%s {\n", themeSelector)
+		fmt.Fprintf(&b, "  --accent: %s;\n", a)
+		fmt.Fprintf(&b, "  --accent-hover: color-mix(in srgb, %s 80%%, white);\n", a)
+		fmt.Fprintf(&b, "  --accent-glow: color-mix(in srgb, %s 30%%, transparent);\n", a)
+		fmt.Fprintf(&b, "  --accent-glow-strong: color-mix(in srgb, %s 60%%, transparent);\n", a)
+		fmt.Fprintf(&b, "  --border-glow: color-mix(in srgb, %s 15%%, transparent);\n", a)
+		fmt.Fprintf(&b, "  --stage-active: %s;\n", a)
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// serveThemeCSS serves the generated theme followed by the custom CSS
+// file. The file is read on every request so edits show on reload.
+func (h *KioskHandler) serveThemeCSS(w http.ResponseWriter, r *http.Request) {
+	css := h.config.themeCSS()
+	if path := h.config.CustomCSSPath; path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			css += "\n/* custom */\n" + string(data)
+		case !os.IsNotExist(err):
+			css += fmt.Sprintf("\n/* custom CSS unreadable: %s */\n", strings.ReplaceAll(err.Error(), "*/", ""))
+		}
+	}
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	fmt.Fprint(w, css)
+}
+
+// serveLogo serves a local logo file.
+func (h *KioskHandler) serveLogo(w http.ResponseWriter, r *http.Request) {
+	if h.config.Logo == "" || isRemoteLogo(h.config.Logo) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(w, r, h.config.Logo)
+}
@@ -0,0 +1,202 @@
+package genui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+// SpeechOutputConfig configures a SpeechOutput.
+type SpeechOutputConfig struct {
+	// TTS synthesizes the speech (OpenAI TTS, local Piper, ...).
+	TTS brain.TextToSpeech
+
+	// OutboxDir, if set, receives an audio file per result, named after
+	// the task.
+	OutboxDir string
+
+	// Player, if set, is the command that plays a file, e.g. "aplay" or
+	// "mpv --really-quiet"; the file's path is appended.
+	Player string
+
+	// MaxChars caps how much of a result is read out. Default: 1500.
+	MaxChars int
+
+	// Keep is how many files the outbox keeps, oldest removed first.
+	// Default: 100.
+	Keep int
+}
+
+// SpeechOutput reads results out for hands-free and kiosk deployments:
+// the result is made speakable (SpeakableText), synthesized, and played
+// and/or written to the outbox.
+type SpeechOutput struct {
+	cfg SpeechOutputConfig
+
+	playMu sync.Mutex // one result plays at a time
+}
+
+// NewSpeechOutput creates a speech output. It needs a TTS backend and an
+// outbox, a player or both.
+func NewSpeechOutput(cfg SpeechOutputConfig) (*SpeechOutput, error) {
+	if cfg.TTS == nil {
+		return nil, fmt.Errorf("speech output: no text-to-speech backend")
+	}
+	if cfg.OutboxDir == "" && cfg.Player == "" {
+		return nil, fmt.Errorf("speech output: set an outbox directory or a player")
+	}
+	if cfg.MaxChars <= 0 {
+		cfg.MaxChars = 1500
+	}
+	if cfg.Keep <= 0 {
+		cfg.Keep = 100
+	}
+	return &SpeechOutput{cfg: cfg}, nil
+}
+
+// Speak reads result out. It returns the outbox file written, or "" when
+// the result was only played or had nothing to say.
+func (s *SpeechOutput) Speak(ctx context.Context, result pipeline.RunResult) (string, error) {
+	text := SpeakableText(result.Result, s.cfg.MaxChars)
+	if text == "" {
+		return "", nil
+	}
+	audio, err := s.cfg.TTS.Synthesize(ctx, brain.SpeechRequest{Text: text})
+	if err != nil {
+		return "", fmt.Errorf("speech output: %w", err)
+	}
+
+	path := ""
+	if s.cfg.OutboxDir != "" {
+		if path, err = s.save(result.TaskID, audio); err != nil {
+			return "", err
+		}
+	}
+	if s.cfg.Player == "" {
+		return path, nil
+	}
+	file := path
+	if file == "" {
+		tmp, err := os.CreateTemp("", "overhuman-speech-*"+audioExt(audio.MIMEType))
+		if err != nil {
+			return "", fmt.Errorf("speech output: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.Write(audio.Data)
+		tmp.Close()
+		if err != nil {
+			return "", fmt.Errorf("speech output: %w", err)
+		}
+		file = tmp.Name()
+	}
+	return path, s.play(ctx, file)
+}
+
+// save writes audio to the outbox and trims it to Keep files.
+func (s *SpeechOutput) save(taskID string, audio *brain.SpeechAudio) (string, error) {
+	if err := os.MkdirAll(s.cfg.OutboxDir, 0o755); err != nil {
+		return "", fmt.Errorf("speech output: %w", err)
+	}
+	name := taskID
+	if name == "" {
+		name = "result"
+	}
+	path := filepath.Join(s.cfg.OutboxDir, filepath.Base(name)+audioExt(audio.MIMEType))
+	if err := os.WriteFile(path, audio.Data, 0o644); err != nil {
+		return "", fmt.Errorf("speech output: %w", err)
+	}
+
+	entries, err := os.ReadDir(s.cfg.OutboxDir)
+	if err != nil || len(entries) <= s.cfg.Keep {
+		return path, nil
+	}
+	type file struct {
+		path string
+		mod  int64
+	}
+	var files []file
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			files = append(files, file{filepath.Join(s.cfg.OutboxDir, e.Name()), info.ModTime().UnixNano()})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod < files[j].mod })
+	for _, f := range files[:max(0, len(files)-s.cfg.Keep)] {
+		if f.path != path {
+			os.Remove(f.path)
+		}
+	}
+	return path, nil
+}
+
+// play runs the player on file, one result at a time.
+func (s *SpeechOutput) play(ctx context.Context, file string) error {
+	args := strings.Fields(s.cfg.Player)
+	s.playMu.Lock()
+	defer s.playMu.Unlock()
+	out, err := exec.CommandContext(ctx, args[0], append(args[1:], file)...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if len(msg) > 300 {
+			msg = msg[len(msg)-300:]
+		}
+		return fmt.Errorf("speech output: play: %w %s", err, msg)
+	}
+	return nil
+}
+
+// audioExt is the file extension for a synthesized audio MIME type.
+func audioExt(mimeType string) string {
+	switch mimeType {
+	case "audio/mpeg":
+		return ".mp3"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	case "audio/ogg":
+		return ".ogg"
+	case "text/plain":
+		return ".txt"
+	}
+	return ".audio"
+}
+
+var (
+	speechCodeBlockRe = regexp.MustCompile("(?s)```.*?```")
+	speechLinkRe      = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	speechURLRe       = regexp.MustCompile(`https?://\S*[^\s.,;:!?)]`)
+	speechMarkupRe    = regexp.MustCompile("(?m)^\\s*(#{1,6}\\s+|>\\s?|[-*+]\\s+|\\|)|[*_`~|]")
+	speechSpaceRe     = regexp.MustCompile(`\s+`)
+)
+
+// SpeakableText turns a Markdown result into text worth reading out: code
+// blocks, link targets and markup are dropped, and the text is cut to at
+// most maxChars at a sentence end where possible.
+func SpeakableText(md string, maxChars int) string {
+	text := speechCodeBlockRe.ReplaceAllString(md, " (code omitted) ")
+	text = speechLinkRe.ReplaceAllString(text, "$1")
+	text = speechURLRe.ReplaceAllString(text, "a link")
+	text = speechMarkupRe.ReplaceAllString(text, "")
+	text = strings.TrimSpace(speechSpaceRe.ReplaceAllString(text, " "))
+	if maxChars <= 0 || len(text) <= maxChars {
+		return text
+	}
+	n := maxChars
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n-- // don't split a rune
+	}
+	cut := text[:n]
+	if i := strings.LastIndexAny(cut, ".!?"); i > maxChars/2 {
+		cut = cut[:i+1]
+	}
+	return strings.TrimSpace(cut) + " The rest is in the written answer."
+}
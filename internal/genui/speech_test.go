@@ -0,0 +1,73 @@
+package genui
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/pipeline"
+)
+
+func TestSpeakableText(t *testing.T) {
+	md := "## Result\n\n**Done.** See [the report](https://example.com/r) or https://example.com/x.\n\n" +
+		"```go\nfmt.Println(1)\n```\n\n- first\n- second"
+	want := "Result Done. See the report or a link. (code omitted) first second"
+	if got := SpeakableText(md, 0); got != want {
+		t.Errorf("SpeakableText = %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("This is a sentence. ", 20)
+	got := SpeakableText(long, 100)
+	if !strings.HasSuffix(got, "sentence. The rest is in the written answer.") || len(got) > 100+len(" The rest is in the written answer.") {
+		t.Errorf("truncated = %q", got)
+	}
+	if got := SpeakableText(strings.Repeat("é", 30), 9); !strings.HasPrefix(got, "éééé ") {
+		t.Errorf("rune cut = %q", got)
+	}
+}
+
+func TestSpeechOutput(t *testing.T) {
+	if _, err := NewSpeechOutput(SpeechOutputConfig{TTS: brain.FakeSpeech{}}); err == nil {
+		t.Error("no outbox or player: expected an error")
+	}
+
+	dir := t.TempDir()
+	out, err := NewSpeechOutput(SpeechOutputConfig{TTS: brain.FakeSpeech{}, OutboxDir: dir, Keep: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"t1", "t2", "t3"} {
+		path, err := out.Speak(context.Background(), pipeline.RunResult{TaskID: id, Result: "**Hello** " + id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != filepath.Join(dir, id+".txt") {
+			t.Errorf("path = %q", path)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "t3.txt")); string(data) != "Hello t3" {
+		t.Errorf("audio = %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("outbox holds %d files, want 2", len(entries))
+	}
+	if path, err := out.Speak(context.Background(), pipeline.RunResult{TaskID: "t4", Result: "  "}); err != nil || path != "" {
+		t.Errorf("empty result: %q, %v", path, err)
+	}
+
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("no true command")
+	}
+	player, _ := NewSpeechOutput(SpeechOutputConfig{TTS: brain.FakeSpeech{}, Player: "true"})
+	if path, err := player.Speak(context.Background(), pipeline.RunResult{TaskID: "t5", Result: "Hi"}); err != nil || path != "" {
+		t.Errorf("play only: %q, %v", path, err)
+	}
+	failing, _ := NewSpeechOutput(SpeechOutputConfig{TTS: brain.FakeSpeech{}, Player: "false"})
+	if _, err := failing.Speak(context.Background(), pipeline.RunResult{TaskID: "t6", Result: "Hi"}); err == nil {
+		t.Error("failing player: expected an error")
+	}
+}
@@ -40,7 +40,7 @@ func (g *UIGenerator) StreamGenerate(ctx context.Context, result pipeline.RunRes
 
 	format := g.selectFormat(caps)
 	prompt := g.buildPrompt(result, format, caps, nil, nil)
-	model := g.router.Select("simple", 100.0)
+	model := g.uiModel()
 
 	chunks := make(chan UIChunk, 16)
 
@@ -5,6 +5,8 @@ package genui
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/overhuman/overhuman/internal/brain"
 	"github.com/overhuman/overhuman/internal/pipeline"
@@ -29,7 +31,7 @@ type GeneratedUI struct {
 	Meta    UIMeta            `json:"meta,omitempty"`
 	Thought *ThoughtLog       `json:"thought,omitempty"` // pipeline thought chain
 	Sandbox bool              `json:"sandbox,omitempty"` // wrap in sandboxed iframe
-	Source  string            `json:"source,omitempty"`  // "fastpath" or "llm"
+	Source  string            `json:"source,omitempty"`  // "fastpath", "llm" or "plain"
 }
 
 // GeneratedAction is an interactive action embedded in generated UI.
@@ -39,11 +41,87 @@ type GeneratedAction struct {
 	Callback string `json:"callback"` // callback ID for daemon
 }
 
-// UIMeta holds metadata about the generated UI.
+// UIMeta holds metadata about the generated UI. The cost, latency, model
+// and quality fields describe the pipeline run behind it, so clients can
+// show them without parsing Summary.
 type UIMeta struct {
 	Title     string `json:"title,omitempty"`
 	Summary   string `json:"summary,omitempty"` // TL;DR for progressive disclosure
 	Streaming bool   `json:"streaming,omitempty"`
+
+	CostUSD   float64 `json:"cost_usd,omitempty"`   // pipeline run cost
+	LatencyMs int64   `json:"latency_ms,omitempty"` // pipeline run wall time
+	Model     string  `json:"model,omitempty"`      // model the execution stage ran on
+	Quality   float64 `json:"quality,omitempty"`    // review score, 0-1
+
+	// Sources are the long-term memories the answer was based on, shown
+	// as a collapsible "based on" section.
+	Sources []pipeline.MemorySource `json:"sources,omitempty"`
+
+	// Downloads are the files the run produced, offered as download links.
+	Downloads []Download `json:"downloads,omitempty"`
+}
+
+// Download is a file produced by a run.
+type Download struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Type string `json:"type,omitempty"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// runMeta returns the metadata of a UI generated for result.
+func runMeta(result pipeline.RunResult, thought *ThoughtLog) UIMeta {
+	meta := UIMeta{
+		CostUSD:   result.CostUSD,
+		LatencyMs: result.ElapsedMs,
+		Model:     result.Model,
+		Quality:   result.QualityScore,
+		Sources:   result.Sources,
+	}
+	if thought != nil {
+		meta.Summary = fmt.Sprintf("Completed in %dms", thought.TotalMs)
+	}
+	return meta
+}
+
+// Footer renders the run metadata as one line, e.g. "claude-sonnet-4 ·
+// $0.0123 · 1.2s · quality 85%". Fields that are unset are left out; the
+// kiosk formats its footer the same way.
+func (m UIMeta) Footer() string {
+	var parts []string
+	if m.Model != "" {
+		parts = append(parts, m.Model)
+	}
+	if m.CostUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.4f", m.CostUSD))
+	}
+	if m.LatencyMs > 0 {
+		parts = append(parts, fmt.Sprintf("%.1fs", float64(m.LatencyMs)/1000))
+	}
+	if m.Quality > 0 {
+		parts = append(parts, fmt.Sprintf("quality %.0f%%", m.Quality*100))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// BasedOn renders the cited memories, one line each, e.g. "2026-03-01 ·
+// telegram · Anna prefers morning meetings (ltm_t42)". The ID is what
+// `overhuman memory forget` takes.
+func (m UIMeta) BasedOn() []string {
+	lines := make([]string, 0, len(m.Sources))
+	for _, s := range m.Sources {
+		parts := []string{s.Date.Format("2006-01-02")}
+		if s.Channel != "" {
+			parts = append(parts, strings.ToLower(s.Channel))
+		}
+		if s.Topic != "" {
+			parts = append(parts, "{"+s.Topic+"}")
+		}
+		parts = append(parts, s.Summary)
+		lines = append(lines, strings.Join(parts, " · ")+" ("+s.ID+")")
+	}
+	return lines
 }
 
 // DeviceCapabilities describes what the rendering device supports.
@@ -57,6 +135,14 @@ type DeviceCapabilities struct {
 	SVG         bool     `json:"svg"`
 	Animation   bool     `json:"animation"`
 	TouchScreen bool     `json:"touch_screen"`
+
+	// ReducedMotion is set when the client prefers reduced motion
+	// (prefers-reduced-motion: reduce). Implies Animation == false.
+	ReducedMotion bool `json:"reduced_motion,omitempty"`
+
+	// Timezone (IANA) and Locale (BCP 47) for rendering dates and numbers.
+	Timezone string `json:"timezone,omitempty"`
+	Locale   string `json:"locale,omitempty"`
 }
 
 // CLICapabilities returns terminal device capabilities.
@@ -137,6 +223,11 @@ type UIReflection struct {
 	Dismissed    bool     `json:"dismissed"`
 }
 
+// DefaultUIQuotaFloor is the rate-limit headroom (0..1) of the UI provider
+// below which the LLM pass is skipped and results are rendered plainly,
+// leaving the remaining quota to the tasks themselves.
+const DefaultUIQuotaFloor = 0.2
+
 // UIGenerator generates UI code from pipeline results using LLM.
 // When fastPathEnabled is true (default), common content patterns are
 // rendered declaratively without an LLM call (hybrid Level 2+3).
@@ -144,11 +235,70 @@ type UIGenerator struct {
 	llm             brain.LLMProvider
 	router          *brain.ModelRouter
 	fastPathEnabled bool
+
+	// pinned is set when UI generation has its own model; model empty
+	// then means the pinned provider's default.
+	pinned     bool
+	model      string
+	quotaFloor float64
 }
 
 // NewUIGenerator creates a new UIGenerator with fast path enabled.
 func NewUIGenerator(llm brain.LLMProvider, router *brain.ModelRouter) *UIGenerator {
-	return &UIGenerator{llm: llm, router: router, fastPathEnabled: true}
+	return &UIGenerator{llm: llm, router: router, fastPathEnabled: true, quotaFloor: DefaultUIQuotaFloor}
+}
+
+// Pin dedicates a provider and model to UI generation, so the cosmetic
+// pass never competes with the main task for tokens or rate limits. A nil
+// llm keeps the main provider; an empty model uses the provider's default.
+func (g *UIGenerator) Pin(llm brain.LLMProvider, model string) {
+	if llm != nil {
+		g.llm = llm
+	}
+	g.pinned, g.model = true, model
+}
+
+// SetQuotaFloor sets the headroom below which the LLM pass is skipped;
+// 0 never skips it.
+func (g *UIGenerator) SetQuotaFloor(floor float64) { g.quotaFloor = floor }
+
+// uiModel returns the model for a UI generation request.
+func (g *UIGenerator) uiModel() string {
+	if g.pinned {
+		return g.model
+	}
+	return g.router.Select("simple", 100.0)
+}
+
+// quotaLow reports whether the UI provider's rate limits are too close to
+// exhausted to spend on UI.
+func (g *UIGenerator) quotaLow() bool {
+	qr, ok := g.llm.(brain.QuotaReporter)
+	if !ok || g.quotaFloor <= 0 {
+		return false
+	}
+	q, ok := qr.Quota()
+	return ok && q.Headroom(time.Now()) < g.quotaFloor
+}
+
+// plainUI renders a result without the LLM, for when quota is low or
+// there is no answer to lay out.
+func (g *UIGenerator) plainUI(result pipeline.RunResult, format UIFormat, thought *ThoughtLog) *GeneratedUI {
+	if format == FormatReact {
+		format = FormatHTML
+	}
+	code := renderEmpty(format)
+	if text := strings.TrimSpace(result.Result); text != "" {
+		code = renderContent(text, ContentShort, format)
+	}
+	return &GeneratedUI{
+		TaskID:  result.TaskID,
+		Format:  format,
+		Code:    code,
+		Meta:    runMeta(result, thought),
+		Thought: thought,
+		Source:  "plain",
+	}
 }
 
 // Generate creates a GeneratedUI from a pipeline result.
@@ -156,6 +306,11 @@ func NewUIGenerator(llm brain.LLMProvider, router *brain.ModelRouter) *UIGenerat
 func (g *UIGenerator) Generate(ctx context.Context, result pipeline.RunResult, caps DeviceCapabilities) (*GeneratedUI, error) {
 	format := g.selectFormat(caps)
 
+	// A declined request has no answer to lay out; say so as it is.
+	if result.Declined {
+		return g.plainUI(result, format, nil), nil
+	}
+
 	// Level 2: fast declarative path.
 	if g.fastPathEnabled {
 		if fp := TryFastPath(result.Result, format); fp.Matched {
@@ -163,12 +318,16 @@ func (g *UIGenerator) Generate(ctx context.Context, result pipeline.RunResult, c
 				TaskID: result.TaskID,
 				Format: format,
 				Code:   fp.Code,
+				Meta:   runMeta(result, nil),
 				Source: "fastpath",
 			}, nil
 		}
 	}
 
 	// Level 3: LLM generation.
+	if g.quotaLow() {
+		return g.plainUI(result, format, nil), nil
+	}
 	prompt := g.buildPrompt(result, format, caps, nil, nil)
 	code, err := g.generateWithRetry(ctx, prompt, format, 2)
 	if err != nil {
@@ -178,6 +337,7 @@ func (g *UIGenerator) Generate(ctx context.Context, result pipeline.RunResult, c
 		TaskID: result.TaskID,
 		Format: format,
 		Code:   code,
+		Meta:   runMeta(result, nil),
 		Source: "llm",
 	}, nil
 }
@@ -187,6 +347,11 @@ func (g *UIGenerator) Generate(ctx context.Context, result pipeline.RunResult, c
 func (g *UIGenerator) GenerateWithThought(ctx context.Context, result pipeline.RunResult, caps DeviceCapabilities, thought *ThoughtLog, hints []string) (*GeneratedUI, error) {
 	format := g.selectFormat(caps)
 
+	// A declined request has no answer to lay out; say so as it is.
+	if result.Declined {
+		return g.plainUI(result, format, thought), nil
+	}
+
 	// Level 2: fast declarative path.
 	if g.fastPathEnabled {
 		if fp := TryFastPath(result.Result, format); fp.Matched {
@@ -194,37 +359,34 @@ func (g *UIGenerator) GenerateWithThought(ctx context.Context, result pipeline.R
 			if thought != nil && len(thought.Stages) > 0 && format == FormatANSI {
 				code += "\n" + FormatThoughtLogANSI(thought)
 			}
-			ui := &GeneratedUI{
+			return &GeneratedUI{
 				TaskID:  result.TaskID,
 				Format:  format,
 				Code:    code,
+				Meta:    runMeta(result, thought),
 				Thought: thought,
 				Source:  "fastpath",
-			}
-			if thought != nil {
-				ui.Meta.Summary = fmt.Sprintf("Completed in %dms", thought.TotalMs)
-			}
-			return ui, nil
+			}, nil
 		}
 	}
 
 	// Level 3: LLM generation.
+	if g.quotaLow() {
+		return g.plainUI(result, format, thought), nil
+	}
 	prompt := g.buildPrompt(result, format, caps, thought, hints)
 	code, err := g.generateWithRetry(ctx, prompt, format, 2)
 	if err != nil {
 		return nil, err
 	}
-	ui := &GeneratedUI{
+	return &GeneratedUI{
 		TaskID:  result.TaskID,
 		Format:  format,
 		Code:    code,
+		Meta:    runMeta(result, thought),
 		Thought: thought,
 		Source:  "llm",
-	}
-	if thought != nil {
-		ui.Meta.Summary = fmt.Sprintf("Completed in %dms", thought.TotalMs)
-	}
-	return ui, nil
+	}, nil
 }
 
 // selectFormat picks the best format for the device.
@@ -267,6 +429,18 @@ func (g *UIGenerator) buildPrompt(result pipeline.RunResult, format UIFormat, ca
 	}
 
 	userContent += fmt.Sprintf("\n\nDevice: %s, %dx%d", format, caps.Width, caps.Height)
+	if caps.TouchScreen {
+		userContent += "\nDevice has a touch screen: use large tap targets (min 44px)."
+	}
+	if caps.ReducedMotion {
+		userContent += "\nUser prefers reduced motion: no animations or transitions."
+	}
+	if caps.Locale != "" || caps.Timezone != "" {
+		userContent += fmt.Sprintf("\nFormat dates, times and numbers for locale %q in timezone %q.", caps.Locale, caps.Timezone)
+	}
+	if caps.Format != FormatANSI && caps.ColorDepth > 0 && caps.ColorDepth <= 256 {
+		userContent += fmt.Sprintf("\nLimited color depth (%d colors): use high-contrast, flat colors.", caps.ColorDepth)
+	}
 
 	return []brain.Message{
 		{Role: "system", Content: sysPrompt},
@@ -289,10 +463,9 @@ func (g *UIGenerator) generateWithRetry(ctx context.Context, prompt []brain.Mess
 			})
 		}
 
-		model := g.router.Select("simple", 100.0)
 		resp, err := g.llm.Complete(ctx, brain.LLMRequest{
 			Messages: messages,
-			Model:    model,
+			Model:    g.uiModel(),
 		})
 		if err != nil {
 			return "", err
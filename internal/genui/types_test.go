@@ -249,3 +249,16 @@ func TestUIReflection_MarshalJSON(t *testing.T) {
 		t.Error("Dismissed: expected false")
 	}
 }
+
+func TestUIMeta_Footer(t *testing.T) {
+	m := UIMeta{Model: "claude-sonnet-4", CostUSD: 0.0123, LatencyMs: 1234, Quality: 0.85}
+	if got, want := m.Footer(), "claude-sonnet-4 · $0.0123 · 1.2s · quality 85%"; got != want {
+		t.Errorf("Footer = %q, want %q", got, want)
+	}
+	if got := (UIMeta{LatencyMs: 40}).Footer(); got != "0.0s" {
+		t.Errorf("latency only = %q", got)
+	}
+	if got := (UIMeta{Summary: "Completed in 42ms"}).Footer(); got != "" {
+		t.Errorf("no run data = %q, want empty", got)
+	}
+}
@@ -167,6 +167,29 @@ func TestBuildPrompt_IncludesDeviceDimensions(t *testing.T) {
 	}
 }
 
+func TestBuildPrompt_IncludesTouchAndReducedMotion(t *testing.T) {
+	gen := NewUIGenerator(nil, brain.NewModelRouter())
+	gen.fastPathEnabled = false
+	result := genSimpleResult("phone", 0.8)
+	caps := WSClientCaps{Width: 390, Height: 844, Touch: true, ReducedMotion: true}.Capabilities()
+
+	userMsg := gen.buildPrompt(result, FormatHTML, caps, nil, nil)[1].Content
+	if !strings.Contains(userMsg, "390x844") {
+		t.Errorf("user prompt should contain 390x844, got: %s", userMsg)
+	}
+	if !strings.Contains(userMsg, "touch screen") {
+		t.Error("user prompt should mention touch screen")
+	}
+	if !strings.Contains(userMsg, "reduced motion") {
+		t.Error("user prompt should mention reduced motion")
+	}
+
+	desktop := gen.buildPrompt(result, FormatHTML, WebCapabilities(1920, 1080), nil, nil)[1].Content
+	if strings.Contains(desktop, "touch screen") || strings.Contains(desktop, "reduced motion") {
+		t.Error("desktop prompt should not carry touch/motion hints")
+	}
+}
+
 func TestBuildPrompt_ReactSystemPrompt(t *testing.T) {
 	gen := NewUIGenerator(nil, brain.NewModelRouter())
 	gen.fastPathEnabled = false
@@ -16,6 +16,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
+	"github.com/overhuman/overhuman/internal/security"
 )
 
 const (
@@ -28,6 +31,9 @@ const (
 	wsPingInterval  = 30 * time.Second
 )
 
+// wsLastUIOwners bounds how many senders' last UI is kept for reconnect.
+const wsLastUIOwners = 100
+
 // WSConn represents a single WebSocket connection.
 type WSConn struct {
 	conn   net.Conn
@@ -35,18 +41,27 @@ type WSConn struct {
 	mu     sync.Mutex
 	closed bool
 	id     string
+	caps   *DeviceCapabilities // reported via hello/input; nil until known
+	sub    *WSSubscription     // from hello; nil = every broadcast
+
+	// principal is who authenticated the upgrade request behind the team
+	// access control (nil without one).
+	principal *security.Principal
 }
 
 // WSServer is a WebSocket server for delivering UI to web clients.
 type WSServer struct {
 	mu       sync.RWMutex
 	clients  map[string]*WSConn
-	lastUI   *WSMessage // cached last UI for reconnect
+	lastUI   map[string]*WSMessage // cached last UI for reconnect, by owner ("" = latest of all)
 	onMsg    func(connID string, msg *WSMessage)
 	addr     string
 	ctx      context.Context
 	srv      *http.Server
 	listener net.Listener
+
+	// middleware, if set, wraps the standalone server's handler.
+	middleware func(http.Handler) http.Handler
 }
 
 // NewWSServer creates a new WebSocket server.
@@ -54,6 +69,7 @@ func NewWSServer(addr string) *WSServer {
 	return &WSServer{
 		addr:    addr,
 		clients: make(map[string]*WSConn),
+		lastUI:  make(map[string]*WSMessage),
 	}
 }
 
@@ -64,6 +80,14 @@ func (s *WSServer) OnMessage(fn func(connID string, msg *WSMessage)) {
 	s.onMsg = fn
 }
 
+// SetMiddleware wraps the standalone server's handler (see Start) in mw,
+// e.g. the team access control.
+func (s *WSServer) SetMiddleware(mw func(http.Handler) http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middleware = mw
+}
+
 // RegisterRoutes registers the /ws handler on an external ServeMux.
 // This allows the WebSocket endpoint to share a port with other HTTP handlers
 // (e.g., the kiosk server), which avoids cross-port WebSocket issues.
@@ -75,6 +99,7 @@ func (s *WSServer) RegisterRoutes(mux *http.ServeMux, ctx context.Context) {
 	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		s.handleUpgrade(w, r, ctx)
 	})
+	mux.HandleFunc("GET /ws/schema", schemaHandler)
 }
 
 // Start launches the WebSocket server on its own port. Blocks until ctx is cancelled.
@@ -83,14 +108,18 @@ func (s *WSServer) Start(ctx context.Context) error {
 	s.RegisterRoutes(mux, ctx)
 
 	s.mu.Lock()
+	var handler http.Handler = mux
+	if s.middleware != nil {
+		handler = s.middleware(mux)
+	}
 	s.srv = &http.Server{
 		Addr:              s.addr,
-		Handler:           mux,
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 	s.mu.Unlock()
 
-	ln, err := net.Listen("tcp", s.addr)
+	ln, err := netaddr.Listen(s.addr)
 	if err != nil {
 		return fmt.Errorf("ws server: listen: %w", err)
 	}
@@ -117,13 +146,33 @@ func (s *WSServer) Addr() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	if s.listener != nil {
-		return s.listener.Addr().String()
+		return netaddr.String(s.listener)
 	}
 	return s.addr
 }
 
-// Broadcast sends a message to all connected clients.
+// Broadcast sends a message to all connected clients whose subscription
+// matches it, taking the task from the payload's task_id. Messages that do
+// not match their schema are not sent.
 func (s *WSServer) Broadcast(msg *WSMessage) error {
+	return s.Publish(msg, WSTopic{})
+}
+
+// Publish is Broadcast with the topic known to the caller, e.g. the channel
+// a result came in on. An empty topic.TaskID is taken from the payload.
+func (s *WSServer) Publish(msg *WSMessage, topic WSTopic) error {
+	if topic.TaskID == "" {
+		var p struct {
+			TaskID string `json:"task_id"`
+		}
+		if json.Unmarshal(msg.Payload, &p) == nil {
+			topic.TaskID = p.TaskID
+		}
+	}
+	if err := ValidateWSMessage(msg); err != nil {
+		log.Printf("[ws] not broadcasting %s: %v", msg.Type, err)
+		return err
+	}
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return err
@@ -137,6 +186,9 @@ func (s *WSServer) Broadcast(msg *WSMessage) error {
 	s.mu.RUnlock()
 
 	for _, c := range clients {
+		if !c.sees(topic.Owner) || !c.subscribed(msg.Type, topic) {
+			continue
+		}
 		if writeErr := c.writeText(data); writeErr != nil {
 			log.Printf("[ws] broadcast write error for %s: %v", c.id, writeErr)
 		}
@@ -146,14 +198,82 @@ func (s *WSServer) Broadcast(msg *WSMessage) error {
 
 // BroadcastUI sends a GeneratedUI to all connected clients and caches it.
 func (s *WSServer) BroadcastUI(ui *GeneratedUI) error {
+	return s.PublishUI(ui, "", "")
+}
+
+// PublishUI is BroadcastUI for a result that came in on channel from
+// owner, so clients subscribed to that channel receive it and, behind the
+// team access control, only owner and admins do.
+func (s *WSServer) PublishUI(ui *GeneratedUI, channel, owner string) error {
 	msg, err := NewUIFullMessage(ui)
 	if err != nil {
 		return err
 	}
 	s.mu.Lock()
-	s.lastUI = msg
+	s.lastUI[""] = msg
+	if owner != "" {
+		if _, ok := s.lastUI[owner]; !ok && len(s.lastUI) > wsLastUIOwners {
+			for o := range s.lastUI {
+				if o != "" {
+					delete(s.lastUI, o)
+					break
+				}
+			}
+		}
+		s.lastUI[owner] = msg
+	}
 	s.mu.Unlock()
-	return s.Broadcast(msg)
+	return s.Publish(msg, WSTopic{TaskID: ui.TaskID, Channel: channel, Owner: owner})
+}
+
+// SendTo sends a message to one client, e.g. the answer to its action,
+// regardless of its subscription.
+func (s *WSServer) SendTo(connID string, msg *WSMessage) error {
+	if err := ValidateWSMessage(msg); err != nil {
+		log.Printf("[ws] not sending %s: %v", msg.Type, err)
+		return err
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.mu.RLock()
+	c := s.clients[connID]
+	s.mu.RUnlock()
+	if c == nil {
+		return fmt.Errorf("ws client %s not connected", connID)
+	}
+	return c.writeText(data)
+}
+
+// ClientCapabilities returns the device capabilities reported by a client
+// in its hello/input messages. ok is false if the client is unknown or has
+// not reported any.
+func (s *WSServer) ClientCapabilities(connID string) (caps DeviceCapabilities, ok bool) {
+	s.mu.RLock()
+	c := s.clients[connID]
+	s.mu.RUnlock()
+	if c == nil {
+		return DeviceCapabilities{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.caps == nil {
+		return DeviceCapabilities{}, false
+	}
+	return *c.caps, true
+}
+
+// ClientPrincipal returns who authenticated a client's connection; ok is
+// false if the client is unknown or no access control is in place.
+func (s *WSServer) ClientPrincipal(connID string) (p security.Principal, ok bool) {
+	s.mu.RLock()
+	c := s.clients[connID]
+	s.mu.RUnlock()
+	if c == nil || c.principal == nil {
+		return security.Principal{}, false
+	}
+	return *c.principal, true
 }
 
 // ClientCount returns the number of connected clients.
@@ -228,10 +348,16 @@ func (s *WSServer) handleUpgrade(w http.ResponseWriter, r *http.Request, ctx con
 		rw:   bufrw,
 		id:   connID,
 	}
+	if p, ok := security.PrincipalFrom(r.Context()); ok {
+		wsConn.principal = &p
+	}
 
 	s.mu.Lock()
 	s.clients[connID] = wsConn
-	lastUI := s.lastUI
+	lastUI := s.lastUI[""]
+	if p := wsConn.principal; p != nil && !p.Role.Allows(security.RoleAdmin) {
+		lastUI = s.lastUI[p.Name]
+	}
 	s.mu.Unlock()
 
 	log.Printf("[ws] client connected: %s", connID)
@@ -274,8 +400,12 @@ func (s *WSServer) readLoop(c *WSConn, ctx context.Context) {
 		switch opcode {
 		case wsOpText:
 			msg, parseErr := ParseWSMessage(payload)
+			if parseErr == nil {
+				parseErr = ValidateClientMessage(msg)
+			}
 			if parseErr != nil {
-				log.Printf("[ws] parse error from %s: %v", c.id, parseErr)
+				log.Printf("[ws] rejected message from %s: %v", c.id, parseErr)
+				c.writeError(http.StatusBadRequest, parseErr.Error())
 				continue
 			}
 			s.handleMessage(c, msg)
@@ -297,7 +427,32 @@ func (s *WSServer) handleMessage(c *WSConn, msg *WSMessage) {
 		data, _ := json.Marshal(pong)
 		c.writeText(data)
 
+	case WSMsgHello:
+		hello, err := ParseHelloPayload(msg)
+		if err != nil {
+			log.Printf("[ws] bad hello from %s: %v", c.id, err)
+			return
+		}
+		if hello.Caps != nil {
+			c.setCaps(hello.Caps.Capabilities())
+		}
+		c.mu.Lock()
+		c.sub = hello.Subscribe
+		c.mu.Unlock()
+
 	default:
+		// Viewers only watch: tasks, actions and feedback need a member.
+		if c.principal != nil && !c.principal.Role.Allows(security.RoleMember) {
+			c.writeError(http.StatusForbidden, "read-only access: "+string(msg.Type)+" needs the member role")
+			return
+		}
+		// Inputs may carry refreshed capabilities (resize, rotation).
+		if msg.Type == WSMsgInput {
+			if in, err := ParseInputPayload(msg); err == nil && in.Caps != nil {
+				c.setCaps(in.Caps.Capabilities())
+			}
+		}
+
 		s.mu.RLock()
 		fn := s.onMsg
 		s.mu.RUnlock()
@@ -307,6 +462,42 @@ func (s *WSServer) handleMessage(c *WSConn, msg *WSMessage) {
 	}
 }
 
+// writeError replies to this client only with a WSMsgError.
+func (c *WSConn) writeError(code int, message string) error {
+	msg, err := NewErrorMessage(code, message)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.writeText(data)
+}
+
+// setCaps records the client's device capabilities.
+func (c *WSConn) setCaps(caps DeviceCapabilities) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caps = &caps
+}
+
+// sees reports whether the client may receive a broadcast about a task of
+// owner: every client of the single-user daemon does, team members those
+// of their own tasks and admins all.
+func (c *WSConn) sees(owner string) bool {
+	p := c.principal
+	return owner == "" || p == nil || p.Role.Allows(security.RoleAdmin) || p.Name == owner
+}
+
+// subscribed reports whether the client wants a broadcast of type t about
+// topic.
+func (c *WSConn) subscribed(t WSMessageType, topic WSTopic) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sub.Matches(t, topic)
+}
+
 // writeText sends a text frame to the WebSocket connection.
 func (c *WSConn) writeText(data []byte) error {
 	c.mu.Lock()
@@ -3,6 +3,7 @@ package genui
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // WSMessageType defines WebSocket message types.
@@ -16,6 +17,8 @@ const (
 	WSMsgError       WSMessageType = "error"         // Error notification
 	WSMsgPong          WSMessageType = "pong"           // Keepalive response
 	WSMsgPipelineStage WSMessageType = "pipeline_stage" // Real-time pipeline stage progress
+	WSMsgNotice        WSMessageType = "notice"         // Operator notice (e.g. a retired model)
+	WSMsgStatus        WSMessageType = "status"         // One-line progress of a running task
 
 	// Client → Server message types.
 	WSMsgAction     WSMessageType = "action"      // User clicked an action button
@@ -23,11 +26,14 @@ const (
 	WSMsgPing       WSMessageType = "ping"        // Keepalive request
 	WSMsgUIFeedback WSMessageType = "ui_feedback" // UI interaction data for reflection
 	WSMsgCancel     WSMessageType = "cancel"      // Emergency stop
+	WSMsgHello      WSMessageType = "hello"       // Client handshake with device capabilities
 )
 
-// WSMessage is the top-level WebSocket message envelope.
+// WSMessage is the top-level WebSocket message envelope. Version is the
+// protocol version (see WSProtocolVersion); 0 means unversioned.
 type WSMessage struct {
 	Type    WSMessageType   `json:"type"`
+	Version int             `json:"v,omitempty"`
 	Payload json.RawMessage `json:"payload"`
 }
 
@@ -44,6 +50,10 @@ type WSUIFullPayload struct {
 type WSUIStreamPayload struct {
 	Chunk string `json:"chunk"`
 	Done  bool   `json:"done"`
+	// TaskID and Text are set for the plain text of a running execution,
+	// which clients show as a preview until the task's ui_full arrives.
+	TaskID string `json:"task_id,omitempty"`
+	Text   bool   `json:"text,omitempty"`
 }
 
 // WSActionPayload is the payload for WSMsgAction messages (client → server).
@@ -61,8 +71,99 @@ type WSActionResultPayload struct {
 }
 
 // WSInputPayload is the payload for WSMsgInput messages.
+// Caps is optional and updates the connection's capabilities (e.g. after
+// the user rotated the device or resized the window).
 type WSInputPayload struct {
-	Text string `json:"text"`
+	Text string        `json:"text"`
+	Caps *WSClientCaps `json:"caps,omitempty"`
+}
+
+// WSHelloPayload is the payload for WSMsgHello messages (client → server),
+// sent once after connecting.
+type WSHelloPayload struct {
+	Client    string          `json:"client,omitempty"` // e.g. "kiosk"
+	Caps      *WSClientCaps   `json:"caps,omitempty"`
+	Subscribe *WSSubscription `json:"subscribe,omitempty"` // nil = every broadcast
+}
+
+// WSSubscription narrows the broadcasts a client receives. A broadcast is
+// delivered if it matches any listed event type, task or channel, so a
+// wall display can take only briefings and a debugger a single task; an
+// empty subscription receives everything.
+type WSSubscription struct {
+	Events   []WSMessageType `json:"events,omitempty"`   // e.g. "notice", "pipeline_stage"
+	Tasks    []string        `json:"tasks,omitempty"`    // task IDs
+	Channels []string        `json:"channels,omitempty"` // input channels, e.g. "TIMER" for heartbeat briefings
+}
+
+// WSTopic is what a broadcast is about. Empty fields are unknown and match
+// no subscription.
+type WSTopic struct {
+	TaskID  string
+	Channel string
+
+	// Owner is the sender of the task. Behind the team access control,
+	// only they and admins receive it; empty is shared with everyone.
+	Owner string
+}
+
+// Matches reports whether a broadcast of type t about topic is delivered
+// under the subscription. A nil subscription matches everything.
+func (s *WSSubscription) Matches(t WSMessageType, topic WSTopic) bool {
+	if s == nil || len(s.Events)+len(s.Tasks)+len(s.Channels) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == t {
+			return true
+		}
+	}
+	if topic.TaskID != "" {
+		for _, id := range s.Tasks {
+			if id == topic.TaskID {
+				return true
+			}
+		}
+	}
+	if topic.Channel != "" {
+		for _, ch := range s.Channels {
+			if strings.EqualFold(ch, topic.Channel) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WSClientCaps is what a browser client can actually report about itself.
+type WSClientCaps struct {
+	Width         int    `json:"width"`                    // viewport CSS pixels
+	Height        int    `json:"height"`                   // viewport CSS pixels
+	ColorDepth    int    `json:"color_depth,omitempty"`    // bits per pixel (screen.colorDepth)
+	Touch         bool   `json:"touch,omitempty"`          // maxTouchPoints > 0
+	ReducedMotion bool   `json:"reduced_motion,omitempty"` // prefers-reduced-motion: reduce
+	Timezone      string `json:"timezone,omitempty"`       // Intl timeZone, e.g. "Europe/Berlin"
+	Locale        string `json:"locale,omitempty"`         // navigator.language, e.g. "de-DE"
+}
+
+// Capabilities converts client-reported caps into DeviceCapabilities,
+// starting from web defaults. Missing or invalid values keep the defaults.
+func (c WSClientCaps) Capabilities() DeviceCapabilities {
+	caps := WebCapabilities(1280, 800)
+	if c.Width > 0 && c.Height > 0 {
+		caps.Width, caps.Height = c.Width, c.Height
+	}
+	if c.ColorDepth > 0 && c.ColorDepth < 24 {
+		caps.ColorDepth = 1 << c.ColorDepth
+	}
+	caps.TouchScreen = c.Touch
+	if c.ReducedMotion {
+		caps.ReducedMotion = true
+		caps.Animation = false
+	}
+	caps.Timezone = c.Timezone
+	caps.Locale = c.Locale
+	return caps
 }
 
 // WSErrorPayload is the payload for WSMsgError messages.
@@ -71,8 +172,16 @@ type WSErrorPayload struct {
 	Message string `json:"message"`
 }
 
-// WSCancelPayload is the payload for WSMsgCancel messages.
+// WSNoticePayload is the payload for WSMsgNotice messages.
+type WSNoticePayload struct {
+	Level   string `json:"level"` // "info" | "warn"
+	Message string `json:"message"`
+}
+
+// WSCancelPayload is the payload for WSMsgCancel messages. Without a
+// TaskID the tasks started from the sending connection are cancelled.
 type WSCancelPayload struct {
+	TaskID string `json:"task_id,omitempty"`
 	Reason string `json:"reason,omitempty"`
 }
 
@@ -107,6 +216,20 @@ func NewPipelineStageMessage(taskID string, stage int, name, status, summary str
 	})
 }
 
+// WSStatusPayload is the payload for WSMsgStatus messages: a readable
+// line such as "Executing subtask 2/3 on gpt-4o…", shown while the task
+// runs and replaced by its UI.
+type WSStatusPayload struct {
+	TaskID string `json:"task_id"`
+	Stage  int    `json:"stage,omitempty"`
+	Text   string `json:"text"`
+}
+
+// NewStatusMessage creates a WSMsgStatus message.
+func NewStatusMessage(taskID string, stage int, text string) (*WSMessage, error) {
+	return NewWSMessage(WSMsgStatus, WSStatusPayload{TaskID: taskID, Stage: stage, Text: text})
+}
+
 // ParseWSMessage decodes a raw JSON byte slice into a WSMessage.
 func ParseWSMessage(data []byte) (*WSMessage, error) {
 	var msg WSMessage
@@ -130,7 +253,7 @@ func NewWSMessage(msgType WSMessageType, payload interface{}) (*WSMessage, error
 	if err != nil {
 		return nil, fmt.Errorf("encode ws payload: %w", err)
 	}
-	return &WSMessage{Type: msgType, Payload: raw}, nil
+	return &WSMessage{Type: msgType, Version: WSProtocolVersion, Payload: raw}, nil
 }
 
 // NewUIFullMessage creates a WSMsgUIFull message from a GeneratedUI.
@@ -153,6 +276,17 @@ func NewUIStreamMessage(chunk string, done bool) (*WSMessage, error) {
 	})
 }
 
+// NewTextStreamMessage creates a WSMsgUIStream message for a chunk of an
+// execution's text output.
+func NewTextStreamMessage(taskID, chunk string, done bool) (*WSMessage, error) {
+	return NewWSMessage(WSMsgUIStream, WSUIStreamPayload{
+		Chunk:  chunk,
+		Done:   done,
+		TaskID: taskID,
+		Text:   true,
+	})
+}
+
 // NewErrorMessage creates a WSMsgError message.
 func NewErrorMessage(code int, message string) (*WSMessage, error) {
 	return NewWSMessage(WSMsgError, WSErrorPayload{
@@ -161,6 +295,19 @@ func NewErrorMessage(code int, message string) (*WSMessage, error) {
 	})
 }
 
+// NewNoticeMessage creates a WSMsgNotice message.
+func NewNoticeMessage(level, message string) (*WSMessage, error) {
+	return NewWSMessage(WSMsgNotice, WSNoticePayload{
+		Level:   level,
+		Message: message,
+	})
+}
+
+// NewActionResultMessage creates a WSMsgActionResult message.
+func NewActionResultMessage(res WSActionResultPayload) (*WSMessage, error) {
+	return NewWSMessage(WSMsgActionResult, res)
+}
+
 // ParseActionPayload extracts WSActionPayload from a WSMessage.
 func ParseActionPayload(msg *WSMessage) (*WSActionPayload, error) {
 	var p WSActionPayload
@@ -179,6 +326,15 @@ func ParseInputPayload(msg *WSMessage) (*WSInputPayload, error) {
 	return &p, nil
 }
 
+// ParseHelloPayload extracts WSHelloPayload from a WSMessage.
+func ParseHelloPayload(msg *WSMessage) (*WSHelloPayload, error) {
+	var p WSHelloPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return nil, fmt.Errorf("parse hello payload: %w", err)
+	}
+	return &p, nil
+}
+
 // ParseCancelPayload extracts WSCancelPayload from a WSMessage.
 func ParseCancelPayload(msg *WSMessage) (*WSCancelPayload, error) {
 	var p WSCancelPayload
@@ -0,0 +1,332 @@
+package genui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// WSProtocolVersion is the version of the WebSocket message protocol. It is
+// sent in the "v" field of every server message; clients may omit it (treated
+// as the current version) but must not send a newer one.
+const WSProtocolVersion = 1
+
+// WSProtocolError describes a message that does not match its schema. The
+// server replies to it with a WSMsgError instead of dropping the message.
+type WSProtocolError struct {
+	Type   WSMessageType // message type, "" if unknown
+	Field  string        // offending field path, e.g. "payload.text"
+	Reason string
+}
+
+func (e *WSProtocolError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid ")
+	if e.Type != "" {
+		fmt.Fprintf(&b, "%q ", e.Type)
+	}
+	b.WriteString("message")
+	if e.Field != "" {
+		b.WriteString(": " + e.Field)
+	}
+	return b.String() + ": " + e.Reason
+}
+
+// JSON kinds a schema field can require.
+const (
+	wsString  = "string"
+	wsInteger = "integer"
+	wsNumber  = "number" // integers included
+	wsBoolean = "boolean"
+	wsObject  = "object"
+	wsArray   = "array"
+	wsAny     = "" // any JSON value
+)
+
+// wsField is one payload property in a message schema.
+type wsField struct {
+	Name     string
+	Kind     string
+	Required bool
+	NonEmpty bool      // strings: must not be blank
+	Fields   []wsField // objects: nested properties
+}
+
+// wsSchema describes the payload of one message type.
+type wsSchema struct {
+	FromClient bool
+	Fields     []wsField
+}
+
+var wsCapsFields = []wsField{
+	{Name: "width", Kind: wsInteger},
+	{Name: "height", Kind: wsInteger},
+	{Name: "color_depth", Kind: wsInteger},
+	{Name: "touch", Kind: wsBoolean},
+	{Name: "reduced_motion", Kind: wsBoolean},
+	{Name: "timezone", Kind: wsString},
+	{Name: "locale", Kind: wsString},
+}
+
+var wsMetaFields = []wsField{
+	{Name: "title", Kind: wsString},
+	{Name: "summary", Kind: wsString},
+	{Name: "streaming", Kind: wsBoolean},
+	{Name: "cost_usd", Kind: wsNumber},
+	{Name: "latency_ms", Kind: wsInteger},
+	{Name: "model", Kind: wsString},
+	{Name: "quality", Kind: wsNumber},
+	{Name: "downloads", Kind: wsArray},
+}
+
+// wsSchemas is the schema of every message type, mirroring the payload
+// structs in ws_protocol.go. Unknown payload properties are allowed so newer
+// clients can add optional fields.
+var wsSchemas = map[WSMessageType]wsSchema{
+	// Client → Server.
+	WSMsgInput: {FromClient: true, Fields: []wsField{
+		{Name: "text", Kind: wsString, Required: true, NonEmpty: true},
+		{Name: "caps", Kind: wsObject, Fields: wsCapsFields},
+	}},
+	WSMsgAction: {FromClient: true, Fields: []wsField{
+		{Name: "action_id", Kind: wsString, Required: true, NonEmpty: true},
+		{Name: "data", Kind: wsAny},
+	}},
+	WSMsgUIFeedback: {FromClient: true, Fields: []wsField{
+		{Name: "task_id", Kind: wsString, Required: true, NonEmpty: true},
+		{Name: "scrolled", Kind: wsBoolean},
+		{Name: "time_to_action_ms", Kind: wsInteger},
+		{Name: "actions_used", Kind: wsArray},
+		{Name: "dismissed", Kind: wsBoolean},
+	}},
+	WSMsgCancel: {FromClient: true, Fields: []wsField{
+		{Name: "task_id", Kind: wsString},
+		{Name: "reason", Kind: wsString},
+	}},
+	WSMsgHello: {FromClient: true, Fields: []wsField{
+		{Name: "client", Kind: wsString},
+		{Name: "caps", Kind: wsObject, Fields: wsCapsFields},
+		{Name: "subscribe", Kind: wsObject, Fields: []wsField{
+			{Name: "events", Kind: wsArray},
+			{Name: "tasks", Kind: wsArray},
+			{Name: "channels", Kind: wsArray},
+		}},
+	}},
+	WSMsgPing: {FromClient: true},
+
+	// Server → Client.
+	WSMsgUIFull: {Fields: []wsField{
+		{Name: "task_id", Kind: wsString, Required: true},
+		{Name: "html", Kind: wsString, Required: true},
+		{Name: "actions", Kind: wsArray},
+		{Name: "meta", Kind: wsObject, Fields: wsMetaFields},
+		{Name: "thought", Kind: wsObject},
+	}},
+	WSMsgUIStream: {Fields: []wsField{
+		{Name: "chunk", Kind: wsString, Required: true},
+		{Name: "done", Kind: wsBoolean, Required: true},
+		{Name: "task_id", Kind: wsString},
+		{Name: "text", Kind: wsBoolean},
+	}},
+	WSMsgActionResult: {Fields: []wsField{
+		{Name: "action_id", Kind: wsString, Required: true, NonEmpty: true},
+		{Name: "success", Kind: wsBoolean, Required: true},
+		{Name: "result", Kind: wsString},
+		{Name: "error", Kind: wsString},
+	}},
+	WSMsgError: {Fields: []wsField{
+		{Name: "code", Kind: wsInteger, Required: true},
+		{Name: "message", Kind: wsString, Required: true, NonEmpty: true},
+	}},
+	WSMsgPong: {},
+	WSMsgPipelineStage: {Fields: []wsField{
+		{Name: "task_id", Kind: wsString, Required: true},
+		{Name: "stage", Kind: wsInteger, Required: true},
+		{Name: "name", Kind: wsString, Required: true, NonEmpty: true},
+		{Name: "status", Kind: wsString, Required: true, NonEmpty: true},
+		{Name: "summary", Kind: wsString},
+		{Name: "duration_ms", Kind: wsInteger},
+	}},
+	WSMsgNotice: {Fields: []wsField{
+		{Name: "level", Kind: wsString, Required: true, NonEmpty: true},
+		{Name: "message", Kind: wsString, Required: true, NonEmpty: true},
+	}},
+	WSMsgStatus: {Fields: []wsField{
+		{Name: "task_id", Kind: wsString, Required: true},
+		{Name: "stage", Kind: wsInteger},
+		{Name: "text", Kind: wsString, Required: true, NonEmpty: true},
+	}},
+}
+
+// ValidateWSMessage checks msg against the schema of its type and the
+// protocol version. It returns a *WSProtocolError on mismatch.
+func ValidateWSMessage(msg *WSMessage) error {
+	if msg.Version > WSProtocolVersion {
+		return &WSProtocolError{Type: msg.Type, Field: "v",
+			Reason: fmt.Sprintf("unsupported protocol version %d (server speaks %d)", msg.Version, WSProtocolVersion)}
+	}
+	if msg.Version < 0 {
+		return &WSProtocolError{Type: msg.Type, Field: "v", Reason: "must not be negative"}
+	}
+	schema, ok := wsSchemas[msg.Type]
+	if !ok {
+		return &WSProtocolError{Field: "type", Reason: fmt.Sprintf("unknown message type %q", msg.Type)}
+	}
+	payload := bytes.TrimSpace(msg.Payload)
+	if len(payload) == 0 || bytes.Equal(payload, []byte("null")) {
+		payload = []byte("{}")
+	}
+	if err := validateWSObject(payload, schema.Fields, "payload"); err != nil {
+		err.Type = msg.Type
+		return err
+	}
+	return nil
+}
+
+// ValidateClientMessage is ValidateWSMessage for messages received from a
+// client: server-only types are rejected too.
+func ValidateClientMessage(msg *WSMessage) error {
+	if schema, ok := wsSchemas[msg.Type]; ok && !schema.FromClient {
+		return &WSProtocolError{Type: msg.Type, Field: "type", Reason: "not a client message type"}
+	}
+	return ValidateWSMessage(msg)
+}
+
+func validateWSObject(raw json.RawMessage, fields []wsField, path string) *WSProtocolError {
+	var obj map[string]json.RawMessage
+	if jsonKind(raw) != wsObject || json.Unmarshal(raw, &obj) != nil {
+		return &WSProtocolError{Field: path, Reason: "must be an object"}
+	}
+	for _, f := range fields {
+		fpath := path + "." + f.Name
+		v, ok := obj[f.Name]
+		if !ok || (!f.Required && bytes.Equal(bytes.TrimSpace(v), []byte("null"))) {
+			if f.Required {
+				return &WSProtocolError{Field: fpath, Reason: "required"}
+			}
+			continue
+		}
+		if f.Kind == wsAny {
+			continue
+		}
+		if kind := jsonKind(v); kind != f.Kind && !(f.Kind == wsNumber && kind == wsInteger) {
+			return &WSProtocolError{Field: fpath, Reason: fmt.Sprintf("must be %s, got %s", article(f.Kind), kindName(v))}
+		}
+		switch f.Kind {
+		case wsString:
+			var s string
+			json.Unmarshal(v, &s)
+			if f.NonEmpty && strings.TrimSpace(s) == "" {
+				return &WSProtocolError{Field: fpath, Reason: "must not be empty"}
+			}
+		case wsObject:
+			if err := validateWSObject(v, f.Fields, fpath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonKind returns the schema kind of a JSON value. Numbers are "integer"
+// only when they have no fractional part.
+func jsonKind(v json.RawMessage) string {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return "null"
+	}
+	switch v[0] {
+	case '"':
+		return wsString
+	case '{':
+		return wsObject
+	case '[':
+		return wsArray
+	case 't', 'f':
+		return wsBoolean
+	case 'n':
+		return "null"
+	}
+	var n json.Number
+	if json.Unmarshal(v, &n) == nil {
+		if _, err := n.Int64(); err == nil {
+			return wsInteger
+		}
+		return "number"
+	}
+	return "invalid"
+}
+
+func kindName(v json.RawMessage) string {
+	if k := jsonKind(v); k != wsInteger {
+		return k
+	}
+	return "number"
+}
+
+func article(kind string) string {
+	if kind == wsInteger || kind == wsObject || kind == wsArray {
+		return "an " + kind
+	}
+	return "a " + kind
+}
+
+// WSSchemas returns the JSON Schema (draft 2020-12) of every message
+// envelope, keyed by message type, for client authors.
+func WSSchemas() map[WSMessageType]map[string]any {
+	out := make(map[WSMessageType]map[string]any, len(wsSchemas))
+	for t, s := range wsSchemas {
+		direction := "server_to_client"
+		if s.FromClient {
+			direction = "client_to_server"
+		}
+		out[t] = map[string]any{
+			"$schema":   "https://json-schema.org/draft/2020-12/schema",
+			"title":     string(t),
+			"type":      "object",
+			"direction": direction,
+			"required":  []string{"type"},
+			"properties": map[string]any{
+				"type":    map[string]any{"const": string(t)},
+				"v":       map[string]any{"type": "integer", "maximum": WSProtocolVersion},
+				"payload": jsonSchemaObject(s.Fields),
+			},
+		}
+	}
+	return out
+}
+
+func jsonSchemaObject(fields []wsField) map[string]any {
+	props := make(map[string]any, len(fields))
+	required := []string{}
+	for _, f := range fields {
+		p := map[string]any{}
+		if f.Kind != wsAny {
+			p["type"] = f.Kind
+		}
+		if f.NonEmpty {
+			p["minLength"] = 1
+		}
+		if f.Kind == wsObject && len(f.Fields) > 0 {
+			p = jsonSchemaObject(f.Fields)
+		}
+		props[f.Name] = p
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+	sort.Strings(required)
+	return map[string]any{"type": "object", "properties": props, "required": required}
+}
+
+// schemaHandler serves WSSchemas as JSON.
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"version":  WSProtocolVersion,
+		"messages": WSSchemas(),
+	})
+}
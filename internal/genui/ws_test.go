@@ -7,13 +7,18 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/security"
 )
 
 // --- Protocol Tests ---
@@ -94,6 +99,31 @@ func TestNewUIFullMessage(t *testing.T) {
 	}
 }
 
+func TestNewUIFullMessage_RunMeta(t *testing.T) {
+	msg, err := NewUIFullMessage(&GeneratedUI{TaskID: "t1", Code: "<p>hi</p>",
+		Meta: UIMeta{CostUSD: 0.5, LatencyMs: 900, Model: "m", Quality: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateWSMessage(msg); err != nil {
+		t.Errorf("ValidateWSMessage: %v", err)
+	}
+	var raw struct {
+		Meta map[string]any `json:"meta"`
+	}
+	json.Unmarshal(msg.Payload, &raw)
+	for _, k := range []string{"cost_usd", "latency_ms", "model", "quality"} {
+		if _, ok := raw.Meta[k]; !ok {
+			t.Errorf("ui_full meta missing %q: %v", k, raw.Meta)
+		}
+	}
+
+	bad := &WSMessage{Type: WSMsgUIFull, Payload: json.RawMessage(`{"task_id":"t","html":"","meta":{"cost_usd":"$0.50"}}`)}
+	if err := ValidateWSMessage(bad); err == nil || !strings.Contains(err.Error(), "payload.meta.cost_usd") {
+		t.Errorf("string cost: err = %v", err)
+	}
+}
+
 func TestNewUIStreamMessage(t *testing.T) {
 	msg, err := NewUIStreamMessage("<div>chunk", false)
 	if err != nil {
@@ -303,6 +333,27 @@ func TestPipelineStagePayload_AllStages(t *testing.T) {
 	}
 }
 
+func TestNewStatusMessage(t *testing.T) {
+	msg, err := NewStatusMessage("task_st", 5, "Executing subtask 2/3 on gpt-4o…")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != WSMsgStatus {
+		t.Errorf("Type = %q, want status", msg.Type)
+	}
+	if err := ValidateWSMessage(msg); err != nil {
+		t.Errorf("schema: %v", err)
+	}
+	var p WSStatusPayload
+	json.Unmarshal(msg.Payload, &p)
+	if p.TaskID != "task_st" || p.Stage != 5 || p.Text != "Executing subtask 2/3 on gpt-4o…" {
+		t.Errorf("payload = %+v", p)
+	}
+	if err := ValidateClientMessage(msg); err == nil {
+		t.Error("status is a server message")
+	}
+}
+
 // --- Frame I/O Tests ---
 
 func TestWriteReadFrame_Short(t *testing.T) {
@@ -553,6 +604,84 @@ func TestWSServer_OnMessage(t *testing.T) {
 	}
 }
 
+func TestActionRouter_Dispatch(t *testing.T) {
+	r := NewActionRouter()
+	r.Handle("view_logs", func(_ context.Context, req ActionRequest) (string, error) {
+		return "logs of " + req.Field("task_id"), nil
+	})
+	r.Handle("skill", func(_ context.Context, req ActionRequest) (string, error) {
+		if req.Arg == "" {
+			return "", fmt.Errorf("skill id required")
+		}
+		return "running " + req.Arg, nil
+	})
+
+	cases := []struct {
+		id, data string
+		want     WSActionResultPayload
+	}{
+		{"view_logs", `{"task_id":"t1"}`, WSActionResultPayload{ActionID: "view_logs", Success: true, Result: "logs of t1"}},
+		{"skill:sk_1", "", WSActionResultPayload{ActionID: "skill:sk_1", Success: true, Result: "running sk_1"}},
+		{"skill", "", WSActionResultPayload{ActionID: "skill", Error: "skill id required"}},
+		{"deploy", "", WSActionResultPayload{ActionID: "deploy", Error: `unknown action "deploy"`}},
+		{"", "", WSActionResultPayload{Error: "action id required"}},
+	}
+	for _, c := range cases {
+		got := r.Dispatch(context.Background(), ActionRequest{ActionID: c.id, Data: json.RawMessage(c.data)})
+		if got != c.want {
+			t.Errorf("Dispatch(%q) = %+v, want %+v", c.id, got, c.want)
+		}
+	}
+
+	// Callbacks without a handler go to the default one.
+	r.HandleDefault(func(_ context.Context, req ActionRequest) (string, error) {
+		return "follow-up for " + req.Name, nil
+	})
+	if got := r.Dispatch(context.Background(), ActionRequest{ActionID: "deploy"}); !got.Success || got.Result != "follow-up for deploy" {
+		t.Errorf("default handler: %+v", got)
+	}
+}
+
+func TestWSServer_SendTo(t *testing.T) {
+	srv := NewWSServer(":0")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv.OnMessage(func(connID string, msg *WSMessage) {
+		p, err := ParseActionPayload(msg)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		m, _ := NewActionResultMessage(WSActionResultPayload{ActionID: p.ActionID, Success: true, Result: "done"})
+		if err := srv.SendTo(connID, m); err != nil {
+			t.Error(err)
+		}
+	})
+	go srv.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	client := dialWS(t, srv.Addr())
+	defer client.conn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	client.sendMessage(t, WSMessage{Type: WSMsgAction, Payload: json.RawMessage(`{"action_id":"deploy"}`)})
+	msg := client.readMessage(t)
+	if msg.Type != WSMsgActionResult {
+		t.Fatalf("type = %q, want action_result", msg.Type)
+	}
+	var res WSActionResultPayload
+	json.Unmarshal(msg.Payload, &res)
+	if res.ActionID != "deploy" || !res.Success || res.Result != "done" {
+		t.Errorf("result = %+v", res)
+	}
+
+	m, _ := NewActionResultMessage(WSActionResultPayload{ActionID: "deploy", Success: true})
+	if err := srv.SendTo("nobody", m); err == nil {
+		t.Error("SendTo unknown client: want error")
+	}
+}
+
 func TestWSServer_PingPong(t *testing.T) {
 	srv := NewWSServer(":0")
 	ctx, cancel := context.WithCancel(context.Background())
@@ -576,6 +705,419 @@ func TestWSServer_PingPong(t *testing.T) {
 	}
 }
 
+func TestWSClientCaps_Capabilities(t *testing.T) {
+	phone := WSClientCaps{Width: 390, Height: 844, ColorDepth: 24, Touch: true, ReducedMotion: true}.Capabilities()
+	if phone.Width != 390 || phone.Height != 844 {
+		t.Errorf("size = %dx%d, want 390x844", phone.Width, phone.Height)
+	}
+	if !phone.TouchScreen {
+		t.Error("TouchScreen should be true")
+	}
+	if phone.Animation || !phone.ReducedMotion {
+		t.Errorf("Animation=%v ReducedMotion=%v, want false/true", phone.Animation, phone.ReducedMotion)
+	}
+	if phone.ColorDepth != 16777216 {
+		t.Errorf("ColorDepth = %d, want 24-bit default", phone.ColorDepth)
+	}
+
+	low := WSClientCaps{ColorDepth: 8}.Capabilities()
+	if low.Width != 1280 || low.Height != 800 {
+		t.Errorf("missing size should keep defaults, got %dx%d", low.Width, low.Height)
+	}
+	if low.ColorDepth != 256 {
+		t.Errorf("ColorDepth = %d, want 256", low.ColorDepth)
+	}
+	if !low.Animation {
+		t.Error("Animation should stay enabled without reduced motion")
+	}
+}
+
+func TestParseHelloPayload(t *testing.T) {
+	msg := &WSMessage{
+		Type:    WSMsgHello,
+		Payload: json.RawMessage(`{"client":"kiosk","caps":{"width":768,"height":1024,"touch":true}}`),
+	}
+	p, err := ParseHelloPayload(msg)
+	if err != nil {
+		t.Fatalf("ParseHelloPayload: %v", err)
+	}
+	if p.Client != "kiosk" || p.Caps == nil || p.Caps.Width != 768 || !p.Caps.Touch {
+		t.Errorf("hello = %+v caps = %+v", p, p.Caps)
+	}
+}
+
+func TestWSSubscription_Matches(t *testing.T) {
+	sub := &WSSubscription{Events: []WSMessageType{WSMsgNotice}, Tasks: []string{"t1"}, Channels: []string{"TIMER"}}
+	tests := []struct {
+		typ   WSMessageType
+		topic WSTopic
+		want  bool
+	}{
+		{WSMsgNotice, WSTopic{}, true},
+		{WSMsgPipelineStage, WSTopic{TaskID: "t1"}, true},
+		{WSMsgUIFull, WSTopic{TaskID: "t2", Channel: "timer"}, true},
+		{WSMsgUIFull, WSTopic{TaskID: "t2", Channel: "TELEGRAM"}, false},
+		{WSMsgPipelineStage, WSTopic{}, false},
+	}
+	for _, tt := range tests {
+		if got := sub.Matches(tt.typ, tt.topic); got != tt.want {
+			t.Errorf("Matches(%s, %+v) = %v, want %v", tt.typ, tt.topic, got, tt.want)
+		}
+	}
+	var none *WSSubscription
+	if !none.Matches(WSMsgPipelineStage, WSTopic{}) || !(&WSSubscription{}).Matches(WSMsgUIFull, WSTopic{}) {
+		t.Error("an empty subscription should receive everything")
+	}
+}
+
+func TestWSServer_Subscriptions(t *testing.T) {
+	srv := NewWSServer(":0")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go srv.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	wall := dialWS(t, srv.Addr())
+	defer wall.conn.Close()
+	dev := dialWS(t, srv.Addr())
+	defer dev.conn.Close()
+	all := dialWS(t, srv.Addr())
+	defer all.conn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	wall.sendMessage(t, WSMessage{Type: WSMsgHello, Payload: json.RawMessage(`{"subscribe":{"events":["notice"],"channels":["TIMER"]}}`)})
+	dev.sendMessage(t, WSMessage{Type: WSMsgHello, Payload: json.RawMessage(`{"subscribe":{"tasks":["task_dev"]}}`)})
+	time.Sleep(50 * time.Millisecond)
+
+	stage, _ := NewPipelineStageMessage("task_dev", 3, "plan", "started", "", 0)
+	srv.Broadcast(stage)
+	srv.PublishUI(&GeneratedUI{TaskID: "task_chat", Format: FormatHTML, Code: "<p>chat</p>"}, "TELEGRAM", "")
+	srv.PublishUI(&GeneratedUI{TaskID: "task_brief", Format: FormatHTML, Code: "<p>brief</p>"}, "TIMER", "")
+	notice, _ := NewNoticeMessage("info", "weekly report")
+	srv.Broadcast(notice)
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		got = append(got, string(wall.readMessage(t).Type))
+	}
+	if strings.Join(got, ",") != "ui_full,notice" {
+		t.Errorf("wall display got %v, want the briefing and the notice", got)
+	}
+	if msg := dev.readMessage(t); msg.Type != WSMsgPipelineStage {
+		t.Errorf("developer tool got %s, want its task's stage", msg.Type)
+	}
+	for i := 0; i < 4; i++ {
+		all.readMessage(t)
+	}
+
+	// Nothing else was sent to the subscribed clients.
+	srv.PublishUI(&GeneratedUI{TaskID: "task_dev", Format: FormatHTML, Code: "<p>done</p>"}, "API", "")
+	if msg := dev.readMessage(t); msg.Type != WSMsgUIFull {
+		t.Errorf("developer tool got %s, want its task's UI", msg.Type)
+	}
+}
+
+func TestWSServer_ClientCapabilities(t *testing.T) {
+	srv := NewWSServer(":0")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var connID string
+	var mu sync.Mutex
+	srv.OnMessage(func(id string, msg *WSMessage) {
+		mu.Lock()
+		connID = id
+		mu.Unlock()
+	})
+
+	go srv.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	client := dialWS(t, srv.Addr())
+	defer client.conn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	client.sendMessage(t, WSMessage{
+		Type:    WSMsgHello,
+		Payload: json.RawMessage(`{"caps":{"width":390,"height":844,"touch":true}}`),
+	})
+	client.sendMessage(t, WSMessage{
+		Type:    WSMsgInput,
+		Payload: json.RawMessage(`{"text":"hi","caps":{"width":844,"height":390,"touch":true}}`),
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	id := connID
+	mu.Unlock()
+	if id == "" {
+		t.Fatal("input was not forwarded to OnMessage")
+	}
+
+	caps, ok := srv.ClientCapabilities(id)
+	if !ok {
+		t.Fatal("ClientCapabilities should be known after hello")
+	}
+	if caps.Width != 844 || caps.Height != 390 || !caps.TouchScreen {
+		t.Errorf("caps = %+v, want rotated 844x390 touch", caps)
+	}
+
+	if _, ok := srv.ClientCapabilities("ws_unknown"); ok {
+		t.Error("unknown client should report ok=false")
+	}
+}
+
+func TestValidateWSMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    WSMessage
+		field  string // "" = valid
+		client bool
+	}{
+		{"input ok", WSMessage{Type: WSMsgInput, Version: 1, Payload: json.RawMessage(`{"text":"hi","caps":{"width":390}}`)}, "", true},
+		{"unversioned input ok", WSMessage{Type: WSMsgInput, Payload: json.RawMessage(`{"text":"hi"}`)}, "", true},
+		{"unknown fields ok", WSMessage{Type: WSMsgInput, Payload: json.RawMessage(`{"text":"hi","extra":1}`)}, "", true},
+		{"input missing text", WSMessage{Type: WSMsgInput, Payload: json.RawMessage(`{}`)}, "payload.text", true},
+		{"input blank text", WSMessage{Type: WSMsgInput, Payload: json.RawMessage(`{"text":"  "}`)}, "payload.text", true},
+		{"input text wrong type", WSMessage{Type: WSMsgInput, Payload: json.RawMessage(`{"text":42}`)}, "payload.text", true},
+		{"caps width float", WSMessage{Type: WSMsgHello, Payload: json.RawMessage(`{"caps":{"width":1.5}}`)}, "payload.caps.width", true},
+		{"caps not object", WSMessage{Type: WSMsgHello, Payload: json.RawMessage(`{"caps":"big"}`)}, "payload.caps", true},
+		{"subscribe events not array", WSMessage{Type: WSMsgHello, Payload: json.RawMessage(`{"subscribe":{"events":"notice"}}`)}, "payload.subscribe.events", true},
+		{"payload not object", WSMessage{Type: WSMsgAction, Payload: json.RawMessage(`[1]`)}, "payload", true},
+		{"action missing id", WSMessage{Type: WSMsgAction, Payload: json.RawMessage(`{"data":{}}`)}, "payload.action_id", true},
+		{"feedback actions not array", WSMessage{Type: WSMsgUIFeedback, Payload: json.RawMessage(`{"task_id":"t1","actions_used":"x"}`)}, "payload.actions_used", true},
+		{"cancel null payload", WSMessage{Type: WSMsgCancel, Payload: json.RawMessage(`null`)}, "", true},
+		{"ping no payload", WSMessage{Type: WSMsgPing}, "", true},
+		{"future version", WSMessage{Type: WSMsgPing, Version: WSProtocolVersion + 1}, "v", true},
+		{"unknown type", WSMessage{Type: "teleport"}, "type", true},
+		{"server type from client", WSMessage{Type: WSMsgUIFull, Payload: json.RawMessage(`{"task_id":"t","html":""}`)}, "type", true},
+		{"server ui_full ok", WSMessage{Type: WSMsgUIFull, Payload: json.RawMessage(`{"task_id":"t","html":""}`)}, "", false},
+		{"server error missing message", WSMessage{Type: WSMsgError, Payload: json.RawMessage(`{"code":500}`)}, "payload.message", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.client {
+				err = ValidateClientMessage(&tt.msg)
+			} else {
+				err = ValidateWSMessage(&tt.msg)
+			}
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var perr *WSProtocolError
+			if !errors.As(err, &perr) {
+				t.Fatalf("err = %v, want *WSProtocolError", err)
+			}
+			if perr.Field != tt.field {
+				t.Errorf("Field = %q, want %q (%v)", perr.Field, tt.field, err)
+			}
+		})
+	}
+}
+
+func TestWSMessage_BuildersMatchSchema(t *testing.T) {
+	var msgs []*WSMessage
+	add := func(m *WSMessage, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, m)
+	}
+	add(NewUIFullMessage(&GeneratedUI{TaskID: "t1", Code: "<p>hi</p>"}))
+	add(NewUIStreamMessage("chunk", false))
+	add(NewTextStreamMessage("t1", "Knead the dough", true))
+	add(NewErrorMessage(400, "bad"))
+	add(NewNoticeMessage("warn", "model retired"))
+	add(NewPipelineStageMessage("t1", 3, "plan", "started", "", 0))
+	add(NewWSMessage(WSMsgActionResult, WSActionResultPayload{ActionID: "a", Success: true}))
+	add(NewActionResultMessage(WSActionResultPayload{ActionID: "skill:s1", Error: "pipeline busy"}))
+	add(NewWSMessage(WSMsgPong, nil))
+	for _, m := range msgs {
+		if m.Version != WSProtocolVersion {
+			t.Errorf("%s: Version = %d, want %d", m.Type, m.Version, WSProtocolVersion)
+		}
+		if err := ValidateWSMessage(m); err != nil {
+			t.Errorf("%s: %v", m.Type, err)
+		}
+	}
+	if _, ok := WSSchemas()[WSMsgInput]; !ok {
+		t.Error("WSSchemas missing input")
+	}
+	if !strings.Contains(KioskHTML, fmt.Sprintf("protocolVersion: %d,", WSProtocolVersion)) {
+		t.Error("kiosk protocol version out of sync with WSProtocolVersion")
+	}
+}
+
+func TestWSServer_RejectsMalformed(t *testing.T) {
+	srv := NewWSServer(":0")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	var mu sync.Mutex
+	srv.OnMessage(func(string, *WSMessage) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+
+	go srv.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	client := dialWS(t, srv.Addr())
+	defer client.conn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	client.sendMessage(t, WSMessage{Type: WSMsgInput, Payload: json.RawMessage(`{"text":42}`)})
+	msg := client.readMessage(t)
+	if msg.Type != WSMsgError {
+		t.Fatalf("expected error reply, got %q", msg.Type)
+	}
+	var p WSErrorPayload
+	json.Unmarshal(msg.Payload, &p)
+	if p.Code != 400 || !strings.Contains(p.Message, "payload.text") {
+		t.Errorf("error payload = %+v", p)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 0 {
+		t.Errorf("OnMessage called %d times for a malformed message", calls)
+	}
+}
+
+func TestWSServer_ViewerIsReadOnly(t *testing.T) {
+	srv := NewWSServer(":0")
+	srv.SetMiddleware(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			viewer := security.Principal{Name: "wall", Role: security.RoleViewer}
+			h.ServeHTTP(w, r.WithContext(security.WithPrincipal(r.Context(), viewer)))
+		})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	var mu sync.Mutex
+	srv.OnMessage(func(string, *WSMessage) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+
+	go srv.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	client := dialWS(t, srv.Addr())
+	defer client.conn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	client.sendMessage(t, WSMessage{Type: WSMsgInput, Payload: json.RawMessage(`{"text":"hi"}`)})
+	msg := client.readMessage(t)
+	if msg.Type != WSMsgError {
+		t.Fatalf("expected error reply, got %q", msg.Type)
+	}
+	var p WSErrorPayload
+	json.Unmarshal(msg.Payload, &p)
+	if p.Code != 403 {
+		t.Errorf("error payload = %+v, want 403", p)
+	}
+
+	mu.Lock()
+	if calls != 0 {
+		t.Errorf("OnMessage called %d times for a viewer's input", calls)
+	}
+	mu.Unlock()
+
+	srv.mu.RLock()
+	var connID string
+	for id := range srv.clients {
+		connID = id
+	}
+	srv.mu.RUnlock()
+	if who, ok := srv.ClientPrincipal(connID); !ok || who.Name != "wall" {
+		t.Errorf("ClientPrincipal = %+v, %v", who, ok)
+	}
+}
+
+func TestWSServer_PublishToOwner(t *testing.T) {
+	srv := NewWSServer(":0")
+	who := []security.Principal{
+		{Name: "alice", Role: security.RoleMember},
+		{Name: "bob", Role: security.RoleMember},
+		{Name: "root", Role: security.RoleAdmin},
+		{Name: "alice", Role: security.RoleMember}, // alice reconnecting
+	}
+	var n atomic.Int32
+	srv.SetMiddleware(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := who[int(n.Add(1)-1)%len(who)]
+			h.ServeHTTP(w, r.WithContext(security.WithPrincipal(r.Context(), p)))
+		})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	alice := dialWS(t, srv.Addr())
+	defer alice.conn.Close()
+	bob := dialWS(t, srv.Addr())
+	defer bob.conn.Close()
+	root := dialWS(t, srv.Addr())
+	defer root.conn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	taskOf := func(msg *WSMessage) string {
+		var p struct {
+			TaskID string `json:"task_id"`
+		}
+		json.Unmarshal(msg.Payload, &p)
+		return p.TaskID
+	}
+	srv.PublishUI(&GeneratedUI{TaskID: "task_a", Format: FormatHTML, Code: "<p>a</p>"}, "API", "alice")
+	stage, _ := NewPipelineStageMessage("task_b", 3, "plan", "started", "", 0)
+	srv.Publish(stage, WSTopic{TaskID: "task_b", Owner: "bob"})
+	notice, _ := NewNoticeMessage("info", "for everyone")
+	srv.Broadcast(notice)
+
+	if msg := alice.readMessage(t); taskOf(msg) != "task_a" {
+		t.Errorf("alice got %s %s, want her UI", msg.Type, msg.Payload)
+	}
+	if msg := alice.readMessage(t); msg.Type != WSMsgNotice {
+		t.Errorf("alice got %s %s, want the notice after her UI", msg.Type, msg.Payload)
+	}
+	if msg := bob.readMessage(t); taskOf(msg) != "task_b" {
+		t.Errorf("bob got %s %s, want his stage", msg.Type, msg.Payload)
+	}
+	for _, want := range []string{"task_a", "task_b"} {
+		if msg := root.readMessage(t); taskOf(msg) != want {
+			t.Errorf("admin got %s %s, want %s", msg.Type, msg.Payload, want)
+		}
+	}
+
+	// A reconnect replays the caller's own last UI, not the latest of all.
+	srv.PublishUI(&GeneratedUI{TaskID: "task_b2", Format: FormatHTML, Code: "<p>b</p>"}, "API", "bob")
+	again := dialWS(t, srv.Addr())
+	defer again.conn.Close()
+	if msg := again.readMessage(t); taskOf(msg) != "task_a" {
+		t.Errorf("alice's reconnect replayed %s", msg.Payload)
+	}
+}
+
+func TestWSServer_BroadcastRejectsInvalid(t *testing.T) {
+	srv := NewWSServer(":0")
+	err := srv.Broadcast(&WSMessage{Type: WSMsgNotice, Payload: json.RawMessage(`{"level":"warn"}`)})
+	if err == nil {
+		t.Fatal("expected schema error for notice without message")
+	}
+}
+
 // --- Test helpers ---
 
 // testWSClient wraps a WebSocket connection with a buffered reader
@@ -0,0 +1,134 @@
+package goals
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// goalRequest is the JSON body of POST /goals and POST /goals/{id}/priority.
+type goalRequest struct {
+	Description string `json:"description"`
+	Priority    string `json:"priority,omitempty"` // low, normal (default), high, critical
+}
+
+// RegisterRoutes exposes the goals. Goals added here come from the user.
+// Routes: GET /goals (?status=PENDING, ...), POST /goals, GET /goals/{id},
+// POST /goals/{id}/priority, POST /goals/{id}/complete, DELETE /goals/{id}
+func (e *Engine) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /goals", func(w http.ResponseWriter, r *http.Request) {
+		status := GoalStatus(strings.ToUpper(r.URL.Query().Get("status")))
+		writeJSON(w, http.StatusOK, map[string]any{"goals": e.list(status)})
+	})
+	mux.HandleFunc("POST /goals", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeGoalRequest(w, r)
+		if !ok {
+			return
+		}
+		req.Description = strings.TrimSpace(req.Description)
+		if req.Description == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "description required"})
+			return
+		}
+		priority := GoalPriorityNormal
+		if req.Priority != "" {
+			p, err := ParsePriority(req.Priority)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			priority = p
+		}
+		g := e.Add(req.Description, GoalSourceUser, priority)
+		e.writeGoal(w, http.StatusCreated, g.ID)
+	})
+	mux.HandleFunc("GET /goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		e.writeGoal(w, http.StatusOK, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /goals/{id}/priority", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeGoalRequest(w, r)
+		if !ok {
+			return
+		}
+		p, err := ParsePriority(req.Priority)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		id := r.PathValue("id")
+		if e.SetPriority(id, p) != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "goal not found"})
+			return
+		}
+		e.writeGoal(w, http.StatusOK, id)
+	})
+	mux.HandleFunc("POST /goals/{id}/complete", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if e.MarkCompleted(id) != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "goal not found"})
+			return
+		}
+		e.writeGoal(w, http.StatusOK, id)
+	})
+	mux.HandleFunc("DELETE /goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if e.Remove(id) != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "goal not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"deleted": id})
+	})
+}
+
+// list returns copies of the goals with status (all if empty), in the
+// order they are worked on: highest priority first, then oldest.
+func (e *Engine) list(status GoalStatus) []Goal {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	out := []Goal{}
+	for _, g := range e.goals {
+		if status == "" || g.Status == status {
+			out = append(out, *g)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority > out[j].Priority
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// writeGoal writes a copy of the goal id, or 404.
+func (e *Engine) writeGoal(w http.ResponseWriter, status int, id string) {
+	e.mu.RLock()
+	g, ok := e.goals[id]
+	var c Goal
+	if ok {
+		c = *g
+	}
+	e.mu.RUnlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "goal not found"})
+		return
+	}
+	writeJSON(w, status, c)
+}
+
+func decodeGoalRequest(w http.ResponseWriter, r *http.Request) (goalRequest, bool) {
+	var req goalRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return req, false
+	}
+	return req, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,67 @@
+package goals
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEngine_Routes(t *testing.T) {
+	e := New()
+	e.Add("Generate code-skill for pattern abc", GoalSourcePattern, GoalPriorityHigh)
+	mux := http.NewServeMux()
+	e.RegisterRoutes(mux)
+
+	call := func(method, path, body string, out any) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		if out != nil {
+			json.NewDecoder(rec.Body).Decode(out)
+		}
+		return rec.Code
+	}
+
+	var created Goal
+	if code := call(http.MethodPost, "/goals", `{"description":"Tidy the inbox","priority":"low"}`, &created); code != http.StatusCreated {
+		t.Fatalf("POST /goals = %d", code)
+	}
+	if created.Source != GoalSourceUser || created.Priority != GoalPriorityLow || created.Status != GoalStatusPending {
+		t.Errorf("created = %+v", created)
+	}
+	if code := call(http.MethodPost, "/goals", `{"description":" "}`, nil); code != http.StatusBadRequest {
+		t.Errorf("POST without description = %d, want 400", code)
+	}
+
+	var g Goal
+	if code := call(http.MethodPost, "/goals/"+created.ID+"/priority", `{"priority":"critical"}`, &g); code != http.StatusOK || g.Priority != GoalPriorityCritical {
+		t.Errorf("prioritize = %d, %+v", code, g)
+	}
+	if code := call(http.MethodPost, "/goals/"+created.ID+"/priority", `{"priority":"asap"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("bad priority = %d, want 400", code)
+	}
+
+	var list struct{ Goals []Goal }
+	call(http.MethodGet, "/goals", "", &list)
+	if len(list.Goals) != 2 || list.Goals[0].ID != created.ID {
+		t.Errorf("GET /goals = %+v, want the critical goal first", list.Goals)
+	}
+
+	if code := call(http.MethodPost, "/goals/"+created.ID+"/complete", "", &g); code != http.StatusOK || g.Status != GoalStatusCompleted {
+		t.Errorf("complete = %d, %+v", code, g)
+	}
+	list.Goals = nil
+	call(http.MethodGet, "/goals?status=pending", "", &list)
+	if len(list.Goals) != 1 || list.Goals[0].Source != GoalSourcePattern {
+		t.Errorf("pending goals = %+v", list.Goals)
+	}
+
+	if code := call(http.MethodDelete, "/goals/"+created.ID, "", nil); code != http.StatusOK || e.Get(created.ID) != nil {
+		t.Errorf("DELETE = %d", code)
+	}
+	if code := call(http.MethodGet, "/goals/"+created.ID, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET deleted goal = %d, want 404", code)
+	}
+}
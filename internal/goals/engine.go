@@ -11,7 +11,9 @@ package goals
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -37,6 +39,26 @@ const (
 	GoalPriorityCritical GoalPriority = 3
 )
 
+var priorityNames = []string{"low", "normal", "high", "critical"}
+
+// String returns the priority's name: low, normal, high or critical.
+func (p GoalPriority) String() string {
+	if p < 0 || int(p) >= len(priorityNames) {
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// ParsePriority parses a priority name, in any case.
+func ParsePriority(s string) (GoalPriority, error) {
+	for i, name := range priorityNames {
+		if strings.EqualFold(s, name) {
+			return GoalPriority(i), nil
+		}
+	}
+	return 0, fmt.Errorf("priority %q is not low, normal, high or critical", s)
+}
+
 // GoalSource indicates what triggered the goal.
 type GoalSource string
 
@@ -61,6 +83,115 @@ type Goal struct {
 	UpdatedAt   time.Time    `json:"updated_at"`
 	Attempts    int          `json:"attempts"`
 	MaxAttempts int          `json:"max_attempts"` // 0 = unlimited
+
+	// Snapshot is the context captured when the goal was created.
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+}
+
+// Snapshot limits: goals are kept in memory and rendered into prompts, so
+// only a handful of short items are captured.
+const (
+	MaxSnapshotItems = 5   // per list
+	MaxSnapshotText  = 500 // runes per item
+)
+
+// Snapshot freezes the context a goal was derived from — example inputs,
+// recent runs, constraints — so a goal executed days later does not depend
+// on state that has since moved on.
+type Snapshot struct {
+	TakenAt        time.Time    `json:"taken_at"`
+	Inputs         []string     `json:"inputs,omitempty"`      // example inputs, most recent first
+	RecentRuns     []RunSummary `json:"recent_runs,omitempty"` // most recent first
+	Constraints    []string     `json:"constraints,omitempty"`
+	ExpectedOutput string       `json:"expected_output,omitempty"`
+	Notes          string       `json:"notes,omitempty"` // review notes or reflection
+}
+
+// RunSummary is one past run in a Snapshot.
+type RunSummary struct {
+	TaskID  string    `json:"task_id"`
+	Summary string    `json:"summary"`
+	Quality float64   `json:"quality,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// trimmed returns a copy of s within the MaxSnapshot* limits.
+func (s Snapshot) trimmed() *Snapshot {
+	clipAll := func(items []string) []string {
+		if len(items) > MaxSnapshotItems {
+			items = items[:MaxSnapshotItems]
+		}
+		out := make([]string, len(items))
+		for i, it := range items {
+			out[i] = clip(it)
+		}
+		return out
+	}
+	s.Inputs = clipAll(s.Inputs)
+	s.Constraints = clipAll(s.Constraints)
+	if len(s.RecentRuns) > MaxSnapshotItems {
+		s.RecentRuns = s.RecentRuns[:MaxSnapshotItems]
+	}
+	runs := make([]RunSummary, len(s.RecentRuns))
+	for i, r := range s.RecentRuns {
+		r.Summary = clip(r.Summary)
+		runs[i] = r
+	}
+	s.RecentRuns = runs
+	s.ExpectedOutput = clip(s.ExpectedOutput)
+	s.Notes = clip(s.Notes)
+	if s.TakenAt.IsZero() {
+		s.TakenAt = time.Now()
+	}
+	return &s
+}
+
+func clip(s string) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= MaxSnapshotText {
+		return string(r)
+	}
+	return string(r[:MaxSnapshotText-1]) + "…"
+}
+
+// Brief renders the goal with its snapshot as the input for executing it.
+// Without a snapshot it is just the description.
+func (g *Goal) Brief() string {
+	s := g.Snapshot
+	if s == nil {
+		return g.Description
+	}
+	var b strings.Builder
+	b.WriteString(g.Description)
+	fmt.Fprintf(&b, "\n\nContext captured %s when this goal was set:", s.TakenAt.UTC().Format("2006-01-02 15:04 UTC"))
+	if len(s.Inputs) > 0 {
+		b.WriteString("\nExample inputs:")
+		for _, in := range s.Inputs {
+			fmt.Fprintf(&b, "\n- %s", in)
+		}
+	}
+	if len(s.RecentRuns) > 0 {
+		b.WriteString("\nRecent runs:")
+		for _, r := range s.RecentRuns {
+			fmt.Fprintf(&b, "\n- %s %s: %s", r.At.UTC().Format("2006-01-02"), r.TaskID, r.Summary)
+			if r.Quality > 0 {
+				fmt.Fprintf(&b, " (quality %.0f%%)", r.Quality*100)
+			}
+		}
+	}
+	if len(s.Constraints) > 0 {
+		b.WriteString("\nConstraints:")
+		for _, c := range s.Constraints {
+			fmt.Fprintf(&b, "\n- %s", c)
+		}
+	}
+	if s.ExpectedOutput != "" {
+		fmt.Fprintf(&b, "\nExpected output: %s", s.ExpectedOutput)
+	}
+	if s.Notes != "" {
+		fmt.Fprintf(&b, "\nNotes: %s", s.Notes)
+	}
+	return b.String()
 }
 
 // Engine manages the lifecycle of proactive goals.
@@ -109,6 +240,16 @@ func (e *Engine) AddWithMeta(description string, source GoalSource, priority Goa
 	return g
 }
 
+// AddWithSnapshot registers a goal with metadata and a context snapshot,
+// trimmed to the MaxSnapshot* limits.
+func (e *Engine) AddWithSnapshot(description string, source GoalSource, priority GoalPriority, meta map[string]string, snap Snapshot) *Goal {
+	g := e.AddWithMeta(description, source, priority, meta)
+	e.mu.Lock()
+	g.Snapshot = snap.trimmed()
+	e.mu.Unlock()
+	return g
+}
+
 // Get returns a goal by ID.
 func (e *Engine) Get(id string) *Goal {
 	e.mu.RLock()
@@ -118,12 +259,18 @@ func (e *Engine) Get(id string) *Goal {
 
 // NextPending returns the highest-priority pending goal, or nil if none.
 func (e *Engine) NextPending() *Goal {
+	return e.NextPendingExcept()
+}
+
+// NextPendingExcept returns the highest-priority pending goal from a
+// source other than skip, or nil if none.
+func (e *Engine) NextPendingExcept(skip ...GoalSource) *Goal {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	var pending []*Goal
 	for _, g := range e.goals {
-		if g.Status == GoalStatusPending {
+		if g.Status == GoalStatusPending && !slices.Contains(skip, g.Source) {
 			pending = append(pending, g)
 		}
 	}
@@ -159,6 +306,25 @@ func (e *Engine) MarkInProgress(id, taskID string) error {
 	return nil
 }
 
+// Requeue puts a goal back to PENDING, undoing MarkInProgress when its
+// run could not start.
+func (e *Engine) Requeue(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	g, ok := e.goals[id]
+	if !ok {
+		return fmt.Errorf("goal %q not found", id)
+	}
+	if g.Status == GoalStatusInProgress {
+		g.Status = GoalStatusPending
+		g.TaskID = ""
+		g.Attempts--
+		g.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
 // MarkCompleted transitions a goal to COMPLETED.
 func (e *Engine) MarkCompleted(id string) error {
 	e.mu.Lock()
@@ -206,6 +372,32 @@ func (e *Engine) Cancel(id string) error {
 	return nil
 }
 
+// SetPriority changes a goal's priority.
+func (e *Engine) SetPriority(id string, priority GoalPriority) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	g, ok := e.goals[id]
+	if !ok {
+		return fmt.Errorf("goal %q not found", id)
+	}
+	g.Priority = priority
+	g.UpdatedAt = time.Now()
+	return nil
+}
+
+// Remove deletes a goal, whatever its status.
+func (e *Engine) Remove(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.goals[id]; !ok {
+		return fmt.Errorf("goal %q not found", id)
+	}
+	delete(e.goals, id)
+	return nil
+}
+
 // ListByStatus returns all goals with the given status.
 func (e *Engine) ListByStatus(status GoalStatus) []*Goal {
 	e.mu.RLock()
@@ -1,6 +1,8 @@
 package goals
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -263,3 +265,97 @@ func TestEngine_ListAll(t *testing.T) {
 		t.Errorf("ListAll = %d, want 2", len(all))
 	}
 }
+
+func TestEngine_AddWithSnapshot(t *testing.T) {
+	e := New()
+	long := strings.Repeat("x", MaxSnapshotText+50)
+	var runs []RunSummary
+	for i := 0; i < MaxSnapshotItems+3; i++ {
+		runs = append(runs, RunSummary{TaskID: fmt.Sprintf("t%d", i), Summary: "weekly report", Quality: 0.8, At: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)})
+	}
+	g := e.AddWithSnapshot("Generate code-skill for pattern abc", GoalSourcePattern, GoalPriorityHigh, nil, Snapshot{
+		Inputs:      []string{long},
+		RecentRuns:  runs,
+		Constraints: []string{"under 200 words"},
+		Notes:       "reviewer wanted a table",
+	})
+
+	s := g.Snapshot
+	if s == nil || s.TakenAt.IsZero() {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if n := len([]rune(s.Inputs[0])); n != MaxSnapshotText {
+		t.Errorf("input clipped to %d runes, want %d", n, MaxSnapshotText)
+	}
+	if len(s.RecentRuns) != MaxSnapshotItems {
+		t.Errorf("recent runs = %d, want %d", len(s.RecentRuns), MaxSnapshotItems)
+	}
+	if len(runs) != MaxSnapshotItems+3 {
+		t.Error("caller's slice was modified")
+	}
+
+	brief := g.Brief()
+	for _, want := range []string{"Generate code-skill for pattern abc", "2026-03-01 t0: weekly report (quality 80%)", "- under 200 words", "Notes: reviewer wanted a table"} {
+		if !strings.Contains(brief, want) {
+			t.Errorf("brief missing %q:\n%s", want, brief)
+		}
+	}
+}
+
+func TestGoal_BriefWithoutSnapshot(t *testing.T) {
+	g := New().Add("Self-check", GoalSourceHeartbeat, GoalPriorityLow)
+	if g.Brief() != "Self-check" {
+		t.Errorf("Brief = %q", g.Brief())
+	}
+}
+
+func TestEngine_SetPriorityAndRemove(t *testing.T) {
+	e := New()
+	a := e.Add("first", GoalSourceUser, GoalPriorityNormal)
+	b := e.Add("second", GoalSourceUser, GoalPriorityNormal)
+
+	if err := e.SetPriority(b.ID, GoalPriorityCritical); err != nil {
+		t.Fatal(err)
+	}
+	if next := e.NextPending(); next.ID != b.ID {
+		t.Errorf("NextPending = %s, want the reprioritized %s", next.ID, b.ID)
+	}
+	if err := e.Remove(b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if e.Get(b.ID) != nil || e.NextPending().ID != a.ID {
+		t.Error("removed goal is still there")
+	}
+	if e.Remove(b.ID) == nil || e.SetPriority("nope", GoalPriorityLow) == nil {
+		t.Error("expected errors for unknown goals")
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range []GoalPriority{GoalPriorityLow, GoalPriorityNormal, GoalPriorityHigh, GoalPriorityCritical} {
+		got, err := ParsePriority(strings.ToUpper(p.String()))
+		if err != nil || got != p {
+			t.Errorf("ParsePriority(%q) = %v, %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+}
+
+func TestEngine_Requeue(t *testing.T) {
+	e := New()
+	g := e.Add("test", GoalSourceUser, GoalPriorityNormal)
+	e.MarkInProgress(g.ID, "")
+	if err := e.Requeue(g.ID); err != nil {
+		t.Fatal(err)
+	}
+	if g.Status != GoalStatusPending || g.Attempts != 0 {
+		t.Errorf("requeued goal: %s, %d attempts", g.Status, g.Attempts)
+	}
+	e.MarkCompleted(g.ID)
+	e.Requeue(g.ID)
+	if g.Status != GoalStatusCompleted {
+		t.Errorf("requeue changed a completed goal to %s", g.Status)
+	}
+}
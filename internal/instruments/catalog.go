@@ -0,0 +1,136 @@
+package instruments
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SetCatalogPath makes the registry write its metadata (including docs) to
+// path whenever a skill is registered, changes status or is removed, and
+// writes it once immediately. "" disables the catalog file.
+func (r *SkillRegistry) SetCatalogPath(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.catalogPath = path
+	return r.saveCatalogLocked()
+}
+
+// Catalog returns all skill metadata sorted by name.
+func (r *SkillRegistry) Catalog() []SkillMeta {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.catalogLocked()
+}
+
+func (r *SkillRegistry) catalogLocked() []SkillMeta {
+	metas := make([]SkillMeta, 0, len(r.skills))
+	for _, s := range r.skills {
+		metas = append(metas, s.Meta)
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		if metas[i].Name != metas[j].Name {
+			return metas[i].Name < metas[j].Name
+		}
+		return metas[i].ID < metas[j].ID
+	})
+	return metas
+}
+
+// saveCatalogLocked writes the catalog file atomically. Caller holds r.mu.
+// Failures are returned but never block registry updates.
+func (r *SkillRegistry) saveCatalogLocked() error {
+	if r.catalogPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.catalogLocked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("skill catalog: encode: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.catalogPath), 0o755); err != nil {
+		return fmt.Errorf("skill catalog: mkdir: %w", err)
+	}
+	tmp := r.catalogPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("skill catalog: write: %w", err)
+	}
+	return os.Rename(tmp, r.catalogPath)
+}
+
+// LoadCatalog reads a catalog file written by SetCatalogPath.
+func LoadCatalog(path string) ([]SkillMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("skill catalog: %w", err)
+	}
+	var metas []SkillMeta
+	if err := json.Unmarshal(data, &metas); err != nil {
+		return nil, fmt.Errorf("skill catalog: parse %s: %w", path, err)
+	}
+	return metas, nil
+}
+
+// skillDetail is the JSON body of GET /api/skills/{id}: the metadata plus
+// the source of code skills, for auditing.
+type skillDetail struct {
+	SkillMeta
+	Source string `json:"source,omitempty"`
+}
+
+// RegisterRoutes exposes the skills catalog for the kiosk and lets admins
+// manage the skills (`overhuman skills`).
+// Routes: GET /api/skills, GET /api/skills/{id},
+// POST /api/skills/{id}/enable, POST /api/skills/{id}/disable,
+// DELETE /api/skills/{id}
+func (r *SkillRegistry) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/skills", func(w http.ResponseWriter, req *http.Request) {
+		writeCatalogJSON(w, http.StatusOK, map[string]any{"skills": r.Catalog()})
+	})
+	mux.HandleFunc("GET /api/skills/{id}", func(w http.ResponseWriter, req *http.Request) {
+		s := r.Get(req.PathValue("id"))
+		if s == nil {
+			writeCatalogJSON(w, http.StatusNotFound, map[string]string{"error": "skill not found"})
+			return
+		}
+		r.mu.RLock()
+		detail := skillDetail{SkillMeta: s.Meta}
+		r.mu.RUnlock()
+		if cs, ok := s.Executor.(*CodeSkill); ok {
+			detail.Source = cs.Source
+		}
+		writeCatalogJSON(w, http.StatusOK, detail)
+	})
+	// Enabling makes a skill ACTIVE; disabling deprecates it, so it is no
+	// longer selected for tasks.
+	for action, status := range map[string]SkillStatus{"enable": SkillStatusActive, "disable": SkillStatusDeprecated} {
+		mux.HandleFunc("POST /api/skills/{id}/"+action, func(w http.ResponseWriter, req *http.Request) {
+			s := r.Get(req.PathValue("id"))
+			if s == nil || r.UpdateStatus(s.Meta.ID, status) != nil {
+				writeCatalogJSON(w, http.StatusNotFound, map[string]string{"error": "skill not found"})
+				return
+			}
+			r.mu.RLock()
+			meta := s.Meta
+			r.mu.RUnlock()
+			writeCatalogJSON(w, http.StatusOK, meta)
+		})
+	}
+	mux.HandleFunc("DELETE /api/skills/{id}", func(w http.ResponseWriter, req *http.Request) {
+		id := req.PathValue("id")
+		if r.Get(id) == nil {
+			writeCatalogJSON(w, http.StatusNotFound, map[string]string{"error": "skill not found"})
+			return
+		}
+		r.Remove(id)
+		writeCatalogJSON(w, http.StatusOK, map[string]string{"deleted": id})
+	})
+}
+
+func writeCatalogJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -9,8 +9,12 @@ package instruments
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -38,6 +42,21 @@ func DefaultSandboxConfig() SandboxConfig {
 	}
 }
 
+// within returns c with the memory, CPU and timeout limits of limits
+// where they are set and lower.
+func (c SandboxConfig) within(limits SandboxConfig) SandboxConfig {
+	if limits.MemoryMB > 0 && limits.MemoryMB < c.MemoryMB {
+		c.MemoryMB = limits.MemoryMB
+	}
+	if limits.CPUs > 0 && limits.CPUs < c.CPUs {
+		c.CPUs = limits.CPUs
+	}
+	if limits.Timeout > 0 && limits.Timeout < c.Timeout {
+		c.Timeout = limits.Timeout
+	}
+	return c
+}
+
 // SandboxResult captures the output of a container execution.
 type SandboxResult struct {
 	ExitCode  int    `json:"exit_code"`
@@ -46,6 +65,26 @@ type SandboxResult struct {
 	ElapsedMs int64  `json:"elapsed_ms"`
 	OOMKilled bool   `json:"oom_killed"` // Out of memory
 	TimedOut  bool   `json:"timed_out"`
+
+	// QuotaExceeded reports that the code filled the run's scratch
+	// directory beyond its quota.
+	QuotaExceeded bool `json:"quota_exceeded,omitempty"`
+}
+
+// Sandbox runs untrusted code in isolation: DockerSandbox or WasmSandbox.
+type Sandbox interface {
+	// Name names the runtime ("docker", "wasm").
+	Name() string
+	IsAvailable() bool
+	Execute(ctx context.Context, language, code string) (*SandboxResult, error)
+	// CreateLimitedSkillExecutor runs code as a skill, within limits
+	// where they are lower than the sandbox's own.
+	CreateLimitedSkillExecutor(language, code string, limits SandboxConfig) SkillExecutor
+}
+
+// sandboxRunner is what a sandboxSkillExecutor runs code with.
+type sandboxRunner interface {
+	execute(ctx context.Context, limits SandboxConfig, language, code string, env map[string]string) (*SandboxResult, error)
 }
 
 // DockerSandbox manages container-based code execution.
@@ -77,6 +116,9 @@ func (d *DockerSandbox) Config() SandboxConfig {
 	return d.config
 }
 
+// Name implements Sandbox.
+func (d *DockerSandbox) Name() string { return "docker" }
+
 // IsAvailable checks if Docker is installed and accessible.
 func (d *DockerSandbox) IsAvailable() bool {
 	cmd := exec.Command("docker", "info")
@@ -85,9 +127,17 @@ func (d *DockerSandbox) IsAvailable() bool {
 
 // Execute runs code in a Docker container.
 // The code string is passed via stdin to the container's interpreter.
+// Within a pipeline run, the run's scratch directory is mounted as the
+// working directory, so steps can pass files to each other.
 func (d *DockerSandbox) Execute(ctx context.Context, language, code string) (*SandboxResult, error) {
+	return d.execute(ctx, SandboxConfig{}, language, code, nil)
+}
+
+// execute is Execute within limits (see within), with environment
+// variables set in the container.
+func (d *DockerSandbox) execute(ctx context.Context, limits SandboxConfig, language, code string, env map[string]string) (*SandboxResult, error) {
 	d.mu.RLock()
-	cfg := d.config
+	cfg := d.config.within(limits)
 	d.mu.RUnlock()
 
 	interpreter, err := languageInterpreter(language)
@@ -107,10 +157,27 @@ func (d *DockerSandbox) Execute(ctx context.Context, language, code string) (*Sa
 		"--read-only",
 		// Tmpfs for /tmp (skills may need temp files).
 		"--tmpfs", "/tmp:size=64m",
-		cfg.Image,
-		interpreter[0],
 	}
-	args = append(args, interpreter[1:]...)
+	scratch := ScratchFrom(ctx)
+	if scratch != nil {
+		args = append(args, "--volume", scratch.Dir()+":"+cfg.WorkDir)
+		// Run as the daemon's user so the code can write to the mount
+		// (all capabilities are dropped, root included).
+		if uid := os.Getuid(); uid >= 0 {
+			args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+		}
+	}
+	// Values go through docker's own environment, not its command line.
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--env", name)
+	}
+	args = append(args, cfg.Image)
+	args = append(args, interpreter...)
 
 	// Create context with timeout.
 	execCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
@@ -118,6 +185,12 @@ func (d *DockerSandbox) Execute(ctx context.Context, language, code string) (*Sa
 
 	cmd := exec.CommandContext(execCtx, "docker", args...)
 	cmd.Stdin = strings.NewReader(code)
+	if len(env) > 0 {
+		cmd.Env = os.Environ()
+		for _, name := range names {
+			cmd.Env = append(cmd.Env, name+"="+env[name])
+		}
+	}
 
 	start := time.Now()
 	var stdout, stderr strings.Builder
@@ -139,6 +212,9 @@ func (d *DockerSandbox) Execute(ctx context.Context, language, code string) (*Sa
 		Stderr:    stderr.String(),
 		ElapsedMs: elapsed,
 	}
+	if scratch != nil && errors.Is(scratch.CheckQuota(), ErrScratchQuota) {
+		result.QuotaExceeded = true
+	}
 
 	if execCtx.Err() == context.DeadlineExceeded {
 		result.TimedOut = true
@@ -172,24 +248,39 @@ func (d *DockerSandbox) Stats() (runs, errors int) {
 
 // CreateSkillExecutor wraps a DockerSandbox as a SkillExecutor for a specific code string.
 func (d *DockerSandbox) CreateSkillExecutor(language, code string) SkillExecutor {
-	return &dockerSkillExecutor{
+	return d.CreateLimitedSkillExecutor(language, code, SandboxConfig{})
+}
+
+// CreateLimitedSkillExecutor is CreateSkillExecutor for code that declared
+// its own memory, CPU and timeout limits (a skill manifest). They apply
+// where they are lower than the sandbox's.
+func (d *DockerSandbox) CreateLimitedSkillExecutor(language, code string, limits SandboxConfig) SkillExecutor {
+	return &sandboxSkillExecutor{
 		sandbox:  d,
 		language: language,
 		code:     code,
+		limits:   limits,
 	}
 }
 
-type dockerSkillExecutor struct {
-	sandbox  *DockerSandbox
+// sandboxSkillExecutor runs code as a skill, its input in SkillInputEnv.
+type sandboxSkillExecutor struct {
+	sandbox  sandboxRunner
 	language string
 	code     string
+	limits   SandboxConfig
 }
 
-func (e *dockerSkillExecutor) Execute(ctx context.Context, input SkillInput) (*SkillOutput, error) {
-	// Inject input parameters into the code environment via stdin.
-	fullCode := fmt.Sprintf("# Input: goal=%s\n%s", input.Goal, e.code)
+// SkillInputEnv is the environment variable holding a code skill's input,
+// the SkillInput as JSON, in the sandbox.
+const SkillInputEnv = "SKILL_INPUT"
 
-	result, err := e.sandbox.Execute(ctx, e.language, fullCode)
+func (e *sandboxSkillExecutor) Execute(ctx context.Context, input SkillInput) (*SkillOutput, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	result, err := e.sandbox.execute(ctx, e.limits, e.language, e.code, map[string]string{SkillInputEnv: string(data)})
 	if err != nil {
 		return &SkillOutput{
 			Success: false,
@@ -213,6 +304,14 @@ func (e *dockerSkillExecutor) Execute(ctx context.Context, input SkillInput) (*S
 		}, nil
 	}
 
+	if result.QuotaExceeded {
+		return &SkillOutput{
+			Success:   false,
+			Error:     ErrScratchQuota.Error(),
+			ElapsedMs: result.ElapsedMs,
+		}, nil
+	}
+
 	if result.ExitCode != 0 {
 		return &SkillOutput{
 			Success:   false,
@@ -233,9 +332,9 @@ func (e *dockerSkillExecutor) Execute(ctx context.Context, input SkillInput) (*S
 func languageInterpreter(lang string) ([]string, error) {
 	switch strings.ToLower(lang) {
 	case "python", "py":
-		return []string{"python3", "-c", "/dev/stdin"}, nil
+		return []string{"python3", "-"}, nil
 	case "javascript", "js", "node":
-		return []string{"node", "-e", "/dev/stdin"}, nil
+		return []string{"node", "-"}, nil
 	case "bash", "sh":
 		return []string{"bash", "-s"}, nil
 	case "go":
@@ -131,10 +131,10 @@ func TestDockerSandbox_CreateSkillExecutor(t *testing.T) {
 		t.Fatal("CreateSkillExecutor returned nil")
 	}
 
-	// Verify it's a dockerSkillExecutor.
-	dse, ok := executor.(*dockerSkillExecutor)
+	// Verify it's a sandboxSkillExecutor.
+	dse, ok := executor.(*sandboxSkillExecutor)
 	if !ok {
-		t.Fatal("expected *dockerSkillExecutor")
+		t.Fatal("expected *sandboxSkillExecutor")
 	}
 	if dse.language != "python" {
 		t.Errorf("language = %q", dse.language)
@@ -144,6 +144,16 @@ func TestDockerSandbox_CreateSkillExecutor(t *testing.T) {
 	}
 }
 
+func TestSandboxConfig_Within(t *testing.T) {
+	cfg := DefaultSandboxConfig().within(SandboxConfig{MemoryMB: 128, CPUs: 2, Timeout: 10 * time.Second})
+	if cfg.MemoryMB != 128 || cfg.CPUs != 0.5 || cfg.Timeout != 10*time.Second {
+		t.Errorf("within = %+v", cfg)
+	}
+	if cfg := DefaultSandboxConfig().within(SandboxConfig{}); cfg != DefaultSandboxConfig() {
+		t.Errorf("no limits changed the config: %+v", cfg)
+	}
+}
+
 func TestSandboxResult_Fields(t *testing.T) {
 	r := &SandboxResult{
 		ExitCode:  0,
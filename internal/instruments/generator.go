@@ -49,26 +49,28 @@ func (g *Generator) Generate(ctx context.Context, spec CodeSpec) (*GeneratedCode
 		lang = "python" // Default to Python for widest compatibility.
 	}
 
-	prompt := fmt.Sprintf(`Generate a %s function that accomplishes this goal.
+	prompt := fmt.Sprintf(`Generate a %s program that accomplishes this goal.
 
 Goal: %s
 Input: %s
 Output: %s
 
 Requirements:
-- The function must be self-contained (no external dependencies beyond stdlib)
-- Handle errors gracefully
-- Return a clear result string
+- The program must be self-contained (no external dependencies beyond stdlib)
+- It reads its task from the %s environment variable, a JSON object:
+  {"goal": "<the request>", "context": "<clarified task>", "parameters": {...}}
+- It prints the result to stdout and exits 0; on failure it prints the reason to stderr and exits non-zero
+- It has no network access and may only write to the current directory
 
 Respond in EXACTLY this format (no markdown fences):
 
 CODE_START
-<your function code here>
+<your program here>
 CODE_END
 
 TESTS_START
 <your test code here>
-TESTS_END`, lang, spec.Goal, spec.InputDesc, spec.OutputDesc)
+TESTS_END`, lang, spec.Goal, spec.InputDesc, spec.OutputDesc, SkillInputEnv)
 
 	if len(spec.Examples) > 0 {
 		prompt += "\n\nExamples:\n" + strings.Join(spec.Examples, "\n")
@@ -142,6 +144,7 @@ func (g *Generator) GenerateAndRegister(
 			Version:     1,
 			CreatedAt:   now,
 			UpdatedAt:   now,
+			Doc:         DocumentGenerated(spec, generated),
 		},
 		Executor: codeSkill,
 	}
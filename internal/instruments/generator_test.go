@@ -2,55 +2,16 @@ package instruments
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/overhumantest"
 )
 
-func mockCodeLLM(t *testing.T, responseText string) *httptest.Server {
-	t.Helper()
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-
-		type contentBlock struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		}
-		type resp struct {
-			ID      string         `json:"id"`
-			Type    string         `json:"type"`
-			Role    string         `json:"role"`
-			Model   string         `json:"model"`
-			Content []contentBlock `json:"content"`
-			StopReason string     `json:"stop_reason"`
-			Usage   struct {
-				InputTokens  int `json:"input_tokens"`
-				OutputTokens int `json:"output_tokens"`
-			} `json:"usage"`
-		}
-
-		r2 := resp{
-			ID:    "msg_gen",
-			Type:  "message",
-			Role:  "assistant",
-			Model: "claude-sonnet-4-20250514",
-			Content: []contentBlock{
-				{Type: "text", Text: responseText},
-			},
-			StopReason: "end_turn",
-		}
-		r2.Usage.InputTokens = 100
-		r2.Usage.OutputTokens = 200
-
-		json.NewEncoder(w).Encode(r2)
-	}))
-}
-
 func TestGenerator_Generate(t *testing.T) {
-	srv := mockCodeLLM(t, `Here's the generated code:
+	srv := overhumantest.NewMockProvider(t, `Here's the generated code:
 
 CODE_START
 def summarize(text):
@@ -96,7 +57,7 @@ TESTS_END`)
 }
 
 func TestGenerator_Generate_DefaultLanguage(t *testing.T) {
-	srv := mockCodeLLM(t, "CODE_START\nprint('hello')\nCODE_END\nTESTS_START\nassert True\nTESTS_END")
+	srv := overhumantest.NewMockProvider(t, "CODE_START\nprint('hello')\nCODE_END\nTESTS_START\nassert True\nTESTS_END")
 	defer srv.Close()
 
 	llm := brain.NewClaudeProvider("test-key", brain.WithClaudeBaseURL(srv.URL))
@@ -112,7 +73,7 @@ func TestGenerator_Generate_DefaultLanguage(t *testing.T) {
 }
 
 func TestGenerator_Generate_NoCodeBlock(t *testing.T) {
-	srv := mockCodeLLM(t, "I can't generate code for this request.")
+	srv := overhumantest.NewMockProvider(t, "I can't generate code for this request.")
 	defer srv.Close()
 
 	llm := brain.NewClaudeProvider("test-key", brain.WithClaudeBaseURL(srv.URL))
@@ -125,7 +86,7 @@ func TestGenerator_Generate_NoCodeBlock(t *testing.T) {
 }
 
 func TestGenerator_GenerateAndRegister(t *testing.T) {
-	srv := mockCodeLLM(t, "CODE_START\ndef solve(): return 42\nCODE_END\nTESTS_START\nassert solve() == 42\nTESTS_END")
+	srv := overhumantest.NewMockProvider(t, "CODE_START\ndef solve(): return 42\nCODE_END\nTESTS_START\nassert solve() == 42\nTESTS_END")
 	defer srv.Close()
 
 	llm := brain.NewClaudeProvider("test-key", brain.WithClaudeBaseURL(srv.URL))
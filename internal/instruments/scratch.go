@@ -0,0 +1,189 @@
+// Package instruments — per-run scratch space.
+//
+// Every pipeline run gets a private working directory that the skills,
+// subagents and sandboxed code of that run share, so a multi-step
+// workflow can pass files between steps without touching the user's
+// filesystem. The directory travels in the context and is removed (or
+// kept as artifacts) when the run ends.
+package instruments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultScratchQuotaMB caps a run's scratch directory.
+const DefaultScratchQuotaMB = 100
+
+// ErrScratchQuota is returned when a write would exceed the quota.
+var ErrScratchQuota = errors.New("scratch quota exceeded")
+
+// Scratch is a run's working directory.
+type Scratch struct {
+	dir   string
+	quota int64 // bytes; 0 = unlimited
+}
+
+// NewScratch creates the directory root/id with a quota in bytes.
+func NewScratch(root, id string, quota int64) (*Scratch, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, fmt.Errorf("scratch: invalid id %q", id)
+	}
+	dir, err := filepath.Abs(filepath.Join(root, id))
+	if err != nil {
+		return nil, fmt.Errorf("scratch: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("scratch: %w", err)
+	}
+	return &Scratch{dir: dir, quota: quota}, nil
+}
+
+// Dir returns the directory's absolute path.
+func (s *Scratch) Dir() string { return s.dir }
+
+// Path resolves name inside the directory, rejecting paths that escape it.
+func (s *Scratch) Path(name string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(strings.TrimLeft(name, `/\`)))
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("scratch: %q is outside the working directory", name)
+	}
+	return filepath.Join(s.dir, rel), nil
+}
+
+// Usage returns the bytes used by the directory's files.
+func (s *Scratch) Usage() (int64, error) {
+	var total int64
+	err := filepath.WalkDir(s.dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// CheckQuota returns ErrScratchQuota when the directory is over quota,
+// e.g. after sandboxed code wrote to it directly.
+func (s *Scratch) CheckQuota() error {
+	if s.quota <= 0 {
+		return nil
+	}
+	used, err := s.Usage()
+	if err != nil {
+		return err
+	}
+	if used > s.quota {
+		return fmt.Errorf("%w: %d of %d bytes", ErrScratchQuota, used, s.quota)
+	}
+	return nil
+}
+
+// WriteFile writes name within the quota.
+func (s *Scratch) WriteFile(name string, data []byte) error {
+	path, err := s.Path(name)
+	if err != nil {
+		return err
+	}
+	if s.quota > 0 {
+		used, err := s.Usage()
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(path); err == nil {
+			used -= info.Size()
+		}
+		if used+int64(len(data)) > s.quota {
+			return fmt.Errorf("%w: writing %d bytes to %s would use %d of %d", ErrScratchQuota, len(data), name, used+int64(len(data)), s.quota)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Files lists the files in the directory, as slash-separated relative
+// paths.
+func (s *Scratch) Files() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(s.dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// Keep moves the directory to root/<id> as the run's artifacts and
+// returns the new path. An empty directory is removed instead ("").
+func (s *Scratch) Keep(root string) (string, error) {
+	if files, _ := s.Files(); len(files) == 0 {
+		return "", s.Remove()
+	}
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(root, filepath.Base(s.dir))
+	if err := os.Rename(s.dir, dest); err != nil {
+		return "", fmt.Errorf("keep scratch: %w", err)
+	}
+	s.dir = dest
+	return dest, nil
+}
+
+// Remove deletes the directory.
+func (s *Scratch) Remove() error { return os.RemoveAll(s.dir) }
+
+type scratchKey struct{}
+
+// WithScratch returns ctx carrying s.
+func WithScratch(ctx context.Context, s *Scratch) context.Context {
+	return context.WithValue(ctx, scratchKey{}, s)
+}
+
+// ScratchFrom returns the scratch directory of the run ctx belongs to, or
+// nil outside a run.
+func ScratchFrom(ctx context.Context) *Scratch {
+	s, _ := ctx.Value(scratchKey{}).(*Scratch)
+	return s
+}
+
+// PruneScratch removes the run directories under root last modified
+// before cutoff, left behind by runs that never finished (crash, kill).
+func PruneScratch(root string, cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !e.IsDir() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, e.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
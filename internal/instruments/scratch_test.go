@@ -0,0 +1,88 @@
+package instruments
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScratch_PathAndQuota(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewScratch(root, "task_1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewScratch(root, "../escape", 10); err == nil {
+		t.Error("id with a path separator accepted")
+	}
+	for _, bad := range []string{"../x", "a/../../x"} {
+		if _, err := s.Path(bad); err == nil {
+			t.Errorf("Path(%q) escaped the directory", bad)
+		}
+	}
+	if p, err := s.Path("/abs/x"); err != nil || p != filepath.Join(s.Dir(), "abs", "x") {
+		t.Errorf("Path(/abs/x) = %q, %v", p, err)
+	}
+
+	if err := s.WriteFile("a.txt", []byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteFile("b.txt", []byte("123")); !errors.Is(err, ErrScratchQuota) {
+		t.Errorf("write over quota: %v", err)
+	}
+	if err := s.WriteFile("a.txt", []byte("1234567890")); err != nil {
+		t.Errorf("overwriting within quota: %v", err)
+	}
+	os.WriteFile(filepath.Join(s.Dir(), "c.txt"), []byte("x"), 0o600) // e.g. sandboxed code
+	if err := s.CheckQuota(); !errors.Is(err, ErrScratchQuota) {
+		t.Errorf("CheckQuota = %v", err)
+	}
+}
+
+func TestScratch_KeepAndRemove(t *testing.T) {
+	root := t.TempDir()
+	empty, _ := NewScratch(root, "empty", 0)
+	if dir, err := empty.Keep(filepath.Join(root, "artifacts")); dir != "" || err != nil {
+		t.Errorf("Keep(empty) = %q, %v", dir, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "empty")); !os.IsNotExist(err) {
+		t.Error("empty scratch not removed")
+	}
+
+	s, _ := NewScratch(root, "task_2", 0)
+	s.WriteFile("out/r.txt", []byte("r"))
+	dir, err := s.Keep(filepath.Join(root, "artifacts"))
+	if err != nil || dir != filepath.Join(root, "artifacts", "task_2") {
+		t.Fatalf("Keep = %q, %v", dir, err)
+	}
+	if files, _ := s.Files(); len(files) != 1 || files[0] != "out/r.txt" {
+		t.Errorf("Files = %v", files)
+	}
+
+	ctx := WithScratch(context.Background(), s)
+	if ScratchFrom(ctx) != s || ScratchFrom(context.Background()) != nil {
+		t.Error("scratch not carried by the context")
+	}
+}
+
+func TestPruneScratch(t *testing.T) {
+	root := t.TempDir()
+	old, _ := NewScratch(root, "old", 0)
+	NewScratch(root, "fresh", 0)
+	past := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(old.Dir(), past, past)
+
+	n, err := PruneScratch(root, time.Now().Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("PruneScratch = %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "fresh")); err != nil {
+		t.Error("fresh scratch removed")
+	}
+	if n, err := PruneScratch(filepath.Join(root, "missing"), time.Now()); n != 0 || err != nil {
+		t.Errorf("missing root: %d, %v", n, err)
+	}
+}
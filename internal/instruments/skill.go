@@ -15,8 +15,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
 )
 
 // SkillType identifies the implementation strategy of a skill.
@@ -67,6 +69,7 @@ type SkillMeta struct {
 	Type        SkillType   `json:"type"`
 	Status      SkillStatus `json:"status"`
 	Fingerprint string      `json:"fingerprint,omitempty"` // Links to the pattern that spawned it
+	Triggers    []string    `json:"triggers,omitempty"`    // Keywords that select it for matching goals
 	Version     int         `json:"version"`
 	CreatedAt   time.Time   `json:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at"`
@@ -77,6 +80,9 @@ type SkillMeta struct {
 	AvgQuality    float64 `json:"avg_quality"`
 	AvgCostUSD    float64 `json:"avg_cost_usd"`
 	AvgElapsedMs  float64 `json:"avg_elapsed_ms"`
+
+	// Doc is the human-readable documentation shown in the skills catalog.
+	Doc *SkillDoc `json:"doc,omitempty"`
 }
 
 // Skill combines metadata with an executor.
@@ -196,6 +202,12 @@ type SkillRegistry struct {
 
 	// Index: fingerprint → skill IDs (for pattern-based lookup).
 	byFingerprint map[string][]string
+
+	// catalogPath, if set, receives a JSON copy of all skill metadata on
+	// every change so CLI commands can read it without the daemon.
+	catalogPath string
+
+	onRemove []func(SkillMeta)
 }
 
 // NewSkillRegistry creates an empty registry.
@@ -206,17 +218,22 @@ func NewSkillRegistry() *SkillRegistry {
 	}
 }
 
-// Register adds a skill to the registry.
+// Register adds a skill to the registry. Skills registered without
+// documentation get a generated SkillDoc.
 func (r *SkillRegistry) Register(skill *Skill) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if skill.Meta.Doc == nil {
+		skill.Meta.Doc = documentSkill(skill)
+	}
 	r.skills[skill.Meta.ID] = skill
 	if skill.Meta.Fingerprint != "" {
 		r.byFingerprint[skill.Meta.Fingerprint] = append(
 			r.byFingerprint[skill.Meta.Fingerprint], skill.Meta.ID,
 		)
 	}
+	r.saveCatalogLocked()
 }
 
 // Get retrieves a skill by ID.
@@ -268,6 +285,36 @@ func (r *SkillRegistry) FindActive(fingerprint string) *Skill {
 	return best
 }
 
+// FindByTrigger returns the active skill with a trigger keyword or phrase
+// that occurs in goal as whole words (case-insensitive). The longest
+// matching trigger wins, so "weather tomorrow" beats "weather".
+func (r *SkillRegistry) FindByTrigger(goal string) *Skill {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	words := " " + strings.Join(strings.FieldsFunc(strings.ToLower(goal), isTriggerSep), " ") + " "
+	var best *Skill
+	bestLen := 0
+	for _, s := range r.skills {
+		if s.Meta.Status == SkillStatusDeprecated {
+			continue
+		}
+		for _, t := range s.Meta.Triggers {
+			phrase := strings.Join(strings.FieldsFunc(strings.ToLower(t), isTriggerSep), " ")
+			if phrase == "" || len(phrase) < bestLen || !strings.Contains(words, " "+phrase+" ") {
+				continue
+			}
+			// Equal lengths tie-break on ID so the choice is stable.
+			if len(phrase) > bestLen || s.Meta.ID < best.Meta.ID {
+				best, bestLen = s, len(phrase)
+			}
+		}
+	}
+	return best
+}
+
+func isTriggerSep(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+
 func typePriority(t SkillType) int {
 	switch t {
 	case SkillTypeCode:
@@ -311,16 +358,24 @@ func (r *SkillRegistry) UpdateStatus(id string, status SkillStatus) error {
 	}
 	s.Meta.Status = status
 	s.Meta.UpdatedAt = time.Now()
+	r.saveCatalogLocked()
 	return nil
 }
 
-// Remove removes a skill from the registry.
-func (r *SkillRegistry) Remove(id string) {
+// OnRemove registers fn to be called with each skill removed, after the
+// registry is updated.
+func (r *SkillRegistry) OnRemove(fn func(SkillMeta)) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.onRemove = append(r.onRemove, fn)
+}
 
+// Remove removes a skill from the registry.
+func (r *SkillRegistry) Remove(id string) {
+	r.mu.Lock()
 	s, ok := r.skills[id]
 	if !ok {
+		r.mu.Unlock()
 		return
 	}
 
@@ -336,6 +391,13 @@ func (r *SkillRegistry) Remove(id string) {
 	}
 
 	delete(r.skills, id)
+	r.saveCatalogLocked()
+	hooks := r.onRemove
+	r.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(s.Meta)
+	}
 }
 
 // MarshalMeta returns JSON of all skill metadata (without executors).
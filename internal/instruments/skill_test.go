@@ -279,6 +279,28 @@ func TestSkillRegistry_FindActive_None(t *testing.T) {
 	}
 }
 
+func TestSkillRegistry_FindByTrigger(t *testing.T) {
+	reg := NewSkillRegistry()
+	reg.Register(&Skill{Meta: SkillMeta{ID: "weather", Status: SkillStatusActive, Triggers: []string{"weather"}}})
+	reg.Register(&Skill{Meta: SkillMeta{ID: "weather_tomorrow", Status: SkillStatusActive, Triggers: []string{"Weather tomorrow"}}})
+	reg.Register(&Skill{Meta: SkillMeta{ID: "old", Status: SkillStatusDeprecated, Triggers: []string{"stock price"}}})
+
+	for goal, want := range map[string]string{
+		"What's the weather in Paris?":   "weather",
+		"weather, tomorrow, in Oslo":     "weather_tomorrow",
+		"is it weathering the storm":     "",
+		"check the stock price for ACME": "",
+	} {
+		got := ""
+		if sk := reg.FindByTrigger(goal); sk != nil {
+			got = sk.Meta.ID
+		}
+		if got != want {
+			t.Errorf("FindByTrigger(%q) = %q, want %q", goal, got, want)
+		}
+	}
+}
+
 func TestSkillRegistry_UpdateStatus(t *testing.T) {
 	reg := NewSkillRegistry()
 	reg.Register(&Skill{Meta: SkillMeta{ID: "s1", Status: SkillStatusActive}})
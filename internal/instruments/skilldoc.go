@@ -0,0 +1,163 @@
+package instruments
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Permissions a skill may need. They are declared for starter skills and
+// inferred from the source of generated code skills.
+const (
+	PermNetwork    = "network"    // outbound HTTP or sockets
+	PermFilesystem = "filesystem" // reads or writes files
+	PermProcess    = "process"    // spawns processes / shell commands
+	PermEnv        = "env"        // reads environment variables
+	PermStorage    = "storage"    // agent's persistent store
+	PermSecrets    = "secrets"    // credential vault
+
+	PermExternalWrite = "external_write" // creates or changes data in external services
+)
+
+// SkillParam documents one input parameter.
+type SkillParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// SkillExample is an example invocation.
+type SkillExample struct {
+	Input  string `json:"input"`
+	Output string `json:"output,omitempty"`
+}
+
+// SkillDoc is the human-readable documentation of a skill, produced when it
+// is generated or registered so users can audit what the agent can do.
+type SkillDoc struct {
+	Summary     string         `json:"summary"`
+	Inputs      []SkillParam   `json:"inputs,omitempty"`
+	Outputs     string         `json:"outputs,omitempty"`
+	Permissions []string       `json:"permissions,omitempty"`
+	Examples    []SkillExample `json:"examples,omitempty"`
+	Language    string         `json:"language,omitempty"` // code skills only
+}
+
+// permissionPatterns maps source-code markers to permissions. They are
+// deliberately broad: a false positive costs a line in the docs, a false
+// negative hides a capability from the user.
+var permissionPatterns = map[string]*regexp.Regexp{
+	PermNetwork:    regexp.MustCompile(`\b(requests|urllib|http\.client|httpx|socket|aiohttp|net/http|fetch\(|axios|XMLHttpRequest|curl|wget)\b`),
+	PermFilesystem: regexp.MustCompile(`\b(open\(|os\.remove|os\.unlink|shutil|pathlib|os\.WriteFile|os\.ReadFile|os\.Create|fs\.|readFile|writeFile)|>\s*/|\brm\s+-`),
+	PermProcess:    regexp.MustCompile(`\b(subprocess|os\.system|os\.popen|exec\.Command|child_process|spawn\(|eval\(|exec\()`),
+	PermEnv:        regexp.MustCompile(`\b(os\.environ|os\.getenv|os\.Getenv|process\.env)\b|\$[A-Z_]{2,}`),
+}
+
+// DetectPermissions infers the permissions source code appears to need.
+// The result is sorted.
+func DetectPermissions(source string) []string {
+	var perms []string
+	for perm, re := range permissionPatterns {
+		if re.MatchString(source) {
+			perms = append(perms, perm)
+		}
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// DocumentGenerated builds the documentation of a generated code skill from
+// its specification and source.
+func DocumentGenerated(spec CodeSpec, code *GeneratedCode) *SkillDoc {
+	doc := &SkillDoc{
+		Summary:  spec.Goal,
+		Outputs:  spec.OutputDesc,
+		Language: code.Language,
+	}
+	if spec.InputDesc != "" {
+		doc.Inputs = []SkillParam{{Name: "input", Description: spec.InputDesc, Required: true}}
+	}
+	doc.Permissions = DetectPermissions(code.Code)
+	for _, ex := range spec.Examples {
+		in, out, _ := strings.Cut(ex, "→")
+		if in == ex {
+			in, out, _ = strings.Cut(ex, "->")
+		}
+		doc.Examples = append(doc.Examples, SkillExample{Input: strings.TrimSpace(in), Output: strings.TrimSpace(out)})
+	}
+	return doc
+}
+
+// documentSkill builds fallback documentation for a skill registered without
+// one (starter or imported skills).
+func documentSkill(s *Skill) *SkillDoc {
+	doc := &SkillDoc{Summary: s.Meta.Description}
+	if doc.Summary == "" {
+		doc.Summary = s.Meta.Name
+	}
+	if cs, ok := s.Executor.(*CodeSkill); ok {
+		doc.Language = cs.Language
+		doc.Permissions = DetectPermissions(cs.Source)
+	}
+	return doc
+}
+
+// FormatSkillDoc renders a skill's metadata and documentation as plain text
+// (used by `overhuman skill info`).
+func FormatSkillDoc(meta SkillMeta) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)\n", meta.Name, meta.ID)
+	fmt.Fprintf(&b, "Type: %s   Status: %s   Version: %d\n", meta.Type, meta.Status, meta.Version)
+	if meta.Fingerprint != "" {
+		fmt.Fprintf(&b, "Pattern: %s\n", meta.Fingerprint)
+	}
+	if len(meta.Triggers) > 0 {
+		fmt.Fprintf(&b, "Triggers: %s\n", strings.Join(meta.Triggers, ", "))
+	}
+	if !meta.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "Created: %s\n", meta.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	if meta.TotalRuns > 0 {
+		fmt.Fprintf(&b, "Runs: %d   Success: %.0f%%   Avg quality: %.2f   Avg cost: $%.4f\n",
+			meta.TotalRuns, meta.SuccessRate*100, meta.AvgQuality, meta.AvgCostUSD)
+	}
+
+	doc := meta.Doc
+	if doc == nil {
+		doc = &SkillDoc{Summary: meta.Description}
+	}
+	fmt.Fprintf(&b, "\n%s\n", doc.Summary)
+	if doc.Language != "" {
+		fmt.Fprintf(&b, "\nLanguage: %s\n", doc.Language)
+	}
+	if len(doc.Inputs) > 0 {
+		b.WriteString("\nInputs:\n")
+		for _, p := range doc.Inputs {
+			req := ""
+			if p.Required {
+				req = " (required)"
+			}
+			fmt.Fprintf(&b, "  - %s%s: %s\n", p.Name, req, p.Description)
+		}
+	}
+	if doc.Outputs != "" {
+		fmt.Fprintf(&b, "\nOutputs: %s\n", doc.Outputs)
+	}
+	if len(doc.Permissions) > 0 {
+		fmt.Fprintf(&b, "\nPermissions: %s\n", strings.Join(doc.Permissions, ", "))
+	} else {
+		b.WriteString("\nPermissions: none\n")
+	}
+	if len(doc.Examples) > 0 {
+		b.WriteString("\nExamples:\n")
+		for _, ex := range doc.Examples {
+			if ex.Output != "" {
+				fmt.Fprintf(&b, "  %s → %s\n", ex.Input, ex.Output)
+			} else {
+				fmt.Fprintf(&b, "  %s\n", ex.Input)
+			}
+		}
+	}
+	return b.String()
+}
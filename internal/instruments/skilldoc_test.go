@@ -0,0 +1,157 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectPermissions(t *testing.T) {
+	src := "import os, requests, subprocess\nkey = os.environ['K']\nrequests.get('https://x')\nsubprocess.run(['ls'])\n"
+	got := DetectPermissions(src)
+	want := []string{PermEnv, PermNetwork, PermProcess}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectPermissions = %v, want %v", got, want)
+	}
+	if p := DetectPermissions("def solve(): return 42"); len(p) != 0 {
+		t.Errorf("pure code permissions = %v, want none", p)
+	}
+}
+
+func TestDocumentGenerated(t *testing.T) {
+	spec := CodeSpec{
+		Goal:       "Convert celsius to fahrenheit",
+		InputDesc:  "temperature in celsius",
+		OutputDesc: "temperature in fahrenheit",
+		Examples:   []string{"0 → 32", "100 -> 212", "bare"},
+	}
+	doc := DocumentGenerated(spec, &GeneratedCode{Language: "python", Code: "print(float(input())*9/5+32)"})
+	if doc.Summary != spec.Goal || doc.Outputs != spec.OutputDesc || doc.Language != "python" {
+		t.Errorf("doc = %+v", doc)
+	}
+	if len(doc.Inputs) != 1 || !doc.Inputs[0].Required {
+		t.Errorf("inputs = %+v", doc.Inputs)
+	}
+	want := []SkillExample{{Input: "0", Output: "32"}, {Input: "100", Output: "212"}, {Input: "bare"}}
+	if !reflect.DeepEqual(doc.Examples, want) {
+		t.Errorf("examples = %+v, want %+v", doc.Examples, want)
+	}
+}
+
+func TestSkillRegistry_RegisterFillsDoc(t *testing.T) {
+	r := NewSkillRegistry()
+	r.Register(&Skill{
+		Meta:     SkillMeta{ID: "s1", Name: "Fetcher", Description: "Fetch a page"},
+		Executor: NewCodeSkill(nil, "python", "import urllib.request"),
+	})
+	doc := r.Get("s1").Meta.Doc
+	if doc == nil || doc.Summary != "Fetch a page" || doc.Language != "python" {
+		t.Fatalf("doc = %+v", doc)
+	}
+	if !reflect.DeepEqual(doc.Permissions, []string{PermNetwork}) {
+		t.Errorf("permissions = %v", doc.Permissions)
+	}
+}
+
+func TestSkillRegistry_CatalogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skills", "catalog.json")
+	r := NewSkillRegistry()
+	if err := r.SetCatalogPath(path); err != nil {
+		t.Fatalf("SetCatalogPath: %v", err)
+	}
+	r.Register(&Skill{Meta: SkillMeta{ID: "b", Name: "Beta"}, Executor: NewLLMSkill(nil)})
+	r.Register(&Skill{Meta: SkillMeta{ID: "a", Name: "Alpha", Doc: &SkillDoc{Summary: "first"}}, Executor: NewLLMSkill(nil)})
+
+	metas, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	if len(metas) != 2 || metas[0].ID != "a" || metas[0].Doc.Summary != "first" {
+		t.Fatalf("catalog = %+v", metas)
+	}
+
+	r.Remove("a")
+	metas, _ = LoadCatalog(path)
+	if len(metas) != 1 || metas[0].ID != "b" {
+		t.Errorf("catalog after remove = %+v", metas)
+	}
+}
+
+func TestSkillRegistry_Routes(t *testing.T) {
+	r := NewSkillRegistry()
+	r.Register(&Skill{
+		Meta:     SkillMeta{ID: "code1", Name: "Code", Type: SkillTypeCode},
+		Executor: NewCodeSkill(func(ctx context.Context, in SkillInput) (*SkillOutput, error) { return nil, nil }, "python", "print(1)"),
+	})
+	mux := http.NewServeMux()
+	r.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/skills", nil))
+	var list struct {
+		Skills []SkillMeta `json:"skills"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Skills) != 1 {
+		t.Fatalf("list = %+v, err %v", list, err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/skills/code1", nil))
+	var detail skillDetail
+	json.NewDecoder(rec.Body).Decode(&detail)
+	if detail.Source != "print(1)" || detail.Doc == nil {
+		t.Errorf("detail = %+v", detail)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/skills/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing skill status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/skills/code1/disable", nil))
+	if rec.Code != http.StatusOK || r.Get("code1").Meta.Status != SkillStatusDeprecated {
+		t.Errorf("disable: %d %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/skills/code1/enable", nil))
+	if rec.Code != http.StatusOK || r.Get("code1").Meta.Status != SkillStatusActive {
+		t.Errorf("enable: %d %s", rec.Code, rec.Body)
+	}
+
+	var removed []string
+	r.OnRemove(func(m SkillMeta) { removed = append(removed, m.ID) })
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("DELETE", "/api/skills/code1", nil))
+	if rec.Code != http.StatusOK || r.Get("code1") != nil || !reflect.DeepEqual(removed, []string{"code1"}) {
+		t.Errorf("delete: %d %s, removed %v", rec.Code, rec.Body, removed)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/skills/code1/enable", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("enable deleted skill status = %d, want 404", rec.Code)
+	}
+}
+
+func TestFormatSkillDoc(t *testing.T) {
+	out := FormatSkillDoc(SkillMeta{
+		ID: "s1", Name: "Weather", Type: SkillTypeCode, Status: SkillStatusActive, Version: 2,
+		Doc: &SkillDoc{
+			Summary:     "Look up the weather",
+			Inputs:      []SkillParam{{Name: "city", Description: "city name", Required: true}},
+			Permissions: []string{PermNetwork},
+			Examples:    []SkillExample{{Input: "Paris", Output: "18°C"}},
+		},
+	})
+	for _, want := range []string{"Weather (s1)", "Look up the weather", "city (required): city name", "Permissions: network", "Paris → 18°C"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
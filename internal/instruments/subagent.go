@@ -6,6 +6,7 @@ package instruments
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -44,6 +45,44 @@ type DelegatedTask struct {
 	Parameters map[string]string `json:"parameters,omitempty"`
 	Priority   int               `json:"priority"` // 0=normal, 1=high, 2=critical
 	Timeout    time.Duration     `json:"timeout,omitempty"`
+
+	// Handoff, if set, is the parent task's hand-off package; Context then
+	// holds its rendered Brief for runners that only take text.
+	Handoff *Handoff `json:"handoff,omitempty"`
+}
+
+// Handoff is what a subagent is told about the task it helps with.
+type Handoff struct {
+	ParentTaskID   string   `json:"parent_task_id"`
+	ParentGoal     string   `json:"parent_goal"`
+	Summary        string   `json:"summary,omitempty"` // parent context, summarized when long
+	Memory         []string `json:"memory,omitempty"`  // relevant long-term memory snippets
+	Constraints    []string `json:"constraints,omitempty"`
+	ExpectedOutput string   `json:"expected_output,omitempty"`
+}
+
+// Brief renders the hand-off as the context of a delegated task.
+func (h *Handoff) Brief() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are helping with a larger task (%s): %s\n", h.ParentTaskID, h.ParentGoal)
+	if h.Summary != "" {
+		b.WriteString("\nContext:\n" + h.Summary + "\n")
+	}
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		b.WriteString("\n" + title + ":\n")
+		for _, it := range items {
+			b.WriteString("- " + it + "\n")
+		}
+	}
+	writeList("Relevant memory", h.Memory)
+	writeList("Constraints", h.Constraints)
+	if h.ExpectedOutput != "" {
+		b.WriteString("\nExpected output: " + h.ExpectedOutput + "\n")
+	}
+	return b.String()
 }
 
 // DelegationResult is the work returned from a subagent.
@@ -54,6 +93,17 @@ type DelegationResult struct {
 	CostUSD   float64 `json:"cost_usd"`
 	ElapsedMs int64   `json:"elapsed_ms"`
 	Error     string  `json:"error,omitempty"`
+
+	// Provenance records where the output came from; set by the manager.
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance identifies the delegation that produced a result.
+type Provenance struct {
+	DelegationID string    `json:"delegation_id"`
+	AgentID      string    `json:"agent_id"`
+	ParentTaskID string    `json:"parent_task_id,omitempty"`
+	CompletedAt  time.Time `json:"completed_at"`
 }
 
 // ---------------------------------------------------------------------------
@@ -127,6 +177,9 @@ func (m *SubagentManager) Delegate(ctx context.Context, parentID, childID string
 	d.Status = DelegationCompleted
 	d.CompletedAt = now
 	d.Result = result
+	if result != nil {
+		result.Provenance = d.provenance()
+	}
 	m.mu.Unlock()
 
 	return result, nil
@@ -177,6 +230,9 @@ func (m *SubagentManager) Execute(ctx context.Context, delegationID string) erro
 
 	d.Status = DelegationCompleted
 	d.Result = result
+	if result != nil {
+		result.Provenance = d.provenance()
+	}
 	return nil
 }
 
@@ -378,6 +434,15 @@ func (m *SubagentManager) createDelegation(parentID, childID string, task Delega
 	return d
 }
 
+// provenance describes d's result.
+func (d *Delegation) provenance() *Provenance {
+	pv := &Provenance{DelegationID: d.ID, AgentID: d.ChildAgentID, CompletedAt: d.CompletedAt}
+	if d.Task.Handoff != nil {
+		pv.ParentTaskID = d.Task.Handoff.ParentTaskID
+	}
+	return pv
+}
+
 func (m *SubagentManager) setStatus(id string, status DelegationStatus) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,33 @@
+// Command wasmprobe is a stand-in interpreter module for the WASM sandbox
+// tests, built with GOOS=wasip1. With "-" it echoes its program and probes
+// its capabilities; with "hog" it allocates until it runs out of memory.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "hog" {
+		var hoard [][]byte
+		for {
+			hoard = append(hoard, make([]byte, 1<<20))
+		}
+	}
+
+	program, _ := io.ReadAll(os.Stdin)
+	fmt.Printf("program=%s\n", program)
+	fmt.Printf("input=%s\n", os.Getenv("SKILL_INPUT"))
+	fmt.Printf("write workspace: %v\n", os.WriteFile("/workspace/out.txt", program, 0o644))
+	lib, err := os.ReadFile("/usr/local/lib/stdlib.txt")
+	fmt.Printf("read lib: %s %v\n", lib, err)
+	fmt.Printf("write lib denied: %v\n", os.WriteFile("/usr/local/lib/x", nil, 0o644) != nil)
+	_, err = os.ReadFile("/etc/passwd")
+	fmt.Printf("read host denied: %v\n", err != nil)
+	if strings.Contains(string(program), "exit(3)") {
+		os.Exit(3)
+	}
+}
@@ -0,0 +1,315 @@
+// Package instruments — WASM sandbox for code-skill execution.
+//
+// For machines without Docker: code runs in a WASI interpreter module
+// (python.wasm, qjs.wasm, ...) inside the process, under wazero. Isolation
+// is capability-based:
+//   - No network (no sockets are configured)
+//   - No filesystem but the directories mounted for the module: the run's
+//     working directory, and the interpreter's own read-only library
+//   - Memory and time limits
+package instruments
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// WasmInterpreter is a WASI module that runs the code of a language,
+// read from stdin.
+type WasmInterpreter struct {
+	// Module is the path of the .wasm file.
+	Module string `json:"module"`
+	// Args are its arguments. Default: ["-"] (read the program from stdin).
+	Args []string `json:"args,omitempty"`
+	// Dirs maps host directories the module needs (its standard library)
+	// to where it sees them.
+	Dirs map[string]string `json:"dirs,omitempty"`
+}
+
+// WasmConfig configures a WasmSandbox.
+type WasmConfig struct {
+	Interpreters map[string]WasmInterpreter // by language: "python", "javascript", ...
+	MemoryMB     int                        // Linear memory limit in MB (default: 256)
+	Timeout      time.Duration              // Execution timeout (default: 30s)
+	WorkDir      string                     // Where the module sees its working directory (default: "/workspace")
+}
+
+// WasmSandbox runs code in WASI interpreter modules.
+type WasmSandbox struct {
+	mu     sync.RWMutex
+	config WasmConfig
+	cache  wazero.CompilationCache // compiled interpreters, shared by runs
+
+	// Stats.
+	totalRuns   int
+	totalErrors int
+}
+
+// NewWasmSandbox creates a WASM sandbox, filling in the defaults.
+func NewWasmSandbox(config WasmConfig) *WasmSandbox {
+	def := DefaultSandboxConfig()
+	if config.MemoryMB <= 0 {
+		config.MemoryMB = def.MemoryMB
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = def.Timeout
+	}
+	if config.WorkDir == "" {
+		config.WorkDir = def.WorkDir
+	}
+	return &WasmSandbox{config: config, cache: wazero.NewCompilationCache()}
+}
+
+// Config returns the sandbox configuration.
+func (w *WasmSandbox) Config() WasmConfig {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.config
+}
+
+// Name implements Sandbox.
+func (w *WasmSandbox) Name() string { return "wasm" }
+
+// IsAvailable checks that an interpreter module exists.
+func (w *WasmSandbox) IsAvailable() bool {
+	cfg := w.Config()
+	for _, in := range cfg.Interpreters {
+		if _, err := os.Stat(in.Module); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute runs code with the interpreter of language. Within a pipeline
+// run, the run's scratch directory is the working directory; otherwise
+// a temporary one is.
+func (w *WasmSandbox) Execute(ctx context.Context, language, code string) (*SandboxResult, error) {
+	return w.execute(ctx, SandboxConfig{}, language, code, nil)
+}
+
+// CreateLimitedSkillExecutor implements Sandbox. The CPU limit doesn't
+// apply: a module runs on one thread.
+func (w *WasmSandbox) CreateLimitedSkillExecutor(language, code string, limits SandboxConfig) SkillExecutor {
+	return &sandboxSkillExecutor{
+		sandbox:  w,
+		language: language,
+		code:     code,
+		limits:   limits,
+	}
+}
+
+// Stats returns execution statistics.
+func (w *WasmSandbox) Stats() (runs, errors int) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.totalRuns, w.totalErrors
+}
+
+func (w *WasmSandbox) execute(ctx context.Context, limits SandboxConfig, language, code string, env map[string]string) (*SandboxResult, error) {
+	cfg := w.Config()
+	lim := SandboxConfig{MemoryMB: cfg.MemoryMB, Timeout: cfg.Timeout}.within(limits)
+
+	in, ok := cfg.Interpreters[wasmLanguage(language)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %s (no WASM interpreter configured)", language)
+	}
+	binary, err := os.ReadFile(in.Module)
+	if err != nil {
+		return nil, fmt.Errorf("wasm: %w", err)
+	}
+
+	workDir := ""
+	scratch := ScratchFrom(ctx)
+	if scratch != nil {
+		workDir = scratch.Dir()
+	} else {
+		dir, err := os.MkdirTemp("", "overhuman-wasm-")
+		if err != nil {
+			return nil, fmt.Errorf("wasm: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		workDir = dir
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, lim.Timeout)
+	defer cancel()
+
+	// The memory limit is enforced by the allocator rather than the
+	// runtime's page limit, so that a refused memory.grow is seen here.
+	mem := &memoryLimit{limit: uint64(lim.MemoryMB) << 20}
+	runCtx := experimental.WithMemoryAllocator(execCtx, mem)
+
+	rt := wazero.NewRuntimeWithConfig(runCtx, wazero.NewRuntimeConfig().
+		WithCompilationCache(w.cache).
+		WithCloseOnContextDone(true))
+	defer rt.Close(context.Background())
+	if _, err := wasi_snapshot_preview1.Instantiate(runCtx, rt); err != nil {
+		return nil, fmt.Errorf("wasm: %w", err)
+	}
+	compiled, err := rt.CompileModule(runCtx, binary)
+	if err != nil {
+		return nil, fmt.Errorf("wasm: compile %s: %w", in.Module, err)
+	}
+	memories := compiled.ImportedMemories()
+	for _, def := range compiled.ExportedMemories() {
+		memories = append(memories, def)
+	}
+	for _, def := range memories {
+		if uint64(def.Min())<<16 > mem.limit {
+			// The module can't even start within the limit.
+			w.countRun(true)
+			return &SandboxResult{ExitCode: -1, OOMKilled: true}, nil
+		}
+	}
+
+	// Capabilities: the working directory read-write, the interpreter's
+	// directories read-only, and nothing else. No sockets are configured
+	// (experimental/sock), so the module has no network.
+	fsConfig := wazero.NewFSConfig().WithDirMount(workDir, cfg.WorkDir)
+	hosts := make([]string, 0, len(in.Dirs))
+	for host := range in.Dirs {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		fsConfig = fsConfig.WithReadOnlyDirMount(host, in.Dirs[host])
+	}
+	args := in.Args
+	if args == nil {
+		args = []string{"-"}
+	}
+	var stdout, stderr strings.Builder
+	modConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{in.Module}, args...)...).
+		WithStdin(strings.NewReader(code)).
+		WithStdout(&stdout).
+		WithStderr(&stderr).
+		WithFSConfig(fsConfig).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithSysNanosleep().
+		WithRandSource(rand.Reader)
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		modConfig = modConfig.WithEnv(name, env[name])
+	}
+
+	start := time.Now()
+	mod, runErr := rt.InstantiateModule(runCtx, compiled, modConfig)
+	if mod != nil {
+		mod.Close(context.Background())
+	}
+	elapsed := time.Since(start).Milliseconds()
+
+	exitCode := 0
+	var exitErr *sys.ExitError
+	switch {
+	case errors.As(runErr, &exitErr):
+		exitCode = int(exitErr.ExitCode())
+	case runErr != nil:
+		// A trap (unreachable, out-of-bounds access, ...) ends the module
+		// like a crash.
+		exitCode = 1
+		stderr.WriteString(runErr.Error())
+	}
+
+	w.countRun(exitCode != 0)
+
+	result := &SandboxResult{
+		ExitCode:  exitCode,
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		ElapsedMs: elapsed,
+		OOMKilled: exitCode != 0 && mem.exceeded.Load(),
+	}
+	if scratch != nil && errors.Is(scratch.CheckQuota(), ErrScratchQuota) {
+		result.QuotaExceeded = true
+	}
+	if execCtx.Err() == context.DeadlineExceeded {
+		result.TimedOut = true
+		result.ExitCode = -1
+	}
+	return result, nil
+}
+
+func (w *WasmSandbox) countRun(failed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.totalRuns++
+	if failed {
+		w.totalErrors++
+	}
+}
+
+// memoryLimit is the allocator of a run's linear memory: it refuses to
+// grow it past limit bytes, and records that it did.
+type memoryLimit struct {
+	limit    uint64
+	exceeded atomic.Bool
+}
+
+// Allocate implements experimental.MemoryAllocator.
+func (m *memoryLimit) Allocate(capacity, _ uint64) experimental.LinearMemory {
+	return &limitedMemory{limit: m, buf: make([]byte, 0, min(capacity, m.limit))}
+}
+
+// limitedMemory is a linear memory allocated by a memoryLimit.
+type limitedMemory struct {
+	limit *memoryLimit
+	buf   []byte
+}
+
+// Reallocate implements experimental.LinearMemory. A nil result makes
+// memory.grow fail in the module, which then usually aborts.
+func (l *limitedMemory) Reallocate(size uint64) []byte {
+	if size > l.limit.limit {
+		l.limit.exceeded.Store(true)
+		return nil
+	}
+	if size <= uint64(cap(l.buf)) {
+		// Linear memory never shrinks: the bytes past len are still zero.
+		l.buf = l.buf[:size]
+		return l.buf
+	}
+	buf := make([]byte, size, min(max(size, 2*uint64(cap(l.buf))), l.limit.limit))
+	copy(buf, l.buf)
+	l.buf = buf
+	return buf
+}
+
+// Free implements experimental.LinearMemory.
+func (l *limitedMemory) Free() { l.buf = nil }
+
+// wasmLanguage maps a language's aliases to the name its interpreter is
+// configured under.
+func wasmLanguage(lang string) string {
+	switch strings.ToLower(lang) {
+	case "python", "py":
+		return "python"
+	case "javascript", "js", "node":
+		return "javascript"
+	case "bash", "sh":
+		return "bash"
+	default:
+		return strings.ToLower(lang)
+	}
+}
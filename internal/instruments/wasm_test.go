@@ -0,0 +1,102 @@
+package instruments
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wasmProbe builds testdata/wasmprobe for wasip1: a module that echoes
+// its program and probes what it can reach, or, with "hog", allocates
+// until it runs out of memory.
+func wasmProbe(t *testing.T) string {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("needs the go tool to build the probe module")
+	}
+	module := filepath.Join(t.TempDir(), "probe.wasm")
+	cmd := exec.Command(goTool, "build", "-o", module, "./testdata/wasmprobe")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build probe: %v\n%s", err, out)
+	}
+	return module
+}
+
+func TestWasmSandbox_Execute(t *testing.T) {
+	module := wasmProbe(t)
+	lib := t.TempDir()
+	os.WriteFile(filepath.Join(lib, "stdlib.txt"), []byte("batteries"), 0o600)
+	sb := NewWasmSandbox(WasmConfig{
+		Interpreters: map[string]WasmInterpreter{"python": {Module: module, Dirs: map[string]string{lib: "/usr/local/lib"}}},
+	})
+	if !sb.IsAvailable() {
+		t.Fatal("sandbox with a module should be available")
+	}
+
+	exec := sb.CreateLimitedSkillExecutor("py", "print(42)", SandboxConfig{MemoryMB: 128, Timeout: 30 * time.Second})
+	out, err := exec.Execute(context.Background(), SkillInput{Goal: "answer"})
+	if err != nil || !out.Success {
+		t.Fatalf("Execute = %+v, %v", out, err)
+	}
+	for _, want := range []string{
+		"program=print(42)",
+		`input={"goal":"answer"`,
+		"write workspace: <nil>",
+		"read lib: batteries <nil>",
+		"write lib denied: true",
+		"read host denied: true",
+	} {
+		if !strings.Contains(out.Result, want) {
+			t.Errorf("output lacks %q:\n%s", want, out.Result)
+		}
+	}
+
+	res, err := sb.Execute(context.Background(), "python", "exit(3)")
+	if err != nil || res.ExitCode != 3 || res.OOMKilled || res.TimedOut {
+		t.Errorf("exit(3) = %+v, %v", res, err)
+	}
+	if runs, errs := sb.Stats(); runs != 2 || errs != 1 {
+		t.Errorf("stats = %d runs, %d errors", runs, errs)
+	}
+
+	if _, err := sb.Execute(context.Background(), "ruby", "puts 1"); err == nil {
+		t.Error("expected an error for a language without interpreter")
+	}
+}
+
+func TestWasmSandbox_MemoryLimit(t *testing.T) {
+	sb := NewWasmSandbox(WasmConfig{
+		Interpreters: map[string]WasmInterpreter{"hog": {Module: wasmProbe(t), Args: []string{"hog"}}},
+		MemoryMB:     64,
+	})
+	res, err := sb.Execute(context.Background(), "hog", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OOMKilled || res.ExitCode == 0 {
+		t.Errorf("hog = %+v, want OOMKilled", res)
+	}
+
+	// A limit below the module's initial memory stops it before it runs.
+	sb.config.MemoryMB = 1
+	if res, err = sb.Execute(context.Background(), "hog", ""); err != nil || !res.OOMKilled {
+		t.Errorf("1 MB limit = %+v, %v", res, err)
+	}
+}
+
+func TestWasmSandbox_Unavailable(t *testing.T) {
+	sb := NewWasmSandbox(WasmConfig{Interpreters: map[string]WasmInterpreter{"python": {Module: "no-such.wasm"}}})
+	if sb.IsAvailable() {
+		t.Error("missing module reported available")
+	}
+	cfg := sb.Config()
+	if cfg.MemoryMB != 256 || cfg.Timeout != 30*time.Second || cfg.WorkDir != "/workspace" {
+		t.Errorf("defaults = %+v", cfg)
+	}
+}
@@ -0,0 +1,136 @@
+// Package locale provides agent-level and per-user timezone/locale settings.
+//
+// Everything time-sensitive (system prompts, heartbeat briefings, email
+// dates, generated UIs, active hours) resolves a Zone through the Resolver
+// so that "tomorrow at 9" means the user's tomorrow rather than UTC.
+package locale
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// Embed the IANA database so timezones resolve on hosts without
+	// /usr/share/zoneinfo (minimal containers, Windows).
+	_ "time/tzdata"
+)
+
+// DefaultLocale is used when no locale is configured.
+const DefaultLocale = "en-US"
+
+// Settings is a timezone/locale pair as written in config.
+type Settings struct {
+	Timezone string `json:"timezone,omitempty"` // IANA name, e.g. "Europe/Berlin"; "" = inherit
+	Locale   string `json:"locale,omitempty"`   // BCP 47 tag, e.g. "de-DE"; "" = inherit
+}
+
+// Zone is a resolved timezone and locale.
+type Zone struct {
+	Location *time.Location
+	Locale   string
+}
+
+// Now returns the current time in the zone.
+func (z Zone) Now() time.Time {
+	return time.Now().In(z.loc())
+}
+
+// In converts t to the zone's location.
+func (z Zone) In(t time.Time) time.Time {
+	return t.In(z.loc())
+}
+
+// ContextLine renders a one-line description of the user's current local
+// date and time for injection into the system context.
+func (z Zone) ContextLine(now time.Time) string {
+	local := now.In(z.loc())
+	return fmt.Sprintf("Current local time: %s (%s, UTC%s). Locale: %s. Interpret relative dates (\"today\", \"tomorrow at 9\") in this timezone.",
+		local.Format("Monday, 2006-01-02 15:04 MST"),
+		z.loc().String(),
+		local.Format("-07:00"),
+		z.Locale,
+	)
+}
+
+func (z Zone) loc() *time.Location {
+	if z.Location == nil {
+		return time.UTC
+	}
+	return z.Location
+}
+
+// Resolver maps users to their Zone, falling back to the agent default.
+// A nil *Resolver resolves everything to the host's local timezone.
+type Resolver struct {
+	mu    sync.RWMutex
+	agent Zone
+	users map[string]Zone
+}
+
+// NewResolver creates a Resolver with the agent-level settings. An empty
+// timezone means the host's local timezone; an empty locale DefaultLocale.
+func NewResolver(agent Settings) (*Resolver, error) {
+	z, err := resolve(agent, Zone{Location: time.Local, Locale: DefaultLocale})
+	if err != nil {
+		return nil, err
+	}
+	return &Resolver{agent: z, users: make(map[string]Zone)}, nil
+}
+
+// SetUser registers per-user settings. Empty fields inherit the agent value.
+func (r *Resolver) SetUser(userID string, s Settings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	z, err := resolve(s, r.agent)
+	if err != nil {
+		return fmt.Errorf("user %q: %w", userID, err)
+	}
+	r.users[userID] = z
+	return nil
+}
+
+// Agent returns the agent-level zone.
+func (r *Resolver) Agent() Zone {
+	if r == nil {
+		return Zone{Location: time.Local, Locale: DefaultLocale}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.agent
+}
+
+// For returns the zone for a user (matched case-insensitively), or the
+// agent zone if the user has no overrides.
+func (r *Resolver) For(userID string) Zone {
+	if r == nil {
+		return r.Agent()
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if z, ok := r.users[userID]; ok {
+		return z
+	}
+	for id, z := range r.users {
+		if strings.EqualFold(id, userID) {
+			return z
+		}
+	}
+	return r.agent
+}
+
+// resolve applies s on top of base.
+func resolve(s Settings, base Zone) (Zone, error) {
+	z := base
+	if tz := strings.TrimSpace(s.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return Zone{}, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		z.Location = loc
+	}
+	if l := strings.TrimSpace(s.Locale); l != "" {
+		z.Locale = strings.ReplaceAll(l, "_", "-")
+	}
+	return z, nil
+}
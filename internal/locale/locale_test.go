@@ -0,0 +1,80 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewResolver_Defaults(t *testing.T) {
+	r, err := NewResolver(Settings{})
+	if err != nil {
+		t.Fatalf("NewResolver: %v", err)
+	}
+	z := r.Agent()
+	if z.Location != time.Local {
+		t.Errorf("Location = %v, want time.Local", z.Location)
+	}
+	if z.Locale != DefaultLocale {
+		t.Errorf("Locale = %q, want %q", z.Locale, DefaultLocale)
+	}
+}
+
+func TestNewResolver_InvalidTimezone(t *testing.T) {
+	if _, err := NewResolver(Settings{Timezone: "Mars/Olympus"}); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
+
+func TestResolver_PerUserOverrides(t *testing.T) {
+	r, err := NewResolver(Settings{Timezone: "Europe/Berlin", Locale: "de_DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Agent().Locale; got != "de-DE" {
+		t.Errorf("agent locale = %q, want de-DE (normalized)", got)
+	}
+
+	if err := r.SetUser("Alice@example.com", Settings{Timezone: "America/New_York"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetUser("bob", Settings{Timezone: "Nowhere/City"}); err == nil {
+		t.Error("SetUser should reject unknown timezone")
+	}
+
+	alice := r.For("alice@example.com")
+	if alice.Location.String() != "America/New_York" {
+		t.Errorf("alice tz = %s", alice.Location)
+	}
+	if alice.Locale != "de-DE" {
+		t.Errorf("alice locale = %q, want inherited de-DE", alice.Locale)
+	}
+
+	if got := r.For("carol").Location.String(); got != "Europe/Berlin" {
+		t.Errorf("unknown user tz = %s, want agent default", got)
+	}
+}
+
+func TestZone_ContextLine(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	z := Zone{Location: tokyo, Locale: "ja-JP"}
+
+	// 2026-03-01 20:30 UTC is already Monday 05:30 in Tokyo.
+	line := z.ContextLine(time.Date(2026, 3, 1, 20, 30, 0, 0, time.UTC))
+	for _, want := range []string{"Monday, 2026-03-02 05:30", "Asia/Tokyo", "UTC+09:00", "ja-JP"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("ContextLine missing %q: %s", want, line)
+		}
+	}
+}
+
+func TestNilResolver(t *testing.T) {
+	var r *Resolver
+	z := r.For("anyone")
+	if z.Location != time.Local || z.Locale != DefaultLocale {
+		t.Errorf("nil resolver zone = %+v", z)
+	}
+	if z.Now().Location() != time.Local {
+		t.Error("Now should be in local time")
+	}
+}
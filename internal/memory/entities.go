@@ -0,0 +1,443 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EntityKind is what an entity in the contact book is.
+type EntityKind string
+
+const (
+	EntityPerson       EntityKind = "person"
+	EntityOrganization EntityKind = "organization"
+)
+
+// ParseEntityKind accepts "person" (or "", "contact") and "organization"
+// (or "org", "company").
+func ParseEntityKind(s string) (EntityKind, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "person", "contact":
+		return EntityPerson, nil
+	case "organization", "organisation", "org", "company":
+		return EntityOrganization, nil
+	}
+	return "", fmt.Errorf("unknown entity kind %q (want person or organization)", s)
+}
+
+// Entity is a person or organization the agent has encountered: a
+// contact book record kept per owner (the sender whose book it is; ""
+// for entries shared by every sender).
+type Entity struct {
+	ID              int64      `json:"id"`
+	Kind            EntityKind `json:"kind"`
+	Name            string     `json:"name"`
+	Aliases         []string   `json:"aliases,omitempty"`
+	Email           string     `json:"email,omitempty"`
+	Relationship    string     `json:"relationship,omitempty"` // to the owner: "colleague", "landlord"
+	Preferences     string     `json:"preferences,omitempty"`  // tone, language, how to address them
+	Owner           string     `json:"owner,omitempty"`
+	LastInteraction time.Time  `json:"last_interaction,omitzero"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Describe renders e as one line for a prompt or listing, e.g. "Anna
+// Petrova <anna@example.com> — colleague; prefers: informal, first names".
+func (e Entity) Describe() string {
+	var b strings.Builder
+	b.WriteString(e.Name)
+	if e.Kind == EntityOrganization {
+		b.WriteString(" (organization)")
+	}
+	if e.Email != "" {
+		fmt.Fprintf(&b, " <%s>", e.Email)
+	}
+	var notes []string
+	if len(e.Aliases) > 0 {
+		notes = append(notes, "also "+strings.Join(e.Aliases, ", "))
+	}
+	if e.Relationship != "" {
+		notes = append(notes, e.Relationship)
+	}
+	if e.Preferences != "" {
+		notes = append(notes, "prefers: "+e.Preferences)
+	}
+	if !e.LastInteraction.IsZero() {
+		notes = append(notes, "last interaction "+e.LastInteraction.Format("2006-01-02"))
+	}
+	if len(notes) > 0 {
+		b.WriteString(" — " + strings.Join(notes, "; "))
+	}
+	return b.String()
+}
+
+// EntityStore is the contact book: entities plus links to the long-term
+// memory entries that mention them.
+type EntityStore struct {
+	db *sql.DB
+}
+
+// NewEntityStore creates the entities and entity_links tables if needed.
+// db is the long-term memory database.
+func NewEntityStore(db *sql.DB) (*EntityStore, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS entities (
+		id               INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner            TEXT NOT NULL DEFAULT '',
+		kind             TEXT NOT NULL DEFAULT 'person',
+		name             TEXT NOT NULL,
+		name_key         TEXT NOT NULL,
+		aliases          TEXT NOT NULL DEFAULT '',
+		email            TEXT NOT NULL DEFAULT '',
+		relationship     TEXT NOT NULL DEFAULT '',
+		preferences      TEXT NOT NULL DEFAULT '',
+		last_interaction DATETIME,
+		updated_at       DATETIME NOT NULL,
+		UNIQUE (owner, name_key)
+	);
+	CREATE TABLE IF NOT EXISTS entity_links (
+		entity_id INTEGER NOT NULL,
+		memory_id TEXT NOT NULL,
+		PRIMARY KEY (entity_id, memory_id)
+	);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("entity store: create tables: %w", err)
+	}
+	return &EntityStore{db: db}, nil
+}
+
+const entityColumns = `id, owner, kind, name, aliases, email, relationship, preferences, last_interaction, updated_at`
+
+// Upsert adds e to its owner's contact book, or merges it into the entry
+// with the same name, alias or email: non-empty fields replace stored
+// ones, aliases are combined and the later interaction is kept.
+func (s *EntityStore) Upsert(e Entity) (Entity, error) {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return Entity{}, fmt.Errorf("entity store: empty name")
+	}
+	if e.Kind == "" {
+		e.Kind = EntityPerson
+	}
+	e.Email = strings.ToLower(strings.TrimSpace(e.Email))
+
+	book, err := s.list(`owner = ?`, e.Owner)
+	if err != nil {
+		return Entity{}, err
+	}
+	if old, ok := matchEntity(book, e); ok {
+		e = mergeEntity(old, e)
+	}
+	e.UpdatedAt = time.Now().UTC()
+
+	var last any
+	if !e.LastInteraction.IsZero() {
+		last = e.LastInteraction.UTC()
+	}
+	aliases := strings.Join(e.Aliases, ",")
+	if e.ID == 0 {
+		res, err := s.db.Exec(
+			`INSERT INTO entities (owner, kind, name, name_key, aliases, email, relationship, preferences, last_interaction, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.Owner, e.Kind, e.Name, entityKey(e.Name), aliases, e.Email, e.Relationship, e.Preferences, last, e.UpdatedAt)
+		if err != nil {
+			return Entity{}, fmt.Errorf("entity store: insert %q: %w", e.Name, err)
+		}
+		e.ID, _ = res.LastInsertId()
+		return e, nil
+	}
+	_, err = s.db.Exec(
+		`UPDATE entities SET kind = ?, name = ?, name_key = ?, aliases = ?, email = ?, relationship = ?, preferences = ?, last_interaction = ?, updated_at = ?
+		 WHERE id = ?`,
+		e.Kind, e.Name, entityKey(e.Name), aliases, e.Email, e.Relationship, e.Preferences, last, e.UpdatedAt, e.ID)
+	if err != nil {
+		return Entity{}, fmt.Errorf("entity store: update %q: %w", e.Name, err)
+	}
+	return e, nil
+}
+
+// matchEntity finds the entry in book that e describes: same email, a
+// name or alias of one matching a name or alias of the other, or a bare
+// first name ("Anna" and "Anna Petrova") that only one person in the book
+// shares with e.
+func matchEntity(book []Entity, e Entity) (Entity, bool) {
+	names := entityNames(e)
+	var byFirst []Entity
+	for _, old := range book {
+		if e.Email != "" && old.Email == e.Email {
+			return old, true
+		}
+		for n := range entityNames(old) {
+			if names[n] {
+				return old, true
+			}
+		}
+		if old.Kind == EntityPerson && e.Kind == EntityPerson && sameFirstName(old.Name, e.Name) {
+			byFirst = append(byFirst, old)
+		}
+	}
+	if len(byFirst) == 1 {
+		return byFirst[0], true
+	}
+	return Entity{}, false
+}
+
+// sameFirstName reports whether one of a and b is a single word that is
+// the other's first name.
+func sameFirstName(a, b string) bool {
+	fa, _, fullA := strings.Cut(entityKey(a), " ")
+	fb, _, fullB := strings.Cut(entityKey(b), " ")
+	return fa == fb && fullA != fullB
+}
+
+func entityNames(e Entity) map[string]bool {
+	names := map[string]bool{entityKey(e.Name): true}
+	for _, a := range e.Aliases {
+		names[entityKey(a)] = true
+	}
+	return names
+}
+
+// mergeEntity folds update into old. The longer name wins ("Anna" then
+// "Anna Petrova"), and the other becomes an alias.
+func mergeEntity(old, update Entity) Entity {
+	merged := old
+	aliases := append(append([]string(nil), old.Aliases...), update.Aliases...)
+	if entityKey(update.Name) != entityKey(old.Name) {
+		if len(update.Name) > len(old.Name) {
+			aliases = append(aliases, old.Name)
+			merged.Name = update.Name
+		} else {
+			aliases = append(aliases, update.Name)
+		}
+	}
+	merged.Aliases = nil
+	seen := map[string]bool{entityKey(merged.Name): true}
+	for _, a := range aliases {
+		a = strings.TrimSpace(a)
+		if k := entityKey(a); a != "" && !seen[k] {
+			seen[k] = true
+			merged.Aliases = append(merged.Aliases, a)
+		}
+	}
+	if update.Kind != "" && update.Kind != EntityPerson {
+		merged.Kind = update.Kind
+	}
+	if update.Email != "" {
+		merged.Email = update.Email
+	}
+	if update.Relationship != "" {
+		merged.Relationship = update.Relationship
+	}
+	if update.Preferences != "" {
+		merged.Preferences = update.Preferences
+	}
+	if update.LastInteraction.After(merged.LastInteraction) {
+		merged.LastInteraction = update.LastInteraction
+	}
+	return merged
+}
+
+// Get returns the owner's entity with the given name or alias, or nil.
+func (s *EntityStore) Get(owner, name string) (*Entity, error) {
+	book, err := s.list(`owner = ?`, owner)
+	if err != nil {
+		return nil, err
+	}
+	key := entityKey(name)
+	for _, e := range book {
+		if entityNames(e)[key] || (e.Email != "" && e.Email == key) {
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+// List returns the owner's contact book, most recently seen first.
+func (s *EntityStore) List(owner string) ([]Entity, error) {
+	book, err := s.list(`owner = ?`, owner)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(book, func(i, j int) bool {
+		return book[i].LastInteraction.After(book[j].LastInteraction)
+	})
+	return book, nil
+}
+
+// Find returns the entities visible to owner (their own and shared ones)
+// that text mentions by name, alias, email or, for people, first name.
+// An owner's entry shadows a shared one with the same name.
+func (s *EntityStore) Find(owner, text string) ([]Entity, error) {
+	book, err := s.list(`owner = ? OR owner = ''`, owner)
+	if err != nil {
+		return nil, err
+	}
+	lower := strings.ToLower(text)
+	own := make(map[string]bool)
+	for _, e := range book {
+		if e.Owner == owner {
+			own[entityKey(e.Name)] = true
+		}
+	}
+	var out []Entity
+	for _, e := range book {
+		if e.Owner != owner && own[entityKey(e.Name)] {
+			continue
+		}
+		if mentions(lower, e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// mentions reports whether lower (lower-cased text) mentions e.
+func mentions(lower string, e Entity) bool {
+	if e.Email != "" && strings.Contains(lower, e.Email) {
+		return true
+	}
+	for n := range entityNames(e) {
+		if containsWord(lower, n) {
+			return true
+		}
+	}
+	if e.Kind == EntityPerson {
+		if first, _, ok := strings.Cut(entityKey(e.Name), " "); ok && len([]rune(first)) >= 3 {
+			return containsWord(lower, first)
+		}
+	}
+	return false
+}
+
+// containsWord reports whether word occurs in text on word boundaries.
+func containsWord(text, word string) bool {
+	if word == "" {
+		return false
+	}
+	for i := 0; ; {
+		j := strings.Index(text[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if !wordRuneBefore(text, start) && !wordRuneAt(text, end) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func wordRuneBefore(text string, i int) bool {
+	r, size := utf8.DecodeLastRuneInString(text[:i])
+	return size > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func wordRuneAt(text string, i int) bool {
+	r, size := utf8.DecodeRuneInString(text[i:])
+	return size > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// Delete removes the owner's entity with the given name or alias and its
+// memory links. It reports whether one was found.
+func (s *EntityStore) Delete(owner, name string) (bool, error) {
+	e, err := s.Get(owner, name)
+	if err != nil || e == nil {
+		return false, err
+	}
+	if _, err := s.db.Exec(`DELETE FROM entity_links WHERE entity_id = ?`, e.ID); err != nil {
+		return false, fmt.Errorf("entity store: delete links: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM entities WHERE id = ?`, e.ID); err != nil {
+		return false, fmt.Errorf("entity store: delete %q: %w", e.Name, err)
+	}
+	return true, nil
+}
+
+// Link records that the long-term memory entry memoryID mentions the
+// entity, and that the entity was interacted with at t.
+func (s *EntityStore) Link(entityID int64, memoryID string, t time.Time) error {
+	if _, err := s.db.Exec(
+		`INSERT OR IGNORE INTO entity_links (entity_id, memory_id) VALUES (?, ?)`, entityID, memoryID); err != nil {
+		return fmt.Errorf("entity store: link: %w", err)
+	}
+	_, err := s.db.Exec(
+		`UPDATE entities SET last_interaction = ? WHERE id = ? AND (last_interaction IS NULL OR last_interaction < ?)`,
+		t.UTC(), entityID, t.UTC())
+	if err != nil {
+		return fmt.Errorf("entity store: touch: %w", err)
+	}
+	return nil
+}
+
+// Mentions returns up to limit long-term memory entries linked to the
+// entity that viewer may see, newest first.
+func (s *EntityStore) Mentions(entityID int64, viewer Viewer, limit int) ([]LongTermEntry, error) {
+	return s.mentions(entityID, &viewer, limit)
+}
+
+// AllMentions is Mentions without visibility checks, for the owner's own
+// tools such as `overhuman contacts show`.
+func (s *EntityStore) AllMentions(entityID int64, limit int) ([]LongTermEntry, error) {
+	return s.mentions(entityID, nil, limit)
+}
+
+func (s *EntityStore) mentions(entityID int64, viewer *Viewer, limit int) ([]LongTermEntry, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+	where := `l.entity_id = ?`
+	args := []any{entityID}
+	if viewer != nil {
+		where += ` AND ` + visibleSQL
+		args = append(args, viewer.sqlArgs()...)
+	}
+	rows, err := s.db.Query(
+		`SELECT `+ltmColumns+`
+		 FROM long_term_memory m
+		 JOIN entity_links l ON l.memory_id = m.id
+		 WHERE `+where+`
+		 ORDER BY m.created_at DESC
+		 LIMIT ?`,
+		append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLongTermRows(rows)
+}
+
+func (s *EntityStore) list(where string, args ...any) ([]Entity, error) {
+	rows, err := s.db.Query(`SELECT `+entityColumns+` FROM entities WHERE `+where+` ORDER BY name_key`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("entity store: list: %w", err)
+	}
+	defer rows.Close()
+	var out []Entity
+	for rows.Next() {
+		var e Entity
+		var aliases string
+		var last sql.NullTime
+		if err := rows.Scan(&e.ID, &e.Owner, &e.Kind, &e.Name, &aliases, &e.Email, &e.Relationship, &e.Preferences, &last, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("entity store: scan: %w", err)
+		}
+		if aliases != "" {
+			e.Aliases = strings.Split(aliases, ",")
+		}
+		if last.Valid {
+			e.LastInteraction = last.Time
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// entityKey is the case-insensitive form names and aliases are matched by.
+func entityKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -1,11 +1,15 @@
 package memory
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/overhuman/overhuman/internal/storage"
 )
 
 // LongTermEntry represents a summarised memory stored persistently.
@@ -15,24 +19,69 @@ type LongTermEntry struct {
 	Tags        []string  `json:"tags"`
 	SourceRunID string    `json:"source_run_id"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	// Owner and Channel are the sender and channel the entry was learned
+	// from; Visibility limits who it is retrieved for (see Viewer).
+	Owner      string     `json:"owner,omitempty"`
+	Channel    string     `json:"channel,omitempty"`
+	Visibility Visibility `json:"visibility,omitempty"`
+
+	// Topic is the named memory namespace ("health", "novel-draft") the
+	// entry was stored under, if any (see NormalizeTopic).
+	Topic string `json:"topic,omitempty"`
 }
 
 // LongTermMemory provides SQLite-backed persistent memory with FTS5 full-text search.
 type LongTermMemory struct {
-	db *sql.DB
+	db   *sql.DB
+	path string
+
+	// Statements every run executes, prepared on first use and reused.
+	stmtMu sync.Mutex
+	stmts  map[string]*sql.Stmt
 }
 
-// NewLongTermMemory opens (or creates) a SQLite database at dbPath and
-// ensures the required tables and FTS5 virtual table exist.
-func NewLongTermMemory(dbPath string) (*LongTermMemory, error) {
-	db, err := sql.Open("sqlite", dbPath)
+const (
+	ltmColumns  = `m.id, m.summary, m.tags, m.source_run_id, m.created_at, m.owner, m.channel, m.visibility, m.topic`
+	ltmStoreSQL = `INSERT OR REPLACE INTO long_term_memory (id, summary, tags, source_run_id, created_at, owner, channel, visibility, topic)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	ltmSearchSQL = `SELECT ` + ltmColumns + `
+		 FROM long_term_memory m
+		 JOIN long_term_memory_fts f ON m.id = f.id
+		 WHERE long_term_memory_fts MATCH ?
+		 ORDER BY rank
+		 LIMIT ?`
+	ltmSearchAsSQL = `SELECT ` + ltmColumns + `
+		 FROM long_term_memory m
+		 JOIN long_term_memory_fts f ON m.id = f.id
+		 WHERE long_term_memory_fts MATCH ? AND ` + visibleSQL + `
+		 ORDER BY rank
+		 LIMIT ?`
+)
+
+// stmt returns the prepared statement for query, preparing it once.
+func (l *LongTermMemory) stmt(query string) (*sql.Stmt, error) {
+	l.stmtMu.Lock()
+	defer l.stmtMu.Unlock()
+	if st, ok := l.stmts[query]; ok {
+		return st, nil
+	}
+	st, err := l.db.Prepare(query)
 	if err != nil {
 		return nil, err
 	}
+	if l.stmts == nil {
+		l.stmts = make(map[string]*sql.Stmt)
+	}
+	l.stmts[query] = st
+	return st, nil
+}
 
-	// Enable WAL mode for better concurrent read performance.
-	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		db.Close()
+// NewLongTermMemory opens (or creates) a SQLite database at dbPath and
+// ensures the required tables and FTS5 virtual table exist.
+func NewLongTermMemory(dbPath string) (*LongTermMemory, error) {
+	db, err := storage.OpenSQLite(dbPath)
+	if err != nil {
 		return nil, err
 	}
 
@@ -42,7 +91,11 @@ func NewLongTermMemory(dbPath string) (*LongTermMemory, error) {
 		summary     TEXT NOT NULL,
 		tags        TEXT NOT NULL DEFAULT '',
 		source_run_id TEXT NOT NULL DEFAULT '',
-		created_at  DATETIME NOT NULL
+		created_at  DATETIME NOT NULL,
+		owner       TEXT NOT NULL DEFAULT '',
+		channel     TEXT NOT NULL DEFAULT '',
+		visibility  TEXT NOT NULL DEFAULT '',
+		topic       TEXT NOT NULL DEFAULT ''
 	);
 
 	CREATE VIRTUAL TABLE IF NOT EXISTS long_term_memory_fts USING fts5(
@@ -75,36 +128,73 @@ func NewLongTermMemory(dbPath string) (*LongTermMemory, error) {
 		db.Close()
 		return nil, err
 	}
+	if err := migrateLongTerm(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &LongTermMemory{db: db, path: dbPath}, nil
+}
 
-	return &LongTermMemory{db: db}, nil
+// OpenLongTermMemoryReadOnly opens an existing database for searching
+// without creating tables, so it is safe while the daemon holds the
+// database. Writes fail.
+func OpenLongTermMemoryReadOnly(dbPath string) (*LongTermMemory, error) {
+	db, err := storage.OpenSQLiteReadOnly(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLongTermSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &LongTermMemory{db: db, path: dbPath}, nil
 }
 
 // Store persists a LongTermEntry into the database.
 func (l *LongTermMemory) Store(entry LongTermEntry) error {
 	tags := strings.Join(entry.Tags, ",")
-	_, err := l.db.Exec(
-		`INSERT OR REPLACE INTO long_term_memory (id, summary, tags, source_run_id, created_at)
-		 VALUES (?, ?, ?, ?, ?)`,
-		entry.ID, entry.Summary, tags, entry.SourceRunID, entry.CreatedAt,
-	)
+	st, err := l.stmt(ltmStoreSQL)
+	if err != nil {
+		return err
+	}
+	_, err = st.Exec(entry.ID, entry.Summary, tags, entry.SourceRunID, entry.CreatedAt,
+		entry.Owner, entry.Channel, string(entry.Visibility), NormalizeTopic(entry.Topic))
 	return err
 }
 
 // Search performs a full-text search using FTS5 MATCH and returns up to limit results.
+// It ignores visibility; use SearchAs for anything shown to a user.
 func (l *LongTermMemory) Search(query string, limit int) ([]LongTermEntry, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
-	rows, err := l.db.Query(
-		`SELECT m.id, m.summary, m.tags, m.source_run_id, m.created_at
-		 FROM long_term_memory m
-		 JOIN long_term_memory_fts f ON m.id = f.id
-		 WHERE long_term_memory_fts MATCH ?
-		 ORDER BY rank
-		 LIMIT ?`,
-		query, limit,
-	)
+	st, err := l.stmt(ltmSearchSQL)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := st.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLongTermRows(rows)
+}
+
+// SearchAs is Search restricted to the entries viewer may see.
+func (l *LongTermMemory) SearchAs(viewer Viewer, query string, limit int) ([]LongTermEntry, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	st, err := l.stmt(ltmSearchAsSQL)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]any{query}, viewer.sqlArgs()...)
+	rows, err := st.Query(append(args, limit)...)
 	if err != nil {
 		return nil, err
 	}
@@ -120,8 +210,8 @@ func (l *LongTermMemory) GetAll(limit int) ([]LongTermEntry, error) {
 	}
 
 	rows, err := l.db.Query(
-		`SELECT id, summary, tags, source_run_id, created_at
-		 FROM long_term_memory
+		`SELECT `+ltmColumns+`
+		 FROM long_term_memory m
 		 ORDER BY created_at DESC
 		 LIMIT ?`,
 		limit,
@@ -134,8 +224,136 @@ func (l *LongTermMemory) GetAll(limit int) ([]LongTermEntry, error) {
 	return scanLongTermRows(rows)
 }
 
-// Close closes the underlying database connection.
+// Forget deletes the entry with id together with its contact links and
+// embedding, so a wrong memory stops being recalled. It reports whether
+// the entry existed.
+func (l *LongTermMemory) Forget(id string) (bool, error) {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`DELETE FROM long_term_memory WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := dropDependents(tx, id, ""); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// dropDependents deletes the contact links and embedding of entry id, or
+// moves its links to entry to when that is set. The link and vector tables
+// belong to other stores and may not exist.
+func dropDependents(tx *sql.Tx, id, to string) error {
+	var stmts []string
+	if to != "" {
+		stmts = append(stmts, `UPDATE OR IGNORE entity_links SET memory_id = ? WHERE memory_id = ?`)
+	}
+	stmts = append(stmts,
+		`DELETE FROM entity_links WHERE memory_id = ?`,
+		`DELETE FROM memory_vectors WHERE kind = '`+VectorKindLTM+`' AND id = ?`,
+	)
+	for i, q := range stmts {
+		args := []any{id}
+		if to != "" && i == 0 {
+			args = []any{to, id}
+		}
+		if _, err := tx.Exec(q, args...); err != nil && !strings.Contains(err.Error(), "no such table") {
+			return err
+		}
+	}
+	return nil
+}
+
+// Consolidate merges entries that repeat the same summary for the same
+// owner, channel, visibility and topic into the newest of them, which
+// gains their tags and contact links. It returns the number of entries
+// merged away.
+func (l *LongTermMemory) Consolidate(ctx context.Context) (int, error) {
+	rows, err := l.db.QueryContext(ctx, `SELECT `+ltmColumns+` FROM long_term_memory m ORDER BY created_at DESC, id`)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := scanLongTermRows(rows)
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	type group struct {
+		keep LongTermEntry
+		tags []string
+		dups []string
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, e := range entries {
+		key := strings.Join([]string{strings.ToLower(strings.Join(strings.Fields(e.Summary), " ")),
+			e.Owner, e.Channel, string(e.Visibility), e.Topic}, "\x00")
+		g := byKey[key]
+		if g == nil {
+			g = &group{keep: e, tags: e.Tags}
+			byKey[key] = g
+			groups = append(groups, g)
+			continue
+		}
+		g.dups = append(g.dups, e.ID)
+		for _, t := range e.Tags {
+			if !slices.Contains(g.tags, t) {
+				g.tags = append(g.tags, t)
+			}
+		}
+	}
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	merged := 0
+	for _, g := range groups {
+		if len(g.dups) == 0 {
+			continue
+		}
+		for _, id := range g.dups {
+			if err := dropDependents(tx, id, g.keep.ID); err != nil {
+				return 0, err
+			}
+			if _, err := tx.Exec(`DELETE FROM long_term_memory WHERE id = ?`, id); err != nil {
+				return 0, err
+			}
+			merged++
+		}
+		if len(g.tags) != len(g.keep.Tags) {
+			if _, err := tx.Exec(`UPDATE long_term_memory SET tags = ? WHERE id = ?`, strings.Join(g.tags, ","), g.keep.ID); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return merged, tx.Commit()
+}
+
+// Count returns the number of stored entries.
+func (l *LongTermMemory) Count() (int, error) {
+	var n int
+	err := l.db.QueryRow(`SELECT COUNT(*) FROM long_term_memory`).Scan(&n)
+	return n, err
+}
+
+// Close runs PRAGMA optimize and closes the underlying database connection.
 func (l *LongTermMemory) Close() error {
+	l.stmtMu.Lock()
+	for _, st := range l.stmts {
+		st.Close()
+	}
+	l.stmts = nil
+	l.stmtMu.Unlock()
+	_, _ = l.db.Exec("PRAGMA optimize")
 	return l.db.Close()
 }
 
@@ -151,7 +369,7 @@ func scanLongTermRows(rows *sql.Rows) ([]LongTermEntry, error) {
 		var e LongTermEntry
 		var tags string
 		var createdAt time.Time
-		if err := rows.Scan(&e.ID, &e.Summary, &tags, &e.SourceRunID, &createdAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Summary, &tags, &e.SourceRunID, &createdAt, &e.Owner, &e.Channel, &e.Visibility, &e.Topic); err != nil {
 			return nil, err
 		}
 		if tags != "" {
@@ -162,3 +380,63 @@ func scanLongTermRows(rows *sql.Rows) ([]LongTermEntry, error) {
 	}
 	return entries, rows.Err()
 }
+
+// migrateLongTerm adds the columns in addedColumns to databases created
+// before they existed.
+func migrateLongTerm(db *sql.DB) error {
+	have, err := ltmColumnSet(db)
+	if err != nil {
+		return err
+	}
+	for _, col := range addedColumns {
+		if have[col] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE long_term_memory ADD COLUMN ` + col + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("long-term memory: add %s column: %w", col, err)
+		}
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_long_term_memory_topic ON long_term_memory(topic)`)
+	return err
+}
+
+// checkLongTermSchema reports an error if db predates a column in
+// addedColumns; read-only handles cannot migrate it.
+func checkLongTermSchema(db *sql.DB) error {
+	have, err := ltmColumnSet(db)
+	if err != nil {
+		return err
+	}
+	for _, col := range addedColumns {
+		if !have[col] {
+			return fmt.Errorf("long-term memory: database has no %s column; start overhuman once to upgrade it", col)
+		}
+	}
+	return nil
+}
+
+// addedColumns are the long_term_memory columns added after the first
+// release: the visibility ACL and the topic.
+var addedColumns = []string{"owner", "channel", "visibility", "topic"}
+
+func ltmColumnSet(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`PRAGMA table_info(long_term_memory)`)
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		have[name] = true
+	}
+	rows.Close()
+	return have, rows.Err()
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+)
+
+// VacuumFreeRatio is the share of free pages above which Maintain vacuums
+// the database to give the space back to the filesystem.
+const VacuumFreeRatio = 0.2
+
+// DBHealth describes the state of the memory database.
+type DBHealth struct {
+	Path         string    `json:"path"`
+	SizeBytes    int64     `json:"size_bytes"`    // main database file
+	WALBytes     int64     `json:"wal_bytes"`     // write-ahead log
+	JournalMode  string    `json:"journal_mode"`  // "wal" when tuned
+	PageSize     int64     `json:"page_size"`     // bytes
+	PageCount    int64     `json:"page_count"`    // pages in use or free
+	FreePages    int64     `json:"free_pages"`    // reclaimable by VACUUM
+	LastOptimize time.Time `json:"last_optimize"` // zero = never
+	LastVacuum   time.Time `json:"last_vacuum"`   // zero = never
+}
+
+// FreeRatio is the share of pages that are free.
+func (h DBHealth) FreeRatio() float64 {
+	if h.PageCount == 0 {
+		return 0
+	}
+	return float64(h.FreePages) / float64(h.PageCount)
+}
+
+// MaintenanceResult reports what Maintain did.
+type MaintenanceResult struct {
+	Optimized      bool  `json:"optimized"`
+	Checkpointed   bool  `json:"checkpointed"`
+	Vacuumed       bool  `json:"vacuumed"`
+	ReclaimedBytes int64 `json:"reclaimed_bytes"`
+}
+
+func ensureMaintenanceTable(db *sql.DB) error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS db_maintenance (
+		task   TEXT PRIMARY KEY,
+		ran_at DATETIME NOT NULL
+	);`)
+	return err
+}
+
+// Health reads file sizes and page statistics of the database.
+func (l *LongTermMemory) Health() (DBHealth, error) {
+	h := DBHealth{Path: l.path}
+	if fi, err := os.Stat(l.path); err == nil {
+		h.SizeBytes = fi.Size()
+	}
+	if fi, err := os.Stat(l.path + "-wal"); err == nil {
+		h.WALBytes = fi.Size()
+	}
+	for pragma, dst := range map[string]*int64{
+		"page_size":      &h.PageSize,
+		"page_count":     &h.PageCount,
+		"freelist_count": &h.FreePages,
+	} {
+		if err := l.db.QueryRow("PRAGMA " + pragma).Scan(dst); err != nil {
+			return h, fmt.Errorf("db health: %s: %w", pragma, err)
+		}
+	}
+	if err := l.db.QueryRow("PRAGMA journal_mode").Scan(&h.JournalMode); err != nil {
+		return h, fmt.Errorf("db health: journal_mode: %w", err)
+	}
+	if err := ensureMaintenanceTable(l.db); err != nil {
+		return h, fmt.Errorf("db health: %w", err)
+	}
+	rows, err := l.db.Query(`SELECT task, ran_at FROM db_maintenance`)
+	if err != nil {
+		return h, fmt.Errorf("db health: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var task string
+		var at time.Time
+		if err := rows.Scan(&task, &at); err != nil {
+			return h, err
+		}
+		switch task {
+		case "optimize":
+			h.LastOptimize = at
+		case "vacuum":
+			h.LastVacuum = at
+		}
+	}
+	return h, rows.Err()
+}
+
+// Maintain runs PRAGMA optimize, truncates the WAL and — when at least
+// VacuumFreeRatio of the pages are free, or forceVacuum is set — VACUUMs.
+// VACUUM rewrites the whole file and blocks writers meanwhile, so it is
+// skipped while there is little to gain.
+func (l *LongTermMemory) Maintain(ctx context.Context, forceVacuum bool) (MaintenanceResult, error) {
+	var res MaintenanceResult
+	if err := ensureMaintenanceTable(l.db); err != nil {
+		return res, fmt.Errorf("db maintenance: %w", err)
+	}
+	record := func(task string) error {
+		_, err := l.db.ExecContext(ctx, `INSERT OR REPLACE INTO db_maintenance (task, ran_at) VALUES (?, ?)`, task, time.Now().UTC())
+		return err
+	}
+
+	if _, err := l.db.ExecContext(ctx, "PRAGMA optimize"); err != nil {
+		return res, fmt.Errorf("db maintenance: optimize: %w", err)
+	}
+	res.Optimized = true
+	if err := record("optimize"); err != nil {
+		return res, fmt.Errorf("db maintenance: %w", err)
+	}
+
+	before, err := l.Health()
+	if err != nil {
+		return res, err
+	}
+	if forceVacuum || before.FreeRatio() >= VacuumFreeRatio {
+		if _, err := l.db.ExecContext(ctx, "VACUUM"); err != nil {
+			return res, fmt.Errorf("db maintenance: vacuum: %w", err)
+		}
+		res.Vacuumed = true
+		if err := record("vacuum"); err != nil {
+			return res, fmt.Errorf("db maintenance: %w", err)
+		}
+	}
+
+	// A busy database may not checkpoint fully; that is retried next time.
+	if _, err := l.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err == nil {
+		res.Checkpointed = true
+	}
+
+	if after, err := l.Health(); err == nil {
+		res.ReclaimedBytes = (before.SizeBytes + before.WALBytes) - (after.SizeBytes + after.WALBytes)
+	}
+	return res, nil
+}
+
+// MaintenanceRuns returns when each maintenance task last ran, by task
+// name: Maintain's "optimize" and "vacuum" and whatever RecordMaintenance
+// recorded.
+func (l *LongTermMemory) MaintenanceRuns() (map[string]time.Time, error) {
+	if err := ensureMaintenanceTable(l.db); err != nil {
+		return nil, fmt.Errorf("db maintenance: %w", err)
+	}
+	rows, err := l.db.Query(`SELECT task, ran_at FROM db_maintenance`)
+	if err != nil {
+		return nil, fmt.Errorf("db maintenance: %w", err)
+	}
+	defer rows.Close()
+	runs := make(map[string]time.Time)
+	for rows.Next() {
+		var task string
+		var at time.Time
+		if err := rows.Scan(&task, &at); err != nil {
+			return nil, err
+		}
+		runs[task] = at
+	}
+	return runs, rows.Err()
+}
+
+// RecordMaintenance records that task ran at t, so the schedule survives
+// restarts.
+func (l *LongTermMemory) RecordMaintenance(task string, t time.Time) error {
+	if err := ensureMaintenanceTable(l.db); err != nil {
+		return fmt.Errorf("db maintenance: %w", err)
+	}
+	_, err := l.db.Exec(`INSERT OR REPLACE INTO db_maintenance (task, ran_at) VALUES (?, ?)`, task, t.UTC())
+	return err
+}
@@ -1,12 +1,18 @@
 package memory
 
 import (
+	"context"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/storage"
 )
 
 // ---------------------------------------------------------------------------
@@ -88,6 +94,24 @@ func TestShortTermMemory_Clear(t *testing.T) {
 	}
 }
 
+func TestShortTermMemory_RemoveSession(t *testing.T) {
+	stm := NewShortTermMemory(4)
+	for i := 0; i < 6; i++ {
+		stm.AddWithSession("user", fmt.Sprintf("msg%d", i), nil, fmt.Sprintf("s%d", i%2))
+	}
+	if n := stm.RemoveSession("s0"); n != 2 {
+		t.Fatalf("RemoveSession = %d, want 2", n)
+	}
+	all := stm.GetAll()
+	if len(all) != 2 || all[0].Content != "msg3" || all[1].Content != "msg5" {
+		t.Fatalf("remaining = %+v", all)
+	}
+	stm.Add("user", "msg6", nil)
+	if got := stm.GetRecent(1); got[0].Content != "msg6" {
+		t.Errorf("add after RemoveSession: %+v", got)
+	}
+}
+
 func TestShortTermMemory_ConcurrentAccess(t *testing.T) {
 	stm := NewShortTermMemory(100)
 	var wg sync.WaitGroup
@@ -131,6 +155,26 @@ func TestShortTermMemory_DefaultMaxSize(t *testing.T) {
 	}
 }
 
+func TestShortTermMemory_Sessions(t *testing.T) {
+	stm := NewShortTermMemory(10)
+	stm.AddWithSession("user", "hi", map[string]string{"channel": "TELEGRAM", "sender": "42"}, "TELEGRAM/42")
+	stm.AddWithSession("assistant", "hello", nil, "TELEGRAM/42")
+	stm.Add("system", "no session", nil)
+	time.Sleep(time.Millisecond)
+	stm.AddWithSession("user", "status?", map[string]string{"channel": "API", "sender": "bob"}, "API/bob")
+
+	got := stm.Sessions()
+	if len(got) != 2 {
+		t.Fatalf("Sessions() = %+v, want 2", got)
+	}
+	if got[0].ID != "API/bob" || got[0].Sender != "bob" || got[0].Entries != 1 {
+		t.Errorf("most recent = %+v", got[0])
+	}
+	if got[1].ID != "TELEGRAM/42" || got[1].Channel != "TELEGRAM" || got[1].Entries != 2 {
+		t.Errorf("older = %+v", got[1])
+	}
+}
+
 func TestShortTermMemory_AddWithSession(t *testing.T) {
 	stm := NewShortTermMemory(20)
 
@@ -292,6 +336,223 @@ func TestLongTermMemory_Close(t *testing.T) {
 	}
 }
 
+func TestLongTermMemory_ReadOnlyAlongsideWriter(t *testing.T) {
+	path := tempDBPath(t)
+	if _, err := OpenLongTermMemoryReadOnly(path); err == nil {
+		t.Fatal("expected error opening a missing database read-only")
+	}
+
+	rw, err := NewLongTermMemory(path)
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer rw.Close()
+	rw.Store(LongTermEntry{ID: "lt-1", Summary: "Quarterly tax filing steps", CreatedAt: time.Now()})
+
+	ro, err := OpenLongTermMemoryReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenLongTermMemoryReadOnly: %v", err)
+	}
+	defer ro.Close()
+
+	// The writer keeps writing while the reader is open.
+	if err := rw.Store(LongTermEntry{ID: "lt-2", Summary: "Tax deadline reminder", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Store with reader open: %v", err)
+	}
+	results, err := ro.Search("tax", 10)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("read-only Search = %d results, %v", len(results), err)
+	}
+	if err := ro.Store(LongTermEntry{ID: "lt-3", Summary: "x", CreatedAt: time.Now()}); err == nil {
+		t.Error("expected write through read-only handle to fail")
+	}
+}
+
+func TestLongTermMemory_SearchAs(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+
+	now := time.Now()
+	for _, e := range []LongTermEntry{
+		{ID: "shared", Summary: "invoice policy shared", CreatedAt: now},
+		{ID: "alice-mail", Summary: "invoice from alice's landlord", CreatedAt: now, Owner: "alice@example.com", Channel: "EMAIL", Visibility: VisibilityPrivate},
+		{ID: "bob-tg", Summary: "invoice bob asked about", CreatedAt: now, Owner: "42", Channel: "TELEGRAM", Visibility: VisibilityPrivate},
+		{ID: "team", Summary: "invoice thread in slack", CreatedAt: now, Owner: "U1", Channel: "SLACK", Visibility: VisibilityChannel},
+	} {
+		if err := ltm.Store(e); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+	}
+
+	ids := func(v Viewer) string {
+		got, err := ltm.SearchAs(v, "invoice", 10)
+		if err != nil {
+			t.Fatalf("SearchAs(%+v): %v", v, err)
+		}
+		var out []string
+		for _, e := range got {
+			if !v.CanSee(e) {
+				t.Errorf("SearchAs(%+v) returned %s, which CanSee rejects", v, e.ID)
+			}
+			out = append(out, e.ID)
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	cases := []struct {
+		v    Viewer
+		want string
+	}{
+		{Viewer{Sender: "42", Channel: "TELEGRAM"}, "bob-tg,shared"},
+		{Viewer{Sender: "7", Channel: "TELEGRAM"}, "shared"},
+		{Viewer{Sender: "alice@example.com", Channel: "EMAIL"}, "alice-mail,shared"},
+		{Viewer{Sender: "alice@example.com", Channel: "TELEGRAM"}, "shared"},
+		{Viewer{Sender: "U2", Channel: "SLACK"}, "shared,team"},
+		{Viewer{}, "shared"},
+	}
+	for _, c := range cases {
+		if got := ids(c.v); got != c.want {
+			t.Errorf("SearchAs(%+v) = %s, want %s", c.v, got, c.want)
+		}
+	}
+
+	// Unscoped search and GetAll keep returning everything, with the ACL.
+	all, _ := ltm.GetAll(10)
+	if len(all) != 4 {
+		t.Fatalf("GetAll = %d entries", len(all))
+	}
+	for _, e := range all {
+		if e.ID == "alice-mail" && (e.Owner != "alice@example.com" || e.Channel != "EMAIL" || e.Visibility != VisibilityPrivate) {
+			t.Errorf("round trip lost the ACL: %+v", e)
+		}
+	}
+}
+
+func TestLongTermMemory_MigratesVisibilityColumns(t *testing.T) {
+	path := tempDBPath(t)
+	db, err := storage.OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE long_term_memory (
+		id TEXT PRIMARY KEY, summary TEXT NOT NULL, tags TEXT NOT NULL DEFAULT '',
+		source_run_id TEXT NOT NULL DEFAULT '', created_at DATETIME NOT NULL);
+		INSERT INTO long_term_memory VALUES ('old', 'legacy note', '', 'run-0', CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if _, err := OpenLongTermMemoryReadOnly(path); err == nil || !strings.Contains(err.Error(), "start overhuman once") {
+		t.Errorf("read-only open of an old database: err = %v", err)
+	}
+
+	ltm, err := NewLongTermMemory(path)
+	if err != nil {
+		t.Fatalf("NewLongTermMemory on old schema: %v", err)
+	}
+	defer ltm.Close()
+	all, err := ltm.GetAll(10)
+	if err != nil || len(all) != 1 || all[0].Visibility != VisibilityShared {
+		t.Fatalf("GetAll after migration = %+v, %v", all, err)
+	}
+	if err := ltm.Store(LongTermEntry{ID: "new", Summary: "n", CreatedAt: time.Now(), Owner: "a", Visibility: VisibilityPrivate, Topic: "work"}); err != nil {
+		t.Errorf("Store after migration: %v", err)
+	}
+	if topics, err := ltm.Topics(); err != nil || len(topics) != 1 || topics[0] != (TopicCount{"work", 1}) {
+		t.Errorf("Topics after migration = %+v, %v", topics, err)
+	}
+}
+
+func TestLongTermMemory_Recall(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+
+	now := time.Now()
+	for i, e := range []LongTermEntry{
+		{ID: "plot", Summary: "the villain is the uncle", Topic: "Novel Draft"},
+		{ID: "setting", Summary: "story set in Lisbon, 1920s", Topic: "novel-draft"},
+		{ID: "bob-plot", Summary: "villain ideas from bob", Topic: "novel-draft", Owner: "bob", Channel: "TELEGRAM", Visibility: VisibilityPrivate},
+		{ID: "work", Summary: "the villain in the quarterly report is churn", Topic: "work"},
+		{ID: "loose", Summary: "villain of the week"},
+	} {
+		e.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := ltm.Store(e); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+	}
+
+	ids := func(got []LongTermEntry, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, e := range got {
+			out = append(out, e.ID)
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	alice := Viewer{Sender: "alice", Channel: "TELEGRAM"}
+	if got := ids(ltm.Recall(alice, "novel-draft", "who is the villain?", 10)); got != "plot" {
+		t.Errorf("Recall villain = %s, want plot", got)
+	}
+	if got := ids(ltm.Recall(Viewer{Sender: "bob", Channel: "TELEGRAM"}, "novel-draft", "villain", 10)); got != "bob-plot,plot" {
+		t.Errorf("Recall as bob = %s", got)
+	}
+	if got := ids(ltm.Recall(alice, "NOVEL DRAFT", "", 10)); got != "plot,setting" {
+		t.Errorf("Recall without text = %s, want the topic's entries", got)
+	}
+	if got := ids(ltm.SearchTopic("novel-draft", "villain", 10)); got != "bob-plot,plot" {
+		t.Errorf("SearchTopic = %s", got)
+	}
+
+	topics, err := ltm.Topics()
+	if err != nil || len(topics) != 2 || topics[0] != (TopicCount{"novel-draft", 3}) || topics[1] != (TopicCount{"work", 1}) {
+		t.Errorf("Topics = %+v, %v", topics, err)
+	}
+}
+
+func TestNormalizeTopic(t *testing.T) {
+	for in, want := range map[string]string{
+		"Novel Draft":              "novel-draft",
+		"  work  ":                 "work",
+		"Q3/planning!":             "q3-planning",
+		"health_2026":              "health_2026",
+		"Здоровье":                 "здоровье",
+		"!!":                       "",
+		strings.Repeat("abc ", 30): strings.Repeat("abc-", 10)[:39],
+		strings.Repeat("я", 50):    strings.Repeat("я", 40),
+	} {
+		if got := NormalizeTopic(in); got != want {
+			t.Errorf("NormalizeTopic(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	for in, want := range map[string]Visibility{"": VisibilityShared, "Public": VisibilityShared, "channel": VisibilityChannel, " private ": VisibilityPrivate} {
+		if got, err := ParseVisibility(in); err != nil || got != want {
+			t.Errorf("ParseVisibility(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseVisibility("team"); err == nil {
+		t.Error("expected error for unknown visibility")
+	}
+	// A private entry without an owner (e.g. learned from a timer) is
+	// limited to its channel.
+	e := LongTermEntry{Channel: "TIMER", Visibility: VisibilityPrivate}
+	if !(Viewer{Channel: "TIMER"}).CanSee(e) || (Viewer{Channel: "API"}).CanSee(e) {
+		t.Error("ownerless private entry should be visible on its channel only")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // PatternTracker tests
 // ---------------------------------------------------------------------------
@@ -403,6 +664,29 @@ func TestPatternTracker_GetAutomatable(t *testing.T) {
 	}
 }
 
+func TestPatternTracker_Top(t *testing.T) {
+	pt := newTestPatternTracker(t)
+
+	fp1 := pt.ComputeFingerprint("task A", "type1")
+	fp2 := pt.ComputeFingerprint("task B", "type2")
+	fp3 := pt.ComputeFingerprint("task C", "type3")
+	pt.Record(fp1, "task A", 0.9)
+	pt.Record(fp2, "task B", 0.9)
+	pt.Record(fp2, "task B", 0.9)
+	pt.Record(fp3, "task C", 0.5)
+
+	top, err := pt.Top(2)
+	if err != nil {
+		t.Fatalf("Top: %v", err)
+	}
+	if len(top) != 2 || top[0].Fingerprint != fp2 || top[0].Count != 2 {
+		t.Fatalf("Top(2) = %+v", top)
+	}
+	if all, _ := pt.Top(0); len(all) != 3 {
+		t.Errorf("Top(0) returned %d patterns, want 3", len(all))
+	}
+}
+
 func TestPatternTracker_LinkSkillExcludesFromAutomatable(t *testing.T) {
 	pt := newTestPatternTracker(t)
 
@@ -453,7 +737,371 @@ func TestPatternTracker_LinkSkillNotFound(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// SavingsTracker tests
+// ---------------------------------------------------------------------------
+
+func TestSavingsTracker_BaselineAndReport(t *testing.T) {
+	ltm, err := NewLongTermMemory(filepath.Join(t.TempDir(), "savings.db"))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	t.Cleanup(func() { ltm.Close() })
+	st, err := NewSavingsTracker(ltm.DB())
+	if err != nil {
+		t.Fatalf("NewSavingsTracker: %v", err)
+	}
+
+	if _, _, ok, err := st.RecordSkillRun("sk1", "fp1", 0, 10); err != nil || ok {
+		t.Fatalf("no baseline: ok=%v err=%v, want ok=false", ok, err)
+	}
+
+	st.RecordLLMRun("fp1", 0.02, 3000)
+	st.RecordLLMRun("fp1", 0.04, 5000)
+	b, err := st.Baseline("fp1")
+	if err != nil || b == nil {
+		t.Fatalf("Baseline: %v, %v", b, err)
+	}
+	if b.Runs != 2 || math.Abs(b.AvgCostUSD-0.03) > 1e-9 || b.AvgElapsedMs != 4000 {
+		t.Errorf("baseline = %+v, want 2 runs, $0.03, 4000ms", b)
+	}
+
+	saved, savedMs, ok, err := st.RecordSkillRun("sk1", "fp1", 0, 100)
+	if err != nil || !ok || math.Abs(saved-0.03) > 1e-9 || savedMs != 3900 {
+		t.Fatalf("RecordSkillRun = %v, %v, %v, %v", saved, savedMs, ok, err)
+	}
+	st.RecordSkillRun("sk1", "fp1", 0.01, 100)
+
+	rep, err := st.Report(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if rep.Runs != 2 || len(rep.Skills) != 1 || math.Abs(rep.SavedCostUSD-0.05) > 1e-9 || rep.SavedMs != 7800 {
+		t.Errorf("report = %+v", rep)
+	}
+	if rep, _ := st.Report(time.Now().Add(time.Hour)); rep.Runs != 0 {
+		t.Errorf("future window should be empty, got %d runs", rep.Runs)
+	}
+}
+
+func TestLongTermMemory_ConcurrentWriters(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*25)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				if err := ltm.Store(LongTermEntry{ID: fmt.Sprintf("w%d-%d", w, i), Summary: "concurrent write", CreatedAt: time.Now()}); err != nil {
+					errs <- err
+				}
+				if _, err := ltm.Search("concurrent", 5); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent access failed (busy timeout not applied?): %v", err)
+	}
+	if n, _ := ltm.Count(); n != 200 {
+		t.Errorf("Count = %d, want 200", n)
+	}
+}
+
+func TestLongTermMemory_HealthAndMaintain(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+
+	big := strings.Repeat("lorem ipsum ", 400)
+	for i := 0; i < 200; i++ {
+		ltm.Store(LongTermEntry{ID: fmt.Sprintf("e%d", i), Summary: big, CreatedAt: time.Now()})
+	}
+	if _, err := ltm.DB().Exec(`DELETE FROM long_term_memory`); err != nil {
+		t.Fatal(err)
+	}
+	ltm.DB().Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+
+	h, err := ltm.Health()
+	if err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if h.JournalMode != "wal" || h.PageCount == 0 || h.FreeRatio() < VacuumFreeRatio || !h.LastVacuum.IsZero() {
+		t.Fatalf("health before = %+v", h)
+	}
+
+	res, err := ltm.Maintain(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Maintain: %v", err)
+	}
+	if !res.Optimized || !res.Vacuumed || res.ReclaimedBytes <= 0 {
+		t.Errorf("result = %+v", res)
+	}
+	h, _ = ltm.Health()
+	if h.FreeRatio() >= VacuumFreeRatio || h.LastVacuum.IsZero() || h.LastOptimize.IsZero() {
+		t.Errorf("health after = %+v", h)
+	}
+
+	// Nothing to reclaim: optimize only.
+	if res, _ := ltm.Maintain(context.Background(), false); res.Vacuumed {
+		t.Error("vacuum without free pages")
+	}
+}
+
+func TestLongTermMemory_Consolidate(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+	book, err := NewEntityStore(ltm.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	ltm.Store(LongTermEntry{ID: "old", Summary: "Anna prefers  mornings", Tags: []string{"anna"}, Owner: "alice", CreatedAt: now.Add(-time.Hour)})
+	ltm.Store(LongTermEntry{ID: "new", Summary: "anna prefers mornings", Tags: []string{"meetings"}, Owner: "alice", CreatedAt: now})
+	ltm.Store(LongTermEntry{ID: "bob", Summary: "Anna prefers mornings", Owner: "bob", CreatedAt: now})
+	anna, _ := book.Upsert(Entity{Name: "Anna", Owner: "alice"})
+	book.Link(anna.ID, "old", now)
+
+	n, err := ltm.Consolidate(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("Consolidate = %d, %v; want 1 merged", n, err)
+	}
+	all, _ := ltm.GetAll(10)
+	if len(all) != 2 {
+		t.Fatalf("entries = %+v", all)
+	}
+	for _, e := range all {
+		if e.ID == "new" && strings.Join(e.Tags, ",") != "meetings,anna" {
+			t.Errorf("kept entry tags = %v", e.Tags)
+		}
+		if e.ID == "old" {
+			t.Error("the older duplicate should be merged away")
+		}
+	}
+	if m, _ := book.AllMentions(anna.ID, 10); len(m) != 1 || m[0].ID != "new" {
+		t.Errorf("links should move to the kept entry, got %+v", m)
+	}
+	if n, _ := ltm.Consolidate(context.Background()); n != 0 {
+		t.Errorf("second Consolidate merged %d", n)
+	}
+}
+
+func TestLongTermMemory_RecordMaintenance(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+
+	at := time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC)
+	if err := ltm.RecordMaintenance("housekeeping:log_rotation", at); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := ltm.MaintenanceRuns()
+	if err != nil || !runs["housekeeping:log_rotation"].Equal(at) {
+		t.Errorf("MaintenanceRuns = %v, %v", runs, err)
+	}
+}
+
 // Ensure temp files are cleaned up properly.
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
+
+func TestEntityStore(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+	book, err := NewEntityStore(ltm.DB())
+	if err != nil {
+		t.Fatalf("NewEntityStore: %v", err)
+	}
+
+	// "Anna" then "Anna Petrova" with details merge into one contact.
+	if _, err := book.Upsert(Entity{Name: "Anna", Owner: "alice", Relationship: "friend"}); err != nil {
+		t.Fatal(err)
+	}
+	anna, err := book.Upsert(Entity{Name: "Anna Petrova", Owner: "alice", Email: " Anna@Example.com", Relationship: "colleague", Preferences: "informal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := book.Upsert(Entity{Name: "Team Lead", Owner: "alice", Email: "anna@example.com", LastInteraction: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := book.List("alice")
+	if len(list) != 1 {
+		t.Fatalf("List = %+v, want one merged contact", list)
+	}
+	got := list[0]
+	if got.ID != anna.ID || got.Name != "Anna Petrova" || got.Email != "anna@example.com" || got.Relationship != "colleague" ||
+		strings.Join(got.Aliases, ",") != "Anna,Team Lead" || got.LastInteraction.IsZero() {
+		t.Errorf("merged contact = %+v", got)
+	}
+	if _, err := book.Upsert(Entity{Name: "  "}); err == nil {
+		t.Error("expected error for an empty name")
+	}
+
+	// Shared entries are visible to everyone; an owner's own shadows them.
+	book.Upsert(Entity{Name: "Acme", Kind: EntityOrganization, Relationship: "supplier"})
+	book.Upsert(Entity{Name: "Anna Petrova", Owner: "bob", Relationship: "sister"})
+
+	names := func(owner, text string) string {
+		found, err := book.Find(owner, text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, e := range found {
+			out = append(out, e.Name+"/"+e.Relationship)
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	for _, c := range []struct{ owner, text, want string }{
+		{"alice", "reply to anna about the Acme order", "Acme/supplier,Anna Petrova/colleague"},
+		{"alice", "ask the team lead", "Anna Petrova/colleague"},
+		{"alice", "cc anna@example.com", "Anna Petrova/colleague"},
+		{"alice", "call Annabelle", ""},
+		{"bob", "reply to Anna", "Anna Petrova/sister"},
+		{"carol", "reply to Anna", ""},
+	} {
+		if got := names(c.owner, c.text); got != c.want {
+			t.Errorf("Find(%s, %q) = %q, want %q", c.owner, c.text, got, c.want)
+		}
+	}
+
+	// Links to long-term memory respect visibility.
+	now := time.Now()
+	ltm.Store(LongTermEntry{ID: "run1", Summary: "emailed Anna the offsite plan", CreatedAt: now, Owner: "alice", Channel: "TELEGRAM", Visibility: VisibilityPrivate})
+	ltm.Store(LongTermEntry{ID: "run2", Summary: "Anna approved the budget", CreatedAt: now.Add(time.Minute)})
+	for _, id := range []string{"run1", "run2", "run2"} {
+		if err := book.Link(anna.ID, id, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m, _ := book.Mentions(anna.ID, Viewer{Sender: "alice", Channel: "TELEGRAM"}, 10); len(m) != 2 || m[0].ID != "run2" {
+		t.Errorf("alice's mentions = %+v", m)
+	}
+	if m, _ := book.Mentions(anna.ID, Viewer{Sender: "bob", Channel: "TELEGRAM"}, 10); len(m) != 1 {
+		t.Errorf("bob should only see the shared mention, got %+v", m)
+	}
+
+	// Forgetting a memory drops its links.
+	if ok, err := ltm.Forget("run1"); !ok || err != nil {
+		t.Errorf("Forget = %v, %v", ok, err)
+	}
+	if ok, _ := ltm.Forget("run1"); ok {
+		t.Error("second Forget should report a missing entry")
+	}
+	if m, _ := book.AllMentions(anna.ID, 10); len(m) != 1 || m[0].ID != "run2" {
+		t.Errorf("mentions after Forget = %+v", m)
+	}
+
+	if ok, err := book.Delete("alice", "team lead"); !ok || err != nil {
+		t.Errorf("Delete = %v, %v", ok, err)
+	}
+	if m, _ := book.AllMentions(anna.ID, 10); len(m) != 0 {
+		t.Errorf("links should go with the contact, got %+v", m)
+	}
+	if e, _ := book.Get("alice", "Anna"); e != nil {
+		t.Errorf("Get after Delete = %+v", e)
+	}
+}
+
+func TestRunBuffer(t *testing.T) {
+	ltm, err := NewLongTermMemory(tempDBPath(t))
+	if err != nil {
+		t.Fatalf("NewLongTermMemory: %v", err)
+	}
+	defer ltm.Close()
+	runs, err := NewRunBuffer(ltm)
+	if err != nil {
+		t.Fatalf("NewRunBuffer: %v", err)
+	}
+	alice := Viewer{Sender: "alice", Channel: "TELEGRAM"}
+	tmpl := LongTermEntry{Owner: "alice", Channel: "TELEGRAM", Visibility: VisibilityPrivate}
+
+	// A failed run with findings stays behind as a partial result.
+	runs.Begin("run1", "compare three vendors", "alice", "TELEGRAM")
+	runs.Checkpoint("run1", "vendor A", "cheapest, slow support")
+	if prev, _ := runs.Latest(alice); prev != nil {
+		t.Errorf("a run in progress should not be offered for resuming: %+v", prev)
+	}
+	runs.Checkpoint("run1", "vendor B", "fast, expensive")
+	if kept, err := runs.Fail("run1", "rate limited", tmpl); err != nil || !kept {
+		t.Fatalf("Fail = %v, %v", kept, err)
+	}
+	prev, err := runs.Latest(alice)
+	if err != nil || prev == nil || prev.Goal != "compare three vendors" || prev.Status != RunFailed || len(prev.Findings) != 2 || prev.Findings[1].Step != "vendor B" {
+		t.Fatalf("Latest = %+v, %v", prev, err)
+	}
+	if d := prev.Describe(); !strings.Contains(d, "(rate limited)") || !strings.Contains(d, "## vendor A\ncheapest, slow support") {
+		t.Errorf("Describe = %q", d)
+	}
+	if got, _ := ltm.SearchAs(alice, "partial result vendors", 5); len(got) != 1 || got[0].ID != "run1_partial" || got[0].Tags[0] != PartialTag {
+		t.Errorf("partial result entry = %+v", got)
+	}
+	if prev, _ := runs.Latest(Viewer{Sender: "bob", Channel: "TELEGRAM"}); prev != nil {
+		t.Errorf("bob sees alice's run: %+v", prev)
+	}
+
+	// A run cut short by a crash is offered too.
+	runs.Begin("run2", "summarize the thread", "alice", "TELEGRAM")
+	runs.Checkpoint("run2", "part 1", "greetings")
+	reopened, err := NewRunBuffer(ltm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev, _ := reopened.Latest(alice); prev == nil || prev.RunID != "run2" || prev.Status != RunRunning {
+		t.Errorf("crashed run not found: %+v", prev)
+	}
+
+	// Success promotes the findings and drops the buffer and partial entry.
+	if n, err := runs.Promote("run1", tmpl); err != nil || n != 2 {
+		t.Fatalf("Promote = %d, %v", n, err)
+	}
+	all, _ := ltm.GetAll(10)
+	var findings int
+	for _, e := range all {
+		if e.ID == "run1_partial" {
+			t.Error("partial result entry should be gone")
+		}
+		if e.SourceRunID == "run1" && e.Tags[0] == "finding" {
+			findings++
+		}
+	}
+	if findings != 2 {
+		t.Errorf("%d finding entries, want 2", findings)
+	}
+
+	// A failed run without findings leaves nothing.
+	runs.Begin("run3", "noop", "alice", "TELEGRAM")
+	if kept, err := runs.Fail("run3", "boom", tmpl); err != nil || kept {
+		t.Errorf("Fail without findings = %v, %v", kept, err)
+	}
+	if r, _ := runs.get("run3"); r != nil {
+		t.Errorf("empty run kept: %+v", r)
+	}
+
+	var off *RunBuffer
+	if err := off.Checkpoint("x", "y", "z"); err != nil {
+		t.Error("a nil buffer should ignore calls")
+	}
+}
@@ -94,6 +94,27 @@ func (p *PatternTracker) GetAutomatable(threshold int) ([]PatternEntry, error) {
 	return scanPatternRows(rows)
 }
 
+// Top returns the most frequent patterns, most recent first among equals
+// (limit <= 0 = all).
+func (p *PatternTracker) Top(limit int) ([]PatternEntry, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := p.db.Query(
+		`SELECT fingerprint, description, count, avg_quality, last_seen, skill_id
+		 FROM patterns
+		 ORDER BY count DESC, last_seen DESC
+		 LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("pattern tracker: top: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPatternRows(rows)
+}
+
 // LinkSkill associates a code-skill ID with the given fingerprint.
 func (p *PatternTracker) LinkSkill(fingerprint, skillID string) error {
 	res, err := p.db.Exec(
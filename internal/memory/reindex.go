@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Reindex defaults.
+const (
+	DefaultReindexConcurrency = 4
+	DefaultReindexBatchSize   = 32
+)
+
+// ReindexOptions tunes Reindex.
+type ReindexOptions struct {
+	// Concurrency is the number of parallel embedding requests.
+	Concurrency int
+	// BatchSize is the number of texts per embedding request.
+	BatchSize int
+	// Force re-embeds every row; otherwise rows whose vector already
+	// matches the model and text are skipped, so an interrupted run
+	// resumes where it stopped.
+	Force bool
+	// Progress, if set, is called after each batch.
+	Progress func(ReindexProgress)
+}
+
+// ReindexProgress reports how far a reindex of one kind has come.
+type ReindexProgress struct {
+	Kind  string
+	Done  int // rows embedded or skipped so far
+	Total int
+}
+
+// ReindexKindResult summarises the reindex of one source table.
+type ReindexKindResult struct {
+	Kind     string `json:"kind"`
+	Rows     int    `json:"rows"`
+	Embedded int    `json:"embedded"`
+	Skipped  int    `json:"skipped"` // already current
+	Failed   int    `json:"failed"`
+	Pruned   int    `json:"pruned"`  // vectors of deleted rows
+	Vectors  int    `json:"vectors"` // vectors for the model after the run
+	Verified bool   `json:"verified"`
+}
+
+// ReindexResult is returned by Reindex.
+type ReindexResult struct {
+	Model string              `json:"model"`
+	Kinds []ReindexKindResult `json:"kinds"`
+}
+
+// Verified reports whether every kind has exactly one current vector per row.
+func (r ReindexResult) Verified() bool {
+	for _, k := range r.Kinds {
+		if !k.Verified {
+			return false
+		}
+	}
+	return true
+}
+
+// reindexItem is one row to embed.
+type reindexItem struct {
+	id, text string
+}
+
+// Reindex (re)computes embeddings for all long-term memory and shared
+// knowledge entries (skb may be nil), removes vectors of deleted rows and
+// verifies that the vector count matches the row count. Failed batches are
+// counted and reported in the returned error; the remaining rows are still
+// processed, and a later run picks up the failures.
+func Reindex(ctx context.Context, emb Embedder, vs *VectorStore, ltm *LongTermMemory, skb *SharedKnowledgeBase, opts ReindexOptions) (ReindexResult, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultReindexConcurrency
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultReindexBatchSize
+	}
+	res := ReindexResult{Model: emb.EmbeddingModel()}
+
+	var failed int
+	var lastErr error
+	sources := []struct {
+		kind string
+		load func() ([]reindexItem, error)
+	}{
+		{VectorKindLTM, func() ([]reindexItem, error) { return ltmItems(ltm) }},
+	}
+	if skb != nil {
+		sources = append(sources, struct {
+			kind string
+			load func() ([]reindexItem, error)
+		}{VectorKindSKB, func() ([]reindexItem, error) { return skbItems(skb) }})
+	}
+	for _, src := range sources {
+		items, err := src.load()
+		if err != nil {
+			return res, fmt.Errorf("reindex %s: %w", src.kind, err)
+		}
+		kr, err := reindexKind(ctx, emb, vs, src.kind, items, opts)
+		res.Kinds = append(res.Kinds, kr)
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		if err != nil {
+			failed += kr.Failed
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return res, fmt.Errorf("reindex: %d rows failed: %w", failed, lastErr)
+	}
+	return res, nil
+}
+
+func reindexKind(ctx context.Context, emb Embedder, vs *VectorStore, kind string, items []reindexItem, opts ReindexOptions) (ReindexKindResult, error) {
+	model := emb.EmbeddingModel()
+	kr := ReindexKindResult{Kind: kind, Rows: len(items)}
+
+	keep := make(map[string]bool, len(items))
+	for _, it := range items {
+		keep[it.id] = true
+	}
+	pruned, err := vs.Prune(kind, keep)
+	if err != nil {
+		return kr, err
+	}
+	kr.Pruned = pruned
+
+	current, err := vs.Current(kind, model)
+	if err != nil {
+		return kr, err
+	}
+	var todo []reindexItem
+	for _, it := range items {
+		if !opts.Force && current[it.id] == textHash(it.text) {
+			kr.Skipped++
+			continue
+		}
+		todo = append(todo, it)
+	}
+
+	var mu sync.Mutex
+	var lastErr error
+	done := kr.Skipped
+	report := func() {
+		if opts.Progress != nil {
+			opts.Progress(ReindexProgress{Kind: kind, Done: done, Total: len(items)})
+		}
+	}
+	report()
+
+	batches := make(chan []reindexItem)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				texts := make([]string, len(batch))
+				for i, it := range batch {
+					texts[i] = it.text
+				}
+				vecs, err := emb.Embed(ctx, texts)
+				if err == nil && len(vecs) != len(batch) {
+					err = fmt.Errorf("got %d vectors for %d texts", len(vecs), len(batch))
+				}
+				// Writes are serialised: SQLite allows one writer at a time.
+				mu.Lock()
+				stored := 0
+				if err == nil {
+					for i, it := range batch {
+						if err = vs.Put(kind, it.id, model, it.text, vecs[i]); err != nil {
+							break
+						}
+						stored++
+					}
+				}
+				kr.Embedded += stored
+				if err != nil {
+					kr.Failed += len(batch) - stored
+					lastErr = err
+				}
+				done += len(batch)
+				report()
+				mu.Unlock()
+			}
+		}()
+	}
+	for start := 0; start < len(todo) && ctx.Err() == nil; start += opts.BatchSize {
+		end := min(start+opts.BatchSize, len(todo))
+		select {
+		case batches <- todo[start:end]:
+		case <-ctx.Done():
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	if kr.Vectors, err = vs.Count(kind, model); err != nil {
+		return kr, err
+	}
+	total, err := vs.Count(kind, "")
+	if err != nil {
+		return kr, err
+	}
+	kr.Verified = kr.Failed == 0 && kr.Vectors == kr.Rows && total == kr.Rows
+	return kr, lastErr
+}
+
+func ltmItems(ltm *LongTermMemory) ([]reindexItem, error) {
+	n, err := ltm.Count()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	entries, err := ltm.GetAll(n)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]reindexItem, len(entries))
+	for i, e := range entries {
+		items[i] = reindexItem{id: e.ID, text: LTMEmbeddingText(e)}
+	}
+	return items, nil
+}
+
+func skbItems(skb *SharedKnowledgeBase) ([]reindexItem, error) {
+	n, err := skb.Count()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	entries, err := skb.TopEntries(n)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]reindexItem, len(entries))
+	for i, e := range entries {
+		items[i] = reindexItem{id: e.ID, text: e.Content}
+	}
+	return items, nil
+}
+
+// LTMEmbeddingText is the text embedded for a long-term memory entry: the
+// summary followed by its tags.
+func LTMEmbeddingText(e LongTermEntry) string {
+	if len(e.Tags) == 0 {
+		return e.Summary
+	}
+	return e.Summary + "\n" + strings.Join(e.Tags, ", ")
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubEmbedder returns one-dimensional vectors (the text length) and can
+// fail the first N calls.
+type stubEmbedder struct {
+	model string
+	calls atomic.Int32
+	fail  int32
+}
+
+func (s *stubEmbedder) EmbeddingModel() string { return s.model }
+
+func (s *stubEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	if s.calls.Add(1) <= s.fail {
+		return nil, errors.New("provider unavailable")
+	}
+	vecs := make([][]float32, len(texts))
+	for i, t := range texts {
+		vecs[i] = []float32{float32(len(t))}
+	}
+	return vecs, nil
+}
+
+func TestReindex(t *testing.T) {
+	skb := setupSKB(t)
+	ltm := &LongTermMemory{db: skb.db}
+	for i := 0; i < 10; i++ {
+		ltm.Store(LongTermEntry{ID: fmt.Sprintf("m%d", i), Summary: fmt.Sprintf("memory %d", i), Tags: []string{"t"}, CreatedAt: time.Now()})
+	}
+	skb.Store(SKBEntry{ID: "k1", Type: "pattern", Content: "shared", CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	vs, err := NewVectorStore(skb.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First run: one batch fails, the rest is stored.
+	emb := &stubEmbedder{model: "e1", fail: 1}
+	var last ReindexProgress
+	opts := ReindexOptions{Concurrency: 1, BatchSize: 3, Progress: func(p ReindexProgress) { last = p }}
+	res, err := Reindex(context.Background(), emb, vs, ltm, skb, opts)
+	if err == nil || res.Verified() {
+		t.Fatalf("expected a failed batch, got err=%v verified=%v", err, res.Verified())
+	}
+	if res.Kinds[0].Failed != 3 || res.Kinds[0].Embedded != 7 {
+		t.Errorf("ltm result = %+v", res.Kinds[0])
+	}
+
+	// Second run resumes: only the failed rows are embedded.
+	res, err = Reindex(context.Background(), emb, vs, ltm, skb, opts)
+	if err != nil || !res.Verified() {
+		t.Fatalf("resume: err=%v result=%+v", err, res)
+	}
+	if k := res.Kinds[0]; k.Embedded != 3 || k.Skipped != 7 || k.Vectors != 10 {
+		t.Errorf("resumed ltm = %+v", k)
+	}
+	if k := res.Kinds[1]; k.Kind != VectorKindSKB || k.Vectors != 1 {
+		t.Errorf("skb = %+v", k)
+	}
+	if last.Kind != VectorKindSKB || last.Done != last.Total {
+		t.Errorf("last progress = %+v", last)
+	}
+
+	// A new model re-embeds everything; deleted rows lose their vectors.
+	ltm.db.Exec(`DELETE FROM long_term_memory WHERE id = 'm0'`)
+	res, err = Reindex(context.Background(), &stubEmbedder{model: "e2"}, vs, ltm, nil, ReindexOptions{Concurrency: 3})
+	if err != nil || !res.Verified() {
+		t.Fatalf("new model: err=%v result=%+v", err, res)
+	}
+	if k := res.Kinds[0]; k.Embedded != 9 || k.Pruned != 1 || k.Vectors != 9 {
+		t.Errorf("new model ltm = %+v", k)
+	}
+	vec, model, err := vs.Get(VectorKindLTM, "m1")
+	if err != nil || model != "e2" || vec[0] != float32(len("memory 1\nt")) {
+		t.Errorf("Get = %v, %q, %v", vec, model, err)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	if c := Cosine([]float32{1, 0}, []float32{2, 0}); c < 0.999 {
+		t.Errorf("parallel = %f", c)
+	}
+	if c := Cosine([]float32{1, 0}, []float32{0, 1}); c != 0 {
+		t.Errorf("orthogonal = %f", c)
+	}
+	if Cosine(nil, []float32{1}) != 0 || Cosine([]float32{1}, []float32{1, 2}) != 0 {
+		t.Error("mismatched vectors should score 0")
+	}
+	v := []float32{0.5, -1.25, 3}
+	if got := decodeVector(encodeVector(v)); len(got) != 3 || got[1] != -1.25 {
+		t.Errorf("round trip = %v", got)
+	}
+}
+
+// conceptEmbedder maps words to concepts, so texts about the same thing
+// are close even when they share no word.
+type conceptEmbedder struct{ calls atomic.Int32 }
+
+var concepts = [][]string{
+	{"car", "vehicle", "automobile", "tyres", "garage"},
+	{"cook", "recipe", "pasta", "dinner"},
+	{"invoice", "bill", "payment", "accountant"},
+}
+
+func (e *conceptEmbedder) EmbeddingModel() string { return "concepts" }
+
+func (e *conceptEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls.Add(1)
+	vecs := make([][]float32, len(texts))
+	for i, t := range texts {
+		v := make([]float32, len(concepts))
+		for _, w := range strings.Fields(strings.ToLower(t)) {
+			for c, words := range concepts {
+				if slices.Contains(words, strings.Trim(w, ".,:")) {
+					v[c]++
+				}
+			}
+		}
+		vecs[i] = v
+	}
+	return vecs, nil
+}
+
+func TestSemanticMemory_Recall(t *testing.T) {
+	skb := setupSKB(t)
+	ltm := &LongTermMemory{db: skb.db}
+	vs, err := NewVectorStore(skb.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	ltm.Store(LongTermEntry{ID: "tyres", Summary: "Booked the garage to change the tyres", CreatedAt: now})
+	ltm.Store(LongTermEntry{ID: "pasta", Summary: "Favourite pasta recipe for dinner", CreatedAt: now})
+	ltm.Store(LongTermEntry{ID: "bill", Summary: "Sent the bill to the accountant", CreatedAt: now, Owner: "alice", Visibility: VisibilityPrivate})
+
+	emb := &conceptEmbedder{}
+	sm := NewSemanticMemory(emb, vs, ltm)
+	got, err := sm.Recall(context.Background(), Viewer{Sender: "bob"}, "Is my automobile ready?", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "tyres" {
+		t.Errorf("recall = %+v, want the tyres entry", got)
+	}
+	if n, _ := vs.Count(VectorKindLTM, "concepts"); n != 3 {
+		t.Errorf("entries indexed = %d, want 3", n)
+	}
+	if emb.calls.Load() != 1 {
+		t.Errorf("Embed calls = %d, want the query and new entries in one", emb.calls.Load())
+	}
+
+	// Private entries are recalled only for their owner.
+	if got, _ := sm.Recall(context.Background(), Viewer{Sender: "bob"}, "pay the invoice", 5); len(got) != 0 {
+		t.Errorf("private entry recalled for another sender: %+v", got)
+	}
+	if got, _ := sm.Recall(context.Background(), Viewer{Sender: "alice"}, "pay the invoice", 5); len(got) != 1 || got[0].ID != "bill" {
+		t.Errorf("recall for owner = %+v", got)
+	}
+
+	// Entries stored later are indexed by the next recall.
+	ltm.Store(LongTermEntry{ID: "cook", Summary: "Learned to cook risotto", CreatedAt: now})
+	if got, _ := sm.Recall(context.Background(), Viewer{}, "what's for dinner", 5); len(got) != 2 {
+		t.Errorf("recall after a new entry = %+v", got)
+	}
+}
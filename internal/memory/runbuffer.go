@@ -0,0 +1,278 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Run buffer states.
+const (
+	RunRunning = "running" // in progress, or the process died during it
+	RunFailed  = "failed"
+)
+
+// PartialTag tags the long-term entry of a run that failed with findings.
+const PartialTag = "partial"
+
+// RunFinding is an intermediate result checkpointed during a run.
+type RunFinding struct {
+	Step    string    `json:"step"` // what was worked on, e.g. a subtask goal
+	Finding string    `json:"finding"`
+	At      time.Time `json:"at"`
+}
+
+// PartialRun is a run that did not finish, with what it had found.
+type PartialRun struct {
+	RunID    string       `json:"run_id"`
+	Goal     string       `json:"goal"`
+	Owner    string       `json:"owner,omitempty"`
+	Channel  string       `json:"channel,omitempty"`
+	Status   string       `json:"status"`
+	Error    string       `json:"error,omitempty"`
+	Started  time.Time    `json:"started"`
+	Updated  time.Time    `json:"updated"` // last checkpoint
+	Findings []RunFinding `json:"findings"`
+}
+
+// RunBuffer is the run-scoped memory of long runs: findings are written as
+// they are made, so a crash or a failed stage does not lose them. On
+// success they are promoted to long-term memory; on failure they stay
+// behind as a partial result that a follow-up request can continue from.
+// A nil *RunBuffer ignores every call.
+type RunBuffer struct {
+	ltm *LongTermMemory
+
+	mu     sync.Mutex
+	active map[string]bool // runs in progress in this process
+}
+
+// NewRunBuffer creates the run buffer tables in the long-term memory
+// database if needed.
+func NewRunBuffer(ltm *LongTermMemory) (*RunBuffer, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS run_buffers (
+		run_id     TEXT PRIMARY KEY,
+		goal       TEXT NOT NULL,
+		owner      TEXT NOT NULL DEFAULT '',
+		channel    TEXT NOT NULL DEFAULT '',
+		status     TEXT NOT NULL,
+		error      TEXT NOT NULL DEFAULT '',
+		started_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS run_findings (
+		run_id  TEXT NOT NULL,
+		seq     INTEGER NOT NULL,
+		step    TEXT NOT NULL,
+		finding TEXT NOT NULL,
+		at      DATETIME NOT NULL,
+		PRIMARY KEY (run_id, seq)
+	);`
+	if _, err := ltm.DB().Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("run buffer: create tables: %w", err)
+	}
+	return &RunBuffer{ltm: ltm, active: make(map[string]bool)}, nil
+}
+
+// Begin opens the buffer of a run. Beginning a run again keeps its
+// findings.
+func (b *RunBuffer) Begin(runID, goal, owner, channel string) error {
+	if b == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	_, err := b.ltm.DB().Exec(`INSERT INTO run_buffers (run_id, goal, owner, channel, status, started_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (run_id) DO UPDATE SET status = excluded.status, updated_at = excluded.updated_at`,
+		runID, goal, owner, channel, RunRunning, now, now)
+	if err != nil {
+		return fmt.Errorf("run buffer: begin %s: %w", runID, err)
+	}
+	b.mu.Lock()
+	b.active[runID] = true
+	b.mu.Unlock()
+	return nil
+}
+
+// Checkpoint appends a finding to the buffer of a begun run.
+func (b *RunBuffer) Checkpoint(runID, step, finding string) error {
+	if b == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	_, err := b.ltm.DB().Exec(`INSERT INTO run_findings (run_id, seq, step, finding, at)
+		SELECT ?, COALESCE(MAX(seq), 0) + 1, ?, ?, ? FROM run_findings WHERE run_id = ?`,
+		runID, step, finding, now, runID)
+	if err == nil {
+		_, err = b.ltm.DB().Exec(`UPDATE run_buffers SET updated_at = ? WHERE run_id = ?`, now, runID)
+	}
+	if err != nil {
+		return fmt.Errorf("run buffer: checkpoint %s: %w", runID, err)
+	}
+	return nil
+}
+
+// Promote stores the findings of a successful run in long-term memory,
+// one entry each based on tmpl (owner, channel, visibility, topic, tags),
+// and drops the buffer. It returns how many were stored.
+func (b *RunBuffer) Promote(runID string, tmpl LongTermEntry) (int, error) {
+	if b == nil {
+		return 0, nil
+	}
+	findings, err := b.findings(runID)
+	if err != nil {
+		return 0, err
+	}
+	for i, f := range findings {
+		e := tmpl
+		e.ID = fmt.Sprintf("%s_finding%d", runID, i+1)
+		e.Summary = fmt.Sprintf("Finding: %s\n%s", f.Step, f.Finding)
+		e.Tags = append([]string{"finding"}, tmpl.Tags...)
+		e.SourceRunID = runID
+		e.CreatedAt = f.At
+		if err := b.ltm.Store(e); err != nil {
+			return i, fmt.Errorf("run buffer: promote %s: %w", runID, err)
+		}
+	}
+	return len(findings), b.Discard(runID)
+}
+
+// Fail marks a run failed. When it found anything, the buffer is kept and
+// a partial result entry based on tmpl is stored in long-term memory so the
+// work can be found and continued; otherwise the buffer is dropped. It
+// reports whether a partial result was kept.
+func (b *RunBuffer) Fail(runID, reason string, tmpl LongTermEntry) (bool, error) {
+	if b == nil {
+		return false, nil
+	}
+	b.mu.Lock()
+	delete(b.active, runID)
+	b.mu.Unlock()
+	run, err := b.get(runID)
+	if err != nil || run == nil {
+		return false, err
+	}
+	if len(run.Findings) == 0 {
+		return false, b.Discard(runID)
+	}
+	if _, err := b.ltm.DB().Exec(`UPDATE run_buffers SET status = ?, error = ?, updated_at = ? WHERE run_id = ?`,
+		RunFailed, reason, time.Now().UTC(), runID); err != nil {
+		return false, fmt.Errorf("run buffer: fail %s: %w", runID, err)
+	}
+	e := tmpl
+	e.ID = runID + "_partial"
+	e.Summary = fmt.Sprintf("Partial result: %s — %d finding(s) before it failed (%s). Ask to continue where it left off.", run.Goal, len(run.Findings), reason)
+	e.Tags = append([]string{PartialTag}, tmpl.Tags...)
+	e.SourceRunID = runID
+	e.CreatedAt = time.Now().UTC()
+	if err := b.ltm.Store(e); err != nil {
+		return false, fmt.Errorf("run buffer: fail %s: %w", runID, err)
+	}
+	return true, nil
+}
+
+// Discard drops the buffer of a run and its partial result entry.
+func (b *RunBuffer) Discard(runID string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	delete(b.active, runID)
+	b.mu.Unlock()
+	db := b.ltm.DB()
+	if _, err := db.Exec(`DELETE FROM run_findings WHERE run_id = ?`, runID); err != nil {
+		return fmt.Errorf("run buffer: discard %s: %w", runID, err)
+	}
+	if _, err := db.Exec(`DELETE FROM run_buffers WHERE run_id = ?`, runID); err != nil {
+		return fmt.Errorf("run buffer: discard %s: %w", runID, err)
+	}
+	if _, err := b.ltm.Forget(runID + "_partial"); err != nil {
+		return fmt.Errorf("run buffer: discard %s: %w", runID, err)
+	}
+	return nil
+}
+
+// Latest returns the most recent unfinished run of viewer's sender in
+// viewer's channel that has findings: one that failed, or one cut short
+// by a crash. Runs in progress in this process are skipped. It returns
+// nil when there is none.
+func (b *RunBuffer) Latest(viewer Viewer) (*PartialRun, error) {
+	if b == nil {
+		return nil, nil
+	}
+	rows, err := b.ltm.DB().Query(`SELECT r.run_id FROM run_buffers r
+		WHERE r.owner = ? AND r.channel = ? AND EXISTS (SELECT 1 FROM run_findings f WHERE f.run_id = r.run_id)
+		ORDER BY r.updated_at DESC`, viewer.Sender, viewer.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("run buffer: latest: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("run buffer: latest: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range ids {
+		if !b.active[id] {
+			return b.get(id)
+		}
+	}
+	return nil, nil
+}
+
+// get loads a run and its findings, or returns nil if there is none.
+func (b *RunBuffer) get(runID string) (*PartialRun, error) {
+	run := &PartialRun{RunID: runID}
+	err := b.ltm.DB().QueryRow(`SELECT goal, owner, channel, status, error, started_at, updated_at FROM run_buffers WHERE run_id = ?`, runID).
+		Scan(&run.Goal, &run.Owner, &run.Channel, &run.Status, &run.Error, &run.Started, &run.Updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("run buffer: load %s: %w", runID, err)
+	}
+	if run.Findings, err = b.findings(runID); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (b *RunBuffer) findings(runID string) ([]RunFinding, error) {
+	rows, err := b.ltm.DB().Query(`SELECT step, finding, at FROM run_findings WHERE run_id = ? ORDER BY seq`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("run buffer: findings of %s: %w", runID, err)
+	}
+	defer rows.Close()
+	var out []RunFinding
+	for rows.Next() {
+		var f RunFinding
+		if err := rows.Scan(&f.Step, &f.Finding, &f.At); err != nil {
+			return nil, fmt.Errorf("run buffer: findings of %s: %w", runID, err)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// Describe renders the findings of a partial run for a prompt.
+func (r *PartialRun) Describe() string {
+	var sb strings.Builder
+	sb.WriteString("An earlier attempt at this task stopped before finishing")
+	if r.Error != "" {
+		fmt.Fprintf(&sb, " (%s)", r.Error)
+	}
+	sb.WriteString(". Its findings so far — build on them instead of redoing the work:\n")
+	for _, f := range r.Findings {
+		fmt.Fprintf(&sb, "\n## %s\n%s\n", f.Step, f.Finding)
+	}
+	return sb.String()
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CostBaseline is the historical cost and latency of solving a pattern with
+// the LLM (the cold path), averaged over the runs before a skill took over.
+type CostBaseline struct {
+	Fingerprint  string  `json:"fingerprint"`
+	Runs         int     `json:"runs"`
+	AvgCostUSD   float64 `json:"avg_cost_usd"`
+	AvgElapsedMs float64 `json:"avg_elapsed_ms"`
+}
+
+// SkillSavings is what one skill saved against the LLM baseline of its
+// pattern over a period. Savings are negative when the skill was costlier or
+// slower than the LLM.
+type SkillSavings struct {
+	SkillID      string  `json:"skill_id"`
+	Runs         int     `json:"runs"`
+	SavedCostUSD float64 `json:"saved_cost_usd"`
+	SavedMs      float64 `json:"saved_ms"`
+}
+
+// SavingsReport summarises automation savings since a point in time.
+type SavingsReport struct {
+	Since        time.Time      `json:"since"`
+	Runs         int            `json:"runs"`
+	SavedCostUSD float64        `json:"saved_cost_usd"`
+	SavedMs      float64        `json:"saved_ms"`
+	Skills       []SkillSavings `json:"skills"` // largest cost saving first
+}
+
+// SavingsTracker profiles the cold path per pattern fingerprint and records
+// how much each skill run saves against it.
+type SavingsTracker struct {
+	db *sql.DB
+}
+
+// NewSavingsTracker creates the llm_baselines and skill_savings tables if
+// needed.
+func NewSavingsTracker(db *sql.DB) (*SavingsTracker, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS llm_baselines (
+		fingerprint    TEXT PRIMARY KEY,
+		runs           INTEGER NOT NULL DEFAULT 0,
+		avg_cost_usd   REAL    NOT NULL DEFAULT 0,
+		avg_elapsed_ms REAL    NOT NULL DEFAULT 0,
+		updated_at     DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS skill_savings (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		skill_id       TEXT NOT NULL,
+		fingerprint    TEXT NOT NULL,
+		saved_cost_usd REAL NOT NULL,
+		saved_ms       REAL NOT NULL,
+		created_at     DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_skill_savings_created ON skill_savings(created_at);`
+
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("savings tracker: create tables: %w", err)
+	}
+	return &SavingsTracker{db: db}, nil
+}
+
+// RecordLLMRun folds one cold-path run into the fingerprint's baseline.
+func (s *SavingsTracker) RecordLLMRun(fingerprint string, costUSD float64, elapsedMs int64) error {
+	_, err := s.db.Exec(`
+	INSERT INTO llm_baselines (fingerprint, runs, avg_cost_usd, avg_elapsed_ms, updated_at)
+	VALUES (?, 1, ?, ?, ?)
+	ON CONFLICT(fingerprint) DO UPDATE SET
+		runs           = llm_baselines.runs + 1,
+		avg_cost_usd   = (llm_baselines.avg_cost_usd * llm_baselines.runs + excluded.avg_cost_usd) / (llm_baselines.runs + 1),
+		avg_elapsed_ms = (llm_baselines.avg_elapsed_ms * llm_baselines.runs + excluded.avg_elapsed_ms) / (llm_baselines.runs + 1),
+		updated_at     = excluded.updated_at;`,
+		fingerprint, costUSD, float64(elapsedMs), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("savings tracker: record llm run: %w", err)
+	}
+	return nil
+}
+
+// Baseline returns the cold-path baseline of a fingerprint, or nil if the
+// pattern was never solved by the LLM.
+func (s *SavingsTracker) Baseline(fingerprint string) (*CostBaseline, error) {
+	b := CostBaseline{Fingerprint: fingerprint}
+	err := s.db.QueryRow(
+		`SELECT runs, avg_cost_usd, avg_elapsed_ms FROM llm_baselines WHERE fingerprint = ?`,
+		fingerprint,
+	).Scan(&b.Runs, &b.AvgCostUSD, &b.AvgElapsedMs)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("savings tracker: baseline: %w", err)
+	}
+	return &b, nil
+}
+
+// RecordSkillRun records what a skill run saved against the fingerprint's
+// baseline. It returns the savings, or ok=false (and records nothing) when
+// there is no baseline to compare with.
+func (s *SavingsTracker) RecordSkillRun(skillID, fingerprint string, costUSD float64, elapsedMs int64) (savedUSD, savedMs float64, ok bool, err error) {
+	b, err := s.Baseline(fingerprint)
+	if err != nil || b == nil {
+		return 0, 0, false, err
+	}
+	savedUSD = b.AvgCostUSD - costUSD
+	savedMs = b.AvgElapsedMs - float64(elapsedMs)
+	_, err = s.db.Exec(
+		`INSERT INTO skill_savings (skill_id, fingerprint, saved_cost_usd, saved_ms, created_at) VALUES (?, ?, ?, ?, ?)`,
+		skillID, fingerprint, savedUSD, savedMs, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("savings tracker: record skill run: %w", err)
+	}
+	return savedUSD, savedMs, true, nil
+}
+
+// Report sums skill savings recorded since the given time (zero = all time).
+func (s *SavingsTracker) Report(since time.Time) (SavingsReport, error) {
+	rep := SavingsReport{Since: since, Skills: []SkillSavings{}}
+	rows, err := s.db.Query(
+		`SELECT skill_id, COUNT(*), SUM(saved_cost_usd), SUM(saved_ms)
+		 FROM skill_savings
+		 WHERE created_at >= ?
+		 GROUP BY skill_id
+		 ORDER BY SUM(saved_cost_usd) DESC, skill_id`,
+		since.UTC(),
+	)
+	if err != nil {
+		return rep, fmt.Errorf("savings tracker: report: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sk SkillSavings
+		if err := rows.Scan(&sk.SkillID, &sk.Runs, &sk.SavedCostUSD, &sk.SavedMs); err != nil {
+			return rep, fmt.Errorf("savings tracker: report: %w", err)
+		}
+		rep.Runs += sk.Runs
+		rep.SavedCostUSD += sk.SavedCostUSD
+		rep.SavedMs += sk.SavedMs
+		rep.Skills = append(rep.Skills, sk)
+	}
+	return rep, rows.Err()
+}
@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Semantic recall defaults.
+const (
+	// DefaultSemanticMinScore is the cosine similarity below which an
+	// entry is not considered related.
+	DefaultSemanticMinScore = 0.35
+	// semanticIndexBatch bounds the entries embedded alongside one query.
+	semanticIndexBatch = 64
+)
+
+// VectorMatch is a row found by VectorStore.Nearest.
+type VectorMatch struct {
+	ID    string
+	Score float64 // cosine similarity
+}
+
+// Nearest returns up to limit rows of kind, embedded with model, whose
+// vectors have a cosine similarity of at least minScore with query, most
+// similar first. It scans every vector of the kind: memory stays in the
+// thousands of rows, where a brute-force scan takes milliseconds.
+func (v *VectorStore) Nearest(kind, model string, query []float32, limit int, minScore float64) ([]VectorMatch, error) {
+	rows, err := v.db.Query(`SELECT id, vector FROM memory_vectors WHERE kind = ? AND model = ?`, kind, model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []VectorMatch
+	for rows.Next() {
+		var id string
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			return nil, err
+		}
+		if score := Cosine(query, decodeVector(blob)); score >= minScore {
+			out = append(out, VectorMatch{ID: id, Score: score})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// SemanticMemory finds long-term memories by meaning rather than shared
+// words, using the embeddings in a VectorStore. Entries stored since the
+// last recall (or `overhuman memory reindex`) are embedded in the same
+// request as the query, so the index keeps up without a separate job.
+type SemanticMemory struct {
+	emb Embedder
+	vs  *VectorStore
+	ltm *LongTermMemory
+
+	// MinScore is the similarity an entry needs to be recalled; 0 uses
+	// DefaultSemanticMinScore.
+	MinScore float64
+}
+
+// NewSemanticMemory searches ltm with vectors from emb kept in vs.
+func NewSemanticMemory(emb Embedder, vs *VectorStore, ltm *LongTermMemory) *SemanticMemory {
+	return &SemanticMemory{emb: emb, vs: vs, ltm: ltm}
+}
+
+// Model returns the embedding model.
+func (s *SemanticMemory) Model() string { return s.emb.EmbeddingModel() }
+
+// Recall returns up to limit entries viewer may see that are closest in
+// meaning to text, best first.
+func (s *SemanticMemory) Recall(ctx context.Context, viewer Viewer, text string, limit int) ([]LongTermEntry, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	model := s.emb.EmbeddingModel()
+	pending, err := s.unembedded(model)
+	if err != nil {
+		return nil, fmt.Errorf("semantic recall: %w", err)
+	}
+	texts := make([]string, 0, 1+len(pending))
+	texts = append(texts, text)
+	for _, e := range pending {
+		texts = append(texts, LTMEmbeddingText(e))
+	}
+	vecs, err := s.emb.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("semantic recall: %w", err)
+	}
+	if len(vecs) != len(texts) {
+		return nil, fmt.Errorf("semantic recall: got %d vectors for %d texts", len(vecs), len(texts))
+	}
+	for i, e := range pending {
+		if err := s.vs.Put(VectorKindLTM, e.ID, model, texts[i+1], vecs[i+1]); err != nil {
+			return nil, fmt.Errorf("semantic recall: index %s: %w", e.ID, err)
+		}
+	}
+
+	minScore := s.MinScore
+	if minScore <= 0 {
+		minScore = DefaultSemanticMinScore
+	}
+	// Visibility is checked after ranking; fetch spare candidates.
+	matches, err := s.vs.Nearest(VectorKindLTM, model, vecs[0], limit*4, minScore)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	entries, err := s.ltm.byIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("semantic recall: %w", err)
+	}
+	var out []LongTermEntry
+	for _, m := range matches {
+		if e, ok := entries[m.ID]; ok && viewer.CanSee(e) {
+			out = append(out, e)
+			if len(out) == limit {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// unembedded returns the newest entries without a vector for model.
+func (s *SemanticMemory) unembedded(model string) ([]LongTermEntry, error) {
+	rows, err := s.ltm.db.Query(
+		`SELECT `+ltmColumns+`
+		 FROM long_term_memory m
+		 WHERE NOT EXISTS (SELECT 1 FROM memory_vectors v WHERE v.kind = ? AND v.id = m.id AND v.model = ?)
+		 ORDER BY m.created_at DESC
+		 LIMIT ?`,
+		VectorKindLTM, model, semanticIndexBatch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLongTermRows(rows)
+}
+
+// byIDs returns the entries with the given IDs, keyed by ID.
+func (l *LongTermMemory) byIDs(ids []string) (map[string]LongTermEntry, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := l.db.Query(
+		`SELECT `+ltmColumns+`
+		 FROM long_term_memory m
+		 WHERE m.id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries, err := scanLongTermRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]LongTermEntry, len(entries))
+	for _, e := range entries {
+		out[e.ID] = e
+	}
+	return out, nil
+}
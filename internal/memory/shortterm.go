@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -145,6 +146,67 @@ func (s *ShortTermMemory) Clear() {
 	s.count = 0
 }
 
+// RemoveSession drops the entries of sessionID, keeping the others in
+// order. It returns how many were removed.
+func (s *ShortTermMemory) RemoveSession(sessionID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := s.getAll()
+	s.entries = make([]MemoryEntry, s.maxSize)
+	s.head, s.count = 0, 0
+	for _, e := range all {
+		if e.SessionID == sessionID {
+			continue
+		}
+		s.entries[s.head] = e
+		s.head = (s.head + 1) % s.maxSize
+		s.count++
+	}
+	return len(all) - s.count
+}
+
+// SessionStats summarizes the entries of one session in the buffer.
+type SessionStats struct {
+	ID         string    `json:"id"`
+	Channel    string    `json:"channel,omitempty"` // from the user entries' metadata
+	Sender     string    `json:"sender,omitempty"`
+	Entries    int       `json:"entries"`
+	LastActive time.Time `json:"last_active"`
+}
+
+// Sessions returns the sessions with entries in the buffer, most recently
+// active first. Entries without a session are not counted.
+func (s *ShortTermMemory) Sessions() []SessionStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byID := make(map[string]*SessionStats)
+	var out []*SessionStats
+	for _, e := range s.getAll() {
+		if e.SessionID == "" {
+			continue
+		}
+		st := byID[e.SessionID]
+		if st == nil {
+			st = &SessionStats{ID: e.SessionID}
+			byID[e.SessionID] = st
+			out = append(out, st)
+		}
+		st.Entries++
+		st.LastActive = e.Timestamp
+		if e.Role == "user" {
+			st.Channel, st.Sender = e.Metadata["channel"], e.Metadata["sender"]
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].LastActive.After(out[j].LastActive) })
+	stats := make([]SessionStats, len(out))
+	for i, st := range out {
+		stats[i] = *st
+	}
+	return stats
+}
+
 // Len returns the number of entries currently stored.
 func (s *ShortTermMemory) Len() int {
 	s.mu.RLock()
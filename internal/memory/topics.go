@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTopicLen bounds topic names, in characters.
+const maxTopicLen = 40
+
+// NormalizeTopic turns a user-given topic name into its stored form:
+// lower case, words joined by dashes, letters, digits, '-' and '_' only
+// ("Novel Draft" → "novel-draft"). It returns "" for names with nothing
+// usable.
+func NormalizeTopic(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+	topic := []rune(b.String())
+	if len(topic) > maxTopicLen {
+		topic = topic[:maxTopicLen]
+	}
+	return strings.TrimRight(string(topic), "-")
+}
+
+// TopicCount is a topic and how many entries it holds.
+type TopicCount struct {
+	Topic   string `json:"topic"`
+	Entries int    `json:"entries"`
+}
+
+// Topics lists the topics in use, largest first.
+func (l *LongTermMemory) Topics() ([]TopicCount, error) {
+	rows, err := l.db.Query(
+		`SELECT topic, COUNT(*) FROM long_term_memory
+		 WHERE topic <> ''
+		 GROUP BY topic
+		 ORDER BY COUNT(*) DESC, topic`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []TopicCount
+	for rows.Next() {
+		var tc TopicCount
+		if err := rows.Scan(&tc.Topic, &tc.Entries); err != nil {
+			return nil, err
+		}
+		out = append(out, tc)
+	}
+	return out, rows.Err()
+}
+
+// Recall returns up to limit entries in topic that viewer may see,
+// best matches for text first. Without usable words in text it returns
+// the topic's newest entries.
+func (l *LongTermMemory) Recall(viewer Viewer, topic, text string, limit int) ([]LongTermEntry, error) {
+	return l.recall(&viewer, topic, text, limit)
+}
+
+// SearchTopic is Recall without visibility checks, for the owner's own
+// tools such as `overhuman memory search --topic`.
+func (l *LongTermMemory) SearchTopic(topic, text string, limit int) ([]LongTermEntry, error) {
+	return l.recall(nil, topic, text, limit)
+}
+
+func (l *LongTermMemory) recall(viewer *Viewer, topic, text string, limit int) ([]LongTermEntry, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	where := `m.topic = ?`
+	args := []any{NormalizeTopic(topic)}
+	if viewer != nil {
+		where += ` AND ` + visibleSQL
+		args = append(args, viewer.sqlArgs()...)
+	}
+	var q string
+	if match := ftsAnyOf(text); match != "" {
+		q = `SELECT ` + ltmColumns + `
+		 FROM long_term_memory m
+		 JOIN long_term_memory_fts f ON m.id = f.id
+		 WHERE ` + where + ` AND long_term_memory_fts MATCH ?
+		 ORDER BY rank
+		 LIMIT ?`
+		args = append(args, match, limit)
+	} else {
+		q = `SELECT ` + ltmColumns + `
+		 FROM long_term_memory m
+		 WHERE ` + where + `
+		 ORDER BY m.created_at DESC
+		 LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := l.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLongTermRows(rows)
+}
+
+// RecallAny returns up to limit entries of any topic that viewer may see
+// and that share a word with text, best matches first.
+func (l *LongTermMemory) RecallAny(viewer Viewer, text string, limit int) ([]LongTermEntry, error) {
+	match := ftsAnyOf(text)
+	if match == "" {
+		return nil, nil
+	}
+	return l.SearchAs(viewer, match, limit)
+}
+
+// ftsAnyOf builds an FTS5 query matching any word of text of three or more
+// characters, each quoted so user text cannot inject FTS syntax.
+func ftsAnyOf(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range words {
+		if len([]rune(w)) < 3 || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, `"`+w+`"`)
+	}
+	return strings.Join(terms, " OR ")
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranscriptEntry is one completed exchange: what was asked and what the
+// agent answered, with its cost.
+type TranscriptEntry struct {
+	Time      time.Time `json:"time"`
+	TaskID    string    `json:"task_id"`
+	SessionID string    `json:"session_id,omitempty"`
+	Sender    string    `json:"sender,omitempty"`
+	Channel   string    `json:"channel"`
+	Input     string    `json:"input"`
+	Result    string    `json:"result"`
+	CostUSD   float64   `json:"cost_usd"`
+	Quality   float64   `json:"quality"`
+	ElapsedMs int64     `json:"elapsed_ms"`
+}
+
+// TranscriptFilter selects entries for export. Zero fields match all.
+type TranscriptFilter struct {
+	Sender    string // case-insensitive exact match
+	SessionID string
+	Since     time.Time
+	Until     time.Time
+}
+
+func (f TranscriptFilter) match(e TranscriptEntry) bool {
+	switch {
+	case f.Sender != "" && !strings.EqualFold(e.Sender, f.Sender):
+		return false
+	case f.SessionID != "" && e.SessionID != f.SessionID:
+		return false
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.Time.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// TranscriptLog appends conversation exchanges as JSON lines. Queries read
+// the file back, which suits the volume of a single agent.
+type TranscriptLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewTranscriptLog opens (or creates the directory for) the log at path.
+func NewTranscriptLog(path string) (*TranscriptLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("transcripts: mkdir: %w", err)
+	}
+	return &TranscriptLog{path: path}, nil
+}
+
+// Append writes e as one JSON line.
+func (l *TranscriptLog) Append(e TranscriptEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("transcripts: encode: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("transcripts: open: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("transcripts: write: %w", err)
+	}
+	return nil
+}
+
+// Query returns the entries matching filter, oldest first. Malformed lines
+// (e.g. a torn final write) are skipped.
+func (l *TranscriptLog) Query(filter TranscriptFilter) ([]TranscriptEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("transcripts: open: %w", err)
+	}
+	defer f.Close()
+
+	var out []TranscriptEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	for sc.Scan() {
+		var e TranscriptEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil && filter.match(e) {
+			out = append(out, e)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("transcripts: read: %w", err)
+	}
+	return out, nil
+}
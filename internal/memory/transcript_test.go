@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTranscriptLog_Query(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcripts.jsonl")
+	l, err := NewTranscriptLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := l.Query(TranscriptFilter{}); err != nil || got != nil {
+		t.Fatalf("empty log = %v, %v", got, err)
+	}
+
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, sender := range []string{"alice", "bob", "Alice"} {
+		if err := l.Append(TranscriptEntry{Time: base.Add(time.Duration(i) * time.Hour), TaskID: string(rune('a' + i)), Sender: sender, Channel: "telegram", SessionID: "s1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A torn final write is skipped.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"task_id":"torn"`)
+	f.Close()
+
+	all, err := l.Query(TranscriptFilter{})
+	if err != nil || len(all) != 3 || all[0].TaskID != "a" {
+		t.Fatalf("all = %+v, %v", all, err)
+	}
+	alice, _ := l.Query(TranscriptFilter{Sender: "ALICE"})
+	if len(alice) != 2 {
+		t.Errorf("sender filter = %d entries, want 2", len(alice))
+	}
+	window, _ := l.Query(TranscriptFilter{Since: base.Add(30 * time.Minute), Until: base.Add(2 * time.Hour)})
+	if len(window) != 1 || window[0].TaskID != "b" {
+		t.Errorf("time window = %+v", window)
+	}
+}
@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Vector kinds: the source table an embedding belongs to.
+const (
+	VectorKindLTM = "ltm"
+	VectorKindSKB = "skb"
+)
+
+// Embedder computes embeddings. brain.Embedder satisfies it.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	EmbeddingModel() string
+}
+
+// VectorStore keeps embeddings of memory rows in SQLite, next to the rows
+// they describe. Each vector records the model and a hash of the embedded
+// text, so stale vectors (changed text or a new model) can be detected.
+type VectorStore struct {
+	db *sql.DB
+}
+
+// NewVectorStore creates the memory_vectors table on db if needed.
+func NewVectorStore(db *sql.DB) (*VectorStore, error) {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS memory_vectors (
+		kind       TEXT NOT NULL,
+		id         TEXT NOT NULL,
+		model      TEXT NOT NULL,
+		text_hash  TEXT NOT NULL,
+		dim        INTEGER NOT NULL,
+		vector     BLOB NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (kind, id)
+	);`)
+	if err != nil {
+		return nil, fmt.Errorf("vector store: %w", err)
+	}
+	return &VectorStore{db: db}, nil
+}
+
+// Put stores (or replaces) the vector of one row.
+func (v *VectorStore) Put(kind, id, model, text string, vec []float32) error {
+	_, err := v.db.Exec(
+		`INSERT OR REPLACE INTO memory_vectors (kind, id, model, text_hash, dim, vector, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		kind, id, model, textHash(text), len(vec), encodeVector(vec), time.Now().UTC(),
+	)
+	return err
+}
+
+// Get returns the vector of a row and the model it was computed with.
+func (v *VectorStore) Get(kind, id string) ([]float32, string, error) {
+	var model string
+	var blob []byte
+	err := v.db.QueryRow(`SELECT model, vector FROM memory_vectors WHERE kind = ? AND id = ?`, kind, id).Scan(&model, &blob)
+	if err != nil {
+		return nil, "", err
+	}
+	return decodeVector(blob), model, nil
+}
+
+// Current returns the hashes of rows of kind already embedded with model,
+// keyed by row ID.
+func (v *VectorStore) Current(kind, model string) (map[string]string, error) {
+	rows, err := v.db.Query(`SELECT id, text_hash FROM memory_vectors WHERE kind = ? AND model = ?`, kind, model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]string)
+	for rows.Next() {
+		var id, h string
+		if err := rows.Scan(&id, &h); err != nil {
+			return nil, err
+		}
+		out[id] = h
+	}
+	return out, rows.Err()
+}
+
+// Count returns the number of vectors of kind computed with model ("" =
+// any model).
+func (v *VectorStore) Count(kind, model string) (int, error) {
+	var n int
+	var err error
+	if model == "" {
+		err = v.db.QueryRow(`SELECT COUNT(*) FROM memory_vectors WHERE kind = ?`, kind).Scan(&n)
+	} else {
+		err = v.db.QueryRow(`SELECT COUNT(*) FROM memory_vectors WHERE kind = ? AND model = ?`, kind, model).Scan(&n)
+	}
+	return n, err
+}
+
+// Prune deletes vectors of kind whose row ID is not in keep, and returns
+// how many were removed.
+func (v *VectorStore) Prune(kind string, keep map[string]bool) (int, error) {
+	rows, err := v.db.Query(`SELECT id FROM memory_vectors WHERE kind = ?`, kind)
+	if err != nil {
+		return 0, err
+	}
+	var stale []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !keep[id] {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+	for _, id := range stale {
+		if _, err := v.db.Exec(`DELETE FROM memory_vectors WHERE kind = ? AND id = ?`, kind, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(stale), nil
+}
+
+// Cosine returns the cosine similarity of a and b (0 if either is empty or
+// the lengths differ).
+func Cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:16])
+}
+
+func encodeVector(vec []float32) []byte {
+	buf := make([]byte, 4*len(vec))
+	for i, x := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vec
+}
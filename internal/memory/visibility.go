@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Visibility says who may see a long-term memory entry at retrieval time.
+type Visibility string
+
+const (
+	// VisibilityShared entries are visible to everyone. Entries stored
+	// before visibility existed, and agent-wide reflections, are shared.
+	VisibilityShared Visibility = ""
+	// VisibilityChannel entries are visible to anyone on the channel they
+	// were learned from (e.g. a team Slack workspace).
+	VisibilityChannel Visibility = "channel"
+	// VisibilityPrivate entries are visible only to the sender they were
+	// learned from.
+	VisibilityPrivate Visibility = "private"
+)
+
+// ParseVisibility accepts "shared" (or "public", ""), "channel" and
+// "private".
+func ParseVisibility(s string) (Visibility, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "shared", "public":
+		return VisibilityShared, nil
+	case "channel":
+		return VisibilityChannel, nil
+	case "private":
+		return VisibilityPrivate, nil
+	}
+	return "", fmt.Errorf("unknown memory visibility %q (want shared, channel or private)", s)
+}
+
+// String returns the visibility's name, "shared" for the zero value.
+func (v Visibility) String() string {
+	if v == VisibilityShared {
+		return "shared"
+	}
+	return string(v)
+}
+
+// Viewer is who a retrieval is for: the sender and channel of the task
+// that will see the results.
+type Viewer struct {
+	Sender  string
+	Channel string
+}
+
+// CanSee reports whether v may see e. A private entry without an owner
+// falls back to its channel.
+func (v Viewer) CanSee(e LongTermEntry) bool {
+	switch e.Visibility {
+	case VisibilityShared:
+		return true
+	case VisibilityChannel:
+		return e.Channel != "" && e.Channel == v.Channel
+	case VisibilityPrivate:
+		if e.Owner == "" {
+			return e.Channel != "" && e.Channel == v.Channel
+		}
+		return e.Owner == v.Sender && (e.Channel == "" || e.Channel == v.Channel)
+	}
+	return false
+}
+
+// visibleSQL is the SQL form of Viewer.CanSee on alias m; its arguments
+// are (channel, channel, sender, channel).
+const visibleSQL = `(m.visibility = ''
+		OR (m.visibility = 'channel' AND m.channel <> '' AND m.channel = ?)
+		OR (m.visibility = 'private' AND m.owner = '' AND m.channel <> '' AND m.channel = ?)
+		OR (m.visibility = 'private' AND m.owner <> '' AND m.owner = ? AND (m.channel = '' OR m.channel = ?)))`
+
+func (v Viewer) sqlArgs() []any {
+	return []any{v.Channel, v.Channel, v.Sender, v.Channel}
+}
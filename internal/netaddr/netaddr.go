@@ -0,0 +1,174 @@
+// Package netaddr parses the listen addresses used by the daemon's servers
+// (API, WebSocket, kiosk, channel webhooks) and builds matching clients.
+//
+// An address is either a TCP host:port — IPv4 ("127.0.0.1:9090"), IPv6
+// ("[::1]:9090") or a name ("localhost:9090") — or a unix domain socket
+// written "unix:/path/to/overhuman.sock". Unix sockets are created with
+// 0600 permissions, so only the owning user can talk to the daemon.
+package netaddr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnixPrefix marks a unix domain socket address.
+const UnixPrefix = "unix:"
+
+// Parse splits addr into a network ("tcp" or "unix") and the address to
+// pass to net.Listen/net.Dial.
+func Parse(addr string) (network, address string) {
+	addr = strings.TrimSpace(addr)
+	if path, ok := strings.CutPrefix(addr, UnixPrefix); ok {
+		// Accept "unix:///run/x.sock" as well as "unix:/run/x.sock".
+		if strings.HasPrefix(path, "//") {
+			path = strings.TrimPrefix(path, "//")
+		}
+		return "unix", path
+	}
+	return "tcp", addr
+}
+
+// IsUnix reports whether addr is a unix domain socket address.
+func IsUnix(addr string) bool {
+	network, _ := Parse(addr)
+	return network == "unix"
+}
+
+// Listen opens a listener for addr. For unix sockets a stale socket file
+// left by a crashed daemon is removed first, and the new socket is made
+// accessible to its owner only.
+func Listen(addr string) (net.Listener, error) {
+	network, address := Parse(addr)
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("empty unix socket path")
+	}
+	if err := os.MkdirAll(filepath.Dir(address), 0o700); err != nil {
+		return nil, err
+	}
+	if fi, err := os.Lstat(address); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		// Only remove it if nobody is listening.
+		if c, err := net.DialTimeout("unix", address, 200*time.Millisecond); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("listen unix %s: address already in use", address)
+		}
+		os.Remove(address)
+	}
+	ln, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// String formats a listener's address in the same notation Parse accepts.
+func String(ln net.Listener) string {
+	a := ln.Addr()
+	if a.Network() == "unix" {
+		return UnixPrefix + a.String()
+	}
+	return a.String()
+}
+
+// Offset derives a sibling address: for TCP the port is increased by n
+// ("[::1]:9090" → "[::1]:9091"); for a unix socket the suffix is added to
+// the file name ("unix:/run/oh.sock" → "unix:/run/oh-ws.sock"). It returns
+// fallback if addr cannot be parsed.
+func Offset(addr string, n int, suffix, fallback string) string {
+	network, address := Parse(addr)
+	if network == "unix" {
+		if address == "" {
+			return fallback
+		}
+		ext := filepath.Ext(address)
+		return UnixPrefix + strings.TrimSuffix(address, ext) + "-" + suffix + ext
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return fallback
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fallback
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port+n))
+}
+
+// IsWildcard reports whether addr listens on all interfaces (":9090",
+// "0.0.0.0:9090", "[::]:9090").
+func IsWildcard(addr string) bool {
+	network, address := Parse(addr)
+	if network != "tcp" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(address)
+	return err == nil && (host == "" || host == "0.0.0.0" || host == "::")
+}
+
+// URL returns the http URL for path on the server listening at addr. Unix
+// socket URLs use the placeholder host "unix"; pair them with HTTPClient.
+func URL(addr, path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if IsUnix(addr) {
+		return "http://unix" + path
+	}
+	_, address := Parse(addr)
+	if IsWildcard(addr) {
+		// Wildcard listen addresses are reached via loopback.
+		host, port, _ := net.SplitHostPort(address)
+		loopback := "127.0.0.1"
+		if host == "::" {
+			loopback = "::1"
+		}
+		address = net.JoinHostPort(loopback, port)
+	}
+	return "http://" + address + path
+}
+
+// HTTPClient returns a client that reaches the server at addr, dialing the
+// socket directly for unix addresses.
+func HTTPClient(addr string, timeout time.Duration) *http.Client {
+	network, address := Parse(addr)
+	if network != "unix" {
+		return &http.Client{Timeout: timeout}
+	}
+	var d net.Dialer
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return d.DialContext(ctx, "unix", address)
+			},
+		},
+	}
+}
+
+// IsLocal reports whether r arrived over a unix socket or from a loopback
+// address.
+func IsLocal(r *http.Request) bool {
+	if la, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && la.Network() == "unix" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,135 @@
+package netaddr
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		addr, network, address string
+	}{
+		{"127.0.0.1:9090", "tcp", "127.0.0.1:9090"},
+		{"[::1]:9090", "tcp", "[::1]:9090"},
+		{":9090", "tcp", ":9090"},
+		{"unix:/run/oh.sock", "unix", "/run/oh.sock"},
+		{"unix:///run/oh.sock", "unix", "/run/oh.sock"},
+	}
+	for _, tt := range tests {
+		network, address := Parse(tt.addr)
+		if network != tt.network || address != tt.address {
+			t.Errorf("Parse(%q) = %q, %q; want %q, %q", tt.addr, network, address, tt.network, tt.address)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		addr, want string
+	}{
+		{"127.0.0.1:9090", "127.0.0.1:9091"},
+		{"[::1]:9090", "[::1]:9091"},
+		{"[fe80::1%eth0]:9090", "[fe80::1%eth0]:9091"},
+		{":9090", ":9091"},
+		{"unix:/run/oh/api.sock", "unix:/run/oh/api-ws.sock"},
+		{"garbage", "fallback"},
+	}
+	for _, tt := range tests {
+		if got := Offset(tt.addr, 1, "ws", "fallback"); got != tt.want {
+			t.Errorf("Offset(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		addr, want string
+	}{
+		{"127.0.0.1:9090", "http://127.0.0.1:9090/health"},
+		{"[::1]:9090", "http://[::1]:9090/health"},
+		{"0.0.0.0:9090", "http://127.0.0.1:9090/health"},
+		{"[::]:9090", "http://[::1]:9090/health"},
+		{"unix:/run/oh.sock", "http://unix/health"},
+	}
+	for _, tt := range tests {
+		if got := URL(tt.addr, "health"); got != tt.want {
+			t.Errorf("URL(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestUnixSocketRoundTrip(t *testing.T) {
+	dir, err := os.MkdirTemp("", "oh") // short path: sun_path is ~108 bytes
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	addr := UnixPrefix + filepath.Join(dir, "api.sock")
+
+	ln, err := Listen(addr)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	if got := String(ln); got != addr {
+		t.Errorf("String = %q, want %q", got, addr)
+	}
+	fi, err := os.Stat(filepath.Join(dir, "api.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsLocal(r) {
+			io.WriteString(w, "local")
+		}
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	resp, err := HTTPClient(addr, 2*time.Second).Get(URL(addr, "/"))
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "local" {
+		t.Errorf("body = %q, want local", body)
+	}
+
+	// A second listener on a live socket must fail rather than steal it.
+	if _, err := Listen(addr); err == nil {
+		t.Error("Listen on a socket in use succeeded")
+	}
+}
+
+func TestListen_RemovesStaleSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "oh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	addr := UnixPrefix + filepath.Join(dir, "stale.sock")
+
+	ln, err := Listen(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash: the file stays behind but nobody listens.
+	if ul, ok := ln.(interface{ SetUnlinkOnClose(bool) }); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	ln.Close()
+
+	ln2, err := Listen(addr)
+	if err != nil {
+		t.Fatalf("Listen over stale socket: %v", err)
+	}
+	ln2.Close()
+}
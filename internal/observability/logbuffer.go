@@ -0,0 +1,202 @@
+package observability
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLogBufferSize is how many log lines a LogBuffer keeps.
+const DefaultLogBufferSize = 2000
+
+// LogEntry is one line of daemon log output.
+type LogEntry struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`               // DEBUG, INFO, WARN or ERROR
+	Component string    `json:"component,omitempty"` // the "[daemon]" tag, or slog's "component"
+	TaskID    string    `json:"task_id,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// LogFilter selects log entries. The zero value matches INFO and above.
+type LogFilter struct {
+	MinLevel slog.Level
+	TaskID   string
+	After    uint64 // only entries with a larger Seq
+}
+
+// Match reports whether e passes the filter.
+func (f LogFilter) Match(e LogEntry) bool {
+	if e.Seq <= f.After || f.TaskID != "" && e.TaskID != f.TaskID {
+		return false
+	}
+	return parseLevel(e.Level) >= f.MinLevel
+}
+
+// LogBuffer keeps the most recent log lines and fans new ones out to
+// subscribers. It is an io.Writer, teed into the daemon's log output, so it
+// sees both log.Printf lines and slog records written through the default
+// logger.
+type LogBuffer struct {
+	mu      sync.Mutex
+	entries []LogEntry // ring
+	next    int
+	seq     uint64
+	partial []byte
+	subs    map[chan LogEntry]struct{}
+}
+
+// NewLogBuffer creates a buffer of size lines (0 = DefaultLogBufferSize).
+func NewLogBuffer(size int) *LogBuffer {
+	if size <= 0 {
+		size = DefaultLogBufferSize
+	}
+	return &LogBuffer{entries: make([]LogEntry, 0, size), subs: make(map[chan LogEntry]struct{})}
+}
+
+// Write records each complete line of p.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data := append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimRight(string(data[:i]), "\r"); strings.TrimSpace(line) != "" {
+			b.add(parseLogLine(line, time.Now()))
+		}
+		data = data[i+1:]
+	}
+	b.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+func (b *LogBuffer) add(e LogEntry) {
+	b.seq++
+	e.Seq = b.seq
+	if len(b.entries) < cap(b.entries) {
+		b.entries = append(b.entries, e)
+	} else {
+		b.entries[b.next] = e
+		b.next = (b.next + 1) % len(b.entries)
+	}
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default: // slow reader: drop rather than block logging
+		}
+	}
+}
+
+// Entries returns the last n buffered entries matching f, oldest first
+// (n <= 0 = all).
+func (b *LogBuffer) Entries(f LogFilter, n int) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []LogEntry
+	for i := range b.entries {
+		e := b.entries[(b.next+i)%len(b.entries)]
+		if f.Match(e) {
+			out = append(out, e)
+		}
+	}
+	if n > 0 && len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out
+}
+
+// Subscribe returns a channel receiving new entries until cancel is
+// called. Entries are dropped while the channel is full.
+func (b *LogBuffer) Subscribe() (<-chan LogEntry, func()) {
+	ch := make(chan LogEntry, 256)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+var (
+	// logTimestamp is the log.LstdFlags prefix, e.g. "2026/10/15 09:30:00 ".
+	logTimestamp = regexp.MustCompile(`^\d{4}/\d\d/\d\d \d\d:\d\d:\d\d(\.\d+)? `)
+	logComponent = regexp.MustCompile(`^\[([\w.-]+)\] ?`)
+	logTask      = regexp.MustCompile(`\btask(?:_id)?=([^\s,]+)`)
+	slogLevels   = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+)
+
+// parseLogLine extracts the level, component and task of a log line. slog
+// lines carry their level; for plain log.Printf lines it is inferred from
+// the text ("WARN", "error", "failed").
+func parseLogLine(line string, now time.Time) LogEntry {
+	e := LogEntry{Time: now}
+	if loc := logTimestamp.FindStringIndex(line); loc != nil {
+		if t, err := time.ParseInLocation("2006/01/02 15:04:05", strings.TrimSpace(line[:loc[1]])[:19], time.Local); err == nil {
+			e.Time = t
+		}
+		line = line[loc[1]:]
+	}
+	for _, lvl := range slogLevels {
+		if rest, ok := strings.CutPrefix(line, lvl+" "); ok {
+			e.Level, line = lvl, rest
+			break
+		}
+	}
+	if m := logComponent.FindStringSubmatch(line); m != nil {
+		e.Component = m[1]
+		line = line[len(m[0]):]
+	} else if i := strings.Index(line, "component="); i >= 0 {
+		if f := strings.Fields(line[i+len("component="):]); len(f) > 0 {
+			e.Component = f[0]
+		}
+	}
+	if m := logTask.FindStringSubmatch(line); m != nil {
+		e.TaskID = strings.Trim(m[1], `"`)
+	}
+	if e.Level == "" {
+		lower := strings.ToLower(line)
+		switch {
+		case strings.Contains(lower, "panic"), strings.Contains(lower, "fatal"):
+			e.Level = "ERROR"
+		case strings.Contains(line, "WARN"), strings.Contains(lower, "error"), strings.Contains(lower, "failed"):
+			e.Level = "WARN"
+		default:
+			e.Level = "INFO"
+		}
+	}
+	e.Message = line
+	return e
+}
+
+// parseLevel maps a level name to its slog.Level (INFO when unknown).
+func parseLevel(s string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// ParseLogLevel parses a level filter such as "warn" (case-insensitive).
+func ParseLogLevel(s string) (slog.Level, bool) {
+	var l slog.Level
+	if s == "" {
+		return slog.LevelDebug, true
+	}
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, false
+	}
+	return l, true
+}
@@ -0,0 +1,73 @@
+package observability
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		line                         string
+		level, component, task, text string
+	}{
+		{"2026/10/15 09:30:00 [daemon] completed task=t_1 quality=90%", "INFO", "daemon", "t_1", "completed task=t_1 quality=90%"},
+		{"2026/10/15 09:30:00 [pipeline] WARN: scratch space unavailable task_id=t_2", "WARN", "pipeline", "t_2", "WARN: scratch space unavailable task_id=t_2"},
+		{"2026/10/15 09:30:00 [daemon] run error: boom", "WARN", "daemon", "", "run error: boom"},
+		{"2026/10/15 09:30:00 ERROR email delivery failed component=email to=a@b", "ERROR", "email", "", "email delivery failed component=email to=a@b"},
+		{"plain line", "INFO", "", "", "plain line"},
+	} {
+		e := parseLogLine(tc.line, now)
+		if e.Level != tc.level || e.Component != tc.component || e.TaskID != tc.task || e.Message != tc.text {
+			t.Errorf("parseLogLine(%q) = %+v", tc.line, e)
+		}
+	}
+	if e := parseLogLine("2026/10/15 09:30:00 x", now); e.Time.Hour() != 9 || e.Time.Day() != 15 {
+		t.Errorf("timestamp = %v", e.Time)
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	buf := NewLogBuffer(3)
+	logger := log.New(buf, "", log.LstdFlags)
+	live, cancel := buf.Subscribe()
+	defer cancel()
+
+	for i := 1; i <= 4; i++ {
+		logger.Printf("[daemon] step %d task=t%d", i, i%2)
+	}
+	buf.Write([]byte("[daemon] WARN: half a li"))
+	buf.Write([]byte("ne\n"))
+
+	all := buf.Entries(LogFilter{}, 0)
+	if len(all) != 3 || all[0].Seq != 3 || all[2].Message != "WARN: half a line" {
+		t.Fatalf("ring = %+v", all)
+	}
+	if got := buf.Entries(LogFilter{TaskID: "t0"}, 0); len(got) != 1 || got[0].Seq != 4 {
+		t.Errorf("task filter = %+v", got)
+	}
+	if got := buf.Entries(LogFilter{MinLevel: slog.LevelWarn}, 0); len(got) != 1 || got[0].Seq != 5 {
+		t.Errorf("level filter = %+v", got)
+	}
+	if got := buf.Entries(LogFilter{After: 4}, 0); len(got) != 1 {
+		t.Errorf("after filter = %+v", got)
+	}
+	if got := buf.Entries(LogFilter{}, 1); len(got) != 1 || got[0].Seq != 5 {
+		t.Errorf("tail = %+v", got)
+	}
+	for i := 1; i <= 5; i++ {
+		if e := <-live; e.Seq != uint64(i) {
+			t.Fatalf("subscriber got %d, want %d", e.Seq, i)
+		}
+	}
+
+	// A subscriber that does not read never blocks logging.
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(buf, "line %d\n", i)
+	}
+	cancel()
+	fmt.Fprintln(buf, "after cancel")
+}
@@ -18,6 +18,7 @@ const (
 	MetricReflection MetricType = "reflection"
 	MetricErrors     MetricType = "errors"
 	MetricPatterns   MetricType = "patterns"
+	MetricQueueWait  MetricType = "queue_wait_ms" // time an input waited for a worker
 )
 
 // MetricPoint is a single recorded data point.
@@ -37,6 +38,11 @@ type MetricsCollector struct {
 	points   []MetricPoint
 	maxSize  int // Ring buffer capacity
 	counters map[string]int64
+	totals   map[MetricType]*total // every point ever recorded, for Prometheus
+
+	families map[string]*family        // labeled counters and histograms
+	gauges   map[string]func() float64 // sampled at scrape time
+	help     map[string]string
 }
 
 // NewMetricsCollector creates a collector with a max ring buffer size.
@@ -48,6 +54,10 @@ func NewMetricsCollector(maxSize int) *MetricsCollector {
 		points:   make([]MetricPoint, 0, maxSize),
 		maxSize:  maxSize,
 		counters: make(map[string]int64),
+		totals:   make(map[MetricType]*total),
+		families: make(map[string]*family),
+		gauges:   make(map[string]func() float64),
+		help:     make(map[string]string),
 	}
 }
 
@@ -62,6 +72,13 @@ func (c *MetricsCollector) Record(mt MetricType, value float64, labels Labels) {
 		Labels:    labels,
 		Timestamp: time.Now(),
 	}
+	t := c.totals[mt]
+	if t == nil {
+		t = &total{}
+		c.totals[mt] = t
+	}
+	t.count++
+	t.sum += value
 
 	if len(c.points) >= c.maxSize {
 		// Shift left (drop oldest).
@@ -181,6 +198,8 @@ func (c *MetricsCollector) Reset() {
 	defer c.mu.Unlock()
 	c.points = c.points[:0]
 	c.counters = make(map[string]int64)
+	c.totals = make(map[MetricType]*total)
+	c.families = make(map[string]*family)
 }
 
 // Snapshot returns a copy of current counters.
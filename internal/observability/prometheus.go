@@ -0,0 +1,270 @@
+package observability
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusPrefix is prepended to every exported metric name.
+const PrometheusPrefix = "overhuman_"
+
+// DefaultBuckets are the upper bounds, in seconds, of histograms recorded
+// with Observe: from a quick intake stage to a long execution.
+var DefaultBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// total is the running count and sum of a MetricType.
+type total struct {
+	count uint64
+	sum   float64
+}
+
+// family is a labeled counter or histogram and its series.
+type family struct {
+	histogram bool
+	series    map[string]*series // by rendered labels
+}
+
+// series is one label combination of a family.
+type series struct {
+	labels Labels
+	value  float64  // counter value, or histogram sum
+	counts []uint64 // histogram: observations per bucket, +Inf last
+	count  uint64
+}
+
+// Describe sets the help text exported with the metric name.
+func (c *MetricsCollector) Describe(name, help string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.help[name] = help
+}
+
+// AddCounter adds v (>= 0) to the counter name with labels, e.g.
+// AddCounter("llm_input_tokens_total", Labels{"provider": "openai"}, 812).
+func (c *MetricsCollector) AddCounter(name string, labels Labels, v float64) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seriesFor(name, labels, false).value += v
+}
+
+// Observe records v in the histogram name with labels, using
+// DefaultBuckets.
+func (c *MetricsCollector) Observe(name string, labels Labels, v float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.seriesFor(name, labels, true)
+	i := sort.SearchFloat64s(DefaultBuckets, v)
+	s.counts[i]++
+	s.count++
+	s.value += v
+}
+
+// SetGauge exports fn's value, read at every scrape, as the gauge name,
+// e.g. the number of connected clients.
+func (c *MetricsCollector) SetGauge(name string, fn func() float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gauges[name] = fn
+}
+
+// seriesFor returns the series of name with labels, creating it. Callers
+// hold c.mu.
+func (c *MetricsCollector) seriesFor(name string, labels Labels, histogram bool) *series {
+	f := c.families[name]
+	if f == nil {
+		f = &family{histogram: histogram, series: make(map[string]*series)}
+		c.families[name] = f
+	}
+	key := formatLabels(labels, "", "")
+	s := f.series[key]
+	if s == nil {
+		s = &series{labels: labels}
+		if f.histogram {
+			s.counts = make([]uint64, len(DefaultBuckets)+1)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// WritePrometheus writes every metric in the Prometheus text exposition
+// format:
+//
+//   - named counters (Increment) as <name>_total
+//   - recorded metric types (Record) as summaries over the rolling window,
+//     with a _count and _sum over everything recorded
+//   - labeled counters (AddCounter), histograms (Observe) and gauges
+//     (SetGauge) under their own names
+func (c *MetricsCollector) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	c.mu.RLock()
+	gauges := make(map[string]func() float64, len(c.gauges))
+	gaugeHelp := make(map[string]string, len(c.gauges))
+	for name, fn := range c.gauges {
+		gauges[name], gaugeHelp[name] = fn, c.help[name]
+	}
+	c.writeCounters(bw)
+	c.writeSummaries(bw)
+	c.writeFamilies(bw)
+	c.mu.RUnlock()
+
+	// Gauges are read unlocked: they may call back into the collector.
+	for _, name := range sortedKeys(gauges) {
+		writeHeader(bw, name, "gauge", gaugeHelp[name])
+		fmt.Fprintf(bw, "%s%s %s\n", PrometheusPrefix, name, formatFloat(gauges[name]()))
+	}
+	return bw.Flush()
+}
+
+func (c *MetricsCollector) writeCounters(w *bufio.Writer) {
+	for _, name := range sortedKeys(c.counters) {
+		metric := sanitizeMetricName(name) + "_total"
+		writeHeader(w, metric, "counter", c.help[metric])
+		fmt.Fprintf(w, "%s%s %d\n", PrometheusPrefix, metric, c.counters[name])
+	}
+}
+
+func (c *MetricsCollector) writeSummaries(w *bufio.Writer) {
+	types := make([]string, 0, len(c.totals))
+	for mt := range c.totals {
+		types = append(types, string(mt))
+	}
+	sort.Strings(types)
+	for _, mt := range types {
+		var values []float64
+		for _, p := range c.points {
+			if string(p.Type) == mt {
+				values = append(values, p.Value)
+			}
+		}
+		sort.Float64s(values)
+		name := sanitizeMetricName(mt)
+		writeHeader(w, name, "summary", c.help[name])
+		for _, q := range []float64{0.5, 0.95, 0.99} {
+			v := math.NaN()
+			if len(values) > 0 {
+				v = percentile(values, q)
+			}
+			fmt.Fprintf(w, "%s%s{quantile=%q} %s\n", PrometheusPrefix, name, formatFloat(q), formatFloat(v))
+		}
+		t := c.totals[MetricType(mt)]
+		fmt.Fprintf(w, "%s%s_sum %s\n", PrometheusPrefix, name, formatFloat(t.sum))
+		fmt.Fprintf(w, "%s%s_count %d\n", PrometheusPrefix, name, t.count)
+	}
+}
+
+func (c *MetricsCollector) writeFamilies(w *bufio.Writer) {
+	for _, name := range sortedKeys(c.families) {
+		f := c.families[name]
+		kind := "counter"
+		if f.histogram {
+			kind = "histogram"
+		}
+		writeHeader(w, name, kind, c.help[name])
+		for _, key := range sortedKeys(f.series) {
+			s := f.series[key]
+			if !f.histogram {
+				fmt.Fprintf(w, "%s%s%s %s\n", PrometheusPrefix, name, key, formatFloat(s.value))
+				continue
+			}
+			var cum uint64
+			for i, n := range s.counts {
+				cum += n
+				le := "+Inf"
+				if i < len(DefaultBuckets) {
+					le = formatFloat(DefaultBuckets[i])
+				}
+				fmt.Fprintf(w, "%s%s_bucket%s %d\n", PrometheusPrefix, name, formatLabels(s.labels, "le", le), cum)
+			}
+			fmt.Fprintf(w, "%s%s_sum%s %s\n", PrometheusPrefix, name, key, formatFloat(s.value))
+			fmt.Fprintf(w, "%s%s_count%s %d\n", PrometheusPrefix, name, key, s.count)
+		}
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, kind, help string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s%s %s\n", PrometheusPrefix, name, strings.ReplaceAll(help, "\n", " "))
+	}
+	fmt.Fprintf(w, "# TYPE %s%s %s\n", PrometheusPrefix, name, kind)
+}
+
+// Handler serves the metrics in the Prometheus text format, for GET
+// /metrics.
+func (c *MetricsCollector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.WritePrometheus(w)
+	})
+}
+
+// formatLabels renders labels as {k="v",...} in key order, plus extraKey
+// if set (the le of a histogram bucket). No labels render as "".
+func formatLabels(labels Labels, extraKey, extraValue string) string {
+	keys := sortedKeys(labels)
+	if len(keys) == 0 && extraKey == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%s", sanitizeMetricName(k), quoteLabel(labels[k]))
+	}
+	if extraKey != "" {
+		if len(keys) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%s", extraKey, quoteLabel(extraValue))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(v string) string { return `"` + labelEscaper.Replace(v) + `"` }
+
+// sanitizeMetricName maps a name such as "supervisor.restarts" or
+// "latency_ms" onto the Prometheus name charset.
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
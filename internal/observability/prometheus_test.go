@@ -0,0 +1,74 @@
+package observability
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	c := NewMetricsCollector(2)
+	c.Increment("supervisor.restarts")
+	for _, v := range []float64{100, 200, 300} {
+		c.Record(MetricLatency, v, Labels{"task_id": "t"})
+	}
+	c.Describe("llm_input_tokens_total", "Prompt tokens sent.")
+	c.AddCounter("llm_input_tokens_total", Labels{"provider": "openai", "model": "gpt-4o"}, 500)
+	c.AddCounter("llm_input_tokens_total", Labels{"provider": "openai", "model": "gpt-4o"}, 12)
+	c.AddCounter("llm_input_tokens_total", Labels{"provider": "claude", "model": `we"ird`}, 3)
+	c.Observe("stage_seconds", Labels{"stage": "plan"}, 0.2)
+	c.Observe("stage_seconds", Labels{"stage": "plan"}, 1000)
+	c.SetGauge("ws_clients", func() float64 { return 4 })
+
+	var b strings.Builder
+	if err := c.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE overhuman_supervisor_restarts_total counter\noverhuman_supervisor_restarts_total 1\n",
+		"# TYPE overhuman_latency_ms summary\n",
+		`overhuman_latency_ms{quantile="0.5"} 250` + "\n", // window holds the last two
+		"overhuman_latency_ms_sum 600\noverhuman_latency_ms_count 3\n",
+		"# HELP overhuman_llm_input_tokens_total Prompt tokens sent.\n# TYPE overhuman_llm_input_tokens_total counter\n",
+		`overhuman_llm_input_tokens_total{model="gpt-4o",provider="openai"} 512` + "\n",
+		`overhuman_llm_input_tokens_total{model="we\"ird",provider="claude"} 3` + "\n",
+		"# TYPE overhuman_stage_seconds histogram\n",
+		`overhuman_stage_seconds_bucket{stage="plan",le="0.1"} 0` + "\n",
+		`overhuman_stage_seconds_bucket{stage="plan",le="0.25"} 1` + "\n",
+		`overhuman_stage_seconds_bucket{stage="plan",le="300"} 1` + "\n",
+		`overhuman_stage_seconds_bucket{stage="plan",le="+Inf"} 2` + "\n",
+		`overhuman_stage_seconds_sum{stage="plan"} 1000.2` + "\n",
+		`overhuman_stage_seconds_count{stage="plan"} 2` + "\n",
+		"# TYPE overhuman_ws_clients gauge\noverhuman_ws_clients 4\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsCollector_Handler(t *testing.T) {
+	c := NewMetricsCollector(10)
+	c.Increment("runs")
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "overhuman_runs_total 1") {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
+func TestMetricsCollector_ResetClearsSeries(t *testing.T) {
+	c := NewMetricsCollector(10)
+	c.AddCounter("x_total", nil, 1)
+	c.Record(MetricRuns, 1, nil)
+	c.Reset()
+	var b strings.Builder
+	c.WritePrometheus(&b)
+	if b.Len() != 0 {
+		t.Errorf("after Reset:\n%s", b.String())
+	}
+}
@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// ErrCancelled is the cause of a run cancelled with Cancel.
+var ErrCancelled = errors.New("task cancelled")
+
+// cancelledMessage is the result of a cancelled run.
+const cancelledMessage = "Task cancelled."
+
+// ActiveTask is a run in progress.
+type ActiveTask struct {
+	TaskID        string    `json:"task_id"`
+	InputID       string    `json:"input_id"`
+	CorrelationID string    `json:"correlation_id,omitempty"` // e.g. the WebSocket connection the input came from
+	Channel       string    `json:"channel"`
+	Sender        string    `json:"sender,omitempty"`
+	Started       time.Time `json:"started"`
+}
+
+type activeTask struct {
+	ActiveTask
+	cancel    context.CancelCauseFunc
+	cancelled bool
+}
+
+// taskRegistry tracks the runs in progress so they can be cancelled.
+type taskRegistry struct {
+	mu    sync.Mutex
+	tasks map[string]*activeTask
+}
+
+// begin registers the run of taskID and returns the context it runs under.
+func (r *taskRegistry) begin(ctx context.Context, taskID string, input senses.UnifiedInput) (context.Context, *activeTask) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	t := &activeTask{
+		ActiveTask: ActiveTask{
+			TaskID:        taskID,
+			InputID:       input.InputID,
+			CorrelationID: input.CorrelationID,
+			Channel:       string(input.SourceType),
+			Sender:        input.SourceMeta.Sender,
+			Started:       time.Now(),
+		},
+		cancel: cancel,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tasks == nil {
+		r.tasks = make(map[string]*activeTask)
+	}
+	r.tasks[taskID] = t
+	return ctx, t
+}
+
+// end unregisters t and reports whether it was cancelled.
+func (r *taskRegistry) end(t *activeTask) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tasks, t.TaskID)
+	t.cancel(nil)
+	return t.cancelled
+}
+
+// ActiveTasks lists the runs in progress, oldest first.
+func (p *Pipeline) ActiveTasks() []ActiveTask {
+	p.active.mu.Lock()
+	defer p.active.mu.Unlock()
+	out := make([]ActiveTask, 0, len(p.active.tasks))
+	for _, t := range p.active.tasks {
+		out = append(out, t.ActiveTask)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.Before(out[j].Started) })
+	return out
+}
+
+// Cancel aborts the run of taskID: its context is cancelled and Run
+// returns a result with Cancelled set. It reports whether the task was
+// running.
+func (p *Pipeline) Cancel(taskID string) bool {
+	p.active.mu.Lock()
+	defer p.active.mu.Unlock()
+	t, ok := p.active.tasks[taskID]
+	if !ok {
+		return false
+	}
+	t.cancelled = true
+	t.cancel(ErrCancelled)
+	p.logInfo("task cancelled", "task_id", taskID)
+	return true
+}
+
+// cancelledResult replaces the result of a cancelled run; rr is what the
+// run returned, possibly nil.
+func (p *Pipeline) cancelledResult(ts *TaskSpec, start time.Time, rr *RunResult) *RunResult {
+	p.incrementMetric("pipeline.cancelled")
+	out := &RunResult{
+		TaskID:    ts.ID,
+		Result:    cancelledMessage,
+		Cancelled: true,
+		ElapsedMs: time.Since(start).Milliseconds(),
+	}
+	if rr != nil {
+		out.CostUSD, out.StageLogs = rr.CostUSD, rr.StageLogs
+	}
+	return out
+}
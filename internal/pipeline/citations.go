@@ -0,0 +1,41 @@
+package pipeline
+
+import (
+	"time"
+
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+// sourceSummaryChars bounds the memory summary quoted in a citation.
+const sourceSummaryChars = 200
+
+// MemorySource cites a long-term memory entry that was in the execution
+// context of a run, so users can check — and correct — what the agent
+// believed it knew.
+type MemorySource struct {
+	ID      string    `json:"id"`                // memory entry ID
+	TaskID  string    `json:"task_id,omitempty"` // run the entry was learned from
+	Date    time.Time `json:"date"`              // when it was learned
+	Channel string    `json:"channel,omitempty"`
+	Topic   string    `json:"topic,omitempty"`
+	Via     string    `json:"via,omitempty"` // why it was recalled: "topic", "related" or the contact's name
+	Summary string    `json:"summary"`
+}
+
+// cite records e as a source of ts, once per entry.
+func (ts *TaskSpec) cite(e memory.LongTermEntry, via string) {
+	for _, s := range ts.Sources {
+		if s.ID == e.ID {
+			return
+		}
+	}
+	ts.Sources = append(ts.Sources, MemorySource{
+		ID:      e.ID,
+		TaskID:  e.SourceRunID,
+		Date:    e.CreatedAt,
+		Channel: e.Channel,
+		Topic:   e.Topic,
+		Via:     via,
+		Summary: truncateRunes(e.Summary, sourceSummaryChars),
+	})
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// clarifyFormat describes the clarification object's fields.
+const clarifyFormat = `"goal": "<clarified goal>", "constraints": ["<constraint>", ...], "expected_output": "<what to produce>", "verification": "<how to verify>"`
+
+// clarifySchema is the JSON Schema of clarification.
+var clarifySchema = brain.Schema{
+	Name:        "clarification",
+	Description: "The clarified task.",
+	JSON: json.RawMessage(`{"type": "object", "properties": {
+		"goal": {"type": "string"},
+		"constraints": {"type": "array", "items": {"type": "string"}},
+		"expected_output": {"type": "string"},
+		"verification": {"type": "string"},
+		"ambiguity": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+	}, "required": ["goal", "constraints", "expected_output", "verification"]}`),
+}
+
+// clarification is the reply of the clarification stage.
+type clarification struct {
+	Goal           string   `json:"goal"`
+	Constraints    []string `json:"constraints"`
+	ExpectedOutput string   `json:"expected_output"`
+	Verification   string   `json:"verification"`
+	// Ambiguity holds two readings of an ambiguous task (speculation only).
+	Ambiguity []string `json:"ambiguity,omitempty"`
+
+	raw string // a reply in the older "GOAL: ..." lines
+}
+
+// ParseText implements brain.TextReply for the older "GOAL: ...\nCONSTRAINTS:
+// ..." lines, which are kept as they are.
+func (c *clarification) ParseText(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if _, ok := cutPrefixFold(strings.TrimSpace(line), "GOAL:"); ok {
+			c.raw = strings.TrimSpace(text)
+			return true
+		}
+	}
+	return false
+}
+
+// Validate implements brain.ValidReply.
+func (c *clarification) Validate() error {
+	if c.raw == "" && strings.TrimSpace(c.Goal) == "" {
+		return errors.New("goal is missing")
+	}
+	return nil
+}
+
+// String renders the clarification in the line format the later stages
+// are given as the task's context.
+func (c clarification) String() string {
+	if c.raw != "" {
+		return c.raw
+	}
+	var b strings.Builder
+	line := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			b.WriteString(key + ": " + value + "\n")
+		}
+	}
+	line("GOAL", c.Goal)
+	line("CONSTRAINTS", strings.Join(c.Constraints, ", "))
+	line("EXPECTED_OUTPUT", c.ExpectedOutput)
+	line("VERIFICATION", c.Verification)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// interpretations returns the two readings of an ambiguous task, or nil.
+func (c clarification) interpretations() []string {
+	if c.raw != "" {
+		return parseInterpretations(c.raw)
+	}
+	return readings(c.Ambiguity)
+}
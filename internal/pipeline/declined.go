@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// ErrDeclined is returned by the execution stage when the provider declined
+// the request (a refusal or its content filter), even after a softened
+// retry. Run turns it into a declined result rather than an error.
+var ErrDeclined = errors.New("the provider declined this request")
+
+// declinedRetry is added to the request once after a refusal: refusals of
+// benign requests are often triggered by one part of them.
+const declinedRetry = "\n\nIf any part of this request cannot be helped with, answer the parts that can and say briefly what was left out."
+
+// completeDeclinable runs req and retries it once with declinedRetry when
+// the provider declines it. It returns ErrDeclined when the retry is
+// declined too; the cost of both calls is in the returned response.
+func (p *Pipeline) completeDeclinable(ctx context.Context, ts *TaskSpec, req brain.LLMRequest, complete func(brain.LLMRequest) (*brain.LLMResponse, error)) (*brain.LLMResponse, error) {
+	resp, err := complete(req)
+	if err != nil || !resp.Declined() {
+		return resp, err
+	}
+	p.logWarn("provider declined the request, retrying softened", "task_id", ts.ID, "model", resp.Model, "stop_reason", resp.StopReason)
+	cost := resp.CostUSD
+	messages := append([]brain.Message(nil), req.Messages...)
+	if n := len(messages); n > 0 && messages[n-1].Role == "user" {
+		messages[n-1].Content += declinedRetry
+	} else {
+		messages = append(messages, brain.Message{Role: "user", Content: declinedRetry[2:]})
+	}
+	req.Messages = messages
+	if resp, err = p.deps.LLM.Complete(ctx, req); err != nil {
+		return nil, err
+	}
+	resp.CostUSD += cost
+	if resp.Declined() {
+		return resp, ErrDeclined
+	}
+	return resp, nil
+}
+
+// declinedResult is the result of a run whose execution the provider
+// declined. It says so plainly instead of passing the refusal off as an
+// answer, and is neither reviewed nor remembered, so it does not count
+// against quality or feed patterns.
+func (p *Pipeline) declinedResult(ts *TaskSpec, start time.Time, cost float64, stageLogs []StageLog) *RunResult {
+	ts.Advance(TaskStatusFailed)
+	p.incrementMetric("pipeline.declined")
+	p.logInfo("provider declined the task", "task_id", ts.ID, "model", ts.Model)
+	msg := "The provider declined this request"
+	if ts.Model != "" {
+		msg += " (" + ts.Model + ")"
+	}
+	msg += ", so there is no answer. Rephrasing it or asking for part of it may help."
+	if p.settleBuffer(ts, ErrDeclined) {
+		msg += "\n\nThe findings so far were saved; ask me to continue where I left off."
+	}
+	return &RunResult{
+		TaskID:    ts.ID,
+		Success:   false,
+		Declined:  true,
+		Result:    msg,
+		CostUSD:   cost,
+		ElapsedMs: time.Since(start).Milliseconds(),
+		Model:     ts.Model,
+		StageLogs: stageLogs,
+	}
+}
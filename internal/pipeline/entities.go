@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+const (
+	entityMentionLimit = 2    // memories shown per known entity
+	entityResultChars  = 2000 // result characters sent for extraction
+)
+
+// entityMemory looks up the contact book entries the goal mentions,
+// records them on ts and returns them, with their latest linked memories,
+// for the execution context.
+func (p *Pipeline) entityMemory(ts *TaskSpec) []string {
+	if p.deps.Entities == nil {
+		return nil
+	}
+	found, err := p.deps.Entities.Find(ts.SourceUserID, ts.Goal)
+	if err != nil {
+		p.logWarn("entity lookup failed", "error", err.Error())
+		return nil
+	}
+	ts.Entities = ts.Entities[:0]
+	var out []string
+	for _, e := range found {
+		ts.Entities = append(ts.Entities, e.ID)
+		out = append(out, "[contact] "+e.Describe())
+		mentions, err := p.deps.Entities.Mentions(e.ID, viewer(ts), entityMentionLimit)
+		if err != nil {
+			continue
+		}
+		for _, m := range mentions {
+			out = append(out, fmt.Sprintf("[%s, %s] %s", e.Name, m.CreatedAt.Format("2006-01-02"), m.Summary))
+			ts.cite(m, e.Name)
+		}
+	}
+	return out
+}
+
+// entityEmailRe finds email addresses.
+var entityEmailRe = regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+
+// mayMentionEntities reports whether text could name a person or
+// organization: it has an email address, or a capitalized word that does
+// not start a sentence ("reply to Anna", "invoice from Acme").
+func mayMentionEntities(text string) bool {
+	if entityEmailRe.MatchString(text) {
+		return true
+	}
+	sentenceStart := true
+	for _, word := range strings.Fields(text) {
+		r := []rune(strings.TrimLeft(word, `"'(«“`))
+		if len(r) > 1 && unicode.IsUpper(r[0]) && unicode.IsLower(r[1]) && !sentenceStart {
+			return true
+		}
+		sentenceStart = strings.ContainsAny(word[len(word)-1:], ".!?:")
+	}
+	return false
+}
+
+// updateEntities keeps the contact book current after a run: known
+// entities the goal mentioned are linked to the run's memory entry, and
+// when the goal may name someone new, the LLM extracts the people and
+// organizations of the exchange into the book.
+func (p *Pipeline) updateEntities(ctx context.Context, ts *TaskSpec, result string, cost *float64) {
+	if p.deps.Entities == nil {
+		return
+	}
+	now := time.Now().UTC()
+	linked := make(map[int64]bool)
+	for _, id := range ts.Entities {
+		linked[id] = true
+		if err := p.deps.Entities.Link(id, ts.ID, now); err != nil {
+			p.logWarn("entity link failed", "error", err.Error())
+		}
+	}
+	if !mayMentionEntities(ts.Goal) {
+		return
+	}
+
+	messages := p.deps.Context.Assemble(brain.ContextLayers{
+		TaskDescription: fmt.Sprintf(
+			"List the people and organizations the user deals with in this exchange (not public figures, not the assistant).\n\nRequest: %s\nResponse: %s\n\nRespond with one line per entity:\nENTITY: <person|organization> | <full name> | <email or -> | <relationship to the user or -> | <preferred tone, language or form of address, or ->\nor NONE if there are none.",
+			ts.Goal, truncateRunes(result, entityResultChars)),
+	})
+	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+		Messages: messages,
+		Model:    p.deps.Router.Select("simple", ts.BudgetUSD),
+	})
+	if err != nil {
+		p.logWarn("entity extraction failed", "task_id", ts.ID, "error", err.Error())
+		return
+	}
+	*cost += resp.CostUSD
+	if p.deps.Budget != nil {
+		p.deps.Budget.Record(ts.ID, resp.CostUSD)
+	}
+
+	for _, e := range parseEntities(resp.Content) {
+		e.Owner = ts.SourceUserID
+		e.LastInteraction = now
+		saved, err := p.deps.Entities.Upsert(e)
+		if err != nil {
+			p.logWarn("entity store failed", "name", e.Name, "error", err.Error())
+			continue
+		}
+		if !linked[saved.ID] {
+			linked[saved.ID] = true
+			p.deps.Entities.Link(saved.ID, ts.ID, now)
+		}
+	}
+}
+
+// parseEntities reads "ENTITY: kind | name | email | relationship |
+// preferences" lines; "-" marks an empty field.
+func parseEntities(text string) []memory.Entity {
+	var out []memory.Entity
+	for _, line := range strings.Split(text, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "ENTITY:")
+		if !ok {
+			continue
+		}
+		fields := strings.Split(rest, "|")
+		for len(fields) < 5 {
+			fields = append(fields, "")
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+			if fields[i] == "-" {
+				fields[i] = ""
+			}
+		}
+		kind, err := memory.ParseEntityKind(fields[0])
+		if err != nil || fields[1] == "" {
+			continue
+		}
+		email := fields[2]
+		if !entityEmailRe.MatchString(email) {
+			email = ""
+		}
+		out = append(out, memory.Entity{
+			Kind:         kind,
+			Name:         fields[1],
+			Email:        email,
+			Relationship: fields[3],
+			Preferences:  fields[4],
+		})
+	}
+	return out
+}
+
+// truncateRunes cuts s to at most n characters.
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"errors"
+
+	"github.com/overhuman/overhuman/internal/events"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// TaskEvent is the data of the task lifecycle events.
+type TaskEvent struct {
+	TaskID string
+	Input  senses.UnifiedInput
+	Result *RunResult // nil for task.started
+	Err    error      // set for task.failed; ErrCancelled for a cancelled run
+}
+
+// PatternEvent is published when a pattern has repeated often enough to be
+// worth a code-skill.
+type PatternEvent struct {
+	TaskID      string
+	Fingerprint string
+	Goal        string
+	Channel     string
+}
+
+// SkillEvent is published when a skill is generated, promoted to active
+// or deprecated.
+type SkillEvent struct {
+	SkillID string
+	From    instruments.SkillStatus
+	To      instruments.SkillStatus
+	Trigger string // what decided it, e.g. "fitness evaluation"
+	Reason  string
+}
+
+// publishOutcome publishes how a run ended. Deferred, throttled and
+// parked runs have not ended yet.
+func (p *Pipeline) publishOutcome(input senses.UnifiedInput, rr *RunResult, err error) {
+	if errors.Is(err, ErrDeferred) || errors.Is(err, ErrThrottled) || errors.Is(err, ErrAwaitingApproval) {
+		return
+	}
+	ev := TaskEvent{Input: input, Result: rr, Err: err}
+	if rr != nil {
+		ev.TaskID = rr.TaskID
+		if rr.Cancelled {
+			err, ev.Err = ErrCancelled, ErrCancelled
+		}
+	}
+	if err != nil {
+		p.deps.Events.Publish(events.TaskFailed, ev)
+		return
+	}
+	p.deps.Events.Publish(events.TaskCompleted, ev)
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/instruments"
+)
+
+// handoffSummaryChars is the parent context length above which a subagent
+// gets a summary of it rather than the context itself.
+const handoffSummaryChars = 2000
+
+// handoffMemoryLimit bounds the memory snippets handed to a subagent.
+const handoffMemoryLimit = 5
+
+// handoff builds the package a subagent receives for sub: the parent task,
+// its context (summarized when long), long-term memories relevant to the
+// subtask, the constraints and what to return.
+func (p *Pipeline) handoff(ctx context.Context, ts *TaskSpec, sub *SubtaskSpec, cost *float64) *instruments.Handoff {
+	h := &instruments.Handoff{
+		ParentTaskID: ts.ID,
+		ParentGoal:   ts.Goal,
+		Summary:      p.handoffSummary(ctx, ts, cost),
+		Constraints:  append([]string(nil), ts.Constraints...),
+		ExpectedOutput: "only the result of this subtask (" + sub.Goal + "); it is merged into the answer to the larger task, " +
+			"so do not restate the larger task or add greetings.",
+	}
+	if ts.ExpectedOutput != "" {
+		h.ExpectedOutput += " The final answer should be: " + ts.ExpectedOutput
+	}
+	if p.deps.LongTerm != nil {
+		entries, err := p.deps.LongTerm.RecallAny(viewer(ts), sub.Goal, handoffMemoryLimit)
+		if err != nil {
+			p.logWarn("hand-off memory recall failed", "subtask", sub.ID, "error", err.Error())
+		}
+		for _, e := range entries {
+			h.Memory = append(h.Memory, fmt.Sprintf("[%s] %s", e.CreatedAt.Format("2006-01-02"), e.Summary))
+		}
+	}
+	return h
+}
+
+// handoffSummary returns the parent task's context, summarized by a cheap
+// model when it is long; when summarizing fails it is cut short instead.
+func (p *Pipeline) handoffSummary(ctx context.Context, ts *TaskSpec, cost *float64) string {
+	text := strings.TrimSpace(ts.Context)
+	if len(text) <= handoffSummaryChars {
+		return text
+	}
+	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+		Model: p.deps.Router.Select("simple", 0),
+		Messages: []brain.Message{{Role: "user", Content: "Summarize this task context in at most ten lines for a colleague " +
+			"who will work on part of the task. Keep names, numbers, dates and decisions.\n\nTask: " + ts.Goal + "\n\n" + text}},
+		MaxTokens: 500,
+	})
+	if err != nil {
+		p.logWarn("hand-off summary failed", "task_id", ts.ID, "error", err.Error())
+		return truncateRunes(text, handoffSummaryChars)
+	}
+	*cost += resp.CostUSD
+	if p.deps.Budget != nil {
+		p.deps.Budget.Record(ts.ID, resp.CostUSD)
+	}
+	return strings.TrimSpace(resp.Content)
+}
@@ -0,0 +1,173 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/approvals"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/security"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// ErrApprovalDenied ends a task whose action a human denied, or that
+// nobody approved in time.
+var ErrApprovalDenied = errors.New("approval denied")
+
+// ErrAwaitingApproval ends a task parked until a human decides on one of
+// its actions; RunResult.AwaitingApproval names the approval. Once it is
+// decided, run the input again after ResumeWithApproval.
+var ErrAwaitingApproval = errors.New("awaiting approval")
+
+// ExtraApprovals is the input extra listing, comma-separated, the decided
+// approvals a parked task is run again with.
+const ExtraApprovals = "approvals"
+
+// ResumeWithApproval marks input, parked on the approval id, to be run
+// again with that decision.
+func ResumeWithApproval(input *senses.UnifiedInput, id string) {
+	if input.SourceMeta.Extra == nil {
+		input.SourceMeta.Extra = make(map[string]string)
+	}
+	if prev := input.SourceMeta.Extra[ExtraApprovals]; prev != "" {
+		id = prev + "," + id
+	}
+	input.SourceMeta.Extra[ExtraApprovals] = id
+}
+
+// awaitingApproval is the ErrAwaitingApproval of a parked task.
+type awaitingApproval struct {
+	id, action string
+}
+
+func (e *awaitingApproval) Error() string {
+	return fmt.Sprintf("%v: %s (%s)", ErrAwaitingApproval, e.action, e.id)
+}
+
+func (e *awaitingApproval) Unwrap() error { return ErrAwaitingApproval }
+
+// policyAgentID is the agent the pipeline's own actions are checked as.
+const policyAgentID = "pipeline"
+
+// permissionDetails describes instruments.Perm* in permission prompts.
+var permissionDetails = map[string]string{
+	instruments.PermNetwork:    "make outbound network requests",
+	instruments.PermFilesystem: "read and write files",
+	instruments.PermProcess:    "run processes and shell commands",
+	instruments.PermEnv:        "read environment variables",
+	instruments.PermStorage:    "read and write the agent's persistent store",
+	instruments.PermSecrets:    "use stored credentials",
+
+	instruments.PermExternalWrite: "create or change pages, issues and other data in external services",
+}
+
+// skillPermitted checks every permission the skill declares against the
+// user's decisions, prompting for new ones when a prompter is wired (the
+// interactive CLI). A denied permission skips the skill.
+func (p *Pipeline) skillPermitted(ctx context.Context, skill *instruments.Skill) bool {
+	if skill.Meta.Doc == nil || (p.deps.Permissions == nil && p.deps.PermissionPrompter == nil) {
+		return true
+	}
+	for _, perm := range skill.Meta.Doc.Permissions {
+		req := security.PermissionRequest{
+			SkillID:    skill.Meta.ID,
+			SkillName:  skill.Meta.Name,
+			Permission: perm,
+			Detail:     permissionDetails[perm],
+		}
+		ok, err := p.deps.Permissions.Check(ctx, req, p.deps.PermissionPrompter)
+		if err != nil {
+			p.logWarn("permission check failed", "skill", skill.Meta.ID, "permission", perm, "error", err.Error())
+		}
+		if !ok {
+			p.logInfo("skill permission denied", "skill", skill.Meta.ID, "permission", perm)
+			p.auditLog(security.AuditExecDenied, security.SeverityInfo, "user", "permission", skill.Meta.ID, false,
+				map[string]string{"permission": perm})
+			return false
+		}
+	}
+	return true
+}
+
+// skillApproved checks the skill against the safety policy. Skills using
+// an ApprovalPermissions permission hit its require_approval rule: unless
+// the task is run again with a decided approval for the skill, one is
+// filed in deps.Approvals and the task is parked with ErrAwaitingApproval
+// instead of holding its worker. A denial ends the task with
+// ErrApprovalDenied.
+func (p *Pipeline) skillApproved(ctx context.Context, ts *TaskSpec, skill *instruments.Skill) error {
+	if p.deps.PolicyEnforcer == nil {
+		return nil
+	}
+	var risky []string
+	if skill.Meta.Doc != nil {
+		for _, perm := range skill.Meta.Doc.Permissions {
+			if slices.Contains(p.deps.ApprovalPermissions, perm) {
+				risky = append(risky, perm)
+			}
+		}
+	}
+	v := p.deps.PolicyEnforcer.CheckExecution(policyAgentID, 0, nil, len(risky) > 0, skill.Meta.ID)
+	if v == nil {
+		return nil
+	}
+	action := fmt.Sprintf("run skill %s (%s)", skill.Meta.Name, skill.Meta.ID)
+	if v.Rule != "require_approval" || p.deps.Approvals == nil {
+		p.auditLog(security.AuditExecDenied, security.SeverityWarn, "system", "policy", skill.Meta.ID, false,
+			map[string]string{"rule": v.Rule, "task_id": ts.ID})
+		return fmt.Errorf("%w: %s: %s", ErrApprovalDenied, action, v.Details)
+	}
+
+	for _, id := range ts.Approvals {
+		if a, ok := p.deps.Approvals.Get(id); ok && a.Action == action && a.Status != approvals.StatusPending {
+			return p.approvalDecided(ts, skill, action, a)
+		}
+	}
+
+	details := make([]string, len(risky))
+	for i, perm := range risky {
+		details[i] = "may " + permissionDetails[perm]
+	}
+	a := p.deps.Approvals.Request(approvals.Approval{
+		TaskID:    ts.ID,
+		Requester: ts.SourceUserID,
+		Rule:      v.Rule,
+		Action:    action,
+		Detail:    strings.Join(details, "; "),
+	})
+	if a.Status != approvals.StatusPending {
+		return p.approvalDecided(ts, skill, action, a)
+	}
+	p.logInfo("parked until approved", "task_id", ts.ID, "skill", skill.Meta.ID, "approval_id", a.ID)
+	p.emitStatus(ts.ID, 5, fmt.Sprintf("Waiting for approval to %s…", action))
+	return &awaitingApproval{id: a.ID, action: action}
+}
+
+// approvalDecided audits the decision a on the skill and returns nil when
+// it was approved, or the ErrApprovalDenied that ends the task.
+func (p *Pipeline) approvalDecided(ts *TaskSpec, skill *instruments.Skill, action string, a approvals.Approval) error {
+	approved := a.Status == approvals.StatusApproved
+	p.auditLog(security.AuditApproval, security.SeverityInfo, a.DecidedBy, string(a.Status), skill.Meta.ID, approved,
+		map[string]string{"approval_id": a.ID, "status": string(a.Status), "task_id": ts.ID})
+	if approved {
+		p.logInfo("approval granted", "task_id", ts.ID, "approval_id", a.ID, "by", a.DecidedBy)
+		return nil
+	}
+	p.logInfo("approval not granted", "task_id", ts.ID, "approval_id", a.ID, "status", string(a.Status))
+	var err error
+	switch {
+	case a.Status == approvals.StatusDenied && a.DecidedBy != "":
+		err = fmt.Errorf("%w: %s was denied by %s", ErrApprovalDenied, action, a.DecidedBy)
+	case a.Status == approvals.StatusDenied:
+		err = fmt.Errorf("%w: %s was denied", ErrApprovalDenied, action)
+	default:
+		err = fmt.Errorf("%w: %s was not approved (%s)", ErrApprovalDenied, action, a.Note)
+	}
+	if a.Note != "" && a.Status == approvals.StatusDenied {
+		err = fmt.Errorf("%w: %s", err, a.Note)
+	}
+	return err
+}
@@ -2,15 +2,23 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/overhuman/overhuman/internal/approvals"
 	"github.com/overhuman/overhuman/internal/brain"
 	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/events"
 	"github.com/overhuman/overhuman/internal/evolution"
 	"github.com/overhuman/overhuman/internal/goals"
 	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/locale"
 	"github.com/overhuman/overhuman/internal/memory"
 	"github.com/overhuman/overhuman/internal/observability"
 	"github.com/overhuman/overhuman/internal/reflection"
@@ -36,9 +44,25 @@ type RunResult struct {
 	QualityScore        float64    `json:"quality_score"`
 	CostUSD             float64    `json:"cost_usd"`
 	ElapsedMs           int64      `json:"elapsed_ms"`
+	Model               string     `json:"model,omitempty"` // model the execution stage ran on
 	Fingerprint         string     `json:"fingerprint,omitempty"`
 	AutomationTriggered bool       `json:"automation_triggered"`
+	Declined            bool       `json:"declined,omitempty"` // the provider refused to answer; Result says so
+	Cancelled           bool       `json:"cancelled,omitempty"` // stopped with Pipeline.Cancel
+	AwaitingApproval    string     `json:"awaiting_approval,omitempty"` // parked until this approval is decided
 	StageLogs           []StageLog `json:"stage_logs,omitempty"`
+
+	// Sources cites the long-term memories the answer was based on.
+	Sources []MemorySource `json:"sources,omitempty"`
+
+	// Delegations records the subagents whose results were merged into
+	// the answer.
+	Delegations []instruments.Provenance `json:"delegations,omitempty"`
+
+	// ArtifactsDir holds the files the run left in its scratch directory,
+	// when they were kept; Artifacts lists them.
+	ArtifactsDir string   `json:"artifacts_dir,omitempty"`
+	Artifacts    []string `json:"artifacts,omitempty"`
 }
 
 // Dependencies holds all subsystem references the pipeline needs.
@@ -57,6 +81,11 @@ type Dependencies struct {
 	Goals     *goals.Engine
 	Budget    *budget.Tracker
 	Generator *instruments.Generator
+	Savings   *memory.SavingsTracker // cold-path baselines and skill savings
+
+	// Transcripts records each completed exchange for export (optional —
+	// nil-safe). Heartbeats are not recorded.
+	Transcripts *memory.TranscriptLog
 
 	// Phase 3 (optional — nil-safe).
 	Evolution      *evolution.Engine
@@ -67,7 +96,7 @@ type Dependencies struct {
 	MicroReflector *reflection.MicroReflector
 	SKB            *memory.SharedKnowledgeBase
 	Experiments    *evolution.ExperimentManager
-	Sandbox        *instruments.DockerSandbox
+	Sandbox        instruments.Sandbox
 	Logger         *observability.Logger
 	Metrics        *observability.MetricsCollector
 
@@ -79,6 +108,83 @@ type Dependencies struct {
 	AuditLog       *security.AuditLogger
 	PolicyEnforcer *security.PolicyEnforcer
 	SecretRegistry *security.SecretRegistry
+
+	// Permissions holds the user's per-skill permission decisions;
+	// PermissionPrompter asks about undecided ones (interactive CLI only).
+	Permissions        *security.PermissionStore
+	PermissionPrompter security.PermissionPrompter
+
+	// ApprovalPermissions lists the skill permissions (e.g. "process")
+	// whose every use needs a human's approval under PolicyEnforcer's
+	// require_approval rule. Approvals holds those requests while the
+	// task waits; without it such skills do not run.
+	ApprovalPermissions []string
+	Approvals           *approvals.Store
+
+	// Speech backends for voice input and spoken replies (optional —
+	// nil-safe).
+	STT brain.SpeechToText
+	TTS brain.TextToSpeech
+
+	// Locale resolves per-user timezone/locale (optional — nil-safe).
+	Locale *locale.Resolver
+
+	// Mode blocks skills and subagents in read-only/maintenance mode
+	// (optional — nil-safe).
+	Mode *senses.ModeSwitch
+
+	// Routing overrides learned per fingerprint (optional — nil-safe).
+	// EscalationThreshold is the review score below which an LLM result
+	// is retried one tier up; 0 disables automatic escalation.
+	RoutingOverrides    *brain.RoutingOverrides
+	EscalationThreshold float64
+
+	// PriorityPolicy bounds model tier and budget use per input priority
+	// (optional — nil means no bounds and hard budget caps).
+	PriorityPolicy budget.Policy
+
+	// SpeculationMultiplier enables speculative execution: when the
+	// clarifier finds two readings of a task, both run in parallel on the
+	// cheap tier if that costs at most this multiple of a normal execution.
+	// 0 disables speculation.
+	SpeculationMultiplier float64
+
+	// WarmStart reuses the clarification and plan of well-established
+	// patterns, skipping stages 2-3 (optional — nil-safe).
+	WarmStart *WarmStart
+
+	// MemoryVisibility sets, per source channel, who may later retrieve
+	// what a run stores in long-term memory. Channels not listed store
+	// private entries when the sender is known and channel-wide ones
+	// otherwise.
+	MemoryVisibility map[string]memory.Visibility
+
+	// Entities is the contact book: people and organizations mentioned in
+	// requests are extracted into it, and known ones are added to the
+	// execution context (optional — nil-safe).
+	Entities *memory.EntityStore
+
+	// Semantic recalls long-term memories related in meaning to each task
+	// into its execution context, beyond word matches (optional —
+	// nil-safe).
+	Semantic *memory.SemanticMemory
+
+	// Sessions configures when conversations end: on "/new" or after an
+	// idle period, optionally summarized into long-term memory.
+	Sessions SessionPolicy
+
+	// Scratch gives each run a private working directory (off when Dir is
+	// empty).
+	Scratch ScratchPolicy
+
+	// Runs checkpoints the findings of multi-step runs so a failure or a
+	// crash keeps them, and lets "continue where you left off" resume the
+	// run (optional — nil-safe).
+	Runs *memory.RunBuffer
+
+	// Events receives the task lifecycle, pattern and skill events
+	// (optional — nil-safe).
+	Events *events.Bus
 }
 
 // StageEvent is emitted in real-time as each pipeline stage starts/completes.
@@ -91,10 +197,26 @@ type StageEvent struct {
 	DurMs   int64
 }
 
+// PartialEvent carries text the execution stage is still generating. Done
+// marks the end of one streamed completion; a later event for the same
+// task starts over. CorrelationID is that of the input the task came from,
+// to stream the text back to its requester.
+type PartialEvent struct {
+	TaskID        string
+	CorrelationID string
+	Chunk         string
+	Done          bool
+}
+
 // Pipeline orchestrates the 10-stage execution flow.
 type Pipeline struct {
-	deps          Dependencies
-	stageCallback func(StageEvent)
+	deps            Dependencies
+	stageCallback   func(StageEvent)
+	partialCallback func(PartialEvent)
+	statusCallback  func(StatusEvent)
+	topics          sessionTopics  // active memory topic per conversation
+	sessions        sessionTracker // current session per conversation
+	active          taskRegistry   // runs in progress, for Cancel
 }
 
 // New creates a Pipeline with all dependencies.
@@ -110,22 +232,46 @@ func (p *Pipeline) OnStageProgress(fn func(StageEvent)) {
 	p.stageCallback = fn
 }
 
-// emitStage fires a stage event if a callback is registered.
+// OnPartial registers a callback for the execution stage's output as the
+// LLM streams it. Parallel subtasks, speculative branches and escalation
+// retries are not streamed.
+func (p *Pipeline) OnPartial(fn func(PartialEvent)) {
+	p.partialCallback = fn
+}
+
+// emitStage fires a stage event if a callback is registered, and the
+// status line the event stands for. Finished stages are timed in the
+// pipeline_stage_duration_seconds histogram.
 func (p *Pipeline) emitStage(taskID string, stage int, name, status, summary string, durMs int64) {
+	evt := StageEvent{
+		TaskID:  taskID,
+		Stage:   stage,
+		Name:    name,
+		Status:  status,
+		Summary: summary,
+		DurMs:   durMs,
+	}
 	if p.stageCallback != nil {
-		p.stageCallback(StageEvent{
-			TaskID:  taskID,
-			Stage:   stage,
-			Name:    name,
-			Status:  status,
-			Summary: summary,
-			DurMs:   durMs,
-		})
+		p.stageCallback(evt)
+	}
+	p.emitStatus(taskID, stage, stageStatus(evt))
+	if status != "started" && p.deps.Metrics != nil {
+		p.deps.Metrics.Observe("pipeline_stage_duration_seconds", observability.Labels{"stage": name, "status": status}, float64(durMs)/1000)
 	}
 }
 
-// Run executes the full 10-stage pipeline for a given input signal.
+// Run executes the full 10-stage pipeline for a given input signal and
+// publishes how it ended to Events.
 func (p *Pipeline) Run(ctx context.Context, input senses.UnifiedInput) (*RunResult, error) {
+	rr, err := p.run(ctx, input)
+	if rr != nil && rr.Cancelled {
+		err = nil
+	}
+	p.publishOutcome(input, rr, err)
+	return rr, err
+}
+
+func (p *Pipeline) run(ctx context.Context, input senses.UnifiedInput) (out *RunResult, _ error) {
 	start := time.Now()
 	var totalCost float64
 	var stageLogs []StageLog
@@ -155,39 +301,86 @@ func (p *Pipeline) Run(ctx context.Context, input senses.UnifiedInput) (*RunResu
 	// --- Stage 1: Intake ---
 	stageStart := time.Now()
 	taskSpec := p.intake(input)
+	if rr := p.applySession(ctx, taskSpec); rr != nil {
+		rr.ElapsedMs = time.Since(start).Milliseconds()
+		return rr, nil
+	}
+	p.applyResume(taskSpec)
+	p.applyRouting(taskSpec, input)
+	if rr := p.applyTopic(taskSpec); rr != nil {
+		rr.ElapsedMs = time.Since(start).Milliseconds()
+		return rr, nil
+	}
+	if p.priorityRule(taskSpec).ShouldDefer(p.deps.Budget) {
+		p.logInfo("task deferred by priority policy", "task_id", taskSpec.ID, "priority", taskSpec.Priority)
+		return &RunResult{TaskID: taskSpec.ID, Result: ErrDeferred.Error()}, ErrDeferred
+	}
+	if p.quotaThrottled(taskSpec) {
+		p.logInfo("task throttled: provider rate limit nearly exhausted", "task_id", taskSpec.ID, "priority", taskSpec.Priority)
+		p.incrementMetric("pipeline.throttled")
+		return &RunResult{TaskID: taskSpec.ID, Result: ErrThrottled.Error()}, ErrThrottled
+	}
+	if rr := p.budgetExhausted(taskSpec, start, 0, nil); rr != nil {
+		return rr, ErrBudgetExhausted
+	}
+	ctx, scratch := p.openScratch(ctx, taskSpec)
+	if scratch != nil {
+		defer func() { p.closeScratch(scratch, taskSpec, out) }()
+	}
+	ctx, task := p.active.begin(ctx, taskSpec.ID, input)
+	defer func() {
+		if p.active.end(task) {
+			out = p.cancelledResult(taskSpec, start, out)
+		}
+	}()
 	p.emitStage(taskSpec.ID, 1, "intake", "started", "", 0)
 	p.logPipeline(1, "intake", "task_id", taskSpec.ID)
 	p.incrementMetric("pipeline.runs")
+	p.deps.Events.Publish(events.TaskStarted, TaskEvent{TaskID: taskSpec.ID, Input: input})
 	stageLogs = append(stageLogs, StageLog{Number: 1, Name: "intake", Summary: "task_id=" + taskSpec.ID, DurMs: time.Since(stageStart).Milliseconds()})
 	p.emitStage(taskSpec.ID, 1, "intake", "completed", "task_id="+taskSpec.ID, time.Since(stageStart).Milliseconds())
 
-	// --- Stage 2: Clarification ---
-	stageStart = time.Now()
-	p.emitStage(taskSpec.ID, 2, "clarify", "started", "", 0)
-	if err := p.clarify(ctx, taskSpec, &totalCost); err != nil {
-		p.incrementMetric("pipeline.errors")
-		p.emitStage(taskSpec.ID, 2, "clarify", "error", "error", time.Since(stageStart).Milliseconds())
-		stageLogs = append(stageLogs, StageLog{Number: 2, Name: "clarify", Summary: "error", DurMs: time.Since(stageStart).Milliseconds()})
-		return p.failResult(taskSpec, start, totalCost, err, stageLogs), err
-	}
-	p.logPipeline(2, "clarified", "version", taskSpec.Version)
-	p.microCheck(ctx, taskSpec, reflection.StepClarify, taskSpec.Context)
-	stageLogs = append(stageLogs, StageLog{Number: 2, Name: "clarify", DurMs: time.Since(stageStart).Milliseconds()})
-	p.emitStage(taskSpec.ID, 2, "clarify", "completed", "", time.Since(stageStart).Milliseconds())
-
-	// --- Stage 3: Planning ---
-	stageStart = time.Now()
-	p.emitStage(taskSpec.ID, 3, "plan", "started", "", 0)
-	if err := p.plan(ctx, taskSpec, &totalCost); err != nil {
-		p.incrementMetric("pipeline.errors")
-		p.emitStage(taskSpec.ID, 3, "plan", "error", "error", time.Since(stageStart).Milliseconds())
-		stageLogs = append(stageLogs, StageLog{Number: 3, Name: "plan", Summary: "error", DurMs: time.Since(stageStart).Milliseconds()})
-		return p.failResult(taskSpec, start, totalCost, err, stageLogs), err
+	// --- Stages 2-3, unless a cached plan for this pattern is reused ---
+	warm := p.applyWarmStart(taskSpec)
+	var fresh *warmPlan
+	if warm {
+		for _, st := range []StageLog{{Number: 2, Name: "clarify"}, {Number: 3, Name: "plan"}} {
+			st.Summary = "warm_start"
+			p.emitStage(taskSpec.ID, st.Number, st.Name, "started", "", 0)
+			stageLogs = append(stageLogs, st)
+			p.emitStage(taskSpec.ID, st.Number, st.Name, "completed", st.Summary, 0)
+		}
+		p.logPipeline(3, "warm start: reused clarification and plan", "subtasks", len(taskSpec.Subtasks))
+	} else {
+		// --- Stage 2: Clarification ---
+		stageStart = time.Now()
+		p.emitStage(taskSpec.ID, 2, "clarify", "started", "", 0)
+		if err := p.clarify(ctx, taskSpec, &totalCost); err != nil {
+			p.incrementMetric("pipeline.errors")
+			p.emitStage(taskSpec.ID, 2, "clarify", "error", "error", time.Since(stageStart).Milliseconds())
+			stageLogs = append(stageLogs, StageLog{Number: 2, Name: "clarify", Summary: "error", DurMs: time.Since(stageStart).Milliseconds()})
+			return p.failResult(taskSpec, start, totalCost, err, stageLogs), err
+		}
+		p.logPipeline(2, "clarified", "version", taskSpec.Version)
+		p.microCheck(ctx, taskSpec, reflection.StepClarify, taskSpec.Context)
+		stageLogs = append(stageLogs, StageLog{Number: 2, Name: "clarify", DurMs: time.Since(stageStart).Milliseconds()})
+		p.emitStage(taskSpec.ID, 2, "clarify", "completed", "", time.Since(stageStart).Milliseconds())
+
+		// --- Stage 3: Planning ---
+		stageStart = time.Now()
+		p.emitStage(taskSpec.ID, 3, "plan", "started", "", 0)
+		if err := p.plan(ctx, taskSpec, &totalCost); err != nil {
+			p.incrementMetric("pipeline.errors")
+			p.emitStage(taskSpec.ID, 3, "plan", "error", "error", time.Since(stageStart).Milliseconds())
+			stageLogs = append(stageLogs, StageLog{Number: 3, Name: "plan", Summary: "error", DurMs: time.Since(stageStart).Milliseconds()})
+			return p.failResult(taskSpec, start, totalCost, err, stageLogs), err
+		}
+		planSummary := fmt.Sprintf("subtasks=%d", len(taskSpec.Subtasks))
+		p.logPipeline(3, "planned", "subtasks", len(taskSpec.Subtasks))
+		stageLogs = append(stageLogs, StageLog{Number: 3, Name: "plan", Summary: planSummary, DurMs: time.Since(stageStart).Milliseconds()})
+		p.emitStage(taskSpec.ID, 3, "plan", "completed", planSummary, time.Since(stageStart).Milliseconds())
+		fresh = p.deps.WarmStart.capture(taskSpec)
 	}
-	planSummary := fmt.Sprintf("subtasks=%d", len(taskSpec.Subtasks))
-	p.logPipeline(3, "planned", "subtasks", len(taskSpec.Subtasks))
-	stageLogs = append(stageLogs, StageLog{Number: 3, Name: "plan", Summary: planSummary, DurMs: time.Since(stageStart).Milliseconds()})
-	p.emitStage(taskSpec.ID, 3, "plan", "completed", planSummary, time.Since(stageStart).Milliseconds())
 
 	// --- Stage 4: Agent Selection ---
 	stageStart = time.Now()
@@ -200,21 +393,52 @@ func (p *Pipeline) Run(ctx context.Context, input senses.UnifiedInput) (*RunResu
 	// --- Stage 5: Execution ---
 	stageStart = time.Now()
 	p.emitStage(taskSpec.ID, 5, "execute", "started", "", 0)
-	result, err := p.execute(ctx, taskSpec, &totalCost)
+	costBefore := totalCost
+	var result string
+	var err error
+	executeSummary := ""
+	candidates := p.speculate(ctx, taskSpec, &totalCost)
+	if candidates != nil {
+		result = bothInterpretations(candidates)
+		executeSummary = "speculative=2"
+	} else {
+		result, err = p.execute(ctx, taskSpec, &totalCost)
+	}
+	if errors.Is(err, ErrDeclined) {
+		p.emitStage(taskSpec.ID, 5, "execute", "completed", "declined", time.Since(stageStart).Milliseconds())
+		stageLogs = append(stageLogs, StageLog{Number: 5, Name: "execute", Summary: "declined", DurMs: time.Since(stageStart).Milliseconds()})
+		return p.declinedResult(taskSpec, start, totalCost, stageLogs), nil
+	}
 	if err != nil {
 		p.incrementMetric("pipeline.errors")
 		p.emitStage(taskSpec.ID, 5, "execute", "error", "error", time.Since(stageStart).Milliseconds())
 		stageLogs = append(stageLogs, StageLog{Number: 5, Name: "execute", Summary: "error", DurMs: time.Since(stageStart).Milliseconds()})
+		if errors.Is(err, ErrBudgetExhausted) {
+			if rr := p.budgetExhausted(taskSpec, start, totalCost, stageLogs); rr != nil {
+				return rr, err
+			}
+		}
+		var parked *awaitingApproval
+		if errors.As(err, &parked) {
+			return p.parkedResult(taskSpec, start, totalCost, parked, stageLogs), err
+		}
 		return p.failResult(taskSpec, start, totalCost, err, stageLogs), err
 	}
 	p.logPipeline(5, "executed")
+	if candidates == nil {
+		// Speculative runs cost more than the task normally would.
+		p.recordBaseline(taskSpec, totalCost-costBefore, time.Since(stageStart).Milliseconds())
+	}
 	p.microCheck(ctx, taskSpec, reflection.StepExecute, result)
-	stageLogs = append(stageLogs, StageLog{Number: 5, Name: "execute", DurMs: time.Since(stageStart).Milliseconds()})
-	p.emitStage(taskSpec.ID, 5, "execute", "completed", "", time.Since(stageStart).Milliseconds())
+	stageLogs = append(stageLogs, StageLog{Number: 5, Name: "execute", Summary: executeSummary, DurMs: time.Since(stageStart).Milliseconds()})
+	p.emitStage(taskSpec.ID, 5, "execute", "completed", executeSummary, time.Since(stageStart).Milliseconds())
 
 	// --- Stage 6: Review ---
 	stageStart = time.Now()
 	p.emitStage(taskSpec.ID, 6, "review", "started", "", 0)
+	if candidates != nil {
+		result = p.pickInterpretation(ctx, taskSpec, candidates, &totalCost)
+	}
 	quality, reviewNotes, err := p.review(ctx, taskSpec, result, &totalCost)
 	if err != nil {
 		p.incrementMetric("pipeline.errors")
@@ -222,9 +446,21 @@ func (p *Pipeline) Run(ctx context.Context, input senses.UnifiedInput) (*RunResu
 		stageLogs = append(stageLogs, StageLog{Number: 6, Name: "review", Summary: "error", DurMs: time.Since(stageStart).Milliseconds()})
 		return p.failResult(taskSpec, start, totalCost, err, stageLogs), err
 	}
+	escalated := false
+	if candidates == nil {
+		result, quality, reviewNotes, escalated = p.escalate(ctx, taskSpec, result, quality, reviewNotes, &totalCost)
+	}
 	taskSpec.QualityScore = quality
 	taskSpec.ReviewNotes = reviewNotes
 	reviewSummary := fmt.Sprintf("quality=%.2f", quality)
+	if escalated {
+		reviewSummary += " escalated=" + taskSpec.Model
+	}
+	if taskSpec.Interpretation == interpretationBoth {
+		reviewSummary += " interpretation=both"
+	} else if taskSpec.Interpretation != "" {
+		reviewSummary += " interpretation=picked"
+	}
 	p.logPipeline(6, "reviewed", "quality", quality)
 	p.microCheck(ctx, taskSpec, reflection.StepReview, reviewNotes)
 	stageLogs = append(stageLogs, StageLog{Number: 6, Name: "review", Summary: reviewSummary, DurMs: time.Since(stageStart).Milliseconds()})
@@ -234,6 +470,8 @@ func (p *Pipeline) Run(ctx context.Context, input senses.UnifiedInput) (*RunResu
 	stageStart = time.Now()
 	p.emitStage(taskSpec.ID, 7, "memory_update", "started", "", 0)
 	p.updateMemory(taskSpec, result)
+	p.updateEntities(ctx, taskSpec, result, &totalCost)
+	p.settleBuffer(taskSpec, nil)
 	p.logPipeline(7, "memory updated")
 	stageLogs = append(stageLogs, StageLog{Number: 7, Name: "memory_update", DurMs: time.Since(stageStart).Milliseconds()})
 	p.emitStage(taskSpec.ID, 7, "memory_update", "completed", "", time.Since(stageStart).Milliseconds())
@@ -242,11 +480,20 @@ func (p *Pipeline) Run(ctx context.Context, input senses.UnifiedInput) (*RunResu
 	stageStart = time.Now()
 	p.emitStage(taskSpec.ID, 8, "pattern_tracking", "started", "", 0)
 	automatable := p.trackPattern(taskSpec)
+	if automatable {
+		p.deps.Events.Publish(events.PatternTriggered, PatternEvent{
+			TaskID:      taskSpec.ID,
+			Fingerprint: taskSpec.Fingerprint,
+			Goal:        taskSpec.Goal,
+			Channel:     taskSpec.SourceChannel,
+		})
+	}
 	patternSummary := fmt.Sprintf("automatable=%v", automatable)
 	p.logPipeline(8, "pattern tracked", "automatable", automatable)
 	p.recordMetric(observability.MetricPatterns, boolToFloat(automatable), observability.Labels{"fingerprint": taskSpec.Fingerprint})
 	stageLogs = append(stageLogs, StageLog{Number: 8, Name: "pattern_tracking", Summary: patternSummary, DurMs: time.Since(stageStart).Milliseconds()})
 	p.emitStage(taskSpec.ID, 8, "pattern_tracking", "completed", patternSummary, time.Since(stageStart).Milliseconds())
+	p.observeWarmStart(taskSpec, fresh, warm)
 
 	// --- Stage 9: Reflection (meso-loop) ---
 	stageStart = time.Now()
@@ -285,17 +532,53 @@ func (p *Pipeline) Run(ctx context.Context, input senses.UnifiedInput) (*RunResu
 		result = p.deps.SecretRegistry.Sanitize(result)
 	}
 
-	return &RunResult{
+	rr := &RunResult{
 		TaskID:              taskSpec.ID,
 		Success:             true,
 		Result:              result,
 		QualityScore:        quality,
 		CostUSD:             totalCost,
 		ElapsedMs:           time.Since(start).Milliseconds(),
+		Model:               taskSpec.Model,
 		Fingerprint:         taskSpec.Fingerprint,
 		AutomationTriggered: automatable,
 		StageLogs:           stageLogs,
-	}, nil
+		Sources:             taskSpec.Sources,
+	}
+	for _, sub := range taskSpec.Subtasks {
+		if sub.Provenance != nil {
+			rr.Delegations = append(rr.Delegations, *sub.Provenance)
+		}
+	}
+	p.recordTranscript(input, rr)
+	return rr, nil
+}
+
+// recordTranscript appends the exchange to the transcript log. The input
+// is recorded as the user sent it, before pre-prompts were applied.
+func (p *Pipeline) recordTranscript(input senses.UnifiedInput, rr *RunResult) {
+	if p.deps.Transcripts == nil || input.SourceType == senses.SourceTimer {
+		return
+	}
+	text := input.Payload
+	if raw, ok := input.SourceMeta.Extra["raw_payload"]; ok {
+		text = raw
+	}
+	err := p.deps.Transcripts.Append(memory.TranscriptEntry{
+		Time:      time.Now().UTC(),
+		TaskID:    rr.TaskID,
+		SessionID: input.SessionID,
+		Sender:    input.SourceMeta.Sender,
+		Channel:   string(input.SourceType),
+		Input:     text,
+		Result:    rr.Result,
+		CostUSD:   rr.CostUSD,
+		Quality:   rr.QualityScore,
+		ElapsedMs: rr.ElapsedMs,
+	})
+	if err != nil {
+		p.logWarn("transcript append failed", "task_id", rr.TaskID, "error", err.Error())
+	}
 }
 
 // --- Stage implementations ---
@@ -309,39 +592,65 @@ func (p *Pipeline) intake(input senses.UnifiedInput) *TaskSpec {
 	ts.SourceChannel = string(input.SourceType)
 	ts.SourceUserID = input.SourceMeta.Sender
 	ts.SessionID = input.SessionID
+	ts.correlationID = input.CorrelationID
+	if ts.SessionID == "" {
+		ts.SessionID = senderConversation(input)
+	}
+	ts.Stateless = input.SourceMeta.Extra["stateless"] == "true"
+	ts.KeepArtifacts = input.SourceMeta.Extra["keep_artifacts"] == "true"
+	if ids := input.SourceMeta.Extra[ExtraApprovals]; ids != "" {
+		ts.Approvals = strings.Split(ids, ",")
+	}
+	ts.Priority = policyPriority(input)
 	return ts
 }
 
 // Stage 2: Clarification — LLM refines the task spec.
 func (p *Pipeline) clarify(ctx context.Context, ts *TaskSpec, cost *float64) error {
-	soulContent, _ := p.deps.Soul.Read()
+	soulContent := p.systemPrompt(ts)
 
+	format := clarifyFormat
+	if p.deps.SpeculationMultiplier > 0 {
+		format += ", " + ambiguityFormat
+	}
 	messages := p.deps.Context.Assemble(brain.ContextLayers{
 		SystemPrompt: soulContent,
 		TaskDescription: fmt.Sprintf(
-			"Clarify this task. Extract: goal, constraints, expected output, verification criteria.\n\nTask: %s\n\nRespond in this exact format:\nGOAL: <clarified goal>\nCONSTRAINTS: <comma-separated>\nEXPECTED_OUTPUT: <what to produce>\nVERIFICATION: <how to verify>",
-			ts.Goal),
+			"Clarify this task. Extract: goal, constraints, expected output, verification criteria.\n\nTask: %s\n\nRespond with only a JSON object:\n{%s}",
+			ts.Goal, format),
 	})
 
 	model := p.deps.Router.Select("simple", ts.BudgetUSD)
-	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+	var c clarification
+	// The same task clarifies the same way: a repeat may be answered from
+	// the response cache.
+	resp, err := brain.StructuredComplete(brain.WithCache(ctx), p.deps.LLM, brain.LLMRequest{
 		Messages: messages,
 		Model:    model,
-	})
-	if err != nil {
+	}, clarifySchema, &c)
+	if resp != nil {
+		*cost += resp.CostUSD
+	}
+	if err != nil && !errors.Is(err, brain.ErrUnstructured) {
 		return fmt.Errorf("clarify: %w", err)
 	}
-	*cost += resp.CostUSD
+	if err != nil {
+		// Unreadable even after a retry: the reply is the best context there is.
+		p.logWarn("clarification unparseable", "task_id", ts.ID)
+		c = clarification{raw: resp.Content}
+	}
 
-	// Parse response (simplified — in production would use structured output).
-	ts.Context = resp.Content
+	ts.Context = c.String()
+	if p.deps.SpeculationMultiplier > 0 {
+		ts.Interpretations = c.interpretations()
+	}
 	ts.Advance(TaskStatusClarified)
 	return nil
 }
 
 // Stage 3: Planning — decompose into subtasks.
 func (p *Pipeline) plan(ctx context.Context, ts *TaskSpec, cost *float64) error {
-	soulContent, _ := p.deps.Soul.Read()
+	soulContent := p.systemPrompt(ts)
 
 	messages := p.deps.Context.Assemble(brain.ContextLayers{
 		SystemPrompt: soulContent,
@@ -373,8 +682,12 @@ func (p *Pipeline) plan(ctx context.Context, ts *TaskSpec, cost *float64) error
 }
 
 // Stage 4: Agent Selection — select agent/skill for each subtask.
-// Priority: 1) existing code/hybrid skill, 2) subagent by role match, 3) self (LLM).
+// Priority: 1) existing skill by fingerprint or trigger keyword, 2) subagent
+// by role match, 3) self (LLM).
 func (p *Pipeline) selectAgent(ts *TaskSpec) {
+	if ts.Fingerprint == "" && p.deps.Patterns != nil {
+		ts.Fingerprint = p.deps.Patterns.ComputeFingerprint(ts.Goal, ts.SourceChannel)
+	}
 	for i := range ts.Subtasks {
 		// 1. Try to find an existing skill for this pattern.
 		if p.deps.Skills != nil && ts.Fingerprint != "" {
@@ -383,6 +696,13 @@ func (p *Pipeline) selectAgent(ts *TaskSpec) {
 				continue
 			}
 		}
+		// Hand-written template skills may name trigger keywords instead.
+		if p.deps.Skills != nil {
+			if skill := p.deps.Skills.FindByTrigger(ts.Subtasks[i].Goal); skill != nil {
+				ts.Subtasks[i].AssignedTo = "skill:" + skill.Meta.ID
+				continue
+			}
+		}
 
 		// 2. Try to delegate to a subagent (if SubagentManager is available
 		//    and the subtask has an agent: prefix hint from planning).
@@ -401,9 +721,13 @@ func (p *Pipeline) execute(ctx context.Context, ts *TaskSpec, cost *float64) (st
 	ts.Advance(TaskStatusExecuting)
 
 	// Check budget before execution.
-	if p.deps.Budget != nil && !p.deps.Budget.CanSpend(0.01) {
-		return "", fmt.Errorf("execute: daily/monthly budget exhausted")
+	if p.deps.Budget != nil && !p.deps.Budget.CanSpendWithin(minTaskCostUSD, p.priorityRule(ts).Overdraft) {
+		return "", ErrBudgetExhausted
+	}
+	if ts.BudgetUSD > 0 && *cost+minTaskCostUSD > ts.BudgetUSD {
+		return "", ErrBudgetExhausted
 	}
+	p.beginBuffer(ts)
 
 	// Use DAG executor for multi-subtask parallel execution.
 	if len(ts.Subtasks) > 1 {
@@ -415,12 +739,28 @@ func (p *Pipeline) execute(ctx context.Context, ts *TaskSpec, cost *float64) (st
 }
 
 // executeDAG runs multiple subtasks in parallel using the DAG executor.
+// Each subtask runs on its own copy of ts and cost, starting from what the
+// task has spent so far; their spend and model are merged as they finish.
 func (p *Pipeline) executeDAG(ctx context.Context, ts *TaskSpec, cost *float64) (string, error) {
+	var mu sync.Mutex
+	spent, model := *cost, ts.Model
 	dag := NewDAGExecutor(func(ctx context.Context, sub *SubtaskSpec) (string, error) {
-		return p.executeSubtask(ctx, ts, sub, cost)
+		mu.Lock()
+		branch, subCost := *ts, spent
+		mu.Unlock()
+		start := subCost
+		result, err := p.executeStep(withoutPartials(ctx), &branch, sub, &subCost)
+		mu.Lock()
+		spent += subCost - start
+		if branch.Model != ts.Model {
+			model = branch.Model
+		}
+		mu.Unlock()
+		return result, err
 	})
 
 	results, err := dag.Execute(ctx, ts.Subtasks)
+	*cost, ts.Model = spent, model
 	if err != nil {
 		return "", fmt.Errorf("execute DAG: %w", err)
 	}
@@ -444,7 +784,7 @@ func (p *Pipeline) executeSingle(ctx context.Context, ts *TaskSpec, cost *float6
 	if len(ts.Subtasks) == 0 {
 		return p.executeLLM(ctx, ts, cost)
 	}
-	result, err := p.executeSubtask(ctx, ts, &ts.Subtasks[0], cost)
+	result, err := p.executeStep(ctx, ts, &ts.Subtasks[0], cost)
 	if err != nil {
 		return "", err
 	}
@@ -453,14 +793,40 @@ func (p *Pipeline) executeSingle(ctx context.Context, ts *TaskSpec, cost *float6
 	return result, nil
 }
 
+// executeStep runs a subtask and checkpoints its result. A step the
+// resumed run already finished is not run again.
+func (p *Pipeline) executeStep(ctx context.Context, ts *TaskSpec, sub *SubtaskSpec, cost *float64) (string, error) {
+	if finding, ok := ts.resumedFinding(sub.Goal); ok {
+		return finding, nil
+	}
+	result, err := p.executeSubtask(withSubtask(ctx, ts, sub), ts, sub, cost)
+	if err == nil {
+		p.checkpoint(ts, sub.Goal, result)
+	}
+	return result, err
+}
+
 // executeSubtask runs a single subtask, trying skill → subagent → LLM fallback.
 func (p *Pipeline) executeSubtask(ctx context.Context, ts *TaskSpec, sub *SubtaskSpec, cost *float64) (string, error) {
+	// Read-only mode: skills and subagents may have side effects, so only
+	// the LLM answers.
+	if p.deps.Mode.ReadOnly() {
+		if sub.AssignedTo != "" && sub.AssignedTo != "self" {
+			p.logInfo("read-only mode: skipping assignee", "subtask", sub.ID, "assignee", sub.AssignedTo)
+		}
+		return p.executeLLM(ctx, ts, cost)
+	}
+
 	// 1. Try skill execution first if assigned.
 	if p.deps.Skills != nil {
 		assignee := sub.AssignedTo
 		if len(assignee) > 6 && assignee[:6] == "skill:" {
 			skillID := assignee[6:]
-			if skill := p.deps.Skills.Get(skillID); skill != nil {
+			if skill := p.deps.Skills.Get(skillID); skill != nil && p.skillPermitted(ctx, skill) {
+				if err := p.skillApproved(ctx, ts, skill); err != nil {
+					return "", err
+				}
+				p.emitStatus(ts.ID, 5, fmt.Sprintf("Running%s with skill %s…", subtaskLabel(ctx), skillID))
 				out, err := skill.Executor.Execute(ctx, instruments.SkillInput{
 					Goal:    sub.Goal,
 					Context: ts.Context,
@@ -472,6 +838,7 @@ func (p *Pipeline) executeSubtask(ctx context.Context, ts *TaskSpec, sub *Subtas
 					}
 					skill.RecordRun(out)
 					p.logInfo("skill executed", "subtask", sub.ID, "skill", skillID, "cost", out.CostUSD)
+					p.recordSkillSavings(ts, skillID, out)
 					p.recordMetric(observability.MetricFitness, 1.0, observability.Labels{"skill_id": skillID})
 					return out.Result, nil
 				}
@@ -485,15 +852,20 @@ func (p *Pipeline) executeSubtask(ctx context.Context, ts *TaskSpec, sub *Subtas
 		assignee := sub.AssignedTo
 		if len(assignee) > 6 && assignee[:6] == "agent:" {
 			agentID := assignee[6:]
+			p.emitStatus(ts.ID, 5, fmt.Sprintf("Delegating%s to agent %s…", subtaskLabel(ctx), agentID))
+			h := p.handoff(ctx, ts, sub, cost)
 			result, err := p.deps.SubagentMgr.Delegate(ctx, "pipeline", agentID, instruments.DelegatedTask{
 				Goal:    sub.Goal,
-				Context: ts.Context,
+				Context: h.Brief(),
+				Handoff: h,
 			})
 			if err == nil && result.Success {
 				*cost += result.CostUSD
 				if p.deps.Budget != nil {
 					p.deps.Budget.Record(ts.ID, result.CostUSD)
 				}
+				sub.QualityScore = result.Quality
+				sub.Provenance = result.Provenance
 				p.logInfo("subagent executed", "subtask", sub.ID, "agent", agentID, "quality", result.Quality)
 				return result.Output, nil
 			}
@@ -510,12 +882,9 @@ func (p *Pipeline) executeSubtask(ctx context.Context, ts *TaskSpec, sub *Subtas
 
 // executeLLM executes via LLM provider.
 func (p *Pipeline) executeLLM(ctx context.Context, ts *TaskSpec, cost *float64) (string, error) {
-	budgetRemaining := ts.BudgetUSD
-	if p.deps.Budget != nil {
-		budgetRemaining = p.deps.Budget.EffectiveBudget()
-	}
+	budgetRemaining := p.budgetRemaining(ts, *cost)
 
-	soulContent, _ := p.deps.Soul.Read()
+	soulContent := p.systemPrompt(ts)
 
 	var history []brain.Message
 	if ts.SessionID != "" {
@@ -527,26 +896,69 @@ func (p *Pipeline) executeLLM(ctx context.Context, ts *TaskSpec, cost *float64)
 	messages := p.deps.Context.Assemble(brain.ContextLayers{
 		SystemPrompt:    soulContent,
 		TaskDescription: ts.Goal,
+		RelevantMemory:  append(append(append(p.entityMemory(ts), p.topicMemory(ts)...), p.semanticMemory(ctx, ts)...), p.resumeMemory(ts)...),
 		RecentHistory:   history,
 	})
 
-	model := p.deps.Router.Select("moderate", budgetRemaining)
-	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+	complexity := ts.Complexity
+	if complexity == "" {
+		complexity = "moderate"
+	}
+	minTier, maxTier := p.tierBounds(ts)
+	model := ts.RequestedModel
+	if model == "" {
+		model = p.deps.Router.SelectBounded(complexity, budgetRemaining, minTier, maxTier)
+	}
+	ts.Model = model
+	p.emitStatus(ts.ID, 5, fmt.Sprintf("Executing%s on %s…", subtaskLabel(ctx), model))
+	req := brain.LLMRequest{
 		Messages:  messages,
 		Model:     model,
 		MaxTokens: 4096,
+	}
+	resp, err := p.completeDeclinable(ctx, ts, req, func(req brain.LLMRequest) (*brain.LLMResponse, error) {
+		if p.partialCallback != nil && ctx.Value(noPartialsKey{}) == nil {
+			resp, err := brain.CompleteStream(ctx, p.deps.LLM, req, func(chunk string) {
+				p.partialCallback(PartialEvent{TaskID: ts.ID, CorrelationID: ts.correlationID, Chunk: chunk})
+			})
+			p.partialCallback(PartialEvent{TaskID: ts.ID, CorrelationID: ts.correlationID, Done: true})
+			return resp, err
+		}
+		return p.deps.LLM.Complete(ctx, req)
 	})
+	if resp != nil {
+		*cost += resp.CostUSD
+		if p.deps.Budget != nil {
+			p.deps.Budget.Record(ts.ID, resp.CostUSD)
+		}
+	}
 	if err != nil {
 		return "", fmt.Errorf("execute: %w", err)
 	}
-	*cost += resp.CostUSD
-	if p.deps.Budget != nil {
-		p.deps.Budget.Record(ts.ID, resp.CostUSD)
-	}
 
 	return resp.Content, nil
 }
 
+// noPartialsKey marks a context whose executions must not stream, because
+// they run side by side or may be discarded.
+type noPartialsKey struct{}
+
+func withoutPartials(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noPartialsKey{}, true)
+}
+
+// systemPrompt returns the soul content followed by the user's local
+// date/time, so relative dates are resolved in the user's timezone.
+func (p *Pipeline) systemPrompt(ts *TaskSpec) string {
+	soulContent, _ := p.deps.Soul.Read()
+	zone := p.deps.Locale.For(ts.SourceUserID)
+	prompt := soulContent + "\n\n" + zone.ContextLine(time.Now())
+	if p.deps.Mode.ReadOnly() {
+		prompt += "\n\nThe agent is in read-only mode: answer questions, but do not perform or promise actions with side effects (sending messages, changing files or calling external services)."
+	}
+	return prompt
+}
+
 // Stage 6: Review — evaluate quality of execution.
 func (p *Pipeline) review(ctx context.Context, ts *TaskSpec, result string, cost *float64) (float64, string, error) {
 	ts.Advance(TaskStatusReviewing)
@@ -556,43 +968,103 @@ func (p *Pipeline) review(ctx context.Context, ts *TaskSpec, result string, cost
 	messages := p.deps.Context.Assemble(brain.ContextLayers{
 		SystemPrompt: soulContent,
 		TaskDescription: fmt.Sprintf(
-			"Review this task result. Rate quality from 0.0 to 1.0.\n\nOriginal task: %s\nResult: %s\n\nRespond in this format:\nSCORE: <0.0-1.0>\nNOTES: <brief assessment>",
-			ts.Goal, result),
+			"Review this task result. Rate quality from 0.0 to 1.0.\n\nOriginal task: %s\nResult: %s\n\n%s",
+			ts.Goal, result, reviewFormat),
 	})
 
 	model := p.deps.Router.Select("simple", ts.BudgetUSD)
-	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+	var v reviewVerdict
+	resp, err := brain.StructuredComplete(brain.WithCache(ctx), p.deps.LLM, brain.LLMRequest{
 		Messages: messages,
 		Model:    model,
-	})
-	if err != nil {
+	}, reviewSchema, &v)
+	if resp != nil {
+		*cost += resp.CostUSD
+	}
+	switch {
+	case errors.Is(err, brain.ErrUnstructured):
+		p.logWarn("review unparseable", "task_id", ts.ID, "reply", resp.Content)
+		p.incrementMetric("review.unparseable")
+		return unparsedReviewScore, resp.Content, nil
+	case err != nil:
 		return 0.5, "review failed", fmt.Errorf("review: %w", err)
 	}
-	*cost += resp.CostUSD
-
-	// Default quality; in production would parse SCORE from response.
-	return 0.8, resp.Content, nil
+	return *v.Score, v.Notes, nil
 }
 
 // Stage 7: Memory Update — store results in short and long term memory.
 func (p *Pipeline) updateMemory(ts *TaskSpec, result string) {
 	// Short-term: add the interaction (scoped to session).
-	p.deps.ShortTerm.AddWithSession("user", ts.Goal, map[string]string{
-		"task_id": ts.ID,
-		"channel": ts.SourceChannel,
-	}, ts.SessionID)
-	p.deps.ShortTerm.AddWithSession("assistant", result, map[string]string{
-		"task_id": ts.ID,
-		"quality": fmt.Sprintf("%.2f", ts.QualityScore),
-	}, ts.SessionID)
+	if !ts.Stateless {
+		p.deps.ShortTerm.AddWithSession("user", ts.Goal, map[string]string{
+			"task_id": ts.ID,
+			"channel": ts.SourceChannel,
+			"sender":  ts.SourceUserID,
+		}, ts.SessionID)
+		p.deps.ShortTerm.AddWithSession("assistant", result, map[string]string{
+			"task_id": ts.ID,
+			"quality": fmt.Sprintf("%.2f", ts.QualityScore),
+		}, ts.SessionID)
+	}
 
 	// Long-term: store a summary.
 	p.deps.LongTerm.Store(memory.LongTermEntry{
 		ID:          ts.ID,
-		Summary:     fmt.Sprintf("Task: %s → Quality: %.2f", ts.Goal, ts.QualityScore),
+		Summary:     fmt.Sprintf(taskSummaryFormat, ts.Goal, ts.QualityScore),
 		Tags:        []string{ts.SourceChannel, ts.Fingerprint},
 		SourceRunID: ts.ID,
+		CreatedAt:   time.Now().UTC(),
+		Owner:       ts.SourceUserID,
+		Channel:     ts.SourceChannel,
+		Visibility:  p.memoryVisibility(ts),
+		Topic:       ts.Topic,
 	})
+	if ts.Note != "" {
+		p.deps.LongTerm.Store(memory.LongTermEntry{
+			ID:          ts.ID + "_note",
+			Summary:     ts.Note,
+			Tags:        []string{"note", ts.Topic},
+			SourceRunID: ts.ID,
+			CreatedAt:   time.Now().UTC(),
+			Owner:       ts.SourceUserID,
+			Channel:     ts.SourceChannel,
+			Visibility:  p.memoryVisibility(ts),
+			Topic:       ts.Topic,
+		})
+	}
+}
+
+// memoryVisibility is the visibility of long-term entries learned from ts.
+func (p *Pipeline) memoryVisibility(ts *TaskSpec) memory.Visibility {
+	if v, ok := p.deps.MemoryVisibility[ts.SourceChannel]; ok {
+		return v
+	}
+	if ts.SourceUserID != "" {
+		return memory.VisibilityPrivate
+	}
+	return memory.VisibilityChannel
+}
+
+// viewer is who sees the results of memory lookups made for ts.
+func viewer(ts *TaskSpec) memory.Viewer {
+	return memory.Viewer{Sender: ts.SourceUserID, Channel: ts.SourceChannel}
+}
+
+// taskSummaryFormat is the long-term memory summary of a run.
+const taskSummaryFormat = "Task: %s → Quality: %.2f"
+
+// parseTaskSummary splits a taskSummaryFormat summary into goal and quality.
+func parseTaskSummary(summary string) (goal string, quality float64, ok bool) {
+	rest, ok := strings.CutPrefix(summary, "Task: ")
+	i := strings.LastIndex(rest, " → Quality: ")
+	if !ok || i < 0 {
+		return "", 0, false
+	}
+	q, err := strconv.ParseFloat(rest[i+len(" → Quality: "):], 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return rest[:i], q, true
 }
 
 // Stage 8: Pattern Tracking — fingerprint and count.
@@ -640,6 +1112,10 @@ func (p *Pipeline) reflect(ctx context.Context, ts *TaskSpec, quality float64, c
 		Summary:     fmt.Sprintf("Reflection on %s: %s", ts.ID, resp.Content),
 		Tags:        []string{"reflection", "meso"},
 		SourceRunID: ts.ID,
+		CreatedAt:   time.Now().UTC(),
+		Owner:       ts.SourceUserID,
+		Channel:     ts.SourceChannel,
+		Visibility:  p.memoryVisibility(ts),
 	})
 
 	return nil
@@ -658,6 +1134,8 @@ func (p *Pipeline) reflectPhase3(ctx context.Context, ts *TaskSpec, quality floa
 		CostUSD:       *cost,
 		Fingerprint:   ts.Fingerprint,
 		SourceChannel: ts.SourceChannel,
+		SourceUserID:  ts.SourceUserID,
+		Visibility:    p.memoryVisibility(ts),
 	}
 
 	_, mesoCost, err := p.deps.Reflection.Meso(ctx, soulContent, summary)
@@ -700,7 +1178,7 @@ func (p *Pipeline) updateGoals(ts *TaskSpec, automatable bool) {
 	}
 
 	if automatable {
-		p.deps.Goals.AddWithMeta(
+		p.deps.Goals.AddWithSnapshot(
 			fmt.Sprintf("Generate code-skill for pattern %s", ts.Fingerprint),
 			goals.GoalSourcePattern,
 			goals.GoalPriorityHigh,
@@ -709,12 +1187,13 @@ func (p *Pipeline) updateGoals(ts *TaskSpec, automatable bool) {
 				"goal":        ts.Goal,
 				"channel":     ts.SourceChannel,
 			},
+			p.goalSnapshot(ts, true),
 		)
 		p.logInfo("goal added: generate code-skill", "fingerprint", ts.Fingerprint)
 	}
 
 	if ts.QualityScore < 0.5 {
-		p.deps.Goals.AddWithMeta(
+		p.deps.Goals.AddWithSnapshot(
 			fmt.Sprintf("Investigate low quality for task type %s", ts.SourceChannel),
 			goals.GoalSourceReflection,
 			goals.GoalPriorityNormal,
@@ -722,10 +1201,48 @@ func (p *Pipeline) updateGoals(ts *TaskSpec, automatable bool) {
 				"task_id": ts.ID,
 				"quality": fmt.Sprintf("%.2f", ts.QualityScore),
 			},
+			p.goalSnapshot(ts, false),
 		)
 	}
 }
 
+// goalSnapshot captures ts — its input, constraints and review notes — for
+// a goal derived from it. With history, earlier runs of the same pattern
+// are added from long-term memory, so a code-skill goal sees several
+// example inputs rather than just the one that crossed the threshold.
+func (p *Pipeline) goalSnapshot(ts *TaskSpec, history bool) goals.Snapshot {
+	snap := goals.Snapshot{
+		TakenAt:        time.Now().UTC(),
+		Inputs:         []string{ts.Goal},
+		RecentRuns:     []goals.RunSummary{{TaskID: ts.ID, Summary: ts.Goal, Quality: ts.QualityScore, At: ts.UpdatedAt}},
+		Constraints:    ts.Constraints,
+		ExpectedOutput: ts.ExpectedOutput,
+		Notes:          ts.ReviewNotes,
+	}
+	if !history || p.deps.LongTerm == nil || ts.Fingerprint == "" {
+		return snap
+	}
+	entries, err := p.deps.LongTerm.SearchAs(viewer(ts), `"`+ts.Fingerprint+`"`, 4*goals.MaxSnapshotItems)
+	if err != nil {
+		p.logWarn("goal snapshot: history lookup failed", "fingerprint", ts.Fingerprint, "error", err.Error())
+		return snap
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	seen := map[string]bool{ts.Goal: true}
+	for _, e := range entries {
+		goal, quality, ok := parseTaskSummary(e.Summary)
+		if !ok || e.SourceRunID == ts.ID {
+			continue
+		}
+		snap.RecentRuns = append(snap.RecentRuns, goals.RunSummary{TaskID: e.SourceRunID, Summary: goal, Quality: quality, At: e.CreatedAt})
+		if !seen[goal] {
+			seen[goal] = true
+			snap.Inputs = append(snap.Inputs, goal)
+		}
+	}
+	return snap
+}
+
 // evolve evaluates skill fitness and triggers deprecation if needed.
 func (p *Pipeline) evolve(ts *TaskSpec, quality float64) {
 	if p.deps.Evolution == nil || p.deps.Skills == nil {
@@ -737,31 +1254,73 @@ func (p *Pipeline) evolve(ts *TaskSpec, quality float64) {
 		winner, loserID, decided := p.deps.Evolution.EvaluateABTest(test.ID, p.deps.Skills)
 		if decided {
 			p.logInfo("A/B test decided", "test_id", test.ID, "winner", winner, "loser", loserID)
-			_ = p.deps.Skills.UpdateStatus(loserID, instruments.SkillStatusDeprecated)
-			_ = p.deps.Skills.UpdateStatus(winner, instruments.SkillStatusActive)
+			p.setSkillStatus(loserID, instruments.SkillStatusDeprecated, "experiment "+test.ID, "Lost A/B test to "+winner)
+			p.setSkillStatus(winner, instruments.SkillStatusActive, "experiment "+test.ID, "Won A/B test against "+loserID)
 		}
 	}
 
 	// Evaluate all skills for deprecation.
 	deprecated := p.deps.Evolution.EvaluateAll(p.deps.Skills)
 	for _, id := range deprecated {
-		_ = p.deps.Skills.UpdateStatus(id, instruments.SkillStatusDeprecated)
+		p.setSkillStatus(id, instruments.SkillStatusDeprecated, "fitness evaluation", "Deprecated for low fitness")
 		p.logInfo("deprecated skill (low fitness)", "skill_id", id)
 	}
 }
 
+// setSkillStatus changes a skill's status and records the change, with the
+// previous status as rollback data, so it shows up for oversight.
+func (p *Pipeline) setSkillStatus(id string, status instruments.SkillStatus, trigger, desc string) {
+	sk := p.deps.Skills.Get(id)
+	if sk == nil || sk.Meta.Status == status {
+		return
+	}
+	prev := sk.Meta.Status
+	if err := p.deps.Skills.UpdateStatus(id, status); err != nil {
+		return
+	}
+	switch status {
+	case instruments.SkillStatusActive:
+		p.deps.Events.Publish(events.SkillPromoted, SkillEvent{SkillID: id, From: prev, To: status, Trigger: trigger, Reason: desc})
+	case instruments.SkillStatusDeprecated:
+		p.deps.Events.Publish(events.SkillDeprecated, SkillEvent{SkillID: id, From: prev, To: status, Trigger: trigger, Reason: desc})
+	}
+	if p.deps.VersionControl == nil {
+		return
+	}
+	p.deps.VersionControl.Record(versioning.Change{
+		Type:            versioning.ChangeSkill,
+		EntityID:        id,
+		Description:     desc,
+		Trigger:         trigger,
+		BaselineQuality: sk.Meta.AvgQuality,
+		BaselineCost:    sk.Meta.AvgCostUSD,
+		RollbackData:    string(prev),
+		Before:          "status: " + string(prev),
+		After:           "status: " + string(status),
+	})
+}
+
 // observeVersion records a run against active observation windows.
 func (p *Pipeline) observeVersion(ts *TaskSpec, quality float64) {
 	if p.deps.VersionControl == nil {
 		return
 	}
 
-	// Observe against all entity types that might have pending changes.
-	rollbacks := p.deps.VersionControl.ObserveRun(ts.Fingerprint, quality, ts.QualityScore)
-	for _, changeID := range rollbacks {
-		ch := p.deps.VersionControl.Get(changeID)
-		if ch != nil {
-			p.logWarn("auto-rollback triggered", "description", ch.Description, "entity", ch.EntityID)
+	// Observe against all entity types that might have pending changes:
+	// the pattern, the soul (which shapes every run) and the pattern's skills.
+	entities := []string{ts.Fingerprint, versioning.SoulEntity}
+	if p.deps.Skills != nil {
+		for _, sk := range p.deps.Skills.FindByFingerprint(ts.Fingerprint) {
+			entities = append(entities, sk.Meta.ID)
+		}
+	}
+	for _, entity := range entities {
+		rollbacks := p.deps.VersionControl.ObserveRun(entity, quality, ts.QualityScore)
+		for _, changeID := range rollbacks {
+			ch := p.deps.VersionControl.Get(changeID)
+			if ch != nil {
+				p.logWarn("auto-rollback triggered", "description", ch.Description, "entity", ch.EntityID)
+			}
 		}
 	}
 }
@@ -890,12 +1449,32 @@ func min(a, b int) int {
 func (p *Pipeline) failResult(ts *TaskSpec, start time.Time, cost float64, err error, stageLogs []StageLog) *RunResult {
 	ts.Advance(TaskStatusFailed)
 	p.recordMetric(observability.MetricErrors, 1, observability.Labels{"task_id": ts.ID})
+	msg := err.Error()
+	if p.settleBuffer(ts, err) {
+		msg += "\n\nThe findings so far were saved; ask me to continue where I left off."
+	}
 	return &RunResult{
 		TaskID:    ts.ID,
 		Success:   false,
-		Result:    err.Error(),
+		Result:    msg,
 		CostUSD:   cost,
 		ElapsedMs: time.Since(start).Milliseconds(),
 		StageLogs: stageLogs,
 	}
 }
+
+// parkedResult ends a task waiting for the approval in parked. Findings of
+// the steps that ran stay in the run buffer.
+func (p *Pipeline) parkedResult(ts *TaskSpec, start time.Time, cost float64, parked *awaitingApproval, stageLogs []StageLog) *RunResult {
+	p.incrementMetric("pipeline.parked")
+	p.settleBuffer(ts, parked)
+	return &RunResult{
+		TaskID:           ts.ID,
+		Result:           fmt.Sprintf("Waiting for an admin to approve: %s. The task will continue once it is decided.", parked.action),
+		CostUSD:          cost,
+		ElapsedMs:        time.Since(start).Milliseconds(),
+		Model:            ts.Model,
+		AwaitingApproval: parked.id,
+		StageLogs:        stageLogs,
+	}
+}
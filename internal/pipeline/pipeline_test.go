@@ -1,61 +1,40 @@
 package pipeline
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/overhuman/overhuman/internal/approvals"
 	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/events"
+	"github.com/overhuman/overhuman/internal/goals"
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/locale"
 	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/observability"
+	"github.com/overhuman/overhuman/overhumantest"
+	"github.com/overhuman/overhuman/internal/security"
 	"github.com/overhuman/overhuman/internal/senses"
 	"github.com/overhuman/overhuman/internal/soul"
 )
 
 // mockLLMServer creates a test server that returns a constant response.
-func mockLLMServer(t *testing.T) *httptest.Server {
+func mockLLMServer(t *testing.T) *overhumantest.MockProvider {
 	t.Helper()
-	callCount := 0
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-		w.Header().Set("Content-Type", "application/json")
-
-		// Return Claude-formatted response.
-		type contentBlock struct {
-			Type string `json:"type"`
-			Text string `json:"text,omitempty"`
-		}
-		type response struct {
-			ID      string         `json:"id"`
-			Type    string         `json:"type"`
-			Role    string         `json:"role"`
-			Model   string         `json:"model"`
-			Content []contentBlock `json:"content"`
-			StopReason string     `json:"stop_reason"`
-			Usage   struct {
-				InputTokens  int `json:"input_tokens"`
-				OutputTokens int `json:"output_tokens"`
-			} `json:"usage"`
-		}
-
-		resp := response{
-			ID:    "msg_test",
-			Type:  "message",
-			Role:  "assistant",
-			Model: "claude-sonnet-4-20250514",
-			Content: []contentBlock{
-				{Type: "text", Text: "SCORE: 0.85\nNOTES: Task completed successfully."},
-			},
-			StopReason: "end_turn",
-		}
-		resp.Usage.InputTokens = 50
-		resp.Usage.OutputTokens = 30
-
-		json.NewEncoder(w).Encode(resp)
-	}))
+	return overhumantest.NewMockProvider(t, "SCORE: 0.85\nNOTES: Task completed successfully.")
 }
 
 func setupDeps(t *testing.T, srvURL string) Dependencies {
@@ -176,6 +155,43 @@ func TestPipeline_MemoryUpdated(t *testing.T) {
 	}
 }
 
+func TestPipeline_RecordsTranscript(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	tl, err := memory.NewTranscriptLog(filepath.Join(t.TempDir(), "transcripts.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Transcripts = tl
+	p := New(deps)
+
+	input := senses.UnifiedInput{
+		InputID:    "input_tr",
+		SourceType: senses.SourceTelegram,
+		Payload:    "[style] Plan my week",
+		SourceMeta: senses.SourceMeta{Sender: "alice", Extra: map[string]string{"raw_payload": "Plan my week"}},
+	}
+	result, err := p.Run(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Run(context.Background(), senses.UnifiedInput{InputID: "tick", SourceType: senses.SourceTimer, Payload: "heartbeat"})
+
+	entries, err := tl.Query(memory.TranscriptFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 transcript entry (timers skipped), got %d", len(entries))
+	}
+	e := entries[0]
+	if e.TaskID != result.TaskID || e.Input != "Plan my week" || e.Sender != "alice" || e.Channel != string(senses.SourceTelegram) || e.Result != result.Result {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
 func TestPipeline_PatternTracking(t *testing.T) {
 	srv := mockLLMServer(t)
 	defer srv.Close()
@@ -203,6 +219,115 @@ func TestPipeline_PatternTracking(t *testing.T) {
 	}
 }
 
+func TestPipeline_PatternGoalSnapshot(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	deps.Goals = goals.New()
+	p := New(deps)
+
+	var taskIDs []string
+	for i := 0; i < 3; i++ {
+		result, err := p.Run(context.Background(), senses.UnifiedInput{
+			InputID:    fmt.Sprintf("input_snap_%d", i),
+			SourceType: senses.SourceText,
+			Payload:    "Convert the sales CSV to a weekly summary",
+		})
+		if err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		taskIDs = append(taskIDs, result.TaskID)
+	}
+
+	pending := deps.Goals.ListByStatus(goals.GoalStatusPending)
+	if len(pending) != 1 || pending[0].Source != goals.GoalSourcePattern {
+		t.Fatalf("pending goals = %+v, want one pattern goal", pending)
+	}
+	snap := pending[0].Snapshot
+	if snap == nil {
+		t.Fatal("pattern goal has no snapshot")
+	}
+	if len(snap.Inputs) != 1 || !strings.Contains(snap.Inputs[0], "sales CSV") {
+		t.Errorf("inputs = %q", snap.Inputs)
+	}
+	// The run that created the goal, then the two earlier ones from memory.
+	if len(snap.RecentRuns) != 3 || snap.RecentRuns[0].TaskID != taskIDs[2] {
+		t.Fatalf("recent runs = %+v", snap.RecentRuns)
+	}
+	for _, r := range snap.RecentRuns[1:] {
+		if r.TaskID == taskIDs[2] || r.Quality == 0 || r.At.IsZero() {
+			t.Errorf("history run = %+v", r)
+		}
+	}
+	if brief := pending[0].Brief(); !strings.Contains(brief, "Recent runs:") || !strings.Contains(brief, taskIDs[0]) {
+		t.Errorf("brief lacks the captured runs:\n%s", brief)
+	}
+}
+
+func TestPipeline_MemoryVisibility(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	// Two runs from alice then one from bob on the same channel; bob's
+	// pattern goal may only see alice's runs if the channel is shared.
+	for _, tc := range []struct {
+		name       string
+		visibility map[string]memory.Visibility
+		wantRuns   int
+	}{
+		{"default private", nil, 1},
+		{"shared channel", map[string]memory.Visibility{string(senses.SourceTelegram): memory.VisibilityShared}, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deps := setupDeps(t, srv.URL)
+			deps.Goals = goals.New()
+			deps.MemoryVisibility = tc.visibility
+			p := New(deps)
+
+			for i, sender := range []string{"alice", "alice", "bob"} {
+				in := senses.UnifiedInput{
+					InputID:    fmt.Sprintf("input_vis_%d", i),
+					SourceType: senses.SourceTelegram,
+					Payload:    "Summarise my bank statement",
+				}
+				in.SourceMeta.Sender = sender
+				if _, err := p.Run(context.Background(), in); err != nil {
+					t.Fatalf("run %d: %v", i+1, err)
+				}
+			}
+
+			pending := deps.Goals.ListByStatus(goals.GoalStatusPending)
+			if len(pending) != 1 || pending[0].Snapshot == nil {
+				t.Fatalf("pending goals = %+v, want one pattern goal", pending)
+			}
+			if got := len(pending[0].Snapshot.RecentRuns); got != tc.wantRuns {
+				t.Errorf("bob's snapshot has %d runs, want %d: %+v", got, tc.wantRuns, pending[0].Snapshot.RecentRuns)
+			}
+
+			all, _ := deps.LongTerm.GetAll(20)
+			for _, e := range all {
+				if e.Owner == "" || e.Channel != string(senses.SourceTelegram) {
+					t.Errorf("entry %s has no owner/channel: %+v", e.ID, e)
+				}
+				if tc.visibility == nil && e.Visibility != memory.VisibilityPrivate {
+					t.Errorf("entry %s visibility = %q, want private", e.ID, e.Visibility)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTaskSummary(t *testing.T) {
+	goal, q, ok := parseTaskSummary(fmt.Sprintf(taskSummaryFormat, "a → b", 0.75))
+	if !ok || goal != "a → b" || q != 0.75 {
+		t.Errorf("parseTaskSummary = %q, %v, %v", goal, q, ok)
+	}
+	if _, _, ok := parseTaskSummary("Reflection on t1: fine"); ok {
+		t.Error("parsed a reflection summary")
+	}
+}
+
 func TestPipeline_Heartbeat(t *testing.T) {
 	srv := mockLLMServer(t)
 	defer srv.Close()
@@ -438,3 +563,1711 @@ func TestEmitStage_WithoutCallback(t *testing.T) {
 	// No callback — should not panic.
 	p.emitStage("t1", 1, "intake", "started", "", 0)
 }
+
+func TestOnStatus_StreamsProgress(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{Default: "Done."})
+	deps.LLM = fake
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	deps.Metrics = observability.NewMetricsCollector(0)
+	p := New(deps)
+
+	var mu sync.Mutex
+	var lines []string
+	p.OnStatus(func(evt StatusEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if evt.TaskID == "" || evt.Stage == 0 {
+			t.Errorf("status without task or stage: %+v", evt)
+		}
+		lines = append(lines, evt.Text)
+	})
+	if _, err := p.Run(context.Background(), senses.UnifiedInput{InputID: "in_1", SourceType: senses.SourceText, Payload: "plan a trip to Rome"}); err != nil {
+		t.Fatal(err)
+	}
+	ts := &TaskSpec{ID: "task_dag", Goal: "plan a trip", Subtasks: []SubtaskSpec{
+		{ID: "s1", Goal: "find flights"}, {ID: "s2", Goal: "find hotels"}, {ID: "s3", Goal: "compare", DependsOn: []string{"s1", "s2"}},
+	}}
+	var cost float64
+	if _, err := p.executeDAG(context.Background(), ts, &cost); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{"Clarifying…", "Executing on ", "Reviewing the result…", "Executing subtask 2/3 on ", "Executing subtask 3/3 on "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("status lines missing %q:\n%s", want, got)
+		}
+	}
+
+	var exp strings.Builder
+	deps.Metrics.WritePrometheus(&exp)
+	for _, want := range []string{`overhuman_pipeline_stage_duration_seconds_count{stage="clarify",status="completed"} 1`, `overhuman_pipeline_stage_duration_seconds_count{stage="review",status="completed"} 1`} {
+		if !strings.Contains(exp.String(), want) {
+			t.Errorf("stage metrics missing %q", want)
+		}
+	}
+}
+
+func TestStageStatus(t *testing.T) {
+	tests := []struct {
+		evt  StageEvent
+		want string
+	}{
+		{StageEvent{Name: "clarify", Status: "started"}, "Clarifying…"},
+		{StageEvent{Name: "plan", Status: "completed", Summary: "subtasks=1"}, ""},
+		{StageEvent{Name: "plan", Status: "completed", Summary: "subtasks=4"}, "Planning 4 subtasks…"},
+		{StageEvent{Name: "plan", Status: "completed", Summary: "warm_start"}, "Reusing the plan of a similar task…"},
+		{StageEvent{Name: "agent_selection", Status: "started"}, ""},
+		{StageEvent{Name: "execute", Status: "error"}, "Stopped: execute failed"},
+	}
+	for _, tt := range tests {
+		if got := stageStatus(tt.evt); got != tt.want {
+			t.Errorf("stageStatus(%+v) = %q, want %q", tt.evt, got, tt.want)
+		}
+	}
+}
+
+func TestPipeline_Cancel(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{Default: "Done.", LatencyMs: 5000})
+	deps.LLM = fake
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	p := New(deps)
+	if p.Cancel("task_unknown") {
+		t.Error("cancelled a task that is not running")
+	}
+
+	type outcome struct {
+		res *RunResult
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		in := senses.UnifiedInput{InputID: "in_slow", SourceType: senses.SourceAPI, Payload: "a slow task", CorrelationID: "conn_1"}
+		res, err := p.Run(context.Background(), in)
+		done <- outcome{res, err}
+	}()
+
+	var active []ActiveTask
+	for deadline := time.Now().Add(2 * time.Second); len(active) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		active = p.ActiveTasks()
+	}
+	if len(active) != 1 || active[0].InputID != "in_slow" || active[0].CorrelationID != "conn_1" {
+		t.Fatalf("ActiveTasks() = %+v", active)
+	}
+	if !p.Cancel(active[0].TaskID) {
+		t.Fatal("Cancel reported the task not running")
+	}
+	select {
+	case o := <-done:
+		if o.err != nil || o.res == nil || !o.res.Cancelled || o.res.Success || o.res.Result != cancelledMessage {
+			t.Errorf("Run = %+v, %v; want a cancelled result", o.res, o.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("run did not stop after Cancel")
+	}
+	if got := p.ActiveTasks(); len(got) != 0 {
+		t.Errorf("ActiveTasks() after the run = %+v", got)
+	}
+}
+
+func TestPipeline_SystemPromptIncludesUserTimezone(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	base := mockLLMServer(t)
+	defer base.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(b))
+		base.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	loc, err := locale.NewResolver(locale.Settings{Timezone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loc.SetUser("alice", locale.Settings{Timezone: "Asia/Tokyo", Locale: "ja-JP"}); err != nil {
+		t.Fatal(err)
+	}
+	deps.Locale = loc
+
+	input := senses.NewFromText("remind me tomorrow at 9")
+	input.SourceMeta.Sender = "alice"
+	if _, err := New(deps).Run(context.Background(), *input); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) == 0 {
+		t.Fatal("no LLM requests captured")
+	}
+	if !strings.Contains(bodies[0], "Asia/Tokyo") || !strings.Contains(bodies[0], "ja-JP") {
+		t.Errorf("clarify request should carry user's timezone and locale, got: %s", bodies[0])
+	}
+}
+
+// countingSkill records how often it was executed.
+type countingSkill struct{ runs int }
+
+func (c *countingSkill) Execute(context.Context, instruments.SkillInput) (*instruments.SkillOutput, error) {
+	c.runs++
+	return &instruments.SkillOutput{Result: "skill ran", Success: true}, nil
+}
+
+func TestPipeline_ReadOnlySkipsSkills(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	exec := &countingSkill{}
+	deps.Skills = instruments.NewSkillRegistry()
+	deps.Skills.Register(&instruments.Skill{
+		Meta:     instruments.SkillMeta{ID: "send_mail", Name: "Send mail", Status: instruments.SkillStatusActive},
+		Executor: exec,
+	})
+	deps.Mode = senses.NewModeSwitch()
+	p := New(deps)
+
+	ts := p.intake(*senses.NewFromText("email the report to Bob"))
+	sub := &SubtaskSpec{ID: "s1", Goal: "send the email", AssignedTo: "skill:send_mail"}
+
+	var cost float64
+	if out, err := p.executeSubtask(context.Background(), ts, sub, &cost); err != nil || out != "skill ran" {
+		t.Fatalf("normal mode: %q, %v", out, err)
+	}
+
+	deps.Mode.Set(senses.ModeReadOnly, "")
+	out, err := p.executeSubtask(context.Background(), ts, sub, &cost)
+	if err != nil {
+		t.Fatalf("read-only mode: %v", err)
+	}
+	if exec.runs != 1 || out == "skill ran" {
+		t.Errorf("read-only mode ran the skill (runs=%d, out=%q)", exec.runs, out)
+	}
+	if !strings.Contains(p.systemPrompt(ts), "read-only mode") {
+		t.Error("system prompt should mention read-only mode")
+	}
+}
+
+func TestPipeline_SelectAgentByTrigger(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	exec := &countingSkill{}
+	deps.Skills = instruments.NewSkillRegistry()
+	deps.Skills.Register(&instruments.Skill{
+		Meta:     instruments.SkillMeta{ID: "tmpl_weather", Name: "Weather", Type: instruments.SkillTypeCode, Status: instruments.SkillStatusActive, Triggers: []string{"weather"}},
+		Executor: exec,
+	})
+	p := New(deps)
+
+	result, err := p.Run(context.Background(), *senses.NewFromText("what's the weather in Paris"))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if exec.runs != 1 || result.Result != "skill ran" {
+		t.Errorf("trigger skill runs = %d, result %q", exec.runs, result.Result)
+	}
+}
+
+func TestPipeline_ScratchSpace(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	dir := t.TempDir()
+	deps.Scratch = ScratchPolicy{Dir: filepath.Join(dir, "scratch"), ArtifactsDir: filepath.Join(dir, "artifacts")}
+	var seen string
+	deps.Skills = instruments.NewSkillRegistry()
+	deps.Skills.Register(&instruments.Skill{
+		Meta: instruments.SkillMeta{ID: "tmpl_report", Name: "Report", Type: instruments.SkillTypeCode, Status: instruments.SkillStatusActive, Triggers: []string{"report"}},
+		Executor: instruments.NewCodeSkill(func(ctx context.Context, _ instruments.SkillInput) (*instruments.SkillOutput, error) {
+			scratch := instruments.ScratchFrom(ctx)
+			if scratch == nil {
+				return &instruments.SkillOutput{Success: false, Error: "no scratch"}, nil
+			}
+			seen = scratch.Dir()
+			if err := scratch.WriteFile("out/report.csv", []byte("a,b\n")); err != nil {
+				return nil, err
+			}
+			return &instruments.SkillOutput{Result: "report written", Success: true}, nil
+		}, "go", ""),
+	})
+	p := New(deps)
+
+	res, err := p.Run(context.Background(), *senses.NewFromText("build the report"))
+	if err != nil || res.Result != "report written" {
+		t.Fatalf("Run = %+v, %v", res, err)
+	}
+	if _, err := os.Stat(seen); !os.IsNotExist(err) || res.ArtifactsDir != "" {
+		t.Errorf("scratch %s not removed after the run (artifacts %q)", seen, res.ArtifactsDir)
+	}
+
+	in := *senses.NewFromText("build the report")
+	in.SourceMeta.Extra = map[string]string{"keep_artifacts": "true"}
+	res, err = p.Run(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ArtifactsDir != filepath.Join(dir, "artifacts", res.TaskID) || len(res.Artifacts) != 1 || res.Artifacts[0] != "out/report.csv" {
+		t.Fatalf("artifacts = %q %v", res.ArtifactsDir, res.Artifacts)
+	}
+	if data, _ := os.ReadFile(filepath.Join(res.ArtifactsDir, "out", "report.csv")); string(data) != "a,b\n" {
+		t.Errorf("kept report = %q", data)
+	}
+}
+
+type scriptedPrompter struct {
+	answers []security.PermissionDecision
+	asked   []string
+}
+
+func (s *scriptedPrompter) PromptPermission(_ context.Context, req security.PermissionRequest) (security.PermissionDecision, error) {
+	s.asked = append(s.asked, req.Permission)
+	d := s.answers[0]
+	s.answers = s.answers[1:]
+	return d, nil
+}
+
+func TestPipeline_SkillPermissions(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	exec := &countingSkill{}
+	deps.Skills = instruments.NewSkillRegistry()
+	deps.Skills.Register(&instruments.Skill{
+		Meta: instruments.SkillMeta{
+			ID: "fetch", Name: "Fetch", Status: instruments.SkillStatusActive,
+			Doc: &instruments.SkillDoc{Permissions: []string{instruments.PermNetwork}},
+		},
+		Executor: exec,
+	})
+	store, err := security.NewPermissionStore(filepath.Join(t.TempDir(), "permissions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompter := &scriptedPrompter{answers: []security.PermissionDecision{security.PermissionOnce, security.PermissionNever}}
+	deps.Permissions = store
+	deps.PermissionPrompter = prompter
+	p := New(deps)
+
+	ts := p.intake(*senses.NewFromText("fetch the page"))
+	sub := &SubtaskSpec{ID: "s1", Goal: "fetch", AssignedTo: "skill:fetch"}
+	var cost float64
+
+	// "once" runs the skill without remembering; "never" is persisted and
+	// later uses fall back to the LLM without asking again.
+	p.executeSubtask(context.Background(), ts, sub, &cost)
+	p.executeSubtask(context.Background(), ts, sub, &cost)
+	p.executeSubtask(context.Background(), ts, sub, &cost)
+	if exec.runs != 1 {
+		t.Errorf("skill runs = %d, want 1", exec.runs)
+	}
+	if len(prompter.asked) != 2 || prompter.asked[0] != instruments.PermNetwork {
+		t.Errorf("asked = %v, want network twice", prompter.asked)
+	}
+	if d, ok := store.Decision("fetch", instruments.PermNetwork); !ok || d != security.PermissionNever {
+		t.Errorf("stored decision = %q, %v", d, ok)
+	}
+}
+
+func TestPipeline_SkillApproval(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	exec := &countingSkill{}
+	deps.Skills = instruments.NewSkillRegistry()
+	deps.Skills.Register(&instruments.Skill{
+		Meta: instruments.SkillMeta{
+			ID: "deploy", Name: "Deploy", Status: instruments.SkillStatusActive,
+			Doc: &instruments.SkillDoc{Permissions: []string{instruments.PermProcess}},
+		},
+		Executor: exec,
+	})
+	deps.PolicyEnforcer = security.NewPolicyEnforcer()
+	deps.ApprovalPermissions = []string{instruments.PermProcess}
+	deps.Approvals = approvals.New(0)
+	decisions := []bool{true, false}
+	deps.Approvals.OnRequest(func(a approvals.Approval) {
+		if a.Action != "run skill Deploy (deploy)" || a.Detail == "" {
+			t.Errorf("approval = %+v", a)
+		}
+		deps.Approvals.Decide(a.ID, decisions[0], "bob", "")
+		decisions = decisions[1:]
+	})
+	p := New(deps)
+
+	ts := p.intake(*senses.NewFromText("deploy the site"))
+	sub := &SubtaskSpec{ID: "s1", Goal: "deploy", AssignedTo: "skill:deploy"}
+	var cost float64
+
+	if _, err := p.executeSubtask(context.Background(), ts, sub, &cost); err != nil || exec.runs != 1 {
+		t.Fatalf("approved run: err = %v, runs = %d", err, exec.runs)
+	}
+	_, err := p.executeSubtask(context.Background(), ts, sub, &cost)
+	if !errors.Is(err, ErrApprovalDenied) || !strings.Contains(err.Error(), "denied by bob") || exec.runs != 1 {
+		t.Fatalf("denied run: err = %v, runs = %d", err, exec.runs)
+	}
+
+	// Nobody decides at once: the task is parked instead of waiting, and
+	// run again with the decision.
+	wait := approvals.New(0)
+	p.deps.Approvals = wait
+	_, err = p.executeSubtask(context.Background(), ts, sub, &cost)
+	pending := wait.Pending()
+	if !errors.Is(err, ErrAwaitingApproval) || len(pending) != 1 || exec.runs != 1 {
+		t.Fatalf("parked run: err = %v, pending = %+v, runs = %d", err, pending, exec.runs)
+	}
+	wait.Decide(pending[0].ID, true, "bob", "")
+	in := senses.NewFromText("deploy the site")
+	ResumeWithApproval(in, pending[0].ID)
+	resumed := p.intake(*in)
+	if _, err := p.executeSubtask(context.Background(), resumed, sub, &cost); err != nil || exec.runs != 2 || len(wait.List("")) != 1 {
+		t.Fatalf("resumed run: err = %v, runs = %d, approvals = %d", err, exec.runs, len(wait.List("")))
+	}
+}
+
+func TestPipeline_SkillSavings(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	savings, err := memory.NewSavingsTracker(deps.LongTerm.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Savings = savings
+	p := New(deps)
+
+	// Cold path: the LLM answers and the fingerprint gets a baseline.
+	input := *senses.NewFromText("summarize the weekly sales numbers")
+	if _, err := p.Run(context.Background(), input); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	fp := deps.Patterns.ComputeFingerprint(input.Payload, string(input.SourceType))
+	b, err := savings.Baseline(fp)
+	if err != nil || b == nil || b.Runs != 1 {
+		t.Fatalf("baseline after cold run = %+v, %v", b, err)
+	}
+
+	// Hot path: a skill for the same pattern takes over and its savings are
+	// recorded; the baseline is left alone.
+	exec := &countingSkill{}
+	deps.Skills = instruments.NewSkillRegistry()
+	deps.Skills.Register(&instruments.Skill{
+		Meta:     instruments.SkillMeta{ID: "sales_summary", Name: "Sales summary", Status: instruments.SkillStatusActive, Fingerprint: fp},
+		Executor: exec,
+	})
+	p = New(deps)
+	if _, err := p.Run(context.Background(), input); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if exec.runs != 1 {
+		t.Fatalf("skill runs = %d, want 1", exec.runs)
+	}
+	rep, err := savings.Report(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Runs != 1 || len(rep.Skills) != 1 || rep.Skills[0].SkillID != "sales_summary" {
+		t.Errorf("savings report = %+v", rep)
+	}
+	if b, _ := savings.Baseline(fp); b.Runs != 1 {
+		t.Errorf("skill run changed the baseline: %+v", b)
+	}
+}
+
+func TestDetectEscalation(t *testing.T) {
+	cases := []struct {
+		in, rest string
+		ok       bool
+	}{
+		{"Use the better model for this: summarize the Q3 report", "summarize the Q3 report", true},
+		{"summarize the Q3 report, use a stronger model", "summarize the Q3 report", true},
+		{"use the better model for this", "", true},
+		{"summarize the Q3 report", "summarize the Q3 report", false},
+	}
+	for _, c := range cases {
+		rest, ok := detectEscalation(c.in)
+		if rest != c.rest || ok != c.ok {
+			t.Errorf("detectEscalation(%q) = %q, %v; want %q, %v", c.in, rest, ok, c.rest, c.ok)
+		}
+	}
+}
+
+func TestPipeline_ManualEscalationLearnsOverride(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	overrides, _ := brain.NewRoutingOverrides("")
+	deps.RoutingOverrides = overrides
+	p := New(deps)
+
+	first := senses.NewFromText("draft the incident postmortem")
+	first.SessionID = "s1"
+	if _, err := p.Run(context.Background(), *first); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(overrides.List()) != 0 {
+		t.Fatal("no override expected before escalation")
+	}
+
+	// A bare escalation re-runs the previous request of the session.
+	again := senses.NewFromText("use the better model for this")
+	again.SessionID = "s1"
+	ts := p.intake(*again)
+	p.applyRouting(ts, *again)
+	if ts.Goal != "draft the incident postmortem" || ts.Complexity != "complex" {
+		t.Errorf("escalated task: goal=%q complexity=%q", ts.Goal, ts.Complexity)
+	}
+	list := overrides.List()
+	if len(list) != 1 || list[0].Tier != brain.TierPowerful || list[0].Reason != brain.OverrideReasonManual {
+		t.Fatalf("overrides = %+v", list)
+	}
+
+	// Next time the same task family goes straight to the powerful tier.
+	next := senses.NewFromText("draft the incident postmortem")
+	ts = p.intake(*next)
+	p.applyRouting(ts, *next)
+	if ts.Complexity != "complex" {
+		t.Errorf("learned override not applied: complexity=%q", ts.Complexity)
+	}
+	ts.BudgetUSD = 10 // a low budget would force the cheap tier
+	var cost float64
+	if _, err := p.executeLLM(context.Background(), ts, &cost); err != nil {
+		t.Fatal(err)
+	}
+	if deps.Router.TierOf(ts.Model) != brain.TierPowerful {
+		t.Errorf("model = %s, want a powerful-tier model", ts.Model)
+	}
+}
+
+func TestPipeline_EscalationKeepsBetterResult(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	overrides, _ := brain.NewRoutingOverrides("")
+	deps.RoutingOverrides = overrides
+	deps.EscalationThreshold = 0.9 // the mock reviews score 0.85, so every run retries
+	p := New(deps)
+
+	ts := p.intake(*senses.NewFromText("plan the offsite"))
+	ts.BudgetUSD = 10
+	var cost float64
+	result, err := p.executeLLM(context.Background(), ts, &cost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstModel := ts.Model
+
+	// The retry scores the same, so the original result and model stay.
+	got, q, _, escalated := p.escalate(context.Background(), ts, result, 0.85, "notes", &cost)
+	if escalated || got != result || q != 0.85 || ts.Model != firstModel {
+		t.Errorf("escalate = %v, %q, %.2f (model %s)", escalated, got, q, ts.Model)
+	}
+	if len(overrides.List()) != 0 {
+		t.Error("no override should be learned when escalation did not help")
+	}
+
+	deps.EscalationThreshold = 0
+	if _, _, _, escalated := New(deps).escalate(context.Background(), ts, result, 0.1, "", &cost); escalated {
+		t.Error("threshold 0 disables escalation")
+	}
+}
+
+func TestPipeline_BudgetExhausted(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	deps.Budget = budget.New(1.0, 0)
+	deps.Budget.Record("earlier", 1.0)
+	deps.PriorityPolicy = budget.DefaultPolicy()
+	p := New(deps)
+
+	res, err := p.Run(context.Background(), *senses.NewFromText("summarize the news"))
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("err = %v, want ErrBudgetExhausted", err)
+	}
+	if res == nil || res.Success || !strings.Contains(res.Result, "$1.00 daily budget") || !strings.Contains(res.Result, "daily_budget_usd") {
+		t.Errorf("result = %+v", res)
+	}
+	if deps.Budget.TotalSpend() != 1.0 {
+		t.Errorf("spent $%f on a refused task", deps.Budget.TotalSpend()-1.0)
+	}
+
+	// Critical tasks may overdraw by 25%.
+	crit := senses.NewFromText("the production database is down")
+	crit.Priority = senses.PriorityCritical
+	if _, err := p.Run(context.Background(), *crit); errors.Is(err, ErrBudgetExhausted) {
+		t.Error("critical task refused within its overdraft")
+	}
+}
+
+func TestPipeline_RequestRouting(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	overrides, _ := brain.NewRoutingOverrides("")
+	deps.RoutingOverrides = overrides
+	p := New(deps)
+	models := deps.Router.Models()
+	want := models[len(models)-1]
+
+	input := senses.NewFromText("use the better model for this summary")
+	input.SourceMeta.Extra = map[string]string{
+		senses.ExtraModel:      want,
+		senses.ExtraComplexity: "simple",
+		senses.ExtraBudgetUSD:  "0.5",
+	}
+	ts := p.intake(*input)
+	p.applyRouting(ts, *input)
+	if ts.RequestedModel != want || ts.Complexity != "simple" || ts.BudgetUSD != 0.5 {
+		t.Fatalf("task routing: model=%q complexity=%q budget=%v", ts.RequestedModel, ts.Complexity, ts.BudgetUSD)
+	}
+	if len(overrides.List()) != 0 {
+		t.Error("request routing should not be learned as an escalation")
+	}
+	var cost float64
+	if _, err := p.executeLLM(context.Background(), ts, &cost); err != nil {
+		t.Fatal(err)
+	}
+	if ts.Model != want {
+		t.Errorf("model = %s, want the requested %s", ts.Model, want)
+	}
+
+	// Unknown values are ignored.
+	bad := senses.NewFromText("summarize the news")
+	bad.SourceMeta.Extra = map[string]string{senses.ExtraModel: "nope", senses.ExtraComplexity: "hard"}
+	ts = p.intake(*bad)
+	p.applyRouting(ts, *bad)
+	if ts.RequestedModel != "" || ts.Complexity == "hard" {
+		t.Errorf("task routing: model=%q complexity=%q", ts.RequestedModel, ts.Complexity)
+	}
+
+	// A task whose own budget cannot cover it is refused.
+	tiny := senses.NewFromText("summarize the news")
+	tiny.SourceMeta.Extra = map[string]string{senses.ExtraBudgetUSD: "0.001"}
+	res, err := p.Run(context.Background(), *tiny)
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("err = %v, want ErrBudgetExhausted", err)
+	}
+	if res == nil || !strings.Contains(res.Result, "budget_usd") {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestPipeline_PriorityPolicy(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	deps.Router.SetProvider("claude")
+	deps.Budget = budget.New(1.0, 0)
+	deps.Budget.Record("earlier", 0.9)
+	deps.PriorityPolicy = budget.DefaultPolicy()
+	p := New(deps)
+
+	// Low priority waits while less than 20% of the budget remains.
+	low := senses.NewFromText("tidy up the notes folder")
+	low.Priority = senses.PriorityLow
+	if _, err := p.Run(context.Background(), *low); !errors.Is(err, ErrDeferred) {
+		t.Fatalf("low priority: err = %v, want ErrDeferred", err)
+	}
+
+	// Critical runs on the powerful tier despite the tight budget.
+	crit := senses.NewFromText("the production database is down")
+	crit.Priority = senses.PriorityCritical
+	ts := p.intake(*crit)
+	p.applyRouting(ts, *crit)
+	var cost float64
+	if _, err := p.executeLLM(context.Background(), ts, &cost); err != nil {
+		t.Fatal(err)
+	}
+	if deps.Router.TierOf(ts.Model) != brain.TierPowerful {
+		t.Errorf("critical model = %s, want a powerful-tier model", ts.Model)
+	}
+
+	// Heartbeats count as low priority for spending.
+	if got := policyPriority(*senses.NewFromText("x")); got != "normal" {
+		t.Errorf("policyPriority(text) = %q", got)
+	}
+	hb := senses.UnifiedInput{SourceType: senses.SourceTimer, Priority: senses.PriorityCritical}
+	if got := policyPriority(hb); got != "low" {
+		t.Errorf("policyPriority(timer) = %q, want low", got)
+	}
+}
+
+// quotaLLM reports a fixed rate-limit state for the wrapped provider.
+type quotaLLM struct {
+	brain.LLMProvider
+	q brain.Quota
+}
+
+func (l quotaLLM) Quota() (brain.Quota, bool) { return l.q, true }
+
+func TestPipeline_QuotaThrottle(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	deps.Router.SetProvider("claude")
+	reset := time.Now().Add(40 * time.Second)
+	deps.LLM = quotaLLM{deps.LLM, brain.Quota{RequestsLimit: 100, RequestsRemaining: 5, RequestsReset: reset}}
+	deps.PriorityPolicy = budget.DefaultPolicy()
+	p := New(deps)
+
+	low := senses.NewFromText("tidy up the notes folder")
+	low.Priority = senses.PriorityLow
+	if _, err := p.Run(context.Background(), *low); !errors.Is(err, ErrThrottled) {
+		t.Fatalf("low priority: err = %v, want ErrThrottled", err)
+	}
+	if got := p.QuotaResetAt(); !got.Equal(reset) {
+		t.Errorf("QuotaResetAt = %v, want %v", got, reset)
+	}
+	if _, err := p.Run(context.Background(), *senses.NewFromText("what's on my calendar?")); err != nil {
+		t.Errorf("normal priority throttled: %v", err)
+	}
+}
+
+func TestParseInterpretations(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"GOAL: x\nAMBIGUITY: Python the language | python the snake", []string{"Python the language", "python the snake"}},
+		{"GOAL: x\nambiguity: none", nil},
+		{"GOAL: x\nAMBIGUITY: a | b | c", nil},
+		{"GOAL: x\nAMBIGUITY: same | Same", nil},
+		{"GOAL: x", nil},
+	}
+	for _, c := range cases {
+		got := parseInterpretations(c.in)
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("parseInterpretations(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+// speculationDeps wires a fake provider whose clarifier finds two readings
+// of "how do I care for my python".
+func speculationDeps(t *testing.T, choice string) (Dependencies, *brain.FakeProvider) {
+	t.Helper()
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{Rules: []brain.FakeRule{
+		{Match: "Decide which reading", Response: "CHOICE: " + choice},
+		{Match: "Clarify this task", Response: "GOAL: care instructions\nAMBIGUITY: Python the language | python the snake"},
+		{Match: "Interpret this as: Python the language", Response: "Keep your interpreter updated."},
+		{Match: "Interpret this as: python the snake", Response: "Feed it mice weekly."},
+	}})
+	deps.LLM = fake
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	deps.SpeculationMultiplier = 2
+	return deps, fake
+}
+
+func TestPipeline_SpeculativeExecution(t *testing.T) {
+	deps, _ := speculationDeps(t, "B")
+	p := New(deps)
+
+	rr, err := p.Run(context.Background(), *senses.NewFromText("how do I care for my python"))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if rr.Result != "Feed it mice weekly." {
+		t.Errorf("result = %q, want the snake reading", rr.Result)
+	}
+	var review StageLog
+	for _, sl := range rr.StageLogs {
+		if sl.Name == "review" {
+			review = sl
+		}
+	}
+	if !strings.Contains(review.Summary, "interpretation=picked") {
+		t.Errorf("review summary = %q", review.Summary)
+	}
+
+	// An undecided reviewer presents both answers.
+	deps, _ = speculationDeps(t, "BOTH")
+	rr, err = New(deps).Run(context.Background(), *senses.NewFromText("how do I care for my python"))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(rr.Result, "Keep your interpreter updated.") || !strings.Contains(rr.Result, "Feed it mice weekly.") {
+		t.Errorf("result = %q, want both answers", rr.Result)
+	}
+}
+
+func TestPipeline_SpeculationBoundedByCost(t *testing.T) {
+	deps, fake := speculationDeps(t, "A")
+	deps.Router = brain.NewModelRouterWithModels([]brain.ModelEntry{
+		{ID: "fake-cheap", Provider: "fake", Tier: brain.TierCheap, CostPer1K: 0.001},
+		{ID: "fake-mid", Provider: "fake", Tier: brain.TierMid, CostPer1K: 0.001},
+	})
+	deps.Budget = budget.New(10, 100) // enough that execution stays on the mid tier
+	deps.SpeculationMultiplier = 1.5  // two cheap runs cost 2x a mid run here
+	p := New(deps)
+
+	rr, err := p.Run(context.Background(), *senses.NewFromText("how do I care for my python"))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if strings.Contains(rr.Result, "interpreter") || strings.Contains(rr.Result, "mice") {
+		t.Errorf("speculated despite the cost bound: %q", rr.Result)
+	}
+	calls := fake.Calls()
+
+	// Cheaper cheap models bring it within the bound.
+	deps.Router = brain.NewModelRouterWithModels([]brain.ModelEntry{
+		{ID: "fake-cheap", Provider: "fake", Tier: brain.TierCheap, CostPer1K: 0.0005},
+		{ID: "fake-mid", Provider: "fake", Tier: brain.TierMid, CostPer1K: 0.001},
+	})
+	rr, err = New(deps).Run(context.Background(), *senses.NewFromText("how do I care for my python"))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if rr.Result != "Keep your interpreter updated." || fake.Calls() == calls {
+		t.Errorf("result = %q", rr.Result)
+	}
+}
+
+func TestPipeline_WarmStart(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	deps.WarmStart = NewWarmStart(3)
+	deps.WarmStart.Reuses = 2
+	p := New(deps)
+
+	// Runs 1-3 plan normally (the third caches its plan), 4-5 reuse it,
+	// and 6 refreshes it after two reuses.
+	wantWarm := []bool{false, false, false, true, true, false, true}
+	for i, want := range wantWarm {
+		result, err := p.Run(context.Background(), senses.UnifiedInput{
+			InputID:    fmt.Sprintf("input_warm_%d", i),
+			SourceType: senses.SourceText,
+			Payload:    "Summarise today's support tickets",
+		})
+		if err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		warm := false
+		for _, sl := range result.StageLogs {
+			if sl.Summary == "warm_start" {
+				warm = true
+			}
+		}
+		if warm != want {
+			t.Errorf("run %d: warm start = %v, want %v (stages %+v)", i+1, warm, want, result.StageLogs)
+		}
+		if len(result.StageLogs) != 10 {
+			t.Errorf("run %d: %d stage logs", i+1, len(result.StageLogs))
+		}
+	}
+
+	// Another sender has no cached plan yet.
+	in := senses.UnifiedInput{InputID: "input_warm_other", SourceType: senses.SourceText, Payload: "Summarise today's support tickets"}
+	in.SourceMeta.Sender = "someone-else"
+	result, err := p.Run(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StageLogs[1].Summary == "warm_start" {
+		t.Error("warm start leaked to another sender")
+	}
+}
+
+func TestWarmStart_DropsLowQualityAndStalePlans(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	w := NewWarmStart(2)
+	w.now = func() time.Time { return now }
+
+	ts := NewTaskSpec("task_1", "weekly report")
+	ts.Fingerprint = "fp"
+	ts.Context = "GOAL: weekly report"
+	ts.Subtasks = []SubtaskSpec{{ID: "task_1_sub1", Goal: "weekly report"}, {ID: "task_1_sub2", Goal: "send it", DependsOn: []string{"task_1_sub1"}}}
+	ts.QualityScore = 0.9
+	plan := w.capture(ts)
+
+	w.observe(ts, plan, false, 1, 0.9)
+	if w.Len() != 0 {
+		t.Fatal("plan cached before the pattern has enough runs")
+	}
+	w.observe(ts, plan, false, 2, 0.7)
+	if w.Len() != 0 {
+		t.Fatal("plan cached for a pattern with low average quality")
+	}
+	w.observe(ts, plan, false, 2, 0.9)
+
+	next := NewTaskSpec("task_2", "weekly report")
+	next.Fingerprint = "fp"
+	if !w.apply(next) {
+		t.Fatal("expected a warm start")
+	}
+	if next.Context != ts.Context || next.Status != TaskStatusPlanned || len(next.Subtasks) != 2 ||
+		next.Subtasks[1].ID != "task_2_sub2" || next.Subtasks[1].DependsOn[0] != "task_2_sub1" {
+		t.Errorf("warm task = %+v", next)
+	}
+
+	// A warm run that reviews badly drops the plan.
+	next.QualityScore = 0.4
+	w.observe(next, nil, true, 3, 0.8)
+	if w.Len() != 0 || w.apply(NewTaskSpec("task_3", "weekly report")) {
+		t.Error("low-quality warm run should drop the plan")
+	}
+
+	// Plans expire after MaxAge.
+	w.observe(ts, w.capture(ts), false, 4, 0.9)
+	now = now.Add(DefaultWarmStartMaxAge)
+	stale := NewTaskSpec("task_4", "weekly report")
+	stale.Fingerprint = "fp"
+	if w.apply(stale) || w.Len() != 0 {
+		t.Error("stale plan was reused")
+	}
+}
+
+func TestParseTopicDirective(t *testing.T) {
+	for _, tc := range []struct {
+		in                string
+		kind, topic, rest string
+	}{
+		{"switch to topic 'Novel Draft'", "switch", "novel-draft", ""},
+		{"Use topic health. How did I sleep?", "switch", "health", "How did I sleep?"},
+		{"topic: work", "switch", "work", ""},
+		{"leave the topic", "clear", "", ""},
+		{"The villain is the uncle — remember this under 'novel'", "remember", "novel", "The villain is the uncle"},
+		{"what do you remember about topic health?", "recall", "health", ""},
+		{"recall from topic work: deadlines for Q3", "recall", "work", "deadlines for Q3"},
+		{"remember this for tomorrow", "", "", ""},
+		{"switch to dark mode", "", "", ""},
+	} {
+		d := parseTopicDirective(tc.in)
+		if d.kind != tc.kind || d.topic != tc.topic || d.rest != tc.rest {
+			t.Errorf("parseTopicDirective(%q) = %+v, want {%s %s %q}", tc.in, d, tc.kind, tc.topic, tc.rest)
+		}
+	}
+}
+
+func TestPipeline_MemoryTopics(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	base := mockLLMServer(t)
+	defer base.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(b))
+		base.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	p := New(deps)
+	run := func(sender, text string) *RunResult {
+		t.Helper()
+		in := senses.UnifiedInput{InputID: "in_" + sender, SourceType: senses.SourceTelegram, Payload: text}
+		in.SourceMeta.Sender = sender
+		mu.Lock()
+		bodies = nil
+		mu.Unlock()
+		res, err := p.Run(context.Background(), in)
+		if err != nil {
+			t.Fatalf("Run(%q): %v", text, err)
+		}
+		return res
+	}
+	sawMemory := func(substr string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, b := range bodies {
+			if strings.Contains(b, "[Relevant Memory]") && strings.Contains(b, substr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	res := run("alice", "switch to topic 'Novel Draft'")
+	if !strings.Contains(res.Result, `"novel-draft"`) || len(bodies) != 0 {
+		t.Errorf("switch: result %q after %d LLM calls", res.Result, len(bodies))
+	}
+	if got := p.ActiveTopic("TELEGRAM/alice"); got != "novel-draft" {
+		t.Fatalf("ActiveTopic = %q", got)
+	}
+
+	run("alice", "The villain is the uncle — remember this under topic novel-draft")
+	notes, _ := deps.LongTerm.SearchTopic("novel-draft", "villain", 10)
+	if len(notes) == 0 || notes[0].Summary != "The villain is the uncle" || notes[0].Owner != "alice" {
+		t.Fatalf("note not filed under the topic: %+v", notes)
+	}
+
+	res = run("alice", "Who is the villain again?")
+	if !sawMemory("The villain is the uncle") {
+		t.Error("active topic should recall the note into the execution context")
+	}
+	cited := false
+	for _, src := range res.Sources {
+		if src.ID == notes[0].ID && src.Topic == "novel-draft" && src.Via == "topic" && src.Summary == "The villain is the uncle" {
+			cited = true
+		}
+	}
+	if !cited {
+		t.Errorf("Sources = %+v, want the recalled note cited", res.Sources)
+	}
+	if n, _ := deps.LongTerm.Topics(); len(n) != 1 || n[0].Entries < 3 {
+		t.Errorf("Topics = %+v, want novel-draft with the runs and note", n)
+	}
+
+	// Bob has no active topic, and alice's private note is not his to see.
+	run("bob", "what do you remember about topic novel-draft")
+	if sawMemory("The villain is the uncle") {
+		t.Error("bob recalled alice's private note")
+	}
+
+	run("alice", "leave the topic")
+	if got := p.ActiveTopic("TELEGRAM/alice"); got != "" {
+		t.Errorf("ActiveTopic after leaving = %q", got)
+	}
+	run("alice", "Who is the villain again?")
+	if sawMemory("villain") {
+		t.Error("recall should stop once the topic is left")
+	}
+}
+
+// promptLog records the requests sent to the wrapped provider.
+type promptLog struct {
+	brain.LLMProvider
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (l *promptLog) Complete(ctx context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+	var b strings.Builder
+	for _, m := range req.Messages {
+		b.WriteString(m.Content + "\n")
+	}
+	l.mu.Lock()
+	l.prompts = append(l.prompts, b.String())
+	l.mu.Unlock()
+	return l.LLMProvider.Complete(ctx, req)
+}
+
+// sent returns the prompts containing substr.
+func (l *promptLog) sent(substr string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []string
+	for _, p := range l.prompts {
+		if strings.Contains(p, substr) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func TestPipeline_Entities(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{Rules: append([]brain.FakeRule{
+		{Match: "List the people and organizations", Response: "ENTITY: person | Anna Petrova | Anna@Example.com | colleague | informal, first names\nENTITY: organization | Acme GmbH | - | supplier | -"},
+	}, brain.DefaultFakeRules()...), Default: "Done."})
+	llm := &promptLog{LLMProvider: fake}
+	deps.LLM = llm
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	entities, err := memory.NewEntityStore(deps.LongTerm.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Entities = entities
+	p := New(deps)
+
+	run := func(sender, text string) *RunResult {
+		t.Helper()
+		in := senses.UnifiedInput{InputID: "in_" + text, SourceType: senses.SourceTelegram, Payload: text}
+		in.SourceMeta.Sender = sender
+		res, err := p.Run(context.Background(), in)
+		if err != nil {
+			t.Fatalf("Run(%q): %v", text, err)
+		}
+		return res
+	}
+
+	run("alice", "Draft a note to Anna Petrova about the Acme GmbH order")
+	anna, err := entities.Get("alice", "anna petrova")
+	if err != nil || anna == nil || anna.Email != "anna@example.com" || anna.Relationship != "colleague" || anna.LastInteraction.IsZero() {
+		t.Fatalf("Anna not learned: %+v, %v", anna, err)
+	}
+	if acme, _ := entities.Get("alice", "Acme GmbH"); acme == nil || acme.Kind != memory.EntityOrganization {
+		t.Errorf("Acme not learned: %+v", acme)
+	}
+	if mentions, _ := entities.AllMentions(anna.ID, 5); len(mentions) != 1 {
+		t.Errorf("Anna should be linked to the first run, got %+v", mentions)
+	}
+
+	// "reply to anna" resolves to the known contact, with her tone.
+	res := run("alice", "reply to anna that the order shipped")
+	if len(res.Sources) != 1 || res.Sources[0].Via != "Anna Petrova" || res.Sources[0].Channel != "TELEGRAM" {
+		t.Errorf("Sources = %+v, want the first run cited via Anna", res.Sources)
+	}
+	exec := llm.sent("[contact] Anna Petrova <anna@example.com>")
+	if len(exec) == 0 || !strings.Contains(exec[0], "prefers: informal, first names") {
+		t.Fatalf("execution context should carry Anna's contact card; prompts: %v", llm.sent("reply to anna"))
+	}
+	if mentions, _ := entities.AllMentions(anna.ID, 5); len(mentions) != 2 {
+		t.Errorf("second run should be linked too, got %d mentions", len(mentions))
+	}
+	if n := len(llm.sent("List the people and organizations")); n != 1 {
+		t.Errorf("extraction ran %d times; a lower-case known name needs none", n)
+	}
+
+	// Alice's contact book is hers.
+	run("bob", "reply to anna that the order shipped")
+	if got := len(llm.sent("[contact] Anna Petrova")); got != 1 {
+		t.Errorf("bob saw alice's contact (%d prompts with the card)", got)
+	}
+}
+
+func TestPipeline_SemanticMemory(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{Default: "Done."})
+	llm := &promptLog{LLMProvider: fake}
+	deps.LLM = llm
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	vs, err := memory.NewVectorStore(deps.LongTerm.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Semantic = memory.NewSemanticMemory(fake, vs, deps.LongTerm)
+	deps.LongTerm.Store(memory.LongTermEntry{ID: "m_tyres", Summary: "Winter tyres are stored at the garage on Elm Street", CreatedAt: time.Now()})
+	deps.LongTerm.Store(memory.LongTermEntry{ID: "m_other", Summary: "Quarterly report sent to finance", CreatedAt: time.Now()})
+	p := New(deps)
+
+	res, err := p.Run(context.Background(), senses.UnifiedInput{InputID: "in_1", SourceType: senses.SourceText, Payload: "where are my winter tyres stored"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Sources) != 1 || res.Sources[0].ID != "m_tyres" || res.Sources[0].Via != "related" {
+		t.Errorf("Sources = %+v, want the tyres memory", res.Sources)
+	}
+	if len(llm.sent("Elm Street")) == 0 {
+		t.Error("execution context should carry the related memory")
+	}
+	if len(llm.sent("Quarterly report")) != 0 {
+		t.Error("unrelated memory recalled")
+	}
+}
+
+func TestMayMentionEntities(t *testing.T) {
+	for text, want := range map[string]bool{
+		"Reply to Anna about the budget":  true,
+		"forward this to ops@example.com": true,
+		"What's the weather? Tomorrow?":   false,
+		"Summarise my notes. I'm tired.":  false,
+		"email «Acme» about the invoice":  true,
+	} {
+		if got := mayMentionEntities(text); got != want {
+			t.Errorf("mayMentionEntities(%q) = %v, want %v", text, got, want)
+		}
+	}
+	got := parseEntities("ENTITY: person | Anna | not-an-email | - | formal\nENTITY: robot | R2 | - | - | -\nNONE")
+	if len(got) != 1 || got[0].Name != "Anna" || got[0].Email != "" || got[0].Relationship != "" || got[0].Preferences != "formal" {
+		t.Errorf("parseEntities = %+v", got)
+	}
+}
+
+func TestPipeline_Translate(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{Rules: []brain.FakeRule{
+		{Match: "from German into English", Response: "  Please send me the report.  "},
+	}, Default: "?"})
+	llm := &promptLog{LLMProvider: fake}
+	deps.LLM = llm
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	deps.Budget = budget.New(10, 100)
+	p := New(deps)
+
+	var _ senses.Translator = p
+	got, err := p.Translate(context.Background(), "Bitte schick mir den Bericht.", "de", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Please send me the report." {
+		t.Errorf("Translate = %q", got)
+	}
+	if len(llm.sent("Bitte schick mir den Bericht.")) != 1 {
+		t.Error("the text to translate was not sent")
+	}
+	if deps.Budget.TaskSpend("preprocess") != deps.Budget.TotalSpend() {
+		t.Error("translation cost should be recorded under preprocess")
+	}
+}
+
+func TestPipeline_NewSession(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	deps.Sessions = SessionPolicy{Summarize: true}
+	p := New(deps)
+	run := func(text string) *RunResult {
+		t.Helper()
+		in := senses.UnifiedInput{InputID: "in", SourceType: senses.SourceText, Payload: text, SessionID: "cli_1"}
+		in.SourceMeta.Sender = "alice"
+		res, err := p.Run(context.Background(), in)
+		if err != nil {
+			t.Fatalf("Run(%q): %v", text, err)
+		}
+		return res
+	}
+
+	run("Plan a trip to Lisbon")
+	if n := len(deps.ShortTerm.GetRecentBySession(10, "cli_1")); n != 2 {
+		t.Fatalf("history = %d entries, want 2", n)
+	}
+	res := run("/new")
+	if !res.Success || !strings.HasPrefix(res.Result, "Started a new conversation. The previous one was saved to memory:") {
+		t.Errorf("/new result = %q", res.Result)
+	}
+	if deps.ShortTerm.Len() != 0 {
+		t.Errorf("history survived /new: %+v", deps.ShortTerm.GetAll())
+	}
+	all, _ := deps.LongTerm.GetAll(50)
+	summarized := false
+	for _, e := range all {
+		if strings.HasPrefix(e.Summary, "Conversation summary:") && e.Owner == "alice" {
+			summarized = true
+		}
+	}
+	if !summarized {
+		t.Errorf("no session summary stored: %+v", all)
+	}
+
+	run("And the hotels?")
+	if n := len(deps.ShortTerm.GetRecentBySession(10, "cli_1#2")); n != 2 {
+		t.Errorf("new session history = %d entries, want 2", n)
+	}
+
+	// Stateless requests neither read nor write history.
+	in := senses.UnifiedInput{InputID: "in", SourceType: senses.SourceAPI, Payload: "One-off question", SessionID: "cli_1"}
+	in.SourceMeta.Extra = map[string]string{"stateless": "true"}
+	if _, err := p.Run(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+	if deps.ShortTerm.Len() != 2 {
+		t.Errorf("stateless request wrote history: %d entries", deps.ShortTerm.Len())
+	}
+}
+
+func TestSessionTracker_IdleRollover(t *testing.T) {
+	var s sessionTracker
+	now := time.Now()
+	if id, ended := s.current("ws_1", now, time.Hour); id != "ws_1" || ended != "" {
+		t.Fatalf("first = %q, %q", id, ended)
+	}
+	if id, ended := s.current("ws_1", now.Add(30*time.Minute), time.Hour); id != "ws_1" || ended != "" {
+		t.Errorf("within idle = %q, %q", id, ended)
+	}
+	if id, ended := s.current("ws_1", now.Add(2*time.Hour), time.Hour); id != "ws_1#2" || ended != "ws_1" {
+		t.Errorf("after idle = %q, %q", id, ended)
+	}
+	if id, _ := s.current("ws_1", now.Add(48*time.Hour), 0); id != "ws_1#2" {
+		t.Errorf("idle rollover disabled, got %q", id)
+	}
+	if ended := s.reset("ws_1"); ended != "ws_1#2" {
+		t.Errorf("reset ended %q", ended)
+	}
+	if id, ended := s.current("ws_1", now.Add(49*time.Hour), time.Hour); id != "ws_1#3" || ended != "" {
+		t.Errorf("after reset = %q, %q", id, ended)
+	}
+}
+
+func TestPipeline_SenderSessions(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	p := New(deps)
+	run := func(sender, text string) {
+		t.Helper()
+		in := senses.UnifiedInput{InputID: "in", SourceType: senses.SourceAPI, Payload: text}
+		in.SourceMeta.Sender = sender
+		if _, err := p.Run(context.Background(), in); err != nil {
+			t.Fatalf("Run(%q): %v", text, err)
+		}
+	}
+
+	run("alice", "My flight is on Friday")
+	run("bob", "What is on my calendar?")
+	alice := deps.ShortTerm.GetRecentBySession(10, "API/alice")
+	if len(alice) != 2 || alice[0].Content != "My flight is on Friday" {
+		t.Errorf("alice's history = %+v", alice)
+	}
+	for _, e := range deps.ShortTerm.GetRecentBySession(10, "API/bob") {
+		if strings.Contains(e.Content, "flight") {
+			t.Errorf("bob's history has alice's message: %+v", e)
+		}
+	}
+	if got := p.Sessions(); len(got) != 2 || got[0].ID != "API/bob" || got[0].Sender != "bob" {
+		t.Errorf("Sessions() = %+v", got)
+	}
+
+	if _, err := p.EndSession(context.Background(), "API/alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.EndSession(context.Background(), "API/alice"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("ending an ended session: err = %v", err)
+	}
+	run("alice", "Which day was my flight?")
+	if got := deps.ShortTerm.GetRecentBySession(10, "API/alice#2"); len(got) != 2 {
+		t.Errorf("alice's new session = %+v", got)
+	}
+}
+
+func TestSessionTracker_Expire(t *testing.T) {
+	var s sessionTracker
+	now := time.Now()
+	s.current("ws_1", now, time.Hour)
+	s.current("ws_2", now.Add(90*time.Minute), time.Hour)
+	if got := s.expire(now.Add(2*time.Hour), 0); got != nil {
+		t.Errorf("expire without TTL = %v", got)
+	}
+	if got := s.expire(now.Add(2*time.Hour), time.Hour); len(got) != 1 || got[0] != "ws_1" {
+		t.Errorf("expire = %v, want the idle session only", got)
+	}
+	if got := s.expire(now.Add(3*time.Hour), time.Hour); len(got) != 1 || got[0] != "ws_2" {
+		t.Errorf("second expire = %v", got)
+	}
+	if id, ended := s.current("ws_1", now.Add(4*time.Hour), time.Hour); id != "ws_1#2" || ended != "" {
+		t.Errorf("after expiry = %q, %q", id, ended)
+	}
+}
+
+// recordingRunner answers delegated tasks and keeps what it was given.
+type recordingRunner struct{ got instruments.DelegatedTask }
+
+func (r *recordingRunner) RunTask(_ context.Context, _ string, task instruments.DelegatedTask) (*instruments.DelegationResult, error) {
+	r.got = task
+	return &instruments.DelegationResult{Output: "3 flights found", Success: true, Quality: 0.9, CostUSD: 0.01}, nil
+}
+
+func TestPipeline_SubagentHandoff(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+
+	deps := setupDeps(t, srv.URL)
+	runner := &recordingRunner{}
+	deps.SubagentMgr = instruments.NewSubagentManager(runner)
+	deps.LongTerm.Store(memory.LongTermEntry{ID: "m1", Summary: "Prefers aisle seats on flights", CreatedAt: time.Now()})
+	deps.LongTerm.Store(memory.LongTermEntry{ID: "m2", Summary: "Cat is called Mia", CreatedAt: time.Now()})
+	p := New(deps)
+
+	ts := NewTaskSpec("task_1", "plan my trip to Lisbon")
+	ts.Context = strings.Repeat("The trip is in May and the budget is tight. ", 60)
+	ts.Constraints = []string{"under $500"}
+	ts.Subtasks = []SubtaskSpec{{ID: "s1", Goal: "find flights to Lisbon", AssignedTo: "agent:travel"}}
+	var cost float64
+	out, err := p.executeSubtask(context.Background(), ts, &ts.Subtasks[0], &cost)
+	if err != nil || out != "3 flights found" {
+		t.Fatalf("executeSubtask = %q, %v", out, err)
+	}
+
+	h := runner.got.Handoff
+	if h == nil || h.ParentTaskID != "task_1" || h.ParentGoal != ts.Goal {
+		t.Fatalf("handoff = %+v", h)
+	}
+	if len(h.Summary) >= len(ts.Context) || h.Summary == "" {
+		t.Errorf("long context was not summarized: %d chars", len(h.Summary))
+	}
+	if len(h.Memory) != 1 || !strings.Contains(h.Memory[0], "aisle seats") {
+		t.Errorf("memory = %q", h.Memory)
+	}
+	if len(h.Constraints) != 1 || !strings.Contains(h.ExpectedOutput, "find flights to Lisbon") {
+		t.Errorf("constraints = %q, expected output = %q", h.Constraints, h.ExpectedOutput)
+	}
+	if runner.got.Context != h.Brief() || !strings.Contains(runner.got.Context, "- under $500") {
+		t.Errorf("context = %q", runner.got.Context)
+	}
+
+	pv := ts.Subtasks[0].Provenance
+	if pv == nil || pv.AgentID != "travel" || pv.ParentTaskID != "task_1" || pv.DelegationID == "" {
+		t.Errorf("provenance = %+v", pv)
+	}
+	if ts.Subtasks[0].QualityScore != 0.9 || cost <= 0.01 {
+		t.Errorf("quality %.2f, cost %.4f", ts.Subtasks[0].QualityScore, cost)
+	}
+}
+
+func TestPipeline_OnPartial(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	fake := brain.NewFakeProvider(brain.FakeConfig{Default: "Knead the dough for ten minutes."})
+	deps.LLM = fake
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	p := New(deps)
+
+	var chunks []string
+	var events []PartialEvent
+	p.OnPartial(func(e PartialEvent) {
+		events = append(events, e)
+		chunks = append(chunks, e.Chunk)
+	})
+	in := senses.NewFromText("how do I bake bread")
+	in.CorrelationID = "req-1"
+	rr, err := p.Run(context.Background(), *in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(events) < 3 || !events[len(events)-1].Done {
+		t.Fatalf("events = %+v", events)
+	}
+	if got := strings.Join(chunks, ""); got != rr.Result {
+		t.Errorf("streamed %q, result %q", got, rr.Result)
+	}
+	for _, e := range events {
+		if e.TaskID != rr.TaskID || e.CorrelationID != "req-1" {
+			t.Errorf("event for task %q (%q), want %q (req-1)", e.TaskID, e.CorrelationID, rr.TaskID)
+		}
+	}
+
+	// Speculative branches run side by side and are not streamed.
+	deps, _ = speculationDeps(t, "B")
+	p = New(deps)
+	events = nil
+	p.OnPartial(func(e PartialEvent) { events = append(events, e) })
+	if _, err := p.Run(context.Background(), *senses.NewFromText("how do I care for my python")); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("speculation streamed %+v", events)
+	}
+}
+
+func TestParseResume(t *testing.T) {
+	for _, tc := range []struct {
+		in           string
+		explicit, ok bool
+	}{
+		{"continue", false, true},
+		{"Resume.", false, true},
+		{"continue where you left off", true, true},
+		{"Please pick up where we stopped!", true, true},
+		{"resume the interrupted task", true, true},
+		{"continue the essay about cats", false, false},
+		{"can you continue?", false, false},
+	} {
+		explicit, ok := parseResume(tc.in)
+		if explicit != tc.explicit || ok != tc.ok {
+			t.Errorf("parseResume(%q) = %v, %v, want %v, %v", tc.in, explicit, ok, tc.explicit, tc.ok)
+		}
+	}
+}
+
+func TestPipeline_ResumeInterruptedRun(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	runs, err := memory.NewRunBuffer(deps.LongTerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Runs = runs
+	deps.Skills = instruments.NewSkillRegistry()
+	deps.Skills.Register(&instruments.Skill{
+		Meta:     instruments.SkillMeta{ID: "lookup", Name: "Lookup", Status: instruments.SkillStatusActive},
+		Executor: &countingSkill{},
+	})
+	broken := brain.NewFakeProvider(brain.FakeConfig{ErrorRate: 1})
+	deps.LLM = broken
+	deps.Router = brain.NewModelRouterWithModels(broken.ModelEntries())
+
+	// A two-step run: the lookup succeeds, the write-up fails.
+	in := senses.UnifiedInput{InputID: "in_1", SourceType: senses.SourceTelegram, Payload: "research and write up the options"}
+	in.SourceMeta.Sender = "alice"
+	p := New(deps)
+	ts := p.intake(in)
+	ts.Subtasks = []SubtaskSpec{
+		{ID: "s1", Goal: "look up the options", AssignedTo: "skill:lookup"},
+		{ID: "s2", Goal: "write up the options", AssignedTo: "self", DependsOn: []string{"s1"}},
+	}
+	var cost float64
+	_, err = p.execute(context.Background(), ts, &cost)
+	if err == nil {
+		t.Fatal("execute should fail")
+	}
+	rr := p.failResult(ts, time.Now(), cost, err, nil)
+	if !strings.Contains(rr.Result, "continue where I left off") {
+		t.Errorf("failure reply = %q", rr.Result)
+	}
+
+	// "continue where you left off" runs the task again with the findings.
+	fake := brain.NewFakeProvider(brain.FakeConfig{Rules: brain.DefaultFakeRules(), Default: "Here is the write-up."})
+	llm := &promptLog{LLMProvider: fake}
+	deps.LLM = llm
+	deps.Router = brain.NewModelRouterWithModels(fake.ModelEntries())
+	p = New(deps)
+	in.InputID, in.Payload = "in_2", "continue where you left off"
+	res, err := p.Run(context.Background(), in)
+	if err != nil || !res.Success {
+		t.Fatalf("Run: %+v, %v", res, err)
+	}
+	if len(llm.sent("research and write up the options")) == 0 {
+		t.Error("the resumed run should work on the interrupted task")
+	}
+	exec := llm.sent("## look up the options\nskill ran")
+	if len(exec) == 0 {
+		t.Error("the execution context should carry the earlier findings")
+	}
+	if prev, _ := runs.Latest(memory.Viewer{Sender: "alice", Channel: "TELEGRAM"}); prev != nil {
+		t.Errorf("finished run still offered for resuming: %+v", prev)
+	}
+	all, _ := deps.LongTerm.GetAll(20)
+	var findings int
+	for _, e := range all {
+		if e.SourceRunID == res.TaskID && e.Tags[0] == "finding" {
+			findings++
+		}
+	}
+	if findings != 2 {
+		t.Errorf("%d findings promoted, want the carried one and the new one", findings)
+	}
+}
+
+func TestTaskQueue(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	q, err := NewTaskQueue(deps.LongTerm.DB(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := senses.NewFromText("first")
+	second := senses.NewFromText("second")
+	second.SourceMeta.Sender = "alice"
+	for _, in := range []*senses.UnifiedInput{first, second} {
+		if err := q.Enqueue(in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Start(first.InputID)
+	q.Start(second.InputID)
+	q.Complete(first.InputID)
+	q.Enqueue(second) // a retry keeps its attempts
+	if q.Len() != 1 {
+		t.Fatalf("Len = %d", q.Len())
+	}
+
+	// After a restart the unfinished input is replayed as it was accepted.
+	reopened, err := NewTaskQueue(deps.LongTerm.DB(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, dropped, err := reopened.Pending()
+	if err != nil || len(replay) != 1 || len(dropped) != 0 {
+		t.Fatalf("Pending = %+v, %+v, %v", replay, dropped, err)
+	}
+	if got := replay[0]; got.Input.InputID != second.InputID || got.Input.SourceMeta.Sender != "alice" || got.Attempts != 1 {
+		t.Errorf("replayed %+v", got)
+	}
+
+	// An input that keeps failing to finish is dropped.
+	reopened.Start(second.InputID)
+	replay, dropped, _ = reopened.Pending()
+	if len(replay) != 0 || len(dropped) != 1 || reopened.Len() != 0 {
+		t.Errorf("after max attempts: replay %d, dropped %d, len %d", len(replay), len(dropped), reopened.Len())
+	}
+
+	// Holding an input (standby, deferral, approval) is not an attempt.
+	held := senses.NewFromText("held")
+	reopened.Enqueue(held)
+	for i := 0; i < 4; i++ {
+		reopened.Start(held.InputID)
+		reopened.Hold(held.InputID)
+	}
+	replay, dropped, _ = reopened.Pending()
+	if len(replay) != 1 || len(dropped) != 0 || replay[0].Input.InputID != held.InputID || replay[0].Attempts != 0 {
+		t.Errorf("held input: replay %+v, dropped %+v", replay, dropped)
+	}
+
+	var off *TaskQueue
+	if err := off.Enqueue(first); err != nil || off.Len() != 0 {
+		t.Error("a nil queue should ignore calls")
+	}
+}
+
+func TestParseReview(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		score float64
+		notes string
+		ok    bool
+	}{
+		{`{"score": 0.72, "notes": "mostly right"}`, 0.72, "mostly right", true},
+		{"```json\n{\"score\": 1, \"notes\": \"perfect\"}\n```", 1, "perfect", true},
+		{"SCORE: 0.85\nNOTES: Task completed successfully.", 0.85, "Task completed successfully.", true},
+		{"**Score:** 7/10\n**Notes:** thin on sources", 0.7, "thin on sources", true},
+		{"score = 85%", 0.85, "", true},
+		{`{"score": 8, "notes": "great"}`, 0, "", false},
+		{"SCORE: 1.5", 0, "", false},
+		{"Looks good to me!", 0, "", false},
+	} {
+		score, notes, ok := parseReview(tc.in)
+		if ok != tc.ok || math.Abs(score-tc.score) > 1e-9 || notes != tc.notes {
+			t.Errorf("parseReview(%q) = %v, %q, %v, want %v, %q, %v", tc.in, score, notes, ok, tc.score, tc.notes, tc.ok)
+		}
+	}
+}
+
+// reviewReplies answers review requests with replies in turn.
+type reviewReplies struct {
+	brain.LLMProvider
+	replies []string
+	reqs    []brain.LLMRequest
+}
+
+func (r *reviewReplies) Complete(_ context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+	r.reqs = append(r.reqs, req)
+	reply := r.replies[0]
+	if len(r.replies) > 1 {
+		r.replies = r.replies[1:]
+	}
+	return &brain.LLMResponse{Content: reply, CostUSD: 0.001}, nil
+}
+
+func TestPipeline_ReviewRetriesUnparseable(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	llm := &reviewReplies{replies: []string{"Great work overall.", `{"score": 0.4, "notes": "misses the deadline"}`}}
+	deps.LLM = llm
+	p := New(deps)
+	ts := p.intake(*senses.NewFromText("plan the offsite"))
+
+	var cost float64
+	q, notes, err := p.review(context.Background(), ts, "an agenda", &cost)
+	if err != nil || q != 0.4 || notes != "misses the deadline" {
+		t.Fatalf("review = %.2f, %q, %v", q, notes, err)
+	}
+	if len(llm.reqs) != 2 || !llm.reqs[0].JSON || !strings.Contains(llm.reqs[1].Messages[len(llm.reqs[1].Messages)-1].Content, "could not be read") {
+		t.Errorf("requests = %+v", llm.reqs)
+	}
+
+	// Still unreadable after the retry: a neutral score, not a failed run.
+	llm.replies, llm.reqs = []string{"Great work overall."}, nil
+	if q, _, err := p.review(context.Background(), ts, "an agenda", &cost); err != nil || q != unparsedReviewScore || len(llm.reqs) != 2 {
+		t.Errorf("unparseable review = %.2f, %v after %d requests", q, err, len(llm.reqs))
+	}
+}
+
+func TestPipeline_ClarifyStructured(t *testing.T) {
+	deps := setupDeps(t, "http://127.0.0.1:0")
+	llm := &reviewReplies{replies: []string{`{"goal": "care for a pet python", "constraints": ["weekly", "indoors"], ` +
+		`"expected_output": "a checklist", "verification": "covers feeding", "ambiguity": ["Python the language", "python the snake"]}`}}
+	deps.LLM = llm
+	deps.SpeculationMultiplier = 2
+	p := New(deps)
+	ts := p.intake(*senses.NewFromText("how do I care for my python"))
+
+	var cost float64
+	if err := p.clarify(context.Background(), ts, &cost); err != nil {
+		t.Fatal(err)
+	}
+	want := "GOAL: care for a pet python\nCONSTRAINTS: weekly, indoors\nEXPECTED_OUTPUT: a checklist\nVERIFICATION: covers feeding"
+	if ts.Context != want || len(ts.Interpretations) != 2 || ts.Interpretations[1] != "python the snake" {
+		t.Errorf("context = %q, interpretations = %q", ts.Context, ts.Interpretations)
+	}
+	if len(llm.reqs) != 1 || llm.reqs[0].Schema == nil || !llm.reqs[0].JSON || cost != 0.001 {
+		t.Errorf("requests = %+v, cost %v", llm.reqs, cost)
+	}
+
+	// A reply in the older lines is kept as it is.
+	llm.replies, llm.reqs = []string{"GOAL: x\nAMBIGUITY: a | b"}, nil
+	if err := p.clarify(context.Background(), ts, &cost); err != nil || ts.Context != "GOAL: x\nAMBIGUITY: a | b" || len(ts.Interpretations) != 2 || len(llm.reqs) != 1 {
+		t.Errorf("line format: %q, %q, %v", ts.Context, ts.Interpretations, err)
+	}
+}
+
+func TestPipeline_PublishesEvents(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+	deps := setupDeps(t, srv.URL)
+	deps.Events = events.New()
+	var got []events.Event
+	deps.Events.Subscribe("*", func(ev events.Event) { got = append(got, ev) })
+	p := New(deps)
+
+	res, err := p.Run(context.Background(), *senses.NewFromText("summarize the week"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Topic != events.TaskStarted || got[1].Topic != events.TaskCompleted {
+		t.Fatalf("events = %+v", got)
+	}
+	if te := got[1].Data.(TaskEvent); te.TaskID != res.TaskID || te.Result != res || te.Input.Payload != "summarize the week" {
+		t.Errorf("task.completed data = %+v", te)
+	}
+
+	// Deferral is not an outcome.
+	got = nil
+	deps.Budget = budget.New(1.0, 0)
+	deps.Budget.Record("earlier", 0.9)
+	deps.PriorityPolicy = budget.DefaultPolicy()
+	in := *senses.NewFromText("tidy the notes")
+	in.Priority = senses.PriorityLow
+	if _, err := New(deps).Run(context.Background(), in); !errors.Is(err, ErrDeferred) || len(got) != 0 {
+		t.Errorf("deferred run: %v, events %+v", err, got)
+	}
+}
+
+// decliningLLM declines the first n execution requests with a content
+// filter stop; everything else goes to the wrapped provider.
+type decliningLLM struct {
+	brain.LLMProvider
+	n    int
+	reqs []brain.LLMRequest
+}
+
+func (d *decliningLLM) Complete(ctx context.Context, req brain.LLMRequest) (*brain.LLMResponse, error) {
+	if req.MaxTokens != 4096 {
+		return d.LLMProvider.Complete(ctx, req)
+	}
+	d.reqs = append(d.reqs, req)
+	if len(d.reqs) > d.n {
+		return d.LLMProvider.Complete(ctx, req)
+	}
+	return &brain.LLMResponse{Content: "I can't help with that.", StopReason: brain.StopContentFilter, CostUSD: 0.001}, nil
+}
+
+func TestPipeline_DeclinedExecution(t *testing.T) {
+	srv := mockLLMServer(t)
+	defer srv.Close()
+	deps := setupDeps(t, srv.URL)
+	llm := &decliningLLM{LLMProvider: deps.LLM, n: 2}
+	deps.LLM = llm
+	p := New(deps)
+
+	res, err := p.Run(context.Background(), *senses.NewFromText("describe the lock mechanism"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Declined || res.Success || res.QualityScore != 0 || !strings.Contains(res.Result, "declined this request") {
+		t.Errorf("result = %+v", res)
+	}
+	if len(llm.reqs) != 2 || !strings.Contains(llm.reqs[1].Messages[len(llm.reqs[1].Messages)-1].Content, "answer the parts that can") {
+		t.Errorf("execution requests = %+v", llm.reqs)
+	}
+
+	// Declined once: the softened retry answers.
+	llm.n, llm.reqs = 1, nil
+	if res, err = p.Run(context.Background(), *senses.NewFromText("describe the lock mechanism")); err != nil || res.Declined || !res.Success {
+		t.Errorf("after one refusal: %+v, %v", res, err)
+	}
+}
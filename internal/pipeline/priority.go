@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/budget"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// ErrDeferred is returned by Run when the priority policy defers a task
+// because the budget is tight. The caller may retry it later.
+var ErrDeferred = errors.New("pipeline: deferred by priority policy (budget is tight)")
+
+// ErrThrottled is returned by Run when the priority policy defers a task
+// because the provider's rate limit is nearly exhausted. Retry it after
+// QuotaResetAt.
+var ErrThrottled = errors.New("pipeline: deferred by priority policy (provider rate limit nearly exhausted)")
+
+// ErrBudgetExhausted is returned by Run, with a result saying which limit
+// is used up, when the daily, monthly or task budget cannot cover the task.
+var ErrBudgetExhausted = errors.New("pipeline: budget exhausted")
+
+// minTaskCostUSD is the least a task is assumed to cost when checking
+// whether the budget can still cover it.
+const minTaskCostUSD = 0.01
+
+// policyPriority returns the priority policy key of an input. Timer inputs
+// (heartbeats) are CRITICAL only so they bypass standby; for spending they
+// are background work and use the "low" row.
+func policyPriority(input senses.UnifiedInput) string {
+	if input.SourceType == senses.SourceTimer {
+		return "low"
+	}
+	return strings.ToLower(input.Priority.String())
+}
+
+// priorityRule returns the policy row for the task's priority.
+func (p *Pipeline) priorityRule(ts *TaskSpec) budget.PriorityRule {
+	return p.deps.PriorityPolicy.Rule(ts.Priority)
+}
+
+// tierBounds returns the execution tier bounds for the task.
+func (p *Pipeline) tierBounds(ts *TaskSpec) (minTier, maxTier brain.Tier) {
+	rule := p.priorityRule(ts)
+	return brain.Tier(rule.MinTier), brain.Tier(rule.MaxTier)
+}
+
+// quotaThrottled reports whether the task should wait for the provider's
+// rate limit to refill.
+func (p *Pipeline) quotaThrottled(ts *TaskSpec) bool {
+	below := p.priorityRule(ts).DeferQuotaBelow
+	if below <= 0 {
+		return false
+	}
+	q, ok := p.quota()
+	return ok && q.Headroom(time.Now()) < below
+}
+
+// QuotaResetAt returns when the provider's tightest rate limit refills, or
+// the zero time when the provider does not report rate limits.
+func (p *Pipeline) QuotaResetAt() time.Time {
+	q, ok := p.quota()
+	if !ok {
+		return time.Time{}
+	}
+	return q.ResetAt(time.Now())
+}
+
+func (p *Pipeline) quota() (brain.Quota, bool) {
+	qr, ok := p.deps.LLM.(brain.QuotaReporter)
+	if !ok {
+		return brain.Quota{}, false
+	}
+	return qr.Quota()
+}
+
+// budgetRemaining returns what the task may still spend: the global
+// budget (with the task's overdraft), capped by the task's own budget
+// minus the spent cost.
+func (p *Pipeline) budgetRemaining(ts *TaskSpec, spent float64) float64 {
+	remaining := ts.BudgetUSD - spent
+	if p.deps.Budget != nil {
+		global := p.deps.Budget.EffectiveBudgetWithin(p.priorityRule(ts).Overdraft)
+		if ts.BudgetUSD <= 0 {
+			return global
+		}
+		remaining = math.Min(remaining, global)
+	}
+	return math.Max(remaining, 0)
+}
+
+// budgetExhausted returns the result of a task the budget can no longer
+// cover, or nil if it can (or no budget is set). The task's overdraft is
+// taken into account, and its own budget after cost is spent.
+func (p *Pipeline) budgetExhausted(ts *TaskSpec, start time.Time, cost float64, stageLogs []StageLog) *RunResult {
+	var msg string
+	switch {
+	case ts.BudgetUSD > 0 && cost+minTaskCostUSD > ts.BudgetUSD:
+		p.logWarn("task budget exhausted", "task_id", ts.ID, "spent_usd", cost, "limit_usd", ts.BudgetUSD)
+		msg = fmt.Sprintf("Budget exhausted: $%.2f of the task's $%.2f budget is spent, so it was stopped. "+
+			"Raise budget_usd in the request to allow more.", cost, ts.BudgetUSD)
+	case p.deps.Budget == nil:
+		return nil
+	default:
+		period := p.deps.Budget.ExceededPeriod(minTaskCostUSD, p.priorityRule(ts).Overdraft)
+		if period == "" {
+			return nil
+		}
+		daily, monthly := p.deps.Budget.Limits()
+		limit, spent, resets := daily, p.deps.Budget.DailySpend(), "at midnight"
+		if period == "monthly" {
+			limit, spent, resets = monthly, p.deps.Budget.MonthlySpend(), "on the 1st"
+		}
+		p.logWarn("budget exhausted", "task_id", ts.ID, "period", period, "spent_usd", spent, "limit_usd", limit)
+		msg = fmt.Sprintf("Budget exhausted: $%.2f of the $%.2f %s budget is spent, so this task was not run. "+
+			"The budget resets %s; raise %s_budget_usd in config.json to allow more.", spent, limit, period, resets, period)
+	}
+	ts.Advance(TaskStatusFailed)
+	p.incrementMetric("pipeline.budget_exhausted")
+	return &RunResult{
+		TaskID:    ts.ID,
+		Result:    msg,
+		CostUSD:   cost,
+		ElapsedMs: time.Since(start).Milliseconds(),
+		StageLogs: stageLogs,
+	}
+}
@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// DefaultMaxAttempts is how often a queued input may be started before
+// replay gives up on it: an input that keeps taking the daemon down with it
+// is dropped rather than replayed forever.
+const DefaultMaxAttempts = 3
+
+// QueuedTask is an accepted input that has not finished yet.
+type QueuedTask struct {
+	Input    senses.UnifiedInput
+	Queued   time.Time
+	Attempts int // times it was started, not counting holds
+}
+
+// TaskQueue is the durable record of accepted inputs: an input is enqueued
+// when it is accepted and removed when its run completes, so the inputs of
+// a daemon that dies in between are replayed at the next start. It shares
+// the long-term memory database. A nil *TaskQueue ignores every call.
+type TaskQueue struct {
+	db          *sql.DB
+	maxAttempts int
+}
+
+// NewTaskQueue creates the queue table if needed. maxAttempts <= 0 uses
+// DefaultMaxAttempts.
+func NewTaskQueue(db *sql.DB, maxAttempts int) (*TaskQueue, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS task_queue (
+		input_id    TEXT PRIMARY KEY,
+		input       TEXT NOT NULL,
+		attempts    INTEGER NOT NULL DEFAULT 0,
+		enqueued_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("task queue: create table: %w", err)
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	return &TaskQueue{db: db, maxAttempts: maxAttempts}, nil
+}
+
+// Enqueue records an accepted input. Enqueuing an input again (a retry or
+// a replay) keeps its place and attempt count.
+func (q *TaskQueue) Enqueue(input *senses.UnifiedInput) error {
+	if q == nil {
+		return nil
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("task queue: enqueue %s: %w", input.InputID, err)
+	}
+	_, err = q.db.Exec(`INSERT INTO task_queue (input_id, input, enqueued_at) VALUES (?, ?, ?)
+		ON CONFLICT (input_id) DO UPDATE SET input = excluded.input`,
+		input.InputID, string(data), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("task queue: enqueue %s: %w", input.InputID, err)
+	}
+	return nil
+}
+
+// Start counts an attempt at running an input.
+func (q *TaskQueue) Start(inputID string) error {
+	if q == nil {
+		return nil
+	}
+	if _, err := q.db.Exec(`UPDATE task_queue SET attempts = attempts + 1 WHERE input_id = ?`, inputID); err != nil {
+		return fmt.Errorf("task queue: start %s: %w", inputID, err)
+	}
+	return nil
+}
+
+// Hold takes back the attempt Start counted for an input that was kept to
+// run later (held in standby, deferred or awaiting approval), so waiting
+// does not count towards maxAttempts.
+func (q *TaskQueue) Hold(inputID string) error {
+	if q == nil {
+		return nil
+	}
+	if _, err := q.db.Exec(`UPDATE task_queue SET attempts = MAX(attempts - 1, 0) WHERE input_id = ?`, inputID); err != nil {
+		return fmt.Errorf("task queue: hold %s: %w", inputID, err)
+	}
+	return nil
+}
+
+// Complete removes a finished input.
+func (q *TaskQueue) Complete(inputID string) error {
+	if q == nil {
+		return nil
+	}
+	if _, err := q.db.Exec(`DELETE FROM task_queue WHERE input_id = ?`, inputID); err != nil {
+		return fmt.Errorf("task queue: complete %s: %w", inputID, err)
+	}
+	return nil
+}
+
+// Pending returns the unfinished inputs to replay, oldest first. Inputs
+// already started maxAttempts times are removed and returned as dropped.
+func (q *TaskQueue) Pending() (replay, dropped []QueuedTask, err error) {
+	if q == nil {
+		return nil, nil, nil
+	}
+	rows, err := q.db.Query(`SELECT input_id, input, attempts, enqueued_at FROM task_queue ORDER BY enqueued_at, rowid`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("task queue: pending: %w", err)
+	}
+	var bad []string
+	for rows.Next() {
+		var id, data string
+		var t QueuedTask
+		if err := rows.Scan(&id, &data, &t.Attempts, &t.Queued); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("task queue: pending: %w", err)
+		}
+		if err := json.Unmarshal([]byte(data), &t.Input); err != nil {
+			bad = append(bad, id) // cannot be replayed
+			continue
+		}
+		if t.Attempts >= q.maxAttempts {
+			dropped = append(dropped, t)
+			continue
+		}
+		replay = append(replay, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("task queue: pending: %w", err)
+	}
+	for _, t := range dropped {
+		bad = append(bad, t.Input.InputID)
+	}
+	for _, id := range bad {
+		if err := q.Complete(id); err != nil {
+			return nil, nil, err
+		}
+	}
+	return replay, dropped, nil
+}
+
+// Len returns the number of unfinished inputs.
+func (q *TaskQueue) Len() int {
+	if q == nil {
+		return 0
+	}
+	var n int
+	q.db.QueryRow(`SELECT COUNT(*) FROM task_queue`).Scan(&n)
+	return n
+}
@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+// resumeWindow is how recent an interrupted run must be for a bare
+// "continue" to pick it up; "continue where you left off" has no limit.
+const resumeWindow = 24 * time.Hour
+
+// Requests to continue an interrupted run, matched case-insensitively:
+// "continue", "resume", "pick up where you left off", "continue the last
+// task". The whole request must be the directive.
+var (
+	resumeRe         = regexp.MustCompile(`(?i)^\s*(?:please\s+)?(?:continue|resume|carry\s+on|pick\s+(?:it\s+)?up)\b(.*?)[\s.!]*$`)
+	resumeExplicitRe = regexp.MustCompile(`(?i)^\s*(?:(?:from\s+)?where\s+(?:you|we|it)\s+(?:left\s+off|stopped)|(?:with\s+)?(?:the\s+)?(?:last|previous|interrupted|failed)\s+(?:task|run|one))\s*(?:please)?$`)
+)
+
+// parseResume reports whether text asks to continue an interrupted run, and
+// whether it says so explicitly rather than with a bare "continue".
+func parseResume(text string) (explicit, ok bool) {
+	m := resumeRe.FindStringSubmatch(text)
+	if m == nil {
+		return false, false
+	}
+	rest := strings.TrimSpace(m[1])
+	if rest == "" {
+		return false, true
+	}
+	if !resumeExplicitRe.MatchString(rest) {
+		return false, false // "continue the essay": a new request
+	}
+	return true, true
+}
+
+// applyResume turns a request to continue an interrupted run into that
+// run's task. Its findings carry over: steps already done are not run
+// again and the rest of the work sees what was found.
+func (p *Pipeline) applyResume(ts *TaskSpec) {
+	if p.deps.Runs == nil {
+		return
+	}
+	explicit, ok := parseResume(ts.Goal)
+	if !ok {
+		return
+	}
+	prev, err := p.deps.Runs.Latest(viewer(ts))
+	if err != nil {
+		p.logWarn("partial run lookup failed", "error", err.Error())
+		return
+	}
+	if prev == nil || (!explicit && time.Since(prev.Updated) > resumeWindow) {
+		return
+	}
+	ts.Goal = prev.Goal
+	ts.ResumedFrom = prev.RunID
+	ts.resumed = prev
+	p.logInfo("resuming interrupted run", "task_id", ts.ID, "from", prev.RunID, "findings", len(prev.Findings))
+}
+
+// beginBuffer opens the run buffer of a multi-step or resumed run and
+// moves the findings of the run it resumes into it.
+func (p *Pipeline) beginBuffer(ts *TaskSpec) {
+	if p.deps.Runs == nil || (len(ts.Subtasks) < 2 && ts.resumed == nil) {
+		return
+	}
+	if err := p.deps.Runs.Begin(ts.ID, ts.Goal, ts.SourceUserID, ts.SourceChannel); err != nil {
+		p.logWarn("run buffer unavailable", "task_id", ts.ID, "error", err.Error())
+		return
+	}
+	ts.buffered = true
+	if ts.resumed == nil {
+		return
+	}
+	for _, f := range ts.resumed.Findings {
+		p.checkpoint(ts, f.Step, f.Finding)
+	}
+	if err := p.deps.Runs.Discard(ts.resumed.RunID); err != nil {
+		p.logWarn("dropping resumed run buffer failed", "run", ts.resumed.RunID, "error", err.Error())
+	}
+}
+
+// checkpoint writes a finding of ts to its run buffer.
+func (p *Pipeline) checkpoint(ts *TaskSpec, step, finding string) {
+	if !ts.buffered {
+		return
+	}
+	if err := p.deps.Runs.Checkpoint(ts.ID, step, finding); err != nil {
+		p.logWarn("checkpoint failed", "task_id", ts.ID, "error", err.Error())
+	}
+}
+
+// resumedFinding returns what the resumed run found for step.
+func (ts *TaskSpec) resumedFinding(step string) (string, bool) {
+	if ts.resumed == nil {
+		return "", false
+	}
+	for _, f := range ts.resumed.Findings {
+		if f.Step == step {
+			return f.Finding, true
+		}
+	}
+	return "", false
+}
+
+// resumeMemory is the resumed run's findings for the execution context.
+func (p *Pipeline) resumeMemory(ts *TaskSpec) []string {
+	if ts.resumed == nil {
+		return nil
+	}
+	return []string{ts.resumed.Describe()}
+}
+
+// bufferEntry is the long-term memory template for the findings of ts.
+func (p *Pipeline) bufferEntry(ts *TaskSpec) memory.LongTermEntry {
+	return memory.LongTermEntry{
+		Tags:       []string{ts.SourceChannel, ts.Fingerprint},
+		Owner:      ts.SourceUserID,
+		Channel:    ts.SourceChannel,
+		Visibility: p.memoryVisibility(ts),
+		Topic:      ts.Topic,
+	}
+}
+
+// settleBuffer promotes the findings of a successful run to long-term
+// memory, or keeps them as a partial result when err is set. It reports
+// whether a partial result was kept.
+func (p *Pipeline) settleBuffer(ts *TaskSpec, err error) bool {
+	if !ts.buffered {
+		return false
+	}
+	ts.buffered = false
+	if err != nil {
+		kept, ferr := p.deps.Runs.Fail(ts.ID, err.Error(), p.bufferEntry(ts))
+		if ferr != nil {
+			p.logWarn("keeping partial result failed", "task_id", ts.ID, "error", ferr.Error())
+		}
+		return kept
+	}
+	n, perr := p.deps.Runs.Promote(ts.ID, p.bufferEntry(ts))
+	if perr != nil {
+		p.logWarn("promoting findings failed", "task_id", ts.ID, "error", perr.Error())
+		return false
+	}
+	if n > 0 {
+		p.logInfo("findings promoted to long-term memory", "task_id", ts.ID, "findings", n)
+	}
+	return false
+}
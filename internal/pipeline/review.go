@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// unparsedReviewScore is the quality of a result whose review could not be
+// read even after a retry: neither good nor bad.
+const unparsedReviewScore = 0.5
+
+// reviewFormat is how the review stage asks for its verdict.
+const reviewFormat = `Respond with only a JSON object:
+{"score": <0.0-1.0>, "notes": "<brief assessment>"}`
+
+var (
+	reviewScoreRe = regexp.MustCompile(`(?im)^[\s*#_]*score[\s*_]*[:=]\s*\**\s*(-?[0-9]*\.?[0-9]+)\s*(%|/\s*10{1,2}\b)?`)
+	reviewNotesRe = regexp.MustCompile(`(?is)(?:^|\n)[\s*#_]*notes[\s*_]*[:=][\s*_]*(.*)`)
+)
+
+// reviewSchema is the JSON Schema of reviewVerdict.
+var reviewSchema = brain.Schema{
+	Name:        "review",
+	Description: "The quality of a task result.",
+	JSON: json.RawMessage(`{"type": "object", "properties": {
+		"score": {"type": "number", "minimum": 0, "maximum": 1},
+		"notes": {"type": "string"}
+	}, "required": ["score", "notes"]}`),
+}
+
+// reviewVerdict is the reply of the review stage.
+type reviewVerdict struct {
+	Score *float64 `json:"score"`
+	Notes string   `json:"notes"`
+}
+
+// Validate implements brain.ValidReply.
+func (v *reviewVerdict) Validate() error {
+	switch {
+	case v.Score == nil:
+		return errors.New("score is missing")
+	case *v.Score < 0 || *v.Score > 1:
+		return fmt.Errorf("score %g is not between 0 and 1", *v.Score)
+	}
+	v.Notes = strings.TrimSpace(v.Notes)
+	return nil
+}
+
+// ParseText implements brain.TextReply for the older "SCORE: 0.8\nNOTES:
+// ..." lines. Scores given out of 10 or 100 ("8/10", "85%") are scaled.
+func (v *reviewVerdict) ParseText(content string) bool {
+	m := reviewScoreRe.FindStringSubmatch(content)
+	if m == nil {
+		return false
+	}
+	score, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return false
+	}
+	switch scale := strings.ReplaceAll(m[2], " ", ""); scale {
+	case "/10":
+		score /= 10
+	case "%", "/100":
+		score /= 100
+	}
+	v.Score = &score
+	if n := reviewNotesRe.FindStringSubmatch(content); n != nil {
+		v.Notes = n[1]
+	}
+	return true
+}
+
+// parseReview extracts the score and notes of a review reply: the JSON
+// object asked for, or the older lines. A score outside 0-1 makes the
+// reply unparseable.
+func parseReview(content string) (score float64, notes string, ok bool) {
+	var v reviewVerdict
+	if brain.DecodeStructured(content, &v) != nil {
+		return 0, "", false
+	}
+	return *v.Score, v.Notes, true
+}
@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// escalationPhrases are user requests for a better model. They are stripped
+// from the goal before execution.
+var escalationPhrases = []string{
+	"use the better model",
+	"use a better model",
+	"use the best model",
+	"use a stronger model",
+	"use the stronger model",
+	"use the smartest model",
+}
+
+// detectEscalation reports whether text asks for a better model and
+// returns the text with the request removed.
+func detectEscalation(text string) (string, bool) {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		return text, false // byte offsets would not line up
+	}
+	for _, phrase := range escalationPhrases {
+		idx := strings.Index(lower, phrase)
+		if idx < 0 {
+			continue
+		}
+		rest := text[:idx] + text[idx+len(phrase):]
+		rest = strings.TrimSpace(rest)
+		if after, ok := cutPrefixFold(rest, "for this"); ok {
+			rest = after
+		}
+		return strings.Trim(rest, " \t\n:,.;-—!"), true
+	}
+	return text, false
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// routingFingerprint identifies the task family for routing overrides. It
+// matches the fingerprint computed in stage 8.
+func (p *Pipeline) routingFingerprint(ts *TaskSpec) string {
+	return p.deps.Patterns.ComputeFingerprint(ts.Goal, ts.SourceChannel)
+}
+
+// applyRouting picks the execution tier before the task runs: routing set
+// by the request itself wins; a manual escalation ("use the better model
+// for this") forces the powerful tier and is learned for the task family;
+// otherwise a learned override applies.
+func (p *Pipeline) applyRouting(ts *TaskSpec, input senses.UnifiedInput) {
+	if p.applyRequestRouting(ts, input) {
+		return
+	}
+	goal, manual := detectEscalation(ts.Goal)
+	if input.SourceMeta.Extra["escalate"] == "true" {
+		manual = true
+	}
+
+	if manual {
+		if goal == "" {
+			// Bare "use the better model" — re-run the previous request of
+			// this session.
+			goal = p.previousGoal(ts.SessionID)
+		}
+		if goal != "" {
+			ts.Goal = goal
+		}
+		ts.Complexity = brain.TierComplexity(brain.TierPowerful)
+		fp := p.routingFingerprint(ts)
+		if err := p.deps.RoutingOverrides.Record(fp, brain.TierPowerful, brain.OverrideReasonManual, ts.Goal); err != nil {
+			p.logWarn("record routing override failed", "error", err.Error())
+		}
+		p.logInfo("manual escalation", "task_id", ts.ID, "fingerprint", fp)
+		return
+	}
+
+	if tier, ok := p.deps.RoutingOverrides.Tier(p.routingFingerprint(ts)); ok {
+		ts.Complexity = brain.TierComplexity(tier)
+		p.logInfo("routing override applied", "task_id", ts.ID, "tier", string(tier))
+	}
+}
+
+// applyRequestRouting applies the per-task budget, model and complexity
+// of an API request (senses.ExtraBudgetUSD and friends). It reports
+// whether the request chose the model or complexity, which then override
+// learned routing. Unknown models and complexities are ignored.
+func (p *Pipeline) applyRequestRouting(ts *TaskSpec, input senses.UnifiedInput) bool {
+	extra := input.SourceMeta.Extra
+	if v := extra[senses.ExtraBudgetUSD]; v != "" {
+		if usd, err := strconv.ParseFloat(v, 64); err == nil && usd > 0 {
+			ts.BudgetUSD = usd
+		}
+	}
+	if m := extra[senses.ExtraModel]; m != "" {
+		if slices.Contains(p.deps.Router.Models(), m) {
+			ts.RequestedModel = m
+		} else {
+			p.logWarn("requested model unknown, routing as usual", "task_id", ts.ID, "model", m)
+		}
+	}
+	switch c := extra[senses.ExtraComplexity]; c {
+	case "simple", "moderate", "complex":
+		ts.Complexity = c
+	}
+	routed := ts.RequestedModel != "" || ts.Complexity != ""
+	if routed || ts.BudgetUSD > 0 {
+		p.logInfo("request routing applied", "task_id", ts.ID, "model", ts.RequestedModel, "complexity", ts.Complexity, "budget_usd", ts.BudgetUSD)
+	}
+	return routed
+}
+
+// previousGoal returns the last user goal of a session, or "".
+func (p *Pipeline) previousGoal(sessionID string) string {
+	entries := p.deps.ShortTerm.GetRecentBySession(10, sessionID)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Role == "user" {
+			return entries[i].Content
+		}
+	}
+	return ""
+}
+
+// escalate retries a low-scoring LLM execution one tier up. If the retry
+// scores better it replaces the result, and if it also clears the threshold
+// the tier is learned for the task family. It returns the (possibly
+// updated) result, quality and review notes, and whether it escalated.
+func (p *Pipeline) escalate(ctx context.Context, ts *TaskSpec, result string, quality float64, notes string, cost *float64) (string, float64, string, bool) {
+	threshold := p.deps.EscalationThreshold
+	if threshold <= 0 || quality >= threshold || ts.Model == "" || ts.RequestedModel != "" {
+		return result, quality, notes, false
+	}
+	next, ok := brain.NextTier(p.deps.Router.TierOf(ts.Model))
+	if minTier, maxTier := p.tierBounds(ts); !ok || brain.ClampTier(next, minTier, maxTier) != next {
+		return result, quality, notes, false
+	}
+
+	prevComplexity, prevModel := ts.Complexity, ts.Model
+	ts.Complexity = brain.TierComplexity(next)
+	retry, err := p.execute(withoutPartials(ctx), ts, cost)
+	if err == nil && ts.Model != prevModel {
+		var q2 float64
+		var notes2 string
+		q2, notes2, err = p.review(ctx, ts, retry, cost)
+		if err == nil && q2 > quality {
+			p.logInfo("escalation improved result", "task_id", ts.ID, "tier", string(next), "quality", q2)
+			if q2 >= threshold {
+				if rerr := p.deps.RoutingOverrides.Record(p.routingFingerprint(ts), next, brain.OverrideReasonEscalation, ts.Goal); rerr != nil {
+					p.logWarn("record routing override failed", "error", rerr.Error())
+				}
+			}
+			return retry, q2, notes2, true
+		}
+	}
+	if err != nil {
+		p.logWarn("escalation failed", "task_id", ts.ID, "error", err.Error())
+	}
+	ts.Complexity, ts.Model = prevComplexity, prevModel
+	return result, quality, notes, false
+}
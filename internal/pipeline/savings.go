@@ -0,0 +1,40 @@
+package pipeline
+
+import (
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+)
+
+// recordBaseline profiles a cold-path run: when no subtask was assigned to a
+// skill, the execution cost and latency feed the fingerprint's LLM baseline,
+// which skills for the same pattern are later compared against.
+func (p *Pipeline) recordBaseline(ts *TaskSpec, costUSD float64, elapsedMs int64) {
+	if p.deps.Savings == nil || ts.Fingerprint == "" {
+		return
+	}
+	for _, sub := range ts.Subtasks {
+		if strings.HasPrefix(sub.AssignedTo, "skill:") {
+			return
+		}
+	}
+	if err := p.deps.Savings.RecordLLMRun(ts.Fingerprint, costUSD, elapsedMs); err != nil {
+		p.logWarn("record llm baseline failed", "error", err.Error())
+	}
+}
+
+// recordSkillSavings records what a successful skill run saved against the
+// LLM baseline of the task's pattern.
+func (p *Pipeline) recordSkillSavings(ts *TaskSpec, skillID string, out *instruments.SkillOutput) {
+	if p.deps.Savings == nil || ts.Fingerprint == "" {
+		return
+	}
+	savedUSD, savedMs, ok, err := p.deps.Savings.RecordSkillRun(skillID, ts.Fingerprint, out.CostUSD, out.ElapsedMs)
+	if err != nil {
+		p.logWarn("record skill savings failed", "skill", skillID, "error", err.Error())
+		return
+	}
+	if ok {
+		p.logInfo("skill savings", "skill", skillID, "saved_usd", savedUSD, "saved_ms", savedMs)
+	}
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+)
+
+// ScratchPolicy configures the per-run scratch directories that the
+// run's skills, subagents and sandboxed code share.
+type ScratchPolicy struct {
+	// Dir holds one directory per run ("" = no scratch space).
+	Dir string `json:"-"`
+	// ArtifactsDir receives the directories of runs whose files are kept.
+	ArtifactsDir string `json:"-"`
+	// QuotaMB caps a run's directory (0 = instruments.DefaultScratchQuotaMB,
+	// negative = unlimited).
+	QuotaMB int `json:"quota_mb,omitempty"`
+	// Keep keeps every run's files as artifacts; otherwise only runs whose
+	// input asks for it ("keep_artifacts": "true") keep them.
+	Keep bool `json:"keep,omitempty"`
+}
+
+func (sp ScratchPolicy) quota() int64 {
+	switch {
+	case sp.QuotaMB < 0:
+		return 0
+	case sp.QuotaMB == 0:
+		return instruments.DefaultScratchQuotaMB << 20
+	}
+	return int64(sp.QuotaMB) << 20
+}
+
+// openScratch creates the run's scratch directory and attaches it to ctx.
+// It returns ctx unchanged and nil when scratch space is off or cannot be
+// created.
+func (p *Pipeline) openScratch(ctx context.Context, ts *TaskSpec) (context.Context, *instruments.Scratch) {
+	sp := p.deps.Scratch
+	if sp.Dir == "" {
+		return ctx, nil
+	}
+	scratch, err := instruments.NewScratch(sp.Dir, ts.ID, sp.quota())
+	if err != nil {
+		p.logWarn("scratch space unavailable", "task_id", ts.ID, "error", err.Error())
+		return ctx, nil
+	}
+	return instruments.WithScratch(ctx, scratch), scratch
+}
+
+// closeScratch removes the run's scratch directory, or keeps its files as
+// artifacts listed in rr when asked to.
+func (p *Pipeline) closeScratch(scratch *instruments.Scratch, ts *TaskSpec, rr *RunResult) {
+	sp := p.deps.Scratch
+	if !sp.Keep && !ts.KeepArtifacts || sp.ArtifactsDir == "" {
+		if err := scratch.Remove(); err != nil {
+			p.logWarn("remove scratch", "task_id", ts.ID, "error", err.Error())
+		}
+		return
+	}
+	dir, err := scratch.Keep(sp.ArtifactsDir)
+	if err != nil {
+		p.logWarn("keep artifacts", "task_id", ts.ID, "error", err.Error())
+		return
+	}
+	if dir == "" || rr == nil {
+		return
+	}
+	rr.ArtifactsDir = dir
+	rr.Artifacts, _ = scratch.Files()
+}
@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+// semanticRecallLimit bounds the memories recalled by meaning.
+const semanticRecallLimit = 5
+
+// semanticMemory returns the long-term memories closest in meaning to the
+// task that ts may see and that are not in its context already (recalled
+// for a topic or contact). Recall errors — the embeddings API being down —
+// leave the context without them.
+func (p *Pipeline) semanticMemory(ctx context.Context, ts *TaskSpec) []string {
+	if p.deps.Semantic == nil {
+		return nil
+	}
+	entries, err := p.deps.Semantic.Recall(ctx, viewer(ts), ts.Goal, semanticRecallLimit)
+	if err != nil {
+		p.logWarn("semantic recall failed", "task_id", ts.ID, "error", err.Error())
+		return nil
+	}
+	cited := make(map[string]bool, len(ts.Sources))
+	for _, s := range ts.Sources {
+		cited[s.ID] = true
+	}
+	var out []string
+	for _, e := range entries {
+		if cited[e.ID] {
+			continue
+		}
+		out = append(out, fmt.Sprintf("[memory, %s] %s", e.CreatedAt.Format("2006-01-02"), e.Summary))
+		ts.cite(e, "related")
+	}
+	return out
+}
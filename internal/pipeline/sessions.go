@@ -0,0 +1,298 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// DefaultSessionIdleMinutes is how long a conversation may idle before its
+// next input starts a new session.
+const DefaultSessionIdleMinutes = 120
+
+// newSessionCommand ends the current conversation.
+const newSessionCommand = "/new"
+
+// sessionHistoryLimit caps the entries of an ended session that are
+// summarized.
+const sessionHistoryLimit = 40
+
+// ErrSessionNotFound is returned for a session with no history.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionPolicy configures the conversation lifecycle.
+type SessionPolicy struct {
+	// IdleMinutes ends a session after this long without input; the next
+	// input starts a new one. 0 = DefaultSessionIdleMinutes, negative =
+	// never.
+	IdleMinutes int `json:"idle_minutes,omitempty"`
+	// Summarize stores a summary of each ended session in long-term memory
+	// before its short-term history is dropped.
+	Summarize bool `json:"summarize,omitempty"`
+}
+
+func (sp SessionPolicy) idle() time.Duration {
+	switch {
+	case sp.IdleMinutes < 0:
+		return 0
+	case sp.IdleMinutes == 0:
+		return DefaultSessionIdleMinutes * time.Minute
+	}
+	return time.Duration(sp.IdleMinutes) * time.Minute
+}
+
+// conversation is the state of one conversation: the session ID its sense
+// assigned (one CLI run, one kiosk connection, an API client's ID).
+type conversation struct {
+	gen  int // sessions ended so far
+	last time.Time
+}
+
+// sessionTracker maps conversations to their current session. The first
+// session keeps the conversation's ID; later ones are numbered, as
+// "cli_123#2".
+type sessionTracker struct {
+	mu    sync.Mutex
+	convs map[string]*conversation
+}
+
+// conversationIdle is how long an idle conversation is remembered.
+const conversationIdle = 7 * 24 * time.Hour
+
+func sessionID(conv string, gen int) string {
+	if gen == 0 {
+		return conv
+	}
+	return fmt.Sprintf("%s#%d", conv, gen+1)
+}
+
+// current returns conv's session at now, rolling over to a new one after
+// idle (0 = never); ended is the session that idled out, if any.
+func (s *sessionTracker) current(conv string, now time.Time, idle time.Duration) (id, ended string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.convs == nil {
+		s.convs = make(map[string]*conversation)
+	}
+	c := s.convs[conv]
+	if c == nil {
+		for k, old := range s.convs {
+			if now.Sub(old.last) > conversationIdle {
+				delete(s.convs, k)
+			}
+		}
+		c = &conversation{}
+		s.convs[conv] = c
+	}
+	if idle > 0 && !c.last.IsZero() && now.Sub(c.last) > idle {
+		ended = sessionID(conv, c.gen)
+		c.gen++
+	}
+	c.last = now
+	return sessionID(conv, c.gen), ended
+}
+
+// expire ends the sessions idle longer than idle at now (0 = never) and
+// returns their IDs, so their history goes even if the conversation does
+// not come back.
+func (s *sessionTracker) expire(now time.Time, idle time.Duration) []string {
+	if idle <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ended []string
+	for conv, c := range s.convs {
+		if !c.last.IsZero() && now.Sub(c.last) > idle {
+			ended = append(ended, sessionID(conv, c.gen))
+			c.gen++
+			c.last = time.Time{}
+		}
+	}
+	return ended
+}
+
+// conversationOf returns the conversation whose current session is id.
+func (s *sessionTracker) conversationOf(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conv, c := range s.convs {
+		if sessionID(conv, c.gen) == id {
+			return conv, true
+		}
+	}
+	return "", false
+}
+
+// reset ends conv's current session and returns its ID.
+func (s *sessionTracker) reset(conv string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.convs == nil {
+		s.convs = make(map[string]*conversation)
+	}
+	c := s.convs[conv]
+	if c == nil {
+		c = &conversation{}
+		s.convs[conv] = c
+	}
+	ended := sessionID(conv, c.gen)
+	c.gen++
+	c.last = time.Time{}
+	return ended
+}
+
+// senderConversation identifies the conversation of an input whose sense
+// assigns no session: the sender on its channel, as "TELEGRAM/12345".
+// Timer inputs and inputs without a sender have none.
+func senderConversation(input senses.UnifiedInput) string {
+	if input.SourceType == senses.SourceTimer || input.SourceMeta.Sender == "" {
+		return ""
+	}
+	return string(input.SourceType) + "/" + input.SourceMeta.Sender
+}
+
+// isNewSession reports whether goal asks for a new conversation.
+func isNewSession(goal string) bool {
+	return strings.EqualFold(strings.TrimSpace(goal), newSessionCommand)
+}
+
+// NewSession ends the current session of conversation conv (the session ID
+// its sense assigned), as "/new" does: the next input starts with no
+// history and no active memory topic. It returns the summary stored in
+// long-term memory, if the policy asks for one.
+func (p *Pipeline) NewSession(ctx context.Context, conv string) (string, error) {
+	return p.endSession(ctx, p.sessions.reset(conv))
+}
+
+// SessionInfo describes a session with history in short-term memory.
+type SessionInfo struct {
+	memory.SessionStats
+	ExpiresAt time.Time `json:"expires_at,omitzero"` // when it idles out; zero = never
+}
+
+// Sessions lists the sessions with short-term history, most recently
+// active first.
+func (p *Pipeline) Sessions() []SessionInfo {
+	idle := p.deps.Sessions.idle()
+	stats := p.deps.ShortTerm.Sessions()
+	out := make([]SessionInfo, len(stats))
+	for i, st := range stats {
+		out[i] = SessionInfo{SessionStats: st}
+		if idle > 0 {
+			out[i].ExpiresAt = st.LastActive.Add(idle)
+		}
+	}
+	return out
+}
+
+// EndSession ends session id as "/new" would for its conversation. It
+// returns ErrSessionNotFound if the session has no history.
+func (p *Pipeline) EndSession(ctx context.Context, id string) (string, error) {
+	if len(p.deps.ShortTerm.GetRecentBySession(1, id)) == 0 {
+		return "", ErrSessionNotFound
+	}
+	if conv, ok := p.sessions.conversationOf(id); ok {
+		return p.NewSession(ctx, conv)
+	}
+	return p.endSession(ctx, id)
+}
+
+// endSession drops the short-term history of session id, summarizing it
+// into long-term memory first when the policy asks for it.
+func (p *Pipeline) endSession(ctx context.Context, id string) (string, error) {
+	entries := p.deps.ShortTerm.GetRecentBySession(sessionHistoryLimit, id)
+	defer p.deps.ShortTerm.RemoveSession(id)
+	if !p.deps.Sessions.Summarize || len(entries) == 0 || p.deps.LongTerm == nil {
+		return "", nil
+	}
+
+	var transcript strings.Builder
+	var channel, sender string
+	for _, e := range entries {
+		fmt.Fprintf(&transcript, "%s: %s\n", e.Role, e.Content)
+		if e.Role == "user" {
+			channel, sender = e.Metadata["channel"], e.Metadata["sender"]
+		}
+	}
+	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+		Model: p.deps.Router.Select("simple", 0),
+		Messages: []brain.Message{{Role: "user", Content: "Summarize this conversation in two or three sentences for future reference: " +
+			"what was asked, what was decided and anything left open.\n\n" + transcript.String()}},
+		MaxTokens: 300,
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarize session: %w", err)
+	}
+	if p.deps.Budget != nil {
+		p.deps.Budget.Record(id, resp.CostUSD)
+	}
+	summary := strings.TrimSpace(resp.Content)
+	ts := &TaskSpec{SourceChannel: channel, SourceUserID: sender}
+	err = p.deps.LongTerm.Store(memory.LongTermEntry{
+		ID:         fmt.Sprintf("session_%d", time.Now().UnixNano()),
+		Summary:    "Conversation summary: " + summary,
+		Tags:       []string{"session_summary", channel},
+		CreatedAt:  time.Now().UTC(),
+		Owner:      sender,
+		Channel:    channel,
+		Visibility: p.memoryVisibility(ts),
+	})
+	if err != nil {
+		return "", fmt.Errorf("store session summary: %w", err)
+	}
+	p.logInfo("session summarized", "session", id, "entries", len(entries))
+	return summary, nil
+}
+
+// applySession resolves the task's session: stateless requests get none,
+// and a conversation that idled out rolls over to a new session, the old
+// one being ended in the background. A "/new" request is answered directly
+// and returned as a result; otherwise nil.
+func (p *Pipeline) applySession(ctx context.Context, ts *TaskSpec) *RunResult {
+	if ts.Stateless {
+		ts.SessionID = ""
+		return nil
+	}
+	conv := ts.SessionID
+	if conv == "" {
+		if isNewSession(ts.Goal) {
+			return &RunResult{TaskID: ts.ID, Success: true, Result: "This channel keeps no conversation history; there is nothing to reset."}
+		}
+		return nil
+	}
+	if isNewSession(ts.Goal) {
+		summary, err := p.NewSession(ctx, conv)
+		msg := "Started a new conversation."
+		switch {
+		case err != nil:
+			p.logWarn("end session failed", "session", conv, "error", err.Error())
+		case summary != "":
+			msg += " The previous one was saved to memory: " + summary
+		}
+		return &RunResult{TaskID: ts.ID, Success: true, Result: msg}
+	}
+	now, idle := time.Now(), p.deps.Sessions.idle()
+	expired := p.sessions.expire(now, idle)
+	id, ended := p.sessions.current(conv, now, idle)
+	ts.SessionID = id
+	if ended != "" {
+		expired = append(expired, ended)
+	}
+	for _, ended := range expired {
+		p.logInfo("session idled out", "session", ended)
+		go func() {
+			if _, err := p.endSession(context.WithoutCancel(ctx), ended); err != nil {
+				p.logWarn("end session failed", "session", ended, "error", err.Error())
+			}
+		}()
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// ambiguityFormat is the clarification field asking for two readings of an
+// ambiguous task. It is only requested when speculation is enabled.
+const ambiguityFormat = `"ambiguity": [<if the task could reasonably mean two different things: "<first reading>", "<second reading>"; otherwise nothing>]`
+
+// interpretationBoth marks a speculative run whose answers were both kept.
+const interpretationBoth = "both"
+
+// interpretationResult is the answer to one reading of an ambiguous task.
+type interpretationResult struct {
+	Interpretation string
+	Result         string
+}
+
+// parseInterpretations extracts the two readings from the AMBIGUITY line
+// of a clarification in the older line format; nil when the task is
+// unambiguous or the line is malformed.
+func parseInterpretations(clarified string) []string {
+	for _, line := range strings.Split(clarified, "\n") {
+		value, ok := cutPrefixFold(strings.TrimSpace(line), "AMBIGUITY:")
+		if !ok {
+			continue
+		}
+		return readings(strings.Split(value, "|"))
+	}
+	return nil
+}
+
+// readings returns the two distinct readings of an ambiguous task; nil
+// unless parts are exactly that.
+func readings(parts []string) []string {
+	if len(parts) != 2 {
+		return nil
+	}
+	a, b := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if a == "" || b == "" || strings.EqualFold(a, b) || strings.EqualFold(a, "none") {
+		return nil
+	}
+	return []string{a, b}
+}
+
+// shouldSpeculate reports whether both readings of an ambiguous task may
+// run: speculation is enabled, the task would be answered by the LLM (not
+// a skill or subagent), and two cheap-tier runs cost at most
+// SpeculationMultiplier times one run on the usual tier. Models of unknown
+// price count as equally expensive.
+func (p *Pipeline) shouldSpeculate(ts *TaskSpec) bool {
+	if p.deps.SpeculationMultiplier <= 0 || len(ts.Interpretations) != 2 {
+		return false
+	}
+	for _, sub := range ts.Subtasks {
+		if sub.AssignedTo != "" && sub.AssignedTo != "self" {
+			return false
+		}
+	}
+	if p.deps.Budget != nil && !p.deps.Budget.CanSpendWithin(0.02, p.priorityRule(ts).Overdraft) {
+		return false
+	}
+	budgetRemaining := p.budgetRemaining(ts, 0)
+	complexity := ts.Complexity
+	if complexity == "" {
+		complexity = "moderate"
+	}
+	minTier, maxTier := p.tierBounds(ts)
+	usual := p.deps.Router.CostPer1K(p.deps.Router.SelectBounded(complexity, budgetRemaining, minTier, maxTier))
+	cheap := p.deps.Router.CostPer1K(p.deps.Router.SelectBounded("simple", budgetRemaining, minTier, maxTier))
+	ratio := 2.0
+	if usual > 0 && cheap > 0 {
+		ratio = 2 * cheap / usual
+	}
+	return ratio <= p.deps.SpeculationMultiplier
+}
+
+// speculate executes both readings of an ambiguous task in parallel on the
+// cheap tier. It returns nil when speculation does not apply or a run
+// fails, in which case the task executes normally.
+func (p *Pipeline) speculate(ctx context.Context, ts *TaskSpec, cost *float64) []interpretationResult {
+	if !p.shouldSpeculate(ts) {
+		return nil
+	}
+	ts.Advance(TaskStatusExecuting)
+
+	results := make([]interpretationResult, len(ts.Interpretations))
+	costs := make([]float64, len(ts.Interpretations))
+	errs := make([]error, len(ts.Interpretations))
+	models := make([]string, len(ts.Interpretations))
+	var wg sync.WaitGroup
+	for i, reading := range ts.Interpretations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			branch := *ts
+			branch.Goal = fmt.Sprintf("%s\n\n(Interpret this as: %s)", ts.Goal, reading)
+			branch.Complexity = brain.TierComplexity(brain.TierCheap)
+			results[i] = interpretationResult{Interpretation: reading}
+			results[i].Result, errs[i] = p.executeLLM(withoutPartials(ctx), &branch, &costs[i])
+			models[i] = branch.Model
+		}()
+	}
+	wg.Wait()
+
+	for i := range costs {
+		*cost += costs[i]
+	}
+	for _, err := range errs {
+		if err != nil {
+			p.logWarn("speculative execution failed, executing normally", "task_id", ts.ID, "error", err.Error())
+			return nil
+		}
+	}
+	ts.Model = models[0]
+	p.incrementMetric("pipeline.speculated")
+	p.logInfo("speculative execution", "task_id", ts.ID, "model", ts.Model)
+	return results
+}
+
+// pickInterpretation asks the reviewer which reading the user most likely
+// meant and returns its answer. When the reviewer cannot tell, or fails,
+// both answers are presented.
+func (p *Pipeline) pickInterpretation(ctx context.Context, ts *TaskSpec, candidates []interpretationResult, cost *float64) string {
+	soulContent, _ := p.deps.Soul.Read()
+	messages := p.deps.Context.Assemble(brain.ContextLayers{
+		SystemPrompt: soulContent,
+		TaskDescription: fmt.Sprintf(
+			"This request is ambiguous, so it was answered once per reading. Decide which reading the user most likely meant.\n\nRequest: %s\n\nA (%s):\n%s\n\nB (%s):\n%s\n\nRespond with exactly one line:\nCHOICE: A, B or BOTH (BOTH if neither reading is clearly more likely)",
+			ts.Goal, candidates[0].Interpretation, candidates[0].Result, candidates[1].Interpretation, candidates[1].Result),
+	})
+	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+		Messages: messages,
+		Model:    p.deps.Router.Select("simple", ts.BudgetUSD),
+	})
+	if err != nil {
+		p.logWarn("interpretation pick failed, presenting both", "task_id", ts.ID, "error", err.Error())
+		ts.Interpretation = interpretationBoth
+		return bothInterpretations(candidates)
+	}
+	*cost += resp.CostUSD
+	if p.deps.Budget != nil {
+		p.deps.Budget.Record(ts.ID, resp.CostUSD)
+	}
+
+	switch parseChoice(resp.Content) {
+	case "A":
+		ts.Interpretation = candidates[0].Interpretation
+		return candidates[0].Result
+	case "B":
+		ts.Interpretation = candidates[1].Interpretation
+		return candidates[1].Result
+	}
+	ts.Interpretation = interpretationBoth
+	return bothInterpretations(candidates)
+}
+
+// parseChoice reads "CHOICE: A|B|BOTH" from a reviewer response; "" when
+// absent.
+func parseChoice(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if value, ok := cutPrefixFold(strings.TrimSpace(line), "CHOICE:"); ok {
+			return strings.ToUpper(strings.Trim(strings.TrimSpace(value), ".*"))
+		}
+	}
+	return ""
+}
+
+// bothInterpretations presents the answers to both readings.
+func bothInterpretations(candidates []interpretationResult) string {
+	var b strings.Builder
+	b.WriteString("Your request can be read two ways, so here are both answers.")
+	for i, c := range candidates {
+		fmt.Fprintf(&b, "\n\n**%d. %s**\n\n%s", i+1, c.Interpretation, strings.TrimSpace(c.Result))
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+// StatusEvent is a one-line account of what a run is doing, e.g.
+// "Executing subtask 2/3 on gpt-4o…". Status lines are derived from the
+// stage events and the execution stage's routing, without an LLM call, so
+// a display can show progress while a long run is under way.
+type StatusEvent struct {
+	TaskID string
+	Stage  int
+	Text   string
+}
+
+// OnStatus registers a callback for status lines. During parallel
+// execution it may be called from several goroutines at once.
+func (p *Pipeline) OnStatus(fn func(StatusEvent)) {
+	p.statusCallback = fn
+}
+
+// emitStatus fires a status event if a callback is registered and text is
+// not empty.
+func (p *Pipeline) emitStatus(taskID string, stage int, text string) {
+	if p.statusCallback != nil && text != "" {
+		p.statusCallback(StatusEvent{TaskID: taskID, Stage: stage, Text: text})
+	}
+}
+
+// stageStatus returns the status line for a stage event, or "" for events
+// too quick or too internal to be worth showing.
+func stageStatus(evt StageEvent) string {
+	switch evt.Status {
+	case "started":
+		switch evt.Name {
+		case "intake":
+			return "Reading the request…"
+		case "clarify":
+			return "Clarifying…"
+		case "plan":
+			return "Planning…"
+		case "execute":
+			return "Executing…"
+		case "review":
+			return "Reviewing the result…"
+		case "memory_update":
+			return "Updating memory…"
+		case "reflection":
+			return "Reflecting on the run…"
+		}
+	case "completed":
+		if evt.Name != "plan" {
+			return ""
+		}
+		if evt.Summary == "warm_start" {
+			return "Reusing the plan of a similar task…"
+		}
+		var n int
+		if _, err := fmt.Sscanf(evt.Summary, "subtasks=%d", &n); err == nil && n > 1 {
+			return fmt.Sprintf("Planning %d subtasks…", n)
+		}
+	case "error":
+		return fmt.Sprintf("Stopped: %s failed", evt.Name)
+	}
+	return ""
+}
+
+// subtaskKey carries the position of the subtask being executed, so the
+// execution status can name it.
+type subtaskKey struct{}
+
+type subtaskPos struct{ n, total int }
+
+// withSubtask marks ctx as executing sub, one of ts's subtasks.
+func withSubtask(ctx context.Context, ts *TaskSpec, sub *SubtaskSpec) context.Context {
+	for i := range ts.Subtasks {
+		if ts.Subtasks[i].ID == sub.ID {
+			return context.WithValue(ctx, subtaskKey{}, subtaskPos{n: i + 1, total: len(ts.Subtasks)})
+		}
+	}
+	return ctx
+}
+
+// subtaskLabel returns " subtask n/total" for a ctx executing one of
+// several subtasks, "" otherwise.
+func subtaskLabel(ctx context.Context) string {
+	pos, ok := ctx.Value(subtaskKey{}).(subtaskPos)
+	if !ok || pos.total < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" subtask %d/%d", pos.n, pos.total)
+}
@@ -1,6 +1,11 @@
 package pipeline
 
-import "time"
+import (
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/memory"
+)
 
 // TaskStatus represents the lifecycle stage of a task.
 type TaskStatus string
@@ -24,6 +29,10 @@ type SubtaskSpec struct {
 	Status       TaskStatus `json:"status"`
 	Result       string   `json:"result,omitempty"`
 	QualityScore float64  `json:"quality_score,omitempty"`
+
+	// Provenance records the delegation that produced Result, when a
+	// subagent did.
+	Provenance *instruments.Provenance `json:"provenance,omitempty"`
 }
 
 // TaskSpec is a versioned specification of a task flowing through the pipeline.
@@ -49,6 +58,46 @@ type TaskSpec struct {
 	SourceChannel string `json:"source_channel,omitempty"` // Which sense channel this came from
 	SourceUserID  string `json:"source_user_id,omitempty"`
 	SessionID     string `json:"session_id,omitempty"` // Groups related interactions for short-term memory
+	Stateless     bool   `json:"stateless,omitempty"`  // One-off request: no session memory read or written
+	KeepArtifacts bool   `json:"keep_artifacts,omitempty"` // Keep the run's scratch files
+
+	// Routing.
+	Complexity     string `json:"complexity,omitempty"`      // Router complexity for execution ("" = moderate)
+	Model          string `json:"model,omitempty"`           // Model the LLM execution ran on
+	RequestedModel string `json:"requested_model,omitempty"` // Model the request pinned, overriding the router
+	Priority       string `json:"priority,omitempty"`        // Priority policy key ("low", "normal", "high", "critical")
+
+	// Speculation — two readings of an ambiguous goal found by the
+	// clarifier, and the one the review picked ("both" when both answers
+	// were kept).
+	Interpretations []string `json:"interpretations,omitempty"`
+	Interpretation  string   `json:"interpretation,omitempty"`
+
+	// Memory topic — the namespace this task stores to and recalls from
+	// (the session's active topic or one named in the request), the text
+	// to recall by, and a note the user asked to remember under it.
+	Topic       string `json:"topic,omitempty"`
+	RecallQuery string `json:"recall_query,omitempty"`
+	Note        string `json:"note,omitempty"`
+
+	// Entities are the contact book entries the request mentions.
+	Entities []int64 `json:"entities,omitempty"`
+
+	// Sources are the long-term memories placed in the execution context.
+	Sources []MemorySource `json:"sources,omitempty"`
+
+	// Approvals are the decided approvals a parked task was run again
+	// with (see ResumeWithApproval).
+	Approvals []string `json:"approvals,omitempty"`
+
+	// ResumedFrom is the interrupted run this task continues. Its findings
+	// are in resumed; buffered is set while the task's own findings are
+	// checkpointed to the run buffer.
+	ResumedFrom string `json:"resumed_from,omitempty"`
+	resumed     *memory.PartialRun
+	buffered    bool
+
+	correlationID string // of the input, for partial results
 }
 
 // NewTaskSpec creates a draft TaskSpec from a goal string.
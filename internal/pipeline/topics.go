@@ -0,0 +1,176 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/overhuman/overhuman/internal/memory"
+)
+
+// topicName matches a topic in a request: quoted ('novel draft') or after
+// the word "topic" (topic health). Bare words are not topics, so "remember
+// this for tomorrow" stays an ordinary request.
+const topicName = `(?:(?:the\s+)?topic\s+(?:['"‘“]([^'"’”]{1,60})['"’”]|([\p{L}\p{N}_-]+))|['"‘“]([^'"’”]{1,60})['"’”])`
+
+// Topic directives, matched case-insensitively.
+var (
+	// "switch to topic work", "use topic 'health'", "topic: novel-draft"
+	topicSwitchRe = regexp.MustCompile(`(?i)^\s*(?:(?:switch|change|go|move)\s+(?:over\s+)?to|use|set|open|enter)\s+` + topicName + `[\s:.,;!-]*`)
+	topicColonRe  = regexp.MustCompile(`(?i)^\s*topic:\s*([\p{L}\p{N}_ -]{1,60}?)\s*(?:$|\n)`)
+	// "leave the topic", "clear topic", "no topic"
+	topicClearRe = regexp.MustCompile(`(?i)^\s*(?:(?:leave|exit|clear|close|drop|end)\s+(?:the\s+)?(?:current\s+)?topic|no\s+topic)\b[\s.!]*`)
+	// "remember this under 'novel-draft': ..."
+	topicRememberRe = regexp.MustCompile(`(?i)\bremember\s+(?:this|that|it)?\s*(?:under|in|for|as)\s+` + topicName + `[\s:.,;!-]*`)
+	// "recall from topic health: ...", "what do you remember about 'work'"
+	topicRecallRe = regexp.MustCompile(`(?i)\b(?:recall|what\s+do\s+(?:you|i|we)\s+(?:remember|have|know))\s+(?:from|in|under|about|on)\s+` + topicName + `[\s:.,;?!-]*`)
+)
+
+// topicDirective is what a request asks of the memory topics.
+type topicDirective struct {
+	kind  string // "switch", "clear", "remember", "recall" or ""
+	topic string // normalized
+	rest  string // the request without the directive
+}
+
+// parseTopicDirective finds a topic directive in text.
+func parseTopicDirective(text string) topicDirective {
+	if m := topicClearRe.FindStringIndex(text); m != nil {
+		return topicDirective{kind: "clear", rest: strings.TrimSpace(text[m[1]:])}
+	}
+	if m := topicSwitchRe.FindStringSubmatchIndex(text); m != nil {
+		return topicDirective{kind: "switch", topic: matchedTopic(text, m), rest: strings.TrimSpace(text[m[1]:])}
+	}
+	if m := topicColonRe.FindStringSubmatchIndex(text); m != nil {
+		return topicDirective{kind: "switch", topic: memory.NormalizeTopic(text[m[2]:m[3]]), rest: strings.TrimSpace(text[m[1]:])}
+	}
+	if m := topicRememberRe.FindStringSubmatchIndex(text); m != nil {
+		return topicDirective{kind: "remember", topic: matchedTopic(text, m), rest: cutSpan(text, m)}
+	}
+	if m := topicRecallRe.FindStringSubmatchIndex(text); m != nil {
+		return topicDirective{kind: "recall", topic: matchedTopic(text, m), rest: cutSpan(text, m)}
+	}
+	return topicDirective{}
+}
+
+// matchedTopic returns the normalized topic from a topicName match.
+func matchedTopic(text string, m []int) string {
+	for g := 1; 2*g+1 < len(m); g++ {
+		if m[2*g] >= 0 {
+			return memory.NormalizeTopic(text[m[2*g]:m[2*g+1]])
+		}
+	}
+	return ""
+}
+
+// cutSpan removes the matched directive from text, keeping what comes
+// before and after it ("The villain is the uncle — remember this under
+// 'novel'" → "The villain is the uncle").
+func cutSpan(text string, m []int) string {
+	before := strings.TrimRight(text[:m[0]], " \t\n-—–:,;.")
+	after := strings.TrimSpace(text[m[1]:])
+	return strings.TrimSpace(before + " " + after)
+}
+
+// sessionTopics holds the active memory topic per conversation.
+type sessionTopics struct {
+	mu     sync.Mutex
+	active map[string]string
+}
+
+func (s *sessionTopics) get(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active[key]
+}
+
+func (s *sessionTopics) set(key, topic string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if topic == "" {
+		delete(s.active, key)
+		return
+	}
+	if s.active == nil {
+		s.active = make(map[string]string)
+	}
+	s.active[key] = topic
+}
+
+// topicSession identifies the conversation ts belongs to: its session, or
+// the sender on the channel for senses without sessions.
+func topicSession(ts *TaskSpec) string {
+	if ts.SessionID != "" {
+		return ts.SessionID
+	}
+	return ts.SourceChannel + "/" + ts.SourceUserID
+}
+
+// ActiveTopic returns the memory topic active for a session ("" = none).
+// Senses without sessions are keyed by channel and sender, as
+// "TELEGRAM/12345".
+func (p *Pipeline) ActiveTopic(session string) string {
+	return p.topics.get(session)
+}
+
+// applyTopic resolves the task's memory topic from a directive in the goal
+// or the session's active topic (none for stateless requests). A request that only switches or clears
+// the topic is answered directly and returned as a result; otherwise nil.
+func (p *Pipeline) applyTopic(ts *TaskSpec) *RunResult {
+	if ts.Stateless {
+		return nil
+	}
+	session := topicSession(ts)
+	d := parseTopicDirective(ts.Goal)
+	switch d.kind {
+	case "switch", "clear":
+		if d.kind == "switch" && d.topic == "" {
+			break
+		}
+		p.topics.set(session, d.topic)
+		p.logInfo("memory topic changed", "session", session, "topic", d.topic)
+		if d.rest != "" {
+			ts.Goal = d.rest
+			break
+		}
+		msg := "Left the topic; memories are no longer scoped."
+		if d.topic != "" {
+			msg = fmt.Sprintf("Now in topic %q: new memories are filed under it and recall is limited to it. Say \"leave the topic\" to stop.", d.topic)
+		}
+		return &RunResult{TaskID: ts.ID, Success: true, Result: msg}
+	case "remember":
+		ts.Topic, ts.Note = d.topic, d.rest
+		return nil
+	case "recall":
+		ts.Topic, ts.RecallQuery = d.topic, d.rest
+		return nil
+	}
+	ts.Topic = p.topics.get(session)
+	if ts.Topic != "" {
+		ts.RecallQuery = ts.Goal
+	}
+	return nil
+}
+
+// topicMemory recalls entries from the task's topic for the execution
+// context. Without a topic nothing is recalled.
+func (p *Pipeline) topicMemory(ts *TaskSpec) []string {
+	if ts.Topic == "" || ts.Note != "" || p.deps.LongTerm == nil {
+		return nil
+	}
+	entries, err := p.deps.LongTerm.Recall(viewer(ts), ts.Topic, ts.RecallQuery, topicRecallLimit)
+	if err != nil {
+		p.logWarn("topic recall failed", "topic", ts.Topic, "error", err.Error())
+		return nil
+	}
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, fmt.Sprintf("[%s, %s] %s", e.Topic, e.CreatedAt.Format("2006-01-02"), e.Summary))
+		ts.cite(e, "topic")
+	}
+	return out
+}
+
+// topicRecallLimit bounds the memories added to the execution context.
+const topicRecallLimit = 8
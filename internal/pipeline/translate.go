@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// languageNames names the languages senses.DetectLanguage recognizes, for
+// translation prompts.
+var languageNames = map[string]string{
+	"en": "English", "de": "German", "fr": "French", "es": "Spanish",
+	"it": "Italian", "pt": "Portuguese", "nl": "Dutch", "ru": "Russian",
+	"uk": "Ukrainian", "zh": "Chinese", "ja": "Japanese", "ko": "Korean",
+	"el": "Greek", "ar": "Arabic", "he": "Hebrew", "hi": "Hindi", "th": "Thai",
+}
+
+func languageName(code string) string {
+	if name := languageNames[code]; name != "" {
+		return name
+	}
+	return code
+}
+
+// Translate translates text into the language to on the cheap tier, for
+// the input preprocessor (it implements senses.Translator). The cost is
+// recorded against the "preprocess" budget entry.
+func (p *Pipeline) Translate(ctx context.Context, text, from, to string) (string, error) {
+	source := "its original language"
+	if from != "" {
+		source = languageName(from)
+	}
+	messages := p.deps.Context.Assemble(brain.ContextLayers{
+		TaskDescription: fmt.Sprintf(
+			"Translate the following message from %s into %s. Keep names, numbers, links and formatting. Respond with the translation only.\n\n%s",
+			source, languageName(to), text),
+	})
+	resp, err := p.deps.LLM.Complete(ctx, brain.LLMRequest{
+		Messages: messages,
+		Model:    p.deps.Router.Select("simple", 0),
+	})
+	if err != nil {
+		return "", err
+	}
+	if p.deps.Budget != nil {
+		p.deps.Budget.Record("preprocess", resp.CostUSD)
+	}
+	p.incrementMetric("preprocess.translations")
+	return strings.TrimSpace(resp.Content), nil
+}
@@ -0,0 +1,202 @@
+package pipeline
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Warm-start defaults.
+const (
+	DefaultWarmStartRuns    = 5   // pattern repetitions before a plan is reused
+	DefaultWarmStartQuality = 0.8 // minimum average and per-run review score
+	DefaultWarmStartReuses  = 20  // reuses before stages 2-3 run again
+	DefaultWarmStartMaxAge  = 24 * time.Hour
+)
+
+// WarmStart caches the clarification and plan of well-established task
+// patterns so repeated runs skip the stage 2-3 LLM calls. A plan is cached
+// once its fingerprint has run MinRuns times with an average quality of at
+// least MinQuality, and is refreshed after Reuses reuses or MaxAge, or
+// dropped as soon as a warm run reviews below MinQuality. Entries are
+// per sender, since the clarifier sees the sender's local time. Safe for
+// concurrent use; the zero value is not usable, use NewWarmStart.
+type WarmStart struct {
+	MinRuns    int
+	MinQuality float64
+	Reuses     int
+	MaxAge     time.Duration
+
+	mu      sync.Mutex
+	entries map[warmKey]*warmPlan
+	now     func() time.Time
+}
+
+// qualityEpsilon absorbs rounding in the pattern tracker's running
+// average, so a pattern scored 0.8 every time meets a 0.8 bar.
+const qualityEpsilon = 1e-9
+
+type warmKey struct {
+	fingerprint string
+	sender      string
+}
+
+// warmPlan is what stages 2-3 produced for a task.
+type warmPlan struct {
+	context         string
+	interpretations []string
+	subtasks        []SubtaskSpec
+	cachedAt        time.Time
+	uses            int
+}
+
+// NewWarmStart returns a cache that reuses plans after minRuns repetitions
+// (0 = DefaultWarmStartRuns), with the default quality bar and refresh
+// period.
+func NewWarmStart(minRuns int) *WarmStart {
+	if minRuns <= 0 {
+		minRuns = DefaultWarmStartRuns
+	}
+	return &WarmStart{
+		MinRuns:    minRuns,
+		MinQuality: DefaultWarmStartQuality,
+		Reuses:     DefaultWarmStartReuses,
+		MaxAge:     DefaultWarmStartMaxAge,
+		entries:    make(map[warmKey]*warmPlan),
+		now:        time.Now,
+	}
+}
+
+// Len returns the number of cached plans.
+func (w *WarmStart) Len() int {
+	if w == nil {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.entries)
+}
+
+// apply fills ts from a cached plan and reports whether there was one. An
+// entry due for refresh is dropped so the run plans afresh.
+func (w *WarmStart) apply(ts *TaskSpec) bool {
+	if w == nil || ts.Fingerprint == "" {
+		return false
+	}
+	key := warmKey{ts.Fingerprint, ts.SourceUserID}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	e, ok := w.entries[key]
+	if !ok {
+		return false
+	}
+	if e.uses >= w.Reuses || w.now().Sub(e.cachedAt) >= w.MaxAge {
+		delete(w.entries, key)
+		return false
+	}
+	e.uses++
+
+	ts.Context = e.context
+	ts.Interpretations = append([]string(nil), e.interpretations...)
+	ts.Advance(TaskStatusClarified)
+	ts.Subtasks = make([]SubtaskSpec, len(e.subtasks))
+	for i, sub := range e.subtasks {
+		sub.ID = rebaseID(ts.ID, sub.ID)
+		sub.DependsOn = mapIDs(sub.DependsOn, func(id string) string { return rebaseID(ts.ID, id) })
+		ts.Subtasks[i] = sub
+	}
+	ts.Advance(TaskStatusPlanned)
+	return true
+}
+
+// capture snapshots ts right after planning, before execution fills in
+// results. Subtask IDs derived from the task ID ("task_1_sub1") are stored
+// without it ("_sub1") and rebased onto the next task's ID.
+func (w *WarmStart) capture(ts *TaskSpec) *warmPlan {
+	if w == nil {
+		return nil
+	}
+	plan := &warmPlan{
+		context:         ts.Context,
+		interpretations: append([]string(nil), ts.Interpretations...),
+		subtasks:        make([]SubtaskSpec, len(ts.Subtasks)),
+	}
+	for i, sub := range ts.Subtasks {
+		plan.subtasks[i] = SubtaskSpec{
+			ID:         strings.TrimPrefix(sub.ID, ts.ID),
+			Goal:       sub.Goal,
+			AssignedTo: sub.AssignedTo,
+			Status:     TaskStatusDraft,
+			DependsOn:  mapIDs(sub.DependsOn, func(id string) string { return strings.TrimPrefix(id, ts.ID) }),
+		}
+	}
+	return plan
+}
+
+// observe updates the cache after a run: a fresh plan is stored when the
+// pattern qualifies, and a warm plan is dropped when its run scored low.
+func (w *WarmStart) observe(ts *TaskSpec, plan *warmPlan, warm bool, runs int, avgQuality float64) {
+	if w == nil || ts.Fingerprint == "" {
+		return
+	}
+	key := warmKey{ts.Fingerprint, ts.SourceUserID}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ts.QualityScore < w.MinQuality-qualityEpsilon {
+		delete(w.entries, key)
+		return
+	}
+	if warm || plan == nil || runs < w.MinRuns || avgQuality < w.MinQuality-qualityEpsilon {
+		return
+	}
+	plan.cachedAt = w.now()
+	w.entries[key] = plan
+}
+
+// rebaseID prefixes a stored relative subtask ID with taskID.
+func rebaseID(taskID, id string) string {
+	if strings.HasPrefix(id, "_") {
+		return taskID + id
+	}
+	return id
+}
+
+func mapIDs(ids []string, fn func(string) string) []string {
+	if ids == nil {
+		return nil
+	}
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = fn(id)
+	}
+	return out
+}
+
+// applyWarmStart fills ts from the warm-start cache and reports whether
+// stages 2-3 can be skipped.
+func (p *Pipeline) applyWarmStart(ts *TaskSpec) bool {
+	if p.deps.WarmStart == nil || p.deps.Patterns == nil {
+		return false
+	}
+	if ts.Fingerprint == "" {
+		ts.Fingerprint = p.routingFingerprint(ts)
+	}
+	if !p.deps.WarmStart.apply(ts) {
+		return false
+	}
+	p.incrementMetric("pipeline.warm_starts")
+	return true
+}
+
+// observeWarmStart feeds a finished run's review score and its pattern's
+// history back into the warm-start cache.
+func (p *Pipeline) observeWarmStart(ts *TaskSpec, fresh *warmPlan, warm bool) {
+	if p.deps.WarmStart == nil || p.deps.Patterns == nil {
+		return
+	}
+	entry, err := p.deps.Patterns.Get(ts.Fingerprint)
+	if err != nil {
+		return
+	}
+	p.deps.WarmStart.observe(ts, fresh, warm, entry.Count, entry.AvgQuality)
+}
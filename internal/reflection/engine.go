@@ -13,8 +13,11 @@ package reflection
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/overhuman/overhuman/internal/brain"
 	"github.com/overhuman/overhuman/internal/memory"
@@ -32,6 +35,11 @@ type RunSummary struct {
 	ElapsedMs     int64
 	Fingerprint   string
 	SourceChannel string
+
+	// SourceUserID and Visibility scope the stored insight like the run's
+	// own memory entry (see memory.Viewer).
+	SourceUserID string
+	Visibility   memory.Visibility
 }
 
 // MesoInsight is the output of a meso-reflection cycle.
@@ -43,6 +51,18 @@ type MesoInsight struct {
 	SkillSuggestion string `json:"skill_suggestion,omitempty"` // If non-empty, suggests a new skill.
 }
 
+// mesoSchema is the JSON Schema of MesoInsight.
+var mesoSchema = brain.Schema{
+	Name:        "meso_reflection",
+	Description: "Insights from one task run.",
+	JSON: json.RawMessage(`{"type": "object", "properties": {
+		"went_well": {"type": "array", "items": {"type": "string"}},
+		"improvements": {"type": "array", "items": {"type": "string"}},
+		"soul_suggestion": {"type": "string"},
+		"skill_suggestion": {"type": "string"}
+	}, "required": ["went_well", "improvements"]}`),
+}
+
 // Engine orchestrates all reflection loops.
 type Engine struct {
 	llm     brain.LLMProvider
@@ -50,8 +70,10 @@ type Engine struct {
 	ctx     *brain.ContextAssembler
 	longMem *memory.LongTermMemory
 
-	// macroThreshold triggers macro-reflection every N runs.
+	// macroThreshold triggers macro-reflection every N runs. Runs may
+	// reflect concurrently, so mu guards the count.
 	macroThreshold int
+	mu             sync.Mutex
 	runsSinceMacro int
 }
 
@@ -89,11 +111,8 @@ Review notes: %s
 Cost: $%.4f
 Time: %dms
 
-Analyze and respond in EXACTLY this format:
-WENT_WELL: <comma-separated list>
-IMPROVEMENTS: <comma-separated list>
-SOUL_SUGGESTION: <one-line suggestion for the soul/strategy update, or NONE>
-SKILL_SUGGESTION: <one-line suggestion for a new skill to build, or NONE>`,
+Analyze and respond with only a JSON object:
+{"went_well": ["<what went well>", ...], "improvements": ["<what to improve>", ...], "soul_suggestion": "<one-line suggestion for the soul/strategy update, or empty>", "skill_suggestion": "<one-line suggestion for a new skill to build, or empty>"}`,
 		summary.Goal,
 		summary.QualityScore,
 		summary.ReviewNotes,
@@ -108,16 +127,17 @@ SKILL_SUGGESTION: <one-line suggestion for a new skill to build, or NONE>`,
 
 	// Use cheapest model for reflection.
 	model := e.router.Select("simple", 100.0)
-	resp, err := e.llm.Complete(ctx, brain.LLMRequest{
+	insight := &MesoInsight{}
+	resp, err := brain.StructuredComplete(ctx, e.llm, brain.LLMRequest{
 		Messages:  messages,
 		Model:     model,
 		MaxTokens: 512,
-	})
-	if err != nil {
+	}, mesoSchema, insight)
+	if err != nil && !errors.Is(err, brain.ErrUnstructured) {
 		return nil, 0, fmt.Errorf("meso reflection: %w", err)
 	}
-
-	insight := parseMesoResponse(summary.TaskID, resp.Content)
+	// An unreadable reply leaves the insight empty.
+	insight.TaskID = summary.TaskID
 
 	// Store insight in long-term memory.
 	var tags []string
@@ -131,38 +151,57 @@ SKILL_SUGGESTION: <one-line suggestion for a new skill to build, or NONE>`,
 		Summary:     fmt.Sprintf("Meso-reflection: well=[%s] improve=[%s]", strings.Join(insight.WentWell, "; "), strings.Join(insight.Improvements, "; ")),
 		Tags:        tags,
 		SourceRunID: summary.TaskID,
+		Owner:       summary.SourceUserID,
+		Channel:     summary.SourceChannel,
+		Visibility:  summary.Visibility,
 	})
 
 	// Track runs for macro-reflection trigger.
+	e.mu.Lock()
 	e.runsSinceMacro++
+	e.mu.Unlock()
 
 	return insight, resp.CostUSD, nil
 }
 
 // ShouldRunMacro returns true if enough runs have passed to warrant macro-reflection.
 func (e *Engine) ShouldRunMacro() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.runsSinceMacro >= e.macroThreshold
 }
 
 // ResetMacroCounter resets the run counter after macro-reflection.
 func (e *Engine) ResetMacroCounter() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.runsSinceMacro = 0
 }
 
 // RunsSinceMacro returns the current count.
 func (e *Engine) RunsSinceMacro() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.runsSinceMacro
 }
 
 // parseMesoResponse extracts structured insight from the LLM response text.
 func parseMesoResponse(taskID, text string) *MesoInsight {
 	insight := &MesoInsight{TaskID: taskID}
+	insight.ParseText(text)
+	return insight
+}
 
+// ParseText implements brain.TextReply for the older "WENT_WELL: ..."
+// lines.
+func (insight *MesoInsight) ParseText(text string) bool {
+	found := false
 	for _, line := range strings.Split(text, "\n") {
 		line = strings.TrimSpace(line)
 
 		switch {
 		case strings.HasPrefix(line, "WENT_WELL:"):
+			found = true
 			raw := strings.TrimPrefix(line, "WENT_WELL:")
 			for _, item := range strings.Split(raw, ",") {
 				item = strings.TrimSpace(item)
@@ -171,6 +210,7 @@ func parseMesoResponse(taskID, text string) *MesoInsight {
 				}
 			}
 		case strings.HasPrefix(line, "IMPROVEMENTS:"):
+			found = true
 			raw := strings.TrimPrefix(line, "IMPROVEMENTS:")
 			for _, item := range strings.Split(raw, ",") {
 				item = strings.TrimSpace(item)
@@ -191,5 +231,5 @@ func parseMesoResponse(taskID, text string) *MesoInsight {
 		}
 	}
 
-	return insight
+	return found
 }
@@ -2,7 +2,6 @@ package reflection
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,48 +10,9 @@ import (
 
 	"github.com/overhuman/overhuman/internal/brain"
 	"github.com/overhuman/overhuman/internal/memory"
+	"github.com/overhuman/overhuman/overhumantest"
 )
 
-// mockLLM creates a test server that returns a meso-formatted response.
-func mockLLM(t *testing.T, responseText string) *httptest.Server {
-	t.Helper()
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-
-		type contentBlock struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		}
-		type resp struct {
-			ID      string         `json:"id"`
-			Type    string         `json:"type"`
-			Role    string         `json:"role"`
-			Model   string         `json:"model"`
-			Content []contentBlock `json:"content"`
-			StopReason string     `json:"stop_reason"`
-			Usage   struct {
-				InputTokens  int `json:"input_tokens"`
-				OutputTokens int `json:"output_tokens"`
-			} `json:"usage"`
-		}
-
-		r2 := resp{
-			ID:    "msg_refl",
-			Type:  "message",
-			Role:  "assistant",
-			Model: "claude-haiku-3-5-20241022",
-			Content: []contentBlock{
-				{Type: "text", Text: responseText},
-			},
-			StopReason: "end_turn",
-		}
-		r2.Usage.InputTokens = 30
-		r2.Usage.OutputTokens = 20
-
-		json.NewEncoder(w).Encode(r2)
-	}))
-}
-
 func setupEngine(t *testing.T, srvURL string) (*Engine, *memory.LongTermMemory) {
 	t.Helper()
 
@@ -76,7 +36,7 @@ func setupEngine(t *testing.T, srvURL string) (*Engine, *memory.LongTermMemory)
 }
 
 func TestMeso_BasicReflection(t *testing.T) {
-	srv := mockLLM(t, `WENT_WELL: fast execution, correct result
+	srv := overhumantest.NewMockProvider(t, `WENT_WELL: fast execution, correct result
 IMPROVEMENTS: could cache results, reduce LLM calls
 SOUL_SUGGESTION: Add caching strategy
 SKILL_SUGGESTION: Create summarization cache skill`)
@@ -121,7 +81,7 @@ SKILL_SUGGESTION: Create summarization cache skill`)
 }
 
 func TestMeso_StoresInLongTermMemory(t *testing.T) {
-	srv := mockLLM(t, `WENT_WELL: good
+	srv := overhumantest.NewMockProvider(t, `WENT_WELL: good
 IMPROVEMENTS: none
 SOUL_SUGGESTION: NONE
 SKILL_SUGGESTION: NONE`)
@@ -150,7 +110,7 @@ SKILL_SUGGESTION: NONE`)
 }
 
 func TestMeso_NoSuggestionWhenNone(t *testing.T) {
-	srv := mockLLM(t, `WENT_WELL: everything
+	srv := overhumantest.NewMockProvider(t, `WENT_WELL: everything
 IMPROVEMENTS: nothing
 SOUL_SUGGESTION: NONE
 SKILL_SUGGESTION: NONE`)
@@ -175,7 +135,7 @@ SKILL_SUGGESTION: NONE`)
 }
 
 func TestMacroThreshold(t *testing.T) {
-	srv := mockLLM(t, "WENT_WELL: ok\nIMPROVEMENTS: none\nSOUL_SUGGESTION: NONE\nSKILL_SUGGESTION: NONE")
+	srv := overhumantest.NewMockProvider(t, "WENT_WELL: ok\nIMPROVEMENTS: none\nSOUL_SUGGESTION: NONE\nSKILL_SUGGESTION: NONE")
 	defer srv.Close()
 
 	engine, _ := setupEngine(t, srv.URL)
@@ -293,3 +253,21 @@ func TestMeso_LLMError(t *testing.T) {
 		t.Errorf("error should mention meso reflection, got: %v", err)
 	}
 }
+
+func TestMeso_StructuredReply(t *testing.T) {
+	srv := overhumantest.NewMockProvider(t, "Overall a decent run.",
+		`{"went_well": ["cited sources"], "improvements": ["shorter intro", "fewer calls"], "soul_suggestion": "", "skill_suggestion": "cache summaries"}`)
+
+	engine, _ := setupEngine(t, srv.URL)
+	insight, cost, err := engine.Meso(context.Background(), "", RunSummary{TaskID: "task_9", Goal: "Summarize article"})
+	if err != nil {
+		t.Fatalf("Meso: %v", err)
+	}
+	if insight.TaskID != "task_9" || len(insight.WentWell) != 1 || len(insight.Improvements) != 2 || insight.SkillSuggestion != "cache summaries" {
+		t.Errorf("insight = %+v", insight)
+	}
+	// The unreadable first reply was asked for again, and both were paid for.
+	if srv.Calls() != 2 || !strings.Contains(srv.Requests()[1].LastUser(), "could not be read") || cost <= 0 {
+		t.Errorf("calls = %d, cost = %v", srv.Calls(), cost)
+	}
+}
@@ -2,6 +2,8 @@ package reflection
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -30,6 +32,19 @@ type MacroInsight struct {
 	ThresholdChanges []string `json:"threshold_changes"`
 }
 
+// macroSchema is the JSON Schema of MacroInsight.
+var macroSchema = brain.Schema{
+	Name:        "macro_reflection",
+	Description: "Adjustments to the agent's strategy.",
+	JSON: json.RawMessage(`{"type": "object", "properties": {
+		"strategy_changes": {"type": "array", "items": {"type": "string"}},
+		"soul_updates": {"type": "array", "items": {"type": "string"}},
+		"new_goals": {"type": "array", "items": {"type": "string"}},
+		"skills_to_generate": {"type": "array", "items": {"type": "string"}},
+		"threshold_changes": {"type": "array", "items": {"type": "string"}}
+	}, "required": ["strategy_changes", "soul_updates", "new_goals", "skills_to_generate", "threshold_changes"]}`),
+}
+
 // Macro performs meta-reflection over recent agent performance.
 // It evaluates whether the agent's strategies are still effective
 // and suggests higher-level adjustments.
@@ -52,12 +67,8 @@ As a meta-analyst, evaluate:
 4. Which skills should be generated or improved?
 5. Should any thresholds be adjusted?
 
-Respond in EXACTLY this format:
-STRATEGY_CHANGES: <comma-separated list, or NONE>
-SOUL_UPDATES: <comma-separated list, or NONE>
-NEW_GOALS: <comma-separated list, or NONE>
-SKILLS_TO_GENERATE: <comma-separated list, or NONE>
-THRESHOLD_CHANGES: <comma-separated list, or NONE>`,
+Respond with only a JSON object; leave a list empty when there is nothing to change:
+{"strategy_changes": [...], "soul_updates": [...], "new_goals": [...], "skills_to_generate": [...], "threshold_changes": [...]}`,
 		summary.TotalRuns,
 		summary.AvgQuality,
 		summary.AvgCostUSD,
@@ -75,20 +86,19 @@ THRESHOLD_CHANGES: <comma-separated list, or NONE>`,
 
 	// Macro-reflection uses a mid-tier model for deeper analysis.
 	model := e.router.Select("moderate", 100.0)
-	resp, err := e.llm.Complete(ctx, brain.LLMRequest{
+	insight := &MacroInsight{}
+	resp, err := brain.StructuredComplete(ctx, e.llm, brain.LLMRequest{
 		Messages:  messages,
 		Model:     model,
 		MaxTokens: 1024,
-	})
-	if err != nil {
+	}, macroSchema, insight)
+	if err != nil && !errors.Is(err, brain.ErrUnstructured) {
 		return nil, 0, fmt.Errorf("macro reflection: %w", err)
 	}
 
-	insight := parseMacroResponse(resp.Content)
-
 	// Store in long-term memory.
 	e.longMem.Store(memory.LongTermEntry{
-		ID:      fmt.Sprintf("macro_%d", e.runsSinceMacro),
+		ID:      fmt.Sprintf("macro_%d", e.RunsSinceMacro()),
 		Summary: fmt.Sprintf("Macro-reflection: strategies=[%s] goals=[%s]", strings.Join(insight.StrategyChanges, "; "), strings.Join(insight.NewGoals, "; ")),
 		Tags:    []string{"reflection", "macro"},
 	})
@@ -99,7 +109,14 @@ THRESHOLD_CHANGES: <comma-separated list, or NONE>`,
 
 func parseMacroResponse(text string) *MacroInsight {
 	insight := &MacroInsight{}
+	insight.ParseText(text)
+	return insight
+}
 
+// ParseText implements brain.TextReply for the older "STRATEGY_CHANGES:
+// ..." lines.
+func (insight *MacroInsight) ParseText(text string) bool {
+	found := false
 	for _, line := range strings.Split(text, "\n") {
 		line = strings.TrimSpace(line)
 
@@ -120,17 +137,22 @@ func parseMacroResponse(text string) *MacroInsight {
 
 		switch {
 		case strings.HasPrefix(line, "STRATEGY_CHANGES:"):
+			found = true
 			insight.StrategyChanges = parseList("STRATEGY_CHANGES:")
 		case strings.HasPrefix(line, "SOUL_UPDATES:"):
+			found = true
 			insight.SoulUpdates = parseList("SOUL_UPDATES:")
 		case strings.HasPrefix(line, "NEW_GOALS:"):
+			found = true
 			insight.NewGoals = parseList("NEW_GOALS:")
 		case strings.HasPrefix(line, "SKILLS_TO_GENERATE:"):
+			found = true
 			insight.SkillsToGenerate = parseList("SKILLS_TO_GENERATE:")
 		case strings.HasPrefix(line, "THRESHOLD_CHANGES:"):
+			found = true
 			insight.ThresholdChanges = parseList("THRESHOLD_CHANGES:")
 		}
 	}
 
-	return insight
+	return found
 }
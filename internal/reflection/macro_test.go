@@ -4,10 +4,12 @@ import (
 	"context"
 	"strings"
 	"testing"
+
+	"github.com/overhuman/overhuman/overhumantest"
 )
 
 func TestMacro_BasicReflection(t *testing.T) {
-	srv := mockLLM(t, `STRATEGY_CHANGES: focus on caching, reduce LLM reliance
+	srv := overhumantest.NewMockProvider(t, `STRATEGY_CHANGES: focus on caching, reduce LLM reliance
 SOUL_UPDATES: add cost-efficiency principle
 NEW_GOALS: build top-5 pattern skills, reduce avg cost below $0.01
 SKILLS_TO_GENERATE: article-summarizer, date-formatter
@@ -53,7 +55,7 @@ THRESHOLD_CHANGES: raise quality threshold to 0.85`)
 }
 
 func TestMacro_ResetsCounter(t *testing.T) {
-	srv := mockLLM(t, "STRATEGY_CHANGES: NONE\nSOUL_UPDATES: NONE\nNEW_GOALS: NONE\nSKILS_TO_GENERATE: NONE\nTHRESHOLD_CHANGES: NONE")
+	srv := overhumantest.NewMockProvider(t, "STRATEGY_CHANGES: NONE\nSOUL_UPDATES: NONE\nNEW_GOALS: NONE\nSKILS_TO_GENERATE: NONE\nTHRESHOLD_CHANGES: NONE")
 	defer srv.Close()
 
 	engine, _ := setupEngine(t, srv.URL)
@@ -86,7 +88,7 @@ func TestMacro_ResetsCounter(t *testing.T) {
 }
 
 func TestMacro_StoresInLongTermMemory(t *testing.T) {
-	srv := mockLLM(t, "STRATEGY_CHANGES: improve caching\nSOUL_UPDATES: NONE\nNEW_GOALS: build cache skill\nSKILLS_TO_GENERATE: NONE\nTHRESHOLD_CHANGES: NONE")
+	srv := overhumantest.NewMockProvider(t, "STRATEGY_CHANGES: improve caching\nSOUL_UPDATES: NONE\nNEW_GOALS: build cache skill\nSKILLS_TO_GENERATE: NONE\nTHRESHOLD_CHANGES: NONE")
 	defer srv.Close()
 
 	engine, ltm := setupEngine(t, srv.URL)
@@ -106,7 +108,7 @@ func TestMacro_StoresInLongTermMemory(t *testing.T) {
 }
 
 func TestMacro_LLMError(t *testing.T) {
-	srv := mockLLM(t, "") // We'll override with a failing server.
+	srv := overhumantest.NewMockProvider(t, "") // We'll override with a failing server.
 	srv.Close()
 
 	// Use a closed server URL to force connection error.
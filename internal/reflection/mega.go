@@ -2,6 +2,8 @@ package reflection
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -30,6 +32,19 @@ type MegaInsight struct {
 	ProcessChanges          []string `json:"process_changes"`          // Higher-level process improvements
 }
 
+// megaSchema is the JSON Schema of MegaInsight.
+var megaSchema = brain.Schema{
+	Name:        "mega_reflection",
+	Description: "Adjustments to the reflection process.",
+	JSON: json.RawMessage(`{"type": "object", "properties": {
+		"reflection_effectiveness": {"type": "string"},
+		"meso_adjustments": {"type": "array", "items": {"type": "string"}},
+		"macro_adjustments": {"type": "array", "items": {"type": "string"}},
+		"threshold_adjustments": {"type": "array", "items": {"type": "string"}},
+		"process_changes": {"type": "array", "items": {"type": "string"}}
+	}, "required": ["reflection_effectiveness", "meso_adjustments", "macro_adjustments", "threshold_adjustments", "process_changes"]}`),
+}
+
 // Mega performs reflection on the reflection process itself.
 // This is the highest-level feedback loop — it asks "Are my reflection loops
 // actually helping me improve?" and adjusts the reflection process.
@@ -53,12 +68,8 @@ Evaluate:
 3. Are the reflection thresholds (when to trigger) appropriate?
 4. What changes to the reflection process itself would improve outcomes?
 
-Respond in EXACTLY this format:
-EFFECTIVENESS: <one-line assessment>
-MESO_ADJUSTMENTS: <comma-separated list, or NONE>
-MACRO_ADJUSTMENTS: <comma-separated list, or NONE>
-THRESHOLD_ADJUSTMENTS: <comma-separated list, or NONE>
-PROCESS_CHANGES: <comma-separated list, or NONE>`,
+Respond with only a JSON object; leave a list empty when there is nothing to change:
+{"reflection_effectiveness": "<one-line assessment>", "meso_adjustments": [...], "macro_adjustments": [...], "threshold_adjustments": [...], "process_changes": [...]}`,
 		summary.TotalMesoRuns,
 		summary.TotalMacroRuns,
 		summary.MesoInsightsActedOn,
@@ -76,17 +87,16 @@ PROCESS_CHANGES: <comma-separated list, or NONE>`,
 
 	// Mega-reflection uses a strong model for deep analysis.
 	model := e.router.Select("complex", 100.0)
-	resp, err := e.llm.Complete(ctx, brain.LLMRequest{
+	insight := &MegaInsight{}
+	resp, err := brain.StructuredComplete(ctx, e.llm, brain.LLMRequest{
 		Messages:  messages,
 		Model:     model,
 		MaxTokens: 1024,
-	})
-	if err != nil {
+	}, megaSchema, insight)
+	if err != nil && !errors.Is(err, brain.ErrUnstructured) {
 		return nil, 0, fmt.Errorf("mega reflection: %w", err)
 	}
 
-	insight := parseMegaResponse(resp.Content)
-
 	// Store in long-term memory.
 	e.longMem.Store(memory.LongTermEntry{
 		ID:      fmt.Sprintf("mega_%d_%d", summary.TotalMesoRuns, summary.TotalMacroRuns),
@@ -100,6 +110,14 @@ PROCESS_CHANGES: <comma-separated list, or NONE>`,
 // parseMegaResponse extracts a MegaInsight from LLM text.
 func parseMegaResponse(text string) *MegaInsight {
 	insight := &MegaInsight{}
+	insight.ParseText(text)
+	return insight
+}
+
+// ParseText implements brain.TextReply for the older "EFFECTIVENESS: ..."
+// lines.
+func (insight *MegaInsight) ParseText(text string) bool {
+	found := false
 
 	parseList := func(line, prefix string) []string {
 		raw := strings.TrimSpace(strings.TrimPrefix(line, prefix))
@@ -121,17 +139,22 @@ func parseMegaResponse(text string) *MegaInsight {
 
 		switch {
 		case strings.HasPrefix(line, "EFFECTIVENESS:"):
+			found = true
 			insight.ReflectionEffectiveness = strings.TrimSpace(strings.TrimPrefix(line, "EFFECTIVENESS:"))
 		case strings.HasPrefix(line, "MESO_ADJUSTMENTS:"):
+			found = true
 			insight.MesoAdjustments = parseList(line, "MESO_ADJUSTMENTS:")
 		case strings.HasPrefix(line, "MACRO_ADJUSTMENTS:"):
+			found = true
 			insight.MacroAdjustments = parseList(line, "MACRO_ADJUSTMENTS:")
 		case strings.HasPrefix(line, "THRESHOLD_ADJUSTMENTS:"):
+			found = true
 			insight.ThresholdAdjustments = parseList(line, "THRESHOLD_ADJUSTMENTS:")
 		case strings.HasPrefix(line, "PROCESS_CHANGES:"):
+			found = true
 			insight.ProcessChanges = parseList(line, "PROCESS_CHANGES:")
 		}
 	}
 
-	return insight
+	return found
 }
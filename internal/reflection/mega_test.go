@@ -4,10 +4,12 @@ import (
 	"context"
 	"strings"
 	"testing"
+
+	"github.com/overhuman/overhuman/overhumantest"
 )
 
 func TestMega_BasicReflection(t *testing.T) {
-	srv := mockLLM(t, `EFFECTIVENESS: Meso insights are useful but macro needs improvement
+	srv := overhumantest.NewMockProvider(t, `EFFECTIVENESS: Meso insights are useful but macro needs improvement
 MESO_ADJUSTMENTS: focus more on cost analysis, reduce verbosity
 MACRO_ADJUSTMENTS: increase frequency, add skill-specific analysis
 THRESHOLD_ADJUSTMENTS: lower macro trigger from 10 to 7 runs
@@ -53,7 +55,7 @@ PROCESS_CHANGES: add automated tracking of insight-to-action ratio`)
 }
 
 func TestMega_AllNone(t *testing.T) {
-	srv := mockLLM(t, `EFFECTIVENESS: Everything works well
+	srv := overhumantest.NewMockProvider(t, `EFFECTIVENESS: Everything works well
 MESO_ADJUSTMENTS: NONE
 MACRO_ADJUSTMENTS: NONE
 THRESHOLD_ADJUSTMENTS: NONE
@@ -79,7 +81,7 @@ PROCESS_CHANGES: NONE`)
 }
 
 func TestMega_StoresInLTM(t *testing.T) {
-	srv := mockLLM(t, "EFFECTIVENESS: good\nMESO_ADJUSTMENTS: NONE\nMACRO_ADJUSTMENTS: NONE\nTHRESHOLD_ADJUSTMENTS: NONE\nPROCESS_CHANGES: NONE")
+	srv := overhumantest.NewMockProvider(t, "EFFECTIVENESS: good\nMESO_ADJUSTMENTS: NONE\nMACRO_ADJUSTMENTS: NONE\nTHRESHOLD_ADJUSTMENTS: NONE\nPROCESS_CHANGES: NONE")
 	defer srv.Close()
 
 	engine, ltm := setupEngine(t, srv.URL)
@@ -2,6 +2,8 @@ package reflection
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -37,6 +39,18 @@ type MicroVerdict struct {
 	Suggestion string   `json:"suggestion"`  // How to fix / what to retry
 }
 
+// microSchema is the JSON Schema of MicroVerdict.
+var microSchema = brain.Schema{
+	Name:        "step_check",
+	Description: "Whether a pipeline step's output is adequate.",
+	JSON: json.RawMessage(`{"type": "object", "properties": {
+		"ok": {"type": "boolean"},
+		"confidence": {"type": "number", "minimum": 0, "maximum": 1},
+		"issue": {"type": "string"},
+		"suggestion": {"type": "string"}
+	}, "required": ["ok", "confidence"]}`),
+}
+
 // MicroReflector performs lightweight per-step quality checks.
 // Only triggers on critical steps (clarify, execute, review) and uses
 // the cheapest model possible to minimize cost overhead.
@@ -107,11 +121,8 @@ Step: %s
 Task goal: %s
 Step output (first 500 chars): %.500s
 
-Is this step output adequate for the task goal? Respond in EXACTLY this format:
-OK: YES or NO
-CONFIDENCE: <0.0-1.0>
-ISSUE: <brief issue description, or NONE>
-SUGGESTION: <what to fix or retry, or NONE>`, result.Step, goal, result.Output)
+Is this step output adequate for the task goal? Respond with only a JSON object:
+{"ok": <true or false>, "confidence": <0.0-1.0>, "issue": "<brief issue description, or empty>", "suggestion": "<what to fix or retry, or empty>"}`, result.Step, goal, result.Output)
 
 	messages := m.ctx.Assemble(brain.ContextLayers{
 		SystemPrompt:    "You are a quality checker. Be concise and fast.",
@@ -119,39 +130,50 @@ SUGGESTION: <what to fix or retry, or NONE>`, result.Step, goal, result.Output)
 	})
 
 	model := m.router.Select("simple", 100.0)
-	resp, err := m.llm.Complete(ctx, brain.LLMRequest{
+	// An unreadable reply leaves the step passing with 0.5 confidence.
+	verdict := &MicroVerdict{OK: true, Confidence: 0.5}
+	resp, err := brain.StructuredComplete(ctx, m.llm, brain.LLMRequest{
 		Messages:  messages,
 		Model:     model,
 		MaxTokens: 128,
-	})
-	if err != nil {
+	}, microSchema, verdict)
+	if err != nil && !errors.Is(err, brain.ErrUnstructured) {
 		return nil, 0, fmt.Errorf("micro reflection (%s): %w", result.Step, err)
 	}
-
-	verdict := parseMicroResponse(result.Step, resp.Content)
+	verdict.Step = result.Step
 	return verdict, resp.CostUSD, nil
 }
 
 // parseMicroResponse extracts a MicroVerdict from LLM text.
 func parseMicroResponse(step StepName, text string) *MicroVerdict {
 	v := &MicroVerdict{Step: step, OK: true, Confidence: 0.5}
+	v.ParseText(text)
+	return v
+}
 
+// ParseText implements brain.TextReply for the older "OK: YES" lines.
+func (v *MicroVerdict) ParseText(text string) bool {
+	found := false
 	for _, line := range strings.Split(text, "\n") {
 		line = strings.TrimSpace(line)
 
 		switch {
 		case strings.HasPrefix(line, "OK:"):
+			found = true
 			val := strings.TrimSpace(strings.TrimPrefix(line, "OK:"))
 			v.OK = strings.EqualFold(val, "YES")
 		case strings.HasPrefix(line, "CONFIDENCE:"):
+			found = true
 			val := strings.TrimSpace(strings.TrimPrefix(line, "CONFIDENCE:"))
 			fmt.Sscanf(val, "%f", &v.Confidence)
 		case strings.HasPrefix(line, "ISSUE:"):
+			found = true
 			val := strings.TrimSpace(strings.TrimPrefix(line, "ISSUE:"))
 			if val != "" && val != "NONE" && val != "none" {
 				v.Issue = val
 			}
 		case strings.HasPrefix(line, "SUGGESTION:"):
+			found = true
 			val := strings.TrimSpace(strings.TrimPrefix(line, "SUGGESTION:"))
 			if val != "" && val != "NONE" && val != "none" {
 				v.Suggestion = val
@@ -159,5 +181,5 @@ func parseMicroResponse(step StepName, text string) *MicroVerdict {
 		}
 	}
 
-	return v
+	return found
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"strings"
 	"testing"
+
+	"github.com/overhuman/overhuman/overhumantest"
 )
 
 func TestMicro_CheckPassing(t *testing.T) {
-	srv := mockLLM(t, "OK: YES\nCONFIDENCE: 0.9\nISSUE: NONE\nSUGGESTION: NONE")
+	srv := overhumantest.NewMockProvider(t, "OK: YES\nCONFIDENCE: 0.9\nISSUE: NONE\nSUGGESTION: NONE")
 	defer srv.Close()
 
 	engine, _ := setupEngine(t, srv.URL)
@@ -36,7 +38,7 @@ func TestMicro_CheckPassing(t *testing.T) {
 }
 
 func TestMicro_CheckFailing(t *testing.T) {
-	srv := mockLLM(t, "OK: NO\nCONFIDENCE: 0.8\nISSUE: Output is empty\nSUGGESTION: Retry with more context")
+	srv := overhumantest.NewMockProvider(t, "OK: NO\nCONFIDENCE: 0.8\nISSUE: Output is empty\nSUGGESTION: Retry with more context")
 	defer srv.Close()
 
 	engine, _ := setupEngine(t, srv.URL)
@@ -62,7 +64,7 @@ func TestMicro_CheckFailing(t *testing.T) {
 }
 
 func TestMicro_DisabledStep(t *testing.T) {
-	srv := mockLLM(t, "should not be called")
+	srv := overhumantest.NewMockProvider(t, "should not be called")
 	defer srv.Close()
 
 	engine, _ := setupEngine(t, srv.URL)
@@ -86,7 +88,7 @@ func TestMicro_DisabledStep(t *testing.T) {
 }
 
 func TestMicro_EnableDisable(t *testing.T) {
-	srv := mockLLM(t, "")
+	srv := overhumantest.NewMockProvider(t, "")
 	defer srv.Close()
 
 	engine, _ := setupEngine(t, srv.URL)
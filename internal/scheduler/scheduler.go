@@ -0,0 +1,346 @@
+// Package scheduler runs recurring tasks — "every weekday at 9am summarize
+// my inbox" — defined by the user with a cron expression. Schedules are
+// kept in the long-term memory database; when one is due its task text is
+// sent to the daemon as a timer input and runs through the pipeline like
+// any other request.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+// ExtraSchedule marks inputs the scheduler sent with the schedule's ID.
+const ExtraSchedule = "schedule_id"
+
+// Channel is the SourceMeta.Channel of scheduled inputs.
+const Channel = "schedule"
+
+// ErrNotFound is returned for an unknown schedule ID.
+var ErrNotFound = errors.New("schedule not found")
+
+// Schedule is a recurring task.
+type Schedule struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Cron     string    `json:"cron"` // five-field cron expression, in the agent's timezone
+	Task     string    `json:"task"` // input text sent to the pipeline
+	Disabled bool      `json:"disabled,omitempty"`
+	Created  time.Time `json:"created"`
+	LastRun  time.Time `json:"last_run,omitzero"`
+	NextRun  time.Time `json:"next_run,omitzero"` // computed, not stored
+}
+
+// Scheduler stores schedules and fires them when due. Runs missed while
+// the daemon was down are not caught up.
+type Scheduler struct {
+	db      *sql.DB
+	loc     *time.Location
+	started time.Time
+
+	mu sync.Mutex // serializes writes with tick
+}
+
+// New creates the schedules table if needed. loc is the timezone cron
+// expressions are read in; nil is the local one.
+func New(db *sql.DB, loc *time.Location) (*Scheduler, error) {
+	createSQL := `
+	CREATE TABLE IF NOT EXISTS schedules (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		cron       TEXT NOT NULL,
+		task       TEXT NOT NULL,
+		disabled   INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		last_run   DATETIME
+	);`
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("scheduler: create table: %w", err)
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Scheduler{db: db, loc: loc, started: time.Now()}, nil
+}
+
+// validate checks a schedule's fields and returns its parsed expression.
+func validate(s *Schedule) (*Cron, error) {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Task = strings.TrimSpace(s.Task)
+	if s.Task == "" {
+		return nil, errors.New("task is required")
+	}
+	if s.Name == "" {
+		s.Name = s.Task
+		if r := []rune(s.Name); len(r) > 60 {
+			s.Name = string(r[:60]) + "..."
+		}
+	}
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return nil, err
+	}
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron %q never matches", s.Cron)
+	}
+	s.Cron = c.String()
+	return c, nil
+}
+
+// Add stores a new schedule and returns it with its ID.
+func (s *Scheduler) Add(sch Schedule) (*Schedule, error) {
+	if _, err := validate(&sch); err != nil {
+		return nil, err
+	}
+	sch.ID = uuid.NewString()
+	sch.Created = time.Now().UTC()
+	sch.LastRun = time.Time{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.db.Exec(`INSERT INTO schedules (id, name, cron, task, disabled, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		sch.ID, sch.Name, sch.Cron, sch.Task, sch.Disabled, sch.Created)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: add: %w", err)
+	}
+	return s.withNext(sch), nil
+}
+
+// Update replaces the name, expression, task and disabled flag of a
+// schedule.
+func (s *Scheduler) Update(id string, sch Schedule) (*Schedule, error) {
+	if _, err := validate(&sch); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, err := s.db.Exec(`UPDATE schedules SET name = ?, cron = ?, task = ?, disabled = ? WHERE id = ?`,
+		sch.Name, sch.Cron, sch.Task, sch.Disabled, id)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: update %s: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	return s.get(id)
+}
+
+// Delete removes a schedule.
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, err := s.db.Exec(`DELETE FROM schedules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("scheduler: delete %s: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get returns a schedule, or ErrNotFound.
+func (s *Scheduler) Get(id string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(id)
+}
+
+func (s *Scheduler) get(id string) (*Schedule, error) {
+	list, err := s.query(`WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrNotFound
+	}
+	return &list[0], nil
+}
+
+// List returns all schedules, oldest first.
+func (s *Scheduler) List() ([]Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.query(`ORDER BY created_at, rowid`)
+}
+
+func (s *Scheduler) query(where string, args ...any) ([]Schedule, error) {
+	rows, err := s.db.Query(`SELECT id, name, cron, task, disabled, created_at, last_run FROM schedules `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: list: %w", err)
+	}
+	defer rows.Close()
+	var out []Schedule
+	for rows.Next() {
+		var sch Schedule
+		var lastRun sql.NullTime
+		if err := rows.Scan(&sch.ID, &sch.Name, &sch.Cron, &sch.Task, &sch.Disabled, &sch.Created, &lastRun); err != nil {
+			return nil, fmt.Errorf("scheduler: list: %w", err)
+		}
+		sch.LastRun = lastRun.Time
+		out = append(out, *s.withNext(sch))
+	}
+	return out, rows.Err()
+}
+
+// withNext fills in NextRun.
+func (s *Scheduler) withNext(sch Schedule) *Schedule {
+	sch.NextRun = time.Time{}
+	if c, err := ParseCron(sch.Cron); err == nil && !sch.Disabled {
+		sch.NextRun = c.Next(s.base(sch))
+	}
+	return &sch
+}
+
+// base is the time a schedule's next run is counted from: its last run,
+// or when it was created or the scheduler started, whichever is latest.
+func (s *Scheduler) base(sch Schedule) time.Time {
+	base := s.started
+	for _, t := range []time.Time{sch.LastRun, sch.Created} {
+		if t.After(base) {
+			base = t
+		}
+	}
+	return base.In(s.loc)
+}
+
+// Run sends the tasks of due schedules to out until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, out chan<- *senses.UnifiedInput) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, in := range s.tick(now) {
+				select {
+				case out <- in:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// tick records the schedules due at now as run and returns their inputs.
+func (s *Scheduler) tick(now time.Time) []*senses.UnifiedInput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list, err := s.query(`WHERE disabled = 0`)
+	if err != nil {
+		log.Printf("[scheduler] %v", err)
+		return nil
+	}
+	var due []*senses.UnifiedInput
+	for _, sch := range list {
+		if sch.NextRun.IsZero() || now.Before(sch.NextRun) {
+			continue
+		}
+		if _, err := s.db.Exec(`UPDATE schedules SET last_run = ? WHERE id = ?`, now.UTC(), sch.ID); err != nil {
+			log.Printf("[scheduler] %s: %v", sch.Name, err)
+			continue
+		}
+		log.Printf("[scheduler] %s fired (%s)", sch.Name, sch.Cron)
+		due = append(due, Input(sch))
+	}
+	return due
+}
+
+// Input is the timer input that runs a schedule's task.
+func Input(sch Schedule) *senses.UnifiedInput {
+	in := senses.NewUnifiedInput(senses.SourceTimer, sch.Task)
+	in.SourceMeta.Channel = Channel
+	in.SourceMeta.Sender = sch.Name
+	in.SourceMeta.Extra = map[string]string{ExtraSchedule: sch.ID}
+	return in
+}
+
+// RegisterRoutes exposes the schedules.
+// Routes: GET /api/schedules, POST /api/schedules, GET /api/schedules/{id},
+// PUT /api/schedules/{id}, DELETE /api/schedules/{id}
+func (s *Scheduler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/schedules", func(w http.ResponseWriter, r *http.Request) {
+		list, err := s.List()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if list == nil {
+			list = []Schedule{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"schedules": list})
+	})
+	mux.HandleFunc("POST /api/schedules", func(w http.ResponseWriter, r *http.Request) {
+		var sch Schedule
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&sch); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+			return
+		}
+		saved, err := s.Add(sch)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusCreated, saved)
+	})
+	mux.HandleFunc("GET /api/schedules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		sch, err := s.Get(r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, sch)
+	})
+	mux.HandleFunc("PUT /api/schedules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var sch Schedule
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&sch); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+			return
+		}
+		saved, err := s.Update(r.PathValue("id"), sch)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, saved)
+	})
+	mux.HandleFunc("DELETE /api/schedules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Delete(r.PathValue("id")); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"deleted": 1})
+	})
+}
+
+// writeError maps ErrNotFound to 404, storage errors to 500 and the rest
+// (validation) to 400.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "scheduler:"):
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/overhuman/overhuman/internal/senses"
+)
+
+func TestCron_Next(t *testing.T) {
+	// Wednesday 2026-01-07 10:30.
+	from := time.Date(2026, 1, 7, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 7, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 7, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 1, 8, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 1, 8, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * sat,sun", time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 1, 8, 10, 30, 0, 0, time.UTC)},
+		{"0 8-18/4 * * *", time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 15th or any Monday.
+		{"0 0 15 * mon", time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: Next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * fun", "@often"} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Errorf("ParseCron(%q) accepted", bad)
+		}
+	}
+}
+
+func TestCron_NextInLocation(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	c, _ := ParseCron("0 9 * * *")
+	got := c.Next(time.Date(2026, 1, 7, 8, 45, 0, 0, loc))
+	if want := time.Date(2026, 1, 7, 9, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func newTestScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "sched.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := New(db, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestScheduler_CRUDAndTick(t *testing.T) {
+	s := newTestScheduler(t)
+
+	if _, err := s.Add(Schedule{Cron: "0 9 * * *"}); err == nil {
+		t.Error("schedule without a task accepted")
+	}
+	if _, err := s.Add(Schedule{Task: "x", Cron: "every morning"}); err == nil {
+		t.Error("bad cron accepted")
+	}
+
+	sch, err := s.Add(Schedule{Name: "minutely", Cron: "* * * * *", Task: "summarize my inbox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sch.ID == "" || sch.NextRun.IsZero() {
+		t.Fatalf("added schedule = %+v", sch)
+	}
+	other, _ := s.Add(Schedule{Cron: "0 0 1 1 *", Task: "new year review"})
+	if other.Name != "new year review" {
+		t.Errorf("default name = %q", other.Name)
+	}
+
+	now := time.Now().Add(2 * time.Minute)
+	due := s.tick(now)
+	if len(due) != 1 {
+		t.Fatalf("tick fired %d schedules, want 1", len(due))
+	}
+	in := due[0]
+	if in.SourceType != senses.SourceTimer || in.Payload != "summarize my inbox" ||
+		in.SourceMeta.Channel != Channel || in.SourceMeta.Extra[ExtraSchedule] != sch.ID {
+		t.Errorf("input = %+v", in)
+	}
+	if again := s.tick(now); len(again) != 0 {
+		t.Errorf("fired twice in the same minute: %d", len(again))
+	}
+	got, err := s.Get(sch.ID)
+	if err != nil || got.LastRun.IsZero() {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+
+	if _, err := s.Update(sch.ID, Schedule{Name: "paused", Cron: "* * * * *", Task: "summarize my inbox", Disabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if due := s.tick(now.Add(10 * time.Minute)); len(due) != 0 {
+		t.Errorf("disabled schedule fired")
+	}
+	if _, err := s.Update("nope", *sch); err != ErrNotFound {
+		t.Errorf("Update unknown = %v", err)
+	}
+
+	if err := s.Delete(other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(other.ID); err != ErrNotFound {
+		t.Errorf("second Delete = %v", err)
+	}
+	list, _ := s.List()
+	if len(list) != 1 || list[0].Name != "paused" || !list[0].NextRun.IsZero() {
+		t.Errorf("List = %+v", list)
+	}
+}
+
+func TestScheduler_Routes(t *testing.T) {
+	s := newTestScheduler(t)
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do("POST", "/api/schedules", `{"name":"inbox","cron":"0 9 * * 1-5","task":"summarize my inbox"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s", rec.Code, rec.Body)
+	}
+	var sch Schedule
+	json.Unmarshal(rec.Body.Bytes(), &sch)
+
+	if rec := do("POST", "/api/schedules", `{"cron":"0 25 * * *","task":"x"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST bad cron = %d", rec.Code)
+	}
+	if rec := do("GET", "/api/schedules/"+sch.ID, ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"next_run"`) {
+		t.Errorf("GET = %d %s", rec.Code, rec.Body)
+	}
+	if rec := do("PUT", "/api/schedules/"+sch.ID, `{"name":"inbox","cron":"0 8 * * 1-5","task":"summarize my inbox"}`); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"0 8 * * 1-5"`) {
+		t.Errorf("PUT = %d %s", rec.Code, rec.Body)
+	}
+	rec = do("GET", "/api/schedules", "")
+	var list struct{ Schedules []Schedule }
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list.Schedules) != 1 {
+		t.Errorf("GET list = %s", rec.Body)
+	}
+	if rec := do("DELETE", "/api/schedules/"+sch.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("DELETE = %d", rec.Code)
+	}
+	if rec := do("GET", "/api/schedules/"+sch.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET deleted = %d", rec.Code)
+	}
+}
@@ -0,0 +1,212 @@
+package security
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
+)
+
+// ---------------------------------------------------------------------------
+// Role-based access control — team members authenticated by bearer tokens
+// ---------------------------------------------------------------------------
+
+// Role is a team member's access level.
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // read-only kiosk and status
+	RoleMember Role = "member" // submit tasks, see their own history
+	RoleAdmin  Role = "admin"  // manage config, skills, policies and mode
+)
+
+// ParseRole accepts "admin", "member" and "viewer".
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(strings.TrimSpace(s))); r {
+	case RoleViewer, RoleMember, RoleAdmin:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q (want admin, member or viewer)", s)
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleMember:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// Allows reports whether r includes the rights of min.
+func (r Role) Allows(min Role) bool { return r.rank() >= min.rank() && r.rank() > 0 }
+
+// TeamMember is one entry of the "team" config block.
+type TeamMember struct {
+	Name  string `json:"name"`
+	Role  Role   `json:"role"`
+	Token string `json:"token"` // bearer token (may be "enc:v1:..." in config.json)
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal AccessControl attached to ctx.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// AccessControl authenticates requests by bearer token and enforces the
+// least role each route needs. The admin token keeps working as the
+// "admin" principal; without one, loopback and unix socket clients are
+// admins, as for the single-user admin endpoints.
+type AccessControl struct {
+	adminToken string
+	members    []TeamMember
+	audit      *AuditLogger
+}
+
+// NewAccessControl validates the team and creates an AccessControl. audit
+// may be nil.
+func NewAccessControl(adminToken string, team []TeamMember, audit *AuditLogger) (*AccessControl, error) {
+	if err := ValidateTeam(team); err != nil {
+		return nil, err
+	}
+	return &AccessControl{adminToken: adminToken, members: team, audit: audit}, nil
+}
+
+// ValidateTeam checks that every member has a unique name and token and a
+// known role.
+func ValidateTeam(team []TeamMember) error {
+	names := make(map[string]bool, len(team))
+	tokens := make(map[string]bool, len(team))
+	for i, m := range team {
+		switch {
+		case strings.TrimSpace(m.Name) == "":
+			return fmt.Errorf("team[%d]: name is required", i)
+		case names[strings.ToLower(m.Name)]:
+			return fmt.Errorf("team[%d]: duplicate name %q", i, m.Name)
+		case len(m.Token) < 16:
+			return fmt.Errorf("team[%d] (%s): token must be at least 16 characters", i, m.Name)
+		case tokens[m.Token]:
+			return fmt.Errorf("team[%d] (%s): token is shared with another member", i, m.Name)
+		}
+		if _, err := ParseRole(string(m.Role)); err != nil {
+			return fmt.Errorf("team[%d] (%s): %w", i, m.Name, err)
+		}
+		names[strings.ToLower(m.Name)] = true
+		tokens[m.Token] = true
+	}
+	return nil
+}
+
+// requestToken is the bearer token of r, or its "token" query parameter,
+// which browsers need for WebSocket connections and download links.
+func requestToken(r *http.Request) string {
+	if t, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return t
+	}
+	return r.URL.Query().Get("token")
+}
+
+// AuthorizeAdmin reports whether r comes from an admin, for the routes
+// that stay admin-only without a team: behind the team access control the
+// principal must be an admin; otherwise r must carry adminToken (see
+// requestToken) or, when there is none, come from a local client
+// (loopback or unix socket).
+func AuthorizeAdmin(r *http.Request, adminToken string) bool {
+	if p, ok := PrincipalFrom(r.Context()); ok {
+		return p.Role.Allows(RoleAdmin)
+	}
+	if adminToken == "" {
+		return netaddr.IsLocal(r)
+	}
+	return subtle.ConstantTimeCompare([]byte(requestToken(r)), []byte(adminToken)) == 1
+}
+
+// Authenticate identifies the caller of r.
+func (ac *AccessControl) Authenticate(r *http.Request) (Principal, bool) {
+	token := requestToken(r)
+	if token == "" {
+		if ac.adminToken == "" && netaddr.IsLocal(r) {
+			return Principal{Name: "local", Role: RoleAdmin}, true
+		}
+		return Principal{}, false
+	}
+	if ac.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(ac.adminToken)) == 1 {
+		return Principal{Name: "admin", Role: RoleAdmin}, true
+	}
+	for _, m := range ac.members {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(m.Token)) == 1 {
+			role, _ := ParseRole(string(m.Role))
+			return Principal{Name: m.Name, Role: role}, true
+		}
+	}
+	return Principal{}, false
+}
+
+// Guard wraps next so that every request needs the role required returns
+// for it ("" = public). The principal is attached to the request context;
+// privileged (admin) requests and denials are audit-logged.
+func (ac *AccessControl) Guard(next http.Handler, required func(*http.Request) Role) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		min := required(r)
+		p, ok := ac.Authenticate(r)
+		if min == "" {
+			if ok {
+				r = r.WithContext(WithPrincipal(r.Context(), p))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !ok {
+			ac.log(AuditAuthAttempt, SeverityWarn, "anonymous", r, false, "no valid token")
+			writeAccessError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		if !p.Role.Allows(min) {
+			ac.log(AuditAuthAttempt, SeverityWarn, p.Name, r, false, fmt.Sprintf("role %s, needs %s", p.Role, min))
+			writeAccessError(w, http.StatusForbidden, fmt.Sprintf("forbidden: needs the %s role", min))
+			return
+		}
+		if min == RoleAdmin {
+			ac.log(AuditAdminAction, SeverityInfo, p.Name, r, true, "")
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+func (ac *AccessControl) log(t AuditEventType, sev AuditSeverity, actor string, r *http.Request, success bool, reason string) {
+	if ac.audit == nil {
+		return
+	}
+	var details map[string]string
+	if reason != "" {
+		details = map[string]string{"reason": reason}
+	}
+	ac.audit.Log(t, sev, "access", actor, r.Method, r.URL.Path, success, details)
+}
+
+func writeAccessError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -1,8 +1,11 @@
 package security
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -15,20 +18,25 @@ import (
 type AuditEventType string
 
 const (
-	AuditSkillExec     AuditEventType = "SKILL_EXEC"
-	AuditSkillCreate   AuditEventType = "SKILL_CREATE"
-	AuditSkillDelete   AuditEventType = "SKILL_DELETE"
-	AuditCredAccess    AuditEventType = "CRED_ACCESS"
-	AuditCredModify    AuditEventType = "CRED_MODIFY"
-	AuditAgentSpawn    AuditEventType = "AGENT_SPAWN"
-	AuditAgentRetire   AuditEventType = "AGENT_RETIRE"
-	AuditPolicyChange  AuditEventType = "POLICY_CHANGE"
-	AuditInjectionWarn AuditEventType = "INJECTION_WARN"
-	AuditRateLimit     AuditEventType = "RATE_LIMIT"
-	AuditAuthAttempt   AuditEventType = "AUTH_ATTEMPT"
-	AuditSoulModify    AuditEventType = "SOUL_MODIFY"
-	AuditInputBlocked  AuditEventType = "INPUT_BLOCKED"
-	AuditExecDenied    AuditEventType = "EXEC_DENIED"
+	AuditSkillExec       AuditEventType = "SKILL_EXEC"
+	AuditSkillCreate     AuditEventType = "SKILL_CREATE"
+	AuditSkillDelete     AuditEventType = "SKILL_DELETE"
+	AuditCredAccess      AuditEventType = "CRED_ACCESS"
+	AuditCredModify      AuditEventType = "CRED_MODIFY"
+	AuditAgentSpawn      AuditEventType = "AGENT_SPAWN"
+	AuditAgentRetire     AuditEventType = "AGENT_RETIRE"
+	AuditPolicyChange    AuditEventType = "POLICY_CHANGE"
+	AuditInjectionWarn   AuditEventType = "INJECTION_WARN"
+	AuditRateLimit       AuditEventType = "RATE_LIMIT"
+	AuditAuthAttempt     AuditEventType = "AUTH_ATTEMPT"
+	AuditSoulModify      AuditEventType = "SOUL_MODIFY"
+	AuditInputBlocked    AuditEventType = "INPUT_BLOCKED"
+	AuditExecDenied      AuditEventType = "EXEC_DENIED"
+	AuditAdminAction     AuditEventType = "ADMIN_ACTION"
+	AuditDeliveryFail    AuditEventType = "DELIVERY_FAIL"
+	AuditComponentFail   AuditEventType = "COMPONENT_FAIL"
+	AuditOutputModerated AuditEventType = "OUTPUT_MODERATED"
+	AuditApproval        AuditEventType = "APPROVAL"
 )
 
 // AuditSeverity indicates the importance of an audit event.
@@ -85,11 +93,25 @@ type AuditLogger struct {
 }
 
 // NewAuditLogger creates an AuditLogger with the given store.
+// IDs continue after the events already in store.
 func NewAuditLogger(store AuditStore) *AuditLogger {
-	return &AuditLogger{
+	a := &AuditLogger{
 		store:  store,
 		nextID: 1,
 	}
+	if store != nil {
+		if n, err := store.Count(); err == nil {
+			a.nextID = n + 1
+		}
+		// After pruning the count is lower than the newest ID.
+		if last, err := store.Query(AuditFilter{Limit: 1}); err == nil && len(last) == 1 {
+			var n int
+			if _, err := fmt.Sscanf(last[0].ID, "audit-%d", &n); err == nil && n >= a.nextID {
+				a.nextID = n + 1
+			}
+		}
+	}
+	return a
 }
 
 // Log records an audit event. Returns the event ID.
@@ -166,6 +188,21 @@ func (a *AuditLogger) Count() (int, error) {
 	return a.store.Count()
 }
 
+// AuditPruner is implemented by stores that can drop old events.
+type AuditPruner interface {
+	Prune(before time.Time) (int, error)
+}
+
+// Prune deletes the events recorded before before, for retention, and
+// returns how many it deleted. Stores that cannot prune keep everything.
+func (a *AuditLogger) Prune(before time.Time) (int, error) {
+	p, ok := a.store.(AuditPruner)
+	if !ok {
+		return 0, nil
+	}
+	return p.Prune(before)
+}
+
 // ---------------------------------------------------------------------------
 // In-memory audit store (for testing and small deployments)
 // ---------------------------------------------------------------------------
@@ -196,9 +233,14 @@ func (s *MemoryAuditStore) Query(filter AuditFilter) ([]AuditEvent, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	return filterEvents(s.events, filter), nil
+}
+
+// filterEvents returns events matching filter, newest first.
+func filterEvents(events []AuditEvent, filter AuditFilter) []AuditEvent {
 	var results []AuditEvent
-	for i := len(s.events) - 1; i >= 0; i-- {
-		e := s.events[i]
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
 
 		// Time filters.
 		if !filter.Since.IsZero() && e.Timestamp.Before(filter.Since) {
@@ -225,7 +267,7 @@ func (s *MemoryAuditStore) Query(filter AuditFilter) ([]AuditEvent, error) {
 			break
 		}
 	}
-	return results, nil
+	return results
 }
 
 // Count returns the total number of events.
@@ -235,9 +277,153 @@ func (s *MemoryAuditStore) Count() (int, error) {
 	return len(s.events), nil
 }
 
+// Prune deletes the events recorded before before.
+func (s *MemoryAuditStore) Prune(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.events[:0]
+	for _, e := range s.events {
+		if !e.Timestamp.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	n := len(s.events) - len(kept)
+	clear(s.events[len(kept):])
+	s.events = kept
+	return n, nil
+}
+
 // MarshalJSON serializes the audit store for export/backup.
 func (s *MemoryAuditStore) MarshalJSON() ([]byte, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return json.Marshal(s.events)
 }
+
+// ---------------------------------------------------------------------------
+// File audit store (JSON lines, survives restarts)
+// ---------------------------------------------------------------------------
+
+// FileAuditStore appends events as JSON lines to a file. Queries read the
+// file back, so it suits the modest volume of a single agent.
+type FileAuditStore struct {
+	mu    sync.Mutex
+	path  string
+	count int
+}
+
+// NewFileAuditStore opens (or creates) the audit log at path.
+func NewFileAuditStore(path string) (*FileAuditStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("audit store: mkdir: %w", err)
+	}
+	s := &FileAuditStore{path: path}
+	events, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	s.count = len(events)
+	return s, nil
+}
+
+// Append writes event as one JSON line.
+func (s *FileAuditStore) Append(event AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("audit store: encode: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("audit store: open: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("audit store: write: %w", err)
+	}
+	s.count++
+	return nil
+}
+
+// Query returns events matching the filter, newest first.
+func (s *FileAuditStore) Query(filter AuditFilter) ([]AuditEvent, error) {
+	s.mu.Lock()
+	events, err := s.readAll()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return filterEvents(events, filter), nil
+}
+
+// Count returns the total number of events.
+func (s *FileAuditStore) Count() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count, nil
+}
+
+// Prune rewrites the log without the events recorded before before. The
+// new log replaces the old one atomically.
+func (s *FileAuditStore) Prune(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events, err := s.readAll()
+	if err != nil {
+		return 0, err
+	}
+	var buf []byte
+	kept := 0
+	for _, e := range events {
+		if e.Timestamp.Before(before) {
+			continue
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return 0, fmt.Errorf("audit store: encode: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+		kept++
+	}
+	if kept == len(events) {
+		return 0, nil
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o600); err != nil {
+		return 0, fmt.Errorf("audit store: prune: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("audit store: prune: %w", err)
+	}
+	s.count = kept
+	return len(events) - kept, nil
+}
+
+// readAll parses the log. Malformed lines (e.g. a torn final write) are
+// skipped.
+func (s *FileAuditStore) readAll() ([]AuditEvent, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audit store: open: %w", err)
+	}
+	defer f.Close()
+
+	var events []AuditEvent
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var e AuditEvent
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			events = append(events, e)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("audit store: read: %w", err)
+	}
+	return events, nil
+}
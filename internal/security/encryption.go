@@ -175,6 +175,18 @@ func (sr *SecretRegistry) Sanitize(text string) string {
 	return text
 }
 
+// Contains reports whether text includes any registered secret.
+func (sr *SecretRegistry) Contains(text string) bool {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	for _, secret := range sr.secrets {
+		if strings.Contains(text, secret) {
+			return true
+		}
+	}
+	return false
+}
+
 // Count returns the number of registered secrets.
 func (sr *SecretRegistry) Count() int {
 	sr.mu.RLock()
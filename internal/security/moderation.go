@@ -0,0 +1,256 @@
+package security
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ModerationAction is what happens to an outgoing message flagged in a
+// category.
+type ModerationAction string
+
+const (
+	ModerationAllow    ModerationAction = "allow"
+	ModerationAnnotate ModerationAction = "annotate" // sent with a warning
+	ModerationApprove  ModerationAction = "approve"  // held until an admin releases it
+	ModerationBlock    ModerationAction = "block"    // not sent
+)
+
+// rank orders actions from least to most restrictive.
+func (a ModerationAction) rank() int {
+	switch a {
+	case ModerationAnnotate:
+		return 1
+	case ModerationApprove:
+		return 2
+	case ModerationBlock:
+		return 3
+	}
+	return 0
+}
+
+// Moderator scores a text per safety category, from 0 to 1. Categories are
+// named as in the OpenAI moderation API ("harassment", "hate/threatening",
+// ...) plus the local classifier's "credentials" and "pii".
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (map[string]float64, error)
+}
+
+// DefaultModeratedChannels are the channels whose replies are moderated
+// when OutputModeration.Channels is empty.
+var DefaultModeratedChannels = []string{"email", "slack", "telegram", "discord", "signal"}
+
+// DefaultModerationCategories is the action per category used when
+// OutputModeration.Categories is empty.
+var DefaultModerationCategories = map[string]ModerationAction{
+	"credentials":   ModerationBlock,
+	"sexual/minors": ModerationBlock,
+	"pii":           ModerationApprove,
+	"hate":          ModerationApprove,
+	"harassment":    ModerationApprove,
+	"illicit":       ModerationApprove,
+	"sexual":        ModerationApprove,
+	"violence":      ModerationAnnotate,
+	"self-harm":     ModerationAnnotate,
+}
+
+// DefaultModerationThreshold is the score at which a category counts as
+// flagged.
+const DefaultModerationThreshold = 0.5
+
+// OutputModeration is the safety pass over results the agent sends to
+// external channels on its own, so it cannot mail or post something the
+// user would regret.
+type OutputModeration struct {
+	Enabled bool `json:"enabled"`
+	// Provider scores the text: "local" (patterns for credentials and
+	// personal numbers, the default) or "openai" (the moderation API,
+	// combined with the local patterns).
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	// Channels are the source type keys moderated ("email", "slack", ...).
+	Channels []string `json:"channels,omitempty"`
+	// Threshold is the score at which a category counts as flagged.
+	Threshold float64 `json:"threshold,omitempty"`
+	// Categories maps a category to its action. A category also covers
+	// its subcategories ("hate" covers "hate/threatening") and "*" covers
+	// every category not listed.
+	Categories map[string]ModerationAction `json:"categories,omitempty"`
+	// OnError is the action when the classifier fails (default "allow").
+	OnError ModerationAction `json:"on_error,omitempty"`
+}
+
+// ModerationVerdict is the outcome of moderating one message.
+type ModerationVerdict struct {
+	Action  ModerationAction
+	Flagged []string // categories at or over the threshold, sorted
+}
+
+// Moderates reports whether replies on channel are moderated.
+func (m OutputModeration) Moderates(channel string) bool {
+	if !m.Enabled {
+		return false
+	}
+	channels := m.Channels
+	if len(channels) == 0 {
+		channels = DefaultModeratedChannels
+	}
+	return slices.ContainsFunc(channels, func(c string) bool { return strings.EqualFold(c, channel) })
+}
+
+// Decide returns the most restrictive action among the flagged categories.
+func (m OutputModeration) Decide(scores map[string]float64) ModerationVerdict {
+	threshold := m.Threshold
+	if threshold <= 0 {
+		threshold = DefaultModerationThreshold
+	}
+	v := ModerationVerdict{Action: ModerationAllow}
+	for cat, score := range scores {
+		if score < threshold {
+			continue
+		}
+		a := m.actionFor(cat)
+		if a.rank() == 0 {
+			continue
+		}
+		v.Flagged = append(v.Flagged, cat)
+		if a.rank() > v.Action.rank() {
+			v.Action = a
+		}
+	}
+	slices.Sort(v.Flagged)
+	return v
+}
+
+// ErrorAction is the action for a message the classifier could not score.
+func (m OutputModeration) ErrorAction() ModerationAction {
+	if m.OnError == "" {
+		return ModerationAllow
+	}
+	return m.OnError
+}
+
+func (m OutputModeration) actionFor(category string) ModerationAction {
+	cats := m.Categories
+	if len(cats) == 0 {
+		cats = DefaultModerationCategories
+	}
+	for c := category; ; {
+		if a, ok := cats[c]; ok {
+			return a
+		}
+		i := strings.LastIndexByte(c, '/')
+		if i < 0 {
+			break
+		}
+		c = c[:i]
+	}
+	return cats["*"]
+}
+
+// Credential and personal number patterns of the local classifier.
+var (
+	credentialPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`),
+		regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`),
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+		regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	}
+	cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	ssnPattern  = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	ibanPattern = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,4})?\b`)
+)
+
+// LocalModerator flags what patterns catch reliably without a model:
+// "credentials" (registered secrets and well-known key formats) and "pii"
+// (payment card numbers passing the Luhn check, US social security
+// numbers, IBANs passing their checksum). Scores are 0 or 1.
+type LocalModerator struct {
+	Secrets *SecretRegistry // may be nil
+}
+
+// Moderate implements Moderator.
+func (l LocalModerator) Moderate(_ context.Context, text string) (map[string]float64, error) {
+	scores := map[string]float64{"credentials": 0, "pii": 0}
+	if l.Secrets != nil && l.Secrets.Contains(text) {
+		scores["credentials"] = 1
+	}
+	for _, re := range credentialPatterns {
+		if re.MatchString(text) {
+			scores["credentials"] = 1
+			break
+		}
+	}
+	if ssnPattern.MatchString(text) ||
+		slices.ContainsFunc(cardPattern.FindAllString(text, -1), luhnValid) ||
+		slices.ContainsFunc(ibanPattern.FindAllString(text, -1), ibanValid) {
+		scores["pii"] = 1
+	}
+	return scores, nil
+}
+
+// CombineModerators returns a Moderator scoring each category with the
+// highest score any of ms gives it. It fails if any of them fails.
+func CombineModerators(ms ...Moderator) Moderator {
+	return combinedModerator(ms)
+}
+
+type combinedModerator []Moderator
+
+func (c combinedModerator) Moderate(ctx context.Context, text string) (map[string]float64, error) {
+	scores := make(map[string]float64)
+	for _, m := range c {
+		s, err := m.Moderate(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		for cat, v := range s {
+			scores[cat] = max(scores[cat], v)
+		}
+	}
+	return scores, nil
+}
+
+// luhnValid reports whether the digits of s pass the Luhn check.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// ibanValid reports whether s, with spaces, passes the IBAN mod-97 check.
+func ibanValid(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	rem := 0
+	for _, c := range s[4:] + s[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
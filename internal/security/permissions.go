@@ -0,0 +1,198 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Permission store — per-skill permission grants decided by the user
+// ---------------------------------------------------------------------------
+
+// PermissionDecision is the user's answer to a permission prompt.
+type PermissionDecision string
+
+const (
+	PermissionAlways PermissionDecision = "always" // allow now and later (persisted)
+	PermissionOnce   PermissionDecision = "once"   // allow this use only
+	PermissionNever  PermissionDecision = "never"  // deny now and later (persisted)
+)
+
+// ParsePermissionDecision accepts "always"/"once"/"never", their first
+// letters, and yes/no (as once/never).
+func ParsePermissionDecision(s string) (PermissionDecision, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "always", "a":
+		return PermissionAlways, true
+	case "once", "o", "y", "yes":
+		return PermissionOnce, true
+	case "never", "n", "no":
+		return PermissionNever, true
+	}
+	return "", false
+}
+
+// PermissionRequest asks whether a skill may use a permission.
+type PermissionRequest struct {
+	SkillID    string `json:"skill_id"`
+	SkillName  string `json:"skill_name,omitempty"`
+	Permission string `json:"permission"`       // e.g. "network", "filesystem"
+	Detail     string `json:"detail,omitempty"` // what the permission allows, for humans
+}
+
+// PermissionPrompter asks the user about a permission interactively.
+type PermissionPrompter interface {
+	PromptPermission(ctx context.Context, req PermissionRequest) (PermissionDecision, error)
+}
+
+// PermissionGrant is a persisted decision.
+type PermissionGrant struct {
+	SkillID    string             `json:"skill_id"`
+	Permission string             `json:"permission"`
+	Decision   PermissionDecision `json:"decision"`
+	DecidedAt  time.Time          `json:"decided_at"`
+}
+
+// PermissionStore persists "always"/"never" decisions as JSON, so each
+// skill asks for each permission at most once.
+type PermissionStore struct {
+	mu     sync.RWMutex
+	path   string // "" = in-memory only
+	grants map[string]PermissionGrant
+}
+
+// NewPermissionStore loads decisions from path (created on first write).
+// An empty path keeps decisions in memory only.
+func NewPermissionStore(path string) (*PermissionStore, error) {
+	s := &PermissionStore{path: path, grants: make(map[string]PermissionGrant)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("permissions: read: %w", err)
+	}
+	var list []PermissionGrant
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("permissions: parse %s: %w", path, err)
+	}
+	for _, g := range list {
+		s.grants[permissionKey(g.SkillID, g.Permission)] = g
+	}
+	return s, nil
+}
+
+func permissionKey(skillID, perm string) string { return skillID + "\x00" + perm }
+
+// Decision returns the persisted decision for a skill and permission.
+func (s *PermissionStore) Decision(skillID, perm string) (PermissionDecision, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	g, ok := s.grants[permissionKey(skillID, perm)]
+	return g.Decision, ok
+}
+
+// Set persists an "always" or "never" decision. "once" is not stored.
+func (s *PermissionStore) Set(skillID, perm string, d PermissionDecision) error {
+	if d != PermissionAlways && d != PermissionNever {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants[permissionKey(skillID, perm)] = PermissionGrant{
+		SkillID: skillID, Permission: perm, Decision: d, DecidedAt: time.Now().UTC(),
+	}
+	return s.saveLocked()
+}
+
+// Forget removes a decision so the user is asked again, and reports whether
+// it existed.
+func (s *PermissionStore) Forget(skillID, perm string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := permissionKey(skillID, perm)
+	if _, ok := s.grants[key]; !ok {
+		return false, nil
+	}
+	delete(s.grants, key)
+	return true, s.saveLocked()
+}
+
+// List returns all decisions, sorted by skill and permission.
+func (s *PermissionStore) List() []PermissionGrant {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedLocked()
+}
+
+// Check decides whether req is allowed. A persisted decision wins; without
+// one the prompter (if any) asks the user and "always"/"never" answers are
+// persisted. Without a prompter — the daemon, where nobody can answer —
+// undecided permissions are allowed, as before prompts existed.
+func (s *PermissionStore) Check(ctx context.Context, req PermissionRequest, prompter PermissionPrompter) (bool, error) {
+	if d, ok := s.Decision(req.SkillID, req.Permission); ok {
+		return d != PermissionNever, nil
+	}
+	if prompter == nil {
+		return true, nil
+	}
+	d, err := prompter.PromptPermission(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	if s != nil {
+		if err := s.Set(req.SkillID, req.Permission, d); err != nil {
+			return d != PermissionNever, err
+		}
+	}
+	return d != PermissionNever, nil
+}
+
+func (s *PermissionStore) sortedLocked() []PermissionGrant {
+	list := make([]PermissionGrant, 0, len(s.grants))
+	for _, g := range s.grants {
+		list = append(list, g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].SkillID != list[j].SkillID {
+			return list[i].SkillID < list[j].SkillID
+		}
+		return list[i].Permission < list[j].Permission
+	})
+	return list
+}
+
+// saveLocked writes decisions to disk atomically. Caller holds s.mu.
+func (s *PermissionStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.sortedLocked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("permissions: encode: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("permissions: mkdir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("permissions: write: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
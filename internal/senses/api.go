@@ -6,8 +6,14 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
+	"github.com/overhuman/overhuman/internal/security"
 )
 
 // APISense implements the Sense interface for HTTP REST API input.
@@ -22,9 +28,59 @@ type APISense struct {
 	listener net.Listener
 	stopped  bool
 
-	// responses stores pending response channels keyed by correlation ID.
+	// responses stores pending response channels keyed by correlation ID;
+	// completions those of chat completion requests.
 	responses   map[string]chan string
+	completions map[string]*chatWait
 	responsesMu sync.RWMutex
+
+	// mode gates inputs in maintenance mode (optional — nil-safe).
+	mode       *ModeSwitch
+	adminToken string
+
+	// limits caps request bodies (zero value = default quotas).
+	limits Limits
+
+	// shutdown, if set, is called by POST /shutdown (admin only).
+	shutdown func()
+
+	// models, if set, lists the models requests may name.
+	models func() []string
+
+	// webhooks are the named webhooks served at POST /webhook/{name}.
+	webhooks map[string]*inboundHook
+
+	// voice, if set, transcribes recordings posted to POST /voice.
+	voice *VoiceSense
+
+	// activeTasks and cancelTask, if set, serve GET /tasks and
+	// DELETE /tasks/{id}.
+	activeTasks func(r *http.Request) any
+	cancelTask  func(r *http.Request, id string) bool
+
+	// capabilities, if set, is served by GET /capabilities.
+	capabilities func() Capabilities
+
+	// rateLimits, if set, adds the provider rate limits to GET /health.
+	rateLimits func() any
+
+	// heartbeat, if set, adds the heartbeat schedule to GET /health.
+	heartbeat func() any
+
+	// queue, if set, adds the input queue to GET /health.
+	queue func() any
+
+	// housekeeping, if set, adds the maintenance tasks to GET /health.
+	housekeeping func() any
+
+	// components, if set, adds the supervised components to GET /health.
+	components func() any
+
+	// routes are the extra routes of SetRoutes, by path prefix.
+	routes map[string]func(mux *http.ServeMux)
+
+	// middleware, if set, wraps the server's handler (access control).
+	middleware func(http.Handler) http.Handler
 }
 
 // apiRequest is the JSON body for POST /input.
@@ -33,7 +89,42 @@ type apiRequest struct {
 	Priority  string            `json:"priority,omitempty"` // "LOW", "NORMAL", "HIGH", "CRITICAL"
 	Sender    string            `json:"sender,omitempty"`
 	SessionID string            `json:"session_id,omitempty"`
+	Stateless bool              `json:"stateless,omitempty"` // one-off: no conversation history read or written
 	Metadata  map[string]string `json:"metadata,omitempty"`
+
+	// BudgetUSD, Model and Complexity route this task only: a spending
+	// cap, a model to execute on, or the complexity the router picks a
+	// model for ("simple", "moderate", "complex").
+	BudgetUSD  float64 `json:"budget_usd,omitempty"`
+	Model      string  `json:"model,omitempty"`
+	Complexity string  `json:"complexity,omitempty"`
+}
+
+// Extra keys carrying an API request's per-task routing to the pipeline.
+const (
+	ExtraBudgetUSD  = "budget_usd"
+	ExtraModel      = "model"
+	ExtraComplexity = "complexity"
+)
+
+// validate checks the request's routing fields; known lists the models
+// requests may name (nil = any).
+func (req apiRequest) validate(known func() []string) error {
+	switch req.Complexity {
+	case "", "simple", "moderate", "complex":
+	default:
+		return fmt.Errorf("complexity must be simple, moderate or complex")
+	}
+	if req.BudgetUSD < 0 {
+		return fmt.Errorf("budget_usd must not be negative")
+	}
+	if req.Model != "" && known != nil {
+		models := known()
+		if !slices.Contains(models, req.Model) {
+			return fmt.Errorf("unknown model %q (available: %s)", req.Model, strings.Join(models, ", "))
+		}
+	}
+	return nil
 }
 
 // apiResponse is the JSON body returned for POST /input.
@@ -44,17 +135,143 @@ type apiResponse struct {
 
 // apiHealthResponse is the JSON body for GET /health.
 type apiHealthResponse struct {
-	Status string `json:"status"`
-	Uptime string `json:"uptime"`
+	Status       string     `json:"status"`
+	Uptime       string     `json:"uptime"`
+	Mode         DaemonMode `json:"mode"`
+	ModeMessage  string     `json:"mode_message,omitempty"`
+	Degraded     string     `json:"degraded,omitempty"`
+	RateLimits   any        `json:"rate_limits,omitempty"`
+	Heartbeat    any        `json:"heartbeat,omitempty"`
+	Queue        any        `json:"queue,omitempty"`
+	Housekeeping any        `json:"housekeeping,omitempty"`
+	Components   any        `json:"components,omitempty"`
 }
 
+// apiSyncTimeout bounds how long a synchronous request waits for its
+// result.
+var apiSyncTimeout = 60 * time.Second
+
 // NewAPISense creates an HTTP API sense adapter.
 // addr is the listen address, e.g. ":8080" or "127.0.0.1:9000".
 func NewAPISense(addr string) *APISense {
 	return &APISense{
-		addr:      addr,
-		responses: make(map[string]chan string),
+		addr:        addr,
+		responses:   make(map[string]chan string),
+		completions: make(map[string]*chatWait),
+	}
+}
+
+// SetMode attaches the daemon mode switch. It must be called before Start.
+// GET /mode reports the mode; POST /mode changes it and requires adminToken
+// as a bearer token (loopback clients only when adminToken is empty).
+func (a *APISense) SetMode(m *ModeSwitch, adminToken string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mode = m
+	a.adminToken = adminToken
+}
+
+// SetShutdown enables POST /shutdown, which calls fn after responding. The
+// endpoint uses the same admin authorization as POST /mode. It must be
+// called before Start.
+func (a *APISense) SetShutdown(fn func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.shutdown = fn
+}
+
+// SetTasks enables GET /tasks, which lists active's result for the
+// request, and DELETE /tasks/{id}, which cancels a running task with
+// cancel. cancel reports whether the caller could cancel a task of that
+// ID; the endpoint answers 404 otherwise. It must be called before Start.
+func (a *APISense) SetTasks(active func(r *http.Request) any, cancel func(r *http.Request, id string) bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.activeTasks, a.cancelTask = active, cancel
+}
+
+// SetModels makes POST /input and /input/sync reject a "model" that is
+// not in fn's result. It must be called before Start.
+func (a *APISense) SetModels(fn func() []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.models = fn
+}
+
+// SetCapabilities enables GET /capabilities, which reports fn's result.
+// It must be called before Start.
+func (a *APISense) SetCapabilities(fn func() Capabilities) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.capabilities = fn
+}
+
+// SetRateLimits makes GET /health report fn's result as "rate_limits"
+// (nil omits it). It must be called before Start.
+func (a *APISense) SetRateLimits(fn func() any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rateLimits = fn
+}
+
+// SetHeartbeat makes GET /health report fn's result as "heartbeat". It
+// must be called before Start.
+func (a *APISense) SetHeartbeat(fn func() any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.heartbeat = fn
+}
+
+// SetQueue makes GET /health report fn's result as "queue". It must be
+// called before Start.
+func (a *APISense) SetQueue(fn func() any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.queue = fn
+}
+
+// SetHousekeeping makes GET /health report fn's result as "housekeeping".
+// It must be called before Start.
+func (a *APISense) SetHousekeeping(fn func() any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.housekeeping = fn
+}
+
+// SetComponents makes GET /health report fn's result as "components". It
+// must be called before Start.
+func (a *APISense) SetComponents(fn func() any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.components = fn
+}
+
+// SetRoutes serves the routes register adds under prefix, e.g. the
+// goals under /goals. GET and HEAD are open like GET /tasks; the other
+// methods need the admin token, like POST /mode. It must be called before
+// Start.
+func (a *APISense) SetRoutes(prefix string, register func(mux *http.ServeMux)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.routes == nil {
+		a.routes = make(map[string]func(mux *http.ServeMux))
 	}
+	a.routes[prefix] = register
+}
+
+// SetMiddleware wraps every request in mw, e.g. the team access control.
+// It must be called before Start.
+func (a *APISense) SetMiddleware(mw func(http.Handler) http.Handler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.middleware = mw
+}
+
+// SetLimits configures the input quotas. It must be called before Start.
+func (a *APISense) SetLimits(l Limits) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.limits = l
 }
 
 // Name returns the sense name.
@@ -69,22 +286,78 @@ func (a *APISense) Start(ctx context.Context, out chan<- *UnifiedInput) error {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		st := a.mode.Status()
+		resp := apiHealthResponse{
+			Status:      "ok",
+			Uptime:      time.Since(startTime).String(),
+			Mode:        st.Mode,
+			ModeMessage: st.Message,
+			Degraded:    st.Degraded,
+		}
+		if a.rateLimits != nil {
+			resp.RateLimits = a.rateLimits()
+		}
+		if a.heartbeat != nil {
+			resp.Heartbeat = a.heartbeat()
+		}
+		if a.queue != nil {
+			resp.Queue = a.queue()
+		}
+		if a.housekeeping != nil {
+			resp.Housekeeping = a.housekeeping()
+		}
+		if a.components != nil {
+			resp.Components = a.components()
+		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(apiHealthResponse{
-			Status: "ok",
-			Uptime: time.Since(startTime).String(),
-		})
+		json.NewEncoder(w).Encode(resp)
 	})
 	mux.HandleFunc("POST /input", a.handleInput)
 	mux.HandleFunc("POST /input/sync", a.handleInputSync)
+	mux.HandleFunc("POST /v1/chat/completions", a.handleChatCompletions)
+	mux.HandleFunc("GET /v1/models", a.handleModels)
+	if len(a.webhooks) > 0 {
+		mux.HandleFunc("POST /webhook/{name}", a.handleWebhook)
+	}
+	if a.voice != nil {
+		mux.HandleFunc("POST /voice", a.handleVoice)
+	}
+	if a.mode != nil {
+		mux.Handle("GET /mode", a.mode)
+		mux.Handle("POST /mode", a.mode.AdminHandler(a.adminToken))
+	}
+	if a.shutdown != nil {
+		mux.HandleFunc("POST /shutdown", a.handleShutdown)
+	}
+	if a.capabilities != nil {
+		mux.Handle("GET /capabilities", CapabilitiesHandler(a.capabilities))
+	}
+	if a.activeTasks != nil {
+		mux.HandleFunc("GET /tasks", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"tasks": a.activeTasks(r)})
+		})
+		mux.HandleFunc("DELETE /tasks/{id}", a.handleCancelTask)
+	}
+	for prefix, register := range a.routes {
+		sub := http.NewServeMux()
+		register(sub)
+		h := a.adminWrites(sub)
+		mux.Handle(prefix, h)
+		mux.Handle(prefix+"/", h)
+	}
 
+	var handler http.Handler = mux
+	if a.middleware != nil {
+		handler = a.middleware(mux)
+	}
 	a.srv = &http.Server{
 		Addr:              a.addr,
-		Handler:           mux,
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	ln, err := net.Listen("tcp", a.addr)
+	ln, err := netaddr.Listen(a.addr)
 	if err != nil {
 		a.mu.Unlock()
 		return fmt.Errorf("api sense: listen: %w", err)
@@ -106,11 +379,68 @@ func (a *APISense) Start(ctx context.Context, out chan<- *UnifiedInput) error {
 	return nil
 }
 
+// handleShutdown handles POST /shutdown — stops the daemon remotely.
+func (a *APISense) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	if !security.AuthorizeAdmin(r, a.adminToken) {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "shutting down"})
+	// Let the response flush before the server begins shutting down.
+	go a.shutdown()
+}
+
+// adminWrites lets GET and HEAD requests through to h and requires the
+// admin token for the others.
+func (a *APISense) adminWrites(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && !security.AuthorizeAdmin(r, a.adminToken) {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// handleCancelTask handles DELETE /tasks/{id}: the run is cancelled and
+// its client receives the cancelled result.
+func (a *APISense) handleCancelTask(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	w.Header().Set("Content-Type", "application/json")
+	if !a.cancelTask(r, id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "no running task " + id})
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"task_id": id, "status": "cancelling"})
+}
+
+// rejectMaintenance answers 503 with the maintenance message and reports
+// whether the request was rejected.
+func (a *APISense) rejectMaintenance(w http.ResponseWriter) bool {
+	if !a.mode.Maintenance() {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "300")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":   "maintenance",
+		"message": a.mode.MaintenanceMessage(),
+	})
+	return true
+}
+
 // handleInput handles async POST /input — fire-and-forget.
 func (a *APISense) handleInput(w http.ResponseWriter, r *http.Request) {
-	var req apiRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+	if a.rejectMaintenance(w) {
+		return
+	}
+	req, ok := a.decodeRequest(w, r)
+	if !ok {
 		return
 	}
 
@@ -119,7 +449,7 @@ func (a *APISense) handleInput(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	input := a.buildInput(req)
+	input := a.buildInput(r, req)
 
 	select {
 	case a.out <- input:
@@ -136,9 +466,11 @@ func (a *APISense) handleInput(w http.ResponseWriter, r *http.Request) {
 
 // handleInputSync handles POST /input/sync — waits for response (with timeout).
 func (a *APISense) handleInputSync(w http.ResponseWriter, r *http.Request) {
-	var req apiRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+	if a.rejectMaintenance(w) {
+		return
+	}
+	req, ok := a.decodeRequest(w, r)
+	if !ok {
 		return
 	}
 
@@ -147,7 +479,7 @@ func (a *APISense) handleInputSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	input := a.buildInput(req)
+	input := a.buildInput(r, req)
 	input.CorrelationID = input.InputID
 	input.ResponseChannel = "api"
 
@@ -170,8 +502,6 @@ func (a *APISense) handleInputSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Wait for response with timeout.
-	timeout := 60 * time.Second
 	select {
 	case msg := <-ch:
 		w.Header().Set("Content-Type", "application/json")
@@ -180,14 +510,42 @@ func (a *APISense) handleInputSync(w http.ResponseWriter, r *http.Request) {
 			"status":   "completed",
 			"result":   msg,
 		})
-	case <-time.After(timeout):
+	case <-time.After(apiSyncTimeout):
 		http.Error(w, `{"error":"timeout"}`, http.StatusGatewayTimeout)
 	case <-r.Context().Done():
 		return
 	}
 }
 
-func (a *APISense) buildInput(req apiRequest) *UnifiedInput {
+// decodeRequest reads the JSON body within the payload quota. On failure it
+// writes the error response and returns false.
+func (a *APISense) decodeRequest(w http.ResponseWriter, r *http.Request) (apiRequest, bool) {
+	var req apiRequest
+	body, err := a.limits.readBody(w, r, a.Name())
+	if err != nil {
+		writeLimitError(w, err)
+		return req, false
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+		return req, false
+	}
+	a.mu.Lock()
+	known := a.models
+	a.mu.Unlock()
+	if err := req.validate(known); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return req, false
+	}
+	return req, true
+}
+
+// buildInput converts req into an input. Team members (see
+// security.AccessControl) submit as themselves; only admins may set the
+// sender.
+func (a *APISense) buildInput(r *http.Request, req apiRequest) *UnifiedInput {
 	priority := PriorityNormal
 	switch req.Priority {
 	case "LOW":
@@ -199,10 +557,34 @@ func (a *APISense) buildInput(req apiRequest) *UnifiedInput {
 	}
 
 	sender := req.Sender
+	if p, ok := security.PrincipalFrom(r.Context()); ok && (p.Role != security.RoleAdmin || sender == "") {
+		sender = p.Name
+	}
 	if sender == "" {
 		sender = "api_user"
 	}
 
+	extra, sessionID := req.Metadata, req.SessionID
+	if req.Stateless || req.BudgetUSD > 0 || req.Model != "" || req.Complexity != "" {
+		extra = make(map[string]string, len(req.Metadata)+4)
+		for k, v := range req.Metadata {
+			extra[k] = v
+		}
+	}
+	if req.Stateless {
+		extra["stateless"] = "true"
+		sessionID = ""
+	}
+	if req.BudgetUSD > 0 {
+		extra[ExtraBudgetUSD] = strconv.FormatFloat(req.BudgetUSD, 'f', -1, 64)
+	}
+	if req.Model != "" {
+		extra[ExtraModel] = req.Model
+	}
+	if req.Complexity != "" {
+		extra[ExtraComplexity] = req.Complexity
+	}
+
 	return &UnifiedInput{
 		InputID:    newUUID(),
 		SourceType: SourceAPI,
@@ -210,11 +592,11 @@ func (a *APISense) buildInput(req apiRequest) *UnifiedInput {
 			Timestamp: time.Now(),
 			Channel:   "api",
 			Sender:    sender,
-			Extra:     req.Metadata,
+			Extra:     extra,
 		},
 		Payload:   req.Payload,
 		Priority:  priority,
-		SessionID: req.SessionID,
+		SessionID: sessionID,
 	}
 }
 
@@ -223,8 +605,16 @@ func (a *APISense) buildInput(req apiRequest) *UnifiedInput {
 func (a *APISense) Send(ctx context.Context, target string, message string) error {
 	a.responsesMu.RLock()
 	ch, ok := a.responses[target]
+	completion, isCompletion := a.completions[target]
 	a.responsesMu.RUnlock()
 
+	if isCompletion {
+		select {
+		case completion.result <- APIResult{Text: message}:
+		default:
+		}
+		return nil
+	}
 	if ok {
 		select {
 		case ch <- message:
@@ -257,7 +647,7 @@ func (a *APISense) Addr() string {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if a.listener != nil {
-		return a.listener.Addr().String()
+		return netaddr.String(a.listener)
 	}
 	return a.addr
 }
@@ -4,9 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
+	"github.com/overhuman/overhuman/internal/security"
 )
 
 func TestAPISense_Name(t *testing.T) {
@@ -23,8 +30,13 @@ func TestAPISense_ImplementsSense(t *testing.T) {
 // startAPI is a test helper that starts the API sense on a random port.
 func startAPI(t *testing.T) (*APISense, chan *UnifiedInput, context.CancelFunc) {
 	t.Helper()
+	return startAPISense(t, NewAPISense("127.0.0.1:0"))
+}
+
+// startAPISense starts a pre-configured API sense.
+func startAPISense(t *testing.T, api *APISense) (*APISense, chan *UnifiedInput, context.CancelFunc) {
+	t.Helper()
 
-	api := NewAPISense("127.0.0.1:0")
 	out := make(chan *UnifiedInput, 10)
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -83,6 +95,79 @@ func TestAPISense_Health(t *testing.T) {
 	}
 }
 
+func TestAPISense_HealthRateLimitsAndHeartbeat(t *testing.T) {
+	api := NewAPISense("127.0.0.1:0")
+	api.SetRateLimits(func() any { return map[string]int{"requests_remaining": 3} })
+	api.SetHeartbeat(func() any { return NewHeartbeatSchedule(0, 0, nil, time.UTC).Plan(time.Now()) })
+	queue := NewDispatcher(DispatchConfig{})
+	queue.Push(NewUnifiedInput(SourceAPI, "queued"))
+	api.SetQueue(func() any { return queue.Stats() })
+	startAPISense(t, api)
+
+	resp, err := http.Get("http://" + api.Addr() + "/health")
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		RateLimits map[string]int `json:"rate_limits"`
+		Heartbeat  HeartbeatPlan  `json:"heartbeat"`
+		Queue      DispatchStats  `json:"queue"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body.RateLimits["requests_remaining"] != 3 {
+		t.Errorf("rate_limits = %v", body.RateLimits)
+	}
+	if body.Heartbeat.Every == "" || body.Heartbeat.Reason == "" || body.Heartbeat.NextAt.IsZero() {
+		t.Errorf("heartbeat = %+v", body.Heartbeat)
+	}
+	if body.Queue.Queued != 1 || len(body.Queue.Lanes) != 1 || body.Queue.Lanes[0].Lane != "API" {
+		t.Errorf("queue = %+v", body.Queue)
+	}
+}
+
+func TestAPISense_Capabilities(t *testing.T) {
+	reg := NewSenseRegistry()
+	reg.Register(NewAPISense(":0"))
+	reg.Register(NewFileWatcherSense(FileWatcherConfig{WatchDir: t.TempDir()}))
+
+	api := NewAPISense("127.0.0.1:0")
+	api.SetCapabilities(func() Capabilities {
+		return Capabilities{Version: "9.9", Senses: reg.Names(), InputTypes: reg.SourceTypes()}
+	})
+	startAPISense(t, api)
+
+	resp, err := http.Get("http://" + api.Addr() + "/capabilities")
+	if err != nil {
+		t.Fatalf("GET /capabilities: %v", err)
+	}
+	defer resp.Body.Close()
+	var c Capabilities
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.APIVersion != APIVersion || c.Version != "9.9" {
+		t.Errorf("versions = %q, %q", c.APIVersion, c.Version)
+	}
+	if len(c.Senses) != 2 || c.Senses[0] != "API" || c.Senses[1] != "FileWatcher" {
+		t.Errorf("senses = %v", c.Senses)
+	}
+	if len(c.InputTypes) != 2 || c.InputTypes[0] != SourceAPI || c.InputTypes[1] != SourceFile {
+		t.Errorf("input types = %v", c.InputTypes)
+	}
+
+	// Without SetCapabilities the endpoint is not registered.
+	plain, _, _ := startAPI(t)
+	resp2, err := http.Get("http://" + plain.Addr() + "/capabilities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp2.Body.Close()
+	if resp2.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp2.StatusCode)
+	}
+}
+
 func TestAPISense_PostInput(t *testing.T) {
 	api, out, _ := startAPI(t)
 
@@ -194,6 +279,102 @@ func TestAPISense_DefaultPriority(t *testing.T) {
 	}
 }
 
+func TestAPISense_Stateless(t *testing.T) {
+	api, out, _ := startAPI(t)
+
+	payload := `{"payload":"one-off","session_id":"client_7","stateless":true}`
+	resp, err := http.Post("http://"+api.Addr()+"/input", "application/json", bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	select {
+	case input := <-out:
+		if input.SessionID != "" || input.SourceMeta.Extra["stateless"] != "true" {
+			t.Errorf("session %q, extra %v; want no session and the stateless flag", input.SessionID, input.SourceMeta.Extra)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestAPISense_RequestRouting(t *testing.T) {
+	sense := NewAPISense("127.0.0.1:0")
+	sense.SetModels(func() []string { return []string{"small", "large"} })
+	api, out, _ := startAPISense(t, sense)
+	post := func(body string) int {
+		t.Helper()
+		resp, err := http.Post("http://"+api.Addr()+"/input", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, body := range []string{
+		`{"payload":"x","model":"huge"}`,
+		`{"payload":"x","complexity":"hard"}`,
+		`{"payload":"x","budget_usd":-1}`,
+	} {
+		if code := post(body); code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, code)
+		}
+	}
+
+	if code := post(`{"payload":"x","model":"large","complexity":"complex","budget_usd":0.25}`); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", code)
+	}
+	select {
+	case input := <-out:
+		extra := input.SourceMeta.Extra
+		if extra[ExtraModel] != "large" || extra[ExtraComplexity] != "complex" || extra[ExtraBudgetUSD] != "0.25" {
+			t.Errorf("extra = %v", extra)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestAPISense_TeamSender(t *testing.T) {
+	api := NewAPISense("127.0.0.1:0")
+	// Stand-in for the team access control: the role comes in a header.
+	api.SetMiddleware(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := security.Principal{Name: r.Header.Get("X-Name"), Role: security.Role(r.Header.Get("X-Role"))}
+			h.ServeHTTP(w, r.WithContext(security.WithPrincipal(r.Context(), p)))
+		})
+	})
+	api, out, _ := startAPISense(t, api)
+
+	for _, tc := range []struct {
+		name string
+		role security.Role
+		want string
+	}{
+		{"ben", security.RoleMember, "ben"},
+		{"anna", security.RoleAdmin, "mallory"},
+	} {
+		req, _ := http.NewRequest("POST", "http://"+api.Addr()+"/input", bytes.NewBufferString(`{"payload":"hi","sender":"mallory"}`))
+		req.Header.Set("X-Name", tc.name)
+		req.Header.Set("X-Role", string(tc.role))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		select {
+		case input := <-out:
+			if input.SourceMeta.Sender != tc.want {
+				t.Errorf("%s: sender = %q, want %q", tc.role, input.SourceMeta.Sender, tc.want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out")
+		}
+	}
+}
+
 func TestAPISense_SendToCorrelationID(t *testing.T) {
 	api := NewAPISense(":0")
 
@@ -234,3 +415,331 @@ func TestAPISense_Stop(t *testing.T) {
 		t.Fatalf("Stop: %v", err)
 	}
 }
+
+func TestAPISense_MaintenanceMode(t *testing.T) {
+	mode := NewModeSwitch()
+	sense := NewAPISense("127.0.0.1:0")
+	sense.SetMode(mode, "s3cret")
+	api, out, _ := startAPISense(t, sense)
+	base := "http://" + api.Addr()
+
+	setMode := func(token, body string) int {
+		req, _ := http.NewRequest("POST", base+"/mode", bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /mode: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := setMode("wrong", `{"mode":"maintenance"}`); code != http.StatusUnauthorized {
+		t.Fatalf("bad token status = %d, want 401", code)
+	}
+	if code := setMode("s3cret", `{"mode":"maintenance","message":"Backing up, back at 3am"}`); code != http.StatusOK {
+		t.Fatalf("set mode status = %d", code)
+	}
+
+	resp, err := http.Post(base+"/input", "application/json", bytes.NewBufferString(`{"payload":"hello"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rejected map[string]string
+	json.NewDecoder(resp.Body).Decode(&rejected)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || rejected["message"] != "Backing up, back at 3am" {
+		t.Errorf("maintenance input: %d %v", resp.StatusCode, rejected)
+	}
+	if len(out) != 0 {
+		t.Error("input should not reach the pipeline in maintenance mode")
+	}
+
+	resp, _ = http.Get(base + "/health")
+	var health apiHealthResponse
+	json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if health.Mode != ModeMaintenance || health.ModeMessage == "" {
+		t.Errorf("health = %+v, want maintenance mode", health)
+	}
+
+	setMode("s3cret", `{"mode":"normal"}`)
+	resp, _ = http.Post(base+"/input", "application/json", bytes.NewBufferString(`{"payload":"hello"}`))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("normal mode input status = %d, want 202", resp.StatusCode)
+	}
+}
+
+func TestAPISense_UnixSocketAndShutdown(t *testing.T) {
+	dir, err := os.MkdirTemp("", "oh") // short path: sun_path is ~108 bytes
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "api.sock")
+	addr := "unix:" + sock
+
+	stopped := make(chan struct{})
+	api := NewAPISense(addr)
+	api.SetShutdown(func() { close(stopped) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go api.Start(ctx, make(chan *UnifiedInput, 1))
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := os.Stat(sock); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("API did not create the unix socket")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := api.Addr(); got != addr {
+		t.Errorf("Addr() = %q, want %q", got, addr)
+	}
+
+	client := netaddr.HTTPClient(addr, 2*time.Second)
+	resp, err := client.Get(netaddr.URL(addr, "/health"))
+	if err != nil {
+		t.Fatalf("GET /health over unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("health status = %d", resp.StatusCode)
+	}
+
+	// No admin token: unix socket clients count as local.
+	resp, err = client.Post(netaddr.URL(addr, "/shutdown"), "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /shutdown: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("shutdown status = %d, want 202", resp.StatusCode)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown callback not called")
+	}
+}
+
+func TestAPISense_ChatCompletions(t *testing.T) {
+	api, out, _ := startAPI(t)
+	url := "http://" + api.Addr() + "/v1/chat/completions"
+
+	// Answer each input as the daemon would.
+	go func() {
+		for in := range out {
+			api.SendResult(context.Background(), in.CorrelationID, APIResult{Text: "Rome, on Friday.", Model: "gpt-4o"}, nil, nil)
+		}
+	}()
+
+	body := `{"model":"anything","messages":[
+		{"role":"system","content":"Be brief."},
+		{"role":"user","content":"I fly to Rome"},
+		{"role":"assistant","content":"Noted."},
+		{"role":"user","content":[{"type":"text","text":"Where and when?"}]}]}`
+	resp, err := http.Post(url, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got struct {
+		Object  string
+		Model   string
+		Choices []struct {
+			Message      struct{ Role, Content string }
+			FinishReason string `json:"finish_reason"`
+		}
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d: %v", resp.StatusCode, err)
+	}
+	if got.Object != "chat.completion" || got.Model != "gpt-4o" || len(got.Choices) != 1 ||
+		got.Choices[0].Message.Content != "Rome, on Friday." || got.Choices[0].FinishReason != "stop" || got.Usage.TotalTokens == 0 {
+		t.Errorf("completion = %+v", got)
+	}
+}
+
+func TestAPISense_ChatCompletionsStream(t *testing.T) {
+	api, out, _ := startAPI(t)
+	go func() {
+		in := <-out
+		if in.SourceMeta.Extra["stateless"] != "true" || in.Payload != "hello" {
+			t.Errorf("input = %+v", in)
+		}
+		api.Send(context.Background(), in.CorrelationID, "Hi there")
+	}()
+
+	resp, err := http.Post("http://"+api.Addr()+"/v1/chat/completions", "application/json",
+		bytes.NewBufferString(`{"stream":true,"messages":[{"role":"user","content":"hello"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	stream := string(data)
+	for _, want := range []string{`"delta":{"role":"assistant"}`, `"delta":{"content":"Hi there"}`, `"finish_reason":"stop"`, "data: [DONE]"} {
+		if !strings.Contains(stream, want) {
+			t.Errorf("stream missing %s:\n%s", want, stream)
+		}
+	}
+}
+
+func TestAPISense_ChatCompletionsStreamPartials(t *testing.T) {
+	for _, tc := range []struct {
+		name, result, want string
+	}{
+		{"result streamed", "Hello world", "Hello world"},
+		{"result differs", "Bonjour", "Hello\n\nBonjour"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api, out, _ := startAPI(t)
+			go func() {
+				in := <-out
+				api.SendPartial(in.CorrelationID, "Hel", false)
+				time.Sleep(50 * time.Millisecond) // let it go out on its own
+				api.SendPartial(in.CorrelationID, "lo", false)
+				api.SendPartial(in.CorrelationID, "", true)
+				api.SendPartial(in.CorrelationID, "retried", false) // a second completion is not streamed
+				api.SendResult(context.Background(), in.CorrelationID, APIResult{Text: tc.result}, nil, nil)
+			}()
+
+			resp, err := http.Post("http://"+api.Addr()+"/v1/chat/completions", "application/json",
+				bytes.NewBufferString(`{"stream":true,"messages":[{"role":"user","content":"hello"}]}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			var deltas []string
+			for _, line := range strings.Split(string(data), "\n") {
+				var chunk struct {
+					Choices []struct {
+						Delta map[string]string `json:"delta"`
+					} `json:"choices"`
+				}
+				if json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk) == nil && len(chunk.Choices) == 1 {
+					if c, ok := chunk.Choices[0].Delta["content"]; ok {
+						deltas = append(deltas, c)
+					}
+				}
+			}
+			if len(deltas) < 2 || deltas[0] != "Hel" || strings.Join(deltas, "") != tc.want {
+				t.Errorf("content deltas = %q, want %q starting with the first chunk", deltas, tc.want)
+			}
+			if !strings.Contains(string(data), "data: [DONE]") {
+				t.Errorf("stream not finished:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestChatPayload(t *testing.T) {
+	msg := func(role, content string) chatMessage {
+		raw, _ := json.Marshal(content)
+		return chatMessage{Role: role, Content: raw}
+	}
+	if _, err := chatPayload(nil); err == nil {
+		t.Error("no messages accepted")
+	}
+	if _, err := chatPayload([]chatMessage{msg("user", "hi"), msg("assistant", "hello")}); err == nil {
+		t.Error("conversation ending with the assistant accepted")
+	}
+	got, err := chatPayload([]chatMessage{msg("system", "Be brief."), msg("user", "I fly to Rome"), msg("user", "When?")})
+	want := "Conversation so far:\nsystem: Be brief.\nuser: I fly to Rome\n\nWhen?"
+	if err != nil || got != want {
+		t.Errorf("payload = %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestAPISense_Tasks(t *testing.T) {
+	api := NewAPISense("127.0.0.1:0")
+	var cancelled []string
+	api.SetTasks(func(*http.Request) any { return []string{"task_1"} }, func(r *http.Request, id string) bool {
+		if id != "task_1" {
+			return false
+		}
+		cancelled = append(cancelled, id)
+		return true
+	})
+	startAPISense(t, api)
+	base := "http://" + api.Addr()
+
+	resp, err := http.Get(base + "/tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list struct{ Tasks []string }
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list.Tasks) != 1 || list.Tasks[0] != "task_1" {
+		t.Errorf("GET /tasks = %+v", list)
+	}
+
+	del := func(id string) int {
+		req, _ := http.NewRequest(http.MethodDelete, base+"/tasks/"+id, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := del("task_1"); code != http.StatusAccepted {
+		t.Errorf("DELETE running task = %d, want 202", code)
+	}
+	if code := del("task_2"); code != http.StatusNotFound {
+		t.Errorf("DELETE unknown task = %d, want 404", code)
+	}
+	if len(cancelled) != 1 {
+		t.Errorf("cancelled = %v", cancelled)
+	}
+}
+
+func TestAPISense_Routes(t *testing.T) {
+	api := NewAPISense("127.0.0.1:0")
+	api.SetMode(NewModeSwitch(), "s3cret")
+	api.SetRoutes("/things", func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /things", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("list")) })
+		mux.HandleFunc("DELETE /things/{id}", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(r.PathValue("id"))) })
+	})
+	startAPISense(t, api)
+	base := "http://" + api.Addr()
+
+	call := func(method, path, token string) (int, string) {
+		req, _ := http.NewRequest(method, base+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if code, body := call(http.MethodGet, "/things", ""); code != http.StatusOK || body != "list" {
+		t.Errorf("GET = %d %q", code, body)
+	}
+	if code, _ := call(http.MethodDelete, "/things/a", ""); code != http.StatusUnauthorized {
+		t.Errorf("DELETE without token = %d, want 401", code)
+	}
+	if code, body := call(http.MethodDelete, "/things/a", "s3cret"); code != http.StatusOK || body != "a" {
+		t.Errorf("DELETE = %d %q", code, body)
+	}
+}
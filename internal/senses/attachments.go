@@ -0,0 +1,101 @@
+package senses
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Outgoing attachments — files sent along with a reply.
+// ---------------------------------------------------------------------------
+
+// AttachmentCaps describes which attachments a sense delivers natively.
+type AttachmentCaps struct {
+	Images   bool  `json:"images"`              // image/* files
+	Files    bool  `json:"files"`               // any other file
+	MaxBytes int64 `json:"max_bytes,omitempty"` // per file; 0 = no limit
+}
+
+// accepts reports whether att can be delivered natively.
+func (c AttachmentCaps) accepts(att Attachment) bool {
+	if c.MaxBytes > 0 && att.Size > c.MaxBytes {
+		return false
+	}
+	if strings.HasPrefix(att.Type, "image/") {
+		return c.Images || c.Files
+	}
+	return c.Files
+}
+
+// AttachmentSender is implemented by senses that can send files with a
+// message. Senses without it get links instead (see SendWithAttachments).
+type AttachmentSender interface {
+	AttachmentCaps() AttachmentCaps
+	// SendAttachments sends message with atts, whose Path is a local file.
+	SendAttachments(ctx context.Context, target, message string, atts []Attachment) error
+}
+
+// SendWithAttachments sends message with atts through s: natively where its
+// capabilities allow, and as links listed under the message otherwise.
+// link returns the download URL of an attachment ("" = none, the file is
+// only named).
+func SendWithAttachments(ctx context.Context, s Sense, target, message string, atts []Attachment, link func(Attachment) string) error {
+	var native, linked []Attachment
+	as, ok := s.(AttachmentSender)
+	for _, att := range atts {
+		if ok && as.AttachmentCaps().accepts(att) {
+			native = append(native, att)
+		} else {
+			linked = append(linked, att)
+		}
+	}
+	message += attachmentLinks(linked, link)
+	if len(native) > 0 {
+		return as.SendAttachments(ctx, target, message, native)
+	}
+	return s.Send(ctx, target, message)
+}
+
+// attachmentLinks lists atts as a block appended to a message.
+func attachmentLinks(atts []Attachment, link func(Attachment) string) string {
+	if len(atts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nFiles:")
+	for _, att := range atts {
+		fmt.Fprintf(&b, "\n- %s (%s)", att.Name, formatSize(att.Size))
+		if link != nil {
+			if u := link(att); u != "" {
+				b.WriteString(": " + u)
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatSize renders n bytes for people.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// AttachmentCaps returns the native attachment capabilities of the
+// registered senses that have any, by name.
+func (r *SenseRegistry) AttachmentCaps() map[string]AttachmentCaps {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	caps := make(map[string]AttachmentCaps)
+	for name, s := range r.senses {
+		if as, ok := s.(AttachmentSender); ok {
+			caps[name] = as.AttachmentCaps()
+		}
+	}
+	return caps
+}
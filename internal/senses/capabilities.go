@@ -0,0 +1,102 @@
+package senses
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// ---------------------------------------------------------------------------
+// Capabilities — GET /capabilities handshake for external clients
+// ---------------------------------------------------------------------------
+
+// APIVersion is the client API version reported by GET /capabilities. It is
+// bumped when an endpoint or message format changes incompatibly.
+const APIVersion = "1"
+
+// Capabilities describes what this deployment of the daemon offers, so
+// mobile apps, plugins and other agents can adapt instead of assuming a
+// particular setup.
+type Capabilities struct {
+	APIVersion string `json:"api_version"`
+	Version    string `json:"version"` // daemon version
+	Agent      string `json:"agent,omitempty"`
+
+	// Senses are the enabled input channels (Sense.Name()); InputTypes are
+	// the source types the daemon accepts through them.
+	Senses     []string     `json:"senses"`
+	InputTypes []SourceType `json:"input_types"`
+	// Attachments are the senses that deliver files with replies natively;
+	// the others get download links.
+	Attachments map[string]AttachmentCaps `json:"attachments,omitempty"`
+
+	// Tools are the enabled instruments beyond skills (e.g. "subagents");
+	// Skills are the active skills.
+	Tools  []string          `json:"tools"`
+	Skills []CapabilitySkill `json:"skills"`
+
+	// UIFormats are the generated UI formats clients may request.
+	UIFormats []string `json:"ui_formats"`
+
+	// Endpoints maps endpoint names ("api", "ws", "kiosk") to addresses.
+	Endpoints map[string]string `json:"endpoints,omitempty"`
+
+	Auth CapabilityAuth `json:"auth"`
+	Mode DaemonMode     `json:"mode,omitempty"`
+}
+
+// CapabilitySkill is a skill as listed in Capabilities.
+type CapabilitySkill struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// CapabilityAuth describes how clients authenticate.
+type CapabilityAuth struct {
+	// Input is the scheme for POST /input and WebSocket input ("none").
+	Input string `json:"input"`
+	// Admin is the scheme for admin endpoints: "bearer" when an admin token
+	// is configured, otherwise "loopback" (local clients only).
+	Admin          string   `json:"admin"`
+	AdminEndpoints []string `json:"admin_endpoints,omitempty"`
+}
+
+// CapabilitiesHandler serves GET /capabilities. fn is called per request so
+// senses and skills registered after startup are included.
+func CapabilitiesHandler(fn func() Capabilities) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := fn()
+		c.APIVersion = APIVersion
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		json.NewEncoder(w).Encode(c)
+	})
+}
+
+// Names returns the names of the registered senses, sorted.
+func (r *SenseRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.senses))
+	for name := range r.senses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SourceTypes returns the source types of the registered senses, sorted.
+func (r *SenseRegistry) SourceTypes() []SourceType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var types []SourceType
+	for st, name := range sourceTypeToSenseName {
+		if _, ok := r.senses[name]; ok {
+			types = append(types, st)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
@@ -1,12 +1,21 @@
 package senses
 
 import (
+	"bufio"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -71,42 +80,78 @@ func TestTelegramSense_IsAllowed_EmptyWhitelist(t *testing.T) {
 
 // TestTelegramSense_PollWithMockServer tests the polling loop against a mock Telegram API.
 func TestTelegramSense_PollWithMockServer(t *testing.T) {
-	callCount := 0
+	var mu sync.Mutex
+	var offsets []string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-		if callCount == 1 {
-			// First call: return a message.
-			json.NewEncoder(w).Encode(telegramResponse{
-				OK: true,
-				Result: []telegramUpdate{
-					{
-						UpdateID: 1,
-						Message: &telegramMessage{
-							MessageID: 100,
-							From:      telegramUser{ID: 42, Username: "testuser", FirstName: "Test"},
-							Chat:      telegramChat{ID: 42, Type: "private"},
-							Text:      "hello bot",
-						},
-					},
-				},
-			})
-		} else {
-			// Second+ call: context should be cancelled, return empty.
+		if r.URL.Path != "/bottok/getUpdates" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		mu.Lock()
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		n := len(offsets)
+		mu.Unlock()
+		if n > 1 {
 			json.NewEncoder(w).Encode(telegramResponse{OK: true})
+			return
 		}
+		json.NewEncoder(w).Encode(telegramResponse{
+			OK: true,
+			Result: []telegramUpdate{
+				{UpdateID: 1, Message: &telegramMessage{MessageID: 100, From: telegramUser{ID: 42, Username: "testuser"}, Chat: telegramChat{ID: 42, Type: "private"}, Text: "hello bot"}},
+				{UpdateID: 2, Message: &telegramMessage{MessageID: 101, From: telegramUser{ID: 9}, Chat: telegramChat{ID: 9, Type: "private"}, Text: "let me in"}},
+				{UpdateID: 3, Message: &telegramMessage{MessageID: 102, From: telegramUser{ID: 7}, Chat: telegramChat{ID: -100, Type: "group"}, Text: "from the team chat"}},
+			},
+		})
 	}))
 	defer server.Close()
 
-	// We can't easily redirect the Telegram API URL from poll(), but we can test
-	// the message parsing by testing the types directly.
-	msg := telegramMessage{
-		MessageID: 1,
-		From:      telegramUser{ID: 42, Username: "user1"},
-		Chat:      telegramChat{ID: 42},
-		Text:      "test message",
+	s := NewTelegramSense(TelegramConfig{Token: "tok", PollTimeout: time.Second, AllowedIDs: []int64{42, -100}})
+	s.apiBase = server.URL + "/bottok"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan *UnifiedInput, 10)
+	go s.Start(ctx, out)
+
+	var got []*UnifiedInput
+	for len(got) < 2 {
+		select {
+		case in := <-out:
+			got = append(got, in)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got %d inputs, want 2", len(got))
+		}
+	}
+	if got[0].Payload != "hello bot" || got[0].SourceType != SourceTelegram || got[0].ResponseChannel != "42" || got[0].SourceMeta.Extra["username"] != "testuser" {
+		t.Errorf("first input = %+v", got[0])
+	}
+	if got[1].Payload != "from the team chat" || got[1].SourceMeta.Sender != "7" || got[1].ResponseChannel != "-100" {
+		t.Errorf("group input = %+v", got[1])
+	}
+	select {
+	case in := <-out:
+		t.Errorf("unexpected input %q from a chat not allowed", in.Payload)
+	case <-time.After(50 * time.Millisecond):
 	}
-	if msg.Text != "test message" {
-		t.Errorf("Text = %q", msg.Text)
+	cancel()
+	mu.Lock()
+	defer mu.Unlock()
+	if offsets[0] != "0" || len(offsets) < 2 || offsets[1] != "4" {
+		t.Errorf("offsets = %v", offsets)
+	}
+}
+
+func TestTelegramSense_PollRejectedToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	s := NewTelegramSense(TelegramConfig{Token: "bad"})
+	s.apiBase = server.URL + "/botbad"
+	err := s.Start(context.Background(), make(chan *UnifiedInput))
+	if err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("Start = %v, want the API error", err)
 	}
 }
 
@@ -179,6 +224,7 @@ func TestSlackSense_EventCallback(t *testing.T) {
 			"user": "U456",
 			"text": "hello from slack",
 			"channel": "C789",
+			"channel_type": "im",
 			"ts": "1234567890.123456"
 		}
 	}`
@@ -267,6 +313,228 @@ func TestSlackSense_Send(t *testing.T) {
 	}
 }
 
+func TestSlackSense_MentionsAndDMs(t *testing.T) {
+	s := NewSlackSense(SlackConfig{BotToken: "xoxb-test"})
+	out := make(chan *UnifiedInput, 10)
+	post := func(event string) {
+		body := `{"type":"event_callback","team_id":"T1","event_id":"` + fmt.Sprint(len(event)) + `","event":` + event + `}`
+		s.handleEvents(out)(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body)))
+	}
+
+	post(`{"type":"app_mention","user":"U1","text":"<@U0BOT> summarize the thread","channel":"C1","ts":"100.1"}`)
+	post(`{"type":"message","user":"U1","text":"<@U0BOT> summarize the thread","channel":"C1","channel_type":"channel","ts":"100.1"}`)
+	post(`{"type":"message","subtype":"message_changed","user":"U1","text":"edited","channel":"D1","channel_type":"im","ts":"100.2"}`)
+	post(`{"type":"message","user":"U2","text":"in a thread","channel":"D1","channel_type":"im","ts":"100.4","thread_ts":"100.3"}`)
+
+	var got []*UnifiedInput
+	for len(out) > 0 {
+		got = append(got, <-out)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d inputs, want the mention and the DM", len(got))
+	}
+	if got[0].Payload != "summarize the thread" || got[0].ResponseChannel != "C1:100.1" {
+		t.Errorf("mention: payload %q, reply to %q", got[0].Payload, got[0].ResponseChannel)
+	}
+	if got[1].ResponseChannel != "D1:100.3" || got[1].SourceMeta.Extra["thread_ts"] != "100.3" {
+		t.Errorf("threaded DM: reply to %q", got[1].ResponseChannel)
+	}
+
+	// Slack redelivers events it thinks were missed.
+	post(`{"type":"message","user":"U2","text":"in a thread","channel":"D1","channel_type":"im","ts":"100.4","thread_ts":"100.3"}`)
+	if len(out) != 0 {
+		t.Error("a redelivered event should be ignored")
+	}
+}
+
+func TestSlackSense_Signature(t *testing.T) {
+	s := NewSlackSense(SlackConfig{BotToken: "xoxb-test", SigningSecret: "shh"})
+	out := make(chan *UnifiedInput, 10)
+	body := `{"type":"url_verification","challenge":"c"}`
+	sign := func(ts time.Time) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+		stamp := fmt.Sprint(ts.Unix())
+		mac := hmac.New(sha256.New, []byte("shh"))
+		mac.Write([]byte("v0:" + stamp + ":" + body))
+		req.Header.Set("X-Slack-Request-Timestamp", stamp)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	s.handleEvents(out)(w, sign(time.Now()))
+	if w.Code != http.StatusOK {
+		t.Errorf("signed request: %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.handleEvents(out)(w, sign(time.Now().Add(-time.Hour)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("replayed request: %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.handleEvents(out)(w, httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned request: %d", w.Code)
+	}
+}
+
+func TestSlackSense_SendInThread(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	s := NewSlackSense(SlackConfig{BotToken: "xoxb-test"})
+	s.apiBase = srv.URL
+	if err := s.Send(context.Background(), "C1:100.1", "done"); err != nil {
+		t.Fatal(err)
+	}
+	if got["channel"] != "C1" || got["thread_ts"] != "100.1" {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestSlackSense_SocketMode(t *testing.T) {
+	var mu sync.Mutex
+	var acks []string
+	connections := 0
+	event := `{"envelope_id":"%s","type":"events_api","payload":{"type":"event_callback","event_id":"Ev1","event":{"type":"app_mention","user":"U1","text":"<@U0BOT> hi","channel":"C1","ts":"5.5"}}}`
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apps.connections.open":
+			if r.Header.Get("Authorization") != "Bearer xapp-test" {
+				w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+				return
+			}
+			fmt.Fprintf(w, `{"ok":true,"url":"ws://%s/link"}`, strings.TrimPrefix(srv.URL, "http://"))
+		case "/link":
+			mu.Lock()
+			connections++
+			n := connections
+			mu.Unlock()
+			rw := acceptTestWebSocket(t, w, r)
+			if n > 1 {
+				readTestFrame(rw.Reader) // hold the connection until the client goes
+				return
+			}
+			writeTestFrame(rw.Writer, wsOpText, []byte(`{"type":"hello"}`))
+			writeTestFrame(rw.Writer, wsOpPing, []byte("p"))
+			for _, id := range []string{"e1", "e2"} { // e2 redelivers the same event
+				writeTestFrame(rw.Writer, wsOpText, fmt.Appendf(nil, event, id))
+			}
+			for n := 0; n < 2; {
+				op, data, err := readTestFrame(rw.Reader)
+				if err != nil {
+					return
+				}
+				if op == wsOpText {
+					mu.Lock()
+					acks = append(acks, string(data))
+					mu.Unlock()
+					n++
+				}
+			}
+			writeTestFrame(rw.Writer, wsOpText, []byte(`{"type":"disconnect","reason":"refresh_requested"}`))
+			readTestFrame(rw.Reader)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewSlackSense(SlackConfig{BotToken: "xoxb-test", AppToken: "xapp-test"})
+	s.apiBase = srv.URL
+	out := make(chan *UnifiedInput, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Start(ctx, out) }()
+
+	select {
+	case in := <-out:
+		if in.Payload != "hi" || in.ResponseChannel != "C1:5.5" {
+			t.Errorf("input = %q, reply to %q", in.Payload, in.ResponseChannel)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no input over socket mode")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := connections
+		mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Start = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if connections != 2 {
+		t.Errorf("connections = %d, want a reconnect after disconnect", connections)
+	}
+	if len(acks) != 2 || acks[0] != `{"envelope_id":"e1"}` {
+		t.Errorf("acks = %q", acks)
+	}
+	if len(out) != 0 {
+		t.Error("the redelivered event should be ignored")
+	}
+
+	s = NewSlackSense(SlackConfig{BotToken: "xoxb-test", AppToken: "xapp-wrong"})
+	s.apiBase = srv.URL
+	if err := s.Start(context.Background(), out); err == nil || !strings.Contains(err.Error(), "invalid_auth") {
+		t.Errorf("bad app token: %v", err)
+	}
+}
+
+// acceptTestWebSocket completes a server-side WebSocket handshake.
+func acceptTestWebSocket(t *testing.T, w http.ResponseWriter, r *http.Request) *bufio.ReadWriter {
+	t.Helper()
+	h := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	_, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(h[:]))
+	rw.Flush()
+	return rw
+}
+
+// writeTestFrame writes an unmasked (server) frame of up to 64 KiB.
+func writeTestFrame(w *bufio.Writer, op byte, data []byte) {
+	if len(data) <= 125 {
+		w.Write([]byte{0x80 | op, byte(len(data))})
+	} else {
+		w.Write([]byte{0x80 | op, 126, byte(len(data) >> 8), byte(len(data))})
+	}
+	w.Write(data)
+	w.Flush()
+}
+
+// readTestFrame reads a masked (client) frame of up to 125 bytes.
+func readTestFrame(r *bufio.Reader) (byte, []byte, error) {
+	var hdr [6]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	data := make([]byte, hdr[1]&0x7f)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+	for i := range data {
+		data[i] ^= hdr[2+i%4]
+	}
+	return hdr[0] & 0x0f, data, nil
+}
+
 // --- Discord tests ---
 
 func TestNewDiscordSense(t *testing.T) {
@@ -518,3 +786,118 @@ func TestDiscordSense_StartStop(t *testing.T) {
 	cancel()
 	<-done
 }
+
+// --- Signal tests ---
+
+// fakeSignalCLI serves one signal-cli JSON-RPC connection over a pipe: it
+// records requests, answers "send" (failing for recipient "+0"), and
+// pushes notifications written to the returned channel.
+func fakeSignalCLI(t *testing.T) (dial func(context.Context) (net.Conn, error), notify chan<- string, requests <-chan map[string]any) {
+	t.Helper()
+	notifyCh := make(chan string, 10)
+	reqCh := make(chan map[string]any, 10)
+	dial = func(ctx context.Context) (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			for line := range notifyCh {
+				server.Write([]byte(line + "\n"))
+			}
+		}()
+		go func() {
+			sc := bufio.NewScanner(server)
+			for sc.Scan() {
+				var req map[string]any
+				json.Unmarshal(sc.Bytes(), &req)
+				reqCh <- req
+				params, _ := req["params"].(map[string]any)
+				resp := fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":{"timestamp":1}}`, req["id"])
+				if r, _ := params["recipient"].([]any); len(r) == 1 && r[0] == "+0" {
+					resp = fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"error":{"code":-1,"message":"Unregistered user"}}`, req["id"])
+				}
+				server.Write([]byte(resp + "\n"))
+			}
+		}()
+		return client, nil
+	}
+	return dial, notifyCh, reqCh
+}
+
+func TestSignalSense_ReceiveAndSend(t *testing.T) {
+	dial, notify, requests := fakeSignalCLI(t)
+	s := NewSignalSense(SignalConfig{
+		Account:        "+4915100000000",
+		AllowedNumbers: []string{"+49 151 1111-1111", "9f0c-uuid"},
+		AttachmentDir:  "/var/signal/att",
+		Dial:           dial,
+	})
+	out := make(chan *UnifiedInput, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Start(ctx, out) }()
+
+	notify <- `{"jsonrpc":"2.0","method":"receive","params":{"account":"+4915100000000","envelope":{"sourceNumber":"+4915122222222","timestamp":1,"dataMessage":{"message":"not allowed"}}}}`
+	notify <- `{"jsonrpc":"2.0","method":"receive","params":{"account":"+4915100000000","envelope":{"sourceNumber":"+4915111111111","timestamp":2,"receiptMessage":{"isDelivery":true}}}}`
+	notify <- `{"jsonrpc":"2.0","method":"receive","params":{"account":"+4915100000000","envelope":{"sourceNumber":"+4915111111111","sourceName":"Alice","timestamp":1700000000000,"dataMessage":{"message":"Book a table","attachments":[{"contentType":"image/jpeg","filename":"menu.jpg","id":"abc.jpg","size":2048}]}}}}`
+	notify <- `{"jsonrpc":"2.0","method":"receive","params":{"account":"+4915100000000","envelope":{"sourceUuid":"9f0c-uuid","timestamp":3,"dataMessage":{"message":"hi team","groupInfo":{"groupId":"R3JvdXA="}}}}}`
+
+	in := <-out
+	if in.SourceType != SourceSignal || in.Payload != "Book a table" || in.SourceMeta.Sender != "+4915111111111" ||
+		in.ResponseChannel != "+4915111111111" || in.SourceMeta.Extra["name"] != "Alice" || in.SourceMeta.Timestamp.UnixMilli() != 1700000000000 {
+		t.Errorf("direct message = %+v", in)
+	}
+	if len(in.Attachments) != 1 || in.Attachments[0].Path != "/var/signal/att/abc.jpg" || in.Attachments[0].Name != "menu.jpg" {
+		t.Errorf("attachments = %+v", in.Attachments)
+	}
+	group := <-out
+	if group.ResponseChannel != "group:R3JvdXA=" || group.SourceMeta.Sender != "9f0c-uuid" {
+		t.Errorf("group message = %+v", group)
+	}
+	select {
+	case extra := <-out:
+		t.Errorf("unexpected input %+v", extra)
+	default:
+	}
+
+	if err := s.Send(ctx, "group:R3JvdXA=", "Done"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := <-requests
+	params := req["params"].(map[string]any)
+	if req["method"] != "send" || params["groupId"] != "R3JvdXA=" || params["message"] != "Done" || params["account"] != "+4915100000000" {
+		t.Errorf("send request = %v", req)
+	}
+	if err := s.SendAttachments(ctx, "+4915111111111", "", []string{"/tmp/receipt.pdf"}); err != nil {
+		t.Fatalf("SendAttachments: %v", err)
+	}
+	req = <-requests
+	params = req["params"].(map[string]any)
+	if atts, _ := params["attachments"].([]any); len(atts) != 1 || atts[0] != "/tmp/receipt.pdf" {
+		t.Errorf("attachment request = %v", req)
+	}
+	if err := s.Send(ctx, "+0", "hello"); err == nil || !strings.Contains(err.Error(), "Unregistered user") {
+		t.Errorf("Send to unregistered = %v", err)
+	}
+	if err := s.Send(ctx, "+4915111111111", ""); err == nil {
+		t.Error("expected error for empty message")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestSignalSense_SendNotConnected(t *testing.T) {
+	s := NewSignalSense(SignalConfig{Account: "+1"})
+	if s.Name() != "Signal" || s.config.Addr != "127.0.0.1:7583" {
+		t.Errorf("defaults: name %q, addr %q", s.Name(), s.config.Addr)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Send(ctx, "+2", "hi"); err == nil {
+		t.Error("expected error without a signal-cli connection")
+	}
+}
+
+func TestSignalSense_ImplementsSense(t *testing.T) {
+	var _ Sense = (*SignalSense)(nil)
+}
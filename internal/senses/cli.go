@@ -103,6 +103,20 @@ func (c *CLISense) Send(ctx context.Context, target string, message string) erro
 	return err
 }
 
+// SendPartial writes a piece of a response that is still being generated,
+// without the blank lines Send puts around a message.
+func (c *CLISense) SendPartial(chunk string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return fmt.Errorf("cli sense is stopped")
+	}
+
+	_, err := io.WriteString(c.writer, chunk)
+	return err
+}
+
 // Stop gracefully stops the CLI sense.
 func (c *CLISense) Stop() error {
 	c.mu.Lock()
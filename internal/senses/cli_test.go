@@ -119,6 +119,20 @@ func TestCLISense_Send(t *testing.T) {
 	}
 }
 
+func TestCLISense_SendPartial(t *testing.T) {
+	writer := &bytes.Buffer{}
+	cli := NewCLISense(nil, writer)
+
+	for _, chunk := range []string{"Knead", " the", " dough"} {
+		if err := cli.SendPartial(chunk); err != nil {
+			t.Fatalf("SendPartial: %v", err)
+		}
+	}
+	if writer.String() != "Knead the dough" {
+		t.Errorf("writer = %q", writer.String())
+	}
+}
+
 func TestCLISense_SendAfterStop(t *testing.T) {
 	writer := &bytes.Buffer{}
 	cli := NewCLISense(nil, writer)
@@ -0,0 +1,184 @@
+package senses
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Dedup — content-hash deduplication across runs
+// ---------------------------------------------------------------------------
+
+// Dedup defaults.
+const (
+	DefaultDedupTTL        = 7 * 24 * time.Hour
+	DefaultDedupMaxEntries = 10000
+)
+
+// Extra keys set on a duplicate input. The daemon acknowledges such inputs
+// instead of running the pipeline again.
+const (
+	ExtraDuplicateOf     = "duplicate_of"      // InputID of the first occurrence
+	ExtraDuplicateSeenAt = "duplicate_seen_at" // RFC 3339 time of the first occurrence
+)
+
+// DedupEntry records the first input seen with a given content hash.
+type DedupEntry struct {
+	Hash       string    `json:"hash"`
+	InputID    string    `json:"input_id"`
+	Sense      string    `json:"sense"`
+	Source     string    `json:"source,omitempty"` // file path or sender
+	SeenAt     time.Time `json:"seen_at"`
+	Duplicates int       `json:"duplicates,omitempty"` // later inputs with the same hash
+}
+
+// Dedup remembers the content hashes of inputs from the file watcher and
+// email senses, persisted as JSON so a re-saved file or a message forwarded
+// twice is recognised after a restart. Entries expire after the TTL.
+// A nil *Dedup treats every input as new.
+type Dedup struct {
+	mu    sync.Mutex
+	path  string // "" = in-memory only
+	ttl   time.Duration
+	max   int
+	items map[string]DedupEntry
+}
+
+// NewDedup loads the dedup store from path (created on first write). An
+// empty path keeps it in memory only; ttl <= 0 uses DefaultDedupTTL.
+func NewDedup(path string, ttl time.Duration) (*Dedup, error) {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	d := &Dedup{path: path, ttl: ttl, max: DefaultDedupMaxEntries, items: make(map[string]DedupEntry)}
+	if path == "" {
+		return d, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dedup: read: %w", err)
+	}
+	var list []DedupEntry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("dedup: parse %s: %w", path, err)
+	}
+	for _, e := range list {
+		d.items[e.Hash] = e
+	}
+	return d, nil
+}
+
+// Check records input under hash. If the hash was already seen (and has not
+// expired) it marks input with ExtraDuplicateOf / ExtraDuplicateSeenAt and
+// returns the first occurrence.
+func (d *Dedup) Check(hash string, input *UnifiedInput, sense string) (DedupEntry, bool) {
+	if d == nil || hash == "" {
+		return DedupEntry{}, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now().UTC()
+	if e, ok := d.items[hash]; ok && now.Sub(e.SeenAt) < d.ttl {
+		e.Duplicates++
+		d.items[hash] = e
+		_ = d.saveLocked(now)
+		if input.SourceMeta.Extra == nil {
+			input.SourceMeta.Extra = make(map[string]string)
+		}
+		input.SourceMeta.Extra[ExtraDuplicateOf] = e.InputID
+		input.SourceMeta.Extra[ExtraDuplicateSeenAt] = e.SeenAt.Format(time.RFC3339)
+		return e, true
+	}
+
+	source := input.SourceMeta.Path
+	if source == "" {
+		source = input.SourceMeta.Sender
+	}
+	d.items[hash] = DedupEntry{Hash: hash, InputID: input.InputID, Sense: sense, Source: source, SeenAt: now}
+	_ = d.saveLocked(now)
+	return DedupEntry{}, false
+}
+
+// Len returns the number of remembered hashes.
+func (d *Dedup) Len() int {
+	if d == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.items)
+}
+
+// saveLocked drops expired entries (and the oldest beyond the cap) and
+// writes the store atomically. Caller holds d.mu.
+func (d *Dedup) saveLocked(now time.Time) error {
+	list := make([]DedupEntry, 0, len(d.items))
+	for h, e := range d.items {
+		if now.Sub(e.SeenAt) >= d.ttl {
+			delete(d.items, h)
+			continue
+		}
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SeenAt.After(list[j].SeenAt) })
+	if len(list) > d.max {
+		for _, e := range list[d.max:] {
+			delete(d.items, e.Hash)
+		}
+		list = list[:d.max]
+	}
+	if d.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("dedup: encode: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return fmt.Errorf("dedup: mkdir: %w", err)
+	}
+	tmp := d.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("dedup: write: %w", err)
+	}
+	return os.Rename(tmp, d.path)
+}
+
+// ContentHash returns the hex SHA-256 of data.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// emailHash identifies an email by its body (whitespace-normalised, so a
+// re-sent copy with different line wrapping matches) and its attachments.
+// Subject and headers are ignored: forwarding adds "Fwd:" and new headers.
+func emailHash(body string, atts []Attachment) string {
+	var b strings.Builder
+	b.WriteString(strings.Join(strings.Fields(body), " "))
+	names := make([]string, 0, len(atts))
+	for _, a := range atts {
+		names = append(names, fmt.Sprintf("%s|%s|%d", a.Name, a.Type, a.Size))
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		b.WriteString("\x00")
+		b.WriteString(n)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return ContentHash([]byte(b.String()))
+}
@@ -0,0 +1,89 @@
+package senses
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDedup_MarksDuplicateAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.json")
+	d, err := NewDedup(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := NewUnifiedInput(SourceFile, "report")
+	if _, dup := d.Check("h1", first, "FileWatcher"); dup {
+		t.Fatal("first input reported as duplicate")
+	}
+
+	// A restart must remember the hash.
+	d2, err := NewDedup(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := NewUnifiedInput(SourceFile, "report")
+	orig, dup := d2.Check("h1", second, "FileWatcher")
+	if !dup || orig.InputID != first.InputID {
+		t.Fatalf("dup=%v orig=%+v", dup, orig)
+	}
+	if second.SourceMeta.Extra[ExtraDuplicateOf] != first.InputID || second.SourceMeta.Extra[ExtraDuplicateSeenAt] == "" {
+		t.Errorf("extra = %v", second.SourceMeta.Extra)
+	}
+}
+
+func TestDedup_ExpiredEntryIsNew(t *testing.T) {
+	d, _ := NewDedup("", time.Hour)
+	d.items["h"] = DedupEntry{Hash: "h", InputID: "old", SeenAt: time.Now().Add(-2 * time.Hour)}
+	if _, dup := d.Check("h", NewUnifiedInput(SourceEmail, "x"), "Email"); dup {
+		t.Error("expired hash reported as duplicate")
+	}
+}
+
+func TestDedup_NilIsNoop(t *testing.T) {
+	var d *Dedup
+	if _, dup := d.Check("h", NewUnifiedInput(SourceEmail, "x"), "Email"); dup {
+		t.Error("nil dedup reported a duplicate")
+	}
+}
+
+func TestEmailHash_IgnoresWhitespace(t *testing.T) {
+	a := emailHash("Hello\n  world", []Attachment{{Name: "a.pdf", Size: 10}})
+	b := emailHash("Hello world\n", []Attachment{{Name: "a.pdf", Size: 10}})
+	if a != b {
+		t.Error("whitespace changes should not affect the hash")
+	}
+	if a == emailHash("Hello world", nil) {
+		t.Error("attachments should affect the hash")
+	}
+}
+
+func TestFileWatcherSense_DedupResavedFile(t *testing.T) {
+	dir := t.TempDir()
+	d, _ := NewDedup("", 0)
+	out, _ := startFileWatcher(t, FileWatcherConfig{WatchDir: dir, PollInterval: 50 * time.Millisecond, Dedup: d})
+
+	next := func() *UnifiedInput {
+		select {
+		case in := <-out:
+			return in
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for file event")
+			return nil
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("same"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first := next()
+	if first.SourceMeta.Extra[ExtraDuplicateOf] != "" {
+		t.Fatal("first file marked as duplicate")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("same"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := next().SourceMeta.Extra[ExtraDuplicateOf]; got != first.InputID {
+		t.Errorf("duplicate_of = %q, want %q", got, first.InputID)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
 )
 
 // DiscordConfig holds Discord bot configuration.
@@ -18,6 +20,7 @@ type DiscordConfig struct {
 	AppID       string `json:"app_id"`
 	PublicKey   string `json:"public_key"` // For interaction verification
 	ListenAddr  string `json:"listen_addr"` // Webhook endpoint address
+	Limits      Limits `json:"limits,omitempty"` // Request body quota
 }
 
 // discordMaxMessageLen is the Discord message length limit.
@@ -64,7 +67,7 @@ func (s *DiscordSense) Start(ctx context.Context, out chan<- *UnifiedInput) erro
 	mux := http.NewServeMux()
 	mux.HandleFunc("/discord/interactions", s.handleInteraction(out))
 
-	ln, err := net.Listen("tcp", s.config.ListenAddr)
+	ln, err := netaddr.Listen(s.config.ListenAddr)
 	if err != nil {
 		return fmt.Errorf("discord listen: %w", err)
 	}
@@ -89,9 +92,9 @@ func (s *DiscordSense) Start(ctx context.Context, out chan<- *UnifiedInput) erro
 // handleInteraction processes Discord interaction webhooks.
 func (s *DiscordSense) handleInteraction(out chan<- *UnifiedInput) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
+		body, err := s.config.Limits.readBody(w, r, s.Name())
 		if err != nil {
-			http.Error(w, "read error", http.StatusBadRequest)
+			writeLimitError(w, err)
 			return
 		}
 		defer r.Body.Close()
@@ -220,7 +223,7 @@ func (s *DiscordSense) Addr() string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.listener != nil {
-		return s.listener.Addr().String()
+		return netaddr.String(s.listener)
 	}
 	return ""
 }
@@ -0,0 +1,373 @@
+package senses
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Dispatcher — fair scheduling of inputs across senders and channels
+// ---------------------------------------------------------------------------
+
+// Dispatch defaults.
+const (
+	DefaultDispatchWorkers   = 1
+	DefaultDispatchPerSender = 1
+	DefaultDispatchMaxQueued = 500
+	DefaultStarvedAfter      = 2 * time.Minute
+	DefaultStallAfter        = 15 * time.Minute
+)
+
+// DispatchConfig configures the Dispatcher. Zero fields use the defaults.
+type DispatchConfig struct {
+	// Workers is the number of inputs processed at once.
+	Workers int `json:"workers,omitempty"`
+	// PerSender caps the inputs of one sender (or one channel, for
+	// senses without senders) processed at once.
+	PerSender int `json:"per_sender,omitempty"`
+	// MaxQueued caps the inputs waiting across all senders; more are
+	// rejected.
+	MaxQueued int `json:"max_queued,omitempty"`
+	// StarvedAfterSec is how long an input may wait before it counts as
+	// starved.
+	StarvedAfterSec int `json:"starved_after_sec,omitempty"`
+	// StallAfterMin is how long inputs may wait without any being taken
+	// or finished before the dispatcher counts as stalled.
+	StallAfterMin int `json:"stall_after_min,omitempty"`
+
+	// OnDequeue, if set, is called when an input leaves the queue with its
+	// lane, how long it waited and whether that counts as starved (e.g.
+	// to record metrics).
+	OnDequeue func(lane string, wait time.Duration, starved bool) `json:"-"`
+
+	// OnAccept, if set, is called when an input is queued, before any
+	// worker can take it (e.g. to persist it).
+	OnAccept func(input *UnifiedInput) `json:"-"`
+}
+
+// withDefaults fills zero fields with the defaults.
+func (c DispatchConfig) withDefaults() DispatchConfig {
+	if c.Workers <= 0 {
+		c.Workers = DefaultDispatchWorkers
+	}
+	if c.PerSender <= 0 {
+		c.PerSender = DefaultDispatchPerSender
+	}
+	if c.MaxQueued <= 0 {
+		c.MaxQueued = DefaultDispatchMaxQueued
+	}
+	return c
+}
+
+func (c DispatchConfig) starvedAfter() time.Duration {
+	if c.StarvedAfterSec <= 0 {
+		return DefaultStarvedAfter
+	}
+	return time.Duration(c.StarvedAfterSec) * time.Second
+}
+
+func (c DispatchConfig) stallAfter() time.Duration {
+	if c.StallAfterMin <= 0 {
+		return DefaultStallAfter
+	}
+	return time.Duration(c.StallAfterMin) * time.Minute
+}
+
+// DispatchLane is the key inputs are scheduled by: the channel and sender,
+// as "TELEGRAM/12345", or the channel alone when there is no sender.
+func DispatchLane(input *UnifiedInput) string {
+	if input.SourceMeta.Sender == "" {
+		return string(input.SourceType)
+	}
+	return string(input.SourceType) + "/" + input.SourceMeta.Sender
+}
+
+type queuedInput struct {
+	input    *UnifiedInput
+	queuedAt time.Time
+}
+
+type dispatchLane struct {
+	queue    []queuedInput
+	inFlight int
+}
+
+// Dispatcher queues inputs per lane and hands them to workers round-robin
+// across lanes, so a chatty user or a bursty webhook cannot starve everyone
+// else. Each lane is first-in, first-out; a lane at its PerSender cap is
+// skipped until one of its inputs finishes.
+type Dispatcher struct {
+	cfg DispatchConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	lanes    map[string]*dispatchLane
+	order    []string // lanes with queued inputs, in round-robin order
+	next     int      // index in order to start the next pick at
+	queued   int
+	inFlight int
+	closed   bool
+	progress time.Time // last take or finish, or when inputs began waiting
+
+	served  int64
+	starved int64
+	dropped int64
+}
+
+// NewDispatcher creates a Dispatcher.
+func NewDispatcher(cfg DispatchConfig) *Dispatcher {
+	d := &Dispatcher{
+		cfg:   cfg.withDefaults(),
+		now:   time.Now,
+		lanes: make(map[string]*dispatchLane),
+	}
+	d.cond = sync.NewCond(&d.mu)
+	return d
+}
+
+// Push queues input. It returns false when the queue is full, or for a
+// heartbeat while another one is still waiting.
+func (d *Dispatcher) Push(input *UnifiedInput) bool {
+	key := DispatchLane(input)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	l := d.lanes[key]
+	if input.SourceType == SourceTimer && l != nil && len(l.queue) > 0 {
+		return false
+	}
+	if d.queued >= d.cfg.MaxQueued {
+		d.dropped++
+		return false
+	}
+	if l == nil {
+		l = &dispatchLane{}
+		d.lanes[key] = l
+	}
+	if d.queued == 0 {
+		d.progress = d.now()
+	}
+	if len(l.queue) == 0 {
+		d.order = append(d.order, key)
+	}
+	if d.cfg.OnAccept != nil {
+		d.cfg.OnAccept(input)
+	}
+	l.queue = append(l.queue, queuedInput{input: input, queuedAt: d.now()})
+	d.queued++
+	d.cond.Signal()
+	return true
+}
+
+// Queued returns the number of waiting inputs.
+func (d *Dispatcher) Queued() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queued
+}
+
+// Run queues inputs from in and processes them with handle on the
+// configured number of workers until ctx is cancelled. When in is closed,
+// the queued inputs are drained first.
+func (d *Dispatcher) Run(ctx context.Context, in <-chan *UnifiedInput, handle func(*UnifiedInput)) {
+	stop := context.AfterFunc(ctx, func() {
+		d.mu.Lock()
+		d.cond.Broadcast()
+		d.mu.Unlock()
+	})
+	defer stop()
+	d.mu.Lock()
+	d.closed = false // Run again after a restart
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				input, done, ok := d.take(ctx)
+				if !ok {
+					return
+				}
+				handle(input)
+				done()
+			}
+		}()
+	}
+
+pump:
+	for {
+		select {
+		case <-ctx.Done():
+			break pump
+		case input, ok := <-in:
+			if !ok {
+				break pump
+			}
+			d.Push(input)
+		}
+	}
+	d.mu.Lock()
+	d.closed = true
+	d.cond.Broadcast()
+	d.mu.Unlock()
+	wg.Wait()
+}
+
+// take blocks until an input may run and returns it with the func to call
+// when it is done. It returns false once ctx is cancelled, or once the
+// dispatcher is closed and drained.
+func (d *Dispatcher) take(ctx context.Context) (*UnifiedInput, func(), bool) {
+	d.mu.Lock()
+	for {
+		if ctx.Err() != nil {
+			d.mu.Unlock()
+			return nil, nil, false
+		}
+		if key, q, ok := d.pick(); ok {
+			d.progress = d.now()
+			wait := d.progress.Sub(q.queuedAt)
+			starved := wait >= d.cfg.starvedAfter()
+			d.served++
+			if starved {
+				d.starved++
+			}
+			d.mu.Unlock()
+			if d.cfg.OnDequeue != nil {
+				d.cfg.OnDequeue(key, wait, starved)
+			}
+			return q.input, func() { d.finish(key) }, true
+		}
+		if d.closed && d.queued == 0 {
+			d.mu.Unlock()
+			return nil, nil, false
+		}
+		d.cond.Wait()
+	}
+}
+
+// pick dequeues the head of the first lane at or after d.next that is
+// under its in-flight cap. d.mu must be held.
+func (d *Dispatcher) pick() (string, queuedInput, bool) {
+	n := len(d.order)
+	for j := 0; j < n; j++ {
+		i := (d.next + j) % n
+		key := d.order[i]
+		l := d.lanes[key]
+		if l.inFlight >= d.cfg.PerSender {
+			continue
+		}
+		q := l.queue[0]
+		l.queue[0] = queuedInput{}
+		l.queue = l.queue[1:]
+		l.inFlight++
+		d.queued--
+		d.inFlight++
+		if len(l.queue) == 0 {
+			d.order = append(d.order[:i], d.order[i+1:]...)
+			d.next = i
+		} else {
+			d.next = i + 1
+		}
+		return key, q, true
+	}
+	return "", queuedInput{}, false
+}
+
+func (d *Dispatcher) finish(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	l := d.lanes[key]
+	l.inFlight--
+	d.inFlight--
+	d.progress = d.now()
+	if l.inFlight == 0 && len(l.queue) == 0 {
+		delete(d.lanes, key)
+	}
+	d.cond.Broadcast()
+}
+
+// Stalled reports whether inputs have been waiting for StallAfterMin
+// without any being taken or finished (e.g. every worker hangs), with the
+// number waiting.
+func (d *Dispatcher) Stalled() (int, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queued, d.queued > 0 && d.now().Sub(d.progress) >= d.cfg.stallAfter()
+}
+
+// WaitingInput is an input in the dispatcher's queue.
+type WaitingInput struct {
+	Lane    string
+	Input   *UnifiedInput
+	Waiting time.Duration
+}
+
+// Waiting lists the queued inputs, lane by lane in the order the lanes
+// are served.
+func (d *Dispatcher) Waiting() []WaitingInput {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	var out []WaitingInput
+	for j := range d.order {
+		key := d.order[(d.next+j)%len(d.order)]
+		for _, q := range d.lanes[key].queue {
+			out = append(out, WaitingInput{Lane: key, Input: q.input, Waiting: now.Sub(q.queuedAt)})
+		}
+	}
+	return out
+}
+
+// DispatchStats is the dispatcher's state, reported by GET /health.
+type DispatchStats struct {
+	Queued   int         `json:"queued"`
+	InFlight int         `json:"in_flight"`
+	Served   int64       `json:"served"`
+	Starved  int64       `json:"starved"` // served after waiting StarvedAfterSec or longer
+	Dropped  int64       `json:"dropped"` // rejected because the queue was full
+	Lanes    []LaneStats `json:"lanes,omitempty"`
+}
+
+// LaneStats is one lane's share of the queue.
+type LaneStats struct {
+	Lane       string `json:"lane"`
+	Queued     int    `json:"queued"`
+	InFlight   int    `json:"in_flight"`
+	OldestWait string `json:"oldest_wait,omitempty"`
+	Starving   bool   `json:"starving,omitempty"` // the oldest input has waited too long
+}
+
+// Stats returns the queue state, busiest lanes first.
+func (d *Dispatcher) Stats() DispatchStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	st := DispatchStats{
+		Queued:   d.queued,
+		InFlight: d.inFlight,
+		Served:   d.served,
+		Starved:  d.starved,
+		Dropped:  d.dropped,
+	}
+	for key, l := range d.lanes {
+		ls := LaneStats{Lane: key, Queued: len(l.queue), InFlight: l.inFlight}
+		if len(l.queue) > 0 {
+			wait := now.Sub(l.queue[0].queuedAt)
+			ls.OldestWait = wait.Round(time.Second).String()
+			ls.Starving = wait >= d.cfg.starvedAfter()
+		}
+		st.Lanes = append(st.Lanes, ls)
+	}
+	sort.Slice(st.Lanes, func(i, j int) bool {
+		a, b := st.Lanes[i], st.Lanes[j]
+		if a.Queued+a.InFlight != b.Queued+b.InFlight {
+			return a.Queued+a.InFlight > b.Queued+b.InFlight
+		}
+		return a.Lane < b.Lane
+	})
+	return st
+}
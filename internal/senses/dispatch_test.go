@@ -0,0 +1,200 @@
+package senses
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func dispatchInput(st SourceType, sender, payload string) *UnifiedInput {
+	in := NewUnifiedInput(st, payload)
+	in.SourceMeta.Sender = sender
+	return in
+}
+
+func TestDispatcher_RoundRobin(t *testing.T) {
+	d := NewDispatcher(DispatchConfig{})
+	for _, p := range []string{"a1", "a2", "a3"} {
+		d.Push(dispatchInput(SourceTelegram, "alice", p))
+	}
+	d.Push(dispatchInput(SourceTelegram, "bob", "b1"))
+	d.Push(dispatchInput(SourceWebhook, "", "w1"))
+	d.Push(dispatchInput(SourceTelegram, "bob", "b2"))
+
+	var waiting []string
+	for _, w := range d.Waiting() {
+		waiting = append(waiting, w.Lane+":"+w.Input.Payload)
+	}
+	if s := strings.Join(waiting, ","); s != "TELEGRAM/alice:a1,TELEGRAM/alice:a2,TELEGRAM/alice:a3,TELEGRAM/bob:b1,TELEGRAM/bob:b2,WEBHOOK:w1" {
+		t.Errorf("Waiting = %s", s)
+	}
+
+	var got []string
+	for d.Queued() > 0 {
+		in, done, ok := d.take(context.Background())
+		if !ok {
+			t.Fatal("take returned false with inputs queued")
+		}
+		got = append(got, in.Payload)
+		done()
+	}
+	if s := strings.Join(got, ","); s != "a1,b1,w1,a2,b2,a3" {
+		t.Errorf("order = %s, want senders interleaved", s)
+	}
+	if st := d.Stats(); st.Served != 6 || st.Queued != 0 || st.InFlight != 0 || len(st.Lanes) != 0 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestDispatcher_PerSenderCap(t *testing.T) {
+	d := NewDispatcher(DispatchConfig{Workers: 2})
+	d.Push(dispatchInput(SourceAPI, "alice", "a1"))
+	d.Push(dispatchInput(SourceAPI, "alice", "a2"))
+
+	first, done, _ := d.take(context.Background())
+	if first.Payload != "a1" {
+		t.Fatalf("first = %q", first.Payload)
+	}
+	// alice is at her cap: her second input waits even with a free worker.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() {
+		d.mu.Lock()
+		d.cond.Broadcast()
+		d.mu.Unlock()
+	})
+	defer stop()
+	if in, _, ok := d.take(ctx); ok {
+		t.Fatalf("took %q while alice had one in flight", in.Payload)
+	}
+	if st := d.Stats(); st.InFlight != 1 || st.Lanes[0].Lane != "API/alice" || st.Lanes[0].Queued != 1 {
+		t.Errorf("Stats = %+v", st)
+	}
+
+	// Another sender is not held back by her.
+	d.Push(dispatchInput(SourceAPI, "bob", "b1"))
+	if in, bdone, ok := d.take(context.Background()); !ok || in.Payload != "b1" {
+		t.Fatalf("take = %v, %v", in, ok)
+	} else {
+		bdone()
+	}
+	done()
+	if in, _, ok := d.take(context.Background()); !ok || in.Payload != "a2" {
+		t.Fatalf("after a1 finished, take = %v, %v", in, ok)
+	}
+}
+
+func TestDispatcher_Starvation(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var waits []string
+	d := NewDispatcher(DispatchConfig{StarvedAfterSec: 60, OnDequeue: func(lane string, wait time.Duration, starved bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if starved {
+			waits = append(waits, lane+" "+wait.String())
+		}
+	}})
+	d.now = func() time.Time { return now }
+
+	d.Push(dispatchInput(SourceSlack, "carol", "c1"))
+	now = now.Add(90 * time.Second)
+	d.Push(dispatchInput(SourceSlack, "dave", "d1"))
+	st := d.Stats()
+	if len(st.Lanes) != 2 || st.Lanes[0].Lane != "SLACK/carol" || !st.Lanes[0].Starving || st.Lanes[0].OldestWait != "1m30s" || st.Lanes[1].Starving {
+		t.Errorf("Stats lanes = %+v", st.Lanes)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, done, _ := d.take(context.Background())
+		done()
+	}
+	if st := d.Stats(); st.Starved != 1 || len(waits) != 1 || waits[0] != "SLACK/carol 1m30s" {
+		t.Errorf("starved = %d, waits = %v", st.Starved, waits)
+	}
+}
+
+func TestDispatcher_Stalled(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	d := NewDispatcher(DispatchConfig{StallAfterMin: 5})
+	d.now = func() time.Time { return now }
+
+	if _, stalled := d.Stalled(); stalled {
+		t.Error("empty dispatcher is stalled")
+	}
+	d.Push(dispatchInput(SourceAPI, "alice", "a1"))
+	_, done, _ := d.take(context.Background()) // a1 hangs
+	now = now.Add(time.Hour)
+	if _, stalled := d.Stalled(); stalled {
+		t.Error("stalled with nothing waiting")
+	}
+	d.Push(dispatchInput(SourceAPI, "bob", "b1"))
+	d.Push(dispatchInput(SourceAPI, "alice", "a2"))
+	_, done2, _ := d.take(context.Background()) // b1 hangs too; a2 waits for alice's lane
+	now = now.Add(4 * time.Minute)
+	if _, stalled := d.Stalled(); stalled {
+		t.Error("stalled before StallAfterMin")
+	}
+	now = now.Add(time.Minute)
+	if n, stalled := d.Stalled(); !stalled || n != 1 {
+		t.Errorf("Stalled = %d, %v; want 1 waiting, stalled", n, stalled)
+	}
+	done()
+	done2()
+	if _, stalled := d.Stalled(); stalled {
+		t.Error("still stalled after progress")
+	}
+}
+
+func TestDispatcher_PushLimits(t *testing.T) {
+	var accepted []string
+	d := NewDispatcher(DispatchConfig{MaxQueued: 2, OnAccept: func(in *UnifiedInput) { accepted = append(accepted, in.Payload) }})
+	if !d.Push(NewHeartbeat()) {
+		t.Fatal("first heartbeat rejected")
+	}
+	if d.Push(NewHeartbeat()) {
+		t.Error("a second heartbeat should not queue behind the first")
+	}
+	d.Push(dispatchInput(SourceAPI, "", "x"))
+	if d.Push(dispatchInput(SourceAPI, "", "y")) {
+		t.Error("push beyond MaxQueued should fail")
+	}
+	if st := d.Stats(); st.Queued != 2 || st.Dropped != 1 {
+		t.Errorf("Stats = %+v", st)
+	}
+	if len(accepted) != 2 || accepted[1] != "x" {
+		t.Errorf("OnAccept saw %q, want the two queued inputs", accepted)
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	d := NewDispatcher(DispatchConfig{Workers: 3})
+	in := make(chan *UnifiedInput, 10)
+	for _, s := range []string{"alice", "alice", "alice", "bob", "carol"} {
+		in <- dispatchInput(SourceAPI, s, s)
+	}
+	close(in)
+
+	var mu sync.Mutex
+	running := map[string]int{}
+	maxPerSender, total := 0, 0
+	d.Run(context.Background(), in, func(input *UnifiedInput) {
+		mu.Lock()
+		running[input.Payload]++
+		maxPerSender = max(maxPerSender, running[input.Payload])
+		total++
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running[input.Payload]--
+		mu.Unlock()
+	})
+	if total != 5 {
+		t.Errorf("handled %d inputs, want all 5 drained after close", total)
+	}
+	if maxPerSender != 1 {
+		t.Errorf("a sender had %d inputs in flight, cap is 1", maxPerSender)
+	}
+}
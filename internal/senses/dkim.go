@@ -0,0 +1,157 @@
+package senses
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// DKIM signing (RFC 6376, relaxed/relaxed; RSA-SHA256 or Ed25519-SHA256).
+// ---------------------------------------------------------------------------
+
+// DKIMConfig signs outgoing mail for Domain. Publish the public key as a
+// TXT record at <selector>._domainkey.<domain>.
+type DKIMConfig struct {
+	Domain   string `json:"domain,omitempty"`
+	Selector string `json:"selector,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"` // PEM private key, RSA or Ed25519
+}
+
+// dkimHeaders are the headers signed, when present.
+var dkimHeaders = []string{"from", "to", "subject", "date", "message-id", "in-reply-to", "mime-version", "content-type"}
+
+// dkimSigner signs messages with a loaded key.
+type dkimSigner struct {
+	domain, selector string
+	key              crypto.Signer
+	algo             string // "rsa-sha256" or "ed25519-sha256"
+}
+
+// newDKIMSigner loads cfg's key; it returns nil when DKIM is not
+// configured.
+func newDKIMSigner(cfg DKIMConfig) (*dkimSigner, error) {
+	if cfg.Domain == "" && cfg.KeyFile == "" {
+		return nil, nil
+	}
+	if cfg.Domain == "" || cfg.Selector == "" || cfg.KeyFile == "" {
+		return nil, errors.New("dkim needs domain, selector and key_file")
+	}
+	data, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("dkim key: %w", err)
+	}
+	key, algo, err := parseDKIMKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("dkim key %s: %w", cfg.KeyFile, err)
+	}
+	return &dkimSigner{domain: cfg.Domain, selector: cfg.Selector, key: key, algo: algo}, nil
+}
+
+// parseDKIMKey reads a PKCS#1 or PKCS#8 PEM private key.
+func parseDKIMKey(data []byte) (crypto.Signer, string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, "", errors.New("no PEM block")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, "rsa-sha256", nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, "", err
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, "rsa-sha256", nil
+	case ed25519.PrivateKey:
+		return k, "ed25519-sha256", nil
+	}
+	return nil, "", fmt.Errorf("unsupported key type %T", key)
+}
+
+// sign returns the DKIM-Signature header line for a message with the
+// given header block ("Name: value\r\n" lines) and body.
+func (d *dkimSigner) sign(headers, body string, now time.Time) (string, error) {
+	bodyHash := sha256.Sum256([]byte(relaxedBody(body)))
+
+	fields := make(map[string]string)
+	for _, line := range strings.Split(headers, "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			fields[strings.ToLower(strings.TrimSpace(name))] = value
+		}
+	}
+	var signed []string
+	h := sha256.New()
+	for _, name := range dkimHeaders {
+		if value, ok := fields[name]; ok {
+			signed = append(signed, name)
+			h.Write([]byte(relaxedHeader(name, value) + "\r\n"))
+		}
+	}
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		d.algo, d.domain, d.selector, now.Unix(), strings.Join(signed, ":"), base64.StdEncoding.EncodeToString(bodyHash[:]))
+	h.Write([]byte(relaxedHeader("DKIM-Signature", value)))
+
+	opts := crypto.Hash(0) // Ed25519 signs the SHA-256 digest itself (RFC 8463)
+	if d.algo == "rsa-sha256" {
+		opts = crypto.SHA256
+	}
+	sig, err := d.key.Sign(rand.Reader, h.Sum(nil), opts)
+	if err != nil {
+		return "", err
+	}
+	return "DKIM-Signature: " + value + base64.StdEncoding.EncodeToString(sig) + "\r\n", nil
+}
+
+// relaxedHeader canonicalizes a header field (RFC 6376 §3.4.2).
+func relaxedHeader(name, value string) string {
+	value = strings.NewReplacer("\r\n", "", "\n", "").Replace(value)
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.TrimSpace(collapseWSP(value))
+}
+
+// relaxedBody canonicalizes a message body (RFC 6376 §3.4.4).
+func relaxedBody(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(collapseWSP(l), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// collapseWSP reduces runs of spaces and tabs to one space.
+func collapseWSP(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
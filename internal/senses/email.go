@@ -1,10 +1,18 @@
 package senses
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +32,18 @@ type EmailConfig struct {
 	SMTPPass   string `json:"smtp_pass"`
 	FromAddr   string `json:"from_addr"`
 
+	// SMTPSecurity is "starttls" (default), "tls" (default on port 465)
+	// or "none" for a plaintext local relay.
+	SMTPSecurity string `json:"smtp_security,omitempty"`
+	// SMTPAuth is "plain" or "login" (default: what the server offers).
+	SMTPAuth string `json:"smtp_auth,omitempty"`
+	// DKIM signs outgoing mail (optional).
+	DKIM DKIMConfig `json:"dkim,omitempty"`
+
+	// OnDeliveryFailure, if set, is called when a reply is refused by the
+	// SMTP server or bounces back. Bounces are not delivered as inputs.
+	OnDeliveryFailure func(DeliveryFailure) `json:"-"`
+
 	// Polling interval for IMAP.
 	PollInterval time.Duration `json:"poll_interval"`
 
@@ -31,6 +51,22 @@ type EmailConfig struct {
 	AllowedSenders []string `json:"allowed_senders"` // Whitelist (empty = allow all)
 	FolderName     string   `json:"folder_name"`     // IMAP folder to watch (default: INBOX)
 
+	// Limits caps message body size and attachment size/count.
+	Limits Limits `json:"limits,omitempty"`
+
+	// Dedup, if set, marks messages whose body and attachments were
+	// already received (e.g. forwarded twice) with ExtraDuplicateOf.
+	Dedup *Dedup `json:"-"`
+
+	// Location is the agent's timezone, used to render the message Date
+	// header in Extra["date"]. Default: time.Local.
+	Location *time.Location `json:"-"`
+
+	// Paused, if set, is checked before each poll; when it returns true the
+	// poll is skipped (e.g. outside active hours). Mail stays on the server
+	// and is picked up on the first poll after resuming.
+	Paused func() bool `json:"-"`
+
 	// TLS configuration (optional, for testing).
 	TLSConfig *tls.Config `json:"-"`
 
@@ -42,9 +78,19 @@ type EmailConfig struct {
 	SMTPSendFunc func(cfg smtpConfig, msg smtpMessage) error `json:"-"`
 }
 
+// DeliveryFailure is a reply that did not arrive.
+type DeliveryFailure struct {
+	To        string
+	Reason    string
+	Permanent bool // 5xx: sending it again will not help
+	Bounce    bool // reported by a bounce message, not at send time
+}
+
 // EmailSense receives messages via IMAP and sends via SMTP.
 type EmailSense struct {
 	config  EmailConfig
+	dkim    *dkimSigner
+	dkimErr error
 	mu      sync.Mutex
 	stopped bool
 	cancel  context.CancelFunc
@@ -56,6 +102,9 @@ func NewEmailSense(config EmailConfig) *EmailSense {
 	if config.PollInterval == 0 {
 		config.PollInterval = 60 * time.Second
 	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
 	if config.FolderName == "" {
 		config.FolderName = "INBOX"
 	}
@@ -63,10 +112,15 @@ func NewEmailSense(config EmailConfig) *EmailSense {
 	if !config.IMAPTLS && strings.HasSuffix(config.IMAPServer, ":993") {
 		config.IMAPTLS = true
 	}
-	return &EmailSense{
+	s := &EmailSense{
 		config: config,
 		logger: slog.Default(),
 	}
+	s.dkim, s.dkimErr = newDKIMSigner(config.DKIM)
+	if s.dkimErr != nil {
+		s.logger.Warn("email: replies will not be sent", "error", s.dkimErr)
+	}
+	return s
 }
 
 func (s *EmailSense) Name() string { return "Email" }
@@ -94,12 +148,19 @@ func (s *EmailSense) pollIMAP(ctx context.Context, out chan<- *UnifiedInput) err
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
+			if s.config.Paused != nil && s.config.Paused() {
+				continue
+			}
 			emails, err := s.fetchNewEmails(ctx)
 			if err != nil {
 				s.logger.Warn("imap fetch error", "error", err)
 				continue
 			}
 			for _, email := range emails {
+				if f, ok := parseBounce(email); ok {
+					s.deliveryFailed(f)
+					continue
+				}
 				if len(s.config.AllowedSenders) > 0 && !s.isAllowed(email.from) {
 					continue
 				}
@@ -113,6 +174,10 @@ func (s *EmailSense) pollIMAP(ctx context.Context, out chan<- *UnifiedInput) err
 					"to":         email.to,
 				}
 				input.ResponseChannel = email.from
+				if sent, err := mail.ParseDate(email.date); err == nil {
+					input.SourceMeta.Timestamp = sent
+					input.SourceMeta.Extra["date"] = sent.In(s.config.Location).Format(time.RFC1123Z)
+				}
 
 				// Add attachments.
 				for _, att := range email.attachments {
@@ -122,6 +187,8 @@ func (s *EmailSense) pollIMAP(ctx context.Context, out chan<- *UnifiedInput) err
 						Size: att.size,
 					})
 				}
+				s.config.Limits.enforce(input, s.Name())
+				s.config.Dedup.Check(emailHash(input.Payload, input.Attachments), input, s.Name())
 
 				select {
 				case out <- input:
@@ -190,6 +257,7 @@ func (s *EmailSense) fetchNewEmails(_ context.Context) ([]emailMessage, error) {
 			from:      extractEmailAddress(result.From),
 			to:        extractEmailAddress(result.To),
 			subject:   result.Subject,
+			date:      result.Date,
 			body:      result.Body,
 		}
 		messages = append(messages, msg)
@@ -206,28 +274,150 @@ func (s *EmailSense) fetchNewEmails(_ context.Context) ([]emailMessage, error) {
 
 // Send sends an email reply via SMTP.
 func (s *EmailSense) Send(_ context.Context, target string, message string) error {
+	return s.deliver(smtpMessage{
+		To:      target,
+		Subject: "Re: Overhuman",
+		Body:    message,
+	})
+}
+
+// emailMaxAttachmentBytes caps a file attached to a reply; most providers
+// refuse messages over 25 MB, and base64 grows them by a third.
+const emailMaxAttachmentBytes = 15 << 20
+
+// AttachmentCaps reports that replies carry files of up to 15 MB.
+func (s *EmailSense) AttachmentCaps() AttachmentCaps {
+	return AttachmentCaps{Images: true, Files: true, MaxBytes: emailMaxAttachmentBytes}
+}
+
+// SendAttachments sends an email reply with atts as a multipart/mixed
+// message.
+func (s *EmailSense) SendAttachments(_ context.Context, target, message string, atts []Attachment) error {
+	body, contentType, err := mimeMixed(message, atts)
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return s.deliver(smtpMessage{
+		To:          target,
+		Subject:     "Re: Overhuman",
+		Body:        body,
+		ContentType: contentType,
+	})
+}
+
+// deliver sends msg with the configured SMTP settings, reporting failures.
+func (s *EmailSense) deliver(msg smtpMessage) error {
 	if s.config.SMTPServer == "" && s.config.SMTPSendFunc == nil {
 		return nil // No SMTP configured — silent no-op.
 	}
 
+	if s.dkimErr != nil {
+		return s.dkimErr
+	}
+
 	cfg := smtpConfig{
 		Host:     s.config.SMTPServer,
 		User:     s.config.SMTPUser,
 		Password: s.config.SMTPPass,
 		From:     s.config.FromAddr,
+		Security: s.config.SMTPSecurity,
+		Auth:     s.config.SMTPAuth,
+		DKIM:     s.dkim,
 	}
 
-	msg := smtpMessage{
-		To:      target,
-		Subject: "Re: Overhuman",
-		Body:    message,
+	send := sendSMTP
+	if s.config.SMTPSendFunc != nil {
+		send = s.config.SMTPSendFunc
 	}
+	if err := send(cfg, msg); err != nil {
+		s.deliveryFailed(DeliveryFailure{To: msg.To, Reason: err.Error(), Permanent: smtpPermanent(err)})
+		return err
+	}
+	return nil
+}
 
-	if s.config.SMTPSendFunc != nil {
-		return s.config.SMTPSendFunc(cfg, msg)
+// mimeMixed builds a multipart/mixed body: the text, then each file
+// base64-encoded. It returns the body and its Content-Type.
+func mimeMixed(text string, atts []Attachment) (body, contentType string, err error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/plain; charset="UTF-8"`},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	if err != nil {
+		return "", "", err
 	}
+	io.WriteString(part, strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n"))
+	for _, att := range atts {
+		data, err := os.ReadFile(att.Path)
+		if err != nil {
+			return "", "", fmt.Errorf("attachment %s: %w", att.Name, err)
+		}
+		typ := att.Type
+		if typ == "" {
+			typ = "application/octet-stream"
+		}
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(typ, map[string]string{"name": att.Name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return "", "", err
+		}
+		enc := base64.StdEncoding.EncodeToString(data)
+		for len(enc) > 76 {
+			io.WriteString(part, enc[:76]+"\r\n")
+			enc = enc[76:]
+		}
+		io.WriteString(part, enc+"\r\n")
+	}
+	if err := w.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), "multipart/mixed; boundary=" + w.Boundary(), nil
+}
 
-	return sendSMTP(cfg, msg)
+// deliveryFailed logs f and reports it to OnDeliveryFailure.
+func (s *EmailSense) deliveryFailed(f DeliveryFailure) {
+	s.logger.Warn("email delivery failed", "to", f.To, "reason", f.Reason, "permanent", f.Permanent, "bounce", f.Bounce)
+	if s.config.OnDeliveryFailure != nil {
+		s.config.OnDeliveryFailure(f)
+	}
+}
+
+// parseBounce recognizes a bounce (delivery status notification) and
+// extracts the failed recipient and the reason.
+func parseBounce(e emailMessage) (DeliveryFailure, bool) {
+	local, _, _ := strings.Cut(strings.ToLower(e.from), "@")
+	field := func(name string) string {
+		for _, line := range strings.Split(e.body, "\n") {
+			if k, v, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(k), name) {
+				v = strings.TrimSpace(v)
+				if _, addr, ok := strings.Cut(v, ";"); ok { // "rfc822; a@b", "smtp; 550 ..."
+					v = strings.TrimSpace(addr)
+				}
+				return v
+			}
+		}
+		return ""
+	}
+	recipient := field("Final-Recipient")
+	if recipient == "" && local != "mailer-daemon" && local != "postmaster" {
+		return DeliveryFailure{}, false
+	}
+	f := DeliveryFailure{To: recipient, Bounce: true}
+	if f.To == "" {
+		f.To = field("Original-Recipient")
+	}
+	status := field("Status")
+	f.Reason = field("Diagnostic-Code")
+	if f.Reason == "" {
+		f.Reason = strings.TrimSpace(status + " " + e.subject)
+	}
+	f.Permanent = strings.HasPrefix(status, "5") || (status == "" && strings.HasPrefix(f.Reason, "5"))
+	return f, true
 }
 
 // Stop gracefully shuts down the email adapter.
@@ -257,6 +447,7 @@ type emailMessage struct {
 	from        string
 	to          string
 	subject     string
+	date        string // raw Date header
 	body        string
 	attachments []emailAttachment
 }
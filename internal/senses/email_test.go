@@ -1,349 +1,38 @@
 package senses
 
 import (
-	"bufio"
 	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
-	"net"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
 	"strings"
-	"sync"
 	"testing"
 	"time"
-)
-
-// ---------------------------------------------------------------------------
-// Mock IMAP server — speaks minimal IMAP4rev1 for testing.
-// ---------------------------------------------------------------------------
-
-type mockIMAPServer struct {
-	listener net.Listener
-	addr     string
-	messages []mockIMAPMessage
-	mu       sync.Mutex
-	stopped  bool
-}
-
-type mockIMAPMessage struct {
-	SeqNum  int
-	From    string
-	To      string
-	Subject string
-	MsgID   string
-	Body    string
-	Seen    bool
-}
-
-func newMockIMAPServer(messages []mockIMAPMessage) (*mockIMAPServer, error) {
-	ln, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		return nil, err
-	}
-
-	s := &mockIMAPServer{
-		listener: ln,
-		addr:     ln.Addr().String(),
-		messages: messages,
-	}
-
-	go s.serve()
-	return s, nil
-}
-
-func (s *mockIMAPServer) serve() {
-	for {
-		conn, err := s.listener.Accept()
-		if err != nil {
-			return
-		}
-		go s.handleConn(conn)
-	}
-}
-
-func (s *mockIMAPServer) handleConn(conn net.Conn) {
-	defer conn.Close()
-	reader := bufio.NewReader(conn)
-
-	// Send greeting.
-	fmt.Fprintf(conn, "* OK Mock IMAP server ready\r\n")
-
-	selectedFolder := ""
-
-	for {
-		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			return
-		}
-		line = strings.TrimRight(line, "\r\n")
-
-		parts := strings.SplitN(line, " ", 3)
-		if len(parts) < 2 {
-			continue
-		}
-
-		tag := parts[0]
-		cmd := strings.ToUpper(parts[1])
-		var args string
-		if len(parts) > 2 {
-			args = parts[2]
-		}
-
-		switch cmd {
-		case "LOGIN":
-			// Accept any credentials.
-			fmt.Fprintf(conn, "%s OK LOGIN completed\r\n", tag)
-
-		case "SELECT":
-			selectedFolder = strings.Trim(args, "\" ")
-			s.mu.Lock()
-			count := len(s.messages)
-			s.mu.Unlock()
-			fmt.Fprintf(conn, "* %d EXISTS\r\n", count)
-			fmt.Fprintf(conn, "* 0 RECENT\r\n")
-			fmt.Fprintf(conn, "* FLAGS (\\Seen \\Answered \\Flagged \\Deleted \\Draft)\r\n")
-			fmt.Fprintf(conn, "%s OK [READ-WRITE] SELECT completed (%s)\r\n", tag, selectedFolder)
-
-		case "SEARCH":
-			s.mu.Lock()
-			var unseenNums []string
-			for _, m := range s.messages {
-				if !m.Seen {
-					unseenNums = append(unseenNums, fmt.Sprintf("%d", m.SeqNum))
-				}
-			}
-			s.mu.Unlock()
-			if len(unseenNums) > 0 {
-				fmt.Fprintf(conn, "* SEARCH %s\r\n", strings.Join(unseenNums, " "))
-			} else {
-				fmt.Fprintf(conn, "* SEARCH\r\n")
-			}
-			fmt.Fprintf(conn, "%s OK SEARCH completed\r\n", tag)
-
-		case "FETCH":
-			seqStr := ""
-			if len(parts) > 2 {
-				fetchParts := strings.SplitN(args, " ", 2)
-				seqStr = fetchParts[0]
-			}
-			seqNum := 0
-			fmt.Sscanf(seqStr, "%d", &seqNum)
-
-			s.mu.Lock()
-			var msg *mockIMAPMessage
-			for i := range s.messages {
-				if s.messages[i].SeqNum == seqNum {
-					msg = &s.messages[i]
-					break
-				}
-			}
-			s.mu.Unlock()
-
-			if msg != nil {
-				header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMessage-ID: %s\r\nDate: Mon, 01 Jan 2026 00:00:00 +0000\r\n",
-					msg.From, msg.To, msg.Subject, msg.MsgID)
-				body := msg.Body
-
-				fmt.Fprintf(conn, "* %d FETCH (BODY[HEADER] {%d}\r\n%s\r\nBODY[TEXT] {%d}\r\n%s\r\nRFC822.SIZE %d)\r\n",
-					seqNum,
-					len(header), header,
-					len(body), body,
-					len(header)+len(body)+4)
-			}
-			fmt.Fprintf(conn, "%s OK FETCH completed\r\n", tag)
-
-		case "STORE":
-			// Parse seq num and mark as seen.
-			storeArgs := strings.Fields(args)
-			if len(storeArgs) > 0 {
-				seqNum := 0
-				fmt.Sscanf(parts[2], "%d", &seqNum)
-				s.mu.Lock()
-				for i := range s.messages {
-					if s.messages[i].SeqNum == seqNum {
-						s.messages[i].Seen = true
-						break
-					}
-				}
-				s.mu.Unlock()
-			}
-			fmt.Fprintf(conn, "%s OK STORE completed\r\n", tag)
-
-		case "LOGOUT":
-			fmt.Fprintf(conn, "* BYE Mock IMAP server closing\r\n")
-			fmt.Fprintf(conn, "%s OK LOGOUT completed\r\n", tag)
-			return
-
-		default:
-			fmt.Fprintf(conn, "%s BAD Unknown command\r\n", tag)
-		}
-	}
-}
-
-func (s *mockIMAPServer) close() {
-	s.mu.Lock()
-	s.stopped = true
-	s.mu.Unlock()
-	s.listener.Close()
-}
-
-// ---------------------------------------------------------------------------
-// Mock SMTP server — minimal SMTP for testing.
-// ---------------------------------------------------------------------------
-
-type mockSMTPServer struct {
-	listener net.Listener
-	addr     string
-	mu       sync.Mutex
-	received []mockSMTPMessage
-	stopped  bool
-}
-
-type mockSMTPMessage struct {
-	From    string
-	To      []string
-	Data    string
-}
-
-func newMockSMTPServer() (*mockSMTPServer, error) {
-	ln, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		return nil, err
-	}
-
-	s := &mockSMTPServer{
-		listener: ln,
-		addr:     ln.Addr().String(),
-	}
 
-	go s.serve()
-	return s, nil
-}
-
-func (s *mockSMTPServer) serve() {
-	for {
-		conn, err := s.listener.Accept()
-		if err != nil {
-			return
-		}
-		go s.handleConn(conn)
-	}
-}
-
-func (s *mockSMTPServer) handleConn(conn net.Conn) {
-	defer conn.Close()
-	reader := bufio.NewReader(conn)
-
-	// Send greeting.
-	fmt.Fprintf(conn, "220 Mock SMTP server ready\r\n")
-
-	var curFrom string
-	var curTo []string
-	var inData bool
-	var dataBuf strings.Builder
-
-	for {
-		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			return
-		}
-		line = strings.TrimRight(line, "\r\n")
-
-		if inData {
-			if line == "." {
-				inData = false
-				s.mu.Lock()
-				s.received = append(s.received, mockSMTPMessage{
-					From: curFrom,
-					To:   curTo,
-					Data: dataBuf.String(),
-				})
-				s.mu.Unlock()
-				fmt.Fprintf(conn, "250 OK message accepted\r\n")
-				curFrom = ""
-				curTo = nil
-				dataBuf.Reset()
-			} else {
-				dataBuf.WriteString(line)
-				dataBuf.WriteString("\n")
-			}
-			continue
-		}
-
-		cmd := strings.ToUpper(line)
-
-		switch {
-		case strings.HasPrefix(cmd, "EHLO") || strings.HasPrefix(cmd, "HELO"):
-			fmt.Fprintf(conn, "250-Mock SMTP\r\n")
-			fmt.Fprintf(conn, "250 OK\r\n")
-
-		case strings.HasPrefix(cmd, "MAIL FROM:"):
-			curFrom = extractAngleBrackets(line[10:])
-			fmt.Fprintf(conn, "250 OK\r\n")
-
-		case strings.HasPrefix(cmd, "RCPT TO:"):
-			curTo = append(curTo, extractAngleBrackets(line[8:]))
-			fmt.Fprintf(conn, "250 OK\r\n")
-
-		case strings.HasPrefix(cmd, "DATA"):
-			inData = true
-			fmt.Fprintf(conn, "354 Start mail input\r\n")
-
-		case strings.HasPrefix(cmd, "QUIT"):
-			fmt.Fprintf(conn, "221 Bye\r\n")
-			return
-
-		case strings.HasPrefix(cmd, "RSET"):
-			curFrom = ""
-			curTo = nil
-			dataBuf.Reset()
-			fmt.Fprintf(conn, "250 OK\r\n")
-
-		default:
-			fmt.Fprintf(conn, "500 Unknown command\r\n")
-		}
-	}
-}
-
-func (s *mockSMTPServer) close() {
-	s.mu.Lock()
-	s.stopped = true
-	s.mu.Unlock()
-	s.listener.Close()
-}
-
-func (s *mockSMTPServer) getReceived() []mockSMTPMessage {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	result := make([]mockSMTPMessage, len(s.received))
-	copy(result, s.received)
-	return result
-}
-
-func extractAngleBrackets(s string) string {
-	s = strings.TrimSpace(s)
-	if idx := strings.Index(s, "<"); idx >= 0 {
-		end := strings.Index(s, ">")
-		if end > idx {
-			return s[idx+1 : end]
-		}
-	}
-	return s
-}
+	"github.com/overhuman/overhuman/overhumantest"
+)
 
 // ---------------------------------------------------------------------------
 // IMAP client tests
 // ---------------------------------------------------------------------------
 
 func TestIMAPClient_Connect(t *testing.T) {
-	srv, err := newMockIMAPServer(nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
+	srv := overhumantest.NewMockIMAP(t, nil)
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -351,13 +40,9 @@ func TestIMAPClient_Connect(t *testing.T) {
 }
 
 func TestIMAPClient_Login(t *testing.T) {
-	srv, err := newMockIMAPServer(nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
+	srv := overhumantest.NewMockIMAP(t, nil)
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -369,15 +54,11 @@ func TestIMAPClient_Login(t *testing.T) {
 }
 
 func TestIMAPClient_SelectFolder(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{SeqNum: 1, From: "a@test.com", Subject: "Test"},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -392,17 +73,13 @@ func TestIMAPClient_SelectFolder(t *testing.T) {
 }
 
 func TestIMAPClient_SearchUnseen(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{SeqNum: 1, From: "a@test.com", Subject: "Msg1", Seen: false},
 		{SeqNum: 2, From: "b@test.com", Subject: "Msg2", Seen: true},
 		{SeqNum: 3, From: "c@test.com", Subject: "Msg3", Seen: false},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -424,15 +101,11 @@ func TestIMAPClient_SearchUnseen(t *testing.T) {
 }
 
 func TestIMAPClient_SearchUnseen_Empty(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{SeqNum: 1, From: "a@test.com", Subject: "Msg1", Seen: true},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -451,22 +124,18 @@ func TestIMAPClient_SearchUnseen_Empty(t *testing.T) {
 }
 
 func TestIMAPClient_Fetch(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{
-			SeqNum:  1,
-			From:    "Alice <alice@test.com>",
-			To:      "bob@test.com",
-			Subject: "Hello World",
-			MsgID:   "<msg001@test.com>",
-			Body:    "This is the body text.",
+			SeqNum:    1,
+			From:      "Alice <alice@test.com>",
+			To:        "bob@test.com",
+			Subject:   "Hello World",
+			MessageID: "<msg001@test.com>",
+			Body:      "This is the body text.",
 		},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -498,15 +167,11 @@ func TestIMAPClient_Fetch(t *testing.T) {
 }
 
 func TestIMAPClient_MarkSeen(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{SeqNum: 1, From: "a@test.com", Subject: "Test", Seen: false},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -527,13 +192,9 @@ func TestIMAPClient_MarkSeen(t *testing.T) {
 }
 
 func TestIMAPClient_Logout(t *testing.T) {
-	srv, err := newMockIMAPServer(nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
+	srv := overhumantest.NewMockIMAP(t, nil)
 
-	client, err := dialIMAPPlain(srv.addr)
+	client, err := dialIMAPPlain(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -578,14 +239,10 @@ func TestIMAPQuote(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestSMTP_Send(t *testing.T) {
-	srv, err := newMockSMTPServer()
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
+	srv := overhumantest.NewMockSMTP(t)
 
 	// Connect directly using net/smtp stdlib.
-	client, err := smtp.Dial(srv.addr)
+	client, err := smtp.Dial(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -604,7 +261,7 @@ func TestSMTP_Send(t *testing.T) {
 	client.Quit()
 
 	// Verify server received the message.
-	received := srv.getReceived()
+	received := srv.Received()
 	if len(received) != 1 {
 		t.Fatalf("received %d messages, want 1", len(received))
 	}
@@ -623,13 +280,9 @@ func TestSMTP_Send(t *testing.T) {
 }
 
 func TestSMTP_MultipleRecipients(t *testing.T) {
-	srv, err := newMockSMTPServer()
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
+	srv := overhumantest.NewMockSMTP(t)
 
-	client, err := smtp.Dial(srv.addr)
+	client, err := smtp.Dial(srv.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -646,7 +299,7 @@ func TestSMTP_MultipleRecipients(t *testing.T) {
 	}
 	client.Quit()
 
-	received := srv.getReceived()
+	received := srv.Received()
 	if len(received) != 1 {
 		t.Fatalf("received %d messages, want 1", len(received))
 	}
@@ -690,33 +343,29 @@ func TestBuildHeaders(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestEmailSense_FetchNewEmails_Real(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{
-			SeqNum:  1,
-			From:    "Alice <alice@example.com>",
-			To:      "me@example.com",
-			Subject: "Hello",
-			MsgID:   "<msg001@example.com>",
-			Body:    "Hi there!",
-			Seen:    false,
+			SeqNum:    1,
+			From:      "Alice <alice@example.com>",
+			To:        "me@example.com",
+			Subject:   "Hello",
+			MessageID: "<msg001@example.com>",
+			Body:      "Hi there!",
+			Seen:      false,
 		},
 		{
-			SeqNum:  2,
-			From:    "Bob <bob@example.com>",
-			To:      "me@example.com",
-			Subject: "Update",
-			MsgID:   "<msg002@example.com>",
-			Body:    "Status update.",
-			Seen:    false,
+			SeqNum:    2,
+			From:      "Bob <bob@example.com>",
+			To:        "me@example.com",
+			Subject:   "Update",
+			MessageID: "<msg002@example.com>",
+			Body:      "Status update.",
+			Seen:      false,
 		},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
 	sense := NewEmailSense(EmailConfig{
-		IMAPServer: srv.addr,
+		IMAPServer: srv.Addr(),
 		IMAPUser:   "me@example.com",
 		IMAPPass:   "password",
 		FolderName: "INBOX",
@@ -767,16 +416,12 @@ func TestEmailSense_FetchNewEmails_NoServer(t *testing.T) {
 }
 
 func TestEmailSense_FetchNewEmails_NoUnseen(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{SeqNum: 1, From: "a@test.com", Subject: "Old", Seen: true},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
 	sense := NewEmailSense(EmailConfig{
-		IMAPServer: srv.addr,
+		IMAPServer: srv.Addr(),
 		IMAPUser:   "user",
 		IMAPPass:   "pass",
 		DialFunc: func(addr string) (*imapClient, error) {
@@ -794,16 +439,12 @@ func TestEmailSense_FetchNewEmails_NoUnseen(t *testing.T) {
 }
 
 func TestEmailSense_FetchMarksAsSeen(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{SeqNum: 1, From: "a@test.com", Subject: "New", Seen: false},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
 	sense := NewEmailSense(EmailConfig{
-		IMAPServer: srv.addr,
+		IMAPServer: srv.Addr(),
 		IMAPUser:   "user",
 		IMAPPass:   "pass",
 		DialFunc: func(addr string) (*imapClient, error) {
@@ -831,17 +472,13 @@ func TestEmailSense_FetchMarksAsSeen(t *testing.T) {
 }
 
 func TestEmailSense_Send_WithMockSMTP(t *testing.T) {
-	srv, err := newMockSMTPServer()
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
+	srv := overhumantest.NewMockSMTP(t)
 
 	var sentCfg smtpConfig
 	var sentMsg smtpMessage
 
 	sense := NewEmailSense(EmailConfig{
-		SMTPServer: srv.addr,
+		SMTPServer: srv.Addr(),
 		SMTPUser:   "user",
 		SMTPPass:   "pass",
 		FromAddr:   "bot@example.com",
@@ -852,7 +489,7 @@ func TestEmailSense_Send_WithMockSMTP(t *testing.T) {
 		},
 	})
 
-	err = sense.Send(context.Background(), "user@example.com", "Hello from bot")
+	err := sense.Send(context.Background(), "user@example.com", "Hello from bot")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -877,17 +514,13 @@ func TestEmailSense_Send_NoSMTP(t *testing.T) {
 }
 
 func TestEmailSense_AllowedSenders_Filter(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{SeqNum: 1, From: "alice@example.com", Subject: "Allowed", Body: "ok", Seen: false},
 		{SeqNum: 2, From: "eve@evil.com", Subject: "Blocked", Body: "bad", Seen: false},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
 	sense := NewEmailSense(EmailConfig{
-		IMAPServer:     srv.addr,
+		IMAPServer:     srv.Addr(),
 		IMAPUser:       "user",
 		IMAPPass:       "pass",
 		AllowedSenders: []string{"alice@example.com"},
@@ -926,27 +559,24 @@ loop:
 }
 
 func TestEmailSense_PollIMAP_ProducesUnifiedInput(t *testing.T) {
-	srv, err := newMockIMAPServer([]mockIMAPMessage{
+	srv := overhumantest.NewMockIMAP(t, []overhumantest.MailMessage{
 		{
-			SeqNum:  1,
-			From:    "sender@test.com",
-			To:      "me@test.com",
-			Subject: "Important",
-			MsgID:   "<msg123@test.com>",
-			Body:    "Please review.",
-			Seen:    false,
+			SeqNum:    1,
+			From:      "sender@test.com",
+			To:        "me@test.com",
+			Subject:   "Important",
+			MessageID: "<msg123@test.com>",
+			Body:      "Please review.",
+			Seen:      false,
 		},
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer srv.close()
 
 	sense := NewEmailSense(EmailConfig{
-		IMAPServer:   srv.addr,
+		IMAPServer:   srv.Addr(),
 		IMAPUser:     "user",
 		IMAPPass:     "pass",
 		PollInterval: 50 * time.Millisecond,
+		Location:     time.FixedZone("UTC+9", 9*3600),
 		DialFunc: func(addr string) (*imapClient, error) {
 			return dialIMAPPlain(addr)
 		},
@@ -958,31 +588,20 @@ func TestEmailSense_PollIMAP_ProducesUnifiedInput(t *testing.T) {
 
 	go sense.Start(ctx, out)
 
-	select {
-	case input := <-out:
-		if input.SourceType != SourceEmail {
-			t.Errorf("SourceType = %q", input.SourceType)
-		}
-		if input.SourceMeta.Channel != "email" {
-			t.Errorf("Channel = %q", input.SourceMeta.Channel)
-		}
-		if input.SourceMeta.Sender != "sender@test.com" {
-			t.Errorf("Sender = %q", input.SourceMeta.Sender)
-		}
-		if input.SourceMeta.Extra["subject"] != "Important" {
-			t.Errorf("subject = %q", input.SourceMeta.Extra["subject"])
-		}
-		if input.SourceMeta.Extra["message_id"] != "<msg123@test.com>" {
-			t.Errorf("message_id = %q", input.SourceMeta.Extra["message_id"])
-		}
-		if input.ResponseChannel != "sender@test.com" {
-			t.Errorf("ResponseChannel = %q", input.ResponseChannel)
-		}
-		if !strings.Contains(input.Payload, "Please review.") {
-			t.Errorf("Payload = %q", input.Payload)
-		}
-	case <-time.After(500 * time.Millisecond):
-		t.Fatal("timeout waiting for message")
+	input := overhumantest.CollectInputs(t, out, 1, 500*time.Millisecond)[0]
+	overhumantest.AssertInput(t, input, overhumantest.InputWant{
+		SourceType:      string(SourceEmail),
+		Channel:         "email",
+		Sender:          "sender@test.com",
+		PayloadContains: "Please review.",
+		ResponseChannel: "sender@test.com",
+		Extra:           map[string]string{"subject": "Important", "message_id": "<msg123@test.com>"},
+	})
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC); !input.SourceMeta.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want Date header %v", input.SourceMeta.Timestamp, want)
+	}
+	if got := input.SourceMeta.Extra["date"]; !strings.Contains(got, "09:00:00 +0900") {
+		t.Errorf("date = %q, want rendered in agent timezone", got)
 	}
 }
 
@@ -1077,3 +696,217 @@ BODY[TEXT] {0}
 		t.Errorf("Subject = %q", result.Subject)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// SMTP security, DKIM and delivery failures
+// ---------------------------------------------------------------------------
+
+func TestSendSMTP_Security(t *testing.T) {
+	srv := overhumantest.NewMockSMTP(t)
+
+	msg := smtpMessage{To: "recipient@test.com", Subject: "Hi", Body: "Hello."}
+	err := sendSMTP(smtpConfig{Host: srv.Addr(), From: "bot@example.com"}, msg)
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("plaintext server with default security: err = %v, want a STARTTLS error", err)
+	}
+	if len(srv.Received()) != 0 {
+		t.Fatal("message sent without TLS")
+	}
+
+	if err := sendSMTP(smtpConfig{Host: srv.Addr(), From: "bot@example.com", Security: SMTPNone}, msg); err != nil {
+		t.Fatalf("security none: %v", err)
+	}
+	received := srv.Received()
+	if len(received) != 1 || !strings.Contains(received[0].Data, "Message-ID: <") || !strings.Contains(received[0].Data, "@example.com>") {
+		t.Errorf("received = %+v", received)
+	}
+}
+
+func TestLoginAuth(t *testing.T) {
+	a := &loginAuth{user: "bot", password: "secret", host: "smtp.example.com"}
+	if _, _, err := a.Start(&smtp.ServerInfo{Name: "smtp.example.com"}); err == nil {
+		t.Error("LOGIN over plaintext should be refused")
+	}
+	if mech, _, err := a.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true}); mech != "LOGIN" || err != nil {
+		t.Fatalf("Start = %q, %v", mech, err)
+	}
+	for challenge, want := range map[string]string{"Username:": "bot", "Password:": "secret"} {
+		if got, err := a.Next([]byte(challenge), true); string(got) != want || err != nil {
+			t.Errorf("Next(%q) = %q, %v", challenge, got, err)
+		}
+	}
+}
+
+func TestDKIM_Canonicalization(t *testing.T) {
+	// RFC 6376 §3.4.5.
+	if got := relaxedHeader("A", " X") + "\r\n" + relaxedHeader("B ", " Y\t\r\n\tZ  "); got != "a:X\r\nb:Y Z" {
+		t.Errorf("relaxed headers = %q", got)
+	}
+	if got := relaxedBody(" C \r\nD \t E\r\n\r\n\r\n"); got != " C\r\nD E\r\n" {
+		t.Errorf("relaxed body = %q", got)
+	}
+	if got := relaxedBody("\r\n"); got != "" {
+		t.Errorf("empty body = %q", got)
+	}
+}
+
+func TestDKIM_SignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edDER, _ := x509.MarshalPKCS8PrivateKey(edKey)
+
+	for _, tc := range []struct {
+		algo   string
+		pem    *pem.Block
+		verify func(digest, sig []byte) error
+	}{
+		{"rsa-sha256", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, func(digest, sig []byte) error {
+			return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest, sig)
+		}},
+		{"ed25519-sha256", &pem.Block{Type: "PRIVATE KEY", Bytes: edDER}, func(digest, sig []byte) error {
+			if !ed25519.Verify(edPub, digest, sig) {
+				return fmt.Errorf("bad signature")
+			}
+			return nil
+		}},
+	} {
+		keyFile := filepath.Join(t.TempDir(), "dkim.pem")
+		os.WriteFile(keyFile, pem.EncodeToMemory(tc.pem), 0o600)
+		signer, err := newDKIMSigner(DKIMConfig{Domain: "example.com", Selector: "agent", KeyFile: keyFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		headers := buildHeaders("bot@example.com", smtpMessage{To: "a@test.com", Subject: "Hi"})
+		body := "Hello  there\n\n"
+		line, err := signer.sign(headers, body, time.Unix(1700000000, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		value := strings.TrimSuffix(strings.TrimPrefix(line, "DKIM-Signature: "), "\r\n")
+		tags := map[string]string{}
+		for _, tag := range strings.Split(value, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(tag), "=")
+			tags[k] = v
+		}
+		bh := sha256.Sum256([]byte("Hello there\r\n"))
+		if tags["a"] != tc.algo || tags["d"] != "example.com" || tags["s"] != "agent" || tags["t"] != "1700000000" ||
+			tags["h"] != "from:to:subject:date:message-id:mime-version:content-type" || tags["bh"] != base64.StdEncoding.EncodeToString(bh[:]) {
+			t.Fatalf("%s: tags = %v", tc.algo, tags)
+		}
+
+		// Verify the way a receiver does: signed headers, then the
+		// signature header with b= emptied.
+		h := sha256.New()
+		for _, l := range strings.Split(strings.TrimSuffix(headers, "\r\n"), "\r\n") {
+			name, v, _ := strings.Cut(l, ":")
+			h.Write([]byte(relaxedHeader(name, v) + "\r\n"))
+		}
+		h.Write([]byte(relaxedHeader("DKIM-Signature", strings.TrimSuffix(value, tags["b"]))))
+		sig, _ := base64.StdEncoding.DecodeString(tags["b"])
+		if err := tc.verify(h.Sum(nil), sig); err != nil {
+			t.Errorf("%s: %v", tc.algo, err)
+		}
+	}
+
+	if _, err := newDKIMSigner(DKIMConfig{Domain: "example.com"}); err == nil {
+		t.Error("incomplete DKIM config accepted")
+	}
+	if s, err := newDKIMSigner(DKIMConfig{}); s != nil || err != nil {
+		t.Errorf("unconfigured DKIM = %v, %v", s, err)
+	}
+}
+
+func TestParseBounce(t *testing.T) {
+	dsn := emailMessage{
+		from:    "MAILER-DAEMON@mx.example.com",
+		subject: "Undelivered Mail Returned to Sender",
+		body: "This is the mail system.\n\nReporting-MTA: dns; mx.example.com\n" +
+			"Final-Recipient: rfc822; gone@example.org\nAction: failed\nStatus: 5.1.1\n" +
+			"Diagnostic-Code: smtp; 550 5.1.1 User unknown\n",
+	}
+	f, ok := parseBounce(dsn)
+	if !ok || f.To != "gone@example.org" || !f.Permanent || !f.Bounce || f.Reason != "550 5.1.1 User unknown" {
+		t.Errorf("parseBounce = %+v, %v", f, ok)
+	}
+	if _, ok := parseBounce(emailMessage{from: "alice@example.com", body: "Status: fine, thanks"}); ok {
+		t.Error("ordinary mail taken for a bounce")
+	}
+}
+
+func TestEmailSense_DeliveryFailure(t *testing.T) {
+	var failures []DeliveryFailure
+	sense := NewEmailSense(EmailConfig{
+		SMTPSendFunc: func(smtpConfig, smtpMessage) error {
+			return fmt.Errorf("smtp rcpt gone@example.org: %w", &textproto.Error{Code: 550, Msg: "no such user"})
+		},
+		OnDeliveryFailure: func(f DeliveryFailure) { failures = append(failures, f) },
+	})
+	if err := sense.Send(context.Background(), "gone@example.org", "hi"); err == nil {
+		t.Fatal("Send should fail")
+	}
+	if len(failures) != 1 || failures[0].To != "gone@example.org" || !failures[0].Permanent || failures[0].Bounce {
+		t.Errorf("failures = %+v", failures)
+	}
+
+	sense = NewEmailSense(EmailConfig{
+		SMTPServer: "smtp.example.com:587",
+		DKIM:       DKIMConfig{Domain: "example.com", Selector: "agent", KeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+	})
+	if err := sense.Send(context.Background(), "a@example.org", "hi"); err == nil || !strings.Contains(err.Error(), "dkim key") {
+		t.Errorf("Send with an unreadable DKIM key: %v", err)
+	}
+}
+
+func TestEmailSense_SendAttachments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	data := []byte(strings.Repeat("%PDF binary \x00\x01", 20))
+	os.WriteFile(path, data, 0o600)
+
+	var sent smtpMessage
+	sense := NewEmailSense(EmailConfig{
+		SMTPSendFunc: func(_ smtpConfig, msg smtpMessage) error {
+			sent = msg
+			return nil
+		},
+	})
+	atts := []Attachment{{Name: "report.pdf", Type: "application/pdf", Size: int64(len(data)), Path: path}}
+	if err := SendWithAttachments(context.Background(), sense, "a@example.org", "Attached.", atts, nil); err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(sent.ContentType)
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q", sent.ContentType)
+	}
+	if !strings.Contains(buildHeaders("me@example.com", sent), "Content-Type: multipart/mixed; boundary=") {
+		t.Error("headers lack the multipart Content-Type")
+	}
+	r := multipart.NewReader(strings.NewReader(sent.Body), params["boundary"])
+	text, err := r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(text); string(b) != "Attached." {
+		t.Errorf("text part = %q", b)
+	}
+	file, err := r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.FileName() != "report.pdf" || file.Header.Get("Content-Transfer-Encoding") != "base64" {
+		t.Errorf("file part header = %v", file.Header)
+	}
+	enc, _ := io.ReadAll(file)
+	got, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(enc), "\r\n", ""))
+	if err != nil || string(got) != string(data) {
+		t.Errorf("attachment round trip: %v", err)
+	}
+	for _, line := range strings.Split(string(enc), "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("base64 line of %d characters", len(line))
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package senses
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/gif"  // register decoders for ExtractContent
+	_ "image/jpeg" // ...
+	_ "image/png"  // ...
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ---------------------------------------------------------------------------
+// Content extraction — documents dropped into the inbox, made readable.
+// ---------------------------------------------------------------------------
+
+// ExtraExtracted is the Extra key naming how a file's payload was extracted:
+// "pdf", "docx", "csv", "image" or "binary". Files read as text don't have it.
+const ExtraExtracted = "extracted"
+
+// csvPreviewRows is how many data rows a CSV preview shows.
+const csvPreviewRows = 20
+
+// maxExtractedChars caps the text taken from a document.
+const maxExtractedChars = 200_000
+
+// ExtractContent turns a file into text the pipeline can work with, and
+// metadata about it. PDF and DOCX files yield their text, CSV and TSV files
+// a preview of the first rows, and images a description with their
+// dimensions. Other text is returned as is; other binary files are only
+// described. Extraction is best effort: a document whose text can't be
+// read is described like any binary file.
+func ExtractContent(path string, data []byte) (string, map[string]string) {
+	name := filepath.Base(path)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf":
+		if text, pages := pdfText(data); text != "" {
+			return text, map[string]string{ExtraExtracted: "pdf", "content_type": "application/pdf", "pages": strconv.Itoa(pages)}
+		}
+	case ".docx":
+		if text := docxText(data); text != "" {
+			return text, map[string]string{ExtraExtracted: "docx", "content_type": "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+		}
+	case ".csv", ".tsv":
+		if text, meta := csvPreview(name, data); text != "" {
+			return text, meta
+		}
+	}
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		return fmt.Sprintf("Image file %s (%d×%d %s, %s)", name, cfg.Width, cfg.Height, strings.ToUpper(format), formatSize(int64(len(data)))),
+			map[string]string{
+				ExtraExtracted: "image",
+				"content_type": "image/" + format,
+				"width":        strconv.Itoa(cfg.Width),
+				"height":       strconv.Itoa(cfg.Height),
+			}
+	}
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+	return fmt.Sprintf("Binary file %s (%s)", name, formatSize(int64(len(data)))), map[string]string{ExtraExtracted: "binary"}
+}
+
+// csvPreview renders the header and first rows of a CSV or TSV file as a
+// Markdown table.
+func csvPreview(name string, data []byte) (string, map[string]string) {
+	r := csv.NewReader(bytes.NewReader(data))
+	if strings.EqualFold(filepath.Ext(name), ".tsv") {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil || len(records) == 0 {
+		return "", nil
+	}
+	columns := 0
+	for _, rec := range records {
+		columns = max(columns, len(rec))
+	}
+	rows := len(records) - 1
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Table %s: %d rows, %d columns.\n\n", name, rows, columns)
+	row := func(rec []string) {
+		cells := make([]string, columns)
+		for i := range cells {
+			if i < len(rec) {
+				cells[i] = strings.ReplaceAll(strings.TrimSpace(rec[i]), "|", `\|`)
+			}
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	row(records[0])
+	b.WriteString(strings.Repeat("|---", columns) + "|\n")
+	for _, rec := range records[1:min(len(records), csvPreviewRows+1)] {
+		row(rec)
+	}
+	if rows > csvPreviewRows {
+		fmt.Fprintf(&b, "\n(%d more rows not shown)\n", rows-csvPreviewRows)
+	}
+	return b.String(), map[string]string{
+		ExtraExtracted: "csv",
+		"content_type": "text/csv",
+		"rows":         strconv.Itoa(rows),
+		"columns":      strconv.Itoa(columns),
+	}
+}
+
+// docxText returns the paragraphs of a Word document.
+func docxText(data []byte) string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+	f, err := zr.Open("word/document.xml")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	dec := xml.NewDecoder(io.LimitReader(f, 64<<20))
+	inText := false
+	for b.Len() < maxExtractedChars {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteByte('\t')
+			case "br":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+var (
+	pdfStreamRe = regexp.MustCompile(`>>\s*stream\r?\n`)
+	pdfPageRe   = regexp.MustCompile(`/Type\s*/Page\b`)
+)
+
+// pdfText returns the text shown by the content streams of a PDF, and its
+// page count. It handles uncompressed and FlateDecode streams with
+// literal-string text operators, which covers most text PDFs; scanned
+// pages and exotic font encodings yield nothing.
+func pdfText(data []byte) (string, int) {
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		return "", 0
+	}
+	var b strings.Builder
+	for _, m := range pdfStreamRe.FindAllIndex(data, -1) {
+		// The stream's dictionary is what follows its "n 0 obj".
+		dict := data[max(0, bytes.LastIndex(data[:m[0]], []byte("obj"))):m[0]]
+		start := m[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		stream := data[start : start+end]
+		if bytes.Contains(dict, []byte("/Image")) {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			zr, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			stream, _ = io.ReadAll(io.LimitReader(zr, 16<<20))
+			zr.Close()
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue // images and other encodings
+		}
+		pdfShowText(&b, stream)
+		if b.Len() >= maxExtractedChars {
+			break
+		}
+	}
+	return strings.TrimSpace(b.String()), len(pdfPageRe.FindAll(data, -1))
+}
+
+// pdfShowText appends the strings shown by a content stream's text
+// operators (Tj, TJ, ' and ") to b, starting a new line where the stream
+// moves to one.
+func pdfShowText(b *strings.Builder, stream []byte) {
+	var pending []string // strings since the last operator
+	for i := 0; i < len(stream); {
+		c := stream[i]
+		switch {
+		case c == '(':
+			s, n := pdfLiteral(stream[i:])
+			pending = append(pending, s)
+			i += n
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case isPDFOperatorByte(c):
+			j := i
+			for j < len(stream) && isPDFOperatorByte(stream[j]) {
+				j++
+			}
+			switch string(stream[i:j]) {
+			case "Tj", "TJ":
+				b.WriteString(strings.Join(pending, ""))
+			case "'", `"`:
+				b.WriteByte('\n')
+				b.WriteString(strings.Join(pending, ""))
+			case "T*", "Td", "TD", "ET":
+				if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+					b.WriteByte('\n')
+				}
+			}
+			pending = pending[:0]
+			i = j
+		default:
+			i++
+		}
+	}
+}
+
+// isPDFOperatorByte reports whether c can be part of a content stream
+// operator.
+func isPDFOperatorByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '*' || c == '\'' || c == '"'
+}
+
+// pdfLiteral decodes the literal string at the start of s ("(...)") and
+// returns it with the number of bytes it took.
+func pdfLiteral(s []byte) (string, int) {
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return b.String(), i + 1
+			}
+		case '\\':
+			i++
+			if i >= len(s) {
+				return b.String(), i
+			}
+			switch e := s[i]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					v, n := 0, 0
+					for n < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7' {
+						v = v*8 + int(s[i]-'0')
+						i++
+						n++
+					}
+					i--
+					b.WriteRune(rune(v & 0xff)) // PDFDocEncoding ≈ Latin-1
+				} else {
+					b.WriteByte(e)
+				}
+			}
+			continue
+		}
+		if c < 0x80 {
+			b.WriteByte(c)
+		} else {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String(), len(s)
+}
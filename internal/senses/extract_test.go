@@ -0,0 +1,104 @@
+package senses
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// testPDF builds a one-page PDF whose content stream is compressed.
+func testPDF(t *testing.T, content string) []byte {
+	t.Helper()
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write([]byte(content))
+	zw.Close()
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	b.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	b.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	b.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n")
+	fmt.Fprintf(&b, "4 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", z.Len())
+	b.Write(z.Bytes())
+	b.WriteString("\nendstream\nendobj\n%%EOF\n")
+	return b.Bytes()
+}
+
+func TestExtractContent_PDF(t *testing.T) {
+	pdf := testPDF(t, "BT /F1 12 Tf 72 720 Td (Invoice \\(draft\\)) Tj 0 -14 Td [(Total: ) -20 (42 EUR)] TJ ET")
+	text, meta := ExtractContent("/in/invoice.pdf", pdf)
+	if text != "Invoice (draft)\nTotal: 42 EUR" {
+		t.Errorf("text = %q", text)
+	}
+	if meta[ExtraExtracted] != "pdf" || meta["pages"] != "1" {
+		t.Errorf("meta = %v", meta)
+	}
+}
+
+func TestExtractContent_DOCX(t *testing.T) {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	w, _ := zw.Create("word/document.xml")
+	w.Write([]byte(`<?xml version="1.0"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>Meeting notes</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">Ship on </w:t></w:r><w:r><w:t>Friday &amp; celebrate</w:t></w:r></w:p>` +
+		`</w:body></w:document>`))
+	zw.Close()
+
+	text, meta := ExtractContent("notes.docx", b.Bytes())
+	if text != "Meeting notes\nShip on Friday & celebrate" {
+		t.Errorf("text = %q", text)
+	}
+	if meta[ExtraExtracted] != "docx" {
+		t.Errorf("meta = %v", meta)
+	}
+}
+
+func TestExtractContent_CSV(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id\tname\n")
+	for i := range 25 {
+		fmt.Fprintf(&b, "%d\titem|%d\n", i, i)
+	}
+	text, meta := ExtractContent("items.tsv", []byte(b.String()))
+	if meta[ExtraExtracted] != "csv" || meta["rows"] != "25" || meta["columns"] != "2" {
+		t.Errorf("meta = %v", meta)
+	}
+	for _, want := range []string{"| id | name |", `| 19 | item\|19 |`, "(5 more rows not shown)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "| 20 |") {
+		t.Errorf("preview shows too many rows:\n%s", text)
+	}
+}
+
+func TestExtractContent_ImageAndOthers(t *testing.T) {
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 64, 32)))
+	text, meta := ExtractContent("photo.png", img.Bytes())
+	if !strings.HasPrefix(text, "Image file photo.png (64×32 PNG") {
+		t.Errorf("text = %q", text)
+	}
+	if meta[ExtraExtracted] != "image" || meta["width"] != "64" || meta["height"] != "32" || meta["content_type"] != "image/png" {
+		t.Errorf("meta = %v", meta)
+	}
+
+	if text, meta := ExtractContent("todo.txt", []byte("buy milk")); text != "buy milk" || meta != nil {
+		t.Errorf("text file: %q %v", text, meta)
+	}
+	if text, meta := ExtractContent("blob.bin", []byte{0xff, 0xfe, 0x00, 0x01}); meta[ExtraExtracted] != "binary" || !strings.HasPrefix(text, "Binary file blob.bin") {
+		t.Errorf("binary file: %q %v", text, meta)
+	}
+	// A broken document is described rather than passed on as bytes.
+	if _, meta := ExtractContent("broken.pdf", []byte("%PDF-1.4\n\xff\xfe garbage")); meta[ExtraExtracted] != "binary" {
+		t.Errorf("broken pdf: %v", meta)
+	}
+}
@@ -20,6 +20,9 @@ type FileWatcherConfig struct {
 	WatchDir string
 
 	// PollInterval is how often the directory is scanned. Default: 5s.
+	// Where the OS reports directory changes (inotify on Linux), files are
+	// picked up as they are written and PollInterval only bounds how soon
+	// a scan skipped while paused is made up.
 	PollInterval time.Duration
 
 	// Extensions filters which file extensions to watch (e.g. [".txt", ".md"]).
@@ -28,22 +31,58 @@ type FileWatcherConfig struct {
 
 	// Recursive controls whether subdirectories are scanned.
 	Recursive bool
+
+	// Paused, if set, is checked before each scan; when it returns true the
+	// scan is skipped. Files changed meanwhile are emitted on resume.
+	Paused func() bool
+
+	// Limits caps the size of files read from the watch directory; larger
+	// files are skipped and reported instead of being loaded into memory.
+	Limits Limits
+
+	// Dedup, if set, marks files whose content was already emitted (e.g. a
+	// re-saved or copied file) with ExtraDuplicateOf.
+	Dedup *Dedup
+
+	// Extract turns documents into readable payloads (see ExtractContent):
+	// PDF and DOCX text, a CSV preview, image dimensions. Without it the
+	// payload is the file's raw content.
+	Extract bool
+}
+
+// notifyRescan is how often a watcher that gets change notifications
+// still scans, in case one was lost.
+const notifyRescan = time.Minute
+
+// notifyDebounce lets a burst of changes (a copied folder) settle into one
+// scan.
+const notifyDebounce = 100 * time.Millisecond
+
+// dirNotifier signals changes in watched directories.
+type dirNotifier interface {
+	// Watch adds dir (not its subdirectories); watching a directory twice
+	// is a no-op.
+	Watch(dir string) error
+	Events() <-chan struct{}
+	Close() error
 }
 
 // ---------------------------------------------------------------------------
 // FileWatcherSense — polls a directory for new/modified files.
 // ---------------------------------------------------------------------------
 
-// FileWatcherSense implements the Sense interface by polling a directory for
+// FileWatcherSense implements the Sense interface by watching a directory for
 // new or modified files and emitting UnifiedInput messages with file content.
-// It uses Go stdlib only (no fsnotify) — a simple polling approach.
+// It uses Go stdlib only (no fsnotify): inotify where available, polling
+// elsewhere, and a diff of directory scans either way.
 type FileWatcherSense struct {
 	cfg FileWatcherConfig
 
-	mu      sync.Mutex
-	out     chan<- *UnifiedInput
-	cancel  context.CancelFunc
-	stopped bool
+	mu       sync.Mutex
+	out      chan<- *UnifiedInput
+	cancel   context.CancelFunc
+	stopped  bool
+	notifier dirNotifier // nil = polling only
 
 	// known tracks filepath → last modification time.
 	known map[string]time.Time
@@ -64,14 +103,20 @@ func NewFileWatcherSense(cfg FileWatcherConfig) *FileWatcherSense {
 // Name returns the sense name.
 func (fw *FileWatcherSense) Name() string { return "FileWatcher" }
 
-// Start begins polling the configured directory for file changes.
+// Start begins watching the configured directory for file changes.
 // On the first scan it records all existing files without emitting events
 // (to avoid flooding the pipeline). Subsequent scans emit events for new
 // or modified files. Start blocks until ctx is cancelled.
 func (fw *FileWatcherSense) Start(ctx context.Context, out chan<- *UnifiedInput) error {
+	notifier, err := newDirNotifier()
+	if err == nil {
+		defer notifier.Close()
+	}
+
 	fw.mu.Lock()
 	fw.out = out
 	fw.stopped = false
+	fw.notifier = notifier
 	ctx, fw.cancel = context.WithCancel(ctx)
 	fw.mu.Unlock()
 
@@ -80,16 +125,38 @@ func (fw *FileWatcherSense) Start(ctx context.Context, out chan<- *UnifiedInput)
 		return fmt.Errorf("filewatcher: initial scan: %w", err)
 	}
 
+	var events <-chan struct{}
+	if notifier != nil {
+		events = notifier.Events()
+	}
 	ticker := time.NewTicker(fw.cfg.PollInterval)
 	defer ticker.Stop()
 
+	var settle <-chan time.Time
+	lastScan, due := time.Now(), false
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-events:
+			if settle == nil {
+				settle = time.After(notifyDebounce)
+			}
+			continue
+		case <-settle:
+			settle, due = nil, true
 		case <-ticker.C:
-			_ = fw.scan(ctx, false)
+			// Notified watchers only scan on ticks to catch up after a
+			// pause and, now and then, in case a notification was lost.
+			if notifier == nil || time.Since(lastScan) >= notifyRescan {
+				due = true
+			}
+		}
+		if !due || fw.cfg.Paused != nil && fw.cfg.Paused() {
+			continue
 		}
+		_ = fw.scan(ctx, false)
+		lastScan, due = time.Now(), false
 	}
 }
 
@@ -128,6 +195,9 @@ func (fw *FileWatcherSense) scan(ctx context.Context, seed bool) error {
 			if !fw.cfg.Recursive && path != fw.cfg.WatchDir {
 				return filepath.SkipDir
 			}
+			if fw.notifier != nil {
+				_ = fw.notifier.Watch(path)
+			}
 			return nil
 		}
 		if !fw.matchesExtension(path) {
@@ -174,17 +244,24 @@ func (fw *FileWatcherSense) matchesExtension(path string) bool {
 
 // emit reads the file content and sends a UnifiedInput to the output channel.
 func (fw *FileWatcherSense) emit(ctx context.Context, path string, modTime time.Time) {
-	content, err := os.ReadFile(path)
+	info, err := os.Stat(path)
 	if err != nil {
 		return // file may have been deleted between scan and read
 	}
+	if err := fw.cfg.Limits.checkInboxFile(info.Size(), path); err != nil {
+		return
+	}
 
-	info, err := os.Stat(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return
 	}
 
-	input := NewUnifiedInput(SourceFile, string(content))
+	payload, meta := string(content), map[string]string(nil)
+	if fw.cfg.Extract {
+		payload, meta = ExtractContent(path, content)
+	}
+	input := NewUnifiedInput(SourceFile, payload)
 	input.SourceMeta.Channel = "filewatcher"
 	input.SourceMeta.Path = path
 	input.SourceMeta.Timestamp = modTime
@@ -192,6 +269,12 @@ func (fw *FileWatcherSense) emit(ctx context.Context, path string, modTime time.
 		"filename": filepath.Base(path),
 		"size":     fmt.Sprintf("%d", info.Size()),
 	}
+	for k, v := range meta {
+		input.SourceMeta.Extra[k] = v
+	}
+	if len(content) > 0 {
+		fw.cfg.Dedup.Check(ContentHash(content), input, fw.Name())
+	}
 
 	select {
 	case <-ctx.Done():
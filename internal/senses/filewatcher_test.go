@@ -4,6 +4,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -390,3 +392,89 @@ func TestFileWatcherSense_DefaultPollInterval(t *testing.T) {
 		t.Errorf("PollInterval = %v, want %v", fw.cfg.PollInterval, 5*time.Second)
 	}
 }
+
+func TestFileWatcherSense_PausedSkipsScan(t *testing.T) {
+	dir := t.TempDir()
+	var paused atomic.Bool
+	paused.Store(true)
+	cfg := FileWatcherConfig{
+		WatchDir:     dir,
+		PollInterval: 50 * time.Millisecond,
+		Paused:       paused.Load,
+	}
+
+	out, _ := startFileWatcher(t, cfg)
+
+	if err := os.WriteFile(filepath.Join(dir, "night.txt"), []byte("overnight"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-out:
+		t.Fatal("paused watcher should not emit events")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	paused.Store(false)
+	select {
+	case input := <-out:
+		if input.Payload != "overnight" {
+			t.Errorf("Payload = %q, want file content", input.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("file changed while paused was not emitted on resume")
+	}
+}
+
+func TestFileWatcherSense_NotifiedBeforePoll(t *testing.T) {
+	if n, err := newDirNotifier(); err != nil {
+		t.Skipf("no change notifications here: %v", err)
+	} else {
+		n.Close()
+	}
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	fw := NewFileWatcherSense(FileWatcherConfig{WatchDir: dir, PollInterval: time.Hour, Recursive: true})
+	out := make(chan *UnifiedInput, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fw.Start(ctx, out)
+	time.Sleep(50 * time.Millisecond) // let the first scan seed
+
+	// A new directory is watched as it is scanned, and files in it are
+	// seen as well.
+	os.Mkdir(sub, 0o755)
+	time.Sleep(notifyDebounce * 3)
+	if err := os.WriteFile(filepath.Join(sub, "now.txt"), []byte("right away"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case input := <-out:
+		if input.Payload != "right away" {
+			t.Errorf("Payload = %q", input.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("file was not picked up before the next poll")
+	}
+}
+
+func TestFileWatcherSense_Extract(t *testing.T) {
+	dir := t.TempDir()
+	out, _ := startFileWatcher(t, FileWatcherConfig{WatchDir: dir, PollInterval: 50 * time.Millisecond, Extract: true})
+
+	if err := os.WriteFile(filepath.Join(dir, "people.csv"), []byte("name,age\nann,31\nbob,42\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case input := <-out:
+		extra := input.SourceMeta.Extra
+		if extra[ExtraExtracted] != "csv" || extra["rows"] != "2" || extra["filename"] != "people.csv" {
+			t.Errorf("Extra = %v", extra)
+		}
+		if !strings.Contains(input.Payload, "| ann | 31 |") {
+			t.Errorf("Payload = %q", input.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the file")
+	}
+}
@@ -0,0 +1,162 @@
+package senses
+
+import (
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// HeartbeatSchedule — adaptive heartbeat frequency.
+// ---------------------------------------------------------------------------
+
+// Heartbeat interval defaults. The old fixed heartbeat ran every 30 minutes.
+const (
+	DefaultHeartbeatMin = 10 * time.Minute
+	DefaultHeartbeatMax = 2 * time.Hour
+)
+
+// Activity windows and tier intervals used by HeartbeatSchedule.
+const (
+	heartbeatBusyWindow   = 15 * time.Minute // input this recent = busy
+	heartbeatRecentWindow = 2 * time.Hour    // input this recent = recently active
+	heartbeatRecent       = 30 * time.Minute
+	heartbeatIdle         = time.Hour
+	nightStartHour        = 23 // local night, used when no active hours are set
+	nightEndHour          = 7
+)
+
+// HeartbeatPlan is the interval the heartbeat currently runs at and why.
+type HeartbeatPlan struct {
+	Interval time.Duration `json:"-"`
+	Every    string        `json:"every"`
+	Reason   string        `json:"reason"`
+	NextAt   time.Time     `json:"next_at,omitzero"`
+	Min      string        `json:"min"`
+	Max      string        `json:"max"`
+}
+
+// HeartbeatSchedule picks the heartbeat interval from recent activity and
+// pending work: Min while inputs are arriving or work is queued, 30
+// minutes after recent activity, hourly when idle and Max overnight.
+// Tier intervals are clamped to [Min, Max]. Safe for concurrent use.
+type HeartbeatSchedule struct {
+	min, max time.Duration
+	hours    *ActiveHours
+	loc      *time.Location
+
+	mu           sync.Mutex
+	lastActivity time.Time
+	lastBeat     time.Time
+	pending      func() int
+	changed      chan struct{}
+}
+
+// NewHeartbeatSchedule creates a schedule bounded by lo and hi (zero =
+// the defaults). hours, if set, defines the day; otherwise 23:00-07:00 in
+// loc counts as night.
+func NewHeartbeatSchedule(lo, hi time.Duration, hours *ActiveHours, loc *time.Location) *HeartbeatSchedule {
+	if lo <= 0 {
+		lo = DefaultHeartbeatMin
+	}
+	if hi <= 0 {
+		hi = DefaultHeartbeatMax
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &HeartbeatSchedule{
+		min:      lo,
+		max:      max(hi, lo),
+		hours:    hours,
+		loc:      loc,
+		lastBeat: time.Now(),
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// SetPending sets the function reporting queued work (deferred or held
+// inputs, pending goals).
+func (h *HeartbeatSchedule) SetPending(fn func() int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending = fn
+}
+
+// Touch records input activity at t. If that shortens the interval, the
+// Changed channel fires so the timer can be rescheduled.
+func (h *HeartbeatSchedule) Touch(t time.Time) {
+	before := h.Plan(t).Interval
+	h.mu.Lock()
+	if t.After(h.lastActivity) {
+		h.lastActivity = t
+	}
+	h.mu.Unlock()
+	if h.Plan(t).Interval < before {
+		select {
+		case h.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Beat records that a heartbeat was sent at t.
+func (h *HeartbeatSchedule) Beat(t time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastBeat = t
+}
+
+// Changed fires when activity shortens the interval.
+func (h *HeartbeatSchedule) Changed() <-chan struct{} { return h.changed }
+
+// Plan returns the current interval, its reason and when the next beat is
+// due: the last beat plus the interval, never in the past.
+func (h *HeartbeatSchedule) Plan(now time.Time) HeartbeatPlan {
+	h.mu.Lock()
+	last, beat, pendingFn := h.lastActivity, h.lastBeat, h.pending
+	h.mu.Unlock()
+
+	pending := 0
+	if pendingFn != nil {
+		pending = pendingFn()
+	}
+
+	var interval time.Duration
+	var reason string
+	switch {
+	case pending > 0:
+		interval, reason = h.min, "work is queued"
+	case !last.IsZero() && now.Sub(last) < heartbeatBusyWindow:
+		interval, reason = h.min, "active"
+	case !last.IsZero() && now.Sub(last) < heartbeatRecentWindow:
+		interval, reason = heartbeatRecent, "recently active"
+	case h.night(now):
+		interval, reason = h.max, "overnight"
+	default:
+		interval, reason = heartbeatIdle, "idle"
+	}
+	interval = min(max(interval, h.min), h.max)
+
+	next := beat.Add(interval)
+	if next.Before(now) {
+		next = now
+	}
+	return HeartbeatPlan{
+		Interval: interval,
+		Every:    interval.String(),
+		Reason:   reason,
+		NextAt:   next,
+		Min:      h.min.String(),
+		Max:      h.max.String(),
+	}
+}
+
+// night reports whether t falls outside the active window, or in the
+// default night hours when none is configured.
+func (h *HeartbeatSchedule) night(t time.Time) bool {
+	if h.hours != nil {
+		return !h.hours.IsActive(t)
+	}
+	hour := t.In(h.loc).Hour()
+	return hour >= nightStartHour || hour < nightEndHour
+}
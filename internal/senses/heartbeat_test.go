@@ -0,0 +1,64 @@
+package senses
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeartbeatSchedule_Plan(t *testing.T) {
+	noon := at(12, 0)
+	h := NewHeartbeatSchedule(5*time.Minute, 3*time.Hour, nil, time.UTC)
+	h.Beat(noon)
+
+	if p := h.Plan(noon); p.Reason != "idle" || p.Interval != time.Hour || !p.NextAt.Equal(noon.Add(time.Hour)) {
+		t.Errorf("idle plan = %+v", p)
+	}
+	if p := h.Plan(at(2, 0)); p.Reason != "overnight" || p.Interval != 3*time.Hour {
+		t.Errorf("night plan = %+v", p)
+	}
+
+	h.Touch(noon.Add(-time.Minute))
+	if p := h.Plan(noon); p.Reason != "active" || p.Interval != 5*time.Minute {
+		t.Errorf("busy plan = %+v", p)
+	}
+	if p := h.Plan(noon.Add(time.Hour)); p.Reason != "recently active" || p.Interval != 30*time.Minute {
+		t.Errorf("recent plan = %+v", p)
+	}
+
+	h.SetPending(func() int { return 2 })
+	if p := h.Plan(at(2, 0)); p.Reason != "work is queued" || p.Interval != 5*time.Minute {
+		t.Errorf("pending plan = %+v", p)
+	}
+}
+
+func TestHeartbeatSchedule_BoundsAndActiveHours(t *testing.T) {
+	hours, _ := ParseActiveHours("09:00-17:00", time.UTC)
+	h := NewHeartbeatSchedule(45*time.Minute, 50*time.Minute, hours, time.UTC)
+	h.Beat(at(8, 0))
+	if p := h.Plan(at(8, 0)); p.Reason != "overnight" || p.Interval != 50*time.Minute {
+		t.Errorf("outside active hours = %+v, want max", p)
+	}
+	h.Touch(at(12, 0))
+	if p := h.Plan(at(12, 30)); p.Interval != 45*time.Minute {
+		t.Errorf("30m tier = %v, want clamped to min 45m", p.Interval)
+	}
+	if p := h.Plan(at(12, 30)); !p.NextAt.Equal(at(12, 30)) {
+		t.Errorf("overdue beat NextAt = %v, want now", p.NextAt)
+	}
+}
+
+func TestHeartbeatSchedule_TouchSignalsWhenSooner(t *testing.T) {
+	h := NewHeartbeatSchedule(0, 0, nil, time.UTC)
+	h.Touch(at(12, 0))
+	select {
+	case <-h.Changed():
+	default:
+		t.Fatal("first activity did not signal a reschedule")
+	}
+	h.Touch(at(12, 1))
+	select {
+	case <-h.Changed():
+		t.Error("activity that keeps the interval signalled again")
+	default:
+	}
+}
@@ -0,0 +1,187 @@
+package senses
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/webhook"
+)
+
+// InboundWebhook is a named webhook the API sense accepts at
+// POST /webhook/{name}, e.g. {"name": "github", "secret": "enc:v1:...",
+// "template": "Review PR #{{.number}}: {{.pull_request.title}}\n\n{{.pull_request.body}}"}.
+//
+// Requests must be signed with Secret, either GitHub style
+// (X-Hub-Signature-256: sha256=<hex HMAC-SHA256 of the body>) or like the
+// agent's own outbound webhooks (X-Overhuman-Timestamp and
+// X-Overhuman-Signature, see package webhook). Template is a text/template
+// executed on the decoded JSON body to build the task; without one the
+// raw body is the task.
+type InboundWebhook struct {
+	Name     string `json:"name"`
+	Secret   string `json:"secret"`
+	Template string `json:"template,omitempty"`
+	Priority string `json:"priority,omitempty"` // "LOW", "NORMAL", "HIGH", "CRITICAL"
+}
+
+// Extra key naming the webhook an input arrived through.
+const ExtraWebhook = "webhook"
+
+// webhookNameRe is what a webhook name may look like: it is a URL path
+// segment.
+var webhookNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// webhookMaxSkew is how old (or early) an X-Overhuman-Timestamp may be.
+const webhookMaxSkew = 5 * time.Minute
+
+// Validate checks the webhook's name, secret, template and priority.
+func (h InboundWebhook) Validate() error {
+	if !webhookNameRe.MatchString(h.Name) {
+		return fmt.Errorf("webhook name %q must be lowercase letters, digits, '-' or '_'", h.Name)
+	}
+	if h.Secret == "" {
+		return fmt.Errorf("webhook %s: secret required", h.Name)
+	}
+	if _, err := h.parse(); err != nil {
+		return fmt.Errorf("webhook %s: template: %w", h.Name, err)
+	}
+	switch h.Priority {
+	case "", "LOW", "NORMAL", "HIGH", "CRITICAL":
+	default:
+		return fmt.Errorf("webhook %s: priority must be LOW, NORMAL, HIGH or CRITICAL", h.Name)
+	}
+	return nil
+}
+
+func (h InboundWebhook) parse() (*template.Template, error) {
+	if h.Template == "" {
+		return nil, nil
+	}
+	return template.New(h.Name).Option("missingkey=zero").Parse(h.Template)
+}
+
+// inboundHook is a registered webhook with its parsed template.
+type inboundHook struct {
+	InboundWebhook
+	tmpl *template.Template
+}
+
+// verify reports whether r's signature headers sign body with the
+// webhook's secret.
+func (h *inboundHook) verify(r *http.Request, body []byte) bool {
+	if sig := r.Header.Get("X-Hub-Signature-256"); sig != "" {
+		mac := hmac.New(sha256.New, []byte(h.Secret))
+		mac.Write(body)
+		return hmac.Equal([]byte(sig), []byte("sha256="+hex.EncodeToString(mac.Sum(nil))))
+	}
+	sig := r.Header.Get(webhook.HeaderSignature)
+	ts, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	if sig == "" || err != nil {
+		return false
+	}
+	if skew := time.Since(time.Unix(ts, 0)); skew > webhookMaxSkew || skew < -webhookMaxSkew {
+		return false
+	}
+	return webhook.Verify(h.Secret, sig, ts, body)
+}
+
+// render returns the task text for body.
+func (h *inboundHook) render(body []byte) (string, error) {
+	if h.tmpl == nil {
+		return string(body), nil
+	}
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("invalid json")
+	}
+	var b bytes.Buffer
+	if err := h.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	// Missing fields render as "<no value>"; an event without them should
+	// not read as if it had them.
+	return strings.TrimSpace(strings.ReplaceAll(b.String(), "<no value>", "")), nil
+}
+
+// SetWebhooks enables POST /webhook/{name} for hooks. It must be called
+// before Start.
+func (a *APISense) SetWebhooks(hooks []InboundWebhook) error {
+	registered := make(map[string]*inboundHook, len(hooks))
+	for _, h := range hooks {
+		if err := h.Validate(); err != nil {
+			return err
+		}
+		if registered[h.Name] != nil {
+			return fmt.Errorf("webhook %s: duplicate name", h.Name)
+		}
+		tmpl, _ := h.parse()
+		registered[h.Name] = &inboundHook{InboundWebhook: h, tmpl: tmpl}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.webhooks = registered
+	return nil
+}
+
+// handleWebhook handles POST /webhook/{name}: a signed event of a
+// registered webhook becomes a task.
+func (a *APISense) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	hook := a.webhooks[r.PathValue("name")]
+	if hook == nil {
+		http.Error(w, `{"error":"unknown webhook"}`, http.StatusNotFound)
+		return
+	}
+	body, err := a.limits.readBody(w, r, a.Name())
+	if err != nil {
+		writeLimitError(w, err)
+		return
+	}
+	if !hook.verify(r, body) {
+		http.Error(w, `{"error":"invalid signature"}`, http.StatusUnauthorized)
+		return
+	}
+	if a.rejectMaintenance(w) {
+		return
+	}
+	payload, err := hook.render(body)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "template: " + err.Error()})
+		return
+	}
+	if payload == "" {
+		http.Error(w, `{"error":"empty payload"}`, http.StatusBadRequest)
+		return
+	}
+
+	input := NewFromWebhook([]byte(payload), "webhook:"+hook.Name)
+	input.SourceMeta.Extra = map[string]string{ExtraWebhook: hook.Name}
+	switch hook.Priority {
+	case "LOW":
+		input.Priority = PriorityLow
+	case "HIGH":
+		input.Priority = PriorityHigh
+	case "CRITICAL":
+		input.Priority = PriorityCritical
+	}
+
+	select {
+	case a.out <- input:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(apiResponse{InputID: input.InputID, Status: "accepted"})
+	default:
+		http.Error(w, `{"error":"pipeline busy"}`, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,105 @@
+package senses
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/webhook"
+)
+
+func TestInboundWebhook_Validate(t *testing.T) {
+	for _, h := range []InboundWebhook{
+		{Name: "GitHub", Secret: "s"},
+		{Name: "github"},
+		{Name: "github", Secret: "s", Template: "{{.title"},
+		{Name: "github", Secret: "s", Priority: "urgent"},
+	} {
+		if h.Validate() == nil {
+			t.Errorf("%+v: expected an error", h)
+		}
+	}
+	if err := (InboundWebhook{Name: "ci_bot-2", Secret: "s", Template: "{{.title}}"}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAPISense_Webhook(t *testing.T) {
+	sense := NewAPISense("127.0.0.1:0")
+	err := sense.SetWebhooks([]InboundWebhook{
+		{Name: "github", Secret: "gh-secret", Template: "Review PR {{.pull_request.title}}\n\n{{.pull_request.body}}", Priority: "HIGH"},
+		{Name: "raw", Secret: "raw-secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, out, _ := startAPISense(t, sense)
+
+	post := func(name, body string, header map[string]string) int {
+		t.Helper()
+		req, _ := http.NewRequest("POST", "http://"+api.Addr()+"/webhook/"+name, bytes.NewBufferString(body))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	githubSig := func(secret, body string) map[string]string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		return map[string]string{"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+	}
+
+	pr := `{"action":"opened","pull_request":{"title":"Fix login","body":"Closes #12"}}`
+	if code := post("github", pr, githubSig("gh-secret", pr)); code != http.StatusAccepted {
+		t.Fatalf("signed request: status = %d, want 202", code)
+	}
+	select {
+	case input := <-out:
+		if input.Payload != "Review PR Fix login\n\nCloses #12" {
+			t.Errorf("payload = %q", input.Payload)
+		}
+		if input.SourceType != SourceWebhook || input.Priority != PriorityHigh || input.SourceMeta.Extra[ExtraWebhook] != "github" {
+			t.Errorf("input = %+v", input)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out")
+	}
+
+	if code := post("github", pr, githubSig("wrong", pr)); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status = %d, want 401", code)
+	}
+	if code := post("github", pr, nil); code != http.StatusUnauthorized {
+		t.Errorf("unsigned: status = %d, want 401", code)
+	}
+	if code := post("nope", pr, githubSig("gh-secret", pr)); code != http.StatusNotFound {
+		t.Errorf("unknown webhook: status = %d, want 404", code)
+	}
+
+	// The agent's own outbound signature works too, if it is fresh.
+	body := "deploy finished"
+	sign := func(ts int64) map[string]string {
+		return map[string]string{
+			webhook.HeaderTimestamp: strconv.FormatInt(ts, 10),
+			webhook.HeaderSignature: webhook.Sign("raw-secret", ts, []byte(body)),
+		}
+	}
+	if code := post("raw", body, sign(time.Now().Unix())); code != http.StatusAccepted {
+		t.Fatalf("overhuman signature: status = %d, want 202", code)
+	}
+	if input := <-out; input.Payload != body || input.SourceMeta.Sender != "webhook:raw" {
+		t.Errorf("input = %+v", input)
+	}
+	if code := post("raw", body, sign(time.Now().Add(-time.Hour).Unix())); code != http.StatusUnauthorized {
+		t.Errorf("stale timestamp: status = %d, want 401", code)
+	}
+}
@@ -0,0 +1,220 @@
+package senses
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// ---------------------------------------------------------------------------
+// Limits — payload and attachment quotas enforced at the sense layer
+// ---------------------------------------------------------------------------
+
+// Default quotas. They keep a giant webhook body or a multi-gigabyte inbox
+// file from stalling the pipeline or blowing the context window.
+const (
+	DefaultMaxPayloadBytes    int64 = 1 << 20  // 1 MiB
+	DefaultMaxAttachmentBytes int64 = 10 << 20 // 10 MiB
+	DefaultMaxAttachments           = 10
+	DefaultMaxInboxFileBytes  int64 = 5 << 20 // 5 MiB
+)
+
+// Limit names reported in violations.
+const (
+	LimitPayload         = "max_payload_bytes"
+	LimitAttachment      = "max_attachment_bytes"
+	LimitAttachmentCount = "max_attachments"
+	LimitInboxFile       = "max_inbox_file_bytes"
+)
+
+// LimitViolation describes an input rejected (or trimmed) by a quota.
+type LimitViolation struct {
+	Sense  string `json:"sense"`  // "API", "Webhook", "FileWatcher", ...
+	Limit  string `json:"limit"`  // one of the Limit* names
+	Size   int64  `json:"size"`   // observed size or count; -1 if unknown
+	Max    int64  `json:"max"`    // configured limit
+	Source string `json:"source"` // remote address, sender or file path
+}
+
+// Error implements error with a message suitable for the sender.
+func (v LimitViolation) Error() string {
+	what := "payload"
+	switch v.Limit {
+	case LimitAttachment:
+		what = "attachment"
+	case LimitInboxFile:
+		what = "file"
+	case LimitAttachmentCount:
+		return fmt.Sprintf("too many attachments: %d (limit %d)", v.Size, v.Max)
+	}
+	if v.Size < 0 {
+		return fmt.Sprintf("%s too large (limit %s)", what, formatBytes(v.Max))
+	}
+	return fmt.Sprintf("%s too large: %s (limit %s)", what, formatBytes(v.Size), formatBytes(v.Max))
+}
+
+// Limits configures input quotas. Zero fields use the defaults; negative
+// fields disable that limit.
+type Limits struct {
+	MaxPayloadBytes    int64 `json:"max_payload_bytes,omitempty"`
+	MaxAttachmentBytes int64 `json:"max_attachment_bytes,omitempty"`
+	MaxAttachments     int   `json:"max_attachments,omitempty"`
+	MaxInboxFileBytes  int64 `json:"max_inbox_file_bytes,omitempty"`
+
+	// OnViolation, if set, is called for every rejected or trimmed input
+	// (e.g. to write an audit entry).
+	OnViolation func(LimitViolation) `json:"-"`
+}
+
+// withDefaults fills zero fields with the default quotas.
+func (l Limits) withDefaults() Limits {
+	if l.MaxPayloadBytes == 0 {
+		l.MaxPayloadBytes = DefaultMaxPayloadBytes
+	}
+	if l.MaxAttachmentBytes == 0 {
+		l.MaxAttachmentBytes = DefaultMaxAttachmentBytes
+	}
+	if l.MaxAttachments == 0 {
+		l.MaxAttachments = DefaultMaxAttachments
+	}
+	if l.MaxInboxFileBytes == 0 {
+		l.MaxInboxFileBytes = DefaultMaxInboxFileBytes
+	}
+	return l
+}
+
+// report forwards v to OnViolation.
+func (l Limits) report(v LimitViolation) {
+	if l.OnViolation != nil {
+		l.OnViolation(v)
+	}
+}
+
+// readBody reads at most MaxPayloadBytes from r.Body. An oversized body is
+// reported and returned as a LimitViolation.
+func (l Limits) readBody(w http.ResponseWriter, r *http.Request, sense string) ([]byte, error) {
+	l = l.withDefaults()
+	if l.MaxPayloadBytes < 0 {
+		return io.ReadAll(r.Body)
+	}
+	if r.ContentLength > l.MaxPayloadBytes {
+		return nil, l.payloadViolation(sense, r.RemoteAddr, r.ContentLength)
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, l.MaxPayloadBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, l.payloadViolation(sense, r.RemoteAddr, -1)
+	}
+	return body, err
+}
+
+func (l Limits) payloadViolation(sense, source string, size int64) LimitViolation {
+	v := LimitViolation{Sense: sense, Limit: LimitPayload, Size: size, Max: l.MaxPayloadBytes, Source: source}
+	l.report(v)
+	return v
+}
+
+// checkAttachments drops attachments over the size or count quota and
+// returns the ones that were kept. Each drop is reported.
+func (l Limits) checkAttachments(atts []Attachment, sense, source string) (kept []Attachment, dropped []LimitViolation) {
+	l = l.withDefaults()
+	for _, att := range atts {
+		if l.MaxAttachmentBytes > 0 && att.Size > l.MaxAttachmentBytes {
+			dropped = append(dropped, LimitViolation{Sense: sense, Limit: LimitAttachment, Size: att.Size, Max: l.MaxAttachmentBytes, Source: source})
+			continue
+		}
+		kept = append(kept, att)
+	}
+	if l.MaxAttachments > 0 && len(kept) > l.MaxAttachments {
+		dropped = append(dropped, LimitViolation{Sense: sense, Limit: LimitAttachmentCount, Size: int64(len(kept)), Max: int64(l.MaxAttachments), Source: source})
+		kept = kept[:l.MaxAttachments]
+	}
+	for _, v := range dropped {
+		l.report(v)
+	}
+	return kept, dropped
+}
+
+// enforce trims an input from a pull-based sense (email, chat polling) to
+// the quotas: the payload is truncated and oversized or excess attachments
+// are dropped. Each cut is reported and summarised in
+// SourceMeta.Extra["limit_notice"] so the agent can tell the sender.
+func (l Limits) enforce(input *UnifiedInput, sense string) {
+	l = l.withDefaults()
+	source := input.SourceMeta.Sender
+	var notices []string
+	if l.MaxPayloadBytes > 0 && int64(len(input.Payload)) > l.MaxPayloadBytes {
+		v := LimitViolation{Sense: sense, Limit: LimitPayload, Size: int64(len(input.Payload)), Max: l.MaxPayloadBytes, Source: source}
+		l.report(v)
+		input.Payload = truncateUTF8(input.Payload, int(l.MaxPayloadBytes))
+		notices = append(notices, v.Error()+"; truncated")
+	}
+	kept, dropped := l.checkAttachments(input.Attachments, sense, source)
+	input.Attachments = kept
+	for _, v := range dropped {
+		notices = append(notices, v.Error()+"; dropped")
+	}
+	if len(notices) > 0 {
+		if input.SourceMeta.Extra == nil {
+			input.SourceMeta.Extra = make(map[string]string)
+		}
+		input.SourceMeta.Extra["limit_notice"] = strings.Join(notices, "\n")
+	}
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// checkInboxFile reports whether a file of size bytes may be read.
+func (l Limits) checkInboxFile(size int64, path string) error {
+	l = l.withDefaults()
+	if l.MaxInboxFileBytes < 0 || size <= l.MaxInboxFileBytes {
+		return nil
+	}
+	v := LimitViolation{Sense: "FileWatcher", Limit: LimitInboxFile, Size: size, Max: l.MaxInboxFileBytes, Source: path}
+	l.report(v)
+	return v
+}
+
+// writeLimitError answers a rejected request: 413 with a JSON body naming
+// the limit for quota violations, 400 for other read errors.
+func writeLimitError(w http.ResponseWriter, err error) {
+	var v LimitViolation
+	if !errors.As(err, &v) {
+		http.Error(w, `{"error":"read body failed"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":   "payload_too_large",
+		"message": v.Error(),
+		"limit":   v.Limit,
+		"max":     v.Max,
+	})
+}
+
+// formatBytes renders n as a short human-readable size.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,169 @@
+package senses
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAPISense_PayloadTooLarge(t *testing.T) {
+	var mu sync.Mutex
+	var violations []LimitViolation
+	api := NewAPISense("127.0.0.1:0")
+	api.SetLimits(Limits{
+		MaxPayloadBytes: 64,
+		OnViolation: func(v LimitViolation) {
+			mu.Lock()
+			violations = append(violations, v)
+			mu.Unlock()
+		},
+	})
+	api, out, _ := startAPISense(t, api)
+
+	body := `{"payload":"` + strings.Repeat("x", 200) + `"}`
+	resp, err := http.Post("http://"+api.Addr()+"/input", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /input: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", resp.StatusCode)
+	}
+	var got map[string]any
+	json.NewDecoder(resp.Body).Decode(&got)
+	if got["limit"] != LimitPayload {
+		t.Errorf("limit = %v, want %s", got["limit"], LimitPayload)
+	}
+	if msg, _ := got["message"].(string); !strings.Contains(msg, "payload too large") {
+		t.Errorf("message = %q", msg)
+	}
+
+	mu.Lock()
+	n := len(violations)
+	mu.Unlock()
+	if n != 1 {
+		t.Errorf("violations = %d, want 1", n)
+	}
+	select {
+	case in := <-out:
+		t.Errorf("oversized input reached the pipeline: %+v", in)
+	default:
+	}
+
+	// A small payload still goes through.
+	resp2, err := http.Post("http://"+api.Addr()+"/input", "application/json", strings.NewReader(`{"payload":"hi"}`))
+	if err != nil {
+		t.Fatalf("POST /input: %v", err)
+	}
+	resp2.Body.Close()
+	if resp2.StatusCode != http.StatusAccepted {
+		t.Errorf("small payload status = %d, want 202", resp2.StatusCode)
+	}
+}
+
+func TestLimits_Enforce(t *testing.T) {
+	var reported []string
+	l := Limits{
+		MaxPayloadBytes:    10,
+		MaxAttachmentBytes: 100,
+		MaxAttachments:     1,
+		OnViolation:        func(v LimitViolation) { reported = append(reported, v.Limit) },
+	}
+	input := NewUnifiedInput(SourceEmail, "héllo wörld, this is long")
+	input.Attachments = []Attachment{
+		{Name: "huge.iso", Size: 1 << 30},
+		{Name: "a.txt", Size: 10},
+		{Name: "b.txt", Size: 20},
+	}
+
+	l.enforce(input, "Email")
+
+	if len(input.Payload) > 10 || !strings.HasPrefix("héllo wörld", input.Payload) {
+		t.Errorf("Payload = %q, want a UTF-8 safe prefix of at most 10 bytes", input.Payload)
+	}
+	if len(input.Attachments) != 1 || input.Attachments[0].Name != "a.txt" {
+		t.Errorf("Attachments = %+v, want only a.txt", input.Attachments)
+	}
+	want := []string{LimitPayload, LimitAttachment, LimitAttachmentCount}
+	if strings.Join(reported, ",") != strings.Join(want, ",") {
+		t.Errorf("reported = %v, want %v", reported, want)
+	}
+	notice := input.SourceMeta.Extra["limit_notice"]
+	if !strings.Contains(notice, "attachment too large: 1.0 GiB") || !strings.Contains(notice, "too many attachments") {
+		t.Errorf("limit_notice = %q", notice)
+	}
+}
+
+func TestLimits_DefaultsAndDisabled(t *testing.T) {
+	var l Limits
+	if err := l.checkInboxFile(DefaultMaxInboxFileBytes, "f"); err != nil {
+		t.Errorf("file at the default limit rejected: %v", err)
+	}
+	if err := l.checkInboxFile(DefaultMaxInboxFileBytes+1, "f"); err == nil {
+		t.Error("file over the default limit accepted")
+	}
+	l.MaxInboxFileBytes = -1
+	if err := l.checkInboxFile(1<<40, "f"); err != nil {
+		t.Errorf("disabled limit rejected file: %v", err)
+	}
+}
+
+func TestFileWatcherSense_SkipsOversizedFile(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	var violations []LimitViolation
+	cfg := FileWatcherConfig{
+		WatchDir:     dir,
+		PollInterval: 50 * time.Millisecond,
+		Limits: Limits{
+			MaxInboxFileBytes: 16,
+			OnViolation: func(v LimitViolation) {
+				mu.Lock()
+				violations = append(violations, v)
+				mu.Unlock()
+			},
+		},
+	}
+	out, _ := startFileWatcher(t, cfg)
+
+	big := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(big, []byte(strings.Repeat("x", 100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	small := filepath.Join(dir, "small.txt")
+	if err := os.WriteFile(small, []byte("ok"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case input := <-out:
+		if input.SourceMeta.Path != small {
+			t.Errorf("Path = %q, want %q", input.SourceMeta.Path, small)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for small file")
+	}
+
+	// big.txt may be visited after small.txt within the same scan.
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(violations)
+		mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(violations) != 1 || violations[0].Source != big || violations[0].Limit != LimitInboxFile {
+		t.Errorf("violations = %+v, want one for %s", violations, big)
+	}
+}
@@ -0,0 +1,166 @@
+package senses
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+// ---------------------------------------------------------------------------
+// Daemon modes — read-only and maintenance switches
+// ---------------------------------------------------------------------------
+
+// DaemonMode is the operating mode of the daemon.
+type DaemonMode string
+
+const (
+	// ModeNormal processes inputs and runs skills as usual.
+	ModeNormal DaemonMode = "normal"
+	// ModeReadOnly answers questions but blocks side-effecting skills,
+	// subagents and tools.
+	ModeReadOnly DaemonMode = "read_only"
+	// ModeMaintenance rejects new inputs with a friendly message while
+	// migrations or backups run.
+	ModeMaintenance DaemonMode = "maintenance"
+)
+
+// DefaultMaintenanceMessage is returned to senders while in maintenance.
+const DefaultMaintenanceMessage = "I'm down for maintenance right now. Please try again in a few minutes."
+
+// ParseDaemonMode converts a string ("read-only", "READ_ONLY", ...) into a
+// DaemonMode.
+func ParseDaemonMode(s string) (DaemonMode, error) {
+	m := DaemonMode(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_"))
+	switch m {
+	case ModeNormal, ModeReadOnly, ModeMaintenance:
+		return m, nil
+	case "readonly":
+		return ModeReadOnly, nil
+	}
+	return "", fmt.Errorf("unknown mode %q (normal, read_only, maintenance)", s)
+}
+
+// ModeStatus is the JSON view of the current mode.
+type ModeStatus struct {
+	Mode    DaemonMode `json:"mode"`
+	Message string     `json:"message,omitempty"`
+	Since   time.Time  `json:"since"`
+
+	// Degraded describes a degraded service (e.g. answering with a local
+	// fallback model) in any mode; empty when everything works.
+	Degraded string `json:"degraded,omitempty"`
+}
+
+// ModeSwitch holds the daemon mode. A nil *ModeSwitch is always ModeNormal.
+type ModeSwitch struct {
+	mu       sync.RWMutex
+	mode     DaemonMode
+	message  string
+	since    time.Time
+	degraded string
+}
+
+// NewModeSwitch creates a switch in ModeNormal.
+func NewModeSwitch() *ModeSwitch {
+	return &ModeSwitch{mode: ModeNormal, since: time.Now()}
+}
+
+// Set changes the mode. message is shown to senders in maintenance mode;
+// empty uses DefaultMaintenanceMessage.
+func (m *ModeSwitch) Set(mode DaemonMode, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mode != m.mode {
+		m.since = time.Now()
+	}
+	m.mode = mode
+	m.message = strings.TrimSpace(message)
+	if m.message == "" && mode == ModeMaintenance {
+		m.message = DefaultMaintenanceMessage
+	}
+}
+
+// Status returns the current mode, message and when it was entered.
+func (m *ModeSwitch) Status() ModeStatus {
+	if m == nil {
+		return ModeStatus{Mode: ModeNormal}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return ModeStatus{Mode: m.mode, Message: m.message, Since: m.since, Degraded: m.degraded}
+}
+
+// SetDegraded records that the daemon works in a degraded way, described
+// by msg; an empty msg clears it. The mode itself does not change.
+func (m *ModeSwitch) SetDegraded(msg string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.degraded = msg
+}
+
+// Mode returns the current mode.
+func (m *ModeSwitch) Mode() DaemonMode { return m.Status().Mode }
+
+// ReadOnly reports whether side effects must be blocked. Maintenance mode
+// is read-only as well, so runs already in flight stop touching the world.
+func (m *ModeSwitch) ReadOnly() bool {
+	mode := m.Mode()
+	return mode == ModeReadOnly || mode == ModeMaintenance
+}
+
+// Maintenance reports whether new inputs must be rejected.
+func (m *ModeSwitch) Maintenance() bool { return m.Mode() == ModeMaintenance }
+
+// MaintenanceMessage returns the message shown to rejected senders.
+func (m *ModeSwitch) MaintenanceMessage() string {
+	if msg := m.Status().Message; msg != "" {
+		return msg
+	}
+	return DefaultMaintenanceMessage
+}
+
+// ServeHTTP serves the current mode as JSON (GET /mode, GET /api/mode).
+func (m *ModeSwitch) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m.Status())
+}
+
+// modeRequest is the JSON body for POST /mode.
+type modeRequest struct {
+	Mode    string `json:"mode"`
+	Message string `json:"message,omitempty"`
+}
+
+// AdminHandler returns a handler for POST /mode that switches the mode.
+// Requests must carry "Authorization: Bearer <token>"; with an empty token
+// only loopback clients are accepted.
+func (m *ModeSwitch) AdminHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !security.AuthorizeAdmin(r, token) {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		var req modeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"invalid json"}`, http.StatusBadRequest)
+			return
+		}
+		mode, err := ParseDaemonMode(req.Mode)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		m.Set(mode, req.Message)
+		m.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,111 @@
+package senses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/overhuman/overhuman/internal/security"
+)
+
+func TestParseDaemonMode(t *testing.T) {
+	for in, want := range map[string]DaemonMode{
+		"normal":       ModeNormal,
+		"READ_ONLY":    ModeReadOnly,
+		"read-only":    ModeReadOnly,
+		"readonly":     ModeReadOnly,
+		" maintenance": ModeMaintenance,
+	} {
+		got, err := ParseDaemonMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDaemonMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseDaemonMode("party"); err == nil {
+		t.Error("unknown mode should fail")
+	}
+}
+
+func TestModeSwitch(t *testing.T) {
+	var nilSwitch *ModeSwitch
+	if nilSwitch.Mode() != ModeNormal || nilSwitch.ReadOnly() || nilSwitch.Maintenance() {
+		t.Error("nil ModeSwitch should be normal")
+	}
+
+	m := NewModeSwitch()
+	m.Set(ModeReadOnly, "")
+	if !m.ReadOnly() || m.Maintenance() {
+		t.Error("read_only: ReadOnly should be true, Maintenance false")
+	}
+
+	m.Set(ModeMaintenance, "")
+	if !m.ReadOnly() || !m.Maintenance() {
+		t.Error("maintenance implies read-only")
+	}
+	if m.MaintenanceMessage() != DefaultMaintenanceMessage {
+		t.Errorf("message = %q, want default", m.MaintenanceMessage())
+	}
+
+	m.SetDegraded("answering with a local model")
+	if st := m.Status(); st.Mode != ModeMaintenance || st.Degraded != "answering with a local model" {
+		t.Errorf("degraded status = %+v", st)
+	}
+	m.SetDegraded("")
+	nilSwitch.SetDegraded("ignored")
+	if m.Status().Degraded != "" || nilSwitch.Status().Degraded != "" {
+		t.Error("degraded not cleared")
+	}
+}
+
+func TestModeSwitch_AdminHandlerLoopbackWithoutToken(t *testing.T) {
+	m := NewModeSwitch()
+	h := m.AdminHandler("")
+
+	req := httptest.NewRequest("POST", "/mode", strings.NewReader(`{"mode":"read_only"}`))
+	req.RemoteAddr = "203.0.113.7:5555"
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized || m.ReadOnly() {
+		t.Errorf("remote client without token: %d, mode %s", rr.Code, m.Mode())
+	}
+
+	req = httptest.NewRequest("POST", "/mode", strings.NewReader(`{"mode":"read_only"}`))
+	req.RemoteAddr = "127.0.0.1:5555"
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !m.ReadOnly() {
+		t.Errorf("loopback client: %d, mode %s", rr.Code, m.Mode())
+	}
+
+	req = httptest.NewRequest("POST", "/mode", strings.NewReader(`{"mode":"bogus"}`))
+	req.RemoteAddr = "127.0.0.1:5555"
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("bogus mode status = %d, want 400", rr.Code)
+	}
+}
+
+func TestModeSwitch_AdminHandlerTeamRole(t *testing.T) {
+	m := NewModeSwitch()
+	h := m.AdminHandler("")
+
+	req := httptest.NewRequest("POST", "/mode", strings.NewReader(`{"mode":"read_only"}`))
+	req.RemoteAddr = "127.0.0.1:5555"
+	req = req.WithContext(security.WithPrincipal(req.Context(), security.Principal{Name: "ben", Role: security.RoleMember}))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized || m.ReadOnly() {
+		t.Errorf("member via loopback: %d, mode %s", rr.Code, m.Mode())
+	}
+
+	req = httptest.NewRequest("POST", "/mode", strings.NewReader(`{"mode":"read_only"}`))
+	req.RemoteAddr = "203.0.113.7:5555"
+	req = req.WithContext(security.WithPrincipal(req.Context(), security.Principal{Name: "anna", Role: security.RoleAdmin}))
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !m.ReadOnly() {
+		t.Errorf("team admin: %d, mode %s", rr.Code, m.Mode())
+	}
+}
@@ -0,0 +1,99 @@
+//go:build linux
+
+package senses
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// inotifyMask is what the watcher wakes up for: files finished writing or
+// moved in, new directories (to watch them too), and watches going away.
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE | syscall.IN_ONLYDIR
+
+// inotifyNotifier implements dirNotifier with Linux inotify.
+type inotifyNotifier struct {
+	fd     int // used as is: os.File.Fd would make reads blocking
+	f      *os.File
+	events chan struct{}
+
+	mu      sync.Mutex
+	watched map[string]int32 // dir → watch descriptor
+}
+
+// newDirNotifier starts an inotify instance. Its descriptor is non-blocking
+// and read through the runtime poller, so Close ends the reader.
+func newDirNotifier() (dirNotifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	n := &inotifyNotifier{
+		fd:      fd,
+		f:       os.NewFile(uintptr(fd), "inotify"),
+		events:  make(chan struct{}, 1),
+		watched: make(map[string]int32),
+	}
+	go n.read()
+	return n, nil
+}
+
+// Watch adds dir, unless it is watched already.
+func (n *inotifyNotifier) Watch(dir string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.watched[dir]; ok {
+		return nil
+	}
+	wd, err := syscall.InotifyAddWatch(n.fd, dir, inotifyMask)
+	if err != nil {
+		return fmt.Errorf("inotify: watch %s: %w", dir, err)
+	}
+	n.watched[dir] = int32(wd)
+	return nil
+}
+
+// Events signals that something changed in a watched directory.
+func (n *inotifyNotifier) Events() <-chan struct{} { return n.events }
+
+// Close stops watching.
+func (n *inotifyNotifier) Close() error { return n.f.Close() }
+
+// read turns inotify events into signals until the notifier is closed.
+// Watches of removed directories are forgotten, so a directory created
+// again under the same name is watched again.
+func (n *inotifyNotifier) read() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		size, err := n.f.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= size; {
+			wd := int32(binary.NativeEndian.Uint32(buf[off:]))
+			mask := binary.NativeEndian.Uint32(buf[off+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			off += syscall.SizeofInotifyEvent + nameLen
+			if mask&syscall.IN_IGNORED != 0 {
+				n.forget(wd)
+			}
+		}
+		select {
+		case n.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *inotifyNotifier) forget(wd int32) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for dir, w := range n.watched {
+		if w == wd {
+			delete(n.watched, dir)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package senses
+
+import "errors"
+
+// newDirNotifier reports that directory notifications are not supported
+// here; the file watcher polls instead.
+func newDirNotifier() (dirNotifier, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,365 @@
+package senses
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// OpenAI-compatible chat completions — POST /v1/chat/completions, so chat
+// UIs, editors and LangChain can use the agent as their model.
+// ---------------------------------------------------------------------------
+
+// OpenAIModel is the model name the agent lists under GET /v1/models.
+// Requests may name any model; they all run through the pipeline.
+const OpenAIModel = "overhuman"
+
+// openAIKeepAlive is how often a streamed response sends an SSE comment
+// while the pipeline runs, so proxies do not close the connection.
+const openAIKeepAlive = 15 * time.Second
+
+// APIResult answers a synchronous API request.
+type APIResult struct {
+	TaskID   string
+	Text     string
+	Model    string // model the execution stage ran on
+	CostUSD  float64
+	Declined bool // the provider refused to answer; Text says so
+}
+
+// chatWait is a pending chat completion request: its result and, when it
+// streams, the text generated so far that is still to be sent.
+type chatWait struct {
+	result chan APIResult
+	stream bool
+	notify chan struct{} // signalled when text is pending
+
+	mu      sync.Mutex
+	pending strings.Builder
+	ended   bool // the first streamed completion ended
+}
+
+// add queues chunk, up to the end of the first streamed completion: a
+// later one (a retry) would repeat the text.
+func (c *chatWait) add(chunk string, done bool) {
+	c.mu.Lock()
+	if c.ended {
+		c.mu.Unlock()
+		return
+	}
+	c.pending.WriteString(chunk)
+	c.ended = done
+	c.mu.Unlock()
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take returns and clears the pending text.
+func (c *chatWait) take() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	text := c.pending.String()
+	c.pending.Reset()
+	return text
+}
+
+// chatRequest is the subset of the chat completions request the agent
+// understands. Sampling parameters are left to the model router.
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+	User     string        `json:"user,omitempty"`
+}
+
+// chatMessage is a chat message; Content is a string or a list of parts,
+// of which the text parts are read.
+type chatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// text returns the message's text content.
+func (m chatMessage) text() string {
+	var s string
+	if json.Unmarshal(m.Content, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(m.Content, &parts) != nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// chatPayload turns the messages into the task payload: the last message,
+// which must come from the user, preceded by the earlier turns. Clients
+// send the whole conversation with every request, so the agent keeps no
+// history of its own for them.
+func chatPayload(messages []chatMessage) (string, error) {
+	if len(messages) == 0 {
+		return "", fmt.Errorf("messages required")
+	}
+	last := messages[len(messages)-1]
+	text := strings.TrimSpace(last.text())
+	if last.Role != "user" || text == "" {
+		return "", fmt.Errorf("the last message must be a non-empty user message")
+	}
+	if len(messages) == 1 {
+		return text, nil
+	}
+	var b strings.Builder
+	b.WriteString("Conversation so far:\n")
+	for _, m := range messages[:len(messages)-1] {
+		if t := strings.TrimSpace(m.text()); t != "" {
+			fmt.Fprintf(&b, "%s: %s\n", m.Role, t)
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(text)
+	return b.String(), nil
+}
+
+// handleChatCompletions handles POST /v1/chat/completions: the request
+// runs through the full pipeline, as POST /input/sync, and its result is
+// returned as a chat completion — streamed as server-sent events when the
+// request asks for it. A stream carries the answer as the execution stage
+// generates it (see SendPartial), then whatever the final result adds.
+func (a *APISense) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if a.mode.Maintenance() {
+		w.Header().Set("Retry-After", "300")
+		writeOpenAIError(w, http.StatusServiceUnavailable, "maintenance", a.mode.MaintenanceMessage())
+		return
+	}
+	body, err := a.limits.readBody(w, r, a.Name())
+	if err != nil {
+		writeLimitError(w, err)
+		return
+	}
+	var req chatRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "invalid json")
+		return
+	}
+	payload, err := chatPayload(req.Messages)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	input := a.buildInput(r, apiRequest{
+		Payload:   payload,
+		Sender:    req.User,
+		Stateless: true,
+		Metadata:  map[string]string{"api": "openai"},
+	})
+	input.CorrelationID = input.InputID
+	input.ResponseChannel = "api"
+
+	wait := &chatWait{result: make(chan APIResult, 1), stream: req.Stream, notify: make(chan struct{}, 1)}
+	a.responsesMu.Lock()
+	a.completions[input.InputID] = wait
+	a.responsesMu.Unlock()
+	defer func() {
+		a.responsesMu.Lock()
+		delete(a.completions, input.InputID)
+		a.responsesMu.Unlock()
+	}()
+
+	select {
+	case a.out <- input:
+	default:
+		writeOpenAIError(w, http.StatusServiceUnavailable, "server_error", "pipeline busy")
+		return
+	}
+
+	id := "chatcmpl-" + strings.ReplaceAll(input.InputID, "-", "")
+	created := time.Now().Unix()
+	timeout := time.After(apiSyncTimeout)
+	if !req.Stream {
+		select {
+		case res := <-wait.result:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(chatCompletion(id, created, payload, res))
+		case <-timeout:
+			writeOpenAIError(w, http.StatusGatewayTimeout, "timeout", "the task did not finish in time")
+		case <-r.Context().Done():
+		}
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	event := func(v any) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	event(chatChunk(id, created, OpenAIModel, map[string]string{"role": "assistant"}, nil))
+	keepAlive := time.NewTicker(openAIKeepAlive)
+	defer keepAlive.Stop()
+	var streamed strings.Builder
+	for {
+		select {
+		case <-wait.notify:
+			if text := wait.take(); text != "" {
+				streamed.WriteString(text)
+				event(chatChunk(id, created, OpenAIModel, map[string]string{"content": text}, nil))
+			}
+		case res := <-wait.result:
+			model, finish := chatModel(res), chatFinishReason(res)
+			pending := wait.take()
+			sent := streamed.String() + pending
+			// The result usually is the streamed text; if it isn't (the
+			// review rejected it, a skill answered), it follows in full.
+			rest := res.Text
+			if strings.HasPrefix(res.Text, sent) {
+				rest = res.Text[len(sent):]
+			} else if sent != "" {
+				rest = "\n\n" + res.Text
+			}
+			if content := pending + rest; content != "" || sent == "" {
+				event(chatChunk(id, created, model, map[string]string{"content": content}, nil))
+			}
+			event(chatChunk(id, created, model, map[string]string{}, &finish))
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": running\n\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-timeout:
+			event(map[string]any{"error": map[string]string{"type": "timeout", "message": "the task did not finish in time"}})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleModels handles GET /v1/models, which clients call to check the
+// server and offer a model picker.
+func (a *APISense) handleModels(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"object": "list",
+		"data": []map[string]any{
+			{"id": OpenAIModel, "object": "model", "created": 0, "owned_by": OpenAIModel},
+		},
+	})
+}
+
+// chatCompletion maps a result to a chat completion. Token counts are
+// estimated (about four characters a token): the pipeline makes several
+// model calls per task and reports cost, not tokens.
+func chatCompletion(id string, created int64, prompt string, res APIResult) map[string]any {
+	in, out := estimateChatTokens(prompt), estimateChatTokens(res.Text)
+	return map[string]any{
+		"id":      id,
+		"object":  "chat.completion",
+		"created": created,
+		"model":   chatModel(res),
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       map[string]string{"role": "assistant", "content": res.Text},
+			"finish_reason": chatFinishReason(res),
+		}},
+		"usage": map[string]int{
+			"prompt_tokens":     in,
+			"completion_tokens": out,
+			"total_tokens":      in + out,
+		},
+	}
+}
+
+// chatChunk is one event of a streamed chat completion.
+func chatChunk(id string, created int64, model string, delta map[string]string, finish *string) map[string]any {
+	return map[string]any{
+		"id":      id,
+		"object":  "chat.completion.chunk",
+		"created": created,
+		"model":   model,
+		"choices": []map[string]any{{"index": 0, "delta": delta, "finish_reason": finish}},
+	}
+}
+
+func chatModel(res APIResult) string {
+	if res.Model != "" {
+		return res.Model
+	}
+	return OpenAIModel
+}
+
+func chatFinishReason(res APIResult) string {
+	if res.Declined {
+		return "content_filter"
+	}
+	return "stop"
+}
+
+func estimateChatTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// writeOpenAIError writes an error in the OpenAI API's format.
+func writeOpenAIError(w http.ResponseWriter, status int, typ, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"message": message, "type": typ, "code": nil},
+	})
+}
+
+// SendResult answers the pending synchronous request target with res:
+// a chat completion gets the model and outcome along with the text, other
+// requests the text with atts (see SendWithAttachments).
+func (a *APISense) SendResult(ctx context.Context, target string, res APIResult, atts []Attachment, link func(Attachment) string) error {
+	a.responsesMu.RLock()
+	wait, ok := a.completions[target]
+	a.responsesMu.RUnlock()
+	if !ok {
+		return SendWithAttachments(ctx, a, target, res.Text, atts, link)
+	}
+	res.Text += attachmentLinks(atts, link)
+	select {
+	case wait.result <- res:
+	default:
+	}
+	return nil
+}
+
+// SendPartial streams a piece of the answer to the pending chat completion
+// request target, if it asked for a stream; done ends a streamed
+// completion. It never blocks.
+func (a *APISense) SendPartial(target, chunk string, done bool) {
+	a.responsesMu.RLock()
+	wait, ok := a.completions[target]
+	a.responsesMu.RUnlock()
+	if ok && wait.stream {
+		wait.add(chunk, done)
+	}
+}
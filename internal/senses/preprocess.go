@@ -0,0 +1,339 @@
+package senses
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ---------------------------------------------------------------------------
+// Preprocessor — per-channel filters that clean payloads before the pipeline
+// sanitizes and runs them.
+// ---------------------------------------------------------------------------
+
+// PreprocessConfig selects the filters applied to one channel's payloads.
+// They run in field order: quoted history, signature, replace rules,
+// whitespace, then language detection and translation.
+type PreprocessConfig struct {
+	// StripQuoted cuts quoted history: "> " lines and everything from an
+	// "On ... wrote:" or "-----Original Message-----" marker on.
+	StripQuoted bool `json:"strip_quoted,omitempty"`
+	// StripSignature cuts everything from a "-- " delimiter or a
+	// "Sent from my ..." footer on.
+	StripSignature bool `json:"strip_signature,omitempty"`
+	// Replace rules are Go regular expressions applied in order; the
+	// replacement may use $1-style group references.
+	Replace []ReplaceRule `json:"replace,omitempty"`
+	// CollapseWhitespace trims lines and folds runs of blank lines and
+	// spaces.
+	CollapseWhitespace bool `json:"collapse_whitespace,omitempty"`
+	// DetectLanguage records the payload's language in
+	// SourceMeta.Extra["language"].
+	DetectLanguage bool `json:"detect_language,omitempty"`
+	// Translate translates payloads that are not in the agent's working
+	// language into it (implies DetectLanguage).
+	Translate bool `json:"translate,omitempty"`
+}
+
+// ReplaceRule is a user-defined regex substitution.
+type ReplaceRule struct {
+	Pattern string `json:"pattern"`
+	With    string `json:"with"`
+}
+
+// Validate reports an invalid replace pattern.
+func (c PreprocessConfig) Validate() error {
+	_, err := c.compile()
+	return err
+}
+
+func (c PreprocessConfig) compile() ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(c.Replace))
+	for i, r := range c.Replace {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("replace[%d]: %w", i, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Translator translates text from one language into another (ISO 639-1
+// codes; from may be empty when unknown).
+type Translator interface {
+	Translate(ctx context.Context, text, from, to string) (string, error)
+}
+
+// preprocessChain is a channel's config with its compiled rules.
+type preprocessChain struct {
+	PreprocessConfig
+	rules []*regexp.Regexp
+}
+
+// Preprocessor applies the configured filter chain of an input's channel.
+type Preprocessor struct {
+	chains     map[SourceType]preprocessChain
+	language   string
+	translator Translator
+}
+
+// NewPreprocessor creates a Preprocessor from per-channel configs.
+// language is the agent's working language ("" = "en"); translator may be
+// nil, which disables translation.
+func NewPreprocessor(configs map[SourceType]PreprocessConfig, language string, translator Translator) (*Preprocessor, error) {
+	if language == "" {
+		language = "en"
+	}
+	pp := &Preprocessor{
+		chains:     make(map[SourceType]preprocessChain, len(configs)),
+		language:   language,
+		translator: translator,
+	}
+	for st, cfg := range configs {
+		rules, err := cfg.compile()
+		if err != nil {
+			return nil, fmt.Errorf("%s preprocess: %w", st, err)
+		}
+		pp.chains[st] = preprocessChain{PreprocessConfig: cfg, rules: rules}
+	}
+	return pp, nil
+}
+
+// Len returns the number of channels with a filter chain.
+func (pp *Preprocessor) Len() int {
+	if pp == nil {
+		return 0
+	}
+	return len(pp.chains)
+}
+
+// Apply runs the chain for input's channel over its payload. The original
+// payload is kept in SourceMeta.Extra["original_payload"] when it changes.
+// Heartbeats are never touched. A failed translation leaves the filtered
+// payload in place and is returned as the error.
+func (pp *Preprocessor) Apply(ctx context.Context, input *UnifiedInput) error {
+	if pp == nil || input == nil || input.SourceType == SourceTimer {
+		return nil
+	}
+	chain, ok := pp.chains[input.SourceType]
+	if !ok {
+		return nil
+	}
+
+	original := input.Payload
+	text := original
+	if chain.StripQuoted {
+		text = StripQuoted(text)
+	}
+	if chain.StripSignature {
+		text = StripSignature(text)
+	}
+	for i, re := range chain.rules {
+		text = re.ReplaceAllString(text, chain.Replace[i].With)
+	}
+	if chain.CollapseWhitespace {
+		text = CollapseWhitespace(text)
+	}
+	if strings.TrimSpace(text) == "" {
+		// Never filter a message down to nothing.
+		text = original
+	}
+
+	var err error
+	if chain.DetectLanguage || chain.Translate {
+		if lang := DetectLanguage(text); lang != "" {
+			setExtra(input, "language", lang)
+			if chain.Translate && pp.translator != nil && lang != pp.language {
+				translated, terr := pp.translator.Translate(ctx, text, lang, pp.language)
+				if terr != nil {
+					err = fmt.Errorf("translate %s→%s: %w", lang, pp.language, terr)
+				} else if strings.TrimSpace(translated) != "" {
+					text = translated
+					setExtra(input, "translated_from", lang)
+				}
+			}
+		}
+	}
+
+	if text != original {
+		setExtra(input, "original_payload", original)
+		input.Payload = text
+	}
+	return err
+}
+
+// quoteMarkerRe matches lines that start quoted history in replies.
+var quoteMarkerRe = regexp.MustCompile(`(?i)^(on\s.+\swrote:|am\s.+\sschrieb.*:|le\s.+\sa écrit\s?:|el\s.+\sescribió:|-{3,}\s*original message\s*-{3,}|-{3,}\s*forwarded message\s*-{3,}|_{10,})$`)
+
+// StripQuoted removes quoted reply history: "> " lines and everything from
+// a reply header ("On Mon, Anna wrote:", "-----Original Message-----",
+// Outlook's underscore rule) on.
+func StripQuoted(text string) string {
+	lines := strings.Split(text, "\n")
+	out := lines[:0:0]
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if quoteMarkerRe.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.TrimRightFunc(strings.Join(out, "\n"), unicode.IsSpace)
+}
+
+// signatureRe matches lines that start an email signature or a mobile
+// client footer.
+var signatureRe = regexp.MustCompile(`(?i)^(--|sent from my .+|sent from (outlook|mail) for .+|get outlook for .+|von meinem .+ gesendet)$`)
+
+// StripSignature removes everything from the signature delimiter ("-- ")
+// or a "Sent from my iPhone"-style footer on.
+func StripSignature(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i > 0 && signatureRe.MatchString(strings.TrimSpace(line)) {
+			return strings.TrimRightFunc(strings.Join(lines[:i], "\n"), unicode.IsSpace)
+		}
+	}
+	return text
+}
+
+// spaceRunRe matches runs of horizontal whitespace.
+var spaceRunRe = regexp.MustCompile(`[ \t\p{Zs}]+`)
+
+// CollapseWhitespace trims every line, folds runs of spaces and tabs into
+// one space and runs of blank lines into one.
+func CollapseWhitespace(text string) string {
+	var b strings.Builder
+	blank := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(spaceRunRe.ReplaceAllString(line, " "))
+		if line == "" {
+			blank = b.Len() > 0
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+			if blank {
+				b.WriteByte('\n')
+			}
+		}
+		blank = false
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// stopwords are frequent short words of the Latin-script languages
+// DetectLanguage tells apart.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "to", "of", "for", "with", "this", "that", "please", "can", "what", "my"},
+	"de": {"der", "die", "das", "den", "dem", "und", "ist", "nicht", "ich", "du", "sie", "mir", "mit", "für", "bitte", "ein", "eine", "auf", "wir", "zu"},
+	"fr": {"le", "la", "les", "et", "est", "vous", "je", "pour", "avec", "une", "des", "pas", "que", "nous", "merci"},
+	"es": {"el", "la", "los", "las", "y", "es", "que", "por", "para", "con", "una", "usted", "gracias", "favor", "está"},
+	"it": {"il", "lo", "gli", "e", "è", "che", "per", "con", "una", "non", "sono", "grazie", "della", "questo", "mi"},
+	"pt": {"o", "os", "as", "e", "é", "que", "para", "com", "uma", "não", "você", "obrigado", "por", "favor", "do"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "ik", "je", "met", "voor", "van", "dat", "wij", "bedankt", "alstublieft"},
+}
+
+// DetectLanguage guesses the ISO 639-1 language of text from its script
+// and, for Latin script, its most frequent short words. It returns "" when
+// the text is too short or ambiguous to tell.
+func DetectLanguage(text string) string {
+	scripts := map[string]int{}
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			scripts["latin"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["cyrillic"]++
+			switch r {
+			case 'і', 'ї', 'є', 'ґ', 'І', 'Ї', 'Є', 'Ґ':
+				scripts["uk"]++
+			}
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			scripts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["han"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		}
+	}
+	if letters < 10 {
+		return ""
+	}
+
+	// Non-Latin scripts decide on their own once they dominate.
+	best, bestN := "", 0
+	for s, n := range scripts {
+		if s != "uk" && n > bestN {
+			best, bestN = s, n
+		}
+	}
+	switch best {
+	case "cyrillic":
+		if scripts["uk"] > 0 {
+			return "uk"
+		}
+		return "ru"
+	case "han":
+		if scripts["ja"] > 0 {
+			return "ja"
+		}
+		return "zh"
+	case "latin", "":
+	default:
+		return best
+	}
+
+	counts := map[string]int{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for lang, words := range stopwords {
+			for _, sw := range words {
+				if w == sw {
+					counts[lang]++
+				}
+			}
+		}
+	}
+	lang, top, second := "", 0, 0
+	for l, n := range counts {
+		switch {
+		case n > top:
+			lang, top, second = l, n, top
+		case n > second:
+			second = n
+		}
+	}
+	if top < 2 || top == second {
+		return ""
+	}
+	return lang
+}
+
+func setExtra(input *UnifiedInput, key, value string) {
+	if input.SourceMeta.Extra == nil {
+		input.SourceMeta.Extra = make(map[string]string)
+	}
+	input.SourceMeta.Extra[key] = value
+}
@@ -0,0 +1,151 @@
+package senses
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const replyEmail = `Sounds good, Friday at 10 works.
+Can you bring the   contract?
+
+--
+Anna Petrova
+Head of Procurement, Acme GmbH
+
+On Mon, 3 Mar 2025 at 09:12, Bob <bob@example.com> wrote:
+> Can we meet this week?
+> Bob`
+
+func TestStripQuotedAndSignature(t *testing.T) {
+	got := StripSignature(StripQuoted(replyEmail))
+	want := "Sounds good, Friday at 10 works.\nCan you bring the   contract?"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	outlook := "Approved.\r\n\r\nSent from my iPhone\r\n\r\n-----Original Message-----\r\nFrom: Bob"
+	if got := StripSignature(StripQuoted(outlook)); got != "Approved." {
+		t.Errorf("outlook reply = %q", got)
+	}
+	// A delimiter on the first line is content, not a signature.
+	if got := StripSignature("--\nfoo"); got != "--\nfoo" {
+		t.Errorf("leading delimiter stripped: %q", got)
+	}
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	in := "  Hello \t  world  \r\n\r\n\r\n\n  second   line\n\n"
+	if got := CollapseWhitespace(in); got != "Hello world\n\nsecond line" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	for text, want := range map[string]string{
+		"Can you please send me the report for this week?":        "en",
+		"Kannst du mir bitte den Bericht für die Woche schicken?": "de",
+		"Pouvez-vous m'envoyer le rapport pour la semaine, merci": "fr",
+		"¿Puede enviarme el informe de la semana, por favor?":     "es",
+		"Пришлите, пожалуйста, отчёт за эту неделю":               "ru",
+		"Надішліть, будь ласка, звіт за цей тиждень":              "uk",
+		"今週のレポートを送ってください":                                         "ja",
+		"请把本周的报告发给我":                                              "zh",
+		"ok thx":                                                  "",
+		"Invoice 2025-03 Q1 ACME":                                 "",
+	} {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+type fakeTranslator struct {
+	calls int
+	err   error
+}
+
+func (f *fakeTranslator) Translate(_ context.Context, text, from, to string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	return "[" + from + "→" + to + "] " + text, nil
+}
+
+func TestPreprocessor_Apply(t *testing.T) {
+	tr := &fakeTranslator{}
+	pp, err := NewPreprocessor(map[SourceType]PreprocessConfig{
+		SourceEmail: {
+			StripQuoted:        true,
+			StripSignature:     true,
+			Replace:            []ReplaceRule{{Pattern: `(?i)\bcontract\b`, With: "agreement"}},
+			CollapseWhitespace: true,
+			DetectLanguage:     true,
+		},
+		SourceTelegram: {Translate: true},
+	}, "en", tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := NewUnifiedInput(SourceEmail, replyEmail)
+	if err := pp.Apply(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Sounds good, Friday at 10 works.\nCan you bring the agreement?"; input.Payload != want {
+		t.Errorf("Payload = %q, want %q", input.Payload, want)
+	}
+	if input.SourceMeta.Extra["original_payload"] != replyEmail {
+		t.Error("original payload not kept")
+	}
+	if input.SourceMeta.Extra["language"] != "en" || tr.calls != 0 {
+		t.Errorf("language = %q, translations = %d", input.SourceMeta.Extra["language"], tr.calls)
+	}
+
+	// Translation into the working language.
+	input = NewUnifiedInput(SourceTelegram, "Kannst du mir bitte den Bericht schicken?")
+	pp.Apply(context.Background(), input)
+	if !strings.HasPrefix(input.Payload, "[de→en] ") || input.SourceMeta.Extra["translated_from"] != "de" {
+		t.Errorf("Payload = %q, extra = %v", input.Payload, input.SourceMeta.Extra)
+	}
+
+	// A failed translation keeps the text and reports the error.
+	tr.err = errors.New("offline")
+	input = NewUnifiedInput(SourceTelegram, "Kannst du mir bitte den Bericht schicken?")
+	if err := pp.Apply(context.Background(), input); err == nil || input.Payload != "Kannst du mir bitte den Bericht schicken?" {
+		t.Errorf("err = %v, Payload = %q", err, input.Payload)
+	}
+
+	// Channels without a chain, heartbeats and nil preprocessors are untouched.
+	for _, in := range []*UnifiedInput{NewUnifiedInput(SourceSlack, "a  b"), NewUnifiedInput(SourceTimer, "a  b")} {
+		pp.Apply(context.Background(), in)
+		if in.Payload != "a  b" || in.SourceMeta.Extra != nil {
+			t.Errorf("%s input changed: %q", in.SourceType, in.Payload)
+		}
+	}
+	var none *Preprocessor
+	if err := none.Apply(context.Background(), input); err != nil || none.Len() != 0 {
+		t.Error("nil Preprocessor should be a no-op")
+	}
+}
+
+func TestPreprocessor_NeverEmpties(t *testing.T) {
+	pp, _ := NewPreprocessor(map[SourceType]PreprocessConfig{SourceEmail: {StripQuoted: true}}, "", nil)
+	input := NewUnifiedInput(SourceEmail, "> only a quote")
+	pp.Apply(context.Background(), input)
+	if input.Payload != "> only a quote" {
+		t.Errorf("Payload = %q", input.Payload)
+	}
+}
+
+func TestNewPreprocessor_InvalidRule(t *testing.T) {
+	cfg := PreprocessConfig{Replace: []ReplaceRule{{Pattern: "(unclosed"}}}
+	if cfg.Validate() == nil {
+		t.Error("Validate accepted an invalid pattern")
+	}
+	if _, err := NewPreprocessor(map[SourceType]PreprocessConfig{SourceEmail: cfg}, "en", nil); err == nil {
+		t.Error("NewPreprocessor accepted an invalid pattern")
+	}
+}
@@ -0,0 +1,149 @@
+package senses
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ---------------------------------------------------------------------------
+// PrePrompts — per-channel instruction templates wrapped around payloads.
+// ---------------------------------------------------------------------------
+
+// PrePrompts wraps incoming payloads with channel-appropriate instructions
+// (e.g. "reply in formal tone" for email). Templates are keyed by SourceType
+// and support {placeholder} substitution:
+//
+//	{payload}  — the original payload
+//	{sender}   — SourceMeta.Sender
+//	{channel}  — SourceMeta.Channel
+//	{path}     — SourceMeta.Path
+//	{name}     — base name of SourceMeta.Path (or Extra["filename"])
+//	{<key>}    — any SourceMeta.Extra key (e.g. {subject})
+//
+// If a template does not contain {payload}, the payload is appended after
+// a blank line so the original content is never lost.
+type PrePrompts struct {
+	mu        sync.RWMutex
+	templates map[SourceType]string
+}
+
+// NewPrePrompts creates a PrePrompts transformer from the given templates.
+// Empty templates are ignored.
+func NewPrePrompts(templates map[SourceType]string) *PrePrompts {
+	pp := &PrePrompts{templates: make(map[SourceType]string)}
+	for st, tmpl := range templates {
+		pp.Set(st, tmpl)
+	}
+	return pp
+}
+
+// Set registers (or replaces) the template for a source type.
+// An empty template removes the rule.
+func (pp *PrePrompts) Set(st SourceType, tmpl string) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if strings.TrimSpace(tmpl) == "" {
+		delete(pp.templates, st)
+		return
+	}
+	pp.templates[st] = tmpl
+}
+
+// Get returns the template for a source type, or "" if none is set.
+func (pp *PrePrompts) Get(st SourceType) string {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	return pp.templates[st]
+}
+
+// Len returns the number of configured templates.
+func (pp *PrePrompts) Len() int {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	return len(pp.templates)
+}
+
+// Apply rewrites input.Payload using the template for its SourceType.
+// The original payload is preserved in SourceMeta.Extra["raw_payload"].
+// Returns true if a template was applied. Heartbeats are never wrapped.
+func (pp *PrePrompts) Apply(input *UnifiedInput) bool {
+	if pp == nil || input == nil || input.SourceType == SourceTimer {
+		return false
+	}
+	tmpl := pp.Get(input.SourceType)
+	if tmpl == "" {
+		return false
+	}
+
+	if input.SourceMeta.Extra == nil {
+		input.SourceMeta.Extra = make(map[string]string)
+	}
+	input.SourceMeta.Extra["raw_payload"] = input.Payload
+	input.Payload = renderPrePrompt(tmpl, input)
+	return true
+}
+
+// renderPrePrompt substitutes placeholders in tmpl with values from input.
+func renderPrePrompt(tmpl string, input *UnifiedInput) string {
+	name := input.SourceMeta.Extra["filename"]
+	if name == "" && input.SourceMeta.Path != "" {
+		name = filepath.Base(input.SourceMeta.Path)
+	}
+
+	payload := input.SourceMeta.Extra["raw_payload"]
+	hasPayload := strings.Contains(tmpl, "{payload}")
+	pairs := []string{
+		"{payload}", payload,
+		"{sender}", input.SourceMeta.Sender,
+		"{channel}", input.SourceMeta.Channel,
+		"{path}", input.SourceMeta.Path,
+		"{name}", name,
+	}
+	for k, v := range input.SourceMeta.Extra {
+		if k == "raw_payload" || k == "payload" {
+			continue
+		}
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+
+	// One pass over the template: placeholder-like text inside the values
+	// (a payload, an e-mail subject) is never expanded.
+	out := strings.NewReplacer(pairs...).Replace(tmpl)
+	if hasPayload {
+		return out
+	}
+	return strings.TrimRight(out, "\n") + "\n\n" + payload
+}
+
+// ParseSourceType converts a config key (case-insensitive, e.g. "email",
+// "file", "filewatcher") to a SourceType. Returns "" if unknown.
+func ParseSourceType(s string) SourceType {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TEXT", "CLI":
+		return SourceText
+	case "JSON":
+		return SourceJSON
+	case "WEBHOOK":
+		return SourceWebhook
+	case "FILE", "FILEWATCHER", "FILE_WATCHER", "INBOX":
+		return SourceFile
+	case "TIMER", "HEARTBEAT":
+		return SourceTimer
+	case "TELEGRAM":
+		return SourceTelegram
+	case "SLACK":
+		return SourceSlack
+	case "DISCORD":
+		return SourceDiscord
+	case "EMAIL":
+		return SourceEmail
+	case "SIGNAL":
+		return SourceSignal
+	case "API":
+		return SourceAPI
+	case "VOICE", "AUDIO":
+		return SourceVoice
+	}
+	return ""
+}
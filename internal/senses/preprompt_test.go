@@ -0,0 +1,125 @@
+package senses
+
+import "testing"
+
+func TestPrePrompts_ApplyWithPayloadPlaceholder(t *testing.T) {
+	pp := NewPrePrompts(map[SourceType]string{
+		SourceEmail: "Reply formally to {sender} about \"{subject}\".\n---\n{payload}\n---",
+	})
+
+	input := NewUnifiedInput(SourceEmail, "Can we meet on Friday?")
+	input.SourceMeta.Sender = "bob@example.com"
+	input.SourceMeta.Extra = map[string]string{"subject": "Meeting"}
+
+	if !pp.Apply(input) {
+		t.Fatal("Apply returned false")
+	}
+	want := "Reply formally to bob@example.com about \"Meeting\".\n---\nCan we meet on Friday?\n---"
+	if input.Payload != want {
+		t.Errorf("Payload = %q, want %q", input.Payload, want)
+	}
+	if input.SourceMeta.Extra["raw_payload"] != "Can we meet on Friday?" {
+		t.Errorf("raw_payload = %q", input.SourceMeta.Extra["raw_payload"])
+	}
+}
+
+func TestPrePrompts_ApplyAppendsPayload(t *testing.T) {
+	pp := NewPrePrompts(map[SourceType]string{
+		SourceFile: "This is a file named {name}, decide whether it needs action.",
+	})
+
+	input := NewUnifiedInput(SourceFile, "invoice total: $120")
+	input.SourceMeta.Path = "/inbox/invoice.txt"
+
+	pp.Apply(input)
+	want := "This is a file named invoice.txt, decide whether it needs action.\n\ninvoice total: $120"
+	if input.Payload != want {
+		t.Errorf("Payload = %q, want %q", input.Payload, want)
+	}
+}
+
+func TestPrePrompts_PayloadNotExpanded(t *testing.T) {
+	pp := NewPrePrompts(map[SourceType]string{SourceAPI: "From {sender}: {payload}"})
+
+	input := NewUnifiedInput(SourceAPI, "literal {sender} text")
+	input.SourceMeta.Sender = "alice"
+
+	pp.Apply(input)
+	if input.Payload != "From alice: literal {sender} text" {
+		t.Errorf("Payload = %q", input.Payload)
+	}
+}
+
+func TestPrePrompts_ExtraNotExpanded(t *testing.T) {
+	pp := NewPrePrompts(map[SourceType]string{SourceEmail: "Subject: {subject}\nFrom {sender}:\n{payload}"})
+
+	input := NewUnifiedInput(SourceEmail, "the body")
+	input.SourceMeta.Sender = "mallory"
+	input.SourceMeta.Extra = map[string]string{"subject": "{payload} {sender}"}
+
+	pp.Apply(input)
+	if input.Payload != "Subject: {payload} {sender}\nFrom mallory:\nthe body" {
+		t.Errorf("Payload = %q", input.Payload)
+	}
+}
+
+func TestPrePrompts_NoTemplate(t *testing.T) {
+	pp := NewPrePrompts(map[SourceType]string{SourceEmail: "formal: {payload}"})
+
+	input := NewUnifiedInput(SourceTelegram, "hi")
+	if pp.Apply(input) {
+		t.Error("Apply should return false for channel without template")
+	}
+	if input.Payload != "hi" {
+		t.Errorf("Payload = %q, want unchanged", input.Payload)
+	}
+}
+
+func TestPrePrompts_SkipsHeartbeat(t *testing.T) {
+	pp := NewPrePrompts(map[SourceType]string{SourceTimer: "wrap: {payload}"})
+
+	hb := NewHeartbeat()
+	orig := hb.Payload
+	if pp.Apply(hb) {
+		t.Error("heartbeat should never be wrapped")
+	}
+	if hb.Payload != orig {
+		t.Errorf("Payload = %q, want %q", hb.Payload, orig)
+	}
+}
+
+func TestPrePrompts_NilSafe(t *testing.T) {
+	var pp *PrePrompts
+	if pp.Apply(NewFromText("x")) {
+		t.Error("nil PrePrompts should not apply")
+	}
+}
+
+func TestPrePrompts_SetEmptyRemoves(t *testing.T) {
+	pp := NewPrePrompts(map[SourceType]string{SourceSlack: "x {payload}", SourceDiscord: "  "})
+	if pp.Len() != 1 {
+		t.Fatalf("Len = %d, want 1 (blank template ignored)", pp.Len())
+	}
+	pp.Set(SourceSlack, "")
+	if pp.Len() != 0 || pp.Get(SourceSlack) != "" {
+		t.Error("Set with empty template should remove the rule")
+	}
+}
+
+func TestParseSourceType(t *testing.T) {
+	tests := map[string]SourceType{
+		"email":       SourceEmail,
+		"Telegram":    SourceTelegram,
+		"file":        SourceFile,
+		"filewatcher": SourceFile,
+		" api ":       SourceAPI,
+		"cli":         SourceText,
+		"SLACK":       SourceSlack,
+		"unknown":     "",
+	}
+	for in, want := range tests {
+		if got := ParseSourceType(in); got != want {
+			t.Errorf("ParseSourceType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"unicode/utf8"
 )
 
 // ---------------------------------------------------------------------------
@@ -98,6 +101,32 @@ func TestTelegramSend_Chunking(t *testing.T) {
 	}
 }
 
+func TestTelegramSend_ChunkingKeepsRunesWhole(t *testing.T) {
+	var messages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		messages = append(messages, payload["text"])
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	tg := NewTelegramSense(TelegramConfig{Token: "tok"})
+	tg.apiBase = srv.URL + "/bottok"
+	msg := "A" + strings.Repeat("ж", 2100) // 4201 bytes; byte 4096 is mid-rune
+	if err := tg.Send(context.Background(), "1", msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(messages) != 2 || messages[0]+messages[1] != msg {
+		t.Fatalf("chunks = %d, rejoined intact = %v", len(messages), strings.Join(messages, "") == msg)
+	}
+	for i, m := range messages {
+		if !utf8.ValidString(m) {
+			t.Errorf("chunk %d is not valid UTF-8", i)
+		}
+	}
+}
+
 func TestTelegramSend_APIError(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{"ok":false,"error_code":403,"description":"bot was blocked"}`))
@@ -351,3 +380,79 @@ func TestSenseRegistry_PrimaryNotSet(t *testing.T) {
 		t.Errorf("expected empty target, got %q", target)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Attachments
+// ---------------------------------------------------------------------------
+
+func TestTelegramSendAttachments(t *testing.T) {
+	dir := t.TempDir()
+	img := filepath.Join(dir, "chart.png")
+	doc := filepath.Join(dir, "data.csv")
+	os.WriteFile(img, []byte("png"), 0o600)
+	os.WriteFile(doc, []byte("a,b\n"), 0o600)
+
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/"):]
+		if method != "/sendMessage" {
+			f, h, err := r.FormFile(map[string]string{"/sendPhoto": "photo", "/sendDocument": "document"}[method])
+			if err != nil {
+				t.Errorf("%s: %v", method, err)
+			} else {
+				data, _ := io.ReadAll(f)
+				method += " " + h.Filename + " " + string(data) + " " + r.FormValue("chat_id")
+			}
+		}
+		mu.Lock()
+		calls = append(calls, method)
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	tg := NewTelegramSense(TelegramConfig{Token: "tok"})
+	tg.apiBase = srv.URL + "/bottok"
+	atts := []Attachment{
+		{Name: "chart.png", Type: "image/png", Size: 3, Path: img},
+		{Name: "data.csv", Type: "text/csv", Size: 4, Path: doc},
+	}
+	if err := SendWithAttachments(context.Background(), tg, "42", "Here you go", atts, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/sendMessage", "/sendPhoto chart.png png 42", "/sendDocument data.csv a,b\n 42"}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+// linkSense records messages and delivers no files itself.
+type linkSense struct{ sent []string }
+
+func (s *linkSense) Name() string                                      { return "Links" }
+func (s *linkSense) Start(context.Context, chan<- *UnifiedInput) error { return nil }
+func (s *linkSense) Stop() error                                       { return nil }
+func (s *linkSense) Send(_ context.Context, _, msg string) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestSendWithAttachments_LinkFallback(t *testing.T) {
+	s := &linkSense{}
+	atts := []Attachment{{Name: "out/report.pdf", Type: "application/pdf", Size: 2048}}
+	link := func(a Attachment) string { return "https://agent.example/artifacts/t1/" + a.Name }
+	if err := SendWithAttachments(context.Background(), s, "x", "Done.", atts, link); err != nil {
+		t.Fatal(err)
+	}
+	want := "Done.\n\nFiles:\n- out/report.pdf (2.0 KB): https://agent.example/artifacts/t1/out/report.pdf"
+	if len(s.sent) != 1 || s.sent[0] != want {
+		t.Errorf("sent = %q, want %q", s.sent, want)
+	}
+
+	// Files over a sense's limit are linked too.
+	caps := AttachmentCaps{Images: true, MaxBytes: 1024}
+	if caps.accepts(atts[0]) || !caps.accepts(Attachment{Type: "image/png", Size: 10}) || caps.accepts(Attachment{Type: "image/png", Size: 2048}) {
+		t.Error("accepts ignores the type or size limit")
+	}
+}
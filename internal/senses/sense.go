@@ -50,7 +50,10 @@ var sourceTypeToSenseName = map[SourceType]string{
 	SourceSlack:    "Slack",
 	SourceDiscord:  "Discord",
 	SourceEmail:    "Email",
+	SourceSignal:   "Signal",
 	SourceAPI:      "API",
+	SourceFile:     "FileWatcher",
+	SourceVoice:    "Voice",
 }
 
 // NewSenseRegistry creates a new, empty SenseRegistry.
@@ -0,0 +1,458 @@
+package senses
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
+)
+
+// SignalConfig holds the signal-cli connection settings. signal-cli must
+// run in JSON-RPC daemon mode for the agent's number, e.g.
+//
+//	signal-cli -a +4915112345678 daemon --tcp 127.0.0.1:7583
+//
+// or with --socket /path for a unix socket ("unix:/path" here).
+type SignalConfig struct {
+	Addr    string `json:"addr"`              // JSON-RPC endpoint (default: 127.0.0.1:7583)
+	Account string `json:"account,omitempty"` // Agent's number; needed if the daemon serves several accounts
+
+	// AllowedNumbers whitelists senders by phone number or Signal UUID
+	// (empty = allow all).
+	AllowedNumbers []string `json:"allowed_numbers"`
+
+	// AttachmentDir is where signal-cli stores received attachments
+	// (default: ~/.local/share/signal-cli/attachments).
+	AttachmentDir string `json:"attachment_dir,omitempty"`
+
+	// Limits caps message size and attachment size/count.
+	Limits Limits `json:"limits,omitempty"`
+
+	// Dial overrides the connection to signal-cli for testing.
+	Dial func(ctx context.Context) (net.Conn, error) `json:"-"`
+}
+
+// Signal defaults.
+const (
+	signalDefaultAddr   = "127.0.0.1:7583"
+	signalCallTimeout   = 30 * time.Second
+	signalMaxBackoff    = 30 * time.Second
+	signalMaxLineBytes  = 4 << 20 // receive notifications carry no attachment data
+	signalGroupPrefix   = "group:"
+	signalConnectedWait = 2 * time.Second
+)
+
+// SignalSense connects to a local signal-cli daemon over JSON-RPC. It
+// receives messages as "receive" notifications and sends replies with the
+// "send" method on the same connection, reconnecting if signal-cli
+// restarts. Group messages are answered in the group.
+type SignalSense struct {
+	config SignalConfig
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	stopped   bool
+	cancel    context.CancelFunc
+	conn      net.Conn
+	connected chan struct{} // closed while conn is set
+	nextID    int64
+	pending   map[int64]chan signalRPCMessage
+
+	writeMu sync.Mutex
+}
+
+// NewSignalSense creates a Signal adapter.
+func NewSignalSense(config SignalConfig) *SignalSense {
+	if config.Addr == "" {
+		config.Addr = signalDefaultAddr
+	}
+	if config.AttachmentDir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			config.AttachmentDir = filepath.Join(home, ".local", "share", "signal-cli", "attachments")
+		}
+	}
+	for i, n := range config.AllowedNumbers {
+		config.AllowedNumbers[i] = normalizeSignalNumber(n)
+	}
+	return &SignalSense{
+		config:    config,
+		logger:    slog.Default(),
+		connected: make(chan struct{}),
+		pending:   make(map[int64]chan signalRPCMessage),
+	}
+}
+
+func (s *SignalSense) Name() string { return "Signal" }
+
+// Start connects to signal-cli and forwards incoming messages until ctx is
+// cancelled, reconnecting with backoff when the connection drops.
+func (s *SignalSense) Start(ctx context.Context, out chan<- *UnifiedInput) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return fmt.Errorf("signal sense already stopped")
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Unlock()
+
+	backoff := time.Second
+	for {
+		conn, err := s.dial(ctx)
+		if err == nil {
+			backoff = time.Second
+			err = s.serve(ctx, conn, out)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.logger.Warn("signal-cli connection lost", "addr", s.config.Addr, "error", err, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, signalMaxBackoff)
+	}
+}
+
+func (s *SignalSense) dial(ctx context.Context) (net.Conn, error) {
+	if s.config.Dial != nil {
+		return s.config.Dial(ctx)
+	}
+	network, address := netaddr.Parse(s.config.Addr)
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
+
+// serve reads JSON-RPC messages from conn until it fails: notifications
+// become inputs, responses complete pending calls.
+func (s *SignalSense) serve(ctx context.Context, conn net.Conn, out chan<- *UnifiedInput) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	s.mu.Lock()
+	s.conn = conn
+	close(s.connected)
+	s.mu.Unlock()
+	defer s.disconnect(conn)
+
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64<<10), signalMaxLineBytes)
+	for sc.Scan() {
+		var msg signalRPCMessage
+		if err := json.Unmarshal(sc.Bytes(), &msg); err != nil {
+			s.logger.Warn("signal: bad JSON-RPC message", "error", err)
+			continue
+		}
+		if msg.Method == "" {
+			s.resolve(msg)
+			continue
+		}
+		if msg.Method != "receive" {
+			continue
+		}
+		var params signalReceiveParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.logger.Warn("signal: bad receive notification", "error", err)
+			continue
+		}
+		if s.config.Account != "" && params.Account != "" && normalizeSignalNumber(params.Account) != normalizeSignalNumber(s.config.Account) {
+			continue
+		}
+		input := s.toInput(params.Envelope)
+		if input == nil {
+			continue
+		}
+		select {
+		case out <- input:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return errors.New("connection closed")
+}
+
+// disconnect clears conn and fails the calls still waiting on it.
+func (s *SignalSense) disconnect(conn net.Conn) {
+	conn.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == conn {
+		s.conn = nil
+		s.connected = make(chan struct{})
+	}
+	for id, ch := range s.pending {
+		ch <- signalRPCMessage{Error: &signalRPCError{Message: "connection to signal-cli lost"}}
+		delete(s.pending, id)
+	}
+}
+
+func (s *SignalSense) resolve(msg signalRPCMessage) {
+	if msg.ID == nil {
+		return
+	}
+	s.mu.Lock()
+	ch, ok := s.pending[*msg.ID]
+	delete(s.pending, *msg.ID)
+	s.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+}
+
+// toInput converts an envelope into an input, or nil for receipts, typing
+// notices, empty messages and senders not on the allowlist.
+func (s *SignalSense) toInput(env signalEnvelope) *UnifiedInput {
+	dm := env.DataMessage
+	if dm == nil || (dm.Message == "" && len(dm.Attachments) == 0) {
+		return nil
+	}
+	sender := env.SourceNumber
+	if sender == "" {
+		sender = env.Source
+	}
+	if sender == "" {
+		sender = env.SourceUUID
+	}
+	if !s.isAllowed(sender, env.SourceUUID) {
+		return nil
+	}
+
+	payload := dm.Message
+	if payload == "" {
+		names := make([]string, len(dm.Attachments))
+		for i, att := range dm.Attachments {
+			names[i] = att.name()
+		}
+		payload = fmt.Sprintf("Sent %d attachment(s): %s", len(names), strings.Join(names, ", "))
+	}
+
+	input := NewUnifiedInput(SourceSignal, payload)
+	input.SourceMeta.Channel = "signal"
+	input.SourceMeta.Sender = sender
+	input.SourceMeta.Extra = map[string]string{
+		"timestamp": strconv.FormatInt(env.Timestamp, 10),
+	}
+	if env.Timestamp > 0 {
+		input.SourceMeta.Timestamp = time.UnixMilli(env.Timestamp).UTC()
+	}
+	if env.SourceName != "" {
+		input.SourceMeta.Extra["name"] = env.SourceName
+	}
+	if env.SourceUUID != "" {
+		input.SourceMeta.Extra["uuid"] = env.SourceUUID
+	}
+	input.ResponseChannel = sender
+	if dm.GroupInfo != nil && dm.GroupInfo.GroupID != "" {
+		input.SourceMeta.Extra["group_id"] = dm.GroupInfo.GroupID
+		input.ResponseChannel = signalGroupPrefix + dm.GroupInfo.GroupID
+	}
+	for _, att := range dm.Attachments {
+		a := Attachment{Name: att.name(), Type: att.ContentType, Size: att.Size}
+		if s.config.AttachmentDir != "" && att.ID != "" {
+			a.Path = filepath.Join(s.config.AttachmentDir, filepath.Base(att.ID))
+		}
+		input.Attachments = append(input.Attachments, a)
+	}
+	s.config.Limits.enforce(input, s.Name())
+	return input
+}
+
+// Send sends a message to a phone number, UUID or "group:<id>".
+func (s *SignalSense) Send(ctx context.Context, target string, message string) error {
+	if message == "" {
+		return fmt.Errorf("signal: empty message")
+	}
+	return s.SendAttachments(ctx, target, message, nil)
+}
+
+// SendAttachments sends a message with files (paths readable by signal-cli)
+// to a phone number, UUID or "group:<id>".
+func (s *SignalSense) SendAttachments(ctx context.Context, target, message string, paths []string) error {
+	if target == "" {
+		return fmt.Errorf("signal: no recipient")
+	}
+	params := map[string]any{"message": message}
+	if group, ok := strings.CutPrefix(target, signalGroupPrefix); ok {
+		params["groupId"] = group
+	} else {
+		params["recipient"] = []string{target}
+	}
+	if s.config.Account != "" {
+		params["account"] = s.config.Account
+	}
+	if len(paths) > 0 {
+		params["attachments"] = paths
+	}
+	_, err := s.call(ctx, "send", params)
+	return err
+}
+
+// call sends a JSON-RPC request and waits for its response. If the sense
+// is reconnecting, it waits briefly for the connection to come back.
+func (s *SignalSense) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, signalCallTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	connected := s.connected
+	s.mu.Unlock()
+	select {
+	case <-connected:
+	case <-time.After(signalConnectedWait):
+		return nil, fmt.Errorf("signal: not connected to signal-cli at %s", s.config.Addr)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	conn := s.conn
+	if conn == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("signal: not connected to signal-cli at %s", s.config.Addr)
+	}
+	s.nextID++
+	id := s.nextID
+	ch := make(chan signalRPCMessage, 1)
+	s.pending[id] = ch
+	s.mu.Unlock()
+
+	line, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		s.forget(id)
+		return nil, fmt.Errorf("signal: encode %s: %w", method, err)
+	}
+	s.writeMu.Lock()
+	_, err = conn.Write(append(line, '\n'))
+	s.writeMu.Unlock()
+	if err != nil {
+		s.forget(id)
+		return nil, fmt.Errorf("signal: %s: %w", method, err)
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("signal: %s: %s", method, resp.Error)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		s.forget(id)
+		return nil, fmt.Errorf("signal: %s: %w", method, ctx.Err())
+	}
+}
+
+func (s *SignalSense) forget(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, id)
+}
+
+func (s *SignalSense) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func (s *SignalSense) isAllowed(number, uuid string) bool {
+	if len(s.config.AllowedNumbers) == 0 {
+		return true
+	}
+	number = normalizeSignalNumber(number)
+	for _, n := range s.config.AllowedNumbers {
+		if n == number || (uuid != "" && strings.EqualFold(n, uuid)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSignalNumber strips the spaces, dashes and parentheses people
+// write phone numbers with.
+func normalizeSignalNumber(n string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(n))
+}
+
+// --- signal-cli JSON-RPC types (minimal subset) ---
+
+type signalRPCMessage struct {
+	ID     *int64          `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *signalRPCError `json:"error,omitempty"`
+}
+
+type signalRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *signalRPCError) String() string {
+	if e.Code == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+type signalReceiveParams struct {
+	Account  string         `json:"account"`
+	Envelope signalEnvelope `json:"envelope"`
+}
+
+type signalEnvelope struct {
+	Source       string             `json:"source"`
+	SourceNumber string             `json:"sourceNumber"`
+	SourceUUID   string             `json:"sourceUuid"`
+	SourceName   string             `json:"sourceName"`
+	Timestamp    int64              `json:"timestamp"`
+	DataMessage  *signalDataMessage `json:"dataMessage,omitempty"`
+}
+
+type signalDataMessage struct {
+	Message     string             `json:"message"`
+	GroupInfo   *signalGroupInfo   `json:"groupInfo,omitempty"`
+	Attachments []signalAttachment `json:"attachments,omitempty"`
+}
+
+type signalGroupInfo struct {
+	GroupID string `json:"groupId"`
+}
+
+type signalAttachment struct {
+	ContentType string `json:"contentType"`
+	Filename    string `json:"filename"`
+	ID          string `json:"id"`
+	Size        int64  `json:"size"`
+}
+
+func (a signalAttachment) name() string {
+	if a.Filename != "" {
+		return a.Filename
+	}
+	return a.ID
+}
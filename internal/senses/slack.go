@@ -3,13 +3,21 @@ package senses
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
 )
 
 // SlackConfig holds Slack app configuration.
@@ -18,9 +26,13 @@ type SlackConfig struct {
 	AppToken     string `json:"app_token"`     // xapp-... (for Socket Mode)
 	SigningSecret string `json:"signing_secret"` // For webhook verification
 	ListenAddr   string `json:"listen_addr"`   // Webhook listen address (e.g., ":3001")
+	Limits       Limits `json:"limits,omitempty"` // Request body quota
 }
 
-// SlackSense receives messages from Slack via Events API webhooks.
+// SlackSense receives mentions of the bot and direct messages from Slack
+// and replies in the message's thread. With an app token it connects over
+// Socket Mode, which needs no public URL; otherwise it serves Events API
+// webhooks, verified with the signing secret when one is set.
 type SlackSense struct {
 	config   SlackConfig
 	mu       sync.Mutex
@@ -29,6 +41,7 @@ type SlackSense struct {
 	srv      *http.Server
 	listener net.Listener
 	client   *http.Client
+	seen     map[string]time.Time // event IDs handled recently; Slack redelivers
 
 	// apiBase is the Slack API base URL. Override in tests.
 	apiBase string
@@ -48,7 +61,9 @@ func NewSlackSense(config SlackConfig) *SlackSense {
 
 func (s *SlackSense) Name() string { return "Slack" }
 
-// Start begins listening for Slack Events API webhooks.
+// Start receives events until ctx is cancelled or Stop is called: over
+// Socket Mode when an app token is configured, as Events API webhooks
+// otherwise.
 func (s *SlackSense) Start(ctx context.Context, out chan<- *UnifiedInput) error {
 	s.mu.Lock()
 	if s.stopped {
@@ -58,10 +73,14 @@ func (s *SlackSense) Start(ctx context.Context, out chan<- *UnifiedInput) error
 	ctx, s.cancel = context.WithCancel(ctx)
 	s.mu.Unlock()
 
+	if s.config.AppToken != "" {
+		return s.runSocketMode(ctx, out)
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/slack/events", s.handleEvents(out))
 
-	ln, err := net.Listen("tcp", s.config.ListenAddr)
+	ln, err := netaddr.Listen(s.config.ListenAddr)
 	if err != nil {
 		return fmt.Errorf("slack listen: %w", err)
 	}
@@ -86,13 +105,18 @@ func (s *SlackSense) Start(ctx context.Context, out chan<- *UnifiedInput) error
 // handleEvents processes incoming Slack Events API payloads.
 func (s *SlackSense) handleEvents(out chan<- *UnifiedInput) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		body, err := io.ReadAll(r.Body)
+		body, err := s.config.Limits.readBody(w, r, s.Name())
 		if err != nil {
-			http.Error(w, "read error", http.StatusBadRequest)
+			writeLimitError(w, err)
 			return
 		}
 		defer r.Body.Close()
 
+		if s.config.SigningSecret != "" && !slackSignatureValid(s.config.SigningSecret, r.Header, body, time.Now()) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
 		var envelope slackEventEnvelope
 		if err := json.Unmarshal(body, &envelope); err != nil {
 			http.Error(w, "invalid json", http.StatusBadRequest)
@@ -108,68 +132,222 @@ func (s *SlackSense) handleEvents(out chan<- *UnifiedInput) http.HandlerFunc {
 			return
 		}
 
-		// Handle event callbacks.
-		if envelope.Type == "event_callback" && envelope.Event != nil {
-			if envelope.Event.Type == "message" && envelope.Event.BotID == "" {
-				input := NewUnifiedInput(SourceSlack, envelope.Event.Text)
-				input.SourceMeta.Channel = "slack"
-				input.SourceMeta.Sender = envelope.Event.User
-				input.SourceMeta.Extra = map[string]string{
-					"channel":   envelope.Event.Channel,
-					"team":      envelope.TeamID,
-					"ts":        envelope.Event.TS,
-					"thread_ts": envelope.Event.ThreadTS,
-				}
-				input.ResponseChannel = envelope.Event.Channel
-
-				select {
-				case out <- input:
-				default:
-					// Drop if channel full.
-				}
-			}
+		s.dispatch(envelope, out)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// slackSignatureMaxAge is how old a signed Events API request may be.
+const slackSignatureMaxAge = 5 * time.Minute
+
+// slackSignatureValid checks an Events API request against the app's
+// signing secret: X-Slack-Signature is "v0=" + hex HMAC-SHA256 of
+// "v0:{X-Slack-Request-Timestamp}:{body}".
+func slackSignatureValid(secret string, h http.Header, body []byte, now time.Time) bool {
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return false // replayed
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", ts)
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(h.Get("X-Slack-Signature")), []byte(want))
+}
+
+// slackLeadingMention matches the bot mention that starts an app_mention.
+var slackLeadingMention = regexp.MustCompile(`^\s*<@[A-Z0-9]+>[\s:,]*`)
+
+// slackSeenTTL is how long an event ID is remembered for deduplication.
+const slackSeenTTL = 10 * time.Minute
+
+// dispatch turns a mention of the bot or a direct message into an input.
+// Everything else — bots (including ourselves), edits, joins, channel
+// chatter — is ignored. Mentions are answered in a thread; direct messages
+// in place, unless they were written in a thread.
+func (s *SlackSense) dispatch(envelope slackEventEnvelope, out chan<- *UnifiedInput) {
+	ev := envelope.Event
+	if envelope.Type != "event_callback" || ev == nil || ev.BotID != "" || ev.Subtype != "" || ev.User == "" {
+		return
+	}
+	text := ev.Text
+	thread := ev.ThreadTS
+	switch {
+	case ev.Type == "app_mention":
+		text = slackLeadingMention.ReplaceAllString(text, "")
+		if thread == "" {
+			thread = ev.TS
 		}
+	case ev.Type == "message" && ev.ChannelType == "im":
+	default:
+		return
+	}
+	if strings.TrimSpace(text) == "" || !s.firstSeen(envelope.EventID) {
+		return
+	}
 
-		w.WriteHeader(http.StatusOK)
+	input := NewUnifiedInput(SourceSlack, text)
+	input.SourceMeta.Channel = "slack"
+	input.SourceMeta.Sender = ev.User
+	input.SourceMeta.Extra = map[string]string{
+		"channel":   ev.Channel,
+		"team":      envelope.TeamID,
+		"ts":        ev.TS,
+		"thread_ts": ev.ThreadTS,
+	}
+	input.ResponseChannel = ev.Channel
+	if thread != "" {
+		input.ResponseChannel += ":" + thread
+	}
+
+	select {
+	case out <- input:
+	default:
+		// Drop if channel full.
 	}
 }
 
-// Send posts a message to a Slack channel via chat.postMessage API.
+// firstSeen records an event ID and reports whether it is new. Events
+// without an ID are always new.
+func (s *SlackSense) firstSeen(eventID string) bool {
+	if eventID == "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if _, ok := s.seen[eventID]; ok {
+		return false
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]time.Time)
+	}
+	for id, t := range s.seen {
+		if now.Sub(t) > slackSeenTTL {
+			delete(s.seen, id)
+		}
+	}
+	s.seen[eventID] = now
+	return true
+}
+
+// runSocketMode receives events over Socket Mode until ctx ends. Slack
+// closes connections now and then after a "disconnect" envelope; those
+// are reopened here, other failures are returned for the caller to retry.
+func (s *SlackSense) runSocketMode(ctx context.Context, out chan<- *UnifiedInput) error {
+	for {
+		err := s.socketSession(ctx, out)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// socketSession runs one Socket Mode connection. Every envelope is
+// acknowledged, or Slack delivers it again.
+func (s *SlackSense) socketSession(ctx context.Context, out chan<- *UnifiedInput) error {
+	var opened struct {
+		URL string `json:"url"`
+	}
+	if err := s.call(ctx, "apps.connections.open", s.config.AppToken, nil, &opened); err != nil {
+		return err
+	}
+	conn, err := dialWebSocket(ctx, opened.URL)
+	if err != nil {
+		return fmt.Errorf("slack: socket mode: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("slack: socket mode: %w", err)
+		}
+		var env slackSocketEnvelope
+		if err := json.Unmarshal(data, &env); err != nil {
+			continue
+		}
+		if env.EnvelopeID != "" {
+			ack, _ := json.Marshal(map[string]string{"envelope_id": env.EnvelopeID})
+			if err := conn.WriteText(ack); err != nil {
+				return fmt.Errorf("slack: socket mode: ack: %w", err)
+			}
+		}
+		switch env.Type {
+		case "disconnect":
+			return nil
+		case "events_api":
+			var envelope slackEventEnvelope
+			if err := json.Unmarshal(env.Payload, &envelope); err == nil {
+				s.dispatch(envelope, out)
+			}
+		}
+	}
+}
+
+// Send posts a message via chat.postMessage. target is a channel ID,
+// optionally followed by ":" and the timestamp of the thread to reply in.
 func (s *SlackSense) Send(ctx context.Context, target string, message string) error {
 	if message == "" {
 		return fmt.Errorf("slack: empty message")
 	}
-
-	payload, _ := json.Marshal(map[string]string{
-		"channel": target,
+	channel, thread, _ := strings.Cut(target, ":")
+	payload := map[string]string{
+		"channel": channel,
 		"text":    message,
-	})
+	}
+	if thread != "" {
+		payload["thread_ts"] = thread
+	}
+	return s.call(ctx, "chat.postMessage", s.config.BotToken, payload, nil)
+}
 
-	url := s.apiBase + "/chat.postMessage"
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+// call invokes a Slack Web API method with token and decodes the response
+// into result (may be nil). Responses with "ok": false are errors.
+func (s *SlackSense) call(ctx context.Context, method, token string, payload, result any) error {
+	var body io.Reader = http.NoBody
+	if payload != nil {
+		data, _ := json.Marshal(payload)
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiBase+"/"+method, body)
 	if err != nil {
 		return fmt.Errorf("slack: create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.config.BotToken)
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("slack: send: %w", err)
+		return fmt.Errorf("slack: %s: %w", method, err)
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	data, _ := io.ReadAll(resp.Body)
 
-	var result struct {
+	var status struct {
 		OK    bool   `json:"ok"`
 		Error string `json:"error,omitempty"`
 	}
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := json.Unmarshal(data, &status); err != nil {
 		return fmt.Errorf("slack: parse response: %w", err)
 	}
-	if !result.OK {
-		return fmt.Errorf("slack: API error: %s", result.Error)
+	if !status.OK {
+		return fmt.Errorf("slack: API error: %s", status.Error)
+	}
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("slack: parse response: %w", err)
+		}
 	}
 	return nil
 }
@@ -193,7 +371,7 @@ func (s *SlackSense) Addr() string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.listener != nil {
-		return s.listener.Addr().String()
+		return netaddr.String(s.listener)
 	}
 	return ""
 }
@@ -204,15 +382,26 @@ type slackEventEnvelope struct {
 	Type      string      `json:"type"`
 	Challenge string      `json:"challenge,omitempty"`
 	TeamID    string      `json:"team_id,omitempty"`
+	EventID   string      `json:"event_id,omitempty"`
 	Event     *slackEvent `json:"event,omitempty"`
 }
 
 type slackEvent struct {
-	Type     string `json:"type"`
-	User     string `json:"user"`
-	Text     string `json:"text"`
-	Channel  string `json:"channel"`
-	TS       string `json:"ts"`
-	ThreadTS string `json:"thread_ts,omitempty"`
-	BotID    string `json:"bot_id,omitempty"`
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	User        string `json:"user"`
+	Text        string `json:"text"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type,omitempty"` // "im" for direct messages
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+	BotID       string `json:"bot_id,omitempty"`
+}
+
+// slackSocketEnvelope wraps every Socket Mode message; events_api payloads
+// are Events API envelopes.
+type slackSocketEnvelope struct {
+	EnvelopeID string          `json:"envelope_id,omitempty"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
 }
@@ -1,10 +1,15 @@
 package senses
 
 import (
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 	"time"
 )
@@ -13,12 +18,27 @@ import (
 // SMTP sender — uses Go stdlib net/smtp.
 // ---------------------------------------------------------------------------
 
+// SMTP connection security (EmailConfig.SMTPSecurity).
+const (
+	SMTPStartTLS = "starttls" // upgrade with STARTTLS, failing when the server does not offer it (default)
+	SMTPTLS      = "tls"      // implicit TLS (default on port 465)
+	SMTPNone     = "none"     // plaintext, for a local relay only
+)
+
 // smtpConfig holds SMTP connection parameters.
 type smtpConfig struct {
 	Host     string // e.g., "smtp.gmail.com:587"
 	User     string
 	Password string
 	From     string
+	Security string // SMTPStartTLS, SMTPTLS or SMTPNone ("" = by port)
+	Auth     string // "plain" or "login" ("" = what the server offers)
+
+	// DKIM, if set, signs every message.
+	DKIM *dkimSigner
+
+	// TLSConfig overrides the TLS settings (for testing).
+	TLSConfig *tls.Config
 }
 
 // smtpMessage represents an outgoing email.
@@ -27,17 +47,42 @@ type smtpMessage struct {
 	Subject string
 	Body    string
 	ReplyTo string
+
+	// ContentType of Body ("" = UTF-8 plain text), e.g. multipart/mixed
+	// with attachments.
+	ContentType string
 }
 
-// sendSMTP sends an email via SMTP with STARTTLS.
+// sendSMTP sends an email via SMTP over TLS (implicit or STARTTLS),
+// authenticating when credentials are set.
 func sendSMTP(cfg smtpConfig, msg smtpMessage) error {
-	host, _, err := net.SplitHostPort(cfg.Host)
+	host, port, err := net.SplitHostPort(cfg.Host)
 	if err != nil {
 		host = cfg.Host
 	}
+	security := strings.ToLower(cfg.Security)
+	if security == "" {
+		security = SMTPStartTLS
+		if port == "465" {
+			security = SMTPTLS
+		}
+	}
+	tlsConfig := cfg.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: host}
+	}
 
 	// Connect to SMTP server.
-	conn, err := net.DialTimeout("tcp", cfg.Host, 30*time.Second)
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	switch security {
+	case SMTPTLS:
+		conn, err = tls.DialWithDialer(dialer, "tcp", cfg.Host, tlsConfig)
+	case SMTPStartTLS, SMTPNone:
+		conn, err = dialer.Dial("tcp", cfg.Host)
+	default:
+		return fmt.Errorf("smtp: unknown security %q (want starttls, tls or none)", cfg.Security)
+	}
 	if err != nil {
 		return fmt.Errorf("smtp dial: %w", err)
 	}
@@ -49,9 +94,10 @@ func sendSMTP(cfg smtpConfig, msg smtpMessage) error {
 	}
 	defer client.Close()
 
-	// STARTTLS if supported.
-	if ok, _ := client.Extension("STARTTLS"); ok {
-		tlsConfig := &tls.Config{ServerName: host}
+	if security == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp: %s does not offer STARTTLS (use security \"tls\", or \"none\" for a local relay)", host)
+		}
 		if err := client.StartTLS(tlsConfig); err != nil {
 			return fmt.Errorf("smtp starttls: %w", err)
 		}
@@ -59,51 +105,97 @@ func sendSMTP(cfg smtpConfig, msg smtpMessage) error {
 
 	// Authenticate if credentials provided.
 	if cfg.User != "" && cfg.Password != "" {
-		auth := smtp.PlainAuth("", cfg.User, cfg.Password, host)
+		auth, err := smtpAuth(client, cfg, host)
+		if err != nil {
+			return err
+		}
 		if err := client.Auth(auth); err != nil {
 			return fmt.Errorf("smtp auth: %w", err)
 		}
 	}
 
-	// Set sender.
-	if err := client.Mail(cfg.From); err != nil {
-		return fmt.Errorf("smtp mail: %w", err)
+	if err := deliverSMTP(client, cfg.From, msg, cfg.DKIM); err != nil {
+		return err
 	}
+	return client.Quit()
+}
 
-	// Set recipients.
-	recipients := strings.Split(msg.To, ",")
-	for _, rcpt := range recipients {
-		rcpt = strings.TrimSpace(rcpt)
-		if rcpt == "" {
-			continue
-		}
-		if err := client.Rcpt(rcpt); err != nil {
-			return fmt.Errorf("smtp rcpt %s: %w", rcpt, err)
+// smtpAuth returns the configured mechanism, or PLAIN unless the server
+// only offers LOGIN. Both refuse to send credentials in the clear to
+// anything but localhost.
+func smtpAuth(client *smtp.Client, cfg smtpConfig, host string) (smtp.Auth, error) {
+	mech := strings.ToLower(cfg.Auth)
+	if mech == "" {
+		mech = "plain"
+		if _, offered := client.Extension("AUTH"); !hasWord(offered, "PLAIN") && hasWord(offered, "LOGIN") {
+			mech = "login"
 		}
 	}
+	switch mech {
+	case "plain":
+		return smtp.PlainAuth("", cfg.User, cfg.Password, host), nil
+	case "login":
+		return &loginAuth{user: cfg.User, password: cfg.Password, host: host}, nil
+	}
+	return nil, fmt.Errorf("smtp: unknown auth %q (want plain or login)", cfg.Auth)
+}
 
-	// Write message body with headers.
-	wc, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("smtp data: %w", err)
+func hasWord(list, word string) bool {
+	for _, w := range strings.Fields(list) {
+		if strings.EqualFold(w, word) {
+			return true
+		}
 	}
+	return false
+}
 
-	headers := buildHeaders(cfg.From, msg)
-	body := headers + "\r\n" + msg.Body + "\r\n"
+// loginAuth implements AUTH LOGIN, which net/smtp lacks.
+type loginAuth struct {
+	user, password, host string
+}
 
-	if _, err := wc.Write([]byte(body)); err != nil {
-		wc.Close()
-		return fmt.Errorf("smtp write: %w", err)
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
 	}
-	if err := wc.Close(); err != nil {
-		return fmt.Errorf("smtp close data: %w", err)
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
 	}
+	return "LOGIN", nil, nil
+}
 
-	return client.Quit()
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch prompt := strings.ToLower(strings.TrimSpace(string(fromServer))); {
+	case strings.HasPrefix(prompt, "username"):
+		return []byte(a.user), nil
+	case strings.HasPrefix(prompt, "password"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+// smtpPermanent reports whether err is a permanent (5xx) SMTP failure:
+// sending the same message again will not help.
+func smtpPermanent(err error) bool {
+	var te *textproto.Error
+	return errors.As(err, &te) && te.Code >= 500
 }
 
 // sendSMTPDirect sends using a pre-connected smtp.Client (for testing with mock servers).
 func sendSMTPDirect(client *smtp.Client, from string, msg smtpMessage) error {
+	return deliverSMTP(client, from, msg, nil)
+}
+
+// deliverSMTP sends msg on an open session, DKIM-signed when dkim is set.
+func deliverSMTP(client *smtp.Client, from string, msg smtpMessage, dkim *dkimSigner) error {
 	if err := client.Mail(from); err != nil {
 		return fmt.Errorf("smtp mail: %w", err)
 	}
@@ -125,13 +217,24 @@ func sendSMTPDirect(client *smtp.Client, from string, msg smtpMessage) error {
 	}
 
 	headers := buildHeaders(from, msg)
+	if dkim != nil {
+		sig, err := dkim.sign(headers, msg.Body+"\r\n", time.Now())
+		if err != nil {
+			wc.Close()
+			return fmt.Errorf("dkim: %w", err)
+		}
+		headers = sig + headers
+	}
 	body := headers + "\r\n" + msg.Body + "\r\n"
 
 	if _, err := wc.Write([]byte(body)); err != nil {
 		wc.Close()
 		return fmt.Errorf("smtp write: %w", err)
 	}
-	return wc.Close()
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("smtp close data: %w", err)
+	}
+	return nil
 }
 
 // buildHeaders constructs RFC 2822 email headers.
@@ -146,7 +249,28 @@ func buildHeaders(from string, msg smtpMessage) string {
 		b.WriteString(fmt.Sprintf("In-Reply-To: %s\r\n", msg.ReplyTo))
 	}
 	b.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z)))
+	b.WriteString(fmt.Sprintf("Message-ID: %s\r\n", newMessageID(from)))
 	b.WriteString("MIME-Version: 1.0\r\n")
-	b.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	if msg.ContentType != "" {
+		b.WriteString(fmt.Sprintf("Content-Type: %s\r\n", msg.ContentType))
+	} else {
+		b.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	}
 	return b.String()
 }
+
+// newMessageID returns a unique Message-ID in the sender's domain; mail
+// providers reject or spam-file messages without one.
+func newMessageID(from string) string {
+	domain := "localhost"
+	addr := from
+	if a, err := mail.ParseAddress(from); err == nil {
+		addr = a.Address
+	}
+	if i := strings.LastIndex(addr, "@"); i >= 0 && i < len(addr)-1 {
+		domain = addr[i+1:]
+	}
+	var b [8]byte
+	rand.Read(b[:])
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b[:]), domain)
+}
@@ -0,0 +1,208 @@
+package senses
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// ActiveHours — daily window during which the agent is fully awake.
+// ---------------------------------------------------------------------------
+
+// ActiveHours is a daily "HH:MM-HH:MM" window. Windows may wrap midnight
+// (e.g. "22:00-06:00" for night-shift users). A nil *ActiveHours means the
+// agent is always active.
+type ActiveHours struct {
+	start int // minutes since midnight
+	end   int // minutes since midnight (exclusive)
+	loc   *time.Location
+}
+
+// ParseActiveHours parses a spec like "08:00-22:00". An empty spec returns
+// (nil, nil) — no standby. Times are interpreted in loc (time.Local if nil).
+func ParseActiveHours(spec string, loc *time.Location) (*ActiveHours, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return nil, fmt.Errorf("active hours %q: want HH:MM-HH:MM", spec)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return nil, fmt.Errorf("active hours %q: %w", spec, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return nil, fmt.Errorf("active hours %q: %w", spec, err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("active hours %q: empty window", spec)
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &ActiveHours{start: start, end: end, loc: loc}, nil
+}
+
+// parseClock parses "HH:MM" (or "HH") into minutes since midnight.
+func parseClock(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	hh, mm, _ := strings.Cut(s, ":")
+	h, err := strconv.Atoi(hh)
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	m := 0
+	if mm != "" {
+		m, err = strconv.Atoi(mm)
+		if err != nil || m < 0 || m > 59 {
+			return 0, fmt.Errorf("invalid minute in %q", s)
+		}
+	}
+	if h == 24 && m != 0 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// IsActive reports whether t falls inside the window.
+func (h *ActiveHours) IsActive(t time.Time) bool {
+	if h == nil {
+		return true
+	}
+	t = t.In(h.loc)
+	min := t.Hour()*60 + t.Minute()
+	if h.start < h.end {
+		return min >= h.start && min < h.end
+	}
+	// Window wraps midnight.
+	return min >= h.start || min < h.end
+}
+
+// String returns the window in "HH:MM-HH:MM" form.
+func (h *ActiveHours) String() string {
+	if h == nil {
+		return "always"
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", h.start/60, h.start%60, h.end/60, h.end%60)
+}
+
+// ---------------------------------------------------------------------------
+// Standby — holds non-critical inputs outside active hours.
+// ---------------------------------------------------------------------------
+
+// DefaultStandbyQueueSize caps how many inputs are held overnight.
+const DefaultStandbyQueueSize = 500
+
+// Standby gates the daemon outside active hours: only critical-priority
+// inputs pass through, everything else is held until the window opens.
+// A nil *Standby admits everything.
+type Standby struct {
+	hours *ActiveHours
+
+	mu      sync.Mutex
+	held    []*UnifiedInput
+	maxHeld int
+	dropped int
+	manual  bool // operator-forced standby, regardless of active hours
+
+	// now is overridable for tests.
+	now func() time.Time
+}
+
+// NewStandby creates a Standby gate for the given active hours.
+func NewStandby(hours *ActiveHours) *Standby {
+	return &Standby{
+		hours:   hours,
+		maxHeld: DefaultStandbyQueueSize,
+		now:     time.Now,
+	}
+}
+
+// Hours returns the configured active window (nil = always active).
+func (s *Standby) Hours() *ActiveHours {
+	if s == nil {
+		return nil
+	}
+	return s.hours
+}
+
+// Active reports whether the agent is currently inside active hours and
+// not paused by the operator.
+func (s *Standby) Active() bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	manual := s.manual
+	s.mu.Unlock()
+	return !manual && s.hours.IsActive(s.now())
+}
+
+// SetPaused forces standby on (true) or returns control to active hours
+// (false). Held inputs are released by the next Release call.
+func (s *Standby) SetPaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manual = paused
+}
+
+// Paused is the inverse of Active, suitable for sense Paused hooks.
+func (s *Standby) Paused() bool { return !s.Active() }
+
+// Admit returns true if input should be processed now. Outside active
+// hours, non-critical inputs are held and false is returned. When the
+// queue is full the oldest held input is dropped.
+func (s *Standby) Admit(input *UnifiedInput) bool {
+	if s == nil || input == nil || input.Priority >= PriorityCritical || s.Active() {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.held) >= s.maxHeld {
+		s.held = s.held[1:]
+		s.dropped++
+	}
+	s.held = append(s.held, input)
+	return false
+}
+
+// Release returns and clears all held inputs once the agent is active
+// again. Outside active hours it returns nil.
+func (s *Standby) Release() []*UnifiedInput {
+	if s == nil || !s.Active() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	held := s.held
+	s.held = nil
+	return held
+}
+
+// Pending returns the number of held inputs.
+func (s *Standby) Pending() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.held)
+}
+
+// Dropped returns how many held inputs were discarded because the queue
+// was full.
+func (s *Standby) Dropped() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
@@ -0,0 +1,140 @@
+package senses
+
+import (
+	"testing"
+	"time"
+)
+
+func at(hour, min int) time.Time {
+	return time.Date(2025, 1, 10, hour, min, 0, 0, time.UTC)
+}
+
+func TestParseActiveHours(t *testing.T) {
+	h, err := ParseActiveHours("08:00-22:30", time.UTC)
+	if err != nil {
+		t.Fatalf("ParseActiveHours: %v", err)
+	}
+	if h.String() != "08:00-22:30" {
+		t.Errorf("String = %q", h.String())
+	}
+
+	if h, err := ParseActiveHours("", nil); h != nil || err != nil {
+		t.Errorf("empty spec = (%v, %v), want (nil, nil)", h, err)
+	}
+
+	for _, bad := range []string{"8-8", "25:00-06:00", "08:00", "08:61-09:00", "a-b"} {
+		if _, err := ParseActiveHours(bad, time.UTC); err == nil {
+			t.Errorf("ParseActiveHours(%q) should fail", bad)
+		}
+	}
+}
+
+func TestActiveHours_IsActive(t *testing.T) {
+	day, _ := ParseActiveHours("08:00-22:00", time.UTC)
+	night, _ := ParseActiveHours("22:00-06:00", time.UTC)
+
+	tests := []struct {
+		h    *ActiveHours
+		t    time.Time
+		want bool
+	}{
+		{day, at(7, 59), false},
+		{day, at(8, 0), true},
+		{day, at(21, 59), true},
+		{day, at(22, 0), false},
+		{night, at(23, 0), true},
+		{night, at(3, 0), true},
+		{night, at(12, 0), false},
+		{nil, at(3, 0), true},
+	}
+	for _, tt := range tests {
+		if got := tt.h.IsActive(tt.t); got != tt.want {
+			t.Errorf("%s.IsActive(%s) = %v, want %v", tt.h, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestStandby_HoldsNonCriticalAndReleases(t *testing.T) {
+	hours, _ := ParseActiveHours("08:00-22:00", time.UTC)
+	s := NewStandby(hours)
+	now := at(2, 0)
+	s.now = func() time.Time { return now }
+
+	if !s.Paused() {
+		t.Fatal("should be paused at 02:00")
+	}
+
+	normal := NewFromText("summarize my inbox")
+	if s.Admit(normal) {
+		t.Error("normal input should be held outside active hours")
+	}
+	critical := NewFromText("server down")
+	critical.Priority = PriorityCritical
+	if !s.Admit(critical) {
+		t.Error("critical input should wake the agent")
+	}
+	if s.Pending() != 1 {
+		t.Fatalf("Pending = %d, want 1", s.Pending())
+	}
+	if held := s.Release(); held != nil {
+		t.Errorf("Release outside active hours = %d inputs, want none", len(held))
+	}
+
+	now = at(8, 5)
+	held := s.Release()
+	if len(held) != 1 || held[0] != normal {
+		t.Fatalf("Release = %v, want the held input", held)
+	}
+	if s.Pending() != 0 {
+		t.Errorf("Pending after release = %d", s.Pending())
+	}
+	if !s.Admit(NewFromText("hi")) {
+		t.Error("inputs should pass during active hours")
+	}
+}
+
+func TestStandby_QueueCap(t *testing.T) {
+	hours, _ := ParseActiveHours("08:00-22:00", time.UTC)
+	s := NewStandby(hours)
+	s.now = func() time.Time { return at(23, 0) }
+	s.maxHeld = 2
+
+	first := NewFromText("1")
+	s.Admit(first)
+	s.Admit(NewFromText("2"))
+	s.Admit(NewFromText("3"))
+
+	if s.Pending() != 2 || s.Dropped() != 1 {
+		t.Errorf("Pending=%d Dropped=%d, want 2 and 1", s.Pending(), s.Dropped())
+	}
+}
+
+func TestStandby_NilAlwaysActive(t *testing.T) {
+	var s *Standby
+	if !s.Active() || s.Paused() || !s.Admit(NewFromText("x")) {
+		t.Error("nil Standby should always be active")
+	}
+	if NewStandby(nil).Paused() {
+		t.Error("Standby without active hours should never pause")
+	}
+}
+
+func TestStandby_SetPaused(t *testing.T) {
+	s := NewStandby(nil)
+	s.SetPaused(true)
+	if !s.Paused() {
+		t.Fatal("manual pause should put the agent in standby")
+	}
+	in := NewFromText("later")
+	if s.Admit(in) {
+		t.Error("input should be held while paused")
+	}
+	if held := s.Release(); held != nil {
+		t.Error("Release while paused should return nothing")
+	}
+
+	s.SetPaused(false)
+	if held := s.Release(); len(held) != 1 || held[0] != in {
+		t.Errorf("Release after resume = %v, want the held input", held)
+	}
+}
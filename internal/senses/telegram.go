@@ -6,9 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // TelegramConfig holds Telegram bot configuration.
@@ -69,11 +73,24 @@ func (s *TelegramSense) Start(ctx context.Context, out chan<- *UnifiedInput) err
 	return s.poll(ctx, out)
 }
 
+// telegramRetryDelay is how long polling waits after a failed getUpdates.
+var telegramRetryDelay = 2 * time.Second
+
 // poll implements the Telegram getUpdates long-polling loop.
 func (s *TelegramSense) poll(ctx context.Context, out chan<- *UnifiedInput) error {
 	offset := 0
 	client := &http.Client{Timeout: s.config.PollTimeout + 5*time.Second}
 
+	// retry waits before the next poll; false means ctx is done.
+	retry := func() bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(telegramRetryDelay):
+			return true
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -81,8 +98,8 @@ func (s *TelegramSense) poll(ctx context.Context, out chan<- *UnifiedInput) erro
 		default:
 		}
 
-		url := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?offset=%d&timeout=%d",
-			s.config.Token, offset, int(s.config.PollTimeout.Seconds()))
+		url := fmt.Sprintf("%s/getUpdates?offset=%d&timeout=%d",
+			s.apiBase, offset, int(s.config.PollTimeout.Seconds()))
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
@@ -91,21 +108,31 @@ func (s *TelegramSense) poll(ctx context.Context, out chan<- *UnifiedInput) erro
 
 		resp, err := client.Do(req)
 		if err != nil {
-			if ctx.Err() != nil {
+			if ctx.Err() != nil || !retry() {
 				return ctx.Err()
 			}
-			time.Sleep(2 * time.Second) // Backoff on error.
 			continue
 		}
 
 		body, err := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		if err != nil {
+			if !retry() {
+				return ctx.Err()
+			}
 			continue
 		}
 
 		var result telegramResponse
-		if err := json.Unmarshal(body, &result); err != nil {
+		if err := json.Unmarshal(body, &result); err != nil || !result.OK {
+			// A rejected token will not fix itself: fail so the
+			// supervisor records it and backs off.
+			if result.ErrorCode == http.StatusUnauthorized {
+				return fmt.Errorf("telegram: getUpdates: API error %d: %s", result.ErrorCode, result.Description)
+			}
+			if !retry() {
+				return ctx.Err()
+			}
 			continue
 		}
 
@@ -115,8 +142,8 @@ func (s *TelegramSense) poll(ctx context.Context, out chan<- *UnifiedInput) erro
 				continue
 			}
 
-			// Whitelist check.
-			if len(s.config.AllowedIDs) > 0 && !s.isAllowed(update.Message.From.ID) {
+			// Whitelist check: the sender or, for groups, the chat.
+			if len(s.config.AllowedIDs) > 0 && !s.isAllowed(update.Message.From.ID) && !s.isAllowed(update.Message.Chat.ID) {
 				offset = update.UpdateID + 1
 				continue
 			}
@@ -150,12 +177,17 @@ func (s *TelegramSense) Send(ctx context.Context, target string, message string)
 		return fmt.Errorf("telegram: empty message")
 	}
 
-	// Split into chunks for Telegram's 4096-char limit.
+	// Split into chunks for Telegram's 4096-char limit, never inside a
+	// UTF-8 sequence.
 	for len(message) > 0 {
 		chunk := message
 		if len(chunk) > telegramMaxMessageLen {
-			chunk = message[:telegramMaxMessageLen]
-			message = message[telegramMaxMessageLen:]
+			n := telegramMaxMessageLen
+			for n > 0 && !utf8.RuneStart(message[n]) {
+				n--
+			}
+			chunk = message[:n]
+			message = message[n:]
 		} else {
 			message = ""
 		}
@@ -181,6 +213,11 @@ func (s *TelegramSense) sendChunk(ctx context.Context, chatID, text string) erro
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	return s.do(req)
+}
+
+// do sends a Bot API request and checks its result.
+func (s *TelegramSense) do(req *http.Request) error {
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("telegram: send: %w", err)
@@ -203,6 +240,67 @@ func (s *TelegramSense) sendChunk(ctx context.Context, chatID, text string) erro
 	return nil
 }
 
+// Bot API upload limits: photos are recompressed and capped lower than
+// documents.
+const (
+	telegramMaxPhotoBytes = 10 << 20
+	telegramMaxFileBytes  = 50 << 20
+)
+
+// AttachmentCaps reports that images are sent as photos and other files as
+// documents, up to the Bot API upload limit.
+func (s *TelegramSense) AttachmentCaps() AttachmentCaps {
+	return AttachmentCaps{Images: true, Files: true, MaxBytes: telegramMaxFileBytes}
+}
+
+// SendAttachments sends message, then each file: images as photos (larger
+// ones as documents), anything else as a document.
+func (s *TelegramSense) SendAttachments(ctx context.Context, target, message string, atts []Attachment) error {
+	if message != "" {
+		if err := s.Send(ctx, target, message); err != nil {
+			return err
+		}
+	}
+	for _, att := range atts {
+		if err := s.sendFile(ctx, target, att); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendFile uploads att with sendPhoto or sendDocument.
+func (s *TelegramSense) sendFile(ctx context.Context, chatID string, att Attachment) error {
+	method, field := "/sendDocument", "document"
+	if strings.HasPrefix(att.Type, "image/") && att.Size <= telegramMaxPhotoBytes {
+		method, field = "/sendPhoto", "photo"
+	}
+	f, err := os.Open(att.Path)
+	if err != nil {
+		return fmt.Errorf("telegram: attachment %s: %w", att.Name, err)
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	w.WriteField("chat_id", chatID)
+	part, err := w.CreateFormFile(field, att.Name)
+	if err != nil {
+		return fmt.Errorf("telegram: attachment %s: %w", att.Name, err)
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("telegram: attachment %s: %w", att.Name, err)
+	}
+	w.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiBase+method, &buf)
+	if err != nil {
+		return fmt.Errorf("telegram: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return s.do(req)
+}
+
 func (s *TelegramSense) Stop() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -225,8 +323,10 @@ func (s *TelegramSense) isAllowed(userID int64) bool {
 // --- Telegram API types (minimal subset) ---
 
 type telegramResponse struct {
-	OK     bool             `json:"ok"`
-	Result []telegramUpdate `json:"result"`
+	OK          bool             `json:"ok"`
+	Result      []telegramUpdate `json:"result"`
+	ErrorCode   int              `json:"error_code,omitempty"`
+	Description string           `json:"description,omitempty"`
 }
 
 type telegramUpdate struct {
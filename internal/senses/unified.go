@@ -26,7 +26,9 @@ const (
 	SourceSlack    SourceType = "SLACK"
 	SourceDiscord  SourceType = "DISCORD"
 	SourceEmail    SourceType = "EMAIL"
+	SourceSignal   SourceType = "SIGNAL"
 	SourceAPI      SourceType = "API"
+	SourceVoice    SourceType = "VOICE"
 )
 
 // ---------------------------------------------------------------------------
@@ -0,0 +1,294 @@
+package senses
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// ---------------------------------------------------------------------------
+// VoiceConfig — configuration for the voice input sense.
+// ---------------------------------------------------------------------------
+
+// VoiceConfig holds configuration for the VoiceSense.
+type VoiceConfig struct {
+	// STT transcribes the audio: an OpenAI-compatible Whisper endpoint
+	// (the "openai" speech provider with a base URL) or a local engine.
+	STT brain.SpeechToText
+
+	// InboxDir, if set, is watched for audio files (AudioExtensions); each
+	// new file is transcribed into a task. Empty = uploads only.
+	InboxDir string
+
+	// PollInterval is how often InboxDir is scanned. Default: 5s.
+	PollInterval time.Duration
+
+	// Language is an ISO 639-1 hint passed to the transcriber. Empty =
+	// auto-detect.
+	Language string
+
+	// Paused, if set, pauses the inbox scan (see FileWatcherConfig).
+	Paused func() bool
+
+	// Limits caps the size of a recording at MaxAttachmentBytes.
+	Limits Limits
+}
+
+// AudioExtensions are the audio files the voice inbox picks up.
+var AudioExtensions = []string{".flac", ".m4a", ".mp3", ".mp4", ".mpeg", ".mpga", ".oga", ".ogg", ".opus", ".wav", ".webm"}
+
+// Extra keys describing a transcribed recording.
+const (
+	ExtraLanguage       = "language"        // ISO 639-1 language of the recording
+	ExtraLanguageSource = "language_source" // "hint", "detected" (by the transcriber) or "guessed" (from the text)
+	ExtraTranscriber    = "transcriber"     // speech backend, e.g. "openai"
+)
+
+// ---------------------------------------------------------------------------
+// VoiceSense — audio uploads and an audio inbox, transcribed to text.
+// ---------------------------------------------------------------------------
+
+// VoiceSense turns recordings into inputs with SourceType SourceVoice: it
+// watches an audio inbox directory, and the API sense hands it uploads
+// (see APISense.SetVoice). The transcript is the payload.
+type VoiceSense struct {
+	cfg VoiceConfig
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewVoiceSense creates a VoiceSense with the given config.
+func NewVoiceSense(cfg VoiceConfig) *VoiceSense {
+	return &VoiceSense{cfg: cfg}
+}
+
+// Name returns the sense name.
+func (v *VoiceSense) Name() string { return "Voice" }
+
+// Start watches the audio inbox, if one is configured, and emits a voice
+// input for each new recording. Like the file watcher, recordings already
+// there are left alone. Start blocks until ctx is cancelled.
+func (v *VoiceSense) Start(ctx context.Context, out chan<- *UnifiedInput) error {
+	v.mu.Lock()
+	ctx, v.cancel = context.WithCancel(ctx)
+	v.mu.Unlock()
+
+	if v.cfg.InboxDir == "" {
+		<-ctx.Done()
+		return nil
+	}
+	limits := v.cfg.Limits.withDefaults()
+	limits.MaxInboxFileBytes = limits.MaxAttachmentBytes
+	files := make(chan *UnifiedInput)
+	watcher := NewFileWatcherSense(FileWatcherConfig{
+		WatchDir:     v.cfg.InboxDir,
+		PollInterval: v.cfg.PollInterval,
+		Extensions:   AudioExtensions,
+		Paused:       v.cfg.Paused,
+		Limits:       limits,
+	})
+	errc := make(chan error, 1)
+	go func() { errc <- watcher.Start(ctx, files) }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errc:
+			return err
+		case file := <-files:
+			input, err := v.Transcribe(ctx, []byte(file.Payload), file.SourceMeta.Path, "")
+			if err != nil {
+				log.Printf("[voice] %s: %v", file.SourceMeta.Path, err)
+				continue
+			}
+			input.SourceMeta.Channel = "voice_inbox"
+			input.SourceMeta.Path = file.SourceMeta.Path
+			input.SourceMeta.Timestamp = file.SourceMeta.Timestamp
+			select {
+			case <-ctx.Done():
+				return nil
+			case out <- input:
+			}
+		}
+	}
+}
+
+// Send is a no-op — the voice sense is input-only.
+func (v *VoiceSense) Send(_ context.Context, _ string, _ string) error {
+	return nil
+}
+
+// Stop stops watching the inbox.
+func (v *VoiceSense) Stop() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cancel != nil {
+		v.cancel()
+	}
+	return nil
+}
+
+// Transcribe turns a recording into a voice input. filename hints the
+// audio format; language is an ISO 639-1 hint (empty = the configured
+// one, or auto-detect). The input's ExtraLanguage says which language was
+// heard and ExtraLanguageSource how it was found.
+func (v *VoiceSense) Transcribe(ctx context.Context, audio []byte, filename, language string) (*UnifiedInput, error) {
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("empty audio")
+	}
+	if language == "" {
+		language = v.cfg.Language
+	}
+	tr, err := v.cfg.STT.Transcribe(ctx, brain.TranscribeRequest{Audio: audio, Filename: filename, Language: language})
+	if err != nil {
+		return nil, fmt.Errorf("transcribe: %w", err)
+	}
+	if tr.Text == "" {
+		return nil, fmt.Errorf("no speech found")
+	}
+
+	input := NewUnifiedInput(SourceVoice, tr.Text)
+	input.SourceMeta.Channel = "voice"
+	input.SourceMeta.Extra = map[string]string{ExtraTranscriber: tr.Provider}
+	if filename != "" {
+		input.SourceMeta.Extra["filename"] = filepath.Base(filename)
+	}
+	switch {
+	case language != "":
+		input.SourceMeta.Extra[ExtraLanguage] = language
+		input.SourceMeta.Extra[ExtraLanguageSource] = "hint"
+	case tr.Language != "":
+		input.SourceMeta.Extra[ExtraLanguage] = tr.Language
+		input.SourceMeta.Extra[ExtraLanguageSource] = "detected"
+	default:
+		if lang := DetectLanguage(tr.Text); lang != "" {
+			input.SourceMeta.Extra[ExtraLanguage] = lang
+			input.SourceMeta.Extra[ExtraLanguageSource] = "guessed"
+		}
+	}
+	return input, nil
+}
+
+// SetVoice enables POST /voice: audio uploaded as the request body (the
+// Content-Type names the format) or as the "file" field of a multipart
+// form is transcribed by v and becomes a task. A "language" query or form
+// field is a hint, and "sender" and "session_id" work as for /input. It
+// must be called before Start.
+func (a *APISense) SetVoice(v *VoiceSense) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.voice = v
+}
+
+// handleVoice handles POST /voice.
+func (a *APISense) handleVoice(w http.ResponseWriter, r *http.Request) {
+	if a.rejectMaintenance(w) {
+		return
+	}
+	// Recordings are held to the attachment quota, not the text one.
+	limits := a.limits.withDefaults()
+	limits.MaxPayloadBytes = limits.MaxAttachmentBytes
+
+	var audio []byte
+	filename := "audio" + audioExtension(r.Header.Get("Content-Type"))
+	field := r.URL.Query().Get
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if limits.MaxPayloadBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, limits.MaxPayloadBytes+1<<20) // room for the form fields
+		}
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			writeVoiceError(w, r, err, limits, a.Name())
+			return
+		}
+		defer f.Close()
+		if audio, err = io.ReadAll(f); err != nil {
+			writeVoiceError(w, r, err, limits, a.Name())
+			return
+		}
+		if limits.MaxPayloadBytes > 0 && int64(len(audio)) > limits.MaxPayloadBytes {
+			writeLimitError(w, limits.payloadViolation(a.Name(), r.RemoteAddr, int64(len(audio))))
+			return
+		}
+		filename = hdr.Filename
+		field = r.FormValue // form fields, then the query
+	} else {
+		body, err := limits.readBody(w, r, a.Name())
+		if err != nil {
+			writeLimitError(w, err)
+			return
+		}
+		audio = body
+	}
+	if len(audio) == 0 {
+		http.Error(w, `{"error":"audio required"}`, http.StatusBadRequest)
+		return
+	}
+
+	input, err := a.voice.Transcribe(r.Context(), audio, filename, field("language"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	req := apiRequest{Sender: field("sender"), SessionID: field("session_id")}
+	base := a.buildInput(r, req)
+	input.SourceMeta.Sender = base.SourceMeta.Sender
+	input.SessionID = base.SessionID
+
+	select {
+	case a.out <- input:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{
+			"input_id": input.InputID,
+			"status":   "accepted",
+			"text":     input.Payload,
+			"language": input.SourceMeta.Extra[ExtraLanguage],
+		})
+	default:
+		http.Error(w, `{"error":"pipeline busy"}`, http.StatusServiceUnavailable)
+	}
+}
+
+// writeVoiceError answers a multipart upload that could not be read.
+func writeVoiceError(w http.ResponseWriter, r *http.Request, err error, limits Limits, sense string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeLimitError(w, limits.payloadViolation(sense, r.RemoteAddr, -1))
+		return
+	}
+	http.Error(w, `{"error":"audio file required in the \"file\" field"}`, http.StatusBadRequest)
+}
+
+// audioExtension returns the file extension for an audio Content-Type, so
+// the transcriber can tell the format of a raw upload.
+func audioExtension(contentType string) string {
+	mt, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mt)) {
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/m4a", "audio/x-m4a":
+		return ".m4a"
+	case "audio/ogg", "audio/opus":
+		return ".ogg"
+	case "audio/webm":
+		return ".webm"
+	case "audio/flac", "audio/x-flac":
+		return ".flac"
+	}
+	return ".wav"
+}
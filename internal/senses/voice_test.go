@@ -0,0 +1,125 @@
+package senses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// detectingSTT reports a fixed language, like Whisper's auto-detect.
+type detectingSTT struct{ brain.FakeSpeech }
+
+func (d detectingSTT) Transcribe(ctx context.Context, req brain.TranscribeRequest) (*brain.Transcript, error) {
+	tr, err := d.FakeSpeech.Transcribe(ctx, req)
+	if err == nil && req.Language == "" {
+		tr.Language = "de"
+	}
+	return tr, err
+}
+
+func TestVoiceSense_Transcribe(t *testing.T) {
+	v := NewVoiceSense(VoiceConfig{STT: detectingSTT{}})
+	in, err := v.Transcribe(context.Background(), []byte("Guten Morgen"), "/tmp/memo.ogg", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.SourceType != SourceVoice || in.Payload != "Guten Morgen" {
+		t.Errorf("input = %+v", in)
+	}
+	extra := in.SourceMeta.Extra
+	if extra[ExtraLanguage] != "de" || extra[ExtraLanguageSource] != "detected" || extra[ExtraTranscriber] != "fake" || extra["filename"] != "memo.ogg" {
+		t.Errorf("extra = %v", extra)
+	}
+
+	if in, _ := v.Transcribe(context.Background(), []byte("Guten Morgen"), "", "fr"); in.SourceMeta.Extra[ExtraLanguageSource] != "hint" || in.SourceMeta.Extra[ExtraLanguage] != "fr" {
+		t.Errorf("hinted extra = %v", in.SourceMeta.Extra)
+	}
+
+	// Without the transcriber's word, the language is guessed from the text.
+	v = NewVoiceSense(VoiceConfig{STT: brain.FakeSpeech{}})
+	in, _ = v.Transcribe(context.Background(), []byte("the meeting is moved to the afternoon and it is in the big room"), "", "")
+	if in.SourceMeta.Extra[ExtraLanguage] != "en" || in.SourceMeta.Extra[ExtraLanguageSource] != "guessed" {
+		t.Errorf("guessed extra = %v", in.SourceMeta.Extra)
+	}
+	if _, err := v.Transcribe(context.Background(), []byte("  "), "", ""); err == nil {
+		t.Error("silence: expected an error")
+	}
+}
+
+func TestAPISense_Voice(t *testing.T) {
+	sense := NewAPISense("127.0.0.1:0")
+	sense.SetVoice(NewVoiceSense(VoiceConfig{STT: detectingSTT{}}))
+	api, out, _ := startAPISense(t, sense)
+
+	post := func(contentType string, body []byte, query string) (int, map[string]string) {
+		t.Helper()
+		resp, err := http.Post("http://"+api.Addr()+"/voice"+query, contentType, bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var res map[string]string
+		json.NewDecoder(resp.Body).Decode(&res)
+		return resp.StatusCode, res
+	}
+
+	code, res := post("audio/ogg", []byte("call mom"), "?language=en")
+	if code != http.StatusAccepted || res["text"] != "call mom" || res["language"] != "en" {
+		t.Fatalf("raw upload: %d %v", code, res)
+	}
+	if in := <-out; in.SourceType != SourceVoice || in.SourceMeta.Sender != "api_user" || in.SourceMeta.Extra["filename"] != "audio.ogg" {
+		t.Errorf("input = %+v", in)
+	}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	mw.WriteField("sender", "alice")
+	fw, _ := mw.CreateFormFile("file", "memo.m4a")
+	fw.Write([]byte("buy milk"))
+	mw.Close()
+	code, res = post(mw.FormDataContentType(), form.Bytes(), "")
+	if code != http.StatusAccepted || res["language"] != "de" {
+		t.Fatalf("multipart upload: %d %v", code, res)
+	}
+	if in := <-out; in.Payload != "buy milk" || in.SourceMeta.Sender != "alice" || in.SourceMeta.Extra[ExtraLanguageSource] != "detected" {
+		t.Errorf("input = %+v", in)
+	}
+
+	if code, _ := post("audio/wav", nil, ""); code != http.StatusBadRequest {
+		t.Errorf("empty upload: status = %d, want 400", code)
+	}
+}
+
+func TestVoiceSense_Inbox(t *testing.T) {
+	dir := t.TempDir()
+	v := NewVoiceSense(VoiceConfig{STT: brain.FakeSpeech{}, InboxDir: dir, PollInterval: 20 * time.Millisecond})
+	out := make(chan *UnifiedInput, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Start(ctx, out)
+	time.Sleep(50 * time.Millisecond) // let the first scan seed
+
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not audio"), 0o644)
+	os.WriteFile(filepath.Join(dir, "memo.wav"), []byte("water the plants"), 0o644)
+	select {
+	case in := <-out:
+		if in.Payload != "water the plants" || in.SourceType != SourceVoice || in.SourceMeta.Channel != "voice_inbox" {
+			t.Errorf("input = %+v", in)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the recording")
+	}
+	select {
+	case in := <-out:
+		t.Errorf("unexpected input %+v", in)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/overhuman/overhuman/internal/netaddr"
 )
 
 // WebhookSense implements the Sense interface for incoming HTTP POST webhooks.
@@ -23,6 +24,9 @@ type WebhookSense struct {
 	mu       sync.Mutex
 	listener net.Listener
 	stopped  bool
+
+	// limits caps request bodies (zero value = default quotas).
+	limits Limits
 }
 
 // NewWebhookSense creates a webhook receiver.
@@ -37,6 +41,13 @@ func NewWebhookSense(addr, path string) *WebhookSense {
 	}
 }
 
+// SetLimits configures the input quotas. It must be called before Start.
+func (w *WebhookSense) SetLimits(l Limits) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.limits = l
+}
+
 // Name returns the sense name.
 func (w *WebhookSense) Name() string { return "Webhook" }
 
@@ -58,7 +69,7 @@ func (w *WebhookSense) Start(ctx context.Context, out chan<- *UnifiedInput) erro
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	ln, err := net.Listen("tcp", w.addr)
+	ln, err := netaddr.Listen(w.addr)
 	if err != nil {
 		w.mu.Unlock()
 		return fmt.Errorf("webhook sense: listen: %w", err)
@@ -80,9 +91,9 @@ func (w *WebhookSense) Start(ctx context.Context, out chan<- *UnifiedInput) erro
 }
 
 func (w *WebhookSense) handleWebhook(rw http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20)) // 1MB max
+	body, err := w.limits.readBody(rw, r, w.Name())
 	if err != nil {
-		http.Error(rw, `{"error":"read body failed"}`, http.StatusBadRequest)
+		writeLimitError(rw, err)
 		return
 	}
 	defer r.Body.Close()
@@ -0,0 +1,210 @@
+package senses
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// WebSocket opcodes (RFC 6455 §5.2).
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xa
+)
+
+// wsClientMaxMessage bounds one message received by wsClient.
+const wsClientMaxMessage = 16 << 20
+
+// wsClient is a minimal RFC 6455 client for the platforms that push events
+// over a WebSocket (Slack Socket Mode): text messages, ping/pong and close.
+// Reads must come from one goroutine; writes may come from any.
+type wsClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	wmu sync.Mutex
+	w   *bufio.Writer
+}
+
+// dialWebSocket opens a WebSocket to a ws:// or wss:// URL.
+func dialWebSocket(ctx context.Context, rawURL string) (*wsClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+	host := u.Host
+	var d net.Dialer
+	var conn net.Conn
+	switch u.Scheme {
+	case "ws":
+		if u.Port() == "" {
+			host += ":80"
+		}
+		conn, err = d.DialContext(ctx, "tcp", host)
+	case "wss":
+		if u.Port() == "" {
+			host += ":443"
+		}
+		td := tls.Dialer{NetDialer: &d, Config: &tls.Config{ServerName: u.Hostname()}}
+		conn, err = td.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("websocket: dial: %w", err)
+	}
+	// Abort the handshake when ctx ends; the connection is ours afterwards.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req, err := http.NewRequest(http.MethodGet, "http://"+u.Host+u.RequestURI(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	resp.Body.Close()
+	h := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(h[:]) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: unexpected response %s", resp.Status)
+	}
+	if ctx.Err() != nil {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	return &wsClient{conn: conn, r: br, w: bufio.NewWriter(conn)}, nil
+}
+
+// ReadMessage returns the next text or binary message, answering pings on
+// the way. A close frame from the server ends the stream with io.EOF.
+func (c *wsClient) ReadMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case wsOpPing:
+			if err := c.write(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			c.write(wsOpClose, nil)
+			return nil, io.EOF
+		}
+		msg = append(msg, payload...)
+		if len(msg) > wsClientMaxMessage {
+			return nil, fmt.Errorf("websocket: message over %d bytes", wsClientMaxMessage)
+		}
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// WriteText sends data as one text message.
+func (c *wsClient) WriteText(data []byte) error {
+	return c.write(wsOpText, data)
+}
+
+// Close closes the connection without a closing handshake.
+func (c *wsClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *wsClient) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode = hdr[0]&0x80 != 0, hdr[0]&0x0f
+	length := uint64(hdr[1] & 0x7f)
+	switch length {
+	case 126:
+		var buf [2]byte
+		if _, err := io.ReadFull(c.r, buf[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(buf[:]))
+	case 127:
+		var buf [8]byte
+		if _, err := io.ReadFull(c.r, buf[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(buf[:])
+	}
+	if length > wsClientMaxMessage {
+		return false, 0, nil, fmt.Errorf("websocket: frame of %d bytes", length)
+	}
+	// Server frames are not masked (RFC 6455 §5.1).
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	return fin, opcode, payload, nil
+}
+
+// write sends one frame. Client frames are always masked.
+func (c *wsClient) write(opcode byte, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	hdr := []byte{0x80 | opcode}
+	switch n := len(data); {
+	case n <= 125:
+		hdr = append(hdr, 0x80|byte(n))
+	case n <= 65535:
+		hdr = append(hdr, 0x80|126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 0x80|127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	var mask [4]byte
+	rand.Read(mask[:])
+	hdr = append(hdr, mask[:]...)
+	masked := make([]byte, len(data))
+	for i, b := range data {
+		masked[i] = b ^ mask[i%4]
+	}
+	if _, err := c.w.Write(hdr); err != nil {
+		return err
+	}
+	if _, err := c.w.Write(masked); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
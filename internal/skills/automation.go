@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"sync"
@@ -14,69 +13,6 @@ import (
 	"github.com/overhuman/overhuman/internal/storage"
 )
 
-// --- API Integration Skill ---
-
-// APIIntegrationSkill performs HTTP REST calls.
-type APIIntegrationSkill struct {
-	client *http.Client
-}
-
-func NewAPIIntegrationSkill() *APIIntegrationSkill {
-	return &APIIntegrationSkill{
-		client: &http.Client{Timeout: 30 * time.Second},
-	}
-}
-
-func (s *APIIntegrationSkill) Execute(ctx context.Context, input instruments.SkillInput) (*instruments.SkillOutput, error) {
-	method := input.Parameters["method"]
-	url := input.Parameters["url"]
-	body := input.Parameters["body"]
-	headers := input.Parameters["headers"]
-
-	if url == "" {
-		return &instruments.SkillOutput{Success: false, Error: "url parameter required"}, nil
-	}
-	if method == "" {
-		method = "GET"
-	}
-
-	var bodyReader io.Reader
-	if body != "" {
-		bodyReader = strings.NewReader(body)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
-	if err != nil {
-		return &instruments.SkillOutput{Success: false, Error: err.Error()}, nil
-	}
-
-	// Parse headers.
-	if headers != "" {
-		var hmap map[string]string
-		if err := json.Unmarshal([]byte(headers), &hmap); err == nil {
-			for k, v := range hmap {
-				req.Header.Set(k, v)
-			}
-		}
-	}
-
-	start := time.Now()
-	resp, err := s.client.Do(req)
-	elapsed := time.Since(start).Milliseconds()
-	if err != nil {
-		return &instruments.SkillOutput{Success: false, Error: err.Error(), ElapsedMs: elapsed}, nil
-	}
-	defer resp.Body.Close()
-
-	data, _ := io.ReadAll(io.LimitReader(resp.Body, 100*1024))
-
-	return &instruments.SkillOutput{
-		Result:    fmt.Sprintf("HTTP %d\n\n%s", resp.StatusCode, string(data)),
-		Success:   resp.StatusCode >= 200 && resp.StatusCode < 400,
-		ElapsedMs: elapsed,
-	}, nil
-}
-
 // --- Web Search Skill ---
 
 // WebSearchSkill performs web search via HTTP.
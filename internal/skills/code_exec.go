@@ -51,13 +51,16 @@ func (s *CodeExecSkill) Execute(ctx context.Context, input instruments.SkillInpu
 
 	return &instruments.SkillOutput{
 		Result:    output,
-		Success:   result.ExitCode == 0,
+		Success:   result.ExitCode == 0 && !result.QuotaExceeded,
 		ElapsedMs: result.ElapsedMs,
 		Error:     errorFromResult(result),
 	}, nil
 }
 
 func errorFromResult(r *instruments.SandboxResult) string {
+	if r.QuotaExceeded {
+		return instruments.ErrScratchQuota.Error()
+	}
 	if r.ExitCode == 0 {
 		return ""
 	}
@@ -14,7 +14,8 @@ import (
 	"github.com/overhuman/overhuman/internal/instruments"
 )
 
-// FileOpsSkill provides file system operations.
+// FileOpsSkill provides file system operations. Within a pipeline run it
+// works in the run's scratch directory instead of baseDir.
 type FileOpsSkill struct {
 	baseDir string
 }
@@ -29,9 +30,28 @@ func NewFileOpsSkill(baseDir string) *FileOpsSkill {
 func (s *FileOpsSkill) Execute(ctx context.Context, input instruments.SkillInput) (*instruments.SkillOutput, error) {
 	action := input.Parameters["action"]
 	path := input.Parameters["path"]
+	base := s.baseDir
+	scratch := instruments.ScratchFrom(ctx)
 
 	// Ensure path is within base directory.
-	if path != "" {
+	if scratch != nil {
+		base = scratch.Dir()
+		if action == "write" {
+			if err := scratch.WriteFile(path, []byte(input.Parameters["content"])); err != nil {
+				return &instruments.SkillOutput{Success: false, Error: err.Error()}, nil
+			}
+			return &instruments.SkillOutput{
+				Result:  fmt.Sprintf("written %d bytes to %s", len(input.Parameters["content"]), path),
+				Success: true,
+			}, nil
+		}
+		if path != "" {
+			var err error
+			if path, err = scratch.Path(path); err != nil {
+				return &instruments.SkillOutput{Success: false, Error: err.Error()}, nil
+			}
+		}
+	} else if path != "" {
 		path = filepath.Join(s.baseDir, filepath.Clean(path))
 	}
 
@@ -43,12 +63,12 @@ func (s *FileOpsSkill) Execute(ctx context.Context, input instruments.SkillInput
 		return s.writeFile(path, content)
 	case "list":
 		pattern := input.Parameters["pattern"]
-		return s.listFiles(path, pattern)
+		return s.listFiles(base, path, pattern)
 	case "stat":
 		return s.statFile(path)
 	case "search":
 		query := input.Parameters["query"]
-		return s.searchFiles(path, query)
+		return s.searchFiles(base, path, query)
 	default:
 		return &instruments.SkillOutput{
 			Success: false,
@@ -84,9 +104,9 @@ func (s *FileOpsSkill) writeFile(path, content string) (*instruments.SkillOutput
 	}, nil
 }
 
-func (s *FileOpsSkill) listFiles(dir, pattern string) (*instruments.SkillOutput, error) {
+func (s *FileOpsSkill) listFiles(base, dir, pattern string) (*instruments.SkillOutput, error) {
 	if dir == "" {
-		dir = s.baseDir
+		dir = base
 	}
 	if pattern == "" {
 		pattern = "*"
@@ -128,9 +148,9 @@ func (s *FileOpsSkill) statFile(path string) (*instruments.SkillOutput, error) {
 	}, nil
 }
 
-func (s *FileOpsSkill) searchFiles(dir, query string) (*instruments.SkillOutput, error) {
+func (s *FileOpsSkill) searchFiles(base, dir, query string) (*instruments.SkillOutput, error) {
 	if dir == "" {
-		dir = s.baseDir
+		dir = base
 	}
 	var matches []string
 	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
@@ -0,0 +1,278 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+)
+
+// --- HTTP Request Skill ---
+
+// DefaultHTTPMaxResponseBytes caps how much of a response body is returned.
+const DefaultHTTPMaxResponseBytes = 64 * 1024
+
+// HTTPEndpoint is a named, operator-defined request template. Headers may
+// reference environment variables ($JIRA_TOKEN) so secrets stay out of
+// prompts; {{name}} placeholders are filled from skill parameters.
+type HTTPEndpoint struct {
+	Method  string            `json:"method,omitempty"` // GET (default) or POST
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// HTTPRequestConfig is the policy for outbound HTTP requests.
+type HTTPRequestConfig struct {
+	// AllowedDomains lists hosts requests may target. "example.com" matches
+	// only that host; "*.example.com" matches its subdomains. Empty denies
+	// every request.
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+
+	// Endpoints are named request templates selected with the "endpoint"
+	// parameter (e.g. "jira_create", "team_webhook").
+	Endpoints map[string]HTTPEndpoint `json:"endpoints,omitempty"`
+
+	MaxResponseBytes int           `json:"max_response_bytes,omitempty"` // default DefaultHTTPMaxResponseBytes
+	Timeout          time.Duration `json:"-"`                            // default 30s
+}
+
+// Validate reports endpoint templates the skill would always refuse.
+func (c HTTPRequestConfig) Validate() error {
+	for name, ep := range c.Endpoints {
+		if ep.URL == "" {
+			return fmt.Errorf("endpoint %q: url required", name)
+		}
+		if m := strings.ToUpper(ep.Method); m != "" && m != http.MethodGet && m != http.MethodPost {
+			return fmt.Errorf("endpoint %q: method %s not allowed (GET or POST)", name, ep.Method)
+		}
+	}
+	return nil
+}
+
+// HTTPRequestSkill performs GET/POST requests with header/body templating,
+// restricted to an allowlist of domains and with a capped response size.
+//
+// Parameters:
+//   - endpoint: name of a configured HTTPEndpoint, or
+//   - method, url, headers (JSON object), body: an ad-hoc request
+//   - any other key: value for a {{key}} placeholder
+type HTTPRequestSkill struct {
+	cfg    HTTPRequestConfig
+	client *http.Client
+}
+
+// NewHTTPRequestSkill creates an HTTP request skill enforcing cfg.
+func NewHTTPRequestSkill(cfg HTTPRequestConfig) *HTTPRequestSkill {
+	if cfg.MaxResponseBytes <= 0 {
+		cfg.MaxResponseBytes = DefaultHTTPMaxResponseBytes
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	s := &HTTPRequestSkill{cfg: cfg}
+	s.client = &http.Client{
+		Timeout: cfg.Timeout,
+		// Redirects must stay inside the allowlist too.
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return s.checkHost(req.URL)
+		},
+	}
+	return s
+}
+
+func (s *HTTPRequestSkill) Execute(ctx context.Context, input instruments.SkillInput) (*instruments.SkillOutput, error) {
+	resp, err := s.do(ctx, input.Parameters)
+	if err != nil {
+		return &instruments.SkillOutput{Success: false, Error: err.Error(), ElapsedMs: resp.elapsedMs}, nil
+	}
+
+	result := fmt.Sprintf("HTTP %d\n\n%s", resp.status, string(resp.body))
+	if resp.truncated {
+		result += fmt.Sprintf("\n\n[truncated at %d bytes]", s.cfg.MaxResponseBytes)
+	}
+	out := &instruments.SkillOutput{
+		Result:    result,
+		Success:   resp.ok(),
+		ElapsedMs: resp.elapsedMs,
+	}
+	if !out.Success {
+		out.Error = fmt.Sprintf("HTTP %d", resp.status)
+	}
+	return out, nil
+}
+
+// httpResponse is a completed request with its size-capped body.
+type httpResponse struct {
+	status    int
+	body      []byte
+	truncated bool
+	elapsedMs int64
+}
+
+func (r httpResponse) ok() bool { return r.status >= 200 && r.status < 400 }
+
+// do builds and sends the request for params and reads up to
+// MaxResponseBytes of the response.
+func (s *HTTPRequestSkill) do(ctx context.Context, params map[string]string) (httpResponse, error) {
+	req, err := s.buildRequest(ctx, params)
+	if err != nil {
+		return httpResponse{}, err
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	out := httpResponse{elapsedMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+
+	limit := int64(s.cfg.MaxResponseBytes)
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if int64(len(data)) > limit {
+		data, out.truncated = data[:limit], true
+	}
+	out.status, out.body = resp.StatusCode, data
+	return out, nil
+}
+
+// buildRequest resolves the endpoint template or ad-hoc request, fills
+// placeholders and enforces the method and domain policy.
+func (s *HTTPRequestSkill) buildRequest(ctx context.Context, params map[string]string) (*http.Request, error) {
+	var ep HTTPEndpoint
+	if name := params["endpoint"]; name != "" {
+		configured, ok := s.cfg.Endpoints[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown endpoint %q", name)
+		}
+		ep = configured
+		ep.Headers = make(map[string]string, len(configured.Headers))
+		for k, v := range configured.Headers {
+			ep.Headers[k] = os.ExpandEnv(v)
+		}
+	} else {
+		ep = HTTPEndpoint{Method: params["method"], URL: params["url"], Body: params["body"]}
+		if h := params["headers"]; h != "" {
+			if err := json.Unmarshal([]byte(h), &ep.Headers); err != nil {
+				return nil, fmt.Errorf("headers must be a JSON object: %w", err)
+			}
+		}
+	}
+	if ep.URL == "" {
+		return nil, errors.New("url or endpoint parameter required")
+	}
+
+	method := strings.ToUpper(ep.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if method != http.MethodGet && method != http.MethodPost {
+		return nil, fmt.Errorf("method %s not allowed (GET or POST)", method)
+	}
+
+	rawURL, err := fillTemplate(ep.URL, params, url.QueryEscape)
+	if err != nil {
+		return nil, fmt.Errorf("url: %w", err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if err := s.checkHost(u); err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if ep.Body != "" {
+		escape := func(v string) string { return v }
+		if isJSONContent(ep.Headers) {
+			escape = jsonEscape
+		}
+		filled, err := fillTemplate(ep.Body, params, escape)
+		if err != nil {
+			return nil, fmt.Errorf("body: %w", err)
+		}
+		body = strings.NewReader(filled)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range ep.Headers {
+		filled, err := fillTemplate(v, params, func(v string) string { return v })
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", k, err)
+		}
+		req.Header.Set(k, filled)
+	}
+	return req, nil
+}
+
+// checkHost reports whether u's host is on the allowlist.
+func (s *HTTPRequestSkill) checkHost(u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	for _, d := range s.cfg.AllowedDomains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if suffix, ok := strings.CutPrefix(d, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return nil
+			}
+		} else if host == d {
+			return nil
+		}
+	}
+	return fmt.Errorf("domain %q is not in the allowlist", host)
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// fillTemplate replaces {{name}} placeholders with escaped parameter values.
+// A placeholder without a matching parameter is an error.
+func fillTemplate(tmpl string, params map[string]string, escape func(string) string) (string, error) {
+	var missing []string
+	out := placeholderRe.ReplaceAllStringFunc(tmpl, func(m string) string {
+		name := placeholderRe.FindStringSubmatch(m)[1]
+		v, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return escape(v)
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing parameter(s): %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+func isJSONContent(headers map[string]string) bool {
+	for k, v := range headers {
+		if strings.EqualFold(k, "Content-Type") && strings.Contains(strings.ToLower(v), "json") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEscape escapes v for use inside a JSON string literal.
+func jsonEscape(v string) string {
+	b, _ := json.Marshal(v)
+	return string(b[1 : len(b)-1])
+}
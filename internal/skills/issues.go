@@ -0,0 +1,700 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+)
+
+// --- Issues Skill (GitHub, Jira) ---
+
+// GitHubIssuesConfig connects the issues skill to GitHub.
+type GitHubIssuesConfig struct {
+	Token       string `json:"token,omitempty"`        // personal access or app token
+	BaseURL     string `json:"base_url,omitempty"`     // default https://api.github.com (GHE: https://host/api/v3)
+	DefaultRepo string `json:"default_repo,omitempty"` // "owner/name" used when no repo parameter is given
+}
+
+// JiraIssuesConfig connects the issues skill to Jira.
+type JiraIssuesConfig struct {
+	BaseURL        string `json:"base_url,omitempty"`        // e.g. https://acme.atlassian.net
+	Email          string `json:"email,omitempty"`           // Jira Cloud: account e-mail for basic auth
+	Token          string `json:"token,omitempty"`           // Cloud API token, or a Data Center PAT (without Email)
+	DefaultProject string `json:"default_project,omitempty"` // project key used when no project parameter is given
+}
+
+// IssuesConfig configures the issue trackers the issues skill talks to.
+type IssuesConfig struct {
+	GitHub GitHubIssuesConfig `json:"github,omitempty"`
+	Jira   JiraIssuesConfig   `json:"jira,omitempty"`
+
+	// AllowWrites enables create, comment and transition. Listing and
+	// searching are always allowed.
+	AllowWrites bool `json:"allow_writes,omitempty"`
+
+	Timeout time.Duration `json:"-"` // default 30s
+}
+
+// Configured reports whether at least one tracker has credentials.
+func (c IssuesConfig) Configured() bool {
+	return c.GitHub.Token != "" || (c.Jira.BaseURL != "" && c.Jira.Token != "")
+}
+
+// issue is a tracker-neutral issue.
+type issue struct {
+	Key      string // "owner/repo#12" or "OPS-12"
+	Title    string
+	State    string
+	Assignee string
+	Labels   []string
+	URL      string
+	Updated  string
+	Body     string
+	Comments []string // "author: text", only filled by get
+}
+
+// issueTracker is implemented by each backend.
+type issueTracker interface {
+	list(ctx context.Context, scope, state string, limit int) ([]issue, error)
+	search(ctx context.Context, scope, query string, limit int) ([]issue, error)
+	get(ctx context.Context, scope, id string) (*issue, error)
+	create(ctx context.Context, scope, title, body string, labels []string) (*issue, error)
+	comment(ctx context.Context, scope, id, body string) error
+	transition(ctx context.Context, scope, id, to string) error
+}
+
+// IssuesSkill lists, searches, creates, comments on and transitions issues
+// in GitHub and Jira, so answers about "my open issues" are grounded in
+// tracker data. Writes require AllowWrites.
+//
+// Parameters:
+//   - tracker: github or jira (default: the only configured one, else github)
+//   - action: list (default), search, get, create, comment, transition
+//   - repo (GitHub "owner/name") or project (Jira key): scope
+//   - state: open (default), closed or all, for list
+//   - query: search text (GitHub search syntax, or JQL for Jira); defaults
+//     to the goal
+//   - id: issue number (GitHub) or key (Jira, e.g. OPS-12)
+//   - title, body, labels (comma-separated): create
+//   - body: comment
+//   - to: target state (GitHub: open/closed; Jira: transition or status name)
+//   - limit: max results (default 20)
+type IssuesSkill struct {
+	cfg      IssuesConfig
+	trackers map[string]issueTracker
+}
+
+// NewIssuesSkill creates an issues skill for the configured trackers.
+func NewIssuesSkill(cfg IssuesConfig) *IssuesSkill {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	s := &IssuesSkill{cfg: cfg, trackers: make(map[string]issueTracker)}
+	if cfg.GitHub.Token != "" {
+		gh := cfg.GitHub
+		if gh.BaseURL == "" {
+			gh.BaseURL = "https://api.github.com"
+		}
+		s.trackers["github"] = &githubTracker{cfg: gh, client: client}
+	}
+	if cfg.Jira.BaseURL != "" && cfg.Jira.Token != "" {
+		s.trackers["jira"] = &jiraTracker{cfg: cfg.Jira, client: client}
+	}
+	return s
+}
+
+func (s *IssuesSkill) Execute(ctx context.Context, input instruments.SkillInput) (*instruments.SkillOutput, error) {
+	start := time.Now()
+	result, err := s.run(ctx, input)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		return &instruments.SkillOutput{Success: false, Error: err.Error(), ElapsedMs: elapsed}, nil
+	}
+	return &instruments.SkillOutput{Result: result, Success: true, ElapsedMs: elapsed}, nil
+}
+
+func (s *IssuesSkill) run(ctx context.Context, input instruments.SkillInput) (string, error) {
+	p := input.Parameters
+	name := p["tracker"]
+	if name == "" {
+		name = "github"
+		if _, ok := s.trackers["github"]; !ok && s.trackers["jira"] != nil {
+			name = "jira"
+		}
+	}
+	t, ok := s.trackers[name]
+	if !ok {
+		return "", fmt.Errorf("issue tracker %q is not configured", name)
+	}
+	scope := p["repo"]
+	if name == "jira" {
+		scope = p["project"]
+		if scope == "" {
+			scope = s.cfg.Jira.DefaultProject
+		}
+	} else if scope == "" {
+		scope = s.cfg.GitHub.DefaultRepo
+	}
+	limit := resultLimit(p["limit"])
+
+	action := p["action"]
+	switch action {
+	case "create", "comment", "transition":
+		if !s.cfg.AllowWrites {
+			return "", errors.New("issue tracker writes are disabled; set issues.allow_writes in config.json")
+		}
+	}
+
+	switch action {
+	case "", "list":
+		state := p["state"]
+		if state == "" {
+			state = "open"
+		}
+		if state != "open" && state != "closed" && state != "all" {
+			return "", fmt.Errorf("state must be open, closed or all, got %q", state)
+		}
+		issues, err := t.list(ctx, scope, state, limit)
+		if err != nil {
+			return "", err
+		}
+		return formatIssues(fmt.Sprintf("%s issues (%s)", state, name), issues), nil
+	case "search":
+		query := p["query"]
+		if query == "" {
+			query = input.Goal
+		}
+		if query == "" {
+			return "", errors.New("query required")
+		}
+		issues, err := t.search(ctx, scope, query, limit)
+		if err != nil {
+			return "", err
+		}
+		return formatIssues(fmt.Sprintf("issues matching %q (%s)", query, name), issues), nil
+	case "get":
+		if p["id"] == "" {
+			return "", errors.New("id required")
+		}
+		is, err := t.get(ctx, scope, p["id"])
+		if err != nil {
+			return "", err
+		}
+		return formatIssue(is), nil
+	case "create":
+		if p["title"] == "" {
+			return "", errors.New("title required")
+		}
+		var labels []string
+		for _, l := range strings.Split(p["labels"], ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				labels = append(labels, l)
+			}
+		}
+		is, err := t.create(ctx, scope, p["title"], p["body"], labels)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Created %s: %s %s", is.Key, is.Title, is.URL), nil
+	case "comment":
+		if p["id"] == "" || p["body"] == "" {
+			return "", errors.New("id and body required")
+		}
+		if err := t.comment(ctx, scope, p["id"], p["body"]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Commented on %s", p["id"]), nil
+	case "transition":
+		if p["id"] == "" || p["to"] == "" {
+			return "", errors.New("id and to required")
+		}
+		if err := t.transition(ctx, scope, p["id"], p["to"]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Moved %s to %s", p["id"], p["to"]), nil
+	}
+	return "", fmt.Errorf("unknown action %q (list, search, get, create, comment, transition)", action)
+}
+
+func formatIssues(heading string, issues []issue) string {
+	if len(issues) == 0 {
+		return "No " + heading
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s:\n", len(issues), heading)
+	for _, is := range issues {
+		fmt.Fprintf(&b, "- %s [%s] %s", is.Key, is.State, is.Title)
+		if is.Assignee != "" {
+			fmt.Fprintf(&b, " (@%s)", is.Assignee)
+		}
+		if len(is.Labels) > 0 {
+			fmt.Fprintf(&b, " {%s}", strings.Join(is.Labels, ", "))
+		}
+		if is.Updated != "" {
+			fmt.Fprintf(&b, " updated %s", is.Updated)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func formatIssue(is *issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s [%s] %s\n", is.Key, is.State, is.Title)
+	if is.URL != "" {
+		fmt.Fprintf(&b, "%s\n", is.URL)
+	}
+	if is.Assignee != "" {
+		fmt.Fprintf(&b, "Assignee: %s\n", is.Assignee)
+	}
+	if len(is.Labels) > 0 {
+		fmt.Fprintf(&b, "Labels: %s\n", strings.Join(is.Labels, ", "))
+	}
+	if is.Body != "" {
+		fmt.Fprintf(&b, "\n%s\n", is.Body)
+	}
+	if len(is.Comments) > 0 {
+		fmt.Fprintf(&b, "\nComments (%d):\n", len(is.Comments))
+		for _, c := range is.Comments {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	out := b.String()
+	if len(out) > DefaultHTTPMaxResponseBytes {
+		out = out[:DefaultHTTPMaxResponseBytes] + "\n... (truncated)"
+	}
+	return out
+}
+
+// trackerRequest sends a JSON request and decodes a JSON response into out.
+func trackerRequest(ctx context.Context, client *http.Client, method, rawURL string, auth func(*http.Request), body, out any) error {
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, rd)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	auth(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message       string   `json:"message"`       // GitHub
+			ErrorMessages []string `json:"errorMessages"` // Jira
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		msg := apiErr.Message
+		if msg == "" && len(apiErr.ErrorMessages) > 0 {
+			msg = strings.Join(apiErr.ErrorMessages, "; ")
+		}
+		if msg != "" {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, msg)
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// --- GitHub ---
+
+type githubTracker struct {
+	cfg    GitHubIssuesConfig
+	client *http.Client
+}
+
+type githubIssue struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	State     string `json:"state"`
+	Body      string `json:"body"`
+	HTMLURL   string `json:"html_url"`
+	UpdatedAt string `json:"updated_at"`
+	Assignee  *struct {
+		Login string `json:"login"`
+	} `json:"assignee"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest   json.RawMessage `json:"pull_request"`
+	RepositoryURL string          `json:"repository_url"`
+}
+
+func (g *githubTracker) do(ctx context.Context, method, path string, body, out any) error {
+	err := trackerRequest(ctx, g.client, method, strings.TrimRight(g.cfg.BaseURL, "/")+path, func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+g.cfg.Token)
+		r.Header.Set("Accept", "application/vnd.github+json")
+	}, body, out)
+	if err != nil {
+		return fmt.Errorf("github: %w", err)
+	}
+	return nil
+}
+
+func (g *githubTracker) convert(repo string, gi githubIssue) issue {
+	if repo == "" {
+		// Search results span repositories: .../repos/owner/name
+		if i := strings.Index(gi.RepositoryURL, "/repos/"); i >= 0 {
+			repo = gi.RepositoryURL[i+len("/repos/"):]
+		}
+	}
+	is := issue{
+		Key:     fmt.Sprintf("%s#%d", repo, gi.Number),
+		Title:   gi.Title,
+		State:   gi.State,
+		URL:     gi.HTMLURL,
+		Updated: gi.UpdatedAt,
+		Body:    gi.Body,
+	}
+	if gi.Assignee != nil {
+		is.Assignee = gi.Assignee.Login
+	}
+	for _, l := range gi.Labels {
+		is.Labels = append(is.Labels, l.Name)
+	}
+	return is
+}
+
+func githubRepo(repo string) (string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("github: repo must be \"owner/name\", got %q", repo)
+	}
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name), nil
+}
+
+// githubNumber accepts "12", "#12" or "owner/name#12".
+func githubNumber(repo, id string) (string, string, error) {
+	if r, n, ok := strings.Cut(id, "#"); ok {
+		if r != "" {
+			repo = r
+		}
+		id = n
+	}
+	if _, err := strconv.Atoi(id); err != nil {
+		return "", "", fmt.Errorf("github: issue id must be a number, got %q", id)
+	}
+	base, err := githubRepo(repo)
+	if err != nil {
+		return "", "", err
+	}
+	return base, id, nil
+}
+
+func (g *githubTracker) list(ctx context.Context, repo, state string, limit int) ([]issue, error) {
+	if repo == "" {
+		// Without a repo: issues assigned to the token's user.
+		var items []githubIssue
+		if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/issues?filter=assigned&state=%s&per_page=%d", state, limit), nil, &items); err != nil {
+			return nil, err
+		}
+		return g.convertAll("", items), nil
+	}
+	base, err := githubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var items []githubIssue
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues?state=%s&per_page=%d", base, state, limit), nil, &items); err != nil {
+		return nil, err
+	}
+	return g.convertAll(repo, items), nil
+}
+
+func (g *githubTracker) convertAll(repo string, items []githubIssue) []issue {
+	out := make([]issue, 0, len(items))
+	for _, gi := range items {
+		if len(gi.PullRequest) > 0 && string(gi.PullRequest) != "null" {
+			continue // the issues API also returns pull requests
+		}
+		out = append(out, g.convert(repo, gi))
+	}
+	return out
+}
+
+func (g *githubTracker) search(ctx context.Context, repo, query string, limit int) ([]issue, error) {
+	q := query + " is:issue"
+	if repo != "" && !strings.Contains(query, "repo:") {
+		q += " repo:" + repo
+	}
+	var resp struct {
+		Items []githubIssue `json:"items"`
+	}
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/search/issues?q=%s&per_page=%d", url.QueryEscape(q), limit), nil, &resp); err != nil {
+		return nil, err
+	}
+	return g.convertAll("", resp.Items), nil
+}
+
+func (g *githubTracker) get(ctx context.Context, repo, id string) (*issue, error) {
+	base, n, err := githubNumber(repo, id)
+	if err != nil {
+		return nil, err
+	}
+	var gi githubIssue
+	if err := g.do(ctx, http.MethodGet, base+"/issues/"+n, nil, &gi); err != nil {
+		return nil, err
+	}
+	is := g.convert(strings.TrimPrefix(base, "/repos/"), gi)
+	var comments []struct {
+		Body string `json:"body"`
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if err := g.do(ctx, http.MethodGet, base+"/issues/"+n+"/comments?per_page=50", nil, &comments); err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		is.Comments = append(is.Comments, c.User.Login+": "+c.Body)
+	}
+	return &is, nil
+}
+
+func (g *githubTracker) create(ctx context.Context, repo, title, body string, labels []string) (*issue, error) {
+	base, err := githubRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]any{"title": title, "body": body}
+	if len(labels) > 0 {
+		req["labels"] = labels
+	}
+	var gi githubIssue
+	if err := g.do(ctx, http.MethodPost, base+"/issues", req, &gi); err != nil {
+		return nil, err
+	}
+	is := g.convert(repo, gi)
+	return &is, nil
+}
+
+func (g *githubTracker) comment(ctx context.Context, repo, id, body string) error {
+	base, n, err := githubNumber(repo, id)
+	if err != nil {
+		return err
+	}
+	return g.do(ctx, http.MethodPost, base+"/issues/"+n+"/comments", map[string]string{"body": body}, nil)
+}
+
+func (g *githubTracker) transition(ctx context.Context, repo, id, to string) error {
+	base, n, err := githubNumber(repo, id)
+	if err != nil {
+		return err
+	}
+	req := map[string]string{}
+	switch strings.ToLower(to) {
+	case "open", "reopen", "reopened":
+		req["state"] = "open"
+	case "closed", "close", "done", "completed":
+		req["state"], req["state_reason"] = "closed", "completed"
+	case "not_planned", "not planned", "wontfix":
+		req["state"], req["state_reason"] = "closed", "not_planned"
+	default:
+		return fmt.Errorf("github: unknown state %q (open, closed, not_planned)", to)
+	}
+	return g.do(ctx, http.MethodPatch, base+"/issues/"+n, req, nil)
+}
+
+// --- Jira ---
+
+type jiraTracker struct {
+	cfg    JiraIssuesConfig
+	client *http.Client
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+		Updated     string `json:"updated"`
+		Status      struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		Assignee *struct {
+			DisplayName string `json:"displayName"`
+		} `json:"assignee"`
+		Labels  []string `json:"labels"`
+		Comment struct {
+			Comments []struct {
+				Body   string `json:"body"`
+				Author struct {
+					DisplayName string `json:"displayName"`
+				} `json:"author"`
+			} `json:"comments"`
+		} `json:"comment"`
+	} `json:"fields"`
+}
+
+func (j *jiraTracker) do(ctx context.Context, method, path string, body, out any) error {
+	err := trackerRequest(ctx, j.client, method, strings.TrimRight(j.cfg.BaseURL, "/")+path, func(r *http.Request) {
+		if j.cfg.Email != "" {
+			r.SetBasicAuth(j.cfg.Email, j.cfg.Token)
+		} else {
+			r.Header.Set("Authorization", "Bearer "+j.cfg.Token)
+		}
+	}, body, out)
+	if err != nil {
+		return fmt.Errorf("jira: %w", err)
+	}
+	return nil
+}
+
+func (j *jiraTracker) convert(ji jiraIssue) issue {
+	is := issue{
+		Key:     ji.Key,
+		Title:   ji.Fields.Summary,
+		State:   ji.Fields.Status.Name,
+		URL:     strings.TrimRight(j.cfg.BaseURL, "/") + "/browse/" + ji.Key,
+		Updated: ji.Fields.Updated,
+		Body:    ji.Fields.Description,
+		Labels:  ji.Fields.Labels,
+	}
+	if ji.Fields.Assignee != nil {
+		is.Assignee = ji.Fields.Assignee.DisplayName
+	}
+	for _, c := range ji.Fields.Comment.Comments {
+		is.Comments = append(is.Comments, c.Author.DisplayName+": "+c.Body)
+	}
+	return is
+}
+
+// jqlQuote quotes a value for use in JQL.
+func jqlQuote(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+func (j *jiraTracker) jql(ctx context.Context, jql string, limit int) ([]issue, error) {
+	var resp struct {
+		Issues []jiraIssue `json:"issues"`
+	}
+	q := url.Values{"jql": {jql}, "maxResults": {strconv.Itoa(limit)}, "fields": {"summary,status,assignee,labels,updated"}}
+	if err := j.do(ctx, http.MethodGet, "/rest/api/2/search?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	out := make([]issue, 0, len(resp.Issues))
+	for _, ji := range resp.Issues {
+		out = append(out, j.convert(ji))
+	}
+	return out, nil
+}
+
+func (j *jiraTracker) list(ctx context.Context, project, state string, limit int) ([]issue, error) {
+	var clauses []string
+	if project != "" {
+		clauses = append(clauses, "project = "+jqlQuote(project))
+	} else {
+		clauses = append(clauses, "assignee = currentUser()")
+	}
+	switch state {
+	case "open":
+		clauses = append(clauses, "statusCategory != Done")
+	case "closed":
+		clauses = append(clauses, "statusCategory = Done")
+	}
+	return j.jql(ctx, strings.Join(clauses, " AND ")+" ORDER BY updated DESC", limit)
+}
+
+// search treats query as JQL when it contains an operator, otherwise as
+// free text.
+func (j *jiraTracker) search(ctx context.Context, project, query string, limit int) ([]issue, error) {
+	jql := query
+	if !strings.ContainsAny(query, "=~") {
+		jql = "text ~ " + jqlQuote(query)
+		if project != "" {
+			jql = "project = " + jqlQuote(project) + " AND " + jql
+		}
+		jql += " ORDER BY updated DESC"
+	}
+	return j.jql(ctx, jql, limit)
+}
+
+func (j *jiraTracker) get(ctx context.Context, _ string, key string) (*issue, error) {
+	var ji jiraIssue
+	if err := j.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=summary,status,assignee,labels,updated,description,comment", nil, &ji); err != nil {
+		return nil, err
+	}
+	is := j.convert(ji)
+	return &is, nil
+}
+
+func (j *jiraTracker) create(ctx context.Context, project, title, body string, labels []string) (*issue, error) {
+	if project == "" {
+		return nil, errors.New("jira: project required")
+	}
+	fields := map[string]any{
+		"project":     map[string]string{"key": project},
+		"summary":     title,
+		"description": body,
+		"issuetype":   map[string]string{"name": "Task"},
+	}
+	if len(labels) > 0 {
+		fields["labels"] = labels
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &resp); err != nil {
+		return nil, err
+	}
+	return &issue{Key: resp.Key, Title: title, URL: strings.TrimRight(j.cfg.BaseURL, "/") + "/browse/" + resp.Key}, nil
+}
+
+func (j *jiraTracker) comment(ctx context.Context, _ string, key, body string) error {
+	return j.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
+
+// transition applies the transition whose name or target status matches to.
+func (j *jiraTracker) transition(ctx context.Context, _ string, key, to string) error {
+	var resp struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	if err := j.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return err
+	}
+	var names []string
+	for _, t := range resp.Transitions {
+		if strings.EqualFold(t.Name, to) || strings.EqualFold(t.To.Name, to) {
+			return j.do(ctx, http.MethodPost, path, map[string]any{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+		names = append(names, t.Name)
+	}
+	return fmt.Errorf("jira: no transition to %q from the current status of %s (available: %s)", to, key, strings.Join(names, ", "))
+}
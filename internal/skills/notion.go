@@ -0,0 +1,635 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+)
+
+// --- Notion Skill ---
+
+// NotionAPIVersion is the Notion-Version header sent with every request.
+const NotionAPIVersion = "2022-06-28"
+
+// notionMaxBlocks is the most blocks Notion accepts in one request.
+const notionMaxBlocks = 100
+
+// NotionConfig connects the docs skill to a Notion workspace.
+type NotionConfig struct {
+	// Token is an internal integration secret or OAuth access token. Pages
+	// and databases must be shared with the integration.
+	Token string `json:"token,omitempty"`
+
+	// BaseURL overrides https://api.notion.com (tests, proxies).
+	BaseURL string `json:"base_url,omitempty"`
+
+	// AllowWrites enables the create, append and update actions. Reads are
+	// always allowed once a token is set.
+	AllowWrites bool `json:"allow_writes,omitempty"`
+
+	Timeout time.Duration `json:"-"` // default 30s
+}
+
+// NotionSkill searches, queries databases, reads pages as plain text and —
+// when AllowWrites is set — creates and updates pages.
+//
+// Parameters:
+//   - action: search (default), query, read, create, append, update
+//   - query: search text (defaults to the goal)
+//   - database_id, filter, sorts (JSON), limit: database query
+//   - page_id: page to read, append to or update
+//   - parent_database_id or parent_page_id, title, title_property: new page
+//   - content: page body; "# ", "## ", "- ", "[ ] " and "[x] " lines become
+//     headings, bullets and to-dos
+//   - properties (JSON): Notion property values for create/update
+type NotionSkill struct {
+	cfg    NotionConfig
+	client *http.Client
+}
+
+// NewNotionSkill creates a Notion skill for cfg.
+func NewNotionSkill(cfg NotionConfig) *NotionSkill {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.notion.com"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &NotionSkill{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (s *NotionSkill) Execute(ctx context.Context, input instruments.SkillInput) (*instruments.SkillOutput, error) {
+	start := time.Now()
+	params := input.Parameters
+	action := params["action"]
+
+	var result string
+	var err error
+	switch action {
+	case "", "search":
+		query := params["query"]
+		if query == "" {
+			query = input.Goal
+		}
+		result, err = s.search(ctx, query, resultLimit(params["limit"]))
+	case "query":
+		result, err = s.queryDatabase(ctx, params)
+	case "read":
+		result, err = s.readPage(ctx, params["page_id"])
+	case "create", "append", "update":
+		if !s.cfg.AllowWrites {
+			err = errors.New("Notion writes are disabled; set notion.allow_writes in config.json")
+			break
+		}
+		switch action {
+		case "create":
+			result, err = s.createPage(ctx, params)
+		case "append":
+			result, err = s.appendContent(ctx, params["page_id"], params["content"])
+		default:
+			result, err = s.updatePage(ctx, params)
+		}
+	default:
+		err = fmt.Errorf("unknown action %q (search, query, read, create, append, update)", action)
+	}
+
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		return &instruments.SkillOutput{Success: false, Error: err.Error(), ElapsedMs: elapsed}, nil
+	}
+	return &instruments.SkillOutput{Result: result, Success: true, ElapsedMs: elapsed}, nil
+}
+
+// resultLimit parses a "limit" parameter: 1-100, default 20.
+func resultLimit(v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 || n > 100 {
+		return 20
+	}
+	return n
+}
+
+// --- Reads ---
+
+func (s *NotionSkill) search(ctx context.Context, query string, limit int) (string, error) {
+	if query == "" {
+		return "", errors.New("query required")
+	}
+	var resp notionList
+	if err := s.do(ctx, http.MethodPost, "/v1/search", map[string]any{"query": query, "page_size": limit}, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Results) == 0 {
+		return fmt.Sprintf("No Notion pages or databases match %q", query), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d result(s) for %q:\n", len(resp.Results), query)
+	for _, obj := range resp.Results {
+		fmt.Fprintf(&b, "- [%s] %s (id %s) %s\n", obj.Object, obj.title(), obj.ID, obj.URL)
+	}
+	return b.String(), nil
+}
+
+func (s *NotionSkill) queryDatabase(ctx context.Context, params map[string]string) (string, error) {
+	id := params["database_id"]
+	if id == "" {
+		return "", errors.New("database_id required")
+	}
+	body := map[string]any{"page_size": resultLimit(params["limit"])}
+	for _, key := range []string{"filter", "sorts"} {
+		if raw := params[key]; raw != "" {
+			var v any
+			if err := json.Unmarshal([]byte(raw), &v); err != nil {
+				return "", fmt.Errorf("%s must be JSON: %w", key, err)
+			}
+			body[key] = v
+		}
+	}
+	var resp notionList
+	if err := s.do(ctx, http.MethodPost, "/v1/databases/"+url.PathEscape(id)+"/query", body, &resp); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d row(s)", len(resp.Results))
+	if resp.HasMore {
+		b.WriteString(" (more available)")
+	}
+	b.WriteString(":\n")
+	for _, page := range resp.Results {
+		fmt.Fprintf(&b, "- %s (id %s)\n", page.title(), page.ID)
+		for _, name := range sortedProperties(page.Properties) {
+			if v := page.Properties[name].text(); v != "" && page.Properties[name].Type != "title" {
+				fmt.Fprintf(&b, "    %s: %s\n", name, v)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// readPage renders a page's properties and top-level blocks as text, for use
+// as context.
+func (s *NotionSkill) readPage(ctx context.Context, id string) (string, error) {
+	if id == "" {
+		return "", errors.New("page_id required")
+	}
+	var page notionObject
+	if err := s.do(ctx, http.MethodGet, "/v1/pages/"+url.PathEscape(id), nil, &page); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", page.title())
+	for _, name := range sortedProperties(page.Properties) {
+		if v := page.Properties[name].text(); v != "" && page.Properties[name].Type != "title" {
+			fmt.Fprintf(&b, "%s: %s\n", name, v)
+		}
+	}
+	b.WriteString("\n")
+
+	cursor := ""
+	for {
+		path := "/v1/blocks/" + url.PathEscape(id) + "/children?page_size=100"
+		if cursor != "" {
+			path += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+		var blocks notionBlockList
+		if err := s.do(ctx, http.MethodGet, path, nil, &blocks); err != nil {
+			return "", err
+		}
+		for _, blk := range blocks.Results {
+			if line := blk.text(); line != "" {
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+		}
+		if !blocks.HasMore || blocks.NextCursor == "" || b.Len() > DefaultHTTPMaxResponseBytes {
+			break
+		}
+		cursor = blocks.NextCursor
+	}
+	out := b.String()
+	if len(out) > DefaultHTTPMaxResponseBytes {
+		out = out[:DefaultHTTPMaxResponseBytes] + "\n... (truncated)"
+	}
+	return out, nil
+}
+
+// --- Writes ---
+
+func (s *NotionSkill) createPage(ctx context.Context, params map[string]string) (string, error) {
+	parent := map[string]string{}
+	titleProp := params["title_property"]
+	switch {
+	case params["parent_database_id"] != "":
+		parent["database_id"] = params["parent_database_id"]
+		if titleProp == "" {
+			titleProp = "Name"
+		}
+	case params["parent_page_id"] != "":
+		parent["page_id"] = params["parent_page_id"]
+		titleProp = "title" // pages under pages only have a title
+	default:
+		return "", errors.New("parent_database_id or parent_page_id required")
+	}
+
+	props, err := notionProperties(params["properties"])
+	if err != nil {
+		return "", err
+	}
+	if title := params["title"]; title != "" {
+		props[titleProp] = map[string]any{"title": notionRichText(title)}
+	}
+	if len(props) == 0 {
+		return "", errors.New("title or properties required")
+	}
+
+	blocks := notionBlocks(params["content"])
+	first := blocks
+	if len(first) > notionMaxBlocks {
+		first = first[:notionMaxBlocks]
+	}
+	var page notionObject
+	body := map[string]any{"parent": parent, "properties": props}
+	if len(first) > 0 {
+		body["children"] = first
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/pages", body, &page); err != nil {
+		return "", err
+	}
+	if len(blocks) > notionMaxBlocks {
+		if err := s.appendBlocks(ctx, page.ID, blocks[notionMaxBlocks:]); err != nil {
+			return "", fmt.Errorf("page %s created, but appending content failed: %w", page.ID, err)
+		}
+	}
+	return fmt.Sprintf("Created Notion page %q (id %s) %s", page.title(), page.ID, page.URL), nil
+}
+
+func (s *NotionSkill) appendContent(ctx context.Context, id, content string) (string, error) {
+	if id == "" {
+		return "", errors.New("page_id required")
+	}
+	blocks := notionBlocks(content)
+	if len(blocks) == 0 {
+		return "", errors.New("content required")
+	}
+	if err := s.appendBlocks(ctx, id, blocks); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Appended %d block(s) to Notion page %s", len(blocks), id), nil
+}
+
+func (s *NotionSkill) updatePage(ctx context.Context, params map[string]string) (string, error) {
+	id := params["page_id"]
+	if id == "" {
+		return "", errors.New("page_id required")
+	}
+	props, err := notionProperties(params["properties"])
+	if err != nil {
+		return "", err
+	}
+	if title := params["title"]; title != "" {
+		titleProp := params["title_property"]
+		if titleProp == "" {
+			titleProp = "title"
+		}
+		props[titleProp] = map[string]any{"title": notionRichText(title)}
+	}
+	if len(props) == 0 && params["content"] == "" {
+		return "", errors.New("properties, title or content required")
+	}
+	if len(props) > 0 {
+		if err := s.do(ctx, http.MethodPatch, "/v1/pages/"+url.PathEscape(id), map[string]any{"properties": props}, nil); err != nil {
+			return "", err
+		}
+	}
+	if blocks := notionBlocks(params["content"]); len(blocks) > 0 {
+		if err := s.appendBlocks(ctx, id, blocks); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("Updated Notion page %s", id), nil
+}
+
+func (s *NotionSkill) appendBlocks(ctx context.Context, id string, blocks []map[string]any) error {
+	for len(blocks) > 0 {
+		n := min(len(blocks), notionMaxBlocks)
+		if err := s.do(ctx, http.MethodPatch, "/v1/blocks/"+url.PathEscape(id)+"/children", map[string]any{"children": blocks[:n]}, nil); err != nil {
+			return err
+		}
+		blocks = blocks[n:]
+	}
+	return nil
+}
+
+// sortedProperties returns property names in a stable order.
+func sortedProperties(props map[string]notionProperty) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func notionProperties(raw string) (map[string]any, error) {
+	props := map[string]any{}
+	if raw == "" {
+		return props, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &props); err != nil {
+		return nil, fmt.Errorf("properties must be a JSON object: %w", err)
+	}
+	return props, nil
+}
+
+// notionRichText splits text into rich text objects of at most 2000
+// characters, Notion's per-object limit.
+func notionRichText(text string) []map[string]any {
+	var out []map[string]any
+	runes := []rune(text)
+	for len(runes) > 0 {
+		n := min(len(runes), 2000)
+		out = append(out, map[string]any{"type": "text", "text": map[string]string{"content": string(runes[:n])}})
+		runes = runes[n:]
+	}
+	return out
+}
+
+// notionBlocks converts lightweight markdown into Notion blocks.
+func notionBlocks(content string) []map[string]any {
+	var blocks []map[string]any
+	add := func(typ, text string, extra map[string]any) {
+		body := map[string]any{"rich_text": notionRichText(text)}
+		for k, v := range extra {
+			body[k] = v
+		}
+		blocks = append(blocks, map[string]any{"object": "block", "type": typ, typ: body})
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "### "):
+			add("heading_3", trimmed[4:], nil)
+		case strings.HasPrefix(trimmed, "## "):
+			add("heading_2", trimmed[3:], nil)
+		case strings.HasPrefix(trimmed, "# "):
+			add("heading_1", trimmed[2:], nil)
+		case strings.HasPrefix(trimmed, "- [ ] "), strings.HasPrefix(trimmed, "[ ] "):
+			add("to_do", trimmed[strings.Index(trimmed, "]")+2:], map[string]any{"checked": false})
+		case strings.HasPrefix(trimmed, "- [x] "), strings.HasPrefix(trimmed, "[x] "):
+			add("to_do", trimmed[strings.Index(trimmed, "]")+2:], map[string]any{"checked": true})
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			add("bulleted_list_item", trimmed[2:], nil)
+		case strings.HasPrefix(trimmed, "> "):
+			add("quote", trimmed[2:], nil)
+		default:
+			add("paragraph", trimmed, nil)
+		}
+	}
+	return blocks
+}
+
+// --- HTTP ---
+
+func (s *NotionSkill) do(ctx context.Context, method, path string, body, out any) error {
+	if s.cfg.Token == "" {
+		return errors.New("Notion token not configured (notion.token in config.json or NOTION_TOKEN)")
+	}
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.cfg.BaseURL, "/")+path, rd)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	req.Header.Set("Notion-Version", NotionAPIVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notion: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("notion: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("notion: HTTP %d %s: %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("notion: HTTP %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("notion: parse response: %w", err)
+	}
+	return nil
+}
+
+// --- Response types ---
+
+type notionRich struct {
+	PlainText string `json:"plain_text"`
+}
+
+func plainText(rt []notionRich) string {
+	var b strings.Builder
+	for _, r := range rt {
+		b.WriteString(r.PlainText)
+	}
+	return b.String()
+}
+
+type notionProperty struct {
+	Type     string       `json:"type"`
+	Title    []notionRich `json:"title"`
+	RichText []notionRich `json:"rich_text"`
+	Number   *float64     `json:"number"`
+	Checkbox *bool        `json:"checkbox"`
+	URL      *string      `json:"url"`
+	Email    *string      `json:"email"`
+	Select   *struct {
+		Name string `json:"name"`
+	} `json:"select"`
+	Status *struct {
+		Name string `json:"name"`
+	} `json:"status"`
+	MultiSelect []struct {
+		Name string `json:"name"`
+	} `json:"multi_select"`
+	Date *struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"date"`
+	People []struct {
+		Name string `json:"name"`
+	} `json:"people"`
+}
+
+// text renders the property value, or "" for empty and unsupported types.
+func (p notionProperty) text() string {
+	switch p.Type {
+	case "title":
+		return plainText(p.Title)
+	case "rich_text":
+		return plainText(p.RichText)
+	case "number":
+		if p.Number != nil {
+			return strconv.FormatFloat(*p.Number, 'f', -1, 64)
+		}
+	case "checkbox":
+		if p.Checkbox != nil {
+			return strconv.FormatBool(*p.Checkbox)
+		}
+	case "url":
+		if p.URL != nil {
+			return *p.URL
+		}
+	case "email":
+		if p.Email != nil {
+			return *p.Email
+		}
+	case "select":
+		if p.Select != nil {
+			return p.Select.Name
+		}
+	case "status":
+		if p.Status != nil {
+			return p.Status.Name
+		}
+	case "multi_select":
+		names := make([]string, len(p.MultiSelect))
+		for i, o := range p.MultiSelect {
+			names[i] = o.Name
+		}
+		return strings.Join(names, ", ")
+	case "date":
+		if p.Date != nil {
+			if p.Date.End != "" {
+				return p.Date.Start + " → " + p.Date.End
+			}
+			return p.Date.Start
+		}
+	case "people":
+		names := make([]string, len(p.People))
+		for i, o := range p.People {
+			names[i] = o.Name
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// notionObject is a page or database.
+type notionObject struct {
+	Object     string                    `json:"object"`
+	ID         string                    `json:"id"`
+	URL        string                    `json:"url"`
+	Title      []notionRich              `json:"title"` // databases
+	Properties map[string]notionProperty `json:"properties"`
+}
+
+func (o notionObject) title() string {
+	if t := plainText(o.Title); t != "" {
+		return t
+	}
+	for _, p := range o.Properties {
+		if p.Type == "title" {
+			if t := plainText(p.Title); t != "" {
+				return t
+			}
+		}
+	}
+	return "(untitled)"
+}
+
+type notionList struct {
+	Results    []notionObject `json:"results"`
+	HasMore    bool           `json:"has_more"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+type notionBlock struct {
+	Type string
+	Raw  map[string]json.RawMessage
+}
+
+func (b *notionBlock) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.Raw); err != nil {
+		return err
+	}
+	return json.Unmarshal(b.Raw["type"], &b.Type)
+}
+
+// text renders a block as a line of lightweight markdown.
+func (b notionBlock) text() string {
+	var body struct {
+		RichText []notionRich `json:"rich_text"`
+		Checked  bool         `json:"checked"`
+		Title    string       `json:"title"`
+		URL      string       `json:"url"`
+	}
+	if raw, ok := b.Raw[b.Type]; ok {
+		_ = json.Unmarshal(raw, &body)
+	}
+	t := plainText(body.RichText)
+	switch b.Type {
+	case "heading_1":
+		return "# " + t
+	case "heading_2":
+		return "## " + t
+	case "heading_3":
+		return "### " + t
+	case "bulleted_list_item", "numbered_list_item":
+		return "- " + t
+	case "to_do":
+		if body.Checked {
+			return "[x] " + t
+		}
+		return "[ ] " + t
+	case "quote", "callout":
+		return "> " + t
+	case "code":
+		return "```\n" + t + "\n```"
+	case "child_page", "child_database":
+		return "[" + strings.TrimPrefix(b.Type, "child_") + "] " + body.Title
+	case "bookmark", "embed", "link_preview":
+		return body.URL
+	case "divider":
+		return "---"
+	}
+	return t
+}
+
+type notionBlockList struct {
+	Results    []notionBlock `json:"results"`
+	HasMore    bool          `json:"has_more"`
+	NextCursor string        `json:"next_cursor"`
+}
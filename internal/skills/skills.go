@@ -7,10 +7,12 @@
 //   - Communication: Email, Calendar, Messaging, Documents
 //   - Research & Information: WebSearch, PDFAnalysis, DataAggregation, Monitoring
 //   - File & Data: FileOps, DataAnalysis, KnowledgeSearch
-//   - Automation & Security: APIIntegration, Scheduler, Audit, Credentials
+//   - Automation & Security: HTTPRequest, Scheduler, Audit, Credentials
 //
 // Skills that require external services (APIs, binaries) are implemented
 // as stubs that return descriptive errors when their backend is not configured.
+// Integrations without a starter slot (the issues skill) are only added once
+// configured.
 package skills
 
 import (
@@ -23,6 +25,9 @@ type Config struct {
 	DataDir     string           // Base directory for file operations
 	Store       storage.Store    // Persistent storage for credentials, audit, etc.
 	Sandbox     *instruments.DockerSandbox // Docker sandbox for code execution
+	HTTP        HTTPRequestConfig          // Domain allowlist and templates for outbound API calls
+	Notion      NotionConfig               // Notion workspace backing the docs skill
+	Issues      IssuesConfig               // GitHub/Jira trackers for the issues skill
 }
 
 // SkillDef describes a starter skill for registration.
@@ -33,6 +38,7 @@ type SkillDef struct {
 	Description string
 	Type        instruments.SkillType
 	Executor    instruments.SkillExecutor
+	Permissions []string // instruments.Perm* the skill may use
 }
 
 // RegisterAll creates and registers all 20 starter skills.
@@ -47,10 +53,16 @@ func RegisterAll(registry *instruments.SkillRegistry, cfg Config) int {
 		skill := &instruments.Skill{
 			Executor: d.Executor,
 			Meta: instruments.SkillMeta{
-				ID:     d.ID,
-				Name:   d.Name,
-				Type:   d.Type,
-				Status: instruments.SkillStatusActive,
+				ID:          d.ID,
+				Name:        d.Name,
+				Description: d.Description,
+				Type:        d.Type,
+				Status:      instruments.SkillStatusActive,
+				Version:     1,
+				Doc: &instruments.SkillDoc{
+					Summary:     d.Description,
+					Permissions: d.Permissions,
+				},
 			},
 		}
 		registry.Register(skill)
@@ -59,13 +71,14 @@ func RegisterAll(registry *instruments.SkillRegistry, cfg Config) int {
 	return count
 }
 
-// AllSkills returns definitions for all 20 starter skills.
+// AllSkills returns definitions for all 20 starter skills, plus the
+// configured integrations.
 func AllSkills(cfg Config) []SkillDef {
-	return []SkillDef{
+	defs := []SkillDef{
 		// --- Development & Code (5) ---
-		{ID: "skill_code_exec", Name: "Code Execution", Category: "dev", Description: "Run Python/JS/Bash in Docker sandbox", Type: instruments.SkillTypeCode, Executor: NewCodeExecSkill(cfg.Sandbox)},
-		{ID: "skill_git", Name: "Git Management", Category: "dev", Description: "Clone, branch, commit, push, PR", Type: instruments.SkillTypeCode, Executor: NewGitSkill(cfg.DataDir)},
-		{ID: "skill_testing", Name: "Testing & QA", Category: "dev", Description: "Generate and run tests, coverage", Type: instruments.SkillTypeHybrid, Executor: NewTestingSkill(cfg.Sandbox)},
+		{ID: "skill_code_exec", Name: "Code Execution", Category: "dev", Description: "Run Python/JS/Bash in Docker sandbox", Type: instruments.SkillTypeCode, Executor: NewCodeExecSkill(cfg.Sandbox), Permissions: []string{instruments.PermProcess}},
+		{ID: "skill_git", Name: "Git Management", Category: "dev", Description: "Clone, branch, commit, push, PR", Type: instruments.SkillTypeCode, Executor: NewGitSkill(cfg.DataDir), Permissions: []string{instruments.PermFilesystem, instruments.PermNetwork, instruments.PermProcess}},
+		{ID: "skill_testing", Name: "Testing & QA", Category: "dev", Description: "Generate and run tests, coverage", Type: instruments.SkillTypeHybrid, Executor: NewTestingSkill(cfg.Sandbox), Permissions: []string{instruments.PermProcess}},
 		{ID: "skill_browser", Name: "Browser Automation", Category: "dev", Description: "Playwright UI tests, screenshots", Type: instruments.SkillTypeCode, Executor: NewStubSkill("browser", "Browser automation requires Playwright")},
 		{ID: "skill_database", Name: "Database Query", Category: "dev", Description: "SQL queries, migrations, schema analysis", Type: instruments.SkillTypeCode, Executor: NewStubSkill("database", "Database requires connection config")},
 
@@ -73,23 +86,49 @@ func AllSkills(cfg Config) []SkillDef {
 		{ID: "skill_email", Name: "Email Management", Category: "comm", Description: "Read/draft/send via IMAP/SMTP", Type: instruments.SkillTypeCode, Executor: NewStubSkill("email", "Email requires IMAP/SMTP config")},
 		{ID: "skill_calendar", Name: "Calendar Integration", Category: "comm", Description: "Schedule, check slots, invitations", Type: instruments.SkillTypeCode, Executor: NewStubSkill("calendar", "Calendar requires CalDAV/API config")},
 		{ID: "skill_messaging", Name: "Messaging", Category: "comm", Description: "Slack/Discord/Telegram messaging", Type: instruments.SkillTypeCode, Executor: NewStubSkill("messaging", "Messaging requires platform tokens")},
-		{ID: "skill_docs", Name: "Document Collaboration", Category: "comm", Description: "Google Docs, Notion read/edit", Type: instruments.SkillTypeCode, Executor: NewStubSkill("docs", "Document collaboration requires API tokens")},
+		docsSkill(cfg.Notion),
 
 		// --- Research & Information (4) ---
-		{ID: "skill_websearch", Name: "Web Search", Category: "research", Description: "Search + extract data from web", Type: instruments.SkillTypeCode, Executor: NewWebSearchSkill()},
+		{ID: "skill_websearch", Name: "Web Search", Category: "research", Description: "Search + extract data from web", Type: instruments.SkillTypeCode, Executor: NewWebSearchSkill(), Permissions: []string{instruments.PermNetwork}},
 		{ID: "skill_pdf", Name: "PDF & Document Analysis", Category: "research", Description: "Extract text, tables, analyze content", Type: instruments.SkillTypeCode, Executor: NewStubSkill("pdf", "PDF analysis requires poppler or similar")},
-		{ID: "skill_aggregation", Name: "Data Aggregation", Category: "research", Description: "Collect data from sources, normalize", Type: instruments.SkillTypeCode, Executor: NewAPIIntegrationSkill()},
+		{ID: "skill_aggregation", Name: "Data Aggregation", Category: "research", Description: "Collect data from sources, normalize", Type: instruments.SkillTypeCode, Executor: NewHTTPRequestSkill(cfg.HTTP), Permissions: []string{instruments.PermNetwork}},
 		{ID: "skill_monitoring", Name: "Real-time Monitoring", Category: "research", Description: "Track website changes, RSS, prices", Type: instruments.SkillTypeCode, Executor: NewStubSkill("monitoring", "Monitoring requires scheduler + targets config")},
 
 		// --- File & Data Management (3) ---
-		{ID: "skill_fileops", Name: "File Operations", Category: "data", Description: "Read/write, organize, pattern search", Type: instruments.SkillTypeCode, Executor: NewFileOpsSkill(cfg.DataDir)},
+		{ID: "skill_fileops", Name: "File Operations", Category: "data", Description: "Read/write, organize, pattern search", Type: instruments.SkillTypeCode, Executor: NewFileOpsSkill(cfg.DataDir), Permissions: []string{instruments.PermFilesystem}},
 		{ID: "skill_data_analysis", Name: "Data Analysis", Category: "data", Description: "CSV/JSON processing, statistics", Type: instruments.SkillTypeCode, Executor: NewDataAnalysisSkill()},
-		{ID: "skill_knowledge", Name: "Knowledge Base Search", Category: "data", Description: "RAG over documents with semantic search", Type: instruments.SkillTypeCode, Executor: NewKnowledgeSearchSkill(cfg.Store)},
+		{ID: "skill_knowledge", Name: "Knowledge Base Search", Category: "data", Description: "RAG over documents with semantic search", Type: instruments.SkillTypeCode, Executor: NewKnowledgeSearchSkill(cfg.Store), Permissions: []string{instruments.PermStorage}},
 
 		// --- Automation & Security (4) ---
-		{ID: "skill_api", Name: "API Integration", Category: "auto", Description: "REST calls, webhook handling", Type: instruments.SkillTypeCode, Executor: NewAPIIntegrationSkill()},
-		{ID: "skill_scheduler", Name: "Scheduled Tasks", Category: "auto", Description: "Cron tasks, reminders, triggers", Type: instruments.SkillTypeCode, Executor: NewSchedulerSkill(cfg.Store)},
-		{ID: "skill_audit", Name: "Audit & Logging", Category: "auto", Description: "Action logging, audit trail", Type: instruments.SkillTypeCode, Executor: NewAuditSkill(cfg.Store)},
-		{ID: "skill_credentials", Name: "Credential Management", Category: "auto", Description: "Secure API key and token storage", Type: instruments.SkillTypeCode, Executor: NewCredentialSkill(cfg.Store)},
+		{ID: "skill_api", Name: "API Integration", Category: "auto", Description: "Allowlisted REST calls and webhooks with templating", Type: instruments.SkillTypeCode, Executor: NewHTTPRequestSkill(cfg.HTTP), Permissions: []string{instruments.PermEnv, instruments.PermNetwork}},
+		{ID: "skill_scheduler", Name: "Scheduled Tasks", Category: "auto", Description: "Cron tasks, reminders, triggers", Type: instruments.SkillTypeCode, Executor: NewSchedulerSkill(cfg.Store), Permissions: []string{instruments.PermStorage}},
+		{ID: "skill_audit", Name: "Audit & Logging", Category: "auto", Description: "Action logging, audit trail", Type: instruments.SkillTypeCode, Executor: NewAuditSkill(cfg.Store), Permissions: []string{instruments.PermStorage}},
+		{ID: "skill_credentials", Name: "Credential Management", Category: "auto", Description: "Secure API key and token storage", Type: instruments.SkillTypeCode, Executor: NewCredentialSkill(cfg.Store), Permissions: []string{instruments.PermSecrets, instruments.PermStorage}},
 	}
+	if cfg.Issues.Configured() {
+		def := SkillDef{ID: "skill_issues", Name: "Issue Tracker", Category: "dev", Description: "GitHub/Jira: list, search and read issues", Type: instruments.SkillTypeCode, Executor: NewIssuesSkill(cfg.Issues), Permissions: []string{instruments.PermNetwork}}
+		if cfg.Issues.AllowWrites {
+			def.Description = "GitHub/Jira: list, search, create, comment on and transition issues"
+			def.Permissions = append(def.Permissions, instruments.PermExternalWrite)
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// docsSkill is backed by Notion when a token is configured. Writes declare
+// PermExternalWrite so the user approves them before first use.
+func docsSkill(cfg NotionConfig) SkillDef {
+	def := SkillDef{ID: "skill_docs", Name: "Document Collaboration", Category: "comm", Description: "Google Docs, Notion read/edit", Type: instruments.SkillTypeCode, Executor: NewStubSkill("docs", "Document collaboration requires API tokens")}
+	if cfg.Token == "" {
+		return def
+	}
+	def.Description = "Notion: search, query databases, read pages"
+	def.Executor = NewNotionSkill(cfg)
+	def.Permissions = []string{instruments.PermNetwork}
+	if cfg.AllowWrites {
+		def.Description = "Notion: search, query databases, read, create and update pages"
+		def.Permissions = append(def.Permissions, instruments.PermExternalWrite)
+	}
+	return def
 }
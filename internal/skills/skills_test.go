@@ -2,8 +2,13 @@ package skills
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/overhuman/overhuman/internal/instruments"
@@ -59,6 +64,18 @@ func TestRegisterAll(t *testing.T) {
 	}
 }
 
+func TestRegisterAll_Docs(t *testing.T) {
+	registry := instruments.NewSkillRegistry()
+	RegisterAll(registry, Config{})
+	s := registry.Get("skill_git")
+	if s == nil || s.Meta.Doc == nil {
+		t.Fatal("skill_git has no doc")
+	}
+	if s.Meta.Doc.Summary != s.Meta.Description || len(s.Meta.Doc.Permissions) == 0 {
+		t.Errorf("doc = %+v", s.Meta.Doc)
+	}
+}
+
 // --- Stub skill tests ---
 
 func TestStubSkill(t *testing.T) {
@@ -158,6 +175,44 @@ func TestFileOpsSkill_ReadWrite(t *testing.T) {
 	}
 }
 
+func TestFileOpsSkill_Scratch(t *testing.T) {
+	base := t.TempDir()
+	scratch, err := instruments.NewScratch(t.TempDir(), "task_1", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := instruments.WithScratch(context.Background(), scratch)
+	s := NewFileOpsSkill(base)
+
+	out, _ := s.Execute(ctx, instruments.SkillInput{
+		Parameters: map[string]string{"action": "write", "path": "step1.txt", "content": "hello"},
+	})
+	if !out.Success {
+		t.Fatalf("write failed: %s", out.Error)
+	}
+	if _, err := os.Stat(filepath.Join(base, "step1.txt")); !os.IsNotExist(err) {
+		t.Error("write within a run went to the base directory")
+	}
+	out, _ = s.Execute(ctx, instruments.SkillInput{
+		Parameters: map[string]string{"action": "read", "path": "step1.txt"},
+	})
+	if !out.Success || out.Result != "hello" {
+		t.Errorf("read: success=%v, result=%q", out.Success, out.Result)
+	}
+	out, _ = s.Execute(ctx, instruments.SkillInput{
+		Parameters: map[string]string{"action": "read", "path": "../../etc/passwd"},
+	})
+	if out.Success {
+		t.Error("read outside the scratch directory allowed")
+	}
+	out, _ = s.Execute(ctx, instruments.SkillInput{
+		Parameters: map[string]string{"action": "write", "path": "big.txt", "content": strings.Repeat("x", 20)},
+	})
+	if out.Success || !strings.Contains(out.Error, "quota") {
+		t.Errorf("write over quota: success=%v, error=%q", out.Success, out.Error)
+	}
+}
+
 func TestFileOpsSkill_List(t *testing.T) {
 	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644)
@@ -288,13 +343,351 @@ func TestComputeStats_Empty(t *testing.T) {
 	}
 }
 
-// --- API Integration tests ---
+// --- HTTP Request tests ---
 
-func TestAPIIntegration_NoURL(t *testing.T) {
-	s := NewAPIIntegrationSkill()
-	out, _ := s.Execute(context.Background(), instruments.SkillInput{})
-	if out.Success {
-		t.Error("should fail without URL")
+func newHTTPTestServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big":
+			w.Write([]byte(strings.Repeat("x", 200)))
+		case "/redirect":
+			http.Redirect(w, r, "http://evil.invalid/steal", http.StatusFound)
+		default:
+			body, _ := io.ReadAll(r.Body)
+			w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " auth=" + r.Header.Get("Authorization") + " body=" + string(body)))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	return srv, u.Hostname()
+}
+
+func TestHTTPRequest_EndpointTemplating(t *testing.T) {
+	srv, host := newHTTPTestServer(t)
+	t.Setenv("TEST_HTTP_TOKEN", "secret")
+	s := NewHTTPRequestSkill(HTTPRequestConfig{
+		AllowedDomains: []string{host},
+		Endpoints: map[string]HTTPEndpoint{
+			"ticket": {
+				Method:  "POST",
+				URL:     srv.URL + "/issues?project={{project}}",
+				Headers: map[string]string{"Authorization": "Bearer $TEST_HTTP_TOKEN", "Content-Type": "application/json"},
+				Body:    `{"summary":"{{summary}}"}`,
+			},
+		},
+	})
+
+	out, _ := s.Execute(context.Background(), instruments.SkillInput{Parameters: map[string]string{
+		"endpoint": "ticket",
+		"project":  "OPS & DEV",
+		"summary":  `Disk "full"`,
+	}})
+	if !out.Success {
+		t.Fatalf("request failed: %s", out.Error)
+	}
+	for _, want := range []string{"POST /issues?project=OPS+%26+DEV", "auth=Bearer secret", `body={"summary":"Disk \"full\""}`} {
+		if !strings.Contains(out.Result, want) {
+			t.Errorf("result %q missing %q", out.Result, want)
+		}
+	}
+
+	out, _ = s.Execute(context.Background(), instruments.SkillInput{Parameters: map[string]string{"endpoint": "ticket"}})
+	if out.Success || !strings.Contains(out.Error, "missing parameter") {
+		t.Errorf("missing placeholder: %+v", out)
+	}
+}
+
+func TestHTTPRequest_Policy(t *testing.T) {
+	srv, host := newHTTPTestServer(t)
+	s := NewHTTPRequestSkill(HTTPRequestConfig{AllowedDomains: []string{host, "*.example.com"}, MaxResponseBytes: 50})
+	run := func(params map[string]string) *instruments.SkillOutput {
+		out, _ := s.Execute(context.Background(), instruments.SkillInput{Parameters: params})
+		return out
+	}
+
+	if out := run(map[string]string{"url": "https://api.other.com/x"}); out.Success || !strings.Contains(out.Error, "allowlist") {
+		t.Errorf("disallowed domain: %+v", out)
+	}
+	if err := s.checkHost(&url.URL{Host: "jira.example.com"}); err != nil {
+		t.Errorf("wildcard subdomain rejected: %v", err)
+	}
+	if err := s.checkHost(&url.URL{Host: "example.com.evil.net"}); err == nil {
+		t.Error("suffix trick should be rejected")
+	}
+	if out := run(map[string]string{"url": srv.URL, "method": "DELETE"}); out.Success || !strings.Contains(out.Error, "not allowed") {
+		t.Errorf("DELETE: %+v", out)
+	}
+	if out := run(map[string]string{"url": "file:///etc/passwd"}); out.Success {
+		t.Error("file scheme should be rejected")
+	}
+	if out := run(map[string]string{"url": srv.URL + "/redirect"}); out.Success || !strings.Contains(out.Error, "allowlist") {
+		t.Errorf("redirect off the allowlist: %+v", out)
+	}
+
+	out := run(map[string]string{"url": srv.URL + "/big"})
+	if !out.Success || !strings.Contains(out.Result, "[truncated at 50 bytes]") || strings.Count(out.Result, "x") != 50 {
+		t.Errorf("response cap: %q", out.Result)
+	}
+
+	if NewHTTPRequestSkill(HTTPRequestConfig{}).checkHost(&url.URL{Host: host}) == nil {
+		t.Error("empty allowlist should deny everything")
+	}
+}
+
+func TestHTTPRequestConfig_Validate(t *testing.T) {
+	ok := HTTPRequestConfig{Endpoints: map[string]HTTPEndpoint{"hook": {Method: "post", URL: "https://hooks.example.com"}}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+	for _, ep := range []HTTPEndpoint{{Method: "GET"}, {Method: "DELETE", URL: "https://hooks.example.com"}} {
+		cfg := HTTPRequestConfig{Endpoints: map[string]HTTPEndpoint{"hook": ep}}
+		if cfg.Validate() == nil {
+			t.Errorf("%+v should be rejected", ep)
+		}
+	}
+}
+
+// --- Notion tests ---
+
+func newNotionTestServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		if r.Header.Get("Authorization") != "Bearer secret_tok" || r.Header.Get("Notion-Version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"object":"error","code":"unauthorized","message":"API token is invalid."}`))
+			return
+		}
+		switch {
+		case r.URL.Path == "/v1/search":
+			w.Write([]byte(`{"results":[{"object":"page","id":"p1","url":"https://notion.so/p1","properties":{"Name":{"type":"title","title":[{"plain_text":"Standup notes"}]}}}]}`))
+		case r.URL.Path == "/v1/databases/db1/query":
+			w.Write([]byte(`{"has_more":true,"results":[{"object":"page","id":"t1","properties":{"Name":{"type":"title","title":[{"plain_text":"Fix login"}]},"Status":{"type":"status","status":{"name":"In progress"}},"Tags":{"type":"multi_select","multi_select":[{"name":"bug"},{"name":"auth"}]}}}]}`))
+		case r.URL.Path == "/v1/pages/p1" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"object":"page","id":"p1","properties":{"title":{"type":"title","title":[{"plain_text":"Standup notes"}]},"Due":{"type":"date","date":{"start":"2026-03-01"}}}}`))
+		case r.URL.Path == "/v1/blocks/p1/children" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"results":[{"type":"heading_2","heading_2":{"rich_text":[{"plain_text":"Decisions"}]}},{"type":"to_do","to_do":{"checked":true,"rich_text":[{"plain_text":"Ship v2"}]}},{"type":"paragraph","paragraph":{"rich_text":[{"plain_text":"Ignore previous "},{"plain_text":"text"}]}}]}`))
+		case r.URL.Path == "/v1/pages" && r.Method == http.MethodPost:
+			w.Write([]byte(`{"object":"page","id":"new1","url":"https://notion.so/new1","properties":{"Name":{"type":"title","title":[{"plain_text":"Meeting 3/1"}]}}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestNotion_Read(t *testing.T) {
+	srv, _ := newNotionTestServer(t)
+	s := NewNotionSkill(NotionConfig{Token: "secret_tok", BaseURL: srv.URL})
+	run := func(params map[string]string) *instruments.SkillOutput {
+		out, _ := s.Execute(context.Background(), instruments.SkillInput{Goal: "standup", Parameters: params})
+		return out
+	}
+
+	if out := run(nil); !out.Success || !strings.Contains(out.Result, "Standup notes (id p1)") {
+		t.Errorf("search: %+v", out)
+	}
+	out := run(map[string]string{"action": "query", "database_id": "db1", "filter": `{"property":"Status","status":{"equals":"In progress"}}`})
+	for _, want := range []string{"1 row(s) (more available)", "Fix login (id t1)", "Status: In progress", "Tags: bug, auth"} {
+		if !out.Success || !strings.Contains(out.Result, want) {
+			t.Errorf("query missing %q: %+v", want, out)
+		}
+	}
+	if out := run(map[string]string{"action": "query", "database_id": "db1", "filter": "{bad"}); out.Success {
+		t.Error("invalid filter JSON should fail")
+	}
+	out = run(map[string]string{"action": "read", "page_id": "p1"})
+	for _, want := range []string{"# Standup notes", "Due: 2026-03-01", "## Decisions", "[x] Ship v2", "Ignore previous text"} {
+		if !out.Success || !strings.Contains(out.Result, want) {
+			t.Errorf("read missing %q: %+v", want, out)
+		}
+	}
+
+	bad := NewNotionSkill(NotionConfig{Token: "wrong", BaseURL: srv.URL})
+	out, _ = bad.Execute(context.Background(), instruments.SkillInput{Goal: "x"})
+	if out.Success || !strings.Contains(out.Error, "API token is invalid") {
+		t.Errorf("auth error: %+v", out)
+	}
+}
+
+func TestNotion_Write(t *testing.T) {
+	srv, calls := newNotionTestServer(t)
+	params := map[string]string{
+		"action":             "create",
+		"parent_database_id": "db1",
+		"title":              "Meeting 3/1",
+		"content":            "# Agenda\n- budget\n[ ] send recap\nplain line",
+	}
+
+	ro := NewNotionSkill(NotionConfig{Token: "secret_tok", BaseURL: srv.URL})
+	out, _ := ro.Execute(context.Background(), instruments.SkillInput{Parameters: params})
+	if out.Success || !strings.Contains(out.Error, "allow_writes") || len(*calls) != 0 {
+		t.Fatalf("write without allow_writes: %+v, calls %v", out, *calls)
+	}
+
+	rw := NewNotionSkill(NotionConfig{Token: "secret_tok", BaseURL: srv.URL, AllowWrites: true})
+	out, _ = rw.Execute(context.Background(), instruments.SkillInput{Parameters: params})
+	if !out.Success || !strings.Contains(out.Result, "new1") {
+		t.Fatalf("create: %+v", out)
+	}
+	req := (*calls)[0]
+	for _, want := range []string{`"database_id":"db1"`, `"Name":{"title"`, `"heading_1"`, `"bulleted_list_item"`, `"to_do"`, `"checked":false`, `"paragraph"`} {
+		if !strings.Contains(req, want) {
+			t.Errorf("create request missing %s: %s", want, req)
+		}
+	}
+
+	out, _ = rw.Execute(context.Background(), instruments.SkillInput{Parameters: map[string]string{
+		"action": "update", "page_id": "p1", "properties": `{"Status":{"status":{"name":"Done"}}}`, "content": "follow-up",
+	}})
+	if !out.Success || len(*calls) != 3 || !strings.HasPrefix((*calls)[1], "PATCH /v1/pages/p1") || !strings.HasPrefix((*calls)[2], "PATCH /v1/blocks/p1/children") {
+		t.Errorf("update: %+v, calls %v", out, *calls)
+	}
+}
+
+func TestRegisterAll_Notion(t *testing.T) {
+	registry := instruments.NewSkillRegistry()
+	RegisterAll(registry, Config{Notion: NotionConfig{Token: "tok", AllowWrites: true}})
+	s := registry.Get("skill_docs")
+	if _, ok := s.Executor.(*NotionSkill); !ok {
+		t.Fatalf("skill_docs executor = %T", s.Executor)
+	}
+	if perms := s.Meta.Doc.Permissions; len(perms) != 2 || perms[1] != instruments.PermExternalWrite {
+		t.Errorf("permissions = %v", perms)
+	}
+}
+
+// --- Issues tests ---
+
+func TestIssues_GitHub(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		if r.Header.Get("Authorization") != "Bearer gh_tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		switch {
+		case r.URL.Path == "/repos/acme/api/issues" && r.Method == http.MethodGet:
+			w.Write([]byte(`[{"number":7,"title":"Crash on login","state":"open","labels":[{"name":"bug"}],"assignee":{"login":"ann"}},{"number":8,"title":"A PR","state":"open","pull_request":{"url":"x"}}]`))
+		case r.URL.Path == "/search/issues":
+			w.Write([]byte(`{"items":[{"number":3,"title":"Slow search","state":"open","repository_url":"https://api.github.com/repos/acme/web"}]}`))
+		case r.URL.Path == "/repos/acme/api/issues" && r.Method == http.MethodPost:
+			w.Write([]byte(`{"number":9,"title":"New","state":"open","html_url":"https://github.com/acme/api/issues/9"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := IssuesConfig{GitHub: GitHubIssuesConfig{Token: "gh_tok", BaseURL: srv.URL, DefaultRepo: "acme/api"}}
+	run := func(s *IssuesSkill, params map[string]string) *instruments.SkillOutput {
+		out, _ := s.Execute(context.Background(), instruments.SkillInput{Goal: "slow", Parameters: params})
+		return out
+	}
+	ro := NewIssuesSkill(cfg)
+
+	out := run(ro, nil)
+	if !out.Success || !strings.Contains(out.Result, "acme/api#7 [open] Crash on login (@ann) {bug}") || strings.Contains(out.Result, "A PR") {
+		t.Errorf("list: %+v", out)
+	}
+	out = run(ro, map[string]string{"action": "search"})
+	if !out.Success || !strings.Contains(out.Result, "acme/web#3") || !strings.Contains(calls[len(calls)-1], "q=slow+is%3Aissue+repo%3Aacme%2Fapi") {
+		t.Errorf("search: %+v, %s", out, calls[len(calls)-1])
+	}
+	n := len(calls)
+	if out = run(ro, map[string]string{"action": "create", "title": "New"}); out.Success || len(calls) != n {
+		t.Errorf("create without allow_writes: %+v", out)
+	}
+	if out = run(ro, map[string]string{"action": "get", "id": "abc"}); out.Success {
+		t.Error("non-numeric GitHub id should fail")
+	}
+
+	cfg.AllowWrites = true
+	rw := NewIssuesSkill(cfg)
+	out = run(rw, map[string]string{"action": "create", "title": "New", "labels": "bug, triage"})
+	if !out.Success || !strings.Contains(out.Result, "acme/api#9") || !strings.Contains(calls[len(calls)-1], `"labels":["bug","triage"]`) {
+		t.Errorf("create: %+v, %s", out, calls[len(calls)-1])
+	}
+	out = run(rw, map[string]string{"action": "transition", "id": "acme/api#7", "to": "closed"})
+	if !out.Success || !strings.HasPrefix(calls[len(calls)-1], "PATCH /repos/acme/api/issues/7") || !strings.Contains(calls[len(calls)-1], `"state_reason":"completed"`) {
+		t.Errorf("transition: %+v, %s", out, calls[len(calls)-1])
+	}
+
+	if out = run(rw, map[string]string{"tracker": "jira"}); out.Success || !strings.Contains(out.Error, "not configured") {
+		t.Errorf("unconfigured tracker: %+v", out)
+	}
+	bad := NewIssuesSkill(IssuesConfig{GitHub: GitHubIssuesConfig{Token: "nope", BaseURL: srv.URL, DefaultRepo: "acme/api"}})
+	if out = run(bad, nil); out.Success || !strings.Contains(out.Error, "Bad credentials") {
+		t.Errorf("auth error: %+v", out)
+	}
+}
+
+func TestIssues_Jira(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+"?"+r.URL.Query().Get("jql")+" "+string(body))
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@acme.io" || pass != "jira_tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/rest/api/2/search":
+			w.Write([]byte(`{"issues":[{"key":"OPS-12","fields":{"summary":"Disk full","status":{"name":"To Do"},"labels":["infra"]}}]}`))
+		case r.URL.Path == "/rest/api/2/issue/OPS-12/transitions" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"transitions":[{"id":"21","name":"Start work","to":{"name":"In Progress"}},{"id":"31","name":"Resolve","to":{"name":"Done"}}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	s := NewIssuesSkill(IssuesConfig{
+		Jira:        JiraIssuesConfig{BaseURL: srv.URL, Email: "me@acme.io", Token: "jira_tok", DefaultProject: "OPS"},
+		AllowWrites: true,
+	})
+	run := func(params map[string]string) *instruments.SkillOutput {
+		out, _ := s.Execute(context.Background(), instruments.SkillInput{Parameters: params})
+		return out
+	}
+
+	out := run(nil)
+	if !out.Success || !strings.Contains(out.Result, "OPS-12 [To Do] Disk full {infra}") {
+		t.Errorf("list: %+v", out)
+	}
+	if !strings.Contains(calls[0], `project = "OPS" AND statusCategory != Done`) {
+		t.Errorf("list JQL: %s", calls[0])
+	}
+	run(map[string]string{"action": "search", "query": `disk "full"`})
+	if !strings.Contains(calls[1], `project = "OPS" AND text ~ "disk \"full\""`) {
+		t.Errorf("search JQL: %s", calls[1])
+	}
+	run(map[string]string{"action": "search", "query": "assignee = currentUser()"})
+	if !strings.Contains(calls[2], "?assignee = currentUser() ") {
+		t.Errorf("raw JQL: %s", calls[2])
+	}
+
+	out = run(map[string]string{"action": "transition", "id": "OPS-12", "to": "in progress"})
+	if !out.Success || !strings.Contains(calls[len(calls)-1], `{"transition":{"id":"21"}}`) {
+		t.Errorf("transition: %+v, %s", out, calls[len(calls)-1])
+	}
+	out = run(map[string]string{"action": "transition", "id": "OPS-12", "to": "Blocked"})
+	if out.Success || !strings.Contains(out.Error, "available: Start work, Resolve") {
+		t.Errorf("unknown transition: %+v", out)
+	}
+}
+
+func TestAllSkills_Issues(t *testing.T) {
+	defs := AllSkills(Config{Issues: IssuesConfig{GitHub: GitHubIssuesConfig{Token: "t"}}})
+	if len(defs) != 21 || defs[20].ID != "skill_issues" || len(defs[20].Permissions) != 1 {
+		t.Fatalf("defs = %d, last %+v", len(defs), defs[len(defs)-1])
 	}
 }
 
@@ -557,3 +950,125 @@ func TestKnowledge_NoStore(t *testing.T) {
 		t.Error("should fail without store")
 	}
 }
+
+// --- Template Skill Tests ---
+
+func TestTemplateSkill_RequestExtractResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"city":"` + r.URL.Query().Get("q") + `","current":[{"temp":21.5,"desc":"sunny"}],"tags":[{"n":"a"},{"n":"b"}]}`))
+	}))
+	defer srv.Close()
+
+	s, err := NewTemplateSkill(TemplateSpec{
+		ID:       "weather",
+		Params:   map[string]TemplateParam{"city": {Pattern: `(?i)weather in (\w+)`, Default: "Berlin"}},
+		Request:  &HTTPEndpoint{URL: srv.URL + "/w?q={{city}}"},
+		Extract:  map[string]string{"temp": ".current[0].temp", "desc": `current[0]["desc"]`, "tags": ".tags[].n"},
+		Response: "{{city}}: {{desc}}, {{temp}}°C ({{tags}})",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := s.Execute(context.Background(), instruments.SkillInput{Goal: "What's the weather in Paris?"})
+	if !out.Success || out.Result != "Paris: sunny, 21.5°C (a, b)" {
+		t.Errorf("goal param: %+v", out)
+	}
+	out, _ = s.Execute(context.Background(), instruments.SkillInput{Goal: "forecast please"})
+	if !out.Success || !strings.HasPrefix(out.Result, "Berlin:") {
+		t.Errorf("default param: %+v", out)
+	}
+	out, _ = s.Execute(context.Background(), instruments.SkillInput{Goal: "weather", Parameters: map[string]string{"city": "Oslo"}})
+	if !strings.HasPrefix(out.Result, "Oslo:") {
+		t.Errorf("explicit param: %+v", out)
+	}
+	if out.CostUSD != 0 {
+		t.Errorf("template skills are free, cost = %v", out.CostUSD)
+	}
+}
+
+func TestTemplateSkill_Failures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"a":1}`))
+	}))
+	defer srv.Close()
+
+	for name, spec := range map[string]TemplateSpec{
+		"http error":    {ID: "x", Request: &HTTPEndpoint{URL: srv.URL + "/missing"}},
+		"no value":      {ID: "x", Request: &HTTPEndpoint{URL: srv.URL}, Extract: map[string]string{"b": ".b"}},
+		"missing param": {ID: "x", Params: map[string]TemplateParam{"who": {}}, Response: "hi {{who}}"},
+	} {
+		s, err := NewTemplateSkill(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if out, _ := s.Execute(context.Background(), instruments.SkillInput{Goal: "go"}); out.Success {
+			t.Errorf("%s: expected failure, got %+v", name, out)
+		}
+	}
+
+	for name, spec := range map[string]TemplateSpec{
+		"no id":          {Response: "x"},
+		"nothing to do":  {ID: "x"},
+		"host template":  {ID: "x", Request: &HTTPEndpoint{URL: "https://{{host}}/api"}},
+		"bad scheme":     {ID: "x", Request: &HTTPEndpoint{URL: "file:///etc/passwd"}},
+		"bad pattern":    {ID: "x", Response: "x", Params: map[string]TemplateParam{"a": {Pattern: "("}}},
+		"bad path":       {ID: "x", Request: &HTTPEndpoint{URL: "https://example.com"}, Extract: map[string]string{"a": ".a[x]"}},
+		"extract no req": {ID: "x", Response: "x", Extract: map[string]string{"a": ".a"}},
+	} {
+		if _, err := NewTemplateSkill(spec); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestRegisterTemplates(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "greet.yaml"), []byte(`# a greeting
+name: Greeter
+description: Says hello
+triggers: [say hello, greet]
+params:
+  who:
+    pattern: 'greet (\w+)'
+    default: world
+request:
+  method: POST
+  url: "https://hooks.example.com/greet"
+  headers:
+    Authorization: "Bearer $GREET_TOKEN"
+response: "hello {{who}}"
+`), 0o644)
+	os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("response: {{x}\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644)
+
+	reg := instruments.NewSkillRegistry()
+	n, errs := RegisterTemplates(reg, dir)
+	if n != 1 || len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken.yaml") {
+		t.Fatalf("RegisterTemplates = %d, %v", n, errs)
+	}
+	sk := reg.Get("tmpl_greet")
+	if sk == nil || sk.Meta.Name != "Greeter" || len(sk.Meta.Triggers) != 2 {
+		t.Fatalf("registered skill = %+v", sk)
+	}
+	if got := strings.Join(sk.Meta.Doc.Permissions, ","); got != "env,external_write,network" {
+		t.Errorf("permissions = %s", got)
+	}
+	if reg.FindByTrigger("please greet Ana") != sk {
+		t.Error("trigger should select the template")
+	}
+
+	// Reloading replaces the skill instead of duplicating it.
+	RegisterTemplates(reg, dir)
+	if reg.Count() != 1 {
+		t.Errorf("Count after reload = %d", reg.Count())
+	}
+	if n, errs := RegisterTemplates(reg, filepath.Join(dir, "none")); n != 0 || errs != nil {
+		t.Errorf("missing dir = %d, %v", n, errs)
+	}
+}
@@ -0,0 +1,433 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/instruments"
+	"github.com/overhuman/overhuman/internal/yamlite"
+)
+
+// --- Template Skills ---
+
+// TemplateIDPrefix prefixes the registry ID of every template skill.
+const TemplateIDPrefix = "tmpl_"
+
+// TemplateSpec is a hand-written skill: an optional HTTP call, values
+// extracted from its JSON response and a response template. Templates
+// live as YAML (or JSON) files in the data dir's skills/templates and
+// cover simple automations without generating code:
+//
+//	name: Weather
+//	description: Current weather for a city
+//	triggers: [weather, forecast]
+//	params:
+//	  city:
+//	    pattern: '(?i)weather in (\w+)'
+//	    default: Berlin
+//	request:
+//	  url: "https://wttr.in/{{city}}?format=j1"
+//	extract:
+//	  temp: .current_condition[0].temp_C
+//	  desc: .current_condition[0].weatherDesc[0].value
+//	response: "{{city}}: {{desc}}, {{temp}}°C"
+type TemplateSpec struct {
+	// ID defaults to the file name without extension.
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Triggers are keywords or phrases that select the skill when they
+	// occur in a goal; Fingerprint binds it to a known task pattern.
+	Triggers    []string `json:"triggers,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+
+	Params   map[string]TemplateParam `json:"params,omitempty"`
+	Request  *HTTPEndpoint            `json:"request,omitempty"`
+	Extract  map[string]string        `json:"extract,omitempty"`
+	Response string                   `json:"response,omitempty"`
+}
+
+// TemplateParam is a {{name}} value: the skill parameter of that name, else
+// the first group of Pattern matched against the goal, else Default.
+type TemplateParam struct {
+	Description string `json:"description,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// TemplateSkill executes a TemplateSpec. It never calls an LLM.
+type TemplateSkill struct {
+	spec     TemplateSpec
+	patterns map[string]*regexp.Regexp
+	http     *HTTPRequestSkill // nil without a request
+}
+
+// NewTemplateSkill validates spec and creates its skill. The request may
+// only reach the host written in its URL.
+func NewTemplateSkill(spec TemplateSpec) (*TemplateSkill, error) {
+	if spec.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	if spec.Request == nil && spec.Response == "" {
+		return nil, errors.New("a request or a response is required")
+	}
+	if spec.Request == nil && len(spec.Extract) > 0 {
+		return nil, errors.New("extract needs a request")
+	}
+	s := &TemplateSkill{spec: spec, patterns: make(map[string]*regexp.Regexp)}
+	for name, p := range spec.Params {
+		if p.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("params.%s.pattern: %w", name, err)
+		}
+		s.patterns[name] = re
+	}
+	for name, path := range spec.Extract {
+		if _, err := parseJSONPath(path); err != nil {
+			return nil, fmt.Errorf("extract.%s: %w", name, err)
+		}
+	}
+	if spec.Request != nil {
+		host, err := templateHost(spec.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("request.url: %w", err)
+		}
+		s.http = NewHTTPRequestSkill(HTTPRequestConfig{
+			AllowedDomains: []string{host},
+			Endpoints:      map[string]HTTPEndpoint{"template": *spec.Request},
+		})
+	}
+	return s, nil
+}
+
+// templateHost returns the literal host of a request URL; placeholders may
+// fill the path and query but not pick the host.
+func templateHost(raw string) (string, error) {
+	u, err := url.Parse(placeholderRe.ReplaceAllString(raw, "x"))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("want an http(s) URL, got %q", raw)
+	}
+	if i := strings.Index(raw, "://"); i >= 0 {
+		rest := raw[i+3:]
+		if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+			rest = rest[:end]
+		}
+		if strings.Contains(rest, "{{") {
+			return "", errors.New("the host cannot contain placeholders")
+		}
+	}
+	return u.Hostname(), nil
+}
+
+func (s *TemplateSkill) Execute(ctx context.Context, input instruments.SkillInput) (*instruments.SkillOutput, error) {
+	start := time.Now()
+	fail := func(err error) (*instruments.SkillOutput, error) {
+		return &instruments.SkillOutput{Success: false, Error: err.Error(), ElapsedMs: time.Since(start).Milliseconds()}, nil
+	}
+
+	vars, err := s.params(input)
+	if err != nil {
+		return fail(err)
+	}
+
+	var body []byte
+	if s.http != nil {
+		params := make(map[string]string, len(vars)+1)
+		for k, v := range vars {
+			params[k] = v
+		}
+		params["endpoint"] = "template"
+		resp, err := s.http.do(ctx, params)
+		if err != nil {
+			return fail(err)
+		}
+		if !resp.ok() {
+			return fail(fmt.Errorf("HTTP %d", resp.status))
+		}
+		body = resp.body
+		vars["body"] = string(body)
+	}
+
+	if len(s.spec.Extract) > 0 {
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return fail(fmt.Errorf("response is not JSON: %w", err))
+		}
+		for name, path := range s.spec.Extract {
+			v, err := extractJSON(doc, path)
+			if err != nil {
+				return fail(fmt.Errorf("extract %s: %w", name, err))
+			}
+			vars[name] = v
+		}
+	}
+
+	result := string(body)
+	if s.spec.Response != "" {
+		if result, err = fillTemplate(s.spec.Response, vars, func(v string) string { return v }); err != nil {
+			return fail(fmt.Errorf("response: %w", err))
+		}
+	}
+	return &instruments.SkillOutput{
+		Result:    strings.TrimSpace(result),
+		Success:   true,
+		ElapsedMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// params resolves the declared parameters, plus {{goal}}.
+func (s *TemplateSkill) params(input instruments.SkillInput) (map[string]string, error) {
+	vars := map[string]string{"goal": input.Goal}
+	var missing []string
+	for name, p := range s.spec.Params {
+		v := input.Parameters[name]
+		if v == "" && s.patterns[name] != nil {
+			if m := s.patterns[name].FindStringSubmatch(input.Goal); len(m) > 1 {
+				v = strings.TrimSpace(m[1])
+			}
+		}
+		if v == "" {
+			v = p.Default
+		}
+		if v == "" {
+			missing = append(missing, name)
+			continue
+		}
+		vars[name] = v
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing parameter(s): %s", strings.Join(missing, ", "))
+	}
+	return vars, nil
+}
+
+// permissions are what the template can do: network access for a request,
+// environment variables for "$VAR" headers and external writes for POSTs.
+func (s *TemplateSkill) permissions() []string {
+	if s.spec.Request == nil {
+		return nil
+	}
+	perms := []string{instruments.PermNetwork}
+	for _, v := range s.spec.Request.Headers {
+		if strings.Contains(v, "$") {
+			perms = append(perms, instruments.PermEnv)
+			break
+		}
+	}
+	if strings.EqualFold(s.spec.Request.Method, "POST") {
+		perms = append(perms, instruments.PermExternalWrite)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// Skill wraps the template as a registry entry.
+func (s *TemplateSkill) Skill() *instruments.Skill {
+	spec := s.spec
+	name := spec.Name
+	if name == "" {
+		name = spec.ID
+	}
+	doc := &instruments.SkillDoc{
+		Summary:     spec.Description,
+		Permissions: s.permissions(),
+		Language:    "template",
+	}
+	if doc.Summary == "" {
+		doc.Summary = "Template skill " + name
+	}
+	names := make([]string, 0, len(spec.Params))
+	for n := range spec.Params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		p := spec.Params[n]
+		doc.Inputs = append(doc.Inputs, instruments.SkillParam{Name: n, Description: p.Description, Required: p.Default == ""})
+	}
+	now := time.Now()
+	return &instruments.Skill{
+		Executor: s,
+		Meta: instruments.SkillMeta{
+			ID:          TemplateIDPrefix + spec.ID,
+			Name:        name,
+			Description: doc.Summary,
+			Type:        instruments.SkillTypeCode,
+			Status:      instruments.SkillStatusActive,
+			Fingerprint: spec.Fingerprint,
+			Triggers:    spec.Triggers,
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Doc:         doc,
+		},
+	}
+}
+
+// LoadTemplates reads every *.yaml, *.yml and *.json template in dir. A
+// missing dir has no templates; broken files are reported per file and
+// skipped.
+func LoadTemplates(dir string) ([]*TemplateSkill, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+	var out []*TemplateSkill
+	var errs []error
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		s, err := loadTemplate(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, errs
+}
+
+func loadTemplate(path string) (*TemplateSkill, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec TemplateSpec
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, &spec)
+	} else {
+		err = yamlite.Decode(data, &spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if spec.ID == "" {
+		spec.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return NewTemplateSkill(spec)
+}
+
+// RegisterTemplates loads the templates in dir into registry, replacing
+// earlier versions of the same template. It returns how many were
+// registered and the files that could not be loaded.
+func RegisterTemplates(registry *instruments.SkillRegistry, dir string) (int, []error) {
+	templates, errs := LoadTemplates(dir)
+	for _, t := range templates {
+		sk := t.Skill()
+		registry.Remove(sk.Meta.ID)
+		registry.Register(sk)
+	}
+	return len(templates), errs
+}
+
+// jsonPathRe matches one step of an extraction path: .key, ["key"], [n]
+// or [] (every element).
+var jsonPathRe = regexp.MustCompile(`^(?:\.([A-Za-z0-9_$@-]+)|\["([^"]*)"\]|\[(\d+)\]|\[\])`)
+
+type jsonPathStep struct {
+	key   string
+	index int // -1: key step, -2: every element
+}
+
+// parseJSONPath parses a jq-style path such as .items[0].name or
+// .tags[].label. A leading dot is optional.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	p := strings.TrimSpace(path)
+	if p == "" || p == "." {
+		return nil, nil
+	}
+	if p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+	var steps []jsonPathStep
+	for p != "" {
+		m := jsonPathRe.FindStringSubmatch(p)
+		if m == nil {
+			return nil, fmt.Errorf("bad path %q near %q", path, p)
+		}
+		switch {
+		case m[1] != "":
+			steps = append(steps, jsonPathStep{key: m[1], index: -1})
+		case strings.HasPrefix(m[0], `["`):
+			steps = append(steps, jsonPathStep{key: m[2], index: -1})
+		case m[3] != "":
+			n, _ := strconv.Atoi(m[3])
+			steps = append(steps, jsonPathStep{index: n})
+		default:
+			steps = append(steps, jsonPathStep{index: -2})
+		}
+		p = p[len(m[0]):]
+	}
+	return steps, nil
+}
+
+// extractJSON evaluates path against doc. Strings come out as-is, other
+// values as JSON; several results ([] steps) are joined with ", ".
+func extractJSON(doc any, path string) (string, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	values := []any{doc}
+	for _, st := range steps {
+		var next []any
+		for _, v := range values {
+			switch {
+			case st.index == -1:
+				if m, ok := v.(map[string]any); ok {
+					if x, ok := m[st.key]; ok {
+						next = append(next, x)
+					}
+				}
+			case st.index == -2:
+				if a, ok := v.([]any); ok {
+					next = append(next, a...)
+				}
+			default:
+				if a, ok := v.([]any); ok && st.index < len(a) {
+					next = append(next, a[st.index])
+				}
+			}
+		}
+		values = next
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("%s: no value", path)
+	}
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		switch x := v.(type) {
+		case string:
+			parts = append(parts, x)
+		case nil:
+			parts = append(parts, "")
+		default:
+			b, _ := json.Marshal(x)
+			parts = append(parts, string(b))
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
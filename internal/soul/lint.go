@@ -0,0 +1,268 @@
+package soul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/overhuman/overhuman/internal/brain"
+)
+
+// DefaultTokenBudget is the soul size limit used when Linter.MaxTokens is
+// unset. The soul is the system prompt of every call, so it is kept small.
+const DefaultTokenBudget = 4000
+
+// Lint severities. Errors block a save; warnings are reported.
+const (
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// LintIssue is one problem found in a soul document.
+type LintIssue struct {
+	Rule     string `json:"rule"` // "size", "dangerous", "contradiction", "duplicate"
+	Severity string `json:"severity"`
+	Line     int    `json:"line,omitempty"` // 1-based; 0 = whole document
+	Message  string `json:"message"`
+}
+
+// LintReport is the result of linting a soul document.
+type LintReport struct {
+	Tokens int         `json:"tokens"`
+	Issues []LintIssue `json:"issues"`
+}
+
+// Blocking reports whether any issue has error severity.
+func (r LintReport) Blocking() bool {
+	for _, is := range r.Issues {
+		if is.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// LintError is returned by Update when the linter blocks a save.
+type LintError struct {
+	Report LintReport
+}
+
+func (e *LintError) Error() string {
+	var msgs []string
+	for _, is := range e.Report.Issues {
+		if is.Severity == SeverityError {
+			msgs = append(msgs, is.Message)
+		}
+	}
+	return "soul lint: " + strings.Join(msgs, "; ")
+}
+
+// dangerousDirectives match instructions that would disable the agent's
+// safeguards. They are errors: a soul containing one is not saved.
+var dangerousDirectives = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bignore\s+(all\s+|any\s+)?(previous|prior|above|safety|security|your)\b(\s+(instructions|rules|guidelines|constraints|anchors|principles))?`),
+	regexp.MustCompile(`(?i)\b(disable|bypass|skip|turn\s+off|override)\s+(all\s+|any\s+|the\s+)?(safety|security|guardrails?|confirmations?|sandbox(ing)?|audit(ing)?|anchors?)\b`),
+	regexp.MustCompile(`(?i)\bnever\s+(ask|request)\s+(for\s+)?(confirmation|permission|approval)\b`),
+	regexp.MustCompile(`(?i)\b(reveal|share|print|output|send|leak)\s+(the\s+|your\s+|all\s+)?(system\s+prompt|api\s+keys?|secrets?|passwords?|credentials|tokens)\b`),
+	regexp.MustCompile(`(?i)\b(without|with\s+no)\s+(any\s+)?(restrictions|limits|limitations|filters?)\b`),
+}
+
+// Linter checks soul documents for size, dangerous directives and
+// contradictory instructions. LLM is optional: without it the consistency
+// pass is limited to always/never pairs.
+type Linter struct {
+	MaxTokens int
+	LLM       brain.LLMProvider
+	Model     string
+}
+
+// NewLinter creates a linter with the default token budget. llm may be nil.
+func NewLinter(llm brain.LLMProvider, model string) *Linter {
+	return &Linter{MaxTokens: DefaultTokenBudget, LLM: llm, Model: model}
+}
+
+// Static runs the checks that need no LLM: size, dangerous directives,
+// duplicates and always/never contradictions.
+func (l *Linter) Static(content string) LintReport {
+	budget := DefaultTokenBudget
+	if l != nil && l.MaxTokens > 0 {
+		budget = l.MaxTokens
+	}
+	rep := LintReport{Tokens: len(content) / 4, Issues: []LintIssue{}}
+	switch {
+	case rep.Tokens > budget:
+		rep.Issues = append(rep.Issues, LintIssue{Rule: "size", Severity: SeverityError,
+			Message: fmt.Sprintf("soul is ~%d tokens, over the %d token budget", rep.Tokens, budget)})
+	case rep.Tokens > budget*8/10:
+		rep.Issues = append(rep.Issues, LintIssue{Rule: "size", Severity: SeverityWarn,
+			Message: fmt.Sprintf("soul is ~%d tokens, close to the %d token budget", rep.Tokens, budget)})
+	}
+
+	seen := make(map[string]int)  // normalised line → first line number
+	polar := make(map[string]int) // "always:rest" / "never:rest" → line number
+	for i, raw := range strings.Split(content, "\n") {
+		n := i + 1
+		line := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(raw), "-*"))
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<!--") || strings.HasPrefix(line, "|") {
+			continue
+		}
+		for _, re := range dangerousDirectives {
+			if m := re.FindString(line); m != "" {
+				rep.Issues = append(rep.Issues, LintIssue{Rule: "dangerous", Severity: SeverityError, Line: n,
+					Message: fmt.Sprintf("line %d: dangerous directive %q", n, m)})
+				break
+			}
+		}
+		norm := strings.ToLower(strings.TrimRight(line, "."))
+		if first, ok := seen[norm]; ok {
+			rep.Issues = append(rep.Issues, LintIssue{Rule: "duplicate", Severity: SeverityWarn, Line: n,
+				Message: fmt.Sprintf("line %d repeats line %d", n, first)})
+			continue
+		}
+		seen[norm] = n
+		norm = strings.TrimPrefix(norm, "i ")
+		for _, pair := range [][2]string{{"always ", "never "}, {"never ", "always "}} {
+			rest, ok := strings.CutPrefix(norm, pair[0])
+			if !ok {
+				continue
+			}
+			if other, ok := polar[pair[1]+rest]; ok {
+				rep.Issues = append(rep.Issues, LintIssue{Rule: "contradiction", Severity: SeverityWarn, Line: n,
+					Message: fmt.Sprintf("line %d contradicts line %d", n, other)})
+			}
+			polar[pair[0]+rest] = n
+		}
+	}
+	return rep
+}
+
+// Lint runs the static checks and, when an LLM is configured, a
+// consistency pass that reports contradictory instructions as warnings.
+// A failed LLM call is reported as a warning rather than an error.
+func (l *Linter) Lint(ctx context.Context, content string) LintReport {
+	rep := l.Static(content)
+	if l == nil || l.LLM == nil {
+		return rep
+	}
+	conflicts, err := l.consistency(ctx, content)
+	if err != nil {
+		rep.Issues = append(rep.Issues, LintIssue{Rule: "contradiction", Severity: SeverityWarn,
+			Message: "consistency check unavailable: " + err.Error()})
+		return rep
+	}
+	for _, c := range conflicts {
+		rep.Issues = append(rep.Issues, LintIssue{Rule: "contradiction", Severity: SeverityWarn, Message: c})
+	}
+	return rep
+}
+
+func (l *Linter) consistency(ctx context.Context, content string) ([]string, error) {
+	resp, err := l.LLM.Complete(ctx, brain.LLMRequest{
+		Model: l.Model,
+		Messages: []brain.Message{
+			{Role: "system", Content: "You review an AI agent's identity document for instructions that contradict each other."},
+			{Role: "user", Content: "List every pair of instructions below that cannot both be followed.\n" +
+				"Reply with one line per conflict in the form:\nCONFLICT: <first instruction> <-> <second instruction>\n" +
+				"Reply NONE if there are no conflicts.\n\n" + content},
+		},
+		Temperature: 0,
+		MaxTokens:   500,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, line := range strings.Split(resp.Content, "\n") {
+		if c, ok := strings.CutPrefix(strings.TrimSpace(line), "CONFLICT:"); ok && strings.TrimSpace(c) != "" {
+			out = append(out, "possible contradiction: "+strings.TrimSpace(c))
+		}
+	}
+	return out, nil
+}
+
+// SetLinter makes Update run the linter's static checks and refuse content
+// with blocking issues. nil disables linting.
+func (s *Soul) SetLinter(l *Linter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.linter = l
+}
+
+// soulUpdateRequest is the JSON body of PUT /api/soul and POST /api/soul/lint.
+type soulUpdateRequest struct {
+	Content string `json:"content"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// RegisterRoutes exposes the soul for viewing, linting and editing.
+// Routes: GET /api/soul, POST /api/soul/lint, PUT /api/soul
+//
+// PUT runs the full lint (including the LLM consistency pass) and answers
+// 422 with the report when an issue blocks the save; warnings are returned
+// alongside the new version.
+func (s *Soul) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/soul", func(w http.ResponseWriter, r *http.Request) {
+		content, err := s.Read()
+		if err != nil {
+			writeSoulJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		version, _ := s.LatestVersion()
+		writeSoulJSON(w, http.StatusOK, map[string]any{"content": content, "version": version})
+	})
+	mux.HandleFunc("POST /api/soul/lint", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeSoulRequest(w, r)
+		if !ok {
+			return
+		}
+		writeSoulJSON(w, http.StatusOK, s.currentLinter().Lint(r.Context(), req.Content))
+	})
+	mux.HandleFunc("PUT /api/soul", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeSoulRequest(w, r)
+		if !ok {
+			return
+		}
+		rep := s.currentLinter().Lint(r.Context(), req.Content)
+		if rep.Blocking() {
+			writeSoulJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": "soul lint failed", "lint": rep})
+			return
+		}
+		if req.Reason == "" {
+			req.Reason = "Edited via soul API"
+		}
+		version, err := s.Update(req.Content, req.Reason)
+		if err != nil {
+			writeSoulJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+			return
+		}
+		writeSoulJSON(w, http.StatusOK, map[string]any{"version": version, "lint": rep})
+	})
+}
+
+// currentLinter returns the configured linter, or a default static-only one.
+func (s *Soul) currentLinter() *Linter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.linter == nil {
+		return NewLinter(nil, "")
+	}
+	return s.linter
+}
+
+func decodeSoulRequest(w http.ResponseWriter, r *http.Request) (soulUpdateRequest, bool) {
+	var req soulUpdateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil || req.Content == "" {
+		writeSoulJSON(w, http.StatusBadRequest, map[string]string{"error": "content required"})
+		return req, false
+	}
+	return req, true
+}
+
+func writeSoulJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
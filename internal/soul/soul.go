@@ -29,6 +29,11 @@ type Soul struct {
 	agentName      string
 	specialization string
 
+	// linter, if set, blocks updates with lint errors (see SetLinter).
+	linter *Linter
+	// onUpdate, if set, is told about every successful Update.
+	onUpdate func(UpdateEvent)
+
 	mu sync.RWMutex
 }
 
@@ -87,35 +92,85 @@ func (s *Soul) Read() (string, error) {
 	return string(data), nil
 }
 
+// UpdateEvent describes a successful Update: the content before and after
+// and the versions they are stored as.
+type UpdateEvent struct {
+	PrevVersion int
+	Version     int
+	Before      string
+	After       string
+	Reason      string
+}
+
+// OnUpdate registers fn to be called after every successful Update.
+// Rollbacks do not fire it. fn runs without the soul lock held.
+func (s *Soul) OnUpdate(fn func(UpdateEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onUpdate = fn
+}
+
 // Update writes new content to the soul file and creates a new version snapshot.
 // It validates that immutable anchors are preserved before allowing the update.
 // Returns the new version number.
 func (s *Soul) Update(newContent, reason string) (int, error) {
+	ev, err := s.update(newContent, reason)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	fn := s.onUpdate
+	s.mu.RUnlock()
+	if fn != nil {
+		fn(ev)
+	}
+	return ev.Version, nil
+}
+
+func (s *Soul) update(newContent, reason string) (UpdateEvent, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// Read current content to extract anchors.
 	currentData, err := os.ReadFile(s.soulPath())
 	if err != nil {
-		return 0, fmt.Errorf("read current soul: %w", err)
+		return UpdateEvent{}, fmt.Errorf("read current soul: %w", err)
 	}
 
 	// Validate anchors are preserved.
 	currentAnchors := extractAnchors(string(currentData))
 	newAnchors := extractAnchors(newContent)
 	if err := validateAnchors(currentAnchors, newAnchors); err != nil {
-		return 0, fmt.Errorf("anchor violation: %w", err)
+		return UpdateEvent{}, fmt.Errorf("anchor violation: %w", err)
+	}
+
+	if s.linter != nil {
+		if rep := s.linter.Static(newContent); rep.Blocking() {
+			return UpdateEvent{}, &LintError{Report: rep}
+		}
 	}
 
+	prev, _ := s.latestVersionLocked()
+
 	if err := os.WriteFile(s.soulPath(), []byte(newContent), 0o644); err != nil {
-		return 0, fmt.Errorf("write soul: %w", err)
+		return UpdateEvent{}, fmt.Errorf("write soul: %w", err)
 	}
 
 	if err := s.saveVersionLocked(newContent, reason); err != nil {
-		return 0, fmt.Errorf("save version: %w", err)
+		return UpdateEvent{}, fmt.Errorf("save version: %w", err)
 	}
 
-	return s.latestVersionLocked()
+	version, err := s.latestVersionLocked()
+	if err != nil {
+		return UpdateEvent{}, err
+	}
+	return UpdateEvent{
+		PrevVersion: prev,
+		Version:     version,
+		Before:      string(currentData),
+		After:       newContent,
+		Reason:      reason,
+	}, nil
 }
 
 // Rollback restores the soul to a specific version.
@@ -181,6 +181,30 @@ func TestRollback(t *testing.T) {
 	}
 }
 
+func TestOnUpdate(t *testing.T) {
+	s := New(tempDir(t), "TestAgent", "general")
+	s.Initialize()
+	before, _ := s.Read()
+
+	var events []UpdateEvent
+	s.OnUpdate(func(ev UpdateEvent) { events = append(events, ev) })
+	after := strings.Replace(before, "Run count: 0", "Run count: 3", 1)
+	if _, err := s.Update(after, "reflection"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := s.Rollback(1); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1 (rollbacks do not fire)", len(events))
+	}
+	ev := events[0]
+	if ev.PrevVersion != 1 || ev.Version != 2 || ev.Before != before || ev.After != after || ev.Reason != "reflection" {
+		t.Errorf("event = %+v", ev)
+	}
+}
+
 func TestRollbackInvalidVersion(t *testing.T) {
 	dir := tempDir(t)
 	s := New(dir, "TestAgent", "general")
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +13,69 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// SQLite connection tuning shared by every database the agent opens.
+const (
+	// SQLiteBusyTimeout is how long a connection waits for a lock held by
+	// another connection before failing with SQLITE_BUSY.
+	SQLiteBusyTimeout = 5 * time.Second
+
+	// SQLitePoolSize bounds open connections per database. WAL allows
+	// concurrent readers alongside the single writer; more connections than
+	// this only add lock contention.
+	SQLitePoolSize = 4
+)
+
+// OpenSQLite opens a SQLite database tuned for concurrent workers: WAL
+// journal, busy timeout and synchronous=NORMAL on every pooled connection
+// (pragmas set with Exec would only reach one of them), and a small pool.
+// ":memory:" databases get a single connection, since each connection
+// would otherwise see its own empty database.
+func OpenSQLite(path string) (*sql.DB, error) {
+	pragmas := fmt.Sprintf("_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)", SQLiteBusyTimeout.Milliseconds())
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", path+sep+pragmas)
+	if err != nil {
+		return nil, err
+	}
+	if path == ":memory:" || strings.Contains(path, "mode=memory") {
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(SQLitePoolSize)
+		db.SetMaxIdleConns(SQLitePoolSize)
+	}
+	// Open a connection now so a bad path or locked file fails here, not on
+	// first use.
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenSQLiteReadOnly opens an existing database for reading only, for CLI
+// commands that run next to the daemon. It creates nothing and runs no
+// migrations; WAL lets it read while the daemon writes.
+func OpenSQLiteReadOnly(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	dsn := fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(%d)&_pragma=query_only(1)", path, SQLiteBusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(SQLitePoolSize)
+	db.SetMaxIdleConns(SQLitePoolSize)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
 // SQLiteStore implements Store using SQLite.
 type SQLiteStore struct {
 	mu sync.RWMutex
@@ -21,17 +85,11 @@ type SQLiteStore struct {
 // NewSQLiteStore opens (or creates) a SQLite-backed store.
 // Use ":memory:" for an in-memory database.
 func NewSQLiteStore(path string) (*SQLiteStore, error) {
-	db, err := sql.Open("sqlite", path)
+	db, err := OpenSQLite(path)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite %q: %w", path, err)
 	}
 
-	// Enable WAL mode for better concurrent read performance.
-	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("set WAL mode: %w", err)
-	}
-
 	// Create tables.
 	schema := `
 	CREATE TABLE IF NOT EXISTS kv_store (
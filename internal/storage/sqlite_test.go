@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -291,3 +293,44 @@ func TestSQLiteStore_NoMetadata(t *testing.T) {
 func TestSQLiteStore_ImplementsStore(t *testing.T) {
 	var _ Store = (*SQLiteStore)(nil)
 }
+
+func TestOpenSQLite_Pragmas(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "tuned.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if db.Stats().MaxOpenConnections != SQLitePoolSize {
+		t.Errorf("pool = %d, want %d", db.Stats().MaxOpenConnections, SQLitePoolSize)
+	}
+
+	// Every pooled connection must carry the pragmas, not just the first.
+	ctx := context.Background()
+	var conns []*sql.Conn
+	for i := 0; i < SQLitePoolSize; i++ {
+		c, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, c)
+		var mode string
+		var timeout int64
+		c.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode)
+		c.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&timeout)
+		if mode != "wal" || timeout != SQLiteBusyTimeout.Milliseconds() {
+			t.Errorf("conn %d: journal_mode=%s busy_timeout=%d", i, mode, timeout)
+		}
+	}
+	for _, c := range conns {
+		c.Close()
+	}
+
+	mem, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+	if mem.Stats().MaxOpenConnections != 1 {
+		t.Errorf(":memory: pool = %d, want 1", mem.Stats().MaxOpenConnections)
+	}
+}
@@ -6,7 +6,14 @@
 package versioning
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -20,6 +27,14 @@ const (
 	ChangePolicy ChangeType = "POLICY"
 )
 
+// SoulEntity is the entity ID soul changes are recorded and observed under.
+// The soul shapes every run, so every run counts towards its window.
+const SoulEntity = "soul"
+
+// MaxHistory bounds how many decided changes are kept; observing changes
+// are never dropped.
+const MaxHistory = 200
+
 // ChangeStatus tracks where a change is in its lifecycle.
 type ChangeStatus string
 
@@ -51,15 +66,35 @@ type Change struct {
 	DecidedAt    time.Time `json:"decided_at,omitempty"`
 
 	// Rollback info.
-	RollbackData string `json:"rollback_data,omitempty"` // Serialized previous state
+	RollbackData  string `json:"rollback_data,omitempty"`  // Serialized previous state
+	RolledBackBy  string `json:"rolled_back_by,omitempty"` // "auto" or "user"
+	RollbackError string `json:"rollback_error,omitempty"` // Set when restoring failed
+
+	// Oversight: what caused the change and what it looked like either side.
+	Trigger string `json:"trigger,omitempty"` // e.g. "reflection", "experiment ab_3", "api"
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after,omitempty"`
 }
 
+// RollbackFunc restores the state a change replaced. It receives a copy of
+// the change, so RollbackData and EntityID are all it should rely on.
+type RollbackFunc func(ch Change) error
+
 // Controller manages observation windows and auto-rollback.
 type Controller struct {
 	mu      sync.RWMutex
 	changes map[string]*Change
 	nextID  int
 
+	// path, if set, persists the change history (see Open).
+	path string
+	// rollbacks restore state per change type; without one a rollback
+	// only marks the change.
+	rollbacks map[ChangeType]RollbackFunc
+	// baselines is a moving average of observed quality per entity, used
+	// when a change is recorded without an explicit baseline.
+	baselines map[string]float64
+
 	// Defaults.
 	defaultWindow    int
 	defaultThreshold float64
@@ -69,11 +104,60 @@ type Controller struct {
 func New() *Controller {
 	return &Controller{
 		changes:          make(map[string]*Change),
+		rollbacks:        make(map[ChangeType]RollbackFunc),
+		baselines:        make(map[string]float64),
 		defaultWindow:    5,
 		defaultThreshold: 0.9, // Rollback if quality drops below 90% of baseline.
 	}
 }
 
+// persistedHistory is the on-disk form of the controller.
+type persistedHistory struct {
+	NextID    int                `json:"next_id"`
+	Changes   []*Change          `json:"changes"`
+	Baselines map[string]float64 `json:"baselines,omitempty"`
+}
+
+// Open creates a version controller persisted at path. A missing file
+// starts an empty history.
+func Open(path string) (*Controller, error) {
+	c := New()
+	c.path = path
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("versioning: read: %w", err)
+	}
+	var h persistedHistory
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("versioning: parse %s: %w", path, err)
+	}
+	c.nextID = h.NextID
+	for _, ch := range h.Changes {
+		c.changes[ch.ID] = ch
+	}
+	for k, v := range h.Baselines {
+		c.baselines[k] = v
+	}
+	return c, nil
+}
+
+// SetRollback registers how changes of type t are undone.
+func (c *Controller) SetRollback(t ChangeType, fn RollbackFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollbacks[t] = fn
+}
+
+// CanRollback reports whether changes of type t can be restored.
+func (c *Controller) CanRollback(t ChangeType) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rollbacks[t] != nil
+}
+
 // SetDefaultWindow sets the default observation window size.
 func (c *Controller) SetDefaultWindow(n int) {
 	c.mu.Lock()
@@ -99,34 +183,52 @@ func (c *Controller) RecordChange(
 	baselineCost float64,
 	rollbackData string,
 ) *Change {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.nextID++
-	ch := &Change{
-		ID:              fmt.Sprintf("change_%d", c.nextID),
+	return c.Record(Change{
 		Type:            changeType,
 		EntityID:        entityID,
 		Description:     description,
-		Status:          StatusObserving,
 		BaselineQuality: baselineQuality,
 		BaselineCost:    baselineCost,
-		WindowSize:      c.defaultWindow,
-		Threshold:       c.defaultThreshold,
-		CreatedAt:       time.Now(),
 		RollbackData:    rollbackData,
+	})
+}
+
+// Record registers a change described by ch and starts its observation
+// window. ID, status, window, threshold and creation time are assigned
+// here; a zero baseline quality is taken from the entity's recent runs.
+func (c *Controller) Record(ch Change) *Change {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	ch.ID = fmt.Sprintf("change_%d", c.nextID)
+	ch.Status = StatusObserving
+	ch.WindowSize = c.defaultWindow
+	ch.Threshold = c.defaultThreshold
+	ch.CreatedAt = time.Now()
+	if ch.BaselineQuality == 0 {
+		ch.BaselineQuality = c.baselines[ch.EntityID]
 	}
-	c.changes[ch.ID] = ch
-	return ch
+	c.changes[ch.ID] = &ch
+	c.pruneLocked()
+	c.saveLocked()
+	return &ch
 }
 
 // ObserveRun records a run's metrics against all active observation windows
-// for the given entity. Returns any change IDs that should be rolled back.
+// for the given entity. Changes whose window ends below the threshold are
+// rolled back with their registered RollbackFunc; their IDs are returned.
 func (c *Controller) ObserveRun(entityID string, quality, cost float64) []string {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
 	var rollbacks []string
+	var undo []Change
+
+	if b, ok := c.baselines[entityID]; ok {
+		c.baselines[entityID] = 0.8*b + 0.2*quality
+	} else {
+		c.baselines[entityID] = quality
+	}
 
 	for _, ch := range c.changes {
 		if ch.Status != StatusObserving || ch.EntityID != entityID {
@@ -143,18 +245,45 @@ func (c *Controller) ObserveRun(entityID string, quality, cost float64) []string
 		if ch.RunsObserved >= ch.WindowSize {
 			if ch.BaselineQuality > 0 && ch.CurrentQuality/ch.BaselineQuality < ch.Threshold {
 				ch.Status = StatusRolledBack
+				ch.RolledBackBy = "auto"
 				ch.DecidedAt = time.Now()
 				rollbacks = append(rollbacks, ch.ID)
+				undo = append(undo, *ch)
 			} else {
 				ch.Status = StatusAccepted
 				ch.DecidedAt = time.Now()
 			}
 		}
 	}
+	c.saveLocked()
+	c.mu.Unlock()
 
+	for _, ch := range undo {
+		c.restore(ch)
+	}
 	return rollbacks
 }
 
+// restore runs the rollback for ch and records a failure on the change.
+func (c *Controller) restore(ch Change) error {
+	c.mu.RLock()
+	fn := c.rollbacks[ch.Type]
+	c.mu.RUnlock()
+	if fn == nil {
+		return nil
+	}
+	err := fn(ch)
+	if err != nil {
+		c.mu.Lock()
+		if cur := c.changes[ch.ID]; cur != nil {
+			cur.RollbackError = err.Error()
+			c.saveLocked()
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
 // Get returns a change by ID.
 func (c *Controller) Get(id string) *Change {
 	c.mu.RLock()
@@ -162,6 +291,20 @@ func (c *Controller) Get(id string) *Change {
 	return c.changes[id]
 }
 
+// List returns copies of every tracked change, newest first.
+func (c *Controller) List() []*Change {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]*Change, 0, len(c.changes))
+	for _, ch := range c.changes {
+		cp := *ch
+		result = append(result, &cp)
+	}
+	sortNewestFirst(result)
+	return result
+}
+
 // ActiveChanges returns all changes currently being observed.
 func (c *Controller) ActiveChanges() []*Change {
 	c.mu.RLock()
@@ -204,18 +347,27 @@ func (c *Controller) ForceAccept(id string) error {
 	return nil
 }
 
-// ForceRollback immediately triggers rollback.
+// ForceRollback immediately rolls a change back on the user's behalf,
+// restoring the previous state when a RollbackFunc is registered.
 func (c *Controller) ForceRollback(id string) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	ch, ok := c.changes[id]
 	if !ok {
+		c.mu.Unlock()
 		return fmt.Errorf("change %q not found", id)
 	}
+	if ch.Status == StatusRolledBack {
+		c.mu.Unlock()
+		return fmt.Errorf("change %q is already rolled back", id)
+	}
 	ch.Status = StatusRolledBack
+	ch.RolledBackBy = "user"
 	ch.DecidedAt = time.Now()
-	return nil
+	snapshot := *ch
+	c.saveLocked()
+	c.mu.Unlock()
+
+	return c.restore(snapshot)
 }
 
 // Count returns total number of tracked changes.
@@ -224,3 +376,61 @@ func (c *Controller) Count() int {
 	defer c.mu.RUnlock()
 	return len(c.changes)
 }
+
+// pruneLocked drops the oldest decided changes beyond MaxHistory.
+func (c *Controller) pruneLocked() {
+	if len(c.changes) <= MaxHistory {
+		return
+	}
+	var decided []*Change
+	for _, ch := range c.changes {
+		if ch.Status != StatusObserving {
+			decided = append(decided, ch)
+		}
+	}
+	sortNewestFirst(decided)
+	for i := len(decided) - 1; i >= 0 && len(c.changes) > MaxHistory; i-- {
+		delete(c.changes, decided[i].ID)
+	}
+}
+
+// saveLocked writes the history to disk. Failures are logged: losing the
+// history must not block the change itself.
+func (c *Controller) saveLocked() {
+	if c.path == "" {
+		return
+	}
+	h := persistedHistory{NextID: c.nextID, Baselines: c.baselines}
+	for _, ch := range c.changes {
+		h.Changes = append(h.Changes, ch)
+	}
+	sortNewestFirst(h.Changes)
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.path), 0o755)
+	}
+	if err == nil {
+		tmp := c.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o644); err == nil {
+			err = os.Rename(tmp, c.path)
+		}
+	}
+	if err != nil {
+		log.Printf("[versioning] save %s: %v", c.path, err)
+	}
+}
+
+// sortNewestFirst orders changes by creation, breaking ties by ID number.
+func sortNewestFirst(list []*Change) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return changeSeq(list[i].ID) > changeSeq(list[j].ID)
+	})
+}
+
+func changeSeq(id string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(id, "change_"))
+	return n
+}
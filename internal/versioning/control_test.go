@@ -1,6 +1,12 @@
 package versioning
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -199,4 +205,146 @@ func TestMultipleChanges_SameEntity(t *testing.T) {
 	if got2.Status != StatusAccepted {
 		t.Errorf("ch2 Status = %q, want ACCEPTED", got2.Status)
 	}
-}
\ No newline at end of file
+}
+func TestObserveRun_AutoRollbackRestores(t *testing.T) {
+	c := New()
+	c.SetDefaultWindow(1)
+	var restored []string
+	c.SetRollback(ChangeSoul, func(ch Change) error {
+		restored = append(restored, ch.RollbackData)
+		return nil
+	})
+
+	c.ObserveRun(SoulEntity, 0.8, 0.01) // establishes the baseline
+	ch := c.Record(Change{Type: ChangeSoul, EntityID: SoulEntity, RollbackData: "3", Trigger: "reflection"})
+	if ch.BaselineQuality != 0.8 {
+		t.Errorf("BaselineQuality = %v, want the observed 0.8", ch.BaselineQuality)
+	}
+	c.ObserveRun(SoulEntity, 0.2, 0.01)
+
+	got := c.Get(ch.ID)
+	if got.Status != StatusRolledBack || got.RolledBackBy != "auto" {
+		t.Errorf("status = %s by %q", got.Status, got.RolledBackBy)
+	}
+	if len(restored) != 1 || restored[0] != "3" {
+		t.Errorf("restored = %v", restored)
+	}
+}
+
+func TestForceRollback_RecordsFailure(t *testing.T) {
+	c := New()
+	c.SetRollback(ChangeSkill, func(Change) error { return fmt.Errorf("skill gone") })
+	ch := c.RecordChange(ChangeSkill, "s1", "promoted", 0.8, 0, "testing")
+
+	if err := c.ForceRollback(ch.ID); err == nil {
+		t.Fatal("ForceRollback succeeded despite a failing restore")
+	}
+	got := c.Get(ch.ID)
+	if got.RolledBackBy != "user" || got.RollbackError != "skill gone" {
+		t.Errorf("change = %+v", got)
+	}
+	if err := c.ForceRollback(ch.ID); err == nil {
+		t.Error("second rollback of the same change succeeded")
+	}
+}
+
+func TestOpen_PersistsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.json")
+	c, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	c.RecordChange(ChangeSoul, SoulEntity, "first", 0.8, 0, "1")
+	second := c.Record(Change{Type: ChangeSkill, EntityID: "s1", Description: "second", Before: "a", After: "b"})
+
+	c2, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	list := c2.List()
+	if len(list) != 2 || list[0].ID != second.ID || list[0].After != "b" {
+		t.Fatalf("reloaded list = %+v", list)
+	}
+	if next := c2.RecordChange(ChangeSoul, SoulEntity, "third", 0, 0, ""); next.ID != "change_3" {
+		t.Errorf("next ID = %s, want change_3", next.ID)
+	}
+}
+
+func TestPrune_KeepsObserving(t *testing.T) {
+	c := New()
+	first := c.RecordChange(ChangeSkill, "s", "kept", 0, 0, "")
+	for i := 0; i < MaxHistory+10; i++ {
+		ch := c.RecordChange(ChangeSkill, "s", "decided", 0, 0, "")
+		c.ForceAccept(ch.ID)
+	}
+	if c.Count() > MaxHistory+1 {
+		t.Errorf("Count = %d, want at most %d", c.Count(), MaxHistory+1)
+	}
+	if c.Get(first.ID) == nil {
+		t.Error("observing change was pruned")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff("a\nb\nc\nd\n", "a\nB\nc\nd\ne\n")
+	var ops []string
+	for _, l := range got {
+		ops = append(ops, string(l.Op)+l.Text)
+	}
+	want := " a,-b,+B, c, d,+e"
+	if s := strings.Join(ops, ","); s != want {
+		t.Errorf("Diff = %s, want %s", s, want)
+	}
+	if d := Diff("", "x"); len(d) != 1 || d[0].Op != DiffAdd {
+		t.Errorf("Diff from empty = %+v", d)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	c := New()
+	var undone []string
+	c.SetRollback(ChangeSoul, func(ch Change) error {
+		undone = append(undone, ch.ID)
+		return nil
+	})
+	soulCh := c.Record(Change{Type: ChangeSoul, EntityID: SoulEntity, Description: "tone", Before: "x\n", After: "y\n"})
+	policy := c.RecordChange(ChangePolicy, "p", "policy", 0, 0, "")
+	mux := http.NewServeMux()
+	c.RegisterRoutes(mux)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := do("GET", "/api/changes?type=soul")
+	var list struct {
+		Changes []changeView `json:"changes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Changes) != 1 || list.Changes[0].After != "" || !list.Changes[0].CanRollback {
+		t.Errorf("list = %s", w.Body)
+	}
+
+	w = do("GET", "/api/changes/"+soulCh.ID)
+	if !strings.Contains(w.Body.String(), `"op":"+","text":"y"`) {
+		t.Errorf("detail = %s", w.Body)
+	}
+	if w := do("POST", "/api/changes/"+policy.ID+"/rollback"); w.Code != http.StatusBadRequest {
+		t.Errorf("rollback without handler: status %d", w.Code)
+	}
+	if w := do("POST", "/api/changes/"+soulCh.ID+"/rollback"); w.Code != http.StatusOK {
+		t.Errorf("rollback: status %d: %s", w.Code, w.Body)
+	}
+	if w := do("POST", "/api/changes/"+soulCh.ID+"/rollback"); w.Code != http.StatusConflict {
+		t.Errorf("repeat rollback: status %d", w.Code)
+	}
+	if len(undone) != 1 {
+		t.Errorf("rollback handler calls = %d, want 1", len(undone))
+	}
+	if w := do("GET", "/api/changes/change_99"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown change: status %d", w.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package versioning
+
+import "strings"
+
+// DiffOp marks a diff line as kept, added or removed.
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = " "
+	DiffAdd    DiffOp = "+"
+	DiffRemove DiffOp = "-"
+)
+
+// DiffLine is one line of a line-based diff.
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxDiffCells caps the LCS table; larger inputs diff as a full replace.
+const maxDiffCells = 4_000_000
+
+// Diff returns a line diff turning before into after, based on the
+// longest common subsequence of lines.
+func Diff(before, after string) []DiffLine {
+	a, b := splitLines(before), splitLines(after)
+
+	// Trim the common prefix and suffix; edits are usually local.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var out []DiffLine
+	for _, l := range a[:pre] {
+		out = append(out, DiffLine{DiffEqual, l})
+	}
+	out = append(out, diffMiddle(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		out = append(out, DiffLine{DiffEqual, l})
+	}
+	return out
+}
+
+func diffMiddle(a, b []string) []DiffLine {
+	var out []DiffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			out = append(out, DiffLine{DiffRemove, l})
+		}
+		for _, l := range b {
+			out = append(out, DiffLine{DiffAdd, l})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, DiffLine{DiffEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{DiffRemove, a[i]})
+			i++
+		default:
+			out = append(out, DiffLine{DiffAdd, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, DiffLine{DiffRemove, a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, DiffLine{DiffAdd, b[j]})
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
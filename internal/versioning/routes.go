@@ -0,0 +1,88 @@
+package versioning
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// changeView is a change as served to the kiosk.
+type changeView struct {
+	*Change
+	CanRollback bool       `json:"can_rollback"`
+	Diff        []DiffLine `json:"diff,omitempty"`
+}
+
+// RegisterRoutes exposes the change timeline for oversight.
+// Routes: GET /api/changes, GET /api/changes/{id},
+// POST /api/changes/{id}/rollback
+//
+// The list omits before/after content; the single-change route adds the
+// line diff between them.
+func (c *Controller) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/changes", func(w http.ResponseWriter, r *http.Request) {
+		typ := ChangeType(strings.ToUpper(r.URL.Query().Get("type")))
+		views := []changeView{}
+		for _, ch := range c.List() {
+			if typ != "" && ch.Type != typ {
+				continue
+			}
+			ch.Before, ch.After = "", ""
+			views = append(views, c.view(ch))
+		}
+		writeChangesJSON(w, http.StatusOK, map[string]any{"changes": views})
+	})
+	mux.HandleFunc("GET /api/changes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ch := c.find(r.PathValue("id"))
+		if ch == nil {
+			writeChangesJSON(w, http.StatusNotFound, map[string]string{"error": "change not found"})
+			return
+		}
+		v := c.view(ch)
+		if ch.Before != "" || ch.After != "" {
+			v.Diff = Diff(ch.Before, ch.After)
+		}
+		writeChangesJSON(w, http.StatusOK, v)
+	})
+	mux.HandleFunc("POST /api/changes/{id}/rollback", func(w http.ResponseWriter, r *http.Request) {
+		ch := c.find(r.PathValue("id"))
+		switch {
+		case ch == nil:
+			writeChangesJSON(w, http.StatusNotFound, map[string]string{"error": "change not found"})
+			return
+		case ch.Status == StatusRolledBack:
+			writeChangesJSON(w, http.StatusConflict, map[string]string{"error": "change is already rolled back"})
+			return
+		case !c.CanRollback(ch.Type):
+			writeChangesJSON(w, http.StatusBadRequest, map[string]string{"error": "changes of type " + string(ch.Type) + " cannot be rolled back"})
+			return
+		}
+		if err := c.ForceRollback(ch.ID); err != nil {
+			writeChangesJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeChangesJSON(w, http.StatusOK, c.view(c.find(ch.ID)))
+	})
+}
+
+// find returns a copy of the change with the given ID, or nil.
+func (c *Controller) find(id string) *Change {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ch, ok := c.changes[id]
+	if !ok {
+		return nil
+	}
+	cp := *ch
+	return &cp
+}
+
+func (c *Controller) view(ch *Change) changeView {
+	return changeView{Change: ch, CanRollback: ch.Status != StatusRolledBack && c.CanRollback(ch.Type)}
+}
+
+func writeChangesJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
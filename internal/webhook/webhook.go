@@ -0,0 +1,304 @@
+// Package webhook delivers agent events to user-configured URLs as signed
+// JSON, so n8n, Zapier or a home-grown receiver can react to task
+// completions, failures, automation runs, budget alerts and approval
+// requests without polling the API.
+//
+// Every delivery is a POST with these headers:
+//
+//	X-Overhuman-Event:     task.completed
+//	X-Overhuman-Delivery:  <payload id, stable across retries>
+//	X-Overhuman-Timestamp: <unix seconds>
+//	X-Overhuman-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// The signature is omitted when the webhook has no secret. Receivers should
+// recompute it (see Verify) and reject stale timestamps.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types.
+const (
+	EventTaskCompleted     = "task.completed"
+	EventTaskFailed        = "task.failed"
+	EventAutomationFired   = "automation.fired"
+	EventBudgetThreshold   = "budget.threshold"
+	EventApprovalRequested = "approval.requested"
+)
+
+// Events lists every event type, for validation and docs.
+var Events = []string{
+	EventTaskCompleted,
+	EventTaskFailed,
+	EventAutomationFired,
+	EventBudgetThreshold,
+	EventApprovalRequested,
+}
+
+// Delivery headers.
+const (
+	HeaderEvent     = "X-Overhuman-Event"
+	HeaderDelivery  = "X-Overhuman-Delivery"
+	HeaderTimestamp = "X-Overhuman-Timestamp"
+	HeaderSignature = "X-Overhuman-Signature"
+)
+
+// Config is one outbound webhook.
+type Config struct {
+	URL string `json:"url"`
+	// Secret keys the HMAC signature ("" = unsigned). May be an
+	// "enc:v1:" value; the caller decrypts it.
+	Secret string `json:"secret,omitempty"`
+	// Events filters what is sent: exact types or a family like "task.*".
+	// Empty sends everything.
+	Events []string `json:"events,omitempty"`
+}
+
+// Wants reports whether the webhook subscribes to event.
+func (c Config) Wants(event string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == "*" || e == event {
+			return true
+		}
+		if family, ok := strings.CutSuffix(e, ".*"); ok && strings.HasPrefix(event, family+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the URL and that every filter names a known event or
+// event family.
+func (c Config) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL, got %q", c.URL)
+	}
+	for _, e := range c.Events {
+		if !knownFilter(e) {
+			return fmt.Errorf("unknown event %q (want one of %s, a family like task.*, or *)", e, strings.Join(Events, ", "))
+		}
+	}
+	return nil
+}
+
+func knownFilter(f string) bool {
+	if f == "*" {
+		return true
+	}
+	for _, e := range Events {
+		if e == f {
+			return true
+		}
+		if family, ok := strings.CutSuffix(f, ".*"); ok && strings.HasPrefix(e, family+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	ID    string    `json:"id"`
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Agent string    `json:"agent,omitempty"`
+	Data  any       `json:"data"`
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches body and timestamp under
+// secret. It is what a Go receiver would call.
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// Delivery defaults.
+const (
+	defaultAttempts = 4
+	defaultBackoff  = 2 * time.Second
+	requestTimeout  = 10 * time.Second
+	maxInflight     = 8
+)
+
+// Dispatcher sends events to the configured webhooks in the background,
+// retrying failed deliveries with exponential backoff. A nil Dispatcher
+// drops events, so callers need not check whether webhooks are set up.
+type Dispatcher struct {
+	hooks    []Config
+	agent    string
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New returns a dispatcher for hooks, or nil when there are none. agent
+// names the sender in payloads.
+func New(hooks []Config, agent string) *Dispatcher {
+	if len(hooks) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		hooks:    hooks,
+		agent:    agent,
+		client:   &http.Client{Timeout: requestTimeout},
+		attempts: defaultAttempts,
+		backoff:  defaultBackoff,
+		ctx:      ctx,
+		cancel:   cancel,
+		sem:      make(chan struct{}, maxInflight),
+	}
+}
+
+// Emit queues event for every webhook subscribed to it and returns
+// immediately. data must marshal to JSON.
+func (d *Dispatcher) Emit(event string, data any) {
+	if d == nil || d.ctx.Err() != nil {
+		return
+	}
+	p := Payload{ID: uuid.NewString(), Event: event, Time: time.Now().UTC(), Agent: d.agent, Data: data}
+	body, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("[webhook] %s: encode: %v", event, err)
+		return
+	}
+	for _, h := range d.hooks {
+		if !h.Wants(event) {
+			continue
+		}
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			select {
+			case d.sem <- struct{}{}:
+			case <-d.ctx.Done():
+				return
+			}
+			defer func() { <-d.sem }()
+			if err := d.deliver(h, p, body); err != nil {
+				log.Printf("[webhook] %s to %s dropped: %v", event, redact(h.URL), err)
+			}
+		}()
+	}
+}
+
+// Close abandons pending retries and waits for deliveries in flight.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Wait blocks until every queued delivery has succeeded or given up.
+func (d *Dispatcher) Wait() {
+	if d != nil {
+		d.wg.Wait()
+	}
+}
+
+// deliver posts body to h, retrying transport errors, 429s and 5xx.
+func (d *Dispatcher) deliver(h Config, p Payload, body []byte) error {
+	wait := d.backoff
+	var err error
+	for attempt := 1; attempt <= d.attempts; attempt++ {
+		var retryAfter time.Duration
+		retryAfter, err = d.post(h, p, body)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt == d.attempts {
+			break
+		}
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		select {
+		case <-time.After(wait):
+		case <-d.ctx.Done():
+			return fmt.Errorf("%w (shutting down)", err)
+		}
+		wait *= 2
+	}
+	return err
+}
+
+// post makes one attempt. retryAfter is negative when retrying is
+// pointless (4xx other than 429), otherwise the server's Retry-After (or
+// zero).
+func (d *Dispatcher) post(h Config, p Payload, body []byte) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "overhuman-webhook/1")
+	req.Header.Set(HeaderEvent, p.Event)
+	req.Header.Set(HeaderDelivery, p.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	if h.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(h.Secret, ts, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		secs, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(min(max(secs, 0), 300)) * time.Second, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return -1, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// redact strips credentials and the query (often a token) from u for logs.
+func redact(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "webhook"
+	}
+	parsed.User = nil
+	parsed.RawQuery = ""
+	return parsed.String()
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfig_Wants(t *testing.T) {
+	tests := []struct {
+		events []string
+		event  string
+		want   bool
+	}{
+		{nil, EventTaskFailed, true},
+		{[]string{EventTaskCompleted}, EventTaskCompleted, true},
+		{[]string{EventTaskCompleted}, EventTaskFailed, false},
+		{[]string{"task.*"}, EventTaskFailed, true},
+		{[]string{"task.*"}, EventBudgetThreshold, false},
+		{[]string{"*"}, EventApprovalRequested, true},
+	}
+	for _, tt := range tests {
+		if got := (Config{Events: tt.events}).Wants(tt.event); got != tt.want {
+			t.Errorf("Wants(%v, %s) = %v, want %v", tt.events, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := []Config{
+		{URL: "https://hooks.example.com/x"},
+		{URL: "http://localhost:5678/webhook", Events: []string{"task.*", EventBudgetThreshold}},
+	}
+	for _, c := range valid {
+		if err := c.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", c, err)
+		}
+	}
+	invalid := []Config{
+		{URL: ""},
+		{URL: "ftp://example.com"},
+		{URL: "/relative"},
+		{URL: "https://example.com", Events: []string{"task.started"}},
+		{URL: "https://example.com", Events: []string{"nope.*"}},
+	}
+	for _, c := range invalid {
+		if c.Validate() == nil {
+			t.Errorf("Validate(%+v) = nil, want error", c)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"event":"task.completed"}`)
+	sig := Sign("s3cret", 1700000000, body)
+	if len(sig) != len("sha256=")+64 || sig[:7] != "sha256=" {
+		t.Fatalf("signature = %q", sig)
+	}
+	if !Verify("s3cret", sig, 1700000000, body) {
+		t.Error("Verify rejected a valid signature")
+	}
+	if Verify("s3cret", sig, 1700000001, body) {
+		t.Error("Verify accepted a different timestamp")
+	}
+	if Verify("other", sig, 1700000000, body) {
+		t.Error("Verify accepted a different secret")
+	}
+}
+
+func TestDispatcher_SignedDelivery(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		got   Payload
+		valid bool
+		event string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		mu.Lock()
+		defer mu.Unlock()
+		valid = Verify("s3cret", r.Header.Get(HeaderSignature), ts, body)
+		event = r.Header.Get(HeaderEvent)
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	d := New([]Config{{URL: srv.URL, Secret: "s3cret"}}, "Jarvis")
+	d.Emit(EventTaskCompleted, map[string]string{"task_id": "t1"})
+	d.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !valid {
+		t.Error("signature did not verify")
+	}
+	if event != EventTaskCompleted || got.Event != EventTaskCompleted || got.Agent != "Jarvis" || got.ID == "" {
+		t.Errorf("header event %q, payload %+v", event, got)
+	}
+	if data, _ := got.Data.(map[string]any); data["task_id"] != "t1" {
+		t.Errorf("data = %v", got.Data)
+	}
+}
+
+func TestDispatcher_FiltersAndRetries(t *testing.T) {
+	var calls, failed atomic.Int32
+	var ids sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		ids.Store(r.Header.Get(HeaderDelivery), true)
+		if failed.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	d := New([]Config{{URL: srv.URL, Events: []string{"task.*"}}}, "")
+	d.backoff = time.Millisecond
+	d.Emit(EventBudgetThreshold, nil) // filtered out
+	d.Emit(EventTaskFailed, map[string]string{"error": "boom"})
+	d.Wait()
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("calls = %d, want 3 (two 502s, then success)", n)
+	}
+	n := 0
+	ids.Range(func(any, any) bool { n++; return true })
+	if n != 1 {
+		t.Errorf("delivery ids = %d, want 1 stable id across retries", n)
+	}
+}
+
+func TestDispatcher_NoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	d := New([]Config{{URL: srv.URL}}, "")
+	d.backoff = time.Millisecond
+	d.Emit(EventTaskCompleted, nil)
+	d.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("calls = %d, want 1", n)
+	}
+}
+
+func TestDispatcher_Nil(t *testing.T) {
+	d := New(nil, "")
+	if d != nil {
+		t.Fatal("New(nil) should return nil")
+	}
+	d.Emit(EventTaskCompleted, nil) // must not panic
+	d.Wait()
+	d.Close()
+}
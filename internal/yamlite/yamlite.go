@@ -0,0 +1,262 @@
+// Package yamlite decodes the small subset of YAML used by hand-written
+// files in the data directory (automations.yaml, skill templates). The
+// module has no YAML dependency and these files only need:
+//
+//   - block mappings ("key: value") and sequences ("- item"), nested by
+//     indentation with spaces
+//   - plain, 'single' and "double" quoted scalars; true/false become bools,
+//     null/~ become null and everything else — numbers too — is a string
+//   - flow sequences of scalars ("[a, b]")
+//   - # comments and a leading "---"
+//
+// Anchors, multi-line block scalars (| and >) and flow mappings are
+// rejected with an error rather than misread.
+package yamlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type yamlLine struct {
+	num    int // 1-based, for errors
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// Decode parses data and stores the result in v like json.Unmarshal.
+func Decode(data []byte, v any) error {
+	tree, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Parse parses src into maps, slices, strings, bools and nils.
+func Parse(src string) (any, error) {
+	p := &yamlParser{}
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		if strings.TrimSpace(line) == "" || (len(p.lines) == 0 && line == "---") {
+			continue
+		}
+		text := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed for indentation", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: len(line) - len(text), text: text})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+	v, err := p.block(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("yaml: line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return v, nil
+}
+
+// block parses the mapping or sequence starting at the current line.
+func (p *yamlParser) block(indent int) (any, error) {
+	if isSeqItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) ([]any, error) {
+	out := []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSeqItem(p.lines[p.pos].text) {
+		l := p.lines[p.pos]
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		switch {
+		case rest == "":
+			p.pos++
+			v, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		case isMapEntry(rest):
+			// "- key: value" opens a mapping indented to where "key" starts.
+			inner := l.indent + len(l.text) - len(rest)
+			p.lines[p.pos] = yamlLine{num: l.num, indent: inner, text: rest}
+			m, err := p.mapping(inner)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, m)
+		default:
+			v, err := yamlScalar(rest, l.num)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			p.pos++
+		}
+	}
+	return out, nil
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	out := map[string]any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent >= indent {
+		l := p.lines[p.pos]
+		if l.indent > indent {
+			return nil, fmt.Errorf("yaml: line %d: unexpected indentation", l.num)
+		}
+		if isSeqItem(l.text) {
+			return nil, fmt.Errorf("yaml: line %d: sequence item where a key was expected", l.num)
+		}
+		key, rest, ok := splitYAMLKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("yaml: line %d: expected \"key: value\"", l.num)
+		}
+		if _, dup := out[key]; dup {
+			return nil, fmt.Errorf("yaml: line %d: duplicate key %q", l.num, key)
+		}
+		p.pos++
+		var v any
+		var err error
+		if rest == "" {
+			v, err = p.nested(indent)
+		} else {
+			v, err = yamlScalar(rest, l.num)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// nested parses the value of a key or item whose text ended the line: a
+// deeper block, a sequence at the same indentation (allowed under a key),
+// or null.
+func (p *yamlParser) nested(indent int) (any, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || (next.indent == indent && isSeqItem(next.text)) {
+		return p.block(next.indent)
+	}
+	return nil, nil
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isMapEntry(text string) bool {
+	_, _, ok := splitYAMLKey(text)
+	return ok
+}
+
+// splitYAMLKey splits "key: value" (or "key:") into its parts.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest = text[1:end+1], text[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), true
+	}
+	if strings.HasSuffix(text, ":") && len(text) > 1 {
+		return strings.TrimSpace(text[:len(text)-1]), "", true
+	}
+	return "", "", false
+}
+
+func yamlScalar(s string, num int) (any, error) {
+	switch {
+	case s[0] == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("yaml: line %d: bad double-quoted string", num)
+		}
+		return v, nil
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return nil, fmt.Errorf("yaml: line %d: unterminated single-quoted string", num)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case s[0] == '[':
+		if s[len(s)-1] != ']' {
+			return nil, fmt.Errorf("yaml: line %d: unterminated flow sequence", num)
+		}
+		items := []any{}
+		if inner := strings.TrimSpace(s[1 : len(s)-1]); inner != "" {
+			for _, part := range strings.Split(inner, ",") {
+				part = strings.TrimSpace(part)
+				if part == "" {
+					return nil, fmt.Errorf("yaml: line %d: empty item in flow sequence", num)
+				}
+				v, err := yamlScalar(part, num)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+			}
+		}
+		return items, nil
+	case strings.ContainsRune("{&*!|>", rune(s[0])):
+		return nil, fmt.Errorf("yaml: line %d: %q is not supported", num, s[:1])
+	}
+	switch s {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	return s, nil
+}
+
+// stripYAMLComment removes a "#" comment that starts the line or follows a
+// space, outside quotes.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '[' || line[i-1] == ',' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
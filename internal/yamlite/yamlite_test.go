@@ -0,0 +1,95 @@
+package yamlite
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	src := `---
+# morning digest
+rules:
+  - name: digest   # trailing comment
+    when:
+      at: "08:30"
+    then:
+      - template: daily digest
+      - notify: 'it''s done: {{result}}'
+    consume: true
+  - name: tags
+    labels: [bug, "p1", 5]
+    nothing:
+    empty: []
+`
+	got, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]any{
+		"rules": []any{
+			map[string]any{
+				"name": "digest",
+				"when": map[string]any{"at": "08:30"},
+				"then": []any{
+					map[string]any{"template": "daily digest"},
+					map[string]any{"notify": "it's done: {{result}}"},
+				},
+				"consume": true,
+			},
+			map[string]any{
+				"name":    "tags",
+				"labels":  []any{"bug", "p1", "5"},
+				"nothing": nil,
+				"empty":   []any{},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestParse_SequenceUnderKeyAtSameIndent(t *testing.T) {
+	got, err := Parse("then:\n- notify: a\n- notify: b\nname: x\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]any{
+		"then": []any{map[string]any{"notify": "a"}, map[string]any{"notify": "b"}},
+		"name": "x",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse = %#v", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for name, src := range map[string]string{
+		"tab indent":    "a:\n\tb: c\n",
+		"bad indent":    "a: b\n   c: d\n",
+		"duplicate key": "a: 1\na: 2\n",
+		"block scalar":  "a: |\n  text\n",
+		"flow mapping":  "a: {b: c}\n",
+		"not a key":     "just text\n",
+		"bad quote":     "a: \"open\n",
+	} {
+		if _, err := Parse(src); err == nil || !strings.HasPrefix(err.Error(), "yaml: line") {
+			t.Errorf("%s: err = %v, want a line-numbered yaml error", name, err)
+		}
+	}
+}
+
+func TestStripYAMLComment(t *testing.T) {
+	for in, want := range map[string]string{
+		"a: b # c":       "a: b ",
+		"# all":          "",
+		`a: "x # y"`:     `a: "x # y"`,
+		"a: issue#42":    "a: issue#42",
+		"a: 'it''s' # c": "a: 'it''s' ",
+	} {
+		if got := stripYAMLComment(in); got != want {
+			t.Errorf("stripYAMLComment(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package overhumantest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// CollectInputs receives n values from ch — the output channel a sense's
+// Start was given — and fails the test if they do not all arrive within
+// timeout.
+func CollectInputs[T any](t testing.TB, ch <-chan T, n int, timeout time.Duration) []T {
+	t.Helper()
+	deadline := time.After(timeout)
+	out := make([]T, 0, n)
+	for len(out) < n {
+		select {
+		case in := <-ch:
+			out = append(out, in)
+		case <-deadline:
+			t.Fatalf("received %d input(s) within %s, want %d", len(out), timeout, n)
+		}
+	}
+	return out
+}
+
+// ExpectNoInput fails the test if ch yields a value within wait.
+func ExpectNoInput[T any](t testing.TB, ch <-chan T, wait time.Duration) {
+	t.Helper()
+	select {
+	case in := <-ch:
+		t.Fatalf("unexpected input: %+v", in)
+	case <-time.After(wait):
+	}
+}
+
+// InputWant is what AssertInput checks. Empty fields are not checked.
+type InputWant struct {
+	SourceType      string
+	Channel         string
+	Sender          string
+	Payload         string // exact payload
+	PayloadContains string
+	Priority        string // "LOW", "NORMAL", "HIGH" or "CRITICAL"
+	ResponseChannel string
+	Extra           map[string]string // entries that must be present
+}
+
+// wireInput is the JSON form of a senses.UnifiedInput.
+type wireInput struct {
+	InputID    string `json:"input_id"`
+	SourceType string `json:"source_type"`
+	SourceMeta struct {
+		Timestamp time.Time         `json:"timestamp"`
+		Channel   string            `json:"channel"`
+		Sender    string            `json:"sender"`
+		Extra     map[string]string `json:"extra"`
+	} `json:"source_meta"`
+	Payload         string `json:"payload"`
+	Priority        string `json:"priority"`
+	ResponseChannel string `json:"response_channel"`
+}
+
+// AssertInput checks a senses.UnifiedInput (or a pointer to one) against
+// want, and that it has the ID and timestamp every input needs. The input
+// is compared in its JSON form — the contract the pipeline, the API and
+// the task queue share.
+func AssertInput(t testing.TB, got any, want InputWant) {
+	t.Helper()
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("input: %v", err)
+	}
+	var in wireInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		t.Fatalf("input %s: %v", data, err)
+	}
+	if in.InputID == "" {
+		t.Error("input has no input_id")
+	}
+	if in.SourceMeta.Timestamp.IsZero() {
+		t.Error("input has no timestamp")
+	}
+	check := func(field, got, want string) {
+		if want != "" && got != want {
+			t.Errorf("input %s = %q, want %q", field, got, want)
+		}
+	}
+	check("source_type", in.SourceType, want.SourceType)
+	check("channel", in.SourceMeta.Channel, want.Channel)
+	check("sender", in.SourceMeta.Sender, want.Sender)
+	check("payload", in.Payload, want.Payload)
+	check("priority", in.Priority, want.Priority)
+	check("response_channel", in.ResponseChannel, want.ResponseChannel)
+	if want.PayloadContains != "" && !strings.Contains(in.Payload, want.PayloadContains) {
+		t.Errorf("input payload = %q, want it to contain %q", in.Payload, want.PayloadContains)
+	}
+	for k, v := range want.Extra {
+		check("extra["+k+"]", in.SourceMeta.Extra[k], v)
+	}
+}
@@ -0,0 +1,283 @@
+package overhumantest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MailMessage is a message in a MockIMAP mailbox.
+type MailMessage struct {
+	SeqNum    int // IMAP sequence number
+	From      string
+	To        string
+	Subject   string
+	MessageID string
+	Body      string
+	Seen      bool
+}
+
+// MockIMAP is a plain-text IMAP4rev1 server with one mailbox. It accepts
+// any login and understands LOGIN, SELECT, SEARCH (unseen), FETCH of
+// header and text, STORE (marks seen) and LOGOUT.
+type MockIMAP struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []MailMessage
+}
+
+// NewMockIMAP starts a mock IMAP server holding messages. It is closed
+// when the test ends.
+func NewMockIMAP(t testing.TB, messages []MailMessage) *MockIMAP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("mock IMAP: %v", err)
+	}
+	s := &MockIMAP{listener: ln, messages: append([]MailMessage(nil), messages...)}
+	go serve(ln, s.handleConn)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Addr returns the server's host:port.
+func (s *MockIMAP) Addr() string { return s.listener.Addr().String() }
+
+// Close stops the server.
+func (s *MockIMAP) Close() { s.listener.Close() }
+
+// Seen reports whether the message with sequence number seq is marked seen.
+func (s *MockIMAP) Seen(seq int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.messages {
+		if m.SeqNum == seq {
+			return m.Seen
+		}
+	}
+	return false
+}
+
+func serve(ln net.Listener, handle func(net.Conn)) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go handle(conn)
+	}
+}
+
+func (s *MockIMAP) handleConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	fmt.Fprintf(conn, "* OK Mock IMAP server ready\r\n")
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		parts := strings.SplitN(strings.TrimRight(line, "\r\n"), " ", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		tag, cmd, args := parts[0], strings.ToUpper(parts[1]), ""
+		if len(parts) > 2 {
+			args = parts[2]
+		}
+
+		switch cmd {
+		case "LOGIN":
+			fmt.Fprintf(conn, "%s OK LOGIN completed\r\n", tag)
+
+		case "SELECT":
+			s.mu.Lock()
+			count := len(s.messages)
+			s.mu.Unlock()
+			fmt.Fprintf(conn, "* %d EXISTS\r\n", count)
+			fmt.Fprintf(conn, "* 0 RECENT\r\n")
+			fmt.Fprintf(conn, "* FLAGS (\\Seen \\Answered \\Flagged \\Deleted \\Draft)\r\n")
+			fmt.Fprintf(conn, "%s OK [READ-WRITE] SELECT completed (%s)\r\n", tag, strings.Trim(args, "\" "))
+
+		case "SEARCH":
+			s.mu.Lock()
+			var unseen []string
+			for _, m := range s.messages {
+				if !m.Seen {
+					unseen = append(unseen, fmt.Sprint(m.SeqNum))
+				}
+			}
+			s.mu.Unlock()
+			if len(unseen) > 0 {
+				fmt.Fprintf(conn, "* SEARCH %s\r\n", strings.Join(unseen, " "))
+			} else {
+				fmt.Fprintf(conn, "* SEARCH\r\n")
+			}
+			fmt.Fprintf(conn, "%s OK SEARCH completed\r\n", tag)
+
+		case "FETCH":
+			var seq int
+			fmt.Sscanf(args, "%d", &seq)
+			if msg, ok := s.message(seq); ok {
+				header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMessage-ID: %s\r\nDate: Mon, 01 Jan 2026 00:00:00 +0000\r\n",
+					msg.From, msg.To, msg.Subject, msg.MessageID)
+				fmt.Fprintf(conn, "* %d FETCH (BODY[HEADER] {%d}\r\n%s\r\nBODY[TEXT] {%d}\r\n%s\r\nRFC822.SIZE %d)\r\n",
+					seq, len(header), header, len(msg.Body), msg.Body, len(header)+len(msg.Body)+4)
+			}
+			fmt.Fprintf(conn, "%s OK FETCH completed\r\n", tag)
+
+		case "STORE":
+			var seq int
+			fmt.Sscanf(args, "%d", &seq)
+			s.mu.Lock()
+			for i := range s.messages {
+				if s.messages[i].SeqNum == seq {
+					s.messages[i].Seen = true
+				}
+			}
+			s.mu.Unlock()
+			fmt.Fprintf(conn, "%s OK STORE completed\r\n", tag)
+
+		case "LOGOUT":
+			fmt.Fprintf(conn, "* BYE Mock IMAP server closing\r\n")
+			fmt.Fprintf(conn, "%s OK LOGOUT completed\r\n", tag)
+			return
+
+		default:
+			fmt.Fprintf(conn, "%s BAD Unknown command\r\n", tag)
+		}
+	}
+}
+
+func (s *MockIMAP) message(seq int) (MailMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.messages {
+		if m.SeqNum == seq {
+			return m, true
+		}
+	}
+	return MailMessage{}, false
+}
+
+// SMTPMessage is a message a MockSMTP server accepted.
+type SMTPMessage struct {
+	From string
+	To   []string
+	Data string // lines joined with "\n"
+}
+
+// MockSMTP is a plain-text SMTP server that accepts every message (no
+// STARTTLS, no AUTH).
+type MockSMTP struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	received []SMTPMessage
+}
+
+// NewMockSMTP starts a mock SMTP server. It is closed when the test ends.
+func NewMockSMTP(t testing.TB) *MockSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("mock SMTP: %v", err)
+	}
+	s := &MockSMTP{listener: ln}
+	go serve(ln, s.handleConn)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Addr returns the server's host:port.
+func (s *MockSMTP) Addr() string { return s.listener.Addr().String() }
+
+// Close stops the server.
+func (s *MockSMTP) Close() { s.listener.Close() }
+
+// Received returns the messages accepted so far.
+func (s *MockSMTP) Received() []SMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SMTPMessage(nil), s.received...)
+}
+
+func (s *MockSMTP) handleConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	fmt.Fprintf(conn, "220 Mock SMTP server ready\r\n")
+
+	var cur SMTPMessage
+	var inData bool
+	var data strings.Builder
+	for {
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if inData {
+			if line == "." {
+				inData = false
+				cur.Data = data.String()
+				s.mu.Lock()
+				s.received = append(s.received, cur)
+				s.mu.Unlock()
+				fmt.Fprintf(conn, "250 OK message accepted\r\n")
+				cur = SMTPMessage{}
+				data.Reset()
+			} else {
+				data.WriteString(line)
+				data.WriteString("\n")
+			}
+			continue
+		}
+
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO") || strings.HasPrefix(cmd, "HELO"):
+			fmt.Fprintf(conn, "250-Mock SMTP\r\n")
+			fmt.Fprintf(conn, "250 OK\r\n")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			cur.From = angleAddr(line[10:])
+			fmt.Fprintf(conn, "250 OK\r\n")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			cur.To = append(cur.To, angleAddr(line[8:]))
+			fmt.Fprintf(conn, "250 OK\r\n")
+		case strings.HasPrefix(cmd, "DATA"):
+			inData = true
+			fmt.Fprintf(conn, "354 Start mail input\r\n")
+		case strings.HasPrefix(cmd, "QUIT"):
+			fmt.Fprintf(conn, "221 Bye\r\n")
+			return
+		case strings.HasPrefix(cmd, "RSET"):
+			cur = SMTPMessage{}
+			data.Reset()
+			fmt.Fprintf(conn, "250 OK\r\n")
+		default:
+			fmt.Fprintf(conn, "500 Unknown command\r\n")
+		}
+	}
+}
+
+// angleAddr returns the address between < and >, or s trimmed.
+func angleAddr(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "<"); i >= 0 {
+		if j := strings.Index(s, ">"); j > i {
+			return s[i+1 : j]
+		}
+	}
+	return s
+}
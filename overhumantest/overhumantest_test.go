@@ -0,0 +1,71 @@
+package overhumantest_test
+
+import (
+	"context"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/overhuman/overhuman/internal/brain"
+	"github.com/overhuman/overhuman/internal/senses"
+	"github.com/overhuman/overhuman/overhumantest"
+)
+
+func TestMockProvider(t *testing.T) {
+	srv := overhumantest.NewMockProvider(t, "first", "second")
+	llm := brain.NewClaudeProvider("test-key", brain.WithClaudeBaseURL(srv.URL))
+
+	var got []string
+	for _, q := range []string{"a", "b", "c"} {
+		resp, err := llm.Complete(context.Background(), brain.LLMRequest{
+			Messages: []brain.Message{{Role: "system", Content: "be brief"}, {Role: "user", Content: q}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, resp.Content)
+	}
+	if strings.Join(got, ",") != "first,second,second" {
+		t.Errorf("replies = %v", got)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 3 || reqs[2].LastUser() != "c" || reqs[0].System != "be brief" {
+		t.Errorf("requests = %+v", reqs)
+	}
+
+	srv.Handle(func(overhumantest.Request) overhumantest.Response {
+		return overhumantest.Response{Status: http.StatusServiceUnavailable, Text: "overloaded"}
+	})
+	if _, err := llm.Complete(context.Background(), brain.LLMRequest{Messages: []brain.Message{{Role: "user", Content: "d"}}}); err == nil || !brain.IsUnreachableError(err) {
+		t.Errorf("503 reply: err = %v", err)
+	}
+}
+
+func TestMockSMTP(t *testing.T) {
+	srv := overhumantest.NewMockSMTP(t)
+	if err := smtp.SendMail(srv.Addr(), nil, "bot@example.com", []string{"a@example.com"}, []byte("Subject: hi\r\n\r\nhello\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := srv.Received()
+	if len(got) != 1 || got[0].From != "bot@example.com" || got[0].To[0] != "a@example.com" || !strings.Contains(got[0].Data, "hello") {
+		t.Errorf("received = %+v", got)
+	}
+}
+
+func TestInputHelpers(t *testing.T) {
+	ch := make(chan *senses.UnifiedInput, 2)
+	in := senses.NewFromText("hello world")
+	in.SourceMeta.Extra = map[string]string{"k": "v"}
+	ch <- in
+	got := overhumantest.CollectInputs(t, ch, 1, time.Second)
+	overhumantest.AssertInput(t, got[0], overhumantest.InputWant{
+		SourceType:      string(senses.SourceText),
+		Channel:         "text",
+		PayloadContains: "world",
+		Priority:        "NORMAL",
+		Extra:           map[string]string{"k": "v"},
+	})
+	overhumantest.ExpectNoInput(t, ch, 10*time.Millisecond)
+}
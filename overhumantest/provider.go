@@ -0,0 +1,149 @@
+// Package overhumantest provides the fakes the core tests run against — a
+// mock LLM provider, mock IMAP and SMTP servers and helpers for the inputs
+// a sense emits — so sense, skill and provider authors can test their code
+// against the same contracts.
+//
+// The package imports none of the other Overhuman packages: a sense's own
+// (in-package) tests can use it without an import cycle. The fakes speak
+// the wire protocols (the Anthropic Messages API, IMAP4rev1, SMTP) and
+// inputs are checked in their JSON form.
+package overhumantest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// MockModel is the model a MockProvider reports, whatever was asked for.
+const MockModel = "claude-sonnet-4-20250514"
+
+// Request is a completion request a MockProvider received.
+type Request struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	System    string    `json:"system,omitempty"`
+	Messages  []Message `json:"messages"`
+	Stream    bool      `json:"stream,omitempty"`
+}
+
+// Message is one message of a Request.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// LastUser returns the content of the request's last user message.
+func (r Request) LastUser() string {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Role == "user" {
+			return r.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// Response is what a MockProvider answers. Zero token counts report 50
+// input and 30 output tokens.
+type Response struct {
+	Text         string
+	StopReason   string // default "end_turn"
+	InputTokens  int
+	OutputTokens int
+	Status       int // non-zero and not 200: an API error with Text as its message
+}
+
+// MockProvider is an httptest server speaking the Anthropic Messages API
+// (POST /v1/messages, non-streaming). Point a provider at it with
+// brain.NewClaudeProvider("test-key", brain.WithClaudeBaseURL(m.URL)).
+// It records the requests it receives.
+type MockProvider struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	replies  []string
+	handler  func(Request) Response
+	requests []Request
+}
+
+// NewMockProvider starts a mock provider that answers with replies in
+// turn, repeating the last one ("OK" when there are none). It is closed
+// when the test ends.
+func NewMockProvider(t testing.TB, replies ...string) *MockProvider {
+	t.Helper()
+	if len(replies) == 0 {
+		replies = []string{"OK"}
+	}
+	m := &MockProvider{replies: replies}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serve))
+	t.Cleanup(m.Close)
+	return m
+}
+
+// Handle makes the provider answer with fn's response from now on. fn may
+// be called concurrently.
+func (m *MockProvider) Handle(fn func(Request) Response) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handler = fn
+}
+
+// Requests returns the requests received so far.
+func (m *MockProvider) Requests() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Request(nil), m.requests...)
+}
+
+// Calls returns the number of requests received so far.
+func (m *MockProvider) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.requests)
+}
+
+func (m *MockProvider) serve(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"type":"error","error":{"type":"invalid_request_error","message":%q}}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	n, handler := len(m.requests), m.handler
+	m.mu.Unlock()
+	var resp Response
+	if handler != nil {
+		resp = handler(req)
+	} else {
+		resp = Response{Text: m.replies[min(n, len(m.replies))-1]}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != 0 && resp.Status != http.StatusOK {
+		w.WriteHeader(resp.Status)
+		json.NewEncoder(w).Encode(map[string]any{
+			"type":  "error",
+			"error": map[string]string{"type": "api_error", "message": resp.Text},
+		})
+		return
+	}
+	if resp.StopReason == "" {
+		resp.StopReason = "end_turn"
+	}
+	if resp.InputTokens == 0 && resp.OutputTokens == 0 {
+		resp.InputTokens, resp.OutputTokens = 50, 30
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":          "msg_test",
+		"type":        "message",
+		"role":        "assistant",
+		"model":       MockModel,
+		"content":     []map[string]string{{"type": "text", "text": resp.Text}},
+		"stop_reason": resp.StopReason,
+		"usage":       map[string]int{"input_tokens": resp.InputTokens, "output_tokens": resp.OutputTokens},
+	})
+}